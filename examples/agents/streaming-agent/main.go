@@ -8,10 +8,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/digitallysavvy/go-ai/pkg/ai"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+	"github.com/digitallysavvy/go-ai/pkg/tui"
 )
 
 func main() {
@@ -67,68 +67,29 @@ func runStreamingAgent(ctx context.Context, model provider.LanguageModel, query
 		createCodeAnalyzerTool(),
 	}
 
-	stepCount := 0
-
-	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
-		Model:  model,
-		Prompt: query,
+	// The chat streams text as it is generated and shows each tool call and
+	// result, calling the model again until it answers without tools
+	chat := tui.New(tui.Options{
+		Model: model,
 		System: `You are a thorough research assistant. Break down complex queries into steps.
 Use available tools to gather information. Think step by step and explain your process.`,
 		Tools:    tools,
-		StopWhen: []ai.StopCondition{ai.StepCountIs(6)},
-		OnStepFinish: func(ctx context.Context, step types.StepResult, userContext interface{}) {
-			stepCount++
-			fmt.Printf("\n[Step %d]\n", stepCount)
-
-			// Show tool calls
-			if len(step.ToolCalls) > 0 {
-				fmt.Println("🔧 Tool Calls:")
-				for _, tc := range step.ToolCalls {
-					fmt.Printf("   • %s\n", tc.ToolName)
-					if args, ok := tc.Arguments["query"].(string); ok {
-						fmt.Printf("     Query: %s\n", args)
-					}
-					if args, ok := tc.Arguments["code"].(string); ok {
-						fmt.Printf("     Code: %s\n", args)
-					}
-				}
-			}
-
-			// Show tool results
-			if len(step.ToolResults) > 0 {
-				fmt.Println("📊 Results:")
-				for _, tr := range step.ToolResults {
-					fmt.Printf("   %s\n", summarizeToolResult(tr.Result))
-				}
-			}
-
-			// Show reasoning text (simulate streaming)
-			if step.Text != "" {
-				fmt.Println("💭 Reasoning:")
-				fmt.Print("   ")
-				simulateStreaming(step.Text)
-				fmt.Println()
-			}
-		},
+		MaxSteps: 6,
+		Color:    true,
 	})
 
+	turn, err := chat.Send(ctx, query)
 	if err != nil {
 		log.Printf("Error: %v", err)
 		return
 	}
 
-	fmt.Println("\n[Final Response]")
-	fmt.Print("📝 ")
-
-	// Simulate streaming the final response
-	simulateStreaming(result.Text)
-	fmt.Println()
-
 	// Show final statistics
 	fmt.Printf("\n[Statistics]\n")
-	fmt.Printf("   Steps: %d\n", stepCount)
+	fmt.Printf("   Steps: %d\n", turn.Steps)
+	fmt.Printf("   Tool calls: %d\n", len(turn.ToolCalls))
 	fmt.Printf("   Tokens: %d (input: %d, output: %d)\n",
-		result.Usage.GetTotalTokens(), result.Usage.GetInputTokens(), result.Usage.GetOutputTokens())
+		turn.Usage.GetTotalTokens(), turn.Usage.GetInputTokens(), turn.Usage.GetOutputTokens())
 }
 
 func createResearchTool() types.Tool {
@@ -309,53 +270,3 @@ func createCodeAnalyzerTool() types.Tool {
 		},
 	}
 }
-
-func summarizeToolResult(result interface{}) string {
-	data, ok := result.(map[string]interface{})
-	if !ok {
-		return fmt.Sprintf("%v", result)
-	}
-
-	// Research results
-	if findings, ok := data["findings"].([]string); ok {
-		return fmt.Sprintf("Found %d findings on %s", len(findings), data["topic"])
-	}
-
-	// Data analysis results
-	if insights, ok := data["insights"].([]string); ok {
-		return fmt.Sprintf("Generated %d insights from analysis", len(insights))
-	}
-
-	// Code analysis results
-	if issues, ok := data["issues"].([]interface{}); ok {
-		return fmt.Sprintf("Identified %d code issues", len(issues))
-	}
-
-	return fmt.Sprintf("%v", result)
-}
-
-func simulateStreaming(text string) {
-	// Simulate streaming by printing character by character with slight delay
-	words := strings.Fields(text)
-	lineLength := 0
-
-	for i, word := range words {
-		fmt.Print(word)
-		lineLength += len(word)
-
-		if i < len(words)-1 {
-			fmt.Print(" ")
-			lineLength++
-		}
-
-		// Add newline for readability
-		if lineLength > 80 && i < len(words)-1 {
-			fmt.Println()
-			fmt.Print("   ")
-			lineLength = 0
-		}
-
-		// Small delay to simulate streaming
-		time.Sleep(20 * time.Millisecond)
-	}
-}
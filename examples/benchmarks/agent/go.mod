@@ -0,0 +1,19 @@
+module github.com/digitallysavvy/go-ai/examples/benchmarks/agent
+
+go 1.25.4
+
+replace github.com/digitallysavvy/go-ai => ../../..
+
+require github.com/digitallysavvy/go-ai v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.42.0 // indirect
+	go.opentelemetry.io/otel/metric v1.42.0 // indirect
+	go.opentelemetry.io/otel/trace v1.42.0 // indirect
+	golang.org/x/time v0.15.0 // indirect
+)
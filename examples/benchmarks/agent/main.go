@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent/benchmark"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+)
+
+func main() {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		log.Fatal("OPENAI_API_KEY required")
+	}
+
+	p := openai.New(openai.Config{APIKey: apiKey})
+	gpt4o, _ := p.LanguageModel("gpt-4o")
+	gpt4oMini, _ := p.LanguageModel("gpt-4o-mini")
+
+	weather := benchmark.MockTool("get_weather", "Get the current weather for a city",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"city"},
+		},
+		func(input map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"city": input["city"], "tempC": 18, "conditions": "rain"}, nil
+		})
+	convert := benchmark.MockTool("celsius_to_fahrenheit", "Convert Celsius to Fahrenheit",
+		map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"celsius": map[string]interface{}{"type": "number"}},
+			"required":   []string{"celsius"},
+		},
+		func(input map[string]interface{}) (interface{}, error) {
+			c, _ := input["celsius"].(float64)
+			return c*9/5 + 32, nil
+		})
+
+	suite := benchmark.Suite{
+		Name: "weather-assistant",
+		Tasks: []benchmark.Task{
+			{
+				Name:   "lookup",
+				Prompt: "Is it raining in Dublin right now?",
+				Tools:  []types.Tool{weather},
+				Check: benchmark.ExpectAll(
+					benchmark.ExpectToolCalled("get_weather"),
+					benchmark.ExpectContains("rain"),
+				),
+			},
+			{
+				Name:   "multi-step",
+				Prompt: "What is the temperature in Dublin in Fahrenheit?",
+				Tools:  []types.Tool{weather, convert},
+				Check: benchmark.ExpectAll(
+					benchmark.ExpectToolCalled("get_weather"),
+					benchmark.ExpectToolCalled("celsius_to_fahrenheit"),
+					benchmark.ExpectContains("64"),
+					benchmark.ExpectMaxSteps(4),
+				),
+			},
+		},
+	}
+
+	report, err := benchmark.Run(context.Background(), suite, benchmark.Options{
+		Models: []benchmark.Model{
+			{Name: "gpt-4o", Model: gpt4o},
+			{Name: "gpt-4o-mini", Model: gpt4oMini},
+		},
+		Runs:        3,
+		Concurrency: 2,
+		OnResult: func(r benchmark.TaskResult) {
+			fmt.Printf("%s/%s run %d: success=%v (%v)\n", r.Model, r.Task, r.Run, r.Success, r.Duration)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println()
+	if err := report.WriteTable(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range report.Failures() {
+		fmt.Printf("FAILED %s/%s run %d: %v\n", f.Model, f.Task, f.Run, f.Err)
+	}
+}
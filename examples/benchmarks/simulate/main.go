@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/benchmark"
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+)
+
+// Replays recorded conversations (go-ai JSON files, as written by
+// conversation.Export) against a model to plan capacity:
+//
+//	go run . -dir ./recorded -concurrency 50 -replays 4 -mock
+//	OPENAI_API_KEY=... go run . -dir ./recorded -model gpt-4o-mini -input-price 0.15 -output-price 0.6
+func main() {
+	dir := flag.String("dir", ".", "directory of recorded conversations (*.json)")
+	concurrency := flag.Int("concurrency", 10, "conversations replayed at once")
+	replays := flag.Int("replays", 1, "times each conversation is replayed")
+	thinkTime := flag.Duration("think-time", 0, "mean pause before each user message")
+	mock := flag.Bool("mock", false, "use a synthetic model instead of OpenAI")
+	modelID := flag.String("model", "gpt-4o-mini", "OpenAI model ID")
+	inputPrice := flag.Float64("input-price", 0, "USD per million input tokens")
+	outputPrice := flag.Float64("output-price", 0, "USD per million output tokens")
+	monthly := flag.Int64("monthly", 100_000, "conversations per month for the cost projection")
+	flag.Parse()
+
+	conversations, err := loadConversations(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var model provider.LanguageModel
+	if *mock {
+		model = benchmark.NewSyntheticModel(benchmark.SyntheticConfig{
+			TimeToFirstToken: benchmark.Normal(400*time.Millisecond, 100*time.Millisecond),
+			OutputTokens:     150,
+			ErrorRate:        0.01,
+		})
+	} else {
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Fatal("OPENAI_API_KEY required (or use -mock)")
+		}
+		model, err = openai.New(openai.Config{APIKey: apiKey}).LanguageModel(*modelID)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	opts := benchmark.SimulationOptions{
+		Conversations: conversations,
+		Model:         model,
+		Concurrency:   *concurrency,
+		Replays:       *replays,
+		Cost: func(usage types.Usage) float64 {
+			return (*inputPrice*float64(usage.GetInputTokens()) + *outputPrice*float64(usage.GetOutputTokens())) / 1e6
+		},
+	}
+	if *thinkTime > 0 {
+		opts.ThinkTime = benchmark.Normal(*thinkTime, *thinkTime/3)
+	}
+
+	fmt.Printf("Replaying %d conversations x%d at concurrency %d\n\n", len(conversations), *replays, *concurrency)
+	result, err := benchmark.Simulate(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	result.Print(os.Stdout)
+	fmt.Printf("Projected cost for %d conversations/month: $%.2f\n", *monthly, result.ProjectCost(*monthly))
+}
+
+func loadConversations(dir string) ([]*conversation.Conversation, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var conversations []*conversation.Conversation
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		c, err := conversation.Import(data, conversation.FormatGoAI)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		conversations = append(conversations, c)
+	}
+	if len(conversations) == 0 {
+		return nil, fmt.Errorf("no conversations found in %s", dir)
+	}
+	return conversations, nil
+}
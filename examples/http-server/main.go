@@ -7,9 +7,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/playground"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
@@ -17,6 +22,12 @@ import (
 
 var model provider.LanguageModel
 
+// aiProvider is probed by the /health endpoint
+var aiProvider provider.Provider
+
+// drainer tracks in-flight generations so the server can shut down gracefully
+var drainer = middleware.NewDrainer()
+
 // Request/Response types
 type GenerateRequest struct {
 	Prompt      string                 `json:"prompt"`
@@ -46,12 +57,12 @@ func main() {
 	}
 
 	// Create OpenAI provider and model
-	p := openai.New(openai.Config{
+	aiProvider = drainer.WrapProvider(openai.New(openai.Config{
 		APIKey: apiKey,
-	})
+	}))
 
 	var err error
-	model, err = p.LanguageModel("gpt-4")
+	model, err = aiProvider.LanguageModel("gpt-4")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -59,13 +70,42 @@ func main() {
 	// Setup HTTP routes
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", handleRoot)
-	mux.HandleFunc("/generate", handleGenerate)
-	mux.HandleFunc("/stream", handleStream)
-	mux.HandleFunc("/tools", handleTools)
 	mux.HandleFunc("/health", handleHealth)
 
-	// CORS middleware
-	handler := corsMiddleware(mux)
+	// Generation endpoints. With SIGNING_SECRET set, only server-to-server
+	// callers that sign requests (see middleware.SigningTransport) may use them.
+	generation := map[string]http.HandlerFunc{
+		"/generate": handleGenerate,
+		"/stream":   handleStream,
+		"/tools":    handleTools,
+	}
+	var verifier *middleware.RequestVerifier
+	if secret := os.Getenv("SIGNING_SECRET"); secret != "" {
+		verifier = middleware.NewRequestVerifier(middleware.RequestVerifierOptions{
+			Keys: map[string]string{os.Getenv("SIGNING_KEY_ID"): secret},
+		})
+	}
+	for path, h := range generation {
+		if verifier != nil {
+			mux.Handle(path, verifier.Handler(h))
+		} else {
+			mux.Handle(path, h)
+		}
+	}
+
+	// Optional browser playground for trying prompts against this deployment.
+	// It uses the server's API key, so only enable it on internal deployments.
+	if os.Getenv("PLAYGROUND") != "" {
+		mux.Handle("/playground/", http.StripPrefix("/playground", playground.Handler(playground.Options{
+			Models: []string{"openai:gpt-4", "openai:gpt-4o-mini"},
+			Resolve: func(ref string) (provider.LanguageModel, error) {
+				return aiProvider.LanguageModel(strings.TrimPrefix(ref, "openai:"))
+			},
+		})))
+	}
+
+	// CORS middleware, with in-flight request tracking for graceful shutdown
+	handler := corsMiddleware(drainer.Handler(mux))
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -79,9 +119,33 @@ func main() {
 	log.Printf("  POST /stream   - Stream text completion (SSE)")
 	log.Printf("  POST /tools    - Generate with tool calling")
 	log.Printf("  GET  /health   - Health check")
+	if os.Getenv("PLAYGROUND") != "" {
+		log.Printf("  GET  /playground/ - Browser playground")
+	}
+	if verifier != nil {
+		log.Printf("Generation endpoints require signed requests")
+	}
 
-	if err := http.ListenAndServe(":"+port, handler); err != nil {
-		log.Fatal(err)
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for SIGINT/SIGTERM, then drain in-flight generations and streams
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Printf("Shutting down, waiting for %d in-flight request(s)...", drainer.InFlight())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := provider.Shutdown(ctx, aiProvider); err != nil {
+		log.Printf("drain: %v", err)
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("server shutdown: %v", err)
 	}
 }
 
@@ -355,13 +419,22 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Probe the provider with a lightweight authenticated call
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	report := provider.CheckHealth(ctx, aiProvider)
+
 	health := map[string]interface{}{
-		"status":    "healthy",
+		"status":    report.Status,
 		"timestamp": time.Now().Unix(),
 		"model":     model.ModelID(),
+		"providers": report.Providers,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if report.Status != provider.HealthStatusHealthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
 	_ = json.NewEncoder(w).Encode(health)
 }
 
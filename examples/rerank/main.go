@@ -4,37 +4,50 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"sort"
 
 	"github.com/digitallysavvy/go-ai/pkg/ai"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
-	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+	"github.com/digitallysavvy/go-ai/pkg/providers/cohere"
+	"github.com/digitallysavvy/go-ai/pkg/rag"
 )
 
 // Document represents a searchable document
 type Document struct {
 	ID      string
 	Content string
-	Score   float64 // Initial retrieval score
+	Score   float64 // Vector retrieval score
 }
 
 // RankedDocument represents a re-ranked document
 type RankedDocument struct {
 	Document
 	RerankScore float64
-	Relevance   string
+	HybridScore float64
 }
 
 func main() {
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	apiKey := os.Getenv("COHERE_API_KEY")
 	if apiKey == "" {
-		log.Fatal("OPENAI_API_KEY required")
+		log.Fatal("COHERE_API_KEY required")
 	}
 
-	p := openai.New(openai.Config{APIKey: apiKey})
-	model, _ := p.LanguageModel("gpt-4")
+	p := cohere.New(cohere.Config{APIKey: apiKey})
+	model, err := p.RerankingModel("rerank-v3.5")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Documents and queries are embedded differently for Cohere search
+	docEmbedder, err := p.EmbeddingModelWithOptions("embed-english-v3.0", cohere.EmbeddingOptions{InputType: cohere.InputTypeSearchDocument})
+	if err != nil {
+		log.Fatal(err)
+	}
+	queryEmbedder, err := p.EmbeddingModelWithOptions("embed-english-v3.0", cohere.EmbeddingOptions{InputType: cohere.InputTypeSearchQuery})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	ctx := context.Background()
 
@@ -44,113 +57,93 @@ func main() {
 	query := "How do I implement authentication in a Go web application?"
 
 	documents := []Document{
-		{ID: "doc1", Content: "Go has built-in HTTP server support with net/http package.", Score: 0.75},
-		{ID: "doc2", Content: "JWT tokens are commonly used for stateless authentication in web APIs.", Score: 0.82},
-		{ID: "doc3", Content: "The crypto package in Go provides cryptographic functions for secure applications.", Score: 0.68},
-		{ID: "doc4", Content: "OAuth 2.0 is a popular authentication framework. Go has several OAuth libraries.", Score: 0.79},
-		{ID: "doc5", Content: "Session-based authentication stores user state on the server side.", Score: 0.71},
+		{ID: "doc1", Content: "Go has built-in HTTP server support with net/http package."},
+		{ID: "doc2", Content: "JWT tokens are commonly used for stateless authentication in web APIs."},
+		{ID: "doc3", Content: "The crypto package in Go provides cryptographic functions for secure applications."},
+		{ID: "doc4", Content: "OAuth 2.0 is a popular authentication framework. Go has several OAuth libraries."},
+		{ID: "doc5", Content: "Session-based authentication stores user state on the server side."},
+	}
+
+	documents, err = retrieve(ctx, docEmbedder, queryEmbedder, query, documents)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	fmt.Printf("Query: %s\n\n", query)
-	fmt.Println("Initial ranking (by retrieval score):")
+	fmt.Println("Initial ranking (by vector similarity):")
 	for i, doc := range documents {
 		fmt.Printf("%d. %s (score: %.2f)\n", i+1, doc.ID, doc.Score)
 	}
 
-	// Rerank documents
-	reranked := rerankDocuments(ctx, model, query, documents)
+	// One request scores every document, instead of one LLM call per document
+	reranked, err := rerankDocuments(ctx, model, query, documents, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	fmt.Println("\nAfter reranking:")
 	for i, doc := range reranked {
-		fmt.Printf("%d. %s (rerank: %.2f, original: %.2f) - %s\n",
-			i+1, doc.ID, doc.RerankScore, doc.Score, doc.Relevance)
+		fmt.Printf("%d. %s (rerank: %.3f, original: %.2f)\n", i+1, doc.ID, doc.RerankScore, doc.Score)
 	}
 	fmt.Println()
 
-	// Example 2: Reranking with context
-	fmt.Println("=== Example 2: Context-Aware Reranking ===")
+	// Example 2: Top-N with context folded into the query
+	fmt.Println("=== Example 2: Context-Aware Top-N Reranking ===")
 
-	query2 := "best practices for error handling"
-	context2 := "I'm building a production REST API in Go"
+	query2 := "I'm building a production REST API in Go. Best practices for error handling?"
 
 	documents2 := []Document{
-		{ID: "doc1", Content: "Error handling in Go uses explicit error returns instead of exceptions.", Score: 0.85},
-		{ID: "doc2", Content: "Python uses try-except blocks for error handling.", Score: 0.76},
-		{ID: "doc3", Content: "HTTP status codes should reflect the actual error type in REST APIs.", Score: 0.82},
-		{ID: "doc4", Content: "Logging errors with context helps debugging in production.", Score: 0.80},
-		{ID: "doc5", Content: "JavaScript has async/await with try-catch for promise error handling.", Score: 0.73},
+		{ID: "doc1", Content: "Error handling in Go uses explicit error returns instead of exceptions."},
+		{ID: "doc2", Content: "Python uses try-except blocks for error handling."},
+		{ID: "doc3", Content: "HTTP status codes should reflect the actual error type in REST APIs."},
+		{ID: "doc4", Content: "Logging errors with context helps debugging in production."},
+		{ID: "doc5", Content: "JavaScript has async/await with try-catch for promise error handling."},
 	}
 
-	fmt.Printf("Query: %s\n", query2)
-	fmt.Printf("Context: %s\n\n", context2)
+	fmt.Printf("Query: %s\n\n", query2)
+
+	documents2, err = retrieve(ctx, docEmbedder, queryEmbedder, query2, documents2)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	reranked2 := rerankWithContext(ctx, model, query2, context2, documents2)
+	reranked2, err := rerankDocuments(ctx, model, query2, documents2, 3)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	fmt.Println("Reranked with context:")
+	fmt.Println("Top 3:")
 	for i, doc := range reranked2 {
-		fmt.Printf("%d. %s (score: %.2f) - %s\n", i+1, doc.ID, doc.RerankScore, doc.Relevance)
+		fmt.Printf("%d. %s (score: %.3f) %s\n", i+1, doc.ID, doc.RerankScore, doc.Content)
 	}
 	fmt.Println()
 
-	// Example 3: Multi-criteria reranking
-	fmt.Println("=== Example 3: Multi-Criteria Reranking ===")
-
-	query3 := "database connection pooling"
-	criteria := []string{
-		"relevance to query",
-		"recency of information",
-		"code examples included",
-		"production readiness",
-	}
+	// Example 3: Hybrid scoring (combining retrieval + rerank)
+	fmt.Println("=== Example 3: Hybrid Scoring ===")
 
+	query3 := "concurrent programming patterns"
 	documents3 := []Document{
-		{ID: "doc1", Content: "Database/sql package provides connection pooling. Max connections set via SetMaxOpenConns(). Published 2023.", Score: 0.88},
-		{ID: "doc2", Content: "Connection pools improve performance by reusing connections. General concept from 2015.", Score: 0.82},
-		{ID: "doc3", Content: "Example: db.SetMaxOpenConns(25); db.SetMaxIdleConns(25); db.SetConnMaxLifetime(5*time.Minute)", Score: 0.91},
-		{ID: "doc4", Content: "PostgreSQL and MySQL both support connection pooling. Documentation from 2018.", Score: 0.75},
+		{ID: "doc1", Content: "Goroutines enable concurrent execution in Go. Use channels for communication."},
+		{ID: "doc2", Content: "Thread pools manage concurrent execution in Java with ExecutorService."},
+		{ID: "doc3", Content: "The select statement in Go allows waiting on multiple channel operations."},
+		{ID: "doc4", Content: "Async/await in JavaScript provides asynchronous programming without callbacks."},
 	}
 
-	fmt.Printf("Query: %s\n", query3)
-	fmt.Printf("Criteria: %v\n\n", criteria)
-
-	reranked3 := rerankMultiCriteria(ctx, model, query3, criteria, documents3)
-
-	fmt.Println("Multi-criteria reranking:")
-	for i, doc := range reranked3 {
-		fmt.Printf("%d. %s (score: %.2f)\n   %s\n", i+1, doc.ID, doc.RerankScore, doc.Relevance)
+	documents3, err = retrieve(ctx, docEmbedder, queryEmbedder, query3, documents3)
+	if err != nil {
+		log.Fatal(err)
 	}
-	fmt.Println()
 
-	// Example 4: Hybrid scoring (combining retrieval + rerank)
-	fmt.Println("=== Example 4: Hybrid Scoring ===")
-
-	query4 := "concurrent programming patterns"
-	documents4 := []Document{
-		{ID: "doc1", Content: "Goroutines enable concurrent execution in Go. Use channels for communication.", Score: 0.85},
-		{ID: "doc2", Content: "Thread pools manage concurrent execution in Java with ExecutorService.", Score: 0.78},
-		{ID: "doc3", Content: "The select statement in Go allows waiting on multiple channel operations.", Score: 0.82},
-		{ID: "doc4", Content: "Async/await in JavaScript provides asynchronous programming without callbacks.", Score: 0.72},
+	reranked3, err := hybridRerank(ctx, model, query3, documents3, 0.6, 0.4)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	reranked4 := hybridRerank(ctx, model, query4, documents4, 0.6, 0.4)
-
-	fmt.Printf("Query: %s\n\n", query4)
+	fmt.Printf("Query: %s\n\n", query3)
 	fmt.Println("Hybrid scoring (60% rerank + 40% retrieval):")
-	for i, doc := range reranked4 {
-		fmt.Printf("%d. %s (hybrid: %.2f, rerank: %.2f, retrieval: %.2f)\n",
-			i+1, doc.ID, doc.Score, doc.RerankScore, doc.Document.Score)
-	}
-
-	fmt.Println("\n=== Reranking Benefits ===")
-	benefits := []string{
-		"✓ Improved relevance ranking",
-		"✓ Context-aware ordering",
-		"✓ Better semantic understanding",
-		"✓ Reduced false positives",
-		"✓ Higher quality search results",
-	}
-	for _, benefit := range benefits {
-		fmt.Println("  " + benefit)
+	for i, doc := range reranked3 {
+		fmt.Printf("%d. %s (hybrid: %.3f, rerank: %.3f, retrieval: %.2f)\n",
+			i+1, doc.ID, doc.HybridScore, doc.RerankScore, doc.Score)
 	}
 
 	fmt.Println("\n=== Use Cases ===")
@@ -166,147 +159,86 @@ func main() {
 	}
 }
 
-func rerankDocuments(ctx context.Context, model provider.LanguageModel, query string, docs []Document) []RankedDocument {
-	ranked := make([]RankedDocument, 0, len(docs))
-
-	for _, doc := range docs {
-		prompt := fmt.Sprintf(`Rate the relevance of this document to the query on a scale of 0-100.
-
-Query: %s
-
-Document: %s
-
-Provide only a number between 0-100.`, query, doc.Content)
-
-		result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
-			Model:  model,
-			Prompt: prompt,
-		})
-
-		score := 50.0 // default
-		if err == nil {
-			fmt.Sscanf(result.Text, "%f", &score)
-		}
-
-		relevance := "Medium"
-		if score > 75 {
-			relevance = "High"
-		} else if score < 40 {
-			relevance = "Low"
-		}
-
-		ranked = append(ranked, RankedDocument{
-			Document:    doc,
-			RerankScore: score / 100.0,
-			Relevance:   relevance,
-		})
+// retrieve indexes docs in an in-memory vector store and returns them
+// ordered by similarity to query, with Score set, as a first-stage
+// retriever would
+func retrieve(ctx context.Context, docEmbedder, queryEmbedder provider.EmbeddingModel, query string, docs []Document) ([]Document, error) {
+	contents := make([]string, len(docs))
+	for i, doc := range docs {
+		contents[i] = doc.Content
 	}
-
-	// Sort by rerank score
-	sort.Slice(ranked, func(i, j int) bool {
-		return ranked[i].RerankScore > ranked[j].RerankScore
-	})
-
-	return ranked
-}
-
-func rerankWithContext(ctx context.Context, model provider.LanguageModel, query string, context string, docs []Document) []RankedDocument {
-	ranked := make([]RankedDocument, 0, len(docs))
-
-	for _, doc := range docs {
-		prompt := fmt.Sprintf(`Given the user's context and query, rate document relevance (0-100).
-
-User Context: %s
-Query: %s
-Document: %s
-
-Score (0-100):`, context, query, doc.Content)
-
-		result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
-			Model:  model,
-			Prompt: prompt,
-		})
-
-		score := 50.0
-		if err == nil {
-			fmt.Sscanf(result.Text, "%f", &score)
-		}
-
-		relevance := "Relevant to context and query"
-		if score < 50 {
-			relevance = "Less relevant given context"
-		}
-
-		ranked = append(ranked, RankedDocument{
-			Document:    doc,
-			RerankScore: score / 100.0,
-			Relevance:   relevance,
-		})
+	embedded, err := ai.EmbedMany(ctx, ai.EmbedManyOptions{Model: docEmbedder, Inputs: contents})
+	if err != nil {
+		return nil, err
+	}
+	store := rag.NewMemoryVectorStore()
+	records := make([]rag.Record, len(docs))
+	byID := make(map[string]Document, len(docs))
+	for i, doc := range docs {
+		records[i] = rag.Record{ID: doc.ID, Vector: embedded.Embeddings[i], Content: doc.Content}
+		byID[doc.ID] = doc
+	}
+	if err := store.Upsert(ctx, records); err != nil {
+		return nil, err
 	}
 
-	sort.Slice(ranked, func(i, j int) bool {
-		return ranked[i].RerankScore > ranked[j].RerankScore
-	})
-
-	return ranked
+	results, err := rag.NewVectorStoreRetriever(queryEmbedder, store, len(docs)).Retrieve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	retrieved := make([]Document, len(results))
+	for i, r := range results {
+		doc := byID[r.ID]
+		doc.Score = r.Score
+		retrieved[i] = doc
+	}
+	return retrieved, nil
 }
 
-func rerankMultiCriteria(ctx context.Context, model provider.LanguageModel, query string, criteria []string, docs []Document) []RankedDocument {
-	ranked := make([]RankedDocument, 0, len(docs))
-
-	for _, doc := range docs {
-		prompt := fmt.Sprintf(`Evaluate this document against multiple criteria (0-100 for each).
-
-Query: %s
-Criteria: %v
-Document: %s
-
-Provide scores and reasoning.`, query, criteria, doc.Content)
-
-		result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
-			Model:  model,
-			Prompt: prompt,
-		})
+// rerankDocuments scores docs against query with the reranking model and
+// returns them in relevance order. topN of 0 returns every document.
+func rerankDocuments(ctx context.Context, model provider.RerankingModel, query string, docs []Document, topN int) ([]RankedDocument, error) {
+	contents := make([]string, len(docs))
+	for i, doc := range docs {
+		contents[i] = doc.Content
+	}
 
-		// Simplified: extract a score
-		score := 50.0
-		if err == nil {
-			// In real implementation, parse individual criterion scores
-			fmt.Sscanf(result.Text, "%f", &score)
-		}
+	opts := ai.RerankOptions{
+		Model:     model,
+		Documents: contents,
+		Query:     query,
+	}
+	if topN > 0 {
+		opts.TopN = &topN
+	}
+	result, err := ai.Rerank(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
 
+	ranked := make([]RankedDocument, 0, len(result.Ranking))
+	for _, item := range result.Ranking {
 		ranked = append(ranked, RankedDocument{
-			Document:    doc,
-			RerankScore: score / 100.0,
-			Relevance:   result.Text[:min(100, len(result.Text))],
+			Document:    docs[item.OriginalIndex],
+			RerankScore: item.Score,
 		})
 	}
-
-	sort.Slice(ranked, func(i, j int) bool {
-		return ranked[i].RerankScore > ranked[j].RerankScore
-	})
-
-	return ranked
+	return ranked, nil
 }
 
-func hybridRerank(ctx context.Context, model provider.LanguageModel, query string, docs []Document, rerankWeight, retrievalWeight float64) []RankedDocument {
-	// First, get rerank scores
-	reranked := rerankDocuments(ctx, model, query, docs)
+func hybridRerank(ctx context.Context, model provider.RerankingModel, query string, docs []Document, rerankWeight, retrievalWeight float64) ([]RankedDocument, error) {
+	reranked, err := rerankDocuments(ctx, model, query, docs, 0)
+	if err != nil {
+		return nil, err
+	}
 
 	// Combine scores
 	for i := range reranked {
-		hybrid := (reranked[i].RerankScore * rerankWeight) + (reranked[i].Document.Score * retrievalWeight)
-		reranked[i].Score = hybrid
+		reranked[i].HybridScore = reranked[i].RerankScore*rerankWeight + reranked[i].Score*retrievalWeight
 	}
 
-	// Re-sort by hybrid score
 	sort.Slice(reranked, func(i, j int) bool {
-		return reranked[i].Score > reranked[j].Score
+		return reranked[i].HybridScore > reranked[j].HybridScore
 	})
-
-	return reranked
-}
-
-func min(a, b int) int {
-	return int(math.Min(float64(a), float64(b)))
+	return reranked, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
@@ -38,6 +39,9 @@ type AgentResult struct {
 	// Empty if the agent ended naturally or was not using custom stop conditions.
 	StopReason string
 
+	// DryRunCalls are the tool calls a dry run did not execute, in order
+	DryRunCalls []types.ToolCall
+
 	// Total usage across all steps
 	Usage types.Usage
 
@@ -127,6 +131,20 @@ type AgentConfig struct {
 	// Tools available to the agent
 	Tools []types.Tool
 
+	// DryRun previews what the agent would do without invoking tool
+	// Execute functions; see ai.DryRunOptions. The calls it intended are
+	// returned in AgentResult.DryRunCalls. Nil executes tools normally.
+	DryRun *ai.DryRunOptions
+
+	// Notes adds the built-in notes tool, a per-run scratch memory for
+	// intermediate findings. Nil leaves it out.
+	Notes *NotesOptions
+
+	// SessionResources are opened at most once per run and shared by all
+	// tool calls, then closed when the run ends. Tools get them with
+	// SessionValue. See ToolSession.
+	SessionResources []SessionResource
+
 	// Skills are reusable agent behaviors
 	// Skills can be registered and executed by the agent
 	Skills *SkillRegistry
@@ -154,6 +172,11 @@ type AgentConfig struct {
 	// Supports total timeout, per-step timeout, and per-chunk timeout
 	Timeout *ai.TimeoutConfig
 
+	// Clock measures tool durations and, when Timeout has no clock of its
+	// own, timeouts. Defaults to the system clock; inject a clock.Fake in
+	// tests.
+	Clock clock.Clock
+
 	// ========================================================================
 	// Dynamic Configuration (v6.0.41 - NEW)
 	// ========================================================================
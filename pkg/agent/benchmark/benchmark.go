@@ -0,0 +1,269 @@
+// Package benchmark runs agents against suites of multi-step tasks and
+// reports success rate, steps, token usage and wall time per model.
+//
+// Example:
+//
+//	suite := benchmark.Suite{
+//		Name: "support",
+//		Tasks: []benchmark.Task{{
+//			Name:   "refund",
+//			Prompt: "Refund order 42",
+//			Tools:  []types.Tool{benchmark.StaticTool("refund_order", "Refund an order", "refunded")},
+//			Check:  benchmark.ExpectAll(benchmark.ExpectToolCalled("refund_order"), benchmark.ExpectContains("refund")),
+//		}},
+//	}
+//	report, err := benchmark.Run(ctx, suite, benchmark.Options{
+//		Models: []benchmark.Model{{Name: "gpt-4o", Model: gpt4o}, {Name: "claude", Model: claude}},
+//		Runs:   5,
+//	})
+//	report.WriteTable(os.Stdout)
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Task is a single benchmark task
+type Task struct {
+	// Name identifies the task in reports
+	Name string
+
+	// Prompt is the user prompt. Messages takes precedence when set.
+	Prompt   string
+	Messages []types.Message
+
+	// System is the system prompt for the agent
+	System string
+
+	// Tools available to the agent, typically mocks (see MockTool)
+	Tools []types.Tool
+
+	// NewTools builds fresh tools for each run, for mocks that keep state.
+	// Takes precedence over Tools.
+	NewTools func() []types.Tool
+
+	// MaxSteps limits the agent loop
+	// Default: 10
+	MaxSteps int
+
+	// Timeout bounds a single run; zero means no limit
+	Timeout time.Duration
+
+	// Check decides whether a run solved the task. A nil Check counts every
+	// run that finishes without an error as a success.
+	Check CheckFunc
+}
+
+// Suite is a named collection of tasks
+type Suite struct {
+	Name  string
+	Tasks []Task
+}
+
+// Model is a named model under test
+type Model struct {
+	Name  string
+	Model provider.LanguageModel
+}
+
+// AgentFactory builds the agent used for one run of a task
+type AgentFactory func(model provider.LanguageModel, task Task, tools []types.Tool) agent.Agent
+
+// Options configures a benchmark run
+type Options struct {
+	// Models to benchmark
+	Models []Model
+
+	// NewAgent builds the agent under test
+	// Default: a ToolLoopAgent with the task's system prompt, tools and MaxSteps
+	NewAgent AgentFactory
+
+	// Runs is the number of times each task is run per model
+	// Default: 1
+	Runs int
+
+	// Concurrency is the number of runs executed in parallel
+	// Default: 1
+	Concurrency int
+
+	// Clock measures wall time
+	// Default: clock.Default
+	Clock clock.Clock
+
+	// OnResult is called after each run completes. Calls may be concurrent
+	// when Concurrency is above 1.
+	OnResult func(result TaskResult)
+}
+
+// TaskResult is the outcome of one run of a task
+type TaskResult struct {
+	Model string
+	Task  string
+	Run   int
+
+	// Success reports whether the run finished and passed the task's check
+	Success bool
+
+	// Err is the agent error or the check failure
+	Err error
+
+	// Steps is the number of agent steps taken
+	Steps int
+
+	// ToolCalls is the number of tool calls made
+	ToolCalls int
+
+	// Usage is the token usage of the run
+	Usage types.Usage
+
+	// Duration is the wall time of the run
+	Duration time.Duration
+
+	// Result is the agent result; nil when the agent failed
+	Result *agent.AgentResult
+}
+
+// defaultMaxSteps is the default per-task step limit
+const defaultMaxSteps = 10
+
+// Run executes every task of suite against each model and returns the
+// aggregated report. Runs that fail are recorded in the report, so the
+// returned error is only non-nil for invalid options or a cancelled context.
+func Run(ctx context.Context, suite Suite, opts Options) (*Report, error) {
+	if len(opts.Models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	if len(suite.Tasks) == 0 {
+		return nil, fmt.Errorf("suite %q has no tasks", suite.Name)
+	}
+	if opts.NewAgent == nil {
+		opts.NewAgent = defaultAgent
+	}
+	if opts.Runs <= 0 {
+		opts.Runs = 1
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	opts.Clock = clock.Default(opts.Clock)
+
+	type job struct {
+		index int
+		model Model
+		task  Task
+		run   int
+	}
+	var jobs []job
+	for _, m := range opts.Models {
+		for _, task := range suite.Tasks {
+			for run := 1; run <= opts.Runs; run++ {
+				jobs = append(jobs, job{index: len(jobs), model: m, task: task, run: run})
+			}
+		}
+	}
+
+	results := make([]TaskResult, len(jobs))
+	jobCh := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobCh {
+				res := runTask(ctx, j.model, j.task, opts)
+				res.Run = j.run
+				results[j.index] = res
+				if opts.OnResult != nil {
+					opts.OnResult(res)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, j := range jobs {
+		select {
+		case jobCh <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobCh)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newReport(suite.Name, opts.Models, results), nil
+}
+
+// runTask performs a single run of task against model
+func runTask(ctx context.Context, m Model, task Task, opts Options) TaskResult {
+	result := TaskResult{Model: m.Name, Task: task.Name}
+
+	tools := task.Tools
+	if task.NewTools != nil {
+		tools = task.NewTools()
+	}
+	a := opts.NewAgent(m.Model, task, tools)
+
+	runCtx := ctx
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = clock.WithTimeout(ctx, opts.Clock, task.Timeout)
+		defer cancel()
+	}
+
+	start := opts.Clock.Now()
+	var agentResult *agent.AgentResult
+	var err error
+	if len(task.Messages) > 0 {
+		agentResult, err = a.ExecuteWithMessages(runCtx, task.Messages)
+	} else {
+		agentResult, err = a.Execute(runCtx, task.Prompt)
+	}
+	result.Duration = opts.Clock.Now().Sub(start)
+
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Result = agentResult
+	result.Steps = len(agentResult.Steps)
+	result.Usage = agentResult.Usage
+	for _, step := range agentResult.Steps {
+		result.ToolCalls += len(step.ToolCalls)
+	}
+
+	if task.Check != nil {
+		if err := task.Check(agentResult); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	result.Success = true
+	return result
+}
+
+// defaultAgent builds a ToolLoopAgent for a task
+func defaultAgent(model provider.LanguageModel, task Task, tools []types.Tool) agent.Agent {
+	maxSteps := task.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+	return agent.NewToolLoopAgent(agent.AgentConfig{
+		Model:    model,
+		System:   task.System,
+		Tools:    tools,
+		MaxSteps: maxSteps,
+	})
+}
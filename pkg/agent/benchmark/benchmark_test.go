@@ -0,0 +1,131 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// toolThenAnswer returns a model that calls tool once and then answers
+func toolThenAnswer(tool, answer string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			tokens := int64(10)
+			usage := types.Usage{TotalTokens: &tokens}
+			for _, msg := range opts.Prompt.Messages {
+				if msg.Role == types.RoleTool {
+					return &types.GenerateResult{Text: answer, FinishReason: types.FinishReasonStop, Usage: usage}, nil
+				}
+			}
+			return &types.GenerateResult{
+				FinishReason: types.FinishReasonToolCalls,
+				ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: tool, Arguments: map[string]interface{}{"id": "42"}}},
+				Usage:        usage,
+			}, nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	recorders := 0
+	suite := Suite{
+		Name: "orders",
+		Tasks: []Task{{
+			Name:   "refund",
+			Prompt: "Refund order 42",
+			NewTools: func() []types.Tool {
+				recorders++
+				return []types.Tool{MockTool("refund_order", "Refund an order", nil, func(input map[string]interface{}) (interface{}, error) {
+					fake.Advance(2 * time.Second)
+					return "refunded " + input["id"].(string), nil
+				})}
+			},
+			Check: ExpectAll(ExpectToolCalled("refund_order"), ExpectContains("REFUNDED"), ExpectMaxSteps(2)),
+		}},
+	}
+
+	var seen int
+	report, err := Run(context.Background(), suite, Options{
+		Models: []Model{
+			{Name: "good", Model: toolThenAnswer("refund_order", "Order 42 was refunded.")},
+			{Name: "wrong", Model: toolThenAnswer("refund_order", "I could not help.")},
+		},
+		Runs:     3,
+		Clock:    fake,
+		OnResult: func(TaskResult) { seen++ },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if seen != 6 || recorders != 6 {
+		t.Errorf("expected 6 runs with fresh tools, got %d results and %d tool sets", seen, recorders)
+	}
+	if len(report.Models) != 2 {
+		t.Fatalf("expected 2 model reports, got %d", len(report.Models))
+	}
+
+	good, wrong := report.Models[0], report.Models[1]
+	if good.SuccessRate != 1 || good.Runs != 3 {
+		t.Errorf("unexpected good report: %+v", good)
+	}
+	if good.MeanSteps != 2 || good.MeanToolCalls != 1 || good.TotalTokens != 60 {
+		t.Errorf("unexpected good stats: %+v", good)
+	}
+	if good.MeanDuration != 2*time.Second || good.P95Duration != 2*time.Second {
+		t.Errorf("expected 2s per run on the fake clock, got %v", good.MeanDuration)
+	}
+	if wrong.SuccessRate != 0 || wrong.Tasks["refund"] != 0 {
+		t.Errorf("unexpected wrong report: %+v", wrong)
+	}
+
+	failures := report.Failures()
+	if len(failures) != 3 || !strings.Contains(failures[0].Err.Error(), "does not contain") {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable: %v", err)
+	}
+	if !strings.Contains(buf.String(), "good") || !strings.Contains(buf.String(), "100.0%") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Run(context.Background(), Suite{Tasks: []Task{{Name: "x"}}}, Options{}); err == nil {
+		t.Error("expected error without models")
+	}
+	model := Model{Name: "m", Model: &testutil.MockLanguageModel{}}
+	if _, err := Run(context.Background(), Suite{Name: "empty"}, Options{Models: []Model{model}}); err == nil {
+		t.Error("expected error for empty suite")
+	}
+}
+
+func TestToolRecorder(t *testing.T) {
+	t.Parallel()
+
+	var rec ToolRecorder
+	tool := rec.Wrap(StaticTool("lookup", "Look up", "ok"))
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"q": "a"}, types.ToolExecutionOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := rec.Calls()
+	if len(calls) != 1 || calls[0].Tool != "lookup" || calls[0].Input["q"] != "a" {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+}
@@ -0,0 +1,119 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// CheckFunc reports why an agent result does not solve a task, or nil
+type CheckFunc func(result *agent.AgentResult) error
+
+// ExpectContains checks that the final text contains substr, ignoring case
+func ExpectContains(substr string) CheckFunc {
+	return func(result *agent.AgentResult) error {
+		if !strings.Contains(strings.ToLower(result.Text), strings.ToLower(substr)) {
+			return fmt.Errorf("final text does not contain %q", substr)
+		}
+		return nil
+	}
+}
+
+// ExpectToolCalled checks that the agent called the named tool at least once
+func ExpectToolCalled(name string) CheckFunc {
+	return func(result *agent.AgentResult) error {
+		for _, step := range result.Steps {
+			for _, call := range step.ToolCalls {
+				if call.ToolName == name {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("tool %q was not called", name)
+	}
+}
+
+// ExpectMaxSteps checks that the agent finished within n steps
+func ExpectMaxSteps(n int) CheckFunc {
+	return func(result *agent.AgentResult) error {
+		if len(result.Steps) > n {
+			return fmt.Errorf("took %d steps, expected at most %d", len(result.Steps), n)
+		}
+		return nil
+	}
+}
+
+// ExpectAll combines checks; every check must pass
+func ExpectAll(checks ...CheckFunc) CheckFunc {
+	return func(result *agent.AgentResult) error {
+		var errs []error
+		for _, check := range checks {
+			if err := check(result); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// MockTool returns a tool that runs fn instead of a real integration
+func MockTool(name, description string, parameters interface{}, fn func(input map[string]interface{}) (interface{}, error)) types.Tool {
+	if parameters == nil {
+		parameters = map[string]interface{}{"type": "object"}
+	}
+	return types.Tool{
+		Name:        name,
+		Description: description,
+		Parameters:  parameters,
+		Execute: func(ctx context.Context, input map[string]interface{}, options types.ToolExecutionOptions) (interface{}, error) {
+			return fn(input)
+		},
+	}
+}
+
+// StaticTool returns a tool that always returns result
+func StaticTool(name, description string, result interface{}) types.Tool {
+	return MockTool(name, description, nil, func(map[string]interface{}) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// ToolRecorder records the calls made to mock tools so checks can assert on
+// arguments. Create one per run with Task.NewTools when runs are concurrent.
+type ToolRecorder struct {
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+// RecordedCall is a tool invocation captured by a ToolRecorder
+type RecordedCall struct {
+	Tool  string
+	Input map[string]interface{}
+}
+
+// Wrap returns tool with its calls recorded
+func (r *ToolRecorder) Wrap(tool types.Tool) types.Tool {
+	execute := tool.Execute
+	tool.Execute = func(ctx context.Context, input map[string]interface{}, options types.ToolExecutionOptions) (interface{}, error) {
+		r.mu.Lock()
+		r.calls = append(r.calls, RecordedCall{Tool: tool.Name, Input: input})
+		r.mu.Unlock()
+		if execute == nil {
+			return nil, nil
+		}
+		return execute(ctx, input, options)
+	}
+	return tool
+}
+
+// Calls returns the recorded calls in order
+func (r *ToolRecorder) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedCall(nil), r.calls...)
+}
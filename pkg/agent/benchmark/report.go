@@ -0,0 +1,133 @@
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Report aggregates the results of a benchmark run
+type Report struct {
+	// Suite is the name of the suite that was run
+	Suite string
+
+	// Models holds one summary per model, in the order they were given
+	Models []ModelReport
+
+	// Results holds every run
+	Results []TaskResult
+}
+
+// ModelReport summarises the runs of one model
+type ModelReport struct {
+	Model string
+
+	// Runs and Successes count the runs across all tasks
+	Runs      int
+	Successes int
+
+	// SuccessRate is Successes / Runs
+	SuccessRate float64
+
+	// MeanSteps and MeanToolCalls are averaged over all runs
+	MeanSteps     float64
+	MeanToolCalls float64
+
+	// InputTokens, OutputTokens and TotalTokens are summed over all runs
+	InputTokens  int64
+	OutputTokens int64
+	TotalTokens  int64
+
+	// MeanDuration, P50Duration and P95Duration describe wall time per run
+	MeanDuration time.Duration
+	P50Duration  time.Duration
+	P95Duration  time.Duration
+
+	// Tasks holds the per-task success rate
+	Tasks map[string]float64
+}
+
+// newReport aggregates results per model
+func newReport(suite string, models []Model, results []TaskResult) *Report {
+	report := &Report{Suite: suite, Results: results}
+	for _, m := range models {
+		mr := ModelReport{Model: m.Name, Tasks: map[string]float64{}}
+		taskRuns := map[string]int{}
+		taskSuccesses := map[string]int{}
+		var durations []time.Duration
+		var steps, toolCalls int
+		var totalDuration time.Duration
+
+		for _, r := range results {
+			if r.Model != m.Name {
+				continue
+			}
+			mr.Runs++
+			taskRuns[r.Task]++
+			if r.Success {
+				mr.Successes++
+				taskSuccesses[r.Task]++
+			}
+			steps += r.Steps
+			toolCalls += r.ToolCalls
+			mr.InputTokens += r.Usage.GetInputTokens()
+			mr.OutputTokens += r.Usage.GetOutputTokens()
+			mr.TotalTokens += r.Usage.GetTotalTokens()
+			durations = append(durations, r.Duration)
+			totalDuration += r.Duration
+		}
+
+		if mr.Runs > 0 {
+			mr.SuccessRate = float64(mr.Successes) / float64(mr.Runs)
+			mr.MeanSteps = float64(steps) / float64(mr.Runs)
+			mr.MeanToolCalls = float64(toolCalls) / float64(mr.Runs)
+			mr.MeanDuration = totalDuration / time.Duration(mr.Runs)
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			mr.P50Duration = percentile(durations, 50)
+			mr.P95Duration = percentile(durations, 95)
+		}
+		for task, runs := range taskRuns {
+			mr.Tasks[task] = float64(taskSuccesses[task]) / float64(runs)
+		}
+		report.Models = append(report.Models, mr)
+	}
+	return report
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// Failures returns the runs that did not succeed
+func (r *Report) Failures() []TaskResult {
+	var failures []TaskResult
+	for _, res := range r.Results {
+		if !res.Success {
+			failures = append(failures, res)
+		}
+	}
+	return failures
+}
+
+// WriteTable writes a per-model summary table to w
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Suite: %s\n", r.Suite)
+	fmt.Fprintln(tw, "MODEL\tSUCCESS\tRUNS\tSTEPS\tTOOL CALLS\tTOKENS\tMEAN\tP50\tP95")
+	for _, m := range r.Models {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%d\t%.1f\t%.1f\t%d\t%v\t%v\t%v\n",
+			m.Model, m.SuccessRate*100, m.Runs, m.MeanSteps, m.MeanToolCalls, m.TotalTokens,
+			m.MeanDuration.Round(time.Millisecond), m.P50Duration.Round(time.Millisecond), m.P95Duration.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
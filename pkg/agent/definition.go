@@ -0,0 +1,354 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/reload"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDefinitionDepth bounds subagent nesting, catching files that include
+// each other
+const maxDefinitionDepth = 8
+
+// Definition is a declarative agent configuration, written in YAML or JSON.
+//
+// Example:
+//
+//	id: support
+//	version: "3"
+//	model: openai:gpt-4o
+//	system: You are a helpful support agent.
+//	tools: [lookup_order, refund]
+//	max_steps: 8
+//	temperature: 0.2
+//	timeout:
+//	  total: 2m
+//	  per_step: 30s
+//	subagents:
+//	  billing:
+//	    file: billing.yaml
+//	  triage:
+//	    model: openai:gpt-4o-mini
+//	    system: Classify the customer's request.
+type Definition struct {
+	// ID and Version identify the agent in results and telemetry
+	ID      string `yaml:"id" json:"id,omitempty"`
+	Version string `yaml:"version" json:"version,omitempty"`
+
+	// Model is a "provider:model" reference or a model alias, resolved
+	// through the loader's model registry
+	Model string `yaml:"model" json:"model,omitempty"`
+
+	// System is the system prompt
+	System string `yaml:"system" json:"system,omitempty"`
+
+	// Tools are names of tools in the loader's tool registry
+	Tools []string `yaml:"tools" json:"tools,omitempty"`
+
+	// MaxSteps limits the tool loop (default: 1)
+	MaxSteps int `yaml:"max_steps" json:"max_steps,omitempty"`
+
+	// Temperature and MaxTokens are passed to every generation
+	Temperature *float64 `yaml:"temperature" json:"temperature,omitempty"`
+	MaxTokens   *int     `yaml:"max_tokens" json:"max_tokens,omitempty"`
+
+	// Timeout sets time limits, as Go durations such as "30s"
+	Timeout *TimeoutDefinition `yaml:"timeout" json:"timeout,omitempty"`
+
+	// OutputSchema is the JSON schema of the agent's answers that
+	// downstream code relies on. Loader.Watch rejects reloads that change it
+	// incompatibly unless Version changes too (see schema.Diff).
+	OutputSchema map[string]interface{} `yaml:"output_schema" json:"output_schema,omitempty"`
+
+	// Subagents are agents this one can delegate to, keyed by name
+	Subagents map[string]*Definition `yaml:"subagents" json:"subagents,omitempty"`
+
+	// File loads a subagent's definition from another file, relative to
+	// the file that references it. Other fields are then ignored.
+	File string `yaml:"file" json:"file,omitempty"`
+}
+
+// TimeoutDefinition holds the durations of an ai.TimeoutConfig
+type TimeoutDefinition struct {
+	Total   string `yaml:"total" json:"total,omitempty"`
+	PerStep string `yaml:"per_step" json:"per_step,omitempty"`
+	Tool    string `yaml:"tool" json:"tool,omitempty"`
+}
+
+// ParseDefinition parses a YAML or JSON agent definition. Unknown keys are
+// rejected, so typos are reported instead of silently ignored.
+func ParseDefinition(data []byte) (*Definition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var def Definition
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("invalid agent definition: %w", err)
+	}
+	return &def, nil
+}
+
+// ToolRegistry holds the tools agent definitions can reference by name
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]types.Tool
+}
+
+// NewToolRegistry creates a tool registry holding tools
+func NewToolRegistry(tools ...types.Tool) *ToolRegistry {
+	r := &ToolRegistry{tools: make(map[string]types.Tool)}
+	for _, tool := range tools {
+		r.tools[tool.Name] = tool
+	}
+	return r
+}
+
+// Register adds a tool to the registry
+// Returns an error if a tool with the same name already exists
+func (r *ToolRegistry) Register(tool types.Tool) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name]; exists {
+		return fmt.Errorf("tool '%s' already registered", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	return nil
+}
+
+// Get retrieves a tool by name
+func (r *ToolRegistry) Get(name string) (types.Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// List returns all registered tool names, sorted
+func (r *ToolRegistry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Loader builds ToolLoopAgents from definitions
+type Loader struct {
+	// Models resolves model references (default: the global registry)
+	Models *registry.Registry
+
+	// Tools holds the tools definitions may reference
+	Tools *ToolRegistry
+
+	// Configure, if set, is called with each agent's config before the
+	// agent is built, to attach callbacks and other settings that cannot
+	// be declared in a file
+	Configure func(def *Definition, config *AgentConfig)
+}
+
+// LoadFile reads a definition file and builds its agent
+func (l *Loader) LoadFile(path string) (*ToolLoopAgent, error) {
+	def, err := l.readFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return l.build(def, filepath.Dir(path), 0, nil)
+}
+
+// Watch loads a definition file and reloads the agent whenever the file, or
+// a subagent file it references, changes. An edit that fails to parse or
+// build is reported to opts.OnError and the previous agent stays in service.
+// So is an edit that makes a breaking change to output_schema without
+// changing version, reported as a *schema.IncompatibleError.
+//
+// Example:
+//
+//	support, err := loader.Watch("agents/support.yaml", reload.Options{})
+//	...
+//	result, err := support.Get().Execute(ctx, prompt)
+func (l *Loader) Watch(path string, opts reload.Options) (*reload.Value[*ToolLoopAgent], error) {
+	var deployed *Definition
+	return reload.Watch(func() (*ToolLoopAgent, []string, error) {
+		var files []string
+		def, err := l.readFile(path, &files)
+		if err != nil {
+			return nil, nil, err
+		}
+		if deployed != nil && def.Version == deployed.Version {
+			if err := schema.CheckCompatible(deployed.OutputSchema, def.OutputSchema); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w (change version to deploy it)", path, err)
+			}
+		}
+		agent, err := l.build(def, filepath.Dir(path), 0, &files)
+		if err != nil {
+			return nil, nil, err
+		}
+		deployed = def
+		return agent, files, nil
+	}, opts)
+}
+
+// Load parses a YAML or JSON definition and builds its agent. Subagent file
+// references are resolved relative to the working directory.
+func (l *Loader) Load(data []byte) (*ToolLoopAgent, error) {
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return l.build(def, ".", 0, nil)
+}
+
+// Build builds the agent described by def
+func (l *Loader) Build(def *Definition) (*ToolLoopAgent, error) {
+	return l.build(def, ".", 0, nil)
+}
+
+// readFile parses a definition file, adding its path to files when files is
+// not nil
+func (l *Loader) readFile(path string, files *[]string) (*Definition, error) {
+	if files != nil {
+		*files = append(*files, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent definition: %w", err)
+	}
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+func (l *Loader) build(def *Definition, dir string, depth int, files *[]string) (*ToolLoopAgent, error) {
+	if def == nil {
+		return nil, fmt.Errorf("agent definition is empty")
+	}
+	if depth > maxDefinitionDepth {
+		return nil, fmt.Errorf("subagents nested deeper than %d levels", maxDefinitionDepth)
+	}
+	if def.Model == "" {
+		return nil, fmt.Errorf("agent %q: model is required", def.ID)
+	}
+
+	models := l.Models
+	if models == nil {
+		models = registry.GetGlobalRegistry()
+	}
+	model, err := models.ResolveLanguageModel(def.Model)
+	if err != nil {
+		return nil, fmt.Errorf("agent %q: %w", def.ID, err)
+	}
+
+	config := AgentConfig{
+		ID:          def.ID,
+		Version:     def.Version,
+		Model:       model,
+		System:      def.System,
+		MaxSteps:    def.MaxSteps,
+		Temperature: def.Temperature,
+		MaxTokens:   def.MaxTokens,
+	}
+
+	for _, name := range def.Tools {
+		var tool types.Tool
+		ok := false
+		if l.Tools != nil {
+			tool, ok = l.Tools.Get(name)
+		}
+		if !ok {
+			return nil, fmt.Errorf("agent %q: unknown tool %q", def.ID, name)
+		}
+		config.Tools = append(config.Tools, tool)
+	}
+
+	if def.Timeout != nil {
+		config.Timeout, err = def.Timeout.config()
+		if err != nil {
+			return nil, fmt.Errorf("agent %q: %w", def.ID, err)
+		}
+	}
+
+	if len(def.Subagents) > 0 {
+		config.Subagents = NewSubagentRegistry()
+		names := make([]string, 0, len(def.Subagents))
+		for name := range def.Subagents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, subDir := def.Subagents[name], dir
+			if sub != nil && sub.File != "" {
+				path := sub.File
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if sub, err = l.readFile(path, files); err != nil {
+					return nil, fmt.Errorf("subagent %q: %w", name, err)
+				}
+				subDir = filepath.Dir(path)
+			}
+			if sub != nil && sub.ID == "" {
+				withID := *sub
+				withID.ID = name
+				sub = &withID
+			}
+			subagent, err := l.build(sub, subDir, depth+1, files)
+			if err != nil {
+				return nil, fmt.Errorf("subagent %q: %w", name, err)
+			}
+			if err := config.Subagents.Register(name, subagent); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if l.Configure != nil {
+		l.Configure(def, &config)
+	}
+	return NewToolLoopAgent(config), nil
+}
+
+// config converts the definition to an ai.TimeoutConfig
+func (t *TimeoutDefinition) config() (*ai.TimeoutConfig, error) {
+	parse := func(field, value string) (*time.Duration, error) {
+		if value == "" {
+			return nil, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout.%s %q", field, value)
+		}
+		return &d, nil
+	}
+
+	var cfg ai.TimeoutConfig
+	var err error
+	if cfg.Total, err = parse("total", t.Total); err != nil {
+		return nil, err
+	}
+	if cfg.PerStep, err = parse("per_step", t.PerStep); err != nil {
+		return nil, err
+	}
+	if cfg.ToolMs, err = parse("tool", t.Tool); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
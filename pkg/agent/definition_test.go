@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/reload"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func definitionLoader() *Loader {
+	models := registry.NewRegistry()
+	models.RegisterProvider("mock", &testutil.MockProvider{ProviderName: "mock"})
+	models.RegisterAlias("fast", "mock:mini")
+
+	noop := func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		return "ok", nil
+	}
+	return &Loader{
+		Models: models,
+		Tools: NewToolRegistry(
+			types.Tool{Name: "lookup_order", Execute: noop},
+			types.Tool{Name: "refund", Execute: noop},
+		),
+	}
+}
+
+func TestLoader_LoadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	main := `
+id: support
+version: "3"
+model: mock:large
+system: You are a helpful support agent.
+tools: [lookup_order, refund]
+max_steps: 8
+temperature: 0.2
+timeout:
+  total: 2m
+  per_step: 30s
+subagents:
+  billing:
+    file: agents/billing.json
+  triage:
+    model: fast
+    system: Classify the request.
+`
+	billing := `{"model": "mock:billing", "tools": ["refund"], "max_tokens": 500}`
+	if err := os.MkdirAll(filepath.Join(dir, "agents"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "support.yaml"), []byte(main), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "agents", "billing.json"), []byte(billing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configured := 0
+	loader := definitionLoader()
+	loader.Configure = func(def *Definition, config *AgentConfig) { configured++ }
+
+	a, err := loader.LoadFile(filepath.Join(dir, "support.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	cfg := a.config
+	if cfg.ID != "support" || cfg.Version != "3" || cfg.System != "You are a helpful support agent." {
+		t.Errorf("unexpected identity: %q %q %q", cfg.ID, cfg.Version, cfg.System)
+	}
+	if cfg.Model.ModelID() != "large" || len(cfg.Tools) != 2 || cfg.Tools[1].Name != "refund" {
+		t.Errorf("unexpected model %q or tools %v", cfg.Model.ModelID(), cfg.Tools)
+	}
+	if cfg.Temperature == nil || *cfg.Temperature != 0.2 {
+		t.Errorf("unexpected temperature %v", cfg.Temperature)
+	}
+	if cfg.Timeout == nil || *cfg.Timeout.Total != 2*time.Minute || *cfg.Timeout.PerStep != 30*time.Second {
+		t.Errorf("unexpected timeout %+v", cfg.Timeout)
+	}
+	if configured != 3 {
+		t.Errorf("Configure called %d times, want 3", configured)
+	}
+
+	sub, ok := a.GetSubagent("billing")
+	if !ok {
+		t.Fatalf("expected billing subagent, got %v", a.ListSubagents())
+	}
+	billingCfg := sub.(*ToolLoopAgent).config
+	if billingCfg.ID != "billing" || billingCfg.Model.ModelID() != "billing" || *billingCfg.MaxTokens != 500 {
+		t.Errorf("unexpected billing config %+v", billingCfg)
+	}
+	triage, _ := a.GetSubagent("triage")
+	if triage.(*ToolLoopAgent).config.Model.ModelID() != "mini" {
+		t.Errorf("expected the alias to resolve to mock:mini")
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		def  string
+		want string
+	}{
+		{"unknown key", "model: mock:m\nmax_step: 3\n", "max_step"},
+		{"missing model", "system: hi\n", "model is required"},
+		{"unknown provider", "model: other:m\n", "provider not found"},
+		{"unknown tool", "model: mock:m\ntools: [delete_everything]\n", `unknown tool "delete_everything"`},
+		{"bad timeout", "model: mock:m\ntimeout: {total: soon}\n", "timeout.total"},
+		{"bad subagent", "model: mock:m\nsubagents: {helper: {system: hi}}\n", `subagent "helper"`},
+	}
+	for _, tt := range tests {
+		_, err := definitionLoader().Load([]byte(tt.def))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestLoader_Watch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	subPath := filepath.Join(dir, "helper.yaml")
+	if err := os.WriteFile(path, []byte("model: mock:m\nsystem: v1\nsubagents: {helper: {file: helper.yaml}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(subPath, []byte("model: mock:m\nsystem: helper v1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	watched, err := definitionLoader().Watch(path, reload.Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer watched.Close() //nolint:errcheck
+
+	first := watched.Get()
+	if err := os.WriteFile(subPath, []byte("model: mock:m\nsystem: helper v2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if swapped, err := watched.Check(); !swapped || err != nil {
+		t.Fatalf("expected a subagent edit to reload: swapped=%v err=%v", swapped, err)
+	}
+	helper, _ := watched.Get().GetSubagent("helper")
+	if helper.(*ToolLoopAgent).config.System != "helper v2" {
+		t.Errorf("expected the reloaded subagent")
+	}
+
+	// An invalid edit keeps the current agent
+	current := watched.Get()
+	if err := os.WriteFile(path, []byte("model: mock:m\ntools: [missing]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watched.Check(); err == nil {
+		t.Error("expected the build error")
+	}
+	if watched.Get() != current || current == first {
+		t.Errorf("expected the last valid agent to stay in service")
+	}
+}
+
+func TestLoader_WatchBlocksBreakingSchemaChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	write := func(version, schema string) {
+		t.Helper()
+		def := "model: mock:m\nversion: \"" + version + "\"\noutput_schema:\n" + schema
+		if err := os.WriteFile(path, []byte(def), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1", "  type: object\n  properties: {label: {type: string}, score: {type: number}}\n  required: [label, score]\n")
+
+	watched, err := definitionLoader().Watch(path, reload.Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer watched.Close() //nolint:errcheck
+
+	// Adding a field is compatible
+	write("1", "  type: object\n  properties: {label: {type: string}, score: {type: number}, note: {type: string}}\n  required: [label, score]\n")
+	if swapped, err := watched.Check(); !swapped || err != nil {
+		t.Fatalf("expected a compatible change to deploy: swapped=%v err=%v", swapped, err)
+	}
+
+	// Dropping a required field under the same version is blocked
+	write("1", "  type: object\n  properties: {label: {type: string}}\n  required: [label]\n")
+	var incompatible *schema.IncompatibleError
+	if _, err := watched.Check(); !errors.As(err, &incompatible) || incompatible.Changes[0].Path != "$.score" {
+		t.Fatalf("expected the removed field to be reported, got %v", err)
+	}
+
+	// A new version may break the schema
+	write("2", "  type: object\n  properties: {label: {type: string}}\n  required: [label]\n")
+	if swapped, err := watched.Check(); !swapped || err != nil {
+		t.Fatalf("expected a version bump to deploy: swapped=%v err=%v", swapped, err)
+	}
+}
@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// NotesToolName is the name of the built-in notes tool
+const NotesToolName = "notes"
+
+// notesResource is the session resource holding a run's notes
+const notesResource = "agent.notes"
+
+// NotesOptions enables the built-in notes tool, a scratch memory the model
+// uses to save findings in one step and read them back in a later one
+// instead of carrying them in the conversation. Notes belong to one run.
+//
+// Example:
+//
+//	config.Notes = &agent.NotesOptions{Store: durable.NewMemoryResultStore()}
+type NotesOptions struct {
+	// Store persists each run's notes under "notes:<run ID>", so a run
+	// resumed with WithRunID sees the notes saved before it stopped.
+	// Default: notes are kept in memory until the run ends.
+	Store NotesStore
+
+	// MaxNotes is the number of notes a run may keep (default: 50)
+	MaxNotes int
+
+	// MaxChars is the longest note in characters (default: 4000)
+	MaxChars int
+}
+
+// NotesStore persists notes by key. durable.ResultStore implementations,
+// such as durable.MemoryResultStore, satisfy it.
+type NotesStore interface {
+	// Get returns the data stored under key, reporting whether it exists
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Put stores data under key
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Note is a saved note
+type Note struct {
+	Key     string `json:"key"`
+	Content string `json:"content"`
+}
+
+// notes is one run's scratch memory
+type notes struct {
+	opts  NotesOptions
+	runID string
+
+	mu    sync.Mutex
+	notes map[string]string
+}
+
+// sessionResource returns the resource that loads a run's notes on first use
+func (o *NotesOptions) sessionResource() SessionResource {
+	return SessionResource{
+		Name: notesResource,
+		Open: func(ctx context.Context) (interface{}, error) {
+			runID, _ := ctx.Value(runIDKey).(string)
+			n := &notes{opts: *o, runID: runID, notes: make(map[string]string)}
+			if o.Store == nil {
+				return n, nil
+			}
+			data, ok, err := o.Store.Get(ctx, n.storeKey())
+			if err != nil || !ok {
+				return n, err
+			}
+			if err := json.Unmarshal(data, &n.notes); err != nil {
+				return nil, fmt.Errorf("notes for run %s: %w", runID, err)
+			}
+			return n, nil
+		},
+	}
+}
+
+func (n *notes) storeKey() string {
+	return "notes:" + n.runID
+}
+
+// save persists the notes; the caller holds n.mu
+func (n *notes) save(ctx context.Context) error {
+	if n.opts.Store == nil {
+		return nil
+	}
+	data, err := json.Marshal(n.notes)
+	if err != nil {
+		return err
+	}
+	return n.opts.Store.Put(ctx, n.storeKey(), data)
+}
+
+func (n *notes) write(ctx context.Context, key, content string) (interface{}, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	if len([]rune(content)) > n.opts.MaxChars {
+		return nil, fmt.Errorf("note is longer than %d characters; split it or summarize it", n.opts.MaxChars)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	previous, exists := n.notes[key]
+	if !exists && len(n.notes) >= n.opts.MaxNotes {
+		return nil, fmt.Errorf("%d notes already saved; delete or overwrite one", n.opts.MaxNotes)
+	}
+	n.notes[key] = content
+	if err := n.save(ctx); err != nil {
+		if exists {
+			n.notes[key] = previous
+		} else {
+			delete(n.notes, key)
+		}
+		return nil, err
+	}
+	return map[string]interface{}{"saved": key, "notes": len(n.notes)}, nil
+}
+
+func (n *notes) read(key string) (interface{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	content, ok := n.notes[key]
+	if !ok {
+		return nil, fmt.Errorf("no note %q", key)
+	}
+	return Note{Key: key, Content: content}, nil
+}
+
+// list returns every note's key with the start of its content
+func (n *notes) list() interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	type entry struct {
+		Key     string `json:"key"`
+		Preview string `json:"preview"`
+		Chars   int    `json:"chars"`
+	}
+	entries := make([]entry, 0, len(n.notes))
+	for key, content := range n.notes {
+		runes := []rune(content)
+		preview := content
+		if len(runes) > 80 {
+			preview = string(runes[:80]) + "…"
+		}
+		entries = append(entries, entry{Key: key, Preview: preview, Chars: len(runes)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return map[string]interface{}{"notes": entries}
+}
+
+func (n *notes) delete(ctx context.Context, key string) (interface{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	content, ok := n.notes[key]
+	if !ok {
+		return nil, fmt.Errorf("no note %q", key)
+	}
+	delete(n.notes, key)
+	if err := n.save(ctx); err != nil {
+		n.notes[key] = content
+		return nil, err
+	}
+	return map[string]interface{}{"deleted": key}, nil
+}
+
+// notesTool is the tool the model reads and writes notes with
+func notesTool() types.Tool {
+	return types.Tool{
+		Name: NotesToolName,
+		Description: "Scratch memory for this task. Save intermediate findings with write, " +
+			"then list and read them in later steps instead of repeating them. " +
+			"Writing an existing key replaces the note.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"action": map[string]interface{}{
+					"type": "string",
+					"enum": []string{"write", "read", "list", "delete"},
+				},
+				"key": map[string]interface{}{
+					"type":        "string",
+					"description": "Short name of the note, e.g. \"pricing-sources\" (not needed for list)",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "Note text (write only)",
+				},
+			},
+			"required": []string{"action"},
+		},
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			n, err := SessionValue[*notes](ctx, notesResource)
+			if err != nil {
+				return nil, err
+			}
+			action, _ := input["action"].(string)
+			key, _ := input["key"].(string)
+			switch action {
+			case "write":
+				content, _ := input["content"].(string)
+				return n.write(ctx, key, content)
+			case "read":
+				return n.read(key)
+			case "list":
+				return n.list(), nil
+			case "delete":
+				return n.delete(ctx, key)
+			default:
+				return nil, fmt.Errorf("unknown action %q; use write, read, list or delete", action)
+			}
+		},
+	}
+}
+
+// withNotes adds the notes tool and its session resource to config
+func (o *NotesOptions) withNotes(config *AgentConfig) {
+	opts := *o
+	if opts.MaxNotes <= 0 {
+		opts.MaxNotes = 50
+	}
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = 4000
+	}
+	config.Tools = append(config.Tools[:len(config.Tools):len(config.Tools)], notesTool())
+	config.SessionResources = append(config.SessionResources[:len(config.SessionResources):len(config.SessionResources)], opts.sessionResource())
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// mapNotesStore is a NotesStore backed by a map
+type mapNotesStore map[string][]byte
+
+func (s mapNotesStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, ok := s[key]
+	return data, ok, nil
+}
+
+func (s mapNotesStore) Put(ctx context.Context, key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func notesCall(id string, args map[string]interface{}) types.GenerateResult {
+	return types.GenerateResult{
+		FinishReason: types.FinishReasonToolCalls,
+		ToolCalls:    []types.ToolCall{{ID: id, ToolName: NotesToolName, Arguments: args}},
+	}
+}
+
+func TestNotes_SavedAcrossStepsAndResumedRuns(t *testing.T) {
+	store := mapNotesStore{}
+	ctx := WithRunID(context.Background(), "run-1")
+
+	a := NewToolLoopAgent(AgentConfig{
+		Model: &mockLanguageModel{responses: []types.GenerateResult{
+			notesCall("c1", map[string]interface{}{"action": "write", "key": "sources", "content": "pricing page"}),
+			notesCall("c2", map[string]interface{}{"action": "write", "key": "draft", "content": strings.Repeat("x", 41)}),
+			notesCall("c3", map[string]interface{}{"action": "list"}),
+			{Text: "done", FinishReason: types.FinishReasonStop},
+		}},
+		Notes:    &NotesOptions{Store: store, MaxChars: 40},
+		MaxSteps: 10,
+	})
+	result, err := a.Execute(ctx, "research pricing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ToolResults) != 3 || result.ToolResults[0].Error != nil {
+		t.Fatalf("unexpected tool results: %+v", result.ToolResults)
+	}
+	if err := result.ToolResults[1].Error; err == nil || !strings.Contains(err.Error(), "longer than 40") {
+		t.Errorf("expected the long note to be rejected, got %v", err)
+	}
+	listed, _ := json.Marshal(result.ToolResults[2].Result)
+	if !strings.Contains(string(listed), `"key":"sources"`) || strings.Contains(string(listed), "draft") {
+		t.Errorf("list = %s", listed)
+	}
+
+	// A resumed run with the same ID reads the stored notes
+	a = NewToolLoopAgent(AgentConfig{
+		Model: &mockLanguageModel{responses: []types.GenerateResult{
+			notesCall("c1", map[string]interface{}{"action": "read", "key": "sources"}),
+			notesCall("c2", map[string]interface{}{"action": "delete", "key": "sources"}),
+			notesCall("c3", map[string]interface{}{"action": "read", "key": "sources"}),
+			{Text: "done", FinishReason: types.FinishReasonStop},
+		}},
+		Notes:    &NotesOptions{Store: store},
+		MaxSteps: 10,
+	})
+	result, err = a.Execute(ctx, "continue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note, _ := result.ToolResults[0].Result.(Note); note.Content != "pricing page" {
+		t.Errorf("read = %+v (%v)", result.ToolResults[0].Result, result.ToolResults[0].Error)
+	}
+	if result.ToolResults[2].Error == nil {
+		t.Error("expected an error reading a deleted note")
+	}
+	if data := string(store["notes:run-1"]); data != "{}" {
+		t.Errorf("stored notes = %s", data)
+	}
+}
@@ -2,10 +2,13 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"io"
+	"strings"
 
 	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 	"github.com/google/uuid"
@@ -25,6 +28,9 @@ type agentCallbacks struct {
 	onToolCallFinish func(ctx context.Context, e ai.OnToolCallFinishEvent)
 	onStepFinish     func(ctx context.Context, e ai.OnStepFinishEvent)
 	onFinish         func(ctx context.Context, e ai.OnFinishEvent)
+
+	// onChunk receives model stream chunks; when set, steps are streamed
+	onChunk func(ctx context.Context, chunk provider.StreamChunk)
 }
 
 // mergeCallbacks combines settings-level and per-call structured callbacks.
@@ -38,6 +44,7 @@ func mergeCallbacks(settings AgentConfig, callOpts agentCallbacks) agentCallback
 		onToolCallFinish: mergeListener(settings.OnToolCallFinish, callOpts.onToolCallFinish),
 		onStepFinish:     mergeListener(settings.OnStepFinishEvent, callOpts.onStepFinish),
 		onFinish:         mergeListener(settings.OnFinishEvent, callOpts.onFinish),
+		onChunk:          callOpts.onChunk,
 	}
 }
 
@@ -86,6 +93,10 @@ func NewToolLoopAgent(config AgentConfig) *ToolLoopAgent {
 		config.MaxSteps = 1000
 	}
 
+	if config.Notes != nil {
+		config.Notes.withNotes(&config)
+	}
+
 	// Initialize skills registry if not provided
 	if config.Skills == nil {
 		config.Skills = NewSkillRegistry()
@@ -96,6 +107,13 @@ func NewToolLoopAgent(config AgentConfig) *ToolLoopAgent {
 		config.Subagents = NewSubagentRegistry()
 	}
 
+	// Timeouts follow the agent's clock unless configured otherwise
+	if config.Clock != nil && config.Timeout != nil && config.Timeout.Clock == nil {
+		timeout := *config.Timeout
+		timeout.Clock = config.Clock
+		config.Timeout = &timeout
+	}
+
 	return &ToolLoopAgent{
 		config: config,
 	}
@@ -117,6 +135,20 @@ func (a *ToolLoopAgent) Execute(ctx context.Context, prompt string) (*AgentResul
 
 // ExecuteWithMessages runs the agent with a message history
 func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []types.Message) (*AgentResult, error) {
+	return a.execute(ctx, messages, agentCallbacks{})
+}
+
+// StreamWithMessages runs the agent like ExecuteWithMessages, but streams
+// each model step and passes its chunks to onChunk as they arrive, so the
+// reply can be used before it is complete, e.g. spoken by a VoiceSession.
+// Chunks of every step are passed on, including text written before a
+// tool call.
+func (a *ToolLoopAgent) StreamWithMessages(ctx context.Context, messages []types.Message, onChunk func(ctx context.Context, chunk provider.StreamChunk)) (*AgentResult, error) {
+	return a.execute(ctx, messages, agentCallbacks{onChunk: onChunk})
+}
+
+// execute runs the agent loop with per-call callbacks
+func (a *ToolLoopAgent) execute(ctx context.Context, messages []types.Message, callOpts agentCallbacks) (*AgentResult, error) {
 	// Validate configuration
 	if a.config.Model == nil {
 		return nil, fmt.Errorf("model is required")
@@ -129,10 +161,28 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		ctx = context.WithValue(ctx, runIDKey, runID)
 	}
 
-	// CB-T23: Merge settings-level callbacks with no per-call overrides.
-	// Per-call callback merging is used when ToolLoopAgent is called via
-	// dedicated generate/stream wrappers that accept per-call callbacks.
-	cbs := mergeCallbacks(a.config, agentCallbacks{})
+	// Make the experimental context visible to tools, skills and subagents;
+	// subagents without their own inherit this one
+	ctx = ai.WithExperimentalContext(ctx, a.config.ExperimentalContext)
+
+	// Stateful tools share one session per run. Error paths close it here;
+	// successful runs close it below and report failures as warnings.
+	var session *ToolSession
+	if len(a.config.SessionResources) > 0 {
+		session = NewToolSession(a.config.SessionResources...)
+		ctx = WithToolSession(ctx, session)
+		defer session.Close(context.WithoutCancel(ctx)) //nolint:errcheck
+		if err := session.OpenEager(ctx); err != nil {
+			if a.config.OnChainError != nil {
+				a.config.OnChainError(err)
+			}
+			return nil, err
+		}
+	}
+
+	// CB-T23: Merge settings-level callbacks with the per-call ones passed
+	// by dedicated wrappers such as StreamWithMessages.
+	cbs := mergeCallbacks(a.config, callOpts)
 
 	// Extract input for OnChainStart callback
 	input := ""
@@ -159,7 +209,7 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		Tools:               a.config.Tools,
 		Temperature:         a.config.Temperature,
 		MaxTokens:           a.config.MaxTokens,
-		ExperimentalContext: a.config.ExperimentalContext,
+		ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 	}, cbs.onStart)
 
 	// Apply total timeout if configured
@@ -199,7 +249,7 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 			Messages:            currentMessages,
 			Tools:               a.config.Tools,
 			PreviousSteps:       result.Steps,
-			ExperimentalContext: a.config.ExperimentalContext,
+			ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 		}, cbs.onStepStart)
 
 		// Execute one step with custom data
@@ -267,11 +317,7 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 				toolMsg := types.Message{
 					Role: types.RoleTool,
 					Content: []types.ContentPart{
-						types.ToolResultContent{
-							ToolCallID: tr.ToolCallID,
-							ToolName:   tr.ToolName,
-							Result:     tr.Result,
-						},
+						ai.NewToolResultContent(ctx, a.config.Tools, tr),
 					},
 				}
 				currentMessages = append(currentMessages, toolMsg)
@@ -294,7 +340,7 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 			FinishReason:        stepResult.FinishReason,
 			Usage:               stepResult.Usage,
 			Warnings:            stepResult.Warnings,
-			ExperimentalContext: a.config.ExperimentalContext,
+			ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 		}, cbs.onStepFinish)
 
 		// Check if we should continue
@@ -327,13 +373,14 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		}
 
 		// Evaluate stop conditions
-		if len(a.config.StopWhen) > 0 {
+		if len(a.config.StopWhen) > 0 || a.config.DryRun != nil {
 			state := ai.StopConditionState{
 				Steps:    result.Steps,
 				Messages: currentMessages,
 				Usage:    result.Usage,
 			}
-			if reason := ai.EvaluateStopConditions(a.config.StopWhen, state); reason != "" {
+			stopWhen := append(a.config.StopWhen[:len(a.config.StopWhen):len(a.config.StopWhen)], a.config.DryRun.StopCondition())
+			if reason := ai.EvaluateStopConditions(stopWhen, state); reason != "" {
 				result.StopReason = reason
 				result.Text = stepResult.Text
 				result.FinishReason = stepResult.FinishReason
@@ -398,6 +445,15 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		}
 	}
 
+	if session != nil {
+		if err := session.Close(context.WithoutCancel(ctx)); err != nil {
+			result.Warnings = append(result.Warnings, types.Warning{
+				Type:    "other",
+				Details: err.Error(),
+			})
+		}
+	}
+
 	// Call OnChainEnd callback (successful completion)
 	if a.config.OnChainEnd != nil {
 		a.config.OnChainEnd(result)
@@ -408,6 +464,8 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		a.config.OnFinish(result)
 	}
 
+	result.DryRunCalls = a.config.DryRun.IntendedCalls(result.Steps, a.config.Tools)
+
 	// Aggregate all tool calls across steps for the finish event
 	var allToolCalls []types.ToolCall
 	for _, s := range result.Steps {
@@ -423,7 +481,7 @@ func (a *ToolLoopAgent) ExecuteWithMessages(ctx context.Context, messages []type
 		Steps:               result.Steps,
 		TotalUsage:          result.Usage,
 		Warnings:            result.Warnings,
-		ExperimentalContext: a.config.ExperimentalContext,
+		ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 	}, cbs.onFinish)
 
 	return result, nil
@@ -464,12 +522,19 @@ func (a *ToolLoopAgent) executeStep(ctx context.Context, stepNum int, messages [
 		},
 		Temperature: callConfig.Temperature,
 		MaxTokens:   callConfig.MaxTokens,
-		Tools:       callConfig.Tools,
+		Tools:       ai.PrepareToolsForModel(callConfig.Tools),
 		ToolChoice:  types.AutoToolChoice(),
 	}
 
 	// Call the model with step context
-	genResult, err := a.config.Model.DoGenerate(stepCtx, genOpts)
+	start := clock.Default(a.config.Clock).Now()
+	var genResult *types.GenerateResult
+	var err error
+	if cbs.onChunk != nil {
+		genResult, err = a.streamStep(stepCtx, genOpts, cbs.onChunk)
+	} else {
+		genResult, err = a.config.Model.DoGenerate(stepCtx, genOpts)
+	}
 	if err != nil {
 		return nil, false, callConfig.CustomData, err
 	}
@@ -504,6 +569,7 @@ func (a *ToolLoopAgent) executeStep(ctx context.Context, stepNum int, messages [
 		Usage:            genResult.Usage,
 		Warnings:         genResult.Warnings,
 		ResponseMessages: []types.Message{responseMsg},
+		DurationMs:       clock.Default(a.config.Clock).Now().Sub(start).Milliseconds(),
 	}
 
 	// Determine if we should continue
@@ -512,6 +578,48 @@ func (a *ToolLoopAgent) executeStep(ctx context.Context, stepNum int, messages [
 	return stepResult, shouldContinue, callConfig.CustomData, nil
 }
 
+// streamStep runs a step with DoStream, passing each chunk to onChunk, and
+// collects the chunks into a GenerateResult
+func (a *ToolLoopAgent) streamStep(ctx context.Context, genOpts *provider.GenerateOptions, onChunk func(ctx context.Context, chunk provider.StreamChunk)) (*types.GenerateResult, error) {
+	stream, err := a.config.Model.DoStream(ctx, genOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	result := &types.GenerateResult{}
+	var text strings.Builder
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		onChunk(ctx, *chunk)
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case provider.ChunkTypeToolCall:
+			if chunk.ToolCall != nil {
+				result.ToolCalls = append(result.ToolCalls, *chunk.ToolCall)
+			}
+		case provider.ChunkTypeStreamStart:
+			result.Warnings = append(result.Warnings, chunk.Warnings...)
+		case provider.ChunkTypeUsage, provider.ChunkTypeFinish:
+			if chunk.Usage != nil {
+				result.Usage = *chunk.Usage
+			}
+			if chunk.Type == provider.ChunkTypeFinish {
+				result.FinishReason = chunk.FinishReason
+			}
+		}
+	}
+	result.Text = text.String()
+	return result, nil
+}
+
 // executeTools executes a list of tool calls with optional approval
 // Updated in v6.0.57 to handle provider-executed (deferrable) tools
 // Updated in v6.1 (CB-T23) to fire structured OnToolCallStart/Finish events
@@ -554,7 +662,7 @@ func (a *ToolLoopAgent) executeTools(ctx context.Context, toolCalls []types.Tool
 		}
 
 		if tool == nil {
-			notFoundErr := fmt.Errorf("tool not found: %s", call.ToolName)
+			notFoundErr := &types.ToolError{Code: types.ToolErrorUnknownTool, Message: fmt.Sprintf("tool not found: %s", call.ToolName)}
 			results[i] = types.ToolResult{
 				ToolCallID:       call.ID,
 				ToolName:         call.ToolName,
@@ -611,15 +719,16 @@ func (a *ToolLoopAgent) executeTools(ctx context.Context, toolCalls []types.Tool
 				StepNumber:          stepNum,
 				ModelProvider:       a.config.Model.Provider(),
 				ModelID:             a.config.Model.ModelID(),
-				ExperimentalContext: a.config.ExperimentalContext,
+				ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 			}, cbs.onToolCallStart)
 
 			execOptions := types.ToolExecutionOptions{
-				ToolCallID: call.ID,
+				ToolCallID:  call.ID,
+				UserContext: ai.ExperimentalContextFrom(ctx),
 			}
-			startMs := time.Now().UnixMilli()
-			toolResult, toolErr := tool.Execute(ctx, call.Arguments, execOptions)
-			durationMs := time.Now().UnixMilli() - startMs
+			startMs := clock.Default(a.config.Clock).Now().UnixMilli()
+			toolResult, toolErr := a.config.DryRun.Executor(*tool)(ctx, call.Arguments, execOptions)
+			durationMs := clock.Default(a.config.Clock).Now().UnixMilli() - startMs
 
 			results[i] = types.ToolResult{
 				ToolCallID:       call.ID,
@@ -627,6 +736,7 @@ func (a *ToolLoopAgent) executeTools(ctx context.Context, toolCalls []types.Tool
 				Result:           toolResult,
 				Error:            toolErr,
 				ProviderExecuted: false,
+				DurationMs:       durationMs,
 			}
 
 			// CB-T23: Emit OnToolCallFinishEvent
@@ -640,7 +750,7 @@ func (a *ToolLoopAgent) executeTools(ctx context.Context, toolCalls []types.Tool
 				StepNumber:          stepNum,
 				ModelProvider:       a.config.Model.Provider(),
 				ModelID:             a.config.Model.ModelID(),
-				ExperimentalContext: a.config.ExperimentalContext,
+				ExperimentalContext: ai.ExperimentalContextFrom(ctx),
 			}, cbs.onToolCallFinish)
 
 			// Call tool result callback (legacy)
@@ -8,6 +8,7 @@ import (
 	"github.com/digitallysavvy/go-ai/pkg/ai"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
 )
 
 // Helper function to create int64 pointers
@@ -1609,3 +1610,117 @@ func TestToolLoopAgent_StopWhen_Default(t *testing.T) {
 		t.Errorf("expected 1 step (default), got %d", len(result.Steps))
 	}
 }
+
+func TestExperimentalContext_FlowsToToolsAndSubagents(t *testing.T) {
+	type tenant struct{ ID string }
+
+	toolCall := func(name string) []types.GenerateResult {
+		return []types.GenerateResult{{
+			FinishReason: types.FinishReasonToolCalls,
+			ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: name, Arguments: map[string]interface{}{}}},
+		}}
+	}
+
+	var subagentSaw tenant
+	sub := NewToolLoopAgent(AgentConfig{
+		Model:    &mockLanguageModel{responses: toolCall("inspect")},
+		MaxSteps: 2,
+		Tools: []types.Tool{{
+			Name: "inspect",
+			Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+				subagentSaw, _ = ai.UserContextValue[tenant](opts.UserContext)
+				return "ok", nil
+			},
+		}},
+	})
+
+	var toolSaw tenant
+	parent := NewToolLoopAgent(AgentConfig{
+		Model:               &mockLanguageModel{responses: toolCall("delegate")},
+		MaxSteps:            2,
+		ExperimentalContext: tenant{ID: "acme"},
+	})
+	if err := parent.AddSubagent("worker", sub); err != nil {
+		t.Fatalf("AddSubagent failed: %v", err)
+	}
+	parent.AddTool(types.Tool{
+		Name: "delegate",
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			toolSaw, _ = ai.ContextValue[tenant](ctx)
+			res, err := parent.DelegateToSubagent(ctx, "worker", "inspect")
+			if err != nil {
+				return nil, err
+			}
+			return res.Text, nil
+		},
+	})
+
+	if _, err := parent.Execute(context.Background(), "go"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if toolSaw.ID != "acme" {
+		t.Errorf("tool: expected tenant acme, got %+v", toolSaw)
+	}
+	if subagentSaw.ID != "acme" {
+		t.Errorf("subagent: expected inherited tenant acme, got %+v", subagentSaw)
+	}
+}
+
+// Test that StreamWithMessages streams every step and collects its result
+func TestStreamWithMessages(t *testing.T) {
+	streams := [][]provider.StreamChunk{
+		{
+			{Type: provider.ChunkTypeText, Text: "Checking. "},
+			{Type: provider.ChunkTypeToolCall, ToolCall: &types.ToolCall{ID: "call_1", ToolName: "test_tool", Arguments: map[string]interface{}{}}},
+			{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonToolCalls, Usage: &types.Usage{TotalTokens: intPtr(5)}},
+		},
+		{
+			{Type: provider.ChunkTypeText, Text: "It is "},
+			{Type: provider.ChunkTypeText, Text: "sunny."},
+			{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop, Usage: &types.Usage{TotalTokens: intPtr(7)}},
+		},
+	}
+	calls := 0
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			calls++
+			return testutil.NewMockTextStream(streams[calls-1]), nil
+		},
+	}
+	agent := NewToolLoopAgent(AgentConfig{
+		Model:    model,
+		MaxSteps: 2,
+		Tools: []types.Tool{{
+			Name:       "test_tool",
+			Parameters: map[string]interface{}{},
+			Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+				return "sunny", nil
+			},
+		}},
+	})
+
+	var text string
+	result, err := agent.StreamWithMessages(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "Weather?"}}},
+	}, func(ctx context.Context, chunk provider.StreamChunk) {
+		text += chunk.Text
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Checking. It is sunny." {
+		t.Errorf("streamed text = %q", text)
+	}
+	if len(model.GenerateCalls) != 0 {
+		t.Error("expected steps to be streamed, not generated")
+	}
+	if len(result.Steps) != 2 || len(result.ToolResults) != 1 {
+		t.Fatalf("expected 2 steps and 1 tool result, got %+v", result)
+	}
+	if result.Text != "It is sunny." || result.FinishReason != types.FinishReasonStop {
+		t.Errorf("unexpected result: %q, %s", result.Text, result.FinishReason)
+	}
+	if result.Usage.TotalTokens == nil || *result.Usage.TotalTokens != 12 {
+		t.Errorf("expected 12 total tokens, got %v", result.Usage.TotalTokens)
+	}
+}
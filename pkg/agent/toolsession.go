@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SessionResource declares state that tools share for the length of a run,
+// such as a database connection, a browser session or an MCP client
+type SessionResource struct {
+	// Name identifies the resource to SessionValue (required)
+	Name string
+
+	// Open creates the resource. It runs at most once per session, on first
+	// use, unless Eager is set. A failed Open is retried on the next use.
+	Open func(ctx context.Context) (interface{}, error)
+
+	// Close releases the resource when the session ends (optional). It is
+	// only called for resources that were opened.
+	Close func(ctx context.Context, value interface{}) error
+
+	// Eager opens the resource when the session starts, so a run fails
+	// before the first step if the resource is unavailable
+	Eager bool
+}
+
+// ToolSession holds the resources shared by tool calls in one agent run.
+// ToolLoopAgent creates one per run from AgentConfig.SessionResources and
+// closes it when the run ends; tools reach it through the context passed to
+// Execute. It is safe for concurrent use by parallel tool calls.
+//
+// Example:
+//
+//	config.SessionResources = []agent.SessionResource{{
+//		Name: "db",
+//		Open: func(ctx context.Context) (interface{}, error) { return sql.Open("pgx", dsn) },
+//		Close: func(ctx context.Context, v interface{}) error { return v.(*sql.DB).Close() },
+//	}}
+//
+//	// In a tool's Execute:
+//	db, err := agent.SessionValue[*sql.DB](ctx, "db")
+type ToolSession struct {
+	parent *ToolSession
+
+	mu        sync.Mutex
+	resources map[string]*sessionResource
+	opened    []*sessionResource
+	closed    bool
+}
+
+type sessionResource struct {
+	SessionResource
+	mu    sync.Mutex
+	open  bool
+	value interface{}
+}
+
+// NewToolSession creates a session for the given resources. Resources are
+// not opened until OpenEager or first use.
+func NewToolSession(resources ...SessionResource) *ToolSession {
+	s := &ToolSession{resources: make(map[string]*sessionResource, len(resources))}
+	for _, r := range resources {
+		s.resources[r.Name] = &sessionResource{SessionResource: r}
+	}
+	return s
+}
+
+type toolSessionKey struct{}
+
+// WithToolSession returns a context carrying s. Resources s does not
+// declare are looked up in the session already in ctx, so subagents can use
+// their parent run's connections.
+func WithToolSession(ctx context.Context, s *ToolSession) context.Context {
+	if parent := ToolSessionFromContext(ctx); parent != nil && parent != s {
+		s.parent = parent
+	}
+	return context.WithValue(ctx, toolSessionKey{}, s)
+}
+
+// ToolSessionFromContext returns the session in ctx, or nil
+func ToolSessionFromContext(ctx context.Context) *ToolSession {
+	s, _ := ctx.Value(toolSessionKey{}).(*ToolSession)
+	return s
+}
+
+// SessionValue returns the named resource from the session in ctx, opening
+// it on first use
+func SessionValue[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+	s := ToolSessionFromContext(ctx)
+	if s == nil {
+		return zero, fmt.Errorf("no tool session in context for resource %q", name)
+	}
+	value, err := s.Get(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("session resource %q is %T, not %T", name, value, zero)
+	}
+	return typed, nil
+}
+
+// Get returns the named resource, opening it on first use
+func (s *ToolSession) Get(ctx context.Context, name string) (interface{}, error) {
+	s.mu.Lock()
+	r, ok := s.resources[name]
+	closed := s.closed
+	s.mu.Unlock()
+	if !ok {
+		if s.parent != nil {
+			return s.parent.Get(ctx, name)
+		}
+		return nil, fmt.Errorf("unknown session resource %q", name)
+	}
+	if closed {
+		return nil, fmt.Errorf("tool session closed, cannot use resource %q", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.open {
+		return r.value, nil
+	}
+	if r.Open == nil {
+		return nil, fmt.Errorf("session resource %q has no Open function", name)
+	}
+	value, err := r.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session resource %q: %w", name, err)
+	}
+	s.mu.Lock()
+	if s.closed {
+		// The run ended while opening; don't leak the resource
+		s.mu.Unlock()
+		if r.Close != nil {
+			_ = r.Close(ctx, value)
+		}
+		return nil, fmt.Errorf("tool session closed, cannot use resource %q", name)
+	}
+	r.open, r.value = true, value
+	s.opened = append(s.opened, r)
+	s.mu.Unlock()
+	return value, nil
+}
+
+// OpenEager opens every resource marked Eager
+func (s *ToolSession) OpenEager(ctx context.Context) error {
+	for name, r := range s.resources {
+		if r.Eager {
+			if _, err := s.Get(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes opened resources in reverse order of opening and returns
+// their errors joined. Later calls do nothing.
+func (s *ToolSession) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	opened := s.opened
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(opened) - 1; i >= 0; i-- {
+		r := opened[i]
+		if r.Close == nil {
+			continue
+		}
+		if err := r.Close(ctx, r.value); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close session resource %q: %w", r.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// fakeConn counts queries on a shared connection
+type fakeConn struct {
+	queries int
+	closed  bool
+}
+
+func TestToolSession_SharedAcrossToolCalls(t *testing.T) {
+	opens := 0
+	var conn *fakeConn
+	query := types.Tool{
+		Name:       "query",
+		Parameters: map[string]interface{}{},
+		Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			c, err := SessionValue[*fakeConn](ctx, "db")
+			if err != nil {
+				return nil, err
+			}
+			c.queries++
+			return c.queries, nil
+		},
+	}
+	toolStep := func(id string) types.GenerateResult {
+		return types.GenerateResult{
+			FinishReason: types.FinishReasonToolCalls,
+			ToolCalls:    []types.ToolCall{{ID: id, ToolName: "query", Arguments: map[string]interface{}{}}},
+		}
+	}
+
+	a := NewToolLoopAgent(AgentConfig{
+		Model:    &mockLanguageModel{responses: []types.GenerateResult{toolStep("c1"), toolStep("c2")}},
+		Tools:    []types.Tool{query},
+		MaxSteps: 5,
+		SessionResources: []SessionResource{{
+			Name: "db",
+			Open: func(ctx context.Context) (interface{}, error) {
+				opens++
+				conn = &fakeConn{}
+				return conn, nil
+			},
+			Close: func(ctx context.Context, v interface{}) error {
+				v.(*fakeConn).closed = true
+				return errors.New("connection reset")
+			},
+		}},
+	})
+	result, err := a.Execute(context.Background(), "run two queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opens != 1 || conn.queries != 2 {
+		t.Errorf("expected one connection used twice, got %d opens and %d queries", opens, conn.queries)
+	}
+	if !conn.closed {
+		t.Error("expected the connection to be closed after the run")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Details, "connection reset") {
+		t.Errorf("expected the close error as a warning, got %+v", result.Warnings)
+	}
+}
+
+func TestToolSession_EagerFailure(t *testing.T) {
+	a := NewToolLoopAgent(AgentConfig{
+		Model: &mockLanguageModel{},
+		SessionResources: []SessionResource{{
+			Name:  "browser",
+			Eager: true,
+			Open:  func(ctx context.Context) (interface{}, error) { return nil, errors.New("no display") },
+		}},
+	})
+	if _, err := a.Execute(context.Background(), "hi"); err == nil || !strings.Contains(err.Error(), "no display") {
+		t.Errorf("expected the eager open error, got %v", err)
+	}
+}
+
+func TestToolSession_ParentLookupAndClose(t *testing.T) {
+	parent := NewToolSession(SessionResource{
+		Name: "mcp",
+		Open: func(ctx context.Context) (interface{}, error) { return "client", nil },
+	})
+	child := NewToolSession()
+	ctx := WithToolSession(WithToolSession(context.Background(), parent), child)
+
+	if v, err := SessionValue[string](ctx, "mcp"); err != nil || v != "client" {
+		t.Errorf("expected the parent's resource, got %v, %v", v, err)
+	}
+	if _, err := SessionValue[int](ctx, "mcp"); err == nil {
+		t.Error("expected a type mismatch error")
+	}
+	if _, err := SessionValue[string](ctx, "missing"); err == nil {
+		t.Error("expected an unknown resource error")
+	}
+
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parent.Get(context.Background(), "mcp"); err == nil {
+		t.Error("expected an error using a closed session")
+	}
+}
@@ -0,0 +1,148 @@
+package trace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+)
+
+// Mermaid renders result as a Mermaid flowchart: one node per step, one per
+// tool call, and one per subagent delegation
+func Mermaid(result *agent.AgentResult) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	writeMermaid(&b, newRun(result), "")
+	return b.String()
+}
+
+func writeMermaid(b *strings.Builder, r *run, prefix string) {
+	node := func(id string) string { return prefix + id }
+	start, end := node("start"), node("end")
+
+	fmt.Fprintf(b, "  %s([\"%s\"])\n", start, mermaidLabel("run · "+runSummary(r)))
+	prev := start
+	for _, s := range r.Steps {
+		id := node(fmt.Sprintf("s%d", s.Number))
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", id, mermaidLabel(stepLabel(s)))
+		fmt.Fprintf(b, "  %s --> %s\n", prev, id)
+		for i, c := range s.Calls {
+			callID := fmt.Sprintf("%s_t%d", id, i+1)
+			fmt.Fprintf(b, "  %s[[\"%s\"]]\n", callID, mermaidLabel(callLabel(c)))
+			fmt.Fprintf(b, "  %s --> %s\n", id, callID)
+			if c.Error != "" {
+				fmt.Fprintf(b, "  style %s stroke:#c0392b,stroke-width:2px\n", callID)
+			}
+		}
+		prev = id
+	}
+	for i, d := range r.Delegations {
+		id := node(fmt.Sprintf("d%d", i+1))
+		fmt.Fprintf(b, "  subgraph %s [\"%s\"]\n", id, mermaidLabel("subagent "+d.Name))
+		if d.Run != nil {
+			writeMermaid(b, d.Run, id+"_")
+		} else {
+			fmt.Fprintf(b, "  %s_err[\"%s\"]\n", id, mermaidLabel("error: "+d.Error))
+		}
+		b.WriteString("  end\n")
+		fmt.Fprintf(b, "  %s -.-> %s\n", prev, id)
+	}
+	fmt.Fprintf(b, "  %s([\"%s\"])\n", end, mermaidLabel(finishLabel(r)))
+	fmt.Fprintf(b, "  %s --> %s\n", prev, end)
+}
+
+// mermaidLabel escapes text for a quoted Mermaid label
+func mermaidLabel(s string) string {
+	s = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
+
+// DOT renders result as a Graphviz digraph
+func DOT(result *agent.AgentResult) string {
+	var b strings.Builder
+	b.WriteString("digraph agent {\n")
+	b.WriteString("  rankdir=TB;\n  node [fontname=\"Helvetica\", fontsize=11];\n")
+	writeDOT(&b, newRun(result), "")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func writeDOT(b *strings.Builder, r *run, prefix string) {
+	node := func(id string) string { return prefix + id }
+	start, end := node("start"), node("end")
+
+	fmt.Fprintf(b, "  %s [shape=oval, label=%s];\n", start, dotLabel("run\n"+runSummary(r)))
+	prev := start
+	for _, s := range r.Steps {
+		id := node(fmt.Sprintf("s%d", s.Number))
+		fmt.Fprintf(b, "  %s [shape=box, style=rounded, label=%s];\n", id, dotLabel(stepLabel(s)))
+		fmt.Fprintf(b, "  %s -> %s;\n", prev, id)
+		for i, c := range s.Calls {
+			callID := fmt.Sprintf("%s_t%d", id, i+1)
+			color := "black"
+			if c.Error != "" {
+				color = "red"
+			}
+			fmt.Fprintf(b, "  %s [shape=component, color=%s, label=%s];\n", callID, color, dotLabel(callLabel(c)))
+			fmt.Fprintf(b, "  %s -> %s;\n", id, callID)
+		}
+		prev = id
+	}
+	for i, d := range r.Delegations {
+		id := node(fmt.Sprintf("d%d", i+1))
+		fmt.Fprintf(b, "  subgraph cluster_%s {\n  label=%s;\n", id, dotLabel("subagent "+d.Name))
+		if d.Run != nil {
+			writeDOT(b, d.Run, id+"_")
+			fmt.Fprintf(b, "  }\n  %s -> %s_start [style=dashed];\n", prev, id)
+		} else {
+			fmt.Fprintf(b, "  %s_err [shape=box, color=red, label=%s];\n  }\n", id, dotLabel("error: "+d.Error))
+			fmt.Fprintf(b, "  %s -> %s_err [style=dashed];\n", prev, id)
+		}
+	}
+	fmt.Fprintf(b, "  %s [shape=oval, label=%s];\n", end, dotLabel(finishLabel(r)))
+	fmt.Fprintf(b, "  %s -> %s;\n", prev, end)
+}
+
+// dotLabel quotes text as a DOT string
+func dotLabel(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+func runSummary(r *run) string {
+	return fmt.Sprintf("%d steps · %d tokens · %s", len(r.Steps), r.Tokens, formatMs(r.DurationMs))
+}
+
+func stepLabel(s step) string {
+	label := fmt.Sprintf("Step %d\n%s · %d tokens", s.Number, formatMs(s.DurationMs), s.Tokens)
+	if s.Text != "" {
+		label += "\n" + preview(s.Text, maxPreview)
+	}
+	return label
+}
+
+func callLabel(c call) string {
+	label := fmt.Sprintf("%s(%s)", c.Name, preview(c.Args, maxPreview))
+	switch {
+	case c.Pending:
+		label += "\npending"
+	case c.Error != "":
+		label += "\nerror: " + preview(c.Error, maxPreview)
+	case c.ProviderExecuted:
+		label += "\nprovider-executed"
+	default:
+		label += fmt.Sprintf("\n%s → %s", formatMs(c.DurationMs), preview(c.Result, maxPreview))
+	}
+	return label
+}
+
+func finishLabel(r *run) string {
+	label := "finish: " + string(r.FinishReason)
+	if r.FinishReason == "" {
+		label = "finish"
+	}
+	if r.StopReason != "" {
+		label += "\n" + preview(r.StopReason, maxPreview)
+	}
+	return label
+}
@@ -0,0 +1,91 @@
+package trace
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+)
+
+// HTML renders result as a self-contained HTML page: a summary, a timeline
+// of steps and tool calls scaled by latency, and expandable arguments,
+// results and subagent runs. The page has no external assets.
+func HTML(result *agent.AgentResult) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, newRun(result)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"ms": formatMs,
+	// width is a bar's share of the run's duration, as a CSS percentage
+	"width": func(part, total int64) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return float64(part) * 100 / float64(total)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Agent run trace</title>
+<style>
+body { font: 14px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 960px; color: #1f2328; padding: 0 1rem; }
+h1 { font-size: 1.4rem; }
+.summary span { display: inline-block; margin-right: 1.5rem; }
+.step { border: 1px solid #d0d7de; border-radius: 6px; margin: 1rem 0; padding: .75rem 1rem; }
+.step h2 { font-size: 1rem; margin: 0 0 .25rem; }
+.meta { color: #656d76; font-size: .85rem; }
+.bar { height: 6px; background: #0969da; border-radius: 3px; margin: .25rem 0 .5rem; min-width: 2px; }
+.call .bar { background: #bf8700; }
+.call.error .bar { background: #cf222e; }
+.call { margin: .5rem 0 .5rem 1rem; }
+.call.error summary { color: #cf222e; }
+pre { background: #f6f8fa; padding: .5rem; border-radius: 4px; white-space: pre-wrap; word-break: break-word; margin: .25rem 0; }
+.subagent { margin-left: 1.5rem; border-left: 3px solid #8250df; padding-left: 1rem; }
+.final { border-top: 1px solid #d0d7de; margin-top: 1.5rem; }
+</style>
+</head>
+<body>
+<h1>Agent run trace</h1>
+{{template "run" .}}
+</body>
+</html>
+{{define "run"}}
+<div class="summary meta">
+<span>{{len .Steps}} steps</span>
+<span>{{ms .DurationMs}}</span>
+<span>{{.Tokens}} tokens ({{.InputTokens}} in / {{.OutputTokens}} out)</span>
+<span>finish: {{.FinishReason}}</span>
+{{if .StopReason}}<span>stopped: {{.StopReason}}</span>{{end}}
+</div>
+{{$total := .DurationMs}}
+{{range .Steps}}
+<div class="step">
+<h2>Step {{.Number}}</h2>
+<div class="meta">{{ms .DurationMs}} · {{.Tokens}} tokens · {{.FinishReason}}</div>
+<div class="bar" style="width: {{width .DurationMs $total}}%"></div>
+{{if .Text}}<pre>{{.Text}}</pre>{{end}}
+{{range .Calls}}
+<details class="call{{if .Error}} error{{end}}">
+<summary><code>{{.Name}}</code> · {{if .Pending}}pending{{else if .ProviderExecuted}}provider-executed{{else}}{{ms .DurationMs}}{{end}}{{if .Error}} · error{{end}}</summary>
+<div class="bar" style="width: {{width .DurationMs $total}}%"></div>
+<div class="meta">arguments</div>
+<pre>{{.Args}}</pre>
+{{if .Error}}<div class="meta">error</div><pre>{{.Error}}</pre>{{else if not .Pending}}<div class="meta">result</div><pre>{{.Result}}</pre>{{end}}
+</details>
+{{end}}
+</div>
+{{end}}
+{{range .Delegations}}
+<details class="subagent" open>
+<summary>subagent <strong>{{.Name}}</strong>{{if .Error}} · error: {{.Error}}{{end}}</summary>
+{{if .Prompt}}<div class="meta">prompt</div><pre>{{.Prompt}}</pre>{{end}}
+{{if .Run}}{{template "run" .Run}}{{end}}
+</details>
+{{end}}
+{{if .Text}}<div class="final"><div class="meta">final answer</div><pre>{{.Text}}</pre></div>{{end}}
+{{end}}`))
@@ -0,0 +1,203 @@
+// Package trace renders an agent run as a diagram for debugging and
+// sharing: steps, tool calls with their arguments and results, latencies and
+// token usage. Everything is taken from the AgentResult, so a run can be
+// exported after the fact or from a stored result.
+//
+// Example:
+//
+//	result, err := myAgent.Execute(ctx, "Plan a trip to Lisbon")
+//	page, _ := trace.Export(result, trace.FormatHTML)
+//	os.WriteFile("run.html", page, 0o644)
+//
+//	diagram, _ := trace.Export(result, trace.FormatMermaid)
+//	fmt.Println(string(diagram))
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Format identifies an export format
+type Format string
+
+const (
+	// FormatHTML is a self-contained HTML page with a timeline and
+	// expandable tool calls
+	FormatHTML Format = "html"
+
+	// FormatMermaid is a Mermaid flowchart, which renders in GitHub and
+	// GitLab markdown
+	FormatMermaid Format = "mermaid"
+
+	// FormatDOT is a Graphviz digraph
+	FormatDOT Format = "dot"
+)
+
+// Export renders result in the given format
+func Export(result *agent.AgentResult, format Format) ([]byte, error) {
+	if result == nil {
+		return nil, fmt.Errorf("trace: nil agent result")
+	}
+	switch format {
+	case FormatHTML:
+		return HTML(result)
+	case FormatMermaid:
+		return []byte(Mermaid(result)), nil
+	case FormatDOT:
+		return []byte(DOT(result)), nil
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", format)
+	}
+}
+
+// maxPreview is how much of a tool's arguments or result is shown in
+// diagram labels
+const maxPreview = 60
+
+// run is the exported view of an agent run
+type run struct {
+	Text         string
+	FinishReason types.FinishReason
+	StopReason   string
+	Steps        []step
+	Delegations  []delegation
+	Tokens       int64
+	InputTokens  int64
+	OutputTokens int64
+	DurationMs   int64
+}
+
+type step struct {
+	Number       int
+	Text         string
+	FinishReason types.FinishReason
+	Tokens       int64
+	DurationMs   int64
+	Calls        []call
+}
+
+type call struct {
+	ID               string
+	Name             string
+	Args             string
+	Result           string
+	Error            string
+	DurationMs       int64
+	ProviderExecuted bool
+	Pending          bool
+}
+
+type delegation struct {
+	Name   string
+	Prompt string
+	Error  string
+	Run    *run
+}
+
+// newRun builds the view of result. Tool results are matched to calls by
+// ID, from the step when it has them and from the run otherwise.
+func newRun(result *agent.AgentResult) *run {
+	r := &run{
+		Text:         result.Text,
+		FinishReason: result.FinishReason,
+		StopReason:   result.StopReason,
+		Tokens:       result.Usage.GetTotalTokens(),
+		InputTokens:  result.Usage.GetInputTokens(),
+		OutputTokens: result.Usage.GetOutputTokens(),
+	}
+
+	results := make(map[string]types.ToolResult, len(result.ToolResults))
+	for _, tr := range result.ToolResults {
+		results[tr.ToolCallID] = tr
+	}
+	for _, s := range result.Steps {
+		for _, tr := range s.ToolResults {
+			results[tr.ToolCallID] = tr
+		}
+	}
+
+	for _, s := range result.Steps {
+		st := step{
+			Number:       s.StepNumber,
+			Text:         s.Text,
+			FinishReason: s.FinishReason,
+			Tokens:       s.Usage.GetTotalTokens(),
+			DurationMs:   s.DurationMs,
+		}
+		for _, tc := range s.ToolCalls {
+			c := call{ID: tc.ID, Name: tc.ToolName, Args: jsonString(tc.Arguments), ProviderExecuted: tc.ProviderExecuted}
+			tr, ok := results[tc.ID]
+			switch {
+			case !ok:
+				c.Pending = true
+			case tr.Error != nil:
+				c.Error = tr.Error.Error()
+			default:
+				c.Result = jsonString(tr.Result)
+			}
+			c.DurationMs = tr.DurationMs
+			c.ProviderExecuted = c.ProviderExecuted || tr.ProviderExecuted
+			st.DurationMs += c.DurationMs
+			st.Calls = append(st.Calls, c)
+		}
+		r.DurationMs += st.DurationMs
+		r.Steps = append(r.Steps, st)
+	}
+
+	for _, d := range result.Delegations {
+		del := delegation{Name: d.SubagentName, Prompt: d.Prompt}
+		if d.Error != nil {
+			del.Error = d.Error.Error()
+		}
+		if d.Result != nil {
+			del.Run = newRun(d.Result)
+		}
+		r.Delegations = append(r.Delegations, del)
+	}
+	return r
+}
+
+// jsonString renders a value for display: strings as is, anything else as
+// JSON
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// formatMs renders a duration in milliseconds compactly, e.g. "850ms" or
+// "1.2s"
+func formatMs(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// preview shortens s to one line of at most n runes
+func preview(s string, n int) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if r == '\n' || r == '\r' || r == '\t' {
+			runes[i] = ' '
+		}
+	}
+	if len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return string(runes)
+}
@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func testResult() *agent.AgentResult {
+	tokens := int64(120)
+	return &agent.AgentResult{
+		Text:         "It is <b>sunny</b> in Paris.",
+		FinishReason: types.FinishReasonStop,
+		Usage:        types.Usage{TotalTokens: &tokens},
+		Steps: []types.StepResult{
+			{
+				StepNumber:   1,
+				FinishReason: types.FinishReasonToolCalls,
+				DurationMs:   800,
+				ToolCalls: []types.ToolCall{
+					{ID: "c1", ToolName: "weather", Arguments: map[string]interface{}{"city": `Paris "FR"`}},
+					{ID: "c2", ToolName: "news", Arguments: map[string]interface{}{}},
+				},
+			},
+			{StepNumber: 2, Text: "It is <b>sunny</b> in Paris.", FinishReason: types.FinishReasonStop, DurationMs: 400},
+		},
+		ToolResults: []types.ToolResult{
+			{ToolCallID: "c1", ToolName: "weather", Result: map[string]interface{}{"sky": "sunny"}, DurationMs: 300},
+			{ToolCallID: "c2", ToolName: "news", Error: errors.New("feed unavailable"), DurationMs: 50},
+		},
+		Delegations: []agent.SubagentDelegation{
+			{SubagentName: "translator", Prompt: "translate", Result: &agent.AgentResult{
+				Text:  "Il fait beau",
+				Steps: []types.StepResult{{StepNumber: 1, Text: "Il fait beau", DurationMs: 100}},
+			}},
+		},
+	}
+}
+
+func TestNewRun(t *testing.T) {
+	t.Parallel()
+
+	r := newRun(testResult())
+	if r.DurationMs != 1550 || len(r.Steps) != 2 || r.Tokens != 120 {
+		t.Fatalf("unexpected run %+v", r)
+	}
+	calls := r.Steps[0].Calls
+	if calls[0].Result != `{"sky":"sunny"}` || calls[0].DurationMs != 300 || calls[1].Error != "feed unavailable" {
+		t.Errorf("expected tool results matched by ID, got %+v", calls)
+	}
+	if len(r.Delegations) != 1 || r.Delegations[0].Run == nil || len(r.Delegations[0].Run.Steps) != 1 {
+		t.Errorf("expected the subagent run, got %+v", r.Delegations)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	t.Parallel()
+
+	out := Mermaid(testResult())
+	for _, want := range []string{
+		"flowchart TD",
+		"start --> s1",
+		"s1 --> s1_t1",
+		`weather({#quot;city#quot;:#quot;Paris \#quot;FR\#quot;#quot;})`,
+		"style s1_t2 stroke:#c0392b",
+		`subgraph d1 ["subagent translator"]`,
+		"d1_start --> d1_s1",
+		"s2 --> end",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected Mermaid output to contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	t.Parallel()
+
+	out := DOT(testResult())
+	for _, want := range []string{
+		"digraph agent {",
+		`s1_t1 [shape=component, color=black, label="weather({\"city\":\"Paris \\\"FR\\\"\"})\n300ms`,
+		"color=red",
+		"subgraph cluster_d1",
+		"s2 -> d1_start [style=dashed];",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected DOT output to contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	page, err := Export(testResult(), FormatHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(page)
+	if strings.Contains(html, "<b>sunny</b>") || !strings.Contains(html, "&lt;b&gt;sunny&lt;/b&gt;") {
+		t.Error("expected model text to be escaped")
+	}
+	for _, want := range []string{"Step 2", "feed unavailable", "subagent <strong>translator</strong>", "1.6s"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q", want)
+		}
+	}
+
+	if _, err := Export(testResult(), "svg"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
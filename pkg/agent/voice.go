@@ -0,0 +1,578 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/sentence"
+)
+
+// VoiceSessionConfig configures a VoiceSession
+type VoiceSessionConfig struct {
+	// Agent answers each transcribed utterance (required)
+	Agent *ToolLoopAgent
+
+	// Transcriber converts caller audio to text (required by HandleUtterance;
+	// sessions fed by Listen or HandleTranscript do not need one)
+	Transcriber provider.TranscriptionModel
+
+	// Speaker synthesizes the agent's reply (required)
+	Speaker provider.SpeechModel
+
+	// Voice and Speed are passed to the speech model
+	Voice string
+	Speed *float64
+
+	// SpeechFormat requests an audio encoding from the speech model
+	// (e.g. "wav" or "pcm"); empty uses the provider default
+	SpeechFormat string
+
+	// MimeType of the utterance audio passed to HandleUtterance (e.g. "audio/wav")
+	MimeType string
+
+	// Language hint for transcription, also used to find sentence
+	// boundaries in the reply (optional)
+	Language string
+
+	// SentenceLatency bounds how long the start of an unfinished sentence
+	// waits for synthesis; after that the text up to the last word boundary
+	// is spoken (default: 1s; negative disables)
+	SentenceLatency time.Duration
+
+	// OnTranscript is called with the caller's transcribed utterance
+	OnTranscript func(ctx context.Context, text string)
+
+	// OnResponse is called with the agent's full text reply once the turn
+	// has been spoken; it is not called for interrupted turns
+	OnResponse func(ctx context.Context, text string)
+
+	// OnAudio is called once per synthesized sentence, in order, so playback
+	// can start before the whole reply has been synthesized
+	OnAudio func(ctx context.Context, segment VoiceAudioSegment)
+
+	// OnInterrupt is called when a new utterance (or Interrupt) barges in on
+	// a turn that is still running
+	OnInterrupt func(ctx context.Context)
+
+	// OnTurnFinish is called after every turn, including interrupted ones
+	OnTurnFinish func(ctx context.Context, turn VoiceTurn)
+
+	// OnError is called with the errors of turns started by Listen
+	OnError func(ctx context.Context, err error)
+}
+
+// VoiceAudioSegment is a synthesized piece of the agent's reply
+type VoiceAudioSegment struct {
+	// Index is the position of the segment within the turn
+	Index int
+
+	// Text is the sentence that was spoken
+	Text string
+
+	// Audio is the synthesized audio
+	Audio []byte
+
+	// MimeType of Audio
+	MimeType string
+}
+
+// VoiceLatency breaks down where time was spent in a turn
+type VoiceLatency struct {
+	// Transcription is the speech-to-text duration
+	Transcription time.Duration
+
+	// Agent is the time the agent took to produce its reply
+	Agent time.Duration
+
+	// FirstAudio is the time from receiving the utterance to the first
+	// synthesized segment, i.e. the caller-perceived response latency
+	FirstAudio time.Duration
+
+	// Total is the duration of the whole turn
+	Total time.Duration
+}
+
+// VoiceTurn is the outcome of handling one utterance
+type VoiceTurn struct {
+	// Transcript is what the caller said
+	Transcript string
+
+	// Response is the agent's text reply, across all of its steps. For an
+	// interrupted turn it holds the text generated before the interruption.
+	Response string
+
+	// Spoken is the part of Response that was synthesized before the turn
+	// ended. It equals Response unless the turn was interrupted.
+	Spoken string
+
+	// Interrupted reports whether the caller barged in before the turn finished
+	Interrupted bool
+
+	// Latency instrumentation for the turn
+	Latency VoiceLatency
+
+	// Usage of the agent's language model calls
+	Usage types.Usage
+}
+
+// VoiceSession runs a realtime voice conversation: each utterance is
+// transcribed, answered by a ToolLoopAgent, and synthesized sentence by
+// sentence while the agent's reply is still streaming. The session keeps the conversation history, and a new utterance
+// arriving while a turn is still running cancels that turn (barge-in); only
+// the sentences that were actually spoken are kept in the history.
+//
+// Example:
+//
+//	session, err := agent.NewVoiceSession(agent.VoiceSessionConfig{
+//		Agent:       agent.NewToolLoopAgent(agent.AgentConfig{Model: model, Tools: tools}),
+//		Transcriber: whisper,
+//		Speaker:     tts,
+//		Voice:       "alloy",
+//		MimeType:    "audio/wav",
+//		OnAudio: func(ctx context.Context, seg agent.VoiceAudioSegment) {
+//			player.Enqueue(seg.Audio)
+//		},
+//		OnInterrupt: func(ctx context.Context) { player.Flush() },
+//	})
+//	for utterance := range vad.Utterances() {
+//		go session.HandleUtterance(ctx, utterance)
+//	}
+type VoiceSession struct {
+	config VoiceSessionConfig
+
+	mu       sync.Mutex
+	history  []types.Message
+	cancel   context.CancelFunc
+	done     chan struct{}
+	notified chan struct{}
+}
+
+// NewVoiceSession creates a VoiceSession
+func NewVoiceSession(config VoiceSessionConfig) (*VoiceSession, error) {
+	if config.Agent == nil {
+		return nil, fmt.Errorf("agent is required")
+	}
+	if config.Speaker == nil {
+		return nil, fmt.Errorf("speaker is required")
+	}
+	return &VoiceSession{config: config}, nil
+}
+
+// History returns a copy of the conversation so far
+func (s *VoiceSession) History() []types.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.Message(nil), s.history...)
+}
+
+// Interrupt cancels the turn in progress, if any, without starting a new one.
+// Use it when voice activity detection reports that the caller started
+// speaking, before their utterance is complete.
+func (s *VoiceSession) Interrupt() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// HandleUtterance processes one complete caller utterance. If a previous turn
+// is still running it is interrupted first, and its partial reply is recorded
+// before this turn reads the history. An interrupted turn returns its
+// VoiceTurn with Interrupted set and a nil error.
+func (s *VoiceSession) HandleUtterance(ctx context.Context, audio []byte) (*VoiceTurn, error) {
+	if s.config.Transcriber == nil {
+		return nil, fmt.Errorf("transcriber is required for HandleUtterance")
+	}
+	return s.runTurn(ctx, func(turnCtx context.Context) (string, error) {
+		transcription, err := s.config.Transcriber.DoTranscribe(turnCtx, &provider.TranscriptionOptions{
+			Audio:    audio,
+			MimeType: s.config.MimeType,
+			Language: s.config.Language,
+		})
+		if err != nil {
+			return "", err
+		}
+		return transcription.Text, nil
+	})
+}
+
+// HandleTranscript processes an utterance that has already been transcribed,
+// e.g. by a streaming transcription session. It behaves like HandleUtterance,
+// including barge-in on a running turn.
+func (s *VoiceSession) HandleTranscript(ctx context.Context, text string) (*VoiceTurn, error) {
+	return s.runTurn(ctx, func(context.Context) (string, error) { return text, nil })
+}
+
+// Listen drives the session from a streaming transcription session until it
+// ends or ctx is cancelled. Speech or interim text from the caller interrupts
+// the running turn; final segments are collected until the end of the
+// utterance and then answered with HandleTranscript. Turn errors go to
+// OnError. Listen closes session when it returns and waits for the last turn
+// to finish.
+func (s *VoiceSession) Listen(ctx context.Context, session provider.TranscriptionSession) error {
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var pending []string
+	dispatch := func() {
+		text := strings.TrimSpace(strings.Join(pending, " "))
+		pending = nil
+		if text == "" {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.HandleTranscript(ctx, text); err != nil && s.config.OnError != nil {
+				s.config.OnError(ctx, err)
+			}
+		}()
+	}
+
+	for {
+		event, err := session.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				dispatch()
+				return nil
+			}
+			return err
+		}
+		switch event.Type {
+		case types.TranscriptEventSpeechStarted:
+			s.bargeIn(ctx)
+		case types.TranscriptEventInterim:
+			if strings.TrimSpace(event.Text) != "" {
+				s.bargeIn(ctx)
+			}
+		case types.TranscriptEventFinal:
+			if text := strings.TrimSpace(event.Text); text != "" {
+				s.bargeIn(ctx)
+				pending = append(pending, text)
+			}
+			if event.EndOfUtterance {
+				dispatch()
+			}
+		case types.TranscriptEventUtteranceEnd:
+			dispatch()
+		}
+	}
+}
+
+// bargeIn interrupts the running turn, if any, and reports it to OnInterrupt
+// once. The next turn still waits for the interrupted one to wind down.
+func (s *VoiceSession) bargeIn(ctx context.Context) {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	first := cancel != nil && s.notified != done
+	if first {
+		s.notified = done
+	}
+	s.mu.Unlock()
+	if !first {
+		return
+	}
+	cancel()
+	if s.config.OnInterrupt != nil {
+		s.config.OnInterrupt(ctx)
+	}
+}
+
+// runTurn interrupts any running turn, obtains the caller's text from
+// transcribe and answers it
+func (s *VoiceSession) runTurn(ctx context.Context, transcribe func(ctx context.Context) (string, error)) (*VoiceTurn, error) {
+	start := time.Now()
+	turnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+
+	s.mu.Lock()
+	prevCancel, prevDone := s.cancel, s.done
+	notified := prevDone != nil && s.notified == prevDone
+	s.cancel, s.done = cancel, done
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.done == done {
+			s.cancel, s.done = nil, nil
+		}
+		s.mu.Unlock()
+	}()
+
+	if prevCancel != nil {
+		prevCancel()
+		if s.config.OnInterrupt != nil && !notified {
+			s.config.OnInterrupt(ctx)
+		}
+		<-prevDone
+	}
+
+	turn := &VoiceTurn{}
+	finish := func() {
+		turn.Latency.Total = time.Since(start)
+		if s.config.OnTurnFinish != nil {
+			s.config.OnTurnFinish(ctx, *turn)
+		}
+	}
+
+	// Speech to text
+	transcript, err := transcribe(turnCtx)
+	turn.Latency.Transcription = time.Since(start)
+	if err != nil {
+		return s.endTurn(ctx, turnCtx, turn, finish, fmt.Errorf("transcription failed: %w", err))
+	}
+	turn.Transcript = strings.TrimSpace(transcript)
+	if turn.Transcript == "" {
+		// Nothing intelligible was said; don't involve the agent
+		finish()
+		return turn, nil
+	}
+	if s.config.OnTranscript != nil {
+		s.config.OnTranscript(ctx, turn.Transcript)
+	}
+
+	userMsg := types.Message{
+		Role:    types.RoleUser,
+		Content: []types.ContentPart{types.TextContent{Text: turn.Transcript}},
+	}
+	messages := append(s.History(), userMsg)
+
+	// Agent, streamed into text to speech one sentence at a time
+	agentCtx, agentCancel := context.WithCancel(turnCtx)
+	defer agentCancel()
+	reply := newReplyStream()
+	var result *AgentResult
+	var agentErr error
+	agentDone := make(chan struct{})
+	agentStart := time.Now()
+	go func() {
+		defer close(agentDone)
+		result, agentErr = s.config.Agent.StreamWithMessages(agentCtx, messages, func(ctx context.Context, chunk provider.StreamChunk) {
+			reply.push(chunk)
+		})
+		turn.Latency.Agent = time.Since(agentStart)
+		reply.finish(agentErr)
+	}()
+
+	latency := s.config.SentenceLatency
+	switch {
+	case latency == 0:
+		latency = time.Second
+	case latency < 0:
+		latency = 0
+	}
+	sentences := sentence.NewStream(reply, sentence.Options{Language: s.config.Language, MaxLatency: latency})
+	defer sentences.Close()
+
+	var spoken []string
+	var speakErr error
+	for turnCtx.Err() == nil {
+		event, err := sentences.Next()
+		if err != nil {
+			// io.EOF, or the agent's error reported below
+			break
+		}
+		text := strings.TrimSpace(event.Text)
+		if event.Chunk != nil || text == "" {
+			continue
+		}
+		speech, err := s.config.Speaker.DoGenerate(turnCtx, &provider.SpeechGenerateOptions{
+			Text:         text,
+			Voice:        s.config.Voice,
+			Speed:        s.config.Speed,
+			OutputFormat: s.config.SpeechFormat,
+		})
+		if err != nil {
+			if turnCtx.Err() == nil {
+				speakErr = err
+			}
+			break
+		}
+		if len(spoken) == 0 {
+			turn.Latency.FirstAudio = time.Since(start)
+		}
+		if s.config.OnAudio != nil {
+			s.config.OnAudio(ctx, VoiceAudioSegment{
+				Index:    len(spoken),
+				Text:     text,
+				Audio:    speech.Audio,
+				MimeType: speech.MimeType,
+			})
+		}
+		spoken = append(spoken, text)
+	}
+	agentCancel()
+	<-agentDone
+
+	turn.Response = reply.text()
+	if result != nil {
+		turn.Usage = result.Usage
+	}
+	turn.Spoken = strings.Join(spoken, " ")
+	turn.Interrupted = turnCtx.Err() != nil && ctx.Err() == nil
+
+	switch {
+	case speakErr != nil:
+		s.appendHistory(userMsg, turn.Spoken)
+		finish()
+		return turn, fmt.Errorf("speech synthesis failed: %w", speakErr)
+	case agentErr != nil && !turn.Interrupted:
+		if turn.Spoken != "" {
+			s.appendHistory(userMsg, turn.Spoken)
+		}
+		finish()
+		return turn, fmt.Errorf("agent failed: %w", agentErr)
+	}
+
+	if s.config.OnResponse != nil && !turn.Interrupted {
+		s.config.OnResponse(ctx, turn.Response)
+	}
+	s.appendHistory(userMsg, turn.Spoken)
+	finish()
+	return turn, nil
+}
+
+// endTurn finishes a turn that failed before any audio was produced. Failures
+// caused by barge-in are reported as an interrupted turn rather than an error;
+// the caller's utterance is still kept so the next turn has its context.
+func (s *VoiceSession) endTurn(ctx, turnCtx context.Context, turn *VoiceTurn, finish func(), err error) (*VoiceTurn, error) {
+	if turnCtx.Err() != nil && ctx.Err() == nil {
+		turn.Interrupted = true
+		if turn.Transcript != "" {
+			s.appendHistory(types.Message{
+				Role:    types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: turn.Transcript}},
+			}, "")
+		}
+		finish()
+		return turn, nil
+	}
+	finish()
+	return turn, err
+}
+
+// appendHistory records a user utterance and the portion of the reply that
+// was spoken
+func (s *VoiceSession) appendHistory(userMsg types.Message, spoken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, userMsg)
+	if spoken != "" {
+		s.history = append(s.history, types.Message{
+			Role:    types.RoleAssistant,
+			Content: []types.ContentPart{types.TextContent{Text: spoken}},
+		})
+	}
+}
+
+// replyStream is a provider.TextStream fed by an agent's chunk callback,
+// so a running agent can be read through a sentence.Stream. Chunks are
+// queued without limit, so synthesis never holds the agent back.
+type replyStream struct {
+	mu       sync.Mutex
+	queue    []provider.StreamChunk
+	steps    []string
+	current  strings.Builder
+	finished bool
+	err      error
+	signal   chan struct{}
+}
+
+func newReplyStream() *replyStream {
+	return &replyStream{signal: make(chan struct{}, 1)}
+}
+
+// push queues a chunk from the agent
+func (r *replyStream) push(chunk provider.StreamChunk) {
+	r.mu.Lock()
+	r.queue = append(r.queue, chunk)
+	switch chunk.Type {
+	case provider.ChunkTypeText:
+		r.current.WriteString(chunk.Text)
+	case provider.ChunkTypeFinish:
+		r.endStepLocked()
+	}
+	r.mu.Unlock()
+	r.notify()
+}
+
+// finish ends the stream once the agent has returned
+func (r *replyStream) finish(err error) {
+	r.mu.Lock()
+	r.finished, r.err = true, err
+	r.endStepLocked()
+	r.mu.Unlock()
+	r.notify()
+}
+
+func (r *replyStream) endStepLocked() {
+	if text := strings.TrimSpace(r.current.String()); text != "" {
+		r.steps = append(r.steps, text)
+	}
+	r.current.Reset()
+}
+
+func (r *replyStream) notify() {
+	select {
+	case r.signal <- struct{}{}:
+	default:
+	}
+}
+
+// text returns the reply text received so far, joining the agent's steps
+func (r *replyStream) text() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.steps, " ")
+}
+
+// Next returns the next queued chunk, waiting for the agent if needed
+func (r *replyStream) Next() (*provider.StreamChunk, error) {
+	for {
+		r.mu.Lock()
+		if len(r.queue) > 0 {
+			chunk := r.queue[0]
+			r.queue = r.queue[1:]
+			r.mu.Unlock()
+			return &chunk, nil
+		}
+		finished, err := r.finished, r.err
+		r.mu.Unlock()
+		if finished {
+			if err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		<-r.signal
+	}
+}
+
+// Err returns the agent's error, if it failed
+func (r *replyStream) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close is a no-op; the agent is stopped through its context
+func (r *replyStream) Close() error {
+	return nil
+}
@@ -0,0 +1,282 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func newTestVoiceSession(t *testing.T, reply string, speak func(ctx context.Context, text string) error) (*VoiceSession, *[]string) {
+	t.Helper()
+	var spoken []string
+	session, err := NewVoiceSession(VoiceSessionConfig{
+		Agent: NewToolLoopAgent(AgentConfig{Model: &testutil.MockLanguageModel{
+			DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+				return testutil.NewMockTextStream(replyChunks(reply)), nil
+			},
+		}}),
+		Transcriber: &testutil.MockTranscriptionModel{
+			DoTranscribeFunc: func(ctx context.Context, opts *provider.TranscriptionOptions) (*types.TranscriptionResult, error) {
+				return &types.TranscriptionResult{Text: string(opts.Audio)}, nil
+			},
+		},
+		Speaker: &testutil.MockSpeechModel{
+			DoGenerateFunc: func(ctx context.Context, opts *provider.SpeechGenerateOptions) (*types.SpeechResult, error) {
+				if speak != nil {
+					if err := speak(ctx, opts.Text); err != nil {
+						return nil, err
+					}
+				}
+				return &types.SpeechResult{Audio: []byte(opts.Text), MimeType: "audio/mpeg"}, nil
+			},
+		},
+		OnAudio: func(ctx context.Context, seg VoiceAudioSegment) {
+			spoken = append(spoken, seg.Text)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewVoiceSession failed: %v", err)
+	}
+	return session, &spoken
+}
+
+// replyChunks streams reply word by word
+func replyChunks(reply string) []provider.StreamChunk {
+	var chunks []provider.StreamChunk
+	for _, word := range strings.SplitAfter(reply, " ") {
+		chunks = append(chunks, provider.StreamChunk{Type: provider.ChunkTypeText, Text: word})
+	}
+	return append(chunks, provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop})
+}
+
+func TestVoiceSession_HandleUtterance(t *testing.T) {
+	t.Parallel()
+
+	session, spoken := newTestVoiceSession(t, "It is sunny. Enjoy your day!", nil)
+
+	turn, err := session.HandleUtterance(context.Background(), []byte("What's the weather?"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if turn.Transcript != "What's the weather?" {
+		t.Errorf("Transcript = %q", turn.Transcript)
+	}
+	if want := []string{"It is sunny.", "Enjoy your day!"}; !reflect.DeepEqual(*spoken, want) {
+		t.Errorf("spoken segments = %q, want %q", *spoken, want)
+	}
+	if turn.Interrupted || turn.Spoken != turn.Response {
+		t.Errorf("expected full reply to be spoken, got %+v", turn)
+	}
+	if turn.Latency.FirstAudio == 0 || turn.Latency.Total < turn.Latency.FirstAudio {
+		t.Errorf("unexpected latency: %+v", turn.Latency)
+	}
+	if len(session.History()) != 2 {
+		t.Errorf("expected user and assistant messages in history, got %d", len(session.History()))
+	}
+}
+
+func TestVoiceSession_BargeIn(t *testing.T) {
+	t.Parallel()
+
+	firstSentence := make(chan struct{})
+	var session *VoiceSession
+	session, _ = newTestVoiceSession(t, "First sentence. Second sentence.", func(ctx context.Context, text string) error {
+		if text == "Second sentence." {
+			select {
+			case <-firstSentence:
+			default:
+				close(firstSentence)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	interrupted := make(chan *VoiceTurn, 1)
+	go func() {
+		turn, err := session.HandleUtterance(context.Background(), []byte("Tell me something"))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		interrupted <- turn
+	}()
+	<-firstSentence
+	session.Interrupt()
+
+	turn := <-interrupted
+	if !turn.Interrupted {
+		t.Fatal("expected the turn to be interrupted")
+	}
+	if turn.Spoken != "First sentence." {
+		t.Errorf("Spoken = %q, want only the first sentence", turn.Spoken)
+	}
+
+	history := session.History()
+	if len(history) != 2 {
+		t.Fatalf("expected 2 history messages, got %d", len(history))
+	}
+	if text := history[1].Content[0].(types.TextContent).Text; text != "First sentence." {
+		t.Errorf("assistant history = %q, want only what was spoken", text)
+	}
+}
+
+// fakeTranscriptionSession delivers events pushed onto its channel
+type fakeTranscriptionSession struct {
+	events chan types.TranscriptEvent
+}
+
+func (f *fakeTranscriptionSession) SendAudio(frame []byte) error { return nil }
+
+func (f *fakeTranscriptionSession) Next() (*types.TranscriptEvent, error) {
+	e, ok := <-f.events
+	if !ok {
+		return nil, io.EOF
+	}
+	return &e, nil
+}
+
+func (f *fakeTranscriptionSession) CloseSend() error { return nil }
+
+func (f *fakeTranscriptionSession) Close() error { return nil }
+
+func TestVoiceSession_Listen(t *testing.T) {
+	t.Parallel()
+
+	firstSentence := make(chan struct{})
+	var blocked atomic.Bool
+	session, _ := newTestVoiceSession(t, "First sentence. Second sentence.", func(ctx context.Context, text string) error {
+		// Only the first turn hangs on its second sentence, waiting for barge-in
+		if text == "Second sentence." && blocked.CompareAndSwap(false, true) {
+			close(firstSentence)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	var interrupts atomic.Int32
+	session.config.OnInterrupt = func(ctx context.Context) { interrupts.Add(1) }
+	session.config.Transcriber = nil
+
+	events := make(chan types.TranscriptEvent, 8)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- session.Listen(context.Background(), &fakeTranscriptionSession{events: events})
+	}()
+
+	events <- types.TranscriptEvent{Type: types.TranscriptEventFinal, Text: "Tell me", EndOfUtterance: false}
+	events <- types.TranscriptEvent{Type: types.TranscriptEventFinal, Text: "something.", EndOfUtterance: true}
+	<-firstSentence
+	events <- types.TranscriptEvent{Type: types.TranscriptEventSpeechStarted}
+	events <- types.TranscriptEvent{Type: types.TranscriptEventInterim, Text: "Stop"}
+	events <- types.TranscriptEvent{Type: types.TranscriptEventFinal, Text: "Stop."}
+	events <- types.TranscriptEvent{Type: types.TranscriptEventUtteranceEnd}
+	close(events)
+
+	if err := <-listenErr; err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	if n := interrupts.Load(); n != 1 {
+		t.Errorf("OnInterrupt called %d times, want 1", n)
+	}
+
+	var got []string
+	for _, msg := range session.History() {
+		got = append(got, string(msg.Role)+": "+msg.Content[0].(types.TextContent).Text)
+	}
+	want := []string{
+		"user: Tell me something.",
+		"assistant: First sentence.",
+		"user: Stop.",
+		"assistant: First sentence. Second sentence.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+}
+
+func TestVoiceSession_HandleUtteranceWithoutTranscriber(t *testing.T) {
+	t.Parallel()
+
+	session, _ := newTestVoiceSession(t, "Hi.", nil)
+	session.config.Transcriber = nil
+	if _, err := session.HandleUtterance(context.Background(), []byte("hello")); err == nil {
+		t.Error("expected an error without a transcriber")
+	}
+}
+
+// blockingStream emits its chunks, then blocks until release is closed
+// before finishing
+type blockingStream struct {
+	chunks   []provider.StreamChunk
+	release  chan struct{}
+	finished bool
+}
+
+func (b *blockingStream) Next() (*provider.StreamChunk, error) {
+	if len(b.chunks) == 0 {
+		if b.finished {
+			return nil, io.EOF
+		}
+		<-b.release
+		b.finished = true
+		return &provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop}, nil
+	}
+	chunk := b.chunks[0]
+	b.chunks = b.chunks[1:]
+	return &chunk, nil
+}
+
+func (b *blockingStream) Err() error   { return nil }
+func (b *blockingStream) Close() error { return nil }
+
+func TestVoiceSession_SpeaksWhileAgentStreams(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var spoken []string
+	session, err := NewVoiceSession(VoiceSessionConfig{
+		Agent: NewToolLoopAgent(AgentConfig{Model: &testutil.MockLanguageModel{
+			DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+				return &blockingStream{chunks: []provider.StreamChunk{
+					{Type: provider.ChunkTypeText, Text: "Dr. Smith is in. "},
+					{Type: provider.ChunkTypeText, Text: "She can"},
+				}, release: release}, nil
+			},
+		}}),
+		Speaker: &testutil.MockSpeechModel{
+			DoGenerateFunc: func(ctx context.Context, opts *provider.SpeechGenerateOptions) (*types.SpeechResult, error) {
+				return &types.SpeechResult{Audio: []byte(opts.Text)}, nil
+			},
+		},
+		OnAudio: func(ctx context.Context, seg VoiceAudioSegment) {
+			spoken = append(spoken, seg.Text)
+			if seg.Index == 0 {
+				// The reply is still streaming when the first sentence is spoken
+				close(release)
+			}
+		},
+		SentenceLatency: -1,
+	})
+	if err != nil {
+		t.Fatalf("NewVoiceSession failed: %v", err)
+	}
+
+	turn, err := session.HandleTranscript(context.Background(), "Is the doctor in?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Dr. Smith is in.", "She can"}; !reflect.DeepEqual(spoken, want) {
+		t.Errorf("spoken segments = %q, want %q", spoken, want)
+	}
+	if turn.Response != "Dr. Smith is in. She can" || turn.Spoken != turn.Response {
+		t.Errorf("unexpected turn: %+v", turn)
+	}
+}
@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// CompareOptions configures Compare
+type CompareOptions struct {
+	// Request is the prompt and generation parameters sent to every model.
+	// Its Model is ignored. Callbacks such as OnFinish are shared, so they
+	// run concurrently.
+	Request GenerateTextOptions
+
+	// Models to compare (required). Results keep this order.
+	Models []provider.LanguageModel
+
+	// Cost prices a model's usage in USD, e.g. a provider's ModelInfo.Cost.
+	// Without it, costs are zero.
+	Cost func(model provider.LanguageModel, usage types.Usage) float64
+
+	// Judge scores the responses; nil skips scoring
+	Judge *CompareJudge
+
+	// Clock measures latency
+	// Default: clock.System
+	Clock clock.Clock
+}
+
+// CompareJudge scores the responses of a comparison with a model. All
+// responses are scored in one request, with the models' names hidden, so
+// scores are relative to each other.
+type CompareJudge struct {
+	// Model that scores the responses (required)
+	Model provider.LanguageModel
+
+	// Criteria describes what makes a response good
+	// Default: "correctness, completeness and clarity"
+	Criteria string
+
+	// Reference is an expected answer to score the responses against
+	Reference string
+}
+
+// CompareEntry is one model's result
+type CompareEntry struct {
+	// Model that produced the entry
+	Model provider.LanguageModel
+
+	// Result of the generation; nil when Err is set
+	Result *GenerateTextResult
+
+	// Err is why the generation failed
+	Err error
+
+	// Latency of the generation
+	Latency time.Duration
+
+	// Cost of the generation in USD
+	Cost float64
+
+	// Score from the judge, from 0 (worst) to 10 (best); nil without a
+	// judge or when the generation failed
+	Score *float64
+
+	// ScoreReason is the judge's explanation of the score
+	ScoreReason string
+}
+
+// Label returns "provider:model" for the entry's model
+func (e CompareEntry) Label() string {
+	return e.Model.Provider() + ":" + e.Model.ModelID()
+}
+
+// CompareResult holds the entries of a comparison in the order of
+// CompareOptions.Models
+type CompareResult struct {
+	Entries []CompareEntry
+
+	// JudgeUsage is the usage of the judge's request
+	JudgeUsage types.Usage
+
+	// JudgeErr is why scoring failed; the entries are still returned
+	JudgeErr error
+}
+
+// Compare runs the same request against several models concurrently and
+// returns their results side by side with latency, cost and, with a judge,
+// scores, e.g. to check that a cheaper model holds up before migrating.
+// A model that fails is recorded in its entry rather than failing the
+// comparison.
+//
+// Example:
+//
+//	result, err := ai.Compare(ctx, ai.CompareOptions{
+//		Request: ai.GenerateTextOptions{Prompt: "Summarize this contract: ..."},
+//		Models:  []provider.LanguageModel{gpt4, gpt4oMini},
+//		Judge:   &ai.CompareJudge{Model: judge, Criteria: "faithfulness to the contract"},
+//	})
+//	for _, e := range result.Entries {
+//		fmt.Printf("%s: %v, $%.4f, score %.1f\n", e.Label(), e.Latency, e.Cost, *e.Score)
+//	}
+func Compare(ctx context.Context, opts CompareOptions) (*CompareResult, error) {
+	if len(opts.Models) == 0 {
+		return nil, fmt.Errorf("models are required")
+	}
+	if opts.Judge != nil && opts.Judge.Model == nil {
+		return nil, fmt.Errorf("judge model is required")
+	}
+	clk := clock.Default(opts.Clock)
+
+	result := &CompareResult{Entries: make([]CompareEntry, len(opts.Models))}
+	var wg sync.WaitGroup
+	for i, model := range opts.Models {
+		if model == nil {
+			return nil, fmt.Errorf("model %d is nil", i)
+		}
+		wg.Add(1)
+		go func(entry *CompareEntry, model provider.LanguageModel) {
+			defer wg.Done()
+			req := opts.Request
+			req.Model = model
+			start := clk.Now()
+			entry.Model = model
+			entry.Result, entry.Err = GenerateText(ctx, req)
+			entry.Latency = clk.Now().Sub(start)
+			if entry.Err == nil && opts.Cost != nil {
+				entry.Cost = opts.Cost(model, entry.Result.Usage)
+			}
+		}(&result.Entries[i], model)
+	}
+	wg.Wait()
+
+	if opts.Judge != nil {
+		result.JudgeUsage, result.JudgeErr = judgeEntries(ctx, opts.Judge, &opts.Request, result.Entries)
+	}
+	return result, nil
+}
+
+// judgeEntries scores the successful entries in place
+func judgeEntries(ctx context.Context, judge *CompareJudge, req *GenerateTextOptions, entries []CompareEntry) (types.Usage, error) {
+	type candidate struct {
+		ID       int    `json:"id"`
+		Response string `json:"response"`
+	}
+	var candidates []candidate
+	for i, e := range entries {
+		if e.Err == nil {
+			candidates = append(candidates, candidate{ID: i + 1, Response: e.Result.Text})
+		}
+	}
+	if len(candidates) == 0 {
+		return types.Usage{}, nil
+	}
+
+	criteria := judge.Criteria
+	if criteria == "" {
+		criteria = "correctness, completeness and clarity"
+	}
+	var prompt strings.Builder
+	prompt.WriteString("Request:\n")
+	prompt.WriteString(requestTranscript(req))
+	if judge.Reference != "" {
+		prompt.WriteString("\n\nReference answer:\n")
+		prompt.WriteString(judge.Reference)
+	}
+	payload, err := json.Marshal(candidates)
+	if err != nil {
+		return types.Usage{}, err
+	}
+	prompt.WriteString("\n\nResponses:\n")
+	prompt.Write(payload)
+
+	result, err := GenerateObject(ctx, GenerateObjectOptions{
+		Model: judge.Model,
+		System: "You compare responses to the same request. Score every response from 0 (worst) to 10 (best) for " +
+			criteria + ", and explain each score in one sentence. Judge the responses on their merits, not their length or order.",
+		Prompt:     prompt.String(),
+		Schema:     judgeScoresSchema,
+		OutputMode: ObjectModeObject,
+	})
+	if err != nil {
+		return types.Usage{}, fmt.Errorf("judge failed: %w", err)
+	}
+
+	var out struct {
+		Scores []struct {
+			ID     int     `json:"id"`
+			Score  float64 `json:"score"`
+			Reason string  `json:"reason"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(result.Text), &out); err != nil {
+		return result.Usage, fmt.Errorf("failed to decode judge scores: %w", err)
+	}
+	for _, s := range out.Scores {
+		if s.ID < 1 || s.ID > len(entries) || entries[s.ID-1].Err != nil {
+			continue
+		}
+		score := s.Score
+		entries[s.ID-1].Score = &score
+		entries[s.ID-1].ScoreReason = s.Reason
+	}
+	return result.Usage, nil
+}
+
+var judgeScoresSchema = schema.NewSimpleJSONSchema(map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"scores": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id":     map[string]interface{}{"type": "integer"},
+					"score":  map[string]interface{}{"type": "number", "minimum": 0, "maximum": 10},
+					"reason": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"id", "score", "reason"},
+			},
+		},
+	},
+	"required": []string{"scores"},
+})
+
+// requestTranscript renders the text of a request for the judge
+func requestTranscript(req *GenerateTextOptions) string {
+	var b strings.Builder
+	if req.System != "" {
+		fmt.Fprintf(&b, "[system] %s\n", req.System)
+	}
+	for _, msg := range req.Messages {
+		for _, part := range msg.Content {
+			if text, ok := part.(types.TextContent); ok {
+				fmt.Fprintf(&b, "[%s] %s\n", msg.Role, text.Text)
+			}
+		}
+	}
+	if req.Prompt != "" {
+		b.WriteString(req.Prompt)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	answering := func(name, text string) *testutil.MockLanguageModel {
+		return &testutil.MockLanguageModel{
+			ProviderName: "openai",
+			ModelName:    name,
+			DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+				return &types.GenerateResult{
+					Text:         text,
+					FinishReason: types.FinishReasonStop,
+					Usage:        types.Usage{InputTokens: int64Ptr(100), OutputTokens: int64Ptr(10)},
+				}, nil
+			},
+		}
+	}
+	failing := &testutil.MockLanguageModel{
+		ProviderName: "openai",
+		ModelName:    "broken",
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+	var judgePrompt string
+	judge := &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			judgePrompt = opts.Prompt.Messages[len(opts.Prompt.Messages)-1].Content[0].(types.TextContent).Text
+			return &types.GenerateResult{
+				Text:         `{"scores":[{"id":1,"score":9,"reason":"correct"},{"id":2,"score":4,"reason":"vague"},{"id":3,"score":10,"reason":"invented"}]}`,
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{TotalTokens: int64Ptr(50)},
+			}, nil
+		},
+	}
+
+	result, err := Compare(context.Background(), CompareOptions{
+		Request: GenerateTextOptions{Prompt: "What is the capital of France?"},
+		Models:  []provider.LanguageModel{answering("gpt-4", "Paris."), answering("gpt-4o-mini", "A city in France."), failing},
+		Cost: func(model provider.LanguageModel, usage types.Usage) float64 {
+			return float64(*usage.InputTokens+*usage.OutputTokens) / 1000
+		},
+		Judge: &CompareJudge{Model: judge, Reference: "Paris"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entries) != 3 || result.JudgeErr != nil {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	best, worse, broken := result.Entries[0], result.Entries[1], result.Entries[2]
+	if best.Label() != "openai:gpt-4" || best.Result.Text != "Paris." || best.Cost != 0.11 {
+		t.Errorf("first entry = %+v", best)
+	}
+	if best.Score == nil || *best.Score != 9 || worse.Score == nil || *worse.Score != 4 || worse.ScoreReason != "vague" {
+		t.Errorf("scores = %v, %v", best.Score, worse.Score)
+	}
+	if broken.Err == nil || broken.Result != nil || broken.Score != nil {
+		t.Errorf("failed entry = %+v", broken)
+	}
+	if !strings.Contains(judgePrompt, "What is the capital of France?") || !strings.Contains(judgePrompt, "Reference answer:\nParis") ||
+		strings.Contains(judgePrompt, "gpt-4") || strings.Contains(judgePrompt, `"id":3`) {
+		t.Errorf("unexpected judge prompt %q", judgePrompt)
+	}
+
+	if _, err := Compare(context.Background(), CompareOptions{}); err == nil {
+		t.Error("expected an error without models")
+	}
+}
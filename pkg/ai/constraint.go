@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// resolveConstraint returns the constraint to forward to the provider, or nil
+// when the output must be validated after generation instead: the model
+// cannot enforce it natively, or tools are offered and a constrained decoder
+// would rule out tool calls.
+func resolveConstraint(model provider.LanguageModel, c *types.Constraint, tools []types.Tool, choice types.ToolChoice) (*types.Constraint, error) {
+	if c == nil {
+		return nil, nil
+	}
+	offersTools := len(tools) > 0 && choice.Type != types.ToolChoiceNone
+	if cm, ok := model.(provider.ConstrainedDecodingModel); ok && cm.SupportsConstraint(c.Kind) && !offersTools {
+		return c, nil
+	}
+	if !c.CanValidate() {
+		if offersTools {
+			return nil, fmt.Errorf("%s constraints cannot be combined with tools", c.Kind)
+		}
+		return nil, fmt.Errorf("model %s/%s does not support %s constraints", model.Provider(), model.ModelID(), c.Kind)
+	}
+	return nil, nil
+}
+
+// needsConstraintValidation reports whether output generated with native
+// must still be checked against c. JSON schemas are always checked: some
+// providers only enforce them best-effort, e.g. OpenAI for schemas strict
+// mode rejects.
+func needsConstraintValidation(c, native *types.Constraint) bool {
+	return c != nil && (native == nil || c.Kind == types.ConstraintJSONSchema)
+}
+
+// validateConstraint checks generated text against a constraint, including
+// structural validation of JSON output against the constraint's schema.
+func validateConstraint(c *types.Constraint, text string) error {
+	if err := c.Validate(text); err != nil {
+		return err
+	}
+	if c.Kind != types.ConstraintJSONSchema || c.Schema == nil {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return &types.ConstraintViolationError{Kind: c.Kind, Text: text, Message: err.Error()}
+	}
+	if err := schema.ValidateValue(c.Schema, value); err != nil {
+		return &types.ConstraintViolationError{Kind: c.Kind, Text: text, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// constrainedMockModel reports native support for every constraint kind
+type constrainedMockModel struct {
+	*testutil.MockLanguageModel
+}
+
+func (m constrainedMockModel) SupportsConstraint(kind types.ConstraintKind) bool { return true }
+
+func textModel(text string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+}
+
+func TestGenerateText_ConstraintNative(t *testing.T) {
+	t.Parallel()
+
+	mock := textModel("not a number")
+	model := constrainedMockModel{mock}
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      model,
+		Prompt:     "Pick a number",
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+	if err != nil {
+		t.Fatalf("native constraints should not be re-validated, got %v", err)
+	}
+	if mock.GenerateCalls[0].Constraint == nil {
+		t.Error("expected constraint to be forwarded to the provider")
+	}
+}
+
+func TestGenerateText_ConstraintFallbackValidation(t *testing.T) {
+	t.Parallel()
+
+	mock := textModel("forty-two")
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      mock,
+		Prompt:     "Pick a number",
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+
+	var violation *types.ConstraintViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected ConstraintViolationError, got %v", err)
+	}
+	if mock.GenerateCalls[0].Constraint != nil {
+		t.Error("unsupported constraints must not be forwarded to the provider")
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      textModel("42"),
+		Prompt:     "Pick a number",
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+	if err != nil || result.Text != "42" {
+		t.Fatalf("expected matching output to pass, got %v", err)
+	}
+}
+
+func TestGenerateText_ConstraintJSONSchemaFallback(t *testing.T) {
+	t.Parallel()
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:  textModel(`{"age": "old"}`),
+		Prompt: "Describe a person",
+		Constraint: types.JSONSchemaConstraint(map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"age": map[string]interface{}{"type": "integer"}},
+		}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "expected integer") {
+		t.Fatalf("expected schema violation, got %v", err)
+	}
+}
+
+func TestGenerateText_ConstraintJSONSchemaNativeValidated(t *testing.T) {
+	t.Parallel()
+
+	// Native JSON schema support can be best-effort, so output is still checked
+	mock := textModel(`{"age": "old"}`)
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:  constrainedMockModel{mock},
+		Prompt: "Describe a person",
+		Constraint: types.JSONSchemaConstraint(map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"age": map[string]interface{}{"type": "integer"}},
+		}),
+	})
+	var violation *types.ConstraintViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected ConstraintViolationError, got %v", err)
+	}
+	if mock.GenerateCalls[0].Constraint == nil {
+		t.Error("expected constraint to be forwarded to the provider")
+	}
+}
+
+func TestGenerateText_ConstraintNotForwardedWithTools(t *testing.T) {
+	t.Parallel()
+
+	// A constrained decoder cannot emit tool calls, so with tools offered the
+	// constraint is validated after generation instead
+	mock := textModel("forty-two")
+	tools := []types.Tool{{Name: "lookup", Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		return "ok", nil
+	}}}
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      constrainedMockModel{mock},
+		Prompt:     "Pick a number",
+		Tools:      tools,
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+	var violation *types.ConstraintViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected ConstraintViolationError, got %v", err)
+	}
+	if mock.GenerateCalls[0].Constraint != nil {
+		t.Error("constraints must not be forwarded on steps that offer tools")
+	}
+
+	_, err = GenerateText(context.Background(), GenerateTextOptions{
+		Model:      constrainedMockModel{textModel("yes")},
+		Prompt:     "Answer",
+		Tools:      tools,
+		Constraint: types.GrammarConstraint(`root ::= "yes" | "no"`),
+	})
+	if err == nil {
+		t.Error("expected error for a grammar constraint combined with tools")
+	}
+}
+
+func TestStreamText_Constraint(t *testing.T) {
+	t.Parallel()
+
+	streamModel := func(text string) *testutil.MockLanguageModel {
+		return &testutil.MockLanguageModel{
+			DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+				return testutil.NewMockTextStream([]provider.StreamChunk{
+					{Type: provider.ChunkTypeText, Text: text},
+					{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+				}), nil
+			},
+		}
+	}
+
+	mock := streamModel("forty-two")
+	result, err := StreamText(context.Background(), StreamTextOptions{
+		Model:      mock,
+		Prompt:     "Pick a number",
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var violation *types.ConstraintViolationError
+	if _, err := result.ReadAll(); !errors.As(err, &violation) {
+		t.Fatalf("expected ConstraintViolationError, got %v", err)
+	}
+	if mock.StreamCalls[0].Constraint != nil {
+		t.Error("unsupported constraints must not be forwarded to the provider")
+	}
+
+	native := streamModel("forty-two")
+	result, err = StreamText(context.Background(), StreamTextOptions{
+		Model:      constrainedMockModel{native},
+		Prompt:     "Pick a number",
+		Constraint: types.RegexConstraint(`\d+`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := result.ReadAll(); err != nil {
+		t.Errorf("native constraints should not be re-validated, got %v", err)
+	}
+	if native.StreamCalls[0].Constraint == nil {
+		t.Error("expected constraint to be forwarded to the provider")
+	}
+}
+
+func TestGenerateText_GrammarRequiresNativeSupport(t *testing.T) {
+	t.Parallel()
+
+	mock := textModel("yes")
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      mock,
+		Prompt:     "Answer",
+		Constraint: types.GrammarConstraint(`root ::= "yes" | "no"`),
+	})
+	if err == nil {
+		t.Fatal("expected error for unsupported grammar constraint")
+	}
+	if len(mock.GenerateCalls) != 0 {
+		t.Error("expected no provider call")
+	}
+}
@@ -0,0 +1,146 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ContinuationOptions configures automatic continuation of responses that
+// were cut off by the output token limit (FinishReason length)
+type ContinuationOptions struct {
+	// MaxContinuations caps the number of continuation requests per step
+	// Default: 3
+	MaxContinuations int
+
+	// Prompt is the user message asking the model to continue
+	// Default: DefaultContinuationPrompt
+	Prompt string
+}
+
+// DefaultContinuationPrompt asks the model to resume a truncated response
+const DefaultContinuationPrompt = "Your previous response was cut off. Continue exactly where it stopped, without repeating any text or adding commentary."
+
+// defaultMaxContinuations is the default continuation cap
+const defaultMaxContinuations = 3
+
+// minContinuationOverlap is the shortest repeated span removed when stitching.
+// Shorter matches are too likely to be coincidental.
+const minContinuationOverlap = 8
+
+// maxContinuationOverlap bounds the overlap search
+const maxContinuationOverlap = 1000
+
+// continueGeneration issues continuation requests while genResult finished
+// because of the length limit and returns the stitched result along with the
+// number of continuations made. Results containing tool calls are returned
+// unchanged.
+func continueGeneration(ctx context.Context, model provider.LanguageModel, genOpts *provider.GenerateOptions, genResult *types.GenerateResult, opts *ContinuationOptions) (*types.GenerateResult, int, error) {
+	if opts == nil || genResult.FinishReason != types.FinishReasonLength || len(genResult.ToolCalls) > 0 {
+		return genResult, 0, nil
+	}
+
+	maxContinuations := opts.MaxContinuations
+	if maxContinuations <= 0 {
+		maxContinuations = defaultMaxContinuations
+	}
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = DefaultContinuationPrompt
+	}
+	jsonOutput := genOpts.ResponseFormat != nil && genOpts.ResponseFormat.Type != "" && genOpts.ResponseFormat.Type != "text"
+
+	merged := *genResult
+	merged.Content = removeTextParts(genResult.Content)
+	var extraContent []types.ContentPart
+	merged.Warnings = append([]types.Warning(nil), genResult.Warnings...)
+
+	count := 0
+	for merged.FinishReason == types.FinishReasonLength && count < maxContinuations {
+		count++
+
+		contOpts := *genOpts
+		messages := make([]types.Message, 0, len(genOpts.Prompt.Messages)+2)
+		messages = append(messages, genOpts.Prompt.Messages...)
+		messages = append(messages,
+			types.Message{
+				Role:    types.RoleAssistant,
+				Content: []types.ContentPart{types.TextContent{Text: merged.Text}},
+			},
+			types.Message{
+				Role:    types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: prompt}},
+			},
+		)
+		contOpts.Prompt.Messages = messages
+
+		next, err := model.DoGenerate(ctx, &contOpts)
+		if err != nil {
+			return nil, count, fmt.Errorf("continuation %d failed: %w", count, err)
+		}
+
+		merged.Text = stitchContinuation(merged.Text, next.Text, jsonOutput)
+		merged.FinishReason = next.FinishReason
+		merged.Usage = merged.Usage.Add(next.Usage)
+		merged.Warnings = append(merged.Warnings, next.Warnings...)
+		merged.ToolCalls = next.ToolCalls
+		merged.RawRequest = next.RawRequest
+		merged.RawResponse = next.RawResponse
+		merged.ProviderMetadata = next.ProviderMetadata
+		extraContent = append(extraContent, removeTextParts(next.Content)...)
+		if len(next.ToolCalls) > 0 {
+			break
+		}
+	}
+
+	// Content holds the stitched text as a single part
+	merged.Content = append(merged.Content, types.TextContent{Text: merged.Text})
+	merged.Content = append(merged.Content, extraContent...)
+
+	return &merged, count, nil
+}
+
+// removeTextParts returns parts without text content
+func removeTextParts(parts []types.ContentPart) []types.ContentPart {
+	var kept []types.ContentPart
+	for _, part := range parts {
+		if _, ok := part.(types.TextContent); !ok {
+			kept = append(kept, part)
+		}
+	}
+	return kept
+}
+
+// stitchContinuation appends next to prev, dropping text the model repeated
+// from the end of prev. For JSON output, markdown fences the model wrapped
+// around either part are removed so the halves join into one document.
+func stitchContinuation(prev, next string, jsonOutput bool) string {
+	if jsonOutput {
+		if trimmed := strings.TrimRight(prev, " \t\r\n"); strings.HasSuffix(trimmed, "```") {
+			prev = strings.TrimSuffix(trimmed, "```")
+		}
+		if trimmed := strings.TrimLeft(next, " \t\r\n"); strings.HasPrefix(trimmed, "```") {
+			next = ""
+			if nl := strings.IndexByte(trimmed, '\n'); nl >= 0 {
+				next = trimmed[nl+1:]
+			}
+		}
+	}
+
+	limit := len(prev)
+	if len(next) < limit {
+		limit = len(next)
+	}
+	if limit > maxContinuationOverlap {
+		limit = maxContinuationOverlap
+	}
+	for n := limit; n >= minContinuationOverlap; n-- {
+		if strings.HasSuffix(prev, next[:n]) {
+			return prev + next[n:]
+		}
+	}
+	return prev + next
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestStitchContinuation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		prev string
+		next string
+		json bool
+		want string
+	}{
+		{"plain join", "The quick brown fo", "x jumps.", false, "The quick brown fox jumps."},
+		{"repeated overlap", "Chapter one ends here and", "ends here and chapter two begins.", false, "Chapter one ends here and chapter two begins."},
+		{"short overlap kept", "it is", "is fine", false, "it isis fine"},
+		{"json fences", "```json\n{\"items\": [1, 2,", "```json\n 3]}\n```", true, "```json\n{\"items\": [1, 2, 3]}\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := stitchContinuation(tt.prev, tt.next, tt.json); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateText_Continuation(t *testing.T) {
+	t.Parallel()
+
+	parts := []string{`{"words": ["alpha", `, `"beta", `, `"gamma"]}`}
+	calls := 0
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			n := calls
+			calls++
+			finish := types.FinishReasonLength
+			if n == len(parts)-1 {
+				finish = types.FinishReasonStop
+			}
+			tokens := int64(10)
+			return &types.GenerateResult{
+				Text:         parts[n],
+				FinishReason: finish,
+				Usage:        types.Usage{OutputTokens: &tokens},
+			}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:          model,
+		Prompt:         "List three words as JSON",
+		ResponseFormat: &provider.ResponseFormat{Type: "json"},
+		Continuation:   &ContinuationOptions{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !json.Valid([]byte(result.Text)) {
+		t.Fatalf("expected stitched JSON, got %q", result.Text)
+	}
+	if result.FinishReason != types.FinishReasonStop {
+		t.Errorf("expected stop, got %s", result.FinishReason)
+	}
+	if result.Continuations != 2 {
+		t.Errorf("expected 2 continuations, got %d", result.Continuations)
+	}
+	if result.Usage.OutputTokens == nil || *result.Usage.OutputTokens != 30 {
+		t.Errorf("expected usage across continuations, got %v", result.Usage.OutputTokens)
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Text != result.Text {
+		t.Errorf("expected a single step with the stitched text")
+	}
+
+	last := model.GenerateCalls[2].Prompt.Messages
+	if got := last[len(last)-1].Content[0].(types.TextContent).Text; got != DefaultContinuationPrompt {
+		t.Errorf("unexpected continuation prompt %q", got)
+	}
+	if got := last[len(last)-2].Content[0].(types.TextContent).Text; !strings.HasSuffix(got, `"beta", `) {
+		t.Errorf("expected accumulated text in assistant message, got %q", got)
+	}
+}
+
+func TestGenerateText_ContinuationCap(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: "more ", FinishReason: types.FinishReasonLength}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:        model,
+		Prompt:       "Write forever",
+		Continuation: &ContinuationOptions{MaxContinuations: 2},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(model.GenerateCalls) != 3 {
+		t.Errorf("expected 3 calls, got %d", len(model.GenerateCalls))
+	}
+	if result.FinishReason != types.FinishReasonLength {
+		t.Errorf("expected length finish once the cap is hit, got %s", result.FinishReason)
+	}
+	if result.Text != "more more more " {
+		t.Errorf("unexpected text %q", result.Text)
+	}
+}
+
+func TestGenerateText_NoContinuationByDefault(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: "cut", FinishReason: types.FinishReasonLength}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{Model: model, Prompt: "Hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(model.GenerateCalls) != 1 || result.Continuations != 0 {
+		t.Errorf("expected no continuation, got %d calls", len(model.GenerateCalls))
+	}
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DefaultSettings are applied to every call made through a model wrapped with
+// WithDefaults. Anything set on an individual call takes precedence;
+// Headers, Metadata and per-provider ProviderOptions are merged key by key.
+type DefaultSettings struct {
+	Temperature      *float64
+	MaxTokens        *int
+	TopP             *float64
+	TopK             *int
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	StopSequences    []string
+	Seed             *int
+	Reasoning        *types.ReasoningLevel
+
+	// System is used when a call has no system prompt or system message of
+	// its own
+	System string
+
+	Headers         map[string]string
+	Metadata        map[string]string
+	ProviderOptions map[string]interface{}
+}
+
+// WithDefaults wraps model so every GenerateText, StreamText, GenerateObject
+// or agent call made with it starts from defaults. The wrapper keeps model's
+// native constraint support, model listing and health checks.
+//
+// Example:
+//
+//	model := ai.WithDefaults(openai.NewLanguageModel(p, "gpt-4o"), ai.DefaultSettings{
+//		Temperature: &temp,
+//		MaxTokens:   &maxTokens,
+//		System:      "You are the Acme support assistant.",
+//	})
+func WithDefaults(model provider.LanguageModel, defaults DefaultSettings) provider.LanguageModel {
+	opts := &provider.GenerateOptions{
+		Prompt:           types.Prompt{System: defaults.System},
+		Temperature:      defaults.Temperature,
+		MaxTokens:        defaults.MaxTokens,
+		TopP:             defaults.TopP,
+		TopK:             defaults.TopK,
+		PresencePenalty:  defaults.PresencePenalty,
+		FrequencyPenalty: defaults.FrequencyPenalty,
+		StopSequences:    defaults.StopSequences,
+		Seed:             defaults.Seed,
+		Reasoning:        defaults.Reasoning,
+		Headers:          defaults.Headers,
+		Metadata:         defaults.Metadata,
+		ProviderOptions:  defaults.ProviderOptions,
+	}
+	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{
+		middleware.DefaultSettingsMiddleware(opts),
+	}, nil, nil)
+	return &defaultsModel{LanguageModel: wrapped, base: model}
+}
+
+// defaultsModel forwards the optional interfaces of the model WithDefaults
+// wrapped
+type defaultsModel struct {
+	provider.LanguageModel
+	base provider.LanguageModel
+}
+
+// SupportsConstraint reports whether the wrapped model enforces constraints
+// of the given kind natively
+func (m *defaultsModel) SupportsConstraint(kind types.ConstraintKind) bool {
+	cm, ok := m.base.(provider.ConstrainedDecodingModel)
+	return ok && cm.SupportsConstraint(kind)
+}
+
+// ListModels forwards model listing to the wrapped model
+func (m *defaultsModel) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	if lister, ok := m.base.(provider.ModelLister); ok {
+		return lister.ListModels(ctx)
+	}
+	return nil, provider.ErrListModelsNotSupported
+}
+
+// Ping forwards health checks to the wrapped model
+func (m *defaultsModel) Ping(ctx context.Context) (*provider.PingResult, error) {
+	if pinger, ok := m.base.(provider.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil, provider.ErrPingNotSupported
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	var received *provider.GenerateOptions
+	base := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			received = opts
+			return &types.GenerateResult{Text: "ok", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	temp, maxTokens := 0.2, 500
+	model := WithDefaults(base, DefaultSettings{
+		Temperature: &temp,
+		MaxTokens:   &maxTokens,
+		System:      "You are helpful.",
+		ProviderOptions: map[string]interface{}{
+			"openai": map[string]interface{}{"user": "svc", "store": false},
+		},
+	})
+
+	callTemp := 0.9
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:       model,
+		Prompt:      "hi",
+		Temperature: &callTemp,
+		ProviderOptions: map[string]interface{}{
+			"openai": map[string]interface{}{"store": true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *received.Temperature != 0.9 {
+		t.Errorf("expected call temperature to win, got %v", *received.Temperature)
+	}
+	if received.MaxTokens == nil || *received.MaxTokens != 500 {
+		t.Errorf("expected default max tokens, got %v", received.MaxTokens)
+	}
+	if received.Prompt.System != "You are helpful." {
+		t.Errorf("expected default system prompt, got %q", received.Prompt.System)
+	}
+	if len(received.Prompt.Messages) == 0 && received.Prompt.Text == "" {
+		t.Error("expected the call's prompt to be preserved")
+	}
+	openai := received.ProviderOptions["openai"].(map[string]interface{})
+	if openai["user"] != "svc" || openai["store"] != true {
+		t.Errorf("expected merged provider options, got %v", openai)
+	}
+}
+
+func TestWithDefaults_SystemMessageReplacesDefault(t *testing.T) {
+	t.Parallel()
+
+	var received *provider.GenerateOptions
+	base := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			received = opts
+			return &types.GenerateResult{Text: "ok", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	model := WithDefaults(base, DefaultSettings{System: "You are helpful."})
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model: model,
+		Messages: []types.Message{
+			{Role: types.RoleSystem, Content: []types.ContentPart{types.TextContent{Text: "Answer in French."}}},
+			{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "hi"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Prompt.System != "" {
+		t.Errorf("expected the caller's system message to replace the default, got %q", received.Prompt.System)
+	}
+}
+
+type pingingMockModel struct {
+	constrainedMockModel
+}
+
+func (m pingingMockModel) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return &provider.PingResult{}, nil
+}
+
+func TestWithDefaults_ForwardsOptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	model := WithDefaults(pingingMockModel{constrainedMockModel{&testutil.MockLanguageModel{}}}, DefaultSettings{})
+
+	cm, ok := model.(provider.ConstrainedDecodingModel)
+	if !ok || !cm.SupportsConstraint(types.ConstraintRegex) {
+		t.Error("expected constraint support to be forwarded")
+	}
+	if _, err := model.(provider.Pinger).Ping(context.Background()); err != nil {
+		t.Errorf("expected ping to be forwarded, got %v", err)
+	}
+	if _, err := model.(provider.ModelLister).ListModels(context.Background()); err != provider.ErrListModelsNotSupported {
+		t.Errorf("expected ErrListModelsNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DryRunNotExecuted is the tool result recorded for calls a dry run
+// without a Stub did not execute
+const DryRunNotExecuted = "not executed: dry run"
+
+// DryRunOptions turns a generation into a preview of what it would do:
+// tool Execute functions are not invoked, and the calls the model makes
+// are returned as intended calls instead.
+//
+// Without a Stub the loop stops after the first step that calls a tool.
+// With one, each call gets the stub's result and the loop carries on, so
+// the whole plan can be previewed.
+//
+// Example:
+//
+//	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+//		Model:    model,
+//		Prompt:   "Refund order 1042 and email the customer",
+//		Tools:    []types.Tool{lookupOrder, refund, sendEmail},
+//		StopWhen: []ai.StopCondition{ai.StepCountIs(10)},
+//		DryRun: &ai.DryRunOptions{
+//			Allow: []string{"lookupOrder"},
+//			Stub:  ai.StubResult(map[string]any{"ok": true}),
+//		},
+//	})
+//	for _, call := range result.DryRunCalls {
+//		fmt.Println(call.ToolName, call.Arguments)
+//	}
+type DryRunOptions struct {
+	// Stub returns the result fed back to the model for an intended call.
+	// Nil stops the loop at the first step that calls a tool.
+	Stub func(ctx context.Context, call types.ToolCall) (interface{}, error)
+
+	// Allow lists tools that still execute, e.g. read-only lookups the
+	// model needs to plan
+	Allow []string
+}
+
+// StubResult returns a Stub that answers every call with result
+func StubResult(result interface{}) func(ctx context.Context, call types.ToolCall) (interface{}, error) {
+	return func(ctx context.Context, call types.ToolCall) (interface{}, error) {
+		return result, nil
+	}
+}
+
+// allowed reports whether a tool still executes during the dry run
+func (o *DryRunOptions) allowed(name string) bool {
+	if o == nil {
+		return true
+	}
+	for _, allowed := range o.Allow {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Executor returns the function that runs tool: its own Execute for
+// allowed tools and when o is nil, otherwise the stub
+func (o *DryRunOptions) Executor(tool types.Tool) types.ToolExecutor {
+	if o.allowed(tool.Name) {
+		return tool.Execute
+	}
+	return func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		if o.Stub == nil {
+			return DryRunNotExecuted, nil
+		}
+		return o.Stub(ctx, types.ToolCall{ID: opts.ToolCallID, ToolName: tool.Name, Arguments: args})
+	}
+}
+
+// StopCondition returns the condition that ends a dry run without a Stub
+// after the first step with an intended call. It never stops when o is nil
+// or has a Stub.
+func (o *DryRunOptions) StopCondition() StopCondition {
+	return func(state StopConditionState) string {
+		if o == nil || o.Stub != nil || len(state.Steps) == 0 {
+			return ""
+		}
+		for _, result := range state.Steps[len(state.Steps)-1].ToolResults {
+			if !result.ProviderExecuted && !o.allowed(result.ToolName) {
+				return "dry run: tool calls recorded"
+			}
+		}
+		return ""
+	}
+}
+
+// IntendedCalls returns the calls in steps that the dry run did not
+// execute, in order
+func (o *DryRunOptions) IntendedCalls(steps []types.StepResult, tools []types.Tool) []types.ToolCall {
+	if o == nil {
+		return nil
+	}
+	local := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		local[tool.Name] = !tool.ProviderExecuted
+	}
+	var calls []types.ToolCall
+	for _, step := range steps {
+		for _, call := range step.ToolCalls {
+			if local[call.ToolName] && !o.allowed(call.ToolName) {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestGenerateText_DryRun(t *testing.T) {
+	t.Parallel()
+
+	// The model looks the order up, refunds it, then answers
+	newModel := func() *testutil.MockLanguageModel {
+		step := 0
+		return &testutil.MockLanguageModel{
+			DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+				step++
+				switch step {
+				case 1:
+					return &types.GenerateResult{FinishReason: types.FinishReasonToolCalls, ToolCalls: []types.ToolCall{
+						{ID: "c1", ToolName: "lookupOrder", Arguments: map[string]interface{}{"id": "1042"}},
+					}}, nil
+				case 2:
+					return &types.GenerateResult{FinishReason: types.FinishReasonToolCalls, ToolCalls: []types.ToolCall{
+						{ID: "c2", ToolName: "refund", Arguments: map[string]interface{}{"id": "1042", "amount": 30.0}},
+					}}, nil
+				default:
+					return &types.GenerateResult{Text: "Refunded.", FinishReason: types.FinishReasonStop}, nil
+				}
+			},
+		}
+	}
+	var executed []string
+	tools := []types.Tool{
+		{Name: "lookupOrder", Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			executed = append(executed, "lookupOrder")
+			return map[string]interface{}{"total": 30.0}, nil
+		}},
+		{Name: "refund", Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			executed = append(executed, "refund")
+			return "refunded", nil
+		}},
+	}
+
+	// Without a stub the loop stops at the first intended call
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    newModel(),
+		Prompt:   "Refund order 1042",
+		Tools:    tools,
+		StopWhen: []StopCondition{StepCountIs(10)},
+		DryRun:   &DryRunOptions{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executed) != 0 || len(result.Steps) != 1 || result.StopReason == "" {
+		t.Errorf("executed %v in %d steps (stop reason %q)", executed, len(result.Steps), result.StopReason)
+	}
+	if len(result.DryRunCalls) != 1 || result.DryRunCalls[0].ToolName != "lookupOrder" {
+		t.Errorf("DryRunCalls = %+v", result.DryRunCalls)
+	}
+
+	// Allowed tools run; the others get stub results and the loop continues
+	result, err = GenerateText(context.Background(), GenerateTextOptions{
+		Model:    newModel(),
+		Prompt:   "Refund order 1042",
+		Tools:    tools,
+		StopWhen: []StopCondition{StepCountIs(10)},
+		DryRun:   &DryRunOptions{Allow: []string{"lookupOrder"}, Stub: StubResult("ok")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executed) != 1 || executed[0] != "lookupOrder" || result.Text != "Refunded." {
+		t.Errorf("executed %v, text %q", executed, result.Text)
+	}
+	if len(result.DryRunCalls) != 1 || result.DryRunCalls[0].ID != "c2" || result.DryRunCalls[0].Arguments["amount"] != 30.0 {
+		t.Errorf("DryRunCalls = %+v", result.DryRunCalls)
+	}
+	if refund := result.ToolResults[1]; refund.Result != "ok" {
+		t.Errorf("refund result = %v, want the stub", refund.Result)
+	}
+}
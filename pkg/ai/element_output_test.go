@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestCompleteArrayElements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		text       string
+		wantCount  int
+		wantClosed bool
+	}{
+		{"empty", ``, 0, false},
+		{"array open", `{"elements":[`, 0, false},
+		{"partial object", `{"elements":[{"a":1},{"a":`, 1, false},
+		{"object at end", `{"elements":[{"a":1}`, 0, false},
+		{"number may grow", `{"elements":[1, 2`, 1, false},
+		{"number terminated", `{"elements":[1, 2,`, 2, false},
+		{"closed", `{"elements":[1, 2]`, 2, true},
+		{"other keys first", `{"note":"x","elements":["a"]}`, 1, true},
+		{"not an object", `["a"]`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, closed := completeArrayElements(tt.text)
+			if len(got) != tt.wantCount || closed != tt.wantClosed {
+				t.Errorf("got %d elements (closed=%v), want %d (closed=%v)", len(got), closed, tt.wantCount, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func elementOutputModel(chunks ...string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			var streamChunks []provider.StreamChunk
+			for _, c := range chunks {
+				streamChunks = append(streamChunks, provider.StreamChunk{Type: provider.ChunkTypeText, Text: c})
+			}
+			streamChunks = append(streamChunks, provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop})
+			return testutil.NewMockTextStream(streamChunks), nil
+		},
+	}
+}
+
+func TestArrayOutput_OnElement(t *testing.T) {
+	t.Parallel()
+
+	model := elementOutputModel(
+		`{"elements":[{"title":"Task 1","priority":1}`,
+		`,{"title":"Task 2","pri`,
+		`ority":2}`,
+		`]}`,
+	)
+
+	var got []ElementStreamResult[TodoItem]
+	output := ArrayOutput[TodoItem](ArrayOutputOptions[TodoItem]{
+		ElementSchema: schema.NewSimpleJSONSchema(map[string]interface{}{"type": "object"}),
+		OnElement: func(ctx context.Context, elem ElementStreamResult[TodoItem]) error {
+			got = append(got, elem)
+			return nil
+		},
+	})
+
+	result, err := StreamText(context.Background(), StreamTextOptions{
+		Model:  model,
+		Prompt: "List tasks",
+		Output: output,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := result.ReadAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 elements, got %d", len(got))
+	}
+	if got[0].Element.Title != "Task 1" || got[0].Index != 0 || got[0].IsFinal {
+		t.Errorf("unexpected first element: %+v", got[0])
+	}
+	if got[1].Element.Title != "Task 2" || got[1].Element.Priority != 2 || got[1].Index != 1 || !got[1].IsFinal {
+		t.Errorf("unexpected second element: %+v", got[1])
+	}
+}
+
+func TestArrayOutput_OnElementStopsEarly(t *testing.T) {
+	t.Parallel()
+
+	model := elementOutputModel(
+		`{"elements":[{"title":"A"},`,
+		`{"title":"B"},`,
+		`{"title":"C"}]}`,
+	)
+
+	var titles []string
+	output := ArrayOutput[TodoItem](ArrayOutputOptions[TodoItem]{
+		ElementSchema: schema.NewSimpleJSONSchema(map[string]interface{}{"type": "object"}),
+		OnElement: func(ctx context.Context, elem ElementStreamResult[TodoItem]) error {
+			titles = append(titles, elem.Element.Title)
+			return ErrStopElementStream
+		},
+	})
+
+	result, err := StreamText(context.Background(), StreamTextOptions{Model: model, Prompt: "x", Output: output})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, err := result.ReadAll()
+	if err != nil {
+		t.Fatalf("expected clean stop, got %v", err)
+	}
+	if len(titles) != 1 || titles[0] != "A" {
+		t.Errorf("expected only the first element, got %v", titles)
+	}
+	if text != `{"elements":[{"title":"A"},` {
+		t.Errorf("expected reading to stop at the first element, got %q", text)
+	}
+}
+
+func TestArrayOutput_OnElementError(t *testing.T) {
+	t.Parallel()
+
+	model := elementOutputModel(`{"elements":[{"title":"A"},{"title":"B"}]}`)
+	boom := errors.New("render failed")
+
+	output := ArrayOutput[TodoItem](ArrayOutputOptions[TodoItem]{
+		ElementSchema: schema.NewSimpleJSONSchema(map[string]interface{}{"type": "object"}),
+		OnElement: func(ctx context.Context, elem ElementStreamResult[TodoItem]) error {
+			return boom
+		},
+	})
+
+	result, err := StreamText(context.Background(), StreamTextOptions{Model: model, Prompt: "x", Output: output})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := result.ReadAll(); !errors.Is(err, boom) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+}
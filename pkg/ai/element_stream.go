@@ -3,7 +3,9 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/jsonutil"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -22,6 +24,11 @@ type ElementStreamResult[ELEMENT any] struct {
 	IsFinal bool
 }
 
+// ErrStopElementStream can be returned from an ArrayOutput OnElement callback
+// to stop generation once enough elements have been received. The stream
+// ends without an error.
+var ErrStopElementStream = errors.New("element stream stopped")
+
 // ElementStreamOptions contains options for element streaming
 type ElementStreamOptions[ELEMENT any] struct {
 	// ElementSchema defines the structure of each array element
@@ -97,12 +104,13 @@ func ElementStream[ELEMENT any](result *StreamTextResult, opts ElementStreamOpti
 				}
 
 				// Emit any new elements
+				_, closed := completeArrayElements(lastText)
 				if len(elements) > lastElementCount {
 					for i := lastElementCount; i < len(elements); i++ {
 						elemResult := ElementStreamResult[ELEMENT]{
 							Element: elements[i],
 							Index:   i,
-							IsFinal: false,
+							IsFinal: closed && i == len(elements)-1,
 						}
 
 						ch <- elemResult
@@ -152,17 +160,12 @@ func parsePartialArrayElements[ELEMENT any](text string, elementSchema schema.Sc
 		return nil, fmt.Errorf("elements is not an array")
 	}
 
-	// Parse each complete element
+	// Parse each complete element; the last one may still be streaming
+	complete, _ := completeArrayElements(text)
 	var elements []ELEMENT
 	for i, elemRaw := range elementsArray {
-		// Skip last element if it might be incomplete
-		// (This is a heuristic - we could be more sophisticated here)
-		if i == len(elementsArray)-1 {
-			// Check if this element looks complete by trying to validate
-			if err := elementSchema.Validator().Validate(elemRaw); err != nil {
-				// Last element is incomplete, skip it
-				break
-			}
+		if i >= len(complete) {
+			break
 		}
 
 		// Validate element
@@ -267,3 +270,108 @@ func ElementStreamWithOutput[ELEMENT any](result *StreamTextResult, output Outpu
 
 	return ch
 }
+
+// elementEmitter is implemented by outputs that report array elements to a
+// callback as they complete during streaming
+type elementEmitter interface {
+	// emitElements reports the complete elements of text starting at index
+	// from and returns the index of the next element to report
+	emitElements(ctx context.Context, text string, from int) (int, error)
+}
+
+func (o *arrayOutput[ELEMENT]) emitElements(ctx context.Context, text string, from int) (int, error) {
+	if o.onElement == nil {
+		return from, nil
+	}
+
+	complete, closed := completeArrayElements(text)
+	for i := from; i < len(complete); i++ {
+		var raw interface{}
+		if err := json.Unmarshal(complete[i], &raw); err != nil {
+			continue
+		}
+		if err := o.elementSchema.Validator().Validate(raw); err != nil {
+			continue
+		}
+		var elem ELEMENT
+		if err := json.Unmarshal(complete[i], &elem); err != nil {
+			continue
+		}
+
+		if err := o.onElement(ctx, ElementStreamResult[ELEMENT]{
+			Element: elem,
+			Index:   i,
+			IsFinal: closed && i == len(complete)-1,
+		}); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(complete), nil
+}
+
+// completeArrayElements returns the elements of the "elements" array in a
+// possibly truncated ArrayOutput response that have been fully received, and
+// whether the array itself has been closed. An element counts as complete
+// once the separator or closing bracket after it has arrived.
+func completeArrayElements(text string) ([]json.RawMessage, bool) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		if key != "elements" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, false
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil, false
+		}
+		var elements []json.RawMessage
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return elements, false
+			}
+			// Wait for the separator or bracket: until then a number may
+			// still be growing and it is unknown whether this is the last
+			if int(dec.InputOffset()) >= len(strings.TrimRight(text, " \t\r\n")) {
+				return elements, false
+			}
+			elements = append(elements, raw)
+		}
+		tok, err := dec.Token()
+		return elements, err == nil && tok == json.Delim(']')
+	}
+	return nil, false
+}
+
+// emitOutputElements reports newly completed array elements to the output's
+// OnElement callback
+func (r *StreamTextResult) emitOutputElements(ctx context.Context) error {
+	emitter, ok := r.outputSpec.(elementEmitter)
+	if !ok {
+		return nil
+	}
+	next, err := emitter.emitElements(ctx, r.text, r.elementsEmitted)
+	r.elementsEmitted = next
+	return err
+}
+
+// stopForElements ends the stream after an OnElement callback returned err.
+// ErrStopElementStream stops cleanly; any other error is recorded.
+func (r *StreamTextResult) stopForElements(err error) {
+	_ = r.stream.Close()
+	if !errors.Is(err, ErrStopElementStream) {
+		r.err = err
+	}
+	r.elementsStopped = true
+}
@@ -0,0 +1,421 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// ReconcileStrategy selects how EnsembleExtract settles fields the models
+// disagree on
+type ReconcileStrategy string
+
+const (
+	// ReconcileMajority picks the value most models returned; ties go to
+	// the earliest model
+	ReconcileMajority ReconcileStrategy = "majority"
+
+	// ReconcileWeighted picks the value with the highest total weight,
+	// using EnsembleExtractOptions.Weights
+	ReconcileWeighted ReconcileStrategy = "weighted"
+
+	// ReconcileJudge asks a judge model to choose between the candidates,
+	// falling back to majority if the judge fails
+	ReconcileJudge ReconcileStrategy = "judge"
+)
+
+// EnsembleExtractOptions configures EnsembleExtract
+type EnsembleExtractOptions struct {
+	// Request is the extraction sent to every model. Its Model is ignored
+	// and its OutputMode must be object mode (the default).
+	Request GenerateObjectOptions
+
+	// Models extract the object concurrently; at least two are required
+	Models []provider.LanguageModel
+
+	// Strategy settles disagreements (default: ReconcileMajority)
+	Strategy ReconcileStrategy
+
+	// Weights are the models' votes for ReconcileWeighted, in the order of
+	// Models, e.g. from each model's past accuracy. Default: 1 each.
+	Weights []float64
+
+	// Judge arbitrates disagreements for ReconcileJudge
+	Judge provider.LanguageModel
+}
+
+// FieldCandidate is one value proposed for a field
+type FieldCandidate struct {
+	// Value proposed
+	Value interface{}
+
+	// Models that proposed the value, as indexes into Models
+	Models []int
+
+	// Weight is the total weight of those models
+	Weight float64
+}
+
+// FieldProvenance records how one field of a reconciled object was decided
+type FieldProvenance struct {
+	// Path is the field's JSON pointer, e.g. "/vendor/name". Objects are
+	// reconciled field by field; arrays and scalars as a whole.
+	Path string
+
+	// Value chosen
+	Value interface{}
+
+	// Models that returned the chosen value, as indexes into Models
+	Models []int
+
+	// Agreement is the share of the successful models' weight behind the
+	// chosen value, from 0 to 1
+	Agreement float64
+
+	// Candidates lists every distinct value, most weight first; more than
+	// one means the models disagreed
+	Candidates []FieldCandidate
+
+	// Reason is the judge's explanation when it decided the field
+	Reason string
+}
+
+// Disputed reports whether the models returned different values
+func (f FieldProvenance) Disputed() bool {
+	return len(f.Candidates) > 1
+}
+
+// EnsembleModelResult is one model's extraction
+type EnsembleModelResult struct {
+	Model  provider.LanguageModel
+	Result *GenerateObjectResult
+	Err    error
+}
+
+// EnsembleExtractResult is a reconciled extraction
+type EnsembleExtractResult struct {
+	// Object is the reconciled object
+	Object map[string]interface{}
+
+	// Fields records the decision for each field, ordered by path
+	Fields []FieldProvenance
+
+	// Results holds each model's extraction, in the order of Models
+	Results []EnsembleModelResult
+
+	// Usage is the total usage of the models and the judge
+	Usage types.Usage
+
+	// JudgeErr is why the judge failed; majority was used instead
+	JudgeErr error
+}
+
+// Field returns the provenance of the field at path
+func (r *EnsembleExtractResult) Field(path string) (FieldProvenance, bool) {
+	for _, f := range r.Fields {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return FieldProvenance{}, false
+}
+
+// Disputed returns the fields the models disagreed on
+func (r *EnsembleExtractResult) Disputed() []FieldProvenance {
+	var out []FieldProvenance
+	for _, f := range r.Fields {
+		if f.Disputed() {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// EnsembleExtract runs the same extraction on several models and reconciles
+// their objects field by field, recording which models support each value.
+// Use it where a wrong field is costly, such as invoices: fields with low
+// Agreement can be routed to a person. Models that fail are left out of the
+// vote; at least one must succeed. The reconciled object is validated
+// against the request schema; on failure the result is returned with the
+// error.
+//
+// Example:
+//
+//	result, err := ai.EnsembleExtract(ctx, ai.EnsembleExtractOptions{
+//		Request:  ai.GenerateObjectOptions{Prompt: invoiceText, Schema: ai.SchemaFor[Invoice]()},
+//		Models:   []provider.LanguageModel{gpt, claude, gemini},
+//		Strategy: ai.ReconcileJudge,
+//		Judge:    claude,
+//	})
+//	for _, f := range result.Disputed() {
+//		log.Printf("%s: chose %v (%.0f%% agreement) %s", f.Path, f.Value, f.Agreement*100, f.Reason)
+//	}
+func EnsembleExtract(ctx context.Context, opts EnsembleExtractOptions) (*EnsembleExtractResult, error) {
+	if len(opts.Models) < 2 {
+		return nil, fmt.Errorf("at least two models are required")
+	}
+	if opts.Request.OutputMode != "" && opts.Request.OutputMode != ObjectModeObject {
+		return nil, fmt.Errorf("ensemble extraction requires object output mode")
+	}
+	if opts.Request.Schema == nil {
+		return nil, fmt.Errorf("schema is required")
+	}
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = ReconcileMajority
+	}
+	switch strategy {
+	case ReconcileMajority:
+	case ReconcileWeighted:
+		if opts.Weights != nil && len(opts.Weights) != len(opts.Models) {
+			return nil, fmt.Errorf("got %d weights for %d models", len(opts.Weights), len(opts.Models))
+		}
+	case ReconcileJudge:
+		if opts.Judge == nil {
+			return nil, fmt.Errorf("judge model is required")
+		}
+	default:
+		return nil, fmt.Errorf("unknown reconcile strategy %q", strategy)
+	}
+
+	result := &EnsembleExtractResult{Results: make([]EnsembleModelResult, len(opts.Models))}
+	var wg sync.WaitGroup
+	for i, model := range opts.Models {
+		if model == nil {
+			return nil, fmt.Errorf("model %d is nil", i)
+		}
+		wg.Add(1)
+		go func(entry *EnsembleModelResult, model provider.LanguageModel) {
+			defer wg.Done()
+			req := opts.Request
+			req.Model = model
+			entry.Model = model
+			entry.Result, entry.Err = GenerateObject(ctx, req)
+		}(&result.Results[i], model)
+	}
+	wg.Wait()
+
+	weights := make([]float64, len(opts.Models))
+	var total float64
+	var objects []map[string]interface{}
+	var voters []int
+	var firstErr error
+	for i, r := range result.Results {
+		weights[i] = 1
+		if strategy == ReconcileWeighted && opts.Weights != nil {
+			weights[i] = opts.Weights[i]
+		}
+		if r.Err != nil {
+			if firstErr == nil {
+				firstErr = r.Err
+			}
+			continue
+		}
+		result.Usage = result.Usage.Add(r.Result.Usage)
+		obj, ok := r.Result.Object.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		objects = append(objects, obj)
+		voters = append(voters, i)
+		total += weights[i]
+	}
+	if len(objects) == 0 {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("no model returned an object")
+		}
+		return nil, fmt.Errorf("every model failed: %w", firstErr)
+	}
+
+	// Collect each field's candidates across the models
+	fields := make(map[string]*FieldProvenance)
+	for n, obj := range objects {
+		model := voters[n]
+		collectLeaves(obj, "", func(path string, value interface{}) {
+			f := fields[path]
+			if f == nil {
+				f = &FieldProvenance{Path: path}
+				fields[path] = f
+			}
+			key := canonicalJSON(value)
+			for i := range f.Candidates {
+				if canonicalJSON(f.Candidates[i].Value) == key {
+					f.Candidates[i].Models = append(f.Candidates[i].Models, model)
+					f.Candidates[i].Weight += weights[model]
+					return
+				}
+			}
+			f.Candidates = append(f.Candidates, FieldCandidate{Value: value, Models: []int{model}, Weight: weights[model]})
+		})
+	}
+	for _, f := range fields {
+		// Stable: candidates were appended in model order, so ties keep
+		// the earliest model's value first
+		sort.SliceStable(f.Candidates, func(i, j int) bool { return f.Candidates[i].Weight > f.Candidates[j].Weight })
+		f.choose(0, total)
+		result.Fields = append(result.Fields, *f)
+	}
+	sort.Slice(result.Fields, func(i, j int) bool { return result.Fields[i].Path < result.Fields[j].Path })
+
+	if strategy == ReconcileJudge && len(result.Disputed()) > 0 {
+		usage, err := judgeFields(ctx, opts.Judge, &opts.Request, result, total)
+		result.Usage = result.Usage.Add(usage)
+		result.JudgeErr = err
+	}
+
+	result.Object = make(map[string]interface{})
+	for _, f := range result.Fields {
+		setPointer(result.Object, f.Path, f.Value)
+	}
+	if err := opts.Request.Schema.Validator().Validate(result.Object); err != nil {
+		return result, fmt.Errorf("reconciled object does not match the schema: %w", err)
+	}
+	return result, nil
+}
+
+// choose sets the field to candidate i
+func (f *FieldProvenance) choose(i int, total float64) {
+	c := f.Candidates[i]
+	f.Value = c.Value
+	f.Models = c.Models
+	f.Agreement = 0
+	if total > 0 {
+		f.Agreement = c.Weight / total
+	}
+}
+
+// judgeFields asks the judge to settle the disputed fields in place
+func judgeFields(ctx context.Context, judge provider.LanguageModel, req *GenerateObjectOptions, result *EnsembleExtractResult, total float64) (types.Usage, error) {
+	type dispute struct {
+		Field      string        `json:"field"`
+		Candidates []interface{} `json:"candidates"`
+	}
+	var disputes []dispute
+	for _, f := range result.Fields {
+		if !f.Disputed() {
+			continue
+		}
+		d := dispute{Field: f.Path}
+		for _, c := range f.Candidates {
+			d.Candidates = append(d.Candidates, c.Value)
+		}
+		disputes = append(disputes, d)
+	}
+	payload, err := json.Marshal(disputes)
+	if err != nil {
+		return types.Usage{}, err
+	}
+
+	var source strings.Builder
+	if req.System != "" {
+		fmt.Fprintf(&source, "[system] %s\n", req.System)
+	}
+	for _, msg := range req.Messages {
+		for _, part := range msg.Content {
+			if text, ok := part.(types.TextContent); ok {
+				fmt.Fprintf(&source, "[%s] %s\n", msg.Role, text.Text)
+			}
+		}
+	}
+	source.WriteString(req.Prompt)
+
+	judged, err := GenerateObject(ctx, GenerateObjectOptions{
+		Model: judge,
+		System: "Several extractions of the same source disagree on some fields. For each field, choose the candidate " +
+			"the source supports, by its 1-based position, and explain the choice in one sentence.",
+		Prompt:     "Source:\n" + strings.TrimSpace(source.String()) + "\n\nDisputed fields:\n" + string(payload),
+		Schema:     judgeFieldsSchema,
+		OutputMode: ObjectModeObject,
+	})
+	if err != nil {
+		return types.Usage{}, fmt.Errorf("judge failed: %w", err)
+	}
+
+	var out struct {
+		Decisions []struct {
+			Field  string `json:"field"`
+			Choice int    `json:"choice"`
+			Reason string `json:"reason"`
+		} `json:"decisions"`
+	}
+	if err := json.Unmarshal([]byte(judged.Text), &out); err != nil {
+		return judged.Usage, fmt.Errorf("failed to decode judge decisions: %w", err)
+	}
+	for _, d := range out.Decisions {
+		for i := range result.Fields {
+			f := &result.Fields[i]
+			if f.Path != d.Field || !f.Disputed() || d.Choice < 1 || d.Choice > len(f.Candidates) {
+				continue
+			}
+			f.choose(d.Choice-1, total)
+			f.Reason = d.Reason
+		}
+	}
+	return judged.Usage, nil
+}
+
+var judgeFieldsSchema = schema.NewSimpleJSONSchema(map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"decisions": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"field":  map[string]interface{}{"type": "string"},
+					"choice": map[string]interface{}{"type": "integer", "minimum": 1},
+					"reason": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"field", "choice", "reason"},
+			},
+		},
+	},
+	"required": []string{"decisions"},
+})
+
+// collectLeaves calls fn for every non-object value in obj with its JSON
+// pointer. Empty objects are leaves.
+func collectLeaves(obj map[string]interface{}, prefix string, fn func(path string, value interface{})) {
+	for key, value := range obj {
+		path := prefix + "/" + schema.EscapePointer(key)
+		if child, ok := value.(map[string]interface{}); ok && len(child) > 0 {
+			collectLeaves(child, path, fn)
+			continue
+		}
+		fn(path, value)
+	}
+}
+
+// setPointer sets the value at a JSON pointer, creating objects on the way
+func setPointer(obj map[string]interface{}, pointer string, value interface{}) {
+	parts, err := schema.ParsePointer(pointer)
+	if err != nil || len(parts) == 0 {
+		return
+	}
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := obj[part].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			obj[part] = child
+		}
+		obj = child
+	}
+	obj[parts[len(parts)-1]] = value
+}
+
+// canonicalJSON returns a comparable encoding of v; encoding/json sorts
+// map keys
+func canonicalJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,152 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+var invoiceSchema = schema.NewSimpleJSONSchema(map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"number": map[string]interface{}{"type": "string"},
+		"total":  map[string]interface{}{"type": "number"},
+		"vendor": map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+		},
+	},
+	"required": []string{"number", "total"},
+})
+
+func extractionModel(name, text string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		ModelName:         name,
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if text == "" {
+				return nil, errors.New("unavailable")
+			}
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+}
+
+func ensembleModels() []provider.LanguageModel {
+	return []provider.LanguageModel{
+		extractionModel("a", `{"number": "INV-7", "total": 1200.5, "vendor": {"name": "Acme"}}`),
+		extractionModel("b", `{"number": "INV-7", "total": 1250.5, "vendor": {"name": "Acme Ltd"}}`),
+		extractionModel("c", `{"number": "INV-1", "total": 1200.5, "vendor": {"name": "Acme Ltd"}}`),
+	}
+}
+
+func TestEnsembleExtract_Majority(t *testing.T) {
+	t.Parallel()
+
+	result, err := EnsembleExtract(context.Background(), EnsembleExtractOptions{
+		Request: GenerateObjectOptions{Prompt: "Invoice INV-7 from Acme Ltd, total 1200.50", Schema: invoiceSchema},
+		Models:  ensembleModels(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Object["number"] != "INV-7" || result.Object["total"] != 1200.5 {
+		t.Errorf("object = %v", result.Object)
+	}
+	if vendor := result.Object["vendor"].(map[string]interface{}); vendor["name"] != "Acme Ltd" {
+		t.Errorf("vendor = %v", vendor)
+	}
+
+	total, _ := result.Field("/total")
+	if !total.Disputed() || len(total.Models) != 2 || total.Models[0] != 0 || total.Models[1] != 2 {
+		t.Errorf("total provenance = %+v", total)
+	}
+	if total.Agreement < 0.66 || total.Agreement > 0.67 {
+		t.Errorf("agreement = %v", total.Agreement)
+	}
+	if len(result.Fields) != 3 || len(result.Disputed()) != 3 {
+		t.Errorf("fields = %+v", result.Fields)
+	}
+}
+
+func TestEnsembleExtract_WeightedAndFailures(t *testing.T) {
+	t.Parallel()
+
+	models := ensembleModels()
+	models = append(models, extractionModel("down", ""))
+	result, err := EnsembleExtract(context.Background(), EnsembleExtractOptions{
+		Request:  GenerateObjectOptions{Prompt: "invoice", Schema: invoiceSchema},
+		Models:   models,
+		Strategy: ReconcileWeighted,
+		Weights:  []float64{1, 3, 1, 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Model b outweighs a and c together; the failed model has no vote
+	if result.Object["total"] != 1250.5 || result.Results[3].Err == nil {
+		t.Errorf("object = %v", result.Object)
+	}
+	if number, _ := result.Field("/number"); number.Agreement != 0.8 {
+		t.Errorf("number agreement = %v", number.Agreement)
+	}
+}
+
+func TestEnsembleExtract_Judge(t *testing.T) {
+	t.Parallel()
+
+	var judgePrompt string
+	judge := &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			judgePrompt = opts.Prompt.Messages[len(opts.Prompt.Messages)-1].Content[0].(types.TextContent).Text
+			return &types.GenerateResult{
+				Text:         `{"decisions": [{"field": "/total", "choice": 2, "reason": "The source says 1250.50"}]}`,
+				FinishReason: types.FinishReasonStop,
+			}, nil
+		},
+	}
+	result, err := EnsembleExtract(context.Background(), EnsembleExtractOptions{
+		Request:  GenerateObjectOptions{Prompt: "Invoice INV-7, total 1250.50", Schema: invoiceSchema},
+		Models:   ensembleModels(),
+		Strategy: ReconcileJudge,
+		Judge:    judge,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(judgePrompt, "Invoice INV-7, total 1250.50") || !strings.Contains(judgePrompt, `"/vendor/name"`) {
+		t.Errorf("judge prompt = %q", judgePrompt)
+	}
+	total, _ := result.Field("/total")
+	if result.Object["total"] != 1250.5 || total.Reason == "" || len(total.Models) != 1 || total.Models[0] != 1 {
+		t.Errorf("total = %+v", total)
+	}
+	// Fields the judge did not decide keep the majority value
+	if result.Object["number"] != "INV-7" || result.JudgeErr != nil {
+		t.Errorf("object = %v, judge error %v", result.Object, result.JudgeErr)
+	}
+}
+
+func TestEnsembleExtract_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := EnsembleExtract(context.Background(), EnsembleExtractOptions{
+		Request: GenerateObjectOptions{Schema: invoiceSchema},
+		Models:  ensembleModels()[:1],
+	}); err == nil {
+		t.Error("expected an error for a single model")
+	}
+	if _, err := EnsembleExtract(context.Background(), EnsembleExtractOptions{
+		Request: GenerateObjectOptions{Schema: invoiceSchema},
+		Models:  []provider.LanguageModel{extractionModel("x", ""), extractionModel("y", "")},
+	}); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("expected the models' error, got %v", err)
+	}
+}
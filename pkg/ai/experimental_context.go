@@ -0,0 +1,58 @@
+package ai
+
+import "context"
+
+// experimentalContextKey stores the ExperimentalContext of the enclosing
+// generation in a context.Context
+type experimentalContextKey struct{}
+
+// userContextKey stores a typed value attached with WithUserContext. Each
+// instantiation is a distinct key, so values of different types coexist.
+type userContextKey[T any] struct{}
+
+// WithUserContext returns a copy of ctx carrying value. Tools, skills,
+// subagents and callbacks can read it back with ContextValue[T].
+func WithUserContext[T any](ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, userContextKey[T]{}, value)
+}
+
+// ContextValue returns the value of type T attached to ctx with
+// WithUserContext. When there is none it falls back to the ExperimentalContext
+// of the enclosing GenerateText, StreamText or agent run, if that holds a T.
+func ContextValue[T any](ctx context.Context) (T, bool) {
+	if v, ok := ctx.Value(userContextKey[T]{}).(T); ok {
+		return v, true
+	}
+	return UserContextValue[T](ctx.Value(experimentalContextKey{}))
+}
+
+// UserContextValue converts an ExperimentalContext or
+// ToolExecutionOptions.UserContext value to T
+func UserContextValue[T any](v interface{}) (T, bool) {
+	t, ok := v.(T)
+	return t, ok
+}
+
+// WithExperimentalContext returns a copy of ctx carrying an ExperimentalContext
+// for nested generations. A nil value leaves ctx unchanged, so an outer
+// context keeps flowing through calls that don't set their own.
+func WithExperimentalContext(ctx context.Context, value interface{}) context.Context {
+	if value == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, experimentalContextKey{}, value)
+}
+
+// ExperimentalContextFrom returns the ExperimentalContext carried by ctx, or nil
+func ExperimentalContextFrom(ctx context.Context) interface{} {
+	return ctx.Value(experimentalContextKey{})
+}
+
+// resolveExperimentalContext inherits the experimental context from ctx when
+// value is nil and embeds value in ctx otherwise
+func resolveExperimentalContext(ctx context.Context, value interface{}) (context.Context, interface{}) {
+	if value == nil {
+		return ctx, ExperimentalContextFrom(ctx)
+	}
+	return WithExperimentalContext(ctx, value), value
+}
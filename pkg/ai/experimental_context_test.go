@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+type requestInfo struct {
+	TenantID string
+}
+
+func TestContextValue(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithUserContext(context.Background(), requestInfo{TenantID: "acme"})
+	ctx = WithUserContext(ctx, 42)
+
+	info, ok := ContextValue[requestInfo](ctx)
+	if !ok || info.TenantID != "acme" {
+		t.Errorf("expected requestInfo, got %+v (ok=%v)", info, ok)
+	}
+	if n, ok := ContextValue[int](ctx); !ok || n != 42 {
+		t.Errorf("expected 42, got %v (ok=%v)", n, ok)
+	}
+	if _, ok := ContextValue[string](ctx); ok {
+		t.Error("expected no string value")
+	}
+}
+
+func TestGenerateText_ExperimentalContextPropagation(t *testing.T) {
+	t.Parallel()
+
+	callCount := 0
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			callCount++
+			if callCount == 1 {
+				return &types.GenerateResult{
+					FinishReason: types.FinishReasonToolCalls,
+					ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: "lookup", Arguments: map[string]interface{}{}}},
+				}, nil
+			}
+			return &types.GenerateResult{Text: "done", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	var fromOptions, fromCtx, nested requestInfo
+	inner := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: "inner", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	tools := []types.Tool{{
+		Name: "lookup",
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			fromOptions, _ = UserContextValue[requestInfo](opts.UserContext)
+			fromCtx, _ = ContextValue[requestInfo](ctx)
+			// A nested generation without its own context inherits the caller's
+			_, err := GenerateText(ctx, GenerateTextOptions{
+				Model:  inner,
+				Prompt: "sub task",
+				OnFinish: func(ctx context.Context, result *GenerateTextResult, userContext interface{}) {
+					nested, _ = UserContextValue[requestInfo](userContext)
+				},
+			})
+			return "ok", err
+		},
+	}}
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:               model,
+		Prompt:              "go",
+		Tools:               tools,
+		StopWhen:            []StopCondition{StepCountIs(3)},
+		ExperimentalContext: requestInfo{TenantID: "acme"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, got := range map[string]requestInfo{"options": fromOptions, "ctx": fromCtx, "nested": nested} {
+		if got.TenantID != "acme" {
+			t.Errorf("%s: expected tenant acme, got %+v", name, got)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
+)
+
+// FileStoreOptions configures a FileStore
+type FileStoreOptions struct {
+	// MaxSize rejects uploads larger than this many bytes (0: provider limit only)
+	MaxSize int64
+
+	// AllowedTypes restricts uploads to these MIME types; entries may end in
+	// "/*" to allow a whole category, e.g. "image/*" (default: any type)
+	AllowedTypes []string
+
+	// Purpose is passed to providers that require one
+	Purpose string
+
+	// VerifyAfter re-checks that a cached upload still exists when it is
+	// referenced this long after it was last seen, so files deleted or
+	// expired on the provider side are uploaded again (0: never re-check)
+	VerifyAfter time.Duration
+}
+
+// FileStore uploads files to a provider's Files API and turns them into
+// prompt references. Local paths are uploaded once and reused while the
+// file is unchanged; a modified file, or one that has disappeared from the
+// provider, is uploaded again automatically.
+//
+// Example:
+//
+//	files := ai.NewFileStore(anthropicProvider, ai.FileStoreOptions{
+//		AllowedTypes: []string{"application/pdf", "image/*"},
+//	})
+//	report, err := files.Reference(ctx, "reports/q3.pdf")
+//	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+//		Model: model,
+//		Messages: []types.Message{{Role: types.RoleUser, Content: []types.ContentPart{
+//			report, types.TextContent{Text: "Summarize this report."},
+//		}}},
+//	})
+type FileStore struct {
+	manager provider.FileManager
+	opts    FileStoreOptions
+
+	mu    sync.Mutex
+	paths map[string]*uploadedPath
+}
+
+// uploadedPath remembers the upload of a local file
+type uploadedPath struct {
+	file     provider.File
+	size     int64
+	modTime  time.Time
+	verified time.Time
+}
+
+// NewFileStore creates a FileStore for a provider's Files API
+func NewFileStore(manager provider.FileManager, opts FileStoreOptions) *FileStore {
+	return &FileStore{manager: manager, opts: opts, paths: make(map[string]*uploadedPath)}
+}
+
+// Upload validates and uploads a file
+func (s *FileStore) Upload(ctx context.Context, data []byte, filename, mimeType string) (*provider.File, error) {
+	opts := provider.FileUploadOptions{Data: data, Filename: filename, MimeType: mimeType, Purpose: s.opts.Purpose}
+	opts.MimeType = providerutils.FileMimeType(opts)
+	if err := s.validate(opts); err != nil {
+		return nil, err
+	}
+	file, err := s.manager.UploadFile(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %q: %w", filename, err)
+	}
+	if file.MimeType == "" {
+		file.MimeType = opts.MimeType
+	}
+	return file, nil
+}
+
+// UploadPath validates and uploads a local file. Files over MaxSize are
+// rejected before they are read.
+func (s *FileStore) UploadPath(ctx context.Context, path string) (*provider.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	var r io.Reader = f
+	if s.opts.MaxSize > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > s.opts.MaxSize {
+			return nil, fmt.Errorf("%w: %q is %d bytes, limit is %d", provider.ErrFileTooLarge, name, info.Size(), s.opts.MaxSize)
+		}
+		// The file may grow after Stat; read one byte past the limit so
+		// validate still catches it
+		r = io.LimitReader(f, s.opts.MaxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.Upload(ctx, data, name, "")
+}
+
+// Reference returns a prompt part referring to the local file at path,
+// uploading it when it has not been uploaded yet, has changed since, or
+// (with VerifyAfter) no longer exists on the provider
+func (s *FileStore) Reference(ctx context.Context, path string) (types.FileContent, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return types.FileContent{}, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return types.FileContent{}, err
+	}
+
+	s.mu.Lock()
+	cached := s.paths[abs]
+	s.mu.Unlock()
+
+	if cached != nil && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		if s.opts.VerifyAfter <= 0 || time.Since(cached.verified) < s.opts.VerifyAfter {
+			return fileReference(cached.file), nil
+		}
+		_, err := s.manager.GetFile(ctx, cached.file.ID)
+		if err == nil {
+			s.mu.Lock()
+			cached.verified = time.Now()
+			s.mu.Unlock()
+			return fileReference(cached.file), nil
+		}
+		if !errors.Is(err, provider.ErrFileNotFound) {
+			return types.FileContent{}, err
+		}
+	}
+
+	file, err := s.UploadPath(ctx, abs)
+	if err != nil {
+		return types.FileContent{}, err
+	}
+	s.mu.Lock()
+	s.paths[abs] = &uploadedPath{file: *file, size: info.Size(), modTime: info.ModTime(), verified: time.Now()}
+	s.mu.Unlock()
+	return fileReference(*file), nil
+}
+
+// List returns the provider's files
+func (s *FileStore) List(ctx context.Context) ([]provider.File, error) {
+	return s.manager.ListFiles(ctx)
+}
+
+// Delete removes a file from the provider and forgets any local path that
+// was uploaded as it
+func (s *FileStore) Delete(ctx context.Context, fileID string) error {
+	if err := s.manager.DeleteFile(ctx, fileID); err != nil && !errors.Is(err, provider.ErrFileNotFound) {
+		return err
+	}
+	s.Forget(fileID)
+	return nil
+}
+
+// Forget drops cached uploads of fileID so the next Reference uploads the
+// file again, e.g. after a request reported the file as missing
+func (s *FileStore) Forget(fileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path, u := range s.paths {
+		if u.file.ID == fileID {
+			delete(s.paths, path)
+		}
+	}
+}
+
+func (s *FileStore) validate(opts provider.FileUploadOptions) error {
+	if s.opts.MaxSize > 0 && int64(len(opts.Data)) > s.opts.MaxSize {
+		return fmt.Errorf("%w: %q is %d bytes, limit is %d", provider.ErrFileTooLarge, opts.Filename, len(opts.Data), s.opts.MaxSize)
+	}
+	if len(s.opts.AllowedTypes) == 0 {
+		return nil
+	}
+	for _, allowed := range s.opts.AllowedTypes {
+		if allowed == opts.MimeType ||
+			(strings.HasSuffix(allowed, "/*") && strings.HasPrefix(opts.MimeType, strings.TrimSuffix(allowed, "*"))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q has type %s", provider.ErrFileTypeNotAllowed, opts.Filename, opts.MimeType)
+}
+
+func fileReference(file provider.File) types.FileContent {
+	return types.FileContent{FileID: file.ID, MimeType: file.MimeType, Filename: file.Filename}
+}
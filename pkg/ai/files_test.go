@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// fakeFileManager stores uploads in memory
+type fakeFileManager struct {
+	files   map[string]provider.File
+	uploads int
+}
+
+func (m *fakeFileManager) UploadFile(ctx context.Context, opts provider.FileUploadOptions) (*provider.File, error) {
+	m.uploads++
+	f := provider.File{ID: fmt.Sprintf("file-%d", m.uploads), Filename: opts.Filename, MimeType: opts.MimeType, Size: int64(len(opts.Data))}
+	m.files[f.ID] = f
+	return &f, nil
+}
+
+func (m *fakeFileManager) ListFiles(ctx context.Context) ([]provider.File, error) {
+	var files []provider.File
+	for _, f := range m.files {
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (m *fakeFileManager) GetFile(ctx context.Context, id string) (*provider.File, error) {
+	f, ok := m.files[id]
+	if !ok {
+		return nil, provider.ErrFileNotFound
+	}
+	return &f, nil
+}
+
+func (m *fakeFileManager) DeleteFile(ctx context.Context, id string) error {
+	delete(m.files, id)
+	return nil
+}
+
+func TestFileStore_Reference(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manager := &fakeFileManager{files: map[string]provider.File{}}
+	store := NewFileStore(manager, FileStoreOptions{VerifyAfter: time.Nanosecond})
+	ctx := context.Background()
+
+	ref, err := store.Reference(ctx, path)
+	if err != nil {
+		t.Fatalf("Reference failed: %v", err)
+	}
+	if ref.FileID != "file-1" || ref.Filename != "notes.txt" || ref.MimeType != "text/plain" {
+		t.Errorf("reference = %+v", ref)
+	}
+
+	// Unchanged and still on the provider: reused
+	if ref, _ = store.Reference(ctx, path); ref.FileID != "file-1" || manager.uploads != 1 {
+		t.Errorf("expected the cached upload, got %s after %d uploads", ref.FileID, manager.uploads)
+	}
+
+	// Deleted on the provider side: uploaded again
+	delete(manager.files, "file-1")
+	if ref, _ = store.Reference(ctx, path); ref.FileID != "file-2" {
+		t.Errorf("expected a re-upload, got %s", ref.FileID)
+	}
+
+	// Modified locally: uploaded again
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(path, []byte("v2 longer"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, later, later)
+	if ref, _ = store.Reference(ctx, path); ref.FileID != "file-3" {
+		t.Errorf("expected a re-upload after modification, got %s", ref.FileID)
+	}
+
+	// Deleting through the store forgets the path
+	if err := store.Delete(ctx, "file-3"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if ref, _ = store.Reference(ctx, path); ref.FileID != "file-4" {
+		t.Errorf("expected a re-upload after Delete, got %s", ref.FileID)
+	}
+}
+
+func TestFileStore_Validation(t *testing.T) {
+	t.Parallel()
+
+	store := NewFileStore(&fakeFileManager{files: map[string]provider.File{}}, FileStoreOptions{
+		MaxSize:      4,
+		AllowedTypes: []string{"application/pdf", "image/*"},
+	})
+	ctx := context.Background()
+
+	if _, err := store.Upload(ctx, []byte("12345"), "a.png", ""); !errors.Is(err, provider.ErrFileTooLarge) {
+		t.Errorf("err = %v, want ErrFileTooLarge", err)
+	}
+	if _, err := store.Upload(ctx, []byte("abc"), "a.exe", ""); !errors.Is(err, provider.ErrFileTypeNotAllowed) {
+		t.Errorf("err = %v, want ErrFileTypeNotAllowed", err)
+	}
+	if f, err := store.Upload(ctx, []byte("abc"), "a.png", ""); err != nil || f.MimeType != "image/png" {
+		t.Errorf("Upload = %+v, %v", f, err)
+	}
+	path := filepath.Join(t.TempDir(), "big.png")
+	if err := os.WriteFile(path, []byte("123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UploadPath(ctx, path); !errors.Is(err, provider.ErrFileTooLarge) {
+		t.Errorf("UploadPath err = %v, want ErrFileTooLarge", err)
+	}
+}
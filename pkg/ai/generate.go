@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 	"github.com/digitallysavvy/go-ai/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // now returns the current time in milliseconds since Unix epoch.
@@ -41,6 +43,11 @@ type GenerateTextOptions struct {
 	Tools      []types.Tool
 	ToolChoice types.ToolChoice
 
+	// DryRun previews the generation without invoking tool Execute
+	// functions; the calls the model makes are returned in
+	// GenerateTextResult.DryRunCalls. Nil executes tools normally.
+	DryRun *DryRunOptions
+
 	// MaxSteps is a convenience shorthand for StopWhen{StepCountIs(N)}.
 	// Deprecated: use StopWhen with StepCountIs instead.
 	// If StopWhen is set, MaxSteps is ignored.
@@ -73,6 +80,28 @@ type GenerateTextOptions struct {
 	// Kept for backward compatibility
 	ResponseFormat *provider.ResponseFormat
 
+	// Continuation automatically requests the rest of a response that was
+	// cut off by the output token limit and stitches the parts together.
+	// Nil disables continuation.
+	Continuation *ContinuationOptions
+
+	// Validate checks the final result against post-conditions. A rejected
+	// result is regenerated with the rejection reason as guidance; once
+	// retries are exhausted a *ValidationError is returned. Callbacks fire
+	// for every attempt.
+	Validate ValidateFunc
+
+	// Validation configures the regeneration loop driven by Validate
+	Validation *ValidationOptions
+
+	// Constraint restricts output to a regex, grammar or JSON schema.
+	// Passed natively to models implementing provider.ConstrainedDecodingModel
+	// (vLLM guided decoding, llama.cpp grammars); for other models, and when
+	// tools are offered, the final text is validated and a
+	// *types.ConstraintViolationError is returned on mismatch. Grammar
+	// constraints require native support and cannot be combined with tools.
+	Constraint *types.Constraint
+
 	// ========================================================================
 	// Context Flow (v6.0 - NEW)
 	// ========================================================================
@@ -83,6 +112,8 @@ type GenerateTextOptions struct {
 	// - PrepareStep callback
 	// - OnStepFinish callback
 	// - OnFinish callback
+	// - Nested generations and agents via ctx (see ContextValue); when nil,
+	//   the context of an enclosing generation is inherited
 	ExperimentalContext interface{}
 
 	// ========================================================================
@@ -149,6 +180,11 @@ type GenerateTextOptions struct {
 	// For MLflow integration, see pkg/observability/mlflow
 	ExperimentalTelemetry *TelemetrySettings
 
+	// Metadata tags this request (e.g. feature, team, user) for cost
+	// attribution. It is added to telemetry span attributes and callback
+	// events, and forwarded to providers that support request metadata.
+	Metadata map[string]string
+
 	// ========================================================================
 	// Callbacks (Updated signatures in v6.0)
 	// ========================================================================
@@ -239,9 +275,27 @@ type GenerateTextResult struct {
 	// Empty if the loop ended naturally (model stopped calling tools).
 	StopReason string
 
+	// DryRunCalls are the tool calls a dry run did not execute, in order
+	DryRunCalls []types.ToolCall
+
+	// ResponseMessages are the assistant and tool messages generated across
+	// all steps, in order. Append them to the conversation history to
+	// continue it:
+	//
+	//	history = append(history, result.ResponseMessages...)
+	ResponseMessages []types.Message
+
 	// Token usage information
 	Usage types.Usage
 
+	// Continuations is the number of continuation requests made for
+	// responses cut off by the output token limit
+	Continuations int
+
+	// ValidationAttempts is the number of generations made when Validate is
+	// set, including the accepted one. Usage covers all attempts.
+	ValidationAttempts int
+
 	// Context management information (Anthropic-specific)
 	// Contains statistics about automatic conversation history cleanup
 	ContextManagement interface{}
@@ -256,6 +310,10 @@ type GenerateTextResult struct {
 	// Populated by providers such as Perplexity and Google Generative AI.
 	Sources []types.SourceContent
 
+	// Audio is the spoken response from the final step for audio-output
+	// models (e.g. OpenAI with modalities ["text", "audio"]); nil otherwise.
+	Audio *types.AudioOutput
+
 	// Raw request/response (for debugging)
 	RawRequest  interface{}
 	RawResponse interface{}
@@ -267,6 +325,13 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 	if opts.Model == nil {
 		return nil, fmt.Errorf("model is required")
 	}
+	if opts.Validate != nil {
+		return generateValidated(ctx, opts)
+	}
+
+	// Nested generations (tools, subagents) inherit the caller's context
+	ctx, opts.ExperimentalContext = resolveExperimentalContext(ctx, opts.ExperimentalContext)
+	opts.ExperimentalTelemetry = withRequestMetadata(opts.ExperimentalTelemetry, opts.Metadata)
 
 	// Fire OnStart — registered integrations start their root spans here and
 	// embed them in the returned context.  When no integration is registered
@@ -304,6 +369,12 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 	// Build prompt
 	prompt := buildPrompt(opts.Prompt, opts.Messages, opts.System)
 
+	// Decide whether the constraint is enforced by the provider or validated afterwards
+	nativeConstraint, err := resolveConstraint(opts.Model, opts.Constraint, opts.Tools, opts.ToolChoice)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract telemetry info once for all callback events
 	cbFuncID, cbMeta := telemetryCallbackInfo(opts.ExperimentalTelemetry)
 
@@ -344,6 +415,9 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 			stopConditions = []StopCondition{StepCountIs(1)}
 		}
 	}
+	if opts.DryRun != nil {
+		stopConditions = append(stopConditions[:len(stopConditions):len(stopConditions)], opts.DryRun.StopCondition())
+	}
 	maxSteps := 1000 // safety ceiling only
 
 	// Current messages for conversation history
@@ -408,20 +482,30 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 			PresencePenalty:  opts.PresencePenalty,
 			StopSequences:    opts.StopSequences,
 			Seed:             opts.Seed,
-			Tools:            opts.Tools,
+			Tools:            prepareToolsForModel(opts.Tools),
 			ToolChoice:       opts.ToolChoice,
 			ResponseFormat:   responseFormat,
+			Constraint:       nativeConstraint,
 			Reasoning:        opts.Reasoning,
 			ProviderOptions:  opts.ProviderOptions,
 			Telemetry:        opts.ExperimentalTelemetry,
+			Metadata:         opts.Metadata,
 		}
 
 		// Call the model with step context
+		stepStart := now()
 		genResult, err := opts.Model.DoGenerate(stepCtx, genOpts)
 		if err != nil {
 			return nil, fmt.Errorf("generation failed at step %d: %w", stepNum, err)
 		}
 
+		// Continue responses truncated by the output token limit
+		genResult, continuations, err := continueGeneration(stepCtx, opts.Model, genOpts, genResult, opts.Continuation)
+		if err != nil {
+			return nil, fmt.Errorf("generation failed at step %d: %w", stepNum, err)
+		}
+		result.Continuations += continuations
+
 		// Extract sources from content parts
 		var stepSources []types.SourceContent
 		for _, part := range genResult.Content {
@@ -440,6 +524,7 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 			Usage:        genResult.Usage,
 			Warnings:     genResult.Warnings,
 			Sources:      stepSources,
+			DurationMs:   now() - stepStart,
 		}
 
 		// Update accumulated usage
@@ -459,6 +544,7 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 				functionID:          cbFuncID,
 				metadata:            cbMeta,
 				timeout:             opts.Timeout,
+				dryRun:              opts.DryRun,
 			}
 			toolResults, err := executeTools(ctx, genResult.ToolCalls, opts.Tools, opts.ExperimentalContext, &result.Usage, toolCallbacks)
 			if err != nil {
@@ -474,45 +560,31 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 			stepResult.ToolResults = toolResults
 			result.ToolResults = append(result.ToolResults, toolResults...)
 
-			// Add assistant message with tool calls to history.
-			// ToolCalls must be carried on the message so providers that require
-			// a top-level tool_calls field (e.g. OpenAI) can emit it correctly.
-			assistantMsg := types.Message{
-				Role:      types.RoleAssistant,
-				Content:   []types.ContentPart{},
-				ToolCalls: genResult.ToolCalls,
-			}
-			if genResult.Text != "" {
-				assistantMsg.Content = append(assistantMsg.Content, types.TextContent{Text: genResult.Text})
-			}
-			currentMessages = append(currentMessages, assistantMsg)
-
-			// Add tool results to history
-			for _, tr := range toolResults {
-				toolMsg := types.Message{
-					Role: types.RoleTool,
-					Content: []types.ContentPart{
-						types.ToolResultContent{
-							ToolCallID: tr.ToolCallID,
-							ToolName:   tr.ToolName,
-							Result:     tr.Result,
-						},
-					},
-				}
-				currentMessages = append(currentMessages, toolMsg)
-			}
+			// Add the assistant message and tool results to history
+			stepMessages := stepResponseMessages(ctx, opts.Tools, genResult, toolResults)
+			currentMessages = append(currentMessages, stepMessages...)
+			result.ResponseMessages = append(result.ResponseMessages, stepMessages...)
 		} else {
+			result.ResponseMessages = append(result.ResponseMessages, stepResponseMessages(ctx, opts.Tools, genResult, nil)...)
 			// No more tool calls, we're done
 			result.Text = genResult.Text
 			result.FinishReason = genResult.FinishReason
 			result.ToolCalls = genResult.ToolCalls
 			result.Sources = stepSources
+			result.Audio = genResult.Audio
 			result.ContextManagement = genResult.ContextManagement
 			result.Warnings = append(result.Warnings, genResult.Warnings...)
 			result.RawRequest = genResult.RawRequest
 			result.RawResponse = genResult.RawResponse
 			result.ProviderMetadata = genResult.ProviderMetadata
 
+			// Validate constraints the provider could not enforce natively
+			if needsConstraintValidation(opts.Constraint, nativeConstraint) {
+				if err := validateConstraint(opts.Constraint, genResult.Text); err != nil {
+					return nil, err
+				}
+			}
+
 			// Parse typed output if an Output spec was provided.
 			// Only parse when generation finished cleanly; a 'length' finish means
 			// the response was truncated and would likely produce invalid JSON.
@@ -608,6 +680,8 @@ func GenerateText(ctx context.Context, opts GenerateTextOptions) (result *Genera
 		}
 	}
 
+	result.DryRunCalls = opts.DryRun.IntendedCalls(result.Steps, opts.Tools)
+
 	// Fire OnFinish — integrations record output attributes and end their spans.
 	telUsage := telemetry.TelemetryUsage{
 		InputTokens:  result.Usage.InputTokens,
@@ -676,6 +750,7 @@ type toolCallEventCallbacks struct {
 	functionID          string
 	metadata            map[string]any
 	timeout             *TimeoutConfig
+	dryRun              *DryRunOptions
 }
 
 // executeTools executes a list of tool calls
@@ -696,7 +771,7 @@ func executeTools(ctx context.Context, toolCalls []types.ToolCall, availableTool
 		}
 
 		if tool == nil {
-			toolErr := fmt.Errorf("tool not found: %s", call.ToolName)
+			toolErr := &types.ToolError{Code: types.ToolErrorUnknownTool, Message: fmt.Sprintf("tool not found: %s", call.ToolName)}
 			results[i] = types.ToolResult{
 				ToolCallID:       call.ID,
 				ToolName:         call.ToolName,
@@ -757,16 +832,17 @@ func executeTools(ctx context.Context, toolCalls []types.ToolCall, availableTool
 			execCtx := toolCtx
 			execCancel := func() {}
 			if toolTimeout := callbacks.timeout.GetToolTimeout(call.ToolName); toolTimeout != nil {
-				execCtx, execCancel = context.WithTimeout(toolCtx, *toolTimeout)
+				execCtx, execCancel = clock.WithTimeout(toolCtx, callbacks.timeout.Clock, *toolTimeout)
 			}
 
+			execute := callbacks.dryRun.Executor(*tool)
 			startTime := now()
 			toolResult, toolErr := telemetry.FireExecuteTool(
 				execCtx,
 				call.ToolName,
 				call.Arguments,
 				func(execCtx context.Context, args map[string]interface{}) (interface{}, error) {
-					return tool.Execute(execCtx, args, execOptions)
+					return execute(execCtx, args, execOptions)
 				},
 			)
 			durationMs := now() - startTime
@@ -779,6 +855,7 @@ func executeTools(ctx context.Context, toolCalls []types.ToolCall, availableTool
 				Result:           toolResult,
 				Error:            toolErr,
 				ProviderExecuted: false,
+				DurationMs:       durationMs,
 			}
 
 			// Fire telemetry OnToolCallFinish (Gap 5 partial: integrations can record errors).
@@ -860,6 +937,23 @@ func telemetryCallbackInfo(t *TelemetrySettings) (functionID string, metadata ma
 	return functionID, metadata
 }
 
+// withRequestMetadata merges per-request metadata into the telemetry
+// settings so it reaches spans and callback events. Settings are created
+// (disabled) when only metadata is given, so callbacks still see it.
+func withRequestMetadata(settings *TelemetrySettings, metadata map[string]string) *TelemetrySettings {
+	if len(metadata) == 0 {
+		return settings
+	}
+	if settings == nil {
+		settings = &TelemetrySettings{}
+	}
+	attrs := make(map[string]attribute.Value, len(metadata))
+	for k, v := range metadata {
+		attrs[k] = attribute.StringValue(v)
+	}
+	return settings.WithMetadata(attrs)
+}
+
 // buildPrompt builds a unified Prompt from various input formats
 func buildPrompt(promptText string, messages []types.Message, system string) types.Prompt {
 	if len(messages) > 0 {
@@ -885,3 +979,45 @@ func buildPrompt(promptText string, messages []types.Message, system string) typ
 
 	return types.Prompt{}
 }
+
+// ResponseMessages rebuilds the assistant and tool messages that steps added
+// to a conversation, the same messages GenerateText reports in
+// GenerateTextResult.ResponseMessages. tools are used to format tool results
+// as described for NewToolResultContent.
+func ResponseMessages(ctx context.Context, tools []types.Tool, steps []types.StepResult) []types.Message {
+	var messages []types.Message
+	for _, step := range steps {
+		genResult := &types.GenerateResult{Text: step.Text, ToolCalls: step.ToolCalls}
+		messages = append(messages, stepResponseMessages(ctx, tools, genResult, step.ToolResults)...)
+	}
+	return messages
+}
+
+// stepResponseMessages returns the messages a generation step adds to the
+// conversation: the assistant message, followed by one tool message per
+// tool result. Empty responses add no messages.
+func stepResponseMessages(ctx context.Context, tools []types.Tool, genResult *types.GenerateResult, toolResults []types.ToolResult) []types.Message {
+	if genResult.Text == "" && len(genResult.ToolCalls) == 0 {
+		return nil
+	}
+	// ToolCalls must be carried on the message so providers that require
+	// a top-level tool_calls field (e.g. OpenAI) can emit it correctly.
+	assistantMsg := types.Message{
+		Role:      types.RoleAssistant,
+		Content:   []types.ContentPart{},
+		ToolCalls: genResult.ToolCalls,
+	}
+	if genResult.Text != "" {
+		assistantMsg.Content = append(assistantMsg.Content, types.TextContent{Text: genResult.Text})
+	}
+	messages := []types.Message{assistantMsg}
+	for _, tr := range toolResults {
+		messages = append(messages, types.Message{
+			Role: types.RoleTool,
+			Content: []types.ContentPart{
+				NewToolResultContent(ctx, tools, tr),
+			},
+		})
+	}
+	return messages
+}
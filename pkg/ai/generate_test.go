@@ -279,6 +279,80 @@ func TestGenerateText_ToolCalling(t *testing.T) {
 	}
 }
 
+func TestGenerateText_ResponseMessages(t *testing.T) {
+	t.Parallel()
+
+	tools := []types.Tool{
+		{
+			Name:       "get_weather",
+			Parameters: map[string]interface{}{"type": "object"},
+			Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+				return "sunny", nil
+			},
+		},
+	}
+
+	var secondPrompt []types.Message
+	callCount := 0
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			callCount++
+			if callCount == 1 {
+				return &types.GenerateResult{
+					Text:         "Checking.",
+					FinishReason: types.FinishReasonToolCalls,
+					ToolCalls: []types.ToolCall{
+						{ID: "call_1", ToolName: "get_weather", Arguments: map[string]interface{}{"location": "NYC"}},
+					},
+				}, nil
+			}
+			secondPrompt = opts.Prompt.Messages
+			return &types.GenerateResult{Text: "It is sunny.", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	history := []types.Message{
+		{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "Weather in NYC?"}}},
+	}
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    model,
+		Messages: history,
+		Tools:    tools,
+		StopWhen: []StopCondition{StepCountIs(5)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := result.ResponseMessages
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 response messages, got %d", len(msgs))
+	}
+	if msgs[0].Role != types.RoleAssistant || len(msgs[0].ToolCalls) != 1 || msgs[0].ToolCalls[0].ID != "call_1" {
+		t.Errorf("unexpected tool call message: %+v", msgs[0])
+	}
+	if msgs[1].Role != types.RoleTool {
+		t.Errorf("expected tool message, got %q", msgs[1].Role)
+	} else if tr, ok := msgs[1].Content[0].(types.ToolResultContent); !ok || tr.ToolCallID != "call_1" {
+		t.Errorf("unexpected tool result content: %+v", msgs[1].Content)
+	}
+	if msgs[2].Role != types.RoleAssistant || len(msgs[2].Content) != 1 {
+		t.Fatalf("unexpected final message: %+v", msgs[2])
+	}
+	if text, ok := msgs[2].Content[0].(types.TextContent); !ok || text.Text != "It is sunny." {
+		t.Errorf("unexpected final content: %+v", msgs[2].Content[0])
+	}
+
+	// The tool-step messages match the history sent to the model
+	if len(secondPrompt) != len(history)+2 {
+		t.Fatalf("expected %d messages in second prompt, got %d", len(history)+2, len(secondPrompt))
+	}
+	if secondPrompt[1].Role != msgs[0].Role || secondPrompt[2].Role != msgs[1].Role {
+		t.Errorf("second prompt roles do not match response messages")
+	}
+}
+
 func TestGenerateText_MultiStepToolCalling(t *testing.T) {
 	t.Parallel()
 
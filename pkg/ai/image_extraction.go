@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// ImageExtractionOptions configures ExtractFromImage
+type ImageExtractionOptions struct {
+	// Model must accept image input
+	Model provider.LanguageModel
+
+	// Images of the document, one per page, in order
+	Images []types.ImageContent
+
+	// DocumentType names what the images show, e.g. "receipt", "invoice"
+	// or "app screenshot" (default: "document")
+	DocumentType string
+
+	// Instructions are appended to the extraction prompt, e.g. field
+	// conventions specific to a vendor
+	Instructions string
+
+	// MaxRetries is how many times output that cannot be parsed or does not
+	// match the target type is sent back to the model with the error
+	// (default: 2, negative disables retries)
+	MaxRetries int
+
+	// MinConfidence flags fields whose confidence is below it in
+	// LowConfidence (default: 0.5)
+	MinConfidence float64
+
+	Temperature *float64
+	MaxTokens   *int
+	Metadata    map[string]string
+}
+
+// ImageExtraction is the result of ExtractFromImage
+type ImageExtraction[T any] struct {
+	// Data is the extracted value
+	Data T
+
+	// Confidence maps field paths in Data (e.g. "total", "items[0].price")
+	// to the model's confidence between 0 and 1
+	Confidence map[string]float64
+
+	// LowConfidence lists the paths in Confidence below MinConfidence,
+	// sorted, for human review
+	LowConfidence []string
+
+	Usage    types.Usage
+	Warnings []types.Warning
+}
+
+const imageExtractionPrompt = `Extract structured data from the %s shown in the attached image(s).
+
+Read the layout before extracting:
+- Read top to bottom and left to right; treat multiple images as consecutive pages.
+- Pair each label with the value beside it on the same line or directly below it.
+- Read tables row by row; keep each row's cells together and skip header, subtotal and empty rows unless asked for them.
+- Prefer printed values over handwriting and the final amount over crossed-out ones.
+
+Conventions:
+- Only report what is visible. Use null for fields that are not present; never guess.
+- Numbers without currency symbols or thousands separators; dates as YYYY-MM-DD.
+
+Respond with a JSON object with two keys: "data", the extracted value, and "confidence", an object mapping the path of every field you filled in (e.g. "total", "items[0].price") to your confidence from 0 to 1 that it was read correctly.`
+
+// ExtractFromImage turns screenshots, receipts, invoices and other
+// documents into a T, with per-field confidence. The model is prompted with
+// layout-reading instructions and T's JSON schema (see SchemaFor); output
+// goes through the JSON mode repair loop, so malformed JSON is repaired and
+// output that does not decode into T is sent back to the model with the
+// error until it does or MaxRetries is exhausted.
+//
+// Example:
+//
+//	type Receipt struct {
+//		Merchant string  `json:"merchant"`
+//		Date     string  `json:"date"`
+//		Total    float64 `json:"total"`
+//	}
+//	result, err := ai.ExtractFromImage[Receipt](ctx, ai.ImageExtractionOptions{
+//		Model:        model,
+//		Images:       []types.ImageContent{{Image: photo, MimeType: "image/jpeg"}},
+//		DocumentType: "receipt",
+//	})
+func ExtractFromImage[T any](ctx context.Context, opts ImageExtractionOptions) (*ImageExtraction[T], error) {
+	if len(opts.Images) == 0 {
+		return nil, fmt.Errorf("at least one image is required")
+	}
+	for _, image := range opts.Images {
+		if err := checkVisionInput(opts.Model, image); err != nil {
+			return nil, err
+		}
+	}
+	documentType := opts.DocumentType
+	if documentType == "" {
+		documentType = "document"
+	}
+	minConfidence := opts.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = 0.5
+	}
+
+	prompt := fmt.Sprintf(imageExtractionPrompt, documentType)
+	if opts.Instructions != "" {
+		prompt += "\n\n" + opts.Instructions
+	}
+	content := make([]types.ContentPart, 0, len(opts.Images)+1)
+	for _, image := range opts.Images {
+		content = append(content, image)
+	}
+	content = append(content, types.TextContent{Text: prompt})
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultJSONModeRetries
+	}
+	result, err := GenerateObject(ctx, GenerateObjectOptions{
+		Model:       opts.Model,
+		Messages:    []types.Message{{Role: types.RoleUser, Content: content}},
+		Schema:      &extractionSchema[T]{data: SchemaFor[T]().Validator().JSONSchema()},
+		OutputMode:  ObjectModeObject,
+		JSONMode:    &JSONModeOptions{Force: true, MaxRetries: maxRetries},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Metadata:    opts.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope struct {
+		Data       T                  `json:"data"`
+		Confidence map[string]float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(result.Text), &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode extracted data: %w", err)
+	}
+	extraction := &ImageExtraction[T]{
+		Data:       envelope.Data,
+		Confidence: envelope.Confidence,
+		Usage:      result.Usage,
+		Warnings:   result.Warnings,
+	}
+	if extraction.Confidence == nil {
+		extraction.Confidence = make(map[string]float64)
+	}
+	for path, c := range extraction.Confidence {
+		if c < minConfidence {
+			extraction.LowConfidence = append(extraction.LowConfidence, path)
+		}
+	}
+	sort.Strings(extraction.LowConfidence)
+	return extraction, nil
+}
+
+// extractionSchema wraps T's schema in the data/confidence envelope and
+// validates output by decoding it into T, so the JSON mode retry loop can
+// feed type errors back to the model
+type extractionSchema[T any] struct {
+	data map[string]interface{}
+}
+
+func (s *extractionSchema[T]) Validator() schema.Validator {
+	return s
+}
+
+func (s *extractionSchema[T]) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data": s.data,
+			"confidence": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+			},
+		},
+		"required": []string{"data", "confidence"},
+	}
+}
+
+func (s *extractionSchema[T]) Validate(value interface{}) error {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a JSON object with \"data\" and \"confidence\"")
+	}
+	data, ok := obj["data"]
+	if !ok || data == nil {
+		return fmt.Errorf("missing \"data\"")
+	}
+	if missing := missingRequired(s.data, data); len(missing) > 0 {
+		return fmt.Errorf("\"data\" is missing required fields: %s", strings.Join(missing, "; "))
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var target T
+	if err := json.Unmarshal(raw, &target); err != nil {
+		return fmt.Errorf("\"data\" does not match the schema: %w", err)
+	}
+	if c, ok := obj["confidence"]; ok && c != nil {
+		scores, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("\"confidence\" must be an object of numbers")
+		}
+		for path, v := range scores {
+			if f, ok := v.(float64); !ok || f < 0 || f > 1 {
+				return fmt.Errorf("confidence for %q must be a number between 0 and 1", path)
+			}
+		}
+	}
+	return nil
+}
+
+// missingRequired lists the required properties absent from value. Only
+// "required" failures count: null values are present, since fields that
+// are not in the document are reported as null.
+func missingRequired(jsonSchema map[string]interface{}, value interface{}) []string {
+	var missing []string
+	for _, failure := range schema.ValidateValue(jsonSchema, value) {
+		if failure.Keyword == "required" {
+			missing = append(missing, failure.Error())
+		}
+	}
+	return missing
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+type testReceipt struct {
+	Merchant string  `json:"merchant"`
+	Total    float64 `json:"total"`
+	Items    []struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	} `json:"items"`
+}
+
+func TestExtractFromImage(t *testing.T) {
+	t.Parallel()
+
+	replies := []string{
+		// Wrong type for total: fed back to the model
+		"```json\n{\"data\":{\"merchant\":\"Cafe\",\"total\":\"4,50\",\"items\":[]},\"confidence\":{}}\n```",
+		`{"data":{"merchant":"Cafe","total":4.5,"items":[{"name":"Latte","price":4.5}]},"confidence":{"merchant":0.95,"total":0.9,"items[0].name":0.4}}`,
+	}
+	var calls []*provider.GenerateOptions
+	model := &testutil.MockLanguageModel{
+		ImageSupport:      true,
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			calls = append(calls, opts)
+			return &types.GenerateResult{Text: replies[len(calls)-1], FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	result, err := ExtractFromImage[testReceipt](context.Background(), ImageExtractionOptions{
+		Model:        model,
+		Images:       []types.ImageContent{testImage},
+		DocumentType: "receipt",
+	})
+	if err != nil {
+		t.Fatalf("ExtractFromImage failed: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected a repair retry, got %d calls", len(calls))
+	}
+	feedback := calls[1].Prompt.Messages[len(calls[1].Prompt.Messages)-1].Content[0].(types.TextContent).Text
+	if !strings.Contains(feedback, "does not match the schema") {
+		t.Errorf("feedback = %q", feedback)
+	}
+	if !strings.Contains(calls[0].Prompt.System, `"merchant"`) {
+		t.Error("expected the target schema in the system prompt")
+	}
+
+	if result.Data.Merchant != "Cafe" || result.Data.Total != 4.5 || len(result.Data.Items) != 1 {
+		t.Errorf("Data = %+v", result.Data)
+	}
+	if result.Confidence["total"] != 0.9 {
+		t.Errorf("Confidence = %v", result.Confidence)
+	}
+	if want := []string{"items[0].name"}; !reflect.DeepEqual(result.LowConfidence, want) {
+		t.Errorf("LowConfidence = %v, want %v", result.LowConfidence, want)
+	}
+}
+
+func TestExtractFromImage_MissingFields(t *testing.T) {
+	t.Parallel()
+
+	model := visionModel(`{"data":{"merchant":"Cafe"},"confidence":{}}`, nil)
+	_, err := ExtractFromImage[testReceipt](context.Background(), ImageExtractionOptions{
+		Model:      model,
+		Images:     []types.ImageContent{testImage},
+		MaxRetries: -1,
+	})
+	if err == nil || !strings.Contains(err.Error(), `missing required property "items"`) || !strings.Contains(err.Error(), `missing required property "total"`) {
+		t.Errorf("err = %v, want missing required fields", err)
+	}
+}
+
+func TestExtractFromImage_RequiresImageInput(t *testing.T) {
+	t.Parallel()
+
+	_, err := ExtractFromImage[testReceipt](context.Background(), ImageExtractionOptions{
+		Model:  &testutil.MockLanguageModel{},
+		Images: []types.ImageContent{testImage},
+	})
+	if !errors.Is(err, ErrImageInputUnsupported) {
+		t.Errorf("err = %v, want ErrImageInputUnsupported", err)
+	}
+}
@@ -0,0 +1,199 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/jsonrepair"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// JSONModeOptions configures the prompt-based JSON mode that GenerateObject
+// falls back to for models without native structured output
+type JSONModeOptions struct {
+	// Force uses prompt-based JSON mode even for models that support
+	// structured output natively
+	Force bool
+
+	// MaxRetries is how many times output that cannot be parsed or fails
+	// schema validation is sent back to the model together with the error.
+	// Default: 2. Use a negative value to disable retries.
+	MaxRetries int
+
+	// Instructions replaces the generated system instructions
+	Instructions string
+}
+
+// defaultJSONModeRetries is the default number of validation retries
+const defaultJSONModeRetries = 2
+
+// useJSONMode reports whether GenerateObject should use prompt-based JSON mode
+func useJSONMode(opts GenerateObjectOptions) bool {
+	if opts.OutputMode != ObjectModeObject && opts.OutputMode != ObjectModeArray {
+		return false
+	}
+	if opts.JSONMode != nil && opts.JSONMode.Force {
+		return true
+	}
+	return !opts.Model.SupportsStructuredOutput()
+}
+
+// generateJSONMode generates an object or array by describing the schema in
+// the system prompt, extracting and repairing the JSON in the reply, and
+// retrying with the validation error when the output does not match
+func generateJSONMode(ctx context.Context, opts GenerateObjectOptions) (*GenerateObjectResult, error) {
+	maxRetries := defaultJSONModeRetries
+	instructions := ""
+	if opts.JSONMode != nil {
+		instructions = opts.JSONMode.Instructions
+		if opts.JSONMode.MaxRetries != 0 {
+			maxRetries = opts.JSONMode.MaxRetries
+		}
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if instructions == "" {
+		instructions = jsonModeInstructions(opts)
+	}
+
+	system := instructions
+	if opts.System != "" {
+		system = opts.System + "\n\n" + instructions
+	}
+
+	genOpts := &provider.GenerateOptions{
+		Prompt:           buildPrompt(opts.Prompt, opts.Messages, system),
+		Temperature:      opts.Temperature,
+		MaxTokens:        opts.MaxTokens,
+		TopP:             opts.TopP,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+		Seed:             opts.Seed,
+		Telemetry:        opts.ExperimentalTelemetry,
+		Metadata:         opts.Metadata,
+	}
+
+	var usage types.Usage
+	warnings := []types.Warning{{
+		Type:    "other",
+		Feature: "structured-output",
+		Details: "model does not support structured output; used prompt-based JSON mode",
+	}}
+
+	for attempt := 0; ; attempt++ {
+		genResult, err := opts.Model.DoGenerate(ctx, genOpts)
+		if err != nil {
+			return nil, fmt.Errorf("generation failed: %w", err)
+		}
+		usage = usage.Add(genResult.Usage)
+		warnings = append(warnings, genResult.Warnings...)
+
+		value, err := parseJSONModeOutput(genResult.Text)
+		parsed := err == nil
+		if parsed {
+			err = validateJSONModeOutput(opts, value)
+		}
+		if err == nil {
+			text, _ := json.Marshal(value)
+			result := &GenerateObjectResult{
+				Text:         string(text),
+				FinishReason: genResult.FinishReason,
+				Usage:        usage,
+				Warnings:     warnings,
+			}
+			if opts.OutputMode == ObjectModeArray {
+				result.Array, _ = value.([]interface{})
+			} else {
+				result.Object = value
+			}
+
+			if opts.OnFinish != nil {
+				opts.OnFinish(ctx, result, opts.ExperimentalContext)
+			}
+			return result, nil
+		}
+
+		if attempt >= maxRetries {
+			noObj := noObjectError(err, parsed, genResult.Text, usage, genResult.FinishReason)
+			noObj.Message = fmt.Sprintf("No object generated: JSON mode output invalid after %d attempts", attempt+1)
+			noObj.Attempts = attempt + 1
+			return nil, noObj
+		}
+
+		// Show the model its previous answer and what was wrong with it
+		messages := make([]types.Message, 0, len(genOpts.Prompt.Messages)+2)
+		messages = append(messages, genOpts.Prompt.Messages...)
+		messages = append(messages,
+			types.Message{
+				Role:    types.RoleAssistant,
+				Content: []types.ContentPart{types.TextContent{Text: genResult.Text}},
+			},
+			types.Message{
+				Role: types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: fmt.Sprintf(
+					"Your previous response was invalid: %v. Respond again with only the corrected JSON.", err)}},
+			},
+		)
+		genOpts.Prompt.Messages = messages
+	}
+}
+
+// jsonModeInstructions builds the system instructions describing the
+// expected output
+func jsonModeInstructions(opts GenerateObjectOptions) string {
+	var b strings.Builder
+	b.WriteString("Respond only with valid JSON. Do not include explanations or markdown.")
+
+	var jsonSchema interface{}
+	if opts.Schema != nil {
+		jsonSchema = opts.Schema.Validator().JSONSchema()
+	}
+	if opts.OutputMode == ObjectModeArray {
+		b.WriteString(" The response must be a JSON array")
+		if jsonSchema != nil {
+			b.WriteString(" whose elements each match this JSON schema")
+		}
+	} else {
+		b.WriteString(" The response must be a JSON object")
+		if jsonSchema != nil {
+			b.WriteString(" matching this JSON schema")
+		}
+	}
+	if jsonSchema != nil {
+		data, err := json.Marshal(jsonSchema)
+		if err == nil {
+			b.WriteString(": " + string(data))
+		}
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// parseJSONModeOutput extracts JSON from free-form model output, repairing
+// fences, surrounding prose, trailing commas and truncation
+func parseJSONModeOutput(text string) (interface{}, error) {
+	value, err := jsonrepair.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+	}
+	return value, nil
+}
+
+// validateJSONModeOutput checks a parsed value against the requested schema
+func validateJSONModeOutput(opts GenerateObjectOptions, value interface{}) error {
+	if opts.OutputMode == ObjectModeArray {
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a JSON array")
+		}
+		return validateElements(opts.Schema, arr)
+	}
+	if err := opts.Schema.Validator().Validate(value); err != nil {
+		return fmt.Errorf("output validation failed: %w", err)
+	}
+	return nil
+}
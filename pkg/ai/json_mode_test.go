@@ -0,0 +1,207 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// requireFieldSchema is a schema whose validator requires one object field
+type requireFieldSchema struct{ field string }
+
+func (s requireFieldSchema) Validator() schema.Validator { return s }
+
+func (s requireFieldSchema) Validate(data interface{}) error {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected object")
+	}
+	if _, ok := obj[s.field]; !ok {
+		return fmt.Errorf("missing required field %q", s.field)
+	}
+	return nil
+}
+
+func (s requireFieldSchema) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "required": []string{s.field}}
+}
+
+func TestParseJSONModeOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`},
+		{"fenced", "Here you go:\n```json\n{\"a\": 1}\n```\nThanks!", `{"a":1}`},
+		{"prose around", `Sure! {"a": [1, 2]} Hope this helps.`, `{"a":[1,2]}`},
+		{"trailing commas", `{"a": [1, 2,], "b": "x,}",}`, `{"a":[1,2],"b":"x,}"}`},
+		{"truncated", `{"a": {"b": "c`, `{"a":{"b":"c"}}`},
+		{"array", "```\n[{\"a\":1},]\n```", `[{"a":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := parseJSONModeOutput(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := mustJSON(t, value)
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := parseJSONModeOutput("no json here"); err == nil {
+		t.Error("expected error for output without JSON")
+	}
+}
+
+func TestGenerateObject_JSONModeFallback(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		StructuredSupport: false,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{
+				Text:         "```json\n{\"name\": \"Ada\",}\n```",
+				FinishReason: types.FinishReasonStop,
+			}, nil
+		},
+	}
+
+	result, err := GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:  model,
+		Prompt: "Who wrote the first program?",
+		System: "Be accurate.",
+		Schema: requireFieldSchema{field: "name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	obj := result.Object.(map[string]interface{})
+	if obj["name"] != "Ada" {
+		t.Errorf("unexpected object: %v", obj)
+	}
+	if result.Text != `{"name":"Ada"}` {
+		t.Errorf("unexpected text: %s", result.Text)
+	}
+	if len(result.Warnings) == 0 || result.Warnings[0].Feature != "structured-output" {
+		t.Errorf("expected JSON mode warning, got %v", result.Warnings)
+	}
+
+	call := model.GenerateCalls[0]
+	if call.ResponseFormat != nil {
+		t.Errorf("expected no response format in JSON mode, got %v", call.ResponseFormat)
+	}
+	if !strings.HasPrefix(call.Prompt.System, "Be accurate.\n\nRespond only with valid JSON") ||
+		!strings.Contains(call.Prompt.System, `"required":["name"]`) {
+		t.Errorf("unexpected system prompt: %q", call.Prompt.System)
+	}
+}
+
+func TestGenerateObject_JSONModeRetriesWithError(t *testing.T) {
+	t.Parallel()
+
+	replies := []string{`{"title": "x"}`, `{"name": "Ada"}`}
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			n := len(opts.Prompt.Messages) / 2
+			one := int64(1)
+			return &types.GenerateResult{
+				Text:  replies[n],
+				Usage: types.Usage{TotalTokens: &one},
+			}, nil
+		},
+	}
+
+	result, err := GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:  model,
+		Prompt: "Name?",
+		Schema: requireFieldSchema{field: "name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(model.GenerateCalls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(model.GenerateCalls))
+	}
+	if result.Usage.TotalTokens == nil || *result.Usage.TotalTokens != 2 {
+		t.Errorf("expected usage summed over attempts, got %v", result.Usage.TotalTokens)
+	}
+
+	retry := model.GenerateCalls[1].Prompt.Messages
+	feedback := retry[len(retry)-1].Content[0].(types.TextContent).Text
+	if !strings.Contains(feedback, `missing required field "name"`) {
+		t.Errorf("expected validation error in retry prompt, got %q", feedback)
+	}
+}
+
+func TestGenerateObject_JSONModeGivesUp(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+
+	_, err := GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:    model,
+		Prompt:   "Name?",
+		Schema:   requireFieldSchema{field: "name"},
+		JSONMode: &JSONModeOptions{MaxRetries: -1},
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(model.GenerateCalls) != 1 {
+		t.Errorf("expected a single attempt, got %d", len(model.GenerateCalls))
+	}
+}
+
+func TestGenerateObject_JSONModeNoObjectGenerated(t *testing.T) {
+	t.Parallel()
+
+	one := int64(1)
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: `{"title": "x"}`, Usage: types.Usage{TotalTokens: &one}}, nil
+		},
+	}
+
+	_, err := GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:    model,
+		Prompt:   "Name?",
+		Schema:   schema.NewSimpleJSONSchema(requireFieldSchema{field: "name"}.JSONSchema()),
+		JSONMode: &JSONModeOptions{MaxRetries: 2},
+	})
+	var noObj *NoObjectGeneratedError
+	if !errors.As(err, &noObj) {
+		t.Fatalf("expected *NoObjectGeneratedError, got %T: %v", err, err)
+	}
+	if noObj.Attempts != 3 || noObj.Usage.GetTotalTokens() != 3 || noObj.Text != `{"title": "x"}` {
+		t.Errorf("unexpected diagnostics %+v", noObj)
+	}
+	if len(noObj.ValidationErrors) != 1 || noObj.ValidationErrors[0].Keyword != "required" {
+		t.Errorf("unexpected validation errors %v", noObj.ValidationErrors)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(data)
+}
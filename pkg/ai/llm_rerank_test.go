@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// scoringModel scores each prompt document by how often it mentions "go",
+// skipping documents that mention "skip"
+func scoringModel(calls *atomic.Int32) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			calls.Add(1)
+			prompt := opts.Prompt.Messages[len(opts.Prompt.Messages)-1].Content[0].(types.TextContent).Text
+			var scores []string
+			for _, line := range strings.Split(prompt, "\n") {
+				var index int
+				if _, err := fmt.Sscanf(line, "[%d]", &index); err != nil || strings.Contains(line, "skip") {
+					continue
+				}
+				scores = append(scores, fmt.Sprintf(`{"index": %d, "score": %d}`, index, 3*strings.Count(strings.ToLower(line), "go")))
+			}
+			return &types.GenerateResult{
+				Text:         `{"scores": [` + strings.Join(scores, ",") + `]}`,
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{InputTokens: int64Ptr(10), OutputTokens: int64Ptr(5)},
+			}, nil
+		},
+	}
+}
+
+func TestLLMReranker(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	reranker := NewLLMReranker(scoringModel(&calls), LLMRerankerOptions{BatchSize: 2})
+	topN := 3
+	result, err := Rerank(context.Background(), RerankOptions{
+		Model:     reranker,
+		Query:     "go concurrency",
+		Documents: []string{"Python threads", "Go goroutines", "skip me", "Go channels, go select, go vet", "Rust async"},
+		TopN:      &topN,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 batched calls, got %d", calls.Load())
+	}
+	if len(result.Ranking) != 3 || result.Ranking[0].OriginalIndex != 3 || result.Ranking[1].OriginalIndex != 1 {
+		t.Fatalf("ranking = %+v", result.Ranking)
+	}
+	if result.Ranking[0].Score != 0.9 || result.Ranking[0].Document != "Go channels, go select, go vet" {
+		t.Errorf("top item = %+v", result.Ranking[0])
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Details, "2") {
+		t.Errorf("warnings = %+v", result.Warnings)
+	}
+	usage := result.ProviderMetadata.(map[string]interface{})["llm"].(map[string]interface{})["usage"].(types.Usage)
+	if usage.GetInputTokens() != 30 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestLLMReranker_StructuredDocuments(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	result, err := NewLLMReranker(scoringModel(&calls), LLMRerankerOptions{MaxDocumentChars: 20}).DoRerank(context.Background(), &provider.RerankOptions{
+		Query:     "go",
+		Documents: []map[string]interface{}{{"title": "Rust"}, {"title": "Go"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 || result.Ranking[0].Index != 1 || len(result.Warnings) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+
+	if _, err := NewLLMReranker(scoringModel(&calls), LLMRerankerOptions{}).DoRerank(context.Background(), &provider.RerankOptions{
+		Query:     "go",
+		Documents: []int{1},
+	}); err == nil {
+		t.Error("expected an unsupported documents error")
+	}
+}
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestGenerateText_Metadata(t *testing.T) {
+	t.Parallel()
+
+	var sent map[string]string
+	var telemetryTeam string
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			sent = opts.Metadata
+			if opts.Telemetry != nil {
+				telemetryTeam = opts.Telemetry.Metadata["team"].AsString()
+			}
+			return &types.GenerateResult{Text: "ok", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	var started, finished map[string]any
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    model,
+		Prompt:   "hi",
+		Metadata: map[string]string{"team": "search", "feature": "autocomplete"},
+		OnStart: func(_ context.Context, e OnStartEvent) {
+			started = e.Metadata
+		},
+		OnFinishEvent: func(_ context.Context, e OnFinishEvent) {
+			finished = e.Metadata
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent["team"] != "search" || sent["feature"] != "autocomplete" {
+		t.Errorf("expected metadata forwarded to provider, got %v", sent)
+	}
+	if telemetryTeam != "search" {
+		t.Errorf("expected metadata in telemetry settings, got %q", telemetryTeam)
+	}
+	if started["team"] != "search" || finished["feature"] != "autocomplete" {
+		t.Errorf("expected metadata on callback events, got start=%v finish=%v", started, finished)
+	}
+}
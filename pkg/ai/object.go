@@ -3,8 +3,10 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/digitallysavvy/go-ai/pkg/jsonparser"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -51,6 +53,10 @@ type GenerateObjectOptions struct {
 	// Enum values (required for enum mode)
 	EnumValues []string
 
+	// JSONMode configures the prompt-based JSON mode used for object and
+	// array output when the model has no native structured output support
+	JSONMode *JSONModeOptions
+
 	// Generation parameters
 	Temperature      *float64
 	MaxTokens        *int
@@ -62,6 +68,11 @@ type GenerateObjectOptions struct {
 	// Telemetry configuration for observability
 	ExperimentalTelemetry *TelemetrySettings
 
+	// Metadata tags this request (e.g. feature, team, user) for cost
+	// attribution. It is added to telemetry span attributes and callback
+	// events, and forwarded to providers that support request metadata.
+	Metadata map[string]string
+
 	// Callbacks
 	OnFinish func(ctx context.Context, result *GenerateObjectResult, userContext interface{})
 
@@ -105,9 +116,11 @@ func GenerateObject(ctx context.Context, opts GenerateObjectOptions) (*GenerateO
 	if opts.Model == nil {
 		return nil, fmt.Errorf("model is required")
 	}
+	opts.ExperimentalTelemetry = withRequestMetadata(opts.ExperimentalTelemetry, opts.Metadata)
 
 	// Create telemetry span if enabled
 	var span trace.Span
+	var payloadMode telemetry.PayloadMode
 	if opts.ExperimentalTelemetry != nil && opts.ExperimentalTelemetry.IsEnabled {
 		tracer := telemetry.GetTracer(opts.ExperimentalTelemetry)
 
@@ -119,6 +132,8 @@ func GenerateObject(ctx context.Context, opts GenerateObjectOptions) (*GenerateO
 
 		ctx, span = tracer.Start(ctx, spanName)
 		defer span.End()
+		span = telemetry.FilterSpan(span, opts.ExperimentalTelemetry)
+		payloadMode = opts.ExperimentalTelemetry.SamplePayloadMode()
 
 		// Add base telemetry attributes
 		span.SetAttributes(
@@ -143,7 +158,9 @@ func GenerateObject(ctx context.Context, opts GenerateObjectOptions) (*GenerateO
 
 		// Record prompt if enabled
 		if opts.ExperimentalTelemetry.RecordInputs && opts.Prompt != "" {
-			span.SetAttributes(attribute.String("ai.prompt", opts.Prompt))
+			if prompt, ok := telemetry.RedactPayload(payloadMode, opts.Prompt); ok {
+				span.SetAttributes(attribute.String("ai.prompt", prompt))
+			}
 		}
 	}
 
@@ -168,22 +185,25 @@ func GenerateObject(ctx context.Context, opts GenerateObjectOptions) (*GenerateO
 		return nil, fmt.Errorf("invalid output mode: %s", opts.OutputMode)
 	}
 
-	// Check if model supports structured output (except for no-schema mode)
-	if opts.OutputMode != ObjectModeNoSchema && !opts.Model.SupportsStructuredOutput() {
+	// Enum mode has no prompt-based fallback
+	if opts.OutputMode == ObjectModeEnum && !opts.Model.SupportsStructuredOutput() {
 		return nil, fmt.Errorf("model does not support structured output")
 	}
 
-	// Handle different modes
+	// Handle different modes. Object and array output fall back to
+	// prompt-based JSON mode for models without structured output support.
 	var result *GenerateObjectResult
 	var err error
-	switch opts.OutputMode {
-	case ObjectModeObject:
+	switch {
+	case useJSONMode(opts):
+		result, err = generateJSONMode(ctx, opts)
+	case opts.OutputMode == ObjectModeObject:
 		result, err = generateObjectMode(ctx, opts)
-	case ObjectModeArray:
+	case opts.OutputMode == ObjectModeArray:
 		result, err = generateArrayMode(ctx, opts)
-	case ObjectModeEnum:
+	case opts.OutputMode == ObjectModeEnum:
 		result, err = generateEnumMode(ctx, opts)
-	case ObjectModeNoSchema:
+	case opts.OutputMode == ObjectModeNoSchema:
 		result, err = generateNoSchemaMode(ctx, opts)
 	default:
 		return nil, fmt.Errorf("unsupported output mode: %s", opts.OutputMode)
@@ -193,7 +213,9 @@ func GenerateObject(ctx context.Context, opts GenerateObjectOptions) (*GenerateO
 	if span != nil && result != nil {
 		// Record output if enabled
 		if opts.ExperimentalTelemetry.RecordOutputs {
-			span.SetAttributes(attribute.String("ai.response.text", result.Text))
+			if text, ok := telemetry.RedactPayload(payloadMode, result.Text); ok {
+				span.SetAttributes(attribute.String("ai.response.text", text))
+			}
 		}
 
 		// Record finish reason
@@ -231,6 +253,7 @@ func generateObjectMode(ctx context.Context, opts GenerateObjectOptions) (*Gener
 			Schema: opts.Schema,
 		},
 		Telemetry: opts.ExperimentalTelemetry,
+		Metadata:  opts.Metadata,
 	}
 
 	genResult, err := opts.Model.DoGenerate(ctx, genOpts)
@@ -240,11 +263,11 @@ func generateObjectMode(ctx context.Context, opts GenerateObjectOptions) (*Gener
 
 	var obj interface{}
 	if err := json.Unmarshal([]byte(genResult.Text), &obj); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+		return nil, noObjectError(err, false, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
 	if err := opts.Schema.Validator().Validate(obj); err != nil {
-		return nil, fmt.Errorf("output validation failed: %w", err)
+		return nil, noObjectError(err, true, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
 	result := &GenerateObjectResult{
@@ -279,6 +302,7 @@ func generateArrayMode(ctx context.Context, opts GenerateObjectOptions) (*Genera
 			Schema: opts.Schema,
 		},
 		Telemetry: opts.ExperimentalTelemetry,
+		Metadata:  opts.Metadata,
 	}
 
 	genResult, err := opts.Model.DoGenerate(ctx, genOpts)
@@ -288,14 +312,11 @@ func generateArrayMode(ctx context.Context, opts GenerateObjectOptions) (*Genera
 
 	var arr []interface{}
 	if err := json.Unmarshal([]byte(genResult.Text), &arr); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON array: %w", err)
+		return nil, noObjectError(err, false, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
-	// Validate each element
-	for i, element := range arr {
-		if err := opts.Schema.Validator().Validate(element); err != nil {
-			return nil, fmt.Errorf("validation failed for element %d: %w", i, err)
-		}
+	if err := validateElements(opts.Schema, arr); err != nil {
+		return nil, noObjectError(err, true, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
 	result := &GenerateObjectResult{
@@ -333,6 +354,7 @@ func generateEnumMode(ctx context.Context, opts GenerateObjectOptions) (*Generat
 			Type: "json_object",
 		},
 		Telemetry: opts.ExperimentalTelemetry,
+		Metadata:  opts.Metadata,
 	}
 
 	genResult, err := opts.Model.DoGenerate(ctx, genOpts)
@@ -353,7 +375,11 @@ func generateEnumMode(ctx context.Context, opts GenerateObjectOptions) (*Generat
 	}
 
 	if !valid {
-		return nil, fmt.Errorf("invalid enum value: %s (expected one of %v)", selectedValue, opts.EnumValues)
+		cause := schema.ValidationErrors{{
+			Keyword: "enum",
+			Message: fmt.Sprintf("invalid enum value: %s (expected one of %v)", selectedValue, opts.EnumValues),
+		}}
+		return nil, noObjectError(cause, true, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
 	result := &GenerateObjectResult{
@@ -387,6 +413,7 @@ func generateNoSchemaMode(ctx context.Context, opts GenerateObjectOptions) (*Gen
 			Type: "json_object",
 		},
 		Telemetry: opts.ExperimentalTelemetry,
+		Metadata:  opts.Metadata,
 	}
 
 	genResult, err := opts.Model.DoGenerate(ctx, genOpts)
@@ -396,7 +423,7 @@ func generateNoSchemaMode(ctx context.Context, opts GenerateObjectOptions) (*Gen
 
 	var obj interface{}
 	if err := json.Unmarshal([]byte(genResult.Text), &obj); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON output: %w", err)
+		return nil, noObjectError(err, false, genResult.Text, genResult.Usage, genResult.FinishReason)
 	}
 
 	result := &GenerateObjectResult{
@@ -434,6 +461,86 @@ func GenerateObjectInto(ctx context.Context, opts GenerateObjectOptions, target
 	return nil
 }
 
+// TypedObjectResult is the result of GenerateObjectAs
+type TypedObjectResult[T any] struct {
+	// The generated object
+	Object T
+
+	// Raw JSON text
+	Text string
+
+	// Finish reason
+	FinishReason types.FinishReason
+
+	// Token usage information
+	Usage types.Usage
+
+	// Warnings from the provider
+	Warnings []types.Warning
+}
+
+// GenerateObjectAs generates an object of type T. When opts.Schema is nil it
+// is derived from T with SchemaFor, honoring json and jsonschema struct tags.
+// A slice T generates in array mode with the element type's schema. The
+// response is validated before decoding; failures return a
+// *NoObjectGeneratedError.
+//
+// Example:
+//
+//	type Recipe struct {
+//	    Name  string   `json:"name" jsonschema:"description=Dish name"`
+//	    Steps []string `json:"steps" jsonschema:"minItems=1"`
+//	}
+//
+//	result, err := ai.GenerateObjectAs[Recipe](ctx, ai.GenerateObjectOptions{
+//	    Model:  model,
+//	    Prompt: "A lasagna recipe",
+//	})
+//	fmt.Println(result.Object.Name)
+func GenerateObjectAs[T any](ctx context.Context, opts GenerateObjectOptions) (*TypedObjectResult[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	isArray := t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+	if opts.OutputMode == "" && isArray {
+		opts.OutputMode = ObjectModeArray
+	}
+	if opts.Schema == nil {
+		if opts.OutputMode == ObjectModeArray && isArray {
+			opts.Schema = schema.NewSimpleJSONSchema(reflectJSONSchema(t.Elem()))
+		} else {
+			opts.Schema = SchemaFor[T]()
+		}
+	}
+
+	result, err := GenerateObject(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch opts.OutputMode {
+	case ObjectModeArray:
+		value = result.Array
+	case ObjectModeEnum:
+		value = result.EnumValue
+	default:
+		value = result.Object
+	}
+	typed := &TypedObjectResult[T]{
+		Text:         result.Text,
+		FinishReason: result.FinishReason,
+		Usage:        result.Usage,
+		Warnings:     result.Warnings,
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(jsonBytes, &typed.Object)
+	}
+	if err != nil {
+		return nil, noObjectError(fmt.Errorf("failed to decode into %s: %w", t, err), true, result.Text, result.Usage, result.FinishReason)
+	}
+	return typed, nil
+}
+
 // StreamObjectOptions contains options for streaming object generation
 type StreamObjectOptions struct {
 	// Model to use for generation
@@ -458,6 +565,11 @@ type StreamObjectOptions struct {
 	// Telemetry configuration for observability
 	ExperimentalTelemetry *TelemetrySettings
 
+	// Metadata tags this request (e.g. feature, team, user) for cost
+	// attribution. It is added to telemetry span attributes and callback
+	// events, and forwarded to providers that support request metadata.
+	Metadata map[string]string
+
 	// Callbacks
 	OnChunk  func(partialObject interface{})
 	OnFinish func(ctx context.Context, result *GenerateObjectResult, userContext interface{})
@@ -480,6 +592,7 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 	if opts.Schema == nil {
 		return nil, fmt.Errorf("schema is required")
 	}
+	opts.ExperimentalTelemetry = withRequestMetadata(opts.ExperimentalTelemetry, opts.Metadata)
 
 	// Build prompt
 	prompt := buildPrompt(opts.Prompt, opts.Messages, opts.System)
@@ -498,6 +611,7 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 			Schema: opts.Schema,
 		},
 		Telemetry: opts.ExperimentalTelemetry,
+		Metadata:  opts.Metadata,
 	}
 
 	// Try to start streaming
@@ -513,12 +627,12 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 		// Parse final JSON
 		var finalObject interface{}
 		if err := json.Unmarshal([]byte(result.Text), &finalObject); err != nil {
-			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+			return nil, noObjectError(err, false, result.Text, result.Usage, result.FinishReason)
 		}
 
 		// Validate
 		if err := opts.Schema.Validator().Validate(finalObject); err != nil {
-			return nil, fmt.Errorf("validation failed: %w", err)
+			return nil, noObjectError(err, true, result.Text, result.Usage, result.FinishReason)
 		}
 
 		finalResult := &GenerateObjectResult{
@@ -564,8 +678,8 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 
 			// If we successfully parsed something and it's different from last
 			if parseResult.Value != nil && !deepEqual(parseResult.Value, lastObject) {
-				// Validate against schema
-				if err := opts.Schema.Validator().Validate(parseResult.Value); err == nil {
+				// Validate against schema; fields still to come may be missing
+				if partialValid(opts.Schema, parseResult.Value) {
 					// Valid partial object - emit it
 					lastObject = parseResult.Value
 
@@ -577,14 +691,15 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 			}
 
 		case provider.ChunkTypeUsage:
+			// Usage reports are cumulative; the latest one wins
 			if chunk.Usage != nil {
-				usage = usage.Add(*chunk.Usage)
+				usage = *chunk.Usage
 			}
 
 		case provider.ChunkTypeFinish:
 			finishReason = chunk.FinishReason
 			if chunk.Usage != nil {
-				usage = usage.Add(*chunk.Usage)
+				usage = *chunk.Usage
 			}
 		}
 	}
@@ -593,12 +708,12 @@ func StreamObject(ctx context.Context, opts StreamObjectOptions) (*GenerateObjec
 	var finalObject interface{}
 	if accumulatedText != "" {
 		if err := json.Unmarshal([]byte(accumulatedText), &finalObject); err != nil {
-			return nil, fmt.Errorf("failed to parse final JSON: %w", err)
+			return nil, noObjectError(err, false, accumulatedText, usage, finishReason)
 		}
 
 		// Validate final object
 		if err := opts.Schema.Validator().Validate(finalObject); err != nil {
-			return nil, fmt.Errorf("final object validation failed: %w", err)
+			return nil, noObjectError(err, true, accumulatedText, usage, finishReason)
 		}
 	}
 
@@ -635,3 +750,44 @@ func deepEqual(a, b interface{}) bool {
 
 	return reflect.DeepEqual(a, b)
 }
+
+// validateElements validates each array element, locating violations with
+// JSON pointers into the array
+func validateElements(s schema.Schema, arr []interface{}) error {
+	for i, element := range arr {
+		err := s.Validator().Validate(element)
+		if err == nil {
+			continue
+		}
+		var violations schema.ValidationErrors
+		if !errors.As(err, &violations) {
+			return fmt.Errorf("validation failed for element %d: %w", i, err)
+		}
+		prefixed := make(schema.ValidationErrors, len(violations))
+		for j, v := range violations {
+			v.Pointer = "/" + strconv.Itoa(i) + v.Pointer
+			prefixed[j] = v
+		}
+		return prefixed
+	}
+	return nil
+}
+
+// partialValid reports whether a partially streamed value can still become
+// valid: it may only be missing required properties
+func partialValid(s schema.Schema, value interface{}) bool {
+	err := s.Validator().Validate(value)
+	if err == nil {
+		return true
+	}
+	var violations schema.ValidationErrors
+	if !errors.As(err, &violations) {
+		return false
+	}
+	for _, v := range violations {
+		if v.Keyword != "required" {
+			return false
+		}
+	}
+	return true
+}
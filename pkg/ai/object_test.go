@@ -242,6 +242,24 @@ func TestGenerateObject_StructuredOutputUnsupported(t *testing.T) {
 	}
 }
 
+func TestGenerateObject_EnumStructuredOutputUnsupported(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{StructuredSupport: false}
+	_, err := GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:      model,
+		Prompt:     "Classify",
+		OutputMode: ObjectModeEnum,
+		EnumValues: []string{"positive", "negative"},
+	})
+	if err == nil {
+		t.Fatal("expected error for enum mode without structured output")
+	}
+	if len(model.GenerateCalls) != 0 {
+		t.Error("expected no model call")
+	}
+}
+
 func TestGenerateObject_JSONParseError(t *testing.T) {
 	t.Parallel()
 
@@ -268,6 +286,55 @@ func TestGenerateObject_JSONParseError(t *testing.T) {
 	}
 }
 
+func TestGenerateObject_NoObjectGeneratedDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	tokens := int64(12)
+	reply := func(text string) *testutil.MockLanguageModel {
+		return &testutil.MockLanguageModel{
+			StructuredSupport: true,
+			DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+				return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonLength, Usage: types.Usage{TotalTokens: &tokens}}, nil
+			},
+		}
+	}
+	testSchema := schema.NewSimpleJSONSchema(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"name"},
+		"properties": map[string]interface{}{
+			"age": map[string]interface{}{"type": "integer"},
+		},
+	})
+
+	_, err := GenerateObject(context.Background(), GenerateObjectOptions{Model: reply(`{"age": "ten"}`), Prompt: "Person", Schema: testSchema})
+	var noObj *NoObjectGeneratedError
+	if !errors.As(err, &noObj) {
+		t.Fatalf("expected *NoObjectGeneratedError, got %T: %v", err, err)
+	}
+	if noObj.Text != `{"age": "ten"}` || noObj.FinishReason != types.FinishReasonLength || noObj.Usage.GetTotalTokens() != 12 || noObj.Attempts != 1 {
+		t.Errorf("unexpected diagnostics %+v", noObj)
+	}
+	if noObj.ParseError != nil || len(noObj.ValidationErrors) != 2 ||
+		noObj.ValidationErrors[0].Pointer != "" || noObj.ValidationErrors[1].Pointer != "/age" {
+		t.Errorf("unexpected validation errors %v (parse error %v)", noObj.ValidationErrors, noObj.ParseError)
+	}
+
+	_, err = GenerateObject(context.Background(), GenerateObjectOptions{Model: reply(`{"name": `), Prompt: "Person", Schema: testSchema})
+	if !errors.As(err, &noObj) || noObj.ParseError == nil || noObj.ValidationErrors != nil {
+		t.Errorf("expected parse error diagnostics, got %v", err)
+	}
+
+	_, err = GenerateObject(context.Background(), GenerateObjectOptions{
+		Model:      reply(`[{"name": "a"}, {"age": 3}]`),
+		Prompt:     "People",
+		Schema:     testSchema,
+		OutputMode: ObjectModeArray,
+	})
+	if !errors.As(err, &noObj) || len(noObj.ValidationErrors) != 1 || noObj.ValidationErrors[0].Pointer != "/1" {
+		t.Errorf("expected element pointer, got %v", err)
+	}
+}
+
 func TestGenerateObject_ArrayParseError(t *testing.T) {
 	t.Parallel()
 
@@ -579,3 +646,56 @@ func TestGenerateObject_RawText(t *testing.T) {
 		t.Error("text content mismatch")
 	}
 }
+
+func TestGenerateObjectAs(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		SKU      string `json:"sku"`
+		Quantity int    `json:"quantity" jsonschema:"minimum=1"`
+	}
+	reply := func(text string, got *provider.GenerateOptions) *testutil.MockLanguageModel {
+		return &testutil.MockLanguageModel{
+			StructuredSupport: true,
+			DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+				*got = *opts
+				return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+			},
+		}
+	}
+
+	var opts provider.GenerateOptions
+	result, err := GenerateObjectAs[Item](context.Background(), GenerateObjectOptions{
+		Model:  reply(`{"sku": "A-1", "quantity": 2}`, &opts),
+		Prompt: "An item",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Object != (Item{SKU: "A-1", Quantity: 2}) {
+		t.Errorf("unexpected object %+v", result.Object)
+	}
+	if opts.ResponseFormat == nil || opts.ResponseFormat.Schema == nil {
+		t.Fatal("expected the reflected schema to be sent")
+	}
+
+	items, err := GenerateObjectAs[[]Item](context.Background(), GenerateObjectOptions{
+		Model:  reply(`[{"sku": "A-1", "quantity": 2}, {"sku": "B-2", "quantity": 1}]`, &opts),
+		Prompt: "Two items",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items.Object) != 2 || items.Object[1].SKU != "B-2" {
+		t.Errorf("unexpected items %+v", items.Object)
+	}
+
+	_, err = GenerateObjectAs[Item](context.Background(), GenerateObjectOptions{
+		Model:  reply(`{"sku": "A-1", "quantity": 0}`, &opts),
+		Prompt: "An item",
+	})
+	var noObj *NoObjectGeneratedError
+	if !errors.As(err, &noObj) || len(noObj.ValidationErrors) != 1 || noObj.ValidationErrors[0].Pointer != "/quantity" {
+		t.Errorf("expected a validation error at /quantity, got %v", err)
+	}
+}
@@ -3,9 +3,12 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/jsonutil"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -61,11 +64,19 @@ type outputProcessor interface {
 // JSON struct tags to generate a JSON Schema. This is a convenience helper for
 // use with ObjectOutput[T] and ArrayOutput[T].
 //
+// Fields are required unless tagged omitempty. A jsonschema tag adds
+// constraints, comma-separated (write a comma inside a value as \\, in the tag):
+//
+//	description=...  title=...  format=...  pattern=...  default=...
+//	enum=a|b|c  minimum=N  maximum=N  minLength=N  maxLength=N
+//	minItems=N  maxItems=N  required  optional
+//
 // Example:
 //
 //	type Recipe struct {
-//	    Name        string   `json:"name"`
-//	    Ingredients []string `json:"ingredients"`
+//	    Name        string   `json:"name" jsonschema:"description=Dish name"`
+//	    Ingredients []string `json:"ingredients" jsonschema:"minItems=1"`
+//	    Difficulty  string   `json:"difficulty" jsonschema:"enum=easy|medium|hard"`
 //	}
 //
 //	output := ObjectOutput[Recipe](ObjectOutputOptions{
@@ -78,8 +89,19 @@ func SchemaFor[T any]() schema.Schema {
 	return schema.NewSimpleJSONSchema(jsonSchema)
 }
 
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
 // reflectJSONSchema generates a JSON Schema map from a reflect.Type.
 func reflectJSONSchema(t reflect.Type) map[string]interface{} {
+	return reflectSchema(t, map[reflect.Type]bool{})
+}
+
+// reflectSchema generates the schema for t. visiting holds the structs
+// being expanded, so recursive types end in an unconstrained object.
+func reflectSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
 	if t == nil {
 		return map[string]interface{}{"type": "object"}
 	}
@@ -87,6 +109,12 @@ func reflectJSONSchema(t reflect.Type) map[string]interface{} {
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	}
 	switch t.Kind() {
 	case reflect.String:
 		return map[string]interface{}{"type": "string"}
@@ -97,14 +125,14 @@ func reflectJSONSchema(t reflect.Type) map[string]interface{} {
 		return map[string]interface{}{"type": "number"}
 	case reflect.Bool:
 		return map[string]interface{}{"type": "boolean"}
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		if t.Elem().Kind() == reflect.Uint8 {
 			// []byte -> string (base64)
 			return map[string]interface{}{"type": "string"}
 		}
 		return map[string]interface{}{
 			"type":  "array",
-			"items": reflectJSONSchema(t.Elem()),
+			"items": reflectSchema(t.Elem(), visiting),
 		}
 	case reflect.Map:
 		return map[string]interface{}{
@@ -112,32 +140,15 @@ func reflectJSONSchema(t reflect.Type) map[string]interface{} {
 			"additionalProperties": true,
 		}
 	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
 		properties := map[string]interface{}{}
 		required := []string{}
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			if !field.IsExported() {
-				continue
-			}
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "-" {
-				continue
-			}
-			name := jsonTag
-			omitempty := false
-			if idx := strings.Index(name, ","); idx >= 0 {
-				opts := name[idx+1:]
-				name = name[:idx]
-				omitempty = strings.Contains(opts, "omitempty")
-			}
-			if name == "" {
-				name = field.Name
-			}
-			properties[name] = reflectJSONSchema(field.Type)
-			if !omitempty {
-				required = append(required, name)
-			}
-		}
+		addStructFields(t, visiting, properties, &required)
 		result := map[string]interface{}{
 			"type":       "object",
 			"properties": properties,
@@ -146,19 +157,180 @@ func reflectJSONSchema(t reflect.Type) map[string]interface{} {
 			result["required"] = required
 		}
 		return result
+	case reflect.Interface:
+		return map[string]interface{}{}
 	default:
 		return map[string]interface{}{"type": "object"}
 	}
 }
 
-// NoObjectGeneratedError is returned when object generation fails
+// addStructFields adds t's JSON fields to properties, flattening embedded
+// structs the way encoding/json does
+func addStructFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name := jsonTag
+		omitempty := false
+		if idx := strings.Index(name, ","); idx >= 0 {
+			opts := name[idx+1:]
+			name = name[:idx]
+			omitempty = strings.Contains(opts, "omitempty")
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			addStructFields(fieldType, visiting, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := reflectSchema(field.Type, visiting)
+		isRequired := !omitempty
+		if tag, ok := field.Tag.Lookup("jsonschema"); ok {
+			isRequired = applySchemaTag(prop, tag, isRequired)
+		}
+		properties[name] = prop
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// applySchemaTag adds the constraints of a jsonschema struct tag to prop and
+// returns whether the field is required
+func applySchemaTag(prop map[string]interface{}, tag string, required bool) bool {
+	for _, option := range splitSchemaTag(tag) {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "description", "title", "format", "pattern":
+			prop[key] = value
+		case "default":
+			prop[key] = schemaTagValue(prop, value)
+		case "enum":
+			values := []interface{}{}
+			for _, v := range strings.Split(value, "|") {
+				values = append(values, schemaTagValue(prop, v))
+			}
+			prop[key] = values
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				prop[key] = n
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			if n, err := strconv.Atoi(value); err == nil {
+				prop[key] = n
+			}
+		}
+	}
+	return required
+}
+
+// splitSchemaTag splits a jsonschema tag on commas not escaped as \,
+func splitSchemaTag(tag string) []string {
+	var options []string
+	var current strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			options = append(options, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(tag[i])
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		options = append(options, s)
+	}
+	return options
+}
+
+// schemaTagValue converts a tag literal to the property's JSON type
+func schemaTagValue(prop map[string]interface{}, value string) interface{} {
+	switch prop["type"] {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// NoObjectGeneratedError is returned when object generation fails. It
+// carries what the model produced so callers can log or recover it.
 type NoObjectGeneratedError struct {
-	Message      string
-	Cause        error
-	Text         string
-	Response     *types.ResponseMetadata
+	Message string
+	Cause   error
+
+	// Text is the raw text of the last attempt
+	Text string
+
+	Response *types.ResponseMetadata
+
+	// Usage of the failed call, summed across attempts when retried
 	Usage        *types.Usage
 	FinishReason types.FinishReason
+
+	// ParseError is set when the text was not valid JSON
+	ParseError error
+
+	// ValidationErrors locate each schema violation with a JSON pointer,
+	// when the validator reports them
+	ValidationErrors []schema.ValidationError
+
+	// Attempts is the number of generations tried (1 without retries)
+	Attempts int
+}
+
+// noObjectError builds a NoObjectGeneratedError for output that could not
+// be parsed, or that was parsed but failed validation
+func noObjectError(cause error, parsed bool, text string, usage types.Usage, finishReason types.FinishReason) *NoObjectGeneratedError {
+	e := &NoObjectGeneratedError{
+		Message:      "No object generated: could not parse the response",
+		Cause:        cause,
+		Text:         text,
+		Usage:        &usage,
+		FinishReason: finishReason,
+		Attempts:     1,
+	}
+	if !parsed {
+		e.ParseError = cause
+		return e
+	}
+	e.Message = "No object generated: response did not match schema"
+	e.ValidationErrors = validationErrors(cause)
+	return e
+}
+
+// validationErrors returns the schema violations reported in err, if any
+func validationErrors(err error) []schema.ValidationError {
+	var violations schema.ValidationErrors
+	if errors.As(err, &violations) {
+		return violations
+	}
+	return nil
 }
 
 func (e *NoObjectGeneratedError) Error() string {
@@ -299,18 +471,22 @@ func (o *objectOutput[T]) ParseCompleteOutput(ctx context.Context, options Parse
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			ParseError:   err,
+			Attempts:     1,
 		}
 	}
 
 	// Validate against schema
 	if err := o.schema.Validator().Validate(result); err != nil {
 		return zero, &NoObjectGeneratedError{
-			Message:      "No object generated: response did not match schema",
-			Cause:        err,
-			Text:         options.Text,
-			Response:     options.Response,
-			Usage:        options.Usage,
-			FinishReason: options.FinishReason,
+			Message:          "No object generated: response did not match schema",
+			Cause:            err,
+			Text:             options.Text,
+			Response:         options.Response,
+			Usage:            options.Usage,
+			FinishReason:     options.FinishReason,
+			ValidationErrors: validationErrors(err),
+			Attempts:         1,
 		}
 	}
 
@@ -373,6 +549,13 @@ type ArrayOutputOptions[ELEMENT any] struct {
 	// Description is an optional description of the output
 	// Used by some providers for additional LLM guidance (e.g., via tool or schema description)
 	Description string
+
+	// OnElement is called for each array element once it has been fully
+	// received and passed schema validation, as a StreamText result is
+	// consumed (ReadAll or the StreamText callbacks). Return
+	// ErrStopElementStream to stop generation early, or any other error to
+	// abort the stream with that error.
+	OnElement func(ctx context.Context, element ElementStreamResult[ELEMENT]) error
 }
 
 // arrayOutput is the implementation of Output for array generation
@@ -380,6 +563,7 @@ type arrayOutput[ELEMENT any] struct {
 	elementSchema schema.Schema
 	name          string
 	description   string
+	onElement     func(ctx context.Context, element ElementStreamResult[ELEMENT]) error
 }
 
 // ArrayOutput creates an output specification for generating arrays of elements
@@ -387,8 +571,9 @@ type arrayOutput[ELEMENT any] struct {
 //
 // Element Streaming:
 // ArrayOutput supports element streaming, which allows you to receive individual
-// array elements as they are generated during streaming. Use the ElementStream or
-// ElementStreamWithOutput functions to access this functionality.
+// array elements as they are generated during streaming. Set OnElement to be
+// called for each validated element as the StreamText result is consumed, or
+// use the ElementStream or ElementStreamWithOutput functions.
 //
 // Example (Standard):
 //
@@ -400,16 +585,24 @@ type arrayOutput[ELEMENT any] struct {
 //
 // Example (With Element Streaming):
 //
-//	result, _ := StreamText(ctx, StreamTextOptions{...})
-//	elements := ElementStreamWithOutput(result, output)
-//	for elem := range elements {
-//	    fmt.Printf("Element %d: %v\n", elem.Index, elem.Element)
-//	}
+//	output := ArrayOutput[Person](ArrayOutputOptions[Person]{
+//	    ElementSchema: schema.NewSimpleJSONSchema(personSchema),
+//	    OnElement: func(ctx context.Context, elem ElementStreamResult[Person]) error {
+//	        render(elem.Element)
+//	        if elem.Index == 9 {
+//	            return ErrStopElementStream // ten is enough
+//	        }
+//	        return nil
+//	    },
+//	})
+//	result, _ := StreamText(ctx, StreamTextOptions{Model: model, Prompt: "...", Output: output})
+//	_, err := result.ReadAll()
 func ArrayOutput[ELEMENT any](opts ArrayOutputOptions[ELEMENT]) Output[[]ELEMENT, []ELEMENT] {
 	return &arrayOutput[ELEMENT]{
 		elementSchema: opts.ElementSchema,
 		name:          opts.Name,
 		description:   opts.Description,
+		onElement:     opts.OnElement,
 	}
 }
 
@@ -462,6 +655,8 @@ func (o *arrayOutput[ELEMENT]) ParseCompleteOutput(ctx context.Context, options
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			ParseError:   err,
+			Attempts:     1,
 		}
 	}
 
@@ -473,6 +668,7 @@ func (o *arrayOutput[ELEMENT]) ParseCompleteOutput(ctx context.Context, options
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			Attempts:     1,
 		}
 	}
 
@@ -482,12 +678,14 @@ func (o *arrayOutput[ELEMENT]) ParseCompleteOutput(ctx context.Context, options
 		// Validate element against schema
 		if err := o.elementSchema.Validator().Validate(elem); err != nil {
 			return nil, &NoObjectGeneratedError{
-				Message:      "No object generated: response did not match schema",
-				Cause:        err,
-				Text:         options.Text,
-				Response:     options.Response,
-				Usage:        options.Usage,
-				FinishReason: options.FinishReason,
+				Message:          "No object generated: response did not match schema",
+				Cause:            err,
+				Text:             options.Text,
+				Response:         options.Response,
+				Usage:            options.Usage,
+				FinishReason:     options.FinishReason,
+				ValidationErrors: validationErrors(err),
+				Attempts:         1,
 			}
 		}
 
@@ -501,6 +699,7 @@ func (o *arrayOutput[ELEMENT]) ParseCompleteOutput(ctx context.Context, options
 				Response:     options.Response,
 				Usage:        options.Usage,
 				FinishReason: options.FinishReason,
+				Attempts:     1,
 			}
 		}
 
@@ -513,6 +712,7 @@ func (o *arrayOutput[ELEMENT]) ParseCompleteOutput(ctx context.Context, options
 				Response:     options.Response,
 				Usage:        options.Usage,
 				FinishReason: options.FinishReason,
+				Attempts:     1,
 			}
 		}
 
@@ -549,17 +749,13 @@ func (o *arrayOutput[ELEMENT]) ParsePartialOutput(ctx context.Context, options P
 		return nil, nil
 	}
 
-	// Parse each element that validates
+	// Parse each element that validates, skipping the last one while it is
+	// still incomplete (matches TypeScript behavior)
+	complete, _ := completeArrayElements(options.Text)
 	var elements []ELEMENT
 	for i, elemRaw := range elementsArray {
-		// Skip last element if JSON was repaired (might be incomplete)
-		// This matches TypeScript behavior
-		if i == len(elementsArray)-1 {
-			// Check if this looks like incomplete JSON
-			elemStr := fmt.Sprintf("%v", elemRaw)
-			if !jsonutil.IsPartiallyValid(elemStr) {
-				break
-			}
+		if i >= len(complete) {
+			break
 		}
 
 		// Validate element
@@ -699,6 +895,8 @@ func (o *choiceOutput[CHOICE]) ParseCompleteOutput(ctx context.Context, options
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			ParseError:   err,
+			Attempts:     1,
 		}
 	}
 
@@ -719,6 +917,7 @@ func (o *choiceOutput[CHOICE]) ParseCompleteOutput(ctx context.Context, options
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			Attempts:     1,
 		}
 	}
 
@@ -864,6 +1063,8 @@ func (o *jsonOutput) ParseCompleteOutput(ctx context.Context, options ParseCompl
 			Response:     options.Response,
 			Usage:        options.Usage,
 			FinishReason: options.FinishReason,
+			ParseError:   err,
+			Attempts:     1,
 		}
 	}
 
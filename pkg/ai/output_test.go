@@ -2,7 +2,9 @@ package ai
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
@@ -320,6 +322,50 @@ func TestSchemaFor_Int(t *testing.T) {
 	}
 }
 
+func TestSchemaFor_JSONSchemaTags(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Node struct {
+		Base
+		Label    string    `json:"label" jsonschema:"description=Short label\\, lowercase,minLength=1,maxLength=20"`
+		Kind     string    `json:"kind" jsonschema:"enum=leaf|branch"`
+		Weight   float64   `json:"weight,omitempty" jsonschema:"minimum=0,maximum=1,required"`
+		Note     string    `json:"note" jsonschema:"optional"`
+		Created  time.Time `json:"created"`
+		Children []*Node   `json:"children,omitempty"`
+	}
+
+	jsonSchema := SchemaFor[Node]().Validator().JSONSchema()
+	props := jsonSchema["properties"].(map[string]interface{})
+	if _, ok := props["id"]; !ok {
+		t.Error("expected embedded field id to be flattened")
+	}
+	label := props["label"].(map[string]interface{})
+	if label["description"] != "Short label, lowercase" || label["minLength"] != 1 || label["maxLength"] != 20 {
+		t.Errorf("unexpected label schema %v", label)
+	}
+	if kind := props["kind"].(map[string]interface{}); !reflect.DeepEqual(kind["enum"], []interface{}{"leaf", "branch"}) {
+		t.Errorf("unexpected kind enum %v", kind["enum"])
+	}
+	if weight := props["weight"].(map[string]interface{}); weight["minimum"] != 0.0 || weight["maximum"] != 1.0 {
+		t.Errorf("unexpected weight schema %v", weight)
+	}
+	if created := props["created"].(map[string]interface{}); created["format"] != "date-time" {
+		t.Errorf("unexpected created schema %v", created)
+	}
+	items := props["children"].(map[string]interface{})["items"].(map[string]interface{})
+	if items["type"] != "object" || items["properties"] != nil {
+		t.Errorf("expected recursive type to end in a plain object, got %v", items)
+	}
+	want := []string{"id", "label", "kind", "weight", "created"}
+	if !reflect.DeepEqual(jsonSchema["required"], want) {
+		t.Errorf("required = %v, want %v", jsonSchema["required"], want)
+	}
+}
+
 // =============================================================================
 // OUT-T13: GenerateText with each output type
 // =============================================================================
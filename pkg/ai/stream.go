@@ -36,10 +36,20 @@ type StreamTextOptions struct {
 	Tools []types.Tool
 	ToolChoice types.ToolChoice
 
+	// DryRun replaces tool Execute functions with the dry run's stub, or a
+	// DryRunNotExecuted result without one, to preview the model's calls
+	DryRun *DryRunOptions
+
 	// Response format (for structured output)
 	// Deprecated: Use Output instead.
 	ResponseFormat *provider.ResponseFormat
 
+	// Constraint restricts output to a regex, grammar or JSON schema, as in
+	// GenerateTextOptions. Without native enforcement the complete text is
+	// validated when the stream ends, and Err reports a
+	// *types.ConstraintViolationError on mismatch.
+	Constraint *types.Constraint
+
 	// Output specifies how to handle and parse model output during streaming.
 	// Use TextOutput(), ObjectOutput(), ArrayOutput(), ChoiceOutput(), or JSONOutput().
 	// When set, the model is called with the appropriate ResponseFormat and
@@ -51,6 +61,11 @@ type StreamTextOptions struct {
 	// Supports total timeout, per-step timeout, and per-chunk timeout
 	Timeout *TimeoutConfig
 
+	// AutoResume re-prompts the model when the stream fails mid-generation,
+	// continuing from the text received so far. Without it, the stream ends
+	// with a *StreamInterruptedError holding the partial text.
+	AutoResume *StreamResumeOptions
+
 	// ExperimentalRetention controls what data is retained from LLM requests/responses.
 	// Useful for reducing memory consumption with images or large contexts.
 	// Default (nil) retains everything for backwards compatibility.
@@ -71,6 +86,11 @@ type StreamTextOptions struct {
 	// Telemetry configuration for observability
 	ExperimentalTelemetry *TelemetrySettings
 
+	// Metadata tags this request (e.g. feature, team, user) for cost
+	// attribution. It is added to telemetry span attributes and callback
+	// events, and forwarded to providers that support request metadata.
+	Metadata map[string]string
+
 	// Callbacks
 	OnChunk  func(chunk provider.StreamChunk)
 	OnFinish func(result *StreamTextResult)
@@ -139,6 +159,16 @@ type StreamTextResult struct {
 	// Error that occurred during streaming
 	err error
 
+	// constraint is validated against the final text unless the provider
+	// enforced nativeConstraint
+	constraint       *types.Constraint
+	nativeConstraint *types.Constraint
+
+	// resumes counts automatic resumes after mid-stream failures, and
+	// usageOffset is the usage of the attempts before the current one
+	resumes     int
+	usageOffset types.Usage
+
 	// Output spec resolved from StreamTextOptions.Output.
 	// nil when no Output option was provided.
 	outputSpec outputProcessor
@@ -162,6 +192,12 @@ type StreamTextResult struct {
 	// Used for deduplication — only written from the stream-consuming goroutine.
 	lastPartialJSON string
 
+	// elementsEmitted is the number of array elements reported to an
+	// ArrayOutput OnElement callback. elementsStopped is set once the
+	// callback ended the stream early.
+	elementsEmitted int
+	elementsStopped bool
+
 	// Timeout configuration for per-chunk timeouts
 	timeout *TimeoutConfig
 
@@ -187,6 +223,9 @@ type StreamTextResult struct {
 	// sources accumulated from ChunkTypeSource chunks
 	sources []types.SourceContent
 
+	// audio assembled from ChunkTypeAudio deltas.  Protected by mu.
+	audio *types.AudioOutput
+
 	// Structured event callbacks (v6.1 - P0-3)
 	// Stored here so processStream can fire them when the stream completes.
 	cbOnStepFinishEvent func(ctx context.Context, e OnStepFinishEvent)
@@ -216,6 +255,10 @@ func StreamText(ctx context.Context, opts StreamTextOptions) (*StreamTextResult,
 		return nil, fmt.Errorf("model is required")
 	}
 
+	// Nested generations (tools, subagents) inherit the caller's context
+	ctx, opts.ExperimentalContext = resolveExperimentalContext(ctx, opts.ExperimentalContext)
+	opts.ExperimentalTelemetry = withRequestMetadata(opts.ExperimentalTelemetry, opts.Metadata)
+
 	// Fire OnStart — integrations start their root spans here and embed them
 	// in the returned context.  FireOnFinish / FireOnError are called later
 	// from processStream or ReadAll once the stream completes.
@@ -298,6 +341,13 @@ func StreamText(ctx context.Context, opts StreamTextOptions) (*StreamTextResult,
 		}
 	}
 
+	// Decide whether the constraint is enforced by the provider or validated afterwards
+	nativeConstraint, err := resolveConstraint(opts.Model, opts.Constraint, opts.Tools, opts.ToolChoice)
+	if err != nil {
+		telemetry.FireOnError(telemetryCtx, telemetry.TelemetryErrorEvent{Error: err})
+		return nil, err
+	}
+
 	// Build generate options
 	genOpts := &provider.GenerateOptions{
 		Prompt:           prompt,
@@ -309,12 +359,14 @@ func StreamText(ctx context.Context, opts StreamTextOptions) (*StreamTextResult,
 		PresencePenalty:  opts.PresencePenalty,
 		StopSequences:    opts.StopSequences,
 		Seed:             opts.Seed,
-		Tools:            opts.Tools,
+		Tools:            prepareToolsForModel(opts.Tools),
 		ToolChoice:       opts.ToolChoice,
 		ResponseFormat:   responseFormat,
+		Constraint:       nativeConstraint,
 		Reasoning:        opts.Reasoning,
 		ProviderOptions:  opts.ProviderOptions,
 		Telemetry:        opts.ExperimentalTelemetry,
+		Metadata:         opts.Metadata,
 	}
 
 	// Start streaming
@@ -332,6 +384,8 @@ func StreamText(ctx context.Context, opts StreamTextOptions) (*StreamTextResult,
 		telemetryCtx:      telemetryCtx,
 		telemetrySettings: opts.ExperimentalTelemetry,
 		outputSpec:        outputSpec,
+		constraint:        opts.Constraint,
+		nativeConstraint:  nativeConstraint,
 		// Structured event callbacks
 		cbOnStepFinishEvent: opts.OnStepFinishEvent,
 		cbOnFinishEvent:     opts.OnFinishEvent,
@@ -399,7 +453,9 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 				break
 			}
 			if err != nil {
-				r.err = err
+				if r.resumeAfter(ctx, err, currentMessages, len(stepToolCalls) > 0) {
+					continue
+				}
 				break
 			}
 
@@ -420,23 +476,10 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 			if chunk.Type == provider.ChunkTypeText {
 				r.text += chunk.Text
 
-				// Update partial output after each text chunk (with deduplication).
-				// Only publishes when the JSON representation of the partial changes,
-				// matching the TypeScript SDK's deduplication behavior.
-				if r.outputSpec != nil {
-					partial := r.outputSpec.parsePartialOutput(ctx, ParsePartialOutputOptions{
-						Text: r.text,
-					})
-					if partial != nil {
-						if newJSON, err := json.Marshal(partial); err == nil {
-							if newJSONStr := string(newJSON); newJSONStr != r.lastPartialJSON {
-								r.lastPartialJSON = newJSONStr
-								r.mu.Lock()
-								r.partialOutput = partial
-								r.mu.Unlock()
-							}
-						}
-					}
+				// Update partial output after each text chunk and report
+				// completed array elements
+				if err := r.updatePartialOutput(ctx); err != nil {
+					r.stopForElements(err)
 				}
 			}
 
@@ -459,7 +502,7 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 				}
 			}
 			if chunk.Usage != nil {
-				r.usage = *chunk.Usage
+				r.recordUsage(*chunk.Usage)
 			}
 
 			// Accumulate provider metadata from each chunk that carries it.
@@ -474,6 +517,13 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 				r.sources = append(r.sources, *chunk.SourceContent)
 			}
 
+			// Assemble spoken output from ChunkTypeAudio deltas.
+			if chunk.Type == provider.ChunkTypeAudio && chunk.Audio != nil {
+				r.mu.Lock()
+				r.audio = appendAudioDelta(r.audio, chunk.Audio)
+				r.mu.Unlock()
+			}
+
 			// Forward chunk to consumer BEFORE any tool Execute fires (Fix 2).
 			if onChunk != nil {
 				onChunk(*chunk)
@@ -483,8 +533,12 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 				ChunkType: string(chunk.Type),
 				Text:      chunk.Text,
 			})
+
+			if r.elementsStopped {
+				break
+			}
 		}
-		if r.err != nil {
+		if r.err != nil || r.elementsStopped {
 			break
 		}
 
@@ -503,6 +557,7 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 				functionID:          r.cbFuncID,
 				metadata:            r.cbMeta,
 				timeout:             r.timeout,
+				dryRun:              opts.DryRun,
 			}
 			stepToolResults, _ = executeTools(ctx, stepToolCalls, opts.Tools, r.cbExperimentalCtx, &r.usage, toolCallbacks)
 		}
@@ -588,11 +643,7 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 			toolMsg := types.Message{
 				Role: types.RoleTool,
 				Content: []types.ContentPart{
-					types.ToolResultContent{
-						ToolCallID: tr.ToolCallID,
-						ToolName:   tr.ToolName,
-						Result:     tr.Result,
-					},
+					NewToolResultContent(ctx, opts.Tools, tr),
 				},
 			}
 			currentMessages = append(currentMessages, toolMsg)
@@ -620,12 +671,14 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 			PresencePenalty:  opts.PresencePenalty,
 			StopSequences:    opts.StopSequences,
 			Seed:             opts.Seed,
-			Tools:            opts.Tools,
+			Tools:            prepareToolsForModel(opts.Tools),
 			ToolChoice:       opts.ToolChoice,
 			ResponseFormat:   responseFormat,
+			Constraint:       r.nativeConstraint,
 			Reasoning:        opts.Reasoning,
 			ProviderOptions:  opts.ProviderOptions,
 			Telemetry:        opts.ExperimentalTelemetry,
+			Metadata:         opts.Metadata,
 		}
 		newStream, err := r.cbModel.DoStream(ctx, nextGenOpts)
 		if err != nil {
@@ -635,6 +688,11 @@ func (r *StreamTextResult) processStream(ctx context.Context, onChunk func(provi
 		r.stream = newStream
 	}
 
+	// Validate constraints the provider could not enforce natively
+	if r.err == nil && !r.elementsStopped {
+		r.err = r.validateConstraint()
+	}
+
 	// Resolve final typed output if spec was provided and stream completed cleanly.
 	// Only parse when finishReason is Stop; truncated responses (e.g. length limit)
 	// would produce invalid JSON, matching the TypeScript SDK's behavior.
@@ -750,7 +808,9 @@ func (r *StreamTextResult) FinishReason() types.FinishReason {
 	return r.finishReason
 }
 
-// Usage returns the usage information (only available after stream completes)
+// Usage returns the usage information. It is final once the stream
+// completes; mid-stream it holds the latest usage chunk, which may be an
+// estimate (see middleware.UsageStreamMiddleware).
 func (r *StreamTextResult) Usage() types.Usage {
 	return r.usage
 }
@@ -788,6 +848,37 @@ func (r *StreamTextResult) Sources() []types.SourceContent {
 	return r.sources
 }
 
+// Audio returns the spoken response assembled from audio chunks, for
+// audio-output models.  Nil if the model produced no audio.  Only complete
+// after the stream finishes.
+func (r *StreamTextResult) Audio() *types.AudioOutput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.audio
+}
+
+// appendAudioDelta merges an audio chunk into the accumulated output
+func appendAudioDelta(acc, delta *types.AudioOutput) *types.AudioOutput {
+	if acc == nil {
+		acc = &types.AudioOutput{}
+	}
+	acc.Data = append(acc.Data, delta.Data...)
+	acc.Transcript += delta.Transcript
+	if delta.ID != "" {
+		acc.ID = delta.ID
+	}
+	if delta.Voice != "" {
+		acc.Voice = delta.Voice
+	}
+	if delta.Format != "" {
+		acc.Format = delta.Format
+	}
+	if delta.ExpiresAt != 0 {
+		acc.ExpiresAt = delta.ExpiresAt
+	}
+	return acc
+}
+
 // Output returns the final parsed typed output after streaming completes.
 // This calls ParseCompleteOutput on the full accumulated text, matching the
 // TypeScript SDK's `.output` property behavior.
@@ -844,7 +935,8 @@ func (r *StreamTextResult) Resume(ctx context.Context) error {
 	return nil
 }
 
-// Err returns any error that occurred during streaming
+// Err returns any error that occurred during streaming. Failures after the
+// stream started are reported as a *StreamInterruptedError.
 func (r *StreamTextResult) Err() error {
 	return r.err
 }
@@ -855,6 +947,8 @@ func (r *StreamTextResult) Close() error {
 }
 
 // ReadAll reads all chunks from the stream and returns the complete text.
+// If the stream fails mid-generation, the text received so far is returned
+// with a *StreamInterruptedError.
 // Tool call chunks are collected and stored in the result, but Execute is not
 // called — use StreamText with callbacks for tool execution.
 func (r *StreamTextResult) ReadAll() (string, error) {
@@ -868,7 +962,10 @@ func (r *StreamTextResult) ReadAll() (string, error) {
 			break
 		}
 		if err != nil {
-			return "", err
+			if r.resumeAfter(ctx, err, r.cbMessages, len(pendingToolCalls) > 0) {
+				continue
+			}
+			return r.text, r.err
 		}
 
 		// Transition Submitted → Streaming on the first chunk.
@@ -888,21 +985,10 @@ func (r *StreamTextResult) ReadAll() (string, error) {
 		if chunk.Type == provider.ChunkTypeText {
 			r.text += chunk.Text
 
-			// Update partial output after each text chunk (with deduplication).
-			if r.outputSpec != nil {
-				partial := r.outputSpec.parsePartialOutput(ctx, ParsePartialOutputOptions{
-					Text: r.text,
-				})
-				if partial != nil {
-					if newJSON, err := json.Marshal(partial); err == nil {
-						if newJSONStr := string(newJSON); newJSONStr != r.lastPartialJSON {
-							r.lastPartialJSON = newJSONStr
-							r.mu.Lock()
-							r.partialOutput = partial
-							r.mu.Unlock()
-						}
-					}
-				}
+			// Update partial output after each text chunk and report
+			// completed array elements
+			if err := r.updatePartialOutput(ctx); err != nil {
+				r.stopForElements(err)
 			}
 		}
 
@@ -919,13 +1005,28 @@ func (r *StreamTextResult) ReadAll() (string, error) {
 			}
 		}
 		if chunk.Usage != nil {
-			r.usage = *chunk.Usage
+			r.recordUsage(*chunk.Usage)
 		}
 
 		// Accumulate provider metadata.
 		if len(chunk.ProviderMetadata) > 0 {
 			r.providerMetadata = chunk.ProviderMetadata
 		}
+
+		// Assemble spoken output.
+		if chunk.Type == provider.ChunkTypeAudio && chunk.Audio != nil {
+			r.mu.Lock()
+			r.audio = appendAudioDelta(r.audio, chunk.Audio)
+			r.mu.Unlock()
+		}
+
+		// An OnElement callback ended the stream early
+		if r.elementsStopped {
+			if r.err != nil {
+				return "", r.err
+			}
+			break
+		}
 	}
 
 	// Store collected tool calls.
@@ -935,6 +1036,12 @@ func (r *StreamTextResult) ReadAll() (string, error) {
 		r.mu.Unlock()
 	}
 
+	// Validate constraints the provider could not enforce natively
+	if err := r.validateConstraint(); err != nil {
+		r.err = err
+		return r.text, err
+	}
+
 	// Resolve final typed output if spec was provided and stream completed cleanly.
 	if r.outputSpec != nil && r.finishReason == types.FinishReasonStop {
 		parsed, parseErr := r.outputSpec.parseCompleteOutput(ctx, ParseCompleteOutputOptions{
@@ -978,6 +1085,39 @@ func (r *StreamTextResult) ReadAll() (string, error) {
 	return r.text, nil
 }
 
+// validateConstraint checks the final text against the constraint when the
+// provider did not enforce it
+func (r *StreamTextResult) validateConstraint() error {
+	if !needsConstraintValidation(r.constraint, r.nativeConstraint) {
+		return nil
+	}
+	return validateConstraint(r.constraint, r.text)
+}
+
+// updatePartialOutput re-parses the partial output after a text chunk and
+// reports newly completed array elements. The partial output is only
+// published when its JSON representation changes, matching the TypeScript
+// SDK's deduplication behavior.
+func (r *StreamTextResult) updatePartialOutput(ctx context.Context) error {
+	if r.outputSpec == nil {
+		return nil
+	}
+	partial := r.outputSpec.parsePartialOutput(ctx, ParsePartialOutputOptions{
+		Text: r.text,
+	})
+	if partial != nil {
+		if newJSON, err := json.Marshal(partial); err == nil {
+			if newJSONStr := string(newJSON); newJSONStr != r.lastPartialJSON {
+				r.lastPartialJSON = newJSONStr
+				r.mu.Lock()
+				r.partialOutput = partial
+				r.mu.Unlock()
+			}
+		}
+	}
+	return r.emitOutputElements(ctx)
+}
+
 // nextChunk reads the next chunk with optional per-chunk timeout
 func (r *StreamTextResult) nextChunk(ctx context.Context) (*provider.StreamChunk, error) {
 	// If no per-chunk timeout, just call Next() directly
@@ -0,0 +1,269 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// StreamInterruptedError is returned when a stream fails after it started,
+// e.g. because the connection dropped or the provider sent an error event.
+// The text received before the failure is kept so callers can show or save
+// it.
+type StreamInterruptedError struct {
+	// PartialText is the text streamed before the failure, including any
+	// text from resumed attempts
+	PartialText string
+
+	// Resumes is the number of automatic resume attempts made
+	Resumes int
+
+	// Cause is the error the stream failed with
+	Cause error
+}
+
+func (e *StreamInterruptedError) Error() string {
+	msg := fmt.Sprintf("stream interrupted after %d characters", len(e.PartialText))
+	if e.Resumes > 0 {
+		msg += fmt.Sprintf(" and %d resume attempts", e.Resumes)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Cause)
+}
+
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Cause
+}
+
+// StreamResumeOptions configures automatic resumption of streams that fail
+// mid-generation. The model is re-prompted with the text streamed so far
+// and asked to continue from where it stopped; text it repeats is dropped
+// so consumers see one continuous response.
+type StreamResumeOptions struct {
+	// MaxAttempts caps the number of resume requests per stream
+	// Default: 2
+	MaxAttempts int
+
+	// Prompt is the user message asking the model to continue. The end of
+	// the partial response is appended after "Continue from:".
+	// Default: DefaultResumePrompt
+	Prompt string
+
+	// ShouldResume reports whether a failure is worth resuming. Streams
+	// whose context is done are never resumed; per-chunk timeouts are.
+	// Default: every error
+	ShouldResume func(err error) bool
+}
+
+// DefaultResumePrompt asks the model to resume an interrupted response
+const DefaultResumePrompt = "Your previous response was interrupted. Continue exactly where it stopped, without repeating any text or adding commentary."
+
+// defaultMaxResumes is the default resume cap
+const defaultMaxResumes = 2
+
+// resumeContextChars is how much of the partial response is quoted in the
+// resume prompt
+const resumeContextChars = 200
+
+// resumeAfter handles a stream error: when auto-resume is configured and
+// allowed, it starts a new stream that continues the partial response and
+// returns true. Otherwise it records a StreamInterruptedError on r. Steps
+// with tool calls are not resumed, since the calls may be incomplete.
+func (r *StreamTextResult) resumeAfter(ctx context.Context, err error, messages []types.Message, hasToolCalls bool) bool {
+	interrupted := &StreamInterruptedError{PartialText: r.text, Resumes: r.resumes, Cause: err}
+	r.err = interrupted
+	if r.finishReason == "" {
+		r.finishReason = types.FinishReasonError
+	}
+
+	opts := r.cbStreamOpts.AutoResume
+	if opts == nil || hasToolCalls || r.elementsStopped || ctx.Err() != nil {
+		return false
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxResumes
+	}
+	if r.resumes >= maxAttempts {
+		return false
+	}
+	if opts.ShouldResume != nil && !opts.ShouldResume(err) {
+		return false
+	}
+
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = DefaultResumePrompt
+	}
+	if r.text != "" {
+		tail := []rune(r.text)
+		if len(tail) > resumeContextChars {
+			tail = tail[len(tail)-resumeContextChars:]
+		}
+		prompt += "\n\nContinue from: " + string(tail)
+	}
+
+	// The interrupted stream's usage is kept; the new stream reports its own
+	_ = r.stream.Close()
+	r.usageOffset = r.usage
+	r.resumes++
+
+	genOpts := r.resumeGenerateOptions(ctx, messages, prompt)
+	stream, startErr := r.cbModel.DoStream(ctx, genOpts)
+	if startErr != nil {
+		r.err = &StreamInterruptedError{PartialText: r.text, Resumes: r.resumes, Cause: errors.Join(err, startErr)}
+		return false
+	}
+	r.stream = &resumedStream{TextStream: stream, prev: r.text, jsonOutput: jsonResponseFormat(genOpts.ResponseFormat)}
+	r.err = nil
+	r.finishReason = ""
+	return true
+}
+
+// resumeGenerateOptions builds the request that continues the partial text
+func (r *StreamTextResult) resumeGenerateOptions(ctx context.Context, messages []types.Message, prompt string) *provider.GenerateOptions {
+	opts := r.cbStreamOpts
+
+	resumeMessages := make([]types.Message, 0, len(messages)+2)
+	resumeMessages = append(resumeMessages, messages...)
+	if r.text != "" {
+		resumeMessages = append(resumeMessages, types.Message{
+			Role:    types.RoleAssistant,
+			Content: []types.ContentPart{types.TextContent{Text: r.text}},
+		})
+	}
+	resumeMessages = append(resumeMessages, types.Message{
+		Role:    types.RoleUser,
+		Content: []types.ContentPart{types.TextContent{Text: prompt}},
+	})
+
+	responseFormat := opts.ResponseFormat
+	if responseFormat == nil && r.outputSpec != nil {
+		if rf, rfErr := r.outputSpec.ResponseFormat(ctx); rfErr == nil {
+			responseFormat = rf
+		}
+	}
+
+	return &provider.GenerateOptions{
+		Prompt: types.Prompt{
+			Messages: resumeMessages,
+			System:   opts.System,
+		},
+		Temperature:      opts.Temperature,
+		MaxTokens:        opts.MaxTokens,
+		TopP:             opts.TopP,
+		TopK:             opts.TopK,
+		FrequencyPenalty: opts.FrequencyPenalty,
+		PresencePenalty:  opts.PresencePenalty,
+		StopSequences:    opts.StopSequences,
+		Seed:             opts.Seed,
+		Tools:            prepareToolsForModel(opts.Tools),
+		ToolChoice:       opts.ToolChoice,
+		ResponseFormat:   responseFormat,
+		Reasoning:        opts.Reasoning,
+		ProviderOptions:  opts.ProviderOptions,
+		Telemetry:        opts.ExperimentalTelemetry,
+		Metadata:         opts.Metadata,
+	}
+}
+
+// Resumes returns the number of times the stream was automatically resumed
+// after failing mid-generation
+func (r *StreamTextResult) Resumes() int {
+	return r.resumes
+}
+
+// recordUsage stores a usage chunk, adding the usage of interrupted
+// attempts once the stream has been resumed
+func (r *StreamTextResult) recordUsage(usage types.Usage) {
+	if r.resumes == 0 {
+		r.usage = usage
+		return
+	}
+	r.usage = r.usageOffset.Add(usage)
+}
+
+// jsonResponseFormat reports whether rf asks for JSON output
+func jsonResponseFormat(rf *provider.ResponseFormat) bool {
+	return rf != nil && rf.Type != "" && rf.Type != "text"
+}
+
+// resumedStream drops text a resumed stream repeats from the end of the
+// interrupted response. Leading text is held back while it could still be
+// a repeat, then released as one chunk without the overlap.
+type resumedStream struct {
+	provider.TextStream
+	prev       string
+	jsonOutput bool
+
+	buffered string
+	resolved bool
+	pending  []resumedChunk
+}
+
+type resumedChunk struct {
+	chunk *provider.StreamChunk
+	err   error
+}
+
+func (s *resumedStream) Next() (*provider.StreamChunk, error) {
+	for {
+		if len(s.pending) > 0 {
+			next := s.pending[0]
+			s.pending = s.pending[1:]
+			return next.chunk, next.err
+		}
+
+		chunk, err := s.TextStream.Next()
+		if s.resolved {
+			return chunk, err
+		}
+		if err == nil {
+			switch chunk.Type {
+			case provider.ChunkTypeText:
+				s.buffered += chunk.Text
+				if s.mayRepeat() {
+					continue
+				}
+				chunk = nil
+			case provider.ChunkTypeFinish, provider.ChunkTypeToolCall:
+			default:
+				// Metadata and reasoning do not affect the overlap
+				return chunk, nil
+			}
+		}
+
+		// The overlap is decided: release the new text, then this chunk
+		s.resolved = true
+		text := s.buffered
+		if stitched := stitchContinuation(s.prev, s.buffered, s.jsonOutput); strings.HasPrefix(stitched, s.prev) {
+			text = stitched[len(s.prev):]
+		}
+		if text != "" {
+			s.pending = append(s.pending, resumedChunk{chunk: &provider.StreamChunk{Type: provider.ChunkTypeText, Text: text}})
+		}
+		if chunk != nil || err != nil {
+			s.pending = append(s.pending, resumedChunk{chunk: chunk, err: err})
+		}
+		if len(s.pending) == 0 {
+			return nil, io.EOF
+		}
+	}
+}
+
+// mayRepeat reports whether the buffered text could still be the start of
+// a repeat of the interrupted response
+func (s *resumedStream) mayRepeat() bool {
+	if len(s.buffered) >= maxContinuationOverlap {
+		return false
+	}
+	tail := s.prev
+	if len(tail) > maxContinuationOverlap {
+		tail = tail[len(tail)-maxContinuationOverlap:]
+	}
+	return strings.Contains(tail, s.buffered)
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+var errConnReset = errors.New("connection reset by peer")
+
+// brokenStream yields chunks, then fails with err (or ends when err is nil)
+type brokenStream struct {
+	chunks []provider.StreamChunk
+	err    error
+}
+
+func (s *brokenStream) Next() (*provider.StreamChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &chunk, nil
+}
+
+func (s *brokenStream) Close() error { return nil }
+func (s *brokenStream) Err() error   { return nil }
+
+func textChunks(texts ...string) []provider.StreamChunk {
+	chunks := make([]provider.StreamChunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = provider.StreamChunk{Type: provider.ChunkTypeText, Text: text}
+	}
+	return chunks
+}
+
+// sequenceModel serves one stream per call and records each call's prompt
+func sequenceModel(streams ...*brokenStream) (*testutil.MockLanguageModel, *[]types.Prompt) {
+	var mu sync.Mutex
+	var prompts []types.Prompt
+	return &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			prompts = append(prompts, opts.Prompt)
+			if len(prompts) > len(streams) {
+				return nil, errors.New("unexpected call")
+			}
+			return streams[len(prompts)-1], nil
+		},
+	}, &prompts
+}
+
+func TestStreamText_InterruptedKeepsPartialText(t *testing.T) {
+	t.Parallel()
+
+	model, _ := sequenceModel(&brokenStream{chunks: textChunks("The quick ", "brown "), err: errConnReset})
+	result, err := StreamText(context.Background(), StreamTextOptions{Model: model, Prompt: "Write a pangram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := result.ReadAll()
+	var interrupted *StreamInterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, errConnReset) {
+		t.Fatalf("expected a StreamInterruptedError, got %v", err)
+	}
+	if text != "The quick brown " || interrupted.PartialText != text || interrupted.Resumes != 0 {
+		t.Errorf("text = %q, error = %+v", text, interrupted)
+	}
+	if result.FinishReason() != types.FinishReasonError || result.Err() != err {
+		t.Errorf("finish reason = %q, Err = %v", result.FinishReason(), result.Err())
+	}
+}
+
+func TestStreamText_AutoResume(t *testing.T) {
+	t.Parallel()
+
+	first := &brokenStream{chunks: []provider.StreamChunk{
+		{Type: provider.ChunkTypeText, Text: "The quick "},
+		{Type: provider.ChunkTypeUsage, Usage: &types.Usage{OutputTokens: int64Ptr(4)}},
+		{Type: provider.ChunkTypeText, Text: "brown "},
+	}, err: errConnReset}
+	// The model repeats "quick brown " before continuing
+	second := &brokenStream{chunks: append(textChunks("quick b", "rown fox", " jumps."), provider.StreamChunk{
+		Type:         provider.ChunkTypeFinish,
+		FinishReason: types.FinishReasonStop,
+		Usage:        &types.Usage{OutputTokens: int64Ptr(6)},
+	})}
+	model, prompts := sequenceModel(first, second)
+
+	result, err := StreamText(context.Background(), StreamTextOptions{
+		Model:      model,
+		Prompt:     "Write a pangram",
+		AutoResume: &StreamResumeOptions{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := result.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "The quick brown fox jumps." || result.Resumes() != 1 {
+		t.Errorf("text = %q after %d resumes", text, result.Resumes())
+	}
+	if result.FinishReason() != types.FinishReasonStop || result.Usage().GetOutputTokens() != 10 {
+		t.Errorf("finish reason %q, usage %+v", result.FinishReason(), result.Usage())
+	}
+
+	resume := (*prompts)[1].Messages
+	if len(resume) != 3 || resume[1].Role != types.RoleAssistant {
+		t.Fatalf("resume messages = %+v", resume)
+	}
+	if ask := resume[2].Content[0].(types.TextContent).Text; !strings.HasSuffix(ask, "Continue from: The quick brown ") {
+		t.Errorf("resume prompt = %q", ask)
+	}
+}
+
+func TestStreamText_AutoResumeGivesUp(t *testing.T) {
+	t.Parallel()
+
+	model, prompts := sequenceModel(
+		&brokenStream{chunks: textChunks("Once upon "), err: errConnReset},
+		&brokenStream{chunks: textChunks("a time"), err: errConnReset},
+	)
+	var streamed strings.Builder
+	finished := make(chan *StreamTextResult, 1)
+	_, err := StreamText(context.Background(), StreamTextOptions{
+		Model:      model,
+		Prompt:     "Tell a story",
+		AutoResume: &StreamResumeOptions{MaxAttempts: 1},
+		OnChunk: func(chunk provider.StreamChunk) {
+			streamed.WriteString(chunk.Text)
+		},
+		OnFinish: func(result *StreamTextResult) { finished <- result },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-finished
+	var interrupted *StreamInterruptedError
+	if !errors.As(result.Err(), &interrupted) || interrupted.Resumes != 1 || interrupted.PartialText != "Once upon a time" {
+		t.Fatalf("err = %v", result.Err())
+	}
+	if streamed.String() != result.Text() || len(*prompts) != 2 {
+		t.Errorf("streamed %q, text %q, %d calls", streamed.String(), result.Text(), len(*prompts))
+	}
+
+	// ShouldResume can rule out errors that a retry will not fix
+	model, prompts = sequenceModel(&brokenStream{chunks: textChunks("x"), err: errConnReset})
+	result2, err := StreamText(context.Background(), StreamTextOptions{
+		Model:      model,
+		Prompt:     "x",
+		AutoResume: &StreamResumeOptions{ShouldResume: func(err error) bool { return false }},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := result2.ReadAll(); !errors.Is(err, errConnReset) || len(*prompts) != 1 {
+		t.Errorf("err = %v after %d calls", err, len(*prompts))
+	}
+}
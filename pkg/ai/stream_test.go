@@ -1343,3 +1343,40 @@ func TestStreamEmitsReasoningBlockBoundaries(t *testing.T) {
 		t.Errorf("reasoning-end ID = %q, want \"thinking-1\"", endChunk.ID)
 	}
 }
+
+func TestStreamText_AssemblesAudioChunks(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeAudio, Audio: &types.AudioOutput{ID: "audio_1", Voice: "alloy", Transcript: "Hi "}},
+				{Type: provider.ChunkTypeAudio, Audio: &types.AudioOutput{Data: []byte{1, 2}}},
+				{Type: provider.ChunkTypeAudio, Audio: &types.AudioOutput{Data: []byte{3}, Transcript: "there"}},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}
+
+	result, err := StreamText(context.Background(), StreamTextOptions{Model: model, Prompt: "Say hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := result.ReadAll(); err != nil {
+		t.Fatalf("unexpected error reading stream: %v", err)
+	}
+
+	audio := result.Audio()
+	if audio == nil {
+		t.Fatal("expected audio output")
+	}
+	if audio.ID != "audio_1" || audio.Voice != "alloy" {
+		t.Errorf("unexpected audio metadata: %+v", audio)
+	}
+	if audio.Transcript != "Hi there" {
+		t.Errorf("Transcript = %q, want %q", audio.Transcript, "Hi there")
+	}
+	if string(audio.Data) != string([]byte{1, 2, 3}) {
+		t.Errorf("Data = %v, want [1 2 3]", audio.Data)
+	}
+}
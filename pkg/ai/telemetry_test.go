@@ -558,3 +558,42 @@ func (m *mockTelemetryIntegration) ExecuteTool(
 ) (interface{}, error) {
 	return execute(ctx, args)
 }
+
+func TestGenerateText_TelemetryPayloadPrivacy(t *testing.T) {
+	spanRecorder, cleanup := setupTelemetryTest(t)
+	defer cleanup()
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:  &mockTelemetryModel{},
+		Prompt: "Test prompt",
+		ExperimentalTelemetry: &telemetry.Settings{
+			IsEnabled:      true,
+			RecordInputs:   true,
+			RecordOutputs:  true,
+			Payloads:       telemetry.PayloadHash,
+			Metadata:       map[string]attribute.Value{"user_email": attribute.StringValue("a@example.com")},
+			DenyAttributes: []string{"ai.telemetry.metadata.*"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateText failed: %v", err)
+	}
+
+	spans := spanRecorder.Ended()
+	if len(spans) == 0 {
+		t.Fatal("Expected at least one span to be recorded")
+	}
+	attrs := map[string]string{}
+	for _, attr := range spans[len(spans)-1].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["ai.prompt"] != telemetry.HashPayload("Test prompt") || attrs["ai.response.text"] != telemetry.HashPayload("Test response") {
+		t.Errorf("Expected hashed prompt and response, got %q and %q", attrs["ai.prompt"], attrs["ai.response.text"])
+	}
+	if _, ok := attrs["ai.telemetry.metadata.user_email"]; ok {
+		t.Error("Expected denied metadata attribute to be absent")
+	}
+	if attrs["ai.response.finishReason"] != "stop" {
+		t.Errorf("Expected other attributes to be kept, got %v", attrs)
+	}
+}
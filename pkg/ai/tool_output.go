@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// FormatToolResult serializes a tool result for the model according to the
+// tool's OutputFormat. Values are normalized through JSON, so object keys are
+// always sorted and the same result always produces the same text. String
+// results are returned as-is apart from length truncation.
+func FormatToolResult(tool types.Tool, result interface{}) (string, error) {
+	format := tool.OutputFormat
+	if format == nil {
+		format = &types.ToolOutputFormat{}
+	}
+
+	if s, ok := result.(string); ok {
+		return truncateText(s, format.MaxLength), nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize result of tool %s: %w", tool.Name, err)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return "", fmt.Errorf("failed to serialize result of tool %s: %w", tool.Name, err)
+	}
+
+	normalized = shapeToolValue(normalized, "", format)
+	// encoding/json sorts map keys, giving a stable key order
+	data, err = json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize result of tool %s: %w", tool.Name, err)
+	}
+	return truncateText(string(data), format.MaxLength), nil
+}
+
+// ToolDescription returns the description sent to the model for tool,
+// including its output schema when OutputFormat.DescribeOutput is set
+func ToolDescription(tool types.Tool) string {
+	if tool.OutputSchema == nil || tool.OutputFormat == nil || !tool.OutputFormat.DescribeOutput {
+		return tool.Description
+	}
+	schema, err := json.Marshal(tool.OutputSchema)
+	if err != nil {
+		return tool.Description
+	}
+	desc := "Returns JSON matching this schema: " + string(schema)
+	if tool.Description == "" {
+		return desc
+	}
+	return tool.Description + "\n\n" + desc
+}
+
+// prepareToolsForModel returns tools with descriptions extended by
+// ToolDescription. The input slice is not modified.
+func prepareToolsForModel(tools []types.Tool) []types.Tool {
+	var out []types.Tool
+	for i, tool := range tools {
+		desc := ToolDescription(tool)
+		if desc == tool.Description {
+			continue
+		}
+		if out == nil {
+			out = append([]types.Tool(nil), tools...)
+		}
+		out[i].Description = desc
+	}
+	if out == nil {
+		return tools
+	}
+	return out
+}
+
+// PrepareToolsForModel is prepareToolsForModel for callers outside this
+// package that build provider requests themselves (such as agents)
+func PrepareToolsForModel(tools []types.Tool) []types.Tool {
+	return prepareToolsForModel(tools)
+}
+
+// NewToolResultContent builds the message content that sends a tool result
+// back to the model. The tool's ToModelOutput hook takes precedence; tools
+// with an OutputSchema or OutputFormat are serialized with FormatToolResult;
+// other results are passed through unchanged. Failures are reported as the
+// JSON from types.ToolError.ModelText, classified with types.AsToolError.
+func NewToolResultContent(ctx context.Context, tools []types.Tool, tr types.ToolResult) types.ToolResultContent {
+	content := types.ToolResultContent{ToolCallID: tr.ToolCallID, ToolName: tr.ToolName, Result: tr.Result}
+	if tr.Error != nil {
+		toolErr := types.AsToolError(tr.Error)
+		text := toolErr.ModelText()
+		content.Result = text
+		content.Error = toolErr.Message
+		content.Output = &types.ToolResultOutput{Type: types.ToolResultOutputError, Value: text}
+		return content
+	}
+	if tr.ProviderExecuted {
+		return content
+	}
+
+	var tool *types.Tool
+	for i := range tools {
+		if tools[i].Name == tr.ToolName {
+			tool = &tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		return content
+	}
+
+	if tool.ToModelOutput != nil {
+		output, err := tool.ToModelOutput(ctx, types.ToModelOutputOptions{
+			Result:   tr.Result,
+			ToolCall: &types.ToolCall{ID: tr.ToolCallID, ToolName: tr.ToolName, Arguments: tr.Input},
+		})
+		if err == nil && output != nil {
+			content.Output = output
+		}
+		return content
+	}
+
+	if tool.OutputSchema != nil || tool.OutputFormat != nil {
+		if text, err := FormatToolResult(*tool, tr.Result); err == nil {
+			content.Result = text
+		}
+	}
+	return content
+}
+
+// shapeToolValue applies units and string/array truncation to a normalized
+// JSON value
+func shapeToolValue(v interface{}, path string, format *types.ToolOutputFormat) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = shapeToolValue(child, joinToolPath(path, k), format)
+		}
+		return val
+	case []interface{}:
+		omitted := 0
+		if format.MaxArrayItems > 0 && len(val) > format.MaxArrayItems {
+			omitted = len(val) - format.MaxArrayItems
+			val = val[:format.MaxArrayItems]
+		}
+		out := make([]interface{}, 0, len(val)+1)
+		for _, child := range val {
+			out = append(out, shapeToolValue(child, path, format))
+		}
+		if omitted > 0 {
+			out = append(out, fmt.Sprintf("… %d more items", omitted))
+		}
+		return out
+	case string:
+		if format.MaxStringLength > 0 && utf8.RuneCountInString(val) > format.MaxStringLength {
+			return string([]rune(val)[:format.MaxStringLength]) + "…"
+		}
+		return val
+	case float64:
+		if unit, ok := format.Units[path]; ok && unit != "" {
+			return strconv.FormatFloat(val, 'f', -1, 64) + " " + unit
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+func joinToolPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// truncateText cuts s to max runes, noting how much was dropped
+func truncateText(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max]) + fmt.Sprintf("… [truncated %d characters]", len(runes)-max)
+}
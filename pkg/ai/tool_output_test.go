@@ -0,0 +1,139 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestFormatToolResult(t *testing.T) {
+	t.Parallel()
+
+	tool := types.Tool{
+		Name: "weather",
+		OutputFormat: &types.ToolOutputFormat{
+			MaxStringLength: 5,
+			MaxArrayItems:   2,
+			Units:           map[string]string{"temp": "°C", "readings.wind": "km/h"},
+		},
+	}
+	result := map[string]interface{}{
+		"temp":     21.5,
+		"summary":  "partly cloudy",
+		"readings": []map[string]interface{}{{"wind": 12}, {"wind": 14}, {"wind": 9}},
+	}
+
+	got, err := FormatToolResult(tool, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"readings":[{"wind":"12 km/h"},{"wind":"14 km/h"},"… 1 more items"],"summary":"partl…","temp":"21.5 °C"}`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestFormatToolResult_MaxLength(t *testing.T) {
+	t.Parallel()
+
+	tool := types.Tool{Name: "t", OutputFormat: &types.ToolOutputFormat{MaxLength: 4}}
+	got, err := FormatToolResult(tool, "abcdefgh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "abcd… [truncated 4 characters]" {
+		t.Errorf("unexpected truncation: %q", got)
+	}
+}
+
+func TestGenerateText_ToolOutputSchema(t *testing.T) {
+	t.Parallel()
+
+	tools := []types.Tool{
+		{
+			Name:         "get_weather",
+			Description:  "Get the weather",
+			OutputSchema: map[string]interface{}{"type": "object"},
+			OutputFormat: &types.ToolOutputFormat{DescribeOutput: true},
+			Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+				return map[string]interface{}{"temperature": 72, "condition": "sunny"}, nil
+			},
+		},
+	}
+
+	var description string
+	var sent interface{}
+	callCount := 0
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			callCount++
+			description = opts.Tools[0].Description
+			if callCount == 1 {
+				return &types.GenerateResult{
+					FinishReason: types.FinishReasonToolCalls,
+					ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: "get_weather", Arguments: map[string]interface{}{}}},
+				}, nil
+			}
+			last := opts.Prompt.Messages[len(opts.Prompt.Messages)-1]
+			sent = last.Content[0].(types.ToolResultContent).Result
+			return &types.GenerateResult{Text: "done", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    model,
+		Prompt:   "weather?",
+		Tools:    tools,
+		StopWhen: []StopCondition{StepCountIs(3)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(description, `Returns JSON matching this schema: {"type":"object"}`) {
+		t.Errorf("expected schema in description, got %q", description)
+	}
+	if tools[0].Description != "Get the weather" {
+		t.Error("caller's tool definition was modified")
+	}
+	if sent != `{"condition":"sunny","temperature":72}` {
+		t.Errorf("unexpected tool result sent to model: %#v", sent)
+	}
+}
+
+func TestNewToolResultContent_ToolErrors(t *testing.T) {
+	t.Parallel()
+
+	typed := &types.ToolError{
+		Code:    types.ToolErrorNotFound,
+		Message: "No order with that ID.",
+		Detail:  "orders.Get: no rows",
+	}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"typed", fmt.Errorf("lookup: %w", typed), `{"error":{"code":"not_found","message":"No order with that ID.","retryable":false}}`},
+		{"timeout", context.DeadlineExceeded, `{"error":{"code":"timeout","message":"The tool did not finish in time.","retryable":true}}`},
+		{"opaque", errors.New("disk full"), `{"error":{"code":"internal","message":"disk full","retryable":false}}`},
+	}
+	for _, tt := range tests {
+		content := NewToolResultContent(context.Background(), nil, types.ToolResult{ToolCallID: "c1", ToolName: "orders", Error: tt.err})
+		if content.Result != tt.want || content.Output == nil || content.Output.Type != types.ToolResultOutputError || content.Output.Value != tt.want {
+			t.Errorf("%s: unexpected content %#v", tt.name, content)
+		}
+		if strings.Contains(content.Result.(string), "no rows") {
+			t.Errorf("%s: internal detail sent to the model", tt.name)
+		}
+	}
+	if typed.Error() != "No order with that ID.: orders.Get: no rows" {
+		t.Errorf("Error() = %q", typed.Error())
+	}
+}
@@ -0,0 +1,358 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Input fields added to paginated tools
+const (
+	// PageCursorField is the input field carrying the cursor from the
+	// previous page's nextCursor
+	PageCursorField = "cursor"
+
+	// PageSizeField is the input field carrying the requested page size
+	PageSizeField = "pageSize"
+)
+
+// PageRequest is the page a paginated tool is asked for
+type PageRequest struct {
+	// Cursor is the previous page's NextCursor, or empty for the first page
+	Cursor string
+
+	// PageSize is the number of items to return, already clamped to the
+	// tool's MaxPageSize
+	PageSize int
+}
+
+// Page is one page of a paginated tool result, as returned to the model
+type Page struct {
+	// Items on this page
+	Items interface{} `json:"items"`
+
+	// NextCursor fetches the next page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// HasMore reports whether there are further pages
+	HasMore bool `json:"hasMore"`
+
+	// Total is the number of items across all pages, when known
+	Total int `json:"total,omitempty"`
+
+	// Next tells the model how to fetch the next page; set by the wrapper
+	Next string `json:"next,omitempty"`
+}
+
+// PageFunc fetches one page for a paginated tool. input is the tool input
+// without the paging fields.
+type PageFunc func(ctx context.Context, input map[string]interface{}, page PageRequest) (*Page, error)
+
+// PaginationOptions configures PaginatedTool and PaginateToolResult
+type PaginationOptions struct {
+	// PageSize is used when the model does not ask for one (default: 20)
+	PageSize int
+
+	// MaxPageSize caps the page size the model may ask for (default: 100)
+	MaxPageSize int
+
+	// ItemSchema is the JSON Schema of one item. When set, the tool's
+	// OutputSchema becomes PageSchema(ItemSchema).
+	ItemSchema interface{}
+
+	// MaxCachedResults is how many results PaginateToolResult keeps for
+	// follow-up pages; the oldest is dropped first (default: 16)
+	MaxCachedResults int
+}
+
+func (o PaginationOptions) withDefaults() PaginationOptions {
+	if o.PageSize <= 0 {
+		o.PageSize = 20
+	}
+	if o.MaxPageSize <= 0 {
+		o.MaxPageSize = 100
+	}
+	if o.PageSize > o.MaxPageSize {
+		o.PageSize = o.MaxPageSize
+	}
+	if o.MaxCachedResults <= 0 {
+		o.MaxCachedResults = 16
+	}
+	return o
+}
+
+const paginationHint = "Results are paginated. To see more, call this tool again with cursor set to the previous nextCursor; " +
+	"stop paging once you have what you need."
+
+// PaginatedTool returns tool with cursor and pageSize input fields whose
+// results are served one page at a time by fetch, so a tool over a large
+// dataset returns a page and the model asks for the next one only when it
+// needs it. tool.Execute is replaced.
+//
+// Example:
+//
+//	orders := ai.PaginatedTool(types.Tool{
+//		Name:        "listOrders",
+//		Description: "List a customer's orders, newest first",
+//		Parameters:  orderFilterSchema,
+//	}, func(ctx context.Context, input map[string]interface{}, page ai.PageRequest) (*ai.Page, error) {
+//		rows, next, err := db.Orders(ctx, input["customerId"].(string), page.Cursor, page.PageSize)
+//		return &ai.Page{Items: rows, NextCursor: next}, err
+//	}, ai.PaginationOptions{PageSize: 25})
+func PaginatedTool(tool types.Tool, fetch PageFunc, opts PaginationOptions) types.Tool {
+	opts = opts.withDefaults()
+	tool = withPaginationSchema(tool, opts)
+	tool.Execute = func(ctx context.Context, input map[string]interface{}, execOpts types.ToolExecutionOptions) (interface{}, error) {
+		rest, req, err := splitPageRequest(input, opts)
+		if err != nil {
+			return nil, err
+		}
+		page, err := fetch(ctx, rest, req)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			page = &Page{}
+		}
+		page.HasMore = page.NextCursor != ""
+		page.Next = nextPageHint(tool.Name, page.NextCursor)
+		return page, nil
+	}
+	return tool
+}
+
+// PaginateToolResult wraps a tool whose Execute returns a list, returning
+// the list one page at a time. The tool runs once per first page; the rest
+// of its result is kept in memory and served for the cursors it hands out,
+// without running the tool again. Results that are not lists are returned
+// unchanged.
+func PaginateToolResult(tool types.Tool, opts PaginationOptions) types.Tool {
+	if tool.Execute == nil {
+		return tool
+	}
+	opts = opts.withDefaults()
+	execute := tool.Execute
+	cache := &pageCache{max: opts.MaxCachedResults, items: make(map[string][]interface{})}
+	tool = withPaginationSchema(tool, opts)
+	tool.Execute = func(ctx context.Context, input map[string]interface{}, execOpts types.ToolExecutionOptions) (interface{}, error) {
+		rest, req, err := splitPageRequest(input, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var id string
+		var items []interface{}
+		offset := 0
+		if req.Cursor != "" {
+			var ok bool
+			id, offset, ok = parsePageCursor(req.Cursor)
+			if ok {
+				items, ok = cache.get(id)
+			}
+			if !ok || offset > len(items) {
+				return nil, fmt.Errorf("cursor %q has expired or is invalid; call %s again without a cursor", req.Cursor, tool.Name)
+			}
+		} else {
+			result, err := execute(ctx, rest, execOpts)
+			if err != nil {
+				return result, err
+			}
+			list, ok := resultList(result)
+			if !ok {
+				return result, nil
+			}
+			items = list
+			if len(items) > req.PageSize {
+				id = newCallID()
+				cache.put(id, items)
+			}
+		}
+
+		end := offset + req.PageSize
+		if end > len(items) {
+			end = len(items)
+		}
+		page := &Page{Items: items[offset:end], Total: len(items)}
+		if end < len(items) {
+			page.NextCursor = id + ":" + strconv.Itoa(end)
+		}
+		page.HasMore = page.NextCursor != ""
+		page.Next = nextPageHint(tool.Name, page.NextCursor)
+		return page, nil
+	}
+	return tool
+}
+
+// PaginationParameters returns a copy of an object input schema with the
+// cursor and pageSize fields added
+func PaginationParameters(parameters interface{}, opts PaginationOptions) map[string]interface{} {
+	opts = opts.withDefaults()
+	schema := map[string]interface{}{"type": "object"}
+	if parameters != nil {
+		if data, err := json.Marshal(parameters); err == nil {
+			var decoded map[string]interface{}
+			if json.Unmarshal(data, &decoded) == nil && decoded != nil {
+				schema = decoded
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	properties[PageCursorField] = map[string]interface{}{
+		"type":        "string",
+		"description": "nextCursor from the previous page; omit for the first page",
+	}
+	properties[PageSizeField] = map[string]interface{}{
+		"type":        "integer",
+		"minimum":     1,
+		"maximum":     opts.MaxPageSize,
+		"description": fmt.Sprintf("Items per page (default %d)", opts.PageSize),
+	}
+	schema["properties"] = properties
+	return schema
+}
+
+// PageSchema returns the JSON Schema of a Page whose items match itemSchema
+func PageSchema(itemSchema interface{}) map[string]interface{} {
+	items := map[string]interface{}{"type": "array"}
+	if itemSchema != nil {
+		items["items"] = itemSchema
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"items":      items,
+			"nextCursor": map[string]interface{}{"type": "string"},
+			"hasMore":    map[string]interface{}{"type": "boolean"},
+			"total":      map[string]interface{}{"type": "integer"},
+			"next":       map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"items", "hasMore"},
+	}
+}
+
+// withPaginationSchema adds the paging fields, output schema and
+// description hint to tool
+func withPaginationSchema(tool types.Tool, opts PaginationOptions) types.Tool {
+	tool.Parameters = PaginationParameters(tool.Parameters, opts)
+	if opts.ItemSchema != nil {
+		tool.OutputSchema = PageSchema(opts.ItemSchema)
+	}
+	if tool.Description == "" {
+		tool.Description = paginationHint
+	} else {
+		tool.Description += "\n\n" + paginationHint
+	}
+	return tool
+}
+
+// splitPageRequest removes the paging fields from input and returns the
+// page they ask for
+func splitPageRequest(input map[string]interface{}, opts PaginationOptions) (map[string]interface{}, PageRequest, error) {
+	req := PageRequest{PageSize: opts.PageSize}
+	rest := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		rest[k] = v
+	}
+	if v, ok := rest[PageCursorField]; ok {
+		delete(rest, PageCursorField)
+		if v != nil {
+			cursor, ok := v.(string)
+			if !ok {
+				return nil, req, fmt.Errorf("%s must be a string", PageCursorField)
+			}
+			req.Cursor = cursor
+		}
+	}
+	if v, ok := rest[PageSizeField]; ok {
+		delete(rest, PageSizeField)
+		if v != nil {
+			size, ok := v.(float64)
+			if !ok {
+				if i, isInt := v.(int); isInt {
+					size, ok = float64(i), true
+				}
+			}
+			if !ok || size < 1 {
+				return nil, req, fmt.Errorf("%s must be a positive integer", PageSizeField)
+			}
+			req.PageSize = int(size)
+		}
+	}
+	if req.PageSize > opts.MaxPageSize {
+		req.PageSize = opts.MaxPageSize
+	}
+	return rest, req, nil
+}
+
+func nextPageHint(toolName, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	return fmt.Sprintf("More results available: call %s again with the same arguments and cursor %q", toolName, cursor)
+}
+
+// resultList returns result as a list of JSON values
+func resultList(result interface{}) ([]interface{}, bool) {
+	if list, ok := result.([]interface{}); ok {
+		return list, true
+	}
+	if result == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(result)
+	if err != nil || len(data) == 0 || data[0] != '[' {
+		return nil, false
+	}
+	var list []interface{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, false
+	}
+	return list, true
+}
+
+func parsePageCursor(cursor string) (string, int, bool) {
+	id, offset, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(offset)
+	if err != nil || n < 0 {
+		return "", 0, false
+	}
+	return id, n, true
+}
+
+// pageCache keeps the most recent results of a PaginateToolResult tool
+type pageCache struct {
+	max int
+
+	mu    sync.Mutex
+	order []string
+	items map[string][]interface{}
+}
+
+func (c *pageCache) get(id string) ([]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items, ok := c.items[id]
+	return items, ok
+}
+
+func (c *pageCache) put(id string, items []interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[id] = items
+	c.order = append(c.order, id)
+	for len(c.order) > c.max {
+		delete(c.items, c.order[0])
+		c.order = c.order[1:]
+	}
+}
@@ -0,0 +1,159 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestPaginatedTool(t *testing.T) {
+	t.Parallel()
+
+	var requests []PageRequest
+	var inputs []map[string]interface{}
+	tool := PaginatedTool(types.Tool{
+		Name:        "listOrders",
+		Description: "List orders",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"status": map[string]interface{}{"type": "string"}},
+		},
+	}, func(ctx context.Context, input map[string]interface{}, page PageRequest) (*Page, error) {
+		requests = append(requests, page)
+		inputs = append(inputs, input)
+		if page.Cursor == "" {
+			return &Page{Items: []string{"a", "b"}, NextCursor: "after-b"}, nil
+		}
+		return &Page{Items: []string{"c"}}, nil
+	}, PaginationOptions{PageSize: 2, MaxPageSize: 5, ItemSchema: map[string]interface{}{"type": "string"}})
+
+	props := tool.Parameters.(map[string]interface{})["properties"].(map[string]interface{})
+	if props["status"] == nil || props[PageCursorField] == nil || props[PageSizeField] == nil {
+		t.Errorf("parameters = %v", props)
+	}
+	if !strings.Contains(tool.Description, "paginated") || tool.OutputSchema == nil {
+		t.Errorf("description = %q, output schema = %v", tool.Description, tool.OutputSchema)
+	}
+
+	first, err := tool.Execute(context.Background(), map[string]interface{}{"status": "open"}, types.ToolExecutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := first.(*Page)
+	if !page.HasMore || page.NextCursor != "after-b" || !strings.Contains(page.Next, `"after-b"`) {
+		t.Errorf("first page = %+v", page)
+	}
+
+	last, err := tool.Execute(context.Background(), map[string]interface{}{"status": "open", "cursor": "after-b", "pageSize": 50.0}, types.ToolExecutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := last.(*Page); page.HasMore || page.Next != "" {
+		t.Errorf("last page = %+v", page)
+	}
+	if requests[0] != (PageRequest{PageSize: 2}) || requests[1] != (PageRequest{Cursor: "after-b", PageSize: 5}) {
+		t.Errorf("requests = %+v", requests)
+	}
+	if len(inputs[1]) != 1 || inputs[1]["status"] != "open" {
+		t.Errorf("paging fields should be removed from input, got %v", inputs[1])
+	}
+}
+
+func TestPaginateToolResult_ModelPagesThroughLoop(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	tool := PaginateToolResult(types.Tool{
+		Name: "search",
+		Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			runs++
+			return []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}}, nil
+		},
+	}, PaginationOptions{PageSize: 2})
+
+	// The model follows nextCursor until the last page, then answers
+	var pages []*Page
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			args := map[string]interface{}{"q": "go"}
+			if msgs := opts.Prompt.Messages; len(msgs) > 1 {
+				page := msgs[len(msgs)-1].Content[0].(types.ToolResultContent).Result.(*Page)
+				pages = append(pages, page)
+				if !page.HasMore {
+					return &types.GenerateResult{Text: "done", FinishReason: types.FinishReasonStop}, nil
+				}
+				args["cursor"] = page.NextCursor
+			}
+			return &types.GenerateResult{
+				FinishReason: types.FinishReasonToolCalls,
+				ToolCalls:    []types.ToolCall{{ID: "call", ToolName: "search", Arguments: args}},
+			}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    model,
+		Prompt:   "find everything",
+		Tools:    []types.Tool{tool},
+		StopWhen: []StopCondition{StepCountIs(10)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "done" || runs != 1 || len(pages) != 3 {
+		t.Fatalf("text %q, tool ran %d times, %d pages", result.Text, runs, len(pages))
+	}
+	var ids []interface{}
+	for _, page := range pages {
+		for _, item := range page.Items.([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["id"])
+		}
+		if page.Total != 5 {
+			t.Errorf("total = %d", page.Total)
+		}
+	}
+	if len(ids) != 5 || ids[0] != 1.0 || ids[4] != 5.0 {
+		t.Errorf("ids = %v", ids)
+	}
+}
+
+func TestPaginateToolResult_Cursors(t *testing.T) {
+	t.Parallel()
+
+	tool := PaginateToolResult(types.Tool{
+		Name: "search",
+		Execute: func(ctx context.Context, args map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			if args["q"] == "one" {
+				return []string{"x"}, nil
+			}
+			return map[string]interface{}{"hits": 0}, nil
+		},
+	}, PaginationOptions{})
+
+	// A list that fits in one page needs no cursor
+	got, err := tool.Execute(context.Background(), map[string]interface{}{"q": "one"}, types.ToolExecutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := got.(*Page); page.HasMore || page.NextCursor != "" || page.Total != 1 {
+		t.Errorf("page = %+v", page)
+	}
+
+	// Results that are not lists pass through
+	got, err = tool.Execute(context.Background(), map[string]interface{}{"q": "other"}, types.ToolExecutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.(map[string]interface{}); !ok {
+		t.Errorf("expected the result unchanged, got %#v", got)
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"cursor": "unknown:2"}, types.ToolExecutionOptions{}); err == nil || !strings.Contains(err.Error(), "without a cursor") {
+		t.Errorf("expected expired cursor error, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ToolResultStrategy selects how oversized tool results are condensed
+type ToolResultStrategy string
+
+const (
+	// ToolResultHeadTail keeps the beginning and end of the serialized result
+	ToolResultHeadTail ToolResultStrategy = "head-tail"
+
+	// ToolResultPruneJSON shortens arrays and strings inside JSON results
+	// until they fit, falling back to head/tail for non-JSON output
+	ToolResultPruneJSON ToolResultStrategy = "prune-json"
+
+	// ToolResultSummarize asks a language model to summarize the result,
+	// falling back to head/tail without a model or if summarization fails
+	ToolResultSummarize ToolResultStrategy = "summarize"
+)
+
+// ToolResultLimit bounds the size of a tool's result before it is added to
+// the conversation
+type ToolResultLimit struct {
+	// MaxTokens is the result budget. Results within the budget are left
+	// untouched (0 = no limit).
+	MaxTokens int
+
+	// Strategy used for results over budget (default: ToolResultHeadTail)
+	Strategy ToolResultStrategy
+
+	// Model summarizes results for ToolResultSummarize. Without one, results
+	// are condensed with head/tail.
+	Model provider.LanguageModel
+
+	// SummaryPrompt overrides the default summarization instructions
+	SummaryPrompt string
+
+	// EstimateTokens overrides the default estimate of 4 characters per token
+	EstimateTokens func(text string) int
+}
+
+const defaultSummaryPrompt = "Summarize the following tool output so it can replace the original in a conversation. " +
+	"Keep identifiers, numbers, names, URLs and any facts needed to answer follow-up questions. " +
+	"Do not add information that is not in the output."
+
+// LimitToolResult wraps tool so results larger than limit are condensed
+// before they reach the model. The tool's other fields are unchanged.
+func LimitToolResult(tool types.Tool, limit ToolResultLimit) types.Tool {
+	if tool.Execute == nil || limit.MaxTokens <= 0 {
+		return tool
+	}
+	execute := tool.Execute
+	tool.Execute = func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		result, err := execute(ctx, input, opts)
+		if err != nil {
+			return result, err
+		}
+		return CondenseToolResult(ctx, result, limit)
+	}
+	return tool
+}
+
+// LimitToolResults applies LimitToolResult to every tool, using the entry in
+// perTool when one exists for the tool's name and limit otherwise
+func LimitToolResults(tools []types.Tool, limit ToolResultLimit, perTool map[string]ToolResultLimit) []types.Tool {
+	out := make([]types.Tool, len(tools))
+	for i, tool := range tools {
+		l := limit
+		if override, ok := perTool[tool.Name]; ok {
+			l = override
+		}
+		out[i] = LimitToolResult(tool, l)
+	}
+	return out
+}
+
+// CondenseToolResult returns result unchanged when it fits in the budget,
+// otherwise a condensed string form produced by the limit's strategy
+func CondenseToolResult(ctx context.Context, result interface{}, limit ToolResultLimit) (interface{}, error) {
+	if limit.MaxTokens <= 0 || result == nil {
+		return result, nil
+	}
+
+	text, isJSON := toolResultText(result)
+	if limit.estimate(text) <= limit.MaxTokens {
+		return result, nil
+	}
+
+	switch limit.Strategy {
+	case ToolResultPruneJSON:
+		if isJSON {
+			if pruned, ok := pruneJSONResult(text, limit); ok {
+				return pruned, nil
+			}
+		}
+	case ToolResultSummarize:
+		if limit.Model != nil {
+			if summary, err := summarizeToolResult(ctx, text, limit); err == nil {
+				return summary, nil
+			}
+		}
+	}
+	return headTail(text, limit.MaxTokens*limit.charsPerToken()), nil
+}
+
+func (l ToolResultLimit) estimate(text string) int {
+	if l.EstimateTokens != nil {
+		return l.EstimateTokens(text)
+	}
+	return utf8.RuneCountInString(text) / 4
+}
+
+// charsPerToken is the character budget per token used when cutting text
+func (l ToolResultLimit) charsPerToken() int {
+	if l.EstimateTokens == nil {
+		return 4
+	}
+	sample := "The quick brown fox jumps over the lazy dog."
+	if n := l.EstimateTokens(sample); n > 0 {
+		if c := utf8.RuneCountInString(sample) / n; c > 0 {
+			return c
+		}
+	}
+	return 4
+}
+
+// toolResultText serializes a result for measuring and condensing, reporting
+// whether the text is JSON
+func toolResultText(result interface{}) (string, bool) {
+	if s, ok := result.(string); ok {
+		return s, json.Valid([]byte(s))
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("%v", result), false
+	}
+	return string(data), true
+}
+
+// pruneJSONResult shortens arrays and long strings step by step until the
+// result fits in the budget
+func pruneJSONResult(text string, limit ToolResultLimit) (string, bool) {
+	levels := []types.ToolOutputFormat{
+		{MaxArrayItems: 50, MaxStringLength: 2000},
+		{MaxArrayItems: 20, MaxStringLength: 1000},
+		{MaxArrayItems: 10, MaxStringLength: 500},
+		{MaxArrayItems: 5, MaxStringLength: 200},
+		{MaxArrayItems: 3, MaxStringLength: 100},
+		{MaxArrayItems: 1, MaxStringLength: 50},
+	}
+	for i := range levels {
+		// shapeToolValue mutates maps in place, so work on a fresh copy
+		var v interface{}
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			return "", false
+		}
+		data, err := json.Marshal(shapeToolValue(v, "", &levels[i]))
+		if err != nil {
+			return "", false
+		}
+		if limit.estimate(string(data)) <= limit.MaxTokens {
+			return string(data), true
+		}
+	}
+	return "", false
+}
+
+func summarizeToolResult(ctx context.Context, text string, limit ToolResultLimit) (string, error) {
+	instructions := limit.SummaryPrompt
+	if instructions == "" {
+		instructions = defaultSummaryPrompt
+	}
+	maxTokens := limit.MaxTokens
+	result, err := GenerateText(ctx, GenerateTextOptions{
+		Model:     limit.Model,
+		System:    instructions,
+		Prompt:    text,
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// headTail keeps roughly two thirds of the budget from the start of text and
+// one third from the end
+func headTail(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	head := maxChars * 2 / 3
+	tail := maxChars - head
+	omitted := len(runes) - head - tail
+	return string(runes[:head]) + fmt.Sprintf("\n… [%d characters omitted] …\n", omitted) + string(runes[len(runes)-tail:])
+}
@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestCondenseToolResult_UnderBudget(t *testing.T) {
+	t.Parallel()
+
+	result := map[string]interface{}{"ok": true}
+	got, err := CondenseToolResult(context.Background(), result, ToolResultLimit{MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m, ok := got.(map[string]interface{}); !ok || m["ok"] != true {
+		t.Errorf("expected result to be returned unchanged, got %#v", got)
+	}
+}
+
+func TestCondenseToolResult_HeadTail(t *testing.T) {
+	t.Parallel()
+
+	text := "HEAD" + strings.Repeat("x", 1000) + "TAIL"
+	got, err := CondenseToolResult(context.Background(), text, ToolResultLimit{MaxTokens: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := got.(string)
+	if !strings.HasPrefix(s, "HEAD") || !strings.HasSuffix(s, "TAIL") || !strings.Contains(s, "characters omitted") {
+		t.Errorf("unexpected head/tail output: %q", s)
+	}
+}
+
+func TestCondenseToolResult_PruneJSON(t *testing.T) {
+	t.Parallel()
+
+	hits := make([]map[string]interface{}, 100)
+	for i := range hits {
+		hits[i] = map[string]interface{}{"title": "result", "snippet": strings.Repeat("lorem ipsum ", 20)}
+	}
+	got, err := CondenseToolResult(context.Background(), map[string]interface{}{"hits": hits}, ToolResultLimit{
+		MaxTokens: 200,
+		Strategy:  ToolResultPruneJSON,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := got.(string)
+	if len(s)/4 > 200 {
+		t.Errorf("pruned result over budget: %d chars", len(s))
+	}
+	if !strings.HasPrefix(s, `{"hits":[`) || !strings.Contains(s, "more items") {
+		t.Errorf("expected pruned JSON with omission marker, got %s", s)
+	}
+}
+
+func TestCondenseToolResult_Summarize(t *testing.T) {
+	t.Parallel()
+
+	var maxTokens *int
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			maxTokens = opts.MaxTokens
+			return &types.GenerateResult{Text: "three results about Go", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	got, err := CondenseToolResult(context.Background(), strings.Repeat("search hit ", 100), ToolResultLimit{
+		MaxTokens: 50,
+		Strategy:  ToolResultSummarize,
+		Model:     model,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "three results about Go" {
+		t.Errorf("unexpected summary: %#v", got)
+	}
+	if maxTokens == nil || *maxTokens != 50 {
+		t.Errorf("expected summary capped at the budget, got %v", maxTokens)
+	}
+
+	failing := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+	got, err = CondenseToolResult(context.Background(), strings.Repeat("search hit ", 100), ToolResultLimit{
+		MaxTokens: 50,
+		Strategy:  ToolResultSummarize,
+		Model:     failing,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got.(string), "characters omitted") {
+		t.Errorf("expected head/tail fallback, got %q", got)
+	}
+
+	got, err = CondenseToolResult(context.Background(), strings.Repeat("search hit ", 100), ToolResultLimit{
+		MaxTokens: 50,
+		Strategy:  ToolResultSummarize,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error without a model: %v", err)
+	}
+	if !strings.Contains(got.(string), "characters omitted") {
+		t.Errorf("expected head/tail fallback without a model, got %q", got)
+	}
+}
+
+func TestLimitToolResults_PerTool(t *testing.T) {
+	t.Parallel()
+
+	big := func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+		return strings.Repeat("a", 400), nil
+	}
+	tools := LimitToolResults(
+		[]types.Tool{{Name: "search", Execute: big}, {Name: "fetch", Execute: big}},
+		ToolResultLimit{MaxTokens: 10},
+		map[string]ToolResultLimit{"fetch": {}},
+	)
+
+	search, _ := tools[0].Execute(context.Background(), nil, types.ToolExecutionOptions{})
+	fetch, _ := tools[1].Execute(context.Background(), nil, types.ToolExecutionOptions{})
+	if len(search.(string)) >= 400 {
+		t.Error("expected search result to be condensed")
+	}
+	if len(fetch.(string)) != 400 {
+		t.Error("expected per-tool override to disable the limit for fetch")
+	}
+}
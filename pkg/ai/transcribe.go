@@ -0,0 +1,284 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/audio"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// TranscribeOptions contains options for Transcribe
+type TranscribeOptions struct {
+	// Model to transcribe with (required)
+	Model provider.TranscriptionModel
+
+	// Audio data and its MIME type (detected from WAV headers when empty)
+	Audio    []byte
+	MimeType string
+
+	// Language of the audio (optional)
+	Language string
+
+	// Timestamps requests segment timestamps in the result
+	Timestamps bool
+
+	// MaxBytes is the largest upload the model accepts
+	// Default: 25MB
+	MaxBytes int
+
+	// SampleRate is the rate decoded audio is converted to, as mono 16-bit
+	// WAV. Audio is never upsampled.
+	// Default: 16000, which transcription models use internally
+	SampleRate int
+
+	// Overlap is the audio shared by adjacent chunks, so words cut at a chunk
+	// boundary are heard whole by one of them
+	// Default: 2s
+	Overlap time.Duration
+
+	// Concurrency is the number of chunks transcribed at once
+	// Default: 4
+	Concurrency int
+
+	// Decode converts audio other than WAV (MP3, M4A, Opus...) to mono
+	// 16-bit PCM, e.g. by piping it through ffmpeg. Without it, such audio
+	// is sent as-is and fails if it is over MaxBytes.
+	Decode func(ctx context.Context, data []byte, mimeType string) (samples []int16, sampleRate int, err error)
+}
+
+// TranscribeResult is the result of Transcribe
+type TranscribeResult struct {
+	// Text is the transcript of the whole recording
+	Text string
+
+	// Timestamps are segment times relative to the start of the recording,
+	// when the model reports them
+	Timestamps []types.TranscriptionTimestamp
+
+	// Usage totals the usage of every request
+	Usage types.TranscriptionUsage
+
+	// Chunks is the number of requests the audio was split into
+	Chunks int
+}
+
+// Transcribe converts speech to text. Decodable audio (WAV, or anything
+// Decode handles) is transcoded to mono WAV at SampleRate, which usually
+// shrinks it several times. Audio still over MaxBytes is split into chunks
+// that overlap by Overlap, transcribed concurrently and merged into one
+// transcript: with timestamps, each overlap is cut at its midpoint; without
+// them, words repeated across the boundary are removed.
+//
+// Example:
+//
+//	whisper, _ := openaiProvider.TranscriptionModel("whisper-1")
+//	result, err := ai.Transcribe(ctx, ai.TranscribeOptions{
+//		Model:    whisper,
+//		Audio:    recording,
+//		MimeType: "audio/wav",
+//	})
+//	fmt.Println(result.Text)
+func Transcribe(ctx context.Context, opts TranscribeOptions) (*TranscribeResult, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if len(opts.Audio) == 0 {
+		return nil, fmt.Errorf("audio is required")
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 25 << 20
+	}
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 16000
+	}
+	if opts.Overlap <= 0 {
+		opts.Overlap = 2 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.MimeType == "" && len(opts.Audio) >= 12 && string(opts.Audio[:4]) == "RIFF" && string(opts.Audio[8:12]) == "WAVE" {
+		opts.MimeType = "audio/wav"
+	}
+
+	samples, rate, err := decodeAudio(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		if len(opts.Audio) > opts.MaxBytes {
+			return nil, fmt.Errorf("%s audio of %d bytes exceeds the %d-byte limit; set Decode to transcode it", opts.MimeType, len(opts.Audio), opts.MaxBytes)
+		}
+		return transcribeOne(ctx, opts, opts.Audio, opts.MimeType)
+	}
+
+	if rate > opts.SampleRate {
+		samples = audio.Resample(samples, rate, opts.SampleRate)
+		rate = opts.SampleRate
+	}
+	const wavHeader = 44
+	if wavHeader+2*len(samples) <= opts.MaxBytes {
+		return transcribeOne(ctx, opts, audio.EncodeWAV(samples, rate), "audio/wav")
+	}
+
+	chunkLen := (opts.MaxBytes - wavHeader) / 2
+	overlap := int(opts.Overlap.Seconds() * float64(rate))
+	if overlap > chunkLen/2 {
+		overlap = chunkLen / 2
+	}
+	var starts []int
+	for start := 0; ; start += chunkLen - overlap {
+		starts = append(starts, start)
+		if start+chunkLen >= len(samples) {
+			break
+		}
+	}
+
+	results := make([]*types.TranscriptionResult, len(starts))
+	errs := make([]error, len(starts))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, start := range starts {
+		wg.Add(1)
+		go func(i, start int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			end := min(start+chunkLen, len(samples))
+			results[i], errs[i] = opts.Model.DoTranscribe(ctx, &provider.TranscriptionOptions{
+				Audio:      audio.EncodeWAV(samples[start:end], rate),
+				MimeType:   "audio/wav",
+				Language:   opts.Language,
+				Timestamps: true,
+			})
+		}(i, start)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("transcription of chunk %d/%d failed: %w", i+1, len(starts), err)
+		}
+	}
+
+	chunks := make([]transcriptChunk, len(starts))
+	for i, start := range starts {
+		chunks[i] = transcriptChunk{
+			result: results[i],
+			start:  float64(start) / float64(rate),
+			// The midpoint of the overlap with the next chunk
+			cut: float64(start+chunkLen-overlap/2) / float64(rate),
+		}
+	}
+	result := mergeTranscripts(chunks)
+	if !opts.Timestamps {
+		result.Timestamps = nil
+	}
+	return result, nil
+}
+
+// decodeAudio returns the audio as mono PCM, or nil samples when it cannot
+// be decoded
+func decodeAudio(ctx context.Context, opts TranscribeOptions) ([]int16, int, error) {
+	switch {
+	case opts.MimeType == "audio/wav" || opts.MimeType == "audio/x-wav" || opts.MimeType == "audio/wave":
+		samples, rate, err := audio.DecodeWAV(opts.Audio)
+		if err == nil {
+			return samples, rate, nil
+		}
+		// Compressed WAV variants fall through to Decode
+		if opts.Decode == nil {
+			return nil, 0, nil
+		}
+	case opts.Decode == nil:
+		return nil, 0, nil
+	}
+	samples, rate, err := opts.Decode(ctx, opts.Audio, opts.MimeType)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode %s audio: %w", opts.MimeType, err)
+	}
+	return samples, rate, nil
+}
+
+func transcribeOne(ctx context.Context, opts TranscribeOptions, data []byte, mimeType string) (*TranscribeResult, error) {
+	result, err := opts.Model.DoTranscribe(ctx, &provider.TranscriptionOptions{
+		Audio:      data,
+		MimeType:   mimeType,
+		Language:   opts.Language,
+		Timestamps: opts.Timestamps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TranscribeResult{Text: result.Text, Timestamps: result.Timestamps, Usage: result.Usage, Chunks: 1}, nil
+}
+
+// transcriptChunk is one chunk's transcript and where it sits in the
+// recording, in seconds
+type transcriptChunk struct {
+	result *types.TranscriptionResult
+	start  float64
+	cut    float64
+}
+
+// mergeTranscripts joins chunk transcripts. Each chunk keeps the segments
+// whose midpoint falls between the previous chunk's cut and its own; chunks
+// without timestamps are joined by removing the words they repeat from the
+// previous chunk.
+func mergeTranscripts(chunks []transcriptChunk) *TranscribeResult {
+	merged := &TranscribeResult{Chunks: len(chunks)}
+	var words []string
+	prevCut := 0.0
+	for i, c := range chunks {
+		merged.Usage.DurationSeconds += c.result.Usage.DurationSeconds
+		last := i == len(chunks)-1
+
+		if len(c.result.Timestamps) > 0 {
+			for _, ts := range c.result.Timestamps {
+				ts.Start += c.start
+				ts.End += c.start
+				mid := (ts.Start + ts.End) / 2
+				if (i > 0 && mid < prevCut) || (!last && mid >= c.cut) {
+					continue
+				}
+				merged.Timestamps = append(merged.Timestamps, ts)
+				words = append(words, strings.Fields(ts.Text)...)
+			}
+		} else {
+			next := strings.Fields(c.result.Text)
+			words = append(words, next[repeatedWords(words, next):]...)
+		}
+		prevCut = c.cut
+	}
+	merged.Text = strings.Join(words, " ")
+	return merged
+}
+
+// repeatedWords returns how many leading words of next repeat the end of
+// prev, comparing case- and punctuation-insensitively
+func repeatedWords(prev, next []string) int {
+	longest := min(min(len(prev), len(next)), 50)
+	// A single matching word is too likely to be a coincidence
+	for n := longest; n > 1; n-- {
+		match := true
+		for i := 0; i < n; i++ {
+			if normalizeWord(prev[len(prev)-n+i]) != normalizeWord(next[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	return 0
+}
+
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool { return unicode.IsPunct(r) }))
+}
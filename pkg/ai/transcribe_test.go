@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/audio"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// secondsTranscriber transcribes each second of 1 kHz audio as the word
+// "wN", where N is the second's index in the whole recording. The samples
+// hold their own index so chunks can be located.
+func secondsTranscriber(timestamps bool, requests *[]provider.TranscriptionOptions, mu *sync.Mutex) *testutil.MockTranscriptionModel {
+	return &testutil.MockTranscriptionModel{
+		DoTranscribeFunc: func(ctx context.Context, opts *provider.TranscriptionOptions) (*types.TranscriptionResult, error) {
+			mu.Lock()
+			*requests = append(*requests, *opts)
+			mu.Unlock()
+			samples, rate, err := audio.DecodeWAV(opts.Audio)
+			if err != nil {
+				return nil, err
+			}
+			first := int(samples[0]) / rate
+			seconds := len(samples) / rate
+			result := &types.TranscriptionResult{Usage: types.TranscriptionUsage{DurationSeconds: float64(seconds)}}
+			var words []string
+			for s := 0; s < seconds; s++ {
+				word := fmt.Sprintf("w%d", first+s)
+				words = append(words, word)
+				if timestamps {
+					result.Timestamps = append(result.Timestamps, types.TranscriptionTimestamp{Text: word, Start: float64(s), End: float64(s + 1)})
+				}
+			}
+			result.Text = strings.Join(words, " ")
+			return result, nil
+		},
+	}
+}
+
+// countingAudio returns n seconds of 1 kHz WAV whose samples are their own
+// index
+func countingAudio(seconds int) []byte {
+	samples := make([]int16, seconds*1000)
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+	return audio.EncodeWAV(samples, 1000)
+}
+
+func TestTranscribe_ChunksAndMerges(t *testing.T) {
+	t.Parallel()
+
+	want := "w0 w1 w2 w3 w4 w5 w6 w7 w8 w9 w10 w11 w12 w13 w14 w15 w16 w17 w18 w19"
+	for _, timestamps := range []bool{true, false} {
+		var requests []provider.TranscriptionOptions
+		var mu sync.Mutex
+		result, err := Transcribe(context.Background(), TranscribeOptions{
+			Model:      secondsTranscriber(timestamps, &requests, &mu),
+			Audio:      countingAudio(20),
+			Timestamps: timestamps,
+			MaxBytes:   44 + 2*8000, // 8 seconds
+			Overlap:    2 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("Transcribe: %v", err)
+		}
+		if result.Text != want {
+			t.Errorf("timestamps=%v: text = %q", timestamps, result.Text)
+		}
+		if result.Chunks != 3 || len(requests) != 3 {
+			t.Errorf("timestamps=%v: %d chunks, %d requests", timestamps, result.Chunks, len(requests))
+		}
+		for _, r := range requests {
+			if len(r.Audio) > 44+2*8000 {
+				t.Errorf("chunk of %d bytes exceeds the limit", len(r.Audio))
+			}
+		}
+		if timestamps {
+			if len(result.Timestamps) != 20 || result.Timestamps[19].Start != 19 {
+				t.Errorf("unexpected timestamps %v", result.Timestamps)
+			}
+		}
+	}
+}
+
+func TestTranscribe_TranscodesBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	var sent provider.TranscriptionOptions
+	model := &testutil.MockTranscriptionModel{
+		DoTranscribeFunc: func(ctx context.Context, opts *provider.TranscriptionOptions) (*types.TranscriptionResult, error) {
+			sent = *opts
+			return &types.TranscriptionResult{Text: "hello"}, nil
+		},
+	}
+	decoded := false
+	result, err := Transcribe(context.Background(), TranscribeOptions{
+		Model:    model,
+		Audio:    []byte("ID3 fake mp3"),
+		MimeType: "audio/mpeg",
+		Decode: func(ctx context.Context, data []byte, mimeType string) ([]int16, int, error) {
+			decoded = true
+			return make([]int16, 48000), 48000, nil
+		},
+	})
+	if err != nil || result.Text != "hello" || !decoded {
+		t.Fatalf("result %+v, err %v", result, err)
+	}
+	samples, rate, err := audio.DecodeWAV(sent.Audio)
+	if err != nil || rate != 16000 || len(samples) != 16000 || sent.MimeType != "audio/wav" {
+		t.Errorf("sent %s at %d Hz with %d samples (%v)", sent.MimeType, rate, len(samples), err)
+	}
+
+	_, err = Transcribe(context.Background(), TranscribeOptions{Model: model, Audio: make([]byte, 100), MimeType: "audio/mpeg", MaxBytes: 50})
+	if err == nil {
+		t.Error("expected an error for undecodable audio over the limit")
+	}
+}
@@ -0,0 +1,448 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/sentence"
+)
+
+// Formality selects the register of a translation
+type Formality string
+
+const (
+	// FormalityDefault leaves the register to the model (the default)
+	FormalityDefault Formality = ""
+
+	// FormalityFormal uses the formal register, e.g. "Sie" in German
+	FormalityFormal Formality = "formal"
+
+	// FormalityInformal uses the informal register, e.g. "du" in German
+	FormalityInformal Formality = "informal"
+)
+
+// TranslateOptions configures Translate
+type TranslateOptions struct {
+	// Model to translate with
+	Model provider.LanguageModel
+
+	// Text to translate. Paragraph breaks and Markdown are preserved.
+	Text string
+
+	// SourceLanguage of the text (default: detected by the model)
+	SourceLanguage string
+
+	// TargetLanguage to translate into, e.g. "German" or "pt-BR"
+	TargetLanguage string
+
+	// Glossary pins translations of terms: each source term that appears in
+	// the text must be translated as its value. Map a term to itself to keep
+	// it untranslated, e.g. product names.
+	Glossary map[string]string
+
+	// Formality of the translation
+	Formality Formality
+
+	// Instructions are added to the prompt, e.g. the audience or domain
+	Instructions string
+
+	// MaxChunkChars is the largest amount of source text sent per request;
+	// longer documents are split at paragraph and then sentence boundaries
+	// (default: 4000)
+	MaxChunkChars int
+
+	// MaxRetries is how many times a response whose segments do not line up
+	// with the source is sent back to the model, for models without native
+	// structured output (default: 2, negative disables retries)
+	MaxRetries int
+
+	Temperature *float64
+	MaxTokens   *int
+	Metadata    map[string]string
+}
+
+// TranslationSegment is a source segment aligned with its translation
+type TranslationSegment struct {
+	Source      string
+	Translation string
+}
+
+// GlossaryMiss is a glossary term whose pinned translation does not appear
+// in the translation of a segment that contains the term
+type GlossaryMiss struct {
+	// Segment is the index in TranslateResult.Segments
+	Segment int
+
+	Term        string
+	Translation string
+}
+
+// TranslateResult is the result of Translate
+type TranslateResult struct {
+	// Text is the translated document
+	Text string
+
+	// SourceLanguage is SourceLanguage from the options, or the language
+	// detected by the model
+	SourceLanguage string
+
+	// Segments aligns each paragraph (or sentence, for long paragraphs)
+	// with its translation, in document order
+	Segments []TranslationSegment
+
+	// GlossaryMisses lists segments where a glossary term was not
+	// translated as pinned, for review
+	GlossaryMisses []GlossaryMiss
+
+	Usage types.Usage
+}
+
+// Translate translates text into TargetLanguage. The text is split into
+// segments that are sent, numbered, through GenerateObject, so every
+// segment is aligned with its translation and the document's structure is
+// kept; long documents are translated in chunks of MaxChunkChars.
+//
+// Example:
+//
+//	result, err := ai.Translate(ctx, ai.TranslateOptions{
+//		Model:          model,
+//		Text:           releaseNotes,
+//		TargetLanguage: "German",
+//		Formality:      ai.FormalityFormal,
+//		Glossary:       map[string]string{"workspace": "Arbeitsbereich", "GoAI": "GoAI"},
+//	})
+func Translate(ctx context.Context, opts TranslateOptions) (*TranslateResult, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if opts.TargetLanguage == "" {
+		return nil, fmt.Errorf("target language is required")
+	}
+	switch opts.Formality {
+	case FormalityDefault, FormalityFormal, FormalityInformal:
+	default:
+		return nil, fmt.Errorf("unknown formality %q", opts.Formality)
+	}
+	maxChars := opts.MaxChunkChars
+	if maxChars <= 0 {
+		maxChars = 4000
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultJSONModeRetries
+	}
+
+	segments := splitTranslationSegments(opts.Text, opts.SourceLanguage, maxChars)
+	result := &TranslateResult{SourceLanguage: opts.SourceLanguage}
+	translations := make([]string, len(segments))
+
+	for _, chunk := range chunkTranslationSegments(segments, maxChars) {
+		sources := make([]string, len(chunk))
+		for i, idx := range chunk {
+			sources[i] = segments[idx].text
+		}
+		out, usage, err := translateChunk(ctx, opts, sources, maxRetries)
+		result.Usage = result.Usage.Add(usage)
+		if err != nil {
+			return nil, err
+		}
+		for i, idx := range chunk {
+			translations[idx] = out.translations[i]
+		}
+		if result.SourceLanguage == "" {
+			result.SourceLanguage = out.sourceLanguage
+		}
+	}
+
+	var b strings.Builder
+	for i, seg := range segments {
+		b.WriteString(translations[i])
+		b.WriteString(seg.sep)
+		result.Segments = append(result.Segments, TranslationSegment{Source: seg.text, Translation: translations[i]})
+		result.GlossaryMisses = append(result.GlossaryMisses, glossaryMisses(i, seg.text, translations[i], opts.Glossary)...)
+	}
+	result.Text = b.String()
+	return result, nil
+}
+
+// translationSegment is a piece of the source text followed by the
+// whitespace that separates it from the next piece
+type translationSegment struct {
+	text string
+	sep  string
+}
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// splitTranslationSegments splits text into paragraphs, and paragraphs
+// longer than maxChars into sentences using the rules of language. Leading
+// whitespace is kept as an empty segment so the output has the same layout
+// as the input.
+func splitTranslationSegments(text, language string, maxChars int) []translationSegment {
+	var segments []translationSegment
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	if lead := text[:len(text)-len(trimmed)]; lead != "" {
+		segments = append(segments, translationSegment{sep: lead})
+	}
+	prev := 0
+	breaks := paragraphBreak.FindAllStringIndex(trimmed, -1)
+	breaks = append(breaks, []int{len(trimmed), len(trimmed)})
+	for _, loc := range breaks {
+		paragraph := trimmed[prev:loc[0]]
+		body := strings.TrimRight(paragraph, " \t\r\n")
+		sep := paragraph[len(body):] + trimmed[loc[0]:loc[1]]
+		prev = loc[1]
+		if body == "" {
+			if sep != "" {
+				segments = append(segments, translationSegment{sep: sep})
+			}
+			continue
+		}
+		if len(body) <= maxChars {
+			segments = append(segments, translationSegment{text: body, sep: sep})
+			continue
+		}
+		sentences := packSentences(body, language, maxChars)
+		for i, s := range sentences {
+			seg := translationSegment{text: s, sep: " "}
+			if i == len(sentences)-1 {
+				seg.sep = sep
+			}
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// packSentences packs the sentences of a paragraph into pieces of at most
+// maxChars. A single sentence longer than maxChars is kept whole.
+func packSentences(paragraph, language string, maxChars int) []string {
+	// A MaxLength past the paragraph's end never splits a sentence
+	segmenter := sentence.NewSegmenter(sentence.Options{Language: language, MaxLength: len(paragraph) + 1})
+	var sentences []string
+	for _, s := range append(segmenter.Write(paragraph), segmenter.Flush()...) {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+
+	var pieces []string
+	current := ""
+	for _, s := range sentences {
+		if current != "" && len(current)+1+len(s) > maxChars {
+			pieces = append(pieces, current)
+			current = ""
+		}
+		if current != "" {
+			current += " "
+		}
+		current += s
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+// chunkTranslationSegments groups the indexes of non-empty segments into
+// chunks of at most maxChars of source text
+func chunkTranslationSegments(segments []translationSegment, maxChars int) [][]int {
+	var chunks [][]int
+	var current []int
+	size := 0
+	for i, seg := range segments {
+		if seg.text == "" {
+			continue
+		}
+		if len(current) > 0 && size+len(seg.text) > maxChars {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, i)
+		size += len(seg.text)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+type translatedChunk struct {
+	sourceLanguage string
+	translations   []string
+}
+
+func translateChunk(ctx context.Context, opts TranslateOptions, sources []string, maxRetries int) (*translatedChunk, types.Usage, error) {
+	type numbered struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	}
+	input := make([]numbered, len(sources))
+	for i, s := range sources {
+		input[i] = numbered{ID: i + 1, Text: s}
+	}
+	payload, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return nil, types.Usage{}, err
+	}
+
+	result, err := GenerateObject(ctx, GenerateObjectOptions{
+		Model:       opts.Model,
+		System:      translationSystemPrompt(opts, sources),
+		Prompt:      string(payload),
+		Schema:      &alignedTranslationSchema{count: len(sources)},
+		OutputMode:  ObjectModeObject,
+		JSONMode:    &JSONModeOptions{MaxRetries: maxRetries},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Metadata:    opts.Metadata,
+	})
+	if err != nil {
+		return nil, types.Usage{}, fmt.Errorf("translation failed: %w", err)
+	}
+
+	var out struct {
+		SourceLanguage string     `json:"sourceLanguage"`
+		Translations   []numbered `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(result.Text), &out); err != nil {
+		return nil, result.Usage, fmt.Errorf("failed to decode translation: %w", err)
+	}
+	chunk := &translatedChunk{sourceLanguage: out.SourceLanguage, translations: make([]string, len(sources))}
+	for _, t := range out.Translations {
+		chunk.translations[t.ID-1] = t.Text
+	}
+	return chunk, result.Usage, nil
+}
+
+func translationSystemPrompt(opts TranslateOptions, sources []string) string {
+	from := "the source language"
+	if opts.SourceLanguage != "" {
+		from = opts.SourceLanguage
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are a professional translator. Translate each numbered segment from %s into %s.\n", from, opts.TargetLanguage)
+	b.WriteString("Translate every segment separately and keep its id; do not merge, split, skip or reorder segments.\n")
+	b.WriteString("Preserve Markdown, line breaks, URLs, code and placeholders such as {name} or %s exactly.\n")
+	switch opts.Formality {
+	case FormalityFormal:
+		b.WriteString("Use a formal register and formal forms of address.\n")
+	case FormalityInformal:
+		b.WriteString("Use an informal register and informal forms of address.\n")
+	}
+
+	// Only pin the terms that occur in this chunk, to keep prompts short
+	var terms []string
+	text := strings.ToLower(strings.Join(sources, "\n"))
+	for term := range opts.Glossary {
+		if term != "" && strings.Contains(text, strings.ToLower(term)) {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) > 0 {
+		sort.Strings(terms)
+		b.WriteString("Always translate these terms exactly as given (inflect only where the grammar requires it):\n")
+		for _, term := range terms {
+			fmt.Fprintf(&b, "- %s => %s\n", term, opts.Glossary[term])
+		}
+	}
+	if opts.Instructions != "" {
+		b.WriteString(opts.Instructions + "\n")
+	}
+	b.WriteString(`Respond with a JSON object: {"sourceLanguage": the name of the source language, "translations": [{"id": segment id, "text": translation}, ...]}.`)
+	return b.String()
+}
+
+// alignedTranslationSchema validates that a response translates each of
+// count segments exactly once, so misaligned output is retried
+type alignedTranslationSchema struct {
+	count int
+}
+
+func (s *alignedTranslationSchema) Validator() schema.Validator {
+	return s
+}
+
+func (s *alignedTranslationSchema) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"sourceLanguage": map[string]interface{}{"type": "string"},
+			"translations": map[string]interface{}{
+				"type":     "array",
+				"minItems": s.count,
+				"maxItems": s.count,
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id":   map[string]interface{}{"type": "integer"},
+						"text": map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"id", "text"},
+				},
+			},
+		},
+		"required": []string{"sourceLanguage", "translations"},
+	}
+}
+
+func (s *alignedTranslationSchema) Validate(value interface{}) error {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a JSON object with \"translations\"")
+	}
+	items, ok := obj["translations"].([]interface{})
+	if !ok {
+		return fmt.Errorf("\"translations\" must be an array")
+	}
+	seen := make(map[int]bool, len(items))
+	for _, item := range items {
+		t, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("each translation must be an object with \"id\" and \"text\"")
+		}
+		id, ok := t["id"].(float64)
+		if !ok || id != float64(int(id)) || id < 1 || int(id) > s.count {
+			return fmt.Errorf("translation id %v is not a segment id between 1 and %d", t["id"], s.count)
+		}
+		if _, ok := t["text"].(string); !ok {
+			return fmt.Errorf("translation %d has no \"text\"", int(id))
+		}
+		if seen[int(id)] {
+			return fmt.Errorf("segment %d is translated more than once", int(id))
+		}
+		seen[int(id)] = true
+	}
+	var missing []string
+	for id := 1; id <= s.count; id++ {
+		if !seen[id] {
+			missing = append(missing, fmt.Sprint(id))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing translations for segments %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func glossaryMisses(segment int, source, translation string, glossary map[string]string) []GlossaryMiss {
+	var misses []GlossaryMiss
+	lowerSource, lowerTranslation := strings.ToLower(source), strings.ToLower(translation)
+	for term, pinned := range glossary {
+		if term == "" || pinned == "" || !strings.Contains(lowerSource, strings.ToLower(term)) {
+			continue
+		}
+		if !strings.Contains(lowerTranslation, strings.ToLower(pinned)) {
+			misses = append(misses, GlossaryMiss{Segment: segment, Term: term, Translation: pinned})
+		}
+	}
+	sort.Slice(misses, func(i, j int) bool { return misses[i].Term < misses[j].Term })
+	return misses
+}
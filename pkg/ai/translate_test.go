@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// translatingModel "translates" each numbered segment by upper-casing it
+func translatingModel(calls *[]*provider.GenerateOptions) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			*calls = append(*calls, opts)
+			var segments []struct {
+				ID   int    `json:"id"`
+				Text string `json:"text"`
+			}
+			text := opts.Prompt.Messages[len(opts.Prompt.Messages)-1].Content[0].(types.TextContent).Text
+			if err := json.Unmarshal([]byte(text), &segments); err != nil {
+				return nil, err
+			}
+			for i := range segments {
+				segments[i].Text = strings.ToUpper(segments[i].Text)
+			}
+			out, _ := json.Marshal(map[string]interface{}{"sourceLanguage": "English", "translations": segments})
+			return &types.GenerateResult{
+				Text:         string(out),
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{TotalTokens: int64Ptr(10)},
+			}, nil
+		},
+	}
+}
+
+func TestTranslate_PreservesLayoutAndChunks(t *testing.T) {
+	t.Parallel()
+
+	var calls []*provider.GenerateOptions
+	text := "\n# Release notes\n\nFaster builds. Smaller binaries.\n\n\n- Fixed a crash\n"
+	result, err := Translate(context.Background(), TranslateOptions{
+		Model:          translatingModel(&calls),
+		Text:           text,
+		TargetLanguage: "German",
+		Formality:      FormalityFormal,
+		MaxChunkChars:  20,
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	want := "\n# RELEASE NOTES\n\nFASTER BUILDS. SMALLER BINARIES.\n\n\n- FIXED A CRASH\n"
+	if result.Text != want {
+		t.Errorf("Text = %q, want %q", result.Text, want)
+	}
+	if result.SourceLanguage != "English" {
+		t.Errorf("SourceLanguage = %q", result.SourceLanguage)
+	}
+	// The 31-character paragraph is split into sentences to fit the chunk size
+	var sources []string
+	for _, s := range result.Segments {
+		if s.Source != "" {
+			sources = append(sources, s.Source)
+		}
+	}
+	if strings.Join(sources, "|") != "# Release notes|Faster builds.|Smaller binaries.|- Fixed a crash" {
+		t.Errorf("segments = %q", sources)
+	}
+	if len(calls) < 2 {
+		t.Errorf("expected the document to be translated in several chunks, got %d calls", len(calls))
+	}
+	if got := result.Usage.GetTotalTokens(); got != int64(10*len(calls)) {
+		t.Errorf("usage = %d, want summed over %d calls", got, len(calls))
+	}
+	if !strings.Contains(calls[0].Prompt.System, "German") || !strings.Contains(calls[0].Prompt.System, "formal register") {
+		t.Errorf("system prompt = %q", calls[0].Prompt.System)
+	}
+	if calls[0].ResponseFormat == nil {
+		t.Error("expected native structured output")
+	}
+}
+
+func TestTranslate_Glossary(t *testing.T) {
+	t.Parallel()
+
+	var calls []*provider.GenerateOptions
+	result, err := Translate(context.Background(), TranslateOptions{
+		Model:          translatingModel(&calls),
+		Text:           "Open the workspace.\n\nAsk GoAI.",
+		SourceLanguage: "English",
+		TargetLanguage: "German",
+		Glossary:       map[string]string{"workspace": "Arbeitsbereich", "GoAI": "GoAI", "billing": "Abrechnung"},
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	system := calls[0].Prompt.System
+	if !strings.Contains(system, "workspace => Arbeitsbereich") || strings.Contains(system, "billing") {
+		t.Errorf("glossary should pin only terms in the text: %q", system)
+	}
+	if !strings.Contains(system, "from English into German") {
+		t.Errorf("system prompt = %q", system)
+	}
+	// The fake translation keeps "GOAI" but not "Arbeitsbereich"
+	if len(result.GlossaryMisses) != 1 || result.GlossaryMisses[0].Term != "workspace" || result.GlossaryMisses[0].Segment != 0 {
+		t.Errorf("GlossaryMisses = %+v", result.GlossaryMisses)
+	}
+}
+
+func TestTranslate_RetriesMisalignedSegments(t *testing.T) {
+	t.Parallel()
+
+	responses := []string{
+		`{"sourceLanguage": "French", "translations": [{"id": 1, "text": "Hello"}]}`,
+		`{"sourceLanguage": "French", "translations": [{"id": 2, "text": "World"}, {"id": 1, "text": "Hello"}]}`,
+	}
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			text := responses[0]
+			responses = responses[1:]
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	result, err := Translate(context.Background(), TranslateOptions{
+		Model:          model,
+		Text:           "Bonjour\n\nMonde",
+		TargetLanguage: "English",
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if result.Text != "Hello\n\nWorld" {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if len(model.GenerateCalls) != 2 {
+		t.Errorf("expected one retry, got %d calls", len(model.GenerateCalls))
+	}
+}
+
+func TestTranslate_Validation(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	if _, err := Translate(context.Background(), TranslateOptions{Model: model, Text: "hi"}); err == nil {
+		t.Error("expected an error without a target language")
+	}
+	if _, err := Translate(context.Background(), TranslateOptions{Model: model, Text: "hi", TargetLanguage: "German", Formality: "polite"}); err == nil {
+		t.Error("expected an error for an unknown formality")
+	}
+}
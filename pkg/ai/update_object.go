@@ -0,0 +1,455 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// ObjectPatchOperation is a single JSON Patch (RFC 6902) operation.
+// UpdateObject supports the "add", "replace" and "remove" operations.
+type ObjectPatchOperation struct {
+	// Op is the operation type: "add", "replace" or "remove"
+	Op string `json:"op"`
+
+	// Path is a JSON Pointer (RFC 6901) to the target location, e.g. "/employees/0/title"
+	Path string `json:"path"`
+
+	// Value is the new value for "add" and "replace" operations
+	Value interface{} `json:"value,omitempty"`
+}
+
+// UpdateObjectOptions contains options for diff-aware object regeneration
+type UpdateObjectOptions struct {
+	// Model to use for generation
+	Model provider.LanguageModel
+
+	// Object is the existing object to update. Structs are converted to their
+	// JSON representation before being sent to the model.
+	Object interface{}
+
+	// Schema the object conforms to. The updated object is validated against
+	// it, and only failures at or under changed paths reject the update.
+	Schema schema.Schema
+
+	// Instruction describes the change to make, e.g. "promote Alice to CTO"
+	Instruction string
+
+	// System is an optional system prompt prepended to the built-in instructions
+	System string
+
+	// Generation parameters
+	Temperature *float64
+	MaxTokens   *int
+	Seed        *int
+
+	// JSONMode configures the prompt-based fallback used for models without
+	// structured output support, as in GenerateObject
+	JSONMode *JSONModeOptions
+
+	// Telemetry configuration for observability
+	ExperimentalTelemetry *TelemetrySettings
+}
+
+// UpdateObjectResult contains the result of an object update
+type UpdateObjectResult struct {
+	// Object is the updated object (the original with the patch applied)
+	Object interface{}
+
+	// Patch holds the operations the model produced
+	Patch []ObjectPatchOperation
+
+	// ChangedPaths lists the JSON Pointers that were modified, in patch order
+	ChangedPaths []string
+
+	// Raw JSON text returned by the model
+	Text string
+
+	// Finish reason
+	FinishReason types.FinishReason
+
+	// Token usage information
+	Usage types.Usage
+
+	// Warnings from the provider
+	Warnings []types.Warning
+}
+
+// updateObjectPatchSchema is the response schema the model must follow.
+var updateObjectPatchSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"operations": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"op":    map[string]interface{}{"type": "string", "enum": []interface{}{"add", "replace", "remove"}},
+					"path":  map[string]interface{}{"type": "string"},
+					"value": map[string]interface{}{},
+				},
+				"required": []interface{}{"op", "path"},
+			},
+		},
+	},
+	"required": []interface{}{"operations"},
+}
+
+const updateObjectInstructions = `You update an existing JSON object. Respond only with a JSON object of the form {"operations": [...]} where each operation is a JSON Patch (RFC 6902) operation with "op" ("add", "replace" or "remove"), "path" (a JSON Pointer) and, for add/replace, "value". Change only what the instruction requires and leave every other field untouched.`
+
+// UpdateObject applies an instruction to an existing object by asking the model
+// for a minimal JSON Patch instead of regenerating the whole structure, which
+// keeps updates to large objects cheap in tokens. The updated object is
+// validated as a whole, but only failures at or under changed paths are
+// reported, so problems the object already had do not reject the update.
+// Models without structured output support fall back to prompt-based JSON
+// mode, like GenerateObject.
+//
+// Example:
+//
+//	result, err := ai.UpdateObject(ctx, ai.UpdateObjectOptions{
+//		Model:       model,
+//		Object:      company,
+//		Schema:      companySchema,
+//		Instruction: "Rename the engineering department to Platform",
+//	})
+func UpdateObject(ctx context.Context, opts UpdateObjectOptions) (*UpdateObjectResult, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if opts.Schema == nil {
+		return nil, fmt.Errorf("schema is required")
+	}
+	if opts.Instruction == "" {
+		return nil, fmt.Errorf("instruction is required")
+	}
+	original, err := normalizeJSONValue(opts.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode object: %w", err)
+	}
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode object: %w", err)
+	}
+	schemaJSON, err := json.Marshal(opts.Schema.Validator().JSONSchema())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	system := updateObjectInstructions
+	if opts.System != "" {
+		system = opts.System + "\n\n" + system
+	}
+	prompt := fmt.Sprintf("Schema:\n%s\n\nCurrent object:\n%s\n\nInstruction:\n%s", schemaJSON, originalJSON, opts.Instruction)
+
+	genResult, patchJSON, err := generateObjectPatch(ctx, opts, prompt, system)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch struct {
+		Operations []ObjectPatchOperation `json:"operations"`
+	}
+	if err := json.Unmarshal(patchJSON, &patch); err != nil {
+		return nil, fmt.Errorf("failed to parse patch output: %w", err)
+	}
+
+	updated, resolved, err := applyObjectPatch(original, patch.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]string, 0, len(patch.Operations))
+	for _, op := range patch.Operations {
+		changed = append(changed, op.Path)
+	}
+	if err := validateChangedPaths(opts.Schema.Validator(), updated, resolved); err != nil {
+		return nil, fmt.Errorf("output validation failed: %w", err)
+	}
+
+	return &UpdateObjectResult{
+		Object:       updated,
+		Patch:        patch.Operations,
+		ChangedPaths: changed,
+		Text:         genResult.Text,
+		FinishReason: genResult.FinishReason,
+		Usage:        genResult.Usage,
+		Warnings:     genResult.Warnings,
+	}, nil
+}
+
+// generateObjectPatch asks the model for patch operations and returns the
+// generation result with the JSON to decode them from. Models without
+// structured output support go through prompt-based JSON mode.
+func generateObjectPatch(ctx context.Context, opts UpdateObjectOptions, prompt, system string) (*GenerateObjectResult, []byte, error) {
+	objOpts := GenerateObjectOptions{
+		Model:                 opts.Model,
+		Prompt:                prompt,
+		System:                system,
+		Schema:                schema.NewSimpleJSONSchema(updateObjectPatchSchema),
+		OutputMode:            ObjectModeObject,
+		JSONMode:              opts.JSONMode,
+		Temperature:           opts.Temperature,
+		MaxTokens:             opts.MaxTokens,
+		Seed:                  opts.Seed,
+		ExperimentalTelemetry: opts.ExperimentalTelemetry,
+	}
+	if useJSONMode(objOpts) {
+		result, err := generateJSONMode(ctx, objOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+		patchJSON, err := json.Marshal(result.Object)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode patch output: %w", err)
+		}
+		return result, patchJSON, nil
+	}
+
+	genResult, err := opts.Model.DoGenerate(ctx, &provider.GenerateOptions{
+		Prompt:      buildPrompt(prompt, nil, system),
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Seed:        opts.Seed,
+		ResponseFormat: &provider.ResponseFormat{
+			Type:        "json_schema",
+			Schema:      updateObjectPatchSchema,
+			Name:        "object_patch",
+			Description: "JSON Patch operations to apply to the current object",
+		},
+		Telemetry: opts.ExperimentalTelemetry,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("generation failed: %w", err)
+	}
+	return &GenerateObjectResult{
+		Text:         genResult.Text,
+		FinishReason: genResult.FinishReason,
+		Usage:        genResult.Usage,
+		Warnings:     genResult.Warnings,
+	}, []byte(genResult.Text), nil
+}
+
+// ApplyObjectPatch applies JSON Patch operations to a JSON value and returns
+// the patched copy. The input is not modified. Supported operations are
+// "add", "replace" and "remove".
+func ApplyObjectPatch(obj interface{}, ops []ObjectPatchOperation) (interface{}, error) {
+	doc, _, err := applyObjectPatch(obj, ops)
+	return doc, err
+}
+
+// applyObjectPatch applies ops like ApplyObjectPatch and also returns the
+// operations with appends ("-") resolved to the index they were added at
+func applyObjectPatch(obj interface{}, ops []ObjectPatchOperation) (interface{}, []ObjectPatchOperation, error) {
+	doc, err := normalizeJSONValue(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to copy object: %w", err)
+	}
+
+	resolved := make([]ObjectPatchOperation, len(ops))
+	for i, op := range ops {
+		resolved[i] = op
+		tokens, err := schema.ParsePointer(op.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("patch operation %d: %w", i, err)
+		}
+		switch op.Op {
+		case "add", "replace", "remove":
+		default:
+			return nil, nil, fmt.Errorf("patch operation %d: unsupported op %q", i, op.Op)
+		}
+		if len(tokens) == 0 {
+			if op.Op == "remove" {
+				return nil, nil, fmt.Errorf("patch operation %d: cannot remove document root", i)
+			}
+			doc = op.Value
+			continue
+		}
+		if op.Op == "add" && tokens[len(tokens)-1] == "-" {
+			if arr, ok := valueAt(doc, tokens[:len(tokens)-1]).([]interface{}); ok {
+				resolved[i].Path = parentPointer(op.Path) + "/" + strconv.Itoa(len(arr))
+			}
+		}
+		doc, err = patchAt(doc, tokens, op)
+		if err != nil {
+			return nil, nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, resolved, nil
+}
+
+// valueAt returns the value at tokens, or nil if there is none
+func valueAt(node interface{}, tokens []string) interface{} {
+	for _, tok := range tokens {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			node = container[tok]
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil || idx < 0 || idx >= len(container) {
+				return nil
+			}
+			node = container[idx]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// patchAt applies a single operation at the location given by tokens and
+// returns the (possibly reallocated) container.
+func patchAt(node interface{}, tokens []string, op ObjectPatchOperation) (interface{}, error) {
+	key := tokens[0]
+	last := len(tokens) == 1
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if last {
+			_, exists := container[key]
+			switch op.Op {
+			case "add":
+				container[key] = op.Value
+			case "replace":
+				if !exists {
+					return nil, fmt.Errorf("path does not exist")
+				}
+				container[key] = op.Value
+			case "remove":
+				if !exists {
+					return nil, fmt.Errorf("path does not exist")
+				}
+				delete(container, key)
+			}
+			return container, nil
+		}
+		child, ok := container[key]
+		if !ok {
+			return nil, fmt.Errorf("path does not exist")
+		}
+		updated, err := patchAt(child, tokens[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		container[key] = updated
+		return container, nil
+
+	case []interface{}:
+		if last && op.Op == "add" && key == "-" {
+			return append(container, op.Value), nil
+		}
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		if last {
+			switch op.Op {
+			case "add":
+				if idx > len(container) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				container = append(container, nil)
+				copy(container[idx+1:], container[idx:])
+				container[idx] = op.Value
+			case "replace":
+				if idx >= len(container) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				container[idx] = op.Value
+			case "remove":
+				if idx >= len(container) {
+					return nil, fmt.Errorf("array index %d out of range", idx)
+				}
+				container = append(container[:idx], container[idx+1:]...)
+			}
+			return container, nil
+		}
+		if idx >= len(container) {
+			return nil, fmt.Errorf("array index %d out of range", idx)
+		}
+		updated, err := patchAt(container[idx], tokens[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		container[idx] = updated
+		return container, nil
+
+	default:
+		return nil, fmt.Errorf("cannot traverse into %T", node)
+	}
+}
+
+// normalizeJSONValue deep-copies a value through its JSON representation so
+// that structs become maps and the caller's data is never mutated.
+func normalizeJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// validateChangedPaths validates the patched object and returns the
+// failures located at or under a patched path. Problems elsewhere predate
+// the update and are left alone. A removed property is checked against its
+// parent's required list.
+func validateChangedPaths(v schema.Validator, updated interface{}, ops []ObjectPatchOperation) error {
+	err := v.Validate(updated)
+	var failures schema.ValidationErrors
+	if !errors.As(err, &failures) {
+		return err
+	}
+
+	var relevant schema.ValidationErrors
+	for _, f := range failures {
+		for _, op := range ops {
+			removedRequired := op.Op == "remove" && f.Keyword == "required" && f.Pointer == parentPointer(op.Path)
+			if removedRequired || pointerWithin(f.Pointer, op.Path) {
+				relevant = append(relevant, f)
+				break
+			}
+		}
+	}
+	if len(relevant) == 0 {
+		return nil
+	}
+	return relevant
+}
+
+// pointerWithin reports whether pointer is path or lies under it
+func pointerWithin(pointer, path string) bool {
+	tokens, err := schema.ParsePointer(pointer)
+	if err != nil {
+		return false
+	}
+	prefix, err := schema.ParsePointer(path)
+	if err != nil || len(prefix) > len(tokens) {
+		return false
+	}
+	for i, tok := range prefix {
+		if tok != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parentPointer returns the pointer of the value containing pointer
+func parentPointer(pointer string) string {
+	if i := strings.LastIndex(pointer, "/"); i >= 0 {
+		return pointer[:i]
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+var updateObjectTestSchema = schema.NewSimpleJSONSchema(map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"name": map[string]interface{}{"type": "string"},
+		"employees": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":  map[string]interface{}{"type": "string"},
+					"title": map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"name"},
+			},
+		},
+	},
+	"required": []interface{}{"name"},
+})
+
+func patchModel(text string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+}
+
+func TestUpdateObject_AppliesPatch(t *testing.T) {
+	t.Parallel()
+
+	original := map[string]interface{}{
+		"name": "Acme",
+		"employees": []interface{}{
+			map[string]interface{}{"name": "Alice", "title": "Engineer"},
+		},
+	}
+	model := patchModel(`{"operations":[{"op":"replace","path":"/employees/0/title","value":"CTO"},{"op":"add","path":"/employees/-","value":{"name":"Bob"}}]}`)
+
+	result, err := UpdateObject(context.Background(), UpdateObjectOptions{
+		Model:       model,
+		Object:      original,
+		Schema:      updateObjectTestSchema,
+		Instruction: "Promote Alice to CTO and hire Bob",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	employees := result.Object.(map[string]interface{})["employees"].([]interface{})
+	if len(employees) != 2 {
+		t.Fatalf("expected 2 employees, got %d", len(employees))
+	}
+	if employees[0].(map[string]interface{})["title"] != "CTO" {
+		t.Errorf("expected title CTO, got %v", employees[0])
+	}
+	if len(result.ChangedPaths) != 2 || result.ChangedPaths[0] != "/employees/0/title" {
+		t.Errorf("unexpected changed paths: %v", result.ChangedPaths)
+	}
+
+	// The original object must not be mutated.
+	if original["employees"].([]interface{})[0].(map[string]interface{})["title"] != "Engineer" {
+		t.Error("original object was mutated")
+	}
+
+	// The prompt should include the current object, not ask for a full regeneration.
+	prompt := model.GenerateCalls[0].Prompt
+	if len(prompt.Messages) == 0 {
+		t.Fatal("expected prompt messages")
+	}
+	text := prompt.Messages[0].Content[0].(types.TextContent).Text
+	if !strings.Contains(text, `"Alice"`) {
+		t.Errorf("expected current object in prompt, got %q", text)
+	}
+}
+
+func TestUpdateObject_ValidatesChangedPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"wrong type", `{"operations":[{"op":"replace","path":"/name","value":42}]}`, "expected string"},
+		{"missing required", `{"operations":[{"op":"add","path":"/employees/-","value":{"title":"Intern"}}]}`, "missing required property"},
+		{"remove required", `{"operations":[{"op":"remove","path":"/name"}]}`, `missing required property "name"`},
+		{"missing path", `{"operations":[{"op":"replace","path":"/missing/field","value":1}]}`, "path does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UpdateObject(context.Background(), UpdateObjectOptions{
+				Model:       patchModel(tt.patch),
+				Object:      map[string]interface{}{"name": "Acme", "employees": []interface{}{}},
+				Schema:      updateObjectTestSchema,
+				Instruction: "change something",
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUpdateObject_IgnoresUnchangedPaths(t *testing.T) {
+	t.Parallel()
+
+	// The existing employee is missing its required name; the patch does not
+	// touch it, so the update is accepted
+	result, err := UpdateObject(context.Background(), UpdateObjectOptions{
+		Model:       patchModel(`{"operations":[{"op":"replace","path":"/name","value":"Acme Inc"}]}`),
+		Object:      map[string]interface{}{"name": "Acme", "employees": []interface{}{map[string]interface{}{"title": "CTO"}}},
+		Schema:      updateObjectTestSchema,
+		Instruction: "rename the company",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Object.(map[string]interface{})["name"] != "Acme Inc" {
+		t.Errorf("unexpected object: %v", result.Object)
+	}
+}
+
+func TestUpdateObject_AppendIgnoresExistingElements(t *testing.T) {
+	t.Parallel()
+
+	// The appended employee is valid; the existing one at index 0 is not, but
+	// "-" resolves to index 1 so its failure is not reported
+	result, err := UpdateObject(context.Background(), UpdateObjectOptions{
+		Model:       patchModel(`{"operations":[{"op":"add","path":"/employees/-","value":{"name":"Bob"}}]}`),
+		Object:      map[string]interface{}{"name": "Acme", "employees": []interface{}{map[string]interface{}{"title": "CTO"}}},
+		Schema:      updateObjectTestSchema,
+		Instruction: "hire Bob",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if employees := result.Object.(map[string]interface{})["employees"].([]interface{}); len(employees) != 2 {
+		t.Errorf("expected 2 employees, got %v", employees)
+	}
+}
+
+func TestUpdateObject_JSONModeFallback(t *testing.T) {
+	t.Parallel()
+
+	var gotFormat *provider.ResponseFormat
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			gotFormat = opts.ResponseFormat
+			return &types.GenerateResult{
+				Text:         "Here is the patch:\n```json\n{\"operations\":[{\"op\":\"replace\",\"path\":\"/name\",\"value\":\"Acme Inc\"}]}\n```",
+				FinishReason: types.FinishReasonStop,
+			}, nil
+		},
+	}
+
+	result, err := UpdateObject(context.Background(), UpdateObjectOptions{
+		Model:       model,
+		Object:      map[string]interface{}{"name": "Acme"},
+		Schema:      updateObjectTestSchema,
+		Instruction: "rename the company",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFormat != nil && gotFormat.Type == "json_schema" {
+		t.Errorf("expected prompt-based JSON mode, got response format %+v", gotFormat)
+	}
+	if result.Object.(map[string]interface{})["name"] != "Acme Inc" {
+		t.Errorf("unexpected object: %v", result.Object)
+	}
+}
+
+func TestUpdateObject_RequiresInstruction(t *testing.T) {
+	t.Parallel()
+
+	_, err := UpdateObject(context.Background(), UpdateObjectOptions{
+		Model:  patchModel(`{}`),
+		Object: map[string]interface{}{},
+		Schema: updateObjectTestSchema,
+	})
+	if err == nil {
+		t.Fatal("expected error for missing instruction")
+	}
+}
+
+func TestApplyObjectPatch_StructInput(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Tags []string `json:"tags"`
+	}
+
+	out, err := ApplyObjectPatch(item{Tags: []string{"a", "c"}}, []ObjectPatchOperation{
+		{Op: "add", Path: "/tags/1", Value: "b"},
+		{Op: "remove", Path: "/tags/0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tags := out.(map[string]interface{})["tags"].([]interface{})
+	if len(tags) != 2 || tags[0] != "b" || tags[1] != "c" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ValidateFunc checks a generated result against post-conditions such as
+// format, length or policy rules. Returning a non-nil error rejects the
+// result; the error message is fed back to the model as guidance.
+type ValidateFunc func(ctx context.Context, result *GenerateTextResult) error
+
+// ValidationOptions configures regeneration of rejected results
+type ValidationOptions struct {
+	// MaxRetries caps the number of regenerations after a rejection
+	// Default: 2
+	MaxRetries int
+
+	// Feedback builds the user message sent after a rejection
+	// Default: DefaultValidationFeedback
+	Feedback func(reason string) string
+}
+
+// defaultValidationRetries is the default regeneration cap
+const defaultValidationRetries = 2
+
+// DefaultValidationFeedback formats a rejection reason as a retry instruction
+func DefaultValidationFeedback(reason string) string {
+	return fmt.Sprintf("Your previous response was rejected: %s\nPlease respond again, fixing this problem.", reason)
+}
+
+// ValidationError is returned when every attempt was rejected by Validate
+type ValidationError struct {
+	// Reasons holds the rejection reason of each attempt in order
+	Reasons []string
+
+	// Result is the last rejected result
+	Result *GenerateTextResult
+
+	// Cause is the last rejection error
+	Cause error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("response rejected after %d attempts: %s", len(e.Reasons), e.Reasons[len(e.Reasons)-1])
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// generateValidated runs GenerateText until opts.Validate accepts the result
+// or the retry budget is spent. Each rejection is appended to the
+// conversation as the rejected attempt's response messages plus a feedback
+// message. Usage is summed across attempts.
+func generateValidated(ctx context.Context, opts GenerateTextOptions) (*GenerateTextResult, error) {
+	validate := opts.Validate
+	maxRetries := defaultValidationRetries
+	feedback := DefaultValidationFeedback
+	if opts.Validation != nil {
+		if opts.Validation.MaxRetries != 0 {
+			maxRetries = max(opts.Validation.MaxRetries, 0)
+		}
+		if opts.Validation.Feedback != nil {
+			feedback = opts.Validation.Feedback
+		}
+	}
+
+	attemptOpts := opts
+	attemptOpts.Validate = nil
+	prompt := buildPrompt(opts.Prompt, opts.Messages, opts.System)
+	attemptOpts.Prompt = ""
+	attemptOpts.Messages = append([]types.Message(nil), prompt.Messages...)
+
+	var usage types.Usage
+	var reasons []string
+	for attempt := 0; ; attempt++ {
+		result, err := GenerateText(ctx, attemptOpts)
+		if err != nil {
+			return nil, err
+		}
+		usage = usage.Add(result.Usage)
+		result.Usage = usage
+		result.ValidationAttempts = attempt + 1
+
+		verr := validate(ctx, result)
+		if verr == nil {
+			return result, nil
+		}
+		reasons = append(reasons, verr.Error())
+		if attempt >= maxRetries {
+			return nil, &ValidationError{Reasons: reasons, Result: result, Cause: verr}
+		}
+
+		// The rejected attempt's full exchange, including tool calls and
+		// results from every step, stays in the conversation
+		attemptOpts.Messages = append(attemptOpts.Messages, result.ResponseMessages...)
+		attemptOpts.Messages = append(attemptOpts.Messages, types.Message{
+			Role:    types.RoleUser,
+			Content: []types.ContentPart{types.TextContent{Text: strings.TrimSpace(feedback(verr.Error()))}},
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestGenerateText_ValidateRegenerates(t *testing.T) {
+	t.Parallel()
+
+	replies := []string{"This answer is far too long to fit the limit", "Short"}
+	var prompts [][]types.Message
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			prompts = append(prompts, opts.Prompt.Messages)
+			tokens := int64(5)
+			return &types.GenerateResult{
+				Text:         replies[len(prompts)-1],
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{OutputTokens: &tokens},
+			}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:  model,
+		Prompt: "Describe Go in one word",
+		Validate: func(ctx context.Context, r *GenerateTextResult) error {
+			if len(r.Text) > 10 {
+				return fmt.Errorf("response must be at most 10 characters")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Text != "Short" || result.ValidationAttempts != 2 {
+		t.Errorf("unexpected result %q after %d attempts", result.Text, result.ValidationAttempts)
+	}
+	if result.Usage.GetOutputTokens() != 10 {
+		t.Errorf("expected usage summed across attempts, got %d", result.Usage.GetOutputTokens())
+	}
+
+	retry := prompts[1]
+	if len(retry) != 3 || retry[1].Role != types.RoleAssistant || retry[2].Role != types.RoleUser {
+		t.Fatalf("unexpected retry prompt: %+v", retry)
+	}
+	feedback := retry[2].Content[0].(types.TextContent).Text
+	if !strings.Contains(feedback, "at most 10 characters") {
+		t.Errorf("expected rejection reason in feedback, got %q", feedback)
+	}
+}
+
+func TestGenerateText_ValidateExhausted(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	_, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:      model,
+		Prompt:     "hi",
+		Validation: &ValidationOptions{MaxRetries: 1},
+		Validate: func(ctx context.Context, r *GenerateTextResult) error {
+			return errors.New("never good enough")
+		},
+	})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(verr.Reasons) != 2 || len(model.GenerateCalls) != 2 {
+		t.Errorf("expected 2 attempts, got %d reasons and %d calls", len(verr.Reasons), len(model.GenerateCalls))
+	}
+	if verr.Result == nil || verr.Result.Text != "mock response" {
+		t.Errorf("expected last rejected result on error")
+	}
+}
+
+func TestGenerateText_ValidateKeepsRejectedToolSteps(t *testing.T) {
+	t.Parallel()
+
+	tools := []types.Tool{{
+		Name:       "lookup",
+		Parameters: map[string]interface{}{"type": "object"},
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			return "42", nil
+		},
+	}}
+	var prompts [][]types.Message
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			prompts = append(prompts, opts.Prompt.Messages)
+			if len(prompts) == 1 {
+				return &types.GenerateResult{
+					FinishReason: types.FinishReasonToolCalls,
+					ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: "lookup", Arguments: map[string]interface{}{}}},
+				}, nil
+			}
+			text := "The answer is 42."
+			if len(prompts) == 2 {
+				text = "It is 42"
+			}
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+
+	result, err := GenerateText(context.Background(), GenerateTextOptions{
+		Model:    model,
+		Prompt:   "What is the answer?",
+		Tools:    tools,
+		StopWhen: []StopCondition{StepCountIs(3)},
+		Validate: func(ctx context.Context, r *GenerateTextResult) error {
+			if !strings.HasSuffix(r.Text, ".") {
+				return errors.New("end with a full stop")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ValidationAttempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", result.ValidationAttempts)
+	}
+
+	// user, assistant tool call, tool result, rejected reply, feedback
+	retry := prompts[2]
+	if len(retry) != 5 {
+		t.Fatalf("expected 5 retry messages, got %d: %+v", len(retry), retry)
+	}
+	if len(retry[1].ToolCalls) != 1 || retry[2].Role != types.RoleTool {
+		t.Errorf("expected the rejected attempt's tool step in the retry prompt, got %+v", retry[1:3])
+	}
+	if text := retry[3].Content[0].(types.TextContent).Text; retry[3].Role != types.RoleAssistant || text != "It is 42" {
+		t.Errorf("expected the rejected reply, got %+v", retry[3])
+	}
+	if retry[4].Role != types.RoleUser {
+		t.Errorf("expected feedback last, got %+v", retry[4])
+	}
+}
@@ -0,0 +1,354 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// ErrImageInputUnsupported is returned by the vision helpers when the model
+// does not accept image inputs
+var ErrImageInputUnsupported = errors.New("model does not support image input")
+
+// DescriptionStyle selects the kind of description DescribeImage produces
+type DescriptionStyle string
+
+const (
+	// DescriptionBrief is one or two sentences (the default)
+	DescriptionBrief DescriptionStyle = "brief"
+
+	// DescriptionDetailed covers subjects, setting, text and notable details
+	DescriptionDetailed DescriptionStyle = "detailed"
+
+	// DescriptionAltText is concise alternative text for accessibility
+	DescriptionAltText DescriptionStyle = "alt-text"
+)
+
+var descriptionPrompts = map[DescriptionStyle]string{
+	DescriptionBrief:    "Describe this image in one or two sentences.",
+	DescriptionDetailed: "Describe this image in detail: the main subjects, the setting, any visible text, and notable details.",
+	DescriptionAltText:  "Write alt text for this image for a screen reader user. Be concise (under 125 characters) and do not start with \"Image of\".",
+}
+
+// DescribeImageOptions configures DescribeImage
+type DescribeImageOptions struct {
+	// Model must accept image input
+	Model provider.LanguageModel
+
+	// Image to describe, as bytes or a URL
+	Image types.ImageContent
+
+	// Style of description (default: DescriptionBrief)
+	Style DescriptionStyle
+
+	// Prompt replaces the style's instruction
+	Prompt string
+
+	// Language of the description, e.g. "French" (default: English)
+	Language string
+
+	Temperature *float64
+	MaxTokens   *int
+	Metadata    map[string]string
+}
+
+// DescribeImageResult is the result of DescribeImage
+type DescribeImageResult struct {
+	Description string
+	Usage       types.Usage
+}
+
+// DescribeImage asks a vision model to describe an image
+func DescribeImage(ctx context.Context, opts DescribeImageOptions) (*DescribeImageResult, error) {
+	if err := checkVisionInput(opts.Model, opts.Image); err != nil {
+		return nil, err
+	}
+	prompt := opts.Prompt
+	if prompt == "" {
+		style := opts.Style
+		if style == "" {
+			style = DescriptionBrief
+		}
+		var ok bool
+		if prompt, ok = descriptionPrompts[style]; !ok {
+			return nil, fmt.Errorf("unknown description style %q", style)
+		}
+	}
+	if opts.Language != "" {
+		prompt += " Respond in " + opts.Language + "."
+	}
+
+	result, err := GenerateText(ctx, GenerateTextOptions{
+		Model:       opts.Model,
+		Messages:    []types.Message{imageMessage(prompt, opts.Image)},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+		Metadata:    opts.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DescribeImageResult{Description: strings.TrimSpace(result.Text), Usage: result.Usage}, nil
+}
+
+// OCRField is a named value to extract from an image
+type OCRField struct {
+	// Name is the key in OCRResult.Fields
+	Name string
+
+	// Description tells the model what the field holds, e.g. "invoice total
+	// including tax"
+	Description string
+
+	// Type is a JSON Schema type (default: "string")
+	Type string
+}
+
+// OCROptions configures OCR
+type OCROptions struct {
+	// Model must accept image input
+	Model provider.LanguageModel
+
+	// Image to read, as bytes or a URL
+	Image types.ImageContent
+
+	// Fields to extract. Without fields only the text is transcribed.
+	Fields []OCRField
+
+	Temperature *float64
+	MaxTokens   *int
+	Metadata    map[string]string
+}
+
+// OCRResult is the result of OCR
+type OCRResult struct {
+	// Text is all text in the image, in reading order
+	Text string
+
+	// Fields holds the extracted field values; fields that are not present
+	// in the image are nil
+	Fields map[string]interface{}
+
+	Usage types.Usage
+}
+
+const ocrPrompt = "Transcribe all text in this image exactly as written, in reading order. " +
+	"Preserve line breaks. Do not add commentary."
+
+// OCR extracts the text from an image and, when fields are given, the
+// values of those fields as structured output via GenerateObject
+func OCR(ctx context.Context, opts OCROptions) (*OCRResult, error) {
+	if err := checkVisionInput(opts.Model, opts.Image); err != nil {
+		return nil, err
+	}
+	if len(opts.Fields) == 0 {
+		result, err := GenerateText(ctx, GenerateTextOptions{
+			Model:       opts.Model,
+			Messages:    []types.Message{imageMessage(ocrPrompt, opts.Image)},
+			Temperature: opts.Temperature,
+			MaxTokens:   opts.MaxTokens,
+			Metadata:    opts.Metadata,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &OCRResult{Text: strings.TrimSpace(result.Text), Usage: result.Usage}, nil
+	}
+
+	properties := make(map[string]interface{}, len(opts.Fields))
+	names := make([]string, 0, len(opts.Fields))
+	for _, f := range opts.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("OCR field name is required")
+		}
+		typ := f.Type
+		if typ == "" {
+			typ = "string"
+		}
+		prop := map[string]interface{}{"type": []string{typ, "null"}}
+		if f.Description != "" {
+			prop["description"] = f.Description
+		}
+		properties[f.Name] = prop
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+
+	var out struct {
+		Text   string                 `json:"text"`
+		Fields map[string]interface{} `json:"fields"`
+	}
+	usage, err := generateVisionObject(ctx, opts.Model, opts.Image,
+		ocrPrompt+" Then extract the requested fields; use null for fields that do not appear in the image.",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{"type": "string", "description": "All text in the image"},
+				"fields": map[string]interface{}{
+					"type":       "object",
+					"properties": properties,
+					"required":   names,
+				},
+			},
+			"required": []string{"text", "fields"},
+		}, opts.Temperature, opts.MaxTokens, opts.Metadata, &out)
+	if err != nil {
+		return nil, err
+	}
+	if out.Fields == nil {
+		out.Fields = make(map[string]interface{})
+	}
+	return &OCRResult{Text: out.Text, Fields: out.Fields, Usage: usage}, nil
+}
+
+// ChartData is the data read from a chart image
+type ChartData struct {
+	Title string `json:"title"`
+
+	// Type is the chart type, e.g. "bar", "line", "pie" or "scatter"
+	Type string `json:"type"`
+
+	XLabel string        `json:"xLabel"`
+	YLabel string        `json:"yLabel"`
+	Series []ChartSeries `json:"series"`
+}
+
+// ChartSeries is one data series of a chart
+type ChartSeries struct {
+	Name   string       `json:"name"`
+	Points []ChartPoint `json:"points"`
+}
+
+// ChartPoint is a data point; X is a category label or a formatted number
+type ChartPoint struct {
+	X string  `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ChartDataOptions configures ExtractChartData
+type ChartDataOptions struct {
+	// Model must accept image input
+	Model provider.LanguageModel
+
+	// Image of the chart, as bytes or a URL
+	Image types.ImageContent
+
+	// Instructions are appended to the extraction prompt, e.g. "values are
+	// in millions"
+	Instructions string
+
+	Temperature *float64
+	MaxTokens   *int
+	Metadata    map[string]string
+}
+
+// ChartDataResult is the result of ExtractChartData
+type ChartDataResult struct {
+	Chart ChartData
+	Usage types.Usage
+}
+
+var chartDataSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"title":  map[string]interface{}{"type": "string"},
+		"type":   map[string]interface{}{"type": "string", "description": "Chart type, e.g. bar, line, pie, scatter"},
+		"xLabel": map[string]interface{}{"type": "string"},
+		"yLabel": map[string]interface{}{"type": "string"},
+		"series": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{"type": "string"},
+					"points": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"x": map[string]interface{}{"type": "string"},
+								"y": map[string]interface{}{"type": "number"},
+							},
+							"required": []string{"x", "y"},
+						},
+					},
+				},
+				"required": []string{"name", "points"},
+			},
+		},
+	},
+	"required": []string{"title", "type", "xLabel", "yLabel", "series"},
+}
+
+// ExtractChartData reads the data series from a chart image
+func ExtractChartData(ctx context.Context, opts ChartDataOptions) (*ChartDataResult, error) {
+	if err := checkVisionInput(opts.Model, opts.Image); err != nil {
+		return nil, err
+	}
+	prompt := "Extract the data shown in this chart. Read each series' values as precisely as the axes allow; " +
+		"use empty strings for missing titles or labels."
+	if opts.Instructions != "" {
+		prompt += " " + opts.Instructions
+	}
+	result := &ChartDataResult{}
+	usage, err := generateVisionObject(ctx, opts.Model, opts.Image, prompt, chartDataSchema,
+		opts.Temperature, opts.MaxTokens, opts.Metadata, &result.Chart)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage = usage
+	return result, nil
+}
+
+// checkVisionInput validates the model's capabilities and the image
+func checkVisionInput(model provider.LanguageModel, image types.ImageContent) error {
+	if model == nil {
+		return fmt.Errorf("model is required")
+	}
+	if !model.SupportsImageInput() {
+		return fmt.Errorf("%w: %s/%s", ErrImageInputUnsupported, model.Provider(), model.ModelID())
+	}
+	if len(image.Image) == 0 && image.URL == "" {
+		return fmt.Errorf("image data or URL is required")
+	}
+	return nil
+}
+
+func imageMessage(prompt string, image types.ImageContent) types.Message {
+	return types.Message{
+		Role:    types.RoleUser,
+		Content: []types.ContentPart{image, types.TextContent{Text: prompt}},
+	}
+}
+
+// generateVisionObject runs GenerateObject on an image prompt and decodes
+// the object into target
+func generateVisionObject(ctx context.Context, model provider.LanguageModel, image types.ImageContent, prompt string, jsonSchema map[string]interface{}, temperature *float64, maxTokens *int, metadata map[string]string, target interface{}) (types.Usage, error) {
+	result, err := GenerateObject(ctx, GenerateObjectOptions{
+		Model:       model,
+		Messages:    []types.Message{imageMessage(prompt, image)},
+		Schema:      schema.NewSimpleJSONSchema(jsonSchema),
+		OutputMode:  ObjectModeObject,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return types.Usage{}, err
+	}
+	data, err := json.Marshal(result.Object)
+	if err != nil {
+		return result.Usage, err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return result.Usage, fmt.Errorf("failed to decode extracted data: %w", err)
+	}
+	return result.Usage, nil
+}
@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+var testImage = types.ImageContent{Image: []byte{0x89, 'P', 'N', 'G'}, MimeType: "image/png"}
+
+func visionModel(reply string, capture *provider.GenerateOptions) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		ImageSupport:      true,
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if capture != nil {
+				*capture = *opts
+			}
+			return &types.GenerateResult{Text: reply, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+}
+
+func TestDescribeImage(t *testing.T) {
+	t.Parallel()
+
+	var got provider.GenerateOptions
+	result, err := DescribeImage(context.Background(), DescribeImageOptions{
+		Model:    visionModel(" A cat on a windowsill. ", &got),
+		Image:    testImage,
+		Style:    DescriptionAltText,
+		Language: "French",
+	})
+	if err != nil {
+		t.Fatalf("DescribeImage failed: %v", err)
+	}
+	if result.Description != "A cat on a windowsill." {
+		t.Errorf("Description = %q", result.Description)
+	}
+
+	msg := got.Prompt.Messages[len(got.Prompt.Messages)-1]
+	if _, ok := msg.Content[0].(types.ImageContent); !ok {
+		t.Fatalf("expected image content first, got %T", msg.Content[0])
+	}
+	text := msg.Content[1].(types.TextContent).Text
+	if text != descriptionPrompts[DescriptionAltText]+" Respond in French." {
+		t.Errorf("prompt = %q", text)
+	}
+}
+
+func TestDescribeImage_RequiresImageInput(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	_, err := DescribeImage(context.Background(), DescribeImageOptions{Model: model, Image: testImage})
+	if !errors.Is(err, ErrImageInputUnsupported) {
+		t.Errorf("err = %v, want ErrImageInputUnsupported", err)
+	}
+	if model.GenerateCalls != nil {
+		t.Error("model should not be called")
+	}
+}
+
+func TestOCR_Fields(t *testing.T) {
+	t.Parallel()
+
+	var got provider.GenerateOptions
+	result, err := OCR(context.Background(), OCROptions{
+		Model: visionModel(`{"text":"ACME\nTotal: $42.50","fields":{"vendor":"ACME","total":42.5,"due_date":null}}`, &got),
+		Image: testImage,
+		Fields: []OCRField{
+			{Name: "vendor"},
+			{Name: "total", Type: "number", Description: "invoice total"},
+			{Name: "due_date"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("OCR failed: %v", err)
+	}
+	if result.Text != "ACME\nTotal: $42.50" {
+		t.Errorf("Text = %q", result.Text)
+	}
+	want := map[string]interface{}{"vendor": "ACME", "total": 42.5, "due_date": nil}
+	if !reflect.DeepEqual(result.Fields, want) {
+		t.Errorf("Fields = %v, want %v", result.Fields, want)
+	}
+	if got.ResponseFormat == nil || got.ResponseFormat.Schema == nil {
+		t.Error("expected a JSON schema response format")
+	}
+}
+
+func TestOCR_TextOnly(t *testing.T) {
+	t.Parallel()
+
+	result, err := OCR(context.Background(), OCROptions{Model: visionModel("STOP\n", nil), Image: testImage})
+	if err != nil {
+		t.Fatalf("OCR failed: %v", err)
+	}
+	if result.Text != "STOP" || result.Fields != nil {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestExtractChartData(t *testing.T) {
+	t.Parallel()
+
+	result, err := ExtractChartData(context.Background(), ChartDataOptions{
+		Model: visionModel(`{"title":"Revenue","type":"bar","xLabel":"Quarter","yLabel":"USD","series":[{"name":"2025","points":[{"x":"Q1","y":10},{"x":"Q2","y":12.5}]}]}`, nil),
+		Image: testImage,
+	})
+	if err != nil {
+		t.Fatalf("ExtractChartData failed: %v", err)
+	}
+	want := ChartData{
+		Title: "Revenue", Type: "bar", XLabel: "Quarter", YLabel: "USD",
+		Series: []ChartSeries{{Name: "2025", Points: []ChartPoint{{X: "Q1", Y: 10}, {X: "Q2", Y: 12.5}}}},
+	}
+	if !reflect.DeepEqual(result.Chart, want) {
+		t.Errorf("Chart = %+v, want %+v", result.Chart, want)
+	}
+}
@@ -0,0 +1,125 @@
+// Package artifact stores generated files, images and audio by content hash,
+// so results can carry a stable ID and URL instead of large byte slices.
+//
+// Stores are content-addressable: the ID of an artifact is the SHA-256 of
+// its bytes, so storing the same output twice keeps one copy and IDs never
+// change meaning.
+//
+// Wrap models to move their outputs into a store as they are generated:
+//
+//	store, _ := artifact.NewFileStore("/var/lib/myapp/artifacts", "https://example.com/artifacts/")
+//	images := artifact.ImageModel(imageModel, store, artifact.Options{})
+//	result, _ := images.DoGenerate(ctx, &provider.ImageGenerateOptions{Prompt: "a lighthouse"})
+//	fmt.Println(result.ArtifactID, result.URL) // result.Image is nil
+//
+//	http.Handle("/artifacts/", http.StripPrefix("/artifacts/", artifact.Handler(store)))
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when no artifact has the requested ID
+var ErrNotFound = errors.New("artifact not found")
+
+// Artifact describes a stored artifact
+type Artifact struct {
+	// ID is "sha256-" followed by the hex SHA-256 of the artifact's bytes
+	ID string `json:"id"`
+
+	// MediaType is the MIME type of the artifact, e.g. "image/png"
+	MediaType string `json:"mediaType"`
+
+	// Size is the artifact's length in bytes
+	Size int64 `json:"size"`
+
+	// URL locates the artifact, when the store can serve it
+	URL string `json:"url,omitempty"`
+}
+
+// Store holds artifacts by content hash. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Put stores data, returning its artifact. Storing bytes that are already
+	// present returns the existing artifact.
+	Put(ctx context.Context, data []byte, mediaType string) (*Artifact, error)
+
+	// Get returns an artifact's bytes, or ErrNotFound
+	Get(ctx context.Context, id string) ([]byte, *Artifact, error)
+
+	// Delete removes an artifact. Deleting a missing artifact is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// ID returns the content-addressed ID of data
+func ID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256-" + hex.EncodeToString(sum[:])
+}
+
+// validID reports whether id has the form returned by ID, so IDs can be
+// used safely as file names and object keys
+func validID(id string) bool {
+	hash, ok := strings.CutPrefix(id, "sha256-")
+	if !ok || len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil && strings.ToLower(hash) == hash
+}
+
+// MemoryStore is an in-memory Store, useful for tests and short-lived
+// processes
+type MemoryStore struct {
+	mu        sync.RWMutex
+	artifacts map[string]memoryArtifact
+}
+
+type memoryArtifact struct {
+	data []byte
+	meta Artifact
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{artifacts: make(map[string]memoryArtifact)}
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(ctx context.Context, data []byte, mediaType string) (*Artifact, error) {
+	id := ID(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.artifacts[id]; ok {
+		meta := existing.meta
+		return &meta, nil
+	}
+	meta := Artifact{ID: id, MediaType: mediaType, Size: int64(len(data))}
+	s.artifacts[id] = memoryArtifact{data: append([]byte(nil), data...), meta: meta}
+	return &meta, nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(ctx context.Context, id string) ([]byte, *Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.artifacts[id]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	meta := a.meta
+	return append([]byte(nil), a.data...), &meta, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.artifacts, id)
+	return nil
+}
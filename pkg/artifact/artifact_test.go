@@ -0,0 +1,174 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), "https://cdn.example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := store.Put(ctx, []byte("png bytes"), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != ID([]byte("png bytes")) || a.URL != "https://cdn.example.com/a/"+a.ID || a.Size != 9 {
+		t.Errorf("unexpected artifact %+v", a)
+	}
+	again, _ := store.Put(ctx, []byte("png bytes"), "image/png")
+	if again.ID != a.ID {
+		t.Errorf("expected identical bytes to share an ID")
+	}
+
+	data, meta, err := store.Get(ctx, a.ID)
+	if err != nil || string(data) != "png bytes" || meta.MediaType != "image/png" {
+		t.Errorf("Get: %q %+v %v", data, meta, err)
+	}
+	if _, _, err := store.Get(ctx, "../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected invalid IDs to be not found, got %v", err)
+	}
+
+	if err := store.Delete(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(ctx, a.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestObjectStore(t *testing.T) {
+	t.Parallel()
+
+	objects := map[string][]byte{}
+	contentTypes := map[string]string{}
+	store := (&ObjectStore{
+		Prefix: "artifacts/",
+		Put: func(ctx context.Context, key string, data []byte, contentType string) error {
+			objects[key], contentTypes[key] = data, contentType
+			return nil
+		},
+		Get: func(ctx context.Context, key string) ([]byte, string, error) {
+			data, ok := objects[key]
+			if !ok {
+				return nil, "", ErrNotFound
+			}
+			return data, contentTypes[key], nil
+		},
+		URL: func(key string) string { return "https://bucket.example.com/" + key },
+	}).Store()
+
+	a, err := store.Put(context.Background(), []byte("audio"), "audio/mpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects["artifacts/"+a.ID]; !ok || a.URL != "https://bucket.example.com/artifacts/"+a.ID {
+		t.Errorf("unexpected artifact %+v", a)
+	}
+	if data, meta, err := store.Get(context.Background(), a.ID); err != nil || string(data) != "audio" || meta.MediaType != "audio/mpeg" {
+		t.Errorf("Get: %q %+v %v", data, meta, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	a, _ := store.Put(context.Background(), []byte("<svg/>"), "image/svg+xml")
+	server := httptest.NewServer(Handler(store))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/" + a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "<svg/>" || resp.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("unexpected response %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+
+	resp, err = http.Get(server.URL + "/" + ID([]byte("missing")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestImageModel(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	model := ImageModel(&testutil.MockImageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.ImageGenerateOptions) (*types.ImageResult, error) {
+			return &types.ImageResult{Image: []byte("image"), MimeType: "image/png"}, nil
+		},
+	}, store, Options{})
+
+	result, err := model.DoGenerate(context.Background(), &provider.ImageGenerateOptions{Prompt: "a cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Image != nil || result.ArtifactID != ID([]byte("image")) {
+		t.Errorf("expected the image to be moved to the store, got %+v", result)
+	}
+	if data, _, err := store.Get(context.Background(), result.ArtifactID); err != nil || string(data) != "image" {
+		t.Errorf("stored image: %q %v", data, err)
+	}
+}
+
+func TestLanguageModelMiddleware(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	chart := types.GeneratedFileContent{MediaType: "image/png", Data: []byte("chart")}
+	model := middleware.WrapLanguageModel(&testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: "done", Content: []types.ContentPart{chart}}, nil
+		},
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeFile, GeneratedFileContent: &chart},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}, []*middleware.LanguageModelMiddleware{LanguageModelMiddleware(store, Options{MinSize: 4})}, nil, nil)
+
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := result.Content[0].(types.GeneratedFileContent)
+	if file.Data != nil || file.ArtifactID != ID([]byte("chart")) {
+		t.Errorf("expected the file to be moved to the store, got %+v", file)
+	}
+
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk, _ := stream.Next()
+	if chunk.GeneratedFileContent.Data != nil || chunk.GeneratedFileContent.ArtifactID == "" {
+		t.Errorf("expected the streamed file to be moved to the store, got %+v", chunk.GeneratedFileContent)
+	}
+	if chart.Data == nil {
+		t.Error("expected the provider's chunk not to be modified")
+	}
+}
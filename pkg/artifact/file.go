@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore stores artifacts as files under a directory, sharded by the
+// first byte of the hash: dir/ab/sha256-ab12....
+// The media type is kept in a ".json" file next to each artifact.
+type FileStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewFileStore creates a store rooted at dir, creating it if needed.
+// baseURL, when set, is where the artifacts are served (e.g. by Handler):
+// an artifact's URL is baseURL followed by its ID. Without it, URLs are
+// file:// URLs.
+func NewFileStore(dir, baseURL string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &FileStore{dir: dir, baseURL: baseURL}, nil
+}
+
+// path returns the file holding the artifact with the given ID
+func (s *FileStore) path(id string) string {
+	hash := strings.TrimPrefix(id, "sha256-")
+	return filepath.Join(s.dir, hash[:2], id)
+}
+
+func (s *FileStore) url(id string) string {
+	if s.baseURL != "" {
+		return s.baseURL + id
+	}
+	abs, err := filepath.Abs(s.path(id))
+	if err != nil {
+		return ""
+	}
+	return "file://" + filepath.ToSlash(abs)
+}
+
+// Put implements Store. Files are written to a temporary name and renamed,
+// so readers never see a partial artifact.
+func (s *FileStore) Put(ctx context.Context, data []byte, mediaType string) (*Artifact, error) {
+	id := ID(data)
+	if _, meta, err := s.stat(id); err == nil {
+		return meta, nil
+	}
+
+	path := s.path(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	meta := &Artifact{ID: id, MediaType: mediaType, Size: int64(len(data)), URL: s.url(id)}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path+".json", metaJSON); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Get implements Store
+func (s *FileStore) Get(ctx context.Context, id string) ([]byte, *Artifact, error) {
+	path, meta, err := s.stat(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, meta, nil
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if !validID(id) {
+		return nil
+	}
+	path := s.path(id)
+	for _, p := range []string{path, path + ".json"} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stat returns the artifact's path and metadata, or ErrNotFound
+func (s *FileStore) stat(id string) (string, *Artifact, error) {
+	if !validID(id) {
+		return "", nil, ErrNotFound
+	}
+	path := s.path(id)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, ErrNotFound
+		}
+		return "", nil, err
+	}
+	meta := &Artifact{ID: id}
+	if data, err := os.ReadFile(path + ".json"); err == nil {
+		_ = json.Unmarshal(data, meta)
+	}
+	if info, err := os.Stat(path); err == nil {
+		meta.Size = info.Size()
+	}
+	meta.URL = s.url(id)
+	return path, meta, nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
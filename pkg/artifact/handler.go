@@ -0,0 +1,41 @@
+package artifact
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Handler serves artifacts from store at "/{id}". Artifacts never change,
+// so responses are marked immutable and cacheable for a year; range
+// requests are supported for audio and video players. Mount it with
+// http.StripPrefix under the store's base URL.
+func Handler(store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/")
+		data, meta, err := store.Get(r.Context(), id)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read artifact", http.StatusInternalServerError)
+			return
+		}
+
+		if meta.MediaType != "" {
+			w.Header().Set("Content-Type", meta.MediaType)
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("ETag", `"`+id+`"`)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,175 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Options configures the model wrappers
+type Options struct {
+	// MinSize keeps outputs smaller than this many bytes inline (default: 0,
+	// every output is stored)
+	MinSize int
+
+	// KeepData leaves the bytes in results after storing them
+	KeepData bool
+}
+
+// offload reports whether data should be moved into the store
+func (o Options) offload(data []byte) bool {
+	return len(data) > 0 && len(data) >= o.MinSize
+}
+
+// ImageModel wraps model so generated images are put in store. Results
+// carry the artifact's ID and URL, and Image is cleared unless
+// Options.KeepData is set. A store failure fails the call.
+func ImageModel(model provider.ImageModel, store Store, opts Options) provider.ImageModel {
+	return &imageModel{ImageModel: model, store: store, opts: opts}
+}
+
+type imageModel struct {
+	provider.ImageModel
+	store Store
+	opts  Options
+}
+
+// DoGenerate generates the image and stores it
+func (m *imageModel) DoGenerate(ctx context.Context, opts *provider.ImageGenerateOptions) (*types.ImageResult, error) {
+	result, err := m.ImageModel.DoGenerate(ctx, opts)
+	if err != nil || !m.opts.offload(result.Image) {
+		return result, err
+	}
+	a, err := m.store.Put(ctx, result.Image, result.MimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store image artifact: %w", err)
+	}
+	result.ArtifactID = a.ID
+	if a.URL != "" {
+		result.URL = a.URL
+	}
+	if !m.opts.KeepData {
+		result.Image = nil
+	}
+	return result, nil
+}
+
+// SpeechModel wraps model so synthesized audio is put in store. Results
+// carry the artifact's ID and URL, and Audio is cleared unless
+// Options.KeepData is set. A store failure fails the call.
+func SpeechModel(model provider.SpeechModel, store Store, opts Options) provider.SpeechModel {
+	return &speechModel{SpeechModel: model, store: store, opts: opts}
+}
+
+type speechModel struct {
+	provider.SpeechModel
+	store Store
+	opts  Options
+}
+
+// DoGenerate synthesizes the audio and stores it
+func (m *speechModel) DoGenerate(ctx context.Context, opts *provider.SpeechGenerateOptions) (*types.SpeechResult, error) {
+	result, err := m.SpeechModel.DoGenerate(ctx, opts)
+	if err != nil || !m.opts.offload(result.Audio) {
+		return result, err
+	}
+	a, err := m.store.Put(ctx, result.Audio, result.MimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store audio artifact: %w", err)
+	}
+	result.ArtifactID, result.URL = a.ID, a.URL
+	if !m.opts.KeepData {
+		result.Audio = nil
+	}
+	return result, nil
+}
+
+// LanguageModelMiddleware returns middleware that puts files generated by
+// language models, such as charts from code execution, in store. Generated
+// file parts in results and "file" stream chunks carry the artifact's ID and
+// URL, and their Data is cleared unless Options.KeepData is set.
+func LanguageModelMiddleware(store Store, opts Options) *middleware.LanguageModelMiddleware {
+	return &middleware.LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil {
+				return nil, err
+			}
+			for i, part := range result.Content {
+				file, ok := part.(types.GeneratedFileContent)
+				if !ok {
+					continue
+				}
+				if err := storeFile(ctx, store, opts, &file); err != nil {
+					return nil, err
+				}
+				result.Content[i] = file
+			}
+			return result, nil
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &fileStream{TextStream: stream, ctx: ctx, store: store, opts: opts}, nil
+		},
+	}
+}
+
+// storeFile moves a generated file's data into store
+func storeFile(ctx context.Context, store Store, opts Options, file *types.GeneratedFileContent) error {
+	if !opts.offload(file.Data) {
+		return nil
+	}
+	a, err := store.Put(ctx, file.Data, file.MediaType)
+	if err != nil {
+		return fmt.Errorf("failed to store file artifact: %w", err)
+	}
+	file.ArtifactID, file.URL = a.ID, a.URL
+	if !opts.KeepData {
+		file.Data = nil
+	}
+	return nil
+}
+
+// fileStream stores the files of "file" chunks as they arrive
+type fileStream struct {
+	provider.TextStream
+	ctx   context.Context
+	store Store
+	opts  Options
+}
+
+// Next returns the next chunk, with generated files moved into the store
+func (s *fileStream) Next() (*provider.StreamChunk, error) {
+	chunk, err := s.TextStream.Next()
+	if err != nil || chunk.Type != provider.ChunkTypeFile || chunk.GeneratedFileContent == nil {
+		return chunk, err
+	}
+	file := *chunk.GeneratedFileContent
+	if err := storeFile(s.ctx, s.store, s.opts, &file); err != nil {
+		return nil, err
+	}
+	stored := *chunk
+	stored.GeneratedFileContent = &file
+	return &stored, nil
+}
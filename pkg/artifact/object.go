@@ -0,0 +1,115 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+)
+
+// ObjectStore adapts an object storage service such as S3, GCS or Azure
+// Blob Storage. Artifacts are stored under Prefix followed by their ID, with
+// the media type as the object's content type. Get must return ErrNotFound
+// for missing objects.
+//
+// Example (aws-sdk-go-v2):
+//
+//	objects := &artifact.ObjectStore{
+//		Prefix: "artifacts/",
+//		Put: func(ctx context.Context, key string, data []byte, contentType string) error {
+//			_, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+//				Bucket: aws.String(bucket), Key: aws.String(key),
+//				Body: bytes.NewReader(data), ContentType: aws.String(contentType),
+//			})
+//			return err
+//		},
+//		Get: func(ctx context.Context, key string) ([]byte, string, error) {
+//			out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+//			var missing *types.NoSuchKey
+//			if errors.As(err, &missing) {
+//				return nil, "", artifact.ErrNotFound
+//			}
+//			if err != nil {
+//				return nil, "", err
+//			}
+//			defer out.Body.Close()
+//			data, err := io.ReadAll(out.Body)
+//			return data, aws.ToString(out.ContentType), err
+//		},
+//		Delete: func(ctx context.Context, key string) error {
+//			_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+//			return err
+//		},
+//		URL: func(key string) string { return "https://" + bucket + ".s3.amazonaws.com/" + key },
+//	}
+//	store := objects.Store()
+type ObjectStore struct {
+	// Prefix is prepended to artifact IDs to form object keys
+	Prefix string
+
+	// Put uploads an object
+	Put func(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get downloads an object and its content type
+	Get func(ctx context.Context, key string) ([]byte, string, error)
+
+	// Exists reports whether an object exists (optional). When set, Put skips
+	// uploading artifacts that are already stored.
+	Exists func(ctx context.Context, key string) (bool, error)
+
+	// Delete removes an object (optional)
+	Delete func(ctx context.Context, key string) error
+
+	// URL returns the public or signed URL of an object (optional)
+	URL func(key string) string
+}
+
+// objectStore exposes ObjectStore as a Store; the struct's fields share the
+// method names
+type objectStore struct{ o *ObjectStore }
+
+// Store returns the Store backed by o
+func (o *ObjectStore) Store() Store {
+	return objectStore{o: o}
+}
+
+func (s objectStore) artifact(id, mediaType string, size int) *Artifact {
+	a := &Artifact{ID: id, MediaType: mediaType, Size: int64(size)}
+	if s.o.URL != nil {
+		a.URL = s.o.URL(s.o.Prefix + id)
+	}
+	return a
+}
+
+func (s objectStore) Put(ctx context.Context, data []byte, mediaType string) (*Artifact, error) {
+	id := ID(data)
+	key := s.o.Prefix + id
+	if s.o.Exists != nil {
+		if ok, err := s.o.Exists(ctx, key); err == nil && ok {
+			return s.artifact(id, mediaType, len(data)), nil
+		}
+	}
+	if err := s.o.Put(ctx, key, data, mediaType); err != nil {
+		return nil, err
+	}
+	return s.artifact(id, mediaType, len(data)), nil
+}
+
+func (s objectStore) Get(ctx context.Context, id string) ([]byte, *Artifact, error) {
+	if !validID(id) {
+		return nil, nil, ErrNotFound
+	}
+	data, mediaType, err := s.o.Get(ctx, s.o.Prefix+id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, s.artifact(id, mediaType, len(data)), nil
+}
+
+func (s objectStore) Delete(ctx context.Context, id string) error {
+	if s.o.Delete == nil {
+		return errors.New("object store does not support deletes")
+	}
+	if !validID(id) {
+		return nil
+	}
+	return s.o.Delete(ctx, s.o.Prefix+id)
+}
@@ -0,0 +1,319 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func TestDistributions(t *testing.T) {
+	t.Parallel()
+
+	rng := clock.NewRNG(1)
+	if d := Fixed(time.Second).Sample(rng); d != time.Second {
+		t.Errorf("Fixed = %v", d)
+	}
+	for i := 0; i < 100; i++ {
+		if d := Uniform(time.Second, 2*time.Second).Sample(rng); d < time.Second || d >= 2*time.Second {
+			t.Fatalf("Uniform out of range: %v", d)
+		}
+		if d := Normal(10*time.Millisecond, 50*time.Millisecond).Sample(rng); d < 0 {
+			t.Fatalf("Normal not clamped: %v", d)
+		}
+	}
+}
+
+func TestSyntheticModel_Generate(t *testing.T) {
+	t.Parallel()
+
+	model := NewSyntheticModel(SyntheticConfig{OutputTokens: 5, Text: "a b c"})
+	result, err := ai.GenerateText(context.Background(), ai.GenerateTextOptions{Model: model, Prompt: "12345678"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "a b c a b" {
+		t.Errorf("unexpected text %q", result.Text)
+	}
+	if result.Usage.GetOutputTokens() != 5 || result.Usage.GetInputTokens() != 3 {
+		t.Errorf("unexpected usage %+v", result.Usage)
+	}
+	if stats := model.Stats(); stats.Requests != 1 || stats.OutputTokens != 5 || stats.ActiveRequests != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSyntheticModel_StreamPacing(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	model := NewSyntheticModel(SyntheticConfig{
+		TimeToFirstToken: Fixed(200 * time.Millisecond),
+		TokensPerSecond:  10,
+		OutputTokens:     3,
+		Clock:            fake,
+	})
+
+	done := make(chan string)
+	go func() {
+		stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+		if err != nil {
+			done <- err.Error()
+			return
+		}
+		var text strings.Builder
+		for {
+			chunk, err := stream.Next()
+			if err == io.EOF {
+				break
+			}
+			text.WriteString(chunk.Text)
+		}
+		done <- text.String()
+	}()
+
+	// Time to first token, then one timer per following token
+	for _, d := range []time.Duration{200 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond} {
+		fake.BlockUntil(1)
+		fake.Advance(d)
+	}
+	if got := <-done; got != "Go is an" {
+		t.Errorf("unexpected text %q", got)
+	}
+	if elapsed := fake.Now().Sub(time.Unix(0, 0)); elapsed != 400*time.Millisecond {
+		t.Errorf("expected 400ms of simulated time, got %v", elapsed)
+	}
+}
+
+func TestSyntheticModel_ErrorInjection(t *testing.T) {
+	t.Parallel()
+
+	model := NewSyntheticModel(SyntheticConfig{RateLimitRate: 1})
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	if !providererrors.IsRateLimitError(err) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+
+	model = NewSyntheticModel(SyntheticConfig{ErrorRate: 1})
+	_, err = model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	var perr *providererrors.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != 500 {
+		t.Errorf("expected provider error, got %v", err)
+	}
+
+	model = NewSyntheticModel(SyntheticConfig{StreamErrorRate: 1, OutputTokens: 10})
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		_, err := stream.Next()
+		if err == nil {
+			continue
+		}
+		if err == io.EOF {
+			t.Fatal("expected the stream to fail")
+		}
+		break
+	}
+	if stats := model.Stats(); stats.StreamFailures != 1 || stats.ActiveRequests != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRunLoad(t *testing.T) {
+	t.Parallel()
+
+	model := NewSyntheticModel(SyntheticConfig{OutputTokens: 4, ErrorRate: 0.3, RNG: clock.NewRNG(7)})
+	var calls atomic.Int64
+	result, err := RunLoad(context.Background(), LoadOptions{
+		Concurrency: 4,
+		Requests:    50,
+		Target: func(ctx context.Context, i int) (int64, error) {
+			calls.Add(1)
+			r, err := ai.GenerateText(ctx, ai.GenerateTextOptions{Model: model, Prompt: "hi"})
+			if err != nil {
+				return 0, err
+			}
+			return r.Usage.GetOutputTokens(), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Requests != 50 || calls.Load() != 50 {
+		t.Errorf("expected 50 requests, got %d", result.Requests)
+	}
+	if result.Failures == 0 || result.Successes+result.Failures != 50 {
+		t.Errorf("unexpected outcome counts %+v", result)
+	}
+	if result.Tokens != result.Successes*4 {
+		t.Errorf("expected 4 tokens per success, got %d", result.Tokens)
+	}
+	if len(result.Latencies) != 50 || result.Percentile(100) < result.Percentile(50) {
+		t.Errorf("unexpected latencies")
+	}
+	if stats := model.Stats(); stats.PeakConcurrency > 4 {
+		t.Errorf("peak concurrency %d exceeds workers", stats.PeakConcurrency)
+	}
+
+	var buf bytes.Buffer
+	result.Print(&buf)
+	if !strings.Contains(buf.String(), "synthetic failure") {
+		t.Errorf("expected error breakdown in summary:\n%s", buf.String())
+	}
+}
+
+func TestRunLoad_Duration(t *testing.T) {
+	t.Parallel()
+
+	result, err := RunLoad(context.Background(), LoadOptions{
+		Concurrency: 2,
+		Duration:    50 * time.Millisecond,
+		Target: func(ctx context.Context, i int) (int64, error) {
+			select {
+			case <-time.After(5 * time.Millisecond):
+				return 1, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Requests == 0 || result.Failures != 0 {
+		t.Errorf("expected only completed requests to be counted, got %+v", result)
+	}
+}
+
+func TestRunLoad_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := RunLoad(context.Background(), LoadOptions{Requests: 1}); err == nil {
+		t.Error("expected error without target")
+	}
+	target := func(context.Context, int) (int64, error) { return 0, nil }
+	if _, err := RunLoad(context.Background(), LoadOptions{Target: target}); err == nil {
+		t.Error("expected error without duration or requests")
+	}
+}
+
+func simulatedConversation(turns int) *conversation.Conversation {
+	c := &conversation.Conversation{Messages: []types.Message{
+		{Role: types.RoleSystem, Content: []types.ContentPart{types.TextContent{Text: "Be brief."}}},
+	}}
+	for i := 0; i < turns; i++ {
+		c.Messages = append(c.Messages,
+			types.Message{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "question"}}},
+			types.Message{Role: types.RoleAssistant, Content: []types.ContentPart{types.TextContent{Text: "answer"}}},
+		)
+	}
+	return c
+}
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var promptLengths []int
+	failing := errors.New("upstream unavailable")
+	result, err := Simulate(context.Background(), SimulationOptions{
+		Conversations: []*conversation.Conversation{simulatedConversation(3), simulatedConversation(1)},
+		Replays:       2,
+		Concurrency:   3,
+		Target: func(ctx context.Context, messages []types.Message) (types.Usage, error) {
+			mu.Lock()
+			promptLengths = append(promptLengths, len(messages))
+			mu.Unlock()
+			// The third turn of the long conversation always fails
+			if len(messages) == 6 {
+				return types.Usage{}, failing
+			}
+			input, output := int64(len(messages)*10), int64(5)
+			return types.Usage{InputTokens: &input, OutputTokens: &output}, nil
+		},
+		Cost: func(usage types.Usage) float64 { return float64(usage.GetInputTokens()) / 1000 },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Per replay: long conversation makes calls with 2, 4 and 6 messages
+	// (the last fails), short conversation one call with 2
+	if result.Conversations != 4 || result.FailedConversations != 2 {
+		t.Errorf("conversations %d failed %d", result.Conversations, result.FailedConversations)
+	}
+	if result.Calls.Requests != 8 || result.Calls.Failures != 2 || result.Calls.Errors[failing.Error()] != 2 {
+		t.Errorf("unexpected call counts %+v", result.Calls)
+	}
+	if result.InputTokens != 2*(20+40+20) || result.OutputTokens != 2*3*5 {
+		t.Errorf("tokens in %d out %d", result.InputTokens, result.OutputTokens)
+	}
+	if got := result.ProjectCost(1000); got < 39.99 || got > 40.01 {
+		t.Errorf("ProjectCost(1000) = %v, want 40", got)
+	}
+	if len(promptLengths) != 8 {
+		t.Errorf("prompt lengths %v", promptLengths)
+	}
+
+	var buf bytes.Buffer
+	result.Print(&buf)
+	if !strings.Contains(buf.String(), "Conversations:   4 (2 failed, 50.0%)") {
+		t.Errorf("unexpected summary:\n%s", buf.String())
+	}
+}
+
+func TestSimulate_ThinkTimeAndModel(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	model := NewSyntheticModel(SyntheticConfig{OutputTokens: 3, Clock: fake})
+	done := make(chan *SimulationResult)
+	go func() {
+		result, err := Simulate(context.Background(), SimulationOptions{
+			Conversations: []*conversation.Conversation{simulatedConversation(2)},
+			Model:         model,
+			ThinkTime:     Fixed(5 * time.Second),
+			Clock:         fake,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		done <- result
+	}()
+
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(5 * time.Second)
+	}
+	result := <-done
+	if result.Calls.Successes != 2 || result.OutputTokens != 6 || result.Calls.Duration != 10*time.Second {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestSimulate_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Simulate(context.Background(), SimulationOptions{Model: NewSyntheticModel(SyntheticConfig{})}); err == nil {
+		t.Error("expected error without conversations")
+	}
+	conversations := []*conversation.Conversation{simulatedConversation(1)}
+	if _, err := Simulate(context.Background(), SimulationOptions{Conversations: conversations}); err == nil {
+		t.Error("expected error without model or target")
+	}
+}
@@ -0,0 +1,218 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+// LoadTarget performs one request of a load test and returns the number of
+// tokens it processed
+type LoadTarget func(ctx context.Context, i int) (tokens int64, err error)
+
+// LoadOptions configures RunLoad
+type LoadOptions struct {
+	// Target is called for each request, e.g. an HTTP call to the server
+	// under test or a direct ai.GenerateText call
+	Target LoadTarget
+
+	// Concurrency is the number of workers issuing requests
+	// Default: 1
+	Concurrency int
+
+	// Duration stops the test after this long
+	Duration time.Duration
+
+	// Requests stops the test after this many requests. At least one of
+	// Duration and Requests must be set.
+	Requests int
+
+	// RatePerSecond caps the request rate across all workers; zero sends
+	// the next request as soon as a worker is free
+	RatePerSecond float64
+
+	// Clock measures latency and paces the request rate
+	// Default: clock.System
+	Clock clock.Clock
+}
+
+// LoadResult summarises a load test
+type LoadResult struct {
+	Requests  int64
+	Successes int64
+	Failures  int64
+	Tokens    int64
+
+	// Duration is the wall time of the whole test
+	Duration time.Duration
+
+	// Latencies of all requests, sorted ascending
+	Latencies []time.Duration
+
+	// Errors counts failures by error message
+	Errors map[string]int64
+}
+
+// RunLoad runs a load test against opts.Target
+func RunLoad(ctx context.Context, opts LoadOptions) (*LoadResult, error) {
+	if opts.Target == nil {
+		return nil, fmt.Errorf("target is required")
+	}
+	if opts.Duration <= 0 && opts.Requests <= 0 {
+		return nil, fmt.Errorf("duration or requests is required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	opts.Clock = clock.Default(opts.Clock)
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = clock.WithTimeout(ctx, opts.Clock, opts.Duration)
+		defer cancel()
+	}
+
+	var limiter *rate.Limiter
+	if opts.RatePerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.RatePerSecond), 1)
+	}
+
+	result := &LoadResult{Errors: map[string]int64{}}
+	var mu sync.Mutex
+	next := 0
+
+	// claim hands out request indexes until the request budget is spent
+	claim := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if opts.Requests > 0 && next >= opts.Requests {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+
+	start := opts.Clock.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if limiter != nil {
+					if err := clock.WaitN(ctx, opts.Clock, limiter, 1); err != nil {
+						return
+					}
+				}
+				i, ok := claim()
+				if !ok {
+					return
+				}
+
+				reqStart := opts.Clock.Now()
+				tokens, err := opts.Target(ctx, i)
+				latency := opts.Clock.Now().Sub(reqStart)
+
+				// Requests cut off by the end of the test are not counted
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				result.Requests++
+				result.Latencies = append(result.Latencies, latency)
+				if err != nil {
+					result.Failures++
+					result.Errors[err.Error()]++
+				} else {
+					result.Successes++
+					result.Tokens += tokens
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	result.Duration = opts.Clock.Now().Sub(start)
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result, nil
+}
+
+// RequestsPerSecond returns the completed request rate
+func (r *LoadResult) RequestsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// TokensPerSecond returns the token throughput of successful requests
+func (r *LoadResult) TokensPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Tokens) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the fraction of requests that failed
+func (r *LoadResult) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Requests)
+}
+
+// MeanLatency returns the average request latency
+func (r *LoadResult) MeanLatency() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, l := range r.Latencies {
+		total += l
+	}
+	return total / time.Duration(len(r.Latencies))
+}
+
+// Percentile returns the p-th latency percentile (0-100)
+func (r *LoadResult) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	index := int(p/100*float64(len(r.Latencies))+0.999999) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(r.Latencies) {
+		index = len(r.Latencies) - 1
+	}
+	return r.Latencies[index]
+}
+
+// Print writes a human-readable summary to w
+func (r *LoadResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "Duration:        %v\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "Requests:        %d (%.2f/s)\n", r.Requests, r.RequestsPerSecond())
+	fmt.Fprintf(w, "Successful:      %d\n", r.Successes)
+	fmt.Fprintf(w, "Failed:          %d (%.1f%%)\n", r.Failures, r.ErrorRate()*100)
+	fmt.Fprintf(w, "Tokens:          %d (%.2f/s)\n", r.Tokens, r.TokensPerSecond())
+	fmt.Fprintf(w, "Latency mean:    %v\n", r.MeanLatency().Round(time.Millisecond))
+	fmt.Fprintf(w, "Latency p50/p90/p99: %v / %v / %v\n",
+		r.Percentile(50).Round(time.Millisecond), r.Percentile(90).Round(time.Millisecond), r.Percentile(99).Round(time.Millisecond))
+	messages := make([]string, 0, len(r.Errors))
+	for msg := range r.Errors {
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	for _, msg := range messages {
+		fmt.Fprintf(w, "  %dx %s\n", r.Errors[msg], msg)
+	}
+}
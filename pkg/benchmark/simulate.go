@@ -0,0 +1,239 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// SimulationTarget sends one model call of a replayed conversation, given
+// the history before the recorded assistant reply, and returns its usage.
+// Use it to drive a deployed server over HTTP instead of a model directly.
+type SimulationTarget func(ctx context.Context, messages []types.Message) (types.Usage, error)
+
+// SimulationOptions configures Simulate
+type SimulationOptions struct {
+	// Conversations are the recorded conversations to replay (required),
+	// e.g. loaded with conversation.Import or from a conversation.Store
+	Conversations []*conversation.Conversation
+
+	// Model receives the replayed calls, e.g. a real provider or a
+	// SyntheticModel. Ignored when Target is set.
+	Model provider.LanguageModel
+
+	// Target replaces Model for deployments reached some other way
+	Target SimulationTarget
+
+	// Concurrency is the number of conversations replayed at once
+	// Default: 1
+	Concurrency int
+
+	// Replays is the number of times each conversation is replayed
+	// Default: 1
+	Replays int
+
+	// ThinkTime is the pause before each user message is sent, modelling
+	// the time users take to read and reply
+	// Default: no pause
+	ThinkTime Distribution
+
+	// Cost prices a call's usage in USD, e.g. a provider's ModelInfo.Cost.
+	// Without it, costs are zero.
+	Cost func(usage types.Usage) float64
+
+	// Clock measures latency and paces think time
+	// Default: clock.System
+	Clock clock.Clock
+
+	// RNG samples think time
+	// Default: the global random source
+	RNG clock.RNG
+}
+
+// SimulationResult summarises a simulation
+type SimulationResult struct {
+	// Calls summarises every model call, with latency percentiles and
+	// error counts
+	Calls LoadResult
+
+	// Conversations replayed, and how many stopped early on a failed call
+	Conversations       int64
+	FailedConversations int64
+
+	// Usage totals the usage of successful calls
+	InputTokens  int64
+	OutputTokens int64
+
+	// Cost is the total cost of successful calls in USD
+	Cost float64
+}
+
+// Simulate replays recorded conversations against a model or deployment for
+// capacity planning. Each recorded assistant reply becomes one call whose
+// prompt is the recorded history before it, so load matches the real
+// conversations regardless of what the target answers. A failed call ends
+// that replay of the conversation.
+//
+// Example:
+//
+//	result, err := benchmark.Simulate(ctx, benchmark.SimulationOptions{
+//		Conversations: recorded,
+//		Model:         model,
+//		Concurrency:   200,
+//		ThinkTime:     benchmark.Normal(8*time.Second, 3*time.Second),
+//		Cost:          fireworks.Models[fireworks.ModelLlama3p3_70BInstruct].Cost,
+//	})
+//	result.Print(os.Stdout)
+//	fmt.Printf("1M conversations/month: $%.2f\n", result.ProjectCost(1_000_000))
+func Simulate(ctx context.Context, opts SimulationOptions) (*SimulationResult, error) {
+	if len(opts.Conversations) == 0 {
+		return nil, fmt.Errorf("conversations are required")
+	}
+	if opts.Target == nil {
+		if opts.Model == nil {
+			return nil, fmt.Errorf("model or target is required")
+		}
+		model := opts.Model
+		opts.Target = func(ctx context.Context, messages []types.Message) (types.Usage, error) {
+			result, err := model.DoGenerate(ctx, &provider.GenerateOptions{Prompt: types.Prompt{Messages: messages}})
+			if err != nil {
+				return types.Usage{}, err
+			}
+			return result.Usage, nil
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Replays <= 0 {
+		opts.Replays = 1
+	}
+	opts.Clock = clock.Default(opts.Clock)
+	opts.RNG = clock.DefaultRNG(opts.RNG)
+
+	jobs := make(chan *conversation.Conversation)
+	result := &SimulationResult{Calls: LoadResult{Errors: map[string]int64{}}}
+	var mu sync.Mutex
+
+	start := opts.Clock.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				replayConversation(ctx, opts, c, result, &mu)
+			}
+		}()
+	}
+feed:
+	for r := 0; r < opts.Replays; r++ {
+		for _, c := range opts.Conversations {
+			select {
+			case jobs <- c:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	result.Calls.Duration = opts.Clock.Now().Sub(start)
+	sort.Slice(result.Calls.Latencies, func(i, j int) bool { return result.Calls.Latencies[i] < result.Calls.Latencies[j] })
+	return result, ctx.Err()
+}
+
+// replayConversation makes one call per recorded assistant reply in c
+func replayConversation(ctx context.Context, opts SimulationOptions, c *conversation.Conversation, result *SimulationResult, mu *sync.Mutex) {
+	calls := 0
+	for i, msg := range c.Messages {
+		if msg.Role != types.RoleAssistant || i == 0 {
+			continue
+		}
+		if opts.ThinkTime != nil && c.Messages[i-1].Role == types.RoleUser {
+			if err := clock.Sleep(ctx, opts.Clock, opts.ThinkTime.Sample(opts.RNG)); err != nil {
+				return
+			}
+		}
+
+		callStart := opts.Clock.Now()
+		usage, err := opts.Target(ctx, c.Messages[:i])
+		latency := opts.Clock.Now().Sub(callStart)
+
+		// Calls cut off by cancellation are not counted
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+
+		mu.Lock()
+		if calls == 0 {
+			result.Conversations++
+		}
+		calls++
+		result.Calls.Requests++
+		result.Calls.Latencies = append(result.Calls.Latencies, latency)
+		if err != nil {
+			result.Calls.Failures++
+			result.Calls.Errors[err.Error()]++
+			result.FailedConversations++
+			mu.Unlock()
+			return
+		}
+		result.Calls.Successes++
+		result.Calls.Tokens += usage.GetTotalTokens()
+		result.InputTokens += usage.GetInputTokens()
+		result.OutputTokens += usage.GetOutputTokens()
+		if opts.Cost != nil {
+			result.Cost += opts.Cost(usage)
+		}
+		mu.Unlock()
+	}
+}
+
+// ConversationErrorRate returns the fraction of conversations that hit a
+// failed call
+func (r *SimulationResult) ConversationErrorRate() float64 {
+	if r.Conversations == 0 {
+		return 0
+	}
+	return float64(r.FailedConversations) / float64(r.Conversations)
+}
+
+// CostPerConversation returns the average cost of a replayed conversation
+func (r *SimulationResult) CostPerConversation() float64 {
+	if r.Conversations == 0 {
+		return 0
+	}
+	return r.Cost / float64(r.Conversations)
+}
+
+// ProjectCost estimates the cost of serving the given number of
+// conversations like the replayed ones
+func (r *SimulationResult) ProjectCost(conversations int64) float64 {
+	return r.CostPerConversation() * float64(conversations)
+}
+
+// CostPerHour returns the cost rate of the simulated load
+func (r *SimulationResult) CostPerHour() float64 {
+	if r.Calls.Duration <= 0 {
+		return 0
+	}
+	return r.Cost / r.Calls.Duration.Hours()
+}
+
+// Print writes a human-readable summary to w
+func (r *SimulationResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "Conversations:   %d (%d failed, %.1f%%)\n", r.Conversations, r.FailedConversations, r.ConversationErrorRate()*100)
+	r.Calls.Print(w)
+	fmt.Fprintf(w, "Input/output tokens: %d / %d\n", r.InputTokens, r.OutputTokens)
+	fmt.Fprintf(w, "Cost:            $%.4f ($%.6f per conversation)\n", r.Cost, r.CostPerConversation())
+	fmt.Fprintf(w, "Cost at this load: $%.2f/hour, $%.2f/day\n", r.CostPerHour(), r.CostPerHour()*24)
+}
@@ -0,0 +1,392 @@
+// Package benchmark load-tests integrations built on go-ai.
+//
+// SyntheticModel is a provider.LanguageModel that simulates a real backend
+// (latency, token rate, failures) without network calls, so a server can be
+// load-tested without API cost. RunLoad drives any target at a fixed
+// concurrency or request rate and reports throughput and latency
+// percentiles. Simulate replays recorded conversations for capacity
+// planning, adding conversation error rates and cost projections.
+//
+// Example:
+//
+//	model := benchmark.NewSyntheticModel(benchmark.SyntheticConfig{
+//		TimeToFirstToken: benchmark.Normal(300*time.Millisecond, 80*time.Millisecond),
+//		TokensPerSecond:  60,
+//		OutputTokens:     120,
+//		ErrorRate:        0.01,
+//	})
+//	result, err := benchmark.RunLoad(ctx, benchmark.LoadOptions{
+//		Concurrency: 50,
+//		Duration:    time.Minute,
+//		Target: func(ctx context.Context, i int) (int64, error) {
+//			r, err := ai.GenerateText(ctx, ai.GenerateTextOptions{Model: model, Prompt: "hi"})
+//			if err != nil {
+//				return 0, err
+//			}
+//			return r.Usage.GetTotalTokens(), nil
+//		},
+//	})
+package benchmark
+
+import (
+	"context"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Distribution samples durations, e.g. request latencies
+type Distribution interface {
+	Sample(rng clock.RNG) time.Duration
+}
+
+// DistributionFunc adapts a function to a Distribution
+type DistributionFunc func(rng clock.RNG) time.Duration
+
+// Sample implements Distribution
+func (f DistributionFunc) Sample(rng clock.RNG) time.Duration { return f(rng) }
+
+// Fixed always returns d
+func Fixed(d time.Duration) Distribution {
+	return DistributionFunc(func(clock.RNG) time.Duration { return d })
+}
+
+// Uniform returns durations spread evenly between min and max
+func Uniform(min, max time.Duration) Distribution {
+	return DistributionFunc(func(rng clock.RNG) time.Duration {
+		return min + time.Duration(rng.Float64()*float64(max-min))
+	})
+}
+
+// Normal returns normally distributed durations, clamped at zero
+func Normal(mean, stddev time.Duration) Distribution {
+	return DistributionFunc(func(rng clock.RNG) time.Duration {
+		// Box-Muller transform
+		u1 := 1 - rng.Float64()
+		u2 := rng.Float64()
+		z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+		d := time.Duration(float64(mean) + z*float64(stddev))
+		if d < 0 {
+			return 0
+		}
+		return d
+	})
+}
+
+// SyntheticConfig configures a SyntheticModel
+type SyntheticConfig struct {
+	// ModelID is reported by the model
+	// Default: "synthetic"
+	ModelID string
+
+	// TimeToFirstToken is the delay before output starts
+	// Default: no delay
+	TimeToFirstToken Distribution
+
+	// TokensPerSecond is the output rate after the first token; zero emits
+	// all tokens at once
+	TokensPerSecond float64
+
+	// OutputTokens is the number of tokens (words) per response
+	// Default: 50
+	OutputTokens int
+
+	// Text is the source text for responses; its words are repeated as
+	// needed to produce OutputTokens tokens
+	// Default: a fixed English passage
+	Text string
+
+	// ErrorRate is the probability that a request fails before any output
+	// with a 500 provider error
+	ErrorRate float64
+
+	// RateLimitRate is the probability that a request is rejected with a
+	// rate limit error
+	RateLimitRate float64
+
+	// StreamErrorRate is the probability that a stream fails midway
+	StreamErrorRate float64
+
+	// Clock paces latency and token output
+	// Default: clock.System
+	Clock clock.Clock
+
+	// RNG drives sampling and error injection
+	// Default: the global random source
+	RNG clock.RNG
+}
+
+// defaultSyntheticText is the default source text for synthetic responses
+const defaultSyntheticText = "Go is an open source programming language that makes it simple to build secure scalable systems. " +
+	"It was designed at Google to improve programming productivity in an era of multicore networked machines and large codebases."
+
+// SyntheticStats counts the requests a SyntheticModel has served
+type SyntheticStats struct {
+	Requests        int64
+	Failures        int64
+	RateLimited     int64
+	StreamFailures  int64
+	OutputTokens    int64
+	ActiveRequests  int64
+	PeakConcurrency int64
+}
+
+// SyntheticModel is a language model that simulates a provider backend
+type SyntheticModel struct {
+	config SyntheticConfig
+	words  []string
+
+	requests       atomic.Int64
+	failures       atomic.Int64
+	rateLimited    atomic.Int64
+	streamFailures atomic.Int64
+	outputTokens   atomic.Int64
+	active         atomic.Int64
+	peak           atomic.Int64
+}
+
+// NewSyntheticModel creates a synthetic model
+func NewSyntheticModel(config SyntheticConfig) *SyntheticModel {
+	if config.ModelID == "" {
+		config.ModelID = "synthetic"
+	}
+	if config.OutputTokens <= 0 {
+		config.OutputTokens = 50
+	}
+	if config.Text == "" {
+		config.Text = defaultSyntheticText
+	}
+	config.Clock = clock.Default(config.Clock)
+	config.RNG = clock.DefaultRNG(config.RNG)
+
+	return &SyntheticModel{
+		config: config,
+		words:  strings.Fields(config.Text),
+	}
+}
+
+// SpecificationVersion returns the specification version
+func (m *SyntheticModel) SpecificationVersion() string { return "v3" }
+
+// Provider returns the provider name
+func (m *SyntheticModel) Provider() string { return "synthetic" }
+
+// ModelID returns the model ID
+func (m *SyntheticModel) ModelID() string { return m.config.ModelID }
+
+// SupportsTools returns whether the model supports tool calling
+func (m *SyntheticModel) SupportsTools() bool { return false }
+
+// SupportsStructuredOutput returns whether the model supports structured output
+func (m *SyntheticModel) SupportsStructuredOutput() bool { return false }
+
+// SupportsImageInput returns whether the model accepts image inputs
+func (m *SyntheticModel) SupportsImageInput() bool { return false }
+
+// Stats returns the counters collected so far
+func (m *SyntheticModel) Stats() SyntheticStats {
+	return SyntheticStats{
+		Requests:        m.requests.Load(),
+		Failures:        m.failures.Load(),
+		RateLimited:     m.rateLimited.Load(),
+		StreamFailures:  m.streamFailures.Load(),
+		OutputTokens:    m.outputTokens.Load(),
+		ActiveRequests:  m.active.Load(),
+		PeakConcurrency: m.peak.Load(),
+	}
+}
+
+// DoGenerate waits for the simulated latency and returns the full response
+func (m *SyntheticModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	m.begin()
+	defer m.active.Add(-1)
+
+	if err := m.admit(ctx); err != nil {
+		return nil, err
+	}
+
+	tokens := m.config.OutputTokens
+	if m.config.TokensPerSecond > 0 {
+		d := time.Duration(float64(tokens-1) / m.config.TokensPerSecond * float64(time.Second))
+		if err := clock.Sleep(ctx, m.config.Clock, d); err != nil {
+			return nil, err
+		}
+	}
+
+	m.outputTokens.Add(int64(tokens))
+	return &types.GenerateResult{
+		Text:         strings.Join(m.tokens(tokens), ""),
+		FinishReason: types.FinishReasonStop,
+		Usage:        m.usage(opts, tokens),
+	}, nil
+}
+
+// DoStream waits for the time to first token and returns a stream that
+// emits one token per chunk at the configured rate
+func (m *SyntheticModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	m.begin()
+
+	if err := m.admit(ctx); err != nil {
+		m.active.Add(-1)
+		return nil, err
+	}
+
+	failAt := -1
+	if m.config.StreamErrorRate > 0 && m.config.RNG.Float64() < m.config.StreamErrorRate {
+		failAt = int(m.config.RNG.Float64() * float64(m.config.OutputTokens))
+	}
+
+	return &syntheticStream{
+		ctx:    ctx,
+		model:  m,
+		opts:   opts,
+		tokens: m.tokens(m.config.OutputTokens),
+		failAt: failAt,
+	}, nil
+}
+
+// begin records the start of a request
+func (m *SyntheticModel) begin() {
+	m.requests.Add(1)
+	active := m.active.Add(1)
+	for {
+		peak := m.peak.Load()
+		if active <= peak || m.peak.CompareAndSwap(peak, active) {
+			return
+		}
+	}
+}
+
+// admit applies error injection and the time to first token
+func (m *SyntheticModel) admit(ctx context.Context) error {
+	if m.config.RateLimitRate > 0 && m.config.RNG.Float64() < m.config.RateLimitRate {
+		m.rateLimited.Add(1)
+		retryAfter := 1
+		return providererrors.NewRateLimitError("synthetic", "synthetic rate limit", &retryAfter, nil)
+	}
+	if m.config.ErrorRate > 0 && m.config.RNG.Float64() < m.config.ErrorRate {
+		m.failures.Add(1)
+		return providererrors.NewProviderError("synthetic", 500, "server_error", "synthetic failure", nil)
+	}
+	if m.config.TimeToFirstToken != nil {
+		return clock.Sleep(ctx, m.config.Clock, m.config.TimeToFirstToken.Sample(m.config.RNG))
+	}
+	return nil
+}
+
+// tokens returns n tokens, each a word with its leading space
+func (m *SyntheticModel) tokens(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		word := m.words[i%len(m.words)]
+		if i > 0 {
+			word = " " + word
+		}
+		out[i] = word
+	}
+	return out
+}
+
+// usage estimates token usage for a request, counting roughly four
+// characters of prompt per input token
+func (m *SyntheticModel) usage(opts *provider.GenerateOptions, outputTokens int) types.Usage {
+	chars := len(opts.Prompt.System) + len(opts.Prompt.Text)
+	for _, msg := range opts.Prompt.Messages {
+		for _, part := range msg.Content {
+			if text, ok := part.(types.TextContent); ok {
+				chars += len(text.Text)
+			}
+		}
+	}
+	input := int64(chars/4 + 1)
+	output := int64(outputTokens)
+	total := input + output
+	return types.Usage{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}
+}
+
+// syntheticStream emits a synthetic response token by token
+type syntheticStream struct {
+	ctx    context.Context
+	model  *SyntheticModel
+	opts   *provider.GenerateOptions
+	tokens []string
+	failAt int
+
+	mu       sync.Mutex
+	index    int
+	finished bool
+	closed   bool
+	err      error
+}
+
+// Next returns the next token chunk, then a finish chunk
+func (s *syntheticStream) Next() (*provider.StreamChunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.finished {
+		return nil, io.EOF
+	}
+
+	if s.index == s.failAt {
+		s.model.streamFailures.Add(1)
+		s.finish()
+		s.err = providererrors.NewStreamError("synthetic stream interrupted", nil)
+		return nil, s.err
+	}
+
+	if s.index < len(s.tokens) {
+		if s.index > 0 && s.model.config.TokensPerSecond > 0 {
+			d := time.Duration(float64(time.Second) / s.model.config.TokensPerSecond)
+			if err := clock.Sleep(s.ctx, s.model.config.Clock, d); err != nil {
+				s.finish()
+				s.err = err
+				return nil, err
+			}
+		}
+		token := s.tokens[s.index]
+		s.index++
+		s.model.outputTokens.Add(1)
+		return &provider.StreamChunk{Type: provider.ChunkTypeText, Text: token}, nil
+	}
+
+	s.finish()
+	usage := s.model.usage(s.opts, len(s.tokens))
+	return &provider.StreamChunk{
+		Type:         provider.ChunkTypeFinish,
+		FinishReason: types.FinishReasonStop,
+		Usage:        &usage,
+	}, nil
+}
+
+// finish marks the request as done
+func (s *syntheticStream) finish() {
+	if !s.finished {
+		s.finished = true
+		s.model.active.Add(-1)
+	}
+}
+
+// Close ends the stream
+func (s *syntheticStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.finish()
+	return nil
+}
+
+// Err returns the injected or context error that ended the stream
+func (s *syntheticStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,185 @@
+// Package clock abstracts time and randomness so timeouts, backoff and rate
+// limiting can be tested deterministically.
+//
+// Production code uses System and the default RNG. Tests inject a Fake clock
+// and a seeded RNG, then advance virtual time instead of sleeping:
+//
+//	fake := clock.NewFake(time.Unix(0, 0))
+//	cfg := retry.DefaultConfig()
+//	cfg.Clock, cfg.RNG = fake, clock.NewRNG(1)
+//	go retry.Do(ctx, cfg, fn)
+//	fake.BlockUntil(1)        // wait for the backoff timer
+//	fake.Advance(time.Second) // fire it
+package clock
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Clock tells the time and creates timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTimer creates a timer that fires once after d
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer created by a Clock
+type Timer interface {
+	// C delivers the time when the timer fires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It reports whether the timer was
+	// stopped before it fired.
+	Stop() bool
+}
+
+// RNG is a source of random numbers
+type RNG interface {
+	// Float64 returns a number in [0.0, 1.0)
+	Float64() float64
+}
+
+// System is the real wall clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+func (t systemTimer) Stop() bool          { return t.t.Stop() }
+
+// Default returns c, or System when c is nil
+func Default(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+// DefaultRNG returns r, or the global math/rand source when r is nil
+func DefaultRNG(r RNG) RNG {
+	if r == nil {
+		return globalRNG{}
+	}
+	return r
+}
+
+type globalRNG struct{}
+
+func (globalRNG) Float64() float64 { return randv2.Float64() }
+
+// NewRNG returns a deterministic RNG seeded with seed. It is safe for
+// concurrent use.
+func NewRNG(seed int64) RNG {
+	return &lockedRNG{r: rand.New(rand.NewSource(seed))}
+}
+
+type lockedRNG struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRNG) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// Sleep waits for d on clock c, returning early with ctx's error if ctx is
+// done first
+func Sleep(ctx context.Context, c Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := Default(c).NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithTimeout is context.WithTimeout measured on clock c. With a Fake clock
+// the context expires when the fake time is advanced past the deadline, and
+// its Err is context.DeadlineExceeded as with a real timeout.
+func WithTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	c = Default(c)
+	if _, ok := c.(systemClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+
+	inner, cancel := context.WithCancel(ctx)
+	tc := &timeoutContext{Context: inner, deadline: c.Now().Add(d)}
+	t := c.NewTimer(d)
+	go func() {
+		select {
+		case <-t.C():
+			tc.mu.Lock()
+			tc.expired = true
+			tc.mu.Unlock()
+			cancel()
+		case <-inner.Done():
+			t.Stop()
+		}
+	}()
+	return tc, cancel
+}
+
+type timeoutContext struct {
+	context.Context
+	deadline time.Time
+
+	mu      sync.Mutex
+	expired bool
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	if d, ok := c.Context.Deadline(); ok && d.Before(c.deadline) {
+		return d, true
+	}
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+// WaitN blocks until lim permits n events, measuring time on clock c so that
+// rate limits follow a Fake clock in tests
+func WaitN(ctx context.Context, c Clock, lim *rate.Limiter, n int) error {
+	c = Default(c)
+	if _, ok := c.(systemClock); ok {
+		return lim.WaitN(ctx, n)
+	}
+
+	r := lim.ReserveN(c.Now(), n)
+	if !r.OK() {
+		return fmt.Errorf("rate: wait(n=%d) exceeds limiter's burst %d", n, lim.Burst())
+	}
+	if err := Sleep(ctx, c, r.DelayFrom(c.Now())); err != nil {
+		r.CancelAt(c.Now())
+		return err
+	}
+	return nil
+}
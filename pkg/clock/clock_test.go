@@ -0,0 +1,86 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestFake_TimersFireOnAdvance(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1000, 0)
+	f := NewFake(start)
+	short := f.NewTimer(time.Second)
+	long := f.NewTimer(time.Minute)
+
+	f.Advance(2 * time.Second)
+	select {
+	case got := <-short.C():
+		if !got.Equal(start.Add(2 * time.Second)) {
+			t.Errorf("timer fired at %v", got)
+		}
+	default:
+		t.Fatal("expected short timer to fire")
+	}
+	select {
+	case <-long.C():
+		t.Fatal("long timer fired early")
+	default:
+	}
+	if !long.Stop() || f.Timers() != 0 {
+		t.Error("expected Stop to remove the pending timer")
+	}
+}
+
+func TestWithTimeout_Fake(t *testing.T) {
+	t.Parallel()
+
+	f := NewFake(time.Unix(0, 0))
+	ctx, cancel := WithTimeout(context.Background(), f, 5*time.Second)
+	defer cancel()
+
+	f.BlockUntil(1)
+	f.Advance(4 * time.Second)
+	if ctx.Err() != nil {
+		t.Fatal("context expired before its deadline")
+	}
+	f.Advance(time.Second)
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", ctx.Err())
+	}
+}
+
+func TestWaitN_Fake(t *testing.T) {
+	t.Parallel()
+
+	f := NewFake(time.Unix(0, 0))
+	lim := rate.NewLimiter(rate.Every(time.Second), 1)
+
+	if err := WaitN(context.Background(), f, lim, 1); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- WaitN(context.Background(), f, lim, 1) }()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("second wait: %v", err)
+	}
+}
+
+func TestNewRNG_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	a, b := NewRNG(7), NewRNG(7)
+	for i := 0; i < 5; i++ {
+		if a.Float64() != b.Float64() {
+			t.Fatal("expected identical sequences for the same seed")
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package conversation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// AnonymizeRule replaces every match of Pattern with a placeholder such as
+// "[ACCOUNT_1]" for Label "ACCOUNT"
+type AnonymizeRule struct {
+	// Label names the kind of value, in upper case, e.g. "ACCOUNT"
+	Label string
+
+	// Pattern matches the values to replace
+	Pattern *regexp.Regexp
+}
+
+// DefaultAnonymizeRules are the built-in rules: email addresses, UUIDs,
+// IP addresses, phone numbers, prefixed IDs such as "cus_8f2kL0qP" and runs
+// of six or more digits
+var DefaultAnonymizeRules = []AnonymizeRule{
+	{Label: "EMAIL", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{Label: "ID", Pattern: regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)},
+	{Label: "IP", Pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+	{Label: "PHONE", Pattern: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`)},
+	{Label: "ID", Pattern: regexp.MustCompile(`\b[a-z]{2,10}_[A-Za-z0-9]{8,}\b`)},
+	{Label: "ID", Pattern: regexp.MustCompile(`\b\d{6,}\b`)},
+}
+
+// AnonymizeOptions configures Anonymize
+type AnonymizeOptions struct {
+	// Names are people, companies and other proper nouns to replace with
+	// "[NAME_n]", matched case-insensitively on word boundaries after the
+	// other rules, so "dana@example.com" stays one email. Every spelling of
+	// a name shares one placeholder and is restored as given here.
+	Names []string
+
+	// Rules are custom rules, applied before the built-ins and Names
+	Rules []AnonymizeRule
+
+	// SkipDefaults disables DefaultAnonymizeRules
+	SkipDefaults bool
+
+	// Map continues an earlier anonymization, so values keep their
+	// placeholders across conversations. It is updated in place.
+	// Default: a new, empty map
+	Map *AnonymizationMap
+}
+
+// AnonymizationMap records the original value behind each placeholder. Keep
+// it private: it is what makes an anonymized conversation reversible.
+type AnonymizationMap struct {
+	// Placeholders maps each placeholder, e.g. "[EMAIL_1]", to its value
+	Placeholders map[string]string `json:"placeholders"`
+
+	// byValue maps label and value to a placeholder, built on first use
+	byValue map[string]string
+}
+
+// NewAnonymizationMap creates an empty map
+func NewAnonymizationMap() *AnonymizationMap {
+	return &AnonymizationMap{Placeholders: make(map[string]string)}
+}
+
+// LoadAnonymizationMap reads a map written by Save
+func LoadAnonymizationMap(path string) (*AnonymizationMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := NewAnonymizationMap()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("anonymization map %s: %w", path, err)
+	}
+	if m.Placeholders == nil {
+		m.Placeholders = make(map[string]string)
+	}
+	return m, nil
+}
+
+// Save writes the map as JSON, readable only by the current user
+func (m *AnonymizationMap) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// placeholder returns the placeholder for value, assigning the next free
+// one for label on first sight
+func (m *AnonymizationMap) placeholder(label, value string) string {
+	if m.Placeholders == nil {
+		m.Placeholders = make(map[string]string)
+	}
+	if m.byValue == nil {
+		m.byValue = make(map[string]string, len(m.Placeholders))
+		for p, v := range m.Placeholders {
+			if l, _, ok := parsePlaceholder(p); ok {
+				m.byValue[l+"\x00"+v] = p
+			}
+		}
+	}
+	key := label + "\x00" + value
+	if p, ok := m.byValue[key]; ok {
+		return p
+	}
+	next := 1
+	for p := range m.Placeholders {
+		if l, n, ok := parsePlaceholder(p); ok && l == label && n >= next {
+			next = n + 1
+		}
+	}
+	p := "[" + label + "_" + strconv.Itoa(next) + "]"
+	m.Placeholders[p] = value
+	m.byValue[key] = p
+	return p
+}
+
+var placeholderPattern = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*)_(\d+)\]`)
+
+// parsePlaceholder splits "[EMAIL_3]" into "EMAIL" and 3
+func parsePlaceholder(p string) (string, int, bool) {
+	match := placeholderPattern.FindStringSubmatch(p)
+	if match == nil || match[0] != p {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(match[2])
+	return match[1], n, err == nil
+}
+
+// Anonymize returns a copy of c with names, emails, IDs and custom patterns
+// replaced by consistent placeholders, so a problematic transcript can be
+// shared for debugging. The same value always gets the same placeholder,
+// and Deanonymize with the returned map restores the original.
+//
+// Text, reasoning, tool call arguments, tool results, message names, the
+// title, the summary and string metadata are rewritten. Images and files
+// are kept as they are; reasoning signatures no longer match the rewritten
+// text, so an anonymized conversation should not be sent back to a model
+// that checks them.
+//
+// Example:
+//
+//	shared, mapping, err := conversation.Anonymize(c, conversation.AnonymizeOptions{
+//		Names: []string{"Dana Whitfield", "Acme Corp"},
+//		Rules: []conversation.AnonymizeRule{
+//			{Label: "ACCOUNT", Pattern: regexp.MustCompile(`ACC-\d+`)},
+//		},
+//	})
+//	err = mapping.Save("ticket-4411.map.json")
+func Anonymize(c *Conversation, opts AnonymizeOptions) (*Conversation, *AnonymizationMap, error) {
+	if c == nil {
+		return nil, nil, fmt.Errorf("conversation is required")
+	}
+	var rules []AnonymizeRule
+	for _, rule := range opts.Rules {
+		if rule.Label == "" || rule.Pattern == nil {
+			return nil, nil, fmt.Errorf("anonymize rule needs a label and a pattern")
+		}
+		if !placeholderPattern.MatchString("[" + rule.Label + "_1]") {
+			return nil, nil, fmt.Errorf("anonymize rule label %q must be upper case letters, digits and underscores", rule.Label)
+		}
+		rules = append(rules, rule)
+	}
+	if !opts.SkipDefaults {
+		rules = append(rules, DefaultAnonymizeRules...)
+	}
+	if names := namesRule(opts.Names); names != nil {
+		rules = append(rules, *names)
+	}
+	m := opts.Map
+	if m == nil {
+		m = NewAnonymizationMap()
+	}
+
+	canonical := make(map[string]string, len(opts.Names))
+	for _, name := range opts.Names {
+		canonical[strings.ToLower(name)] = name
+	}
+	anonymize := func(s string) string {
+		for _, rule := range rules {
+			s = replaceOutsidePlaceholders(s, rule.Pattern, func(value string) string {
+				if name, ok := canonical[strings.ToLower(value)]; ok && rule.Label == "NAME" {
+					value = name
+				}
+				return m.placeholder(rule.Label, value)
+			})
+		}
+		return s
+	}
+	return rewrite(c, anonymize), m, nil
+}
+
+// Deanonymize returns a copy of c with the placeholders in m replaced by
+// their original values. Placeholders missing from m are left as they are.
+func Deanonymize(c *Conversation, m *AnonymizationMap) (*Conversation, error) {
+	if c == nil {
+		return nil, fmt.Errorf("conversation is required")
+	}
+	if m == nil {
+		return nil, fmt.Errorf("anonymization map is required")
+	}
+	return rewrite(c, func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(p string) string {
+			if value, ok := m.Placeholders[p]; ok {
+				return value
+			}
+			return p
+		})
+	}), nil
+}
+
+// namesRule matches any of names as whole words, longest first so a full
+// name wins over a part of it
+func namesRule(names []string) *AnonymizeRule {
+	var quoted []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return &AnonymizeRule{
+		Label:   "NAME",
+		Pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`),
+	}
+}
+
+// replaceOutsidePlaceholders replaces matches of pattern in s, leaving
+// placeholders from earlier rules untouched
+func replaceOutsidePlaceholders(s string, pattern *regexp.Regexp, replace func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range placeholderPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(pattern.ReplaceAllStringFunc(s[last:span[0]], replace))
+		b.WriteString(s[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(pattern.ReplaceAllStringFunc(s[last:], replace))
+	return b.String()
+}
+
+// rewrite returns a copy of c with f applied to every text field
+func rewrite(c *Conversation, f func(string) string) *Conversation {
+	out := *c
+	out.Title = f(c.Title)
+	out.Summary = f(c.Summary)
+	if c.Metadata != nil {
+		out.Metadata = rewriteValue(c.Metadata, f).(map[string]any)
+	}
+	out.Messages = make([]types.Message, len(c.Messages))
+	for i, msg := range c.Messages {
+		msg.Name = f(msg.Name)
+		if msg.Content != nil {
+			content := make([]types.ContentPart, len(msg.Content))
+			for j, part := range msg.Content {
+				content[j] = rewritePart(part, f)
+			}
+			msg.Content = content
+		}
+		if msg.ToolCalls != nil {
+			calls := make([]types.ToolCall, len(msg.ToolCalls))
+			for j, call := range msg.ToolCalls {
+				if call.Arguments != nil {
+					call.Arguments = rewriteValue(call.Arguments, f).(map[string]interface{})
+				}
+				calls[j] = call
+			}
+			msg.ToolCalls = calls
+		}
+		out.Messages[i] = msg
+	}
+	return &out
+}
+
+func rewritePart(part types.ContentPart, f func(string) string) types.ContentPart {
+	switch p := part.(type) {
+	case types.TextContent:
+		p.Text = f(p.Text)
+		return p
+	case types.ReasoningContent:
+		p.Text = f(p.Text)
+		return p
+	case types.ToolResultContent:
+		p.Result = rewriteValue(p.Result, f)
+		p.Error = f(p.Error)
+		if p.Output != nil {
+			output := *p.Output
+			output.Value = rewriteValue(output.Value, f)
+			output.Reason = f(output.Reason)
+			if output.Content != nil {
+				blocks := make([]types.ToolResultContentBlock, len(output.Content))
+				for i, block := range output.Content {
+					if text, ok := block.(types.TextContentBlock); ok {
+						text.Text = f(text.Text)
+						block = text
+					}
+					blocks[i] = block
+				}
+				output.Content = blocks
+			}
+			p.Output = &output
+		}
+		return p
+	default:
+		return part
+	}
+}
+
+// rewriteValue applies f to the strings in a JSON-like value, copying maps
+// and slices rather than modifying them. Map keys are visited in order so
+// placeholders are numbered the same way on every run.
+func rewriteValue(v any, f func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return f(v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(map[string]any, len(v))
+		for _, k := range keys {
+			out[k] = rewriteValue(v[k], f)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = rewriteValue(item, f)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = f(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
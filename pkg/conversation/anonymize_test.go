@@ -0,0 +1,123 @@
+package conversation
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func ticketConversation() *Conversation {
+	return &Conversation{
+		ID:       "conv-7",
+		Title:    "Refund for Dana Whitfield",
+		Metadata: map[string]any{"customer": "dana@example.com", "tags": []any{"ACC-5521"}, "priority": 2},
+		Messages: []types.Message{
+			{Role: types.RoleUser, Name: "Dana Whitfield", Content: []types.ContentPart{
+				types.TextContent{Text: "Hi, I'm Dana Whitfield (dana@example.com, +1 415-555-0134). Account ACC-5521 was charged twice."},
+			}},
+			{
+				Role:      types.RoleAssistant,
+				Content:   []types.ContentPart{types.TextContent{Text: "Sorry dana, let me look up ACC-5521."}},
+				ToolCalls: []types.ToolCall{{ID: "call_1", ToolName: "lookup", Arguments: map[string]interface{}{"email": "dana@example.com"}}},
+			},
+			{Role: types.RoleTool, Content: []types.ContentPart{
+				types.ToolResultContent{ToolCallID: "call_1", ToolName: "lookup", Result: map[string]any{
+					"customer_id": "cus_8f2kL0qPz", "orders": []any{"4471902", "4471903"},
+				}},
+			}},
+		},
+	}
+}
+
+func TestAnonymize_ConsistentPlaceholders(t *testing.T) {
+	original := ticketConversation()
+	anon, m, err := Anonymize(original, AnonymizeOptions{
+		Names: []string{"Dana Whitfield", "Dana"},
+		Rules: []AnonymizeRule{{Label: "ACCOUNT", Pattern: regexp.MustCompile(`ACC-\d+`)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := anon.Messages[0].Content[0].(types.TextContent).Text
+	want := "Hi, I'm [NAME_1] ([EMAIL_1], [PHONE_1]). Account [ACCOUNT_1] was charged twice."
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	if got := anon.Messages[1].Content[0].(types.TextContent).Text; got != "Sorry [NAME_2], let me look up [ACCOUNT_1]." {
+		t.Errorf("reply = %q", got)
+	}
+	if anon.Title != "Refund for [NAME_1]" || anon.Messages[0].Name != "[NAME_1]" {
+		t.Errorf("title = %q, name = %q", anon.Title, anon.Messages[0].Name)
+	}
+	if got := anon.Messages[1].ToolCalls[0].Arguments["email"]; got != "[EMAIL_1]" {
+		t.Errorf("tool argument = %v", got)
+	}
+	result := anon.Messages[2].Content[0].(types.ToolResultContent).Result.(map[string]any)
+	if result["customer_id"] != "[ID_1]" || !reflect.DeepEqual(result["orders"], []any{"[ID_2]", "[ID_3]"}) {
+		t.Errorf("tool result = %v", result)
+	}
+	if anon.Metadata["customer"] != "[EMAIL_1]" || anon.Metadata["priority"] != 2 {
+		t.Errorf("metadata = %v", anon.Metadata)
+	}
+	if m.Placeholders["[NAME_2]"] != "Dana" {
+		t.Errorf("case variants should restore the configured spelling, got %q", m.Placeholders["[NAME_2]"])
+	}
+
+	if !strings.Contains(original.Messages[0].Content[0].(types.TextContent).Text, "dana@example.com") {
+		t.Error("Anonymize modified the original conversation")
+	}
+}
+
+func TestAnonymize_RoundTripThroughMapFile(t *testing.T) {
+	original := ticketConversation()
+	anon, m, err := Anonymize(original, AnonymizeOptions{Names: []string{"Dana Whitfield"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "map.json")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAnonymizationMap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Deanonymize(anon, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored, original) {
+		t.Errorf("restored conversation differs:\n got %+v\nwant %+v", restored, original)
+	}
+}
+
+func TestAnonymize_MapCarriesAcrossConversations(t *testing.T) {
+	m := NewAnonymizationMap()
+	first := &Conversation{Messages: []types.Message{{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "a@example.com"}}}}}
+	second := &Conversation{Messages: []types.Message{{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "b@example.com a@example.com"}}}}}
+
+	if _, _, err := Anonymize(first, AnonymizeOptions{Map: m}); err != nil {
+		t.Fatal(err)
+	}
+	anon, _, err := Anonymize(second, AnonymizeOptions{Map: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := anon.Messages[0].Content[0].(types.TextContent).Text; got != "[EMAIL_2] [EMAIL_1]" {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestAnonymize_InvalidRule(t *testing.T) {
+	_, _, err := Anonymize(ticketConversation(), AnonymizeOptions{
+		Rules: []AnonymizeRule{{Label: "account", Pattern: regexp.MustCompile(`x`)}},
+	})
+	if err == nil {
+		t.Fatal("expected error for lower case label")
+	}
+}
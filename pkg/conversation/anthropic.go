@@ -0,0 +1,354 @@
+package conversation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+type anthropicConversation struct {
+	System   json.RawMessage    `json:"system,omitempty"`
+	Messages []anthropicMessage `json:"messages"`
+}
+
+type anthropicMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type anthropicBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// thinking / redacted_thinking
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
+
+	// image / document
+	Source *anthropicSource `json:"source,omitempty"`
+	Title  string           `json:"title,omitempty"`
+
+	// tool_use
+	ID    string         `json:"id,omitempty"`
+	Name  string         `json:"name,omitempty"`
+	Input map[string]any `json:"input,omitempty"`
+
+	// tool_result
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// MarshalAnthropic serializes c as an Anthropic Messages request body:
+// {"system": "...", "messages": [...]}. System messages are joined into the
+// top-level system prompt, tool results are sent as tool_result blocks in
+// user messages, and consecutive messages with the same role are merged as
+// the API requires. Sources, generated files and provider metadata are
+// dropped; ID, Metadata and CreatedAt are not written.
+func MarshalAnthropic(c *Conversation) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("conversation is nil")
+	}
+
+	var system []string
+	type pending struct {
+		role   string
+		blocks []anthropicBlock
+	}
+	var messages []pending
+	for i, msg := range c.Messages {
+		if msg.Role == types.RoleSystem {
+			if text := joinText(msg.Content); text != "" {
+				system = append(system, text)
+			}
+			continue
+		}
+		role, blocks, err := toAnthropicBlocks(msg)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(messages); n > 0 && messages[n-1].role == role {
+			messages[n-1].blocks = append(messages[n-1].blocks, blocks...)
+			continue
+		}
+		messages = append(messages, pending{role: role, blocks: blocks})
+	}
+
+	doc := anthropicConversation{Messages: make([]anthropicMessage, 0, len(messages))}
+	if len(system) > 0 {
+		doc.System, _ = json.Marshal(strings.Join(system, "\n\n"))
+	}
+	for _, m := range messages {
+		content, err := json.Marshal(m.blocks)
+		if err != nil {
+			return nil, err
+		}
+		doc.Messages = append(doc.Messages, anthropicMessage{Role: m.role, Content: content})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func toAnthropicBlocks(msg types.Message) (string, []anthropicBlock, error) {
+	role := "user"
+	switch msg.Role {
+	case types.RoleUser, types.RoleTool:
+	case types.RoleAssistant:
+		role = "assistant"
+	default:
+		return "", nil, fmt.Errorf("unsupported role %q", msg.Role)
+	}
+
+	var blocks []anthropicBlock
+	for _, part := range msg.Content {
+		switch p := part.(type) {
+		case types.TextContent:
+			if p.Text != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: p.Text})
+			}
+		case types.ReasoningContent:
+			if p.RedactedData != "" {
+				blocks = append(blocks, anthropicBlock{Type: "redacted_thinking", Data: p.RedactedData})
+			} else {
+				blocks = append(blocks, anthropicBlock{Type: "thinking", Thinking: p.Text, Signature: p.Signature})
+			}
+		case types.ImageContent:
+			blocks = append(blocks, anthropicBlock{Type: "image", Source: anthropicMediaSource(p.MimeType, p.Image, p.URL)})
+		case types.FileContent:
+			blocks = append(blocks, anthropicBlock{
+				Type:   "document",
+				Source: anthropicMediaSource(p.MimeType, p.Data, ""),
+				Title:  p.Filename,
+			})
+		case types.ToolResultContent:
+			content, err := anthropicToolResultContent(p)
+			if err != nil {
+				return "", nil, err
+			}
+			blocks = append(blocks, anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: p.ToolCallID,
+				Content:   content,
+				IsError:   p.Error != "" || (p.Output != nil && p.Output.Type == types.ToolResultOutputError),
+			})
+		}
+	}
+	for _, tc := range msg.ToolCalls {
+		input := tc.Arguments
+		if input == nil {
+			input = map[string]any{}
+		}
+		blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.ToolName, Input: input})
+	}
+	return role, blocks, nil
+}
+
+func anthropicMediaSource(mediaType string, data []byte, url string) *anthropicSource {
+	if len(data) == 0 && url != "" {
+		return &anthropicSource{Type: "url", URL: url}
+	}
+	return &anthropicSource{Type: "base64", MediaType: mediaType, Data: base64.StdEncoding.EncodeToString(data)}
+}
+
+// anthropicToolResultContent keeps image blocks of structured tool output and
+// renders everything else as text
+func anthropicToolResultContent(tr types.ToolResultContent) (json.RawMessage, error) {
+	if tr.Error == "" && tr.Output != nil && tr.Output.Type == types.ToolResultOutputContent {
+		var blocks []anthropicBlock
+		for _, block := range tr.Output.Content {
+			switch b := block.(type) {
+			case types.TextContentBlock:
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: b.Text})
+			case types.ImageContentBlock:
+				blocks = append(blocks, anthropicBlock{Type: "image", Source: anthropicMediaSource(b.MediaType, b.Data, "")})
+			}
+		}
+		return json.Marshal(blocks)
+	}
+	return json.Marshal(toolResultText(tr))
+}
+
+// UnmarshalAnthropic parses an Anthropic Messages request body. The system
+// prompt becomes a leading system message, and user messages carrying
+// tool_result blocks are split into a tool message followed by any remaining
+// user content.
+func UnmarshalAnthropic(data []byte) (*Conversation, error) {
+	var doc anthropicConversation
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Anthropic messages: %w", err)
+	}
+
+	c := &Conversation{}
+	if system, err := anthropicSystemText(doc.System); err != nil {
+		return nil, err
+	} else if system != "" {
+		c.Messages = append(c.Messages, types.Message{
+			Role:    types.RoleSystem,
+			Content: []types.ContentPart{types.TextContent{Text: system}},
+		})
+	}
+
+	toolNames := make(map[string]string)
+	for i, am := range doc.Messages {
+		blocks, err := parseAnthropicContent(am.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		switch am.Role {
+		case "assistant":
+			c.Messages = append(c.Messages, fromAnthropicAssistant(blocks, toolNames))
+		case "user":
+			tool := types.Message{Role: types.RoleTool}
+			user := types.Message{Role: types.RoleUser}
+			for _, b := range blocks {
+				if b.Type == "tool_result" {
+					tool.Content = append(tool.Content, fromAnthropicToolResult(b, toolNames))
+				} else if part, ok := fromAnthropicBlock(b); ok {
+					user.Content = append(user.Content, part)
+				}
+			}
+			if len(tool.Content) > 0 {
+				c.Messages = append(c.Messages, tool)
+			}
+			if len(user.Content) > 0 {
+				c.Messages = append(c.Messages, user)
+			}
+		default:
+			return nil, fmt.Errorf("message %d: unsupported role %q", i, am.Role)
+		}
+	}
+	return c, nil
+}
+
+func anthropicSystemText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+	var blocks []anthropicBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return "", fmt.Errorf("invalid system prompt: %w", err)
+	}
+	var texts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// parseAnthropicContent accepts both the string shorthand and block arrays
+func parseAnthropicContent(raw json.RawMessage) ([]anthropicBlock, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []anthropicBlock{{Type: "text", Text: text}}, nil
+	}
+	var blocks []anthropicBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, fmt.Errorf("invalid content: %w", err)
+	}
+	return blocks, nil
+}
+
+func fromAnthropicAssistant(blocks []anthropicBlock, toolNames map[string]string) types.Message {
+	msg := types.Message{Role: types.RoleAssistant}
+	for _, b := range blocks {
+		if b.Type == "tool_use" {
+			toolNames[b.ID] = b.Name
+			msg.ToolCalls = append(msg.ToolCalls, types.ToolCall{ID: b.ID, ToolName: b.Name, Arguments: b.Input})
+			continue
+		}
+		if part, ok := fromAnthropicBlock(b); ok {
+			msg.Content = append(msg.Content, part)
+		}
+	}
+	return msg
+}
+
+func fromAnthropicBlock(b anthropicBlock) (types.ContentPart, bool) {
+	switch b.Type {
+	case "text":
+		return types.TextContent{Text: b.Text}, true
+	case "thinking":
+		return types.ReasoningContent{Text: b.Thinking, Signature: b.Signature}, true
+	case "redacted_thinking":
+		return types.ReasoningContent{RedactedData: b.Data}, true
+	case "image":
+		if b.Source == nil {
+			return nil, false
+		}
+		if b.Source.Type == "url" {
+			return types.ImageContent{URL: b.Source.URL}, true
+		}
+		data, err := base64.StdEncoding.DecodeString(b.Source.Data)
+		if err != nil {
+			return nil, false
+		}
+		return types.ImageContent{Image: data, MimeType: b.Source.MediaType}, true
+	case "document":
+		if b.Source == nil || b.Source.Type != "base64" {
+			return nil, false
+		}
+		data, err := base64.StdEncoding.DecodeString(b.Source.Data)
+		if err != nil {
+			return nil, false
+		}
+		return types.FileContent{Data: data, MimeType: b.Source.MediaType, Filename: b.Title}, true
+	default:
+		return nil, false
+	}
+}
+
+func fromAnthropicToolResult(b anthropicBlock, toolNames map[string]string) types.ToolResultContent {
+	part := types.ToolResultContent{ToolCallID: b.ToolUseID, ToolName: toolNames[b.ToolUseID]}
+
+	var text string
+	if err := json.Unmarshal(b.Content, &text); err != nil {
+		var blocks []anthropicBlock
+		_ = json.Unmarshal(b.Content, &blocks)
+		output := &types.ToolResultOutput{Type: types.ToolResultOutputContent}
+		var texts []string
+		for _, inner := range blocks {
+			switch inner.Type {
+			case "text":
+				texts = append(texts, inner.Text)
+				output.Content = append(output.Content, types.TextContentBlock{Text: inner.Text})
+			case "image":
+				if img, ok := fromAnthropicBlock(inner); ok {
+					ic := img.(types.ImageContent)
+					output.Content = append(output.Content, types.ImageContentBlock{Data: ic.Image, MediaType: ic.MimeType})
+				}
+			}
+		}
+		text = strings.Join(texts, "\n")
+		if len(output.Content) > len(texts) {
+			part.Output = output
+		}
+	}
+
+	if b.IsError {
+		part.Error = text
+	} else if part.Output == nil {
+		part.Result = text
+	}
+	return part
+}
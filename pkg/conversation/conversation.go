@@ -0,0 +1,115 @@
+// Package conversation serializes conversations and agent runs to and from
+// portable formats: a stable, lossless go-ai JSON format, the OpenAI Chat
+// Completions message format, and the Anthropic Messages format.
+//
+// The provider formats cannot represent everything a go-ai message can hold
+// (reasoning signatures, sources, provider metadata), so exporting to them is
+// lossy. Use FormatGoAI for archival and round-tripping.
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Format identifies a serialization format
+type Format string
+
+const (
+	// FormatGoAI is the stable, versioned go-ai JSON format
+	FormatGoAI Format = "go-ai"
+
+	// FormatOpenAI is an OpenAI Chat Completions message list, wrapped as
+	// {"messages": [...]} like a fine-tuning JSONL line
+	FormatOpenAI Format = "openai"
+
+	// FormatAnthropic is an Anthropic Messages request body:
+	// {"system": "...", "messages": [...]}
+	FormatAnthropic Format = "anthropic"
+)
+
+// Conversation is a message history with optional identifying metadata
+type Conversation struct {
+	// ID identifies the conversation (optional)
+	ID string
+
+	// Messages in chronological order, including system messages
+	Messages []types.Message
+
+	// Metadata holds arbitrary JSON-serializable attributes
+	Metadata map[string]any
+
+	// CreatedAt is when the conversation started (optional)
+	CreatedAt time.Time
+
+	// Title is a short label for the conversation (see TitleSummaryHook)
+	Title string
+
+	// Summary condenses Messages[:SummaryThrough], so long conversations can
+	// be listed, searched or resumed without replaying every message
+	Summary        string
+	SummaryThrough int
+}
+
+// Export serializes c in the given format
+func Export(c *Conversation, format Format) ([]byte, error) {
+	switch format {
+	case FormatGoAI:
+		return MarshalJSON(c)
+	case FormatOpenAI:
+		return MarshalOpenAI(c)
+	case FormatAnthropic:
+		return MarshalAnthropic(c)
+	default:
+		return nil, fmt.Errorf("unknown conversation format %q", format)
+	}
+}
+
+// Import parses data in the given format
+func Import(data []byte, format Format) (*Conversation, error) {
+	switch format {
+	case FormatGoAI:
+		return UnmarshalJSON(data)
+	case FormatOpenAI:
+		return UnmarshalOpenAI(data)
+	case FormatAnthropic:
+		return UnmarshalAnthropic(data)
+	default:
+		return nil, fmt.Errorf("unknown conversation format %q", format)
+	}
+}
+
+// FromAgentResult builds a conversation from the messages an agent was run
+// with and the steps it took. Each step becomes an assistant message (text
+// and tool calls) followed by one tool message per tool result. Run
+// statistics are recorded in Metadata.
+func FromAgentResult(input []types.Message, result *agent.AgentResult) *Conversation {
+	c := &Conversation{Messages: append([]types.Message(nil), input...)}
+	if result == nil {
+		return c
+	}
+	c.Messages = append(c.Messages, MessagesFromSteps(result.Steps)...)
+
+	c.Metadata = map[string]any{
+		"finishReason": string(result.FinishReason),
+		"steps":        len(result.Steps),
+	}
+	if result.StopReason != "" {
+		c.Metadata["stopReason"] = result.StopReason
+	}
+	if result.Usage.TotalTokens != nil {
+		c.Metadata["totalTokens"] = *result.Usage.TotalTokens
+	}
+	return c
+}
+
+// MessagesFromSteps converts generation steps into assistant and tool
+// messages, formatted the same way as GenerateText's response messages
+func MessagesFromSteps(steps []types.StepResult) []types.Message {
+	return ai.ResponseMessages(context.Background(), nil, steps)
+}
@@ -0,0 +1,242 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func sampleConversation() *Conversation {
+	return &Conversation{
+		ID:        "conv-1",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:  map[string]any{"user": "u1"},
+		Messages: []types.Message{
+			{Role: types.RoleSystem, Content: []types.ContentPart{types.TextContent{Text: "Be brief."}}},
+			{Role: types.RoleUser, Content: []types.ContentPart{
+				types.TextContent{Text: "What's in this image, and the weather?"},
+				types.ImageContent{Image: []byte{0x89, 'P', 'N', 'G'}, MimeType: "image/png"},
+			}},
+			{
+				Role: types.RoleAssistant,
+				Content: []types.ContentPart{
+					types.ReasoningContent{Text: "Need the weather tool.", Signature: "sig"},
+					types.TextContent{Text: "Checking."},
+				},
+				ToolCalls: []types.ToolCall{{ID: "call_1", ToolName: "weather", Arguments: map[string]interface{}{"city": "Paris"}}},
+			},
+			{Role: types.RoleTool, Content: []types.ContentPart{
+				types.ToolResultContent{ToolCallID: "call_1", ToolName: "weather", Result: "sunny"},
+			}},
+			{Role: types.RoleAssistant, Content: []types.ContentPart{types.TextContent{Text: "A logo. It's sunny."}}},
+		},
+	}
+}
+
+func TestJSON_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	c := sampleConversation()
+	c.Messages = append(c.Messages, types.Message{Role: types.RoleAssistant, Content: []types.ContentPart{
+		types.SourceContent{SourceType: "url", ID: "s1", URL: "https://example.com"},
+		types.GeneratedFileContent{MediaType: "image/png", Data: []byte{1, 2}},
+	}}, types.Message{Role: types.RoleTool, Content: []types.ContentPart{
+		types.ToolResultContent{ToolCallID: "call_2", ToolName: "screenshot", Output: &types.ToolResultOutput{
+			Type: types.ToolResultOutputContent,
+			Content: []types.ToolResultContentBlock{
+				types.TextContentBlock{Text: "captured"},
+				types.ImageContentBlock{Data: []byte{3}, MediaType: "image/png"},
+			},
+		}},
+	}})
+
+	data, err := Export(c, FormatGoAI)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	got, err := Import(data, FormatGoAI)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("round trip mismatch\n got: %#v\nwant: %#v", got, c)
+	}
+}
+
+func TestJSON_RejectsUnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := UnmarshalJSON([]byte(`{"format":"go-ai.conversation","version":99,"messages":[]}`)); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+	if _, err := UnmarshalJSON([]byte(`{"messages":[]}`)); err == nil {
+		t.Error("expected error for missing format")
+	}
+}
+
+func TestOpenAI_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data, err := MarshalOpenAI(sampleConversation())
+	if err != nil {
+		t.Fatalf("MarshalOpenAI: %v", err)
+	}
+	if !strings.Contains(string(data), `"tool_call_id": "call_1"`) ||
+		!strings.Contains(string(data), `"arguments": "{\"city\":\"Paris\"}"`) ||
+		!strings.Contains(string(data), `data:image/png;base64,`) {
+		t.Fatalf("unexpected OpenAI output:\n%s", data)
+	}
+
+	got, err := UnmarshalOpenAI(data)
+	if err != nil {
+		t.Fatalf("UnmarshalOpenAI: %v", err)
+	}
+	if len(got.Messages) != 5 {
+		t.Fatalf("got %d messages, want 5", len(got.Messages))
+	}
+	if img, ok := got.Messages[1].Content[1].(types.ImageContent); !ok || img.MimeType != "image/png" || len(img.Image) != 4 {
+		t.Errorf("image not restored: %#v", got.Messages[1].Content[1])
+	}
+	// Reasoning has no OpenAI chat representation
+	if want := []types.ContentPart{types.TextContent{Text: "Checking."}}; !reflect.DeepEqual(got.Messages[2].Content, want) {
+		t.Errorf("assistant content = %#v", got.Messages[2].Content)
+	}
+	if call := got.Messages[2].ToolCalls[0]; call.ToolName != "weather" || call.Arguments["city"] != "Paris" {
+		t.Errorf("tool call = %#v", call)
+	}
+	want := types.ToolResultContent{ToolCallID: "call_1", ToolName: "weather", Result: "sunny"}
+	if !reflect.DeepEqual(got.Messages[3].Content[0], want) {
+		t.Errorf("tool result = %#v", got.Messages[3].Content[0])
+	}
+}
+
+func TestOpenAI_ImportsBareArrayAndMergesToolMessages(t *testing.T) {
+	t.Parallel()
+
+	data := `[
+		{"role":"developer","content":"Rules"},
+		{"role":"assistant","content":null,"tool_calls":[
+			{"id":"a","type":"function","function":{"name":"x","arguments":"{}"}},
+			{"id":"b","type":"function","function":{"name":"y","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"a","content":"1"},
+		{"role":"tool","tool_call_id":"b","content":[{"type":"text","text":"2"}]}
+	]`
+	got, err := UnmarshalOpenAI([]byte(data))
+	if err != nil {
+		t.Fatalf("UnmarshalOpenAI: %v", err)
+	}
+	if len(got.Messages) != 3 || got.Messages[0].Role != types.RoleSystem {
+		t.Fatalf("unexpected messages: %#v", got.Messages)
+	}
+	results := got.Messages[2].Content
+	if len(results) != 2 || results[1].(types.ToolResultContent).ToolName != "y" || results[1].(types.ToolResultContent).Result != "2" {
+		t.Errorf("tool results = %#v", results)
+	}
+}
+
+func TestAnthropic_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data, err := MarshalAnthropic(sampleConversation())
+	if err != nil {
+		t.Fatalf("MarshalAnthropic: %v", err)
+	}
+	var doc struct {
+		System   string `json:"system"`
+		Messages []struct {
+			Role    string           `json:"role"`
+			Content []map[string]any `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, data)
+	}
+	if doc.System != "Be brief." {
+		t.Errorf("system = %q", doc.System)
+	}
+	roles := make([]string, len(doc.Messages))
+	for i, m := range doc.Messages {
+		roles[i] = m.Role
+	}
+	if want := []string{"user", "assistant", "user", "assistant"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+	if last := doc.Messages[1].Content[2]; last["type"] != "tool_use" || last["name"] != "weather" {
+		t.Errorf("tool_use block = %v", last)
+	}
+
+	got, err := UnmarshalAnthropic(data)
+	if err != nil {
+		t.Fatalf("UnmarshalAnthropic: %v", err)
+	}
+	want := sampleConversation()
+	want.ID, want.Metadata, want.CreatedAt = "", nil, time.Time{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestFromAgentResult(t *testing.T) {
+	t.Parallel()
+
+	total := int64(42)
+	input := []types.Message{{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "hi"}}}}
+	result := &agent.AgentResult{
+		Text:         "done",
+		FinishReason: types.FinishReasonStop,
+		Usage:        types.Usage{TotalTokens: &total},
+		Steps: []types.StepResult{
+			{
+				ToolCalls:   []types.ToolCall{{ID: "c1", ToolName: "lookup"}},
+				ToolResults: []types.ToolResult{{ToolCallID: "c1", ToolName: "lookup", Result: "ok"}},
+			},
+			{Text: "done"},
+		},
+	}
+
+	c := FromAgentResult(input, result)
+	roles := make([]types.MessageRole, len(c.Messages))
+	for i, m := range c.Messages {
+		roles[i] = m.Role
+	}
+	want := []types.MessageRole{types.RoleUser, types.RoleAssistant, types.RoleTool, types.RoleAssistant}
+	if !reflect.DeepEqual(roles, want) {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+	if c.Metadata["totalTokens"] != int64(42) || c.Metadata["steps"] != 2 {
+		t.Errorf("metadata = %v", c.Metadata)
+	}
+}
+
+func TestMessagesFromSteps_MatchesResponseMessages(t *testing.T) {
+	t.Parallel()
+
+	steps := []types.StepResult{{
+		ToolCalls: []types.ToolCall{{ID: "c1", ToolName: "lookup"}, {ID: "c2", ToolName: "lookup"}},
+		ToolResults: []types.ToolResult{
+			{ToolCallID: "c1", ToolName: "lookup", Result: "ok"},
+			{ToolCallID: "c2", ToolName: "lookup", Error: errors.New("not found")},
+		},
+	}}
+
+	got := MessagesFromSteps(steps)
+	want := ai.ResponseMessages(context.Background(), nil, steps)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MessagesFromSteps = %#v, want %#v", got, want)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected an assistant message and two tool messages, got %d", len(got))
+	}
+	failed := got[2].Content[0].(types.ToolResultContent)
+	if failed.Error == "" || failed.Output == nil || failed.Output.Type != types.ToolResultOutputError {
+		t.Errorf("expected the failed result to be reported as a tool error, got %#v", failed)
+	}
+}
@@ -0,0 +1,294 @@
+package conversation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// formatName and formatVersion identify documents written by MarshalJSON.
+// The version is bumped only for incompatible changes; new optional fields
+// are added without a bump.
+const (
+	formatName    = "go-ai.conversation"
+	formatVersion = 1
+)
+
+// jsonConversation is the top-level go-ai document
+type jsonConversation struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	ID        string         `json:"id,omitempty"`
+	CreatedAt *time.Time     `json:"createdAt,omitempty"`
+	Title     string         `json:"title,omitempty"`
+	Summary   *jsonSummary   `json:"summary,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Messages  []jsonMessage  `json:"messages"`
+}
+
+// jsonSummary is a rolling summary of the first Through messages
+type jsonSummary struct {
+	Text    string `json:"text"`
+	Through int    `json:"through"`
+}
+
+type jsonMessage struct {
+	Role      types.MessageRole `json:"role"`
+	Name      string            `json:"name,omitempty"`
+	Content   []json.RawMessage `json:"content"`
+	ToolCalls []types.ToolCall  `json:"toolCalls,omitempty"`
+}
+
+// jsonToolResult mirrors types.ToolResultContent with discriminated output
+// content blocks
+type jsonToolResult struct {
+	ToolCallID string          `json:"toolCallId"`
+	ToolName   string          `json:"toolName"`
+	Result     any             `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Output     *jsonToolOutput `json:"output,omitempty"`
+}
+
+type jsonToolOutput struct {
+	Type    types.ToolResultOutputType `json:"type"`
+	Value   any                        `json:"value,omitempty"`
+	Content []json.RawMessage          `json:"content,omitempty"`
+	Reason  string                     `json:"reason,omitempty"`
+}
+
+// MarshalJSON serializes c in the stable go-ai JSON format. Every message
+// field and content part type is preserved, so UnmarshalJSON reproduces the
+// conversation exactly (tool results and arbitrary values come back as
+// generic JSON values).
+func MarshalJSON(c *Conversation) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("conversation is nil")
+	}
+	doc := jsonConversation{
+		Format:   formatName,
+		Version:  formatVersion,
+		ID:       c.ID,
+		Title:    c.Title,
+		Metadata: c.Metadata,
+		Messages: make([]jsonMessage, 0, len(c.Messages)),
+	}
+	if c.Summary != "" {
+		doc.Summary = &jsonSummary{Text: c.Summary, Through: c.SummaryThrough}
+	}
+	if !c.CreatedAt.IsZero() {
+		createdAt := c.CreatedAt
+		doc.CreatedAt = &createdAt
+	}
+	for i, msg := range c.Messages {
+		jm := jsonMessage{Role: msg.Role, Name: msg.Name, ToolCalls: msg.ToolCalls, Content: []json.RawMessage{}}
+		for _, part := range msg.Content {
+			raw, err := encodePart(part)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+			jm.Content = append(jm.Content, raw)
+		}
+		doc.Messages = append(doc.Messages, jm)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// UnmarshalJSON parses a conversation written by MarshalJSON
+func UnmarshalJSON(data []byte) (*Conversation, error) {
+	var doc jsonConversation
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid conversation JSON: %w", err)
+	}
+	if doc.Format != formatName {
+		return nil, fmt.Errorf("unexpected format %q, want %q", doc.Format, formatName)
+	}
+	if doc.Version < 1 || doc.Version > formatVersion {
+		return nil, fmt.Errorf("unsupported conversation format version %d", doc.Version)
+	}
+
+	c := &Conversation{ID: doc.ID, Title: doc.Title, Metadata: doc.Metadata}
+	if doc.Summary != nil {
+		c.Summary, c.SummaryThrough = doc.Summary.Text, doc.Summary.Through
+	}
+	if doc.CreatedAt != nil {
+		c.CreatedAt = *doc.CreatedAt
+	}
+	for i, jm := range doc.Messages {
+		msg := types.Message{Role: jm.Role, Name: jm.Name, ToolCalls: jm.ToolCalls}
+		for _, raw := range jm.Content {
+			part, err := decodePart(raw)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+			msg.Content = append(msg.Content, part)
+		}
+		c.Messages = append(c.Messages, msg)
+	}
+	return c, nil
+}
+
+// partKind returns the discriminator for a content part. It differs from
+// ContentType where that is ambiguous (GeneratedFileContent reports "file").
+func partKind(part types.ContentPart) (string, bool) {
+	switch part.(type) {
+	case types.TextContent:
+		return "text", true
+	case types.ReasoningContent:
+		return "reasoning", true
+	case types.ImageContent:
+		return "image", true
+	case types.FileContent:
+		return "file", true
+	case types.SourceContent:
+		return "source", true
+	case types.GeneratedFileContent:
+		return "generated-file", true
+	case types.CustomContent:
+		return "custom", true
+	case types.ReasoningFileContent:
+		return "reasoning-file", true
+	case types.ToolResultContent:
+		return "tool-result", true
+	default:
+		return "", false
+	}
+}
+
+func encodePart(part types.ContentPart) (json.RawMessage, error) {
+	kind, ok := partKind(part)
+	if !ok {
+		return nil, fmt.Errorf("unsupported content part %T", part)
+	}
+	var value any = part
+	if tr, ok := part.(types.ToolResultContent); ok {
+		jtr := jsonToolResult{ToolCallID: tr.ToolCallID, ToolName: tr.ToolName, Result: tr.Result, Error: tr.Error}
+		if tr.Output != nil {
+			jtr.Output = &jsonToolOutput{Type: tr.Output.Type, Value: tr.Output.Value, Reason: tr.Output.Reason}
+			for _, block := range tr.Output.Content {
+				raw, err := withType(block.ToolResultContentType(), block)
+				if err != nil {
+					return nil, err
+				}
+				jtr.Output.Content = append(jtr.Output.Content, raw)
+			}
+		}
+		value = jtr
+	}
+	return withType(kind, value)
+}
+
+// withType encodes v as a JSON object with an added "type" discriminator
+func withType(kind string, v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(kind)
+	return json.Marshal(fields)
+}
+
+func decodePart(raw json.RawMessage) (types.ContentPart, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch head.Type {
+	case "text":
+		var p types.TextContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "reasoning":
+		var p types.ReasoningContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "image":
+		var p types.ImageContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "file":
+		var p types.FileContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "source":
+		var p types.SourceContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "generated-file":
+		var p types.GeneratedFileContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "custom":
+		var p types.CustomContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "reasoning-file":
+		var p types.ReasoningFileContent
+		err = json.Unmarshal(raw, &p)
+		return p, err
+	case "tool-result":
+		return decodeToolResult(raw)
+	default:
+		return nil, fmt.Errorf("unknown content part type %q", head.Type)
+	}
+}
+
+func decodeToolResult(raw json.RawMessage) (types.ContentPart, error) {
+	var jtr jsonToolResult
+	if err := json.Unmarshal(raw, &jtr); err != nil {
+		return nil, err
+	}
+	part := types.ToolResultContent{ToolCallID: jtr.ToolCallID, ToolName: jtr.ToolName, Result: jtr.Result, Error: jtr.Error}
+	if jtr.Output == nil {
+		return part, nil
+	}
+	part.Output = &types.ToolResultOutput{Type: jtr.Output.Type, Value: jtr.Output.Value, Reason: jtr.Output.Reason}
+	for _, rawBlock := range jtr.Output.Content {
+		block, err := decodeBlock(rawBlock)
+		if err != nil {
+			return nil, err
+		}
+		part.Output.Content = append(part.Output.Content, block)
+	}
+	return part, nil
+}
+
+func decodeBlock(raw json.RawMessage) (types.ToolResultContentBlock, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch head.Type {
+	case "text":
+		var b types.TextContentBlock
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	case "image":
+		var b types.ImageContentBlock
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	case "file":
+		var b types.FileContentBlock
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	case "custom":
+		var b types.CustomContentBlock
+		err = json.Unmarshal(raw, &b)
+		return b, err
+	default:
+		return nil, fmt.Errorf("unknown tool result block type %q", head.Type)
+	}
+}
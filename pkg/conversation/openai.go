@@ -0,0 +1,337 @@
+package conversation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+type openAIConversation struct {
+	Messages []openAIMessage `json:"messages"`
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    json.RawMessage  `json:"content,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+	File     *openAIFile     `json:"file,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+type openAIFile struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+}
+
+// MarshalOpenAI serializes c as OpenAI Chat Completions messages wrapped in
+// {"messages": [...]}. Tool results become one "tool" message per result.
+// Reasoning, sources, generated files and provider metadata have no OpenAI
+// chat equivalent and are dropped; ID, Metadata and CreatedAt are not written.
+func MarshalOpenAI(c *Conversation) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("conversation is nil")
+	}
+	doc := openAIConversation{Messages: []openAIMessage{}}
+	for i, msg := range c.Messages {
+		messages, err := toOpenAIMessages(msg)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		doc.Messages = append(doc.Messages, messages...)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func toOpenAIMessages(msg types.Message) ([]openAIMessage, error) {
+	switch msg.Role {
+	case types.RoleTool:
+		var out []openAIMessage
+		for _, part := range msg.Content {
+			tr, ok := part.(types.ToolResultContent)
+			if !ok {
+				continue
+			}
+			content, _ := json.Marshal(toolResultText(tr))
+			out = append(out, openAIMessage{Role: "tool", ToolCallID: tr.ToolCallID, Content: content})
+		}
+		return out, nil
+
+	case types.RoleUser:
+		om := openAIMessage{Role: "user", Name: msg.Name}
+		var parts []openAIPart
+		simple := true
+		for _, part := range msg.Content {
+			switch p := part.(type) {
+			case types.TextContent:
+				parts = append(parts, openAIPart{Type: "text", Text: p.Text})
+			case types.ImageContent:
+				url := p.URL
+				if url == "" {
+					url = dataURL(p.MimeType, p.Image)
+				}
+				parts = append(parts, openAIPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+				simple = false
+			case types.FileContent:
+				parts = append(parts, openAIPart{Type: "file", File: &openAIFile{
+					Filename: p.Filename,
+					FileData: dataURL(p.MimeType, p.Data),
+				}})
+				simple = false
+			}
+		}
+		var err error
+		if simple {
+			om.Content, err = json.Marshal(joinText(msg.Content))
+		} else {
+			om.Content, err = json.Marshal(parts)
+		}
+		return []openAIMessage{om}, err
+
+	case types.RoleSystem, types.RoleAssistant:
+		om := openAIMessage{Role: string(msg.Role), Name: msg.Name}
+		text := joinText(msg.Content)
+		if text != "" || len(msg.ToolCalls) == 0 {
+			om.Content, _ = json.Marshal(text)
+		}
+		for _, tc := range msg.ToolCalls {
+			args, err := json.Marshal(tc.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("tool call %s: %w", tc.ID, err)
+			}
+			call := openAIToolCall{ID: tc.ID, Type: "function"}
+			call.Function.Name = tc.ToolName
+			call.Function.Arguments = string(args)
+			om.ToolCalls = append(om.ToolCalls, call)
+		}
+		return []openAIMessage{om}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported role %q", msg.Role)
+	}
+}
+
+// UnmarshalOpenAI parses OpenAI Chat Completions messages, either a bare JSON
+// array or an object with a "messages" field (a request body or fine-tuning
+// line). "developer" messages are imported as system messages.
+func UnmarshalOpenAI(data []byte) (*Conversation, error) {
+	var messages []openAIMessage
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("invalid OpenAI messages: %w", err)
+		}
+	} else {
+		var doc openAIConversation
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid OpenAI messages: %w", err)
+		}
+		messages = doc.Messages
+	}
+
+	c := &Conversation{}
+	toolNames := make(map[string]string)
+	for i, om := range messages {
+		msg, err := fromOpenAIMessage(om, toolNames)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		// Consecutive tool messages answer the same assistant turn
+		if n := len(c.Messages); msg.Role == types.RoleTool && n > 0 && c.Messages[n-1].Role == types.RoleTool {
+			c.Messages[n-1].Content = append(c.Messages[n-1].Content, msg.Content...)
+			continue
+		}
+		c.Messages = append(c.Messages, msg)
+	}
+	return c, nil
+}
+
+func fromOpenAIMessage(om openAIMessage, toolNames map[string]string) (types.Message, error) {
+	msg := types.Message{Name: om.Name}
+	switch om.Role {
+	case "system", "developer":
+		msg.Role = types.RoleSystem
+	case "user":
+		msg.Role = types.RoleUser
+	case "assistant":
+		msg.Role = types.RoleAssistant
+	case "tool":
+		text, _, err := parseOpenAIContent(om.Content)
+		if err != nil {
+			return msg, err
+		}
+		msg.Role = types.RoleTool
+		msg.Content = []types.ContentPart{types.ToolResultContent{
+			ToolCallID: om.ToolCallID,
+			ToolName:   toolNames[om.ToolCallID],
+			Result:     text,
+		}}
+		return msg, nil
+	default:
+		return msg, fmt.Errorf("unsupported role %q", om.Role)
+	}
+
+	_, parts, err := parseOpenAIContent(om.Content)
+	if err != nil {
+		return msg, err
+	}
+	msg.Content = parts
+
+	for _, call := range om.ToolCalls {
+		var args map[string]interface{}
+		if call.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				return msg, fmt.Errorf("tool call %s has invalid arguments: %w", call.ID, err)
+			}
+		}
+		toolNames[call.ID] = call.Function.Name
+		msg.ToolCalls = append(msg.ToolCalls, types.ToolCall{ID: call.ID, ToolName: call.Function.Name, Arguments: args})
+	}
+	return msg, nil
+}
+
+// parseOpenAIContent handles both string and content-part array forms,
+// returning the concatenated text and the converted parts
+func parseOpenAIContent(raw json.RawMessage) (string, []types.ContentPart, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return "", nil, nil
+		}
+		return text, []types.ContentPart{types.TextContent{Text: text}}, nil
+	}
+
+	var parts []openAIPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, fmt.Errorf("invalid content: %w", err)
+	}
+	var out []types.ContentPart
+	var texts []string
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			texts = append(texts, p.Text)
+			out = append(out, types.TextContent{Text: p.Text})
+		case "image_url":
+			if p.ImageURL == nil {
+				continue
+			}
+			img := types.ImageContent{URL: p.ImageURL.URL}
+			if mediaType, data, ok := parseDataURL(p.ImageURL.URL); ok {
+				img = types.ImageContent{Image: data, MimeType: mediaType}
+			}
+			out = append(out, img)
+		case "file":
+			if p.File == nil {
+				continue
+			}
+			if mediaType, data, ok := parseDataURL(p.File.FileData); ok {
+				out = append(out, types.FileContent{Data: data, MimeType: mediaType, Filename: p.File.Filename})
+			}
+		}
+	}
+	return strings.Join(texts, ""), out, nil
+}
+
+// joinText concatenates the text parts of a message
+func joinText(parts []types.ContentPart) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		if t, ok := part.(types.TextContent); ok {
+			sb.WriteString(t.Text)
+		}
+	}
+	return sb.String()
+}
+
+// toolResultText renders a tool result as the string content providers
+// expect for tool messages
+func toolResultText(tr types.ToolResultContent) string {
+	if tr.Error != "" {
+		return tr.Error
+	}
+	if tr.Output != nil {
+		switch tr.Output.Type {
+		case types.ToolResultOutputContent:
+			var texts []string
+			for _, block := range tr.Output.Content {
+				if t, ok := block.(types.TextContentBlock); ok {
+					texts = append(texts, t.Text)
+				}
+			}
+			return strings.Join(texts, "\n")
+		case types.ToolResultOutputExecutionDenied:
+			if tr.Output.Reason != "" {
+				return tr.Output.Reason
+			}
+			return "Tool execution denied."
+		default:
+			return valueText(tr.Output.Value)
+		}
+	}
+	return valueText(tr.Result)
+}
+
+func valueText(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func dataURL(mediaType string, data []byte) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// parseDataURL decodes a base64 data URL
+func parseDataURL(url string) (mediaType string, data []byte, ok bool) {
+	rest, found := strings.CutPrefix(url, "data:")
+	if !found {
+		return "", nil, false
+	}
+	header, payload, found := strings.Cut(rest, ",")
+	if !found {
+		return "", nil, false
+	}
+	mediaType, found = strings.CutSuffix(header, ";base64")
+	if !found {
+		return "", nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, false
+	}
+	return mediaType, data, true
+}
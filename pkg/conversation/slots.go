@@ -0,0 +1,295 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// SlotsMetadataKey is the Conversation.Metadata key holding the fields a
+// SlotFiller has collected so far
+const SlotsMetadataKey = "slots"
+
+// SlotFillerOptions configures a SlotFiller
+type SlotFillerOptions struct {
+	// Model extracts field values and words the questions (required)
+	Model provider.LanguageModel
+
+	// Chats stores the conversation and the fields collected so far
+	// (required)
+	Chats *ChatStore
+
+	// Schema describes the form; its required fields are asked for.
+	// Default: ai.SchemaFor[T]()
+	Schema schema.Schema
+
+	// Instructions describe the task to the model, e.g. "Book a table at
+	// Luigi's. Opening hours are 17:00-23:00."
+	Instructions string
+}
+
+// SlotFiller fills a T over a multi-turn conversation: each turn it
+// extracts the values the user gave, validates them against the schema,
+// and asks only for the required fields that are still missing or
+// invalid, until T is complete.
+//
+// Example:
+//
+//	type Booking struct {
+//		Name   string `json:"name"`
+//		Date   string `json:"date" jsonschema:"description=YYYY-MM-DD"`
+//		Guests int    `json:"guests" jsonschema:"minimum=1,maximum=12"`
+//		Notes  string `json:"notes,omitempty"`
+//	}
+//
+//	filler := conversation.NewSlotFiller[Booking](conversation.SlotFillerOptions{Model: model, Chats: chats})
+//	turn, err := filler.Respond(ctx, chatID, "A table for 4 on Friday please")
+//	if turn.Complete {
+//		book(turn.Value)
+//	} else {
+//		say(turn.Reply) // e.g. "Sure! What name should the booking be under?"
+//	}
+type SlotFiller[T any] struct {
+	opts SlotFillerOptions
+}
+
+// NewSlotFiller creates a SlotFiller for T
+func NewSlotFiller[T any](opts SlotFillerOptions) *SlotFiller[T] {
+	if opts.Schema == nil {
+		opts.Schema = ai.SchemaFor[T]()
+	}
+	return &SlotFiller[T]{opts: opts}
+}
+
+// SlotFillResult is the outcome of one turn
+type SlotFillResult[T any] struct {
+	// Complete reports whether every required field is filled and valid
+	Complete bool
+
+	// Value is the completed form; set when Complete
+	Value T
+
+	// Fields holds the values collected so far
+	Fields map[string]interface{}
+
+	// Missing lists the required fields still to collect, in schema order
+	Missing []string
+
+	// Invalid maps fields whose last value failed validation to the reason.
+	// Invalid values are discarded and asked for again.
+	Invalid map[string]string
+
+	// Reply is the question to send the user; empty when Complete. It has
+	// already been appended to the conversation.
+	Reply string
+
+	// Conversation is the stored conversation after this turn
+	Conversation *Conversation
+
+	// Usage is the token usage of this turn
+	Usage types.Usage
+}
+
+// Respond records the user's message in the conversation chatID, updates
+// the form from it and returns the next question, or the completed value.
+// Users can correct an earlier answer in any later turn, including after
+// the form is complete.
+func (f *SlotFiller[T]) Respond(ctx context.Context, chatID, userMessage string) (*SlotFillResult[T], error) {
+	if f.opts.Model == nil {
+		return nil, fmt.Errorf("slot filling model is required")
+	}
+	if f.opts.Chats == nil {
+		return nil, fmt.Errorf("slot filling chat store is required")
+	}
+	formSchema := f.opts.Schema.Validator().JSONSchema()
+	props, _ := formSchema["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		return nil, fmt.Errorf("slot filling schema must be an object with properties")
+	}
+
+	history, err := f.opts.Chats.Load(ctx, chatID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	var messages []types.Message
+	fields := make(map[string]interface{})
+	if history != nil {
+		messages = history.Messages
+		if saved, ok := history.Metadata[SlotsMetadataKey].(map[string]interface{}); ok {
+			for k, v := range saved {
+				fields[k] = v
+			}
+		}
+	}
+	user := types.Message{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: userMessage}}}
+	messages = append(messages[:len(messages):len(messages)], user)
+
+	result := &SlotFillResult[T]{Fields: fields, Invalid: make(map[string]string)}
+	extracted, usage, err := f.extract(ctx, formSchema, fields, messages)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage = usage
+	for name, value := range extracted {
+		if _, known := props[name]; known && value != nil {
+			fields[name] = value
+		}
+	}
+
+	for _, verr := range schema.ValidateValue(formSchema, fields) {
+		name, _, _ := strings.Cut(strings.TrimPrefix(verr.Pointer, "/"), "/")
+		if name == "" {
+			continue
+		}
+		if _, ok := result.Invalid[name]; !ok {
+			result.Invalid[name] = verr.Message
+		}
+		delete(fields, name)
+	}
+	result.Missing = missingFields(formSchema, fields)
+
+	reply := []types.Message{user}
+	if len(result.Missing) == 0 {
+		data, err := json.Marshal(fields)
+		if err == nil {
+			err = json.Unmarshal(data, &result.Value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode collected fields: %w", err)
+		}
+		result.Complete = true
+	} else {
+		question, usage, err := f.ask(ctx, formSchema, result, messages)
+		if err != nil {
+			return nil, err
+		}
+		result.Usage = result.Usage.Add(usage)
+		result.Reply = question
+		reply = append(reply, types.Message{Role: types.RoleAssistant, Content: []types.ContentPart{types.TextContent{Text: question}}})
+	}
+
+	result.Conversation, err = f.opts.Chats.update(ctx, chatID, func(c *Conversation) {
+		if c.Metadata == nil {
+			c.Metadata = make(map[string]any)
+		}
+		c.Metadata[SlotsMetadataKey] = fields
+	}, reply...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// extract asks the model for the field values stated in the conversation
+func (f *SlotFiller[T]) extract(ctx context.Context, formSchema map[string]interface{}, fields map[string]interface{}, messages []types.Message) (map[string]interface{}, types.Usage, error) {
+	// The user rarely answers everything at once, and out-of-range answers
+	// are reported by Respond rather than dropped here
+	partial := looseSchema(formSchema)
+	collected, err := json.Marshal(fields)
+	if err != nil {
+		return nil, types.Usage{}, err
+	}
+	system := "You fill in a form from a conversation with a user. Return the value of every field the user has stated or confirmed, " +
+		"including corrections to earlier values. Omit fields the user has not given; never guess or invent values."
+	if f.opts.Instructions != "" {
+		system += "\n\nTask: " + f.opts.Instructions
+	}
+	result, err := ai.GenerateObject(ctx, ai.GenerateObjectOptions{
+		Model:      f.opts.Model,
+		System:     system,
+		Prompt:     "Collected so far: " + string(collected) + "\n\nConversation:\n" + transcript(messages),
+		Schema:     schema.NewSimpleJSONSchema(partial.(map[string]interface{})),
+		OutputMode: ai.ObjectModeObject,
+	})
+	if err != nil {
+		return nil, types.Usage{}, fmt.Errorf("failed to extract form fields: %w", err)
+	}
+	extracted, _ := result.Object.(map[string]interface{})
+	return extracted, result.Usage, nil
+}
+
+// ask words a question for the missing and invalid fields
+func (f *SlotFiller[T]) ask(ctx context.Context, formSchema map[string]interface{}, state *SlotFillResult[T], messages []types.Message) (string, types.Usage, error) {
+	props, _ := formSchema["properties"].(map[string]interface{})
+	var needed strings.Builder
+	for _, name := range state.Missing {
+		fmt.Fprintf(&needed, "- %s", name)
+		if prop, ok := props[name].(map[string]interface{}); ok {
+			if desc, ok := prop["description"].(string); ok && desc != "" {
+				fmt.Fprintf(&needed, " (%s)", desc)
+			}
+		}
+		if reason, ok := state.Invalid[name]; ok {
+			fmt.Fprintf(&needed, ": the value given was invalid, %s", reason)
+		}
+		needed.WriteString("\n")
+	}
+	system := "You are collecting information from a user. Write your next message: briefly acknowledge what they said, " +
+		"then ask for the missing information below and nothing else. Explain any invalid value. Respond with the message only."
+	if f.opts.Instructions != "" {
+		system += "\n\nTask: " + f.opts.Instructions
+	}
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model:  f.opts.Model,
+		System: system,
+		Prompt: "Still needed:\n" + needed.String() + "\nConversation:\n" + transcript(messages),
+	})
+	if err != nil {
+		return "", types.Usage{}, fmt.Errorf("failed to ask for form fields: %w", err)
+	}
+	return strings.TrimSpace(result.Text), result.Usage, nil
+}
+
+// looseSchema copies a schema without required fields or value
+// constraints
+func looseSchema(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			switch k {
+			case "required", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+				"minLength", "maxLength", "pattern", "minItems", "maxItems":
+				continue
+			}
+			if props, ok := v.(map[string]interface{}); ok && k == "properties" {
+				// Keys here are field names, not keywords
+				copied := make(map[string]interface{}, len(props))
+				for name, prop := range props {
+					copied[name] = looseSchema(prop)
+				}
+				out[k] = copied
+				continue
+			}
+			out[k] = looseSchema(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(n))
+		for i, v := range n {
+			out[i] = looseSchema(v)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+// missingFields returns the required fields absent from fields, in the
+// schema's order
+func missingFields(formSchema map[string]interface{}, fields map[string]interface{}) []string {
+	var missing []string
+	for _, name := range schema.RequiredProperties(formSchema) {
+		if _, ok := fields[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
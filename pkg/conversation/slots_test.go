@@ -0,0 +1,88 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+type booking struct {
+	Name   string `json:"name"`
+	Date   string `json:"date" jsonschema:"description=YYYY-MM-DD"`
+	Guests int    `json:"guests" jsonschema:"minimum=1,maximum=12"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+func TestSlotFiller_AsksUntilComplete(t *testing.T) {
+	t.Parallel()
+
+	// Each turn's extraction, as the model would return it
+	extractions := []string{
+		`{"guests": 40, "date": "2026-10-23"}`,
+		`{"guests": 4}`,
+		`{"name": "Dana", "notes": "window seat"}`,
+	}
+	var questions []string
+	turn := 0
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if strings.Contains(opts.Prompt.System, "fill in a form") {
+				turn++
+				return &types.GenerateResult{Text: extractions[turn-1], FinishReason: types.FinishReasonStop}, nil
+			}
+			prompt := opts.Prompt.Messages[0].Content[0].(types.TextContent).Text
+			questions = append(questions, prompt)
+			return &types.GenerateResult{Text: "What else?", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	chats := NewChatStore(NewMemoryStore(), ChatStoreOptions{})
+	filler := NewSlotFiller[booking](SlotFillerOptions{Model: model, Chats: chats})
+	ctx := context.Background()
+
+	first, err := filler.Respond(ctx, "c1", "A table for 40 on the 23rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Complete || first.Reply != "What else?" || first.Invalid["guests"] == "" {
+		t.Fatalf("first turn = %+v", first)
+	}
+	if strings.Join(first.Missing, ",") != "name,guests" {
+		t.Errorf("missing = %v", first.Missing)
+	}
+	if !strings.Contains(questions[0], "- guests: the value given was invalid") || strings.Contains(questions[0], "- date") {
+		t.Errorf("question prompt = %q", questions[0])
+	}
+
+	second, err := filler.Respond(ctx, "c1", "Sorry, 4 people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Complete || strings.Join(second.Missing, ",") != "name" || second.Fields["date"] != "2026-10-23" {
+		t.Fatalf("second turn = %+v", second)
+	}
+
+	last, err := filler.Respond(ctx, "c1", "Dana, and a window seat if possible")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := booking{Name: "Dana", Date: "2026-10-23", Guests: 4, Notes: "window seat"}
+	if !last.Complete || last.Value != want || last.Reply != "" {
+		t.Fatalf("last turn = %+v", last)
+	}
+
+	c, err := chats.Load(ctx, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three user messages and two questions
+	if len(c.Messages) != 5 || c.Messages[4].Role != types.RoleUser {
+		t.Errorf("stored %d messages", len(c.Messages))
+	}
+	if saved := c.Metadata[SlotsMetadataKey].(map[string]any); saved["name"] != "Dana" {
+		t.Errorf("saved fields = %v", saved)
+	}
+}
@@ -0,0 +1,217 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ErrNotFound is returned by a Store for unknown conversation IDs
+var ErrNotFound = errors.New("conversation not found")
+
+// Store persists conversations by ID. Back it with a database for
+// production use; MarshalJSON and UnmarshalJSON give a lossless encoding
+// for document or blob stores.
+type Store interface {
+	// Load returns the conversation, or ErrNotFound
+	Load(ctx context.Context, id string) (*Conversation, error)
+
+	// Save creates or replaces the conversation
+	Save(ctx context.Context, c *Conversation) error
+
+	// Delete removes the conversation
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is an in-process Store for tests and prototypes
+type MemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string][]byte
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{conversations: make(map[string][]byte)}
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(ctx context.Context, id string) (*Conversation, error) {
+	s.mu.RLock()
+	data, ok := s.conversations[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return UnmarshalJSON(data)
+}
+
+// Save implements Store. The conversation is stored as a copy.
+func (s *MemoryStore) Save(ctx context.Context, c *Conversation) error {
+	data, err := MarshalJSON(c)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversations[c.ID] = data
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, id)
+	return nil
+}
+
+// IDs returns the stored conversation IDs, sorted
+func (s *MemoryStore) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.conversations))
+	for id := range s.conversations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ChatStoreHook runs after messages are appended and before the
+// conversation is saved, and may update it (e.g. set a title)
+type ChatStoreHook interface {
+	AfterAppend(ctx context.Context, c *Conversation) error
+}
+
+// ChatStoreHookFunc adapts a function to ChatStoreHook
+type ChatStoreHookFunc func(ctx context.Context, c *Conversation) error
+
+// AfterAppend calls f(ctx, c)
+func (f ChatStoreHookFunc) AfterAppend(ctx context.Context, c *Conversation) error {
+	return f(ctx, c)
+}
+
+// ChatStoreOptions configures a ChatStore
+type ChatStoreOptions struct {
+	// Hooks run in order after each Append
+	Hooks []ChatStoreHook
+
+	// OnHookError is called when a hook fails. The messages are saved
+	// regardless; each hook runs on a copy of the conversation that is
+	// discarded when the hook fails, so a failed hook only skips its own
+	// update.
+	OnHookError func(ctx context.Context, id string, err error)
+}
+
+// ChatStore appends messages to stored conversations and runs hooks such
+// as TitleSummaryHook on each update. Appends to the same conversation are
+// serialized within a ChatStore.
+type ChatStore struct {
+	store Store
+	opts  ChatStoreOptions
+
+	mu    sync.Mutex
+	locks map[string]*chatLock
+}
+
+// chatLock serializes appends to one conversation. refs counts the
+// appends holding or waiting for it, so it can be dropped when unused.
+type chatLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewChatStore wraps store with hooks
+func NewChatStore(store Store, opts ChatStoreOptions) *ChatStore {
+	return &ChatStore{store: store, opts: opts, locks: make(map[string]*chatLock)}
+}
+
+// Load returns the stored conversation
+func (s *ChatStore) Load(ctx context.Context, id string) (*Conversation, error) {
+	return s.store.Load(ctx, id)
+}
+
+// Delete removes the conversation
+func (s *ChatStore) Delete(ctx context.Context, id string) error {
+	return s.store.Delete(ctx, id)
+}
+
+// Append adds messages to the conversation, creating it if needed, runs
+// the hooks and saves the result
+func (s *ChatStore) Append(ctx context.Context, id string, messages ...types.Message) (*Conversation, error) {
+	return s.update(ctx, id, nil, messages...)
+}
+
+// update appends messages like Append, calling edit on the conversation
+// before the hooks run
+func (s *ChatStore) update(ctx context.Context, id string, edit func(c *Conversation), messages ...types.Message) (*Conversation, error) {
+	s.lock(id)
+	defer s.unlock(id)
+
+	c, err := s.store.Load(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		c, err = &Conversation{ID: id, CreatedAt: time.Now()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Messages = append(c.Messages, messages...)
+	if edit != nil {
+		edit(c)
+	}
+
+	for _, hook := range s.opts.Hooks {
+		updated := c.clone()
+		if err := hook.AfterAppend(ctx, updated); err != nil {
+			if s.opts.OnHookError != nil {
+				s.opts.OnHookError(ctx, id, err)
+			}
+			continue
+		}
+		c = updated
+	}
+	if err := s.store.Save(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// lock acquires the conversation's lock, creating it on first use
+func (s *ChatStore) lock(id string) {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &chatLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+	l.Lock()
+}
+
+// unlock releases the conversation's lock and drops it once no append
+// holds or waits for it
+func (s *ChatStore) unlock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.locks[id]
+	l.Unlock()
+	if l.refs--; l.refs == 0 {
+		delete(s.locks, id)
+	}
+}
+
+// clone copies c so a hook's changes can be discarded. Messages and
+// metadata are copied one level deep.
+func (c *Conversation) clone() *Conversation {
+	cp := *c
+	cp.Messages = slices.Clone(c.Messages)
+	cp.Metadata = maps.Clone(c.Metadata)
+	return &cp
+}
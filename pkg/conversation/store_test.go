@@ -0,0 +1,155 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func textMessage(role types.MessageRole, text string) types.Message {
+	return types.Message{Role: role, Content: []types.ContentPart{types.TextContent{Text: text}}}
+}
+
+func TestMemoryStore_RoundTripsTitleAndSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	c := sampleConversation()
+	c.Title = "Logo and weather"
+	c.Summary = "The user asked about an image and the weather."
+	c.SummaryThrough = 3
+	if err := store.Save(ctx, c); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := store.Load(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Title != c.Title || got.Summary != c.Summary || got.SummaryThrough != 3 {
+		t.Errorf("got title %q summary %q through %d", got.Title, got.Summary, got.SummaryThrough)
+	}
+
+	if err := store.Delete(ctx, c.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Load(ctx, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestChatStore_TitleAndRollingSummary(t *testing.T) {
+	t.Parallel()
+
+	var prompts []string
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			system := opts.Prompt.System
+			prompts = append(prompts, transcript(opts.Prompt.Messages))
+			if strings.Contains(system, "title") {
+				return &types.GenerateResult{Text: "\"Planning a Trip to Lisbon.\"", FinishReason: types.FinishReasonStop}, nil
+			}
+			return &types.GenerateResult{Text: "Summary " + string(rune('0'+len(prompts))), FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	chats := NewChatStore(NewMemoryStore(), ChatStoreOptions{
+		Hooks: []ChatStoreHook{&TitleSummaryHook{Model: model, SummaryEvery: 2}},
+	})
+	ctx := context.Background()
+
+	c, err := chats.Append(ctx, "c1", textMessage(types.RoleUser, "Help me plan a trip to Lisbon"))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if c.Title != "Planning a Trip to Lisbon" {
+		t.Errorf("Title = %q", c.Title)
+	}
+	if c.Summary != "" || c.CreatedAt.IsZero() {
+		t.Errorf("unexpected summary %q or zero CreatedAt", c.Summary)
+	}
+
+	c, err = chats.Append(ctx, "c1", textMessage(types.RoleAssistant, "Sure, when are you going?"))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if c.Summary == "" || c.SummaryThrough != 2 {
+		t.Fatalf("summary %q through %d, want a summary through 2", c.Summary, c.SummaryThrough)
+	}
+	first := c.Summary
+
+	chats.Append(ctx, "c1", textMessage(types.RoleUser, "In May"))
+	c, err = chats.Append(ctx, "c1", textMessage(types.RoleAssistant, "May is lovely."))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if c.SummaryThrough != 4 || c.Summary == first {
+		t.Errorf("summary %q through %d, want an updated summary through 4", c.Summary, c.SummaryThrough)
+	}
+	// The title is generated once; the rolling summary only sees new messages
+	if len(prompts) != 3 {
+		t.Fatalf("model called %d times, want 3", len(prompts))
+	}
+	last := prompts[2]
+	if !strings.Contains(last, first) || strings.Contains(last, "Lisbon") || !strings.Contains(last, "In May") {
+		t.Errorf("rolling summary prompt = %q", last)
+	}
+
+	stored, err := chats.Load(ctx, "c1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if stored.Title != c.Title || stored.Summary != c.Summary || len(stored.Messages) != 4 {
+		t.Errorf("stored conversation does not match: %+v", stored)
+	}
+}
+
+func TestChatStore_HookErrorStillSaves(t *testing.T) {
+	t.Parallel()
+
+	var hookErr error
+	chats := NewChatStore(NewMemoryStore(), ChatStoreOptions{
+		Hooks: []ChatStoreHook{ChatStoreHookFunc(func(ctx context.Context, c *Conversation) error {
+			c.Title = "half done"
+			return errors.New("model unavailable")
+		})},
+		OnHookError: func(ctx context.Context, id string, err error) { hookErr = err },
+	})
+	ctx := context.Background()
+	if _, err := chats.Append(ctx, "c1", textMessage(types.RoleUser, "hi")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if hookErr == nil {
+		t.Error("OnHookError was not called")
+	}
+	c, err := chats.Load(ctx, "c1")
+	if err != nil || len(c.Messages) != 1 {
+		t.Errorf("Load = %+v, %v; want the message saved", c, err)
+	}
+	if c.Title != "" {
+		t.Errorf("Title = %q; a failed hook's changes should be discarded", c.Title)
+	}
+	if len(chats.locks) != 0 {
+		t.Errorf("expected conversation locks to be released, %d remain", len(chats.locks))
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Title: Debugging Go Tests":         "Debugging Go Tests",
+		"**Weekend plans**\nMore text":      "Weekend plans",
+		"one two three four five six seven": "one two three four five six",
+		"“Quoted title!”":                   "Quoted title",
+	}
+	for in, want := range tests {
+		if got := cleanTitle(in, 6); got != want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
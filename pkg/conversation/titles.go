@@ -0,0 +1,142 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// TitleSummaryHook is a ChatStoreHook that gives each conversation a short
+// title and keeps a rolling summary of it up to date. Use a small, cheap
+// model; the hook only sends the messages added since the last summary.
+//
+// Example:
+//
+//	chats := conversation.NewChatStore(conversation.NewMemoryStore(), conversation.ChatStoreOptions{
+//		Hooks: []conversation.ChatStoreHook{&conversation.TitleSummaryHook{Model: cheapModel}},
+//	})
+//	c, err := chats.Append(ctx, chatID, userMessage, assistantMessage)
+//	fmt.Println(c.Title)
+type TitleSummaryHook struct {
+	// Model generates titles and summaries
+	Model provider.LanguageModel
+
+	// SummaryEvery updates the summary once this many messages have been
+	// added since the last update (default: 10, negative disables summaries)
+	SummaryEvery int
+
+	// MaxTitleWords limits the title length (default: 6)
+	MaxTitleWords int
+
+	// MaxSummaryWords limits the summary length (default: 150)
+	MaxSummaryWords int
+}
+
+// AfterAppend implements ChatStoreHook
+func (h *TitleSummaryHook) AfterAppend(ctx context.Context, c *Conversation) error {
+	if h.Model == nil {
+		return fmt.Errorf("title and summary model is required")
+	}
+	if c.Title == "" && hasRole(c.Messages, types.RoleUser) {
+		if err := h.generateTitle(ctx, c); err != nil {
+			return err
+		}
+	}
+	every := h.SummaryEvery
+	if every == 0 {
+		every = 10
+	}
+	if every > 0 && len(c.Messages)-c.SummaryThrough >= every {
+		return h.updateSummary(ctx, c)
+	}
+	return nil
+}
+
+func (h *TitleSummaryHook) generateTitle(ctx context.Context, c *Conversation) error {
+	words := h.MaxTitleWords
+	if words <= 0 {
+		words = 6
+	}
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model: h.Model,
+		System: fmt.Sprintf("Write a title of at most %d words for the conversation below. "+
+			"Respond with the title only: no quotes, no trailing punctuation.", words),
+		Prompt: transcript(c.Messages),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate title: %w", err)
+	}
+	c.Title = cleanTitle(result.Text, words)
+	return nil
+}
+
+func (h *TitleSummaryHook) updateSummary(ctx context.Context, c *Conversation) error {
+	words := h.MaxSummaryWords
+	if words <= 0 {
+		words = 150
+	}
+	through := min(c.SummaryThrough, len(c.Messages))
+	prompt := transcript(c.Messages[through:])
+	if c.Summary != "" {
+		prompt = "Summary so far:\n" + c.Summary + "\n\nNew messages:\n" + prompt
+	}
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model: h.Model,
+		System: fmt.Sprintf("Summarize the conversation in at most %d words, keeping facts, decisions and open questions. "+
+			"When a summary so far is given, update it with the new messages. Respond with the summary only.", words),
+		Prompt: prompt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update summary: %w", err)
+	}
+	c.Summary = strings.TrimSpace(result.Text)
+	c.SummaryThrough = len(c.Messages)
+	return nil
+}
+
+// transcript renders the text of messages as "role: text" lines
+func transcript(messages []types.Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		var parts []string
+		for _, part := range m.Content {
+			if t, ok := part.(types.TextContent); ok && strings.TrimSpace(t.Text) != "" {
+				parts = append(parts, strings.TrimSpace(t.Text))
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, strings.Join(parts, " "))
+	}
+	return b.String()
+}
+
+// cleanTitle strips quotes, labels and trailing punctuation models tend to
+// add, and truncates to maxWords
+func cleanTitle(text string, maxWords int) string {
+	title := strings.TrimSpace(text)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	title = strings.TrimPrefix(title, "Title:")
+	title = strings.Trim(title, " \t\"'`*“”‘’")
+	title = strings.TrimRight(title, ".!")
+	if fields := strings.Fields(title); len(fields) > maxWords {
+		title = strings.Join(fields[:maxWords], " ")
+	}
+	return title
+}
+
+func hasRole(messages []types.Message, role types.MessageRole) bool {
+	for _, m := range messages {
+		if m.Role == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+// Package dataset maps GenerateObject over the rows of a CSV or JSONL file,
+// writing one JSONL result per row. Rows run concurrently, failures are
+// recorded per row instead of stopping the run, and the output file doubles
+// as a checkpoint: rerunning after a crash or Ctrl-C skips rows that already
+// succeeded.
+//
+// Example:
+//
+//	summary, err := dataset.RunFile(ctx, "reviews.csv", "reviews.out.jsonl", dataset.Options{
+//		Request: ai.GenerateObjectOptions{Model: model, Schema: sentimentSchema},
+//		Prompt: func(row dataset.Row) (string, error) {
+//			return fmt.Sprintf("Classify the sentiment of this review:\n%s", row["text"]), nil
+//		},
+//		Concurrency: 8,
+//		Cost:        fireworks.Models[fireworks.ModelLlama3p3_70BInstruct].Cost,
+//	})
+//	fmt.Printf("%d ok, %d failed, $%.4f\n", summary.Succeeded, summary.Failed, summary.Cost)
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Options configures a dataset run
+type Options struct {
+	// Request is the template for every row's request: model, schema,
+	// system prompt and generation parameters (Model is required)
+	Request ai.GenerateObjectOptions
+
+	// Prompt builds the prompt for a row (required). An error marks the row
+	// as failed without calling the model.
+	Prompt func(row Row) (string, error)
+
+	// Concurrency is the number of rows processed at once (default: 4)
+	Concurrency int
+
+	// Completed holds the indexes of rows to skip, e.g. from ReadCheckpoint.
+	// RunFile fills it from the output file.
+	Completed map[int]bool
+
+	// Cost prices a row's usage in USD, e.g. a provider's ModelInfo.Cost.
+	// Without it, costs are zero.
+	Cost func(usage types.Usage) float64
+
+	// OnResult is called after each row's result is written
+	OnResult func(result Result)
+}
+
+// Result is one output line
+type Result struct {
+	// Index is the row's zero-based position in the input
+	Index int `json:"index"`
+
+	// Input is the row as read
+	Input Row `json:"input"`
+
+	// Output is the generated object, array or enum value
+	Output any `json:"output,omitempty"`
+
+	// Error describes why the row failed
+	Error string `json:"error,omitempty"`
+
+	// Usage and Cost of the row's model call
+	Usage types.Usage `json:"usage"`
+	Cost  float64     `json:"cost,omitempty"`
+
+	// DurationMs is how long the row took
+	DurationMs int64 `json:"durationMs"`
+}
+
+// Summary totals a run
+type Summary struct {
+	// Rows is the number of rows read, including skipped ones
+	Rows int
+
+	// Succeeded and Failed count rows processed in this run
+	Succeeded int
+	Failed    int
+
+	// Skipped counts rows already completed by an earlier run
+	Skipped int
+
+	// Usage and Cost of this run's model calls
+	Usage types.Usage
+	Cost  float64
+
+	// Duration is the run's wall time
+	Duration time.Duration
+}
+
+// Run processes every row from in and writes a Result line per processed
+// row to out, in completion order. It returns when the input is exhausted
+// and all rows are written, or on a read or write error. When ctx is
+// cancelled, rows cut short are not written, so a resumed run retries them;
+// Run then returns the summary so far and ctx's error.
+func Run(ctx context.Context, in Reader, out io.Writer, opts Options) (*Summary, error) {
+	if opts.Request.Model == nil {
+		return nil, fmt.Errorf("dataset: Request.Model is required")
+	}
+	if opts.Prompt == nil {
+		return nil, fmt.Errorf("dataset: Prompt is required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		index int
+		row   Row
+	}
+	jobs := make(chan job)
+	results := make(chan Result)
+
+	var workers sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				result := processRow(ctx, j.index, j.row, &opts)
+				if ctx.Err() != nil {
+					// Cut short by cancellation: leave the row for a resumed run
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+
+	// The writer owns out and the summary
+	summary := &Summary{}
+	var writeErr error
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		enc := json.NewEncoder(out)
+		for result := range results {
+			if writeErr != nil {
+				continue
+			}
+			if err := enc.Encode(result); err != nil {
+				writeErr = fmt.Errorf("failed to write result for row %d: %w", result.Index, err)
+				cancel()
+				continue
+			}
+			if result.Error != "" {
+				summary.Failed++
+			} else {
+				summary.Succeeded++
+			}
+			summary.Usage = summary.Usage.Add(result.Usage)
+			summary.Cost += result.Cost
+			if opts.OnResult != nil {
+				opts.OnResult(result)
+			}
+		}
+	}()
+
+	var readErr error
+	for index := 0; ctx.Err() == nil; index++ {
+		row, err := in.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("failed to read row %d: %w", index, err)
+			break
+		}
+		summary.Rows++
+		if opts.Completed[index] {
+			summary.Skipped++
+			continue
+		}
+		select {
+		case jobs <- job{index: index, row: row}:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	workers.Wait()
+	close(results)
+	<-written
+
+	summary.Duration = time.Since(start)
+	switch {
+	case writeErr != nil:
+		return summary, writeErr
+	case readErr != nil:
+		return summary, readErr
+	}
+	return summary, context.Cause(ctx)
+}
+
+// processRow generates the object for one row
+func processRow(ctx context.Context, index int, row Row, opts *Options) Result {
+	start := time.Now()
+	result := Result{Index: index, Input: row}
+
+	prompt, err := opts.Prompt(row)
+	if err != nil {
+		result.Error = fmt.Sprintf("prompt: %v", err)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result
+	}
+	req := opts.Request
+	req.Prompt = prompt
+	generated, err := ai.GenerateObject(ctx, req)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Usage = generated.Usage
+	if opts.Cost != nil {
+		result.Cost = opts.Cost(generated.Usage)
+	}
+	switch {
+	case generated.Array != nil:
+		result.Output = generated.Array
+	case generated.EnumValue != "":
+		result.Output = generated.EnumValue
+	default:
+		result.Output = generated.Object
+	}
+	return result
+}
+
+// ReadCheckpoint returns the indexes of rows that succeeded in an earlier
+// run's output. Failed rows are not included, so they are retried, and a
+// line cut off by a crash is ignored.
+func ReadCheckpoint(r io.Reader) (map[int]bool, error) {
+	completed := make(map[int]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			Index *int   `json:"index"`
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &line) != nil || line.Index == nil {
+			continue
+		}
+		if line.Error == "" {
+			completed[*line.Index] = true
+		}
+	}
+	return completed, scanner.Err()
+}
+
+// RunFile processes the dataset at inPath (see OpenFile), appending results
+// to outPath. When outPath exists, rows that already succeeded are skipped
+// and a partially written last line is removed, so interrupted runs can be
+// resumed by running the same command again. Failed rows are retried; the
+// last line for an index is the current result.
+func RunFile(ctx context.Context, inPath, outPath string, opts Options) (*Summary, error) {
+	in, closer, err := OpenFile(inPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(out)
+	if err != nil {
+		return nil, err
+	}
+	// Drop a line cut off by a crash so appended results start on a new line
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := out.Truncate(int64(end)); err != nil {
+			return nil, err
+		}
+		data = data[:end]
+	}
+	if _, err := out.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+
+	completed, err := ReadCheckpoint(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for index := range opts.Completed {
+		completed[index] = true
+	}
+	opts.Completed = completed
+
+	// Each result is encoded with a single write, so lines reach the file
+	// whole as soon as rows finish
+	summary, runErr := Run(ctx, in, out, opts)
+	if err := out.Sync(); err != nil && runErr == nil {
+		runErr = err
+	}
+	return summary, runErr
+}
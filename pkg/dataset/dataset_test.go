@@ -0,0 +1,165 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestReaders(t *testing.T) {
+	t.Parallel()
+
+	csvRows := readAll(t, NewCSVReader(strings.NewReader("\ufeffid,text\n1,\"hello, world\"\n2\n")))
+	if len(csvRows) != 2 || csvRows[0]["id"] != "1" || csvRows[0]["text"] != "hello, world" || csvRows[1]["text"] != "" {
+		t.Errorf("unexpected CSV rows %v", csvRows)
+	}
+
+	jsonlRows := readAll(t, NewJSONLReader(strings.NewReader("{\"id\":1,\"tags\":[\"a\"]}\n\n{\"id\":2}\n")))
+	if len(jsonlRows) != 2 || jsonlRows[0]["id"] != float64(1) || jsonlRows[1]["id"] != float64(2) {
+		t.Errorf("unexpected JSONL rows %v", jsonlRows)
+	}
+
+	if _, err := NewJSONLReader(strings.NewReader("not json\n")).Next(); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func readAll(t *testing.T, r Reader) []Row {
+	t.Helper()
+	var rows []Row
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// labelModel labels prompts as JSON and fails on prompts containing "bad"
+func labelModel(prompts *[]string, mu *sync.Mutex) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			prompt := opts.Prompt.Messages[len(opts.Prompt.Messages)-1].Content[0].(types.TextContent).Text
+			mu.Lock()
+			*prompts = append(*prompts, prompt)
+			mu.Unlock()
+			if strings.Contains(prompt, "bad") {
+				return nil, errors.New("model overloaded")
+			}
+			tokens, total := int64(10), int64(20)
+			return &types.GenerateResult{
+				Text:         `{"label":"` + strings.ToUpper(prompt) + `"}`,
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{InputTokens: &tokens, OutputTokens: &tokens, TotalTokens: &total},
+			}, nil
+		},
+	}
+}
+
+func TestRunFile_Resume(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	out := filepath.Join(dir, "out.jsonl")
+	if err := os.WriteFile(in, []byte("text\na\nb\nbad\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// An earlier run finished row 0 and crashed while writing row 1
+	if err := os.WriteFile(out, []byte(`{"index":0,"input":{"text":"a"},"output":{"label":"A"},"usage":{}}`+"\n"+`{"index":1,"inp`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	summary, err := RunFile(context.Background(), in, out, Options{
+		Request: ai.GenerateObjectOptions{
+			Model:      labelModel(&prompts, &mu),
+			OutputMode: ai.ObjectModeNoSchema,
+		},
+		Prompt: func(row Row) (string, error) {
+			return row["text"].(string), nil
+		},
+		Concurrency: 2,
+		Cost:        func(usage types.Usage) float64 { return float64(usage.GetTotalTokens()) / 1000 },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Rows != 4 || summary.Skipped != 1 || summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if summary.Usage.GetTotalTokens() != 40 || summary.Cost != 0.04 {
+		t.Errorf("unexpected usage %d and cost %v", summary.Usage.GetTotalTokens(), summary.Cost)
+	}
+	for _, p := range prompts {
+		if p == "a" {
+			t.Error("expected the completed row to be skipped")
+		}
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || strings.Contains(string(data), `"inp`+"\n") {
+		t.Fatalf("expected the torn line to be replaced by three results, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "model overloaded") {
+		t.Errorf("expected the failure to be recorded, got:\n%s", data)
+	}
+
+	// A second run only retries the failed row
+	prompts = nil
+	summary, err = RunFile(context.Background(), in, out, Options{
+		Request: ai.GenerateObjectOptions{Model: labelModel(&prompts, &mu), OutputMode: ai.ObjectModeNoSchema},
+		Prompt:  func(row Row) (string, error) { return row["text"].(string), nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Skipped != 3 || len(prompts) != 1 || prompts[0] != "bad" {
+		t.Errorf("expected only the failed row to be retried, got %+v and prompts %v", summary, prompts)
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			cancel()
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	var out strings.Builder
+	_, err := Run(ctx, NewJSONLReader(strings.NewReader("{\"a\":1}\n{\"a\":2}\n")), &out, Options{
+		Request: ai.GenerateObjectOptions{Model: model, OutputMode: ai.ObjectModeNoSchema},
+		Prompt:  func(row Row) (string, error) { return "x", nil },
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected interrupted rows not to be written, got %q", out.String())
+	}
+}
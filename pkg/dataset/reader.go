@@ -0,0 +1,116 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Row is one input record, keyed by CSV column or JSON field name. CSV
+// values are strings; JSONL values keep their JSON types.
+type Row map[string]any
+
+// Reader yields rows until io.EOF
+type Reader interface {
+	Next() (Row, error)
+}
+
+// csvReader reads CSV with a header row
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader reads CSV whose first record names the columns
+func NewCSVReader(r io.Reader) Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvReader{r: cr}
+}
+
+func (c *csvReader) Next() (Row, error) {
+	if c.header == nil {
+		header, err := c.r.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+		c.header = header
+	}
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(Row, len(c.header))
+	for i, name := range c.header {
+		if i < len(record) {
+			row[name] = record[i]
+		} else {
+			row[name] = ""
+		}
+	}
+	return row, nil
+}
+
+// jsonlReader reads one JSON object per line
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONLReader reads newline-delimited JSON objects. Blank lines are
+// skipped.
+func NewJSONLReader(r io.Reader) Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &jsonlReader{scanner: scanner}
+}
+
+func (j *jsonlReader) Next() (Row, error) {
+	for j.scanner.Scan() {
+		j.line++
+		line := bytes.TrimSpace(j.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var row Row
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %w", j.line, err)
+		}
+		return row, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// OpenFile opens a dataset file, choosing the reader by extension: ".csv"
+// for CSV and ".jsonl", ".ndjson" or ".json" for JSONL
+func OpenFile(path string) (Reader, io.Closer, error) {
+	var newReader func(io.Reader) Reader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		newReader = NewCSVReader
+	case ".jsonl", ".ndjson", ".json":
+		newReader = NewJSONLReader
+	default:
+		return nil, nil, fmt.Errorf("unsupported dataset format %q (want .csv or .jsonl)", filepath.Ext(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newReader(bufio.NewReader(f)), f, nil
+}
@@ -0,0 +1,357 @@
+// Package durable runs generation, tool execution and agent steps under a
+// durable-execution engine such as Temporal.
+//
+// Each unit of work is an activity: a method on Activities taking and
+// returning JSON-serializable values, so it can be registered directly with a
+// Temporal worker and retried independently. RunAgent drives a tool-calling
+// agent purely through those activities, which keeps the loop deterministic
+// and safe to replay inside a workflow.
+//
+// Registering the activities with Temporal:
+//
+//	acts := &durable.Activities{Tools: tools, Store: redisStore}
+//	w := worker.New(client, "ai", worker.Options{})
+//	w.RegisterActivity(acts)
+//
+// and running an agent from a workflow:
+//
+//	func AgentWorkflow(ctx workflow.Context, in durable.AgentInput) (*durable.AgentOutput, error) {
+//		ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+//			StartToCloseTimeout: 2 * time.Minute,
+//			RetryPolicy: &temporal.RetryPolicy{
+//				NonRetryableErrorTypes: []string{durable.NonRetryableErrorType},
+//			},
+//		})
+//		var acts *durable.Activities
+//		in.RunID = workflow.GetInfo(ctx).WorkflowExecution.ID
+//		return durable.RunAgent(durable.ExecutorFuncs{
+//			StepFunc: func(in durable.StepInput) (*durable.StepOutput, error) {
+//				var out durable.StepOutput
+//				err := workflow.ExecuteActivity(ctx, acts.ModelStep, in).Get(ctx, &out)
+//				return &out, err
+//			},
+//			ToolFunc: func(in durable.ToolInput) (*durable.ToolOutput, error) {
+//				var out durable.ToolOutput
+//				err := workflow.ExecuteActivity(ctx, acts.ExecuteTool, in).Get(ctx, &out)
+//				return &out, err
+//			},
+//		}, in)
+//	}
+package durable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+)
+
+// Activities holds the dependencies of the durable activities. Register a
+// pointer to it with the durable-execution worker; every exported method is
+// an activity.
+type Activities struct {
+	// ResolveModel looks up models. Defaults to the global registry.
+	ResolveModel registry.ModelResolver
+
+	// DefaultModel is used when an input does not name a model (optional)
+	DefaultModel string
+
+	// Tools available to GenerateText, ModelStep and ExecuteTool
+	Tools []types.Tool
+
+	// Store records tool results by idempotency key so a retried or
+	// replayed ExecuteTool returns the recorded result instead of running a
+	// side-effecting tool twice (optional; recommended with retries)
+	Store ResultStore
+}
+
+// Settings are optional generation settings
+type Settings struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"maxTokens,omitempty"`
+	TopP        *float64 `json:"topP,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// GenerateTextInput is the input of the GenerateText activity
+type GenerateTextInput struct {
+	Model    string   `json:"model,omitempty"`
+	System   string   `json:"system,omitempty"`
+	Prompt   string   `json:"prompt,omitempty"`
+	Messages Messages `json:"messages,omitempty"`
+	Settings Settings `json:"settings"`
+
+	// MaxSteps allows tool calls to be executed inside the activity
+	// (default 1: no tool round trips)
+	MaxSteps int `json:"maxSteps,omitempty"`
+}
+
+// GenerateTextOutput is the output of the GenerateText activity
+type GenerateTextOutput struct {
+	Text         string             `json:"text"`
+	FinishReason types.FinishReason `json:"finishReason"`
+	Usage        types.Usage        `json:"usage"`
+}
+
+// GenerateText runs ai.GenerateText as a single activity. Tool calls, if
+// MaxSteps allows them, execute within the activity, so the whole call is
+// retried as a unit; use RunAgent for per-tool durability.
+func (a *Activities) GenerateText(ctx context.Context, in GenerateTextInput) (*GenerateTextOutput, error) {
+	model, err := a.model(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	opts := ai.GenerateTextOptions{
+		Model:       model,
+		System:      in.System,
+		Prompt:      in.Prompt,
+		Messages:    in.Messages,
+		Temperature: in.Settings.Temperature,
+		MaxTokens:   in.Settings.MaxTokens,
+		TopP:        in.Settings.TopP,
+		Seed:        in.Settings.Seed,
+		Tools:       a.Tools,
+	}
+	if in.MaxSteps > 1 {
+		opts.StopWhen = []ai.StopCondition{ai.StepCountIs(in.MaxSteps)}
+	}
+	result, err := ai.GenerateText(ctx, opts)
+	if err != nil {
+		return nil, classify(err)
+	}
+	return &GenerateTextOutput{Text: result.Text, FinishReason: result.FinishReason, Usage: result.Usage}, nil
+}
+
+// StepInput is the input of the ModelStep activity
+type StepInput struct {
+	Model    string   `json:"model,omitempty"`
+	System   string   `json:"system,omitempty"`
+	Messages Messages `json:"messages"`
+	Settings Settings `json:"settings"`
+
+	// ToolNames restricts the tools offered to the model; empty offers all
+	// of Activities.Tools
+	ToolNames []string `json:"toolNames,omitempty"`
+}
+
+// StepOutput is the output of the ModelStep activity
+type StepOutput struct {
+	// Message is the assistant message to append to the conversation,
+	// including its tool calls
+	Message Messages `json:"message"`
+
+	Text         string             `json:"text"`
+	ToolCalls    []types.ToolCall   `json:"toolCalls,omitempty"`
+	FinishReason types.FinishReason `json:"finishReason"`
+	Usage        types.Usage        `json:"usage"`
+}
+
+// ModelStep makes one model call offering the tools without executing them.
+// It is the model half of a durable agent step; tool calls are run with
+// ExecuteTool.
+func (a *Activities) ModelStep(ctx context.Context, in StepInput) (*StepOutput, error) {
+	model, err := a.model(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := a.tools(in.ToolNames)
+	if err != nil {
+		return nil, err
+	}
+	result, err := model.DoGenerate(ctx, &provider.GenerateOptions{
+		Prompt:      types.Prompt{System: in.System, Messages: in.Messages},
+		Temperature: in.Settings.Temperature,
+		MaxTokens:   in.Settings.MaxTokens,
+		TopP:        in.Settings.TopP,
+		Seed:        in.Settings.Seed,
+		Tools:       tools,
+	})
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	msg := types.Message{Role: types.RoleAssistant, ToolCalls: result.ToolCalls}
+	for _, part := range result.Content {
+		switch part.(type) {
+		case types.TextContent, types.ReasoningContent:
+			msg.Content = append(msg.Content, part)
+		}
+	}
+	if len(msg.Content) == 0 && result.Text != "" {
+		msg.Content = []types.ContentPart{types.TextContent{Text: result.Text}}
+	}
+	return &StepOutput{
+		Message:      Messages{msg},
+		Text:         result.Text,
+		ToolCalls:    result.ToolCalls,
+		FinishReason: result.FinishReason,
+		Usage:        result.Usage,
+	}, nil
+}
+
+// ToolInput is the input of the ExecuteTool activity
+type ToolInput struct {
+	Call types.ToolCall `json:"call"`
+
+	// IdempotencyKey identifies this execution across retries and replays,
+	// e.g. "<workflow id>/<step>/<tool call id>". Empty disables the guard.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// ToolOutput is the output of the ExecuteTool activity
+type ToolOutput struct {
+	ToolCallID string `json:"toolCallId"`
+	ToolName   string `json:"toolName"`
+	Result     any    `json:"result,omitempty"`
+
+	// Error is the tool's error message. Tool errors are returned to the
+	// model rather than failing the activity.
+	Error string `json:"error,omitempty"`
+
+	// Replayed reports that the result came from the Store
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// ExecuteTool runs one tool call. When a Store and IdempotencyKey are set,
+// a previously recorded result is returned without running the tool again.
+func (a *Activities) ExecuteTool(ctx context.Context, in ToolInput) (*ToolOutput, error) {
+	if a.Store != nil && in.IdempotencyKey != "" {
+		data, ok, err := a.Store.Get(ctx, in.IdempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tool result: %w", err)
+		}
+		if ok {
+			var out ToolOutput
+			if err := json.Unmarshal(data, &out); err != nil {
+				return nil, fmt.Errorf("failed to decode recorded tool result: %w", err)
+			}
+			out.Replayed = true
+			return &out, nil
+		}
+	}
+
+	tools, err := a.tools([]string{in.Call.ToolName})
+	if err != nil {
+		return nil, err
+	}
+	tool := tools[0]
+	if tool.Execute == nil {
+		return nil, &NonRetryableError{Err: fmt.Errorf("tool %q has no Execute function", tool.Name)}
+	}
+
+	out := &ToolOutput{ToolCallID: in.Call.ID, ToolName: in.Call.ToolName}
+	result, toolErr := tool.Execute(ctx, in.Call.Arguments, types.ToolExecutionOptions{
+		ToolCallID: in.Call.ID,
+		Metadata:   map[string]interface{}{"idempotencyKey": in.IdempotencyKey},
+	})
+	if toolErr != nil {
+		if ctx.Err() != nil {
+			// Cancelled or timed out: let the engine retry the activity
+			return nil, toolErr
+		}
+		out.Error = toolErr.Error()
+	} else {
+		out.Result = result
+	}
+
+	if a.Store != nil && in.IdempotencyKey != "" {
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, &NonRetryableError{Err: fmt.Errorf("tool result is not serializable: %w", err)}
+		}
+		if err := a.Store.Put(ctx, in.IdempotencyKey, data); err != nil {
+			return nil, fmt.Errorf("failed to record tool result: %w", err)
+		}
+	}
+	return out, nil
+}
+
+func (a *Activities) model(name string) (provider.LanguageModel, error) {
+	if name == "" {
+		name = a.DefaultModel
+	}
+	if name == "" {
+		return nil, &NonRetryableError{Err: fmt.Errorf("model is required")}
+	}
+	resolve := a.ResolveModel
+	if resolve == nil {
+		resolve = registry.ResolveLanguageModel
+	}
+	model, err := resolve(name)
+	if err != nil {
+		return nil, &NonRetryableError{Err: fmt.Errorf("unknown model %q: %w", name, err)}
+	}
+	return model, nil
+}
+
+// tools returns the named tools in order, or all tools for an empty list
+func (a *Activities) tools(names []string) ([]types.Tool, error) {
+	if len(names) == 0 {
+		return a.Tools, nil
+	}
+	out := make([]types.Tool, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, t := range a.Tools {
+			if t.Name == name {
+				out = append(out, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &NonRetryableError{Err: fmt.Errorf("unknown tool %q", name)}
+		}
+	}
+	return out, nil
+}
+
+// NonRetryableErrorType is the type name reported for NonRetryableError; add
+// it to the retry policy's non-retryable error types (Temporal:
+// RetryPolicy.NonRetryableErrorTypes)
+const NonRetryableErrorType = "NonRetryableError"
+
+// NonRetryableError marks failures that retrying cannot fix, such as invalid
+// requests or unknown models
+type NonRetryableError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *NonRetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsNonRetryable reports whether err is marked as non-retryable
+func IsNonRetryable(err error) bool {
+	var nr *NonRetryableError
+	return errors.As(err, &nr)
+}
+
+// classify wraps validation and client errors as non-retryable; rate limits,
+// server errors and network failures are left for the engine to retry
+func classify(err error) error {
+	if providererrors.IsValidationError(err) {
+		return &NonRetryableError{Err: err}
+	}
+	if providererrors.IsRateLimitError(err) {
+		return err
+	}
+	var provErr *providererrors.ProviderError
+	if errors.As(err, &provErr) && provErr.StatusCode >= 400 && provErr.StatusCode < 500 &&
+		provErr.StatusCode != 408 && provErr.StatusCode != 429 {
+		return &NonRetryableError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package durable
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Executor runs activities on behalf of RunAgent. Inside a workflow each
+// method schedules the corresponding activity; outside one, LocalExecutor
+// calls Activities directly.
+type Executor interface {
+	Step(in StepInput) (*StepOutput, error)
+	Tool(in ToolInput) (*ToolOutput, error)
+}
+
+// ExecutorFuncs adapts a pair of functions to the Executor interface
+type ExecutorFuncs struct {
+	StepFunc func(in StepInput) (*StepOutput, error)
+	ToolFunc func(in ToolInput) (*ToolOutput, error)
+}
+
+// Step calls StepFunc
+func (e ExecutorFuncs) Step(in StepInput) (*StepOutput, error) { return e.StepFunc(in) }
+
+// Tool calls ToolFunc
+func (e ExecutorFuncs) Tool(in ToolInput) (*ToolOutput, error) { return e.ToolFunc(in) }
+
+// LocalExecutor runs the activities in-process, e.g. for tests or when no
+// durable-execution engine is available
+func LocalExecutor(ctx context.Context, a *Activities) Executor {
+	return ExecutorFuncs{
+		StepFunc: func(in StepInput) (*StepOutput, error) { return a.ModelStep(ctx, in) },
+		ToolFunc: func(in ToolInput) (*ToolOutput, error) { return a.ExecuteTool(ctx, in) },
+	}
+}
+
+// AgentInput is the input of RunAgent
+type AgentInput struct {
+	// RunID namespaces idempotency keys; use the workflow ID so replays and
+	// retries of the same run share recorded tool results (required)
+	RunID string `json:"runId"`
+
+	Model    string   `json:"model,omitempty"`
+	System   string   `json:"system,omitempty"`
+	Prompt   string   `json:"prompt,omitempty"`
+	Messages Messages `json:"messages,omitempty"`
+	Settings Settings `json:"settings"`
+
+	// ToolNames restricts the tools offered to the model (default: all)
+	ToolNames []string `json:"toolNames,omitempty"`
+
+	// MaxSteps bounds the number of model calls (default 10)
+	MaxSteps int `json:"maxSteps,omitempty"`
+}
+
+// AgentOutput is the result of RunAgent
+type AgentOutput struct {
+	Text         string             `json:"text"`
+	FinishReason types.FinishReason `json:"finishReason"`
+	Steps        int                `json:"steps"`
+
+	// Messages is the full conversation including the input
+	Messages Messages `json:"messages"`
+
+	// Usage summed over all model steps
+	Usage types.Usage `json:"usage"`
+}
+
+// RunAgent runs a tool-calling loop in which every model call and every tool
+// execution is an activity. The loop itself does no I/O and reads no clock or
+// randomness, so it replays deterministically inside a workflow: on replay
+// the engine feeds back the recorded activity results and the loop takes the
+// same path. Tool calls run sequentially in the order the model returned
+// them, each with the idempotency key "<RunID>/<step>/<tool call id>".
+func RunAgent(exec Executor, in AgentInput) (*AgentOutput, error) {
+	if in.RunID == "" {
+		return nil, fmt.Errorf("run id is required")
+	}
+	maxSteps := in.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = 10
+	}
+
+	messages := append(Messages(nil), in.Messages...)
+	if in.Prompt != "" {
+		messages = append(messages, types.Message{
+			Role:    types.RoleUser,
+			Content: []types.ContentPart{types.TextContent{Text: in.Prompt}},
+		})
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("prompt or messages is required")
+	}
+
+	out := &AgentOutput{}
+	for step := 0; step < maxSteps; step++ {
+		res, err := exec.Step(StepInput{
+			Model:     in.Model,
+			System:    in.System,
+			Messages:  messages,
+			Settings:  in.Settings,
+			ToolNames: in.ToolNames,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("step %d failed: %w", step+1, err)
+		}
+		out.Steps = step + 1
+		out.Usage = out.Usage.Add(res.Usage)
+		out.Text = res.Text
+		out.FinishReason = res.FinishReason
+		messages = append(messages, res.Message...)
+
+		if len(res.ToolCalls) == 0 {
+			break
+		}
+
+		toolMsg := types.Message{Role: types.RoleTool}
+		for _, call := range res.ToolCalls {
+			tr, err := exec.Tool(ToolInput{
+				Call:           call,
+				IdempotencyKey: fmt.Sprintf("%s/%d/%s", in.RunID, step+1, call.ID),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("tool %s failed: %w", call.ToolName, err)
+			}
+			toolMsg.Content = append(toolMsg.Content, types.ToolResultContent{
+				ToolCallID: tr.ToolCallID,
+				ToolName:   tr.ToolName,
+				Result:     tr.Result,
+				Error:      tr.Error,
+			})
+		}
+		messages = append(messages, toolMsg)
+	}
+
+	out.Messages = messages
+	return out, nil
+}
@@ -0,0 +1,167 @@
+package durable
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// toolCallingModel asks for the weather tool once, then answers
+func toolCallingModel() *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			usage := types.Usage{TotalTokens: int64Ptr(5)}
+			last := opts.Prompt.Messages[len(opts.Prompt.Messages)-1]
+			if last.Role == types.RoleTool {
+				tr := last.Content[0].(types.ToolResultContent)
+				return &types.GenerateResult{Text: "It is " + tr.Result.(string), FinishReason: types.FinishReasonStop, Usage: usage}, nil
+			}
+			if len(opts.Tools) != 1 {
+				return nil, providererrors.NewProviderError("mock", 400, "bad", "expected one tool", nil)
+			}
+			return &types.GenerateResult{
+				ToolCalls:    []types.ToolCall{{ID: "call_1", ToolName: "weather", Arguments: map[string]interface{}{"city": "Oslo"}}},
+				FinishReason: types.FinishReasonToolCalls,
+				Usage:        usage,
+			}, nil
+		},
+	}
+}
+
+func weatherTool(runs *atomic.Int32) types.Tool {
+	return types.Tool{
+		Name:       "weather",
+		Parameters: map[string]interface{}{"type": "object"},
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			runs.Add(1)
+			return "snowing in " + input["city"].(string), nil
+		},
+	}
+}
+
+// jsonExecutor round-trips every activity input and output through JSON, as
+// a durable-execution engine's data converter would
+func jsonExecutor(t *testing.T, ctx context.Context, a *Activities) Executor {
+	roundTrip := func(in, out any) {
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", in, err)
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("unmarshal %T: %v", out, err)
+		}
+	}
+	return ExecutorFuncs{
+		StepFunc: func(in StepInput) (*StepOutput, error) {
+			var decoded StepInput
+			roundTrip(in, &decoded)
+			res, err := a.ModelStep(ctx, decoded)
+			if err != nil {
+				return nil, err
+			}
+			var out StepOutput
+			roundTrip(res, &out)
+			return &out, nil
+		},
+		ToolFunc: func(in ToolInput) (*ToolOutput, error) {
+			var decoded ToolInput
+			roundTrip(in, &decoded)
+			res, err := a.ExecuteTool(ctx, decoded)
+			if err != nil {
+				return nil, err
+			}
+			var out ToolOutput
+			roundTrip(res, &out)
+			return &out, nil
+		},
+	}
+}
+
+func TestRunAgent_ThroughSerializedActivities(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	acts := &Activities{
+		ResolveModel: func(string) (provider.LanguageModel, error) { return toolCallingModel(), nil },
+		DefaultModel: "mock:model",
+		Tools:        []types.Tool{weatherTool(&runs)},
+		Store:        NewMemoryResultStore(),
+	}
+
+	out, err := RunAgent(jsonExecutor(t, context.Background(), acts), AgentInput{RunID: "wf-1", Prompt: "Weather in Oslo?"})
+	if err != nil {
+		t.Fatalf("RunAgent: %v", err)
+	}
+	if out.Text != "It is snowing in Oslo" || out.Steps != 2 || out.Usage.GetTotalTokens() != 10 {
+		t.Errorf("output = %+v", out)
+	}
+	// user, assistant(tool call), tool, assistant
+	if len(out.Messages) != 4 || out.Messages[1].ToolCalls[0].ID != "call_1" {
+		t.Errorf("messages = %#v", out.Messages)
+	}
+
+	// Replaying the same run reuses the recorded tool result
+	if _, err := RunAgent(LocalExecutor(context.Background(), acts), AgentInput{RunID: "wf-1", Prompt: "Weather in Oslo?"}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("tool ran %d times, want 1", runs.Load())
+	}
+}
+
+func TestExecuteTool_IdempotencyGuard(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	acts := &Activities{Tools: []types.Tool{weatherTool(&runs)}, Store: NewMemoryResultStore()}
+	in := ToolInput{Call: types.ToolCall{ID: "c", ToolName: "weather", Arguments: map[string]interface{}{"city": "Rome"}}, IdempotencyKey: "k"}
+
+	first, err := acts.ExecuteTool(context.Background(), in)
+	if err != nil || first.Replayed {
+		t.Fatalf("first = %+v, %v", first, err)
+	}
+	second, err := acts.ExecuteTool(context.Background(), in)
+	if err != nil || !second.Replayed || second.Result != "snowing in Rome" {
+		t.Fatalf("second = %+v, %v", second, err)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("tool ran %d times, want 1", runs.Load())
+	}
+
+	if _, err := acts.ExecuteTool(context.Background(), ToolInput{Call: types.ToolCall{ToolName: "missing"}}); !IsNonRetryable(err) {
+		t.Errorf("unknown tool error should be non-retryable, got %v", err)
+	}
+}
+
+func TestActivities_ClassifiesErrors(t *testing.T) {
+	t.Parallel()
+
+	status := 400
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, providererrors.NewProviderError("mock", status, "", "failed", nil)
+		},
+	}
+	acts := &Activities{ResolveModel: func(string) (provider.LanguageModel, error) { return model, nil }, DefaultModel: "m"}
+
+	_, err := acts.GenerateText(context.Background(), GenerateTextInput{Prompt: "hi"})
+	if !IsNonRetryable(err) {
+		t.Errorf("400 should be non-retryable, got %v", err)
+	}
+	status = 503
+	_, err = acts.ModelStep(context.Background(), StepInput{Messages: Messages{{Role: types.RoleUser}}})
+	if err == nil || IsNonRetryable(err) {
+		t.Errorf("503 should be retryable, got %v", err)
+	}
+	if _, err := (&Activities{}).GenerateText(context.Background(), GenerateTextInput{Prompt: "hi"}); !IsNonRetryable(err) {
+		t.Errorf("missing model should be non-retryable, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package durable
+
+import (
+	"encoding/json"
+
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Messages is a message list that survives JSON round trips. types.Message
+// holds content parts behind an interface, which encoding/json cannot
+// decode; Messages uses the go-ai conversation format instead, so activity
+// inputs and outputs can pass through any JSON data converter.
+type Messages []types.Message
+
+// MarshalJSON implements json.Marshaler
+func (m Messages) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	data, err := conversation.MarshalJSON(&conversation.Conversation{Messages: m})
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Messages json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Messages, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (m *Messages) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = nil
+		return nil
+	}
+	doc, err := json.Marshal(struct {
+		Format   string          `json:"format"`
+		Version  int             `json:"version"`
+		Messages json.RawMessage `json:"messages"`
+	}{"go-ai.conversation", 1, data})
+	if err != nil {
+		return err
+	}
+	c, err := conversation.UnmarshalJSON(doc)
+	if err != nil {
+		return err
+	}
+	*m = c.Messages
+	return nil
+}
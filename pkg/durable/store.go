@@ -0,0 +1,45 @@
+package durable
+
+import (
+	"context"
+	"sync"
+)
+
+// ResultStore records activity results by idempotency key. Back it with a
+// database or cache shared by all workers so a retry on another worker sees
+// the result.
+type ResultStore interface {
+	// Get returns the recorded result for key, if any
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Put records the result for key
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// MemoryResultStore is an in-process ResultStore for tests and
+// single-worker deployments
+type MemoryResultStore struct {
+	mu      sync.RWMutex
+	results map[string][]byte
+}
+
+// NewMemoryResultStore creates an empty MemoryResultStore
+func NewMemoryResultStore() *MemoryResultStore {
+	return &MemoryResultStore{results: make(map[string][]byte)}
+}
+
+// Get implements ResultStore
+func (s *MemoryResultStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.results[key]
+	return data, ok, nil
+}
+
+// Put implements ResultStore
+func (s *MemoryResultStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = append([]byte(nil), data...)
+	return nil
+}
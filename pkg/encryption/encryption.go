@@ -0,0 +1,237 @@
+// Package encryption encrypts data at rest with AES-GCM: persisted
+// conversations, cached results and embeddings, and audit logs.
+//
+// Keys come from a KeyProvider, typically backed by a KMS or secret
+// manager. Every ciphertext records the ID of the key that sealed it, so
+// keys can be rotated without re-encrypting existing data at once: make the
+// new key current and keep the old ones for decryption. Data is re-sealed
+// with the current key the next time it is written, or explicitly with
+// Rotate.
+//
+//	keys, err := encryption.NewStaticKeys("2026-10", map[string][]byte{
+//		"2026-10": newKey,
+//		"2026-04": oldKey, // still decrypts older data
+//	})
+//	enc := encryption.NewEncrypter(keys)
+//	store := encryption.NewConversationStore(blobs, enc)
+//	chats := conversation.NewChatStore(store, conversation.ChatStoreOptions{})
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// version is the first byte of every ciphertext
+const version = 1
+
+// ErrDecrypt is returned when data cannot be decrypted: it was not sealed
+// by an Encrypter, was modified, or was sealed with different associated
+// data
+var ErrDecrypt = errors.New("encryption: message authentication failed")
+
+// KeyProvider supplies AES keys of 16, 24 or 32 bytes. Implementations must
+// be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKey returns the key new data is sealed with and its ID
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+
+	// Key returns the key with the given ID, for decryption
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider holding keys in memory, loaded e.g. from
+// environment variables or a secret manager at startup
+type StaticKeys struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeys creates a StaticKeys sealing with keys[current]
+func NewStaticKeys(current string, keys map[string][]byte) (*StaticKeys, error) {
+	s := &StaticKeys{keys: map[string][]byte{}}
+	for id, key := range keys {
+		if err := s.Add(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.SetCurrent(current); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add adds a key. Adding an existing ID replaces its key.
+func (s *StaticKeys) Add(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("key ID must be 1 to 255 bytes")
+	}
+	if err := checkKey(key); err != nil {
+		return fmt.Errorf("key %q: %w", id, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[id] = append([]byte(nil), key...)
+	return nil
+}
+
+// SetCurrent makes the key with the given ID the one new data is sealed
+// with
+func (s *StaticKeys) SetCurrent(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[id]; !ok {
+		return fmt.Errorf("unknown key %q", id)
+	}
+	s.current = id
+	return nil
+}
+
+// CurrentKey implements KeyProvider
+func (s *StaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current, s.keys[s.current], nil
+}
+
+// Key implements KeyProvider
+func (s *StaticKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	return key, nil
+}
+
+func checkKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+	return fmt.Errorf("AES keys must be 16, 24 or 32 bytes, got %d", len(key))
+}
+
+// Encrypter seals and opens data with AES-GCM. It is safe for concurrent
+// use.
+//
+// A sealed message is a version byte, the length-prefixed key ID, a random
+// 12-byte nonce and the ciphertext with its tag.
+type Encrypter struct {
+	keys KeyProvider
+}
+
+// NewEncrypter creates an Encrypter using keys
+func NewEncrypter(keys KeyProvider) *Encrypter {
+	return &Encrypter{keys: keys}
+}
+
+// Seal encrypts plaintext with the current key. associatedData is
+// authenticated but not encrypted; pass the record's key or ID so a
+// ciphertext cannot be swapped onto another record.
+func (e *Encrypter) Seal(ctx context.Context, plaintext, associatedData []byte) ([]byte, error) {
+	id, key, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current key: %w", err)
+	}
+	if id == "" || len(id) > 255 {
+		return nil, fmt.Errorf("key ID must be 1 to 255 bytes")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 2+len(id)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out = append(out, version, byte(len(id)))
+	out = append(out, id...)
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out = out[:len(out)+len(nonce)]
+	return aead.Seal(out, nonce, plaintext, associatedData), nil
+}
+
+// Open decrypts data sealed by Seal with the same associatedData
+func (e *Encrypter) Open(ctx context.Context, data, associatedData []byte) ([]byte, error) {
+	id, rest, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.keys.Key(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %q: %w", id, err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// Rotate re-seals data with the current key. It reports whether data was
+// sealed with an older key; when it was not, data is returned unchanged.
+func (e *Encrypter) Rotate(ctx context.Context, data, associatedData []byte) ([]byte, bool, error) {
+	id, err := KeyID(data)
+	if err != nil {
+		return nil, false, err
+	}
+	current, _, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the current key: %w", err)
+	}
+	if id == current {
+		return data, false, nil
+	}
+	plaintext, err := e.Open(ctx, data, associatedData)
+	if err != nil {
+		return nil, false, err
+	}
+	sealed, err := e.Seal(ctx, plaintext, associatedData)
+	if err != nil {
+		return nil, false, err
+	}
+	return sealed, true, nil
+}
+
+// KeyID returns the ID of the key data was sealed with
+func KeyID(data []byte) (string, error) {
+	id, _, err := parse(data)
+	return id, err
+}
+
+func parse(data []byte) (id string, rest []byte, err error) {
+	if len(data) < 2 || data[0] != version {
+		return "", nil, ErrDecrypt
+	}
+	n := int(data[1])
+	if n == 0 || len(data) < 2+n {
+		return "", nil, ErrDecrypt
+	}
+	return string(data[2 : 2+n]), data[2+n:], nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
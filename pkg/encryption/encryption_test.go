@@ -0,0 +1,156 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/durable"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func testKeys(t *testing.T) *StaticKeys {
+	t.Helper()
+	keys, err := NewStaticKeys("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestEncrypter_SealOpenAndRotate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keys := testKeys(t)
+	enc := NewEncrypter(keys)
+
+	sealed, err := enc.Seal(ctx, []byte("secret"), []byte("record-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("plaintext visible in ciphertext")
+	}
+	if _, err := enc.Open(ctx, sealed, []byte("record-2")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for other associated data, got %v", err)
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := enc.Open(ctx, tampered, []byte("record-1")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for modified data, got %v", err)
+	}
+
+	if err := keys.Add("k2", bytes.Repeat([]byte{2}, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.SetCurrent("k2"); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := enc.Open(ctx, sealed, []byte("record-1")); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("old key no longer decrypts: %q %v", plaintext, err)
+	}
+	rotated, changed, err := enc.Rotate(ctx, sealed, []byte("record-1"))
+	if err != nil || !changed {
+		t.Fatalf("Rotate = %v, %v", changed, err)
+	}
+	if id, _ := KeyID(rotated); id != "k2" {
+		t.Errorf("rotated key ID = %q", id)
+	}
+	if _, changed, _ := enc.Rotate(ctx, rotated, []byte("record-1")); changed {
+		t.Error("data sealed with the current key was rotated again")
+	}
+
+	if _, err := NewStaticKeys("k1", map[string][]byte{"k1": []byte("short")}); err == nil {
+		t.Error("expected an error for an invalid key length")
+	}
+}
+
+func TestConversationStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blobs := NewMemoryBlobStore()
+	chats := conversation.NewChatStore(NewConversationStore(blobs, NewEncrypter(testKeys(t))), conversation.ChatStoreOptions{})
+
+	msg := types.Message{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "my card is 4111"}}}
+	if _, err := chats.Append(ctx, "c1", msg); err != nil {
+		t.Fatal(err)
+	}
+	stored, _, _ := blobs.Get(ctx, "c1")
+	if bytes.Contains(stored, []byte("4111")) {
+		t.Error("conversation stored in plaintext")
+	}
+	loaded, err := chats.Load(ctx, "c1")
+	if err != nil || len(loaded.Messages) != 1 {
+		t.Fatalf("Load = %v, %v", loaded, err)
+	}
+
+	// A ciphertext moved to another ID does not decrypt
+	_ = blobs.Put(ctx, "c2", stored)
+	if _, err := chats.Load(ctx, "c2"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+	if _, err := chats.Load(ctx, "missing"); !errors.Is(err, conversation.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCacheWrappers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	enc := NewEncrypter(testKeys(t))
+
+	backing := durable.NewMemoryResultStore()
+	results := NewResultStore(backing, enc)
+	if err := results.Put(ctx, "job-1", []byte(`{"answer":42}`)); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _, _ := backing.Get(ctx, "job-1"); bytes.Contains(raw, []byte("answer")) {
+		t.Error("result stored in plaintext")
+	}
+	if data, ok, err := results.Get(ctx, "job-1"); err != nil || !ok || string(data) != `{"answer":42}` {
+		t.Errorf("Get = %q %v %v", data, ok, err)
+	}
+
+	kv := map[string][]byte{}
+	cache := NewKVEmbeddingCache(&middleware.KVEmbeddingCache{
+		Get: func(ctx context.Context, key string) ([]byte, bool, error) { v, ok := kv[key]; return v, ok, nil },
+		Set: func(ctx context.Context, key string, value []byte) error { kv[key] = value; return nil },
+	}, enc).Store()
+	if err := cache.Set(ctx, "e1", []float64{0.5, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if embedding, ok, err := cache.Get(ctx, "e1"); err != nil || !ok || embedding[1] != -1 {
+		t.Errorf("Get = %v %v %v", embedding, ok, err)
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	enc := NewEncrypter(testKeys(t))
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(NewLogWriter(ctx, &buf, enc), nil))
+	logger.Info("tool called", "user", "alice", "tool", "refund")
+	logger.Info("tool called", "user", "bob", "tool", "lookup")
+
+	if strings.Contains(buf.String(), "alice") || strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("unexpected log contents %q", buf.String())
+	}
+	var records []string
+	err := ReadLog(ctx, &buf, enc, func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	if err != nil || len(records) != 2 || !strings.Contains(records[0], `"user":"alice"`) {
+		t.Errorf("ReadLog = %v, %v", records, err)
+	}
+}
@@ -0,0 +1,227 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/conversation"
+	"github.com/digitallysavvy/go-ai/pkg/durable"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+)
+
+// BlobStore persists bytes by key, e.g. in a database table, Redis or an
+// object store. Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Get returns the data stored under key, reporting whether it exists
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Put stores data under key
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryBlobStore is an in-process BlobStore for tests and prototypes
+type MemoryBlobStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryBlobStore creates an empty MemoryBlobStore
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+// Get implements BlobStore
+func (s *MemoryBlobStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blobs[key]
+	return data, ok, nil
+}
+
+// Put implements BlobStore
+func (s *MemoryBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Delete implements BlobStore
+func (s *MemoryBlobStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// ConversationStore is a conversation.Store that keeps conversations
+// encrypted in a BlobStore, under their ID. Saving a conversation, which
+// ChatStore does on every Append, re-seals it with the current key.
+type ConversationStore struct {
+	blobs BlobStore
+	enc   *Encrypter
+}
+
+// NewConversationStore creates a ConversationStore
+func NewConversationStore(blobs BlobStore, enc *Encrypter) *ConversationStore {
+	return &ConversationStore{blobs: blobs, enc: enc}
+}
+
+// Load implements conversation.Store
+func (s *ConversationStore) Load(ctx context.Context, id string) (*conversation.Conversation, error) {
+	data, ok, err := s.blobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, conversation.ErrNotFound
+	}
+	plaintext, err := s.enc.Open(ctx, data, []byte("conversation:"+id))
+	if err != nil {
+		return nil, fmt.Errorf("conversation %q: %w", id, err)
+	}
+	return conversation.UnmarshalJSON(plaintext)
+}
+
+// Save implements conversation.Store
+func (s *ConversationStore) Save(ctx context.Context, c *conversation.Conversation) error {
+	plaintext, err := conversation.MarshalJSON(c)
+	if err != nil {
+		return err
+	}
+	data, err := s.enc.Seal(ctx, plaintext, []byte("conversation:"+c.ID))
+	if err != nil {
+		return err
+	}
+	return s.blobs.Put(ctx, c.ID, data)
+}
+
+// Delete implements conversation.Store
+func (s *ConversationStore) Delete(ctx context.Context, id string) error {
+	return s.blobs.Delete(ctx, id)
+}
+
+// resultStore encrypts a durable.ResultStore
+type resultStore struct {
+	store durable.ResultStore
+	enc   *Encrypter
+}
+
+// NewResultStore returns a durable.ResultStore that encrypts results before
+// they reach store
+func NewResultStore(store durable.ResultStore, enc *Encrypter) durable.ResultStore {
+	return &resultStore{store: store, enc: enc}
+}
+
+func (s *resultStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, ok, err := s.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	plaintext, err := s.enc.Open(ctx, data, []byte("result:"+key))
+	if err != nil {
+		return nil, false, fmt.Errorf("result %q: %w", key, err)
+	}
+	return plaintext, true, nil
+}
+
+func (s *resultStore) Put(ctx context.Context, key string, data []byte) error {
+	sealed, err := s.enc.Seal(ctx, data, []byte("result:"+key))
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, key, sealed)
+}
+
+// NewKVEmbeddingCache returns a KVEmbeddingCache that encrypts embeddings
+// before they reach kv. Embeddings can leak the text they were computed
+// from, so cache them encrypted when the text is sensitive.
+func NewKVEmbeddingCache(kv *middleware.KVEmbeddingCache, enc *Encrypter) *middleware.KVEmbeddingCache {
+	return &middleware.KVEmbeddingCache{
+		Get: func(ctx context.Context, key string) ([]byte, bool, error) {
+			data, ok, err := kv.Get(ctx, key)
+			if err != nil || !ok {
+				return nil, ok, err
+			}
+			plaintext, err := enc.Open(ctx, data, []byte("embedding:"+key))
+			if err != nil {
+				return nil, false, fmt.Errorf("embedding %q: %w", key, err)
+			}
+			return plaintext, true, nil
+		},
+		Set: func(ctx context.Context, key string, value []byte) error {
+			sealed, err := enc.Seal(ctx, value, []byte("embedding:"+key))
+			if err != nil {
+				return err
+			}
+			return kv.Set(ctx, key, sealed)
+		},
+	}
+}
+
+// LogWriter encrypts an append-only log such as an audit log. Each Write is
+// sealed as one record and written as a line of base64, so the log stays
+// line-oriented for shipping and rotation. Loggers that write one record
+// per call, like slog's handlers, map one log entry to one record.
+type LogWriter struct {
+	ctx context.Context
+	w   io.Writer
+	enc *Encrypter
+
+	mu sync.Mutex
+}
+
+// NewLogWriter creates a LogWriter writing to w. ctx is passed to the
+// KeyProvider.
+func NewLogWriter(ctx context.Context, w io.Writer, enc *Encrypter) *LogWriter {
+	return &LogWriter{ctx: ctx, w: w, enc: enc}
+}
+
+// Write seals p as one record
+func (l *LogWriter) Write(p []byte) (int, error) {
+	sealed, err := l.enc.Seal(l.ctx, p, nil)
+	if err != nil {
+		return 0, err
+	}
+	line := make([]byte, base64.StdEncoding.EncodedLen(len(sealed))+1)
+	base64.StdEncoding.Encode(line, sealed)
+	line[len(line)-1] = '\n'
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadLog decrypts a log written by LogWriter, calling fn with each record
+// in order. It stops at the first record that fails to decrypt.
+func ReadLog(ctx context.Context, r io.Reader, enc *Encrypter, fn func(record []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		sealed, err := base64.StdEncoding.DecodeString(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("log line %d: %w", n, err)
+		}
+		record, err := enc.Open(ctx, sealed, nil)
+		if err != nil {
+			return fmt.Errorf("log line %d: %w", n, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
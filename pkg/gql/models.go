@@ -0,0 +1,136 @@
+package gql
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ChatRole is the GraphQL ChatRole enum
+type ChatRole string
+
+const (
+	ChatRoleSystem    ChatRole = "SYSTEM"
+	ChatRoleUser      ChatRole = "USER"
+	ChatRoleAssistant ChatRole = "ASSISTANT"
+)
+
+// IsValid reports whether r is a known role
+func (r ChatRole) IsValid() bool {
+	switch r {
+	case ChatRoleSystem, ChatRoleUser, ChatRoleAssistant:
+		return true
+	}
+	return false
+}
+
+// UnmarshalGQL implements the gqlgen Unmarshaler interface
+func (r *ChatRole) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("ChatRole must be a string")
+	}
+	*r = ChatRole(s)
+	if !r.IsValid() {
+		return fmt.Errorf("%s is not a valid ChatRole", s)
+	}
+	return nil
+}
+
+// MarshalGQL implements the gqlgen Marshaler interface
+func (r ChatRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(r)))
+}
+
+// StreamPartType is the GraphQL StreamPartType enum
+type StreamPartType string
+
+const (
+	StreamPartTypeTextDelta      StreamPartType = "TEXT_DELTA"
+	StreamPartTypeReasoningDelta StreamPartType = "REASONING_DELTA"
+	StreamPartTypeToolCall       StreamPartType = "TOOL_CALL"
+	StreamPartTypeFinish         StreamPartType = "FINISH"
+	StreamPartTypeError          StreamPartType = "ERROR"
+)
+
+// IsValid reports whether t is a known part type
+func (t StreamPartType) IsValid() bool {
+	switch t {
+	case StreamPartTypeTextDelta, StreamPartTypeReasoningDelta, StreamPartTypeToolCall,
+		StreamPartTypeFinish, StreamPartTypeError:
+		return true
+	}
+	return false
+}
+
+// UnmarshalGQL implements the gqlgen Unmarshaler interface
+func (t *StreamPartType) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("StreamPartType must be a string")
+	}
+	*t = StreamPartType(s)
+	if !t.IsValid() {
+		return fmt.Errorf("%s is not a valid StreamPartType", s)
+	}
+	return nil
+}
+
+// MarshalGQL implements the gqlgen Marshaler interface
+func (t StreamPartType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(t)))
+}
+
+// ChatMessageInput is the GraphQL ChatMessageInput input
+type ChatMessageInput struct {
+	Role    ChatRole `json:"role"`
+	Content string   `json:"content"`
+}
+
+// GenerationSettingsInput is the GraphQL GenerationSettingsInput input
+type GenerationSettingsInput struct {
+	Temperature   *float64 `json:"temperature,omitempty"`
+	MaxTokens     *int     `json:"maxTokens,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// ChatInput is the GraphQL ChatInput input
+type ChatInput struct {
+	Model    *string                  `json:"model,omitempty"`
+	System   *string                  `json:"system,omitempty"`
+	Prompt   *string                  `json:"prompt,omitempty"`
+	Messages []*ChatMessageInput      `json:"messages,omitempty"`
+	Settings *GenerationSettingsInput `json:"settings,omitempty"`
+}
+
+// Usage is the GraphQL Usage type
+type Usage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	TotalTokens  int `json:"totalTokens"`
+}
+
+// ChatResult is the GraphQL ChatResult type
+type ChatResult struct {
+	Text         string `json:"text"`
+	FinishReason string `json:"finishReason"`
+	Usage        *Usage `json:"usage"`
+}
+
+// ToolCall is the GraphQL ToolCall type
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// StreamPart is the GraphQL StreamPart type
+type StreamPart struct {
+	Type         StreamPartType `json:"type"`
+	Text         *string        `json:"text,omitempty"`
+	ToolCall     *ToolCall      `json:"toolCall,omitempty"`
+	FinishReason *string        `json:"finishReason,omitempty"`
+	Usage        *Usage         `json:"usage,omitempty"`
+	Error        *string        `json:"error,omitempty"`
+}
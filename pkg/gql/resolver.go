@@ -0,0 +1,265 @@
+// Package gql exposes go-ai chat generation through GraphQL. It is written to
+// plug into gqlgen without depending on it: the schema is provided in
+// schema.graphqls (also available as Schema), the Go types in this package
+// can be bound as gqlgen models, and Resolver implements the mutation and
+// subscription with the signatures gqlgen generates.
+//
+// gqlgen.yml:
+//
+//	schema:
+//	  - graph/*.graphqls
+//	  - <path to go-ai>/pkg/gql/schema.graphqls
+//	autobind:
+//	  - github.com/digitallysavvy/go-ai/pkg/gql
+//
+// Generated resolvers then delegate to Resolver:
+//
+//	func (r *mutationResolver) Chat(ctx context.Context, input gql.ChatInput) (*gql.ChatResult, error) {
+//		return r.AI.Chat(ctx, input)
+//	}
+//
+//	func (r *subscriptionResolver) ChatStream(ctx context.Context, input gql.ChatInput) (<-chan *gql.StreamPart, error) {
+//		return r.AI.ChatStream(ctx, input)
+//	}
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+)
+
+// Schema is the GraphQL schema implemented by Resolver
+//
+//go:embed schema.graphqls
+var Schema string
+
+// Resolver implements the chat mutation and chatStream subscription
+type Resolver struct {
+	// ResolveModel looks up request models. Defaults to the global registry.
+	ResolveModel registry.ModelResolver
+
+	// DefaultModel is used when the input does not name a model (optional)
+	DefaultModel string
+
+	// AllowedModels restricts the models clients may request. Empty allows
+	// any model the resolver knows.
+	AllowedModels []string
+
+	// Tools are offered to the model on every request (optional). Calls are
+	// reported as TOOL_CALL parts when streaming.
+	Tools []types.Tool
+
+	// StreamBuffer is the capacity of the subscription channel (default 16)
+	StreamBuffer int
+}
+
+// NewResolver creates a Resolver that resolves models from the global
+// registry and falls back to defaultModel
+func NewResolver(defaultModel string) *Resolver {
+	return &Resolver{DefaultModel: defaultModel}
+}
+
+// Chat resolves the chat mutation
+func (r *Resolver) Chat(ctx context.Context, input ChatInput) (*ChatResult, error) {
+	opts, err := r.textOptions(input)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ai.GenerateText(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatResult{
+		Text:         result.Text,
+		FinishReason: string(result.FinishReason),
+		Usage:        toUsage(result.Usage),
+	}, nil
+}
+
+// ChatStream resolves the chatStream subscription. The channel is closed
+// after the FINISH or ERROR part, or when ctx is cancelled (gqlgen cancels
+// it when the client unsubscribes).
+func (r *Resolver) ChatStream(ctx context.Context, input ChatInput) (<-chan *StreamPart, error) {
+	opts, err := r.textOptions(input)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ai.StreamText(ctx, ai.StreamTextOptions{
+		Model:         opts.Model,
+		System:        opts.System,
+		Prompt:        opts.Prompt,
+		Messages:      opts.Messages,
+		Temperature:   opts.Temperature,
+		MaxTokens:     opts.MaxTokens,
+		TopP:          opts.TopP,
+		StopSequences: opts.StopSequences,
+		Tools:         opts.Tools,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	size := r.StreamBuffer
+	if size <= 0 {
+		size = 16
+	}
+	parts := make(chan *StreamPart, size)
+	go func() {
+		defer close(parts)
+		defer result.Close()
+
+		send := func(part *StreamPart) bool {
+			select {
+			case parts <- part:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var usage types.Usage
+		var finishReason string
+		for {
+			chunk, err := result.Stream().Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					send(errorPart(err.Error()))
+				}
+				return
+			}
+			if part := toStreamPart(chunk); part != nil {
+				if !send(part) {
+					return
+				}
+				if part.Type == StreamPartTypeError {
+					return
+				}
+			}
+			if chunk.Usage != nil && (chunk.Type == provider.ChunkTypeUsage || chunk.Type == provider.ChunkTypeFinish) {
+				usage = *chunk.Usage
+			}
+			if chunk.Type == provider.ChunkTypeFinish {
+				finishReason = string(chunk.FinishReason)
+			}
+		}
+		send(&StreamPart{Type: StreamPartTypeFinish, FinishReason: &finishReason, Usage: toUsage(usage)})
+	}()
+	return parts, nil
+}
+
+// toStreamPart maps a stream chunk to a GraphQL part; chunks without a
+// GraphQL representation return nil
+func toStreamPart(chunk *provider.StreamChunk) *StreamPart {
+	switch chunk.Type {
+	case provider.ChunkTypeText:
+		if chunk.Text == "" {
+			return nil
+		}
+		text := chunk.Text
+		return &StreamPart{Type: StreamPartTypeTextDelta, Text: &text}
+	case provider.ChunkTypeReasoning:
+		if chunk.Reasoning == "" {
+			return nil
+		}
+		text := chunk.Reasoning
+		return &StreamPart{Type: StreamPartTypeReasoningDelta, Text: &text}
+	case provider.ChunkTypeToolCall:
+		if chunk.ToolCall == nil {
+			return nil
+		}
+		args, err := json.Marshal(chunk.ToolCall.Arguments)
+		if err != nil || chunk.ToolCall.Arguments == nil {
+			args = []byte("{}")
+		}
+		return &StreamPart{Type: StreamPartTypeToolCall, ToolCall: &ToolCall{
+			ID:        chunk.ToolCall.ID,
+			Name:      chunk.ToolCall.ToolName,
+			Arguments: string(args),
+		}}
+	case provider.ChunkTypeError:
+		return errorPart(chunk.Text)
+	default:
+		return nil
+	}
+}
+
+func errorPart(msg string) *StreamPart {
+	return &StreamPart{Type: StreamPartTypeError, Error: &msg}
+}
+
+// textOptions validates the input and converts it to generation options
+func (r *Resolver) textOptions(input ChatInput) (ai.GenerateTextOptions, error) {
+	var opts ai.GenerateTextOptions
+
+	name := r.DefaultModel
+	if input.Model != nil && *input.Model != "" {
+		name = *input.Model
+	}
+	if name == "" {
+		return opts, fmt.Errorf("model is required")
+	}
+	resolve := r.ResolveModel
+	if resolve == nil {
+		resolve = registry.ResolveLanguageModel
+	}
+	model, err := registry.AllowModels(resolve, r.AllowedModels)(name)
+	if errors.Is(err, registry.ErrModelNotAllowed) {
+		return opts, fmt.Errorf("model %q is not allowed", name)
+	}
+	if err != nil {
+		return opts, fmt.Errorf("unknown model %q: %w", name, err)
+	}
+	opts.Model = model
+	opts.Tools = r.Tools
+
+	if input.System != nil {
+		opts.System = *input.System
+	}
+	if input.Prompt != nil {
+		opts.Prompt = *input.Prompt
+	}
+	if opts.Prompt != "" && len(input.Messages) > 0 {
+		return opts, fmt.Errorf("set either prompt or messages, not both")
+	}
+	if opts.Prompt == "" && len(input.Messages) == 0 {
+		return opts, fmt.Errorf("prompt or messages is required")
+	}
+	for i, m := range input.Messages {
+		if m == nil || !m.Role.IsValid() {
+			return opts, fmt.Errorf("message %d: invalid role", i)
+		}
+		opts.Messages = append(opts.Messages, types.Message{
+			Role:    types.MessageRole(strings.ToLower(string(m.Role))),
+			Content: []types.ContentPart{types.TextContent{Text: m.Content}},
+		})
+	}
+
+	if s := input.Settings; s != nil {
+		opts.Temperature = s.Temperature
+		opts.MaxTokens = s.MaxTokens
+		opts.TopP = s.TopP
+		opts.StopSequences = s.StopSequences
+	}
+	return opts, nil
+}
+
+func toUsage(u types.Usage) *Usage {
+	return &Usage{
+		InputTokens:  int(u.GetInputTokens()),
+		OutputTokens: int(u.GetOutputTokens()),
+		TotalTokens:  int(u.GetTotalTokens()),
+	}
+}
@@ -0,0 +1,120 @@
+package gql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func staticResolver(model provider.LanguageModel) registry.ModelResolver {
+	return func(string) (provider.LanguageModel, error) { return model, nil }
+}
+
+func TestResolver_Chat(t *testing.T) {
+	t.Parallel()
+
+	var got *provider.GenerateOptions
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			got = opts
+			return &types.GenerateResult{Text: "hi there", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	r := &Resolver{ResolveModel: staticResolver(model), DefaultModel: "mock:model"}
+
+	result, err := r.Chat(context.Background(), ChatInput{
+		Messages: []*ChatMessageInput{
+			{Role: ChatRoleSystem, Content: "Be nice"},
+			{Role: ChatRoleUser, Content: "hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if result.Text != "hi there" || result.FinishReason != "stop" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(got.Prompt.Messages) != 2 || got.Prompt.Messages[1].Role != types.RoleUser {
+		t.Errorf("messages not converted: %+v", got.Prompt.Messages)
+	}
+}
+
+func TestResolver_ChatStream(t *testing.T) {
+	t.Parallel()
+
+	total := int64(5)
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "Hel"},
+				{Type: provider.ChunkTypeText, Text: "lo"},
+				{Type: provider.ChunkTypeToolCall, ToolCall: &types.ToolCall{ID: "c1", ToolName: "lookup", Arguments: map[string]interface{}{"q": "x"}}},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonToolCalls, Usage: &types.Usage{TotalTokens: &total}},
+			}), nil
+		},
+	}
+	r := &Resolver{ResolveModel: staticResolver(model)}
+	prompt, name := "hi", "mock:model"
+
+	parts, err := r.ChatStream(context.Background(), ChatInput{Model: &name, Prompt: &prompt})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var text strings.Builder
+	var kinds []StreamPartType
+	var last *StreamPart
+	for part := range parts {
+		kinds = append(kinds, part.Type)
+		if part.Type == StreamPartTypeTextDelta {
+			text.WriteString(*part.Text)
+		}
+		last = part
+	}
+	if text.String() != "Hello" {
+		t.Errorf("text = %q", text.String())
+	}
+	want := []StreamPartType{StreamPartTypeTextDelta, StreamPartTypeTextDelta, StreamPartTypeToolCall, StreamPartTypeFinish}
+	if len(kinds) != len(want) {
+		t.Fatalf("parts = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("parts = %v, want %v", kinds, want)
+		}
+	}
+	if *last.FinishReason != "tool-calls" || last.Usage.TotalTokens != 5 {
+		t.Errorf("finish part = %+v", last)
+	}
+}
+
+func TestResolver_Validation(t *testing.T) {
+	t.Parallel()
+
+	r := &Resolver{ResolveModel: staticResolver(&testutil.MockLanguageModel{}), AllowedModels: []string{"a:b"}}
+	prompt, other := "hi", "c:d"
+
+	if _, err := r.Chat(context.Background(), ChatInput{Prompt: &prompt}); err == nil {
+		t.Error("expected error without a model")
+	}
+	if _, err := r.Chat(context.Background(), ChatInput{Model: &other, Prompt: &prompt}); err == nil {
+		t.Error("expected error for disallowed model")
+	}
+
+	var role ChatRole
+	if err := role.UnmarshalGQL("ROBOT"); err == nil {
+		t.Error("expected error for invalid role")
+	}
+}
+
+func TestSchemaEmbedded(t *testing.T) {
+	t.Parallel()
+
+	if !strings.Contains(Schema, "chatStream(input: ChatInput!): StreamPart!") {
+		t.Error("schema does not declare the chatStream subscription")
+	}
+}
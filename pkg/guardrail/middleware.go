@@ -0,0 +1,238 @@
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Source supplies the policy in force. *reload.Value[*Policy], returned by
+// Watch, is a Source.
+type Source interface {
+	Get() *Policy
+}
+
+type staticSource struct{ policy *Policy }
+
+func (s staticSource) Get() *Policy { return s.policy }
+
+// Static returns a Source that always supplies policy
+func Static(policy *Policy) Source {
+	return staticSource{policy}
+}
+
+type routeKey struct{}
+
+// WithRoute returns a context whose calls are checked as coming from route,
+// which is matched against the policy's exempt routes
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// ViolationError is returned when a block check fires on the input
+type ViolationError struct {
+	Violation Violation
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("guardrail %q blocked the %s: %s", e.Violation.Check, e.Violation.Stage, e.Violation.Reason)
+}
+
+// MiddlewareOptions configures Middleware
+type MiddlewareOptions struct {
+	// OnViolation is called for every check that fires, whatever its action
+	OnViolation func(ctx context.Context, violation Violation)
+}
+
+// Middleware returns middleware that enforces the policy supplied by source,
+// read afresh on every call so reloaded policies apply immediately.
+//
+// Input checks run over the prompt text and user messages: block rejects
+// the call with a *ViolationError, redact rewrites the text before it is
+// sent. Output checks run over the response: block replaces it with the
+// policy's refusal and FinishReasonContentFilter, redact rewrites it. Warn
+// checks add a warning to the result.
+//
+// Streamed output is scanned as it arrives, as by
+// middleware.ModerationStreamMiddleware, and only block checks apply to it:
+// redacting or warning needs the whole response.
+func Middleware(source Source, opts MiddlewareOptions) *middleware.LanguageModelMiddleware {
+	checkInput := func(ctx context.Context, policy *Policy, params *provider.GenerateOptions) (*provider.GenerateOptions, []types.Warning, error) {
+		route, _ := ctx.Value(routeKey{}).(string)
+		var warnings []types.Warning
+		var blocked error
+		evaluate := func(text string) string {
+			result := policy.Evaluate(ctx, StageInput, route, text)
+			warnings = append(warnings, report(ctx, opts, result)...)
+			if result.Blocked && blocked == nil {
+				blocked = &ViolationError{Violation: result.Violations[len(result.Violations)-1]}
+			}
+			return result.Text
+		}
+		transformed := redactInput(params, evaluate)
+		if blocked != nil {
+			return nil, nil, blocked
+		}
+		return transformed, warnings, nil
+	}
+
+	return &middleware.LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			policy := source.Get()
+			params, warnings, err := checkInput(ctx, policy, params)
+			if err != nil {
+				return nil, err
+			}
+			result, err := model.DoGenerate(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			route, _ := ctx.Value(routeKey{}).(string)
+			checked := policy.Evaluate(ctx, StageOutput, route, result.Text)
+			warnings = append(warnings, report(ctx, opts, checked)...)
+			switch {
+			case checked.Blocked:
+				result.Text = policy.Refusal()
+				result.Content = []types.ContentPart{types.TextContent{Text: result.Text}}
+				result.ToolCalls = nil
+				result.FinishReason = types.FinishReasonContentFilter
+			case checked.Text != result.Text:
+				result.Text = checked.Text
+				result.Content = replaceText(result.Content, checked.Text)
+			}
+			result.Warnings = append(result.Warnings, warnings...)
+			return result, nil
+		},
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			policy := source.Get()
+			params, _, err := checkInput(ctx, policy, params)
+			if err != nil {
+				return nil, err
+			}
+			route, _ := ctx.Value(routeKey{}).(string)
+			moderation := middleware.ModerationStreamMiddleware(&middleware.ModerationStreamOptions{
+				Refusal: policy.Refusal(),
+				Scanner: middleware.StreamScannerFunc(func(ctx context.Context, text string) (*middleware.StreamViolation, error) {
+					result := policy.Evaluate(ctx, StageOutput, route, text)
+					if !result.Blocked {
+						return nil, nil
+					}
+					violation := result.Violations[len(result.Violations)-1]
+					if opts.OnViolation != nil {
+						opts.OnViolation(ctx, violation)
+					}
+					return &middleware.StreamViolation{Category: violation.Check, Reason: violation.Reason}, nil
+				}),
+			})
+			return moderation.WrapStream(ctx, nil, func() (provider.TextStream, error) {
+				return model.DoStream(ctx, params)
+			}, params, model)
+		},
+	}
+}
+
+// report passes violations to OnViolation and returns warnings for the
+// warn checks among them
+func report(ctx context.Context, opts MiddlewareOptions, result Result) []types.Warning {
+	var warnings []types.Warning
+	for _, v := range result.Violations {
+		if opts.OnViolation != nil {
+			opts.OnViolation(ctx, v)
+		}
+		if v.Action == ActionWarn {
+			warnings = append(warnings, types.Warning{
+				Type:    "other",
+				Feature: "guardrail",
+				Details: fmt.Sprintf("%s %s: %s", v.Check, v.Stage, v.Reason),
+			})
+		}
+	}
+	return warnings
+}
+
+// redactInput passes the prompt text and each user text part through
+// evaluate, returning params with the results. params is copied only when
+// something changed.
+func redactInput(params *provider.GenerateOptions, evaluate func(text string) string) *provider.GenerateOptions {
+	transformed := *params
+	changed := false
+	if params.Prompt.Text != "" {
+		if text := evaluate(params.Prompt.Text); text != params.Prompt.Text {
+			transformed.Prompt.Text = text
+			changed = true
+		}
+	}
+	var messages []types.Message
+	copied := map[int]bool{}
+	for i, msg := range params.Prompt.Messages {
+		if msg.Role != types.RoleUser {
+			continue
+		}
+		for j, part := range msg.Content {
+			tc, ok := part.(types.TextContent)
+			if !ok {
+				continue
+			}
+			text := evaluate(tc.Text)
+			if text == tc.Text {
+				continue
+			}
+			if messages == nil {
+				messages = append([]types.Message(nil), params.Prompt.Messages...)
+			}
+			if !copied[i] {
+				messages[i].Content = append([]types.ContentPart(nil), msg.Content...)
+				copied[i] = true
+			}
+			tc.Text = text
+			messages[i].Content[j] = tc
+		}
+	}
+	if messages != nil {
+		transformed.Prompt.Messages = messages
+		changed = true
+	}
+	if !changed {
+		return params
+	}
+	return &transformed
+}
+
+// replaceText replaces the text parts of content with one part holding text,
+// where the first of them was
+func replaceText(content []types.ContentPart, text string) []types.ContentPart {
+	var out []types.ContentPart
+	placed := false
+	for _, part := range content {
+		if _, ok := part.(types.TextContent); ok {
+			if !placed {
+				out = append(out, types.TextContent{Text: text})
+				placed = true
+			}
+			continue
+		}
+		out = append(out, part)
+	}
+	if !placed && strings.TrimSpace(text) != "" {
+		out = append(out, types.TextContent{Text: text})
+	}
+	return out
+}
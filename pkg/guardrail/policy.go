@@ -0,0 +1,550 @@
+// Package guardrail runs declarative guardrail policies over model input and
+// output. A policy is written in YAML, so security teams can tune checks,
+// thresholds, actions and exempt routes without code changes, and Watch
+// reloads it while the server runs:
+//
+//	refusal: Sorry, I can't help with that.
+//	exempt_routes: [/internal/*]
+//	checks:
+//	  - name: api-keys
+//	    type: regex
+//	    patterns: ['sk-[A-Za-z0-9]{20,}']
+//	    action: redact
+//	  - name: competitors
+//	    type: keywords
+//	    words: [acme corp, globex]
+//	    stages: [output]
+//	    action: warn
+//	  - name: personal-data
+//	    type: pii
+//	    action: redact
+//	  - name: injection
+//	    type: prompt_injection
+//	    kinds: [instruction-override, role-impersonation]
+//	  - name: toxicity
+//	    type: toxicity        # a Checker registered by the application
+//	    threshold: 0.8
+//	    action: block
+//	    exempt_routes: [/moderators/*]
+//
+// Load it and wrap a model:
+//
+//	policy, err := guardrail.Watch("guardrails.yaml", guardrail.Checkers{"toxicity": perspective}, reload.Options{})
+//	...
+//	guard := guardrail.Middleware(policy, guardrail.MiddlewareOptions{})
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{guard}, nil, nil)
+//	result, err := ai.GenerateText(guardrail.WithRoute(ctx, r.URL.Path), ...)
+package guardrail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/reload"
+	"gopkg.in/yaml.v3"
+)
+
+// Stage is the side of a call a check applies to
+type Stage string
+
+const (
+	// StageInput checks the user's text before it is sent
+	StageInput Stage = "input"
+
+	// StageOutput checks the model's response
+	StageOutput Stage = "output"
+)
+
+// Action is what happens when a check fires
+type Action string
+
+const (
+	// ActionBlock rejects the input with a *ViolationError, or replaces the
+	// output with the policy's refusal (the default)
+	ActionBlock Action = "block"
+
+	// ActionRedact replaces the matched text. It applies to regex,
+	// keywords, pii and code_injection checks; other checks treat it as
+	// block.
+	ActionRedact Action = "redact"
+
+	// ActionWarn lets the call through and adds a warning to the result
+	ActionWarn Action = "warn"
+
+	// ActionLog only reports the violation to OnViolation
+	ActionLog Action = "log"
+)
+
+// Checker scores text for a custom check type. It returns a score from 0
+// (clean) to 1 (certain violation), compared against the check's
+// threshold. params holds the check's params from the policy file.
+type Checker func(ctx context.Context, text string, params map[string]any) (float64, error)
+
+// Checkers maps custom check types to their implementation
+type Checkers map[string]Checker
+
+// Config is a policy as written in YAML
+type Config struct {
+	// Refusal replaces blocked output
+	// Default: "I can't help with that."
+	Refusal string `yaml:"refusal"`
+
+	// ExemptRoutes are route patterns (path.Match syntax) no check applies to
+	ExemptRoutes []string `yaml:"exempt_routes"`
+
+	// Checks run in order
+	Checks []CheckConfig `yaml:"checks"`
+}
+
+// CheckConfig is one check as written in YAML
+type CheckConfig struct {
+	// Name identifies the check in violations (required)
+	Name string `yaml:"name"`
+
+	// Type is a built-in check type or a registered Checker (required).
+	// The built-in types are:
+	//   - regex and keywords: match Patterns or Words
+	//   - max_length: limit text to MaxChars
+	//   - pii: find email addresses, card and social security numbers,
+	//     phone numbers and IP addresses, as middleware.ScrubPII does.
+	//     Redaction uses placeholders such as "[EMAIL]".
+	//   - prompt_injection: find instruction overrides, fake roles, hidden
+	//     text and exfiltration links, as ai.InjectionDetector does
+	//   - code_injection: find the SQL injection, path traversal or shell
+	//     metacharacter patterns listed in Kinds, as ai.ToolArgGuard does.
+	//     The SQL and shell patterns also match ordinary prose, so use them
+	//     only where text is code or a command. Redaction neutralizes the
+	//     offending characters.
+	Type string `yaml:"type"`
+
+	// Stages the check applies to (default: input and output)
+	Stages []Stage `yaml:"stages"`
+
+	// Action taken when the check fires (default: block)
+	Action Action `yaml:"action"`
+
+	// Threshold is the score at which a Checker fires (default: 0.5)
+	Threshold float64 `yaml:"threshold"`
+
+	// ExemptRoutes are route patterns this check does not apply to
+	ExemptRoutes []string `yaml:"exempt_routes"`
+
+	// FailOpen lets text through when a Checker fails. By default a failed
+	// check fires.
+	FailOpen bool `yaml:"fail_open"`
+
+	// Patterns are the regular expressions of a regex check
+	Patterns []string `yaml:"patterns"`
+
+	// Words are the case-insensitive phrases of a keywords check
+	Words []string `yaml:"words"`
+
+	// MaxChars is the limit of a max_length check
+	MaxChars int `yaml:"max_chars"`
+
+	// Kinds restricts a prompt_injection check to these ai.InjectionKind
+	// values (default: all). A code_injection check requires them and
+	// looks only for these ai.ArgThreat values.
+	Kinds []string `yaml:"kinds"`
+
+	// Replacement is the text redacted matches are replaced with
+	// Default: "[REDACTED]"
+	Replacement string `yaml:"replacement"`
+
+	// Params are passed to a registered Checker
+	Params map[string]any `yaml:"params"`
+}
+
+// Policy is a compiled guardrail policy. It is immutable and safe for
+// concurrent use.
+type Policy struct {
+	refusal string
+	exempt  []string
+	checks  []*check
+}
+
+type check struct {
+	CheckConfig
+	patterns []*regexp.Regexp
+	checker  Checker
+	detector *detector
+}
+
+// detector is a built-in check backed by one of the SDK's detectors
+type detector struct {
+	// scan returns why text violates the check, or "" if it is clean
+	scan func(text string) string
+
+	// redact removes the violation from text; nil if the check cannot redact
+	redact func(text string) string
+}
+
+// Parse parses and compiles a YAML policy. Unknown keys, check types and
+// actions are rejected, so typos are reported instead of silently weakening
+// the policy.
+func Parse(data []byte, checkers Checkers) (*Policy, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var config Config
+	if err := dec.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid guardrail policy: %w", err)
+	}
+	return Compile(config, checkers)
+}
+
+// ParseFile reads and compiles a YAML policy file
+func ParseFile(path string, checkers Checkers) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read guardrail policy: %w", err)
+	}
+	policy, err := Parse(data, checkers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Watch loads a policy file and reloads it whenever it changes. An edit
+// that fails to compile is reported to opts.OnError and the previous policy
+// stays in force.
+func Watch(path string, checkers Checkers, opts reload.Options) (*reload.Value[*Policy], error) {
+	return reload.Watch(func() (*Policy, []string, error) {
+		policy, err := ParseFile(path, checkers)
+		if err != nil {
+			return nil, nil, err
+		}
+		return policy, []string{path}, nil
+	}, opts)
+}
+
+// Compile validates config and compiles it into a Policy
+func Compile(config Config, checkers Checkers) (*Policy, error) {
+	p := &Policy{refusal: config.Refusal, exempt: config.ExemptRoutes}
+	if p.refusal == "" {
+		p.refusal = "I can't help with that."
+	}
+	if err := validRoutes(p.exempt); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i, cfg := range config.Checks {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("check %d: name is required", i)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("check %q is declared twice", cfg.Name)
+		}
+		names[cfg.Name] = true
+		c, err := compileCheck(cfg, checkers)
+		if err != nil {
+			return nil, fmt.Errorf("check %q: %w", cfg.Name, err)
+		}
+		p.checks = append(p.checks, c)
+	}
+	return p, nil
+}
+
+func compileCheck(cfg CheckConfig, checkers Checkers) (*check, error) {
+	if len(cfg.Stages) == 0 {
+		cfg.Stages = []Stage{StageInput, StageOutput}
+	}
+	for _, s := range cfg.Stages {
+		if s != StageInput && s != StageOutput {
+			return nil, fmt.Errorf("unknown stage %q", s)
+		}
+	}
+	switch cfg.Action {
+	case "":
+		cfg.Action = ActionBlock
+	case ActionBlock, ActionRedact, ActionWarn, ActionLog:
+	default:
+		return nil, fmt.Errorf("unknown action %q", cfg.Action)
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 0.5
+	}
+	if cfg.Replacement == "" {
+		cfg.Replacement = "[REDACTED]"
+	}
+	if err := validRoutes(cfg.ExemptRoutes); err != nil {
+		return nil, err
+	}
+
+	c := &check{CheckConfig: cfg}
+	switch cfg.Type {
+	case "regex":
+		if len(cfg.Patterns) == 0 {
+			return nil, fmt.Errorf("regex check needs patterns")
+		}
+		for _, pattern := range cfg.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			c.patterns = append(c.patterns, re)
+		}
+	case "keywords":
+		if len(cfg.Words) == 0 {
+			return nil, fmt.Errorf("keywords check needs words")
+		}
+		quoted := make([]string, len(cfg.Words))
+		for i, w := range cfg.Words {
+			quoted[i] = regexp.QuoteMeta(w)
+		}
+		c.patterns = []*regexp.Regexp{regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)}
+	case "max_length":
+		if cfg.MaxChars <= 0 {
+			return nil, fmt.Errorf("max_length check needs max_chars")
+		}
+	case "pii":
+		c.detector = piiDetector()
+	case "prompt_injection":
+		d, err := promptInjectionDetector(cfg.Kinds)
+		if err != nil {
+			return nil, err
+		}
+		c.detector = d
+	case "code_injection":
+		d, err := codeInjectionDetector(cfg.Kinds)
+		if err != nil {
+			return nil, err
+		}
+		c.detector = d
+	case "":
+		return nil, fmt.Errorf("type is required")
+	default:
+		c.checker = checkers[cfg.Type]
+		if c.checker == nil {
+			return nil, fmt.Errorf("unknown check type %q", cfg.Type)
+		}
+	}
+	if len(cfg.Kinds) > 0 && cfg.Type != "prompt_injection" && cfg.Type != "code_injection" {
+		return nil, fmt.Errorf("kinds only apply to prompt_injection and code_injection checks")
+	}
+	return c, nil
+}
+
+func piiDetector() *detector {
+	return &detector{
+		scan: func(text string) string {
+			if middleware.ScrubPII(text) == text {
+				return ""
+			}
+			return "found personal information"
+		},
+		redact: middleware.ScrubPII,
+	}
+}
+
+var injectionKinds = []ai.InjectionKind{ai.InjectionInstructionOverride, ai.InjectionRoleImpersonation, ai.InjectionHiddenText, ai.InjectionExfiltration}
+
+func promptInjectionDetector(kinds []string) (*detector, error) {
+	d := &ai.InjectionDetector{}
+	for _, k := range kinds {
+		if !slices.Contains(injectionKinds, ai.InjectionKind(k)) {
+			return nil, fmt.Errorf("unknown prompt injection kind %q", k)
+		}
+		d.Kinds = append(d.Kinds, ai.InjectionKind(k))
+	}
+	return &detector{
+		scan: func(text string) string {
+			findings := d.Scan(text)
+			if len(findings) == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%s: %q", findings[0].Kind, findings[0].Match)
+		},
+	}, nil
+}
+
+var argThreats = []ai.ArgThreat{ai.ArgThreatSQLInjection, ai.ArgThreatPathTraversal, ai.ArgThreatShellMetacharacters}
+
+func codeInjectionDetector(kinds []string) (*detector, error) {
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("code_injection check needs kinds")
+	}
+	guard := ai.ToolArgGuard{}
+	for _, k := range kinds {
+		if !slices.Contains(argThreats, ai.ArgThreat(k)) {
+			return nil, fmt.Errorf("unknown code injection kind %q", k)
+		}
+		guard.Threats = append(guard.Threats, ai.ArgThreat(k))
+	}
+	return &detector{
+		scan: func(text string) string {
+			violations := ai.CheckToolArgs("", map[string]interface{}{"text": text}, guard)
+			if len(violations) == 0 {
+				return ""
+			}
+			return fmt.Sprintf("possible %s", violations[0].Threat)
+		},
+		redact: func(text string) string {
+			sanitized, _ := ai.SanitizeToolArgs(map[string]interface{}{"text": text}, guard)["text"].(string)
+			return sanitized
+		},
+	}, nil
+}
+
+func validRoutes(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid route pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func routeMatches(patterns []string, route string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, route); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is a check that fired
+type Violation struct {
+	// Check is the name of the check
+	Check string
+
+	// Stage the text was checked at
+	Stage Stage
+
+	// Action the check took
+	Action Action
+
+	// Score is the Checker's score, or 1 for built-in checks
+	Score float64
+
+	// Reason explains the violation, for logs
+	Reason string
+}
+
+// Result is the outcome of Evaluate
+type Result struct {
+	// Text is the input text with redactions applied
+	Text string
+
+	// Violations lists every check that fired, in policy order
+	Violations []Violation
+
+	// Blocked is set when a block check fired
+	Blocked bool
+}
+
+// Refusal returns the text that replaces blocked output
+func (p *Policy) Refusal() string {
+	return p.refusal
+}
+
+// Evaluate runs the checks that apply to stage and route over text. Checks
+// run in order, each on the text as redacted by the ones before it, and
+// evaluation stops at the first block.
+func (p *Policy) Evaluate(ctx context.Context, stage Stage, route string, text string) Result {
+	result := Result{Text: text}
+	if route != "" && routeMatches(p.exempt, route) {
+		return result
+	}
+	for _, c := range p.checks {
+		if !c.applies(stage, route) {
+			continue
+		}
+		violation, redacted := c.run(ctx, result.Text)
+		if violation == nil {
+			continue
+		}
+		violation.Stage = stage
+		result.Violations = append(result.Violations, *violation)
+		switch violation.Action {
+		case ActionBlock:
+			result.Blocked = true
+			return result
+		case ActionRedact:
+			result.Text = redacted
+		}
+	}
+	return result
+}
+
+func (c *check) applies(stage Stage, route string) bool {
+	if route != "" && routeMatches(c.ExemptRoutes, route) {
+		return false
+	}
+	for _, s := range c.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// run runs the check, returning the violation, if any, and the redacted
+// text for redact checks
+func (c *check) run(ctx context.Context, text string) (*Violation, string) {
+	violation := &Violation{Check: c.Name, Action: c.Action, Score: 1}
+	switch {
+	case c.patterns != nil:
+		matched := false
+		for _, re := range c.patterns {
+			if loc := re.FindStringIndex(text); loc != nil {
+				if !matched {
+					violation.Reason = fmt.Sprintf("matched %q", text[loc[0]:loc[1]])
+				}
+				matched = true
+				if c.Action == ActionRedact {
+					text = re.ReplaceAllLiteralString(text, c.Replacement)
+				}
+			}
+		}
+		if !matched {
+			return nil, ""
+		}
+		return violation, text
+
+	case c.detector != nil:
+		violation.Reason = c.detector.scan(text)
+		if violation.Reason == "" {
+			return nil, ""
+		}
+		if c.Action == ActionRedact && c.detector.redact != nil {
+			return violation, c.detector.redact(text)
+		}
+
+	case c.Type == "max_length":
+		n := len([]rune(text))
+		if n <= c.MaxChars {
+			return nil, ""
+		}
+		violation.Reason = fmt.Sprintf("%d characters exceeds %d", n, c.MaxChars)
+
+	default:
+		score, err := c.checker(ctx, text, c.Params)
+		switch {
+		case err != nil && c.FailOpen:
+			return nil, ""
+		case err != nil:
+			violation.Reason = fmt.Sprintf("check failed: %v", err)
+		case score < c.Threshold:
+			return nil, ""
+		default:
+			violation.Score = score
+			violation.Reason = fmt.Sprintf("score %.2f reached threshold %.2f", score, c.Threshold)
+		}
+	}
+	// Only pattern and redacting detector checks know what to redact
+	if violation.Action == ActionRedact {
+		violation.Action = ActionBlock
+	}
+	return violation, text
+}
@@ -0,0 +1,123 @@
+// Package audio converts between PCM samples and WAV files for speech
+// features
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Resample converts mono PCM between sample rates using linear interpolation.
+// It is intended for speech, where the quality loss is inaudible on a phone line.
+func Resample(samples []int16, fromRate, toRate int) []int16 {
+	if fromRate == toRate || len(samples) == 0 || fromRate <= 0 || toRate <= 0 {
+		return samples
+	}
+	n := int(int64(len(samples)) * int64(toRate) / int64(fromRate))
+	out := make([]int16, n)
+	ratio := float64(fromRate) / float64(toRate)
+	for i := range out {
+		pos := float64(i) * ratio
+		j := int(pos)
+		if j+1 >= len(samples) {
+			out[i] = samples[len(samples)-1]
+			continue
+		}
+		frac := pos - float64(j)
+		out[i] = int16(float64(samples[j])*(1-frac) + float64(samples[j+1])*frac)
+	}
+	return out
+}
+
+// EncodeWAV wraps mono 16-bit PCM samples in a WAV container, the format most
+// transcription models accept
+func EncodeWAV(samples []int16, sampleRate int) []byte {
+	dataLen := len(samples) * 2
+	buf := make([]byte, 44+dataLen)
+	copy(buf[0:], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:], uint32(36+dataLen))
+	copy(buf[8:], "WAVE")
+	copy(buf[12:], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:], 16)
+	binary.LittleEndian.PutUint16(buf[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(buf[22:], 1) // mono
+	binary.LittleEndian.PutUint32(buf[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(buf[28:], uint32(sampleRate*2))
+	binary.LittleEndian.PutUint16(buf[32:], 2)
+	binary.LittleEndian.PutUint16(buf[34:], 16)
+	copy(buf[36:], "data")
+	binary.LittleEndian.PutUint32(buf[40:], uint32(dataLen))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[44+i*2:], uint16(s))
+	}
+	return buf
+}
+
+// DecodeWAV extracts 16-bit PCM samples and the sample rate from a WAV file.
+// Multi-channel audio is downmixed to mono.
+func DecodeWAV(data []byte) ([]int16, int, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, errors.New("not a WAV file")
+	}
+
+	var sampleRate, channels, bitsPerSample int
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4:]))
+		body := data[off+8:]
+		// Streaming encoders write a placeholder size for the data chunk
+		if size > len(body) || size < 0 {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, 0, errors.New("invalid WAV fmt chunk")
+			}
+			if format := binary.LittleEndian.Uint16(body[0:]); format != 1 {
+				return nil, 0, fmt.Errorf("unsupported WAV encoding %d (only PCM is supported)", format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:]))
+		case "data":
+			if sampleRate == 0 {
+				return nil, 0, errors.New("WAV data chunk before fmt chunk")
+			}
+			if bitsPerSample != 16 {
+				return nil, 0, fmt.Errorf("unsupported WAV bit depth %d (only 16-bit is supported)", bitsPerSample)
+			}
+			return downmix(DecodePCM16(body), channels), sampleRate, nil
+		}
+		off += 8 + size + size%2
+	}
+	return nil, 0, errors.New("WAV file has no data chunk")
+}
+
+// DecodePCM16 interprets raw little-endian 16-bit PCM bytes as samples
+func DecodePCM16(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples
+}
+
+// downmix averages interleaved channels into a single channel
+func downmix(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+	out := make([]int16, len(samples)/channels)
+	for i := range out {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += int(samples[i*channels+c])
+		}
+		out[i] = int16(sum / channels)
+	}
+	return out
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
 )
 
 // DefaultHTTPClient is a shared HTTP client with sensible defaults
@@ -77,6 +79,10 @@ type Request struct {
 	Headers map[string]string
 	Body    interface{}
 	Query   map[string]string
+
+	// RawBody is sent as-is instead of JSON-encoding Body, e.g. for
+	// multipart uploads. Set its Content-Type in Headers.
+	RawBody io.Reader
 }
 
 // Response represents an HTTP response
@@ -86,8 +92,45 @@ type Response struct {
 	Body       []byte
 }
 
-// Do performs an HTTP request
+// Do performs an HTTP request. When ctx has a deadline the request is
+// bounded by provider.DeadlineBudget, and running out of time returns a
+// DeadlineExceededError.
 func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	reqCtx, cancel, client, budget := c.withDeadline(ctx)
+	defer cancel()
+
+	httpReq, err := c.newRequest(reqCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, deadlineError(reqCtx, client, budget, fmt.Errorf("LHTTP request failed: %w", err))
+	}
+	defer httpResp.Body.Close() //nolint:errcheck
+
+	// Read response body
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, deadlineError(reqCtx, client, budget, fmt.Errorf("failed to read response body: %w", err))
+	}
+
+	resp := &Response{
+		StatusCode: httpResp.StatusCode,
+		Headers:    httpResp.Header,
+		Body:       respBody,
+	}
+	if err := transformResponse(ctx, resp, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// newRequest builds the HTTP request for req, applying any request
+// transforms set on ctx with provider.ContextWithHTTPTransforms
+func (c *Client) newRequest(ctx context.Context, req Request) (*http.Request, error) {
 	// Build full URL
 	url := c.baseURL + req.Path
 	if len(req.Query) > 0 {
@@ -103,8 +146,8 @@ func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 	}
 
 	// Serialize body if present
-	var bodyReader io.Reader
-	if req.Body != nil {
+	bodyReader := req.RawBody
+	if req.Body != nil && req.RawBody == nil {
 		bodyBytes, err := json.Marshal(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
@@ -112,45 +155,68 @@ func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
+	header := make(http.Header)
 
 	// Add default headers
 	for k, v := range c.headers {
-		httpReq.Header.Set(k, v)
+		header.Set(k, v)
 	}
 
 	// Add request-specific headers
 	for k, v := range req.Headers {
-		httpReq.Header.Set(k, v)
+		header.Set(k, v)
 	}
 
 	// Set content type for JSON body
-	if req.Body != nil {
-		httpReq.Header.Set("Content-Type", "application/json")
+	if req.Body != nil && req.RawBody == nil {
+		header.Set("Content-Type", "application/json")
 	}
 
-	// Perform request
-	httpResp, err := c.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("LHTTP request failed: %w", err)
+	method := req.Method
+	if transforms := provider.HTTPTransformsFromContext(ctx); transforms != nil && len(transforms.Request) > 0 {
+		var body []byte
+		if bodyReader != nil {
+			var err error
+			if body, err = io.ReadAll(bodyReader); err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+		}
+		transformed := &provider.HTTPRequest{Method: method, URL: url, Header: header, Body: body}
+		for _, transform := range transforms.Request {
+			if err := transform(ctx, transformed); err != nil {
+				return nil, fmt.Errorf("request transform failed: %w", err)
+			}
+		}
+		method, url, header = transformed.Method, transformed.URL, transformed.Header
+		bodyReader = nil
+		if transformed.Body != nil {
+			bodyReader = bytes.NewReader(transformed.Body)
+		}
 	}
-	defer httpResp.Body.Close() //nolint:errcheck
 
-	// Read response body
-	respBody, err := io.ReadAll(httpResp.Body)
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
+	httpReq.Header = header
+	return httpReq, nil
+}
 
-	return &Response{
-		StatusCode: httpResp.StatusCode,
-		Headers:    httpResp.Header,
-		Body:       respBody,
-	}, nil
+// transformResponse applies any response transforms set on ctx
+func transformResponse(ctx context.Context, resp *Response, stream bool) error {
+	transforms := provider.HTTPTransformsFromContext(ctx)
+	if transforms == nil || len(transforms.Response) == 0 {
+		return nil
+	}
+	transformed := &provider.HTTPResponse{StatusCode: resp.StatusCode, Header: resp.Headers, Body: resp.Body, Stream: stream}
+	for _, transform := range transforms.Response {
+		if err := transform(ctx, transformed); err != nil {
+			return fmt.Errorf("response transform failed: %w", err)
+		}
+	}
+	resp.StatusCode, resp.Headers, resp.Body = transformed.StatusCode, transformed.Header, transformed.Body
+	return nil
 }
 
 // DoJSON performs an HTTP request and decodes the JSON response
@@ -162,7 +228,7 @@ func (c *Client) DoJSON(ctx context.Context, req Request, result interface{}) er
 
 	// Check for error status codes
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("LHTTP %d: %s", resp.StatusCode, string(resp.Body))
+		return statusError(resp.StatusCode, resp.Body)
 	}
 
 	// Decode JSON response
@@ -183,7 +249,7 @@ func (c *Client) DoJSONResponse(ctx context.Context, req Request, result interfa
 		return nil, err
 	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("LHTTP %d: %s", resp.StatusCode, string(resp.Body))
+		return nil, statusError(resp.StatusCode, resp.Body)
 	}
 	if err := json.Unmarshal(resp.Body, result); err != nil {
 		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
@@ -191,65 +257,45 @@ func (c *Client) DoJSONResponse(ctx context.Context, req Request, result interfa
 	return resp, nil
 }
 
-// DoStream performs an HTTP request that returns a streaming response
+// DoStream performs an HTTP request that returns a streaming response. As
+// with Do, the ctx deadline bounds the request, including reading the
+// stream.
 func (c *Client) DoStream(ctx context.Context, req Request) (*http.Response, error) {
-	// Build full URL
-	url := c.baseURL + req.Path
-	if len(req.Query) > 0 {
-		url += "?"
-		first := true
-		for k, v := range req.Query {
-			if !first {
-				url += "&"
-			}
-			url += fmt.Sprintf("%s=%s", k, v)
-			first = false
-		}
-	}
-
-	// Serialize body if present
-	var bodyReader io.Reader
-	if req.Body != nil {
-		bodyBytes, err := json.Marshal(req.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		bodyReader = bytes.NewReader(bodyBytes)
-	}
+	reqCtx, cancel, client, budget := c.withDeadline(ctx)
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, bodyReader)
+	httpReq, err := c.newRequest(reqCtx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Add default headers
-	for k, v := range c.headers {
-		httpReq.Header.Set(k, v)
-	}
-
-	// Add request-specific headers
-	for k, v := range req.Headers {
-		httpReq.Header.Set(k, v)
-	}
-
-	// Set content type for JSON body
-	if req.Body != nil {
-		httpReq.Header.Set("Content-Type", "application/json")
+		cancel()
+		return nil, err
 	}
 
 	// Perform request
-	httpResp, err := c.client.Do(httpReq)
+	httpResp, err := client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("LHTTP request failed: %w", err)
+		cancel()
+		return nil, deadlineError(reqCtx, client, budget, fmt.Errorf("LHTTP request failed: %w", err))
 	}
 
 	// Check for error status codes
 	if httpResp.StatusCode >= 400 {
+		defer cancel()
 		defer httpResp.Body.Close() //nolint:errcheck
 		errBody, _ := io.ReadAll(httpResp.Body)
-		return nil, fmt.Errorf("LHTTP %d: %s", httpResp.StatusCode, string(errBody))
+		resp := &Response{StatusCode: httpResp.StatusCode, Headers: httpResp.Header, Body: errBody}
+		if err := transformResponse(ctx, resp, false); err != nil {
+			return nil, err
+		}
+		return nil, statusError(resp.StatusCode, resp.Body)
+	}
+
+	resp := &Response{StatusCode: httpResp.StatusCode, Headers: httpResp.Header}
+	if err := transformResponse(ctx, resp, true); err != nil {
+		httpResp.Body.Close() //nolint:errcheck
+		cancel()
+		return nil, err
 	}
+	httpResp.StatusCode, httpResp.Header = resp.StatusCode, resp.Headers
+	httpResp.Body = &deadlineBody{ReadCloser: httpResp.Body, reqCtx: reqCtx, client: client, budget: budget, cancel: cancel}
 
 	// Return the response for streaming (caller must close Body)
 	return httpResp, nil
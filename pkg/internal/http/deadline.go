@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+// withDeadline bounds ctx by its deadline budget and returns the HTTP client
+// to send with. Under a deadline the budget replaces the default client
+// timeout; a configured client timeout still applies when it is shorter.
+func (c *Client) withDeadline(ctx context.Context) (context.Context, context.CancelFunc, *http.Client, time.Duration) {
+	budget, ok := provider.DeadlineBudget(ctx)
+	if !ok {
+		return ctx, func() {}, c.client, 0
+	}
+	client := c.client
+	if client.Timeout > 0 && (client == DefaultHTTPClient || client.Timeout >= budget) {
+		unbounded := *client
+		unbounded.Timeout = 0
+		client = &unbounded
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, budget)
+	return reqCtx, cancel, client, budget
+}
+
+// deadlineError returns err as a DeadlineExceededError when the request ran
+// out of time on the SDK side: its deadline budget or the client timeout
+func deadlineError(reqCtx context.Context, client *http.Client, budget time.Duration, err error) error {
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceSDK, budget, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceSDK, client.Timeout, err)
+	}
+	return err
+}
+
+// statusError returns the error for a failed response. Timeout statuses
+// (408 and 504) become provider DeadlineExceededErrors.
+func statusError(statusCode int, body []byte) error {
+	err := fmt.Errorf("LHTTP %d: %s", statusCode, string(body))
+	if statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout {
+		deadlineErr := providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceProvider, 0, err)
+		deadlineErr.StatusCode = statusCode
+		return deadlineErr
+	}
+	return err
+}
+
+// deadlineBody releases a streaming request's deadline when the body is
+// closed, and reports reads cut off by it as DeadlineExceededErrors
+type deadlineBody struct {
+	io.ReadCloser
+	reqCtx context.Context
+	client *http.Client
+	budget time.Duration
+	cancel context.CancelFunc
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = deadlineError(b.reqCtx, b.client, b.budget, err)
+	}
+	return n, err
+}
+
+func (b *deadlineBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
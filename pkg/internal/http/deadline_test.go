@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+func TestDo_DeadlineBudget(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := NewClient(Config{BaseURL: srv.URL}).Get(ctx, "/")
+
+	var deadlineErr *providererrors.DeadlineExceededError
+	if !errors.As(err, &deadlineErr) || deadlineErr.Source != providererrors.DeadlineSourceSDK {
+		t.Fatalf("expected an SDK DeadlineExceededError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected the error to match context.DeadlineExceeded")
+	}
+	// The budget leaves a margin before the caller's deadline
+	if ctx.Err() != nil || deadlineErr.Timeout <= 0 || deadlineErr.Timeout >= time.Second {
+		t.Errorf("expected to fail within the budget, got timeout %v (ctx err %v)", deadlineErr.Timeout, ctx.Err())
+	}
+}
+
+func TestDo_ShorterClientTimeoutApplies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := NewClient(Config{BaseURL: srv.URL, Timeout: 50 * time.Millisecond}).Get(ctx, "/")
+
+	var deadlineErr *providererrors.DeadlineExceededError
+	if !errors.As(err, &deadlineErr) || deadlineErr.Timeout != 50*time.Millisecond {
+		t.Fatalf("expected the 50ms client timeout, got %v", err)
+	}
+}
+
+func TestDoStream_DeadlineCutsOffBody(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: partial\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	resp, err := NewClient(Config{BaseURL: srv.URL}).DoStream(ctx, Request{Method: http.MethodGet, Path: "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	_, err = io.ReadAll(resp.Body)
+	if !providererrors.IsDeadlineExceededError(err) {
+		t.Errorf("expected a DeadlineExceededError reading the stream, got %v", err)
+	}
+}
+
+func TestDoJSON_ProviderTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer srv.Close()
+
+	var out map[string]interface{}
+	err := NewClient(Config{BaseURL: srv.URL}).GetJSON(context.Background(), "/", &out)
+
+	var deadlineErr *providererrors.DeadlineExceededError
+	if !errors.As(err, &deadlineErr) || deadlineErr.Source != providererrors.DeadlineSourceProvider || deadlineErr.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("expected a provider DeadlineExceededError, got %v", err)
+	}
+}
@@ -2,8 +2,12 @@ package polling
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 )
 
 // JobStatus represents the status of an async job
@@ -78,7 +82,9 @@ func DefaultPollOptions() PollOptions {
 }
 
 // PollForCompletion polls a job until completion or timeout
-// Returns the JobResult when complete, or an error on timeout/failure
+// Returns the JobResult when complete, or an error on timeout/failure.
+// A ctx deadline caps the polling time at provider.DeadlineBudget, and
+// running out of time returns a DeadlineExceededError.
 func PollForCompletion(ctx context.Context, checker StatusChecker, opts PollOptions) (*JobResult, error) {
 	// Set defaults
 	if opts.PollIntervalMs == 0 {
@@ -96,6 +102,9 @@ func PollForCompletion(ctx context.Context, checker StatusChecker, opts PollOpti
 
 	interval := time.Duration(opts.PollIntervalMs) * time.Millisecond
 	timeout := time.Duration(opts.PollTimeoutMs) * time.Millisecond
+	if budget, ok := provider.DeadlineBudget(ctx); ok && budget < timeout {
+		timeout = budget
+	}
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -108,10 +117,14 @@ func PollForCompletion(ctx context.Context, checker StatusChecker, opts PollOpti
 	for {
 		select {
 		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceSDK, timeout, ctx.Err())
+			}
 			return nil, ctx.Err()
 
 		case <-timeoutTimer.C:
-			return nil, fmt.Errorf("polling timeout after %v", timeout)
+			return nil, providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceSDK, timeout,
+				fmt.Errorf("polling timeout after %v", timeout))
 
 		case <-ticker.C:
 			attempts++
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 )
 
 // Config contains configuration for retry logic
@@ -27,6 +29,12 @@ type Config struct {
 	// ShouldRetry determines if an error should trigger a retry
 	// If nil, all errors trigger retries
 	ShouldRetry func(error) bool
+
+	// Clock measures backoff delays (default: the system clock)
+	Clock clock.Clock
+
+	// RNG provides jitter (default: math/rand)
+	RNG clock.RNG
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -48,7 +56,9 @@ type RetryFunc func(ctx context.Context) error
 func Do(ctx context.Context, cfg Config, fn RetryFunc) error {
 	// Use default config if not provided
 	if cfg.MaxRetries == 0 {
+		c, rng := cfg.Clock, cfg.RNG
 		cfg = DefaultConfig()
+		cfg.Clock, cfg.RNG = c, rng
 	}
 
 	var lastErr error
@@ -88,13 +98,8 @@ func Do(ctx context.Context, cfg Config, fn RetryFunc) error {
 		delay := calculateDelay(attempt, cfg)
 
 		// Wait before retrying
-		timer := time.NewTimer(delay)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
+		if err := clock.Sleep(ctx, cfg.Clock, delay); err != nil {
 			return fmt.Errorf("context cancelled after %d attempts: %w", attempt, lastErr)
-		case <-timer.C:
-			// Continue to next attempt
 		}
 	}
 
@@ -113,7 +118,7 @@ func calculateDelay(attempt int, cfg Config) time.Duration {
 
 	// Add jitter if enabled (random 0-25% variation)
 	if cfg.Jitter {
-		jitter := delay * 0.25 * (0.5 + (float64(time.Now().UnixNano()%1000) / 2000.0))
+		jitter := delay * 0.25 * (0.5 + clock.DefaultRNG(cfg.RNG).Float64()/2)
 		delay = delay + jitter
 	}
 
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 )
 
 func TestDo_Success(t *testing.T) {
@@ -318,3 +320,44 @@ func TestDo_ContextCancelledBeforeStart(t *testing.T) {
 		t.Errorf("expected 0 calls, got %d", calls)
 	}
 }
+
+func TestDo_FakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	cfg := Config{
+		MaxRetries:   2,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Jitter:       true,
+		Clock:        fake,
+		RNG:          clock.NewRNG(1),
+	}
+
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(context.Background(), cfg, func(ctx context.Context) error {
+			attempts++
+			return errors.New("fail")
+		})
+	}()
+
+	// Two backoffs: 1s and 2s, each plus up to 25% jitter
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(3 * time.Second)
+	}
+	if err := <-done; err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	// Same seed, same jitter
+	a := calculateDelay(1, Config{InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2, Jitter: true, RNG: clock.NewRNG(1)})
+	b := calculateDelay(1, Config{InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2, Jitter: true, RNG: clock.NewRNG(1)})
+	if a != b {
+		t.Errorf("expected reproducible jitter, got %v and %v", a, b)
+	}
+}
@@ -0,0 +1,198 @@
+// Package ws provides the websocket transport used by realtime providers
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"golang.org/x/net/websocket"
+)
+
+// Conn is a message-oriented websocket connection
+type Conn interface {
+	// ReadMessage returns the next text or binary message
+	ReadMessage() ([]byte, error)
+
+	// WriteText sends a text message
+	WriteText(data []byte) error
+
+	// WriteBinary sends a binary message
+	WriteBinary(data []byte) error
+
+	Close() error
+}
+
+// Dialer opens websocket connections. Providers accept one so tests and
+// callers can substitute their own transport.
+type Dialer func(ctx context.Context, rawURL string, header http.Header) (Conn, error)
+
+// Dial opens a websocket connection with the given request headers
+func Dial(ctx context.Context, rawURL string, header http.Header) (Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	origin := &url.URL{Scheme: "https", Host: u.Host}
+	if u.Scheme == "ws" {
+		origin.Scheme = "http"
+	}
+	config, err := websocket.NewConfig(rawURL, origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Header = header
+	c, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("websocket dial %s: %w", u.Host, err)
+	}
+	return &conn{ws: c}, nil
+}
+
+type conn struct {
+	ws *websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *conn) ReadMessage() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.ws, &data)
+	return data, err
+}
+
+func (c *conn) WriteText(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return websocket.Message.Send(c.ws, string(data))
+}
+
+func (c *conn) WriteBinary(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return websocket.Message.Send(c.ws, data)
+}
+
+func (c *conn) Close() error {
+	return c.ws.Close()
+}
+
+// ParseFunc converts a provider message into transcript events. Returning
+// done ends the session after the events are delivered.
+type ParseFunc func(msg []byte) (events []types.TranscriptEvent, done bool, err error)
+
+// TranscriptionSession implements provider.TranscriptionSession on top of a
+// Conn, reading messages in the background and parsing them with a ParseFunc
+type TranscriptionSession struct {
+	conn      Conn
+	sendAudio func(Conn, []byte) error
+	closeSend func(Conn) error
+
+	events    chan types.TranscriptEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewTranscriptionSession starts reading from conn. sendAudio frames audio
+// for the provider and closeSend sends its end-of-stream message.
+func NewTranscriptionSession(conn Conn, parse ParseFunc, sendAudio func(Conn, []byte) error, closeSend func(Conn) error) *TranscriptionSession {
+	s := &TranscriptionSession{
+		conn:      conn,
+		sendAudio: sendAudio,
+		closeSend: closeSend,
+		events:    make(chan types.TranscriptEvent, 64),
+		done:      make(chan struct{}),
+	}
+	go s.read(parse)
+	return s
+}
+
+func (s *TranscriptionSession) read(parse ParseFunc) {
+	defer close(s.events)
+	for {
+		msg, err := s.conn.ReadMessage()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				select {
+				case <-s.done:
+				default:
+					s.err = err
+				}
+			}
+			return
+		}
+		events, finished, err := parse(msg)
+		for _, e := range events {
+			select {
+			case s.events <- e:
+			case <-s.done:
+				return
+			}
+		}
+		if err != nil {
+			s.err = err
+			return
+		}
+		if finished {
+			return
+		}
+	}
+}
+
+// SendAudio implements provider.TranscriptionSession
+func (s *TranscriptionSession) SendAudio(frame []byte) error {
+	return s.sendAudio(s.conn, frame)
+}
+
+// Next implements provider.TranscriptionSession
+func (s *TranscriptionSession) Next() (*types.TranscriptEvent, error) {
+	e, ok := <-s.events
+	if !ok {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	return &e, nil
+}
+
+// CloseSend implements provider.TranscriptionSession
+func (s *TranscriptionSession) CloseSend() error {
+	return s.closeSend(s.conn)
+}
+
+// Close implements provider.TranscriptionSession
+func (s *TranscriptionSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// URL converts an http(s) base URL into a ws(s) URL with the given path
+// appended
+func URL(baseURL, path string, query url.Values) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,163 @@
+// Package jsonrepair recovers JSON from raw model output.
+//
+// Models asked for JSON frequently wrap it in markdown fences, surround it
+// with prose, leave trailing commas behind or stop mid-object when they run
+// out of tokens. The functions in this package undo those mistakes so the
+// result can be passed to encoding/json.
+//
+// Example:
+//
+//	var ticket Ticket
+//	if err := jsonrepair.Unmarshal(result.Text, &ticket); err != nil {
+//		return err
+//	}
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/jsonparser"
+)
+
+// ErrNoJSON is returned when the text contains nothing that looks like JSON
+var ErrNoJSON = errors.New("jsonrepair: no JSON found")
+
+// fencePattern matches a markdown code fence, capturing its contents
+var fencePattern = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\r?\n?(.*?)(?:```|$)")
+
+// Repair returns valid JSON text recovered from text. It strips markdown
+// fences and surrounding prose, removes trailing commas and closes strings,
+// objects and arrays left open by truncated output.
+func Repair(text string) (string, error) {
+	if json.Valid([]byte(strings.TrimSpace(text))) {
+		return strings.TrimSpace(text), nil
+	}
+
+	candidate := Extract(text)
+	if candidate == "" {
+		return "", ErrNoJSON
+	}
+	if json.Valid([]byte(candidate)) {
+		return candidate, nil
+	}
+
+	withoutCommas := RemoveTrailingCommas(candidate)
+	if json.Valid([]byte(withoutCommas)) {
+		return withoutCommas, nil
+	}
+
+	closed := RemoveTrailingCommas(Close(withoutCommas))
+	if json.Valid([]byte(closed)) {
+		return closed, nil
+	}
+
+	// Report the error for the best attempt
+	var v interface{}
+	err := json.Unmarshal([]byte(closed), &v)
+	if err == nil {
+		err = errors.New("jsonrepair: invalid JSON")
+	}
+	return "", err
+}
+
+// Parse repairs text and decodes it into a generic JSON value
+func Parse(text string) (interface{}, error) {
+	repaired, err := Repair(text)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(repaired), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Unmarshal repairs text and decodes it into v
+func Unmarshal(text string, v interface{}) error {
+	repaired, err := Repair(text)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(repaired), v)
+}
+
+// StripFences returns the contents of the first markdown code fence in text,
+// or text unchanged when it has none. An unterminated fence runs to the end
+// of text.
+func StripFences(text string) string {
+	if m := fencePattern.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+	return text
+}
+
+// Extract returns the JSON portion of text: the span from the first '{' or
+// '[' to its last closing counterpart, or to the end of text when the output
+// was cut off. Markdown fences are stripped first. It returns "" when text
+// contains no object or array.
+func Extract(text string) string {
+	text = StripFences(text)
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return ""
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return strings.TrimSpace(text[start:])
+	}
+	return text[start : end+1]
+}
+
+// RemoveTrailingCommas drops commas directly before a closing '}' or ']'
+// and a dangling comma at the end of text, leaving string contents untouched
+func RemoveTrailingCommas(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	inString := false
+	escaped := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			b.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(text) && strings.IndexByte(" \t\r\n", text[j]) >= 0 {
+				j++
+			}
+			if j == len(text) || text[j] == '}' || text[j] == ']' {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Close completes truncated JSON by closing an unterminated string,
+// finishing a partial true/false/null literal and closing open objects and
+// arrays
+func Close(text string) string {
+	return jsonparser.FixJSON(text)
+}
@@ -0,0 +1,132 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid object", `{"a":1}`, `{"a":1}`},
+		{"valid scalar", ` "hi" `, `"hi"`},
+		{"fenced", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"unterminated fence", "```json\n{\"a\": 1}", `{"a": 1}`},
+		{"prose around", `Sure! {"a": [1, 2]} Hope this helps.`, `{"a": [1, 2]}`},
+		{"trailing commas", `{"a": [1, 2,], "b": "x,}",}`, `{"a": [1, 2], "b": "x,}"}`},
+		{"truncated string", `{"a": {"b": "c`, `{"a": {"b": "c"}}`},
+		{"truncated after comma", `[1, 2,`, `[1, 2]`},
+		{"truncated literal", `{"ok": tr`, `{"ok": true}`},
+		{"array in fence", "```\n[{\"a\":1},]\n```", `[{"a":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Repair(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRepairNoJSON(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Repair("I cannot help with that."); !errors.Is(err, ErrNoJSON) {
+		t.Errorf("expected ErrNoJSON, got %v", err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	var got struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	if err := Unmarshal("Here:\n```json\n{\"name\": \"Ada\", \"tags\": [\"math\",", &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "Ada" || len(got.Tags) != 1 || got.Tags[0] != "math" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestRemoveTrailingCommas(t *testing.T) {
+	t.Parallel()
+
+	got := RemoveTrailingCommas(`{"a": "\",}", "b": [1 , ] ,}`)
+	want := `{"a": "\",}", "b": [1  ] }`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func FuzzRepair(f *testing.F) {
+	seeds := []string{
+		`{"a":1}`,
+		`[1, 2,`,
+		"```json\n{\"a\": [true, fal",
+		`Sure: {"a": "b\"c", "d": {"e": null,}}`,
+		`{"a": "é`,
+		`not json`,
+		`]{[`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		repaired, err := Repair(input)
+		if err != nil {
+			return
+		}
+		if !json.Valid([]byte(repaired)) {
+			t.Fatalf("Repair(%q) returned invalid JSON %q", input, repaired)
+		}
+
+		// Valid input must decode to the same value after repair
+		var want interface{}
+		if json.Unmarshal([]byte(input), &want) == nil {
+			got, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed on valid JSON: %v", input, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Parse(%q) = %v, want %v", input, got, want)
+			}
+		}
+	})
+}
+
+func FuzzRemoveTrailingCommas(f *testing.F) {
+	f.Add(`{"a": [1, 2,],}`)
+	f.Add(`["x,]", ,]`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Removing commas from valid JSON never changes its value
+		var want interface{}
+		if json.Unmarshal([]byte(input), &want) != nil {
+			return
+		}
+		var got interface{}
+		if err := json.Unmarshal([]byte(RemoveTrailingCommas(input)), &got); err != nil {
+			t.Fatalf("RemoveTrailingCommas(%q) broke valid JSON: %v", input, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("RemoveTrailingCommas(%q) changed value", input)
+		}
+	})
+}
@@ -0,0 +1,424 @@
+// Package markdown splits streamed markdown into render-safe pieces, so
+// terminal and HTML renderers never show half a code fence, a dangling
+// "**" or a table row cut in two.
+//
+// A Splitter tracks block state (code fences, lists, headings, quotes and
+// tables) across text deltas and emits Segments annotated with the block
+// they belong to. Stream applies a Splitter to a model stream:
+//
+//	stream, _ := model.DoStream(ctx, opts)
+//	md := markdown.NewStream(stream)
+//	for {
+//		event, err := md.Next()
+//		if err != nil {
+//			break
+//		}
+//		if event.Chunk == nil {
+//			renderer.Write(event.Segment)
+//		}
+//	}
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BlockType is the kind of markdown block a segment belongs to
+type BlockType string
+
+const (
+	// BlockParagraph is running text
+	BlockParagraph BlockType = "paragraph"
+
+	// BlockHeading is an ATX heading ("# Title")
+	BlockHeading BlockType = "heading"
+
+	// BlockCode is a fenced code block, fences included
+	BlockCode BlockType = "code"
+
+	// BlockList is a bulleted or numbered list
+	BlockList BlockType = "list"
+
+	// BlockQuote is a block quote
+	BlockQuote BlockType = "blockquote"
+
+	// BlockTable is a pipe table
+	BlockTable BlockType = "table"
+
+	// BlockBreak is a thematic break ("---")
+	BlockBreak BlockType = "thematic-break"
+)
+
+// Block describes a markdown block
+type Block struct {
+	Type BlockType
+
+	// Level is the heading level, 1 to 6
+	Level int `json:",omitempty"`
+
+	// Lang is the info string of a code fence, e.g. "go"
+	Lang string `json:",omitempty"`
+
+	// Ordered is set for numbered lists
+	Ordered bool `json:",omitempty"`
+}
+
+// Segment is a render-safe piece of markdown
+type Segment struct {
+	// Text is the markdown source. Concatenating every segment's Text
+	// reproduces the input exactly.
+	Text string
+
+	// Block is the block Text belongs to
+	Block Block
+
+	// Start is set on the first segment of a block; renderers close the
+	// previous block when they see it
+	Start bool
+}
+
+// Splitter turns markdown deltas into render-safe segments. Complete lines
+// are released as they arrive; partial lines are released only up to a word
+// boundary with balanced inline markup, and partial code fence and table
+// lines are held until the line is complete. The zero value is ready to use.
+type Splitter struct {
+	pending  string // unreleased text of the current line
+	lineText string // released text of the current line
+	started  bool   // the current block has begun
+	block    Block
+	fence    string // opening fence while inside a code block
+	boundary bool   // the next line cannot continue the current block
+}
+
+// Write adds a delta and returns the segments that are now safe to render
+func (s *Splitter) Write(text string) []Segment {
+	s.pending += text
+	var out []Segment
+	for {
+		i := strings.IndexByte(s.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := s.pending[:i+1]
+		s.pending = s.pending[i+1:]
+		out = s.completeLine(out, line)
+	}
+	return s.partialLine(out)
+}
+
+// Flush releases any held text, e.g. when the stream ends
+func (s *Splitter) Flush() []Segment {
+	if s.pending == "" {
+		return nil
+	}
+	line := s.pending
+	s.pending = ""
+	return s.completeLine(nil, line)
+}
+
+// InCodeBlock reports whether the text so far ends inside a code fence
+func (s *Splitter) InCodeBlock() bool {
+	return s.fence != ""
+}
+
+// completeLine releases the rest of a complete line and updates block state
+func (s *Splitter) completeLine(out []Segment, rest string) []Segment {
+	full := s.lineText + rest
+	line := strings.TrimRight(full, "\r\n")
+	start, opened := false, false
+	if s.lineText == "" {
+		start, opened = s.classify(line)
+	}
+	if rest != "" {
+		out = append(out, Segment{Text: rest, Block: s.block, Start: start})
+	}
+	s.lineText = ""
+
+	switch {
+	case s.fence != "" && !opened:
+		if isClosingFence(line, s.fence) {
+			s.fence = ""
+			s.boundary = true
+		}
+	case s.fence != "":
+	case strings.TrimSpace(line) == "", s.block.Type == BlockHeading, s.block.Type == BlockBreak:
+		s.boundary = true
+	default:
+		s.boundary = false
+	}
+	return out
+}
+
+// partialLine releases the safe prefix of the incomplete current line
+func (s *Splitter) partialLine(out []Segment) []Segment {
+	if s.pending == "" {
+		return out
+	}
+	start, saved := false, *s
+	if s.lineText == "" {
+		if s.ambiguous(s.pending) {
+			return out
+		}
+		start, _ = s.classify(s.pending)
+	}
+
+	n := len(s.pending)
+	switch s.block.Type {
+	case BlockCode:
+		// Code is rendered literally, so any text on a line that can no
+		// longer become a fence is safe
+	case BlockTable:
+		n = 0
+	default:
+		n = safePrefix(s.lineText, s.pending)
+	}
+	if n == 0 {
+		// Nothing was released, so the line is classified again later
+		*s = saved
+		return out
+	}
+	out = append(out, Segment{Text: s.pending[:n], Block: s.block, Start: start})
+	s.lineText += s.pending[:n]
+	s.pending = s.pending[n:]
+	return out
+}
+
+// ambiguous reports whether a partial line could still turn into a
+// different kind of block, or is an opening fence whose info string may be
+// incomplete
+func (s *Splitter) ambiguous(line string) bool {
+	rest := strings.TrimLeft(line, " \t")
+	if s.fence != "" {
+		return strings.Trim(rest, "`~ \t") == ""
+	}
+	if rest == "" || strings.Trim(rest, "#-*+_>|`~=.)0123456789 \t") == "" {
+		return true
+	}
+	_, fence := openingFence(line)
+	return fence
+}
+
+// classify decides the block of a line that starts without released text.
+// It returns whether the line starts a new block and whether it opens a
+// code fence.
+func (s *Splitter) classify(line string) (start, opened bool) {
+	if s.fence != "" {
+		return false, false
+	}
+
+	var block Block
+	continues := false
+	indented := strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")
+	rest := strings.TrimLeft(line, " \t")
+	switch {
+	case rest == "":
+		if !s.started {
+			s.block = Block{Type: BlockParagraph}
+		}
+		return false, false
+	case isFenceLine(line):
+		lang, _ := openingFence(line)
+		s.fence = fenceMarker(rest)
+		s.block, s.started = Block{Type: BlockCode, Lang: lang}, true
+		return true, true
+	case headingLevel(line) > 0:
+		block = Block{Type: BlockHeading, Level: headingLevel(line)}
+	case isThematicBreak(line):
+		block = Block{Type: BlockBreak}
+	case listMarker(rest) != "":
+		block = Block{Type: BlockList, Ordered: unicode.IsDigit(rune(rest[0]))}
+		continues = s.block.Type == BlockList
+	case strings.HasPrefix(rest, ">"):
+		block = Block{Type: BlockQuote}
+		continues = s.block.Type == BlockQuote && !s.boundary
+	case strings.HasPrefix(rest, "|"):
+		block = Block{Type: BlockTable}
+		continues = s.block.Type == BlockTable && !s.boundary
+	case s.block.Type == BlockList && indented:
+		block, continues = s.block, true
+	default:
+		block = Block{Type: BlockParagraph}
+		// Lazy continuation lines extend paragraphs, quotes and list items
+		switch s.block.Type {
+		case BlockParagraph, BlockQuote, BlockList:
+			if !s.boundary {
+				block, continues = s.block, true
+			}
+		}
+	}
+
+	if continues && s.started {
+		return false, false
+	}
+	s.block, s.started = block, true
+	return true, false
+}
+
+// safePrefix returns how much of pending can be released after the line's
+// already released text: up to the last whitespace at which inline code,
+// emphasis and links are balanced
+func safePrefix(released, pending string) int {
+	for i := len(pending); i > 0; i-- {
+		if !unicode.IsSpace(rune(pending[i-1])) {
+			continue
+		}
+		if !inlineOpen(released + pending[:i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// inlineOpen reports whether text ends inside a code span, emphasis or link
+func inlineOpen(text string) bool {
+	var (
+		tick     int // length of the open code span's backtick run
+		stars    int
+		brackets int
+		inURL    bool
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '`' {
+			j := i
+			for j < len(text) && text[j] == '`' {
+				j++
+			}
+			switch run := j - i; {
+			case tick == 0:
+				tick = run
+			case run == tick:
+				tick = 0
+			}
+			i = j - 1
+			continue
+		}
+		if tick > 0 {
+			continue
+		}
+		switch c {
+		case '*':
+			// Each run of stars is one delimiter; a run between spaces, like
+			// a list marker, is not emphasis
+			j := i
+			for j < len(text) && text[j] == '*' {
+				j++
+			}
+			before := i == 0 || unicode.IsSpace(rune(text[i-1]))
+			after := j == len(text) || unicode.IsSpace(rune(text[j]))
+			if !(before && after) {
+				stars++
+			}
+			i = j - 1
+		case '[':
+			brackets++
+		case ']':
+			if brackets > 0 {
+				brackets--
+				inURL = i+1 < len(text) && text[i+1] == '('
+			}
+		case ')':
+			inURL = false
+		}
+	}
+	return tick > 0 || stars%2 == 1 || brackets > 0 || inURL
+}
+
+// isFenceLine reports whether line opens a code fence
+func isFenceLine(line string) bool {
+	_, ok := openingFence(line)
+	return ok
+}
+
+// openingFence returns the info string's first word if line opens a code
+// fence
+func openingFence(line string) (string, bool) {
+	if indent(line) > 3 {
+		return "", false
+	}
+	rest := strings.TrimLeft(line, " ")
+	marker := fenceMarker(rest)
+	if len(marker) < 3 {
+		return "", false
+	}
+	info := strings.TrimSpace(rest[len(marker):])
+	if marker[0] == '`' && strings.Contains(info, "`") {
+		return "", false
+	}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		return fields[0], true
+	}
+	return "", true
+}
+
+// isClosingFence reports whether line closes a fence opened with open
+func isClosingFence(line, open string) bool {
+	if indent(line) > 3 {
+		return false
+	}
+	rest := strings.TrimSpace(line)
+	marker := fenceMarker(rest)
+	return len(marker) >= len(open) && marker[0] == open[0] && len(marker) == len(rest)
+}
+
+// fenceMarker returns the run of backticks or tildes at the start of s
+func fenceMarker(s string) string {
+	if s == "" || (s[0] != '`' && s[0] != '~') {
+		return ""
+	}
+	i := 0
+	for i < len(s) && s[i] == s[0] {
+		i++
+	}
+	return s[:i]
+}
+
+// headingLevel returns the level of an ATX heading line, or 0
+func headingLevel(line string) int {
+	if indent(line) > 3 {
+		return 0
+	}
+	rest := strings.TrimLeft(line, " ")
+	level := 0
+	for level < len(rest) && rest[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(rest) && rest[level] != ' ' && rest[level] != '\t') {
+		return 0
+	}
+	return level
+}
+
+// isThematicBreak reports whether line is three or more of the same "-",
+// "*" or "_", optionally separated by spaces
+func isThematicBreak(line string) bool {
+	if indent(line) > 3 {
+		return false
+	}
+	compact := strings.Join(strings.Fields(line), "")
+	if len(compact) < 3 || !strings.ContainsRune("-*_", rune(compact[0])) {
+		return false
+	}
+	return strings.Count(compact, compact[:1]) == len(compact)
+}
+
+// listMarker returns the bullet or number marker starting rest, or ""
+func listMarker(rest string) string {
+	if len(rest) >= 2 && strings.ContainsRune("-*+", rune(rest[0])) && (rest[1] == ' ' || rest[1] == '\t') {
+		return rest[:1]
+	}
+	i := 0
+	for i < len(rest) && i < 9 && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(rest) && (rest[i] == '.' || rest[i] == ')') && (rest[i+1] == ' ' || rest[i+1] == '\t') {
+		return rest[:i+1]
+	}
+	return ""
+}
+
+// indent returns the number of leading spaces
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -0,0 +1,194 @@
+package markdown
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// split feeds deltas to a Splitter and returns every segment
+func split(deltas ...string) []Segment {
+	var s Splitter
+	var out []Segment
+	for _, d := range deltas {
+		out = append(out, s.Write(d)...)
+	}
+	return append(out, s.Flush()...)
+}
+
+func TestSplitter_Blocks(t *testing.T) {
+	t.Parallel()
+
+	input := "# Title\nSome text\nmore text\n\n- one\n- two\n\n```go\nfmt.Println()\n```\n> quoted\n| a | b |\n| 1 | 2 |\n---\n"
+	var text strings.Builder
+	var starts []Block
+	for _, seg := range split(input) {
+		text.WriteString(seg.Text)
+		if seg.Start {
+			starts = append(starts, seg.Block)
+		}
+	}
+	if text.String() != input {
+		t.Errorf("segments do not reproduce the input: %q", text.String())
+	}
+
+	want := []Block{
+		{Type: BlockHeading, Level: 1},
+		{Type: BlockParagraph},
+		{Type: BlockList},
+		{Type: BlockCode, Lang: "go"},
+		{Type: BlockQuote},
+		{Type: BlockTable},
+		{Type: BlockBreak},
+	}
+	if len(starts) != len(want) {
+		t.Fatalf("expected blocks %+v, got %+v", want, starts)
+	}
+	for i := range want {
+		if starts[i] != want[i] {
+			t.Errorf("block %d: expected %+v, got %+v", i, want[i], starts[i])
+		}
+	}
+}
+
+func TestSplitter_HoldsPartialFences(t *testing.T) {
+	t.Parallel()
+
+	var s Splitter
+	if out := s.Write("``"); len(out) != 0 {
+		t.Errorf("expected a partial fence to be held, got %+v", out)
+	}
+	if out := s.Write("`pyth"); len(out) != 0 {
+		t.Errorf("expected an incomplete info string to be held, got %+v", out)
+	}
+	out := s.Write("on\nprint(1")
+	if len(out) != 2 || out[0].Text != "```python\n" || !out[0].Start || out[0].Block.Lang != "python" {
+		t.Fatalf("unexpected fence segments %+v", out)
+	}
+	if out[1].Text != "print(1" || out[1].Block.Type != BlockCode {
+		t.Errorf("expected partial code to be released, got %+v", out[1])
+	}
+	if !s.InCodeBlock() {
+		t.Error("expected to be inside a code block")
+	}
+
+	if out := s.Write(")\n``"); len(out) != 1 || out[0].Text != ")\n" {
+		t.Errorf("expected the possible closing fence to be held, got %+v", out)
+	}
+	out = s.Write("`\nAfter ")
+	if len(out) != 2 || out[0].Text != "```\n" || out[0].Block.Type != BlockCode || out[1].Block.Type != BlockParagraph || !out[1].Start {
+		t.Errorf("unexpected segments after the closing fence %+v", out)
+	}
+	if s.InCodeBlock() {
+		t.Error("expected the code block to be closed")
+	}
+}
+
+func TestSplitter_InlineBoundaries(t *testing.T) {
+	t.Parallel()
+
+	var s Splitter
+	out := s.Write("This is **very im")
+	if len(out) != 1 || out[0].Text != "This is " {
+		t.Errorf("expected text before the open emphasis, got %+v", out)
+	}
+	out = s.Write("portant** and `code span")
+	if len(out) != 1 || out[0].Text != "**very important** and " || out[0].Start {
+		t.Errorf("expected the closed emphasis to be released, got %+v", out)
+	}
+	if out := s.Write(" here` "); len(out) != 1 || out[0].Text != "`code span here` " {
+		t.Errorf("expected the closed code span to be released, got %+v", out)
+	}
+}
+
+func TestSplitter_Lists(t *testing.T) {
+	t.Parallel()
+
+	segments := split("1. first\n   continued\n\n2. second\n\nParagraph\n")
+	var starts []Block
+	for _, seg := range segments {
+		if seg.Start {
+			starts = append(starts, seg.Block)
+		}
+	}
+	if len(starts) != 2 || starts[0] != (Block{Type: BlockList, Ordered: true}) || starts[1].Type != BlockParagraph {
+		t.Errorf("expected a loose list and a paragraph, got %+v", starts)
+	}
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	source := testutil.NewMockTextStream([]provider.StreamChunk{
+		{Type: provider.ChunkTypeText, Text: "Here:\n``"},
+		{Type: provider.ChunkTypeText, Text: "`sh\nls"},
+		{Type: provider.ChunkTypeText, Text: " -la\n```"},
+		{Type: provider.ChunkTypeFinish},
+	})
+	stream := NewStream(source)
+
+	var text strings.Builder
+	var blocks []BlockType
+	var finished bool
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Chunk != nil {
+			finished = event.Chunk.Type == provider.ChunkTypeFinish
+			continue
+		}
+		if finished {
+			t.Error("expected text to be flushed before the finish chunk")
+		}
+		text.WriteString(event.Text)
+		blocks = append(blocks, event.Block.Type)
+	}
+	if text.String() != "Here:\n```sh\nls -la\n```" || !finished {
+		t.Errorf("unexpected text %q", text.String())
+	}
+	if blocks[0] != BlockParagraph || blocks[len(blocks)-1] != BlockCode {
+		t.Errorf("unexpected blocks %v", blocks)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	model := middleware.WrapLanguageModel(&testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "A `pa"},
+				{Type: provider.ChunkTypeText, Text: "th` done"},
+				{Type: provider.ChunkTypeFinish},
+			}), nil
+		},
+	}, []*middleware.LanguageModelMiddleware{Middleware()}, nil, nil)
+
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for {
+		chunk, err := stream.Next()
+		if err != nil {
+			break
+		}
+		if chunk.Type == provider.ChunkTypeText {
+			texts = append(texts, chunk.Text)
+		}
+	}
+	if strings.Join(texts, "|") != "A |`path` |done" {
+		t.Errorf("unexpected text chunks %q", texts)
+	}
+}
@@ -0,0 +1,122 @@
+package markdown
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Event is one item read from a Stream: either a render-safe text segment
+// or a non-text chunk passed through from the model stream
+type Event struct {
+	Segment
+
+	// Chunk is set for non-text chunks (tool calls, usage, finish...), which
+	// are passed through in order
+	Chunk *provider.StreamChunk
+}
+
+// Stream reads a model stream as annotated, render-safe markdown segments.
+// Held text is released when the text block ends, the stream finishes or
+// the source is exhausted.
+type Stream struct {
+	source   provider.TextStream
+	splitter Splitter
+	queue    []Event
+	textID   string // ID of the text block being split
+	err      error
+}
+
+// NewStream wraps a model stream
+func NewStream(source provider.TextStream) *Stream {
+	return &Stream{source: source}
+}
+
+// Next returns the next event, or io.EOF when the stream is complete
+func (s *Stream) Next() (*Event, error) {
+	for len(s.queue) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		chunk, err := s.source.Next()
+		if err != nil {
+			s.err = err
+			s.queueSegments(s.splitter.Flush())
+			continue
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			s.textID = chunk.ID
+			s.queueSegments(s.splitter.Write(chunk.Text))
+			continue
+		case provider.ChunkTypeTextEnd, provider.ChunkTypeFinish:
+			s.queueSegments(s.splitter.Flush())
+		}
+		s.queue = append(s.queue, Event{Chunk: chunk})
+	}
+	event := s.queue[0]
+	s.queue = s.queue[1:]
+	return &event, nil
+}
+
+// Err returns the source stream's error
+func (s *Stream) Err() error {
+	return s.source.Err()
+}
+
+// Close closes the source stream
+func (s *Stream) Close() error {
+	return s.source.Close()
+}
+
+func (s *Stream) queueSegments(segments []Segment) {
+	for _, seg := range segments {
+		s.queue = append(s.queue, Event{Segment: seg})
+	}
+}
+
+// Middleware returns middleware that re-chunks streamed text at render-safe
+// boundaries, for consumers that render each text chunk as it arrives but
+// do not need block annotations. Use NewStream to also get the block of
+// each segment. Generate calls are unchanged.
+//
+// Example:
+//
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{markdown.Middleware()}, nil, nil)
+func Middleware() *middleware.LanguageModelMiddleware {
+	return &middleware.LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &textStream{Stream: NewStream(stream)}, nil
+		},
+	}
+}
+
+// textStream turns a Stream's segments back into text chunks
+type textStream struct {
+	*Stream
+}
+
+func (s *textStream) Next() (*provider.StreamChunk, error) {
+	event, err := s.Stream.Next()
+	if err != nil {
+		return nil, err
+	}
+	if event.Chunk != nil {
+		return event.Chunk, nil
+	}
+	return &provider.StreamChunk{Type: provider.ChunkTypeText, ID: s.textID, Text: event.Text}, nil
+}
@@ -186,6 +186,12 @@ func (c *MCPClient) GetSerializableTools(ctx context.Context) (*ListToolsResult,
 
 // CallTool calls a tool on the MCP server
 func (c *MCPClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	return c.CallToolWithMeta(ctx, name, arguments, nil)
+}
+
+// CallToolWithMeta calls a tool and sends meta as the request's _meta field,
+// which servers can use for request-scoped metadata such as tenant or trace IDs
+func (c *MCPClient) CallToolWithMeta(ctx context.Context, name string, arguments map[string]interface{}, meta map[string]interface{}) (*CallToolResult, error) {
 	if !c.initialized {
 		return nil, fmt.Errorf("client not initialized")
 	}
@@ -193,6 +199,7 @@ func (c *MCPClient) CallTool(ctx context.Context, name string, arguments map[str
 	params := CallToolParams{
 		Name:      name,
 		Arguments: arguments,
+		Meta:      meta,
 	}
 
 	var result CallToolResult
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
 
 // mockTransport implements Transport interface for testing
 type mockTransport struct {
 	messages  chan *MCPMessage
 	connected bool
+	toolCalls []CallToolParams
 }
 
 func newMockTransport() *mockTransport {
@@ -70,6 +73,21 @@ func (m *mockTransport) Send(ctx context.Context, msg *MCPMessage) error {
 		}
 	}
 
+	// Simulate tools/call, recording the request
+	if msg.Method == "tools/call" {
+		var params CallToolParams
+		_ = json.Unmarshal(msg.Params, &params)
+		m.toolCalls = append(m.toolCalls, params)
+
+		resultBytes, _ := json.Marshal(CallToolResult{
+			Content: []ToolResultContent{{Type: "text", Text: "ok"}},
+		})
+		select {
+		case m.messages <- &MCPMessage{JSONRpc: "2.0", ID: msg.ID, Result: resultBytes}:
+		default:
+		}
+	}
+
 	// Simulate initialize response
 	if msg.Method == "initialize" {
 		response := &MCPMessage{
@@ -249,3 +267,29 @@ func TestGetSerializableToolsVsListTools(t *testing.T) {
 		t.Error("GetSerializableTools should include NextCursor for pagination")
 	}
 }
+
+func TestMCPToolConverter_ContextMeta(t *testing.T) {
+	transport := newMockTransport()
+	client := NewMCPClient(transport, MCPClientConfig{ClientName: "test-client", ClientVersion: "1.0.0"})
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	converter := NewMCPToolConverter(client)
+	converter.SetContextMeta(func(ctx context.Context, userContext interface{}) map[string]interface{} {
+		return map[string]interface{}{"tenant": userContext}
+	})
+	tools, err := converter.ConvertToGoAITools(ctx)
+	if err != nil {
+		t.Fatalf("ConvertToGoAITools failed: %v", err)
+	}
+
+	if _, err := tools[0].Execute(ctx, map[string]interface{}{"input": "x"}, types.ToolExecutionOptions{UserContext: "acme"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(transport.toolCalls) != 1 || transport.toolCalls[0].Meta["tenant"] != "acme" {
+		t.Errorf("expected _meta with tenant, got %+v", transport.toolCalls)
+	}
+}
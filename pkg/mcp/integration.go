@@ -10,9 +10,15 @@ import (
 
 // MCPToolConverter converts MCP tools to Go-AI tools
 type MCPToolConverter struct {
-	client *MCPClient
+	client      *MCPClient
+	contextMeta ContextMetaFunc
 }
 
+// ContextMetaFunc derives the _meta sent with an MCP tool call from the
+// call's context and the generation's user context
+// (GenerateTextOptions.ExperimentalContext)
+type ContextMetaFunc func(ctx context.Context, userContext interface{}) map[string]interface{}
+
 // NewMCPToolConverter creates a new MCP tool converter
 func NewMCPToolConverter(client *MCPClient) *MCPToolConverter {
 	return &MCPToolConverter{
@@ -20,6 +26,12 @@ func NewMCPToolConverter(client *MCPClient) *MCPToolConverter {
 	}
 }
 
+// SetContextMeta configures how request metadata is forwarded to the MCP
+// server on each tool call
+func (c *MCPToolConverter) SetContextMeta(fn ContextMetaFunc) {
+	c.contextMeta = fn
+}
+
 // ConvertToGoAITools fetches MCP tools and converts them to Go-AI tools
 func (c *MCPToolConverter) ConvertToGoAITools(ctx context.Context) ([]types.Tool, error) {
 	// List tools from MCP server
@@ -45,7 +57,11 @@ func (c *MCPToolConverter) convertTool(mcpTool MCPTool) types.Tool {
 		Parameters:  mcpTool.InputSchema,
 		Execute: func(ctx context.Context, input map[string]interface{}, options types.ToolExecutionOptions) (interface{}, error) {
 			// Call MCP tool
-			result, err := c.client.CallTool(ctx, mcpTool.Name, input)
+			var meta map[string]interface{}
+			if c.contextMeta != nil {
+				meta = c.contextMeta(ctx, options.UserContext)
+			}
+			result, err := c.client.CallToolWithMeta(ctx, mcpTool.Name, input, meta)
 			if err != nil {
 				return nil, fmt.Errorf("LMCP tool execution failed: %w", err)
 			}
@@ -0,0 +1,473 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets calls through and records their outcomes
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen rejects calls immediately with a *CircuitOpenError
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen lets a limited number of probe calls through to
+	// decide whether to close or re-open the circuit
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// ErrCircuitOpen is matched by errors.Is for every *CircuitOpenError
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned when a call is rejected by an open circuit
+type CircuitOpenError struct {
+	// Key identifies the endpoint, in "provider/modelID" form
+	Key string
+
+	// RetryAfter is how long until the circuit will allow a probe call
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s (retry after %s)", e.Key, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Is reports whether target is ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreakerOptions configures circuit breaking
+type CircuitBreakerOptions struct {
+	// WindowSize is the number of most recent calls used to compute the
+	// failure and slow-call rates (default: 20)
+	WindowSize int
+
+	// MinimumCalls is the number of calls required in the window before the
+	// circuit can trip (default: 10)
+	MinimumCalls int
+
+	// FailureRateThreshold trips the circuit when the fraction of failed calls
+	// in the window reaches it (default: 0.5)
+	FailureRateThreshold float64
+
+	// SlowCallDuration marks calls that take longer as slow. Zero disables
+	// latency-based tripping. For streams, the time to first chunk is measured.
+	SlowCallDuration time.Duration
+
+	// SlowCallRateThreshold trips the circuit when the fraction of slow calls
+	// in the window reaches it (default: 1.0, i.e. every call is slow)
+	SlowCallRateThreshold float64
+
+	// OpenDuration is how long the circuit stays open before allowing probe
+	// calls (default: 30 seconds)
+	OpenDuration time.Duration
+
+	// HalfOpenMaxCalls is the number of probe calls allowed while half-open.
+	// All of them must succeed for the circuit to close (default: 1)
+	HalfOpenMaxCalls int
+
+	// ProbeTimeout frees a half-open probe slot whose call has not reported
+	// an outcome within it, so an abandoned call cannot hold the circuit
+	// half-open (default: OpenDuration)
+	ProbeTimeout time.Duration
+
+	// IsFailure decides whether an error counts as a failure. Defaults to
+	// counting every error except context cancellation, so callers that give
+	// up do not trip the circuit.
+	IsFailure func(err error) bool
+
+	// OnStateChange is called whenever a circuit changes state. It runs while
+	// the breaker is locked, so it must not call back into the breaker.
+	OnStateChange func(key string, from, to CircuitState)
+
+	// Clock is the time source (default clock.System)
+	Clock clock.Clock
+}
+
+// CircuitBreaker tracks call outcomes for one endpoint and decides whether
+// calls may proceed
+type CircuitBreaker struct {
+	key  string
+	opts CircuitBreakerOptions
+
+	mu         sync.Mutex
+	state      CircuitState
+	outcomes   []callOutcome
+	next       int
+	openedAt   time.Time
+	halfOpenOK int
+
+	// generation counts state changes; calls admitted under an earlier
+	// generation are stale and their outcomes are ignored
+	generation uint64
+
+	// probes holds the start times of in-flight half-open probes, oldest first
+	probes []time.Time
+}
+
+// CircuitCall is a call admitted by CircuitBreaker.Allow. Its outcome is
+// reported once, with Record or Release.
+type CircuitCall struct {
+	breaker    *CircuitBreaker
+	generation uint64
+	admittedAt time.Time
+	done       bool
+}
+
+type callOutcome struct {
+	failed bool
+	slow   bool
+}
+
+// NewCircuitBreaker creates a circuit breaker for the endpoint identified by key
+func NewCircuitBreaker(key string, options *CircuitBreakerOptions) *CircuitBreaker {
+	opts := CircuitBreakerOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = 20
+	}
+	if opts.MinimumCalls <= 0 {
+		opts.MinimumCalls = 10
+	}
+	if opts.MinimumCalls > opts.WindowSize {
+		opts.MinimumCalls = opts.WindowSize
+	}
+	if opts.FailureRateThreshold <= 0 {
+		opts.FailureRateThreshold = 0.5
+	}
+	if opts.SlowCallRateThreshold <= 0 {
+		opts.SlowCallRateThreshold = 1.0
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = 30 * time.Second
+	}
+	if opts.HalfOpenMaxCalls <= 0 {
+		opts.HalfOpenMaxCalls = 1
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	if opts.ProbeTimeout <= 0 {
+		opts.ProbeTimeout = opts.OpenDuration
+	}
+	opts.Clock = clock.Default(opts.Clock)
+
+	return &CircuitBreaker{key: key, opts: opts, state: CircuitClosed}
+}
+
+// State returns the current state, moving from open to half-open if the
+// open duration has elapsed
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refreshLocked()
+	return cb.state
+}
+
+// Allow reports whether a call may proceed. When it returns a call, the
+// caller must report the outcome with its Record method, or call its Release
+// method if the call ended without one.
+func (cb *CircuitBreaker) Allow() (*CircuitCall, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refreshLocked()
+
+	now := cb.opts.Clock.Now()
+	switch cb.state {
+	case CircuitOpen:
+		return nil, &CircuitOpenError{Key: cb.key, RetryAfter: cb.openedAt.Add(cb.opts.OpenDuration).Sub(now)}
+	case CircuitHalfOpen:
+		if len(cb.probes) >= cb.opts.HalfOpenMaxCalls {
+			return nil, &CircuitOpenError{Key: cb.key}
+		}
+		cb.probes = append(cb.probes, now)
+	}
+	return &CircuitCall{breaker: cb, generation: cb.generation, admittedAt: now}, nil
+}
+
+// Release frees the slot of a call that ended without an outcome, e.g. a
+// stream closed before its first chunk. The call is not counted towards any
+// rate.
+func (c *CircuitCall) Release() {
+	cb := c.breaker
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	stale := c.done || c.generation != cb.generation
+	c.done = true
+	if stale {
+		return
+	}
+	cb.releaseProbeLocked(c.admittedAt)
+}
+
+// Record reports the outcome of the call. Outcomes of calls admitted before
+// the breaker last changed state are ignored: they neither count towards
+// the current state nor free a half-open probe slot they never took.
+func (c *CircuitCall) Record(err error, duration time.Duration) {
+	cb := c.breaker
+	failed := err != nil && cb.opts.IsFailure(err)
+	slow := cb.opts.SlowCallDuration > 0 && duration >= cb.opts.SlowCallDuration
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	stale := c.done || c.generation != cb.generation
+	c.done = true
+	if stale {
+		return
+	}
+
+	if cb.state == CircuitHalfOpen {
+		cb.releaseProbeLocked(c.admittedAt)
+		if failed || slow {
+			cb.transitionLocked(CircuitOpen)
+			return
+		}
+		cb.halfOpenOK++
+		if cb.halfOpenOK >= cb.opts.HalfOpenMaxCalls {
+			cb.transitionLocked(CircuitClosed)
+		}
+		return
+	}
+	if cb.state != CircuitClosed {
+		return
+	}
+
+	outcome := callOutcome{failed: failed, slow: slow}
+	if len(cb.outcomes) < cb.opts.WindowSize {
+		cb.outcomes = append(cb.outcomes, outcome)
+	} else {
+		cb.outcomes[cb.next] = outcome
+		cb.next = (cb.next + 1) % cb.opts.WindowSize
+	}
+
+	if len(cb.outcomes) < cb.opts.MinimumCalls {
+		return
+	}
+	var failures, slowCalls int
+	for _, o := range cb.outcomes {
+		if o.failed {
+			failures++
+		}
+		if o.slow {
+			slowCalls++
+		}
+	}
+	total := float64(len(cb.outcomes))
+	if float64(failures)/total >= cb.opts.FailureRateThreshold ||
+		(cb.opts.SlowCallDuration > 0 && float64(slowCalls)/total >= cb.opts.SlowCallRateThreshold) {
+		cb.transitionLocked(CircuitOpen)
+	}
+}
+
+// refreshLocked moves an open circuit to half-open once OpenDuration has
+// passed and frees probe slots held longer than ProbeTimeout
+func (cb *CircuitBreaker) refreshLocked() {
+	now := cb.opts.Clock.Now()
+	if cb.state == CircuitOpen && !now.Before(cb.openedAt.Add(cb.opts.OpenDuration)) {
+		cb.transitionLocked(CircuitHalfOpen)
+	}
+	for len(cb.probes) > 0 && !now.Before(cb.probes[0].Add(cb.opts.ProbeTimeout)) {
+		cb.probes = cb.probes[1:]
+	}
+}
+
+// releaseProbeLocked frees the half-open probe slot taken at admittedAt,
+// unless ProbeTimeout already freed it
+func (cb *CircuitBreaker) releaseProbeLocked(admittedAt time.Time) {
+	if cb.state != CircuitHalfOpen {
+		return
+	}
+	for i, t := range cb.probes {
+		if t.Equal(admittedAt) {
+			cb.probes = append(cb.probes[:i], cb.probes[i+1:]...)
+			return
+		}
+	}
+}
+
+// transitionLocked changes state, resets per-state bookkeeping and fires the callback
+func (cb *CircuitBreaker) transitionLocked(to CircuitState) {
+	from := cb.state
+	if from == to {
+		return
+	}
+	cb.state = to
+	cb.generation++
+	cb.probes = nil
+	cb.halfOpenOK = 0
+	switch to {
+	case CircuitOpen:
+		cb.openedAt = cb.opts.Clock.Now()
+	case CircuitClosed:
+		cb.outcomes = cb.outcomes[:0]
+		cb.next = 0
+	}
+	if cb.opts.OnStateChange != nil {
+		cb.opts.OnStateChange(cb.key, from, to)
+	}
+}
+
+// CircuitBreakerGroup holds one circuit breaker per provider endpoint
+// (provider name and model ID), created on first use
+type CircuitBreakerGroup struct {
+	opts     *CircuitBreakerOptions
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewCircuitBreakerGroup creates a group whose breakers share options
+func NewCircuitBreakerGroup(options *CircuitBreakerOptions) *CircuitBreakerGroup {
+	return &CircuitBreakerGroup{opts: options, breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Breaker returns the circuit breaker for key, creating it if needed
+func (g *CircuitBreakerGroup) Breaker(key string) *CircuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	cb, ok := g.breakers[key]
+	if !ok {
+		cb = NewCircuitBreaker(key, g.opts)
+		g.breakers[key] = cb
+	}
+	return cb
+}
+
+// States returns a snapshot of every breaker's state keyed by endpoint
+func (g *CircuitBreakerGroup) States() map[string]CircuitState {
+	g.mu.Lock()
+	breakers := make(map[string]*CircuitBreaker, len(g.breakers))
+	for k, cb := range g.breakers {
+		breakers[k] = cb
+	}
+	g.mu.Unlock()
+
+	states := make(map[string]CircuitState, len(breakers))
+	for k, cb := range breakers {
+		states[k] = cb.State()
+	}
+	return states
+}
+
+// LanguageModelMiddleware returns middleware that guards each provider/model
+// endpoint with its own circuit breaker. While a circuit is open, calls fail
+// immediately with a *CircuitOpenError so that FallbackLanguageModel (or the
+// caller) can move on to another model without waiting for timeouts.
+//
+// Example:
+//
+//	breakers := middleware.NewCircuitBreakerGroup(&middleware.CircuitBreakerOptions{
+//		FailureRateThreshold: 0.5,
+//		SlowCallDuration:     10 * time.Second,
+//		OnStateChange: func(key string, from, to middleware.CircuitState) {
+//			log.Printf("circuit %s: %s -> %s", key, from, to)
+//		},
+//	})
+//	guarded := middleware.WrapLanguageModel(model,
+//		[]*middleware.LanguageModelMiddleware{breakers.LanguageModelMiddleware()}, nil, nil)
+func (g *CircuitBreakerGroup) LanguageModelMiddleware() *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			cb := g.Breaker(model.Provider() + "/" + model.ModelID())
+			call, err := cb.Allow()
+			if err != nil {
+				return nil, err
+			}
+			start := cb.opts.Clock.Now()
+			result, err := doGenerate()
+			call.Record(err, cb.opts.Clock.Now().Sub(start))
+			return result, err
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			cb := g.Breaker(model.Provider() + "/" + model.ModelID())
+			call, err := cb.Allow()
+			if err != nil {
+				return nil, err
+			}
+			start := cb.opts.Clock.Now()
+			stream, err := doStream()
+			if err != nil {
+				call.Record(err, cb.opts.Clock.Now().Sub(start))
+				return nil, err
+			}
+			return &circuitStream{TextStream: stream, breaker: cb, call: call, start: start}, nil
+		},
+	}
+}
+
+// circuitStream records the stream outcome once: latency to the first chunk,
+// and failure if the stream errors before completing
+type circuitStream struct {
+	provider.TextStream
+	breaker  *CircuitBreaker
+	call     *CircuitCall
+	start    time.Time
+	firstAt  time.Duration
+	started  bool
+	recorded bool
+}
+
+// Next returns the next chunk and records the outcome when the stream ends
+func (s *circuitStream) Next() (*provider.StreamChunk, error) {
+	chunk, err := s.TextStream.Next()
+	if !s.started {
+		s.started = true
+		s.firstAt = s.breaker.opts.Clock.Now().Sub(s.start)
+	}
+	if err != nil && !s.recorded {
+		s.recorded = true
+		if errors.Is(err, io.EOF) {
+			s.call.Record(nil, s.firstAt)
+		} else {
+			s.call.Record(err, s.firstAt)
+		}
+	}
+	return chunk, err
+}
+
+// Close closes the stream, counting an early close after the first chunk as
+// a success. A stream closed before its first chunk says nothing about the
+// endpoint, so its slot is released without an outcome.
+func (s *circuitStream) Close() error {
+	if !s.recorded {
+		s.recorded = true
+		if s.started {
+			s.call.Record(nil, s.firstAt)
+		} else {
+			s.call.Release()
+		}
+	}
+	return s.TextStream.Close()
+}
@@ -0,0 +1,308 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestCircuitBreaker_TripsOnFailureRate(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	var transitions []CircuitState
+	cb := NewCircuitBreaker("mock/model", &CircuitBreakerOptions{
+		WindowSize:   4,
+		MinimumCalls: 4,
+		OpenDuration: time.Minute,
+		Clock:        fake,
+		OnStateChange: func(key string, from, to CircuitState) {
+			transitions = append(transitions, to)
+		},
+	})
+
+	failure := errors.New("503 service unavailable")
+	for _, err := range []error{nil, failure, nil, failure} {
+		call, allowErr := cb.Allow()
+		if allowErr != nil {
+			t.Fatalf("unexpected rejection: %v", allowErr)
+		}
+		call.Record(err, time.Millisecond)
+	}
+
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", cb.State())
+	}
+	if _, err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// After the open duration a single probe is allowed
+	fake.Advance(time.Minute)
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if _, err := cb.Allow(); err == nil {
+		t.Fatal("expected second half-open call to be rejected")
+	}
+	probe.Record(nil, time.Millisecond)
+
+	if cb.State() != CircuitClosed {
+		t.Fatalf("expected circuit to close after successful probe, got %s", cb.State())
+	}
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transition %d: expected %s, got %s", i, want[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_TripsOnSlowCalls(t *testing.T) {
+	t.Parallel()
+
+	cb := NewCircuitBreaker("mock/model", &CircuitBreakerOptions{
+		WindowSize:            2,
+		MinimumCalls:          2,
+		SlowCallDuration:      time.Second,
+		SlowCallRateThreshold: 1.0,
+	})
+	for i := 0; i < 2; i++ {
+		call, _ := cb.Allow()
+		call.Record(nil, 2*time.Second)
+	}
+	if cb.State() != CircuitOpen {
+		t.Errorf("expected slow calls to open the circuit, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	cb := NewCircuitBreaker("mock/model", &CircuitBreakerOptions{
+		WindowSize:   1,
+		MinimumCalls: 1,
+		OpenDuration: time.Second,
+		Clock:        fake,
+	})
+	call, _ := cb.Allow()
+	call.Record(errors.New("boom"), 0)
+	fake.Advance(time.Second)
+
+	probe, _ := cb.Allow()
+	probe.Record(errors.New("still down"), 0)
+	if cb.State() != CircuitOpen {
+		t.Errorf("expected failed probe to re-open the circuit, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_ReleasesAbandonedProbes(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	cb := NewCircuitBreaker("mock/model", &CircuitBreakerOptions{
+		WindowSize:   1,
+		MinimumCalls: 1,
+		OpenDuration: time.Second,
+		ProbeTimeout: 5 * time.Second,
+		Clock:        fake,
+	})
+	call, _ := cb.Allow()
+	call.Record(errors.New("boom"), 0)
+	fake.Advance(time.Second)
+
+	// A released probe frees its slot without closing the circuit
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	probe.Release()
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected circuit to stay half-open, got %s", cb.State())
+	}
+
+	// A probe that never reports frees its slot after ProbeTimeout
+	if _, err := cb.Allow(); err != nil {
+		t.Fatalf("expected probe after release to be allowed, got %v", err)
+	}
+	if _, err := cb.Allow(); err == nil {
+		t.Fatal("expected second probe to be rejected")
+	}
+	fake.Advance(5 * time.Second)
+	if _, err := cb.Allow(); err != nil {
+		t.Fatalf("expected timed-out probe slot to be freed, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresStaleOutcomes(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	cb := NewCircuitBreaker("mock/model", &CircuitBreakerOptions{
+		WindowSize:   1,
+		MinimumCalls: 1,
+		OpenDuration: time.Second,
+		Clock:        fake,
+	})
+	slow, _ := cb.Allow()
+	failed, _ := cb.Allow()
+	failed.Record(errors.New("boom"), 0)
+	fake.Advance(time.Second)
+
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+
+	// A success admitted while closed neither closes the circuit nor frees
+	// the probe's slot
+	slow.Record(nil, 0)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected stale success to be ignored, got %s", cb.State())
+	}
+	if _, err := cb.Allow(); err == nil {
+		t.Fatal("expected the probe slot to stay taken")
+	}
+
+	probe.Record(nil, 0)
+	probe.Record(errors.New("reported twice"), 0)
+	if cb.State() != CircuitClosed {
+		t.Errorf("expected the probe to close the circuit, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerMiddleware_StreamClosedBeforeFirstChunk(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	group := NewCircuitBreakerGroup(&CircuitBreakerOptions{
+		WindowSize:   1,
+		MinimumCalls: 1,
+		OpenDuration: time.Second,
+		Clock:        fake,
+	})
+	cb := group.Breaker("mock/model")
+	call, _ := cb.Allow()
+	call.Record(errors.New("boom"), 0)
+	fake.Advance(time.Second)
+
+	model := WrapLanguageModel(&testutil.MockLanguageModel{
+		ProviderName: "mock",
+		ModelName:    "model",
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream(nil), nil
+		},
+	}, []*LanguageModelMiddleware{group.LanguageModelMiddleware()}, nil, nil)
+
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("expected probe stream, got %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected circuit to stay half-open, got %s", cb.State())
+	}
+	if _, err := model.DoStream(context.Background(), &provider.GenerateOptions{}); err != nil {
+		t.Errorf("expected the released slot to admit another probe, got %v", err)
+	}
+}
+
+func TestCircuitBreakerMiddleware_FailsFastAndFallsBack(t *testing.T) {
+	t.Parallel()
+
+	failing := &testutil.MockLanguageModel{
+		ModelName: "primary",
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, errors.New("provider outage")
+		},
+	}
+	backup := &testutil.MockLanguageModel{ModelName: "backup"}
+
+	breakers := NewCircuitBreakerGroup(&CircuitBreakerOptions{WindowSize: 2, MinimumCalls: 2})
+	mw := []*LanguageModelMiddleware{breakers.LanguageModelMiddleware()}
+
+	var fallbacks int
+	model := FallbackLanguageModel([]provider.LanguageModel{
+		WrapLanguageModel(failing, mw, nil, nil),
+		WrapLanguageModel(backup, mw, nil, nil),
+	}, &FallbackOptions{
+		OnFallback: func(ctx context.Context, failed provider.LanguageModel, err error, next provider.LanguageModel) {
+			fallbacks++
+		},
+	})
+
+	for i := 0; i < 4; i++ {
+		result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if result.Text != "mock response" {
+			t.Errorf("call %d: expected backup response, got %q", i, result.Text)
+		}
+	}
+
+	// The primary is only called until its circuit opens
+	if len(failing.GenerateCalls) != 2 {
+		t.Errorf("expected 2 calls to the failing model, got %d", len(failing.GenerateCalls))
+	}
+	if fallbacks != 4 {
+		t.Errorf("expected 4 fallbacks, got %d", fallbacks)
+	}
+	if breakers.States()["mock/primary"] != CircuitOpen {
+		t.Errorf("expected primary circuit to be open, got %v", breakers.States())
+	}
+}
+
+func TestFallbackLanguageModel_StopsOnCancellation(t *testing.T) {
+	t.Parallel()
+
+	first := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, context.Canceled
+		},
+	}
+	second := &testutil.MockLanguageModel{}
+
+	model := FallbackLanguageModel([]provider.LanguageModel{first, second}, nil)
+	if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(second.GenerateCalls) != 0 {
+		t.Error("expected no fallback on cancellation")
+	}
+}
+
+func TestFallbackLanguageModel_FallsBackOnProviderTimeout(t *testing.T) {
+	t.Parallel()
+
+	first := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			timeout := providererrors.NewDeadlineExceededError(providererrors.DeadlineSourceProvider, 0, errors.New("LHTTP 504: gateway timeout"))
+			timeout.StatusCode = 504
+			return nil, timeout
+		},
+	}
+	second := &testutil.MockLanguageModel{}
+
+	model := FallbackLanguageModel([]provider.LanguageModel{first, second}, nil)
+	if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{}); err != nil {
+		t.Fatalf("expected the fallback model to answer, got %v", err)
+	}
+	if len(second.GenerateCalls) != 1 {
+		t.Error("expected a gateway timeout to fall back")
+	}
+}
@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ContextWindowOptions configures ContextWindowMiddleware
+type ContextWindowOptions struct {
+	// ContextWindow returns the context window of a model in tokens, or 0
+	// when unknown, in which case calls are sent unchecked. Use a provider
+	// catalog, e.g. fireworks.Models[id].ContextWindow.
+	ContextWindow func(modelID string) int
+
+	// EstimateTokens counts the tokens in text (default: 4 characters per
+	// token). Plug in a real tokenizer for tighter checks.
+	EstimateTokens func(text string) int
+
+	// ReserveOutputTokens is the room kept for the response when a call does
+	// not set MaxTokens (default: 0)
+	ReserveOutputTokens int
+}
+
+// TruncationKind is a way to make a prompt fit
+type TruncationKind string
+
+const (
+	// TruncateDropOldest drops the oldest non-system messages
+	TruncateDropOldest TruncationKind = "drop-oldest"
+
+	// TruncateShortenMessage shortens one large message
+	TruncateShortenMessage TruncationKind = "shorten-message"
+
+	// TruncateRemoveTools removes tool definitions
+	TruncateRemoveTools TruncationKind = "remove-tools"
+
+	// TruncateLowerMaxTokens lowers the output token limit
+	TruncateLowerMaxTokens TruncationKind = "lower-max-tokens"
+)
+
+// TruncationPoint is one suggested change that makes a prompt fit
+type TruncationPoint struct {
+	Kind TruncationKind
+
+	// Start and End are the affected Prompt.Messages indexes, inclusive;
+	// both are -1 when the suggestion is not about messages
+	Start, End int
+
+	// Tokens is the estimated number of tokens the change frees
+	Tokens int
+
+	// Description explains the change
+	Description string
+}
+
+// ContextOverflowError is returned before sending a prompt that does not
+// fit the model's context window
+type ContextOverflowError struct {
+	ModelID       string
+	ContextWindow int
+
+	// PromptTokens is the estimated size of the prompt and tool definitions
+	PromptTokens int
+
+	// OutputTokens is the room reserved for the response
+	OutputTokens int
+
+	// Overflow is how many tokens must be freed
+	Overflow int
+
+	// Suggestions are alternative ways to make the prompt fit, most
+	// conservative first
+	Suggestions []TruncationPoint
+}
+
+// Error implements the error interface
+func (e *ContextOverflowError) Error() string {
+	msg := fmt.Sprintf("prompt of ~%d tokens plus %d output tokens exceeds the %d-token context window of %s by %d tokens",
+		e.PromptTokens, e.OutputTokens, e.ContextWindow, e.ModelID, e.Overflow)
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+	descriptions := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		descriptions[i] = s.Description
+	}
+	return msg + "; to fit: " + strings.Join(descriptions, ", or ")
+}
+
+// ContextWindowMiddleware returns middleware that checks prompts against the
+// model's context window before they are sent. A prompt that does not fit,
+// with room for MaxTokens of output, fails with a *ContextOverflowError
+// listing the overflow and where to truncate, instead of the provider's
+// raw 400.
+//
+// Example:
+//
+//	preflight := middleware.ContextWindowMiddleware(middleware.ContextWindowOptions{
+//		ContextWindow: func(id string) int { return fireworks.Models[id].ContextWindow },
+//	})
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{preflight}, nil, nil)
+//
+//	_, err := ai.GenerateText(ctx, ai.GenerateTextOptions{Model: wrapped, Messages: history})
+//	var overflow *middleware.ContextOverflowError
+//	if errors.As(err, &overflow) {
+//		history = history[overflow.Suggestions[0].End+1:]
+//	}
+func ContextWindowMiddleware(opts ContextWindowOptions) *LanguageModelMiddleware {
+	if opts.EstimateTokens == nil {
+		opts.EstimateTokens = func(text string) int {
+			return (utf8.RuneCountInString(text) + 3) / 4
+		}
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			if err := checkContextWindow(opts, params, model); err != nil {
+				return nil, err
+			}
+			return doGenerate()
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			if err := checkContextWindow(opts, params, model); err != nil {
+				return nil, err
+			}
+			return doStream()
+		},
+	}
+}
+
+// checkContextWindow returns a *ContextOverflowError when params do not fit
+// the model's context window
+func checkContextWindow(opts ContextWindowOptions, params *provider.GenerateOptions, model provider.LanguageModel) error {
+	if opts.ContextWindow == nil {
+		return nil
+	}
+	window := opts.ContextWindow(model.ModelID())
+	if window <= 0 {
+		return nil
+	}
+
+	messages := params.Prompt.Messages
+	messageTokens := make([]int, len(messages))
+	toolTokens := opts.EstimateTokens(toolsText(params.Tools))
+	promptTokens := opts.EstimateTokens(params.Prompt.System+params.Prompt.Text) + toolTokens
+	for i, msg := range messages {
+		messageTokens[i] = opts.EstimateTokens(messageText(msg))
+		promptTokens += messageTokens[i]
+	}
+	outputTokens := opts.ReserveOutputTokens
+	if params.MaxTokens != nil {
+		outputTokens = *params.MaxTokens
+	}
+	overflow := promptTokens + outputTokens - window
+	if overflow <= 0 {
+		return nil
+	}
+
+	return &ContextOverflowError{
+		ModelID:       model.ModelID(),
+		ContextWindow: window,
+		PromptTokens:  promptTokens,
+		OutputTokens:  outputTokens,
+		Overflow:      overflow,
+		Suggestions:   truncationPoints(messages, messageTokens, toolTokens, promptTokens, window, overflow),
+	}
+}
+
+// truncationPoints suggests ways to free overflow tokens
+func truncationPoints(messages []types.Message, messageTokens []int, toolTokens, promptTokens, window, overflow int) []TruncationPoint {
+	var points []TruncationPoint
+
+	// Drop the oldest messages, keeping system messages and the latest
+	// message, and never separating tool results from their call
+	start := 0
+	for start < len(messages) && messages[start].Role == types.RoleSystem {
+		start++
+	}
+	freed := 0
+	for end := start; end < len(messages)-1; end++ {
+		freed += messageTokens[end]
+		if freed < overflow {
+			continue
+		}
+		for end+1 < len(messages)-1 && messages[end+1].Role == types.RoleTool {
+			end++
+			freed += messageTokens[end]
+		}
+		if end+1 < len(messages) && messages[end+1].Role != types.RoleTool {
+			points = append(points, TruncationPoint{
+				Kind:        TruncateDropOldest,
+				Start:       start,
+				End:         end,
+				Tokens:      freed,
+				Description: fmt.Sprintf("drop messages %d-%d (the oldest, ~%d tokens)", start, end, freed),
+			})
+		}
+		break
+	}
+
+	// Shorten the largest message if it alone can make room
+	largest := -1
+	for i, msg := range messages {
+		if msg.Role != types.RoleSystem && (largest < 0 || messageTokens[i] > messageTokens[largest]) {
+			largest = i
+		}
+	}
+	if largest >= 0 && messageTokens[largest] > overflow {
+		points = append(points, TruncationPoint{
+			Kind:   TruncateShortenMessage,
+			Start:  largest,
+			End:    largest,
+			Tokens: overflow,
+			Description: fmt.Sprintf("shorten message %d (%s, ~%d tokens) by at least %d tokens",
+				largest, messages[largest].Role, messageTokens[largest], overflow),
+		})
+	}
+
+	if toolTokens >= overflow {
+		points = append(points, TruncationPoint{
+			Kind:        TruncateRemoveTools,
+			Start:       -1,
+			End:         -1,
+			Tokens:      toolTokens,
+			Description: fmt.Sprintf("remove unused tool definitions (~%d tokens)", toolTokens),
+		})
+	}
+
+	if promptTokens < window {
+		points = append(points, TruncationPoint{
+			Kind:        TruncateLowerMaxTokens,
+			Start:       -1,
+			End:         -1,
+			Tokens:      overflow,
+			Description: fmt.Sprintf("lower MaxTokens to at most %d", window-promptTokens),
+		})
+	}
+	return points
+}
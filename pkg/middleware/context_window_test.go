@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func contextWindowModel(window int) (provider.LanguageModel, *int) {
+	calls := 0
+	model := &testutil.MockLanguageModel{
+		ModelName: "small",
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			calls++
+			return &types.GenerateResult{Text: "ok"}, nil
+		},
+	}
+	mw := ContextWindowMiddleware(ContextWindowOptions{
+		ContextWindow:  func(id string) int { return map[string]int{"small": window}[id] },
+		EstimateTokens: func(text string) int { return len(text) },
+	})
+	return WrapLanguageModel(model, []*LanguageModelMiddleware{mw}, nil, nil), &calls
+}
+
+func sized(role types.MessageRole, n int) types.Message {
+	return types.Message{Role: role, Content: []types.ContentPart{types.TextContent{Text: strings.Repeat("x", n)}}}
+}
+
+func TestContextWindowMiddleware_PassesPromptsThatFit(t *testing.T) {
+	t.Parallel()
+
+	model, calls := contextWindowModel(100)
+	maxTokens := 40
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt:    types.Prompt{Messages: []types.Message{sized(types.RoleUser, 60)}},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil || *calls != 1 {
+		t.Fatalf("err = %v, calls = %d", err, *calls)
+	}
+}
+
+func TestContextWindowMiddleware_ReportsOverflow(t *testing.T) {
+	t.Parallel()
+
+	model, calls := contextWindowModel(100)
+	maxTokens := 20
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Messages: []types.Message{
+			sized(types.RoleSystem, 10),
+			sized(types.RoleUser, 30),
+			sized(types.RoleAssistant, 20),
+			sized(types.RoleUser, 40),
+		}},
+		MaxTokens: &maxTokens,
+	})
+	var overflow *ContextOverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("expected *ContextOverflowError, got %v", err)
+	}
+	if *calls != 0 {
+		t.Error("the prompt was sent")
+	}
+	if overflow.ModelID != "small" || overflow.PromptTokens != 100 || overflow.OutputTokens != 20 || overflow.Overflow != 20 {
+		t.Errorf("unexpected error %+v", overflow)
+	}
+
+	kinds := map[TruncationKind]TruncationPoint{}
+	for _, s := range overflow.Suggestions {
+		kinds[s.Kind] = s
+	}
+	if drop := kinds[TruncateDropOldest]; drop.Start != 1 || drop.End != 1 || drop.Tokens != 30 {
+		t.Errorf("unexpected drop suggestion %+v", drop)
+	}
+	if shorten := kinds[TruncateShortenMessage]; shorten.Start != 3 || shorten.Tokens != 20 {
+		t.Errorf("unexpected shorten suggestion %+v", shorten)
+	}
+	if _, ok := kinds[TruncateLowerMaxTokens]; ok {
+		t.Error("lowering MaxTokens cannot help a prompt that fills the window")
+	}
+	if !strings.Contains(err.Error(), "exceeds the 100-token context window of small by 20 tokens") {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestContextWindowMiddleware_KeepsToolResultsWithCalls(t *testing.T) {
+	t.Parallel()
+
+	model, _ := contextWindowModel(50)
+	call := types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", ToolName: "search"}}}
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Messages: []types.Message{
+			sized(types.RoleUser, 10),
+			call,
+			sized(types.RoleTool, 20),
+			sized(types.RoleUser, 10),
+		}},
+	})
+	var overflow *ContextOverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("expected *ContextOverflowError, got %v", err)
+	}
+	drop := overflow.Suggestions[0]
+	if drop.Kind != TruncateDropOldest || drop.End != 2 {
+		t.Errorf("expected the tool result to be dropped with its call, got %+v", drop)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
 
 // DefaultSettingsMiddleware creates a language model middleware that applies default settings
@@ -19,7 +20,9 @@ func DefaultSettingsMiddleware(settings *provider.GenerateOptions) *LanguageMode
 	}
 }
 
-// mergeGenerateOptions merges two GenerateOptions, with the second taking precedence
+// mergeGenerateOptions merges two GenerateOptions, with the second taking
+// precedence. Every field set on overrides is kept; unset fields are filled
+// from defaults. Headers and per-provider ProviderOptions are merged key by key.
 func mergeGenerateOptions(defaults, overrides *provider.GenerateOptions) *provider.GenerateOptions {
 	if defaults == nil {
 		return overrides
@@ -28,103 +31,121 @@ func mergeGenerateOptions(defaults, overrides *provider.GenerateOptions) *provid
 		return defaults
 	}
 
-	result := &provider.GenerateOptions{}
+	result := *overrides
 
-	// Copy from defaults first
-	if defaults.Prompt.Messages != nil {
+	if result.Prompt.Messages == nil {
 		result.Prompt.Messages = defaults.Prompt.Messages
 	}
-	if defaults.MaxTokens != nil {
+	// A system message in the call replaces the default system prompt
+	if result.Prompt.System == "" && !hasSystemMessage(result.Prompt.Messages) {
+		result.Prompt.System = defaults.Prompt.System
+	}
+	if result.MaxTokens == nil {
 		result.MaxTokens = defaults.MaxTokens
 	}
-	if defaults.Temperature != nil {
+	if result.Temperature == nil {
 		result.Temperature = defaults.Temperature
 	}
-	if defaults.TopP != nil {
+	if result.TopP == nil {
 		result.TopP = defaults.TopP
 	}
-	if defaults.TopK != nil {
+	if result.TopK == nil {
 		result.TopK = defaults.TopK
 	}
-	if defaults.PresencePenalty != nil {
+	if result.PresencePenalty == nil {
 		result.PresencePenalty = defaults.PresencePenalty
 	}
-	if defaults.FrequencyPenalty != nil {
+	if result.FrequencyPenalty == nil {
 		result.FrequencyPenalty = defaults.FrequencyPenalty
 	}
-	if defaults.StopSequences != nil {
+	if result.StopSequences == nil {
 		result.StopSequences = defaults.StopSequences
 	}
-	if defaults.Seed != nil {
+	if result.Seed == nil {
 		result.Seed = defaults.Seed
 	}
-	if defaults.Tools != nil {
+	if result.Tools == nil {
 		result.Tools = defaults.Tools
 	}
-	if defaults.ToolChoice.Type != "" {
+	if result.ToolChoice.Type == "" {
 		result.ToolChoice = defaults.ToolChoice
 	}
-	if defaults.ResponseFormat != nil {
+	if result.ResponseFormat == nil {
 		result.ResponseFormat = defaults.ResponseFormat
 	}
-	if defaults.Headers != nil {
-		result.Headers = make(map[string]string)
-		for k, v := range defaults.Headers {
-			result.Headers[k] = v
-		}
+	if result.Constraint == nil {
+		result.Constraint = defaults.Constraint
 	}
-	if defaults.MaxSteps != nil {
+	if result.MaxSteps == nil {
 		result.MaxSteps = defaults.MaxSteps
 	}
-
-	// Override with values from overrides
-	if overrides.Prompt.Messages != nil {
-		result.Prompt.Messages = overrides.Prompt.Messages
-	}
-	if overrides.MaxTokens != nil {
-		result.MaxTokens = overrides.MaxTokens
-	}
-	if overrides.Temperature != nil {
-		result.Temperature = overrides.Temperature
-	}
-	if overrides.TopP != nil {
-		result.TopP = overrides.TopP
+	if result.Reasoning == nil {
+		result.Reasoning = defaults.Reasoning
 	}
-	if overrides.TopK != nil {
-		result.TopK = overrides.TopK
+	if result.Telemetry == nil {
+		result.Telemetry = defaults.Telemetry
 	}
-	if overrides.PresencePenalty != nil {
-		result.PresencePenalty = overrides.PresencePenalty
-	}
-	if overrides.FrequencyPenalty != nil {
-		result.FrequencyPenalty = overrides.FrequencyPenalty
+	result.Headers = mergeStringMaps(defaults.Headers, overrides.Headers)
+	result.Metadata = mergeStringMaps(defaults.Metadata, overrides.Metadata)
+	result.ProviderOptions = mergeProviderOptions(defaults.ProviderOptions, overrides.ProviderOptions)
+
+	return &result
+}
+
+// mergeStringMaps returns a copy of defaults overlaid with overrides, or nil
+// when both are nil
+func mergeStringMaps(defaults, overrides map[string]string) map[string]string {
+	if defaults == nil && overrides == nil {
+		return nil
 	}
-	if overrides.StopSequences != nil {
-		result.StopSequences = overrides.StopSequences
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = v
 	}
-	if overrides.Seed != nil {
-		result.Seed = overrides.Seed
+	for k, v := range overrides {
+		merged[k] = v
 	}
-	if overrides.Tools != nil {
-		result.Tools = overrides.Tools
+	return merged
+}
+
+// mergeProviderOptions merges provider options one level deep, so defaults
+// for a provider (e.g. "openai") combine with per-call options for it
+func mergeProviderOptions(defaults, overrides map[string]interface{}) map[string]interface{} {
+	if defaults == nil {
+		return overrides
 	}
-	if overrides.ToolChoice.Type != "" {
-		result.ToolChoice = overrides.ToolChoice
+	if overrides == nil {
+		return defaults
 	}
-	if overrides.ResponseFormat != nil {
-		result.ResponseFormat = overrides.ResponseFormat
+	merged := make(map[string]interface{}, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = v
 	}
-	if overrides.Headers != nil {
-		if result.Headers == nil {
-			result.Headers = make(map[string]string)
+	for k, v := range overrides {
+		d, dok := merged[k].(map[string]interface{})
+		o, ook := v.(map[string]interface{})
+		if !dok || !ook {
+			merged[k] = v
+			continue
 		}
-		for k, v := range overrides.Headers {
-			result.Headers[k] = v
+		inner := make(map[string]interface{}, len(d)+len(o))
+		for ik, iv := range d {
+			inner[ik] = iv
 		}
+		for ik, iv := range o {
+			inner[ik] = iv
+		}
+		merged[k] = inner
 	}
-	if overrides.MaxSteps != nil {
-		result.MaxSteps = overrides.MaxSteps
-	}
+	return merged
+}
 
-	return result
+// hasSystemMessage reports whether messages include a system message
+func hasSystemMessage(messages []types.Message) bool {
+	for _, m := range messages {
+		if m.Role == types.RoleSystem {
+			return true
+		}
+	}
+	return false
 }
@@ -352,3 +352,29 @@ func TestMergeGenerateOptions_Messages(t *testing.T) {
 		t.Error("expected override messages to take precedence")
 	}
 }
+
+func TestMergeGenerateOptions_PreservesUnlistedFields(t *testing.T) {
+	t.Parallel()
+
+	reasoning := types.ReasoningHigh
+	defaults := &provider.GenerateOptions{
+		Prompt:   types.Prompt{System: "default system"},
+		Metadata: map[string]string{"team": "search"},
+	}
+	overrides := &provider.GenerateOptions{
+		Prompt:    types.Prompt{Text: "hello"},
+		Reasoning: &reasoning,
+		Metadata:  map[string]string{"feature": "chat"},
+	}
+
+	result := mergeGenerateOptions(defaults, overrides)
+	if result.Prompt.Text != "hello" || result.Prompt.System != "default system" {
+		t.Errorf("unexpected prompt: %+v", result.Prompt)
+	}
+	if result.Reasoning == nil || *result.Reasoning != types.ReasoningHigh {
+		t.Error("expected reasoning to be preserved")
+	}
+	if result.Metadata["team"] != "search" || result.Metadata["feature"] != "chat" {
+		t.Errorf("expected merged metadata, got %v", result.Metadata)
+	}
+}
@@ -0,0 +1,507 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DegradationMode is how a degraded call was answered
+type DegradationMode string
+
+const (
+	// DegradedCache served a previously cached answer
+	DegradedCache DegradationMode = "cache"
+
+	// DegradedFallback answered with the fallback model
+	DegradedFallback DegradationMode = "fallback"
+
+	// DegradedResponse returned the structured degraded response
+	DegradedResponse DegradationMode = "response"
+)
+
+// DegradationMetadataKey is the ProviderMetadata key set on degraded
+// results. Its value is a map with "mode" and "reason" entries.
+const DegradationMetadataKey = "degradation"
+
+// ResponseCache stores generation results so they can be served while a
+// model is unavailable. Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns a result for the request, reporting whether one was found.
+	// Semantic caches may match similar rather than identical requests.
+	Get(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions) (*types.GenerateResult, bool, error)
+
+	// Set stores the result of a successful request
+	Set(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions, result *types.GenerateResult) error
+}
+
+// DegradationOptions configures NewDegradation. When a call is degraded the
+// cache is tried first, then the fallback model, then the degraded response;
+// if none answers, the original error is returned.
+type DegradationOptions struct {
+	// ShouldDegrade decides whether an error degrades the call. Defaults to
+	// errors rejected by an open circuit breaker (ErrCircuitOpen).
+	ShouldDegrade func(err error) bool
+
+	// Cache records successful results and serves them when degraded
+	Cache ResponseCache
+
+	// Fallback is a (typically cheaper) model that answers degraded calls
+	Fallback provider.LanguageModel
+
+	// Response builds a structured degraded answer, e.g. a "service is busy"
+	// message, when neither the cache nor the fallback model answers
+	Response func(ctx context.Context, params *provider.GenerateOptions, err error) *types.GenerateResult
+
+	// OnDegrade is called for every degraded call, e.g. to export metrics
+	OnDegrade func(ctx context.Context, event DegradationEvent)
+
+	// OnError is called when the cache fails. Cache failures never fail the
+	// call.
+	OnError func(ctx context.Context, err error)
+}
+
+// DegradationEvent describes a degraded call
+type DegradationEvent struct {
+	// Key identifies the endpoint, in "provider/modelID" form
+	Key string
+
+	// Mode is how the call was answered; empty when nothing could answer it
+	// and the error was returned
+	Mode DegradationMode
+
+	// Err is the error that degraded the call
+	Err error
+}
+
+// DegradationStats counts calls seen by a Degradation
+type DegradationStats struct {
+	// Calls is the total number of calls
+	Calls int64
+
+	// Degraded is the number of calls answered in a degraded mode
+	Degraded int64
+
+	// Unanswered is the number of degraded calls nothing could answer
+	Unanswered int64
+
+	// ByMode counts degraded calls by how they were answered
+	ByMode map[DegradationMode]int64
+}
+
+// Rate returns the fraction of calls that were degraded, answered or not
+func (s DegradationStats) Rate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Degraded+s.Unanswered) / float64(s.Calls)
+}
+
+// Degradation serves degraded answers while a model is unavailable
+type Degradation struct {
+	opts DegradationOptions
+
+	calls      atomic.Int64
+	unanswered atomic.Int64
+	mu         sync.Mutex
+	byMode     map[DegradationMode]int64
+}
+
+// NewDegradation creates a graceful degradation policy
+func NewDegradation(opts DegradationOptions) *Degradation {
+	if opts.ShouldDegrade == nil {
+		opts.ShouldDegrade = func(err error) bool {
+			return errors.Is(err, ErrCircuitOpen)
+		}
+	}
+	return &Degradation{opts: opts, byMode: make(map[DegradationMode]int64)}
+}
+
+// Stats returns the call and degradation counts so far
+func (d *Degradation) Stats() DegradationStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := DegradationStats{
+		Calls:      d.calls.Load(),
+		Unanswered: d.unanswered.Load(),
+		ByMode:     make(map[DegradationMode]int64, len(d.byMode)),
+	}
+	for mode, n := range d.byMode {
+		stats.ByMode[mode] = n
+		stats.Degraded += n
+	}
+	return stats
+}
+
+// LanguageModelMiddleware returns middleware applying the policy. Place it
+// before circuit breaker middleware so it sees calls rejected by an open
+// circuit. Degraded streams replay the degraded answer as a single-chunk
+// stream.
+//
+// Example:
+//
+//	breakers := middleware.NewCircuitBreakerGroup(nil)
+//	degrade := middleware.NewDegradation(middleware.DegradationOptions{
+//		Cache:    middleware.NewMemoryResponseCache(1000),
+//		Fallback: cheapModel,
+//		Response: func(ctx context.Context, params *provider.GenerateOptions, err error) *types.GenerateResult {
+//			return &types.GenerateResult{Text: "We're experiencing high demand, please try again shortly.", FinishReason: types.FinishReasonOther}
+//		},
+//	})
+//	model := middleware.WrapLanguageModel(primary, []*middleware.LanguageModelMiddleware{
+//		degrade.LanguageModelMiddleware(),
+//		breakers.LanguageModelMiddleware(),
+//	}, nil, nil)
+func (d *Degradation) LanguageModelMiddleware() *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			d.calls.Add(1)
+			result, err := doGenerate()
+			if err == nil {
+				d.save(ctx, model, params, result)
+				return result, nil
+			}
+			if !d.opts.ShouldDegrade(err) {
+				return nil, err
+			}
+			return d.degrade(ctx, model, params, err)
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			d.calls.Add(1)
+			stream, err := doStream()
+			if err == nil {
+				if d.opts.Cache == nil {
+					return stream, nil
+				}
+				return &recordingStream{TextStream: stream, ctx: ctx, d: d, model: model, params: params}, nil
+			}
+			if !d.opts.ShouldDegrade(err) {
+				return nil, err
+			}
+			result, err := d.degrade(ctx, model, params, err)
+			if err != nil {
+				return nil, err
+			}
+			return &simulatedStream{ctx: ctx, result: result}, nil
+		},
+	}
+}
+
+// degrade answers a call that failed with err from the cache, the fallback
+// model or the degraded response, in that order
+func (d *Degradation) degrade(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions, err error) (*types.GenerateResult, error) {
+	if d.opts.Cache != nil {
+		result, ok, cacheErr := d.opts.Cache.Get(ctx, model, params)
+		if cacheErr != nil {
+			d.reportError(ctx, cacheErr)
+		}
+		if ok && cacheErr == nil {
+			return d.answer(ctx, model, DegradedCache, err, result), nil
+		}
+	}
+	if d.opts.Fallback != nil {
+		if result, fallbackErr := d.opts.Fallback.DoGenerate(ctx, params); fallbackErr == nil {
+			return d.answer(ctx, model, DegradedFallback, err, result), nil
+		}
+	}
+	if d.opts.Response != nil {
+		if result := d.opts.Response(ctx, params, err); result != nil {
+			return d.answer(ctx, model, DegradedResponse, err, result), nil
+		}
+	}
+
+	d.unanswered.Add(1)
+	if d.opts.OnDegrade != nil {
+		d.opts.OnDegrade(ctx, DegradationEvent{Key: model.Provider() + "/" + model.ModelID(), Err: err})
+	}
+	return nil, err
+}
+
+// answer marks result as degraded, counts it and fires OnDegrade. The result
+// is copied so cached results are never modified.
+func (d *Degradation) answer(ctx context.Context, model provider.LanguageModel, mode DegradationMode, err error, result *types.GenerateResult) *types.GenerateResult {
+	degraded := *result
+	degraded.ProviderMetadata = make(map[string]interface{}, len(result.ProviderMetadata)+1)
+	for k, v := range result.ProviderMetadata {
+		degraded.ProviderMetadata[k] = v
+	}
+	degraded.ProviderMetadata[DegradationMetadataKey] = map[string]interface{}{
+		"mode":   string(mode),
+		"reason": err.Error(),
+	}
+	degraded.Warnings = append(append([]types.Warning{}, result.Warnings...), types.Warning{
+		Type:    "other",
+		Details: fmt.Sprintf("degraded response (%s): %v", mode, err),
+	})
+
+	d.mu.Lock()
+	d.byMode[mode]++
+	d.mu.Unlock()
+	if d.opts.OnDegrade != nil {
+		d.opts.OnDegrade(ctx, DegradationEvent{Key: model.Provider() + "/" + model.ModelID(), Mode: mode, Err: err})
+	}
+	return &degraded
+}
+
+func (d *Degradation) save(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions, result *types.GenerateResult) {
+	if d.opts.Cache == nil {
+		return
+	}
+	if err := d.opts.Cache.Set(ctx, model, params, result); err != nil {
+		d.reportError(ctx, err)
+	}
+}
+
+func (d *Degradation) reportError(ctx context.Context, err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(ctx, fmt.Errorf("response cache: %w", err))
+	}
+}
+
+// recordingStream collects a stream's text and tool calls and caches the
+// result once the stream finishes
+type recordingStream struct {
+	provider.TextStream
+	ctx    context.Context
+	d      *Degradation
+	model  provider.LanguageModel
+	params *provider.GenerateOptions
+
+	text      strings.Builder
+	result    types.GenerateResult
+	finished  bool
+	discarded bool
+}
+
+// Next returns the next chunk, caching the collected result at the end of a
+// successful stream
+func (s *recordingStream) Next() (*provider.StreamChunk, error) {
+	chunk, err := s.TextStream.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) && s.finished && !s.discarded {
+			s.discarded = true
+			s.result.Text = s.text.String()
+			s.d.save(s.ctx, s.model, s.params, &s.result)
+		} else {
+			s.discarded = true
+		}
+		return chunk, err
+	}
+	switch chunk.Type {
+	case provider.ChunkTypeText:
+		s.text.WriteString(chunk.Text)
+	case provider.ChunkTypeToolCall:
+		if chunk.ToolCall != nil {
+			s.result.ToolCalls = append(s.result.ToolCalls, *chunk.ToolCall)
+		}
+	case provider.ChunkTypeFinish:
+		s.finished = true
+		s.result.FinishReason = chunk.FinishReason
+		if chunk.Usage != nil {
+			s.result.Usage = *chunk.Usage
+		}
+	}
+	return chunk, nil
+}
+
+// MemoryResponseCache is an exact-match ResponseCache keyed by
+// RequestFingerprint, with optional least-recently-used eviction
+type MemoryResponseCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type responseCacheEntry struct {
+	key    string
+	result *types.GenerateResult
+}
+
+// NewMemoryResponseCache creates a cache holding at most maxEntries results
+// (0 = unbounded)
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	return &MemoryResponseCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements ResponseCache
+func (c *MemoryResponseCache) Get(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions) (*types.GenerateResult, bool, error) {
+	key := RequestFingerprint(model, params)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*responseCacheEntry).result, true, nil
+}
+
+// Set implements ResponseCache
+func (c *MemoryResponseCache) Set(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions, result *types.GenerateResult) error {
+	key := RequestFingerprint(model, params)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*responseCacheEntry).result = result
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&responseCacheEntry{key: key, result: result})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+	return nil
+}
+
+// SemanticResponseCache is a ResponseCache that matches requests by the
+// meaning of their last user message, so paraphrased questions can be served
+// while a model is unavailable. Requests only match others to the same model
+// with the same system prompt.
+//
+// Every Set and Get embeds the message; wrap the embedding model with
+// NewCachedEmbeddingModel to avoid embedding repeated messages twice.
+type SemanticResponseCache struct {
+	model      provider.EmbeddingModel
+	threshold  float64
+	maxEntries int
+
+	mu      sync.Mutex
+	entries []semanticEntry
+}
+
+type semanticEntry struct {
+	scope     string
+	embedding []float64
+	result    *types.GenerateResult
+}
+
+// NewSemanticResponseCache creates a semantic cache that serves the most
+// similar stored answer with cosine similarity of at least threshold
+// (default 0.95), holding at most maxEntries results (0 = unbounded, oldest
+// evicted first)
+func NewSemanticResponseCache(model provider.EmbeddingModel, threshold float64, maxEntries int) *SemanticResponseCache {
+	if threshold <= 0 {
+		threshold = 0.95
+	}
+	return &SemanticResponseCache{model: model, threshold: threshold, maxEntries: maxEntries}
+}
+
+// Get implements ResponseCache
+func (c *SemanticResponseCache) Get(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions) (*types.GenerateResult, bool, error) {
+	query := lastUserText(params)
+	if query == "" {
+		return nil, false, nil
+	}
+	embedding, err := c.model.DoEmbed(ctx, query, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	scope := semanticScope(model, params)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var best *types.GenerateResult
+	bestScore := c.threshold
+	for _, e := range c.entries {
+		if e.scope != scope {
+			continue
+		}
+		if score := cosine(embedding.Embedding, e.embedding); score >= bestScore {
+			best, bestScore = e.result, score
+		}
+	}
+	return best, best != nil, nil
+}
+
+// Set implements ResponseCache
+func (c *SemanticResponseCache) Set(ctx context.Context, model provider.LanguageModel, params *provider.GenerateOptions, result *types.GenerateResult) error {
+	query := lastUserText(params)
+	if query == "" {
+		return nil
+	}
+	embedding, err := c.model.DoEmbed(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, semanticEntry{scope: semanticScope(model, params), embedding: embedding.Embedding, result: result})
+	if c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		c.entries = append([]semanticEntry(nil), c.entries[len(c.entries)-c.maxEntries:]...)
+	}
+	return nil
+}
+
+// semanticScope limits semantic matches to the same model and system prompt
+func semanticScope(model provider.LanguageModel, params *provider.GenerateOptions) string {
+	return model.Provider() + "/" + model.ModelID() + "\x00" + params.Prompt.System
+}
+
+// lastUserText returns the text of the last user message, or the simple
+// text prompt
+func lastUserText(params *provider.GenerateOptions) string {
+	if params == nil {
+		return ""
+	}
+	for i := len(params.Prompt.Messages) - 1; i >= 0; i-- {
+		msg := params.Prompt.Messages[i]
+		if msg.Role != types.RoleUser {
+			continue
+		}
+		var sb strings.Builder
+		for _, part := range msg.Content {
+			if text, ok := part.(types.TextContent); ok {
+				sb.WriteString(text.Text)
+			}
+		}
+		return sb.String()
+	}
+	return params.Prompt.Text
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when they differ in
+// length or either is zero
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
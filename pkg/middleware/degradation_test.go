@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func userPrompt(text string) *provider.GenerateOptions {
+	return &provider.GenerateOptions{Prompt: types.Prompt{Messages: []types.Message{
+		{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: text}}},
+	}}}
+}
+
+func TestDegradation_CacheFallbackAndResponse(t *testing.T) {
+	t.Parallel()
+
+	outage := false
+	primary := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if outage {
+				return nil, &CircuitOpenError{Key: "mock/mock-model"}
+			}
+			return &types.GenerateResult{Text: "fresh answer", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	fallbackDown := false
+	fallback := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if fallbackDown {
+				return nil, errors.New("fallback unavailable")
+			}
+			return &types.GenerateResult{Text: "cheap answer", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	var events []DegradationEvent
+	respond := true
+	degrade := NewDegradation(DegradationOptions{
+		Cache:    NewMemoryResponseCache(10),
+		Fallback: fallback,
+		Response: func(ctx context.Context, params *provider.GenerateOptions, err error) *types.GenerateResult {
+			if !respond {
+				return nil
+			}
+			return &types.GenerateResult{Text: "busy, try later", FinishReason: types.FinishReasonOther}
+		},
+		OnDegrade: func(ctx context.Context, event DegradationEvent) {
+			events = append(events, event)
+		},
+	})
+	model := WrapLanguageModel(primary, []*LanguageModelMiddleware{degrade.LanguageModelMiddleware()}, nil, nil)
+	ctx := context.Background()
+
+	if _, err := model.DoGenerate(ctx, userPrompt("hello")); err != nil {
+		t.Fatal(err)
+	}
+	outage = true
+
+	tests := []struct {
+		prompt string
+		setup  func()
+		want   string
+		mode   DegradationMode
+	}{
+		{"hello", func() {}, "fresh answer", DegradedCache},
+		{"uncached", func() {}, "cheap answer", DegradedFallback},
+		{"uncached", func() { fallbackDown = true }, "busy, try later", DegradedResponse},
+	}
+	for _, tt := range tests {
+		tt.setup()
+		result, err := model.DoGenerate(ctx, userPrompt(tt.prompt))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+		meta, _ := result.ProviderMetadata[DegradationMetadataKey].(map[string]interface{})
+		if result.Text != tt.want || meta["mode"] != string(tt.mode) {
+			t.Errorf("%s: got %q with metadata %v", tt.mode, result.Text, result.ProviderMetadata)
+		}
+	}
+
+	respond = false
+	if _, err := model.DoGenerate(ctx, userPrompt("uncached")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected the circuit error when nothing can answer, got %v", err)
+	}
+
+	stats := degrade.Stats()
+	if stats.Calls != 5 || stats.Degraded != 3 || stats.Unanswered != 1 || stats.ByMode[DegradedCache] != 1 || stats.Rate() != 0.8 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(events) != 4 || events[3].Mode != "" || events[0].Key != "mock/mock-model" {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+func TestDegradation_Stream(t *testing.T) {
+	t.Parallel()
+
+	outage := false
+	primary := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			if outage {
+				return nil, &CircuitOpenError{Key: "mock/mock-model"}
+			}
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "streamed "},
+				{Type: provider.ChunkTypeText, Text: "answer"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}
+	degrade := NewDegradation(DegradationOptions{Cache: NewMemoryResponseCache(0)})
+	model := WrapLanguageModel(primary, []*LanguageModelMiddleware{degrade.LanguageModelMiddleware()}, nil, nil)
+
+	readAll := func() (string, error) {
+		stream, err := model.DoStream(context.Background(), userPrompt("hi"))
+		if err != nil {
+			return "", err
+		}
+		defer stream.Close() //nolint:errcheck
+		var sb strings.Builder
+		for {
+			chunk, err := stream.Next()
+			if errors.Is(err, io.EOF) {
+				return sb.String(), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(chunk.Text)
+		}
+	}
+
+	if text, err := readAll(); err != nil || text != "streamed answer" {
+		t.Fatalf("got %q, %v", text, err)
+	}
+	outage = true
+	if text, err := readAll(); err != nil || text != "streamed answer" {
+		t.Errorf("expected the cached stream answer, got %q, %v", text, err)
+	}
+}
+
+func TestSemanticResponseCache(t *testing.T) {
+	t.Parallel()
+
+	vectors := map[string][]float64{
+		"what is the capital of france?": {1, 0, 0},
+		"capital of france":              {0.99, 0.1, 0},
+		"how tall is everest?":           {0, 0, 1},
+	}
+	embedder := &testutil.MockEmbeddingModel{
+		DoEmbedFunc: func(ctx context.Context, input string, opts *provider.EmbedModelOptions) (*types.EmbeddingResult, error) {
+			return &types.EmbeddingResult{Embedding: vectors[input]}, nil
+		},
+	}
+	cache := NewSemanticResponseCache(embedder, 0.9, 0)
+	model := &testutil.MockLanguageModel{}
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, model, userPrompt("what is the capital of france?"), &types.GenerateResult{Text: "Paris"}); err != nil {
+		t.Fatal(err)
+	}
+	if result, ok, _ := cache.Get(ctx, model, userPrompt("capital of france")); !ok || result.Text != "Paris" {
+		t.Errorf("expected a paraphrase to match, got %v %v", result, ok)
+	}
+	if _, ok, _ := cache.Get(ctx, model, userPrompt("how tall is everest?")); ok {
+		t.Error("expected an unrelated question not to match")
+	}
+	other := &testutil.MockLanguageModel{ModelName: "other"}
+	if _, ok, _ := cache.Get(ctx, other, userPrompt("capital of france")); ok {
+		t.Error("expected answers not to be shared across models")
+	}
+}
@@ -0,0 +1,330 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DistillationMessage is a single chat message in a fine-tuning record.
+// The shape matches the chat fine-tuning JSONL format accepted by OpenAI
+// and most open-model training toolchains.
+type DistillationMessage struct {
+	Role       string                 `json:"role"`
+	Content    string                 `json:"content"`
+	ToolCalls  []DistillationToolCall `json:"tool_calls,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	ToolCallID string                 `json:"tool_call_id,omitempty"`
+}
+
+// DistillationToolCall is a tool call made by the assistant in a fine-tuning record.
+type DistillationToolCall struct {
+	ID       string                       `json:"id"`
+	Type     string                       `json:"type"`
+	Function DistillationToolCallFunction `json:"function"`
+}
+
+// DistillationToolCallFunction holds the function name and JSON-encoded arguments.
+type DistillationToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// DistillationRecord is one line of the exported JSONL dataset.
+type DistillationRecord struct {
+	// Messages is the full conversation, ending with the assistant response.
+	Messages []DistillationMessage `json:"messages"`
+
+	// Metadata carries the source model and finish reason when
+	// DistillationExportOptions.IncludeMetadata is set. Most fine-tuning
+	// APIs ignore unknown top-level keys, but some reject them, so it is opt-in.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// DistillationExportOptions configures the distillation export middleware.
+type DistillationExportOptions struct {
+	// Writer receives one JSON record per line. Required.
+	// Writes are serialized by the middleware, so any io.Writer is safe to use.
+	Writer io.Writer
+
+	// SampleRate is the fraction of calls to capture, between 0 and 1.
+	// Zero (the default) captures every call.
+	SampleRate float64
+
+	// Scrub is applied to every message content before it is written.
+	// Defaults to ScrubPII. Set to a function returning its input unchanged
+	// to disable scrubbing.
+	Scrub func(text string) string
+
+	// Filter decides whether a completed call should be exported.
+	// Defaults to exporting only calls that finished with FinishReasonStop
+	// or FinishReasonToolCalls, so truncated or filtered outputs are skipped.
+	Filter func(params *provider.GenerateOptions, result *types.GenerateResult) bool
+
+	// IncludeMetadata adds the source provider, model ID and finish reason
+	// to each record under the "metadata" key.
+	IncludeMetadata bool
+
+	// Random returns a float in [0, 1) and is used for sampling.
+	// Defaults to math/rand. Override for deterministic tests.
+	Random func() float64
+
+	// OnError is called when a record cannot be encoded or written.
+	// Export failures never fail the generation call itself.
+	OnError func(err error)
+}
+
+// DistillationExportMiddleware returns middleware that captures production
+// generate calls and writes them as fine-tuning-ready JSONL. Each record holds
+// the prompt messages followed by the model's response, with PII scrubbed.
+//
+// The exported dataset can be used to fine-tune a smaller, cheaper model on
+// the outputs of a larger one.
+//
+// Example:
+//
+//	f, _ := os.Create("distill.jsonl")
+//	defer f.Close()
+//	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+//		DistillationExportMiddleware(&DistillationExportOptions{
+//			Writer:     f,
+//			SampleRate: 0.1,
+//		}),
+//	}, nil, nil)
+//
+// Only non-streaming calls are captured; streaming calls pass through unchanged.
+func DistillationExportMiddleware(options *DistillationExportOptions) *LanguageModelMiddleware {
+	opts := DistillationExportOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Scrub == nil {
+		opts.Scrub = ScrubPII
+	}
+	if opts.Filter == nil {
+		opts.Filter = defaultDistillationFilter
+	}
+	if opts.Random == nil {
+		opts.Random = rand.Float64
+	}
+
+	var mu sync.Mutex
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil || opts.Writer == nil {
+				return result, err
+			}
+			if opts.SampleRate > 0 && opts.SampleRate < 1 && opts.Random() >= opts.SampleRate {
+				return result, nil
+			}
+			if !opts.Filter(params, result) {
+				return result, nil
+			}
+
+			record := buildDistillationRecord(params, result, opts.Scrub)
+			if opts.IncludeMetadata {
+				record.Metadata = map[string]interface{}{
+					"provider":     model.Provider(),
+					"modelId":      model.ModelID(),
+					"finishReason": string(result.FinishReason),
+				}
+			}
+
+			line, encErr := json.Marshal(record)
+			if encErr == nil {
+				mu.Lock()
+				_, encErr = opts.Writer.Write(append(line, '\n'))
+				mu.Unlock()
+			}
+			if encErr != nil && opts.OnError != nil {
+				opts.OnError(encErr)
+			}
+
+			return result, nil
+		},
+	}
+}
+
+// defaultDistillationFilter exports only calls that completed normally.
+func defaultDistillationFilter(_ *provider.GenerateOptions, result *types.GenerateResult) bool {
+	return result.FinishReason == types.FinishReasonStop || result.FinishReason == types.FinishReasonToolCalls
+}
+
+// buildDistillationRecord converts a prompt and its result into a fine-tuning record.
+func buildDistillationRecord(params *provider.GenerateOptions, result *types.GenerateResult, scrub func(string) string) DistillationRecord {
+	var messages []DistillationMessage
+
+	if params != nil {
+		if params.Prompt.System != "" {
+			messages = append(messages, DistillationMessage{Role: string(types.RoleSystem), Content: scrub(params.Prompt.System)})
+		}
+		for _, msg := range params.Prompt.Messages {
+			messages = append(messages, distillationMessageFromMessage(msg, scrub)...)
+		}
+		if params.Prompt.Text != "" {
+			messages = append(messages, DistillationMessage{Role: string(types.RoleUser), Content: scrub(params.Prompt.Text)})
+		}
+	}
+
+	response := DistillationMessage{
+		Role:      string(types.RoleAssistant),
+		Content:   scrub(result.Text),
+		ToolCalls: distillationToolCalls(result.ToolCalls, scrub),
+	}
+	messages = append(messages, response)
+
+	return DistillationRecord{Messages: messages}
+}
+
+// distillationMessageFromMessage flattens a prompt message into one or more
+// fine-tuning messages. Tool messages are split so that each tool result gets
+// its own entry, as required by the chat fine-tuning format.
+func distillationMessageFromMessage(msg types.Message, scrub func(string) string) []DistillationMessage {
+	if msg.Role == types.RoleTool {
+		var out []DistillationMessage
+		for _, part := range msg.Content {
+			tr, ok := part.(types.ToolResultContent)
+			if !ok {
+				continue
+			}
+			out = append(out, DistillationMessage{
+				Role:       string(types.RoleTool),
+				Content:    toolResultText(tr, scrub),
+				Name:       tr.ToolName,
+				ToolCallID: tr.ToolCallID,
+			})
+		}
+		return out
+	}
+
+	var text strings.Builder
+	for _, part := range msg.Content {
+		if tc, ok := part.(types.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+
+	return []DistillationMessage{{
+		Role:      string(msg.Role),
+		Content:   scrub(text.String()),
+		ToolCalls: distillationToolCalls(msg.ToolCalls, scrub),
+		Name:      msg.Name,
+	}}
+}
+
+// distillationToolCalls converts tool calls into the fine-tuning wire shape.
+func distillationToolCalls(calls []types.ToolCall, scrub func(string) string) []DistillationToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]DistillationToolCall, 0, len(calls))
+	for _, call := range calls {
+		args := scrubJSON(call.Arguments, scrub)
+		if args == "" {
+			args = "{}"
+		}
+		out = append(out, DistillationToolCall{
+			ID:   call.ID,
+			Type: "function",
+			Function: DistillationToolCallFunction{
+				Name:      call.ToolName,
+				Arguments: args,
+			},
+		})
+	}
+	return out
+}
+
+// toolResultText renders a scrubbed tool result as plain text for the dataset.
+func toolResultText(tr types.ToolResultContent, scrub func(string) string) string {
+	if tr.Error != "" {
+		return scrub(tr.Error)
+	}
+	value := tr.Result
+	if tr.Output != nil {
+		value = tr.Output.Value
+	}
+	if s, ok := value.(string); ok {
+		return scrub(s)
+	}
+	return scrubJSON(value, scrub)
+}
+
+// scrubJSON marshals value with scrub applied to its string values only, so
+// patterns that match digits or punctuation cannot corrupt the JSON itself.
+// It returns "" if value cannot be marshalled.
+func scrubJSON(value interface{}, scrub func(string) string) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return ""
+	}
+	data, err = json.Marshal(scrubJSONValue(decoded, scrub))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// scrubJSONValue applies scrub to every string in a decoded JSON value
+func scrubJSONValue(value interface{}, scrub func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return scrub(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = scrubJSONValue(item, scrub)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrubJSONValue(item, scrub)
+		}
+	}
+	return value
+}
+
+var (
+	piiEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	piiCardPattern  = regexp.MustCompile(`\b(?:\d[ \-]?){13,19}\b`)
+	piiSSNPattern   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	piiPhonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?\(?\d{3}\)?[ .\-]?\d{3}[ .\-]?\d{4}\b`)
+	piiIPv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// ScrubPII replaces common personally identifiable information in text with
+// placeholder tokens: email addresses, credit card numbers, US social security
+// numbers, phone numbers, and IPv4 addresses.
+//
+// The patterns are intentionally conservative heuristics. Applications with
+// stricter requirements should supply their own DistillationExportOptions.Scrub.
+func ScrubPII(text string) string {
+	text = piiEmailPattern.ReplaceAllString(text, "[EMAIL]")
+	text = piiSSNPattern.ReplaceAllString(text, "[SSN]")
+	text = piiCardPattern.ReplaceAllString(text, "[CARD]")
+	text = piiPhonePattern.ReplaceAllString(text, "[PHONE]")
+	text = piiIPv4Pattern.ReplaceAllString(text, "[IP]")
+	return text
+}
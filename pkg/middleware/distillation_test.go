@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestDistillationExportMiddleware_WritesRecord(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	model := &testutil.MockLanguageModel{}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+		DistillationExportMiddleware(&DistillationExportOptions{
+			Writer:          &buf,
+			IncludeMetadata: true,
+		}),
+	}, nil, nil)
+
+	_, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{
+			System: "You are helpful.",
+			Messages: []types.Message{
+				{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "Email me at jane@example.com"}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(lines))
+	}
+
+	var record DistillationRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid JSON record: %v", err)
+	}
+	if len(record.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(record.Messages))
+	}
+	if record.Messages[0].Role != "system" || record.Messages[2].Role != "assistant" {
+		t.Errorf("unexpected roles: %+v", record.Messages)
+	}
+	if record.Messages[1].Content != "Email me at [EMAIL]" {
+		t.Errorf("expected scrubbed content, got %q", record.Messages[1].Content)
+	}
+	if record.Messages[2].Content != "mock response" {
+		t.Errorf("expected response content, got %q", record.Messages[2].Content)
+	}
+	if record.Metadata["modelId"] != "mock-model" {
+		t.Errorf("expected metadata modelId, got %v", record.Metadata)
+	}
+}
+
+func TestDistillationExportMiddleware_Sampling(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	model := &testutil.MockLanguageModel{}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+		DistillationExportMiddleware(&DistillationExportOptions{
+			Writer:     &buf,
+			SampleRate: 0.5,
+			Random:     func() float64 { return 0.9 },
+		}),
+	}, nil, nil)
+
+	if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "hello"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected call to be sampled out, got %q", buf.String())
+	}
+}
+
+func TestDistillationExportMiddleware_SkipsTruncated(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: "partial", FinishReason: types.FinishReasonLength}, nil
+		},
+	}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+		DistillationExportMiddleware(&DistillationExportOptions{Writer: &buf}),
+	}, nil, nil)
+
+	if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "hello"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected truncated output to be skipped, got %q", buf.String())
+	}
+}
+
+func TestDistillationExportMiddleware_ToolMessages(t *testing.T) {
+	t.Parallel()
+
+	record := buildDistillationRecord(&provider.GenerateOptions{
+		Prompt: types.Prompt{
+			Messages: []types.Message{
+				{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "weather?"}}},
+				{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", ToolName: "weather", Arguments: map[string]interface{}{"city": "Paris"}}}},
+				{Role: types.RoleTool, Content: []types.ContentPart{types.ToolResultContent{ToolCallID: "c1", ToolName: "weather", Result: "sunny"}}},
+			},
+		},
+	}, &types.GenerateResult{Text: "It is sunny."}, func(s string) string { return s })
+
+	if len(record.Messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(record.Messages))
+	}
+	call := record.Messages[1].ToolCalls[0]
+	if call.Function.Name != "weather" || call.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+	if record.Messages[2].ToolCallID != "c1" || record.Messages[2].Content != "sunny" {
+		t.Errorf("unexpected tool message: %+v", record.Messages[2])
+	}
+}
+
+func TestDistillationToolCalls_ScrubsStringValuesOnly(t *testing.T) {
+	t.Parallel()
+
+	calls := distillationToolCalls([]types.ToolCall{{
+		ID:       "c1",
+		ToolName: "pay",
+		Arguments: map[string]interface{}{
+			"card":   "4111 1111 1111 1111",
+			"amount": 1234567890123,
+			"items":  []interface{}{"call 555-123-4567", 3},
+		},
+	}}, ScrubPII)
+
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(calls[0].Function.Arguments), &args); err != nil {
+		t.Fatalf("scrubbed arguments are not valid JSON: %v (%s)", err, calls[0].Function.Arguments)
+	}
+	if args["card"] != "[CARD]" {
+		t.Errorf("expected card to be scrubbed, got %v", args["card"])
+	}
+	if args["amount"] != float64(1234567890123) {
+		t.Errorf("expected numbers to be left alone, got %v", args["amount"])
+	}
+	if items, _ := args["items"].([]interface{}); len(items) != 2 || items[0] != "call [PHONE]" {
+		t.Errorf("expected nested strings to be scrubbed, got %v", args["items"])
+	}
+}
+
+func TestScrubPII(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"contact bob@corp.io", "contact [EMAIL]"},
+		{"ssn 123-45-6789", "ssn [SSN]"},
+		{"card 4111 1111 1111 1111", "card [CARD]"},
+		{"call (555) 123-4567", "call [PHONE]"},
+		{"from 10.0.0.1", "from [IP]"},
+		{"nothing here", "nothing here"},
+	}
+	for _, tt := range tests {
+		if got := ScrubPII(tt.input); got != tt.want {
+			t.Errorf("ScrubPII(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
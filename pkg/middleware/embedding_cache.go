@@ -0,0 +1,365 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// EmbeddingCacheStore persists embeddings by cache key. Implementations must
+// be safe for concurrent use.
+type EmbeddingCacheStore interface {
+	// Get returns the embedding stored under key, reporting whether it exists
+	Get(ctx context.Context, key string) ([]float64, bool, error)
+
+	// Set stores embedding under key
+	Set(ctx context.Context, key string, embedding []float64) error
+}
+
+// EmbeddingCacheOptions configures NewCachedEmbeddingModel
+type EmbeddingCacheOptions struct {
+	// Store holds cached embeddings (default: an unbounded in-memory store)
+	Store EmbeddingCacheStore
+
+	// OnError is called when the store fails. Store failures never fail the
+	// embedding call; the input is embedded by the model instead.
+	OnError func(ctx context.Context, err error)
+}
+
+// EmbeddingCacheStats counts cache lookups
+type EmbeddingCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// CachedEmbeddingModel serves embeddings from a cache keyed by provider,
+// model, provider options and a SHA-256 hash of the input, so unchanged
+// content is never embedded twice. Cache hits are reported in
+// types.EmbeddingUsage.CachedInputs.
+type CachedEmbeddingModel struct {
+	provider.EmbeddingModel
+
+	store   EmbeddingCacheStore
+	onError func(ctx context.Context, err error)
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// NewCachedEmbeddingModel wraps model with an embedding cache
+func NewCachedEmbeddingModel(model provider.EmbeddingModel, opts EmbeddingCacheOptions) *CachedEmbeddingModel {
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryEmbeddingCache(0)
+	}
+	return &CachedEmbeddingModel{EmbeddingModel: model, store: store, onError: opts.OnError}
+}
+
+// Stats returns the number of cache hits and misses so far
+func (m *CachedEmbeddingModel) Stats() EmbeddingCacheStats {
+	return EmbeddingCacheStats{Hits: m.hits.Load(), Misses: m.misses.Load()}
+}
+
+// CacheKey returns the cache key for input under opts
+func (m *CachedEmbeddingModel) CacheKey(input string, opts *provider.EmbedModelOptions) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", m.Provider(), m.ModelID())
+	if opts != nil && len(opts.ProviderOptions) > 0 {
+		// json.Marshal sorts map keys, so equal options hash equally
+		data, _ := json.Marshal(opts.ProviderOptions)
+		h.Write(data)
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(input))
+	return "emb:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// DoEmbed returns the cached embedding for input or embeds and caches it
+func (m *CachedEmbeddingModel) DoEmbed(ctx context.Context, input string, opts *provider.EmbedModelOptions) (*types.EmbeddingResult, error) {
+	key := m.CacheKey(input, opts)
+	if embedding, ok := m.lookup(ctx, key); ok {
+		return &types.EmbeddingResult{Embedding: embedding, Usage: types.EmbeddingUsage{CachedInputs: 1}}, nil
+	}
+	result, err := m.EmbeddingModel.DoEmbed(ctx, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	m.save(ctx, key, result.Embedding)
+	return result, nil
+}
+
+// DoEmbedMany serves cached inputs from the store and embeds the rest in a
+// single call. Duplicate inputs are embedded once.
+func (m *CachedEmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+	embeddings := make([][]float64, len(inputs))
+	keys := make([]string, len(inputs))
+	missIndex := make(map[string][]int)
+	var missInputs []string
+	cached := 0
+
+	for i, input := range inputs {
+		keys[i] = m.CacheKey(input, opts)
+		if idx, pending := missIndex[keys[i]]; pending {
+			missIndex[keys[i]] = append(idx, i)
+			continue
+		}
+		if embedding, ok := m.lookup(ctx, keys[i]); ok {
+			embeddings[i] = embedding
+			cached++
+			continue
+		}
+		missIndex[keys[i]] = []int{i}
+		missInputs = append(missInputs, input)
+	}
+
+	result := &types.EmbeddingsResult{Embeddings: embeddings}
+	if len(missInputs) > 0 {
+		res, err := m.EmbeddingModel.DoEmbedMany(ctx, missInputs, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Embeddings) != len(missInputs) {
+			return nil, fmt.Errorf("model returned %d embeddings for %d inputs", len(res.Embeddings), len(missInputs))
+		}
+		for j, input := range missInputs {
+			key := m.CacheKey(input, opts)
+			for _, i := range missIndex[key] {
+				embeddings[i] = res.Embeddings[j]
+			}
+			m.save(ctx, key, res.Embeddings[j])
+		}
+		result.Usage = res.Usage
+		result.Warnings = res.Warnings
+		result.Responses = res.Responses
+	}
+	result.Usage.CachedInputs = cached
+	return result, nil
+}
+
+func (m *CachedEmbeddingModel) lookup(ctx context.Context, key string) ([]float64, bool) {
+	embedding, ok, err := m.store.Get(ctx, key)
+	if err != nil {
+		m.reportError(ctx, err)
+	}
+	if ok && err == nil {
+		m.hits.Add(1)
+		return embedding, true
+	}
+	m.misses.Add(1)
+	return nil, false
+}
+
+func (m *CachedEmbeddingModel) save(ctx context.Context, key string, embedding []float64) {
+	if err := m.store.Set(ctx, key, embedding); err != nil {
+		m.reportError(ctx, err)
+	}
+}
+
+func (m *CachedEmbeddingModel) reportError(ctx context.Context, err error) {
+	if m.onError != nil {
+		m.onError(ctx, fmt.Errorf("embedding cache: %w", err))
+	}
+}
+
+// MemoryEmbeddingCache is an in-memory EmbeddingCacheStore with optional
+// least-recently-used eviction
+type MemoryEmbeddingCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key       string
+	embedding []float64
+}
+
+// NewMemoryEmbeddingCache creates a store holding at most maxEntries
+// embeddings (0 = unbounded)
+func NewMemoryEmbeddingCache(maxEntries int) *MemoryEmbeddingCache {
+	return &MemoryEmbeddingCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements EmbeddingCacheStore
+func (c *MemoryEmbeddingCache) Get(ctx context.Context, key string) ([]float64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*memoryCacheEntry).embedding, true, nil
+}
+
+// Set implements EmbeddingCacheStore
+func (c *MemoryEmbeddingCache) Set(ctx context.Context, key string, embedding []float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*memoryCacheEntry).embedding = embedding
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, embedding: embedding})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached embeddings
+func (c *MemoryEmbeddingCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// KVEmbeddingCache adapts a byte-oriented key/value store such as Redis or
+// Memcached. Embeddings are encoded with EncodeEmbedding.
+//
+// Example (go-redis):
+//
+//	store := &middleware.KVEmbeddingCache{
+//		Get: func(ctx context.Context, key string) ([]byte, bool, error) {
+//			data, err := rdb.Get(ctx, key).Bytes()
+//			if errors.Is(err, redis.Nil) {
+//				return nil, false, nil
+//			}
+//			return data, err == nil, err
+//		},
+//		Set: func(ctx context.Context, key string, value []byte) error {
+//			return rdb.Set(ctx, key, value, 30*24*time.Hour).Err()
+//		},
+//	}
+//	cached := middleware.NewCachedEmbeddingModel(model, middleware.EmbeddingCacheOptions{Store: store.Store()})
+type KVEmbeddingCache struct {
+	Get func(ctx context.Context, key string) ([]byte, bool, error)
+	Set func(ctx context.Context, key string, value []byte) error
+}
+
+// kvStore exposes KVEmbeddingCache as an EmbeddingCacheStore; the struct's
+// fields share the method names
+type kvStore struct{ kv *KVEmbeddingCache }
+
+// Store returns the EmbeddingCacheStore backed by c
+func (c *KVEmbeddingCache) Store() EmbeddingCacheStore {
+	return kvStore{kv: c}
+}
+
+func (s kvStore) Get(ctx context.Context, key string) ([]float64, bool, error) {
+	data, ok, err := s.kv.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	embedding, err := DecodeEmbedding(data)
+	return embedding, err == nil, err
+}
+
+func (s kvStore) Set(ctx context.Context, key string, embedding []float64) error {
+	return s.kv.Set(ctx, key, EncodeEmbedding(embedding))
+}
+
+// SQLiteEmbeddingCache stores embeddings in a SQLite table through
+// database/sql. The caller opens db with the SQLite driver of their choice.
+type SQLiteEmbeddingCache struct {
+	db    *sql.DB
+	table string
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// SQLiteEmbeddingCacheOptions configures NewSQLiteEmbeddingCache
+type SQLiteEmbeddingCacheOptions struct {
+	// Table is the cache table (default "embedding_cache")
+	Table string
+
+	// TTL is how long a stored embedding is served (0 = forever). Expired
+	// rows are replaced when their input is embedded again.
+	TTL time.Duration
+
+	// Clock is the time source (default clock.System)
+	Clock clock.Clock
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewSQLiteEmbeddingCache creates the cache table if it does not exist and
+// returns a store backed by it
+func NewSQLiteEmbeddingCache(ctx context.Context, db *sql.DB, opts SQLiteEmbeddingCacheOptions) (*SQLiteEmbeddingCache, error) {
+	table := opts.Table
+	if table == "" {
+		table = "embedding_cache"
+	}
+	if !sqlIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" (key TEXT PRIMARY KEY, embedding BLOB NOT NULL, created_at INTEGER NOT NULL)")
+	if err != nil {
+		return nil, fmt.Errorf("create embedding cache table: %w", err)
+	}
+	return &SQLiteEmbeddingCache{db: db, table: table, ttl: opts.TTL, clock: clock.Default(opts.Clock)}, nil
+}
+
+// Get implements EmbeddingCacheStore
+func (c *SQLiteEmbeddingCache) Get(ctx context.Context, key string) ([]float64, bool, error) {
+	var data []byte
+	var createdAt int64
+	err := c.db.QueryRowContext(ctx, "SELECT embedding, created_at FROM "+c.table+" WHERE key = ?", key).Scan(&data, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if c.ttl > 0 && c.clock.Now().Sub(time.Unix(0, createdAt)) >= c.ttl {
+		return nil, false, nil
+	}
+	embedding, err := DecodeEmbedding(data)
+	return embedding, err == nil, err
+}
+
+// Set implements EmbeddingCacheStore
+func (c *SQLiteEmbeddingCache) Set(ctx context.Context, key string, embedding []float64) error {
+	_, err := c.db.ExecContext(ctx, "INSERT OR REPLACE INTO "+c.table+" (key, embedding, created_at) VALUES (?, ?, ?)",
+		key, EncodeEmbedding(embedding), c.clock.Now().UnixNano())
+	return err
+}
+
+// EncodeEmbedding encodes an embedding as little-endian float64 values
+func EncodeEmbedding(embedding []float64) []byte {
+	data := make([]byte, 8*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return data
+}
+
+// DecodeEmbedding decodes the output of EncodeEmbedding
+func DecodeEmbedding(data []byte) ([]float64, error) {
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("invalid embedding encoding: %d bytes", len(data))
+	}
+	embedding := make([]float64, len(data)/8)
+	for i := range embedding {
+		embedding[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+	}
+	return embedding, nil
+}
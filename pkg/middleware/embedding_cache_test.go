@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// lengthEmbedder embeds each input as [len(input)] and records batch calls
+func lengthEmbedder() *testutil.MockEmbeddingModel {
+	return &testutil.MockEmbeddingModel{
+		DoEmbedManyFunc: func(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+			out := make([][]float64, len(inputs))
+			for i, in := range inputs {
+				out[i] = []float64{float64(len(in))}
+			}
+			return &types.EmbeddingsResult{Embeddings: out, Usage: types.EmbeddingUsage{InputTokens: len(inputs), TotalTokens: len(inputs)}}, nil
+		},
+	}
+}
+
+func TestCachedEmbeddingModel_EmbedMany(t *testing.T) {
+	t.Parallel()
+
+	base := lengthEmbedder()
+	cached := NewCachedEmbeddingModel(base, EmbeddingCacheOptions{})
+	ctx := context.Background()
+
+	first, err := cached.DoEmbedMany(ctx, []string{"a", "bb", "a"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Embeddings, [][]float64{{1}, {2}, {1}}) {
+		t.Errorf("unexpected embeddings %v", first.Embeddings)
+	}
+	if !reflect.DeepEqual(base.EmbedManyCalls, [][]string{{"a", "bb"}}) {
+		t.Errorf("expected duplicate inputs to be embedded once, got %v", base.EmbedManyCalls)
+	}
+
+	second, err := cached.DoEmbedMany(ctx, []string{"bb", "ccc"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(second.Embeddings, [][]float64{{2}, {3}}) {
+		t.Errorf("unexpected embeddings %v", second.Embeddings)
+	}
+	if second.Usage.CachedInputs != 1 || second.Usage.InputTokens != 1 {
+		t.Errorf("expected one cached input and one embedded, got %+v", second.Usage)
+	}
+	if len(base.EmbedManyCalls) != 2 || !reflect.DeepEqual(base.EmbedManyCalls[1], []string{"ccc"}) {
+		t.Errorf("expected only the miss to be embedded, got %v", base.EmbedManyCalls)
+	}
+	if stats := cached.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCachedEmbeddingModel_KeyIncludesOptions(t *testing.T) {
+	t.Parallel()
+
+	cached := NewCachedEmbeddingModel(lengthEmbedder(), EmbeddingCacheOptions{})
+	small := &provider.EmbedModelOptions{ProviderOptions: map[string]interface{}{"openai": map[string]interface{}{"dimensions": 256}}}
+	if cached.CacheKey("x", nil) == cached.CacheKey("x", small) {
+		t.Error("provider options should change the cache key")
+	}
+	other := NewCachedEmbeddingModel(&testutil.MockEmbeddingModel{ModelName: "other"}, EmbeddingCacheOptions{})
+	if cached.CacheKey("x", nil) == other.CacheKey("x", nil) {
+		t.Error("model ID should change the cache key")
+	}
+}
+
+func TestCachedEmbeddingModel_StoreErrors(t *testing.T) {
+	t.Parallel()
+
+	var reported []error
+	kv := &KVEmbeddingCache{
+		Get: func(ctx context.Context, key string) ([]byte, bool, error) {
+			return nil, false, errors.New("redis down")
+		},
+		Set: func(ctx context.Context, key string, value []byte) error { return errors.New("redis down") },
+	}
+	cached := NewCachedEmbeddingModel(lengthEmbedder(), EmbeddingCacheOptions{
+		Store:   kv.Store(),
+		OnError: func(ctx context.Context, err error) { reported = append(reported, err) },
+	})
+
+	res, err := cached.DoEmbedMany(context.Background(), []string{"abc"}, nil)
+	if err != nil || res.Embeddings[0][0] != 3 {
+		t.Fatalf("store failures should fall back to the model, got %v, %v", res, err)
+	}
+	if len(reported) != 2 {
+		t.Errorf("expected get and set errors to be reported, got %v", reported)
+	}
+}
+
+func TestMemoryEmbeddingCache_Evicts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := NewMemoryEmbeddingCache(2)
+	_ = c.Set(ctx, "a", []float64{1})
+	_ = c.Set(ctx, "b", []float64{2})
+	_, _, _ = c.Get(ctx, "a")
+	_ = c.Set(ctx, "c", []float64{3})
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok || c.Len() != 2 {
+		t.Error("expected recently used entry to be kept")
+	}
+}
+
+func TestSQLiteEmbeddingCache(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	clk := clock.NewFake(time.Unix(1000, 0))
+	c, err := NewSQLiteEmbeddingCache(ctx, db, SQLiteEmbeddingCacheOptions{TTL: time.Hour, Clock: clk})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok, err := c.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("expected a miss, got ok=%v err=%v", ok, err)
+	}
+	if err := c.Set(ctx, "a", []float64{0.5, -2}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if got, ok, err := c.Get(ctx, "a"); !ok || err != nil || !reflect.DeepEqual(got, []float64{0.5, -2}) {
+		t.Errorf("expected stored embedding, got %v, %v, %v", got, ok, err)
+	}
+
+	clk.Advance(time.Hour)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("expected embedding to expire after TTL")
+	}
+	if err := c.Set(ctx, "a", []float64{1}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if got, ok, _ := c.Get(ctx, "a"); !ok || !reflect.DeepEqual(got, []float64{1}) {
+		t.Errorf("expected re-embedded value to replace the expired row, got %v", got)
+	}
+
+	if _, err := NewSQLiteEmbeddingCache(ctx, db, SQLiteEmbeddingCacheOptions{Table: "x; DROP TABLE y"}); err == nil {
+		t.Error("expected invalid table name to be rejected")
+	}
+}
+
+func TestEncodeEmbedding(t *testing.T) {
+	t.Parallel()
+
+	in := []float64{0.5, -1.25, 3e-9}
+	out, err := DecodeEmbedding(EncodeEmbedding(in))
+	if err != nil || !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip failed: %v, %v", out, err)
+	}
+	if _, err := DecodeEmbedding([]byte{1, 2, 3}); err == nil {
+		t.Error("expected error for truncated data")
+	}
+}
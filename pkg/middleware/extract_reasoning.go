@@ -78,7 +78,7 @@ func ExtractReasoningMiddleware(options *ExtractReasoningOptions) *LanguageModel
 			}
 
 			// Extract all reasoning blocks
-			pattern := fmt.Sprintf(`%s(.*?)%s`, regexp.QuoteMeta(openingTag), regexp.QuoteMeta(closingTag))
+			pattern := fmt.Sprintf(`(?s)%s(.*?)%s`, regexp.QuoteMeta(openingTag), regexp.QuoteMeta(closingTag))
 			re := regexp.MustCompile(pattern)
 			matches := re.FindAllStringSubmatch(text, -1)
 
@@ -93,8 +93,7 @@ func ExtractReasoningMiddleware(options *ExtractReasoningOptions) *LanguageModel
 					reasoningParts[i] = match[1]
 				}
 			}
-			// reasoningText is extracted but not stored in GenerateResult (no field for it yet)
-			_ = strings.Join(reasoningParts, options.Separator)
+			reasoningText := strings.Join(reasoningParts, options.Separator)
 
 			// Remove reasoning blocks from text
 			textWithoutReasoning := text
@@ -116,14 +115,10 @@ func ExtractReasoningMiddleware(options *ExtractReasoningOptions) *LanguageModel
 				textWithoutReasoning = beforeMatch + separator + afterMatch
 			}
 
-			// Update result with separated reasoning and text
-			// Note: The Go SDK stores reasoning separately but still includes it in Text field
-			// for backwards compatibility
+			// Update result with separated reasoning and text; the reasoning is
+			// exposed as a leading reasoning content part
 			result.Text = textWithoutReasoning
-
-			// Store reasoning in a structured way (if there's a field for it in the future)
-			// For now, we've extracted it but the Go SDK doesn't have a separate Reasoning field
-			// in GenerateResult. This is primarily useful for streaming where we emit separate chunks.
+			result.Content = append([]types.ContentPart{types.ReasoningContent{Text: reasoningText}}, result.Content...)
 
 			return result, nil
 		},
@@ -84,6 +84,33 @@ func TestExtractReasoningMiddleware_Generate(t *testing.T) {
 	}
 }
 
+func TestExtractReasoningMiddleware_GenerateReasoningContent(t *testing.T) {
+	mockModel := &mockLanguageModel{
+		generateResult: &types.GenerateResult{
+			Text: "<think>step one\nstep two</think>answer",
+		},
+	}
+
+	middleware := ExtractReasoningMiddleware(&ExtractReasoningOptions{TagName: "think"})
+	wrapped := WrapLanguageModel(mockModel, []*LanguageModelMiddleware{middleware}, nil, nil)
+
+	result, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Text != "answer" {
+		t.Errorf("expected %q, got %q", "answer", result.Text)
+	}
+	if len(result.Content) == 0 {
+		t.Fatal("expected reasoning content")
+	}
+	reasoning, ok := result.Content[0].(types.ReasoningContent)
+	if !ok || reasoning.Text != "step one\nstep two" {
+		t.Errorf("unexpected reasoning content: %#v", result.Content[0])
+	}
+}
+
 func TestExtractReasoningMiddleware_Stream(t *testing.T) {
 	tests := []struct {
 		name              string
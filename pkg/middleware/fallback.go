@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// FallbackOptions configures a fallback chain
+type FallbackOptions struct {
+	// ShouldFallback decides whether an error from one model should move the
+	// call on to the next model. Defaults to falling back on every error
+	// except context cancellation and deadline expiry.
+	ShouldFallback func(err error) bool
+
+	// OnFallback is called each time a model fails and the next one is tried
+	OnFallback func(ctx context.Context, failed provider.LanguageModel, err error, next provider.LanguageModel)
+}
+
+// fallbackLanguageModel tries each model in order until one succeeds
+type fallbackLanguageModel struct {
+	models []provider.LanguageModel
+	opts   FallbackOptions
+}
+
+// FallbackLanguageModel returns a model that tries models in order, moving on
+// to the next one when a call fails. At least one model is required. Combined
+// with circuit breaker middleware, an endpoint with an open circuit is skipped
+// immediately.
+//
+// Metadata and capability methods report the first (primary) model. Streams
+// fall back only when the stream cannot be opened; errors after the first
+// chunk are returned to the caller.
+//
+// Example:
+//
+//	breakers := middleware.NewCircuitBreakerGroup(nil)
+//	mw := []*middleware.LanguageModelMiddleware{breakers.LanguageModelMiddleware()}
+//	model := middleware.FallbackLanguageModel([]provider.LanguageModel{
+//		middleware.WrapLanguageModel(primary, mw, nil, nil),
+//		middleware.WrapLanguageModel(secondary, mw, nil, nil),
+//	}, nil)
+func FallbackLanguageModel(models []provider.LanguageModel, options *FallbackOptions) provider.LanguageModel {
+	opts := FallbackOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.ShouldFallback == nil {
+		opts.ShouldFallback = func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+	return &fallbackLanguageModel{models: models, opts: opts}
+}
+
+// SpecificationVersion returns the specification version
+func (f *fallbackLanguageModel) SpecificationVersion() string {
+	return "v3"
+}
+
+// Provider returns the primary model's provider name
+func (f *fallbackLanguageModel) Provider() string {
+	return f.models[0].Provider()
+}
+
+// ModelID returns the primary model's ID
+func (f *fallbackLanguageModel) ModelID() string {
+	return f.models[0].ModelID()
+}
+
+// SupportsTools returns whether the primary model supports tool calling
+func (f *fallbackLanguageModel) SupportsTools() bool {
+	return f.models[0].SupportsTools()
+}
+
+// SupportsStructuredOutput returns whether the primary model supports structured output
+func (f *fallbackLanguageModel) SupportsStructuredOutput() bool {
+	return f.models[0].SupportsStructuredOutput()
+}
+
+// SupportsImageInput returns whether the primary model accepts image inputs
+func (f *fallbackLanguageModel) SupportsImageInput() bool {
+	return f.models[0].SupportsImageInput()
+}
+
+// DoGenerate tries each model in turn and returns the first success
+func (f *fallbackLanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	var errs []error
+	for i, model := range f.models {
+		result, err := model.DoGenerate(ctx, opts)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, err)
+		if !f.next(ctx, i, err) {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// DoStream tries each model in turn and returns the first stream that opens
+func (f *fallbackLanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	var errs []error
+	for i, model := range f.models {
+		stream, err := model.DoStream(ctx, opts)
+		if err == nil {
+			return stream, nil
+		}
+		errs = append(errs, err)
+		if !f.next(ctx, i, err) {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// next reports whether to try the model after index i, firing OnFallback if so
+func (f *fallbackLanguageModel) next(ctx context.Context, i int, err error) bool {
+	if i == len(f.models)-1 || !f.opts.ShouldFallback(err) {
+		return false
+	}
+	if f.opts.OnFallback != nil {
+		f.opts.OnFallback(ctx, f.models[i], err, f.models[i+1])
+	}
+	return true
+}
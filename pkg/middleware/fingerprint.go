@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// fingerprintVersion changes whenever the fingerprint encoding changes, so
+// keys from older releases never collide with new ones
+const fingerprintVersion = "v1"
+
+// fingerprintRequest is the canonical form of a request that is hashed by
+// RequestFingerprint. Field order is fixed and json.Marshal sorts map keys,
+// so equal requests always encode identically.
+type fingerprintRequest struct {
+	Version              string                 `json:"version"`
+	SpecificationVersion string                 `json:"specificationVersion"`
+	Provider             string                 `json:"provider"`
+	ModelID              string                 `json:"modelId"`
+	System               string                 `json:"system,omitempty"`
+	Text                 string                 `json:"text,omitempty"`
+	Messages             []fingerprintMessage   `json:"messages,omitempty"`
+	Tools                []fingerprintTool      `json:"tools,omitempty"`
+	ToolChoice           types.ToolChoice       `json:"toolChoice"`
+	ResponseFormat       *fingerprintFormat     `json:"responseFormat,omitempty"`
+	Constraint           *types.Constraint      `json:"constraint,omitempty"`
+	Temperature          *float64               `json:"temperature,omitempty"`
+	MaxTokens            *int                   `json:"maxTokens,omitempty"`
+	TopP                 *float64               `json:"topP,omitempty"`
+	TopK                 *int                   `json:"topK,omitempty"`
+	FrequencyPenalty     *float64               `json:"frequencyPenalty,omitempty"`
+	PresencePenalty      *float64               `json:"presencePenalty,omitempty"`
+	StopSequences        []string               `json:"stopSequences,omitempty"`
+	Seed                 *int                   `json:"seed,omitempty"`
+	Reasoning            *types.ReasoningLevel  `json:"reasoning,omitempty"`
+	ProviderOptions      map[string]interface{} `json:"providerOptions,omitempty"`
+}
+
+type fingerprintMessage struct {
+	Role      types.MessageRole `json:"role"`
+	Name      string            `json:"name,omitempty"`
+	Content   []fingerprintPart `json:"content"`
+	ToolCalls []types.ToolCall  `json:"toolCalls,omitempty"`
+}
+
+// fingerprintPart tags a content part with its type, so parts with the
+// same fields (e.g. text and reasoning) hash differently
+type fingerprintPart struct {
+	Type string            `json:"type"`
+	Part types.ContentPart `json:"part"`
+}
+
+type fingerprintTool struct {
+	Name             string                   `json:"name"`
+	Title            string                   `json:"title,omitempty"`
+	Description      string                   `json:"description,omitempty"`
+	Parameters       interface{}              `json:"parameters,omitempty"`
+	OutputSchema     interface{}              `json:"outputSchema,omitempty"`
+	InputExamples    []types.ToolInputExample `json:"inputExamples,omitempty"`
+	Strict           bool                     `json:"strict,omitempty"`
+	ProviderExecuted bool                     `json:"providerExecuted,omitempty"`
+}
+
+type fingerprintFormat struct {
+	Type        string      `json:"type"`
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Schema      interface{} `json:"schema,omitempty"`
+}
+
+// RequestFingerprint returns a stable hash of everything in a request that
+// affects the model's response: the provider, model ID and specification
+// version, the prompt and messages, tool definitions, tool choice, response
+// format and schema, constraint, sampling settings, reasoning level and
+// provider options. Use it as a response cache key or an idempotency key.
+//
+// Headers, telemetry settings and metadata are not included, since they do
+// not change what the model generates. Schemas are hashed in their resolved
+// JSON Schema form, so equivalent schema.Schema values match.
+func RequestFingerprint(model provider.LanguageModel, params *provider.GenerateOptions) string {
+	req := fingerprintRequest{
+		Version:              fingerprintVersion,
+		SpecificationVersion: model.SpecificationVersion(),
+		Provider:             model.Provider(),
+		ModelID:              model.ModelID(),
+	}
+	if params != nil {
+		req.System = params.Prompt.System
+		req.Text = params.Prompt.Text
+		for _, msg := range params.Prompt.Messages {
+			m := fingerprintMessage{Role: msg.Role, Name: msg.Name, ToolCalls: msg.ToolCalls}
+			for _, part := range msg.Content {
+				m.Content = append(m.Content, fingerprintPart{Type: part.ContentType(), Part: part})
+			}
+			req.Messages = append(req.Messages, m)
+		}
+		for _, tool := range params.Tools {
+			req.Tools = append(req.Tools, fingerprintTool{
+				Name:             tool.Name,
+				Title:            tool.Title,
+				Description:      tool.Description,
+				Parameters:       resolveSchema(tool.Parameters),
+				OutputSchema:     resolveSchema(tool.OutputSchema),
+				InputExamples:    tool.InputExamples,
+				Strict:           tool.Strict,
+				ProviderExecuted: tool.ProviderExecuted,
+			})
+		}
+		req.ToolChoice = params.ToolChoice
+		if rf := params.ResponseFormat; rf != nil {
+			req.ResponseFormat = &fingerprintFormat{
+				Type:        rf.Type,
+				Name:        rf.Name,
+				Description: rf.Description,
+				Schema:      resolveSchema(rf.Schema),
+			}
+		}
+		req.Constraint = params.Constraint
+		req.Temperature = params.Temperature
+		req.MaxTokens = params.MaxTokens
+		req.TopP = params.TopP
+		req.TopK = params.TopK
+		req.FrequencyPenalty = params.FrequencyPenalty
+		req.PresencePenalty = params.PresencePenalty
+		req.StopSequences = params.StopSequences
+		req.Seed = params.Seed
+		req.Reasoning = params.Reasoning
+		req.ProviderOptions = params.ProviderOptions
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		// Provider options may hold values JSON cannot encode; fmt prints
+		// maps with sorted keys, so their printed form is stable too
+		req.ProviderOptions = map[string]interface{}{"printed": fmt.Sprint(req.ProviderOptions)}
+		data, _ = json.Marshal(req)
+	}
+	sum := sha256.Sum256(data)
+	return "gen:" + hex.EncodeToString(sum[:])
+}
+
+// resolveSchema returns the JSON Schema of schema.Schema values, and other
+// values unchanged
+func resolveSchema(v interface{}) interface{} {
+	switch s := v.(type) {
+	case schema.Schema:
+		return s.Validator().JSONSchema()
+	case schema.Validator:
+		return s.JSONSchema()
+	}
+	return v
+}
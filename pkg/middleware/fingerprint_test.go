@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func fingerprintParams() *provider.GenerateOptions {
+	temp := 0.2
+	return &provider.GenerateOptions{
+		Prompt: types.Prompt{
+			System: "Be brief.",
+			Messages: []types.Message{
+				{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "Weather in NYC?"}}},
+				{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "call_1", ToolName: "weather", Arguments: map[string]interface{}{"city": "NYC"}}}},
+			},
+		},
+		Tools: []types.Tool{
+			{Name: "weather", Description: "Get the weather", Parameters: map[string]interface{}{"type": "object"}},
+		},
+		Temperature:     &temp,
+		ProviderOptions: map[string]interface{}{"openai": map[string]interface{}{"user": "u1", "store": true}},
+	}
+}
+
+func TestRequestFingerprint_Stable(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	a := RequestFingerprint(model, fingerprintParams())
+	b := RequestFingerprint(model, fingerprintParams())
+	if a != b {
+		t.Errorf("expected equal requests to match, got %s and %s", a, b)
+	}
+
+	// Headers and metadata do not affect the response
+	params := fingerprintParams()
+	params.Headers = map[string]string{"X-Request-ID": "abc"}
+	params.Metadata = map[string]string{"trace": "1"}
+	if got := RequestFingerprint(model, params); got != a {
+		t.Errorf("expected headers and metadata to be ignored")
+	}
+
+	// Equivalent schemas hash equally
+	params = fingerprintParams()
+	params.ResponseFormat = &provider.ResponseFormat{Type: "json", Schema: schema.NewSimpleJSONSchema(map[string]interface{}{"type": "object"})}
+	other := fingerprintParams()
+	other.ResponseFormat = &provider.ResponseFormat{Type: "json", Schema: map[string]interface{}{"type": "object"}}
+	if RequestFingerprint(model, params) != RequestFingerprint(model, other) {
+		t.Errorf("expected schema.Schema and its JSON Schema to match")
+	}
+}
+
+func TestRequestFingerprint_Changes(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	base := RequestFingerprint(model, fingerprintParams())
+
+	tests := []struct {
+		name   string
+		model  provider.LanguageModel
+		mutate func(*provider.GenerateOptions)
+	}{
+		{"model", &testutil.MockLanguageModel{ModelName: "other-model"}, func(*provider.GenerateOptions) {}},
+		{"message", model, func(p *provider.GenerateOptions) {
+			p.Prompt.Messages[0].Content = []types.ContentPart{types.TextContent{Text: "Weather in LA?"}}
+		}},
+		{"content type", model, func(p *provider.GenerateOptions) {
+			p.Prompt.Messages[0].Content = []types.ContentPart{types.ReasoningContent{Text: "Weather in NYC?"}}
+		}},
+		{"tool call", model, func(p *provider.GenerateOptions) {
+			p.Prompt.Messages[1].ToolCalls[0].Arguments = map[string]interface{}{"city": "LA"}
+		}},
+		{"tool definition", model, func(p *provider.GenerateOptions) {
+			p.Tools[0].Description = "Get the forecast"
+		}},
+		{"tool choice", model, func(p *provider.GenerateOptions) {
+			p.ToolChoice = types.ToolChoice{Type: types.ToolChoiceRequired}
+		}},
+		{"schema", model, func(p *provider.GenerateOptions) {
+			p.ResponseFormat = &provider.ResponseFormat{Type: "json", Schema: map[string]interface{}{"type": "array"}}
+		}},
+		{"temperature", model, func(p *provider.GenerateOptions) {
+			temp := 0.3
+			p.Temperature = &temp
+		}},
+		{"provider options", model, func(p *provider.GenerateOptions) {
+			p.ProviderOptions["openai"].(map[string]interface{})["store"] = false
+		}},
+	}
+	for _, tt := range tests {
+		params := fingerprintParams()
+		tt.mutate(params)
+		if got := RequestFingerprint(tt.model, params); got == base {
+			t.Errorf("%s: expected the fingerprint to change", tt.name)
+		}
+	}
+}
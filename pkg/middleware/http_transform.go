@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// WrapProviderHTTP wraps a provider so every API call made by its models
+// passes through the transforms: request transforms rewrite the outgoing
+// payload and headers, response transforms rewrite what the provider
+// decodes. This gives gateway-style customization, such as stripping
+// fields an OpenAI-compatible server rejects or adding organization
+// headers, without forking provider code.
+//
+// Transforms apply to providers built on the SDK's shared HTTP client,
+// which includes the bundled OpenAI, Anthropic and OpenAI-compatible
+// providers. Transforms can also be scoped to a single call with
+// provider.ContextWithHTTPTransforms.
+//
+// Example:
+//
+//	p := middleware.WrapProviderHTTP(openai.New(cfg), provider.HTTPTransforms{
+//		Request: []provider.RequestTransform{
+//			middleware.SetRequestHeaders(map[string]string{"X-Org-ID": "acme"}),
+//			middleware.RemoveRequestFields("stream_options", "parallel_tool_calls"),
+//		},
+//	})
+func WrapProviderHTTP(p provider.Provider, transforms provider.HTTPTransforms) provider.Provider {
+	return &httpTransformProvider{Provider: p, transforms: transforms}
+}
+
+// httpTransformProvider adds HTTP transforms to the context of every model call
+type httpTransformProvider struct {
+	provider.Provider
+	transforms provider.HTTPTransforms
+}
+
+func (p *httpTransformProvider) with(ctx context.Context) context.Context {
+	return provider.ContextWithHTTPTransforms(ctx, p.transforms)
+}
+
+// Ping forwards health checks with the transforms applied
+func (p *httpTransformProvider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return provider.Ping(p.with(ctx), p.Provider)
+}
+
+// ListModels forwards model listing with the transforms applied
+func (p *httpTransformProvider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return provider.ListModels(p.with(ctx), p.Provider)
+}
+
+// Shutdown forwards graceful shutdown to the wrapped provider
+func (p *httpTransformProvider) Shutdown(ctx context.Context) error {
+	return provider.Shutdown(ctx, p.Provider)
+}
+
+// LanguageModel returns a language model by ID, with the transforms applied
+func (p *httpTransformProvider) LanguageModel(modelID string) (provider.LanguageModel, error) {
+	model, err := p.Provider.LanguageModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformLanguageModel{LanguageModel: model, p: p}, nil
+}
+
+// EmbeddingModel returns an embedding model by ID, with the transforms applied
+func (p *httpTransformProvider) EmbeddingModel(modelID string) (provider.EmbeddingModel, error) {
+	model, err := p.Provider.EmbeddingModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformEmbeddingModel{EmbeddingModel: model, p: p}, nil
+}
+
+// ImageModel returns an image model by ID, with the transforms applied
+func (p *httpTransformProvider) ImageModel(modelID string) (provider.ImageModel, error) {
+	model, err := p.Provider.ImageModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformImageModel{ImageModel: model, p: p}, nil
+}
+
+// SpeechModel returns a speech model by ID, with the transforms applied
+func (p *httpTransformProvider) SpeechModel(modelID string) (provider.SpeechModel, error) {
+	model, err := p.Provider.SpeechModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformSpeechModel{SpeechModel: model, p: p}, nil
+}
+
+// TranscriptionModel returns a transcription model by ID, with the transforms applied
+func (p *httpTransformProvider) TranscriptionModel(modelID string) (provider.TranscriptionModel, error) {
+	model, err := p.Provider.TranscriptionModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformTranscriptionModel{TranscriptionModel: model, p: p}, nil
+}
+
+// RerankingModel returns a reranking model by ID, with the transforms applied
+func (p *httpTransformProvider) RerankingModel(modelID string) (provider.RerankingModel, error) {
+	model, err := p.Provider.RerankingModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransformRerankingModel{RerankingModel: model, p: p}, nil
+}
+
+type httpTransformLanguageModel struct {
+	provider.LanguageModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformLanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	return m.LanguageModel.DoGenerate(m.p.with(ctx), opts)
+}
+
+func (m *httpTransformLanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	return m.LanguageModel.DoStream(m.p.with(ctx), opts)
+}
+
+type httpTransformEmbeddingModel struct {
+	provider.EmbeddingModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformEmbeddingModel) DoEmbed(ctx context.Context, input string, opts *provider.EmbedModelOptions) (*types.EmbeddingResult, error) {
+	return m.EmbeddingModel.DoEmbed(m.p.with(ctx), input, opts)
+}
+
+func (m *httpTransformEmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+	return m.EmbeddingModel.DoEmbedMany(m.p.with(ctx), inputs, opts)
+}
+
+type httpTransformImageModel struct {
+	provider.ImageModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformImageModel) DoGenerate(ctx context.Context, opts *provider.ImageGenerateOptions) (*types.ImageResult, error) {
+	return m.ImageModel.DoGenerate(m.p.with(ctx), opts)
+}
+
+type httpTransformSpeechModel struct {
+	provider.SpeechModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformSpeechModel) DoGenerate(ctx context.Context, opts *provider.SpeechGenerateOptions) (*types.SpeechResult, error) {
+	return m.SpeechModel.DoGenerate(m.p.with(ctx), opts)
+}
+
+type httpTransformTranscriptionModel struct {
+	provider.TranscriptionModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformTranscriptionModel) DoTranscribe(ctx context.Context, opts *provider.TranscriptionOptions) (*types.TranscriptionResult, error) {
+	return m.TranscriptionModel.DoTranscribe(m.p.with(ctx), opts)
+}
+
+type httpTransformRerankingModel struct {
+	provider.RerankingModel
+	p *httpTransformProvider
+}
+
+func (m *httpTransformRerankingModel) DoRerank(ctx context.Context, opts *provider.RerankOptions) (*types.RerankResult, error) {
+	return m.RerankingModel.DoRerank(m.p.with(ctx), opts)
+}
+
+// SetRequestHeaders returns a RequestTransform that sets headers on every
+// request, replacing existing values
+func SetRequestHeaders(headers map[string]string) provider.RequestTransform {
+	return func(ctx context.Context, req *provider.HTTPRequest) error {
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return nil
+	}
+}
+
+// RemoveRequestFields returns a RequestTransform that deletes fields from
+// JSON request bodies. Paths are dot-separated, e.g. "stream_options" or
+// "generationConfig.thinkingConfig". Non-JSON bodies are left unchanged.
+func RemoveRequestFields(paths ...string) provider.RequestTransform {
+	return editJSONBody(func(body map[string]interface{}) {
+		for _, path := range paths {
+			parent, key := jsonParent(body, path, false)
+			if parent != nil {
+				delete(parent, key)
+			}
+		}
+	})
+}
+
+// SetRequestFields returns a RequestTransform that sets fields in JSON
+// request bodies, creating intermediate objects as needed. Keys are
+// dot-separated paths, e.g. "metadata.team".
+func SetRequestFields(fields map[string]interface{}) provider.RequestTransform {
+	return editJSONBody(func(body map[string]interface{}) {
+		for path, value := range fields {
+			if parent, key := jsonParent(body, path, true); parent != nil {
+				parent[key] = value
+			}
+		}
+	})
+}
+
+// editJSONBody applies edit to JSON object bodies
+func editJSONBody(edit func(body map[string]interface{})) provider.RequestTransform {
+	return func(ctx context.Context, req *provider.HTTPRequest) error {
+		if len(req.Body) == 0 || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+			return nil
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return nil
+		}
+		edit(body)
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req.Body = data
+		return nil
+	}
+}
+
+// jsonParent returns the object holding the last element of path, and that
+// element's key, optionally creating missing objects along the way
+func jsonParent(body map[string]interface{}, path string, create bool) (map[string]interface{}, string) {
+	parts := strings.Split(path, ".")
+	current := body
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	return current, parts[len(parts)-1]
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+)
+
+func TestWrapProviderHTTP_TransformsRequestsAndResponses(t *testing.T) {
+	t.Parallel()
+
+	var gotBody map[string]interface{}
+	var gotOrg string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOrg = r.Header.Get("X-Org-ID")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"internal answer"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	p := WrapProviderHTTP(openai.New(openai.Config{APIKey: "k", BaseURL: server.URL}), provider.HTTPTransforms{
+		Request: []provider.RequestTransform{
+			SetRequestHeaders(map[string]string{"X-Org-ID": "acme"}),
+			RemoveRequestFields("temperature"),
+			SetRequestFields(map[string]interface{}{"metadata.team": "search"}),
+		},
+		Response: []provider.ResponseTransform{
+			func(ctx context.Context, resp *provider.HTTPResponse) error {
+				resp.Body = []byte(strings.ReplaceAll(string(resp.Body), "internal", "public"))
+				return nil
+			},
+		},
+	})
+	model, err := p.LanguageModel("gpt-4o")
+	if err != nil {
+		t.Fatalf("LanguageModel: %v", err)
+	}
+	temperature := 0.2
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt:      types.Prompt{Text: "hi"},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	if gotOrg != "acme" {
+		t.Errorf("X-Org-ID = %q", gotOrg)
+	}
+	if _, ok := gotBody["temperature"]; ok {
+		t.Error("temperature was not removed")
+	}
+	if meta, _ := gotBody["metadata"].(map[string]interface{}); meta["team"] != "search" {
+		t.Errorf("metadata = %v", gotBody["metadata"])
+	}
+	if gotBody["model"] != "gpt-4o" {
+		t.Errorf("unrelated fields should be kept, model = %v", gotBody["model"])
+	}
+	if result.Text != "public answer" {
+		t.Errorf("Text = %q, want the transformed response", result.Text)
+	}
+}
+
+func TestWrapProviderHTTP_RequestTransformError(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	blocked := errors.New("blocked by policy")
+	p := WrapProviderHTTP(openai.New(openai.Config{APIKey: "k", BaseURL: server.URL}), provider.HTTPTransforms{
+		Request: []provider.RequestTransform{func(ctx context.Context, req *provider.HTTPRequest) error {
+			return blocked
+		}},
+	})
+	model, _ := p.LanguageModel("gpt-4o")
+	_, err := model.DoStream(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "hi"}})
+	if !errors.Is(err, blocked) {
+		t.Errorf("DoStream error = %v, want the transform error", err)
+	}
+	if called {
+		t.Error("request was sent despite the transform error")
+	}
+}
+
+func TestWrapProviderHTTP_ListModels(t *testing.T) {
+	t.Parallel()
+
+	var gotOrg string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOrg = r.Header.Get("X-Org-ID")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":[{"id":"gpt-4o","owned_by":"openai"}]}`)
+	}))
+	defer server.Close()
+
+	p := WrapProviderHTTP(openai.New(openai.Config{APIKey: "k", BaseURL: server.URL}), provider.HTTPTransforms{
+		Request: []provider.RequestTransform{SetRequestHeaders(map[string]string{"X-Org-ID": "acme"})},
+	})
+	models, err := provider.ListModels(context.Background(), p)
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 1 || models[0].ID != "gpt-4o" {
+		t.Errorf("models = %+v", models)
+	}
+	if gotOrg != "acme" {
+		t.Errorf("X-Org-ID = %q, want the transform applied", gotOrg)
+	}
+}
+
+func TestRemoveRequestFields_IgnoresNonJSON(t *testing.T) {
+	t.Parallel()
+
+	req := &provider.HTTPRequest{Header: http.Header{"Content-Type": {"multipart/form-data"}}, Body: []byte("raw")}
+	if err := RemoveRequestFields("a")(context.Background(), req); err != nil || string(req.Body) != "raw" {
+		t.Errorf("body = %q, err = %v", req.Body, err)
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, or 1 when
+// it has none
+func jpegOrientation(data []byte) int {
+	for _, seg := range jpegSegments(data) {
+		if seg.marker != 0xE1 || !bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			continue
+		}
+		tiff := seg.payload[6:]
+		if len(tiff) < 8 {
+			return 1
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return 1
+		}
+		ifd := int(order.Uint32(tiff[4:8]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) == 0x0112 {
+				if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+					return o
+				}
+				return 1
+			}
+		}
+	}
+	return 1
+}
+
+type jpegSegment struct {
+	marker  byte
+	payload []byte
+	raw     []byte
+}
+
+// jpegSegments returns the marker segments before the image data, or nil
+// if data is not a well-formed JPEG
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	var segs []jpegSegment
+	for pos := 2; pos+4 <= len(data) && data[pos] == 0xFF; {
+		marker := data[pos+1]
+		if marker == 0xDA {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil
+		}
+		segs = append(segs, jpegSegment{marker: marker, payload: data[pos+4 : pos+2+length], raw: data[pos : pos+2+length]})
+		pos += 2 + length
+	}
+	return segs
+}
+
+// stripJPEGMetadata removes EXIF, XMP, IPTC and comment segments from a
+// JPEG without re-encoding it. ICC color profiles are kept.
+func stripJPEGMetadata(data []byte) []byte {
+	segs := jpegSegments(data)
+	if segs == nil {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	pos := 2
+	for _, seg := range segs {
+		pos += len(seg.raw)
+		switch seg.marker {
+		case 0xE1, 0xED, 0xFE: // APP1 (EXIF, XMP), APP13 (IPTC), COM
+			continue
+		}
+		out = append(out, seg.raw...)
+	}
+	return append(out, data[pos:]...)
+}
+
+// pngMetadataChunks are the PNG chunks that carry text and EXIF metadata
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true}
+
+// stripPNGMetadata removes text, EXIF and timestamp chunks from a PNG
+// without re-encoding it
+func stripPNGMetadata(data []byte) []byte {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	for pos := len(signature); pos+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		chunk := data[pos:end]
+		if !pngMetadataChunks[string(chunk[4:8])] {
+			out = append(out, chunk...)
+		}
+		pos = end
+	}
+	return out
+}
+
+// orient applies an EXIF orientation so the image displays upright
+func orient(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			si := src.PixOffset(b.Min.X+x, b.Min.Y+y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/media"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ImageLimits describes the images a provider accepts. Zero fields are not
+// limited.
+type ImageLimits struct {
+	// MaxWidth and MaxHeight bound the image dimensions in pixels
+	MaxWidth  int
+	MaxHeight int
+
+	// MaxPixels bounds width × height
+	MaxPixels int
+
+	// MaxBytes bounds the encoded size
+	MaxBytes int
+
+	// Formats lists the accepted media types; other images are re-encoded
+	// as JPEG (or PNG when JPEG is not accepted)
+	Formats []string
+
+	// JPEGQuality is the starting quality for re-encoded JPEGs
+	// Default: 85
+	JPEGQuality int
+
+	// KeepMetadata keeps EXIF, XMP and text metadata, which is otherwise
+	// stripped for privacy (it can carry GPS location and device details)
+	KeepMetadata bool
+}
+
+// AnthropicImageLimits are the Anthropic Messages API image limits
+var AnthropicImageLimits = ImageLimits{
+	MaxWidth:  8000,
+	MaxHeight: 8000,
+	MaxBytes:  5 << 20,
+	Formats:   []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+}
+
+// OpenAIImageLimits are the OpenAI image input limits
+var OpenAIImageLimits = ImageLimits{
+	MaxBytes: 20 << 20,
+	Formats:  []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+}
+
+type imageLimitsKey struct{}
+
+// WithImageLimits returns a context whose calls are preprocessed with limits
+// instead of the middleware's
+func WithImageLimits(ctx context.Context, limits ImageLimits) context.Context {
+	return context.WithValue(ctx, imageLimitsKey{}, limits)
+}
+
+// ImagePreprocessMiddleware returns middleware that fits the image parts of
+// every call to limits before they are sent: oversized images are
+// downscaled, unaccepted formats and images over MaxBytes are re-encoded,
+// and metadata is stripped. EXIF orientation is applied to the pixels
+// before it is removed. Images given only by URL are sent unchanged.
+// WithImageLimits overrides limits for one call.
+//
+// Re-encoded GIFs keep only their first frame. An image that cannot be fit,
+// such as an oversized WebP that cannot be decoded, fails the call before
+// it is sent.
+//
+// Example:
+//
+//	images := middleware.ImagePreprocessMiddleware(middleware.AnthropicImageLimits)
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{images}, nil, nil)
+func ImagePreprocessMiddleware(limits ImageLimits) *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		TransformParams: func(ctx context.Context, callType string, params *provider.GenerateOptions, model provider.LanguageModel) (*provider.GenerateOptions, error) {
+			callLimits := limits
+			if l, ok := ctx.Value(imageLimitsKey{}).(ImageLimits); ok {
+				callLimits = l
+			}
+
+			var messages []types.Message
+			copied := map[int]bool{}
+			for i, msg := range params.Prompt.Messages {
+				for j, part := range msg.Content {
+					img, ok := part.(types.ImageContent)
+					if !ok || len(img.Image) == 0 {
+						continue
+					}
+					data, mediaType, err := PreprocessImage(img.Image, img.MimeType, callLimits)
+					if err != nil {
+						return nil, fmt.Errorf("message %d, part %d: %w", i, j, err)
+					}
+					if bytes.Equal(data, img.Image) && mediaType == img.MimeType {
+						continue
+					}
+					if messages == nil {
+						messages = append([]types.Message(nil), params.Prompt.Messages...)
+					}
+					if !copied[i] {
+						messages[i].Content = append([]types.ContentPart(nil), msg.Content...)
+						copied[i] = true
+					}
+					img.Image, img.MimeType = data, mediaType
+					messages[i].Content[j] = img
+				}
+			}
+			if messages == nil {
+				return params, nil
+			}
+			transformed := *params
+			transformed.Prompt.Messages = messages
+			return &transformed, nil
+		},
+	}
+}
+
+// PreprocessImage fits one image to limits and returns the result and its
+// media type. mediaType may be empty, in which case it is detected. An image
+// already within limits is returned with only its metadata stripped.
+func PreprocessImage(data []byte, mediaType string, limits ImageLimits) ([]byte, string, error) {
+	if mediaType == "" {
+		mediaType = media.DetectImageMediaType(data)
+	}
+	if limits.JPEGQuality <= 0 {
+		limits.JPEGQuality = 85
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		// Formats the standard library cannot decode pass through when they
+		// already fit
+		if accepted(limits, mediaType) && (limits.MaxBytes <= 0 || len(data) <= limits.MaxBytes) {
+			return data, mediaType, nil
+		}
+		return nil, "", fmt.Errorf("cannot fit %s image of %d bytes to limits: %w", mediaType, len(data), err)
+	}
+	mediaType = "image/" + format
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	width, height := config.Width, config.Height
+	if orientation >= 5 {
+		width, height = height, width
+	}
+	scale := fitScale(width, height, limits)
+	stripOrientation := orientation != 1 && !limits.KeepMetadata
+
+	if scale == 1 && !stripOrientation && accepted(limits, mediaType) && (limits.MaxBytes <= 0 || len(data) <= limits.MaxBytes) {
+		if limits.KeepMetadata {
+			return data, mediaType, nil
+		}
+		switch format {
+		case "jpeg":
+			data = stripJPEGMetadata(data)
+		case "png":
+			data = stripPNGMetadata(data)
+		}
+		return data, mediaType, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s image: %w", mediaType, err)
+	}
+	img := orient(toNRGBA(decoded), orientation)
+	return encodeWithinLimits(img, scale, format, limits)
+}
+
+// fitScale returns the factor (at most 1) that fits width × height to the
+// dimension limits
+func fitScale(width, height int, limits ImageLimits) float64 {
+	scale := 1.0
+	if limits.MaxWidth > 0 && width > limits.MaxWidth {
+		scale = math.Min(scale, float64(limits.MaxWidth)/float64(width))
+	}
+	if limits.MaxHeight > 0 && height > limits.MaxHeight {
+		scale = math.Min(scale, float64(limits.MaxHeight)/float64(height))
+	}
+	if limits.MaxPixels > 0 && width*height > limits.MaxPixels {
+		scale = math.Min(scale, math.Sqrt(float64(limits.MaxPixels)/float64(width*height)))
+	}
+	return scale
+}
+
+// encodeWithinLimits scales and encodes img, lowering JPEG quality and then
+// the scale until it fits MaxBytes
+func encodeWithinLimits(img *image.NRGBA, scale float64, format string, limits ImageLimits) ([]byte, string, error) {
+	target := "image/png"
+	switch {
+	case format == "png" && accepted(limits, "image/png"):
+	case accepted(limits, "image/jpeg"):
+		target = "image/jpeg"
+	case !accepted(limits, "image/png"):
+		return nil, "", fmt.Errorf("limits accept neither JPEG nor PNG images")
+	}
+
+	b := img.Bounds()
+	for attempt := 0; attempt < 8; attempt++ {
+		w := max(1, int(math.Floor(float64(b.Dx())*scale)))
+		h := max(1, int(math.Floor(float64(b.Dy())*scale)))
+		scaled := img
+		if w != b.Dx() || h != b.Dy() {
+			scaled = resizeBox(img, w, h)
+		}
+
+		qualities := []int{0}
+		if target == "image/jpeg" {
+			qualities = []int{limits.JPEGQuality}
+			for q := limits.JPEGQuality - 15; q >= 40; q -= 15 {
+				qualities = append(qualities, q)
+			}
+		}
+		for _, quality := range qualities {
+			data, err := encodeImage(scaled, target, quality)
+			if err != nil {
+				return nil, "", err
+			}
+			if limits.MaxBytes <= 0 || len(data) <= limits.MaxBytes {
+				return data, target, nil
+			}
+		}
+		// A PNG that is too large is retried as JPEG before shrinking
+		if target == "image/png" && accepted(limits, "image/jpeg") {
+			target = "image/jpeg"
+			continue
+		}
+		scale *= 0.75
+	}
+	return nil, "", fmt.Errorf("cannot encode image within %d bytes", limits.MaxBytes)
+}
+
+func encodeImage(img *image.NRGBA, mediaType string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if mediaType == "image/png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	// JPEG has no alpha; flatten onto white
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// accepted reports whether limits accept mediaType
+func accepted(limits ImageLimits, mediaType string) bool {
+	if len(limits.Formats) == 0 {
+		return true
+	}
+	for _, f := range limits.Formats {
+		if f == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func toNRGBA(src image.Image) *image.NRGBA {
+	if n, ok := src.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) {
+		return n
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// resizeBox downscales src to w × h, averaging the source pixels that fall
+// in each destination pixel
+func resizeBox(src *image.NRGBA, w, h int) *image.NRGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					pa := uint64(src.Pix[i+3])
+					// Weight color by alpha so transparent pixels do not darken edges
+					r += uint64(src.Pix[i]) * pa
+					g += uint64(src.Pix[i+1]) * pa
+					bl += uint64(src.Pix[i+2]) * pa
+					a += pa
+					n++
+					i += 4
+				}
+			}
+			d := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[d] = uint8(r / a)
+				dst.Pix[d+1] = uint8(g / a)
+				dst.Pix[d+2] = uint8(bl / a)
+			}
+			dst.Pix[d+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// noise returns a w × h image of random pixels, which compresses badly
+func noise(w, h int) *image.NRGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+		if i%4 == 3 {
+			img.Pix[i] = 255
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIFOrientation inserts an EXIF segment with the given orientation
+// after a JPEG's start marker
+func withEXIFOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestPreprocessImage_DownscalesToLimits(t *testing.T) {
+	t.Parallel()
+
+	data, mediaType, err := PreprocessImage(encodePNG(t, noise(400, 100)), "image/png", ImageLimits{MaxWidth: 200, MaxHeight: 200})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || format != "png" || mediaType != "image/png" {
+		t.Fatalf("got %s (%s): %v", format, mediaType, err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("size = %v", b.Size())
+	}
+}
+
+func TestPreprocessImage_AppliesAndStripsEXIF(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, noise(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	tagged := withEXIFOrientation(buf.Bytes(), 6)
+	if jpegOrientation(tagged) != 6 {
+		t.Fatal("test image has no orientation")
+	}
+
+	data, _, err := PreprocessImage(tagged, "image/jpeg", ImageLimits{})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	if bytes.Contains(data, []byte("Exif")) {
+		t.Error("EXIF was not stripped")
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width != 20 || config.Height != 40 {
+		t.Errorf("expected the image rotated upright, got %dx%d (%v)", config.Width, config.Height, err)
+	}
+
+	kept, _, _ := PreprocessImage(tagged, "image/jpeg", ImageLimits{KeepMetadata: true})
+	if !bytes.Equal(kept, tagged) {
+		t.Error("KeepMetadata changed the image")
+	}
+}
+
+func TestPreprocessImage_StripsPNGTextWithoutReencoding(t *testing.T) {
+	t.Parallel()
+
+	data := encodePNG(t, noise(8, 8))
+	chunk := binary.BigEndian.AppendUint32(nil, 12)
+	chunk = append(chunk, "tEXtGPS\x0051.5,0.1"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	ihdrEnd := 8 + 25
+	tagged := append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+
+	out, _, err := PreprocessImage(tagged, "", ImageLimits{})
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("expected only the text chunk to be removed")
+	}
+}
+
+func TestPreprocessImage_ReencodesToFitBytesAndFormats(t *testing.T) {
+	t.Parallel()
+
+	limits := ImageLimits{MaxBytes: 20_000, Formats: []string{"image/jpeg"}}
+	data, mediaType, err := PreprocessImage(encodePNG(t, noise(300, 300)), "image/png", limits)
+	if err != nil {
+		t.Fatalf("PreprocessImage: %v", err)
+	}
+	if mediaType != "image/jpeg" || len(data) > limits.MaxBytes {
+		t.Errorf("got %s of %d bytes", mediaType, len(data))
+	}
+
+	if _, _, err := PreprocessImage([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp", limits); err == nil {
+		t.Error("expected an error for an undecodable format that is not accepted")
+	}
+}
+
+func TestImagePreprocessMiddleware_PerCallLimits(t *testing.T) {
+	t.Parallel()
+
+	var sent types.ImageContent
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			sent = opts.Prompt.Messages[0].Content[1].(types.ImageContent)
+			return &types.GenerateResult{}, nil
+		},
+	}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{ImagePreprocessMiddleware(ImageLimits{MaxWidth: 100})}, nil, nil)
+
+	original := encodePNG(t, noise(64, 64))
+	messages := []types.Message{{Role: types.RoleUser, Content: []types.ContentPart{
+		types.TextContent{Text: "What is this?"},
+		types.ImageContent{Image: original, MimeType: "image/png"},
+	}}}
+
+	ctx := WithImageLimits(context.Background(), ImageLimits{MaxWidth: 16})
+	if _, err := wrapped.DoGenerate(ctx, &provider.GenerateOptions{Prompt: types.Prompt{Messages: messages}}); err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(sent.Image))
+	if err != nil || config.Width != 16 {
+		t.Errorf("expected the per-call limit, got width %d (%v)", config.Width, err)
+	}
+	if !bytes.Equal(messages[0].Content[1].(types.ImageContent).Image, original) {
+		t.Error("caller's message was modified")
+	}
+}
@@ -107,6 +107,13 @@ func (w *wrappedLanguageModel) SupportsImageInput() bool {
 	return w.model.SupportsImageInput()
 }
 
+// SupportsConstraint reports whether the wrapped model enforces constraints
+// of the given kind natively
+func (w *wrappedLanguageModel) SupportsConstraint(kind types.ConstraintKind) bool {
+	cm, ok := w.model.(provider.ConstrainedDecodingModel)
+	return ok && cm.SupportsConstraint(kind)
+}
+
 // DoGenerate performs non-streaming text generation
 func (w *wrappedLanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
 	// Transform parameters if middleware provides transformParams
@@ -228,6 +228,30 @@ func TestWrappedModel_SupportsImageInput(t *testing.T) {
 	}
 }
 
+// regexOnlyModel enforces regex constraints natively
+type regexOnlyModel struct {
+	*testutil.MockLanguageModel
+}
+
+func (m regexOnlyModel) SupportsConstraint(kind types.ConstraintKind) bool {
+	return kind == types.ConstraintRegex
+}
+
+func TestWrappedModel_SupportsConstraint(t *testing.T) {
+	t.Parallel()
+
+	wrapped := WrapLanguageModel(regexOnlyModel{&testutil.MockLanguageModel{}}, []*LanguageModelMiddleware{{}}, nil, nil)
+	cm, ok := wrapped.(provider.ConstrainedDecodingModel)
+	if !ok || !cm.SupportsConstraint(types.ConstraintRegex) || cm.SupportsConstraint(types.ConstraintGrammar) {
+		t.Error("expected SupportsConstraint to be forwarded to the wrapped model")
+	}
+
+	plain := WrapLanguageModel(&testutil.MockLanguageModel{}, []*LanguageModelMiddleware{{}}, nil, nil)
+	if plain.(provider.ConstrainedDecodingModel).SupportsConstraint(types.ConstraintRegex) {
+		t.Error("expected no native constraint support without a constrained model")
+	}
+}
+
 func TestWrappedModel_SpecificationVersion(t *testing.T) {
 	t.Parallel()
 
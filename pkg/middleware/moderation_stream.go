@@ -0,0 +1,383 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// StreamViolation describes a policy violation found by a StreamScanner
+type StreamViolation struct {
+	// Category of the violation, e.g. "self-harm" or "pii"
+	Category string
+
+	// Reason is a human-readable explanation, for logs
+	Reason string
+}
+
+// StreamScanner checks text for policy violations. It returns nil when the
+// text is allowed. Scanners see a sliding window of recent output, not the
+// whole response, and may be called many times per stream.
+type StreamScanner interface {
+	Scan(ctx context.Context, text string) (*StreamViolation, error)
+}
+
+// StreamScannerFunc adapts a function to StreamScanner
+type StreamScannerFunc func(ctx context.Context, text string) (*StreamViolation, error)
+
+// Scan calls f(ctx, text)
+func (f StreamScannerFunc) Scan(ctx context.Context, text string) (*StreamViolation, error) {
+	return f(ctx, text)
+}
+
+// PatternScanner returns a StreamScanner that reports a violation of
+// category when any of the patterns matches
+func PatternScanner(category string, patterns ...*regexp.Regexp) StreamScanner {
+	return StreamScannerFunc(func(ctx context.Context, text string) (*StreamViolation, error) {
+		for _, p := range patterns {
+			if p.MatchString(text) {
+				return &StreamViolation{Category: category, Reason: "matched " + p.String()}, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// ModerationAction selects what happens to a stream when a violation is found
+type ModerationAction string
+
+const (
+	// ModerationTruncate stops the stream: text already delivered stays,
+	// held-back text is dropped and the refusal is appended (the default)
+	ModerationTruncate ModerationAction = "truncate"
+
+	// ModerationReplace holds back all text until the response is complete
+	// and scanned, so a violating response is replaced by the refusal and no
+	// part of it reaches the client. This trades streaming for safety.
+	ModerationReplace ModerationAction = "replace"
+)
+
+// ModerationStreamOptions configures ModerationStreamMiddleware
+type ModerationStreamOptions struct {
+	// Scanner checks the output (required)
+	Scanner StreamScanner
+
+	// Action taken on a violation (default: ModerationTruncate)
+	Action ModerationAction
+
+	// Refusal is the text sent in place of unsafe content
+	// (default: "I can't help with that.")
+	Refusal string
+
+	// WindowSize is how many characters of recent output the scanner sees,
+	// including already delivered text, so violations that span chunks are
+	// caught (default: 500)
+	WindowSize int
+
+	// HoldBack is how many characters of scanned text are held back from the
+	// client, so a violation that only becomes recognizable a few words later
+	// can still be stopped before its start is delivered (default: 100)
+	HoldBack int
+
+	// ScanInterval is how many new characters accumulate between scans; raise
+	// it for slow scanners such as moderation models (default: 0, scan every
+	// chunk). Text is never released before it has been scanned.
+	ScanInterval int
+
+	// FailOpen delivers text when the scanner returns an error. By default a
+	// scanner error is treated as a violation.
+	FailOpen bool
+
+	// OnViolation is called when a violation stops or replaces the output
+	OnViolation func(ctx context.Context, violation StreamViolation)
+}
+
+// ModerationStreamMiddleware returns middleware that scans model output for
+// policy violations as it streams. Text chunks are buffered in a sliding
+// window; each release to the client is preceded by a scan, and a violation
+// cuts the stream off and sends a refusal before the unsafe text is
+// delivered. The stream then finishes with FinishReasonContentFilter.
+// Generate calls are scanned in full and their text replaced by the refusal.
+//
+// Example:
+//
+//	guard := middleware.ModerationStreamMiddleware(&middleware.ModerationStreamOptions{
+//		Scanner: middleware.PatternScanner("secrets", regexp.MustCompile(`sk-[A-Za-z0-9]{20,}`)),
+//	})
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{guard}, nil, nil)
+func ModerationStreamMiddleware(options *ModerationStreamOptions) *LanguageModelMiddleware {
+	opts := *options
+	if opts.Action == "" {
+		opts.Action = ModerationTruncate
+	}
+	if opts.Refusal == "" {
+		opts.Refusal = "I can't help with that."
+	}
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = 500
+	}
+	if opts.HoldBack <= 0 {
+		opts.HoldBack = 100
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil || result.Text == "" {
+				return result, err
+			}
+			violation, err := scanText(ctx, &opts, result.Text)
+			if err != nil {
+				return nil, err
+			}
+			if violation != nil {
+				result.Text = opts.Refusal
+				result.Content = []types.ContentPart{types.TextContent{Text: opts.Refusal}}
+				result.ToolCalls = nil
+				result.FinishReason = types.FinishReasonContentFilter
+			}
+			return result, nil
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			if opts.Scanner == nil {
+				return nil, errors.New("moderation scanner is required")
+			}
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &moderatedStream{underlying: stream, ctx: ctx, opts: &opts}, nil
+		},
+	}
+}
+
+// scanText scans text, reporting violations and applying FailOpen. The
+// returned error is only set when the scanner fails and FailOpen is not.
+func scanText(ctx context.Context, opts *ModerationStreamOptions, text string) (*StreamViolation, error) {
+	if opts.Scanner == nil {
+		return nil, errors.New("moderation scanner is required")
+	}
+	violation, err := opts.Scanner.Scan(ctx, text)
+	if err != nil {
+		if opts.FailOpen {
+			return nil, nil
+		}
+		violation = &StreamViolation{Category: "scan-error", Reason: err.Error()}
+	}
+	if violation != nil && opts.OnViolation != nil {
+		opts.OnViolation(ctx, *violation)
+	}
+	return violation, nil
+}
+
+// moderatedStream holds text back until it has been scanned
+type moderatedStream struct {
+	underlying provider.TextStream
+	ctx        context.Context
+	opts       *ModerationStreamOptions
+
+	held      string // scanned or unscanned text not yet delivered
+	unscanned int    // bytes at the end of held added since the last scan
+	delivered string // tail of delivered text, kept for the scan window
+	textID    string // ID of the text block the held text belongs to
+	openText  bool   // a text-start was delivered without its text-end
+
+	queue    []*provider.StreamChunk
+	deferred []*provider.StreamChunk // all chunks, for ModerationReplace
+	stopped  bool
+	done     bool
+}
+
+// Next returns the next chunk that has passed moderation
+func (s *moderatedStream) Next() (*provider.StreamChunk, error) {
+	for len(s.queue) == 0 {
+		if s.done {
+			return nil, io.EOF
+		}
+		chunk, err := s.underlying.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				s.done = true
+				s.flush(true)
+				continue
+			}
+			return chunk, err
+		}
+		s.process(chunk)
+	}
+	chunk := s.queue[0]
+	s.queue = s.queue[1:]
+	return chunk, nil
+}
+
+func (s *moderatedStream) process(chunk *provider.StreamChunk) {
+	if s.opts.Action == ModerationReplace {
+		s.deferChunk(chunk)
+		return
+	}
+	switch chunk.Type {
+	case provider.ChunkTypeText:
+		if s.held != "" && chunk.ID != s.textID {
+			if !s.flush(true) {
+				return
+			}
+		}
+		s.textID = chunk.ID
+		s.held += chunk.Text
+		s.unscanned += len(chunk.Text)
+		if s.unscanned > s.opts.ScanInterval {
+			s.flush(false)
+		}
+	default:
+		// Non-text output (tool calls, text-end, finish, ...) must not
+		// overtake the text before it, so the held text is scanned and
+		// released first
+		if !s.flush(true) {
+			return
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeTextStart:
+			s.openText = true
+		case provider.ChunkTypeTextEnd:
+			s.openText = false
+		}
+		s.queue = append(s.queue, chunk)
+	}
+}
+
+// deferChunk buffers a chunk for ModerationReplace, scanning the text so far
+// at each ScanInterval so a violation ends the stream early
+func (s *moderatedStream) deferChunk(chunk *provider.StreamChunk) {
+	s.deferred = append(s.deferred, chunk)
+	if chunk.Type != provider.ChunkTypeText {
+		return
+	}
+	s.textID = chunk.ID
+	s.held += chunk.Text
+	s.unscanned += len(chunk.Text)
+	if s.unscanned > s.opts.ScanInterval {
+		s.scan(s.held)
+	}
+}
+
+// flush scans the held text and releases it, keeping HoldBack characters
+// unless all is set. It reports false when a violation stopped the stream.
+func (s *moderatedStream) flush(all bool) bool {
+	if s.stopped {
+		return false
+	}
+	if s.opts.Action == ModerationReplace {
+		// Called at the end of the stream: release everything once clean
+		if !s.scan(s.held) {
+			return false
+		}
+		s.queue = append(s.queue, s.deferred...)
+		s.deferred = nil
+		return true
+	}
+	if !s.scan(s.delivered + s.held) {
+		return false
+	}
+
+	release := s.held
+	if !all {
+		release = s.held[:cutBefore(s.held, s.opts.HoldBack)]
+	}
+	if release == "" {
+		return true
+	}
+	s.held = s.held[len(release):]
+	s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, ID: s.textID, Text: release})
+	s.delivered += release
+	if over := len(s.delivered) - s.opts.WindowSize; over > 0 {
+		s.delivered = s.delivered[cutAfter(s.delivered, over):]
+	}
+	return true
+}
+
+// scan checks window when there is unscanned text and stops the stream on
+// a violation. It reports false when the stream was stopped.
+func (s *moderatedStream) scan(window string) bool {
+	if s.stopped {
+		return false
+	}
+	if s.unscanned == 0 {
+		return true
+	}
+	if violation, _ := scanText(s.ctx, s.opts, window); violation != nil {
+		s.stop()
+		return false
+	}
+	s.unscanned = 0
+	return true
+}
+
+// stop drops held text, sends the refusal and finishes the stream
+func (s *moderatedStream) stop() {
+	s.stopped = true
+	s.done = true
+	s.held = ""
+	s.queue = nil
+	s.deferred = nil
+	s.underlying.Close()
+
+	if s.opts.Action == ModerationTruncate && s.delivered != "" {
+		s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, ID: s.textID, Text: "\n\n" + s.opts.Refusal})
+	} else {
+		s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, ID: s.textID, Text: s.opts.Refusal})
+	}
+	if s.openText {
+		s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeTextEnd, ID: s.textID})
+	}
+	s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonContentFilter})
+}
+
+// Close closes the underlying stream
+func (s *moderatedStream) Close() error {
+	s.done = true
+	return s.underlying.Close()
+}
+
+// Err returns the underlying stream's error
+func (s *moderatedStream) Err() error {
+	return s.underlying.Err()
+}
+
+// cutBefore returns the byte offset that leaves the last keep characters of
+// text, on a rune boundary
+func cutBefore(text string, keep int) int {
+	i := len(text)
+	for n := 0; n < keep && i > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:i])
+		i -= size
+	}
+	return i
+}
+
+// cutAfter returns the first rune boundary at or after byte offset i
+func cutAfter(text string, i int) int {
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	return i
+}
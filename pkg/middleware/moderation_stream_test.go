@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func moderatedModel(t *testing.T, opts *ModerationStreamOptions, texts ...string) provider.LanguageModel {
+	t.Helper()
+	chunks := []provider.StreamChunk{{Type: provider.ChunkTypeTextStart, ID: "t1"}}
+	for _, text := range texts {
+		chunks = append(chunks, provider.StreamChunk{Type: provider.ChunkTypeText, ID: "t1", Text: text})
+	}
+	chunks = append(chunks,
+		provider.StreamChunk{Type: provider.ChunkTypeTextEnd, ID: "t1"},
+		provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+	)
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, o *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream(chunks), nil
+		},
+		DoGenerateFunc: func(ctx context.Context, o *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: strings.Join(texts, ""), FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	return WrapLanguageModel(model, []*LanguageModelMiddleware{ModerationStreamMiddleware(opts)}, nil, nil)
+}
+
+// readModerated returns the delivered text, the chunk types and the finish reason
+func readModerated(t *testing.T, model provider.LanguageModel) (string, []provider.ChunkType, types.FinishReason) {
+	t.Helper()
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("DoStream: %v", err)
+	}
+	defer stream.Close()
+	var text strings.Builder
+	var kinds []provider.ChunkType
+	var finish types.FinishReason
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return text.String(), kinds, finish
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		kinds = append(kinds, chunk.Type)
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case provider.ChunkTypeFinish:
+			finish = chunk.FinishReason
+		}
+	}
+}
+
+var secretScanner = PatternScanner("secrets", regexp.MustCompile(`sk-[a-z0-9]{8,}`))
+
+func TestModerationStream_PassesCleanText(t *testing.T) {
+	t.Parallel()
+
+	model := moderatedModel(t, &ModerationStreamOptions{Scanner: secretScanner, HoldBack: 5}, "Hello ", "there, ", "how are you?")
+	text, kinds, finish := readModerated(t, model)
+	if text != "Hello there, how are you?" || finish != types.FinishReasonStop {
+		t.Errorf("text %q finish %q", text, finish)
+	}
+	if kinds[0] != provider.ChunkTypeTextStart || kinds[len(kinds)-2] != provider.ChunkTypeTextEnd {
+		t.Errorf("chunk order = %v", kinds)
+	}
+}
+
+func TestModerationStream_TruncatesBeforeUnsafeTextIsDelivered(t *testing.T) {
+	t.Parallel()
+
+	var violations []StreamViolation
+	model := moderatedModel(t, &ModerationStreamOptions{
+		Scanner:     secretScanner,
+		HoldBack:    12,
+		Refusal:     "[redacted]",
+		OnViolation: func(ctx context.Context, v StreamViolation) { violations = append(violations, v) },
+	}, "Here is some context for you. ", "The key is sk-", "abc12345", "xyz and more")
+	text, kinds, finish := readModerated(t, model)
+
+	if strings.Contains(text, "sk-") {
+		t.Errorf("unsafe text reached the client: %q", text)
+	}
+	if !strings.HasPrefix(text, "Here is some context") || !strings.HasSuffix(text, "[redacted]") {
+		t.Errorf("text = %q", text)
+	}
+	if finish != types.FinishReasonContentFilter {
+		t.Errorf("finish = %q, want content-filter", finish)
+	}
+	if kinds[len(kinds)-2] != provider.ChunkTypeTextEnd {
+		t.Errorf("expected the open text block to be closed: %v", kinds)
+	}
+	if len(violations) != 1 || violations[0].Category != "secrets" {
+		t.Errorf("violations = %+v", violations)
+	}
+}
+
+func TestModerationStream_WindowSpansChunks(t *testing.T) {
+	t.Parallel()
+
+	// The pattern only matches across three chunks
+	model := moderatedModel(t, &ModerationStreamOptions{Scanner: secretScanner, HoldBack: 4},
+		"token: s", "k-abc", "defgh done")
+	text, _, finish := readModerated(t, model)
+	if finish != types.FinishReasonContentFilter || strings.Contains(text, "abcdefgh") {
+		t.Errorf("text %q finish %q", text, finish)
+	}
+}
+
+func TestModerationStream_Replace(t *testing.T) {
+	t.Parallel()
+
+	model := moderatedModel(t, &ModerationStreamOptions{Scanner: secretScanner, Action: ModerationReplace, Refusal: "No."},
+		"Totally safe start. ", "sk-abcdefgh1")
+	text, kinds, finish := readModerated(t, model)
+	if text != "No." || finish != types.FinishReasonContentFilter {
+		t.Errorf("text %q finish %q", text, finish)
+	}
+	if kinds[0] != provider.ChunkTypeText {
+		t.Errorf("nothing of the original response should be delivered: %v", kinds)
+	}
+
+	clean := moderatedModel(t, &ModerationStreamOptions{Scanner: secretScanner, Action: ModerationReplace}, "All ", "good.")
+	if text, _, finish := readModerated(t, clean); text != "All good." || finish != types.FinishReasonStop {
+		t.Errorf("clean text %q finish %q", text, finish)
+	}
+}
+
+func TestModerationStream_ScannerErrors(t *testing.T) {
+	t.Parallel()
+
+	failing := StreamScannerFunc(func(ctx context.Context, text string) (*StreamViolation, error) {
+		return nil, errors.New("moderation API down")
+	})
+	_, _, finish := readModerated(t, moderatedModel(t, &ModerationStreamOptions{Scanner: failing}, "hi"))
+	if finish != types.FinishReasonContentFilter {
+		t.Errorf("fail-closed finish = %q", finish)
+	}
+	text, _, finish := readModerated(t, moderatedModel(t, &ModerationStreamOptions{Scanner: failing, FailOpen: true}, "hi"))
+	if text != "hi" || finish != types.FinishReasonStop {
+		t.Errorf("fail-open text %q finish %q", text, finish)
+	}
+}
+
+func TestModerationStream_Generate(t *testing.T) {
+	t.Parallel()
+
+	model := moderatedModel(t, &ModerationStreamOptions{Scanner: secretScanner}, "key sk-abcdefgh1")
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	if result.Text != "I can't help with that." || result.FinishReason != types.FinishReasonContentFilter {
+		t.Errorf("result = %q %q", result.Text, result.FinishReason)
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 )
 
@@ -27,6 +29,21 @@ func (w *wrappedProvider) Name() string {
 	return w.provider.Name()
 }
 
+// Ping forwards health checks to the wrapped provider
+func (w *wrappedProvider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return provider.Ping(ctx, w.provider)
+}
+
+// ListModels forwards model listing to the wrapped provider
+func (w *wrappedProvider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return provider.ListModels(ctx, w.provider)
+}
+
+// Shutdown forwards graceful shutdown to the wrapped provider
+func (w *wrappedProvider) Shutdown(ctx context.Context) error {
+	return provider.Shutdown(ctx, w.provider)
+}
+
 // LanguageModel returns a language model by ID, with middleware applied
 func (w *wrappedProvider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	model, err := w.provider.LanguageModel(modelID)
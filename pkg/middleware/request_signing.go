@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/google/uuid"
+)
+
+// Header names used by signed requests
+const (
+	HeaderSignatureKey       = "X-Signature-Key"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureNonce     = "X-Signature-Nonce"
+	HeaderSignature          = "X-Signature"
+)
+
+// Errors returned by RequestVerifier.Verify
+var (
+	ErrSignatureMissing    = errors.New("request signature: missing signature headers")
+	ErrSignatureUnknownKey = errors.New("request signature: unknown key")
+	ErrSignatureExpired    = errors.New("request signature: timestamp outside tolerance")
+	ErrSignatureMismatch   = errors.New("request signature: signature mismatch")
+	ErrSignatureReplayed   = errors.New("request signature: nonce already used")
+)
+
+// SignRequest signs req for server-to-server calls to generation endpoints.
+// The signature is a hex HMAC-SHA256 over the method, path and query,
+// timestamp, a random nonce and the SHA-256 of the body, sent as
+//
+//	X-Signature-Key:       keyID
+//	X-Signature-Timestamp: Unix seconds
+//	X-Signature-Nonce:     random ID, rejected if seen again
+//	X-Signature:           v1=<hex HMAC>
+//
+// The body is read and replaced, so req can still be sent.
+func SignRequest(req *http.Request, keyID, secret string, now time.Time) error {
+	body, err := readRequestBody(req, 0)
+	if err != nil {
+		return err
+	}
+	timestamp := now.Unix()
+	nonce := uuid.New().String()
+	req.Header.Set(HeaderSignatureKey, keyID)
+	req.Header.Set(HeaderSignatureTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignatureNonce, nonce)
+	req.Header.Set(HeaderSignature, "v1="+signRequest(secret, req, timestamp, nonce, body))
+	return nil
+}
+
+// SigningTransport is an http.RoundTripper that signs every request with
+// SignRequest, for HTTP clients calling a server protected by
+// RequestVerifier.
+//
+// Example:
+//
+//	client := &http.Client{Transport: &middleware.SigningTransport{KeyID: "billing", Secret: secret}}
+type SigningTransport struct {
+	// KeyID and Secret identify the caller (required)
+	KeyID  string
+	Secret string
+
+	// Base sends the signed requests (default: http.DefaultTransport)
+	Base http.RoundTripper
+
+	// Clock timestamps signatures (default: system clock)
+	Clock clock.Clock
+}
+
+// RoundTrip signs a clone of req and sends it
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := SignRequest(req, t.KeyID, t.Secret, clock.Default(t.Clock).Now()); err != nil {
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// RequestVerifierOptions configures a RequestVerifier
+type RequestVerifierOptions struct {
+	// Keys maps key IDs to secrets (required). Several keys allow one per
+	// caller and rotation without downtime.
+	Keys map[string]string
+
+	// Tolerance is how far a signature's timestamp may be from now
+	// (default: 5 minutes). Nonces are remembered for twice this long.
+	Tolerance time.Duration
+
+	// MaxBodyBytes limits the body read for verification (default: 10 MB)
+	MaxBodyBytes int64
+
+	// Clock is used for timestamp checks and nonce expiry (default: system clock)
+	Clock clock.Clock
+}
+
+// RequestVerifier checks request signatures made by SignRequest and rejects
+// replayed requests. It is safe for concurrent use. Nonces are kept in
+// memory, so replicas behind a load balancer each see only their own.
+//
+// Example:
+//
+//	verifier := middleware.NewRequestVerifier(middleware.RequestVerifierOptions{
+//		Keys: map[string]string{"billing": os.Getenv("BILLING_SIGNING_SECRET")},
+//	})
+//	http.Handle("/generate", verifier.Handler(generateHandler))
+type RequestVerifier struct {
+	opts  RequestVerifierOptions
+	clock clock.Clock
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	checks int
+}
+
+// NewRequestVerifier creates a verifier with the given options
+func NewRequestVerifier(opts RequestVerifierOptions) *RequestVerifier {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 5 * time.Minute
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 10 << 20
+	}
+	return &RequestVerifier{opts: opts, clock: clock.Default(opts.Clock), nonces: make(map[string]time.Time)}
+}
+
+// Verify checks r's signature and returns the key ID that signed it. The
+// body is read and replaced so handlers can still read it. A nonce is only
+// recorded once the signature is valid, so forged requests cannot burn
+// nonces.
+func (v *RequestVerifier) Verify(r *http.Request) (string, error) {
+	keyID := r.Header.Get(HeaderSignatureKey)
+	ts := r.Header.Get(HeaderSignatureTimestamp)
+	nonce := r.Header.Get(HeaderSignatureNonce)
+	sigs := r.Header.Get(HeaderSignature)
+	if keyID == "" || ts == "" || nonce == "" || sigs == "" {
+		return "", ErrSignatureMissing
+	}
+	secret, ok := v.opts.Keys[keyID]
+	if !ok {
+		return "", ErrSignatureUnknownKey
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", ErrSignatureMissing
+	}
+	now := v.clock.Now()
+	if age := now.Sub(time.Unix(timestamp, 0)); age > v.opts.Tolerance || age < -v.opts.Tolerance {
+		return "", ErrSignatureExpired
+	}
+
+	body, err := readRequestBody(r, v.opts.MaxBodyBytes)
+	if err != nil {
+		return "", err
+	}
+	expected := []byte(signRequest(secret, r, timestamp, nonce, body))
+	valid := false
+	for _, sig := range strings.Fields(sigs) {
+		if s, ok := strings.CutPrefix(sig, "v1="); ok && hmac.Equal([]byte(s), expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", ErrSignatureMismatch
+	}
+
+	if !v.useNonce(keyID+":"+nonce, now) {
+		return "", ErrSignatureReplayed
+	}
+	return keyID, nil
+}
+
+// useNonce records a nonce, reporting false if it was already used
+func (v *RequestVerifier) useNonce(nonce string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Signatures older than the tolerance are rejected before reaching
+	// here, so older nonces can be forgotten
+	v.checks++
+	if v.checks%256 == 0 {
+		for n, seen := range v.nonces {
+			if now.Sub(seen) > 2*v.opts.Tolerance {
+				delete(v.nonces, n)
+			}
+		}
+	}
+	if _, seen := v.nonces[nonce]; seen {
+		return false
+	}
+	v.nonces[nonce] = now
+	return true
+}
+
+type signingKeyContextKey struct{}
+
+// SigningKeyFromContext returns the key ID that signed the request being
+// handled, as set by RequestVerifier.Handler
+func SigningKeyFromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(signingKeyContextKey{}).(string)
+	return keyID, ok
+}
+
+// Handler wraps an HTTP handler so only correctly signed, fresh requests
+// reach next. Others receive 401 Unauthorized with a JSON error. The
+// verified key ID is available from SigningKeyFromContext.
+func (v *RequestVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, err := v.Verify(r)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), signingKeyContextKey{}, keyID)))
+	})
+}
+
+// signRequest returns the hex HMAC-SHA256 of
+// "method\npath?query\ntimestamp\nnonce\nhex(sha256(body))"
+func signRequest(secret string, r *http.Request, timestamp int64, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n%s", r.Method, r.URL.RequestURI(), timestamp, nonce, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readRequestBody reads r's body and replaces it with an in-memory copy.
+// A positive limit rejects larger bodies.
+func readRequestBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	reader := io.Reader(r.Body)
+	if limit > 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("request signature: failed to read body: %w", err)
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("request signature: body exceeds %d bytes", limit)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return body, nil
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+func TestRequestVerifier(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	verifier := NewRequestVerifier(RequestVerifierOptions{
+		Keys:  map[string]string{"billing": "s3cret"},
+		Clock: fake,
+	})
+	server := httptest.NewServer(verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, _ := SigningKeyFromContext(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, keyID+":"+string(body))
+	})))
+	defer server.Close()
+
+	client := &http.Client{Transport: &SigningTransport{KeyID: "billing", Secret: "s3cret", Clock: fake}}
+	resp, err := client.Post(server.URL+"/generate?stream=false", "application/json", strings.NewReader(`{"prompt":"hi"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK || string(body) != `billing:{"prompt":"hi"}` {
+		t.Fatalf("expected the signed request to pass, got %d %s", resp.StatusCode, body)
+	}
+
+	sign := func(body string, secret string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(body))
+		if err := SignRequest(req, "billing", secret, fake.Now()); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	// Replaying the same signed request is rejected
+	req := sign(`{"prompt":"hi"}`, "s3cret")
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"prompt":"hi"}`))
+	if _, err := verifier.Verify(req); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+	if _, err := verifier.Verify(replay); !errors.Is(err, ErrSignatureReplayed) {
+		t.Errorf("expected ErrSignatureReplayed, got %v", err)
+	}
+
+	// A tampered body fails verification
+	req = sign(`{"prompt":"hi"}`, "s3cret")
+	req.Body = io.NopCloser(strings.NewReader(`{"prompt":"bye"}`))
+	if _, err := verifier.Verify(req); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("expected ErrSignatureMismatch, got %v", err)
+	}
+
+	if _, err := verifier.Verify(sign("{}", "wrong")); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("expected ErrSignatureMismatch for a wrong secret, got %v", err)
+	}
+
+	// Stale signatures are rejected
+	req = sign("{}", "s3cret")
+	fake.Advance(6 * time.Minute)
+	if _, err := verifier.Verify(req); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expected ErrSignatureExpired, got %v", err)
+	}
+
+	if _, err := verifier.Verify(httptest.NewRequest(http.MethodPost, "/generate", nil)); !errors.Is(err, ErrSignatureMissing) {
+		t.Errorf("expected ErrSignatureMissing, got %v", err)
+	}
+	resp, err = http.Post(server.URL+"/generate", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unsigned request, got %d", resp.StatusCode)
+	}
+}
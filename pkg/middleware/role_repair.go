@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// RoleRepairOptions configures RepairMessages and RoleRepairMiddleware
+type RoleRepairOptions struct {
+	// ContinuePrompt is the user message inserted before a conversation that
+	// starts with an assistant message
+	// Default: "Continue."
+	ContinuePrompt string
+
+	// MissingToolResult is the error reported for tool calls that have no
+	// result
+	// Default: "tool result missing"
+	MissingToolResult string
+
+	// OnRepair is called by RoleRepairMiddleware with the warnings for every
+	// call whose messages were changed
+	OnRepair func(ctx context.Context, warnings []types.Warning)
+}
+
+// RepairMessages fixes message sequences that providers reject. The rules
+// are applied in this order:
+//
+//  1. Tool results are paired with the tool calls of the assistant message
+//     before them. Results with no matching call, or repeating an answered
+//     call, are dropped; calls with no result get an error result saying
+//     MissingToolResult. Provider-executed calls are not paired.
+//  2. Consecutive system, user or assistant messages are merged into one,
+//     keeping their content and tool calls in order. Tool messages are never
+//     merged.
+//  3. If the first message after the system messages is from the assistant,
+//     a user message saying ContinuePrompt is inserted before it.
+//
+// messages is not modified. Each change is reported as a warning; a valid
+// sequence is returned as-is with no warnings.
+func RepairMessages(messages []types.Message, opts RoleRepairOptions) ([]types.Message, []types.Warning) {
+	if opts.ContinuePrompt == "" {
+		opts.ContinuePrompt = "Continue."
+	}
+	if opts.MissingToolResult == "" {
+		opts.MissingToolResult = "tool result missing"
+	}
+
+	var warnings []types.Warning
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, types.Warning{Type: "other", Feature: "messages", Details: fmt.Sprintf(format, args...)})
+	}
+
+	paired := pairToolResults(messages, opts.MissingToolResult, warn)
+	merged := mergeSameRole(paired, warn)
+
+	first := 0
+	for first < len(merged) && merged[first].Role == types.RoleSystem {
+		first++
+	}
+	if first < len(merged) && merged[first].Role == types.RoleAssistant {
+		warn("inserted a user message before the leading assistant message")
+		continued := types.Message{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: opts.ContinuePrompt}}}
+		merged = append(merged[:first:first], append([]types.Message{continued}, merged[first:]...)...)
+	}
+
+	if len(warnings) == 0 {
+		return messages, nil
+	}
+	return merged, warnings
+}
+
+// pairToolResults applies rule 1 of RepairMessages
+func pairToolResults(messages []types.Message, missing string, warn func(string, ...interface{})) []types.Message {
+	out := make([]types.Message, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role == types.RoleTool {
+			// Tool messages not consumed below have no preceding call
+			if kept, ok := keepToolResults(msg, nil, warn); ok {
+				out = append(out, kept)
+			}
+			continue
+		}
+		out = append(out, msg)
+		if msg.Role != types.RoleAssistant {
+			continue
+		}
+
+		pending := map[string]bool{}
+		var calls []types.ToolCall
+		for _, call := range msg.ToolCalls {
+			if !call.ProviderExecuted && !hasToolResult(msg, call.ID) {
+				pending[call.ID] = true
+				calls = append(calls, call)
+			}
+		}
+		for i+1 < len(messages) && messages[i+1].Role == types.RoleTool {
+			i++
+			if kept, ok := keepToolResults(messages[i], pending, warn); ok {
+				out = append(out, kept)
+			}
+		}
+
+		var results []types.ContentPart
+		for _, call := range calls {
+			if pending[call.ID] {
+				warn("added an error result for tool call %s (%s), which had no result", call.ID, call.ToolName)
+				results = append(results, types.ErrorResult(call.ID, call.ToolName, missing))
+			}
+		}
+		if len(results) > 0 {
+			out = append(out, types.Message{Role: types.RoleTool, Content: results})
+		}
+	}
+	return out
+}
+
+// keepToolResults drops the tool results of msg that do not answer a pending
+// call, marking the rest answered. ok is false when nothing is left.
+func keepToolResults(msg types.Message, pending map[string]bool, warn func(string, ...interface{})) (types.Message, bool) {
+	content := make([]types.ContentPart, 0, len(msg.Content))
+	for _, part := range msg.Content {
+		result, isResult := part.(types.ToolResultContent)
+		if !isResult {
+			content = append(content, part)
+			continue
+		}
+		if !pending[result.ToolCallID] {
+			warn("dropped tool result %s (%s), which answers no open tool call", result.ToolCallID, result.ToolName)
+			continue
+		}
+		pending[result.ToolCallID] = false
+		content = append(content, part)
+	}
+	if len(content) == 0 {
+		if len(msg.Content) == 0 {
+			warn("dropped an empty tool message")
+		}
+		return msg, false
+	}
+	msg.Content = content
+	return msg, true
+}
+
+// hasToolResult reports whether msg carries the result of call id itself,
+// as provider-executed tools do
+func hasToolResult(msg types.Message, id string) bool {
+	for _, part := range msg.Content {
+		if result, ok := part.(types.ToolResultContent); ok && result.ToolCallID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSameRole applies rule 2 of RepairMessages
+func mergeSameRole(messages []types.Message, warn func(string, ...interface{})) []types.Message {
+	out := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		n := len(out)
+		if n == 0 || msg.Role == types.RoleTool || out[n-1].Role != msg.Role {
+			out = append(out, msg)
+			continue
+		}
+		warn("merged consecutive %s messages", msg.Role)
+		prev := &out[n-1]
+		prev.Content = append(append([]types.ContentPart{}, prev.Content...), msg.Content...)
+		if len(msg.ToolCalls) > 0 {
+			prev.ToolCalls = append(append([]types.ToolCall{}, prev.ToolCalls...), msg.ToolCalls...)
+		}
+	}
+	return out
+}
+
+// RoleRepairMiddleware returns middleware that runs RepairMessages on every
+// call's messages before they are sent. Generate results carry the repair
+// warnings alongside the provider's.
+//
+// Example:
+//
+//	repair := middleware.RoleRepairMiddleware(middleware.RoleRepairOptions{
+//		OnRepair: func(ctx context.Context, warnings []types.Warning) {
+//			log.Printf("repaired prompt: %v", warnings)
+//		},
+//	})
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{repair}, nil, nil)
+func RoleRepairMiddleware(opts RoleRepairOptions) *LanguageModelMiddleware {
+	repair := func(ctx context.Context, params *provider.GenerateOptions) (*provider.GenerateOptions, []types.Warning) {
+		messages, warnings := RepairMessages(params.Prompt.Messages, opts)
+		if len(warnings) == 0 {
+			return params, nil
+		}
+		if opts.OnRepair != nil {
+			opts.OnRepair(ctx, warnings)
+		}
+		repaired := *params
+		repaired.Prompt.Messages = messages
+		return &repaired, warnings
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			repaired, warnings := repair(ctx, params)
+			if len(warnings) == 0 {
+				return doGenerate()
+			}
+			result, err := model.DoGenerate(ctx, repaired)
+			if err != nil {
+				return nil, err
+			}
+			result.Warnings = append(warnings, result.Warnings...)
+			return result, nil
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			repaired, warnings := repair(ctx, params)
+			if len(warnings) == 0 {
+				return doStream()
+			}
+			return model.DoStream(ctx, repaired)
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func says(role types.MessageRole, text string) types.Message {
+	return types.Message{Role: role, Content: []types.ContentPart{types.TextContent{Text: text}}}
+}
+
+func TestRepairMessages_LeavesValidSequences(t *testing.T) {
+	t.Parallel()
+
+	messages := []types.Message{
+		says(types.RoleSystem, "Be brief."),
+		says(types.RoleUser, "Weather?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", ToolName: "weather"}}},
+		{Role: types.RoleTool, Content: []types.ContentPart{types.SimpleTextResult("c1", "weather", "sunny")}},
+		says(types.RoleAssistant, "Sunny."),
+	}
+	repaired, warnings := RepairMessages(messages, RoleRepairOptions{})
+	if warnings != nil || !reflect.DeepEqual(repaired, messages) {
+		t.Errorf("valid sequence changed: %v %v", repaired, warnings)
+	}
+}
+
+func TestRepairMessages_FixesRejectedSequences(t *testing.T) {
+	t.Parallel()
+
+	messages := []types.Message{
+		says(types.RoleSystem, "Be brief."),
+		says(types.RoleAssistant, "Hi!"),
+		says(types.RoleAssistant, "How can I help?"),
+		says(types.RoleUser, "Weather and time?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", ToolName: "weather"}, {ID: "c2", ToolName: "time"}}},
+		{Role: types.RoleTool, Content: []types.ContentPart{
+			types.SimpleTextResult("c1", "weather", "sunny"),
+			types.SimpleTextResult("c9", "stale", "old"),
+		}},
+		says(types.RoleUser, "Thanks."),
+		says(types.RoleUser, "Bye."),
+	}
+	original := append([]types.Message(nil), messages...)
+
+	repaired, warnings := RepairMessages(messages, RoleRepairOptions{ContinuePrompt: "(resume)"})
+	want := []types.Message{
+		says(types.RoleSystem, "Be brief."),
+		says(types.RoleUser, "(resume)"),
+		{Role: types.RoleAssistant, Content: []types.ContentPart{types.TextContent{Text: "Hi!"}, types.TextContent{Text: "How can I help?"}}},
+		says(types.RoleUser, "Weather and time?"),
+		messages[4],
+		{Role: types.RoleTool, Content: []types.ContentPart{types.SimpleTextResult("c1", "weather", "sunny")}},
+		{Role: types.RoleTool, Content: []types.ContentPart{types.ErrorResult("c2", "time", "tool result missing")}},
+		{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "Thanks."}, types.TextContent{Text: "Bye."}}},
+	}
+	if !reflect.DeepEqual(repaired, want) {
+		t.Errorf("got  %v\nwant %v", repaired, want)
+	}
+	if len(warnings) != 5 {
+		t.Errorf("expected 5 warnings, got %v", warnings)
+	}
+	if !reflect.DeepEqual(messages, original) {
+		t.Error("input messages were modified")
+	}
+}
+
+func TestRoleRepairMiddleware_SendsRepairedMessages(t *testing.T) {
+	t.Parallel()
+
+	var sent []types.Message
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			sent = opts.Prompt.Messages
+			return &types.GenerateResult{Text: "ok"}, nil
+		},
+	}
+	var reported []types.Warning
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{RoleRepairMiddleware(RoleRepairOptions{
+		OnRepair: func(ctx context.Context, warnings []types.Warning) { reported = warnings },
+	})}, nil, nil)
+
+	result, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Messages: []types.Message{says(types.RoleUser, "a"), says(types.RoleUser, "b")}},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	if len(sent) != 1 || len(sent[0].Content) != 2 {
+		t.Errorf("unexpected messages sent %v", sent)
+	}
+	if len(result.Warnings) != 1 || !reflect.DeepEqual(result.Warnings, reported) {
+		t.Errorf("warnings = %v, reported %v", result.Warnings, reported)
+	}
+}
@@ -0,0 +1,382 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Priority ranks scheduled calls; higher values are served first
+type Priority int
+
+const (
+	// PriorityBackground is for batch jobs, evals and other work that can
+	// wait or be dropped
+	PriorityBackground Priority = 0
+
+	// PriorityNormal is the default priority
+	PriorityNormal Priority = 1
+
+	// PriorityInteractive is for calls a user is waiting on
+	PriorityInteractive Priority = 2
+)
+
+// String returns the priority name
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PriorityNormal:
+		return "normal"
+	case PriorityInteractive:
+		return "interactive"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose calls are scheduled at p
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority set by WithPriority
+func PriorityFromContext(ctx context.Context) (Priority, bool) {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	return p, ok
+}
+
+// ErrLoadShed is matched by errors.Is for every *LoadShedError
+var ErrLoadShed = errors.New("request shed by scheduler")
+
+// LoadShedError is returned when the scheduler drops a call under pressure
+type LoadShedError struct {
+	Priority Priority
+
+	// Reason is "queue-full" or "max-wait"
+	Reason string
+}
+
+// Error implements the error interface
+func (e *LoadShedError) Error() string {
+	return fmt.Sprintf("%s request shed by scheduler: %s", e.Priority, e.Reason)
+}
+
+// Is reports whether target is ErrLoadShed
+func (e *LoadShedError) Is(target error) bool {
+	return target == ErrLoadShed
+}
+
+// SchedulerOptions configures a Scheduler
+type SchedulerOptions struct {
+	// RequestsPerSecond is the provider budget the bucket refills at.
+	// Zero admits every call immediately.
+	RequestsPerSecond float64
+
+	// Burst is the bucket capacity (default: 1)
+	Burst int
+
+	// Reserve is the fraction of the bucket kept back from each priority,
+	// e.g. {PriorityBackground: 0.5} runs background calls only while the
+	// bucket is at least half full, leaving headroom for interactive spikes.
+	// Reserves should not increase with priority.
+	Reserve map[Priority]float64
+
+	// MaxQueue sheds calls of a priority when that many are already waiting.
+	// Missing or zero entries do not limit the queue.
+	MaxQueue map[Priority]int
+
+	// MaxWait sheds calls of a priority that have waited this long.
+	// Missing or zero entries wait until the call's context ends.
+	MaxWait map[Priority]time.Duration
+
+	// DefaultPriority is used for calls without WithPriority
+	// (default: PriorityNormal)
+	DefaultPriority *Priority
+
+	// Clock drives refills and waits (default: system clock)
+	Clock clock.Clock
+}
+
+// SchedulerStats reports admissions and sheds per priority
+type SchedulerStats struct {
+	Admitted map[Priority]int64
+	Shed     map[Priority]int64
+	Queued   map[Priority]int
+}
+
+// Scheduler shares a token-bucket rate budget between priorities. The
+// highest-priority waiter is always served first; lower priorities are held
+// back by their reserve and queued or shed when the budget runs short.
+// When a provider returns a rate limit error the bucket is drained and
+// admissions pause for the provider's retry-after.
+type Scheduler struct {
+	opts     SchedulerOptions
+	clock    clock.Clock
+	burst    float64
+	fallback Priority
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	waiters     []*schedWaiter
+	timer       clock.Timer
+	timerAt     time.Time
+	timerStop   chan struct{}
+	admitted    map[Priority]int64
+	shed        map[Priority]int64
+}
+
+type schedWaiter struct {
+	priority Priority
+	ready    chan struct{}
+	granted  bool
+}
+
+// NewScheduler creates a scheduler with a full bucket
+func NewScheduler(opts SchedulerOptions) *Scheduler {
+	s := &Scheduler{
+		opts:     opts,
+		clock:    clock.Default(opts.Clock),
+		burst:    float64(max(opts.Burst, 1)),
+		fallback: PriorityNormal,
+		admitted: make(map[Priority]int64),
+		shed:     make(map[Priority]int64),
+	}
+	if opts.DefaultPriority != nil {
+		s.fallback = *opts.DefaultPriority
+	}
+	s.tokens = s.burst
+	s.last = s.clock.Now()
+	return s
+}
+
+// Acquire waits until a call at priority p may proceed. It returns a
+// *LoadShedError when the call is shed, or ctx.Err() if ctx ends first.
+func (s *Scheduler) Acquire(ctx context.Context, p Priority) error {
+	if s.opts.RequestsPerSecond <= 0 {
+		s.mu.Lock()
+		s.admitted[p]++
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.mu.Lock()
+	if limit := s.opts.MaxQueue[p]; limit > 0 && s.queued(p) >= limit {
+		s.shed[p]++
+		s.mu.Unlock()
+		return &LoadShedError{Priority: p, Reason: "queue-full"}
+	}
+	w := &schedWaiter{priority: p, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.pump()
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if d := s.opts.MaxWait[p]; d > 0 {
+		t := s.clock.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C()
+	}
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		if s.abandon(w) {
+			return nil
+		}
+		return ctx.Err()
+	case <-timeout:
+		if s.abandon(w) {
+			return nil
+		}
+		s.mu.Lock()
+		s.shed[p]++
+		s.mu.Unlock()
+		return &LoadShedError{Priority: p, Reason: "max-wait"}
+	}
+}
+
+// Penalize drains the bucket and pauses admissions for d, e.g. after the
+// provider reports a rate limit
+func (s *Scheduler) Penalize(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	s.refill(now)
+	s.tokens = 0
+	if until := now.Add(d); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+	s.pump()
+}
+
+// Stats returns a snapshot of admission counters and queue lengths
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := SchedulerStats{
+		Admitted: make(map[Priority]int64, len(s.admitted)),
+		Shed:     make(map[Priority]int64, len(s.shed)),
+		Queued:   make(map[Priority]int),
+	}
+	for p, n := range s.admitted {
+		stats.Admitted[p] = n
+	}
+	for p, n := range s.shed {
+		stats.Shed[p] = n
+	}
+	for _, w := range s.waiters {
+		stats.Queued[w.priority]++
+	}
+	return stats
+}
+
+// abandon removes w from the queue, reporting whether it had already been
+// granted (in which case the caller owns the admission)
+func (s *Scheduler) abandon(w *schedWaiter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.granted {
+		return true
+	}
+	for i, x := range s.waiters {
+		if x == w {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			break
+		}
+	}
+	// The departing waiter may have been blocking lower priorities
+	s.pump()
+	return false
+}
+
+func (s *Scheduler) queued(p Priority) int {
+	n := 0
+	for _, w := range s.waiters {
+		if w.priority == p {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Scheduler) refill(now time.Time) {
+	if elapsed := now.Sub(s.last); elapsed > 0 {
+		s.tokens = min(s.burst, s.tokens+elapsed.Seconds()*s.opts.RequestsPerSecond)
+	}
+	s.last = now
+}
+
+// pump admits waiters in priority order while the budget allows and arms a
+// timer for the next admission. Must be called with s.mu held.
+func (s *Scheduler) pump() {
+	now := s.clock.Now()
+	s.refill(now)
+	for len(s.waiters) > 0 {
+		if now.Before(s.pausedUntil) {
+			s.wakeAt(s.pausedUntil)
+			return
+		}
+		best := 0
+		for i, w := range s.waiters {
+			if w.priority > s.waiters[best].priority {
+				best = i
+			}
+		}
+		w := s.waiters[best]
+		need := 1 + s.opts.Reserve[w.priority]*s.burst
+		need = min(need, s.burst)
+		if s.tokens < need {
+			wait := time.Duration((need - s.tokens) / s.opts.RequestsPerSecond * float64(time.Second))
+			s.wakeAt(now.Add(max(wait, time.Millisecond)))
+			return
+		}
+		s.tokens--
+		s.waiters = append(s.waiters[:best], s.waiters[best+1:]...)
+		w.granted = true
+		s.admitted[w.priority]++
+		close(w.ready)
+	}
+}
+
+// wakeAt arms the pump timer for at, replacing a later one. Must be called
+// with s.mu held.
+func (s *Scheduler) wakeAt(at time.Time) {
+	if s.timer != nil && !s.timerAt.After(at) {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		close(s.timerStop)
+	}
+	t := s.clock.NewTimer(at.Sub(s.clock.Now()))
+	stop := make(chan struct{})
+	s.timer, s.timerAt, s.timerStop = t, at, stop
+	go func() {
+		select {
+		case <-t.C():
+			s.mu.Lock()
+			if s.timer == t {
+				s.timer = nil
+			}
+			s.pump()
+			s.mu.Unlock()
+		case <-stop:
+		}
+	}()
+}
+
+// LanguageModelMiddleware schedules generate and stream calls using the
+// priority from the call's context (see WithPriority). Provider rate limit
+// errors penalize the scheduler for their retry-after (one refill interval
+// when the provider gives none).
+func (s *Scheduler) LanguageModelMiddleware() *LanguageModelMiddleware {
+	acquire := func(ctx context.Context) error {
+		p, ok := PriorityFromContext(ctx)
+		if !ok {
+			p = s.fallback
+		}
+		return s.Acquire(ctx, p)
+	}
+	observe := func(err error) {
+		var rl *providererrors.RateLimitError
+		if !errors.As(err, &rl) || s.opts.RequestsPerSecond <= 0 {
+			return
+		}
+		d := time.Duration(float64(time.Second) / s.opts.RequestsPerSecond)
+		if rl.RetryAfterSeconds != nil {
+			d = time.Duration(*rl.RetryAfterSeconds) * time.Second
+		}
+		s.Penalize(d)
+	}
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		WrapGenerate: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (*types.GenerateResult, error) {
+			if err := acquire(ctx); err != nil {
+				return nil, err
+			}
+			result, err := doGenerate()
+			observe(err)
+			return result, err
+		},
+		WrapStream: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (provider.TextStream, error) {
+			if err := acquire(ctx); err != nil {
+				return nil, err
+			}
+			stream, err := doStream()
+			observe(err)
+			return stream, err
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// acquireAsync starts Acquire in a goroutine and waits until it is queued
+func acquireAsync(t *testing.T, s *Scheduler, p Priority) <-chan error {
+	t.Helper()
+	before := s.Stats().Queued[p]
+	done := make(chan error, 1)
+	go func() { done <- s.Acquire(context.Background(), p) }()
+	deadline := time.Now().Add(time.Second)
+	for s.Stats().Queued[p] == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s call was not queued", p)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return done
+}
+
+func receive(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for admission")
+		return nil
+	}
+}
+
+func TestScheduler_ServesHighestPriorityFirst(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	s := NewScheduler(SchedulerOptions{RequestsPerSecond: 1, Clock: fake})
+	if err := s.Acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatalf("expected the first call to use the full bucket: %v", err)
+	}
+
+	background := acquireAsync(t, s, PriorityBackground)
+	interactive := acquireAsync(t, s, PriorityInteractive)
+
+	fake.Advance(time.Second)
+	if err := receive(t, interactive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Stats().Queued[PriorityBackground] != 1 {
+		t.Fatal("background call should still be waiting")
+	}
+
+	fake.Advance(time.Second)
+	if err := receive(t, background); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScheduler_ReserveHoldsBackLowPriority(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	s := NewScheduler(SchedulerOptions{
+		RequestsPerSecond: 1,
+		Burst:             4,
+		Reserve:           map[Priority]float64{PriorityBackground: 0.5},
+		Clock:             fake,
+	})
+
+	ctx := context.Background()
+	_ = s.Acquire(ctx, PriorityBackground)
+	_ = s.Acquire(ctx, PriorityBackground)
+	background := acquireAsync(t, s, PriorityBackground)
+
+	// The reserved half of the bucket is still available to interactive calls
+	for i := 0; i < 2; i++ {
+		if err := s.Acquire(ctx, PriorityInteractive); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	fake.Advance(3 * time.Second)
+	if err := receive(t, background); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats := s.Stats()
+	if stats.Admitted[PriorityBackground] != 3 || stats.Admitted[PriorityInteractive] != 2 {
+		t.Errorf("unexpected admissions %+v", stats.Admitted)
+	}
+}
+
+func TestScheduler_Shedding(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	s := NewScheduler(SchedulerOptions{
+		RequestsPerSecond: 0.1,
+		MaxQueue:          map[Priority]int{PriorityBackground: 1},
+		MaxWait:           map[Priority]time.Duration{PriorityBackground: 5 * time.Second},
+		Clock:             fake,
+	})
+	_ = s.Acquire(context.Background(), PriorityInteractive)
+
+	waiting := acquireAsync(t, s, PriorityBackground)
+	err := s.Acquire(context.Background(), PriorityBackground)
+	var shed *LoadShedError
+	if !errors.As(err, &shed) || shed.Reason != "queue-full" {
+		t.Fatalf("expected queue-full shed, got %v", err)
+	}
+
+	fake.Advance(5 * time.Second)
+	if err := receive(t, waiting); !errors.Is(err, ErrLoadShed) {
+		t.Fatalf("expected max-wait shed, got %v", err)
+	}
+	if s.Stats().Shed[PriorityBackground] != 2 {
+		t.Errorf("expected 2 shed calls, got %+v", s.Stats().Shed)
+	}
+}
+
+func TestScheduler_MiddlewarePenalizesRateLimits(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	s := NewScheduler(SchedulerOptions{RequestsPerSecond: 10, Burst: 10, Clock: fake})
+	retryAfter := 30
+	calls := 0
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			calls++
+			if calls == 1 {
+				return nil, providererrors.NewRateLimitError("mock", "slow down", &retryAfter, nil)
+			}
+			return &types.GenerateResult{Text: "ok"}, nil
+		},
+	}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{s.LanguageModelMiddleware()}, nil, nil)
+
+	ctx := WithPriority(context.Background(), PriorityInteractive)
+	if _, err := wrapped.DoGenerate(ctx, &provider.GenerateOptions{}); !providererrors.IsRateLimitError(err) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := wrapped.DoGenerate(ctx, &provider.GenerateOptions{})
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("call should wait for the retry-after pause")
+	default:
+	}
+	fake.Advance(30 * time.Second)
+	if err := receive(t, done); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
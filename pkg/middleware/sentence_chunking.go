@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/sentence"
+)
+
+// SentenceChunkingMiddleware returns middleware that re-chunks streamed text
+// so each text chunk is one sentence, for TTS consumers reading the model
+// stream directly. Generate calls are unchanged.
+//
+// Example:
+//
+//	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+//		SentenceChunkingMiddleware(sentence.Options{MaxLatency: time.Second}),
+//	}, nil, nil)
+func SentenceChunkingMiddleware(opts sentence.Options) *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return sentence.NewTextStream(stream, opts), nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/sentence"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestSentenceChunkingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	model := WrapLanguageModel(&testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "Hi Dr. Smith. How"},
+				{Type: provider.ChunkTypeText, Text: " are you? Fine"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}, []*LanguageModelMiddleware{SentenceChunkingMiddleware(sentence.Options{})}, nil, nil)
+
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var texts []string
+	finished := false
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			texts = append(texts, chunk.Text)
+		case provider.ChunkTypeFinish:
+			finished = true
+		}
+	}
+
+	want := []string{"Hi Dr. Smith. ", "How are you? ", "Fine"}
+	if len(texts) != len(want) {
+		t.Fatalf("got chunks %q, want %q", texts, want)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Errorf("chunk %d: got %q, want %q", i, texts[i], want[i])
+		}
+	}
+	if !finished {
+		t.Error("expected the finish chunk to be passed through")
+	}
+}
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ErrShuttingDown is returned for generations started after Drainer.Shutdown
+// has been called.
+var ErrShuttingDown = errors.New("shutting down: not accepting new generations")
+
+// Drainer coordinates graceful shutdown of AI workloads. It tracks in-flight
+// generate, stream and embedding calls (and HTTP requests, via Handler), stops
+// accepting new work once Shutdown is called, waits for in-flight work to
+// finish, and cancels whatever is still running when the shutdown deadline
+// expires. Registered shutdown hooks run afterwards to flush telemetry
+// exporters, caches and similar resources.
+//
+// The provider returned by WrapProvider and the handler returned by Handler
+// both expose Shutdown(ctx), so servers can shut down through
+// provider.Shutdown or the handler they already hold; every entry point
+// shares the drainer's state.
+//
+// Example:
+//
+//	drainer := middleware.NewDrainer()
+//	drainer.OnShutdown(tracerProvider.Shutdown)
+//	p := drainer.WrapProvider(openai.New(openai.Config{APIKey: key}))
+//	http.Handle("/chat", drainer.Handler(chatHandler))
+//
+//	<-sigterm
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	_ = provider.Shutdown(ctx, p)
+type Drainer struct {
+	mu       sync.Mutex
+	closing  bool
+	nextID   uint64
+	inFlight map[uint64]context.CancelFunc
+	hooks    []func(ctx context.Context) error
+
+	// drained is closed once shutdown has started and no work is in flight
+	drained chan struct{}
+}
+
+// cancelGracePeriod is how long Shutdown waits for cancelled work to unwind
+// after its deadline. Work still running after that, e.g. a stream its
+// caller never reads or closes, is abandoned.
+const cancelGracePeriod = time.Second
+
+// NewDrainer creates a Drainer that is accepting work
+func NewDrainer() *Drainer {
+	return &Drainer{inFlight: make(map[uint64]context.CancelFunc)}
+}
+
+// OnShutdown registers a hook that runs after in-flight work has drained
+// (or been cancelled). Hooks run in registration order and receive the
+// Shutdown context, so they share its deadline.
+func (d *Drainer) OnShutdown(hook func(ctx context.Context) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hooks = append(d.hooks, hook)
+}
+
+// InFlight returns the number of tracked operations currently running
+func (d *Drainer) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.inFlight)
+}
+
+// IsShuttingDown reports whether Shutdown has been called
+func (d *Drainer) IsShuttingDown() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closing
+}
+
+// Shutdown stops accepting new work and waits for in-flight operations to
+// complete. If ctx expires first, remaining operations are cancelled and
+// Shutdown waits briefly for them to unwind before running the shutdown
+// hooks; operations that ignore cancellation are abandoned rather than
+// blocking shutdown. The returned error joins ctx.Err() (when the deadline
+// was hit) with any hook errors. Calling Shutdown more than once is safe;
+// later calls only wait for draining and do not re-run hooks.
+func (d *Drainer) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	first := !d.closing
+	if first {
+		d.closing = true
+		d.drained = make(chan struct{})
+		if len(d.inFlight) == 0 {
+			close(d.drained)
+		}
+	}
+	drained := d.drained
+	hooks := d.hooks
+	d.mu.Unlock()
+
+	var errs []error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+		d.mu.Lock()
+		for _, cancel := range d.inFlight {
+			cancel()
+		}
+		d.mu.Unlock()
+
+		grace := time.NewTimer(cancelGracePeriod)
+		select {
+		case <-drained:
+		case <-grace.C:
+		}
+		grace.Stop()
+	}
+
+	if first {
+		for _, hook := range hooks {
+			if err := hook(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// acquire registers a new in-flight operation. It returns a derived context
+// that is cancelled if the shutdown deadline expires, and a release function
+// that must be called exactly once when the operation ends.
+func (d *Drainer) acquire(ctx context.Context) (context.Context, func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closing {
+		return nil, nil, ErrShuttingDown
+	}
+
+	opCtx, cancel := context.WithCancel(ctx)
+	id := d.nextID
+	d.nextID++
+	d.inFlight[id] = cancel
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			cancel()
+			d.mu.Lock()
+			delete(d.inFlight, id)
+			if d.closing && len(d.inFlight) == 0 {
+				close(d.drained)
+			}
+			d.mu.Unlock()
+		})
+	}
+	return opCtx, release, nil
+}
+
+// LanguageModelMiddleware returns middleware that tracks generate and stream
+// calls. A stream counts as in flight until it is closed or fully consumed.
+func (d *Drainer) LanguageModelMiddleware() *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			opCtx, release, err := d.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			// Call the model directly so the cancellable context reaches the provider
+			return model.DoGenerate(opCtx, params)
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			opCtx, release, err := d.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			stream, err := model.DoStream(opCtx, params)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			return &drainingStream{TextStream: stream, release: release}, nil
+		},
+	}
+}
+
+// EmbeddingModelMiddleware returns middleware that tracks embedding calls.
+// Embedding calls are waited for but not cancelled on deadline, since the
+// middleware cannot replace the context used by the wrapped call.
+func (d *Drainer) EmbeddingModelMiddleware() *EmbeddingModelMiddleware {
+	return &EmbeddingModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapEmbed: func(ctx context.Context, doEmbed func() (*types.EmbeddingResult, error), input string, model provider.EmbeddingModel) (*types.EmbeddingResult, error) {
+			_, release, err := d.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return doEmbed()
+		},
+
+		WrapEmbedMany: func(ctx context.Context, doEmbedMany func() (*types.EmbeddingsResult, error), inputs []string, model provider.EmbeddingModel) (*types.EmbeddingsResult, error) {
+			_, release, err := d.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return doEmbedMany()
+		},
+	}
+}
+
+// WrapProvider applies the drainer's language and embedding model middleware
+// to every model returned by p. The returned provider implements
+// provider.Shutdowner: Shutdown drains the drainer, then shuts p down.
+func (d *Drainer) WrapProvider(p provider.Provider) provider.Provider {
+	return &drainingProvider{
+		Provider: WrapProvider(p,
+			[]*LanguageModelMiddleware{d.LanguageModelMiddleware()},
+			[]*EmbeddingModelMiddleware{d.EmbeddingModelMiddleware()},
+		),
+		drainer: d,
+	}
+}
+
+// drainingProvider adds Shutdown to a provider wrapped by a Drainer
+type drainingProvider struct {
+	provider.Provider
+	drainer *Drainer
+}
+
+// Ping forwards health checks to the wrapped provider
+func (p *drainingProvider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return provider.Ping(ctx, p.Provider)
+}
+
+// ListModels forwards model listing to the wrapped provider
+func (p *drainingProvider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return provider.ListModels(ctx, p.Provider)
+}
+
+// Shutdown drains in-flight work, then shuts down the wrapped provider
+func (p *drainingProvider) Shutdown(ctx context.Context) error {
+	return errors.Join(p.drainer.Shutdown(ctx), provider.Shutdown(ctx, p.Provider))
+}
+
+// DrainingHandler is an HTTP handler whose requests are tracked by a Drainer
+type DrainingHandler struct {
+	next    http.Handler
+	drainer *Drainer
+}
+
+// Handler wraps an HTTP handler so that each request is tracked as in-flight
+// work. Requests arriving after Shutdown receive 503 Service Unavailable, and
+// requests still running at the shutdown deadline see their context cancelled.
+func (d *Drainer) Handler(next http.Handler) *DrainingHandler {
+	return &DrainingHandler{next: next, drainer: d}
+}
+
+// ServeHTTP serves the request unless the drainer is shutting down
+func (h *DrainingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, release, err := h.drainer.acquire(r.Context())
+	if err != nil {
+		w.Header().Set("Connection", "close")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Shutdown shuts down the handler's drainer; see Drainer.Shutdown
+func (h *DrainingHandler) Shutdown(ctx context.Context) error {
+	return h.drainer.Shutdown(ctx)
+}
+
+// drainingStream releases its in-flight slot when the stream ends or is closed
+type drainingStream struct {
+	provider.TextStream
+	release func()
+}
+
+// Next returns the next chunk, releasing the slot once the stream is exhausted
+func (s *drainingStream) Next() (*provider.StreamChunk, error) {
+	chunk, err := s.TextStream.Next()
+	if err != nil {
+		s.release()
+	}
+	return chunk, err
+}
+
+// Close closes the underlying stream and releases the slot
+func (s *drainingStream) Close() error {
+	defer s.release()
+	return s.TextStream.Close()
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestDrainer_RejectsAfterShutdown(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	wrapped := WrapLanguageModel(&testutil.MockLanguageModel{}, []*LanguageModelMiddleware{d.LanguageModelMiddleware()}, nil, nil)
+
+	if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{}); err != nil {
+		t.Fatalf("unexpected error before shutdown: %v", err)
+	}
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+	if _, err := wrapped.DoStream(context.Background(), &provider.GenerateOptions{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown for stream, got %v", err)
+	}
+}
+
+func TestDrainer_WaitsForInFlightStream(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	wrapped := WrapLanguageModel(&testutil.MockLanguageModel{}, []*LanguageModelMiddleware{d.LanguageModelMiddleware()}, nil, nil)
+
+	stream, err := wrapped.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.InFlight() != 1 {
+		t.Fatalf("expected 1 in-flight stream, got %d", d.InFlight())
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- d.Shutdown(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("shutdown returned before the stream finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	for {
+		if _, err := stream.Next(); err == io.EOF {
+			break
+		}
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected shutdown error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not return after stream finished")
+	}
+}
+
+func TestDrainer_CancelsAfterDeadline(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	started := make(chan struct{})
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{d.LanguageModelMiddleware()}, nil, nil)
+
+	genErr := make(chan error, 1)
+	go func() {
+		_, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{})
+		genErr <- err
+	}()
+	<-started
+
+	var hookRan bool
+	d.OnShutdown(func(ctx context.Context) error {
+		hookRan = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if !errors.Is(<-genErr, context.Canceled) {
+		t.Error("expected in-flight generation to be cancelled")
+	}
+	if !hookRan {
+		t.Error("expected shutdown hook to run")
+	}
+	if d.InFlight() != 0 {
+		t.Errorf("expected no in-flight work, got %d", d.InFlight())
+	}
+}
+
+func TestDrainer_Handler(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	handler := d.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/chat", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 before shutdown, got %d", rec.Code)
+	}
+
+	_ = d.Shutdown(context.Background())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/chat", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after shutdown, got %d", rec.Code)
+	}
+}
+
+func TestDrainer_AbandonedStreamDoesNotBlockShutdown(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	wrapped := WrapLanguageModel(&testutil.MockLanguageModel{}, []*LanguageModelMiddleware{d.LanguageModelMiddleware()}, nil, nil)
+
+	// The stream is never read or closed
+	if _, err := wrapped.DoStream(context.Background(), &provider.GenerateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.Shutdown(ctx) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(cancelGracePeriod + time.Second):
+		t.Fatal("shutdown blocked on an abandoned stream")
+	}
+}
+
+func TestDrainer_ShutdownThroughProviderAndHandler(t *testing.T) {
+	t.Parallel()
+
+	d := NewDrainer()
+	p := d.WrapProvider(&testutil.MockProvider{ProviderName: "mock"})
+	if err := provider.Shutdown(context.Background(), p); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if !d.IsShuttingDown() {
+		t.Error("expected provider.Shutdown to shut the drainer down")
+	}
+	model, err := p.LanguageModel("gpt-4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+
+	handlerDrainer := NewDrainer()
+	handler := handlerDrainer.Handler(http.NotFoundHandler())
+	if err := handler.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if !handlerDrainer.IsShuttingDown() {
+		t.Error("expected handler Shutdown to shut the drainer down")
+	}
+}
@@ -3,11 +3,51 @@ package middleware
 import (
 	"context"
 	"io"
+	"time"
+	"unicode"
 
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/sentence"
 )
 
+// StreamChunking controls how simulated streams split text
+type StreamChunking string
+
+const (
+	// ChunkWhole emits all text in a single chunk (default)
+	ChunkWhole StreamChunking = ""
+
+	// ChunkByWord emits one word (with its trailing whitespace) per chunk
+	ChunkByWord StreamChunking = "word"
+
+	// ChunkBySentence emits one sentence per chunk
+	ChunkBySentence StreamChunking = "sentence"
+
+	// ChunkByLine emits one line per chunk
+	ChunkByLine StreamChunking = "line"
+)
+
+// SimulateStreamingOptions configures SimulateStreamingMiddlewareWithOptions
+type SimulateStreamingOptions struct {
+	// Chunking splits text and reasoning into chunks. Concatenating the
+	// chunks always reproduces the original text.
+	Chunking StreamChunking
+
+	// Delay paces the stream by pausing before each text or reasoning chunk
+	// after the first (0 = no pacing)
+	Delay time.Duration
+
+	// Clock measures Delay (default: the system clock)
+	Clock clock.Clock
+
+	// Fallback tries the model's real stream first and only simulates
+	// streaming when DoStream fails, e.g. for providers or modes that
+	// return complete responses only
+	Fallback bool
+}
+
 // SimulateStreamingMiddleware returns middleware that converts non-streaming
 // generate responses into simulated streams.
 //
@@ -22,6 +62,21 @@ import (
 //	// Now stream calls will use generate internally and simulate streaming
 //	stream, err := wrapped.DoStream(ctx, opts)
 func SimulateStreamingMiddleware() *LanguageModelMiddleware {
+	return SimulateStreamingMiddlewareWithOptions(SimulateStreamingOptions{})
+}
+
+// SimulateStreamingMiddlewareWithOptions returns middleware that turns
+// complete responses into paced streams, so UIs get a uniform streaming
+// contract regardless of the provider.
+//
+// Example:
+//
+//	middleware := SimulateStreamingMiddlewareWithOptions(SimulateStreamingOptions{
+//		Chunking: ChunkByWord,
+//		Delay:    20 * time.Millisecond,
+//		Fallback: true,
+//	})
+func SimulateStreamingMiddlewareWithOptions(opts SimulateStreamingOptions) *LanguageModelMiddleware {
 	return &LanguageModelMiddleware{
 		SpecificationVersion: "v3",
 
@@ -33,6 +88,12 @@ func SimulateStreamingMiddleware() *LanguageModelMiddleware {
 			params *provider.GenerateOptions,
 			model provider.LanguageModel,
 		) (provider.TextStream, error) {
+			if opts.Fallback {
+				if stream, err := doStream(); err == nil {
+					return stream, nil
+				}
+			}
+
 			// Call generate instead of stream
 			result, err := doGenerate()
 			if err != nil {
@@ -41,6 +102,8 @@ func SimulateStreamingMiddleware() *LanguageModelMiddleware {
 
 			// Create a simulated stream from the result
 			return &simulatedStream{
+				ctx:     ctx,
+				opts:    opts,
 				result:  result,
 				chunks:  nil, // Will be built lazily
 				current: 0,
@@ -51,10 +114,14 @@ func SimulateStreamingMiddleware() *LanguageModelMiddleware {
 
 // simulatedStream simulates a streaming response from a GenerateResult
 type simulatedStream struct {
+	ctx     context.Context
+	opts    SimulateStreamingOptions
 	result  *types.GenerateResult
 	chunks  []*provider.StreamChunk
 	current int
+	paced   int
 	closed  bool
+	err     error
 }
 
 // buildChunks creates the sequence of chunks that simulate streaming
@@ -65,11 +132,25 @@ func (s *simulatedStream) buildChunks() {
 
 	s.chunks = []*provider.StreamChunk{}
 
-	// Emit text content as a single text chunk
-	if len(s.result.Text) > 0 {
+	// Emit reasoning before the answer, as streaming providers do
+	for _, part := range s.result.Content {
+		reasoning, ok := part.(types.ReasoningContent)
+		if !ok {
+			continue
+		}
+		for _, piece := range splitForStreaming(reasoning.Text, s.opts.Chunking) {
+			s.chunks = append(s.chunks, &provider.StreamChunk{
+				Type:      provider.ChunkTypeReasoning,
+				Reasoning: piece,
+			})
+		}
+	}
+
+	// Emit text content
+	for _, piece := range splitForStreaming(s.result.Text, s.opts.Chunking) {
 		s.chunks = append(s.chunks, &provider.StreamChunk{
 			Type: provider.ChunkTypeText,
-			Text: s.result.Text,
+			Text: piece,
 		})
 	}
 
@@ -100,6 +181,9 @@ func (s *simulatedStream) Next() (*provider.StreamChunk, error) {
 	if s.closed {
 		return nil, io.EOF
 	}
+	if s.err != nil {
+		return nil, s.err
+	}
 
 	// Build chunks on first access
 	if s.chunks == nil {
@@ -112,6 +196,15 @@ func (s *simulatedStream) Next() (*provider.StreamChunk, error) {
 	}
 
 	chunk := s.chunks[s.current]
+	if chunk.Type == provider.ChunkTypeText || chunk.Type == provider.ChunkTypeReasoning {
+		if s.paced > 0 && s.opts.Delay > 0 {
+			if err := clock.Sleep(s.ctx, s.opts.Clock, s.opts.Delay); err != nil {
+				s.err = err
+				return nil, err
+			}
+		}
+		s.paced++
+	}
 	s.current++
 	return chunk, nil
 }
@@ -129,7 +222,49 @@ func (s *simulatedStream) Close() error {
 	return nil
 }
 
-// Err returns any error from the stream (always nil for simulated streams)
+// Err returns the error that ended the stream, if pacing was interrupted by
+// context cancellation
 func (s *simulatedStream) Err() error {
-	return nil
+	return s.err
+}
+
+// splitForStreaming splits text into chunks that concatenate back to text
+func splitForStreaming(text string, mode StreamChunking) []string {
+	if text == "" {
+		return nil
+	}
+
+	var boundary func(runes []rune, i int) bool
+	switch mode {
+	case ChunkByWord:
+		// Break where whitespace is followed by a non-space character
+		boundary = func(runes []rune, i int) bool {
+			return unicode.IsSpace(runes[i]) && i+1 < len(runes) && !unicode.IsSpace(runes[i+1])
+		}
+	case ChunkBySentence:
+		// Segments keep their trailing whitespace; MaxLength is lifted so
+		// long sentences are not split
+		segmenter := sentence.NewSegmenter(sentence.Options{MaxLength: len(text) + 1})
+		return append(segmenter.Write(text), segmenter.Flush()...)
+	case ChunkByLine:
+		boundary = func(runes []rune, i int) bool {
+			return runes[i] == '\n' && i+1 < len(runes)
+		}
+	default:
+		return []string{text}
+	}
+
+	runes := []rune(text)
+	var chunks []string
+	start := 0
+	for i := range runes {
+		if boundary(runes, i) {
+			chunks = append(chunks, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		chunks = append(chunks, string(runes[start:]))
+	}
+	return chunks
 }
@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
@@ -229,3 +233,83 @@ func TestSimulateStreamingMiddleware_Close(t *testing.T) {
 		t.Errorf("expected EOF after close, got %v", err)
 	}
 }
+
+func TestSplitForStreaming(t *testing.T) {
+	text := "Hello there.  How are you?\nFine!"
+	cases := map[StreamChunking][]string{
+		ChunkWhole:      {text},
+		ChunkByWord:     {"Hello ", "there.  ", "How ", "are ", "you?\n", "Fine!"},
+		ChunkBySentence: {"Hello there.  ", "How are you?\n", "Fine!"},
+		ChunkByLine:     {"Hello there.  How are you?\n", "Fine!"},
+	}
+	for mode, want := range cases {
+		got := splitForStreaming(text, mode)
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("%q: got %q, want %q", mode, got, want)
+		}
+	}
+}
+
+func TestSimulateStreamingMiddlewareWithOptions_Pacing(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	mockModel := &mockLanguageModel{
+		generateResult: &types.GenerateResult{Text: "one two three", FinishReason: types.FinishReasonStop},
+	}
+	middleware := SimulateStreamingMiddlewareWithOptions(SimulateStreamingOptions{
+		Chunking: ChunkByWord,
+		Delay:    time.Second,
+		Clock:    fake,
+	})
+	wrapped := WrapLanguageModel(mockModel, []*LanguageModelMiddleware{middleware}, nil, nil)
+
+	stream, err := wrapped.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := make(chan []string, 1)
+	go func() {
+		var texts []string
+		for {
+			chunk, err := stream.Next()
+			if err != nil {
+				result <- texts
+				return
+			}
+			if chunk.Type == provider.ChunkTypeText {
+				texts = append(texts, chunk.Text)
+			}
+		}
+	}()
+
+	// The first word is immediate; each later word waits one delay
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+	}
+	texts := <-result
+	if strings.Join(texts, "|") != "one |two |three" {
+		t.Errorf("unexpected chunks: %q", texts)
+	}
+	if elapsed := fake.Now().Sub(time.Unix(0, 0)); elapsed != 2*time.Second {
+		t.Errorf("expected two paced delays, got %v", elapsed)
+	}
+}
+
+func TestSimulateStreamingMiddlewareWithOptions_Fallback(t *testing.T) {
+	mockModel := &mockLanguageModel{
+		generateResult: &types.GenerateResult{Text: "complete", FinishReason: types.FinishReasonStop},
+		streamError:    errors.New("streaming not supported"),
+	}
+	middleware := SimulateStreamingMiddlewareWithOptions(SimulateStreamingOptions{Fallback: true})
+	wrapped := WrapLanguageModel(mockModel, []*LanguageModelMiddleware{middleware}, nil, nil)
+
+	stream, err := wrapped.DoStream(context.Background(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("expected fallback to generate, got %v", err)
+	}
+	chunk, err := stream.Next()
+	if err != nil || chunk.Text != "complete" {
+		t.Errorf("unexpected first chunk: %+v, %v", chunk, err)
+	}
+}
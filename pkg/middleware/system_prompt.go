@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+// SystemPromptOptions configures the system prompt middleware
+type SystemPromptOptions struct {
+	// Prepend is placed before the call's system prompt (e.g. a persona)
+	Prepend string
+
+	// Append is placed after the call's system prompt (e.g. policies)
+	Append string
+
+	// Separator joins the parts
+	// Default: "\n\n"
+	Separator string
+}
+
+// SystemPromptMiddleware returns middleware that layers content around the
+// system prompt of every call. Several instances compose: each wraps the
+// prompt produced by the ones inside it.
+//
+// When a call has no Prompt.System but starts with a system message, that
+// message is extended instead.
+//
+// Example:
+//
+//	persona := SystemPromptMiddleware(SystemPromptOptions{Prepend: "You are Ada, Acme's support assistant."})
+//	policy := SystemPromptMiddleware(SystemPromptOptions{Append: "Never share internal URLs."})
+//	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{persona, policy}, nil, nil)
+func SystemPromptMiddleware(options SystemPromptOptions) *LanguageModelMiddleware {
+	if options.Separator == "" {
+		options.Separator = "\n\n"
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		TransformParams: func(ctx context.Context, callType string, params *provider.GenerateOptions, model provider.LanguageModel) (*provider.GenerateOptions, error) {
+			return withSystemPrompt(params, func(system string) string {
+				parts := make([]string, 0, 3)
+				for _, part := range []string{options.Prepend, system, options.Append} {
+					if part != "" {
+						parts = append(parts, part)
+					}
+				}
+				return strings.Join(parts, options.Separator)
+			}), nil
+		},
+	}
+}
+
+// ResponseFormatMiddleware returns middleware that applies format to every
+// call that does not set its own response format. For models without native
+// structured output support the format is also described in the system
+// prompt, so the model is still instructed to reply with matching JSON.
+//
+// Example:
+//
+//	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+//		ResponseFormatMiddleware(&provider.ResponseFormat{Type: "json", Schema: ticketSchema}),
+//	}, nil, nil)
+func ResponseFormatMiddleware(format *provider.ResponseFormat) *LanguageModelMiddleware {
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		TransformParams: func(ctx context.Context, callType string, params *provider.GenerateOptions, model provider.LanguageModel) (*provider.GenerateOptions, error) {
+			if format == nil || params.ResponseFormat != nil {
+				return params, nil
+			}
+			result := *params
+			result.ResponseFormat = format
+			if model != nil && model.SupportsStructuredOutput() {
+				return &result, nil
+			}
+			instructions := responseFormatInstructions(format)
+			if instructions == "" {
+				return &result, nil
+			}
+			return withSystemPrompt(&result, func(system string) string {
+				if system == "" {
+					return instructions
+				}
+				return system + "\n\n" + instructions
+			}), nil
+		},
+	}
+}
+
+// responseFormatInstructions describes format in words for the system prompt
+func responseFormatInstructions(format *provider.ResponseFormat) string {
+	if format.Type == "" || format.Type == "text" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Respond only with valid JSON, without markdown fences or commentary.")
+	if format.Description != "" {
+		b.WriteString(" The JSON should be: " + format.Description + ".")
+	}
+	var jsonSchema interface{} = format.Schema
+	if s, ok := format.Schema.(schema.Schema); ok {
+		jsonSchema = s.Validator().JSONSchema()
+	}
+	if jsonSchema != nil {
+		if data, err := json.Marshal(jsonSchema); err == nil {
+			b.WriteString(" It must match this JSON schema: " + string(data))
+		}
+	}
+	return b.String()
+}
+
+// withSystemPrompt returns a copy of params with its system prompt rewritten
+// by update. The leading system message is used when Prompt.System is empty.
+func withSystemPrompt(params *provider.GenerateOptions, update func(system string) string) *provider.GenerateOptions {
+	result := *params
+
+	msgs := params.Prompt.Messages
+	if params.Prompt.System == "" && len(msgs) > 0 && msgs[0].Role == types.RoleSystem {
+		var text strings.Builder
+		for _, part := range msgs[0].Content {
+			if t, ok := part.(types.TextContent); ok {
+				text.WriteString(t.Text)
+			}
+		}
+		first := msgs[0]
+		first.Content = []types.ContentPart{types.TextContent{Text: update(text.String())}}
+		result.Prompt.Messages = append([]types.Message{first}, msgs[1:]...)
+		return &result
+	}
+
+	result.Prompt.System = update(params.Prompt.System)
+	return &result
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestSystemPromptMiddleware_Layers(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	persona := SystemPromptMiddleware(SystemPromptOptions{Prepend: "You are Ada."})
+	policy := SystemPromptMiddleware(SystemPromptOptions{Append: "Be brief."})
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{persona, policy}, nil, nil)
+
+	params := &provider.GenerateOptions{Prompt: types.Prompt{System: "Answer billing questions."}}
+	if _, err := wrapped.DoGenerate(context.Background(), params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := model.GenerateCalls[0].Prompt.System
+	want := "You are Ada.\n\nAnswer billing questions.\n\nBe brief."
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if params.Prompt.System != "Answer billing questions." {
+		t.Errorf("caller params were mutated: %q", params.Prompt.System)
+	}
+}
+
+func TestSystemPromptMiddleware_SystemMessage(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{}
+	mw := SystemPromptMiddleware(SystemPromptOptions{Prepend: "Persona.", Separator: " "})
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{mw}, nil, nil)
+
+	_, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Messages: []types.Message{
+			{Role: types.RoleSystem, Content: []types.ContentPart{types.TextContent{Text: "Rules."}}},
+			{Role: types.RoleUser, Content: []types.ContentPart{types.TextContent{Text: "hi"}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := model.GenerateCalls[0]
+	if call.Prompt.System != "" {
+		t.Errorf("expected Prompt.System to stay empty, got %q", call.Prompt.System)
+	}
+	text := call.Prompt.Messages[0].Content[0].(types.TextContent).Text
+	if text != "Persona. Rules." {
+		t.Errorf("expected system message to be extended, got %q", text)
+	}
+	if len(call.Prompt.Messages) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(call.Prompt.Messages))
+	}
+}
+
+func TestResponseFormatMiddleware(t *testing.T) {
+	t.Parallel()
+
+	format := &provider.ResponseFormat{
+		Type:   "json",
+		Schema: map[string]interface{}{"type": "object"},
+	}
+
+	t.Run("native structured output", func(t *testing.T) {
+		t.Parallel()
+		model := &testutil.MockLanguageModel{StructuredSupport: true}
+		wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{ResponseFormatMiddleware(format)}, nil, nil)
+		if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		call := model.GenerateCalls[0]
+		if call.ResponseFormat != format {
+			t.Errorf("expected response format to be applied")
+		}
+		if call.Prompt.System != "" {
+			t.Errorf("expected no system instructions, got %q", call.Prompt.System)
+		}
+	})
+
+	t.Run("instructions for models without structured output", func(t *testing.T) {
+		t.Parallel()
+		model := &testutil.MockLanguageModel{}
+		wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{ResponseFormatMiddleware(format)}, nil, nil)
+		_, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{
+			Prompt: types.Prompt{System: "Be helpful."},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		system := model.GenerateCalls[0].Prompt.System
+		if !strings.HasPrefix(system, "Be helpful.\n\nRespond only with valid JSON") {
+			t.Errorf("unexpected system prompt %q", system)
+		}
+		if !strings.Contains(system, `{"type":"object"}`) {
+			t.Errorf("expected schema in system prompt, got %q", system)
+		}
+	})
+
+	t.Run("call format wins", func(t *testing.T) {
+		t.Parallel()
+		model := &testutil.MockLanguageModel{}
+		own := &provider.ResponseFormat{Type: "text"}
+		wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{ResponseFormatMiddleware(format)}, nil, nil)
+		if _, err := wrapped.DoGenerate(context.Background(), &provider.GenerateOptions{ResponseFormat: own}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if model.GenerateCalls[0].ResponseFormat != own {
+			t.Errorf("expected call response format to be kept")
+		}
+	})
+}
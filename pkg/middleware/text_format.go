@@ -0,0 +1,553 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	nethtml "golang.org/x/net/html"
+)
+
+// TextFormat is the markup a chat response may contain
+type TextFormat string
+
+const (
+	// TextFormatPlain is text without markup. Markdown syntax and HTML tags
+	// are removed; code block contents are kept.
+	TextFormatPlain TextFormat = "plain"
+
+	// TextFormatMarkdown is Markdown without raw HTML. Tags are removed,
+	// script and style contents dropped, and javascript: links disabled.
+	// Code spans and blocks are left alone.
+	TextFormatMarkdown TextFormat = "markdown"
+
+	// TextFormatHTML is an HTML fragment that is safe to insert into a page:
+	// only formatting tags survive, without event handlers or unsafe URLs,
+	// text is escaped and unclosed tags are closed.
+	TextFormatHTML TextFormat = "html-safe"
+)
+
+// TextFormatOptions configures TextFormatMiddleware
+type TextFormatOptions struct {
+	// Format is the markup to enforce (required)
+	Format TextFormat
+
+	// Instructions replace the format's default system prompt scaffolding
+	Instructions string
+
+	// NoInstructions skips the system prompt scaffolding, relying on
+	// sanitization alone
+	NoInstructions bool
+}
+
+// Default system prompt scaffolding for each format
+var textFormatInstructions = map[TextFormat]string{
+	TextFormatPlain:    "Respond in plain text only. Do not use Markdown, HTML or any other markup.",
+	TextFormatMarkdown: "Format your response with Markdown. Do not include raw HTML.",
+	TextFormatHTML: "Format your response as an HTML fragment using only these tags: " +
+		"p, br, hr, strong, em, b, i, u, s, code, pre, blockquote, ul, ol, li, h1-h6, a, table, thead, tbody, tr, th, td. " +
+		"Do not include scripts, styles, attributes other than href, or a full HTML document.",
+}
+
+// TextFormatMiddleware returns middleware that keeps response text in one
+// format, so chat servers need not sanitize model output themselves. The
+// format is requested in the system prompt, and text is sanitized as it
+// streams: Markdown and plain text line by line, HTML tag by tag. Reasoning
+// and tool calls are not changed.
+//
+// Example:
+//
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{
+//		middleware.TextFormatMiddleware(middleware.TextFormatOptions{Format: middleware.TextFormatHTML}),
+//	}, nil, nil)
+func TextFormatMiddleware(options TextFormatOptions) *LanguageModelMiddleware {
+	instructions := options.Instructions
+	if instructions == "" {
+		instructions = textFormatInstructions[options.Format]
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		TransformParams: func(ctx context.Context, callType string, params *provider.GenerateOptions, model provider.LanguageModel) (*provider.GenerateOptions, error) {
+			if _, ok := textFormatInstructions[options.Format]; !ok {
+				return nil, errors.New("text format must be plain, markdown or html-safe")
+			}
+			if options.NoInstructions || params.ResponseFormat != nil {
+				return params, nil
+			}
+			return withSystemPrompt(params, func(system string) string {
+				if system == "" {
+					return instructions
+				}
+				return system + "\n\n" + instructions
+			}), nil
+		},
+
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil || params.ResponseFormat != nil {
+				return result, err
+			}
+			result.Text = SanitizeText(options.Format, result.Text)
+			for i, part := range result.Content {
+				if text, ok := part.(types.TextContent); ok {
+					text.Text = SanitizeText(options.Format, text.Text)
+					result.Content[i] = text
+				}
+			}
+			return result, nil
+		},
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil || params.ResponseFormat != nil {
+				return stream, err
+			}
+			return &formattedStream{underlying: stream, format: options.Format, sanitizers: map[string]textSanitizer{}}, nil
+		},
+	}
+}
+
+// SanitizeText converts complete text to format; see TextFormatMiddleware
+func SanitizeText(format TextFormat, text string) string {
+	s := newTextSanitizer(format)
+	return s.write(text) + s.flush()
+}
+
+// textSanitizer converts streamed text, holding back what it cannot
+// convert yet
+type textSanitizer interface {
+	write(text string) string
+	flush() string
+}
+
+func newTextSanitizer(format TextFormat) textSanitizer {
+	switch format {
+	case TextFormatHTML:
+		return &htmlSanitizer{filter: htmlFilter{safe: true}}
+	case TextFormatMarkdown:
+		return &lineSanitizer{convert: (&markdownLines{}).convert}
+	default:
+		return &lineSanitizer{convert: (&plainLines{}).convert}
+	}
+}
+
+// formattedStream sanitizes text chunks. Text held back by a sanitizer is
+// released before any non-text chunk, so output order is preserved.
+type formattedStream struct {
+	underlying provider.TextStream
+	format     TextFormat
+	sanitizers map[string]textSanitizer
+	order      []string
+	queue      []*provider.StreamChunk
+	done       bool
+}
+
+// Next returns the next chunk with its text sanitized
+func (s *formattedStream) Next() (*provider.StreamChunk, error) {
+	for len(s.queue) == 0 {
+		if s.done {
+			return nil, io.EOF
+		}
+		chunk, err := s.underlying.Next()
+		if errors.Is(err, io.EOF) {
+			s.done = true
+			s.flush()
+			continue
+		}
+		if err != nil {
+			return chunk, err
+		}
+		if chunk.Type != provider.ChunkTypeText {
+			s.flush()
+			s.queue = append(s.queue, chunk)
+			continue
+		}
+		sanitizer, ok := s.sanitizers[chunk.ID]
+		if !ok {
+			sanitizer = newTextSanitizer(s.format)
+			s.sanitizers[chunk.ID] = sanitizer
+			s.order = append(s.order, chunk.ID)
+		}
+		if text := sanitizer.write(chunk.Text); text != "" {
+			out := *chunk
+			out.Text = text
+			s.queue = append(s.queue, &out)
+		}
+	}
+	chunk := s.queue[0]
+	s.queue = s.queue[1:]
+	return chunk, nil
+}
+
+// flush releases held text for every text block
+func (s *formattedStream) flush() {
+	for _, id := range s.order {
+		if text := s.sanitizers[id].flush(); text != "" {
+			s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, ID: id, Text: text})
+		}
+	}
+}
+
+// Err returns the underlying stream's error
+func (s *formattedStream) Err() error {
+	return s.underlying.Err()
+}
+
+// Close closes the underlying stream
+func (s *formattedStream) Close() error {
+	return s.underlying.Close()
+}
+
+// lineSanitizer converts text a line at a time
+type lineSanitizer struct {
+	partial string
+	convert func(line string, final bool) string
+}
+
+func (l *lineSanitizer) write(text string) string {
+	l.partial += text
+	end := strings.LastIndexByte(l.partial, '\n')
+	if end < 0 {
+		return ""
+	}
+	lines := l.partial[:end+1]
+	l.partial = l.partial[end+1:]
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(lines, "\n") {
+		if line != "" {
+			out.WriteString(l.convert(line, false))
+		}
+	}
+	return out.String()
+}
+
+func (l *lineSanitizer) flush() string {
+	line := l.partial
+	l.partial = ""
+	return l.convert(line, true)
+}
+
+// isFence reports whether line opens or closes a fenced code block
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+var (
+	codeSpanPattern   = regexp.MustCompile("`+[^`\n]*`+")
+	unsafeLinkPattern = regexp.MustCompile(`(?i)\]\(\s*(?:javascript|vbscript|data):[^()]*(?:\([^()]*\)[^()]*)*\)`)
+)
+
+// markdownLines removes raw HTML from Markdown outside code
+type markdownLines struct {
+	inFence bool
+	filter  htmlFilter
+}
+
+func (m *markdownLines) convert(line string, final bool) string {
+	if isFence(line) && m.filter.idle() {
+		m.inFence = !m.inFence
+		return line
+	}
+	if m.inFence {
+		return line
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, span := range codeSpanPattern.FindAllStringIndex(line, -1) {
+		out.WriteString(m.filter.process(line[last:span[0]], false))
+		if m.filter.idle() {
+			out.WriteString(line[span[0]:span[1]])
+		} else {
+			out.WriteString(m.filter.process(line[span[0]:span[1]], false))
+		}
+		last = span[1]
+	}
+	out.WriteString(m.filter.process(line[last:], final))
+	return unsafeLinkPattern.ReplaceAllString(out.String(), "](#)")
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	quotePattern    = regexp.MustCompile(`^\s{0,3}(?:>\s?)+`)
+	bulletPattern   = regexp.MustCompile(`^(\s*)[*+]\s+`)
+	rulePattern     = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(\s*([^)\s]+)[^)]*\)`)
+	boldPattern     = regexp.MustCompile(`\*\*(\S(?:[^*]*?\S)?)\*\*|__(\S(?:[^_]*?\S)?)__`)
+	italicPattern   = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	underscorePat   = regexp.MustCompile(`(^|[^\w])_(\S(?:[^_]*?\S)?)_([^\w]|$)`)
+	strikePattern   = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	inlineCodeRegex = regexp.MustCompile("`+([^`\n]*)`+")
+)
+
+// plainLines removes Markdown and HTML markup
+type plainLines struct {
+	inFence bool
+	filter  htmlFilter
+}
+
+func (p *plainLines) convert(line string, final bool) string {
+	if isFence(line) && p.filter.idle() {
+		p.inFence = !p.inFence
+		return ""
+	}
+	if p.inFence {
+		return line
+	}
+
+	text := p.filter.process(line, final)
+	body, newline := strings.CutSuffix(text, "\n")
+	if rulePattern.MatchString(body) {
+		return ""
+	}
+	body = headingPattern.ReplaceAllString(body, "")
+	body = quotePattern.ReplaceAllString(body, "")
+	body = bulletPattern.ReplaceAllString(body, "$1- ")
+	body = imagePattern.ReplaceAllString(body, "$1")
+	body = linkPattern.ReplaceAllString(body, "$1 ($2)")
+	body = inlineCodeRegex.ReplaceAllString(body, "$1")
+	body = boldPattern.ReplaceAllString(body, "$1$2")
+	body = italicPattern.ReplaceAllString(body, "$1")
+	body = underscorePat.ReplaceAllString(body, "$1$2$3")
+	body = strikePattern.ReplaceAllString(body, "$1")
+	body = html.UnescapeString(body)
+	if newline {
+		body += "\n"
+	}
+	return body
+}
+
+// htmlSanitizer adapts htmlFilter to textSanitizer
+type htmlSanitizer struct {
+	filter htmlFilter
+}
+
+func (h *htmlSanitizer) write(text string) string { return h.filter.process(text, false) }
+func (h *htmlSanitizer) flush() string            { return h.filter.process("", true) }
+
+// HTML elements kept by the html-safe format
+var safeHTMLTags = map[string]bool{
+	"p": true, "br": true, "hr": true, "strong": true, "em": true, "b": true, "i": true, "u": true,
+	"s": true, "del": true, "sub": true, "sup": true, "code": true, "pre": true, "blockquote": true,
+	"ul": true, "ol": true, "li": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "a": true, "span": true, "div": true, "table": true, "thead": true, "tbody": true,
+	"tr": true, "th": true, "td": true,
+}
+
+// HTML elements dropped together with their content
+var droppedHTMLContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "svg": true, "math": true, "textarea": true, "select": true,
+}
+
+var voidHTMLTags = map[string]bool{"br": true, "hr": true}
+
+var languageClassPattern = regexp.MustCompile(`^language-[\w+-]+$`)
+
+// maxTagLength bounds how long a "<" may wait for its ">"; longer runs are
+// treated as text
+const maxTagLength = 1024
+
+// htmlFilter sanitizes streamed HTML. In safe mode it keeps allowlisted tags
+// with safe attributes, escapes text and balances tags. Otherwise it removes
+// all tags and leaves text as-is. Either way, the content of script-like
+// elements is dropped.
+type htmlFilter struct {
+	safe     bool
+	pending  string
+	suppress string
+	open     []string
+}
+
+// idle reports whether no text is held back or being dropped
+func (f *htmlFilter) idle() bool {
+	return f.pending == "" && f.suppress == ""
+}
+
+// process filters text, holding back an incomplete tag or entity unless
+// final is set
+func (f *htmlFilter) process(text string, final bool) string {
+	f.pending += text
+	var out strings.Builder
+	for f.pending != "" {
+		i := strings.IndexByte(f.pending, '<')
+		if i < 0 {
+			cut := len(f.pending)
+			if f.safe && !final {
+				// Hold a possible entity split across chunks
+				if amp := strings.LastIndexByte(f.pending, '&'); amp >= 0 && !strings.Contains(f.pending[amp:], ";") && len(f.pending)-amp < 12 {
+					cut = amp
+				}
+			}
+			f.text(&out, f.pending[:cut])
+			f.pending = f.pending[cut:]
+			break
+		}
+		f.text(&out, f.pending[:i])
+		f.pending = f.pending[i:]
+
+		if len(f.pending) == 1 {
+			if final {
+				f.text(&out, f.pending)
+				f.pending = ""
+			}
+			break
+		}
+		if next := f.pending[1]; !isASCIILetter(next) && next != '/' && next != '!' {
+			f.text(&out, "<")
+			f.pending = f.pending[1:]
+			continue
+		}
+		if strings.HasPrefix(f.pending, "<!--") {
+			end := strings.Index(f.pending, "-->")
+			if end < 0 {
+				if final {
+					f.pending = ""
+				}
+				break
+			}
+			f.pending = f.pending[end+3:]
+			continue
+		}
+		end := strings.IndexByte(f.pending, '>')
+		if end < 0 {
+			if !final && len(f.pending) <= maxTagLength {
+				break
+			}
+			f.text(&out, "<")
+			f.pending = f.pending[1:]
+			continue
+		}
+		f.tag(&out, f.pending[:end+1])
+		f.pending = f.pending[end+1:]
+	}
+	if final {
+		f.suppress = ""
+		for i := len(f.open) - 1; i >= 0; i-- {
+			out.WriteString("</" + f.open[i] + ">")
+		}
+		f.open = nil
+	}
+	return out.String()
+}
+
+func (f *htmlFilter) text(out *strings.Builder, text string) {
+	if f.suppress != "" || text == "" {
+		return
+	}
+	if f.safe {
+		text = html.EscapeString(html.UnescapeString(text))
+	}
+	out.WriteString(text)
+}
+
+func (f *htmlFilter) tag(out *strings.Builder, raw string) {
+	z := nethtml.NewTokenizer(strings.NewReader(raw))
+	tt := z.Next()
+	if tt != nethtml.StartTagToken && tt != nethtml.EndTagToken && tt != nethtml.SelfClosingTagToken {
+		return
+	}
+	token := z.Token()
+	name := token.Data
+
+	if f.suppress != "" {
+		if tt == nethtml.EndTagToken && name == f.suppress {
+			f.suppress = ""
+		}
+		return
+	}
+	if droppedHTMLContent[name] {
+		if tt == nethtml.StartTagToken {
+			f.suppress = name
+		}
+		return
+	}
+	if !f.safe || !safeHTMLTags[name] {
+		return
+	}
+
+	if tt == nethtml.EndTagToken {
+		for i := len(f.open) - 1; i >= 0; i-- {
+			if f.open[i] == name {
+				for j := len(f.open) - 1; j >= i; j-- {
+					out.WriteString("</" + f.open[j] + ">")
+				}
+				f.open = f.open[:i]
+				return
+			}
+		}
+		return
+	}
+
+	out.WriteString("<" + name)
+	for _, attr := range token.Attr {
+		if value, ok := safeAttribute(name, attr.Key, attr.Val); ok {
+			out.WriteString(" " + attr.Key + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	if name == "a" {
+		out.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	out.WriteString(">")
+	switch {
+	case voidHTMLTags[name]:
+	case tt == nethtml.SelfClosingTagToken:
+		out.WriteString("</" + name + ">")
+	default:
+		f.open = append(f.open, name)
+	}
+}
+
+// safeAttribute reports whether an attribute may be kept on a tag
+func safeAttribute(tag, key, value string) (string, bool) {
+	switch {
+	case tag == "a" && key == "href":
+		return value, safeURL(value)
+	case tag == "a" && key == "title":
+		return value, true
+	case (tag == "code" || tag == "pre") && key == "class":
+		return value, languageClassPattern.MatchString(value)
+	case (tag == "td" || tag == "th") && (key == "colspan" || key == "rowspan"):
+		return value, value != "" && strings.Trim(value, "0123456789") == ""
+	}
+	return "", false
+}
+
+// safeURL allows relative URLs and http, https and mailto links
+func safeURL(value string) bool {
+	value = strings.TrimSpace(value)
+	colon := strings.IndexByte(value, ':')
+	if colon < 0 || strings.ContainsAny(value[:colon], "/?#") {
+		return true
+	}
+	switch strings.ToLower(value[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestSanitizeText_HTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"keeps formatting", "<p>Hi <strong>there</strong></p>", "<p>Hi <strong>there</strong></p>"},
+		{"drops scripts", `<p>a<script>alert("x")</script>b</p>`, "<p>ab</p>"},
+		{"drops handlers", `<p onclick="steal()">x</p>`, "<p>x</p>"},
+		{"unsafe href", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"safe href", `<a href="https://example.com">x</a>`, `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"closes open tags", "<ul><li>one<li>two", "<ul><li>one<li>two</li></li></ul>"},
+		{"drops stray end tags", "a</div>b", "ab"},
+		{"closes skipped tags", "<p><em>x</p>", "<p><em>x</em></p>"},
+		{"escapes text", "1 < 2 & 3 > 2", "1 &lt; 2 &amp; 3 &gt; 2"},
+		{"keeps entities", "&amp; &lt;", "&amp; &lt;"},
+		{"drops unknown tags", `<img src=x onerror=alert(1)>text`, "text"},
+		{"drops comments", "a<!-- hidden -->b", "ab"},
+		{"code language", `<pre><code class="language-go">x</code></pre>`, `<pre><code class="language-go">x</code></pre>`},
+	}
+	for _, tt := range tests {
+		if got := SanitizeText(TextFormatHTML, tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeText_Markdown(t *testing.T) {
+	t.Parallel()
+
+	in := "# Title\n\nSome <b>bold</b> text<script>alert(1)</script>.\n" +
+		"Use `<div>` here and [click](javascript:alert(1)).\n" +
+		"```html\n<script>kept in code</script>\n```\n"
+	want := "# Title\n\nSome bold text.\n" +
+		"Use `<div>` here and [click](#).\n" +
+		"```html\n<script>kept in code</script>\n```\n"
+	if got := SanitizeText(TextFormatMarkdown, in); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeText_Plain(t *testing.T) {
+	t.Parallel()
+
+	in := "## Steps\n\n* **Open** the [docs](https://example.com)\n> _note_ &amp; `code`\n---\n```\nx := 1\n```\nDone ~~now~~."
+	want := "Steps\n\n- Open the docs (https://example.com)\nnote & code\nx := 1\nDone now."
+	if got := SanitizeText(TextFormatPlain, in); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func textFormatModel(format TextFormat, texts ...string) (*testutil.MockLanguageModel, provider.LanguageModel) {
+	chunks := []provider.StreamChunk{{Type: provider.ChunkTypeTextStart, ID: "t1"}}
+	for _, text := range texts {
+		chunks = append(chunks, provider.StreamChunk{Type: provider.ChunkTypeText, ID: "t1", Text: text})
+	}
+	chunks = append(chunks,
+		provider.StreamChunk{Type: provider.ChunkTypeTextEnd, ID: "t1"},
+		provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+	)
+	mock := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, o *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream(chunks), nil
+		},
+		DoGenerateFunc: func(ctx context.Context, o *provider.GenerateOptions) (*types.GenerateResult, error) {
+			text := strings.Join(texts, "")
+			return &types.GenerateResult{Text: text, Content: []types.ContentPart{types.TextContent{Text: text}}}, nil
+		},
+	}
+	return mock, WrapLanguageModel(mock, []*LanguageModelMiddleware{TextFormatMiddleware(TextFormatOptions{Format: format})}, nil, nil)
+}
+
+func TestTextFormatMiddleware_StreamsHTMLAcrossChunks(t *testing.T) {
+	t.Parallel()
+
+	// Tags, script bodies and entities split across chunk boundaries
+	_, model := textFormatModel(TextFormatHTML, "<p>Hi <str", "ong>there</strong><scr", "ipt>bad()</scr", "ipt> &am", "p; bye")
+	text, kinds, _ := readModerated(t, model)
+	if want := "<p>Hi <strong>there</strong> &amp; bye</p>"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	if kinds[len(kinds)-2] != provider.ChunkTypeTextEnd {
+		t.Errorf("held text delivered after text-end: %v", kinds)
+	}
+}
+
+func TestTextFormatMiddleware_StreamsPlainByLine(t *testing.T) {
+	t.Parallel()
+
+	_, model := textFormatModel(TextFormatPlain, "# He", "llo\n**wor", "ld**")
+	text, _, _ := readModerated(t, model)
+	if text != "Hello\nworld" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestTextFormatMiddleware_Generate(t *testing.T) {
+	t.Parallel()
+
+	mock, model := textFormatModel(TextFormatMarkdown, "ok<script>x</script>")
+	params := &provider.GenerateOptions{Prompt: types.Prompt{System: "Be brief."}}
+	result, err := model.DoGenerate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	if result.Text != "ok" || result.Content[0].(types.TextContent).Text != "ok" {
+		t.Errorf("result = %q %v", result.Text, result.Content)
+	}
+	system := mock.GenerateCalls[0].Prompt.System
+	if !strings.HasPrefix(system, "Be brief.\n\n") || !strings.Contains(system, "Markdown") {
+		t.Errorf("system = %q", system)
+	}
+}
+
+func TestTextFormatMiddleware_RejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, model := textFormatModel("rtf", "x")
+	if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// UsageUpdate is a usage report delivered to UsageStreamOptions.OnUsage
+type UsageUpdate struct {
+	// Usage is the cumulative usage of the generation so far
+	Usage types.Usage
+
+	// Estimated reports that Usage is a client-side estimate
+	Estimated bool
+
+	// Final is set for the usage on the finish chunk
+	Final bool
+}
+
+// UsageStreamOptions configures UsageStreamMiddleware
+type UsageStreamOptions struct {
+	// Interval is how many estimated output tokens accumulate between usage
+	// chunks (default: 20)
+	Interval int
+
+	// EstimateTokens counts the tokens in text (default: 4 characters per
+	// token). Plug in a real tokenizer for tighter estimates.
+	EstimateTokens func(text string) int
+
+	// OnUsage is called with every usage chunk, estimated or reported, and
+	// with the final usage. Returning an error stops the stream: the
+	// provider request is closed and Next returns the error. Use it to
+	// enforce token budgets while the response is still streaming.
+	OnUsage func(ctx context.Context, update UsageUpdate) error
+}
+
+// UsageStreamMiddleware returns middleware that reports token usage while a
+// response streams. Input tokens are estimated from the prompt and tools;
+// output tokens from the text, reasoning and tool calls received so far.
+// Every Interval output tokens a ChunkTypeUsage chunk carrying the estimate
+// is sent with UsageEstimated set. The finish chunk carries the
+// provider-reported usage when there is one, and the estimate otherwise.
+//
+// Once the provider reports usage mid-stream, estimates stop and its reports
+// are passed through.
+//
+// Example:
+//
+//	budget := middleware.UsageStreamMiddleware(&middleware.UsageStreamOptions{
+//		OnUsage: func(ctx context.Context, u middleware.UsageUpdate) error {
+//			if u.Usage.GetTotalTokens() > 50_000 {
+//				return errors.New("token budget exceeded")
+//			}
+//			return nil
+//		},
+//	})
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{budget}, nil, nil)
+func UsageStreamMiddleware(options *UsageStreamOptions) *LanguageModelMiddleware {
+	opts := UsageStreamOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 20
+	}
+	if opts.EstimateTokens == nil {
+		opts.EstimateTokens = func(text string) int {
+			return (utf8.RuneCountInString(text) + 3) / 4
+		}
+	}
+
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &usageStream{
+				underlying:  stream,
+				ctx:         ctx,
+				opts:        &opts,
+				inputTokens: int64(opts.EstimateTokens(promptText(params))),
+			}, nil
+		},
+	}
+}
+
+// promptText returns the text of the prompt and tool definitions, for
+// estimating input tokens. Images and files are not counted.
+func promptText(params *provider.GenerateOptions) string {
+	var b strings.Builder
+	b.WriteString(params.Prompt.System)
+	b.WriteString(params.Prompt.Text)
+	for _, msg := range params.Prompt.Messages {
+		b.WriteString(messageText(msg))
+	}
+	b.WriteString(toolsText(params.Tools))
+	return b.String()
+}
+
+// messageText returns the text of one message, including reasoning, tool
+// calls and tool results
+func messageText(msg types.Message) string {
+	var b strings.Builder
+	for _, part := range msg.Content {
+		switch p := part.(type) {
+		case types.TextContent:
+			b.WriteString(p.Text)
+		case types.ReasoningContent:
+			b.WriteString(p.Text)
+		case types.ToolResultContent:
+			if data, err := json.Marshal(p); err == nil {
+				b.Write(data)
+			}
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		if data, err := json.Marshal(call); err == nil {
+			b.Write(data)
+		}
+	}
+	return b.String()
+}
+
+// toolsText returns the text of tool definitions
+func toolsText(tools []types.Tool) string {
+	var b strings.Builder
+	for _, tool := range tools {
+		b.WriteString(tool.Name)
+		b.WriteString(tool.Description)
+		if data, err := json.Marshal(tool.Parameters); err == nil {
+			b.Write(data)
+		}
+	}
+	return b.String()
+}
+
+// usageStream adds usage chunks to a stream
+type usageStream struct {
+	underlying provider.TextStream
+	ctx        context.Context
+	opts       *UsageStreamOptions
+
+	inputTokens  int64
+	outputTokens int64
+	lastReported int64 // outputTokens at the last estimate sent
+	reported     bool  // the provider reported usage mid-stream
+
+	queue []*provider.StreamChunk
+	err   error
+}
+
+// Next returns the next chunk, followed by a usage chunk when the estimate
+// has grown by Interval tokens
+func (s *usageStream) Next() (*provider.StreamChunk, error) {
+	if len(s.queue) > 0 {
+		chunk := s.queue[0]
+		s.queue = s.queue[1:]
+		return chunk, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	chunk, err := s.underlying.Next()
+	if err != nil {
+		if err == io.EOF {
+			s.err = io.EOF
+		}
+		return chunk, err
+	}
+
+	switch chunk.Type {
+	case provider.ChunkTypeText:
+		s.count(chunk.Text)
+	case provider.ChunkTypeReasoning:
+		s.count(chunk.Reasoning)
+	case provider.ChunkTypeToolCall:
+		if chunk.ToolCall != nil {
+			data, _ := json.Marshal(chunk.ToolCall.Arguments)
+			s.count(chunk.ToolCall.ToolName + string(data))
+		}
+	case provider.ChunkTypeUsage:
+		if chunk.Usage != nil && !chunk.UsageEstimated {
+			s.reported = true
+			if err := s.notify(UsageUpdate{Usage: *chunk.Usage}); err != nil {
+				return nil, err
+			}
+		}
+		return chunk, nil
+	case provider.ChunkTypeFinish:
+		update := UsageUpdate{Final: true}
+		if chunk.Usage != nil {
+			update.Usage = *chunk.Usage
+		} else {
+			estimated := *chunk
+			estimated.Usage = s.estimate()
+			estimated.UsageEstimated = true
+			chunk = &estimated
+			update.Usage, update.Estimated = *chunk.Usage, true
+		}
+		if err := s.notify(update); err != nil {
+			return nil, err
+		}
+		return chunk, nil
+	default:
+		return chunk, nil
+	}
+
+	if !s.reported && s.outputTokens-s.lastReported >= int64(s.opts.Interval) {
+		s.lastReported = s.outputTokens
+		usage := s.estimate()
+		if err := s.notify(UsageUpdate{Usage: *usage, Estimated: true}); err != nil {
+			return nil, err
+		}
+		s.queue = append(s.queue, &provider.StreamChunk{
+			Type:           provider.ChunkTypeUsage,
+			Usage:          usage,
+			UsageEstimated: true,
+		})
+	}
+	return chunk, nil
+}
+
+func (s *usageStream) count(text string) {
+	if text != "" {
+		s.outputTokens += int64(s.opts.EstimateTokens(text))
+	}
+}
+
+func (s *usageStream) estimate() *types.Usage {
+	input, output := s.inputTokens, s.outputTokens
+	total := input + output
+	return &types.Usage{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}
+}
+
+// notify calls OnUsage, stopping the stream when it returns an error
+func (s *usageStream) notify(update UsageUpdate) error {
+	if s.opts.OnUsage == nil {
+		return nil
+	}
+	if err := s.opts.OnUsage(s.ctx, update); err != nil {
+		s.queue = nil
+		s.err = err
+		_ = s.underlying.Close() //nolint:errcheck
+		return err
+	}
+	return nil
+}
+
+// Err returns the error that stopped the stream, if any
+func (s *usageStream) Err() error {
+	if s.err != nil && s.err != io.EOF {
+		return s.err
+	}
+	return s.underlying.Err()
+}
+
+// Close closes the underlying stream
+func (s *usageStream) Close() error {
+	s.queue = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	return s.underlying.Close()
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func usageModel(opts *UsageStreamOptions, chunks []provider.StreamChunk) provider.LanguageModel {
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, o *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream(chunks), nil
+		},
+	}
+	return WrapLanguageModel(model, []*LanguageModelMiddleware{UsageStreamMiddleware(opts)}, nil, nil)
+}
+
+// words returns n text chunks of 8 characters (2 estimated tokens) each
+func words(n int) []provider.StreamChunk {
+	chunks := make([]provider.StreamChunk, n)
+	for i := range chunks {
+		chunks[i] = provider.StreamChunk{Type: provider.ChunkTypeText, Text: "abcdefg "}
+	}
+	return chunks
+}
+
+func TestUsageStreamMiddleware_EmitsEstimatesAndExactFinish(t *testing.T) {
+	t.Parallel()
+
+	input, output, total := int64(12), int64(21), int64(33)
+	exact := &types.Usage{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}
+	chunks := append(words(10), provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop, Usage: exact})
+
+	var updates []UsageUpdate
+	model := usageModel(&UsageStreamOptions{
+		Interval: 8,
+		OnUsage: func(ctx context.Context, u UsageUpdate) error {
+			updates = append(updates, u)
+			return nil
+		},
+	}, chunks)
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: strings.Repeat("x", 40)}})
+	if err != nil {
+		t.Fatalf("DoStream: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	var estimates []int64
+	var finish *provider.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeUsage:
+			if !chunk.UsageEstimated || chunk.Usage.GetInputTokens() != 10 {
+				t.Errorf("usage chunk = %+v, want an estimate with 10 input tokens", chunk)
+			}
+			estimates = append(estimates, chunk.Usage.GetOutputTokens())
+		case provider.ChunkTypeFinish:
+			finish = chunk
+		}
+	}
+
+	if len(estimates) != 2 || estimates[0] != 8 || estimates[1] != 16 {
+		t.Errorf("estimates = %v, want output tokens 8 and 16", estimates)
+	}
+	if finish == nil || finish.UsageEstimated || finish.Usage.GetTotalTokens() != 33 {
+		t.Errorf("finish = %+v, want the provider-reported usage", finish)
+	}
+	if len(updates) != 3 || !updates[2].Final || updates[2].Estimated {
+		t.Errorf("updates = %+v", updates)
+	}
+}
+
+func TestUsageStreamMiddleware_EstimatesFinishWithoutReportedUsage(t *testing.T) {
+	t.Parallel()
+
+	chunks := append(words(3), provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop})
+	stream, _ := usageModel(nil, chunks).DoStream(context.Background(), &provider.GenerateOptions{})
+	defer stream.Close() //nolint:errcheck
+
+	var finish *provider.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err != nil {
+			break
+		}
+		if chunk.Type == provider.ChunkTypeFinish {
+			finish = chunk
+		}
+	}
+	if finish == nil || !finish.UsageEstimated || finish.Usage.GetOutputTokens() != 6 {
+		t.Errorf("finish = %+v, want an estimated 6 output tokens", finish)
+	}
+}
+
+func TestUsageStreamMiddleware_OnUsageStopsStream(t *testing.T) {
+	t.Parallel()
+
+	budgetErr := errors.New("token budget exceeded")
+	chunks := append(words(50), provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop})
+	model := usageModel(&UsageStreamOptions{
+		Interval: 10,
+		OnUsage: func(ctx context.Context, u UsageUpdate) error {
+			if u.Usage.GetOutputTokens() >= 30 {
+				return budgetErr
+			}
+			return nil
+		},
+	}, chunks)
+	stream, _ := model.DoStream(context.Background(), &provider.GenerateOptions{})
+	defer stream.Close() //nolint:errcheck
+
+	var texts int
+	for {
+		chunk, err := stream.Next()
+		if err != nil {
+			if !errors.Is(err, budgetErr) {
+				t.Fatalf("err = %v, want the budget error", err)
+			}
+			break
+		}
+		if chunk.Type == provider.ChunkTypeText {
+			texts++
+		}
+	}
+	if texts != 14 {
+		t.Errorf("received %d text chunks, want the stream stopped at 30 tokens", texts)
+	}
+	if !errors.Is(stream.Err(), budgetErr) {
+		t.Errorf("Err() = %v", stream.Err())
+	}
+	if _, err := stream.Next(); !errors.Is(err, budgetErr) {
+		t.Errorf("Next after stop = %v", err)
+	}
+}
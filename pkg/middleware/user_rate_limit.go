@@ -0,0 +1,348 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"golang.org/x/time/rate"
+)
+
+// WindowLimit allows at most Max requests in any sliding Window
+type WindowLimit struct {
+	Window time.Duration
+	Max    int
+}
+
+// KeyedLimiterOptions configures a KeyedLimiter
+type KeyedLimiterOptions struct {
+	// Limits are sliding windows applied to every key, e.g. 20 per minute
+	// and 200 per day. A request must fit in all of them.
+	Limits []WindowLimit
+
+	// BurstRate and Burst bound short spikes with a per-key token bucket
+	// refilled at BurstRate requests per second. Zero disables burst control.
+	BurstRate float64
+	Burst     int
+
+	// ChallengeAfter requires a key to pass a challenge (captcha, step-up
+	// auth) after this many rejected requests within the longest window.
+	// Zero disables challenges.
+	ChallengeAfter int
+
+	// Clock is used for windows and blocks (default: system clock)
+	Clock clock.Clock
+}
+
+// Decision is the outcome of a KeyedLimiter check
+type Decision struct {
+	// Allowed reports whether the request may proceed
+	Allowed bool
+
+	// Key the decision applies to
+	Key string
+
+	// Reason explains a rejection: "rate-limit", "burst", "challenge" or "blocked"
+	Reason string
+
+	// RetryAfter is how long until a request could succeed (0 when unknown)
+	RetryAfter time.Duration
+
+	// Remaining is the number of requests left in the tightest window
+	// after this one (-1 when no windows are configured)
+	Remaining int
+}
+
+// UserRateLimitError is returned by the KeyedLimiter middleware when a key
+// is over its limit. It is deliberately distinct from provider rate limit
+// errors so retry logic does not retry it.
+type UserRateLimitError struct {
+	Decision Decision
+}
+
+func (e *UserRateLimitError) Error() string {
+	if e.Decision.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited (%s) for %q: retry after %s", e.Decision.Reason, e.Decision.Key, e.Decision.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited (%s) for %q", e.Decision.Reason, e.Decision.Key)
+}
+
+// KeyedLimiter applies per-user, per-IP or per-tenant limits with sliding
+// windows, burst control, manual blocks and challenge escalation. It is
+// independent of provider-level rate limiting and safe for concurrent use.
+//
+// Example:
+//
+//	limiter := middleware.NewKeyedLimiter(middleware.KeyedLimiterOptions{
+//		Limits:         []middleware.WindowLimit{{Window: time.Minute, Max: 20}},
+//		BurstRate:      1,
+//		Burst:          5,
+//		ChallengeAfter: 10,
+//	})
+//	http.Handle("/chat", limiter.Handler(chatHandler, middleware.LimitHandlerOptions{}))
+type KeyedLimiter struct {
+	opts    KeyedLimiterOptions
+	clock   clock.Clock
+	longest time.Duration
+
+	mu     sync.Mutex
+	keys   map[string]*keyState
+	checks int
+}
+
+type keyState struct {
+	requests   []time.Time
+	rejections []time.Time
+	bucket     *rate.Limiter
+	challenged bool
+	blocked    time.Time
+}
+
+// NewKeyedLimiter creates a limiter with the given options
+func NewKeyedLimiter(opts KeyedLimiterOptions) *KeyedLimiter {
+	l := &KeyedLimiter{opts: opts, clock: clock.Default(opts.Clock), keys: make(map[string]*keyState)}
+	for _, lim := range opts.Limits {
+		l.longest = max(l.longest, lim.Window)
+	}
+	return l
+}
+
+// Allow records a request for key and reports whether it may proceed.
+// Rejected requests are not counted against the windows.
+func (l *KeyedLimiter) Allow(key string) Decision {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	s := l.state(key)
+	d := Decision{Key: key, Remaining: -1}
+
+	if now.Before(s.blocked) {
+		d.Reason, d.RetryAfter = "blocked", s.blocked.Sub(now)
+		return d
+	}
+	if s.challenged {
+		d.Reason = "challenge"
+		return d
+	}
+
+	s.requests = prune(s.requests, now.Add(-l.longest))
+	for _, lim := range l.opts.Limits {
+		inWindow := countSince(s.requests, now.Add(-lim.Window))
+		if inWindow >= lim.Max {
+			d.Reason = "rate-limit"
+			// The window frees up when its oldest request expires
+			oldest := s.requests[len(s.requests)-inWindow]
+			d.RetryAfter = max(d.RetryAfter, oldest.Add(lim.Window).Sub(now))
+			continue
+		}
+		if remaining := lim.Max - inWindow - 1; d.Remaining < 0 || remaining < d.Remaining {
+			d.Remaining = remaining
+		}
+	}
+	if d.Reason == "" && s.bucket != nil {
+		if r := s.bucket.ReserveN(now, 1); !r.OK() || r.DelayFrom(now) > 0 {
+			if r.OK() {
+				d.RetryAfter = r.DelayFrom(now)
+				r.CancelAt(now)
+			}
+			d.Reason = "burst"
+		}
+	}
+
+	if d.Reason != "" {
+		d.Remaining = 0
+		l.reject(s, now)
+		return d
+	}
+	s.requests = append(s.requests, now)
+	d.Allowed = true
+	return d
+}
+
+// Block rejects every request for key until d has elapsed
+func (l *KeyedLimiter) Block(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state(key).blocked = l.clock.Now().Add(d)
+}
+
+// RequireChallenge makes key pass a challenge before further requests are
+// allowed, e.g. after an abuse signal from another system
+func (l *KeyedLimiter) RequireChallenge(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state(key).challenged = true
+}
+
+// PassChallenge records that key solved its challenge, clearing the
+// challenge requirement and its rejection history
+func (l *KeyedLimiter) PassChallenge(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s, ok := l.keys[key]; ok {
+		s.challenged = false
+		s.rejections = nil
+	}
+}
+
+// Reset forgets all state for key
+func (l *KeyedLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.keys, key)
+}
+
+func (l *KeyedLimiter) state(key string) *keyState {
+	s, ok := l.keys[key]
+	if !ok {
+		s = &keyState{}
+		if l.opts.BurstRate > 0 && l.opts.Burst > 0 {
+			s.bucket = rate.NewLimiter(rate.Limit(l.opts.BurstRate), l.opts.Burst)
+		}
+		l.keys[key] = s
+	}
+	return s
+}
+
+func (l *KeyedLimiter) reject(s *keyState, now time.Time) {
+	if l.opts.ChallengeAfter <= 0 {
+		return
+	}
+	s.rejections = append(prune(s.rejections, now.Add(-l.longest)), now)
+	if len(s.rejections) >= l.opts.ChallengeAfter {
+		s.challenged = true
+	}
+}
+
+// sweep periodically drops idle keys so memory stays bounded by active users
+func (l *KeyedLimiter) sweep(now time.Time) {
+	l.checks++
+	if l.checks%1024 != 0 {
+		return
+	}
+	cutoff := now.Add(-l.longest)
+	for key, s := range l.keys {
+		idle := len(prune(s.requests, cutoff)) == 0 && !s.challenged && !now.Before(s.blocked)
+		if idle && (s.bucket == nil || s.bucket.TokensAt(now) >= float64(s.bucket.Burst())) {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// prune drops timestamps before cutoff from the sorted slice
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+func countSince(times []time.Time, cutoff time.Time) int {
+	return len(prune(times, cutoff))
+}
+
+// LimitHandlerOptions configures KeyedLimiter.Handler
+type LimitHandlerOptions struct {
+	// Key extracts the limiter key from a request (default: client IP)
+	Key func(r *http.Request) string
+
+	// OnLimited writes the response for rejected requests
+	// (default: 429 with Retry-After, or 403 for challenges)
+	OnLimited func(w http.ResponseWriter, r *http.Request, d Decision)
+}
+
+// Handler wraps an HTTP handler so each request is checked against the
+// limiter before reaching next. Requests with an empty key are not limited.
+func (l *KeyedLimiter) Handler(next http.Handler, opts LimitHandlerOptions) http.Handler {
+	key := opts.Key
+	if key == nil {
+		key = ClientIP
+	}
+	onLimited := opts.OnLimited
+	if onLimited == nil {
+		onLimited = writeLimited
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		if k == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d := l.Allow(k)
+		if !d.Allowed {
+			onLimited(w, r, d)
+			return
+		}
+		if d.Remaining >= 0 {
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP returns the request's remote IP without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeLimited(w http.ResponseWriter, r *http.Request, d Decision) {
+	status := http.StatusTooManyRequests
+	if d.Reason == "challenge" || d.Reason == "blocked" {
+		status = http.StatusForbidden
+	}
+	if d.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": d.Reason})
+}
+
+// LanguageModelMiddleware limits generate and stream calls by the key
+// returned for each call, e.g. a user ID taken from ctx or opts.Metadata.
+// Calls with an empty key are not limited. Rejections return a
+// *UserRateLimitError without calling the model.
+func (l *KeyedLimiter) LanguageModelMiddleware(key func(ctx context.Context, opts *provider.GenerateOptions) string) *LanguageModelMiddleware {
+	check := func(ctx context.Context, opts *provider.GenerateOptions) error {
+		k := key(ctx, opts)
+		if k == "" {
+			return nil
+		}
+		if d := l.Allow(k); !d.Allowed {
+			return &UserRateLimitError{Decision: d}
+		}
+		return nil
+	}
+	return &LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		WrapGenerate: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (*types.GenerateResult, error) {
+			if err := check(ctx, params); err != nil {
+				return nil, err
+			}
+			return doGenerate()
+		},
+		WrapStream: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (provider.TextStream, error) {
+			if err := check(ctx, params); err != nil {
+				return nil, err
+			}
+			return doStream()
+		},
+	}
+}
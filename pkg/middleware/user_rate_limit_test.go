@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func TestKeyedLimiter_SlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	l := NewKeyedLimiter(KeyedLimiterOptions{
+		Limits: []WindowLimit{{Window: time.Minute, Max: 3}},
+		Clock:  fake,
+	})
+
+	for i := 0; i < 3; i++ {
+		if d := l.Allow("alice"); !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("request %d: unexpected decision %+v", i, d)
+		}
+		fake.Advance(10 * time.Second)
+	}
+	d := l.Allow("alice")
+	if d.Allowed || d.Reason != "rate-limit" || d.RetryAfter != 30*time.Second {
+		t.Fatalf("expected rejection with 30s retry, got %+v", d)
+	}
+	if !l.Allow("bob").Allowed {
+		t.Error("keys should be limited independently")
+	}
+
+	fake.Advance(30 * time.Second)
+	if !l.Allow("alice").Allowed {
+		t.Error("expected the oldest request to slide out of the window")
+	}
+}
+
+func TestKeyedLimiter_Burst(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	l := NewKeyedLimiter(KeyedLimiterOptions{BurstRate: 1, Burst: 2, Clock: fake})
+
+	l.Allow("k")
+	l.Allow("k")
+	d := l.Allow("k")
+	if d.Allowed || d.Reason != "burst" || d.RetryAfter != time.Second {
+		t.Fatalf("expected burst rejection, got %+v", d)
+	}
+	fake.Advance(time.Second)
+	if !l.Allow("k").Allowed {
+		t.Error("expected a token to refill after one second")
+	}
+}
+
+func TestKeyedLimiter_ChallengeAndBlock(t *testing.T) {
+	t.Parallel()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	l := NewKeyedLimiter(KeyedLimiterOptions{
+		Limits:         []WindowLimit{{Window: time.Minute, Max: 1}},
+		ChallengeAfter: 2,
+		Clock:          fake,
+	})
+
+	l.Allow("k")
+	l.Allow("k")
+	l.Allow("k")
+	fake.Advance(time.Minute)
+	if d := l.Allow("k"); d.Allowed || d.Reason != "challenge" {
+		t.Fatalf("expected challenge after repeated rejections, got %+v", d)
+	}
+	l.PassChallenge("k")
+	if !l.Allow("k").Allowed {
+		t.Error("expected requests to resume after passing the challenge")
+	}
+
+	l.Block("k", time.Hour)
+	if d := l.Allow("k"); d.Reason != "blocked" || d.RetryAfter != time.Hour {
+		t.Errorf("expected blocked decision, got %+v", d)
+	}
+}
+
+func TestKeyedLimiter_Handler(t *testing.T) {
+	t.Parallel()
+
+	l := NewKeyedLimiter(KeyedLimiterOptions{Limits: []WindowLimit{{Window: time.Minute, Max: 1}}})
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), LimitHandlerOptions{Key: func(r *http.Request) string { return r.Header.Get("X-User") }})
+
+	serve := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/chat", nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("u1"); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected first response %d %v", rec.Code, rec.Header())
+	}
+	rec := serve("u1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := serve(""); rec.Code != http.StatusOK {
+		t.Errorf("requests without a key should not be limited, got %d", rec.Code)
+	}
+}
+
+func TestKeyedLimiter_LanguageModelMiddleware(t *testing.T) {
+	t.Parallel()
+
+	l := NewKeyedLimiter(KeyedLimiterOptions{Limits: []WindowLimit{{Window: time.Minute, Max: 1}}})
+	model := &testutil.MockLanguageModel{}
+	wrapped := WrapLanguageModel(model, []*LanguageModelMiddleware{
+		l.LanguageModelMiddleware(func(ctx context.Context, opts *provider.GenerateOptions) string {
+			return opts.Metadata["user"]
+		}),
+	}, nil, nil)
+
+	opts := &provider.GenerateOptions{Metadata: map[string]string{"user": "u1"}}
+	if _, err := wrapped.DoGenerate(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := wrapped.DoStream(context.Background(), opts)
+	var limitErr *UserRateLimitError
+	if !errors.As(err, &limitErr) || limitErr.Decision.Key != "u1" {
+		t.Fatalf("expected UserRateLimitError, got %v", err)
+	}
+	if len(model.GenerateCalls) != 1 {
+		t.Errorf("model should not be called when limited")
+	}
+}
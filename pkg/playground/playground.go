@@ -0,0 +1,351 @@
+// Package playground serves a single-page web UI for trying prompts,
+// streaming, tools and structured output against a deployment's configured
+// providers.
+//
+// The playground is a plain http.Handler, so it mounts on net/http and on
+// the gin, echo, chi and fiber servers alike:
+//
+//	registry.RegisterProvider("openai", openai.New(openai.Config{APIKey: key}))
+//
+//	mux.Handle("/playground/", http.StripPrefix("/playground", playground.Handler(playground.Options{
+//		Models: []string{"openai:gpt-4o", "openai:gpt-4o-mini"},
+//		Tools:  []types.Tool{weatherTool},
+//	})))
+//
+// Requests run with the server's provider credentials. Mount the playground
+// only on internal deployments or behind authentication.
+package playground
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Options configures the playground
+type Options struct {
+	// Title is shown in the page header (default: "go-ai playground")
+	Title string
+
+	// Models are the model references offered in the model picker, e.g.
+	// "openai:gpt-4o". Users may also type any other reference.
+	Models []string
+
+	// Resolve resolves model references (default:
+	// registry.ResolveLanguageModel)
+	Resolve func(model string) (provider.LanguageModel, error)
+
+	// Tools are the tools users can enable for a request
+	Tools []types.Tool
+
+	// MaxSteps limits tool-calling steps per request (default: 5)
+	MaxSteps int
+
+	// Timeout limits each request (default: 2 minutes)
+	Timeout time.Duration
+}
+
+// Request is the body of a playground generation request
+type Request struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	MaxTokens   *int      `json:"maxTokens,omitempty"`
+
+	// Tools are the names of the Options.Tools to enable
+	Tools []string `json:"tools,omitempty"`
+
+	// Schema requests structured output matching this JSON Schema
+	Schema map[string]interface{} `json:"schema,omitempty"`
+}
+
+// Message is a text message of the conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type server struct {
+	opts  Options
+	tools map[string]types.Tool
+}
+
+// Handler returns the playground. It serves the UI at "/" and its API under
+// "/api/"; mount it with http.StripPrefix to serve it under a sub-path.
+func Handler(opts Options) http.Handler {
+	if opts.Title == "" {
+		opts.Title = "go-ai playground"
+	}
+	if opts.Resolve == nil {
+		opts.Resolve = registry.ResolveLanguageModel
+	}
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = 5
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+
+	s := &server{opts: opts, tools: make(map[string]types.Tool, len(opts.Tools))}
+	for _, tool := range opts.Tools {
+		s.tools[tool.Name] = tool
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /api/config", s.handleConfig)
+	mux.HandleFunc("POST /api/generate", s.handleGenerate)
+	mux.HandleFunc("POST /api/stream", s.handleStream)
+	return mux
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(indexHTML)
+}
+
+// handleConfig describes the models and tools the UI can offer
+func (s *server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	type toolInfo struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description,omitempty"`
+		Parameters  interface{} `json:"parameters,omitempty"`
+	}
+	tools := make([]toolInfo, 0, len(s.opts.Tools))
+	for _, tool := range s.opts.Tools {
+		tools = append(tools, toolInfo{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters})
+	}
+	models := s.opts.Models
+	if models == nil {
+		models = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"title":  s.opts.Title,
+		"models": models,
+		"tools":  tools,
+	})
+}
+
+// handleGenerate runs a non-streaming generation, or GenerateObject when a
+// schema is given
+func (s *server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	req, model, messages, tools, ok := s.parse(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.opts.Timeout)
+	defer cancel()
+	start := time.Now()
+
+	if req.Schema != nil {
+		result, err := ai.GenerateObject(ctx, ai.GenerateObjectOptions{
+			Model:       model,
+			System:      req.System,
+			Messages:    messages,
+			Schema:      schema.NewSimpleJSONSchema(req.Schema),
+			Temperature: req.Temperature,
+			MaxTokens:   req.MaxTokens,
+		})
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"object":       result.Object,
+			"text":         result.Text,
+			"finishReason": result.FinishReason,
+			"usage":        result.Usage,
+			"durationMs":   time.Since(start).Milliseconds(),
+		})
+		return
+	}
+
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model:       model,
+		System:      req.System,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Tools:       tools,
+		StopWhen:    []ai.StopCondition{ai.StepCountIs(s.opts.MaxSteps)},
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	toolResults := make([]map[string]interface{}, 0, len(result.ToolResults))
+	for _, tr := range result.ToolResults {
+		toolResults = append(toolResults, toolResultJSON(tr))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"text":         result.Text,
+		"toolCalls":    stepToolCalls(result.Steps),
+		"toolResults":  toolResults,
+		"steps":        len(result.Steps),
+		"finishReason": result.FinishReason,
+		"usage":        result.Usage,
+		"durationMs":   time.Since(start).Milliseconds(),
+	})
+}
+
+// handleStream streams a generation as server-sent events: "text",
+// "reasoning", "tool-call" and "tool-result" events while generating, then
+// "finish" with the usage, or "error"
+func (s *server) handleStream(w http.ResponseWriter, r *http.Request) {
+	req, model, messages, tools, ok := s.parse(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.opts.Timeout)
+	defer cancel()
+	start := time.Now()
+
+	stream, err := ai.StreamText(ctx, ai.StreamTextOptions{
+		Model:       model,
+		System:      req.System,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Tools:       tools,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer stream.Close() //nolint:errcheck
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		flusher.Flush()
+	}
+
+	for chunk := range stream.Chunks() {
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			send("text", chunk.Text)
+		case provider.ChunkTypeReasoning:
+			send("reasoning", chunk.Reasoning)
+		case provider.ChunkTypeToolCall:
+			if chunk.ToolCall != nil {
+				send("tool-call", chunk.ToolCall)
+			}
+		case provider.ChunkTypeToolResult:
+			if chunk.ToolResult != nil {
+				send("tool-result", toolResultJSON(*chunk.ToolResult))
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		send("error", map[string]string{"error": err.Error()})
+		return
+	}
+	send("finish", map[string]interface{}{
+		"finishReason": stream.FinishReason(),
+		"usage":        stream.Usage(),
+		"durationMs":   time.Since(start).Milliseconds(),
+	})
+}
+
+// parse decodes and validates a request, writing an error response and
+// returning false when it is invalid
+func (s *server) parse(w http.ResponseWriter, r *http.Request) (*Request, provider.LanguageModel, []types.Message, []types.Tool, bool) {
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return nil, nil, nil, nil, false
+	}
+	if req.Model == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("model is required"))
+		return nil, nil, nil, nil, false
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("messages are required"))
+		return nil, nil, nil, nil, false
+	}
+	model, err := s.opts.Resolve(req.Model)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, nil, nil, nil, false
+	}
+
+	messages := make([]types.Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		role := types.MessageRole(m.Role)
+		if role != types.RoleUser && role != types.RoleAssistant {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported message role %q", m.Role))
+			return nil, nil, nil, nil, false
+		}
+		messages = append(messages, types.Message{
+			Role:    role,
+			Content: []types.ContentPart{types.TextContent{Text: m.Content}},
+		})
+	}
+
+	var tools []types.Tool
+	for _, name := range req.Tools {
+		tool, ok := s.tools[name]
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown tool %q", name))
+			return nil, nil, nil, nil, false
+		}
+		tools = append(tools, tool)
+	}
+	return &req, model, messages, tools, true
+}
+
+// stepToolCalls returns the tool calls of all steps
+func stepToolCalls(steps []types.StepResult) []types.ToolCall {
+	calls := []types.ToolCall{}
+	for _, step := range steps {
+		calls = append(calls, step.ToolCalls...)
+	}
+	return calls
+}
+
+// toolResultJSON renders a tool result with its error as a string
+func toolResultJSON(tr types.ToolResult) map[string]interface{} {
+	out := map[string]interface{}{
+		"toolCallId": tr.ToolCallID,
+		"toolName":   tr.ToolName,
+		"result":     tr.Result,
+	}
+	if tr.Error != nil {
+		out["error"] = tr.Error.Error()
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
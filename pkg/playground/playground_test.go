@@ -0,0 +1,175 @@
+package playground
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func newTestServer(t *testing.T, model *testutil.MockLanguageModel) *httptest.Server {
+	t.Helper()
+	weather := types.Tool{
+		Name:        "weather",
+		Description: "Get the weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			return "sunny", nil
+		},
+	}
+	handler := Handler(Options{
+		Models: []string{"mock:test"},
+		Tools:  []types.Tool{weather},
+		Resolve: func(ref string) (provider.LanguageModel, error) {
+			if ref != "mock:test" {
+				return nil, fmt.Errorf("provider not found: %s", ref)
+			}
+			return model, nil
+		},
+	})
+	server := httptest.NewServer(http.StripPrefix("/playground", handler))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, url, body string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, _ := io.ReadAll(resp.Body)
+	return resp, string(data)
+}
+
+func TestHandler_IndexAndConfig(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, &testutil.MockLanguageModel{})
+
+	resp, err := http.Get(server.URL + "/playground/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "<title>go-ai playground</title>") {
+		t.Errorf("unexpected index: %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/playground/api/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		Models []string `json:"models"`
+		Tools  []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&cfg)
+	_ = resp.Body.Close()
+	if len(cfg.Models) != 1 || cfg.Models[0] != "mock:test" || len(cfg.Tools) != 1 || cfg.Tools[0].Name != "weather" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestHandler_Generate(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			calls++
+			if calls == 1 {
+				return &types.GenerateResult{
+					FinishReason: types.FinishReasonToolCalls,
+					ToolCalls:    []types.ToolCall{{ID: "c1", ToolName: "weather", Arguments: map[string]interface{}{}}},
+				}, nil
+			}
+			if opts.Prompt.System != "Be brief." || len(opts.Prompt.Messages) != 5 {
+				return nil, fmt.Errorf("unexpected prompt %+v", opts.Prompt)
+			}
+			return &types.GenerateResult{Text: "It is sunny.", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	server := newTestServer(t, model)
+
+	resp, body := post(t, server.URL+"/playground/api/generate", `{
+		"model": "mock:test",
+		"system": "Be brief.",
+		"messages": [{"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello"}, {"role": "user", "content": "Weather?"}],
+		"tools": ["weather"]
+	}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Text        string                   `json:"text"`
+		ToolCalls   []types.ToolCall         `json:"toolCalls"`
+		ToolResults []map[string]interface{} `json:"toolResults"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "It is sunny." || len(result.ToolCalls) != 1 || len(result.ToolResults) != 1 || result.ToolResults[0]["result"] != "sunny" {
+		t.Errorf("unexpected result %s", body)
+	}
+}
+
+func TestHandler_Stream(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "Hel"},
+				{Type: provider.ChunkTypeText, Text: "lo"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}
+	server := newTestServer(t, model)
+
+	resp, body := post(t, server.URL+"/playground/api/stream", `{"model": "mock:test", "messages": [{"role": "user", "content": "Hi"}]}`)
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q: %s", resp.Header.Get("Content-Type"), body)
+	}
+	for _, want := range []string{"event: text\ndata: \"Hel\"\n\n", "event: text\ndata: \"lo\"\n\n", "event: finish\ndata: {"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in stream:\n%s", want, body)
+		}
+	}
+}
+
+func TestHandler_BadRequests(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, &testutil.MockLanguageModel{})
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{`, "invalid request body"},
+		{`{"messages": [{"role": "user", "content": "Hi"}]}`, "model is required"},
+		{`{"model": "other:x", "messages": [{"role": "user", "content": "Hi"}]}`, "provider not found"},
+		{`{"model": "mock:test", "messages": [{"role": "system", "content": "Hi"}]}`, "unsupported message role"},
+		{`{"model": "mock:test", "messages": [{"role": "user", "content": "Hi"}], "tools": ["shell"]}`, `unknown tool \"shell\"`},
+	}
+	for _, tt := range tests {
+		resp, body := post(t, server.URL+"/playground/api/generate", tt.body)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, tt.want) {
+			t.Errorf("%s: got %d %s, want 400 containing %q", tt.body, resp.StatusCode, body, tt.want)
+		}
+	}
+}
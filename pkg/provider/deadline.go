@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// MaxDeadlineMargin caps the time DeadlineBudget reserves before a context
+// deadline
+const MaxDeadlineMargin = 2 * time.Second
+
+// DeadlineBudget returns how long a provider call may take under ctx's
+// deadline: the time remaining less a margin of 10% (at most
+// MaxDeadlineMargin). The margin lets the SDK fail with a typed
+// DeadlineExceededError while the caller still has time to handle it. ok is
+// false when ctx has no deadline; the budget is 0 once it has passed.
+func DeadlineBudget(ctx context.Context) (budget time.Duration, ok bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return 0, true
+	}
+	margin := remaining / 10
+	if margin > MaxDeadlineMargin {
+		margin = MaxDeadlineMargin
+	}
+	return remaining - margin, true
+}
@@ -1,8 +1,10 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Error types for the AI SDK
@@ -398,3 +400,83 @@ func NewDownloadError(url string, statusCode int, statusText, message string, ca
 		Cause:      cause,
 	}
 }
+
+// DeadlineSource identifies which side ran out of time
+type DeadlineSource string
+
+const (
+	// DeadlineSourceSDK means the request's context deadline, or the timeout
+	// the SDK derived from it, passed before the provider answered
+	DeadlineSourceSDK DeadlineSource = "sdk"
+
+	// DeadlineSourceProvider means the provider gave up and answered with a
+	// timeout status (408 or 504)
+	DeadlineSourceProvider DeadlineSource = "provider"
+)
+
+// DeadlineExceededError represents a request that ran out of time. SDK-side
+// timeouts match context.DeadlineExceeded with errors.Is, so existing
+// deadline checks keep working. Provider timeouts do not: the caller's
+// deadline has not passed, so retrying or falling back is still possible.
+type DeadlineExceededError struct {
+	// Source is the side that timed out
+	Source DeadlineSource
+
+	// Provider name (if known)
+	Provider string
+
+	// Timeout is the time budget the request was given (0 if unknown)
+	Timeout time.Duration
+
+	// StatusCode is the provider's HTTP status for provider timeouts
+	StatusCode int
+
+	// Underlying cause
+	Cause error
+}
+
+// Error implements the error interface
+func (e *DeadlineExceededError) Error() string {
+	msg := "request deadline exceeded"
+	if e.Source == DeadlineSourceProvider {
+		msg = "provider timed out"
+		if e.StatusCode > 0 {
+			msg = fmt.Sprintf("provider timed out (%d)", e.StatusCode)
+		}
+	} else if e.Timeout > 0 {
+		msg = fmt.Sprintf("request deadline exceeded after %v", e.Timeout.Round(time.Millisecond))
+	}
+	if e.Provider != "" {
+		msg = e.Provider + ": " + msg
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s (caused by: %v)", msg, e.Cause)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause
+func (e *DeadlineExceededError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is context.DeadlineExceeded and the SDK side
+// ran out of time
+func (e *DeadlineExceededError) Is(target error) bool {
+	return target == context.DeadlineExceeded && e.Source == DeadlineSourceSDK
+}
+
+// IsDeadlineExceededError checks if an error is a DeadlineExceededError
+func IsDeadlineExceededError(err error) bool {
+	var deadlineErr *DeadlineExceededError
+	return errors.As(err, &deadlineErr)
+}
+
+// NewDeadlineExceededError creates a new deadline exceeded error
+func NewDeadlineExceededError(source DeadlineSource, timeout time.Duration, cause error) *DeadlineExceededError {
+	return &DeadlineExceededError{
+		Source:  source,
+		Timeout: timeout,
+		Cause:   cause,
+	}
+}
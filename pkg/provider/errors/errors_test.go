@@ -1,8 +1,11 @@
 package errors
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestProviderError_Error(t *testing.T) {
@@ -716,3 +719,28 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDeadlineExceededError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("LHTTP request failed")
+	err := NewDeadlineExceededError(DeadlineSourceSDK, 1500*time.Millisecond, cause)
+
+	if err.Error() != "request deadline exceeded after 1.5s (caused by: LHTTP request failed)" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, cause) {
+		t.Error("expected the error to match context.DeadlineExceeded and its cause")
+	}
+	if !IsDeadlineExceededError(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("expected IsDeadlineExceededError to see through wrapping")
+	}
+
+	providerErr := &DeadlineExceededError{Source: DeadlineSourceProvider, Provider: "openai", StatusCode: 504}
+	if providerErr.Error() != "openai: provider timed out (504)" {
+		t.Errorf("unexpected message %q", providerErr.Error())
+	}
+	if errors.Is(providerErr, context.DeadlineExceeded) {
+		t.Error("expected a provider timeout not to match context.DeadlineExceeded")
+	}
+}
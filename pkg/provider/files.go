@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFileNotFound is wrapped by FileManager errors for unknown file IDs
+	ErrFileNotFound = errors.New("file not found")
+
+	// ErrFileTooLarge is wrapped by upload errors for files over a size limit
+	ErrFileTooLarge = errors.New("file too large")
+
+	// ErrFileTypeNotAllowed is wrapped by upload errors for rejected MIME types
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+)
+
+// FileManager is implemented by providers with a Files API. Uploaded files
+// are referenced in prompts by ID with types.FileContent.FileID instead of
+// resending their contents.
+type FileManager interface {
+	// UploadFile stores a file with the provider
+	UploadFile(ctx context.Context, opts FileUploadOptions) (*File, error)
+
+	// ListFiles returns the files stored with the provider
+	ListFiles(ctx context.Context) ([]File, error)
+
+	// GetFile returns a file's metadata
+	GetFile(ctx context.Context, fileID string) (*File, error)
+
+	// DeleteFile removes a file
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+// FileUploadOptions describes a file to upload
+type FileUploadOptions struct {
+	// Data is the file content
+	Data []byte
+
+	// Filename is sent with the upload (required)
+	Filename string
+
+	// MimeType of Data; detected from Filename or the content when empty
+	MimeType string
+
+	// Purpose is required by some providers, e.g. OpenAI's "user_data"
+	// (the default) or "assistants"
+	Purpose string
+}
+
+// File is a file stored with a provider
+type File struct {
+	ID        string
+	Filename  string
+	MimeType  string
+	Size      int64
+	CreatedAt time.Time
+	Purpose   string
+}
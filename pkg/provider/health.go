@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPingNotSupported is returned by Ping for providers that do not implement Pinger
+var ErrPingNotSupported = errors.New("provider does not support health checks")
+
+// PingResult describes the outcome of a provider reachability probe
+type PingResult struct {
+	// Provider is the provider name
+	Provider string
+
+	// Latency is the round-trip time of the probe request
+	Latency time.Duration
+
+	// StatusCode is the HTTP status returned by the provider (0 if the request failed)
+	StatusCode int
+
+	// CheckedAt is when the probe started
+	CheckedAt time.Time
+}
+
+// Pinger is implemented by providers that can perform a lightweight,
+// authenticated reachability check — typically listing available models.
+// A successful Ping confirms network reachability and valid credentials
+// without spending tokens.
+type Pinger interface {
+	// Ping probes the provider. The result is non-nil whenever a request was
+	// attempted, even if an error is also returned, so latency can be reported
+	// for failed probes.
+	Ping(ctx context.Context) (*PingResult, error)
+}
+
+// Ping probes p if it implements Pinger and returns ErrPingNotSupported otherwise
+func Ping(ctx context.Context, p Provider) (*PingResult, error) {
+	pinger, ok := p.(Pinger)
+	if !ok {
+		return nil, ErrPingNotSupported
+	}
+	return pinger.Ping(ctx)
+}
+
+// Health status values reported by CheckHealth
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// ProviderHealth is the health of a single provider, suitable for JSON responses
+type ProviderHealth struct {
+	Provider  string `json:"provider"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport aggregates provider health for a /health endpoint
+type HealthReport struct {
+	// Status is "healthy" when every probed provider is reachable, "unhealthy"
+	// when none are, and "degraded" otherwise
+	Status    string           `json:"status"`
+	Providers []ProviderHealth `json:"providers"`
+	CheckedAt time.Time        `json:"checkedAt"`
+}
+
+// CheckHealth pings all providers concurrently and aggregates the results.
+// Providers that do not support Ping are skipped; if none can be probed the
+// report is healthy with an empty provider list.
+//
+// Example:
+//
+//	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+//		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+//		defer cancel()
+//		report := provider.CheckHealth(ctx, openaiProvider, anthropicProvider)
+//		if report.Status != provider.HealthStatusHealthy {
+//			w.WriteHeader(http.StatusServiceUnavailable)
+//		}
+//		_ = json.NewEncoder(w).Encode(report)
+//	})
+func CheckHealth(ctx context.Context, providers ...Provider) HealthReport {
+	report := HealthReport{CheckedAt: time.Now()}
+	results := make([]*ProviderHealth, len(providers))
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			res, err := Ping(ctx, p)
+			if errors.Is(err, ErrPingNotSupported) {
+				return
+			}
+			health := &ProviderHealth{Provider: p.Name()}
+			if res != nil {
+				health.LatencyMs = res.Latency.Milliseconds()
+			}
+			if err != nil {
+				health.Error = err.Error()
+			} else {
+				health.Healthy = true
+			}
+			results[i] = health
+		}(i, p)
+	}
+	wg.Wait()
+
+	healthy := 0
+	for _, h := range results {
+		if h == nil {
+			continue
+		}
+		report.Providers = append(report.Providers, *h)
+		if h.Healthy {
+			healthy++
+		}
+	}
+
+	switch {
+	case healthy == len(report.Providers):
+		report.Status = HealthStatusHealthy
+	case healthy == 0:
+		report.Status = HealthStatusUnhealthy
+	default:
+		report.Status = HealthStatusDegraded
+	}
+	return report
+}
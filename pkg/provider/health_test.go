@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubProvider is a minimal Provider; embed it to add a Ping method
+type stubProvider struct {
+	Provider
+	name string
+}
+
+func (s stubProvider) Name() string { return s.name }
+
+type stubPinger struct {
+	stubProvider
+	err error
+}
+
+func (s stubPinger) Ping(ctx context.Context) (*PingResult, error) {
+	return &PingResult{Provider: s.name, Latency: 5 * time.Millisecond}, s.err
+}
+
+func TestPing_NotSupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := Ping(context.Background(), stubProvider{name: "plain"})
+	if !errors.Is(err, ErrPingNotSupported) {
+		t.Errorf("expected ErrPingNotSupported, got %v", err)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Parallel()
+
+	healthy := stubPinger{stubProvider: stubProvider{name: "a"}}
+	failing := stubPinger{stubProvider: stubProvider{name: "b"}, err: errors.New("401 unauthorized")}
+	unprobed := stubProvider{name: "c"}
+
+	tests := []struct {
+		name      string
+		providers []Provider
+		status    string
+		count     int
+	}{
+		{"all healthy", []Provider{healthy, unprobed}, HealthStatusHealthy, 1},
+		{"degraded", []Provider{healthy, failing}, HealthStatusDegraded, 2},
+		{"unhealthy", []Provider{failing}, HealthStatusUnhealthy, 1},
+		{"nothing to probe", []Provider{unprobed}, HealthStatusHealthy, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckHealth(context.Background(), tt.providers...)
+			if report.Status != tt.status {
+				t.Errorf("expected status %s, got %s", tt.status, report.Status)
+			}
+			if len(report.Providers) != tt.count {
+				t.Errorf("expected %d provider entries, got %d", tt.count, len(report.Providers))
+			}
+		})
+	}
+
+	report := CheckHealth(context.Background(), failing)
+	if report.Providers[0].Error == "" || report.Providers[0].LatencyMs != 5 {
+		t.Errorf("expected error and latency for failing provider, got %+v", report.Providers[0])
+	}
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+)
+
+// HTTPRequest is an outgoing provider API request, as seen by a
+// RequestTransform. Transforms may change any field; Body is the encoded
+// payload (usually JSON).
+type HTTPRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// HTTPResponse is a provider API response, as seen by a ResponseTransform.
+// For streaming responses Body is nil and Stream is set: the status and
+// headers can be inspected or changed, but not the event stream.
+type HTTPResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Stream     bool
+}
+
+// RequestTransform rewrites an outgoing request. Returning an error fails
+// the call without sending it.
+type RequestTransform func(ctx context.Context, req *HTTPRequest) error
+
+// ResponseTransform rewrites a response before the provider decodes it
+type ResponseTransform func(ctx context.Context, resp *HTTPResponse) error
+
+// HTTPTransforms are request and response transforms applied, in order, by
+// providers built on the SDK's HTTP client
+type HTTPTransforms struct {
+	Request  []RequestTransform
+	Response []ResponseTransform
+}
+
+type httpTransformsKey struct{}
+
+// ContextWithHTTPTransforms returns a context whose provider calls apply the
+// transforms, after any transforms already in ctx
+func ContextWithHTTPTransforms(ctx context.Context, transforms HTTPTransforms) context.Context {
+	if existing := HTTPTransformsFromContext(ctx); existing != nil {
+		transforms = HTTPTransforms{
+			Request:  append(append([]RequestTransform{}, existing.Request...), transforms.Request...),
+			Response: append(append([]ResponseTransform{}, existing.Response...), transforms.Response...),
+		}
+	}
+	return context.WithValue(ctx, httpTransformsKey{}, &transforms)
+}
+
+// HTTPTransformsFromContext returns the transforms set on ctx, or nil
+func HTTPTransformsFromContext(ctx context.Context) *HTTPTransforms {
+	t, _ := ctx.Value(httpTransformsKey{}).(*HTTPTransforms)
+	return t
+}
@@ -10,6 +10,24 @@ import (
 
 // LanguageModel represents a language model (V3 specification)
 // This is the core interface that all language model providers must implement
+//
+// Implementations outside this module are supported; the contract the SDK
+// relies on is:
+//   - SpecificationVersion returns "v3"; Provider and ModelID are non-empty.
+//   - DoGenerate returns a non-nil result or an error, never both nil. The
+//     FinishReason is one of the types.FinishReason constants, tool calls
+//     have an ID, name and decoded arguments and finish with
+//     FinishReasonToolCalls, and Usage reports token counts when known.
+//   - DoStream returns a TextStream whose Next ends with io.EOF after exactly
+//     one ChunkTypeFinish chunk. Text and reasoning start/end chunks, when
+//     emitted, are balanced. Close may be called at any time and more than
+//     once, after which Next returns io.EOF.
+//   - Failed API calls return (or wrap) an *errors.ProviderError, or an
+//     *errors.RateLimitError when rate limited, and cancellation of ctx is
+//     reported with an error wrapping ctx.Err().
+//
+// The providertest package checks these rules; run
+// providertest.RunConformance from a custom provider's tests.
 type LanguageModel interface {
 	// Metadata methods
 	SpecificationVersion() string // Returns "v3" for V3 models
@@ -26,6 +44,15 @@ type LanguageModel interface {
 	DoStream(ctx context.Context, opts *GenerateOptions) (TextStream, error)
 }
 
+// ConstrainedDecodingModel is implemented by language models that can enforce
+// a types.Constraint natively while sampling (e.g. vLLM guided decoding or
+// llama.cpp grammars). Models that do not implement it get post-generation
+// validation instead.
+type ConstrainedDecodingModel interface {
+	// SupportsConstraint reports whether the model can enforce constraints of the given kind
+	SupportsConstraint(kind types.ConstraintKind) bool
+}
+
 // GenerateOptions contains all options for text generation
 type GenerateOptions struct {
 	// Prompt for the model (either text or messages)
@@ -61,6 +88,11 @@ type GenerateOptions struct {
 	// Response format (for structured output)
 	ResponseFormat *ResponseFormat
 
+	// Constraint restricts decoding to a regex, grammar or JSON schema.
+	// Only set by callers when the model reports native support via
+	// ConstrainedDecodingModel; providers that do not support it ignore it.
+	Constraint *types.Constraint
+
 	// Seed for deterministic generation
 	Seed *int
 
@@ -84,8 +116,16 @@ type GenerateOptions struct {
 	// Telemetry configuration for observability
 	// Providers can use this to instrument their API calls with OpenTelemetry spans
 	Telemetry *telemetry.Settings
+
+	// Metadata tags the request for attribution (feature, team, end user).
+	// Providers forward it where their API supports request metadata; the
+	// MetadataKeyUser entry maps to end-user fields such as OpenAI's "user".
+	Metadata map[string]string
 }
 
+// MetadataKeyUser is the GenerateOptions.Metadata key identifying the end user
+const MetadataKeyUser = "user"
+
 // ResponseFormat specifies the format of the response
 // Updated in v6.0 to support name and description for provider guidance
 type ResponseFormat struct {
@@ -181,9 +221,17 @@ type StreamChunk struct {
 	// Synthetic chunks emitted by streamText after deferred tool execution.
 	ToolResult *types.ToolResult
 
-	// Usage information (when Type is ChunkTypeUsage or ChunkTypeFinish)
+	// Usage information (when Type is ChunkTypeUsage or ChunkTypeFinish).
+	// Usage is cumulative for the generation so far, not a delta: a later
+	// report replaces an earlier one.
 	Usage *types.Usage
 
+	// UsageEstimated reports that Usage is a client-side estimate of the
+	// tokens so far rather than provider-reported usage. Estimates are sent
+	// mid-stream; exact usage, when the provider reports it, arrives on a
+	// later usage or finish chunk.
+	UsageEstimated bool
+
 	// Finish reason (when Type is ChunkTypeFinish)
 	FinishReason types.FinishReason
 
@@ -215,6 +263,11 @@ type StreamChunk struct {
 	// ChunkTypeFile.
 	GeneratedFileContent *types.GeneratedFileContent
 
+	// Audio carries an incremental piece of spoken output when Type is
+	// ChunkTypeAudio.  Data and Transcript are deltas; ID, Voice and Format
+	// are set when known.
+	Audio *types.AudioOutput
+
 	// ProviderMetadata carries provider-specific metadata attached to a stream
 	// part.  Present on block-boundary chunk types (ChunkTypeTextStart,
 	// ChunkTypeText, ChunkTypeTextEnd, ChunkTypeReasoningStart,
@@ -289,6 +342,11 @@ const (
 	// GeneratedFileContent part.
 	ChunkTypeFile ChunkType = "file"
 
+	// ChunkTypeAudio indicates a piece of audio output from models that speak
+	// their response (e.g. OpenAI audio-output chat models).  The chunk carries
+	// an AudioOutput delta whose Data and Transcript should be concatenated.
+	ChunkTypeAudio ChunkType = "audio"
+
 	// ChunkTypeToolInputStart marks the beginning of streaming tool input for a
 	// custom function tool call.  The ToolCall field contains the tool call ID and
 	// name.  Subsequent ChunkTypeToolInputDelta chunks carry incremental JSON and
@@ -434,6 +492,10 @@ type SpeechGenerateOptions struct {
 
 	// Speed of speech (0.25 to 4.0)
 	Speed *float64
+
+	// OutputFormat requests an audio encoding such as "mp3", "wav" or "pcm"
+	// (optional; providers that cannot honour it use their default)
+	OutputFormat string
 }
 
 // TranscriptionModel represents a speech-to-text model
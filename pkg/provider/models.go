@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+// ErrListModelsNotSupported is returned by ListModels for providers that do
+// not implement ModelLister
+var ErrListModelsNotSupported = errors.New("provider does not support listing models")
+
+// ModelListing describes a model advertised by a provider's model-list API.
+// Fields the provider does not report are left at their zero value.
+type ModelListing struct {
+	// ID is the model ID accepted by LanguageModel, EmbeddingModel, etc.
+	ID string `json:"id"`
+
+	// DisplayName is a human-readable name, if the provider reports one
+	DisplayName string `json:"displayName,omitempty"`
+
+	// OwnedBy is the organization that owns the model
+	OwnedBy string `json:"ownedBy,omitempty"`
+
+	// Type is the provider's model category, e.g. "chat" or "embedding"
+	Type string `json:"type,omitempty"`
+
+	// ContextWindow is the maximum number of tokens the model accepts
+	ContextWindow int `json:"contextWindow,omitempty"`
+
+	// Created is when the model was published
+	Created time.Time `json:"created,omitzero"`
+}
+
+// ModelLister is implemented by providers that can list the models available
+// to the configured credentials. Each call fetches the list from the
+// provider; wrap calls in a ModelListCache to avoid refetching on every
+// lookup.
+type ModelLister interface {
+	// ListModels fetches the provider's current model list
+	ListModels(ctx context.Context) ([]ModelListing, error)
+}
+
+// ListModels lists p's models if it implements ModelLister and returns
+// ErrListModelsNotSupported otherwise
+func ListModels(ctx context.Context, p Provider) ([]ModelListing, error) {
+	lister, ok := p.(ModelLister)
+	if !ok {
+		return nil, ErrListModelsNotSupported
+	}
+	return lister.ListModels(ctx)
+}
+
+// ModelListCacheOptions configures a ModelListCache
+type ModelListCacheOptions struct {
+	// TTL is how long a fetched list is served without refreshing (default 10m)
+	TTL time.Duration
+
+	// MaxStale is how long past TTL an expired list is still served while a
+	// background refresh runs. Beyond that, callers wait for a fresh list.
+	// Defaults to 24h; a negative value disables stale serving.
+	MaxStale time.Duration
+
+	// OnRefreshError is called when a background refresh fails. The stale
+	// list keeps being served until MaxStale elapses.
+	OnRefreshError func(provider string, err error)
+
+	// Clock is the time source (default clock.System)
+	Clock clock.Clock
+}
+
+// ModelListCache caches provider model lists so registries and CLIs can look
+// models up without calling the provider on every request. Lists are keyed
+// by provider instance, so two providers sharing a name (such as several
+// OpenAI-compatible endpoints) are cached separately; providers must be
+// comparable, which pointer implementations are. Once a list expires the cached copy is returned
+// immediately and refreshed in the background; concurrent fetches for the
+// same provider share one request.
+type ModelListCache struct {
+	opts ModelListCacheOptions
+
+	mu      sync.Mutex
+	entries map[Provider]*modelListEntry
+}
+
+type modelListEntry struct {
+	models    []ModelListing
+	fetchedAt time.Time
+
+	// inflight is the running fetch; nil when idle
+	inflight *modelListFetch
+}
+
+// modelListFetch is a single ListModels call shared by concurrent callers
+type modelListFetch struct {
+	done   chan struct{}
+	models []ModelListing
+	err    error
+}
+
+// NewModelListCache creates an empty cache
+func NewModelListCache(opts ModelListCacheOptions) *ModelListCache {
+	if opts.TTL <= 0 {
+		opts.TTL = 10 * time.Minute
+	}
+	if opts.MaxStale == 0 {
+		opts.MaxStale = 24 * time.Hour
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.System
+	}
+	return &ModelListCache{opts: opts, entries: make(map[Provider]*modelListEntry)}
+}
+
+// ListModels returns p's model list from the cache, fetching it on first use
+// or once the cached list is too old to serve. The returned slice is shared
+// and must not be modified.
+func (c *ModelListCache) ListModels(ctx context.Context, p Provider) ([]ModelListing, error) {
+	lister, ok := p.(ModelLister)
+	if !ok {
+		return nil, ErrListModelsNotSupported
+	}
+	name := p.Name()
+
+	c.mu.Lock()
+	entry := c.entries[p]
+	if entry == nil {
+		entry = &modelListEntry{}
+		c.entries[p] = entry
+	}
+	if !entry.fetchedAt.IsZero() {
+		age := c.opts.Clock.Now().Sub(entry.fetchedAt)
+		if age < c.opts.TTL {
+			models := entry.models
+			c.mu.Unlock()
+			return models, nil
+		}
+		if c.opts.MaxStale > 0 && age < c.opts.TTL+c.opts.MaxStale {
+			models := entry.models
+			if entry.inflight == nil {
+				c.startLocked(ctx, name, lister, entry, true)
+			}
+			c.mu.Unlock()
+			return models, nil
+		}
+	}
+	if entry.inflight == nil {
+		c.startLocked(ctx, name, lister, entry, false)
+	}
+	fetch := entry.inflight
+	c.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.models, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startLocked launches a fetch for entry; c.mu must be held. The fetch is
+// detached from ctx's cancellation because other callers may be waiting on it.
+func (c *ModelListCache) startLocked(ctx context.Context, name string, lister ModelLister, entry *modelListEntry, background bool) {
+	fetch := &modelListFetch{done: make(chan struct{})}
+	entry.inflight = fetch
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		models, err := lister.ListModels(ctx)
+
+		c.mu.Lock()
+		fetch.models, fetch.err = models, err
+		if err == nil {
+			entry.models, entry.fetchedAt = models, c.opts.Clock.Now()
+		}
+		entry.inflight = nil
+		close(fetch.done)
+		c.mu.Unlock()
+
+		if err != nil && background && c.opts.OnRefreshError != nil {
+			c.opts.OnRefreshError(name, err)
+		}
+	}()
+}
+
+// Lookup returns the cached listing for modelID from p's model list
+func (c *ModelListCache) Lookup(ctx context.Context, p Provider, modelID string) (ModelListing, bool, error) {
+	models, err := c.ListModels(ctx, p)
+	if err != nil {
+		return ModelListing{}, false, err
+	}
+	for _, m := range models {
+		if m.ID == modelID {
+			return m, true, nil
+		}
+	}
+	return ModelListing{}, false, nil
+}
+
+// Invalidate drops the cached list for p so the next call fetches it again
+func (c *ModelListCache) Invalidate(p Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry := c.entries[p]; entry != nil {
+		entry.models, entry.fetchedAt = nil, time.Time{}
+	}
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+// stubLister returns a list naming the fetch count; block holds fetches
+// until it is closed
+type stubLister struct {
+	stubProvider
+	calls atomic.Int32
+	block chan struct{}
+	err   atomic.Value
+}
+
+func (s *stubLister) ListModels(ctx context.Context) ([]ModelListing, error) {
+	n := s.calls.Add(1)
+	if s.block != nil {
+		<-s.block
+	}
+	if err, _ := s.err.Load().(error); err != nil {
+		return nil, err
+	}
+	return []ModelListing{{ID: "model-" + string(rune('0'+n))}}, nil
+}
+
+// waitForModel polls the cache until a background refresh lands
+func waitForModel(t *testing.T, cache *ModelListCache, p Provider, id string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok, _ := cache.Lookup(context.Background(), p, id); ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("model %q never appeared", id)
+}
+
+func TestListModels_NotSupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := ListModels(context.Background(), stubProvider{name: "plain"})
+	if !errors.Is(err, ErrListModelsNotSupported) {
+		t.Errorf("expected ErrListModelsNotSupported, got %v", err)
+	}
+	cache := NewModelListCache(ModelListCacheOptions{})
+	if _, err := cache.ListModels(context.Background(), stubProvider{name: "plain"}); !errors.Is(err, ErrListModelsNotSupported) {
+		t.Errorf("expected ErrListModelsNotSupported from the cache, got %v", err)
+	}
+}
+
+func TestModelListCache_TTLAndBackgroundRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	lister := &stubLister{stubProvider: stubProvider{name: "p"}}
+	refreshErrs := make(chan error, 4)
+	cache := NewModelListCache(ModelListCacheOptions{
+		TTL:            time.Minute,
+		MaxStale:       time.Hour,
+		Clock:          clk,
+		OnRefreshError: func(provider string, err error) { refreshErrs <- err },
+	})
+
+	models, err := cache.ListModels(ctx, lister)
+	if err != nil || models[0].ID != "model-1" {
+		t.Fatalf("models = %v, err = %v", models, err)
+	}
+	clk.Advance(30 * time.Second)
+	if models, _ := cache.ListModels(ctx, lister); models[0].ID != "model-1" || lister.calls.Load() != 1 {
+		t.Fatalf("expected a cached list, got %v after %d calls", models, lister.calls.Load())
+	}
+
+	// Expired lists are served stale while a refresh runs in the background
+	clk.Advance(time.Minute)
+	if models, _ := cache.ListModels(ctx, lister); models[0].ID != "model-1" {
+		t.Fatalf("expected the stale list, got %v", models)
+	}
+	waitForModel(t, cache, lister, "model-2")
+
+	// A failed refresh keeps the old list and reports the error
+	lister.err.Store(errors.New("503"))
+	clk.Advance(2 * time.Minute)
+	if models, _ := cache.ListModels(ctx, lister); models[0].ID != "model-2" {
+		t.Fatalf("expected the stale list, got %v", models)
+	}
+	if err := <-refreshErrs; err.Error() != "503" {
+		t.Errorf("refresh error = %v", err)
+	}
+	if _, err := cache.ListModels(ctx, lister); err != nil {
+		t.Errorf("expected the stale list, got %v", err)
+	}
+
+	// Past MaxStale callers wait for the fetch and see its error
+	clk.Advance(2 * time.Hour)
+	if _, err := cache.ListModels(ctx, lister); err == nil || err.Error() != "503" {
+		t.Errorf("expected the fetch error, got %v", err)
+	}
+}
+
+func TestModelListCache_SharesFetches(t *testing.T) {
+	t.Parallel()
+
+	lister := &stubLister{stubProvider: stubProvider{name: "p"}, block: make(chan struct{})}
+	cache := NewModelListCache(ModelListCacheOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if models, err := cache.ListModels(context.Background(), lister); err != nil || len(models) != 1 {
+				t.Errorf("models = %v, err = %v", models, err)
+			}
+		}()
+	}
+
+	// A caller that gives up does not cancel the shared fetch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.ListModels(ctx, lister); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	close(lister.block)
+	wg.Wait()
+	if n := lister.calls.Load(); n != 1 {
+		t.Errorf("expected one fetch, got %d", n)
+	}
+
+	cache.Invalidate(lister)
+	if models, _ := cache.ListModels(context.Background(), lister); models[0].ID != "model-2" {
+		t.Errorf("expected a refetch after Invalidate, got %v", models)
+	}
+}
+
+func TestModelListCache_KeysByProviderInstance(t *testing.T) {
+	t.Parallel()
+
+	// Two endpoints of the same provider type report the same name
+	first := &stubLister{stubProvider: stubProvider{name: "openai-compatible"}}
+	second := &stubLister{stubProvider: stubProvider{name: "openai-compatible"}}
+	cache := NewModelListCache(ModelListCacheOptions{})
+
+	ctx := context.Background()
+	if _, err := cache.ListModels(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.ListModels(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if first.calls.Load() != 1 || second.calls.Load() != 1 {
+		t.Errorf("expected each provider to be fetched once, got %d and %d", first.calls.Load(), second.calls.Load())
+	}
+}
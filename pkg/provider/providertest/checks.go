@@ -0,0 +1,271 @@
+package providertest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// maxStreamChunks bounds CheckStream so a stream that never ends fails
+// instead of hanging
+const maxStreamChunks = 100000
+
+var finishReasons = map[types.FinishReason]bool{
+	types.FinishReasonStop:          true,
+	types.FinishReasonLength:        true,
+	types.FinishReasonContentFilter: true,
+	types.FinishReasonToolCalls:     true,
+	types.FinishReasonError:         true,
+	types.FinishReasonOther:         true,
+}
+
+// StreamSummary is what CheckStream read from a stream
+type StreamSummary struct {
+	Text         string
+	ToolCalls    []types.ToolCall
+	FinishReason types.FinishReason
+	Usage        *types.Usage
+	Chunks       int
+}
+
+// CheckMetadata checks the model's identity methods
+func CheckMetadata(model provider.LanguageModel) error {
+	var problems []string
+	if v := model.SpecificationVersion(); v != "v3" {
+		problems = append(problems, fmt.Sprintf("SpecificationVersion() = %q, want \"v3\"", v))
+	}
+	if model.Provider() == "" {
+		problems = append(problems, "Provider() is empty")
+	}
+	if model.ModelID() == "" {
+		problems = append(problems, "ModelID() is empty")
+	}
+	return joinProblems(problems)
+}
+
+// CheckGenerateResult checks a DoGenerate result for a text prompt
+func CheckGenerateResult(result *types.GenerateResult) error {
+	if result == nil {
+		return errors.New("DoGenerate returned a nil result without an error")
+	}
+	var problems []string
+	if result.Text == "" && len(result.ToolCalls) == 0 && len(result.Content) == 0 {
+		problems = append(problems, "result has no text, content or tool calls")
+	}
+	if !finishReasons[result.FinishReason] {
+		problems = append(problems, fmt.Sprintf("FinishReason %q is not a types.FinishReason constant", result.FinishReason))
+	}
+	if err := checkUsageValues(result.Usage); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return joinProblems(problems)
+}
+
+// CheckUsage checks that usage reports input and output tokens
+func CheckUsage(usage types.Usage) error {
+	var problems []string
+	if usage.InputTokens == nil || *usage.InputTokens <= 0 {
+		problems = append(problems, "Usage.InputTokens is not set")
+	}
+	if usage.OutputTokens == nil || *usage.OutputTokens <= 0 {
+		problems = append(problems, "Usage.OutputTokens is not set")
+	}
+	if err := checkUsageValues(usage); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return joinProblems(problems)
+}
+
+// checkUsageValues checks the usage fields that are set
+func checkUsageValues(usage types.Usage) error {
+	for name, v := range map[string]*int64{"InputTokens": usage.InputTokens, "OutputTokens": usage.OutputTokens, "TotalTokens": usage.TotalTokens} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("Usage.%s is negative (%d)", name, *v)
+		}
+	}
+	if usage.InputTokens != nil && usage.OutputTokens != nil && usage.TotalTokens != nil &&
+		*usage.TotalTokens < *usage.InputTokens+*usage.OutputTokens {
+		return fmt.Errorf("Usage.TotalTokens (%d) is less than InputTokens + OutputTokens (%d)",
+			*usage.TotalTokens, *usage.InputTokens+*usage.OutputTokens)
+	}
+	return nil
+}
+
+// CheckStream reads a stream to the end and checks its lifecycle: it ends
+// with io.EOF, has exactly one finish chunk, balances text and reasoning
+// blocks, keeps returning io.EOF after the end and reports no error from
+// Err. An error returned by Next is wrapped, so errors.As still finds the
+// provider error.
+func CheckStream(stream provider.TextStream) (*StreamSummary, error) {
+	summary := &StreamSummary{}
+	var text strings.Builder
+	var problems []string
+	open := map[string]bool{}
+	finishes := 0
+
+	for {
+		if summary.Chunks >= maxStreamChunks {
+			return summary, fmt.Errorf("stream did not end after %d chunks", maxStreamChunks)
+		}
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return summary, fmt.Errorf("Next failed: %w", err)
+		}
+		if chunk == nil {
+			return summary, errors.New("Next returned a nil chunk without an error")
+		}
+		summary.Chunks++
+		if finishes > 0 {
+			problems = append(problems, fmt.Sprintf("%s chunk after the finish chunk", chunk.Type))
+		}
+
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case provider.ChunkTypeTextStart, provider.ChunkTypeReasoningStart:
+			key := string(chunk.Type) + ":" + chunk.ID
+			if open[key] {
+				problems = append(problems, fmt.Sprintf("%s %q started twice", chunk.Type, chunk.ID))
+			}
+			open[key] = true
+		case provider.ChunkTypeTextEnd, provider.ChunkTypeReasoningEnd:
+			start := strings.TrimSuffix(string(chunk.Type), "-end") + "-start"
+			if !open[start+":"+chunk.ID] {
+				problems = append(problems, fmt.Sprintf("%s %q without a start", chunk.Type, chunk.ID))
+			}
+			delete(open, start+":"+chunk.ID)
+		case provider.ChunkTypeToolCall:
+			if chunk.ToolCall == nil {
+				problems = append(problems, "tool-call chunk without a ToolCall")
+			} else {
+				summary.ToolCalls = append(summary.ToolCalls, *chunk.ToolCall)
+			}
+		case provider.ChunkTypeUsage:
+			if chunk.Usage != nil {
+				summary.Usage = chunk.Usage
+			}
+		case provider.ChunkTypeFinish:
+			finishes++
+			summary.FinishReason = chunk.FinishReason
+			if !finishReasons[chunk.FinishReason] {
+				problems = append(problems, fmt.Sprintf("finish chunk has FinishReason %q, not a types.FinishReason constant", chunk.FinishReason))
+			}
+			if chunk.Usage != nil {
+				summary.Usage = chunk.Usage
+			}
+		case provider.ChunkTypeError:
+			problems = append(problems, "stream emitted an error chunk: "+chunk.Text)
+		}
+	}
+	summary.Text = text.String()
+
+	if finishes != 1 {
+		problems = append(problems, fmt.Sprintf("stream has %d finish chunks, want 1", finishes))
+	}
+	for key := range open {
+		problems = append(problems, fmt.Sprintf("block %s was never ended", key))
+	}
+	if summary.Usage != nil {
+		if err := checkUsageValues(*summary.Usage); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if _, err := stream.Next(); !errors.Is(err, io.EOF) {
+		problems = append(problems, fmt.Sprintf("Next after the end returned %v, want io.EOF", err))
+	}
+	if err := stream.Err(); err != nil {
+		problems = append(problems, fmt.Sprintf("Err() after a complete stream returned %v, want nil", err))
+	}
+	return summary, joinProblems(problems)
+}
+
+// CheckStreamClose checks that a stream can be closed early, more than
+// once, and returns io.EOF from Next afterwards
+func CheckStreamClose(stream provider.TextStream) error {
+	if _, err := stream.Next(); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("Next failed: %w", err)
+	}
+	if err := stream.Close(); err != nil {
+		return fmt.Errorf("Close failed: %w", err)
+	}
+	if err := closeAgain(stream); err != nil {
+		return err
+	}
+	if _, err := stream.Next(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("Next after Close returned %v, want io.EOF", err)
+	}
+	return nil
+}
+
+// closeAgain reports a panic from a second Close
+func closeAgain(stream provider.TextStream) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("second Close panicked: %v", r)
+		}
+	}()
+	stream.Close()
+	return nil
+}
+
+// CheckToolCalls checks the tool calls made for ToolOptions
+func CheckToolCalls(calls []types.ToolCall, finish types.FinishReason) error {
+	if len(calls) == 0 {
+		return errors.New("no tool calls, want a call to " + WeatherTool.Name)
+	}
+	var problems []string
+	for i, call := range calls {
+		if call.ID == "" {
+			problems = append(problems, fmt.Sprintf("tool call %d has no ID", i))
+		}
+		if call.ToolName != WeatherTool.Name {
+			problems = append(problems, fmt.Sprintf("tool call %d is to %q, want %q", i, call.ToolName, WeatherTool.Name))
+		}
+		if city, _ := call.Arguments["city"].(string); city == "" {
+			problems = append(problems, fmt.Sprintf("tool call %d arguments %v have no \"city\" string", i, call.Arguments))
+		}
+	}
+	if finish != types.FinishReasonToolCalls {
+		problems = append(problems, fmt.Sprintf("FinishReason = %q, want %q", finish, types.FinishReasonToolCalls))
+	}
+	return joinProblems(problems)
+}
+
+// CheckError checks that an HTTP failure with the given status surfaces as
+// a *errors.ProviderError (or *errors.RateLimitError for 429) whose status
+// code, when set, matches
+func CheckError(err error, status int) error {
+	if err == nil {
+		return fmt.Errorf("HTTP %d: no error returned", status)
+	}
+	var providerErr *providererrors.ProviderError
+	if errors.As(err, &providerErr) {
+		if providerErr.StatusCode != 0 && providerErr.StatusCode != status {
+			return fmt.Errorf("HTTP %d: ProviderError.StatusCode = %d", status, providerErr.StatusCode)
+		}
+		if providerErr.Provider == "" {
+			return fmt.Errorf("HTTP %d: ProviderError.Provider is empty", status)
+		}
+		return nil
+	}
+	var rateLimitErr *providererrors.RateLimitError
+	if status == 429 && errors.As(err, &rateLimitErr) {
+		return nil
+	}
+	return fmt.Errorf("HTTP %d: error %q (%T) is not a *errors.ProviderError", status, err, err)
+}
+
+func joinProblems(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
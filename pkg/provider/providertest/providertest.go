@@ -0,0 +1,249 @@
+// Package providertest is a conformance test suite for provider.LanguageModel
+// implementations. Out-of-tree providers run it from their own tests to check
+// that they honor the contract the rest of the SDK relies on: generation
+// results, stream lifecycle, tool calls, usage and error mapping.
+//
+// Point the model at a recorded or fake backend for fast, deterministic
+// runs, or at the live API behind a build tag or environment variable:
+//
+//	func TestConformance(t *testing.T) {
+//		providertest.RunConformance(t, providertest.Config{
+//			NewModel: func(t *testing.T) provider.LanguageModel {
+//				return myprovider.New(myprovider.Config{BaseURL: fakeServer(t)}).LanguageModel("my-model")
+//			},
+//			NewFailingModel: func(t *testing.T, status int) provider.LanguageModel {
+//				return myprovider.New(myprovider.Config{BaseURL: failingServer(t, status)}).LanguageModel("my-model")
+//			},
+//		})
+//	}
+package providertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Test names accepted by Config.Skip
+const (
+	TestMetadata        = "Metadata"
+	TestGenerate        = "Generate"
+	TestUsage           = "Usage"
+	TestStream          = "Stream"
+	TestStreamClose     = "StreamClose"
+	TestToolCalls       = "ToolCalls"
+	TestToolCallsStream = "ToolCallsStream"
+	TestErrorMapping    = "ErrorMapping"
+	TestContextCanceled = "ContextCanceled"
+)
+
+// ErrorStatuses are the HTTP statuses ErrorMapping passes to NewFailingModel
+var ErrorStatuses = []int{400, 401, 403, 404, 429, 500, 503}
+
+// Config describes the model under test
+type Config struct {
+	// NewModel returns a working model. It is called once per test.
+	NewModel func(t *testing.T) provider.LanguageModel
+
+	// NewFailingModel returns a model whose backend answers every request
+	// with the given HTTP status. Without it ErrorMapping is skipped.
+	NewFailingModel func(t *testing.T, status int) provider.LanguageModel
+
+	// Skip lists tests to skip, e.g. TestUsage for a backend that does not
+	// report token counts. Tool tests are skipped automatically when the
+	// model reports no tool support.
+	Skip []string
+
+	// Timeout bounds each model call (default: 60s)
+	Timeout time.Duration
+}
+
+// RunConformance runs the conformance suite as subtests of t
+func RunConformance(t *testing.T, cfg Config) {
+	t.Helper()
+	if cfg.NewModel == nil {
+		t.Fatal("providertest: Config.NewModel is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	skip := make(map[string]bool, len(cfg.Skip))
+	for _, name := range cfg.Skip {
+		skip[name] = true
+	}
+	run := func(name string, fn func(t *testing.T, ctx context.Context)) {
+		t.Run(name, func(t *testing.T) {
+			if skip[name] {
+				t.Skip("skipped by Config.Skip")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+			defer cancel()
+			fn(t, ctx)
+		})
+	}
+	requireTools := func(t *testing.T, model provider.LanguageModel) {
+		if !model.SupportsTools() {
+			t.Skip("model does not support tools")
+		}
+	}
+
+	run(TestMetadata, func(t *testing.T, ctx context.Context) {
+		check(t, CheckMetadata(cfg.NewModel(t)))
+	})
+
+	run(TestGenerate, func(t *testing.T, ctx context.Context) {
+		result, err := cfg.NewModel(t).DoGenerate(ctx, TextOptions())
+		if err != nil {
+			t.Fatalf("DoGenerate: %v", err)
+		}
+		check(t, CheckGenerateResult(result))
+	})
+
+	run(TestUsage, func(t *testing.T, ctx context.Context) {
+		result, err := cfg.NewModel(t).DoGenerate(ctx, TextOptions())
+		if err != nil {
+			t.Fatalf("DoGenerate: %v", err)
+		}
+		check(t, CheckUsage(result.Usage))
+	})
+
+	run(TestStream, func(t *testing.T, ctx context.Context) {
+		stream, err := cfg.NewModel(t).DoStream(ctx, TextOptions())
+		if err != nil {
+			t.Fatalf("DoStream: %v", err)
+		}
+		defer stream.Close()
+		summary, err := CheckStream(stream)
+		check(t, err)
+		if err == nil && summary.Text == "" && len(summary.ToolCalls) == 0 {
+			t.Error("stream produced no text")
+		}
+	})
+
+	run(TestStreamClose, func(t *testing.T, ctx context.Context) {
+		stream, err := cfg.NewModel(t).DoStream(ctx, TextOptions())
+		if err != nil {
+			t.Fatalf("DoStream: %v", err)
+		}
+		check(t, CheckStreamClose(stream))
+	})
+
+	run(TestToolCalls, func(t *testing.T, ctx context.Context) {
+		model := cfg.NewModel(t)
+		requireTools(t, model)
+		result, err := model.DoGenerate(ctx, ToolOptions())
+		if err != nil {
+			t.Fatalf("DoGenerate: %v", err)
+		}
+		check(t, CheckToolCalls(result.ToolCalls, result.FinishReason))
+	})
+
+	run(TestToolCallsStream, func(t *testing.T, ctx context.Context) {
+		model := cfg.NewModel(t)
+		requireTools(t, model)
+		stream, err := model.DoStream(ctx, ToolOptions())
+		if err != nil {
+			t.Fatalf("DoStream: %v", err)
+		}
+		defer stream.Close()
+		summary, err := CheckStream(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, CheckToolCalls(summary.ToolCalls, summary.FinishReason))
+	})
+
+	run(TestErrorMapping, func(t *testing.T, ctx context.Context) {
+		if cfg.NewFailingModel == nil {
+			t.Skip("Config.NewFailingModel not set")
+		}
+		for _, status := range ErrorStatuses {
+			model := cfg.NewFailingModel(t, status)
+			_, err := model.DoGenerate(ctx, TextOptions())
+			if err := CheckError(err, status); err != nil {
+				t.Errorf("DoGenerate: %v", err)
+			}
+			if err := CheckError(streamError(model, ctx), status); err != nil {
+				t.Errorf("DoStream: %v", err)
+			}
+		}
+	})
+
+	run(TestContextCanceled, func(t *testing.T, ctx context.Context) {
+		model := cfg.NewModel(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := model.DoGenerate(canceled, TextOptions())
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("DoGenerate with a canceled context returned %v, want an error wrapping context.Canceled", err)
+		}
+	})
+}
+
+// TextOptions is the prompt used by the text tests
+func TextOptions() *provider.GenerateOptions {
+	maxTokens := 64
+	return &provider.GenerateOptions{
+		Prompt: types.Prompt{
+			System: "You are a test fixture. Answer in at most five words.",
+			Messages: []types.Message{{
+				Role:    types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: "Say hello."}},
+			}},
+		},
+		MaxTokens: &maxTokens,
+	}
+}
+
+// WeatherTool is the tool offered by the tool tests
+var WeatherTool = types.Tool{
+	Name:        "get_weather",
+	Description: "Get the current weather for a city",
+	Parameters: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string", "description": "City name"},
+		},
+		"required": []string{"city"},
+	},
+}
+
+// ToolOptions is the prompt used by the tool tests; it requires a call to
+// WeatherTool
+func ToolOptions() *provider.GenerateOptions {
+	maxTokens := 256
+	return &provider.GenerateOptions{
+		Prompt: types.Prompt{
+			Messages: []types.Message{{
+				Role:    types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: "What is the weather in Paris?"}},
+			}},
+		},
+		Tools:      []types.Tool{WeatherTool},
+		ToolChoice: types.ToolChoice{Type: types.ToolChoiceTool, ToolName: WeatherTool.Name},
+		MaxTokens:  &maxTokens,
+	}
+}
+
+// streamError returns the error from DoStream, or from reading the stream
+// when DoStream succeeded
+func streamError(model provider.LanguageModel, ctx context.Context) error {
+	stream, err := model.DoStream(ctx, TextOptions())
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = CheckStream(stream)
+	return err
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,154 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// conformantModel is a minimal model that honors the contract
+func conformantModel(t *testing.T) provider.LanguageModel {
+	usage := types.Usage{InputTokens: int64Ptr(5), OutputTokens: int64Ptr(2), TotalTokens: int64Ptr(7)}
+	return &testutil.MockLanguageModel{
+		ProviderName: "fake",
+		ModelName:    "fake-1",
+		ToolSupport:  true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, providererrors.NewProviderError("fake", 0, "", err.Error(), err)
+			}
+			if len(opts.Tools) > 0 {
+				return &types.GenerateResult{
+					ToolCalls:    []types.ToolCall{{ID: "c1", ToolName: "get_weather", Arguments: map[string]interface{}{"city": "Paris"}}},
+					FinishReason: types.FinishReasonToolCalls,
+					Usage:        usage,
+				}, nil
+			}
+			return &types.GenerateResult{Text: "Hello!", FinishReason: types.FinishReasonStop, Usage: usage}, nil
+		},
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			chunks := []provider.StreamChunk{
+				{Type: provider.ChunkTypeTextStart, ID: "0"},
+				{Type: provider.ChunkTypeText, ID: "0", Text: "Hello!"},
+				{Type: provider.ChunkTypeTextEnd, ID: "0"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop, Usage: &usage},
+			}
+			if len(opts.Tools) > 0 {
+				chunks = []provider.StreamChunk{
+					{Type: provider.ChunkTypeToolCall, ToolCall: &types.ToolCall{ID: "c1", ToolName: "get_weather", Arguments: map[string]interface{}{"city": "Paris"}}},
+					{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonToolCalls},
+				}
+			}
+			return testutil.NewMockTextStream(chunks), nil
+		},
+	}
+}
+
+func failingModel(t *testing.T, status int) provider.LanguageModel {
+	err := providererrors.NewProviderError("fake", status, "", fmt.Sprintf("HTTP %d", status), nil)
+	return &testutil.MockLanguageModel{
+		ProviderName: "fake",
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, err
+		},
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return nil, err
+		},
+	}
+}
+
+func TestRunConformance_ConformantModel(t *testing.T) {
+	RunConformance(t, Config{NewModel: conformantModel, NewFailingModel: failingModel})
+}
+
+func TestCheckStream_Problems(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		chunks []provider.StreamChunk
+		want   string
+	}{
+		{
+			name:   "no finish",
+			chunks: []provider.StreamChunk{{Type: provider.ChunkTypeText, Text: "hi"}},
+			want:   "0 finish chunks",
+		},
+		{
+			name: "chunk after finish",
+			chunks: []provider.StreamChunk{
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+				{Type: provider.ChunkTypeText, Text: "late"},
+			},
+			want: "text chunk after the finish chunk",
+		},
+		{
+			name: "unbalanced block",
+			chunks: []provider.StreamChunk{
+				{Type: provider.ChunkTypeTextStart, ID: "a"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			},
+			want: "never ended",
+		},
+		{
+			name:   "unknown finish reason",
+			chunks: []provider.StreamChunk{{Type: provider.ChunkTypeFinish, FinishReason: "end_turn"}},
+			want:   "not a types.FinishReason constant",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := CheckStream(testutil.NewMockTextStream(tt.chunks))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckStream error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	t.Parallel()
+
+	if err := CheckError(providererrors.NewProviderError("p", 0, "", "boom", nil), 500); err != nil {
+		t.Errorf("status 0 should be accepted: %v", err)
+	}
+	if err := CheckError(fmt.Errorf("wrapped: %w", providererrors.NewProviderError("p", 404, "", "", nil)), 404); err != nil {
+		t.Errorf("wrapped provider error should be accepted: %v", err)
+	}
+	if err := CheckError(providererrors.NewRateLimitError("p", "slow down", nil, nil), 429); err != nil {
+		t.Errorf("rate limit error should be accepted for 429: %v", err)
+	}
+	if err := CheckError(providererrors.NewProviderError("p", 500, "", "", nil), 404); err == nil {
+		t.Error("mismatched status code should be rejected")
+	}
+	if err := CheckError(errors.New("HTTP 500"), 500); err == nil {
+		t.Error("plain error should be rejected")
+	}
+	if err := CheckError(nil, 500); err == nil {
+		t.Error("missing error should be rejected")
+	}
+}
+
+func TestCheckToolCalls(t *testing.T) {
+	t.Parallel()
+
+	err := CheckToolCalls([]types.ToolCall{{ToolName: "get_weather", Arguments: map[string]interface{}{}}}, types.FinishReasonStop)
+	if err == nil {
+		t.Fatal("expected problems")
+	}
+	for _, want := range []string{"no ID", "no \"city\"", "FinishReason"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
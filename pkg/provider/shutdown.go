@@ -0,0 +1,22 @@
+package provider
+
+import "context"
+
+// Shutdowner is implemented by providers that hold resources needing a
+// graceful shutdown, e.g. providers wrapped with middleware.Drainer that
+// wait for in-flight generations and streams before returning.
+type Shutdowner interface {
+	// Shutdown stops accepting new work and waits for in-flight work to
+	// finish, cancelling it once ctx expires
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown shuts p down if it implements Shutdowner. Providers without
+// shutdown support hold nothing to drain, so it returns nil for them.
+func Shutdown(ctx context.Context, p Provider) error {
+	s, ok := p.(Shutdowner)
+	if !ok {
+		return nil
+	}
+	return s.Shutdown(ctx)
+}
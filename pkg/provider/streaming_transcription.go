@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// StreamingTranscriptionModel transcribes live audio over a realtime
+// connection, emitting interim and final transcripts as the speaker talks
+type StreamingTranscriptionModel interface {
+	// Metadata
+	SpecificationVersion() string
+	Provider() string
+	ModelID() string
+
+	// DoStreamTranscription opens a session. Audio is sent with
+	// TranscriptionSession.SendAudio and transcripts read with Next.
+	DoStreamTranscription(ctx context.Context, opts *StreamingTranscriptionOptions) (TranscriptionSession, error)
+}
+
+// StreamingTranscriptionOptions describes the audio sent to a session
+type StreamingTranscriptionOptions struct {
+	// Encoding of the raw audio frames: "pcm16" (signed 16-bit little-endian,
+	// the default) or "mulaw"
+	Encoding string
+
+	// SampleRate in Hz (default: 16000)
+	SampleRate int
+
+	// Channels in the audio (default: 1)
+	Channels int
+
+	// Language hint (optional)
+	Language string
+
+	// InterimResults requests partial transcripts while a phrase is spoken
+	InterimResults bool
+
+	// ProviderOptions holds provider-specific options keyed by provider name
+	ProviderOptions map[string]interface{}
+}
+
+// TranscriptionSession is a live transcription connection. SendAudio may be
+// called concurrently with Next.
+type TranscriptionSession interface {
+	// SendAudio sends one frame of raw audio
+	SendAudio(frame []byte) error
+
+	// Next returns the next transcript event, or io.EOF once the session has
+	// ended after CloseSend
+	Next() (*types.TranscriptEvent, error)
+
+	// CloseSend signals the end of the audio. Remaining transcripts are
+	// still delivered through Next.
+	CloseSend() error
+
+	// Close terminates the session immediately
+	Close() error
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoTimestamps is returned when captions are requested for a
+// transcription without timestamps. Request them with
+// TranscriptionOptions.Timestamps.
+var ErrNoTimestamps = errors.New("transcription has no timestamps")
+
+// CaptionOptions controls how a transcription is split into caption cues
+type CaptionOptions struct {
+	// MaxLineLength is the maximum number of characters per line (default: 42)
+	MaxLineLength int
+
+	// MaxLines is the maximum number of lines per cue (default: 2)
+	MaxLines int
+
+	// MaxDuration is the longest a cue stays on screen (default: 7s)
+	MaxDuration time.Duration
+
+	// MinDuration extends short cues so they can be read, without
+	// overlapping the next cue (default: 1s)
+	MinDuration time.Duration
+
+	// MaxGap starts a new cue when the pause between words is longer
+	// (default: 1.5s)
+	MaxGap time.Duration
+
+	// KeepSentences disables starting a new cue after sentence-ending
+	// punctuation
+	KeepSentences bool
+}
+
+// CaptionCue is one caption shown between Start and End (in seconds)
+type CaptionCue struct {
+	Start float64
+	End   float64
+
+	// Lines of the cue, each within MaxLineLength where possible
+	Lines []string
+}
+
+// Text returns the cue's lines joined by newlines
+func (c CaptionCue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+// Cues splits the transcription into caption cues. Word timestamps are
+// used directly; segment timestamps are split into words with times
+// interpolated by character count.
+func (r *TranscriptionResult) Cues(opts CaptionOptions) ([]CaptionCue, error) {
+	if len(r.Timestamps) == 0 {
+		return nil, ErrNoTimestamps
+	}
+	opts = opts.withDefaults()
+
+	var cues []CaptionCue
+	var words []TranscriptionTimestamp
+	flush := func() {
+		if len(words) == 0 {
+			return
+		}
+		cues = append(cues, CaptionCue{
+			Start: words[0].Start,
+			End:   words[len(words)-1].End,
+			Lines: wrapCaption(words, opts.MaxLineLength),
+		})
+		words = nil
+	}
+
+	for _, w := range captionWords(r.Timestamps) {
+		if len(words) > 0 {
+			first, last := words[0], words[len(words)-1]
+			candidate := append(words[:len(words):len(words)], w)
+			switch {
+			case seconds(w.Start-last.End) > opts.MaxGap,
+				seconds(w.End-first.Start) > opts.MaxDuration,
+				len(wrapCaption(candidate, opts.MaxLineLength)) > opts.MaxLines,
+				!opts.KeepSentences && endsSentence(last.Text):
+				flush()
+			}
+		}
+		words = append(words, w)
+	}
+	flush()
+
+	// Give short cues time to be read, but never overlap the next cue
+	for i := range cues {
+		end := cues[i].Start + opts.MinDuration.Seconds()
+		if i+1 < len(cues) {
+			end = min(end, cues[i+1].Start)
+		}
+		cues[i].End = max(cues[i].End, end)
+	}
+	return cues, nil
+}
+
+// SRT renders the transcription as SubRip subtitles
+func (r *TranscriptionResult) SRT(opts CaptionOptions) (string, error) {
+	cues, err := r.Cues(opts)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, c := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, captionTime(c.Start, ','), captionTime(c.End, ','), c.Text())
+	}
+	return b.String(), nil
+}
+
+// VTT renders the transcription as WebVTT captions
+func (r *TranscriptionResult) VTT(opts CaptionOptions) (string, error) {
+	cues, err := r.Cues(opts)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		// "-->" may not appear in cue text
+		text := strings.ReplaceAll(c.Text(), "-->", "->")
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", captionTime(c.Start, '.'), captionTime(c.End, '.'), text)
+	}
+	return b.String(), nil
+}
+
+func (o CaptionOptions) withDefaults() CaptionOptions {
+	if o.MaxLineLength <= 0 {
+		o.MaxLineLength = 42
+	}
+	if o.MaxLines <= 0 {
+		o.MaxLines = 2
+	}
+	if o.MaxDuration <= 0 {
+		o.MaxDuration = 7 * time.Second
+	}
+	if o.MinDuration <= 0 {
+		o.MinDuration = time.Second
+	}
+	if o.MaxGap <= 0 {
+		o.MaxGap = 1500 * time.Millisecond
+	}
+	return o
+}
+
+// captionWords expands multi-word timestamps into single words, sharing the
+// timestamp's duration in proportion to word length
+func captionWords(timestamps []TranscriptionTimestamp) []TranscriptionTimestamp {
+	var words []TranscriptionTimestamp
+	for _, ts := range timestamps {
+		fields := strings.Fields(ts.Text)
+		if len(fields) <= 1 {
+			if len(fields) == 1 {
+				words = append(words, TranscriptionTimestamp{Text: fields[0], Start: ts.Start, End: ts.End})
+			}
+			continue
+		}
+		total := 0
+		for _, f := range fields {
+			total += utf8.RuneCountInString(f)
+		}
+		start, chars := ts.Start, 0
+		for _, f := range fields {
+			chars += utf8.RuneCountInString(f)
+			end := ts.Start + (ts.End-ts.Start)*float64(chars)/float64(total)
+			words = append(words, TranscriptionTimestamp{Text: f, Start: start, End: end})
+			start = end
+		}
+	}
+	return words
+}
+
+// wrapCaption fills lines greedily up to maxLen characters. A word longer
+// than maxLen gets a line of its own.
+func wrapCaption(words []TranscriptionTimestamp, maxLen int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, w := range words {
+		if line.Len() > 0 && utf8.RuneCountInString(line.String())+1+utf8.RuneCountInString(w.Text) > maxLen {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(w.Text)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+func endsSentence(word string) bool {
+	word = strings.TrimRight(word, `"')]»”’`)
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") || strings.HasSuffix(word, "!")
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// captionTime formats seconds as HH:MM:SS followed by sep and milliseconds
+func captionTime(s float64, sep byte) string {
+	ms := int64(s*1000 + 0.5)
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTranscriptionResult_SRT(t *testing.T) {
+	t.Parallel()
+
+	r := &TranscriptionResult{Timestamps: []TranscriptionTimestamp{
+		{Text: "Hello", Start: 0, End: 0.4},
+		{Text: "world.", Start: 0.5, End: 0.9},
+		{Text: "This", Start: 1.0, End: 1.2},
+		{Text: "is", Start: 1.2, End: 1.3},
+		{Text: "a", Start: 1.3, End: 1.4},
+		{Text: "test.", Start: 1.4, End: 1.8},
+	}}
+
+	got, err := r.SRT(CaptionOptions{})
+	if err != nil {
+		t.Fatalf("SRT failed: %v", err)
+	}
+	want := "1\n00:00:00,000 --> 00:00:01,000\nHello world.\n\n" +
+		"2\n00:00:01,000 --> 00:00:02,000\nThis is a test.\n\n"
+	if got != want {
+		t.Errorf("SRT =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTranscriptionResult_VTT(t *testing.T) {
+	t.Parallel()
+
+	r := &TranscriptionResult{Timestamps: []TranscriptionTimestamp{
+		{Text: "A long segment that will not fit on a single caption line at all", Start: 3661, End: 3667.5},
+	}}
+
+	got, err := r.VTT(CaptionOptions{MaxLineLength: 20, MaxLines: 2})
+	if err != nil {
+		t.Fatalf("VTT failed: %v", err)
+	}
+	cues, _ := r.Cues(CaptionOptions{MaxLineLength: 20, MaxLines: 2})
+	if len(cues) != 2 {
+		t.Fatalf("got %d cues, want 2: %+v", len(cues), cues)
+	}
+	if want := []string{"A long segment that", "will not fit on a"}; !reflect.DeepEqual(cues[0].Lines, want) {
+		t.Errorf("first cue lines = %q, want %q", cues[0].Lines, want)
+	}
+	if cues[0].End != cues[1].Start {
+		t.Errorf("interpolated cues should be contiguous: %v, %v", cues[0].End, cues[1].Start)
+	}
+	if prefix := "WEBVTT\n\n01:01:01.000 --> "; got[:len(prefix)] != prefix {
+		t.Errorf("VTT = %q", got)
+	}
+}
+
+func TestTranscriptionResult_CuesSplitOnGapAndDuration(t *testing.T) {
+	t.Parallel()
+
+	r := &TranscriptionResult{Timestamps: []TranscriptionTimestamp{
+		{Text: "one", Start: 0, End: 0.5},
+		{Text: "two", Start: 3, End: 3.5},
+		{Text: "three", Start: 3.5, End: 6},
+		{Text: "four", Start: 6, End: 9},
+	}}
+
+	cues, err := r.Cues(CaptionOptions{MaxDuration: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Cues failed: %v", err)
+	}
+	var texts []string
+	for _, c := range cues {
+		texts = append(texts, c.Text())
+	}
+	if want := []string{"one", "two three", "four"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("cues = %q, want %q", texts, want)
+	}
+}
+
+func TestTranscriptionResult_CuesWithoutTimestamps(t *testing.T) {
+	t.Parallel()
+
+	_, err := (&TranscriptionResult{Text: "hi"}).SRT(CaptionOptions{})
+	if !errors.Is(err, ErrNoTimestamps) {
+		t.Errorf("err = %v, want ErrNoTimestamps", err)
+	}
+}
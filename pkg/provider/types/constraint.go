@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// ConstraintKind identifies the kind of decoding constraint
+type ConstraintKind string
+
+const (
+	// ConstraintRegex constrains output to match a regular expression
+	ConstraintRegex ConstraintKind = "regex"
+
+	// ConstraintGrammar constrains output to a context-free grammar.
+	// The grammar syntax is backend-specific (GBNF for llama.cpp,
+	// EBNF/Lark for vLLM).
+	ConstraintGrammar ConstraintKind = "grammar"
+
+	// ConstraintJSONSchema constrains output to JSON matching a schema
+	ConstraintJSONSchema ConstraintKind = "json-schema"
+)
+
+// Constraint describes a grammar, regex or JSON schema that generated text
+// must conform to. Providers with constrained decoding (vLLM guided decoding,
+// llama.cpp grammars) enforce it natively while sampling; for other providers
+// the SDK validates the output after generation.
+type Constraint struct {
+	// Kind of constraint
+	Kind ConstraintKind `json:"kind"`
+
+	// Pattern is the regular expression for ConstraintRegex.
+	// The whole output must match; anchors are added automatically.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Grammar is the grammar source for ConstraintGrammar
+	Grammar string `json:"grammar,omitempty"`
+
+	// Schema is the JSON Schema for ConstraintJSONSchema
+	Schema map[string]interface{} `json:"schema,omitempty"`
+}
+
+// RegexConstraint returns a constraint requiring output to match pattern
+func RegexConstraint(pattern string) *Constraint {
+	return &Constraint{Kind: ConstraintRegex, Pattern: pattern}
+}
+
+// GrammarConstraint returns a constraint requiring output to follow grammar
+func GrammarConstraint(grammar string) *Constraint {
+	return &Constraint{Kind: ConstraintGrammar, Grammar: grammar}
+}
+
+// JSONSchemaConstraint returns a constraint requiring output to be JSON matching schema
+func JSONSchemaConstraint(schema map[string]interface{}) *Constraint {
+	return &Constraint{Kind: ConstraintJSONSchema, Schema: schema}
+}
+
+// CanValidate reports whether the constraint can be checked after generation.
+// Grammar constraints cannot, because grammar dialects are backend-specific.
+func (c *Constraint) CanValidate() bool {
+	return c.Kind == ConstraintRegex || c.Kind == ConstraintJSONSchema
+}
+
+// Validate checks text against the constraint.
+// For ConstraintJSONSchema only well-formedness is checked here; callers
+// with access to a schema validator should validate the decoded value too.
+func (c *Constraint) Validate(text string) error {
+	switch c.Kind {
+	case ConstraintRegex:
+		re, err := regexp.Compile(`^(?:` + c.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid regex constraint: %w", err)
+		}
+		if !re.MatchString(text) {
+			return &ConstraintViolationError{Kind: c.Kind, Text: text, Message: "output does not match pattern " + c.Pattern}
+		}
+		return nil
+	case ConstraintJSONSchema:
+		if !json.Valid([]byte(text)) {
+			return &ConstraintViolationError{Kind: c.Kind, Text: text, Message: "output is not valid JSON"}
+		}
+		return nil
+	case ConstraintGrammar:
+		return fmt.Errorf("grammar constraints cannot be validated after generation")
+	default:
+		return fmt.Errorf("unknown constraint kind: %s", c.Kind)
+	}
+}
+
+// ConstraintViolationError indicates that generated text does not satisfy
+// a Constraint that the provider could not enforce natively
+type ConstraintViolationError struct {
+	// Kind of constraint that was violated
+	Kind ConstraintKind
+
+	// Text is the generated output
+	Text string
+
+	// Message describes the violation
+	Message string
+}
+
+// Error implements the error interface
+func (e *ConstraintViolationError) Error() string {
+	return fmt.Sprintf("%s constraint violated: %s", e.Kind, e.Message)
+}
@@ -1,6 +1,11 @@
 package types
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
 
 // MissingToolResultError indicates that a provider did not return an expected tool result
 // This is used specifically for provider-executed (deferrable) tools where the provider
@@ -71,3 +76,132 @@ func (e *ToolExecutionError) Error() string {
 func (e *ToolExecutionError) Unwrap() error {
 	return e.Err
 }
+
+// ToolErrorCode classifies a tool failure for the model
+type ToolErrorCode string
+
+const (
+	// ToolErrorInvalidInput means the arguments were wrong; the model should
+	// fix them and call again
+	ToolErrorInvalidInput ToolErrorCode = "invalid_input"
+
+	// ToolErrorNotFound means the requested resource does not exist
+	ToolErrorNotFound ToolErrorCode = "not_found"
+
+	// ToolErrorPermissionDenied means the caller may not perform the action
+	ToolErrorPermissionDenied ToolErrorCode = "permission_denied"
+
+	// ToolErrorRateLimited means the tool is throttled; retrying later may
+	// succeed
+	ToolErrorRateLimited ToolErrorCode = "rate_limited"
+
+	// ToolErrorUnavailable means a dependency is down; retrying later may
+	// succeed
+	ToolErrorUnavailable ToolErrorCode = "unavailable"
+
+	// ToolErrorTimeout means the tool did not finish in time
+	ToolErrorTimeout ToolErrorCode = "timeout"
+
+	// ToolErrorCanceled means execution was canceled
+	ToolErrorCanceled ToolErrorCode = "canceled"
+
+	// ToolErrorUnknownTool means the model called a tool that does not exist
+	ToolErrorUnknownTool ToolErrorCode = "unknown_tool"
+
+	// ToolErrorInternal is any other failure
+	ToolErrorInternal ToolErrorCode = "internal"
+)
+
+// ToolError is a tool failure reported to the model. Tools return it from
+// Execute to control what the model sees: Code, Message and Retryable are
+// sent so the model can correct its call or try something else, while Detail
+// and Cause stay in logs and callbacks.
+//
+// Example:
+//
+//	if order == nil {
+//		return nil, &types.ToolError{
+//			Code:    types.ToolErrorNotFound,
+//			Message: "No order with that ID. Ask the user to check the order number.",
+//			Detail:  fmt.Sprintf("orders.Get(%q): no rows", id),
+//		}
+//	}
+type ToolError struct {
+	// Code classifies the failure
+	Code ToolErrorCode
+
+	// Message is shown to the model and should say how to recover
+	Message string
+
+	// Retryable reports whether the same call may succeed if repeated
+	Retryable bool
+
+	// Detail is internal context that is not sent to the model
+	Detail string
+
+	// Cause is the underlying error, not sent to the model
+	Cause error
+}
+
+// Error implements the error interface
+func (e *ToolError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = string(e.Code)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Cause != nil && e.Cause.Error() != e.Message {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap allows errors.Unwrap to access the underlying error
+func (e *ToolError) Unwrap() error {
+	return e.Cause
+}
+
+// ModelText returns the JSON error report sent to the model, e.g.
+// {"error":{"code":"not_found","message":"...","retryable":false}}
+func (e *ToolError) ModelText() string {
+	report := struct {
+		Error struct {
+			Code      ToolErrorCode `json:"code"`
+			Message   string        `json:"message"`
+			Retryable bool          `json:"retryable"`
+		} `json:"error"`
+	}{}
+	report.Error.Code = e.Code
+	report.Error.Message = e.Message
+	report.Error.Retryable = e.Retryable
+	data, _ := json.Marshal(report)
+	return string(data)
+}
+
+// AsToolError classifies err for the model. A *ToolError anywhere in err's
+// chain is returned as-is; context deadlines and cancellations become
+// timeout and canceled errors; any other error is internal, with its text as
+// the message. Returns nil for a nil error.
+func AsToolError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		if toolErr.Code == "" {
+			copied := *toolErr
+			copied.Code = ToolErrorInternal
+			return &copied
+		}
+		return toolErr
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &ToolError{Code: ToolErrorTimeout, Message: "The tool did not finish in time.", Retryable: true, Cause: err}
+	case errors.Is(err, context.Canceled):
+		return &ToolError{Code: ToolErrorCanceled, Message: "The tool call was canceled.", Cause: err}
+	}
+	return &ToolError{Code: ToolErrorInternal, Message: err.Error(), Cause: err}
+}
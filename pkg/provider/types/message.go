@@ -117,6 +117,10 @@ type FileContent struct {
 
 	// Optional filename
 	Filename string `json:"filename,omitempty"`
+
+	// FileID references a file uploaded with the provider's Files API
+	// (see provider.FileManager). When set, Data is not sent.
+	FileID string `json:"fileId,omitempty"`
 }
 
 // ContentType implements ContentPart interface
@@ -176,6 +180,11 @@ type GeneratedFileContent struct {
 	// encoding/json marshals []byte as base64 and unmarshals base64 to []byte.
 	Data []byte `json:"data"`
 
+	// URL and ArtifactID locate the file in an artifact store when its data
+	// has been moved out of the result (see package artifact)
+	URL        string `json:"url,omitempty"`
+	ArtifactID string `json:"artifactId,omitempty"`
+
 	// ProviderMetadata holds optional raw JSON metadata from the provider.
 	ProviderMetadata json.RawMessage `json:"providerMetadata,omitempty"`
 }
@@ -35,6 +35,35 @@ type GenerateResult struct {
 	// ProviderMetadata holds provider-specific metadata keyed by provider name.
 	// Example: map[string]interface{}{"googleVertex": map[string]interface{}{"finishMessage": "..."}}
 	ProviderMetadata map[string]interface{} `json:"providerMetadata,omitempty"`
+
+	// Audio holds the spoken response from models that generate audio output
+	// (e.g. OpenAI gpt-4o-audio-preview with modalities ["text", "audio"]).
+	// Nil when the model produced no audio.
+	Audio *AudioOutput `json:"audio,omitempty"`
+}
+
+// AudioOutput is audio generated by a language model as part of its response.
+// In stream chunks, Data and Transcript carry incremental deltas that must be
+// concatenated; on results they hold the complete audio and transcript.
+type AudioOutput struct {
+	// ID identifies the audio response so it can be referenced in later turns
+	ID string `json:"id,omitempty"`
+
+	// Data is the decoded audio bytes
+	Data []byte `json:"data,omitempty"`
+
+	// Transcript is the text transcript of the spoken audio
+	Transcript string `json:"transcript,omitempty"`
+
+	// Voice is the voice used to synthesize the audio
+	Voice string `json:"voice,omitempty"`
+
+	// Format is the audio encoding (e.g. "wav", "mp3", "pcm16")
+	Format string `json:"format,omitempty"`
+
+	// ExpiresAt is the Unix timestamp after which the provider no longer
+	// retains the audio for multi-turn references (0 if unknown)
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
 }
 
 // EmbeddingResponse contains metadata about the HTTP response from the embedding provider.
@@ -88,6 +117,10 @@ type ImageResult struct {
 	// Optional URL if image is hosted
 	URL string `json:"url,omitempty"`
 
+	// ArtifactID identifies the image in an artifact store when its data has
+	// been moved out of the result (see package artifact)
+	ArtifactID string `json:"artifactId,omitempty"`
+
 	// Usage information
 	Usage ImageUsage `json:"usage"`
 
@@ -107,6 +140,13 @@ type SpeechResult struct {
 	// MIME type of the audio
 	MimeType string `json:"mimeType"`
 
+	// URL of the audio when it is hosted, e.g. in an artifact store
+	URL string `json:"url,omitempty"`
+
+	// ArtifactID identifies the audio in an artifact store when its data has
+	// been moved out of the result (see package artifact)
+	ArtifactID string `json:"artifactId,omitempty"`
+
 	// Usage information
 	Usage SpeechUsage `json:"usage"`
 }
@@ -161,6 +201,10 @@ type GeneratedFile struct {
 	// URL is the URL to the file (if available)
 	URL string `json:"url,omitempty"`
 
+	// ArtifactID identifies the file in an artifact store when its data has
+	// been moved out of the result (see package artifact)
+	ArtifactID string `json:"artifactId,omitempty"`
+
 	// MediaType is the MIME type of the file (e.g., "video/mp4", "image/png")
 	MediaType string `json:"mediaType"`
 }
@@ -203,4 +247,7 @@ type StepResult struct {
 	// Response messages generated in this step
 	// Contains the assistant message with any text and tool calls
 	ResponseMessages []Message `json:"responseMessages,omitempty"`
+
+	// DurationMs is how long the model call took, in milliseconds
+	DurationMs int64 `json:"durationMs,omitempty"`
 }
@@ -31,6 +31,17 @@ type Tool struct {
 	// If nil, the raw result will be used
 	ToModelOutput ToModelOutputFunc `json:"-"`
 
+	// OutputSchema is an optional JSON Schema describing the tool's result.
+	// When OutputFormat.DescribeOutput is set it is appended to the tool
+	// description so the model knows how to read results.
+	OutputSchema interface{} `json:"outputSchema,omitempty"`
+
+	// OutputFormat controls how results are serialized before they are sent
+	// back to the model. When OutputFormat or OutputSchema is set, results are
+	// rendered as compact JSON with sorted keys; see ToolOutputFormat.
+	// Ignored when ToModelOutput is set.
+	OutputFormat *ToolOutputFormat `json:"-"`
+
 	// InputExamples provides example inputs to help guide the LLM
 	// These examples can improve the model's ability to use the tool correctly
 	InputExamples []ToolInputExample `json:"inputExamples,omitempty"`
@@ -88,6 +99,28 @@ type Tool struct {
 	OnInputAvailable OnInputAvailableFunc `json:"-"`
 }
 
+// ToolOutputFormat configures the serialization of tool results for the model
+type ToolOutputFormat struct {
+	// MaxLength truncates the serialized result to this many characters
+	// (0 = no limit)
+	MaxLength int
+
+	// MaxStringLength truncates individual string values (0 = no limit)
+	MaxStringLength int
+
+	// MaxArrayItems keeps only the first N items of arrays, followed by a
+	// marker with the number of omitted items (0 = no limit)
+	MaxArrayItems int
+
+	// Units maps dotted field paths to units. Numbers at those paths are
+	// rendered with the unit appended, e.g. {"temp": "21.5 °C"}. Array
+	// elements share their array's path ("readings.temp").
+	Units map[string]string
+
+	// DescribeOutput appends OutputSchema to the tool description
+	DescribeOutput bool
+}
+
 // ToolExecutor is a function that executes a tool
 // It receives the input arguments and returns the result or an error
 // Updated in v6.0 to include options with ToolCallID
@@ -210,6 +243,10 @@ type ToolResult struct {
 	// When false or unset, the tool was executed locally by the client
 	// This affects error handling and validation behavior
 	ProviderExecuted bool `json:"providerExecuted,omitempty"`
+
+	// DurationMs is how long local execution took, in milliseconds.
+	// Zero for provider-executed tools.
+	DurationMs int64 `json:"durationMs,omitempty"`
 }
 
 // ToolChoice specifies how the model should choose tools
@@ -0,0 +1,41 @@
+package types
+
+// TranscriptEventType identifies a streaming transcription event
+type TranscriptEventType string
+
+const (
+	// TranscriptEventInterim is a partial transcript that may still change
+	TranscriptEventInterim TranscriptEventType = "interim"
+
+	// TranscriptEventFinal is a transcript segment that will not change
+	TranscriptEventFinal TranscriptEventType = "final"
+
+	// TranscriptEventSpeechStarted reports that voice activity began
+	TranscriptEventSpeechStarted TranscriptEventType = "speech-started"
+
+	// TranscriptEventUtteranceEnd reports the end of an utterance after
+	// silence, without new text
+	TranscriptEventUtteranceEnd TranscriptEventType = "utterance-end"
+)
+
+// TranscriptEvent is emitted by a streaming transcription session
+type TranscriptEvent struct {
+	Type TranscriptEventType `json:"type"`
+
+	// Text of an interim or final transcript. Interim text replaces the
+	// previous interim text of the same segment.
+	Text string `json:"text,omitempty"`
+
+	// EndOfUtterance marks the final segment that ends what the speaker said
+	EndOfUtterance bool `json:"endOfUtterance,omitempty"`
+
+	// Start and End are offsets in seconds from the start of the audio
+	Start float64 `json:"start,omitempty"`
+	End   float64 `json:"end,omitempty"`
+
+	// Confidence of the transcript, when reported (0-1)
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Words carries word-level timings when the provider reports them
+	Words []TranscriptionTimestamp `json:"words,omitempty"`
+}
@@ -176,6 +176,10 @@ type EmbeddingUsage struct {
 
 	// Total tokens
 	TotalTokens int `json:"totalTokens"`
+
+	// CachedInputs is the number of inputs served from an embedding cache
+	// without a provider call; their tokens are not counted above
+	CachedInputs int `json:"cachedInputs,omitempty"`
 }
 
 // ImageUsage represents usage for image generation operations
@@ -0,0 +1,134 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
+)
+
+// MaxFileSize is the largest file the Anthropic Files API accepts
+const MaxFileSize = 500 << 20
+
+// anthropicFile is a file object of the Files API
+type anthropicFile struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename"`
+	MimeType  string    `json:"mime_type"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (f anthropicFile) toFile() provider.File {
+	return provider.File{
+		ID:        f.ID,
+		Filename:  f.Filename,
+		MimeType:  f.MimeType,
+		Size:      f.SizeBytes,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+// UploadFile uploads a file to the Files API (beta). Purpose is ignored.
+func (p *Provider) UploadFile(ctx context.Context, opts provider.FileUploadOptions) (*provider.File, error) {
+	body, contentType, err := providerutils.MultipartFileUpload(opts, MaxFileSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	var f anthropicFile
+	if err := p.doFiles(ctx, internalhttp.Request{
+		Method:  "POST",
+		Path:    "/v1/files",
+		RawBody: body,
+		Headers: map[string]string{"Content-Type": contentType},
+	}, &f); err != nil {
+		return nil, err
+	}
+	file := f.toFile()
+	return &file, nil
+}
+
+// ListFiles returns the workspace's files, following pagination
+func (p *Provider) ListFiles(ctx context.Context) ([]provider.File, error) {
+	var files []provider.File
+	query := map[string]string{"limit": "1000"}
+	for {
+		var page struct {
+			Data    []anthropicFile `json:"data"`
+			HasMore bool            `json:"has_more"`
+			LastID  string          `json:"last_id"`
+		}
+		if err := p.doFiles(ctx, internalhttp.Request{Method: "GET", Path: "/v1/files", Query: query}, &page); err != nil {
+			return nil, err
+		}
+		for _, f := range page.Data {
+			files = append(files, f.toFile())
+		}
+		if !page.HasMore || page.LastID == "" {
+			return files, nil
+		}
+		query = map[string]string{"limit": "1000", "after_id": url.QueryEscape(page.LastID)}
+	}
+}
+
+// GetFile returns a file's metadata
+func (p *Provider) GetFile(ctx context.Context, fileID string) (*provider.File, error) {
+	var f anthropicFile
+	if err := p.doFiles(ctx, internalhttp.Request{Method: "GET", Path: "/v1/files/" + url.PathEscape(fileID)}, &f); err != nil {
+		return nil, err
+	}
+	file := f.toFile()
+	return &file, nil
+}
+
+// DeleteFile deletes a file
+func (p *Provider) DeleteFile(ctx context.Context, fileID string) error {
+	return p.doFiles(ctx, internalhttp.Request{Method: "DELETE", Path: "/v1/files/" + url.PathEscape(fileID)}, nil)
+}
+
+// doFiles performs a Files API request with the beta header, decoding the
+// response into result when it is not nil
+func (p *Provider) doFiles(ctx context.Context, req internalhttp.Request, result interface{}) error {
+	if req.Headers == nil {
+		req.Headers = map[string]string{}
+	}
+	req.Headers["anthropic-beta"] = BetaHeaderFilesAPI
+
+	resp, err := p.client.Do(ctx, req)
+	if err != nil {
+		return providererrors.NewProviderError("anthropic", 0, "", err.Error(), err)
+	}
+	if resp.StatusCode >= 400 {
+		var body struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(resp.Body, &body)
+		msg := body.Error.Message
+		if msg == "" {
+			msg = string(resp.Body)
+		}
+		var cause error
+		if resp.StatusCode == 404 {
+			cause = provider.ErrFileNotFound
+		}
+		return providererrors.NewProviderError("anthropic", resp.StatusCode, body.Error.Type, msg, cause)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, result); err != nil {
+		return fmt.Errorf("failed to decode files response: %w", err)
+	}
+	return nil
+}
+
+var _ provider.FileManager = (*Provider)(nil)
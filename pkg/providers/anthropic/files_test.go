@@ -0,0 +1,65 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func TestProvider_Files(t *testing.T) {
+	t.Parallel()
+
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("anthropic-beta"); got != BetaHeaderFilesAPI {
+			t.Errorf("anthropic-beta = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST":
+			if _, header, err := r.FormFile("file"); err != nil || header.Filename != "a.pdf" {
+				t.Errorf("unexpected upload: %v", err)
+			}
+			w.Write([]byte(`{"id":"file_1","type":"file","filename":"a.pdf","mime_type":"application/pdf","size_bytes":4,"created_at":"2025-04-14T00:00:00Z"}`))
+		case r.URL.Query().Get("after_id") == "":
+			pages++
+			w.Write([]byte(`{"data":[{"id":"file_1","filename":"a.pdf"}],"has_more":true,"last_id":"file_1"}`))
+		default:
+			pages++
+			w.Write([]byte(`{"data":[{"id":"file_2","filename":"b.pdf"}],"has_more":false,"last_id":"file_2"}`))
+		}
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "test", BaseURL: server.URL})
+	file, err := p.UploadFile(context.Background(), provider.FileUploadOptions{Data: []byte("%PDF"), Filename: "a.pdf"})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if file.ID != "file_1" || file.MimeType != "application/pdf" || file.Size != 4 || file.CreatedAt.Year() != 2025 {
+		t.Errorf("file = %+v", file)
+	}
+
+	files, err := p.ListFiles(context.Background())
+	if err != nil || len(files) != 2 || pages != 2 {
+		t.Errorf("ListFiles = %+v, %v after %d pages", files, err, pages)
+	}
+}
+
+func TestCombineBetaHeaders_FileReferences(t *testing.T) {
+	t.Parallel()
+
+	m := NewLanguageModel(New(Config{APIKey: "test"}), "claude-sonnet-4-5", nil)
+	opts := &provider.GenerateOptions{Prompt: types.Prompt{Messages: []types.Message{{
+		Role:    types.RoleUser,
+		Content: []types.ContentPart{types.FileContent{FileID: "file_1", MimeType: "application/pdf"}},
+	}}}}
+	if got := m.combineBetaHeaders(opts, false); !strings.Contains(got, BetaHeaderFilesAPI) {
+		t.Errorf("beta headers = %q, want %s", got, BetaHeaderFilesAPI)
+	}
+}
@@ -434,6 +434,11 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 		// Otherwise (empty ContainerConfig): don't add any container field
 	}
 
+	// Anthropic's request metadata only carries an end-user identifier
+	if user := opts.Metadata[provider.MetadataKeyUser]; user != "" {
+		body["metadata"] = map[string]interface{}{"user_id": user}
+	}
+
 	return body
 }
 
@@ -719,6 +724,11 @@ func (m *LanguageModel) combineBetaHeaders(opts *provider.GenerateOptions, strea
 			}
 		}
 
+		// Files API: prompts that reference uploaded files by ID
+		if !strings.Contains(base, BetaHeaderFilesAPI) && referencesUploadedFiles(opts.Prompt.Messages) {
+			needed[BetaHeaderFilesAPI] = true
+		}
+
 		// Inject in a stable order so the header value is deterministic.
 		for _, h := range []string{
 			BetaHeaderCodeExecution,
@@ -731,6 +741,7 @@ func (m *LanguageModel) combineBetaHeaders(opts *provider.GenerateOptions, strea
 			BetaHeaderComputerUse20251124,
 			BetaHeaderContextManagement,
 			BetaHeaderAdvancedToolUse,
+			BetaHeaderFilesAPI,
 		} {
 			if needed[h] {
 				if base != "" {
@@ -745,6 +756,19 @@ func (m *LanguageModel) combineBetaHeaders(opts *provider.GenerateOptions, strea
 	return base
 }
 
+// referencesUploadedFiles reports whether any message refers to a file by
+// its Files API ID
+func referencesUploadedFiles(messages []types.Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			if f, ok := part.(types.FileContent); ok && f.FileID != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // getBetaHeaders returns the comma-separated beta headers needed for context management
 func (m *LanguageModel) getBetaHeaders() string {
 	if m.options == nil {
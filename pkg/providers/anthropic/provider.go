@@ -1,10 +1,12 @@
 package anthropic
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 const (
@@ -67,6 +69,17 @@ func (p *Provider) Name() string {
 	return "anthropic"
 }
 
+// Ping checks that the API is reachable and the credentials are valid by
+// listing available models. It does not consume tokens.
+func (p *Provider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return providerutils.PingEndpoint(ctx, p.client, p.Name(), "/v1/models")
+}
+
+// ListModels lists the models available to the configured credentials.
+func (p *Provider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return providerutils.ListModelsEndpoint(ctx, p.client, p.Name(), "/v1/models", map[string]string{"limit": "1000"})
+}
+
 // LanguageModel returns a language model by ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	// Validate model ID
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 )
 
@@ -11,6 +12,7 @@ import (
 type Provider struct {
 	config Config
 	client *http.Client
+	dial   ws.Dialer
 }
 
 // Config contains configuration for the AssemblyAI provider
@@ -20,6 +22,10 @@ type Config struct {
 
 	// BaseURL is the base URL for the AssemblyAI API (optional)
 	BaseURL string
+
+	// StreamingBaseURL is the base URL for streaming transcription
+	// (default: wss://streaming.assemblyai.com)
+	StreamingBaseURL string
 }
 
 // New creates a new AssemblyAI provider with the given configuration
@@ -40,6 +46,7 @@ func New(cfg Config) *Provider {
 	return &Provider{
 		config: cfg,
 		client: client,
+		dial:   ws.Dial,
 	}
 }
 
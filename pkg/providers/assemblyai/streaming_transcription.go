@@ -0,0 +1,123 @@
+package assemblyai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DoStreamTranscription opens a session on AssemblyAI's v3 Universal
+// Streaming websocket. Options under ProviderOptions["assemblyai"] are
+// passed as query parameters (e.g. "format_turns": true,
+// "end_of_turn_confidence_threshold": 0.7).
+func (m *TranscriptionModel) DoStreamTranscription(ctx context.Context, opts *provider.StreamingTranscriptionOptions) (provider.TranscriptionSession, error) {
+	if opts == nil {
+		opts = &provider.StreamingTranscriptionOptions{}
+	}
+	sampleRate := opts.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	query := url.Values{}
+	query.Set("sample_rate", strconv.Itoa(sampleRate))
+	query.Set("encoding", "pcm_s16le")
+	if opts.Encoding == "mulaw" {
+		query.Set("encoding", "pcm_mulaw")
+	}
+	if po, ok := opts.ProviderOptions["assemblyai"].(map[string]interface{}); ok {
+		for k, v := range po {
+			query.Set(k, fmt.Sprint(v))
+		}
+	}
+
+	baseURL := m.provider.config.StreamingBaseURL
+	if baseURL == "" {
+		baseURL = "wss://streaming.assemblyai.com"
+	}
+	wsURL, err := ws.URL(baseURL, "/v3/ws", query)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Authorization", m.provider.config.APIKey)
+	conn, err := m.provider.dial(ctx, wsURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("assemblyai: %w", err)
+	}
+
+	parser := &turnParser{formatTurns: query.Get("format_turns") == "true", interim: opts.InterimResults}
+	return ws.NewTranscriptionSession(conn, parser.parse,
+		func(c ws.Conn, frame []byte) error { return c.WriteBinary(frame) },
+		func(c ws.Conn) error { return c.WriteText([]byte(`{"type":"Terminate"}`)) },
+	), nil
+}
+
+type assemblyAIStreamMessage struct {
+	Type            string `json:"type"`
+	Transcript      string `json:"transcript"`
+	EndOfTurn       bool   `json:"end_of_turn"`
+	TurnIsFormatted bool   `json:"turn_is_formatted"`
+	Words           []struct {
+		Text       string  `json:"text"`
+		Start      float64 `json:"start"`
+		End        float64 `json:"end"`
+		Confidence float64 `json:"confidence"`
+	} `json:"words"`
+	Error string `json:"error"`
+}
+
+// turnParser converts Turn messages into transcript events. With
+// format_turns, AssemblyAI sends each finished turn twice (raw, then
+// formatted); only the formatted one is reported as final.
+type turnParser struct {
+	formatTurns bool
+	interim     bool
+}
+
+func (p *turnParser) parse(data []byte) ([]types.TranscriptEvent, bool, error) {
+	var msg assemblyAIStreamMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, false, fmt.Errorf("assemblyai: invalid message: %w", err)
+	}
+	if msg.Error != "" {
+		return nil, true, fmt.Errorf("assemblyai: %s", msg.Error)
+	}
+
+	switch msg.Type {
+	case "Turn":
+		final := msg.EndOfTurn && (!p.formatTurns || msg.TurnIsFormatted)
+		if !final && (msg.EndOfTurn || !p.interim || msg.Transcript == "") {
+			return nil, false, nil
+		}
+		event := types.TranscriptEvent{Type: types.TranscriptEventInterim, Text: msg.Transcript}
+		if final {
+			event.Type = types.TranscriptEventFinal
+			event.EndOfUtterance = true
+		}
+		var confidence float64
+		for _, w := range msg.Words {
+			// Word timings are reported in milliseconds
+			event.Words = append(event.Words, types.TranscriptionTimestamp{Text: w.Text, Start: w.Start / 1000, End: w.End / 1000})
+			confidence += w.Confidence
+		}
+		if n := len(msg.Words); n > 0 {
+			event.Start = event.Words[0].Start
+			event.End = event.Words[n-1].End
+			event.Confidence = confidence / float64(n)
+		}
+		if final && msg.Transcript == "" {
+			return []types.TranscriptEvent{{Type: types.TranscriptEventUtteranceEnd, End: event.End}}, false, nil
+		}
+		return []types.TranscriptEvent{event}, false, nil
+	case "Termination":
+		return nil, true, nil
+	}
+	return nil, false, nil
+}
@@ -111,6 +111,10 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 	body := map[string]interface{}{
 		"stream": stream,
 	}
+	if stream {
+		// Report usage in the final stream event
+		body["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
 
 	// Convert messages
 	if opts.Prompt.IsMessages() {
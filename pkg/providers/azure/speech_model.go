@@ -7,6 +7,7 @@ import (
 	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 // SpeechModel implements the provider.SpeechModel interface for Azure OpenAI
@@ -59,7 +60,7 @@ func (m *SpeechModel) DoGenerate(ctx context.Context, opts *provider.SpeechGener
 	// The response is the raw audio bytes
 	return &types.SpeechResult{
 		Audio:    resp.Body,
-		MimeType: "audio/mpeg",
+		MimeType: providerutils.SpeechFormatMimeType(opts.OutputFormat),
 	}, nil
 }
 
@@ -80,6 +81,9 @@ func (m *SpeechModel) buildRequestBody(opts *provider.SpeechGenerateOptions) map
 
 	// Default to mp3 format
 	reqBody["response_format"] = "mp3"
+	if opts.OutputFormat != "" {
+		reqBody["response_format"] = opts.OutputFormat
+	}
 
 	return reqBody
 }
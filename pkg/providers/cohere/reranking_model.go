@@ -2,8 +2,11 @@ package cohere
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"time"
 
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
 	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
@@ -45,7 +48,12 @@ func (m *RerankingModel) DoRerank(ctx context.Context, opts *provider.RerankOpti
 
 	// Make API request
 	var response cohereRerankResponse
-	err := m.provider.client.PostJSON(ctx, "/rerank", reqBody, &response)
+	err := m.provider.client.DoJSON(ctx, internalhttp.Request{
+		Method:  http.MethodPost,
+		Path:    "/rerank",
+		Body:    reqBody,
+		Headers: opts.Headers,
+	}, &response)
 	if err != nil {
 		return nil, m.handleError(err)
 	}
@@ -78,9 +86,46 @@ func (m *RerankingModel) buildRequestBody(opts *provider.RerankOptions) map[stri
 		body["top_n"] = *opts.TopN
 	}
 
+	cohereOpts := rerankProviderOptions(opts)
+	if cohereOpts.MaxChunksPerDoc != nil {
+		body["max_chunks_per_doc"] = *cohereOpts.MaxChunksPerDoc
+	}
+	if cohereOpts.ReturnDocuments != nil {
+		body["return_documents"] = *cohereOpts.ReturnDocuments
+	}
+	if len(cohereOpts.RankFields) > 0 {
+		body["rank_fields"] = cohereOpts.RankFields
+	}
+
 	return body
 }
 
+// RerankProviderOptions contains Cohere-specific rerank options, passed as
+// ProviderOptions["cohere"]
+type RerankProviderOptions struct {
+	// MaxChunksPerDoc is the maximum number of chunks a long document is
+	// split into; the document scores as its best chunk
+	MaxChunksPerDoc *int `json:"maxChunksPerDoc,omitempty"`
+
+	// ReturnDocuments includes the documents in the response, surfaced as
+	// the "documents" provider metadata
+	ReturnDocuments *bool `json:"returnDocuments,omitempty"`
+
+	// RankFields selects the fields of structured documents to rank on
+	RankFields []string `json:"rankFields,omitempty"`
+}
+
+// rerankProviderOptions extracts the Cohere-specific provider options
+func rerankProviderOptions(opts *provider.RerankOptions) RerankProviderOptions {
+	var cohereOpts RerankProviderOptions
+	if raw, ok := opts.ProviderOptions["cohere"]; ok {
+		if jsonData, err := json.Marshal(raw); err == nil {
+			json.Unmarshal(jsonData, &cohereOpts) //nolint:errcheck
+		}
+	}
+	return cohereOpts
+}
+
 // convertResponse converts a Cohere response to RerankResult
 func (m *RerankingModel) convertResponse(response cohereRerankResponse) *types.RerankResult {
 	result := &types.RerankResult{
@@ -93,11 +138,20 @@ func (m *RerankingModel) convertResponse(response cohereRerankResponse) *types.R
 	}
 
 	// Convert ranking results
+	var documents []interface{}
 	for i, item := range response.Results {
 		result.Ranking[i] = types.RerankItem{
 			Index:          item.Index,
 			RelevanceScore: item.RelevanceScore,
 		}
+		if item.Document != nil {
+			documents = append(documents, item.Document)
+		}
+	}
+	if len(documents) > 0 {
+		result.ProviderMetadata = map[string]interface{}{
+			"cohere": map[string]interface{}{"documents": documents},
+		}
 	}
 
 	return result
@@ -0,0 +1,57 @@
+package cohere
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+func TestCohereRerank(t *testing.T) {
+	var body map[string]interface{}
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("path = %s, want /rerank", r.URL.Path)
+		}
+		header = r.Header.Get("X-Client-Name")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"rr-1","results":[{"index":2,"relevance_score":0.91,"document":{"text":"JWT"}},{"index":0,"relevance_score":0.12,"document":{"text":"net/http"}}]}`))
+	}))
+	defer srv.Close()
+
+	model := NewRerankingModel(New(Config{BaseURL: srv.URL, APIKey: "test-key"}), "rerank-v3.5")
+	topN := 2
+	result, err := model.DoRerank(t.Context(), &provider.RerankOptions{
+		Documents: []string{"net/http", "crypto", "JWT"},
+		Query:     "authentication",
+		TopN:      &topN,
+		Headers:   map[string]string{"X-Client-Name": "search"},
+		ProviderOptions: map[string]interface{}{
+			"cohere": map[string]interface{}{"returnDocuments": true, "maxChunksPerDoc": 4},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body["model"] != "rerank-v3.5" || body["top_n"] != float64(2) || body["return_documents"] != true || body["max_chunks_per_doc"] != float64(4) {
+		t.Errorf("unexpected request body: %v", body)
+	}
+	if header != "search" {
+		t.Errorf("header not forwarded: %q", header)
+	}
+	if len(result.Ranking) != 2 || result.Ranking[0].Index != 2 || result.Ranking[0].RelevanceScore != 0.91 {
+		t.Errorf("unexpected ranking: %+v", result.Ranking)
+	}
+	if result.Response.ID != "rr-1" {
+		t.Errorf("response ID = %q", result.Response.ID)
+	}
+	meta, _ := result.ProviderMetadata.(map[string]interface{})["cohere"].(map[string]interface{})
+	if docs, _ := meta["documents"].([]interface{}); len(docs) != 2 {
+		t.Errorf("documents metadata = %v", result.ProviderMetadata)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 )
 
@@ -11,6 +12,7 @@ import (
 type Provider struct {
 	config Config
 	client *http.Client
+	dial   ws.Dialer
 }
 
 // Config contains configuration for the Deepgram provider
@@ -40,6 +42,7 @@ func New(cfg Config) *Provider {
 	return &Provider{
 		config: cfg,
 		client: client,
+		dial:   ws.Dial,
 	}
 }
 
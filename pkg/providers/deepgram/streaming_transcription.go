@@ -0,0 +1,145 @@
+package deepgram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DoStreamTranscription opens a live transcription session on Deepgram's
+// /v1/listen websocket. Options under ProviderOptions["deepgram"] are passed
+// as query parameters (e.g. "endpointing": 300, "smart_format": true).
+func (m *TranscriptionModel) DoStreamTranscription(ctx context.Context, opts *provider.StreamingTranscriptionOptions) (provider.TranscriptionSession, error) {
+	if opts == nil {
+		opts = &provider.StreamingTranscriptionOptions{}
+	}
+	query := url.Values{}
+	query.Set("model", m.modelID)
+	query.Set("encoding", "linear16")
+	if opts.Encoding == "mulaw" {
+		query.Set("encoding", "mulaw")
+	}
+	query.Set("sample_rate", strconv.Itoa(orDefault(opts.SampleRate, 16000)))
+	query.Set("channels", strconv.Itoa(orDefault(opts.Channels, 1)))
+	query.Set("punctuate", "true")
+	query.Set("vad_events", "true")
+	if opts.InterimResults {
+		query.Set("interim_results", "true")
+		query.Set("utterance_end_ms", "1000")
+	}
+	if opts.Language != "" {
+		query.Set("language", opts.Language)
+	}
+	if po, ok := opts.ProviderOptions["deepgram"].(map[string]interface{}); ok {
+		for k, v := range po {
+			query.Set(k, fmt.Sprint(v))
+		}
+	}
+
+	baseURL := m.provider.config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.deepgram.com"
+	}
+	wsURL, err := ws.URL(baseURL, "/v1/listen", query)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Token "+m.provider.config.APIKey)
+	conn, err := m.provider.dial(ctx, wsURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("deepgram: %w", err)
+	}
+
+	return ws.NewTranscriptionSession(conn, parseDeepgramMessage,
+		func(c ws.Conn, frame []byte) error { return c.WriteBinary(frame) },
+		func(c ws.Conn) error { return c.WriteText([]byte(`{"type":"CloseStream"}`)) },
+	), nil
+}
+
+// deepgramLiveMessage covers the Results, SpeechStarted and UtteranceEnd
+// messages of the live API
+type deepgramLiveMessage struct {
+	Type        string  `json:"type"`
+	IsFinal     bool    `json:"is_final"`
+	SpeechFinal bool    `json:"speech_final"`
+	Start       float64 `json:"start"`
+	Duration    float64 `json:"duration"`
+	Timestamp   float64 `json:"timestamp"`
+	LastWordEnd float64 `json:"last_word_end"`
+	Channel     struct {
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+			Words      []struct {
+				Word           string  `json:"word"`
+				PunctuatedWord string  `json:"punctuated_word"`
+				Start          float64 `json:"start"`
+				End            float64 `json:"end"`
+			} `json:"words"`
+		} `json:"alternatives"`
+	} `json:"channel"`
+	Description string `json:"description"`
+	Message     string `json:"message"`
+}
+
+func parseDeepgramMessage(data []byte) ([]types.TranscriptEvent, bool, error) {
+	var msg deepgramLiveMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, false, fmt.Errorf("deepgram: invalid message: %w", err)
+	}
+
+	switch msg.Type {
+	case "Results":
+		if len(msg.Channel.Alternatives) == 0 {
+			return nil, false, nil
+		}
+		alt := msg.Channel.Alternatives[0]
+		if alt.Transcript == "" {
+			if msg.IsFinal && msg.SpeechFinal {
+				return []types.TranscriptEvent{{Type: types.TranscriptEventUtteranceEnd, End: msg.Start + msg.Duration}}, false, nil
+			}
+			return nil, false, nil
+		}
+		event := types.TranscriptEvent{
+			Type:           types.TranscriptEventInterim,
+			Text:           alt.Transcript,
+			EndOfUtterance: msg.IsFinal && msg.SpeechFinal,
+			Start:          msg.Start,
+			End:            msg.Start + msg.Duration,
+			Confidence:     alt.Confidence,
+		}
+		if msg.IsFinal {
+			event.Type = types.TranscriptEventFinal
+		}
+		for _, w := range alt.Words {
+			text := w.PunctuatedWord
+			if text == "" {
+				text = w.Word
+			}
+			event.Words = append(event.Words, types.TranscriptionTimestamp{Text: text, Start: w.Start, End: w.End})
+		}
+		return []types.TranscriptEvent{event}, false, nil
+	case "SpeechStarted":
+		return []types.TranscriptEvent{{Type: types.TranscriptEventSpeechStarted, Start: msg.Timestamp}}, false, nil
+	case "UtteranceEnd":
+		return []types.TranscriptEvent{{Type: types.TranscriptEventUtteranceEnd, End: msg.LastWordEnd}}, false, nil
+	case "Error":
+		return nil, true, fmt.Errorf("deepgram: %s %s", msg.Description, msg.Message)
+	}
+	return nil, false, nil
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
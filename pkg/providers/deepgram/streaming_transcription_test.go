@@ -0,0 +1,121 @@
+package deepgram
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// fakeConn replays canned messages and records what the session sends
+type fakeConn struct {
+	incoming chan []byte
+	text     [][]byte
+	binary   [][]byte
+}
+
+func (c *fakeConn) ReadMessage() ([]byte, error) {
+	msg, ok := <-c.incoming
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (c *fakeConn) WriteText(data []byte) error {
+	c.text = append(c.text, data)
+	return nil
+}
+
+func (c *fakeConn) WriteBinary(data []byte) error {
+	c.binary = append(c.binary, data)
+	return nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func TestTranscriptionModel_DoStreamTranscription(t *testing.T) {
+	t.Parallel()
+
+	conn := &fakeConn{incoming: make(chan []byte, 8)}
+	var dialURL string
+	var dialHeader http.Header
+	p := New(Config{APIKey: "dg-key"})
+	p.dial = func(ctx context.Context, rawURL string, header http.Header) (ws.Conn, error) {
+		dialURL, dialHeader = rawURL, header
+		return conn, nil
+	}
+	model := NewTranscriptionModel(p, "nova-3")
+
+	session, err := model.DoStreamTranscription(context.Background(), &provider.StreamingTranscriptionOptions{
+		SampleRate:     8000,
+		InterimResults: true,
+	})
+	if err != nil {
+		t.Fatalf("DoStreamTranscription failed: %v", err)
+	}
+	defer session.Close()
+
+	u, _ := url.Parse(dialURL)
+	q := u.Query()
+	if u.Scheme != "wss" || u.Path != "/v1/listen" || q.Get("model") != "nova-3" || q.Get("sample_rate") != "8000" || q.Get("interim_results") != "true" {
+		t.Errorf("unexpected dial URL %s", dialURL)
+	}
+	if got := dialHeader.Get("Authorization"); got != "Token dg-key" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	session.SendAudio([]byte{0, 1})
+	session.CloseSend()
+	if len(conn.binary) != 1 || string(conn.text[0]) != `{"type":"CloseStream"}` {
+		t.Errorf("unexpected writes: binary %v, text %q", conn.binary, conn.text)
+	}
+
+	conn.incoming <- []byte(`{"type":"SpeechStarted","timestamp":0.2}`)
+	conn.incoming <- []byte(`{"type":"Results","is_final":false,"start":0,"duration":1,"channel":{"alternatives":[{"transcript":"book a","confidence":0.8}]}}`)
+	conn.incoming <- []byte(`{"type":"Results","is_final":true,"speech_final":true,"start":0,"duration":1.5,"channel":{"alternatives":[{"transcript":"Book a table.","confidence":0.95,"words":[{"word":"book","punctuated_word":"Book","start":0.1,"end":0.4}]}]}}`)
+	conn.incoming <- []byte(`{"type":"UtteranceEnd","last_word_end":1.4}`)
+	close(conn.incoming)
+
+	var got []types.TranscriptEvent
+	for {
+		e, err := session.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		got = append(got, *e)
+	}
+
+	if len(got) != 4 {
+		t.Fatalf("got %d events, want 4: %+v", len(got), got)
+	}
+	if got[0].Type != types.TranscriptEventSpeechStarted || got[0].Start != 0.2 {
+		t.Errorf("event 0 = %+v", got[0])
+	}
+	if got[1].Type != types.TranscriptEventInterim || got[1].Text != "book a" || got[1].EndOfUtterance {
+		t.Errorf("event 1 = %+v", got[1])
+	}
+	if got[2].Type != types.TranscriptEventFinal || !got[2].EndOfUtterance || got[2].End != 1.5 || got[2].Words[0].Text != "Book" {
+		t.Errorf("event 2 = %+v", got[2])
+	}
+	if got[3].Type != types.TranscriptEventUtteranceEnd || got[3].End != 1.4 {
+		t.Errorf("event 3 = %+v", got[3])
+	}
+}
+
+func TestParseDeepgramMessage_Error(t *testing.T) {
+	t.Parallel()
+
+	_, done, err := parseDeepgramMessage([]byte(`{"type":"Error","description":"bad audio","message":"could not decode"}`))
+	if !done || err == nil {
+		t.Errorf("expected terminal error, got done=%v err=%v", done, err)
+	}
+}
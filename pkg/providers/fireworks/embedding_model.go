@@ -72,6 +72,9 @@ func (m *EmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts
 		"input": inputs,
 		"model": m.modelID,
 	}
+	if dims := embedDimensions(opts); dims > 0 {
+		reqBody["dimensions"] = dims
+	}
 	var response fireworksEmbedResponse
 	httpResp, err := m.provider.client.DoJSONResponse(ctx, internalhttp.Request{
 		Method:  http.MethodPost,
@@ -96,6 +99,23 @@ func (m *EmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts
 	}, nil
 }
 
+// embedDimensions returns the "dimensions" option from
+// ProviderOptions["fireworks"], for models with Matryoshka embeddings such as
+// nomic-embed-text-v1.5
+func embedDimensions(opts *provider.EmbedModelOptions) int {
+	if opts == nil {
+		return 0
+	}
+	m, _ := opts.ProviderOptions["fireworks"].(map[string]interface{})
+	switch d := m["dimensions"].(type) {
+	case int:
+		return d
+	case float64:
+		return int(d)
+	}
+	return 0
+}
+
 // optsEmbedHeaders extracts the Headers map from EmbedModelOptions (nil-safe).
 func optsEmbedHeaders(opts *provider.EmbedModelOptions) map[string]string {
 	if opts == nil {
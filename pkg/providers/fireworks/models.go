@@ -0,0 +1,111 @@
+package fireworks
+
+import (
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// modelPrefix is the account path of Fireworks' serverless models
+const modelPrefix = "accounts/fireworks/models/"
+
+// Serverless language model IDs
+const (
+	ModelLlama3p3_70BInstruct  = "accounts/fireworks/models/llama-v3p3-70b-instruct"
+	ModelLlama3p1_8BInstruct   = "accounts/fireworks/models/llama-v3p1-8b-instruct"
+	ModelLlama3p1_405BInstruct = "accounts/fireworks/models/llama-v3p1-405b-instruct"
+	ModelLlama4Maverick        = "accounts/fireworks/models/llama4-maverick-instruct-basic"
+	ModelDeepSeekV3            = "accounts/fireworks/models/deepseek-v3"
+	ModelDeepSeekR1            = "accounts/fireworks/models/deepseek-r1"
+	ModelQwen2p5_72BInstruct   = "accounts/fireworks/models/qwen2p5-72b-instruct"
+	ModelMixtral8x7BInstruct   = "accounts/fireworks/models/mixtral-8x7b-instruct"
+	ModelKimiK2Instruct        = "accounts/fireworks/models/kimi-k2-instruct"
+)
+
+// Embedding model IDs
+const (
+	ModelNomicEmbedTextV1p5 = "nomic-ai/nomic-embed-text-v1.5"
+)
+
+// ModelInfo describes a model's limits, capabilities and list prices
+type ModelInfo struct {
+	// ContextWindow is the maximum number of prompt and output tokens
+	ContextWindow int
+
+	// InputPrice and OutputPrice are USD per million tokens
+	InputPrice  float64
+	OutputPrice float64
+
+	// CachedInputPrice is USD per million cached prompt tokens; zero means
+	// cached tokens are billed at InputPrice
+	CachedInputPrice float64
+
+	// Tools reports native function calling support
+	Tools bool
+
+	// JSONMode reports JSON mode and JSON schema support
+	JSONMode bool
+
+	// ImageInput reports image input support
+	ImageInput bool
+}
+
+// Cost returns the USD cost of usage at the model's list prices
+func (i ModelInfo) Cost(usage types.Usage) float64 {
+	input := float64(usage.GetInputTokens())
+	cost := 0.0
+	if i.CachedInputPrice > 0 && usage.InputDetails != nil && usage.InputDetails.CacheReadTokens != nil {
+		cached := float64(*usage.InputDetails.CacheReadTokens)
+		cost += cached * i.CachedInputPrice
+		input -= cached
+	}
+	cost += input*i.InputPrice + float64(usage.GetOutputTokens())*i.OutputPrice
+	return cost / 1e6
+}
+
+// Models is the catalog of serverless models, keyed by full model ID. Prices
+// are Fireworks' published serverless rates; use Config.Models to add
+// dedicated deployments or update prices.
+var Models = map[string]ModelInfo{
+	ModelLlama3p3_70BInstruct:  {ContextWindow: 131072, InputPrice: 0.90, OutputPrice: 0.90, Tools: true, JSONMode: true},
+	ModelLlama3p1_8BInstruct:   {ContextWindow: 131072, InputPrice: 0.20, OutputPrice: 0.20, Tools: true, JSONMode: true},
+	ModelLlama3p1_405BInstruct: {ContextWindow: 131072, InputPrice: 3.00, OutputPrice: 3.00, Tools: true, JSONMode: true},
+	ModelLlama4Maverick:        {ContextWindow: 1048576, InputPrice: 0.22, OutputPrice: 0.88, Tools: true, JSONMode: true, ImageInput: true},
+	ModelDeepSeekV3:            {ContextWindow: 131072, InputPrice: 0.90, OutputPrice: 0.90, Tools: true, JSONMode: true},
+	ModelDeepSeekR1:            {ContextWindow: 163840, InputPrice: 3.00, OutputPrice: 8.00, JSONMode: true},
+	ModelQwen2p5_72BInstruct:   {ContextWindow: 32768, InputPrice: 0.90, OutputPrice: 0.90, Tools: true, JSONMode: true},
+	ModelMixtral8x7BInstruct:   {ContextWindow: 32768, InputPrice: 0.50, OutputPrice: 0.50, JSONMode: true},
+	ModelKimiK2Instruct:        {ContextWindow: 131072, InputPrice: 0.60, OutputPrice: 2.50, Tools: true, JSONMode: true},
+	ModelNomicEmbedTextV1p5:    {ContextWindow: 8192, InputPrice: 0.008},
+}
+
+// normalizeModelID expands short serverless model names such as
+// "llama-v3p3-70b-instruct" to their full "accounts/..." path
+func normalizeModelID(modelID string) string {
+	if modelID == "" || strings.Contains(modelID, "/") {
+		return modelID
+	}
+	return modelPrefix + modelID
+}
+
+// LookupModel returns catalog information for a model, checking the
+// provider's Config.Models before the built-in catalog
+func (p *Provider) LookupModel(modelID string) (ModelInfo, bool) {
+	modelID = normalizeModelID(modelID)
+	if info, ok := p.config.Models[modelID]; ok {
+		return info, true
+	}
+	info, ok := Models[modelID]
+	return info, ok
+}
+
+// Metadata is the Fireworks-specific metadata of a generation, stored in
+// GenerateResult.ProviderMetadata["fireworks"]
+type Metadata struct {
+	// Model is the model that served the request
+	Model string `json:"model,omitempty"`
+
+	// Cost is the USD cost at catalog list prices; nil for models not in
+	// the catalog
+	Cost *float64 `json:"cost,omitempty"`
+}
@@ -0,0 +1,107 @@
+package fireworks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+)
+
+func TestDoGenerateJSONSchemaAndCost(t *testing.T) {
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"model":"accounts/fireworks/models/llama-v3p3-70b-instruct","choices":[{"index":0,"message":{"role":"assistant","content":"{\"city\":\"Paris\"}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1000000,"completion_tokens":500000,"total_tokens":1500000}}`)
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	model, _ := p.LanguageModel("llama-v3p3-70b-instruct")
+	if model.ModelID() != ModelLlama3p3_70BInstruct {
+		t.Errorf("ModelID() = %q, want the short name expanded", model.ModelID())
+	}
+
+	citySchema := schema.NewSimpleJSONSchema(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+	})
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt:         types.Prompt{Text: "Capital of France?"},
+		ResponseFormat: &provider.ResponseFormat{Type: "json_schema", Schema: citySchema},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	format, _ := gotBody["response_format"].(map[string]interface{})
+	jsonSchema, _ := format["json_schema"].(map[string]interface{})
+	if format["type"] != "json_schema" || jsonSchema["schema"] == nil {
+		t.Errorf("response_format = %v, want the schema included", format)
+	}
+
+	meta, ok := result.ProviderMetadata["fireworks"].(*Metadata)
+	if !ok || meta.Cost == nil {
+		t.Fatalf("metadata = %#v", result.ProviderMetadata["fireworks"])
+	}
+	// 1M input tokens at $0.90 plus 0.5M output tokens at $0.90
+	if math.Abs(*meta.Cost-1.35) > 1e-9 || meta.Model != ModelLlama3p3_70BInstruct {
+		t.Errorf("metadata = %+v, cost %v", meta, *meta.Cost)
+	}
+}
+
+func TestLookupModel(t *testing.T) {
+	p := New(Config{
+		APIKey: "test-key",
+		Models: map[string]ModelInfo{
+			"accounts/acme/models/support-ft": {InputPrice: 0.5, OutputPrice: 0.5, Tools: false, JSONMode: true},
+		},
+	})
+
+	if _, ok := p.LookupModel("deepseek-r1"); !ok {
+		t.Error("short serverless names should resolve")
+	}
+	model, _ := p.LanguageModel("accounts/acme/models/support-ft")
+	if model.SupportsTools() || !model.SupportsStructuredOutput() {
+		t.Error("capabilities should come from Config.Models")
+	}
+	unknown, _ := p.LanguageModel("accounts/acme/models/unknown")
+	if !unknown.SupportsTools() || unknown.SupportsImageInput() {
+		t.Error("unknown models should default to tools without image input")
+	}
+	maverick, _ := p.LanguageModel(ModelLlama4Maverick)
+	if !maverick.SupportsImageInput() {
+		t.Error("Llama 4 Maverick accepts images")
+	}
+}
+
+func TestEmbeddingDimensions(t *testing.T) {
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":[{"embedding":[0.1,0.2],"index":0}],"usage":{"prompt_tokens":3,"total_tokens":3}}`)
+	}))
+	defer server.Close()
+
+	model, _ := New(Config{APIKey: "test-key", BaseURL: server.URL}).EmbeddingModel("")
+	result, err := model.DoEmbed(context.Background(), "hello", &provider.EmbedModelOptions{
+		ProviderOptions: map[string]interface{}{"fireworks": map[string]interface{}{"dimensions": 256}},
+	})
+	if err != nil {
+		t.Fatalf("DoEmbed: %v", err)
+	}
+	if gotBody["dimensions"] != float64(256) || gotBody["model"] != ModelNomicEmbedTextV1p5 {
+		t.Errorf("body = %v", gotBody)
+	}
+	if len(result.Embedding) != 2 || result.Usage.InputTokens != 3 {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -28,6 +28,10 @@ type Config struct {
 	// ImagePollTimeoutMs is the maximum duration to wait for async image generation
 	// to complete. Defaults to 120000ms (2 minutes).
 	ImagePollTimeoutMs int
+
+	// Models adds catalog entries for dedicated deployments and fine-tuned
+	// models, or overrides built-in entries, keyed by full model ID
+	Models map[string]ModelInfo
 }
 
 // New creates a new Fireworks AI provider with the given configuration
@@ -56,19 +60,20 @@ func (p *Provider) Name() string {
 	return "fireworks"
 }
 
-// LanguageModel returns a language model by ID
+// LanguageModel returns a language model by ID. Serverless models may be
+// named without the "accounts/fireworks/models/" prefix.
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	if modelID == "" {
-		modelID = "accounts/fireworks/models/mixtral-8x7b-instruct"
+		modelID = ModelMixtral8x7BInstruct
 	}
 
-	return NewLanguageModel(p, modelID), nil
+	return NewLanguageModel(p, normalizeModelID(modelID)), nil
 }
 
 // EmbeddingModel returns an embedding model by ID
 func (p *Provider) EmbeddingModel(modelID string) (provider.EmbeddingModel, error) {
 	if modelID == "" {
-		modelID = "nomic-ai/nomic-embed-text-v1.5"
+		modelID = ModelNomicEmbedTextV1p5
 	}
 
 	return NewEmbeddingModel(p, modelID), nil
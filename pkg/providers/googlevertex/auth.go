@@ -0,0 +1,360 @@
+package googlevertex
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// cloudPlatformScope is the OAuth2 scope used for Vertex AI
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// defaultTokenURI is Google's OAuth2 token endpoint
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+
+	// tokenRefreshMargin refreshes tokens this long before they expire
+	tokenRefreshMargin = time.Minute
+)
+
+// Token is an OAuth2 access token
+type Token struct {
+	AccessToken string
+
+	// Expiry is when the token expires; zero means it does not
+	Expiry time.Time
+}
+
+// valid reports whether the token can be used now
+func (t *Token) valid() bool {
+	return t != nil && t.AccessToken != "" &&
+		(t.Expiry.IsZero() || time.Now().Add(tokenRefreshMargin).Before(t.Expiry))
+}
+
+// TokenSource supplies OAuth2 access tokens for Vertex AI requests
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always returns accessToken
+func StaticTokenSource(accessToken string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: accessToken}, nil
+	})
+}
+
+// Credentials are Google credentials resolved from a credentials file or
+// the environment
+type Credentials struct {
+	// TokenSource mints access tokens; tokens are cached until shortly
+	// before they expire
+	TokenSource TokenSource
+
+	// ProjectID is the project named by the credentials, if any
+	ProjectID string
+}
+
+// FindDefaultCredentials looks up Application Default Credentials in the
+// order used by Google's client libraries:
+//  1. the file named by GOOGLE_APPLICATION_CREDENTIALS
+//  2. gcloud's file, written by "gcloud auth application-default login"
+//  3. the metadata server, when running on Google Cloud
+//
+// Service account keys and gcloud user credentials are supported.
+func FindDefaultCredentials() (*Credentials, error) {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		creds, err := credentialsFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		return creds, nil
+	}
+	if path := wellKnownCredentialsFile(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return credentialsFromFile(path)
+		}
+	}
+	return &Credentials{TokenSource: cachedTokenSource(&metadataTokenSource{})}, nil
+}
+
+// CredentialsFromJSON parses a service account key or a gcloud user
+// credentials file
+func CredentialsFromJSON(data []byte) (*Credentials, error) {
+	var file struct {
+		Type           string `json:"type"`
+		ProjectID      string `json:"project_id"`
+		QuotaProjectID string `json:"quota_project_id"`
+
+		// service_account
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+
+		// authorized_user
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid credentials JSON: %w", err)
+	}
+	tokenURI := file.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+
+	switch file.Type {
+	case "service_account":
+		key, err := parsePrivateKey(file.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return &Credentials{
+			TokenSource: cachedTokenSource(&serviceAccountTokenSource{
+				email:    file.ClientEmail,
+				key:      key,
+				keyID:    file.PrivateKeyID,
+				tokenURI: tokenURI,
+			}),
+			ProjectID: file.ProjectID,
+		}, nil
+
+	case "authorized_user":
+		return &Credentials{
+			TokenSource: cachedTokenSource(&refreshTokenSource{
+				clientID:     file.ClientID,
+				clientSecret: file.ClientSecret,
+				refreshToken: file.RefreshToken,
+				tokenURI:     tokenURI,
+			}),
+			ProjectID: file.QuotaProjectID,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported credentials type %q", file.Type)
+	}
+}
+
+func credentialsFromFile(path string) (*Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return CredentialsFromJSON(data)
+}
+
+// wellKnownCredentialsFile returns the path of gcloud's application default
+// credentials
+func wellKnownCredentialsFile() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud", "application_default_credentials.json")
+		}
+		return ""
+	}
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("invalid service account private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("service account private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// cachingTokenSource reuses a token until shortly before it expires
+type cachingTokenSource struct {
+	mu     sync.Mutex
+	source TokenSource
+	token  *Token
+}
+
+func cachedTokenSource(source TokenSource) TokenSource {
+	return &cachingTokenSource{source: source}
+}
+
+func (s *cachingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.valid() {
+		return s.token, nil
+	}
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// serviceAccountTokenSource exchanges a signed JWT for an access token
+type serviceAccountTokenSource struct {
+	email    string
+	key      *rsa.PrivateKey
+	keyID    string
+	tokenURI string
+}
+
+func (s *serviceAccountTokenSource) Token(ctx context.Context) (*Token, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.keyID})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   s.email,
+		"scope": cloudPlatformScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("signing token request: %w", err)
+	}
+
+	return exchangeToken(ctx, s.tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + enc.EncodeToString(signature)},
+	})
+}
+
+// refreshTokenSource exchanges a gcloud user refresh token for an access token
+type refreshTokenSource struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+	tokenURI     string
+}
+
+func (s *refreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	return exchangeToken(ctx, s.tokenURI, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"refresh_token": {s.refreshToken},
+	})
+}
+
+// metadataTokenSource fetches the attached service account's token from the
+// Google Cloud metadata server
+type metadataTokenSource struct{}
+
+func (s *metadataTokenSource) Token(ctx context.Context) (*Token, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	token, err := doTokenRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("no Google credentials found (set AccessToken, GOOGLE_APPLICATION_CREDENTIALS or run \"gcloud auth application-default login\"); metadata server: %w", err)
+	}
+	return token, nil
+}
+
+func exchangeToken(ctx context.Context, tokenURI string, form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doTokenRequest(req)
+}
+
+var tokenHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func doTokenRequest(req *http.Request) (*Token, error) {
+	resp, err := tokenHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("invalid token response: %s", body)
+	}
+	result := &Token{AccessToken: token.AccessToken}
+	if token.ExpiresIn > 0 {
+		result.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return result, nil
+}
+
+// authTransport adds a bearer token from a TokenSource to every request
+type authTransport struct {
+	base   http.RoundTripper
+	source TokenSource
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close() //nolint:errcheck
+		}
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return t.base.RoundTrip(req)
+}
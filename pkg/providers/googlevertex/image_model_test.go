@@ -417,12 +417,13 @@ func TestProvider_New_ValidationErrors(t *testing.T) {
 			expectError: "location is required",
 		},
 		{
-			name: "Missing access token",
+			name: "Invalid credentials",
 			config: Config{
-				Project:  "my-project",
-				Location: "us-central1",
+				Project:         "my-project",
+				Location:        "us-central1",
+				CredentialsJSON: []byte(`{"type":"external_account"}`),
 			},
-			expectError: "access token is required",
+			expectError: "unsupported credentials type",
 		},
 	}
 
@@ -0,0 +1,131 @@
+package googlevertex
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/anthropic"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openaicompatible"
+)
+
+// vertexAnthropicVersion is the Anthropic API version for Claude on Vertex
+const vertexAnthropicVersion = "vertex-2023-10-16"
+
+// AnthropicLanguageModel is a Claude model served by Vertex AI. It shares
+// the Anthropic provider's Messages API mapping; requests are sent to the
+// model's rawPredict and streamRawPredict methods with Google credentials.
+type AnthropicLanguageModel struct {
+	*anthropic.LanguageModel
+	provider *Provider
+}
+
+// NewAnthropicLanguageModel creates a Claude model on Vertex AI. Model IDs
+// use Vertex's versioned form, e.g. "claude-sonnet-4-5@20250929".
+func NewAnthropicLanguageModel(p *Provider, modelID string) *AnthropicLanguageModel {
+	base := anthropic.New(anthropic.Config{BaseURL: p.publisherURL("anthropic")})
+	return &AnthropicLanguageModel{
+		LanguageModel: anthropic.NewLanguageModel(base, modelID, nil),
+		provider:      p,
+	}
+}
+
+// Provider returns the provider name
+func (m *AnthropicLanguageModel) Provider() string {
+	return "google-vertex-anthropic"
+}
+
+// DoGenerate performs non-streaming text generation
+func (m *AnthropicLanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	return m.LanguageModel.DoGenerate(m.withVertex(ctx), opts)
+}
+
+// DoStream performs streaming text generation
+func (m *AnthropicLanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	return m.LanguageModel.DoStream(m.withVertex(ctx), opts)
+}
+
+// withVertex rewrites Messages API requests into Vertex rawPredict calls:
+// the model moves from the body to the URL, the API version moves from the
+// header to the body, and the API key is replaced by a Google access token
+func (m *AnthropicLanguageModel) withVertex(ctx context.Context) context.Context {
+	return provider.ContextWithHTTPTransforms(ctx, provider.HTTPTransforms{
+		Request: []provider.RequestTransform{func(ctx context.Context, req *provider.HTTPRequest) error {
+			base, ok := strings.CutSuffix(req.URL, "/v1/messages")
+			if !ok {
+				return nil
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(req.Body, &body); err != nil {
+				return err
+			}
+			method := ":rawPredict"
+			if stream, _ := body["stream"].(bool); stream {
+				method = ":streamRawPredict"
+			}
+			delete(body, "model")
+			body["anthropic_version"] = vertexAnthropicVersion
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+
+			req.URL = base + "/models/" + m.ModelID() + method
+			req.Body = data
+			req.Header.Del("x-api-key")
+			req.Header.Del("anthropic-version")
+			return m.provider.authorize(ctx, req)
+		}},
+	})
+}
+
+// OpenModel is a partner or open model (Llama, Mistral, DeepSeek, Qwen, ...)
+// served by Vertex AI's OpenAI-compatible chat completions endpoint
+type OpenModel struct {
+	*openaicompatible.LanguageModel
+	provider *Provider
+}
+
+// NewOpenModel creates a Model Garden model served through Vertex's
+// OpenAI-compatible endpoint. Model IDs include the publisher, e.g.
+// "meta/llama-3.3-70b-instruct-maas".
+func NewOpenModel(p *Provider, modelID string) *OpenModel {
+	compat := openaicompatible.New(openaicompatible.Config{
+		Name:    "google-vertex",
+		BaseURL: p.projectURL("v1") + "/endpoints/openapi",
+	})
+	return &OpenModel{
+		LanguageModel: openaicompatible.NewLanguageModel(compat, modelID),
+		provider:      p,
+	}
+}
+
+// DoGenerate performs non-streaming text generation
+func (m *OpenModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	return m.LanguageModel.DoGenerate(m.provider.withAuth(ctx), opts)
+}
+
+// DoStream performs streaming text generation
+func (m *OpenModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	return m.LanguageModel.DoStream(m.provider.withAuth(ctx), opts)
+}
+
+// withAuth adds Google credentials to requests made by clients the provider
+// does not own
+func (p *Provider) withAuth(ctx context.Context) context.Context {
+	return provider.ContextWithHTTPTransforms(ctx, provider.HTTPTransforms{
+		Request: []provider.RequestTransform{p.authorize},
+	})
+}
+
+// authorize sets the request's bearer token
+func (p *Provider) authorize(ctx context.Context, req *provider.HTTPRequest) error {
+	token, err := p.tokenSource.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
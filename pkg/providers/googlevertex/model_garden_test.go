@@ -0,0 +1,212 @@
+package googlevertex
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// serviceAccountJSON returns a service account key whose token endpoint is
+// tokenURI
+func serviceAccountJSON(t *testing.T, tokenURI string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "sa-project",
+		"client_email": "bot@sa-project.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    tokenURI,
+	})
+	return data
+}
+
+func TestProvider_ServiceAccountCredentials(t *testing.T) {
+	t.Parallel()
+
+	var tokenRequests atomic.Int32
+	var gotGrant, gotAssertion string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		_ = r.ParseForm()
+		gotGrant, gotAssertion = r.PostForm.Get("grant_type"), r.PostForm.Get("assertion")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"ya29.test","expires_in":3600,"token_type":"Bearer"}`)
+	}))
+	defer tokenServer.Close()
+
+	var gotAuth []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`)
+	}))
+	defer api.Close()
+
+	prov, err := New(Config{
+		Location:        "us-central1",
+		CredentialsJSON: serviceAccountJSON(t, tokenServer.URL),
+		BaseURL:         api.URL,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if prov.Project() != "sa-project" {
+		t.Errorf("Project() = %q, want the credentials' project", prov.Project())
+	}
+
+	model, _ := prov.LanguageModel("gemini-2.5-flash")
+	for i := 0; i < 2; i++ {
+		if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "hello"}}); err != nil {
+			t.Fatalf("DoGenerate: %v", err)
+		}
+	}
+
+	if tokenRequests.Load() != 1 {
+		t.Errorf("token requests = %d, want the token cached", tokenRequests.Load())
+	}
+	if gotGrant != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(gotAssertion, ".") != 2 {
+		t.Errorf("grant = %q, assertion = %q", gotGrant, gotAssertion)
+	}
+	for _, auth := range gotAuth {
+		if auth != "Bearer ya29.test" {
+			t.Errorf("Authorization = %q", auth)
+		}
+	}
+}
+
+func TestProvider_RegionalEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		location  string
+		publisher string
+		want      string
+	}{
+		{"us-central1", "google", "https://us-central1-aiplatform.googleapis.com/v1beta1/projects/p/locations/us-central1/publishers/google"},
+		{"europe-west4", "anthropic", "https://europe-west4-aiplatform.googleapis.com/v1/projects/p/locations/europe-west4/publishers/anthropic"},
+		{"global", "google", "https://aiplatform.googleapis.com/v1beta1/projects/p/locations/global/publishers/google"},
+	}
+	for _, tt := range tests {
+		prov, err := New(Config{Project: "p", Location: tt.location, AccessToken: "t"})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if got := prov.publisherURL(tt.publisher); got != tt.want {
+			t.Errorf("publisherURL(%q) in %s = %q, want %q", tt.publisher, tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestModelPublisher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		modelID   string
+		publisher string
+		id        string
+	}{
+		{"gemini-2.5-pro", "google", "gemini-2.5-pro"},
+		{"google/gemini-2.5-pro", "google", "gemini-2.5-pro"},
+		{"claude-sonnet-4-5@20250929", "anthropic", "claude-sonnet-4-5@20250929"},
+		{"publishers/anthropic/models/claude-3-5-haiku@20241022", "anthropic", "claude-3-5-haiku@20241022"},
+		{"meta/llama-3.3-70b-instruct-maas", "meta", "llama-3.3-70b-instruct-maas"},
+	}
+	for _, tt := range tests {
+		publisher, id := modelPublisher(tt.modelID)
+		if publisher != tt.publisher || id != tt.id {
+			t.Errorf("modelPublisher(%q) = %q, %q; want %q, %q", tt.modelID, publisher, id, tt.publisher, tt.id)
+		}
+	}
+}
+
+func TestAnthropicLanguageModel_RawPredict(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotAuth, gotAPIKey string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth, gotAPIKey = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("x-api-key")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Bonjour"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	prov, _ := New(Config{
+		Project:     "p",
+		Location:    "us-east5",
+		AccessToken: "ya29.test",
+		BaseURL:     server.URL + "/v1beta1/projects/p/locations/us-east5/publishers/google",
+	})
+	model, err := prov.LanguageModel("claude-sonnet-4-5@20250929")
+	if err != nil {
+		t.Fatalf("LanguageModel: %v", err)
+	}
+	if model.Provider() != "google-vertex-anthropic" {
+		t.Errorf("Provider() = %q", model.Provider())
+	}
+
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "Say hello in French"}})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	wantPath := "/v1beta1/projects/p/locations/us-east5/publishers/anthropic/models/claude-sonnet-4-5@20250929:rawPredict"
+	if gotPath != wantPath {
+		t.Errorf("path = %q, want %q", gotPath, wantPath)
+	}
+	if gotAuth != "Bearer ya29.test" || gotAPIKey != "" {
+		t.Errorf("Authorization = %q, x-api-key = %q", gotAuth, gotAPIKey)
+	}
+	if _, ok := gotBody["model"]; ok || gotBody["anthropic_version"] != vertexAnthropicVersion {
+		t.Errorf("body = %v", gotBody)
+	}
+	if result.Text != "Bonjour" {
+		t.Errorf("Text = %q", result.Text)
+	}
+}
+
+func TestOpenModel_ChatCompletions(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotAuth, gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel, _ = body["model"].(string)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	prov, _ := New(Config{Project: "p", Location: "us-central1", AccessToken: "ya29.test", BaseURL: server.URL + "/v1/projects/p/locations/us-central1"})
+	model, _ := prov.LanguageModel("meta/llama-3.3-70b-instruct-maas")
+	if _, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "hi"}}); err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	if gotPath != "/v1/projects/p/locations/us-central1/endpoints/openapi/chat/completions" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotAuth != "Bearer ya29.test" || gotModel != "meta/llama-3.3-70b-instruct-maas" {
+		t.Errorf("Authorization = %q, model = %q", gotAuth, gotModel)
+	}
+}
@@ -2,6 +2,9 @@ package googlevertex
 
 import (
 	"fmt"
+	gohttp "net/http"
+	"os"
+	"strings"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -15,28 +18,72 @@ const (
 
 // Provider implements the provider.Provider interface for Google Vertex AI
 type Provider struct {
-	config Config
-	client *http.Client
+	config      Config
+	client      *http.Client
+	tokenSource TokenSource
 }
 
 // Config contains configuration for the Google Vertex AI provider
 type Config struct {
-	// Project is the Google Cloud project ID
+	// Project is the Google Cloud project ID. If empty, GOOGLE_VERTEX_PROJECT,
+	// GOOGLE_CLOUD_PROJECT or the project of the credentials is used.
 	Project string
 
-	// Location is the Google Cloud location (e.g., "us-central1")
+	// Location is the Google Cloud region (e.g., "us-central1"), or "global"
+	// for the global endpoint. If empty, GOOGLE_VERTEX_LOCATION or
+	// GOOGLE_CLOUD_LOCATION is used.
 	Location string
 
-	// AccessToken is the OAuth2 access token for authentication
-	// This can be obtained from Google Cloud SDK or service account
+	// AccessToken is a fixed OAuth2 access token, e.g. from
+	// "gcloud auth print-access-token". Tokens expire after an hour; prefer
+	// Application Default Credentials for long-running processes.
 	AccessToken string
 
-	// BaseURL is the base URL for the Vertex AI API (optional, computed from project/location if not provided)
+	// TokenSource supplies access tokens, e.g. from golang.org/x/oauth2/google.
+	// Used when AccessToken is empty.
+	TokenSource TokenSource
+
+	// CredentialsJSON is a service account key or gcloud user credentials
+	// file. Used when neither AccessToken nor TokenSource is set.
+	CredentialsJSON []byte
+
+	// BaseURL is the base URL for Gemini models (optional, computed from
+	// project/location if not provided). Anthropic and open models use the
+	// sibling publisher and OpenAI-compatible endpoint paths.
 	BaseURL string
 }
 
-// New creates a new Google Vertex AI provider with the given configuration
+// New creates a new Google Vertex AI provider with the given configuration.
+//
+// Credentials are resolved in order: AccessToken, TokenSource,
+// CredentialsJSON, then Application Default Credentials (see
+// FindDefaultCredentials). Tokens are fetched when the first request is made
+// and refreshed before they expire.
 func New(cfg Config) (*Provider, error) {
+	var source TokenSource
+	var credsProject string
+	switch {
+	case cfg.AccessToken != "":
+		source = StaticTokenSource(cfg.AccessToken)
+	case cfg.TokenSource != nil:
+		source = cfg.TokenSource
+	default:
+		var creds *Credentials
+		var err error
+		if len(cfg.CredentialsJSON) > 0 {
+			creds, err = CredentialsFromJSON(cfg.CredentialsJSON)
+		} else {
+			creds, err = FindDefaultCredentials()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("google vertex credentials: %w", err)
+		}
+		source, credsProject = creds.TokenSource, creds.ProjectID
+	}
+
+	cfg.Project = firstNonEmpty(cfg.Project, os.Getenv("GOOGLE_VERTEX_PROJECT"), os.Getenv("GOOGLE_CLOUD_PROJECT"), credsProject)
+	cfg.Location = firstNonEmpty(cfg.Location, os.Getenv("GOOGLE_VERTEX_LOCATION"), os.Getenv("GOOGLE_CLOUD_LOCATION"))
+
 	// Validate required fields
 	if cfg.Project == "" {
 		return nil, fmt.Errorf("project is required for Google Vertex AI")
@@ -44,30 +91,56 @@ func New(cfg Config) (*Provider, error) {
 	if cfg.Location == "" {
 		return nil, fmt.Errorf("location is required for Google Vertex AI")
 	}
-	if cfg.AccessToken == "" {
-		return nil, fmt.Errorf("access token is required for Google Vertex AI")
-	}
 
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		// Build base URL from template
-		baseURL = fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1beta1/projects/%s/locations/%s/publishers/google",
-			cfg.Location, cfg.Project, cfg.Location)
-	}
+	p := &Provider{config: cfg, tokenSource: source}
 
-	// Google Vertex uses Bearer token authentication
-	client := http.NewClient(http.Config{
-		BaseURL: baseURL,
+	// Google Vertex uses Bearer token authentication, added per request so
+	// tokens can be refreshed
+	p.client = http.NewClient(http.Config{
+		BaseURL: p.publisherURL("google"),
 		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": "Bearer " + cfg.AccessToken,
+			"Content-Type": "application/json",
+		},
+		HTTPClient: &gohttp.Client{
+			Timeout:   http.DefaultHTTPClient.Timeout,
+			Transport: &authTransport{base: http.DefaultHTTPClient.Transport, source: source},
 		},
 	})
 
-	return &Provider{
-		config: cfg,
-		client: client,
-	}, nil
+	return p, nil
+}
+
+// projectURL returns the API URL of the project and location
+func (p *Provider) projectURL(version string) string {
+	if p.config.BaseURL != "" {
+		return strings.TrimSuffix(strings.TrimSuffix(p.config.BaseURL, "/"), "/publishers/google")
+	}
+	host := p.config.Location + "-aiplatform.googleapis.com"
+	if p.config.Location == "global" {
+		host = "aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s/%s/projects/%s/locations/%s", host, version, p.config.Project, p.config.Location)
+}
+
+// publisherURL returns the base URL of a Model Garden publisher's models
+func (p *Provider) publisherURL(publisher string) string {
+	if publisher == "google" && p.config.BaseURL != "" {
+		return p.config.BaseURL
+	}
+	version := "v1"
+	if publisher == "google" {
+		version = "v1beta1"
+	}
+	return p.projectURL(version) + "/publishers/" + publisher
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // Name returns the provider name
@@ -75,14 +148,47 @@ func (p *Provider) Name() string {
 	return "google-vertex"
 }
 
-// LanguageModel returns a language model by ID
+// LanguageModel returns a language model by ID, routed to its Model Garden
+// publisher:
+//   - Gemini models ("gemini-2.5-flash", "google/gemini-2.5-flash") use the
+//     Gemini API
+//   - Claude models ("claude-sonnet-4-5@20250929", "anthropic/claude-...")
+//     use Anthropic's Messages API on Vertex
+//   - other "publisher/model" IDs ("meta/llama-3.3-70b-instruct-maas") use
+//     Vertex's OpenAI-compatible endpoint for partner and open models
+//
+// Full resource names ("publishers/anthropic/models/claude-...") are accepted.
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	// Validate model ID
 	if modelID == "" {
 		return nil, fmt.Errorf("model ID cannot be empty")
 	}
 
-	return NewLanguageModel(p, modelID), nil
+	publisher, id := modelPublisher(modelID)
+	switch publisher {
+	case "google":
+		return NewLanguageModel(p, id), nil
+	case "anthropic":
+		return NewAnthropicLanguageModel(p, id), nil
+	default:
+		return NewOpenModel(p, publisher+"/"+id), nil
+	}
+}
+
+// modelPublisher splits a model ID into its Model Garden publisher and model
+func modelPublisher(modelID string) (publisher, id string) {
+	if rest, ok := strings.CutPrefix(modelID, "publishers/"); ok {
+		if pub, model, ok := strings.Cut(rest, "/models/"); ok {
+			return pub, model
+		}
+	}
+	if pub, model, ok := strings.Cut(modelID, "/"); ok {
+		return pub, model
+	}
+	if strings.HasPrefix(modelID, "claude-") {
+		return "anthropic", modelID
+	}
+	return "google", modelID
 }
 
 // EmbeddingModel returns an embedding model by ID
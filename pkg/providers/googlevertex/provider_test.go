@@ -56,12 +56,13 @@ func TestNewProvider_ValidationErrors(t *testing.T) {
 			expectedErr: "location is required for Google Vertex AI",
 		},
 		{
-			name: "missing access token",
+			name: "invalid credentials",
 			config: Config{
-				Project:  "test-project",
-				Location: "us-central1",
+				Project:         "test-project",
+				Location:        "us-central1",
+				CredentialsJSON: []byte(`{"type":"external_account"}`),
 			},
-			expectedErr: "access token is required for Google Vertex AI",
+			expectedErr: `google vertex credentials: unsupported credentials type "external_account"`,
 		},
 	}
 
@@ -7,7 +7,6 @@ import (
 	"net/http"
 
 	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
-	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
@@ -54,23 +53,19 @@ func (m *EmbeddingModel) SupportsParallelCalls() bool {
 
 // DoEmbed performs embedding generation for a single input
 func (m *EmbeddingModel) DoEmbed(ctx context.Context, input string, opts *provider.EmbedModelOptions) (*types.EmbeddingResult, error) {
-	path := fmt.Sprintf("/models/%s", m.modelID)
+	path := m.provider.modelPath(m.modelID)
 	reqBody := map[string]interface{}{
 		"inputs": input,
 	}
 
-	resp, err := m.provider.client.Do(ctx, internalhttp.Request{
+	resp, err := m.provider.post(ctx, internalhttp.Request{
 		Method:  http.MethodPost,
 		Path:    path,
 		Body:    reqBody,
 		Headers: optsHeaders(opts),
 	})
 	if err != nil {
-		return nil, providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("hugging face API returned status %d: %s", resp.StatusCode, string(resp.Body))
+		return nil, err
 	}
 
 	embedding, err := m.parseEmbeddingResponse(resp.Body)
@@ -93,7 +88,7 @@ func (m *EmbeddingModel) DoEmbed(ctx context.Context, input string, opts *provid
 
 // DoEmbedMany performs embedding generation for multiple inputs
 func (m *EmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
-	path := fmt.Sprintf("/models/%s", m.modelID)
+	path := m.provider.modelPath(m.modelID)
 
 	var embeddings [][]float64
 	var totalTokens int
@@ -104,18 +99,14 @@ func (m *EmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts
 			"inputs": input,
 		}
 
-		resp, err := m.provider.client.Do(ctx, internalhttp.Request{
+		resp, err := m.provider.post(ctx, internalhttp.Request{
 			Method:  http.MethodPost,
 			Path:    path,
 			Body:    reqBody,
 			Headers: optsHeaders(opts),
 		})
 		if err != nil {
-			return nil, providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
-		}
-
-		if resp.StatusCode != 200 {
-			return nil, fmt.Errorf("hugging face API returned status %d: %s", resp.StatusCode, string(resp.Body))
+			return nil, err
 		}
 
 		embedding, err := m.parseEmbeddingResponse(resp.Body)
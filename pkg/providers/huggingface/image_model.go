@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
-	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
 )
@@ -43,14 +44,13 @@ func (m *ImageModel) ModelID() string {
 func (m *ImageModel) DoGenerate(ctx context.Context, opts *provider.ImageGenerateOptions) (*types.ImageResult, error) {
 	reqBody := m.buildRequestBody(opts)
 
-	path := fmt.Sprintf("/models/%s", m.modelID)
-	resp, err := m.provider.client.Post(ctx, path, reqBody)
+	resp, err := m.provider.post(ctx, internalhttp.Request{
+		Method: http.MethodPost,
+		Path:   m.provider.modelPath(m.modelID),
+		Body:   reqBody,
+	})
 	if err != nil {
-		return nil, providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("LHugging Face API returned status %d: %s", resp.StatusCode, string(resp.Body))
+		return nil, err
 	}
 
 	return m.convertResponse(resp.Body)
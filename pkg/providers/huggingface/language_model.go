@@ -4,24 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 
-	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils/streaming"
 )
 
 // LanguageModel implements the provider.LanguageModel interface for Hugging Face
 type LanguageModel struct {
 	provider *Provider
 	modelID  string
+
+	// chat serves TaskChat through the OpenAI-compatible Messages API
+	chat *openai.LanguageModel
 }
 
 // NewLanguageModel creates a new Hugging Face language model
 func NewLanguageModel(provider *Provider, modelID string) *LanguageModel {
-	return &LanguageModel{
+	m := &LanguageModel{
 		provider: provider,
 		modelID:  modelID,
 	}
+	if provider.config.Task == TaskChat {
+		m.chat = openai.NewLanguageModel(openai.New(openai.Config{
+			APIKey:  provider.config.APIKey,
+			BaseURL: provider.config.baseURL() + provider.modelPath(modelID) + "/v1",
+		}), modelID)
+	}
+	return m
 }
 
 // SpecificationVersion returns the specification version
@@ -41,7 +57,8 @@ func (m *LanguageModel) ModelID() string {
 
 // SupportsTools returns whether the model supports tool calling
 func (m *LanguageModel) SupportsTools() bool {
-	return false
+	// The Messages API accepts tools; raw text generation does not
+	return m.chat != nil
 }
 
 // SupportsStructuredOutput returns whether the model supports structured output
@@ -57,16 +74,24 @@ func (m *LanguageModel) SupportsImageInput() bool {
 
 // DoGenerate performs non-streaming text generation
 func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
-	reqBody := m.buildRequestBody(opts)
-
-	path := fmt.Sprintf("/models/%s", m.modelID)
-	resp, err := m.provider.client.Post(ctx, path, reqBody)
-	if err != nil {
-		return nil, providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
+	if m.chat != nil {
+		var result *types.GenerateResult
+		err := m.provider.retryColdStart(ctx, func() error {
+			var err error
+			result, err = m.chat.DoGenerate(m.chatContext(ctx), opts)
+			return err
+		})
+		return result, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("LHugging Face API returned status %d: %s", resp.StatusCode, string(resp.Body))
+	reqBody := m.buildRequestBody(opts)
+	resp, err := m.provider.post(ctx, internalhttp.Request{
+		Method: http.MethodPost,
+		Path:   m.provider.modelPath(m.modelID),
+		Body:   reqBody,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return m.convertResponse(resp.Body)
@@ -74,20 +99,43 @@ func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateO
 
 // DoStream performs streaming text generation
 func (m *LanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
-	// Hugging Face Inference API doesn't have native streaming for all models
-	// We'll simulate it by chunking the response
-	result, err := m.DoGenerate(ctx, opts)
-	if err != nil {
-		return nil, err
-	}
+	var stream provider.TextStream
+	err := m.provider.retryColdStart(ctx, func() error {
+		if m.chat != nil {
+			var err error
+			stream, err = m.chat.DoStream(m.chatContext(ctx), opts)
+			return err
+		}
 
-	stream := &huggingfaceStream{
-		result:   result,
-		position: 0,
-		done:     false,
-	}
+		reqBody := m.buildRequestBody(opts)
+		reqBody["stream"] = true
+		httpResp, err := m.provider.client.DoStream(ctx, internalhttp.Request{
+			Method:  http.MethodPost,
+			Path:    m.provider.modelPath(m.modelID),
+			Body:    reqBody,
+			Headers: map[string]string{"Accept": "text/event-stream"},
+		})
+		if err != nil {
+			return providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
+		}
+		stream = newTGIStream(httpResp.Body)
+		return nil
+	})
+	return stream, err
+}
 
-	return stream, nil
+// chatContext adds the provider's headers to Messages API calls, which are
+// made by the OpenAI client
+func (m *LanguageModel) chatContext(ctx context.Context) context.Context {
+	if !m.provider.config.WaitForModel {
+		return ctx
+	}
+	return provider.ContextWithHTTPTransforms(ctx, provider.HTTPTransforms{
+		Request: []provider.RequestTransform{func(ctx context.Context, req *provider.HTTPRequest) error {
+			req.Header.Set("x-wait-for-model", "true")
+			return nil
+		}},
+	})
 }
 
 func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions) map[string]interface{} {
@@ -138,10 +186,20 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions) map[str
 		parameters["top_k"] = *opts.TopK
 	}
 
-	if len(parameters) > 0 {
-		reqBody["parameters"] = parameters
+	if len(opts.StopSequences) > 0 {
+		parameters["stop"] = opts.StopSequences
 	}
 
+	if opts.Seed != nil {
+		parameters["seed"] = *opts.Seed
+	}
+
+	// Return only the completion, with the finish reason and token counts
+	parameters["return_full_text"] = false
+	parameters["details"] = true
+
+	reqBody["parameters"] = parameters
+
 	return reqBody
 }
 
@@ -150,21 +208,13 @@ func (m *LanguageModel) convertResponse(body []byte) (*types.GenerateResult, err
 	// Try to parse as array first (most common format)
 	var responses []hfTextGenerationResponse
 	if err := json.Unmarshal(body, &responses); err == nil && len(responses) > 0 {
-		return &types.GenerateResult{
-			Text:         responses[0].GeneratedText,
-			FinishReason: types.FinishReasonStop,
-			Usage:        types.Usage{}, // HF doesn't return token counts
-		}, nil
+		return responses[0].result(), nil
 	}
 
 	// Try single object format
 	var response hfTextGenerationResponse
 	if err := json.Unmarshal(body, &response); err == nil && response.GeneratedText != "" {
-		return &types.GenerateResult{
-			Text:         response.GeneratedText,
-			FinishReason: types.FinishReasonStop,
-			Usage:        types.Usage{},
-		}, nil
+		return response.result(), nil
 	}
 
 	// Try error format
@@ -177,57 +227,128 @@ func (m *LanguageModel) convertResponse(body []byte) (*types.GenerateResult, err
 }
 
 type hfTextGenerationResponse struct {
-	GeneratedText string `json:"generated_text"`
+	GeneratedText string     `json:"generated_text"`
+	Details       *hfDetails `json:"details,omitempty"`
+}
+
+// hfDetails is returned by text generation when details are requested
+type hfDetails struct {
+	FinishReason    string `json:"finish_reason"`
+	GeneratedTokens int    `json:"generated_tokens"`
+}
+
+func (r hfTextGenerationResponse) result() *types.GenerateResult {
+	result := &types.GenerateResult{
+		Text:         r.GeneratedText,
+		FinishReason: types.FinishReasonStop,
+		RawResponse:  r,
+	}
+	if r.Details != nil {
+		result.FinishReason = providerutils.MapOpenAIFinishReason(r.Details.FinishReason)
+		result.Usage = r.Details.usage()
+	}
+	return result
+}
+
+// usage reports the generated token count; the API does not count prompt tokens
+func (d *hfDetails) usage() types.Usage {
+	output := int64(d.GeneratedTokens)
+	return types.Usage{OutputTokens: &output}
 }
 
 type hfErrorResponse struct {
 	Error string `json:"error"`
 }
 
-type huggingfaceStream struct {
-	result   *types.GenerateResult
-	position int
-	done     bool
+// tgiStream reads the text-generation task's server-sent events: one event
+// per token, the last carrying the finish reason and token counts
+type tgiStream struct {
+	reader   io.ReadCloser
+	parser   *streaming.SSEParser
+	queue    []*provider.StreamChunk
+	finished bool
+	err      error
 }
 
-func (s *huggingfaceStream) Next() (*provider.StreamChunk, error) {
-	if s.done {
-		return nil, fmt.Errorf("stream exhausted")
-	}
+func newTGIStream(reader io.ReadCloser) *tgiStream {
+	return &tgiStream{reader: reader, parser: streaming.NewSSEParser(reader)}
+}
 
-	// Emit text in chunks
-	chunkSize := 10
-	text := s.result.Text
+// Next returns the next chunk in the stream
+func (s *tgiStream) Next() (*provider.StreamChunk, error) {
+	for {
+		if len(s.queue) > 0 {
+			chunk := s.queue[0]
+			s.queue = s.queue[1:]
+			return chunk, nil
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
 
-	if s.position >= len(text) {
-		s.done = true
-		return &provider.StreamChunk{
-			Type:         provider.ChunkTypeFinish,
-			Text:         "",
-			FinishReason: s.result.FinishReason,
-			Usage:        &s.result.Usage,
-		}, nil
-	}
+		event, err := s.parser.Next()
+		if err == io.EOF || (err == nil && streaming.IsStreamDone(event)) {
+			// Servers without details end the stream without a finish reason
+			if !s.finished {
+				s.finish(types.FinishReasonStop, nil)
+			}
+			s.err = io.EOF
+			continue
+		}
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
 
-	end := s.position + chunkSize
-	if end > len(text) {
-		end = len(text)
-	}
+		var data struct {
+			Token *struct {
+				Text    string `json:"text"`
+				Special bool   `json:"special"`
+			} `json:"token"`
+			Details *hfDetails `json:"details"`
+			Error   string     `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+			s.err = fmt.Errorf("failed to parse stream chunk: %w", err)
+			return nil, s.err
+		}
+		if data.Error != "" {
+			s.err = providererrors.NewProviderError("huggingface", 0, "", data.Error, nil)
+			return nil, s.err
+		}
 
-	chunk := text[s.position:end]
-	s.position = end
+		if data.Token != nil && !data.Token.Special && data.Token.Text != "" {
+			s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, Text: data.Token.Text})
+		}
+		if data.Details != nil && !s.finished {
+			usage := data.Details.usage()
+			s.finish(providerutils.MapOpenAIFinishReason(data.Details.FinishReason), &usage)
+		}
+	}
+}
 
-	return &provider.StreamChunk{
-		Type: provider.ChunkTypeText,
-		Text: chunk,
-	}, nil
+func (s *tgiStream) finish(reason types.FinishReason, usage *types.Usage) {
+	s.finished = true
+	s.queue = append(s.queue, &provider.StreamChunk{
+		Type:         provider.ChunkTypeFinish,
+		FinishReason: reason,
+		Usage:        usage,
+	})
 }
 
-func (s *huggingfaceStream) Err() error {
-	return nil
+// Err returns any error that occurred during streaming
+func (s *tgiStream) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
 }
 
-func (s *huggingfaceStream) Close() error {
-	s.done = true
-	return nil
+// Close closes the stream. Later Next calls return io.EOF.
+func (s *tgiStream) Close() error {
+	s.queue = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	return s.reader.Close()
 }
@@ -0,0 +1,206 @@
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// loadingResponse is the Inference API's answer while a model is cold
+const loadingResponse = `{"error":"Model gpt2 is currently loading","estimated_time":0.01}`
+
+func TestLanguageModel_TextGenerationRetriesColdStart(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var gotPath, gotAuth string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, loadingResponse)
+			return
+		}
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = io.WriteString(w, `[{"generated_text":" world","details":{"finish_reason":"length","generated_tokens":2}}]`)
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "hf_test", BaseURL: server.URL})
+	model, _ := p.LanguageModel("gpt2")
+	maxTokens := 2
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt:        types.Prompt{Text: "hello"},
+		MaxTokens:     &maxTokens,
+		StopSequences: []string{"\n"},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 2 cold-start retries", calls.Load())
+	}
+	if gotPath != "/models/gpt2" || gotAuth != "Bearer hf_test" {
+		t.Errorf("path = %q, auth = %q", gotPath, gotAuth)
+	}
+	params, _ := gotBody["parameters"].(map[string]interface{})
+	if params["max_new_tokens"] != float64(2) || params["return_full_text"] != false || params["details"] != true {
+		t.Errorf("parameters = %v", params)
+	}
+	if result.Text != " world" || result.FinishReason != types.FinishReasonLength {
+		t.Errorf("result = %q / %q", result.Text, result.FinishReason)
+	}
+	if result.Usage.OutputTokens == nil || *result.Usage.OutputTokens != 2 {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+}
+
+func TestLanguageModel_ColdStartRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, loadingResponse)
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "hf_test", BaseURL: server.URL, ColdStartRetries: 1})
+	model, _ := p.LanguageModel("gpt2")
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "hi"}})
+
+	var providerErr *providererrors.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 provider error", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestLanguageModel_ColdStartWaitHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"error":"loading","estimated_time":60}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	model, _ := New(Config{APIKey: "hf_test", BaseURL: server.URL}).LanguageModel("gpt2")
+	_, err := model.DoGenerate(ctx, &provider.GenerateOptions{Prompt: types.Prompt{Text: "hi"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context deadline", err)
+	}
+}
+
+func TestLanguageModel_TextGenerationStream(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"Hel", "lo", "</s>"} {
+			special := token == "</s>"
+			fmt.Fprintf(w, "data: {\"token\":{\"text\":%q,\"special\":%t}}\n\n", token, special)
+		}
+		fmt.Fprint(w, "data: {\"token\":{\"text\":\"\",\"special\":true},\"generated_text\":\"Hello\",\"details\":{\"finish_reason\":\"eos_token\",\"generated_tokens\":3}}\n\n")
+	}))
+	defer server.Close()
+
+	model, _ := New(Config{APIKey: "hf_test", BaseURL: server.URL}).LanguageModel("gpt2")
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{Prompt: types.Prompt{Text: "hi"}})
+	if err != nil {
+		t.Fatalf("DoStream: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	var text strings.Builder
+	var finish *provider.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case provider.ChunkTypeFinish:
+			finish = chunk
+		}
+	}
+	if text.String() != "Hello" {
+		t.Errorf("text = %q, want special tokens skipped", text.String())
+	}
+	if finish == nil || finish.FinishReason != types.FinishReasonStop || *finish.Usage.OutputTokens != 3 {
+		t.Errorf("finish = %+v", finish)
+	}
+}
+
+func TestLanguageModel_ChatTaskOnEndpoint(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var gotPath, gotWait string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, loadingResponse)
+			return
+		}
+		gotPath, gotWait = r.URL.Path, r.Header.Get("x-wait-for-model")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"0","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":20,"completion_tokens":5,"total_tokens":25}}`)
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "hf_test", EndpointURL: server.URL + "/", Task: TaskChat, WaitForModel: true})
+	model, err := p.LanguageModel("")
+	if err != nil {
+		t.Fatalf("LanguageModel: %v", err)
+	}
+	if !model.SupportsTools() {
+		t.Error("chat models should support tools")
+	}
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "Weather in Paris?"},
+		Tools:  []types.Tool{{Name: "get_weather", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	if gotPath != "/v1/chat/completions" || gotWait != "true" {
+		t.Errorf("path = %q, x-wait-for-model = %q", gotPath, gotWait)
+	}
+	if _, ok := gotBody["messages"]; !ok {
+		t.Errorf("body = %v, want a Messages API request", gotBody)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].ToolName != "get_weather" {
+		t.Errorf("tool calls = %+v", result.ToolCalls)
+	}
+	if model.Provider() != "huggingface" {
+		t.Errorf("Provider() = %q", model.Provider())
+	}
+}
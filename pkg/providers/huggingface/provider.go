@@ -1,10 +1,17 @@
 package huggingface
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 )
 
 // Provider implements the provider.Provider interface for Hugging Face
@@ -15,26 +22,85 @@ type Provider struct {
 
 // Config contains configuration for the Hugging Face provider
 type Config struct {
-	// APIKey is the Hugging Face API token
+	// APIKey is the Hugging Face access token. If empty, the HF_TOKEN
+	// environment variable is used.
 	APIKey string
 
 	// BaseURL is the base URL for the Hugging Face Inference API (optional)
 	BaseURL string
+
+	// EndpointURL is the URL of a dedicated Inference Endpoint, e.g.
+	// "https://xyz.us-east-1.aws.endpoints.huggingface.cloud". When set,
+	// requests go to the endpoint instead of the serverless Inference API
+	// and the model ID is only reported.
+	EndpointURL string
+
+	// Task selects the API used by language models: TaskTextGeneration
+	// (default) sends a raw prompt to the text-generation task, TaskChat
+	// uses the OpenAI-compatible chat completions (Messages) API, which
+	// applies the model's chat template and supports tools.
+	Task Task
+
+	// WaitForModel asks the API to hold requests until a cold model has
+	// loaded, instead of answering 503
+	WaitForModel bool
+
+	// ColdStartRetries is how many times a request is retried while the
+	// model is loading or a scaled-to-zero endpoint is starting (HTTP 503).
+	// Default: 5; negative disables retries.
+	ColdStartRetries int
+
+	// MaxColdStartWait caps each wait between cold-start retries. The API's
+	// estimated load time is used when shorter. Default: 30 seconds.
+	MaxColdStartWait time.Duration
+}
+
+// Task is the inference task used by language models
+type Task string
+
+const (
+	// TaskTextGeneration generates a continuation of a raw text prompt
+	TaskTextGeneration Task = "text-generation"
+
+	// TaskChat uses the OpenAI-compatible chat completions API
+	TaskChat Task = "chat"
+)
+
+const (
+	defaultColdStartRetries = 5
+	defaultMaxColdStartWait = 30 * time.Second
+
+	// defaultColdStartWait is used when a 503 carries no estimated time
+	defaultColdStartWait = 5 * time.Second
+)
+
+// getAPIKey resolves the Hugging Face access token.
+// Resolution order:
+//  1. Explicit value from cfg.APIKey
+//  2. HF_TOKEN environment variable
+func getAPIKey(apiKey string) string {
+	if apiKey != "" {
+		return apiKey
+	}
+	return os.Getenv("HF_TOKEN")
 }
 
 // New creates a new Hugging Face provider with the given configuration
 func New(cfg Config) *Provider {
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = "https://api-inference.huggingface.co"
+	cfg.APIKey = getAPIKey(cfg.APIKey)
+	if cfg.Task == "" {
+		cfg.Task = TaskTextGeneration
+	}
+	if cfg.ColdStartRetries == 0 {
+		cfg.ColdStartRetries = defaultColdStartRetries
+	}
+	if cfg.MaxColdStartWait <= 0 {
+		cfg.MaxColdStartWait = defaultMaxColdStartWait
 	}
 
 	client := http.NewClient(http.Config{
-		BaseURL: baseURL,
-		Headers: map[string]string{
-			"Authorization": "Bearer " + cfg.APIKey,
-			"Content-Type":  "application/json",
-		},
+		BaseURL: cfg.baseURL(),
+		Headers: cfg.headers(),
 	})
 
 	return &Provider{
@@ -43,6 +109,30 @@ func New(cfg Config) *Provider {
 	}
 }
 
+// baseURL returns the URL requests are sent to
+func (c Config) baseURL() string {
+	switch {
+	case c.EndpointURL != "":
+		return strings.TrimSuffix(c.EndpointURL, "/")
+	case c.BaseURL != "":
+		return c.BaseURL
+	default:
+		return "https://api-inference.huggingface.co"
+	}
+}
+
+// headers returns the headers sent with every request
+func (c Config) headers() map[string]string {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if c.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.APIKey
+	}
+	if c.WaitForModel {
+		headers["x-wait-for-model"] = "true"
+	}
+	return headers
+}
+
 // Name returns the provider name
 func (p *Provider) Name() string {
 	return "huggingface"
@@ -51,7 +141,10 @@ func (p *Provider) Name() string {
 // LanguageModel returns a language model by model ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	if modelID == "" {
-		return nil, fmt.Errorf("LHugging Face requires a model ID (e.g., 'meta-llama/Llama-2-7b-chat-hf')")
+		if p.config.EndpointURL == "" {
+			return nil, fmt.Errorf("LHugging Face requires a model ID (e.g., 'meta-llama/Llama-2-7b-chat-hf')")
+		}
+		modelID = "endpoint"
 	}
 
 	return NewLanguageModel(p, modelID), nil
@@ -94,3 +187,83 @@ func (p *Provider) RerankingModel(modelID string) (provider.RerankingModel, erro
 func (p *Provider) Client() *http.Client {
 	return p.client
 }
+
+// modelPath returns the request path for a model: the model's route on the
+// serverless Inference API, or the root of a dedicated endpoint
+func (p *Provider) modelPath(modelID string) string {
+	if p.config.EndpointURL != "" {
+		return ""
+	}
+	return "/models/" + modelID
+}
+
+// post sends a request, retrying while the model is cold, and returns a
+// ProviderError for error statuses
+func (p *Provider) post(ctx context.Context, req http.Request) (*http.Response, error) {
+	var resp *http.Response
+	err := p.retryColdStart(ctx, func() error {
+		var err error
+		resp, err = p.client.Do(ctx, req)
+		if err != nil {
+			return providererrors.NewProviderError("huggingface", 0, "", err.Error(), err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return providererrors.NewProviderError("huggingface", resp.StatusCode, "", string(resp.Body), nil)
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// retryColdStart calls fn until it succeeds, fails with an error other than
+// a cold start, or the retries are used up. Between attempts it waits for
+// the load time estimated by the API, capped by MaxColdStartWait.
+func (p *Provider) retryColdStart(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		wait, cold := coldStartWait(err)
+		if !cold || attempt >= p.config.ColdStartRetries {
+			return err
+		}
+		if wait > p.config.MaxColdStartWait {
+			wait = p.config.MaxColdStartWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// coldStartWait reports whether err is a 503 from a loading model or a
+// starting endpoint, and the load time the API estimated
+func coldStartWait(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+	body, ok := "", false
+	var providerErr *providererrors.ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode == 503 {
+		body, ok = providerErr.Message, true
+	}
+	// Errors from the shared HTTP client carry the status in the message
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if i := strings.Index(e.Error(), "HTTP 503: "); i >= 0 {
+			body, ok = e.Error()[i+len("HTTP 503: "):], true
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+
+	var loading struct {
+		EstimatedTime float64 `json:"estimated_time"`
+	}
+	if json.Unmarshal([]byte(body), &loading) == nil && loading.EstimatedTime > 0 {
+		return time.Duration(loading.EstimatedTime * float64(time.Second)), true
+	}
+	return defaultColdStartWait, true
+}
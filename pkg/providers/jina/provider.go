@@ -0,0 +1,95 @@
+// Package jina provides a Jina AI reranking provider for the Go AI SDK.
+package jina
+
+import (
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+const (
+	// DefaultBaseURL is the default Jina AI API base URL
+	DefaultBaseURL = "https://api.jina.ai/v1"
+)
+
+// Reranking model IDs
+const (
+	ModelRerankerV2BaseMultilingual = "jina-reranker-v2-base-multilingual"
+	ModelRerankerM0                 = "jina-reranker-m0"
+	ModelColBERTV2                  = "jina-colbert-v2"
+)
+
+// Provider implements the provider.Provider interface for Jina AI
+type Provider struct {
+	config Config
+	client *http.Client
+}
+
+// Config contains configuration for the Jina AI provider
+type Config struct {
+	// APIKey is the Jina AI API key
+	APIKey string
+
+	// BaseURL is the base URL for the Jina AI API (default: https://api.jina.ai/v1)
+	BaseURL string
+}
+
+// New creates a new Jina AI provider with the given configuration
+func New(cfg Config) *Provider {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	client := http.NewClient(http.Config{
+		BaseURL: baseURL,
+		Headers: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", cfg.APIKey),
+		},
+	})
+
+	return &Provider{
+		config: cfg,
+		client: client,
+	}
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "jina"
+}
+
+// LanguageModel returns a language model (not supported by Jina AI)
+func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
+	return nil, fmt.Errorf("jina does not provide language models")
+}
+
+// EmbeddingModel returns an embedding model (not supported yet)
+func (p *Provider) EmbeddingModel(modelID string) (provider.EmbeddingModel, error) {
+	return nil, fmt.Errorf("jina embedding models are not supported yet")
+}
+
+// ImageModel returns an image model (not supported by Jina AI)
+func (p *Provider) ImageModel(modelID string) (provider.ImageModel, error) {
+	return nil, fmt.Errorf("jina does not provide image models")
+}
+
+// SpeechModel returns a speech synthesis model (not supported by Jina AI)
+func (p *Provider) SpeechModel(modelID string) (provider.SpeechModel, error) {
+	return nil, fmt.Errorf("jina does not provide speech synthesis models")
+}
+
+// TranscriptionModel returns a speech-to-text model (not supported by Jina AI)
+func (p *Provider) TranscriptionModel(modelID string) (provider.TranscriptionModel, error) {
+	return nil, fmt.Errorf("jina does not provide transcription models")
+}
+
+// RerankingModel returns a reranking model by ID
+func (p *Provider) RerankingModel(modelID string) (provider.RerankingModel, error) {
+	if modelID == "" {
+		modelID = ModelRerankerV2BaseMultilingual
+	}
+
+	return NewRerankingModel(p, modelID), nil
+}
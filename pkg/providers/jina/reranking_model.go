@@ -0,0 +1,135 @@
+package jina
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// RerankingModel implements the provider.RerankingModel interface for Jina AI
+type RerankingModel struct {
+	provider *Provider
+	modelID  string
+}
+
+// NewRerankingModel creates a new Jina AI reranking model
+func NewRerankingModel(provider *Provider, modelID string) *RerankingModel {
+	return &RerankingModel{
+		provider: provider,
+		modelID:  modelID,
+	}
+}
+
+// SpecificationVersion returns the specification version
+func (m *RerankingModel) SpecificationVersion() string {
+	return "v3"
+}
+
+// Provider returns the provider name
+func (m *RerankingModel) Provider() string {
+	return "jina"
+}
+
+// ModelID returns the model ID
+func (m *RerankingModel) ModelID() string {
+	return m.modelID
+}
+
+// RerankProviderOptions contains Jina-specific rerank options, passed as
+// ProviderOptions["jina"]
+type RerankProviderOptions struct {
+	// ReturnDocuments includes the documents in the response, surfaced as
+	// the "documents" provider metadata
+	ReturnDocuments *bool `json:"returnDocuments,omitempty"`
+}
+
+// DoRerank performs document reranking. Structured documents are sent as-is,
+// so multimodal models accept {"text": ...} and {"image": ...} objects.
+func (m *RerankingModel) DoRerank(ctx context.Context, opts *provider.RerankOptions) (*types.RerankResult, error) {
+	body := map[string]interface{}{
+		"model":     m.modelID,
+		"query":     opts.Query,
+		"documents": opts.Documents,
+		// Jina echoes documents by default; only return them when asked
+		"return_documents": false,
+	}
+	if opts.TopN != nil && *opts.TopN > 0 {
+		body["top_n"] = *opts.TopN
+	}
+
+	var jinaOpts RerankProviderOptions
+	if raw, ok := opts.ProviderOptions["jina"]; ok {
+		if jsonData, err := json.Marshal(raw); err == nil {
+			json.Unmarshal(jsonData, &jinaOpts) //nolint:errcheck
+		}
+	}
+	if jinaOpts.ReturnDocuments != nil {
+		body["return_documents"] = *jinaOpts.ReturnDocuments
+	}
+
+	var response jinaRerankResponse
+	err := m.provider.client.DoJSON(ctx, internalhttp.Request{
+		Method:  http.MethodPost,
+		Path:    "/rerank",
+		Body:    body,
+		Headers: opts.Headers,
+	}, &response)
+	if err != nil {
+		return nil, providererrors.NewProviderError("jina", 0, "", err.Error(), err)
+	}
+
+	return m.convertResponse(response), nil
+}
+
+// convertResponse converts a Jina response to RerankResult
+func (m *RerankingModel) convertResponse(response jinaRerankResponse) *types.RerankResult {
+	modelID := response.Model
+	if modelID == "" {
+		modelID = m.modelID
+	}
+	result := &types.RerankResult{
+		Ranking: make([]types.RerankItem, len(response.Results)),
+		Response: types.RerankResponse{
+			Timestamp: time.Now(),
+			ModelID:   modelID,
+		},
+	}
+
+	var documents []interface{}
+	for i, item := range response.Results {
+		result.Ranking[i] = types.RerankItem{
+			Index:          item.Index,
+			RelevanceScore: item.RelevanceScore,
+		}
+		if item.Document != nil {
+			documents = append(documents, item.Document)
+		}
+	}
+
+	metadata := map[string]interface{}{"totalTokens": response.Usage.TotalTokens}
+	if len(documents) > 0 {
+		metadata["documents"] = documents
+	}
+	result.ProviderMetadata = map[string]interface{}{"jina": metadata}
+
+	return result
+}
+
+// jinaRerankResponse represents the Jina rerank API response
+type jinaRerankResponse struct {
+	Model string `json:"model"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+	Results []struct {
+		Index          int         `json:"index"`
+		RelevanceScore float64     `json:"relevance_score"`
+		Document       interface{} `json:"document,omitempty"`
+	} `json:"results"`
+}
@@ -0,0 +1,56 @@
+package jina
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+func TestJinaRerank(t *testing.T) {
+	var body map[string]interface{}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("path = %s, want /rerank", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"jina-reranker-v2-base-multilingual","usage":{"total_tokens":38},"results":[{"index":2,"relevance_score":0.87},{"index":0,"relevance_score":0.05}]}`))
+	}))
+	defer srv.Close()
+
+	model, err := New(Config{BaseURL: srv.URL, APIKey: "test-key"}).RerankingModel("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topN := 2
+	result, err := model.DoRerank(t.Context(), &provider.RerankOptions{
+		Documents: []map[string]interface{}{{"text": "net/http"}, {"text": "crypto"}, {"text": "JWT"}},
+		Query:     "authentication",
+		TopN:      &topN,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body["model"] != ModelRerankerV2BaseMultilingual || body["top_n"] != float64(2) || body["return_documents"] != false {
+		t.Errorf("unexpected request body: %v", body)
+	}
+	if docs, _ := body["documents"].([]interface{}); len(docs) != 3 {
+		t.Errorf("documents = %v", body["documents"])
+	}
+	if auth != "Bearer test-key" {
+		t.Errorf("authorization = %q", auth)
+	}
+	if len(result.Ranking) != 2 || result.Ranking[0].Index != 2 || result.Ranking[0].RelevanceScore != 0.87 {
+		t.Errorf("unexpected ranking: %+v", result.Ranking)
+	}
+	meta, _ := result.ProviderMetadata.(map[string]interface{})["jina"].(map[string]interface{})
+	if meta["totalTokens"] != 38 {
+		t.Errorf("metadata = %v", result.ProviderMetadata)
+	}
+}
@@ -0,0 +1,301 @@
+// Package local runs embedding models in-process, e.g. sentence-transformer
+// ONNX exports, for offline use and low-latency semantic routing.
+//
+// The package has no native dependencies: inference is delegated to a
+// Session, which callers implement with the runtime of their choice. An
+// adapter for onnxruntime-go (github.com/yalue/onnxruntime_go) looks like:
+//
+//	session := local.SessionFunc(func(ctx context.Context, b local.Batch) (*local.Output, error) {
+//		shape := ort.NewShape(int64(len(b.InputIDs)), int64(b.SequenceLength))
+//		ids, _ := ort.NewTensor(shape, flatten(b.InputIDs))
+//		mask, _ := ort.NewTensor(shape, flatten(b.AttentionMask))
+//		types, _ := ort.NewTensor(shape, flatten(b.TokenTypeIDs))
+//		hidden, _ := ort.NewEmptyTensor[float32](ort.NewShape(shape[0], shape[1], 384))
+//		defer destroyAll(ids, mask, types, hidden)
+//		err := ortSession.Run([]ort.Value{ids, mask, types}, []ort.Value{hidden})
+//		return &local.Output{TokenEmbeddings: unflatten(hidden.GetData(), shape)}, err
+//	})
+//	model, err := local.NewEmbeddingModel(local.Config{
+//		ModelID:   "all-MiniLM-L6-v2",
+//		Tokenizer: tokenizer,
+//		Session:   session,
+//	})
+package local
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Batch is the padded model input for a group of texts. All rows have
+// SequenceLength entries.
+type Batch struct {
+	InputIDs       [][]int64
+	AttentionMask  [][]int64
+	TokenTypeIDs   [][]int64
+	SequenceLength int
+}
+
+// Output is the result of running a Batch. Sessions return either
+// per-token hidden states, which are pooled by the model, or ready-made
+// sentence embeddings.
+type Output struct {
+	// TokenEmbeddings is the last hidden state, [batch][sequence][dim]
+	TokenEmbeddings [][][]float32
+
+	// SentenceEmbeddings is used as-is when set, [batch][dim]
+	SentenceEmbeddings [][]float32
+}
+
+// Session runs a model on a batch of token IDs
+type Session interface {
+	Run(ctx context.Context, batch Batch) (*Output, error)
+}
+
+// SessionFunc adapts a function to the Session interface
+type SessionFunc func(ctx context.Context, batch Batch) (*Output, error)
+
+// Run calls f(ctx, batch)
+func (f SessionFunc) Run(ctx context.Context, batch Batch) (*Output, error) {
+	return f(ctx, batch)
+}
+
+// Pooling selects how token embeddings are combined
+type Pooling string
+
+const (
+	// PoolingMean averages token embeddings weighted by the attention mask
+	PoolingMean Pooling = "mean"
+
+	// PoolingCLS uses the embedding of the first ([CLS]) token
+	PoolingCLS Pooling = "cls"
+)
+
+// Config configures a local embedding model
+type Config struct {
+	// ModelID is reported by ModelID (required)
+	ModelID string
+
+	// Tokenizer converts text to token IDs (required)
+	Tokenizer Tokenizer
+
+	// Session runs the model (required)
+	Session Session
+
+	// Pooling combines token embeddings (default: PoolingMean)
+	Pooling Pooling
+
+	// DisableNormalize skips L2 normalization of the embeddings
+	DisableNormalize bool
+
+	// MaxSequenceLength truncates inputs (default: 256)
+	MaxSequenceLength int
+
+	// MaxBatchSize is the number of texts per Session.Run (default: 32)
+	MaxBatchSize int
+
+	// Parallel reports that Session is safe for concurrent Run calls
+	Parallel bool
+}
+
+// EmbeddingModel implements the provider.EmbeddingModel interface for
+// in-process models
+type EmbeddingModel struct {
+	config Config
+}
+
+// NewEmbeddingModel creates a local embedding model
+func NewEmbeddingModel(config Config) (*EmbeddingModel, error) {
+	if config.ModelID == "" || config.Tokenizer == nil || config.Session == nil {
+		return nil, fmt.Errorf("local embedding model requires ModelID, Tokenizer and Session")
+	}
+	if config.Pooling == "" {
+		config.Pooling = PoolingMean
+	}
+	if config.MaxSequenceLength <= 0 {
+		config.MaxSequenceLength = 256
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 32
+	}
+	return &EmbeddingModel{config: config}, nil
+}
+
+// SpecificationVersion returns the specification version
+func (m *EmbeddingModel) SpecificationVersion() string {
+	return "v3"
+}
+
+// Provider returns the provider name
+func (m *EmbeddingModel) Provider() string {
+	return "local"
+}
+
+// ModelID returns the model ID
+func (m *EmbeddingModel) ModelID() string {
+	return m.config.ModelID
+}
+
+// MaxEmbeddingsPerCall returns the maximum number of embeddings per call.
+// Larger inputs are split into batches internally, so there is no limit.
+func (m *EmbeddingModel) MaxEmbeddingsPerCall() int {
+	return 0
+}
+
+// SupportsParallelCalls returns whether parallel calls are supported
+func (m *EmbeddingModel) SupportsParallelCalls() bool {
+	return m.config.Parallel
+}
+
+// DoEmbed performs embedding for a single input
+func (m *EmbeddingModel) DoEmbed(ctx context.Context, input string, opts *provider.EmbedModelOptions) (*types.EmbeddingResult, error) {
+	result, err := m.DoEmbedMany(ctx, []string{input}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &types.EmbeddingResult{Embedding: result.Embeddings[0], Usage: result.Usage}, nil
+}
+
+// DoEmbedMany performs embedding for multiple inputs in batches of
+// MaxBatchSize. Usage counts the tokens fed to the model.
+func (m *EmbeddingModel) DoEmbedMany(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+	result := &types.EmbeddingsResult{Embeddings: make([][]float64, 0, len(inputs))}
+	for start := 0; start < len(inputs); start += m.config.MaxBatchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+m.config.MaxBatchSize, len(inputs))
+		batch, tokens := m.encode(inputs[start:end])
+
+		out, err := m.config.Session.Run(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("local embedding: %w", err)
+		}
+		embeddings, err := m.pool(batch, out)
+		if err != nil {
+			return nil, err
+		}
+		result.Embeddings = append(result.Embeddings, embeddings...)
+		result.Usage.InputTokens += tokens
+	}
+	result.Usage.TotalTokens = result.Usage.InputTokens
+	return result, nil
+}
+
+// encode tokenizes texts and pads them to the longest sequence
+func (m *EmbeddingModel) encode(texts []string) (Batch, int) {
+	encoded := make([][]int64, len(texts))
+	longest, tokens := 0, 0
+	for i, text := range texts {
+		encoded[i] = m.config.Tokenizer.Encode(text, m.config.MaxSequenceLength)
+		longest = max(longest, len(encoded[i]))
+		tokens += len(encoded[i])
+	}
+
+	pad := m.config.Tokenizer.PadID()
+	batch := Batch{
+		InputIDs:       make([][]int64, len(texts)),
+		AttentionMask:  make([][]int64, len(texts)),
+		TokenTypeIDs:   make([][]int64, len(texts)),
+		SequenceLength: longest,
+	}
+	for i, ids := range encoded {
+		row := make([]int64, longest)
+		mask := make([]int64, longest)
+		for j := range row {
+			if j < len(ids) {
+				row[j], mask[j] = ids[j], 1
+			} else {
+				row[j] = pad
+			}
+		}
+		batch.InputIDs[i] = row
+		batch.AttentionMask[i] = mask
+		batch.TokenTypeIDs[i] = make([]int64, longest)
+	}
+	return batch, tokens
+}
+
+// pool converts session output into one normalized vector per input
+func (m *EmbeddingModel) pool(batch Batch, out *Output) ([][]float64, error) {
+	n := len(batch.InputIDs)
+	embeddings := make([][]float64, n)
+
+	switch {
+	case out == nil:
+		return nil, fmt.Errorf("local embedding: session returned no output")
+	case out.SentenceEmbeddings != nil:
+		if len(out.SentenceEmbeddings) != n {
+			return nil, fmt.Errorf("local embedding: session returned %d embeddings for %d inputs", len(out.SentenceEmbeddings), n)
+		}
+		for i, v := range out.SentenceEmbeddings {
+			embeddings[i] = toFloat64(v)
+		}
+	default:
+		if len(out.TokenEmbeddings) != n {
+			return nil, fmt.Errorf("local embedding: session returned %d sequences for %d inputs", len(out.TokenEmbeddings), n)
+		}
+		for i, tokens := range out.TokenEmbeddings {
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("local embedding: empty sequence output for input %d", i)
+			}
+			if m.config.Pooling == PoolingCLS {
+				embeddings[i] = toFloat64(tokens[0])
+				continue
+			}
+			embeddings[i] = meanPool(tokens, batch.AttentionMask[i])
+		}
+	}
+
+	if !m.config.DisableNormalize {
+		for _, v := range embeddings {
+			normalize(v)
+		}
+	}
+	return embeddings, nil
+}
+
+// meanPool averages the token vectors whose mask is set
+func meanPool(tokens [][]float32, mask []int64) []float64 {
+	sum := make([]float64, len(tokens[0]))
+	count := 0
+	for j, vec := range tokens {
+		if j < len(mask) && mask[j] == 0 {
+			continue
+		}
+		for k, x := range vec {
+			sum[k] += float64(x)
+		}
+		count++
+	}
+	if count > 0 {
+		for k := range sum {
+			sum[k] /= float64(count)
+		}
+	}
+	return sum
+}
+
+func normalize(v []float64) {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm = math.Sqrt(norm); norm > 0 {
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+}
+
+func toFloat64(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package local
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testVocab = []string{"[PAD]", "[UNK]", "[CLS]", "[SEP]", "hello", "world", "play", "##ing", "!", "cafe", "中"}
+
+func testTokenizer(t *testing.T) *WordPieceTokenizer {
+	t.Helper()
+	tok, err := ReadVocab(strings.NewReader(strings.Join(testVocab, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenizer, err := NewWordPieceTokenizer(tok, WordPieceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenizer
+}
+
+func TestWordPieceTokenizer(t *testing.T) {
+	t.Parallel()
+
+	tok := testTokenizer(t)
+	if got := tok.Tokens("Hello, PLAYING world! Café 中 xyz"); !reflect.DeepEqual(got, []string{
+		"hello", "[UNK]", "play", "##ing", "world", "!", "cafe", "中", "[UNK]",
+	}) {
+		t.Errorf("unexpected tokens %v", got)
+	}
+	if got := tok.Encode("hello world hello", 4); !reflect.DeepEqual(got, []int64{2, 4, 5, 3}) {
+		t.Errorf("expected truncation to keep [SEP], got %v", got)
+	}
+	if _, err := NewWordPieceTokenizer([]string{"a"}, WordPieceOptions{}); err == nil {
+		t.Error("expected error for vocabulary without special tokens")
+	}
+}
+
+func TestEmbeddingModel_MeanPooling(t *testing.T) {
+	t.Parallel()
+
+	var batches []Batch
+	// Each token's hidden state is [id, 1], so pooled vectors depend on the
+	// unpadded tokens only
+	session := SessionFunc(func(ctx context.Context, b Batch) (*Output, error) {
+		batches = append(batches, b)
+		out := make([][][]float32, len(b.InputIDs))
+		for i, row := range b.InputIDs {
+			for _, id := range row {
+				out[i] = append(out[i], []float32{float32(id), 1})
+			}
+		}
+		return &Output{TokenEmbeddings: out}, nil
+	})
+	model, err := NewEmbeddingModel(Config{ModelID: "test", Tokenizer: testTokenizer(t), Session: session, MaxBatchSize: 2, DisableNormalize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := model.DoEmbedMany(context.Background(), []string{"hello", "hello world", "world"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 2 || batches[0].SequenceLength != 4 || !reflect.DeepEqual(batches[0].AttentionMask[0], []int64{1, 1, 1, 0}) {
+		t.Fatalf("unexpected batches %+v", batches)
+	}
+	// [CLS]=2 hello=4 [SEP]=3 -> mean 3; the pad token must be ignored
+	if res.Embeddings[0][0] != 3 || res.Embeddings[1][0] != 3.5 || res.Embeddings[2][0] != 10.0/3 {
+		t.Errorf("unexpected pooled embeddings %v", res.Embeddings)
+	}
+	if res.Usage.InputTokens != 10 {
+		t.Errorf("expected 10 input tokens, got %d", res.Usage.InputTokens)
+	}
+}
+
+func TestEmbeddingModel_SentenceOutputNormalized(t *testing.T) {
+	t.Parallel()
+
+	session := SessionFunc(func(ctx context.Context, b Batch) (*Output, error) {
+		return &Output{SentenceEmbeddings: [][]float32{{3, 4}}}, nil
+	})
+	model, _ := NewEmbeddingModel(Config{ModelID: "test", Tokenizer: testTokenizer(t), Session: session})
+
+	res, err := model.DoEmbed(context.Background(), "hello", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(res.Embedding[0]-0.6) > 1e-9 || math.Abs(res.Embedding[1]-0.8) > 1e-9 {
+		t.Errorf("expected unit vector, got %v", res.Embedding)
+	}
+}
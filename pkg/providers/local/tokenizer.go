@@ -0,0 +1,194 @@
+package local
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tokenizer converts text into model input IDs
+type Tokenizer interface {
+	// Encode returns the token IDs for text including any special tokens,
+	// truncated to maxLen
+	Encode(text string, maxLen int) []int64
+
+	// PadID is the ID used to pad sequences in a batch
+	PadID() int64
+}
+
+// WordPieceTokenizer implements the BERT tokenizer used by
+// sentence-transformers models such as all-MiniLM-L6-v2 and bge-small
+type WordPieceTokenizer struct {
+	vocab     map[string]int64
+	lowercase bool
+
+	unkID, clsID, sepID, padID int64
+}
+
+// WordPieceOptions configures a WordPieceTokenizer
+type WordPieceOptions struct {
+	// Cased keeps case and accents (default: lowercase and strip accents,
+	// as in uncased models)
+	Cased bool
+}
+
+// NewWordPieceTokenizer creates a tokenizer from a vocabulary in ID order,
+// as listed in a model's vocab.txt. The vocabulary must contain [UNK],
+// [CLS], [SEP] and [PAD].
+func NewWordPieceTokenizer(vocab []string, opts WordPieceOptions) (*WordPieceTokenizer, error) {
+	t := &WordPieceTokenizer{vocab: make(map[string]int64, len(vocab)), lowercase: !opts.Cased}
+	for i, tok := range vocab {
+		t.vocab[tok] = int64(i)
+	}
+	for tok, id := range map[string]*int64{"[UNK]": &t.unkID, "[CLS]": &t.clsID, "[SEP]": &t.sepID, "[PAD]": &t.padID} {
+		v, ok := t.vocab[tok]
+		if !ok {
+			return nil, fmt.Errorf("vocabulary is missing %s", tok)
+		}
+		*id = v
+	}
+	return t, nil
+}
+
+// LoadWordPieceTokenizer reads a vocab.txt file with one token per line
+func LoadWordPieceTokenizer(path string, opts WordPieceOptions) (*WordPieceTokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vocab, err := ReadVocab(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewWordPieceTokenizer(vocab, opts)
+}
+
+// ReadVocab reads one token per line
+func ReadVocab(r io.Reader) ([]string, error) {
+	var vocab []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		vocab = append(vocab, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return vocab, scanner.Err()
+}
+
+// PadID implements Tokenizer
+func (t *WordPieceTokenizer) PadID() int64 {
+	return t.padID
+}
+
+// Encode implements Tokenizer, wrapping the tokens in [CLS] ... [SEP]
+func (t *WordPieceTokenizer) Encode(text string, maxLen int) []int64 {
+	ids := []int64{t.clsID}
+	for _, word := range t.basicTokenize(text) {
+		ids = append(ids, t.wordPiece(word)...)
+	}
+	if maxLen > 1 && len(ids) > maxLen-1 {
+		ids = ids[:maxLen-1]
+	}
+	return append(ids, t.sepID)
+}
+
+// Tokens returns the vocabulary tokens for text without special tokens,
+// which is useful for debugging
+func (t *WordPieceTokenizer) Tokens(text string) []string {
+	byID := make(map[int64]string, len(t.vocab))
+	for tok, id := range t.vocab {
+		byID[id] = tok
+	}
+	ids := t.Encode(text, 0)
+	out := make([]string, 0, len(ids)-2)
+	for _, id := range ids[1 : len(ids)-1] {
+		out = append(out, byID[id])
+	}
+	return out
+}
+
+// basicTokenize cleans text and splits it on whitespace and punctuation,
+// isolating CJK characters
+func (t *WordPieceTokenizer) basicTokenize(text string) []string {
+	if t.lowercase {
+		text = strings.ToLower(text)
+		// Strip accents: decompose, then drop combining marks
+		text = strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, norm.NFD.String(text))
+	}
+
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r == 0 || r == unicode.ReplacementChar || (unicode.IsControl(r) && !unicode.IsSpace(r)):
+			continue
+		case unicode.IsSpace(r):
+			flush()
+		case isPunctuation(r) || isCJK(r):
+			flush()
+			words = append(words, string(r))
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return words
+}
+
+// wordPiece splits word into the longest matching vocabulary pieces,
+// returning [UNK] when no split exists
+func (t *WordPieceTokenizer) wordPiece(word string) []int64 {
+	runes := []rune(word)
+	if len(runes) > 100 {
+		return []int64{t.unkID}
+	}
+	var ids []int64
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		found := false
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = "##" + piece
+			}
+			if id, ok := t.vocab[piece]; ok {
+				ids = append(ids, id)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return []int64{t.unkID}
+		}
+		start = end
+	}
+	return ids
+}
+
+// isPunctuation treats all non-alphanumeric ASCII symbols as punctuation,
+// matching BERT
+func isPunctuation(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || (r >= 0x3400 && r <= 0x4DBF) || (r >= 0xF900 && r <= 0xFAFF)
+}
@@ -129,9 +129,25 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 			"type": opts.ResponseFormat.Type,
 		}
 	}
+	if opts.Constraint != nil && opts.Constraint.Kind == types.ConstraintJSONSchema {
+		body["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "response",
+				"schema": opts.Constraint.Schema,
+			},
+		}
+	}
 	return body
 }
 
+// SupportsConstraint reports which decoding constraints Ollama enforces natively.
+// Ollama compiles JSON schemas to a llama.cpp grammar server-side but does not
+// accept raw grammars or regexes, so those fall back to output validation.
+func (m *LanguageModel) SupportsConstraint(kind types.ConstraintKind) bool {
+	return kind == types.ConstraintJSONSchema
+}
+
 func (m *LanguageModel) convertResponse(response ollamaResponse) *types.GenerateResult {
 	if len(response.Choices) == 0 {
 		return &types.GenerateResult{
@@ -1,10 +1,12 @@
 package ollama
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 // Provider implements the provider.Provider interface for Ollama
@@ -44,6 +46,17 @@ func (p *Provider) Name() string {
 	return "ollama"
 }
 
+// Ping checks that the API is reachable and the credentials are valid by
+// listing installed models. It does not consume tokens.
+func (p *Provider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return providerutils.PingEndpoint(ctx, p.client, p.Name(), "/api/tags")
+}
+
+// ListModels lists the models installed to the configured credentials.
+func (p *Provider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return providerutils.ListModelsEndpoint(ctx, p.client, p.Name(), "/api/tags", nil)
+}
+
 // LanguageModel returns a language model by ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	if modelID == "" {
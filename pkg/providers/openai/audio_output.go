@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"encoding/base64"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// openAIAudio is the audio object returned on assistant messages (and, in
+// streaming, on deltas) when the request asks for the "audio" modality
+type openAIAudio struct {
+	ID         string `json:"id,omitempty"`
+	Data       string `json:"data,omitempty"` // base64-encoded audio
+	ExpiresAt  int64  `json:"expires_at,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// applyAudioOutputOptions maps the "modalities" and "audio" provider options
+// onto the request body.
+//
+// Example:
+//
+//	ProviderOptions: map[string]interface{}{
+//		"openai": map[string]interface{}{
+//			"modalities": []string{"text", "audio"},
+//			"audio":      map[string]interface{}{"voice": "alloy", "format": "wav"},
+//		},
+//	}
+func applyAudioOutputOptions(body map[string]interface{}, openaiOpts map[string]interface{}) {
+	if modalities, ok := openaiOpts["modalities"]; ok {
+		body["modalities"] = modalities
+	}
+	if audio, ok := openaiOpts["audio"].(map[string]interface{}); ok {
+		body["audio"] = audio
+	}
+}
+
+// requestedAudioSettings returns the voice and format requested through the
+// "audio" provider option, so they can be reported on the audio output
+func requestedAudioSettings(opts *provider.GenerateOptions) (voice, format string) {
+	if opts == nil || opts.ProviderOptions == nil {
+		return "", ""
+	}
+	openaiOpts, ok := opts.ProviderOptions["openai"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	audio, ok := openaiOpts["audio"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	voice, _ = audio["voice"].(string)
+	format, _ = audio["format"].(string)
+	return voice, format
+}
+
+// convertOpenAIAudio decodes an OpenAI audio object. Undecodable data is
+// dropped rather than failing the whole response, since the transcript is
+// still useful on its own.
+func convertOpenAIAudio(audio *openAIAudio, voice, format string) *types.AudioOutput {
+	if audio == nil {
+		return nil
+	}
+	out := &types.AudioOutput{
+		ID:         audio.ID,
+		Transcript: audio.Transcript,
+		Voice:      voice,
+		Format:     format,
+		ExpiresAt:  audio.ExpiresAt,
+	}
+	if audio.Data != "" {
+		if data, err := base64.StdEncoding.DecodeString(audio.Data); err == nil {
+			out.Data = data
+		}
+	}
+	return out
+}
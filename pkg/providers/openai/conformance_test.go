@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/providertest"
+)
+
+// fakeChatServer answers chat completions like the OpenAI API: text for
+// plain prompts, a get_weather call when tools are offered, as SSE when
+// streaming is requested
+func fakeChatServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool          `json:"stream"`
+			Tools  []interface{} `json:"tools"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		withTools := len(req.Tools) > 0
+
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			if withTools {
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Paris\"}"}}]}}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`+"\n\n")
+			} else {
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Hello"}}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"content":" there!"}}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+
+		message := `{"role":"assistant","content":"Hello there!"}`
+		finish := "stop"
+		if withTools {
+			message = `{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]}`
+			finish = "tool_calls"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":%s,"finish_reason":%q}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`, message, finish)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func failingChatServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":{"message":"failure %d","type":"test_error","code":"test"}}`, status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLanguageModel_Conformance(t *testing.T) {
+	providertest.RunConformance(t, providertest.Config{
+		NewModel: func(t *testing.T) provider.LanguageModel {
+			return NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: fakeChatServer(t).URL}), "gpt-4o")
+		},
+		NewFailingModel: func(t *testing.T, status int) provider.LanguageModel {
+			return NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: failingChatServer(t, status).URL}), "gpt-4o")
+		},
+	})
+}
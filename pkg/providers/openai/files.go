@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
+)
+
+// MaxFileSize is the largest file the OpenAI Files API accepts
+const MaxFileSize = 512 << 20
+
+// openaiFile is a file object of the Files API
+type openaiFile struct {
+	ID        string `json:"id"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+}
+
+func (f openaiFile) toFile() provider.File {
+	return provider.File{
+		ID:        f.ID,
+		Filename:  f.Filename,
+		MimeType:  providerutils.FileMimeType(provider.FileUploadOptions{Filename: f.Filename}),
+		Size:      f.Bytes,
+		CreatedAt: time.Unix(f.CreatedAt, 0),
+		Purpose:   f.Purpose,
+	}
+}
+
+// UploadFile uploads a file to the Files API. Purpose defaults to
+// "user_data", which allows the file to be referenced in prompts.
+func (p *Provider) UploadFile(ctx context.Context, opts provider.FileUploadOptions) (*provider.File, error) {
+	purpose := opts.Purpose
+	if purpose == "" {
+		purpose = "user_data"
+	}
+	body, contentType, err := providerutils.MultipartFileUpload(opts, MaxFileSize, map[string]string{"purpose": purpose})
+	if err != nil {
+		return nil, err
+	}
+	var f openaiFile
+	if err := p.doFiles(ctx, internalhttp.Request{
+		Method:  "POST",
+		Path:    "/files",
+		RawBody: body,
+		Headers: map[string]string{"Content-Type": contentType},
+	}, &f); err != nil {
+		return nil, err
+	}
+	file := f.toFile()
+	file.MimeType = providerutils.FileMimeType(opts)
+	return &file, nil
+}
+
+// ListFiles returns the organization's files
+func (p *Provider) ListFiles(ctx context.Context) ([]provider.File, error) {
+	var resp struct {
+		Data []openaiFile `json:"data"`
+	}
+	if err := p.doFiles(ctx, internalhttp.Request{Method: "GET", Path: "/files"}, &resp); err != nil {
+		return nil, err
+	}
+	files := make([]provider.File, len(resp.Data))
+	for i, f := range resp.Data {
+		files[i] = f.toFile()
+	}
+	return files, nil
+}
+
+// GetFile returns a file's metadata
+func (p *Provider) GetFile(ctx context.Context, fileID string) (*provider.File, error) {
+	var f openaiFile
+	if err := p.doFiles(ctx, internalhttp.Request{Method: "GET", Path: "/files/" + url.PathEscape(fileID)}, &f); err != nil {
+		return nil, err
+	}
+	file := f.toFile()
+	return &file, nil
+}
+
+// DeleteFile deletes a file
+func (p *Provider) DeleteFile(ctx context.Context, fileID string) error {
+	return p.doFiles(ctx, internalhttp.Request{Method: "DELETE", Path: "/files/" + url.PathEscape(fileID)}, nil)
+}
+
+// doFiles performs a Files API request, decoding the response into result
+// when it is not nil
+func (p *Provider) doFiles(ctx context.Context, req internalhttp.Request, result interface{}) error {
+	resp, err := p.client.Do(ctx, req)
+	if err != nil {
+		return providererrors.NewProviderError("openai", 0, "", err.Error(), err)
+	}
+	if resp.StatusCode >= 400 {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(resp.Body, &body)
+		msg := body.Error.Message
+		if msg == "" {
+			msg = string(resp.Body)
+		}
+		var cause error
+		if resp.StatusCode == 404 {
+			cause = provider.ErrFileNotFound
+		}
+		return providererrors.NewProviderError("openai", resp.StatusCode, body.Error.Code, msg, cause)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, result); err != nil {
+		return fmt.Errorf("failed to decode files response: %w", err)
+	}
+	return nil
+}
+
+var _ provider.FileManager = (*Provider)(nil)
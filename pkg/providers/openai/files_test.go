@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+func TestProvider_Files(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/files":
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+				t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("missing file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if string(data) != "%PDF-1.7" || header.Filename != "report.pdf" || r.FormValue("purpose") != "user_data" {
+				t.Errorf("unexpected upload: %q %q %q", data, header.Filename, r.FormValue("purpose"))
+			}
+			if ct := header.Header.Get("Content-Type"); ct != "application/pdf" {
+				t.Errorf("part Content-Type = %q", ct)
+			}
+			w.Write([]byte(`{"id":"file-1","object":"file","bytes":8,"created_at":1700000000,"filename":"report.pdf","purpose":"user_data"}`))
+		case r.Method == "GET" && r.URL.Path == "/v1/files":
+			w.Write([]byte(`{"data":[{"id":"file-1","bytes":8,"created_at":1700000000,"filename":"report.pdf","purpose":"user_data"}]}`))
+		case r.Method == "DELETE" && r.URL.Path == "/v1/files/file-1":
+			w.Write([]byte(`{"id":"file-1","deleted":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"No such File object","code":null}}`))
+		}
+	}))
+	defer server.Close()
+
+	p := New(Config{APIKey: "test", BaseURL: server.URL + "/v1"})
+	ctx := context.Background()
+
+	file, err := p.UploadFile(ctx, provider.FileUploadOptions{Data: []byte("%PDF-1.7"), Filename: "report.pdf"})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if file.ID != "file-1" || file.Size != 8 || file.MimeType != "application/pdf" || file.CreatedAt.Unix() != 1700000000 {
+		t.Errorf("file = %+v", file)
+	}
+
+	files, err := p.ListFiles(ctx)
+	if err != nil || len(files) != 1 || files[0].ID != "file-1" {
+		t.Errorf("ListFiles = %+v, %v", files, err)
+	}
+	if err := p.DeleteFile(ctx, "file-1"); err != nil {
+		t.Errorf("DeleteFile failed: %v", err)
+	}
+	if _, err := p.GetFile(ctx, "file-missing"); !errors.Is(err, provider.ErrFileNotFound) {
+		t.Errorf("GetFile error = %v, want ErrFileNotFound", err)
+	}
+}
+
+func TestProvider_UploadFileTooLarge(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{APIKey: "test"})
+	_, err := p.UploadFile(context.Background(), provider.FileUploadOptions{Data: make([]byte, MaxFileSize+1), Filename: "big.bin"})
+	if !errors.Is(err, provider.ErrFileTooLarge) {
+		t.Errorf("err = %v, want ErrFileTooLarge", err)
+	}
+}
@@ -80,7 +80,11 @@ func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateO
 	}
 
 	// Convert response to GenerateResult
-	return m.convertResponse(response), nil
+	result := m.convertResponse(response)
+	if result.Audio != nil {
+		result.Audio.Voice, result.Audio.Format = requestedAudioSettings(opts)
+	}
+	return result, nil
 }
 
 // DoStream performs streaming text generation
@@ -102,7 +106,9 @@ func (m *LanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOpt
 	}
 
 	// Create stream wrapper
-	return newOpenAIStream(httpResp.Body), nil
+	stream := newOpenAIStream(httpResp.Body)
+	stream.audioVoice, stream.audioFormat = requestedAudioSettings(opts)
+	return stream, nil
 }
 
 // buildRequestBody builds the OpenAI API request body
@@ -111,6 +117,10 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 		"model":  m.modelID,
 		"stream": stream,
 	}
+	if stream {
+		// Report usage in the final stream event
+		body["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
 
 	// Extract store flag early — needed before message conversion so we can
 	// filter unencrypted reasoning parts from assistant messages when store=false.
@@ -204,6 +214,11 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 		}
 	}
 
+	// Add native decoding constraint if present
+	if opts.Constraint != nil {
+		m.applyConstraint(body, opts.Constraint)
+	}
+
 	// Map top-level Reasoning to OpenAI reasoning_effort.
 	// none → "disabled", minimal/low → "low", medium → "medium", high/xhigh → "high".
 	// provider-default → omit (let OpenAI use its own default).
@@ -238,12 +253,77 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 			if v, ok := openaiOpts["textVerbosity"].(string); ok {
 				body["verbosity"] = v
 			}
+			// Audio-output models (e.g. gpt-4o-audio-preview) speak their
+			// response when "audio" is among the requested modalities.
+			applyAudioOutputOptions(body, openaiOpts)
 		}
 	}
 
+	// Request metadata: the user entry maps to "user"; the API only accepts
+	// the metadata map itself for stored completions.
+	if user := opts.Metadata[provider.MetadataKeyUser]; user != "" {
+		if _, ok := body["user"]; !ok {
+			body["user"] = user
+		}
+	}
+	if len(opts.Metadata) > 0 && storeExplicit && store {
+		body["metadata"] = opts.Metadata
+	}
+
 	return body
 }
 
+// SupportsConstraint reports which decoding constraints can be enforced natively.
+// The hosted OpenAI API supports JSON schemas via structured outputs;
+// vLLM and llama.cpp servers additionally support regex and/or grammars.
+func (m *LanguageModel) SupportsConstraint(kind types.ConstraintKind) bool {
+	switch m.provider.config.GuidedDecoding {
+	case GuidedDecodingVLLM:
+		return true
+	case GuidedDecodingLlamaCpp:
+		return kind == types.ConstraintGrammar || kind == types.ConstraintJSONSchema
+	default:
+		return kind == types.ConstraintJSONSchema
+	}
+}
+
+// applyConstraint writes the constraint into the request body using the
+// parameter names of the configured backend
+func (m *LanguageModel) applyConstraint(body map[string]interface{}, c *types.Constraint) {
+	switch m.provider.config.GuidedDecoding {
+	case GuidedDecodingVLLM:
+		switch c.Kind {
+		case types.ConstraintRegex:
+			body["guided_regex"] = c.Pattern
+		case types.ConstraintGrammar:
+			body["guided_grammar"] = c.Grammar
+		case types.ConstraintJSONSchema:
+			body["guided_json"] = c.Schema
+		}
+	case GuidedDecodingLlamaCpp:
+		switch c.Kind {
+		case types.ConstraintGrammar:
+			body["grammar"] = c.Grammar
+		case types.ConstraintJSONSchema:
+			body["json_schema"] = c.Schema
+		}
+	default:
+		if c.Kind == types.ConstraintJSONSchema {
+			// Strict mode rejects schemas with optional or additional
+			// properties; those are sent non-strict and validated by the SDK
+			schema, strict := strictJSONSchema(c.Schema)
+			body["response_format"] = map[string]interface{}{
+				"type": "json_schema",
+				"json_schema": map[string]interface{}{
+					"name":   "response",
+					"schema": schema,
+					"strict": strict,
+				},
+			}
+		}
+	}
+}
+
 // convertResponse converts an OpenAI response to GenerateResult
 // Updated in v6.0 to support detailed usage tracking
 func (m *LanguageModel) convertResponse(response openAIResponse) *types.GenerateResult {
@@ -261,6 +341,9 @@ func (m *LanguageModel) convertResponse(response openAIResponse) *types.Generate
 			result.Text = choice.Message.Content
 		}
 
+		// Extract spoken audio (audio-output models)
+		result.Audio = convertOpenAIAudio(choice.Message.Audio, "", "")
+
 		// Extract tool calls
 		if len(choice.Message.ToolCalls) > 0 {
 			result.ToolCalls = make([]types.ToolCall, len(choice.Message.ToolCalls))
@@ -408,6 +491,7 @@ type openAIMessage struct {
 	Role      string           `json:"role"`
 	Content   string           `json:"content"`
 	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+	Audio     *openAIAudio     `json:"audio,omitempty"`
 }
 
 // openAIToolCall represents an OpenAI tool call
@@ -472,6 +556,15 @@ type openAIStream struct {
 	err           error
 	toolCallAccum map[int]*openAIStreamAccumToolCall // keyed by tool call index
 	flushQueue    []*provider.StreamChunk            // fully assembled chunks ready to emit
+
+	// pendingFinish is held back until the usage report, which OpenAI sends
+	// in a separate event after the finish reason
+	pendingFinish *provider.StreamChunk
+	usage         *types.Usage
+
+	// Requested audio voice and format, reported on audio chunks
+	audioVoice  string
+	audioFormat string
 }
 
 // newOpenAIStream creates a new OpenAI stream
@@ -488,8 +581,13 @@ func (s *openAIStream) Read(p []byte) (n int, err error) {
 	return s.reader.Read(p)
 }
 
-// Close implements io.Closer
+// Close implements io.Closer. Later Next calls return io.EOF, including for
+// events the parser had already buffered.
 func (s *openAIStream) Close() error {
+	s.flushQueue = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
 	return s.reader.Close()
 }
 
@@ -510,12 +608,18 @@ func (s *openAIStream) Next() (*provider.StreamChunk, error) {
 	event, err := s.parser.Next()
 	if err != nil {
 		s.err = err
+		if s.flushFinish() {
+			return s.Next()
+		}
 		return nil, err
 	}
 
 	// Check for stream completion
 	if streaming.IsStreamDone(event) {
 		s.err = io.EOF
+		if s.flushFinish() {
+			return s.Next()
+		}
 		return nil, io.EOF
 	}
 
@@ -525,7 +629,8 @@ func (s *openAIStream) Next() (*provider.StreamChunk, error) {
 	var chunkData struct {
 		Choices []struct {
 			Delta struct {
-				Content   string `json:"content"`
+				Content   string       `json:"content"`
+				Audio     *openAIAudio `json:"audio,omitempty"`
 				ToolCalls []struct {
 					Index    int     `json:"index"`
 					ID       string  `json:"id"`
@@ -538,78 +643,113 @@ func (s *openAIStream) Next() (*provider.StreamChunk, error) {
 			} `json:"delta"`
 			FinishReason *string `json:"finish_reason"`
 		} `json:"choices"`
+		Usage *openAIUsage `json:"usage"`
 	}
 
 	if err := json.Unmarshal([]byte(event.Data), &chunkData); err != nil {
 		return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
 	}
 
+	if chunkData.Usage != nil {
+		usage := convertOpenAIUsage(*chunkData.Usage)
+		s.usage = &usage
+		if s.flushFinish() {
+			return s.Next()
+		}
+	}
+
 	if len(chunkData.Choices) > 0 {
 		choice := chunkData.Choices[0]
 
-		// Text chunk
+		// A delta can carry text, audio and tool call fragments together,
+		// and some OpenAI-compatible servers send the finish reason in the
+		// same delta, so queue everything it carries in that order.
 		if choice.Delta.Content != "" {
-			return &provider.StreamChunk{
+			s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
 				Type: provider.ChunkTypeText,
 				Text: choice.Delta.Content,
-			}, nil
+			})
+		}
+
+		// Audio delta — base64 audio fragments and/or transcript text
+		if a := choice.Delta.Audio; a != nil && (a.Data != "" || a.Transcript != "" || a.ID != "") {
+			s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
+				Type:  provider.ChunkTypeAudio,
+				Audio: convertOpenAIAudio(a, s.audioVoice, s.audioFormat),
+			})
 		}
 
 		// Tool call delta — accumulate partial arguments by index.
 		// OpenAI sends: first delta has id + name + empty/partial args;
 		// subsequent deltas for the same index carry argument fragments only.
-		if len(choice.Delta.ToolCalls) > 0 {
-			for _, tc := range choice.Delta.ToolCalls {
-				accum, ok := s.toolCallAccum[tc.Index]
-				if !ok {
-					accum = &openAIStreamAccumToolCall{}
-					s.toolCallAccum[tc.Index] = accum
-				}
-				if tc.ID != "" {
-					accum.id = tc.ID
-				}
-				if tc.Function.Name != "" {
-					accum.name = tc.Function.Name
-				}
-				accum.arguments += tc.Function.Arguments
+		for _, tc := range choice.Delta.ToolCalls {
+			accum, ok := s.toolCallAccum[tc.Index]
+			if !ok {
+				accum = &openAIStreamAccumToolCall{}
+				s.toolCallAccum[tc.Index] = accum
 			}
-			// No chunk to emit yet — keep accumulating.
-			return s.Next()
+			if tc.ID != "" {
+				accum.id = tc.ID
+			}
+			if tc.Function.Name != "" {
+				accum.name = tc.Function.Name
+			}
+			accum.arguments += tc.Function.Arguments
 		}
 
 		// Finish chunk — flush all accumulated tool calls first.
 		if choice.FinishReason != nil {
-			// Emit one ChunkTypeToolCall per accumulated entry in index order.
-			for i := 0; i < len(s.toolCallAccum); i++ {
-				accum, ok := s.toolCallAccum[i]
-				if !ok {
-					continue
-				}
-				var args map[string]interface{}
-				if accum.arguments != "" {
-					_ = json.Unmarshal([]byte(accum.arguments), &args) //nolint:errcheck
-				}
-				s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
-					Type: provider.ChunkTypeToolCall,
-					ToolCall: &types.ToolCall{
-						ID:        accum.id,
-						ToolName:  accum.name,
-						Arguments: args,
-					},
-				})
-			}
-			s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
-				Type:         provider.ChunkTypeFinish,
-				FinishReason: providerutils.MapOpenAIFinishReason(*choice.FinishReason),
-			})
-			return s.Next()
+			s.queueFinish(*choice.FinishReason)
 		}
 	}
 
-	// Empty chunk, get next
+	// Emit what was queued, or read the next event
 	return s.Next()
 }
 
+// queueFinish queues the accumulated tool calls, in index order, and holds
+// the finish chunk until usage is reported
+func (s *openAIStream) queueFinish(reason string) {
+	for i := 0; i < len(s.toolCallAccum); i++ {
+		accum, ok := s.toolCallAccum[i]
+		if !ok {
+			continue
+		}
+		var args map[string]interface{}
+		if accum.arguments != "" {
+			_ = json.Unmarshal([]byte(accum.arguments), &args) //nolint:errcheck
+		}
+		s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
+			Type: provider.ChunkTypeToolCall,
+			ToolCall: &types.ToolCall{
+				ID:        accum.id,
+				ToolName:  accum.name,
+				Arguments: args,
+			},
+		})
+	}
+	s.toolCallAccum = make(map[int]*openAIStreamAccumToolCall)
+	s.pendingFinish = &provider.StreamChunk{
+		Type:         provider.ChunkTypeFinish,
+		FinishReason: providerutils.MapOpenAIFinishReason(reason),
+	}
+	if s.usage != nil {
+		s.flushFinish()
+	}
+}
+
+// flushFinish queues the held finish chunk with the latest usage report.
+// It reports whether there was a finish chunk to queue.
+func (s *openAIStream) flushFinish() bool {
+	if s.pendingFinish == nil {
+		return false
+	}
+	s.pendingFinish.Usage = s.usage
+	s.flushQueue = append(s.flushQueue, s.pendingFinish)
+	s.pendingFinish = nil
+	return true
+}
+
 // Err returns any error that occurred during streaming
 func (s *openAIStream) Err() error {
 	if s.err == io.EOF {
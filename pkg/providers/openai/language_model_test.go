@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -608,6 +609,38 @@ func TestDoStreamFinishReasonMapping(t *testing.T) {
 	}
 }
 
+// TestDoStreamFinishReasonWithText verifies that a finish reason sent in the
+// same delta as the last text, as some OpenAI-compatible servers do, is not lost.
+func TestDoStreamFinishReasonWithText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"delta":{"content":"Hi"},"finish_reason":"length"}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	model := NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: server.URL}), "gpt-4")
+	stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "Hello"},
+	})
+	if err != nil {
+		t.Fatalf("DoStream failed: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	chunk, err := stream.Next()
+	if err != nil || chunk.Type != provider.ChunkTypeText || chunk.Text != "Hi" {
+		t.Fatalf("expected text chunk 'Hi', got %+v (err %v)", chunk, err)
+	}
+	chunk, err = stream.Next()
+	if err != nil || chunk.Type != provider.ChunkTypeFinish {
+		t.Fatalf("expected finish chunk, got %+v (err %v)", chunk, err)
+	}
+	if chunk.FinishReason != types.FinishReasonLength {
+		t.Errorf("FinishReason = %q, want %q", chunk.FinishReason, types.FinishReasonLength)
+	}
+}
+
 // TestDoStreamToolCallChunks verifies that incremental tool call deltas are accumulated
 // and emitted as complete ChunkTypeToolCall chunks before the finish chunk.
 // OpenAI streams tool call arguments across multiple SSE deltas; each delta for a given
@@ -1098,3 +1131,259 @@ func TestTextVerbosityOmittedWhenNotSet(t *testing.T) {
 		t.Errorf("expected no verbosity when textVerbosity not set")
 	}
 }
+
+// TestBuildRequestBodyGuidedDecoding verifies that constraints are mapped to
+// the parameter names of the configured OpenAI-compatible backend.
+func TestBuildRequestBodyGuidedDecoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		backend    string
+		constraint *types.Constraint
+		key        string
+	}{
+		{"vllm regex", GuidedDecodingVLLM, types.RegexConstraint(`\d+`), "guided_regex"},
+		{"vllm grammar", GuidedDecodingVLLM, types.GrammarConstraint(`root ::= "yes" | "no"`), "guided_grammar"},
+		{"vllm json", GuidedDecodingVLLM, types.JSONSchemaConstraint(map[string]interface{}{"type": "object"}), "guided_json"},
+		{"llama.cpp grammar", GuidedDecodingLlamaCpp, types.GrammarConstraint(`root ::= "yes" | "no"`), "grammar"},
+		{"llama.cpp json", GuidedDecodingLlamaCpp, types.JSONSchemaConstraint(map[string]interface{}{"type": "object"}), "json_schema"},
+		{"openai json", "", types.JSONSchemaConstraint(map[string]interface{}{"type": "object"}), "response_format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(Config{APIKey: "test-key", GuidedDecoding: tt.backend})
+			model := NewLanguageModel(p, "local-model")
+
+			if !model.SupportsConstraint(tt.constraint.Kind) {
+				t.Fatalf("expected %s to be supported by %q", tt.constraint.Kind, tt.backend)
+			}
+
+			body := model.buildRequestBody(&provider.GenerateOptions{
+				Prompt:     types.Prompt{Text: "Hello"},
+				Constraint: tt.constraint,
+			}, false)
+
+			if _, ok := body[tt.key]; !ok {
+				t.Errorf("expected %s in request body, got %v", tt.key, body)
+			}
+		})
+	}
+}
+
+// TestSupportsConstraintHostedOpenAI verifies that the hosted API only
+// advertises JSON schema constraints.
+func TestSupportsConstraintHostedOpenAI(t *testing.T) {
+	model := NewLanguageModel(New(Config{APIKey: "test-key"}), "gpt-4o")
+
+	if model.SupportsConstraint(types.ConstraintRegex) {
+		t.Error("expected regex constraints to be unsupported")
+	}
+	if model.SupportsConstraint(types.ConstraintGrammar) {
+		t.Error("expected grammar constraints to be unsupported")
+	}
+}
+
+// TestBuildRequestBodyStrictJSONSchema verifies that JSON schema constraints
+// are normalized for strict structured outputs, and sent non-strict when
+// strict mode would change their meaning.
+func TestBuildRequestBodyStrictJSONSchema(t *testing.T) {
+	model := NewLanguageModel(New(Config{APIKey: "test-key"}), "gpt-4o")
+	jsonSchema := func(schema map[string]interface{}) map[string]interface{} {
+		body := model.buildRequestBody(&provider.GenerateOptions{
+			Prompt:     types.Prompt{Text: "Hello"},
+			Constraint: types.JSONSchemaConstraint(schema),
+		}, false)
+		return body["response_format"].(map[string]interface{})["json_schema"].(map[string]interface{})
+	}
+
+	closed := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+			"tags": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"label": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"label"},
+				},
+			},
+		},
+		"required": []string{"name", "tags"},
+	}
+	got := jsonSchema(closed)
+	if got["strict"] != true {
+		t.Fatalf("expected strict mode for a fully required schema, got %v", got)
+	}
+	schema := got["schema"].(map[string]interface{})
+	item := schema["properties"].(map[string]interface{})["tags"].(map[string]interface{})["items"].(map[string]interface{})
+	if schema["additionalProperties"] != false || item["additionalProperties"] != false {
+		t.Errorf("expected every object to set additionalProperties false, got %v", schema)
+	}
+	if _, ok := closed["additionalProperties"]; ok {
+		t.Error("expected the constraint's schema to be left unmodified")
+	}
+
+	for name, schema := range map[string]map[string]interface{}{
+		"optional property": {
+			"type":       "object",
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+		},
+		"open object": {
+			"type":                 "object",
+			"properties":           map[string]interface{}{},
+			"additionalProperties": true,
+		},
+		"oneOf": {
+			"oneOf": []interface{}{map[string]interface{}{"type": "string"}, map[string]interface{}{"type": "number"}},
+		},
+	} {
+		if got := jsonSchema(schema); got["strict"] != false {
+			t.Errorf("%s: expected strict false, got %v", name, got)
+		}
+	}
+}
+
+// TestAudioOutput verifies that modalities/audio options are forwarded and
+// that spoken responses are decoded for both generate and stream calls.
+func TestAudioOutput(t *testing.T) {
+	audioOpts := map[string]interface{}{
+		"openai": map[string]interface{}{
+			"modalities": []string{"text", "audio"},
+			"audio":      map[string]interface{}{"voice": "alloy", "format": "wav"},
+		},
+	}
+
+	t.Run("generate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["modalities"]; !ok {
+				t.Error("expected modalities in request body")
+			}
+			if audio, _ := body["audio"].(map[string]interface{}); audio["voice"] != "alloy" {
+				t.Errorf("expected audio voice in request body, got %v", body["audio"])
+			}
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":null,"audio":{"id":"audio_1","data":"AQID","expires_at":1700000000,"transcript":"Hello there"}},"finish_reason":"stop"}]}`)
+		}))
+		defer server.Close()
+
+		model := NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: server.URL}), "gpt-4o-audio-preview")
+		result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+			Prompt:          types.Prompt{Text: "Hi"},
+			ProviderOptions: audioOpts,
+		})
+		if err != nil {
+			t.Fatalf("DoGenerate failed: %v", err)
+		}
+		if result.Audio == nil {
+			t.Fatal("expected audio output")
+		}
+		if string(result.Audio.Data) != "\x01\x02\x03" {
+			t.Errorf("Data = %v, want [1 2 3]", result.Audio.Data)
+		}
+		if result.Audio.Transcript != "Hello there" || result.Audio.ID != "audio_1" {
+			t.Errorf("unexpected audio: %+v", result.Audio)
+		}
+		if result.Audio.Voice != "alloy" || result.Audio.Format != "wav" {
+			t.Errorf("expected requested voice/format, got %q/%q", result.Audio.Voice, result.Audio.Format)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"audio":{"id":"audio_1","transcript":"Hel"}}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"audio":{"data":"AQID"}}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		model := NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: server.URL}), "gpt-4o-audio-preview")
+		stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{
+			Prompt:          types.Prompt{Text: "Hi"},
+			ProviderOptions: audioOpts,
+		})
+		if err != nil {
+			t.Fatalf("DoStream failed: %v", err)
+		}
+		defer stream.Close()
+
+		var audioChunks []*provider.StreamChunk
+		for {
+			chunk, err := stream.Next()
+			if err != nil {
+				break
+			}
+			if chunk.Type == provider.ChunkTypeAudio {
+				audioChunks = append(audioChunks, chunk)
+			}
+		}
+		if len(audioChunks) != 2 {
+			t.Fatalf("expected 2 audio chunks, got %d", len(audioChunks))
+		}
+		if audioChunks[0].Audio.Transcript != "Hel" || audioChunks[0].Audio.Voice != "alloy" {
+			t.Errorf("unexpected first audio chunk: %+v", audioChunks[0].Audio)
+		}
+		if len(audioChunks[1].Audio.Data) != 3 {
+			t.Errorf("expected decoded audio bytes, got %v", audioChunks[1].Audio.Data)
+		}
+	})
+
+	t.Run("stream content and audio in one delta", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Hi","audio":{"data":"AQID"}},"finish_reason":"stop"}]}`+"\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		model := NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: server.URL}), "gpt-4o-audio-preview")
+		stream, err := model.DoStream(context.Background(), &provider.GenerateOptions{
+			Prompt:          types.Prompt{Text: "Hi"},
+			ProviderOptions: audioOpts,
+		})
+		if err != nil {
+			t.Fatalf("DoStream failed: %v", err)
+		}
+		defer stream.Close()
+
+		var chunkTypes []provider.ChunkType
+		for {
+			chunk, err := stream.Next()
+			if err != nil {
+				break
+			}
+			chunkTypes = append(chunkTypes, chunk.Type)
+		}
+		want := []provider.ChunkType{provider.ChunkTypeText, provider.ChunkTypeAudio, provider.ChunkTypeFinish}
+		if !reflect.DeepEqual(chunkTypes, want) {
+			t.Errorf("chunk types = %v, want %v", chunkTypes, want)
+		}
+	})
+}
+
+// TestBuildRequestBodyWithMetadata tests that request metadata is forwarded
+func TestBuildRequestBodyWithMetadata(t *testing.T) {
+	p := New(Config{APIKey: "test-key"})
+	model := NewLanguageModel(p, "gpt-4o")
+
+	opts := &provider.GenerateOptions{
+		Prompt:   types.Prompt{Text: "Hello"},
+		Metadata: map[string]string{"user": "u-123", "team": "search"},
+	}
+	body := model.buildRequestBody(opts, false)
+	if body["user"] != "u-123" {
+		t.Errorf("expected user=u-123, got %v", body["user"])
+	}
+	if _, ok := body["metadata"]; ok {
+		t.Error("metadata must not be sent without store=true")
+	}
+
+	opts.ProviderOptions = map[string]interface{}{"openai": map[string]interface{}{"store": true}}
+	body = model.buildRequestBody(opts, false)
+	if md, ok := body["metadata"].(map[string]string); !ok || md["team"] != "search" {
+		t.Errorf("expected metadata for stored completion, got %v", body["metadata"])
+	}
+}
@@ -1,10 +1,13 @@
 package openai
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 const (
@@ -16,6 +19,7 @@ const (
 type Provider struct {
 	config Config
 	client *http.Client
+	dial   ws.Dialer
 }
 
 // Config contains configuration for the OpenAI provider
@@ -31,8 +35,24 @@ type Config struct {
 
 	// Project is the optional project ID
 	Project string
+
+	// GuidedDecoding enables native constrained decoding when BaseURL points
+	// at an OpenAI-compatible local server. Use GuidedDecodingVLLM or
+	// GuidedDecodingLlamaCpp. Empty means the hosted OpenAI API, which only
+	// supports JSON schema constraints.
+	GuidedDecoding string
 }
 
+const (
+	// GuidedDecodingVLLM sends constraints as vLLM guided decoding parameters
+	// (guided_regex, guided_grammar, guided_json)
+	GuidedDecodingVLLM = "vllm"
+
+	// GuidedDecodingLlamaCpp sends constraints as llama.cpp server parameters
+	// (grammar for GBNF, json_schema)
+	GuidedDecodingLlamaCpp = "llama.cpp"
+)
+
 // New creates a new OpenAI provider with the given configuration
 func New(cfg Config) *Provider {
 	baseURL := cfg.BaseURL
@@ -61,6 +81,7 @@ func New(cfg Config) *Provider {
 	return &Provider{
 		config: cfg,
 		client: client,
+		dial:   ws.Dial,
 	}
 }
 
@@ -69,6 +90,17 @@ func (p *Provider) Name() string {
 	return "openai"
 }
 
+// Ping checks that the API is reachable and the credentials are valid by
+// listing available models. It does not consume tokens.
+func (p *Provider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return providerutils.PingEndpoint(ctx, p.client, p.Name(), "/models")
+}
+
+// ListModels lists the models available to the configured credentials.
+func (p *Provider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return providerutils.ListModelsEndpoint(ctx, p.client, p.Name(), "/models", nil)
+}
+
 // LanguageModel returns a language model by ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	// Validate model ID
@@ -258,9 +258,15 @@ func (m *ResponsesLanguageModel) buildRequestBody(opts *provider.GenerateOptions
 	if serviceTier != "" {
 		body["service_tier"] = serviceTier
 	}
+	if user == "" {
+		user = opts.Metadata[provider.MetadataKeyUser]
+	}
 	if user != "" {
 		body["user"] = user
 	}
+	if len(opts.Metadata) > 0 {
+		body["metadata"] = opts.Metadata
+	}
 	if maxToolCalls > 0 {
 		body["max_tool_calls"] = maxToolCalls
 	}
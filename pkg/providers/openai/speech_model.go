@@ -7,6 +7,7 @@ import (
 	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 // SpeechModel implements the provider.SpeechModel interface for OpenAI TTS
@@ -53,7 +54,7 @@ func (m *SpeechModel) DoGenerate(ctx context.Context, opts *provider.SpeechGener
 
 	return &types.SpeechResult{
 		Audio:    resp.Body,
-		MimeType: "audio/mpeg",
+		MimeType: providerutils.SpeechFormatMimeType(opts.OutputFormat),
 		Usage: types.SpeechUsage{
 			CharacterCount: len(opts.Text),
 		},
@@ -73,5 +74,8 @@ func (m *SpeechModel) buildRequestBody(opts *provider.SpeechGenerateOptions) map
 	if opts.Speed != nil {
 		body["speed"] = *opts.Speed
 	}
+	if opts.OutputFormat != "" {
+		body["response_format"] = opts.OutputFormat
+	}
 	return body
 }
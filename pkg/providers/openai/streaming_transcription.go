@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/ws"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// DoStreamTranscription opens a Realtime API transcription session. Audio
+// must be 24kHz mono pcm16 or 8kHz mulaw; server-side VAD segments it into
+// utterances. Options under ProviderOptions["openai"] are merged into the
+// session's input_audio_transcription settings (e.g. "prompt").
+func (m *TranscriptionModel) DoStreamTranscription(ctx context.Context, opts *provider.StreamingTranscriptionOptions) (provider.TranscriptionSession, error) {
+	if opts == nil {
+		opts = &provider.StreamingTranscriptionOptions{}
+	}
+	format := "pcm16"
+	if opts.Encoding == "mulaw" {
+		format = "g711_ulaw"
+	}
+	transcription := map[string]interface{}{"model": m.modelID}
+	if opts.Language != "" {
+		transcription["language"] = opts.Language
+	}
+	if po, ok := opts.ProviderOptions["openai"].(map[string]interface{}); ok {
+		for k, v := range po {
+			transcription[k] = v
+		}
+	}
+	update, err := json.Marshal(map[string]interface{}{
+		"type": "transcription_session.update",
+		"session": map[string]interface{}{
+			"input_audio_format":        format,
+			"input_audio_transcription": transcription,
+			"turn_detection":            map[string]interface{}{"type": "server_vad"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := m.provider.config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	wsURL, err := ws.URL(baseURL, "/realtime", url.Values{"intent": {"transcription"}})
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+m.provider.config.APIKey)
+	header.Set("OpenAI-Beta", "realtime=v1")
+	if m.provider.config.Organization != "" {
+		header.Set("OpenAI-Organization", m.provider.config.Organization)
+	}
+	if m.provider.config.Project != "" {
+		header.Set("OpenAI-Project", m.provider.config.Project)
+	}
+
+	conn, err := m.provider.dial(ctx, wsURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	if err := conn.WriteText(update); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("openai: failed to configure session: %w", err)
+	}
+
+	parser := &realtimeTranscriptParser{interim: opts.InterimResults, partial: make(map[string]*strings.Builder)}
+	return ws.NewTranscriptionSession(conn, parser.parse, sendRealtimeAudio,
+		func(c ws.Conn) error { return c.WriteText([]byte(`{"type":"input_audio_buffer.commit"}`)) },
+	), nil
+}
+
+func sendRealtimeAudio(c ws.Conn, frame []byte) error {
+	msg, err := json.Marshal(map[string]string{
+		"type":  "input_audio_buffer.append",
+		"audio": base64.StdEncoding.EncodeToString(frame),
+	})
+	if err != nil {
+		return err
+	}
+	return c.WriteText(msg)
+}
+
+type realtimeTranscriptMessage struct {
+	Type         string `json:"type"`
+	ItemID       string `json:"item_id"`
+	Delta        string `json:"delta"`
+	Transcript   string `json:"transcript"`
+	AudioStartMS int    `json:"audio_start_ms"`
+	AudioEndMS   int    `json:"audio_end_ms"`
+	Error        *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// realtimeTranscriptParser accumulates transcription deltas per item so
+// interim events carry the full text heard so far
+type realtimeTranscriptParser struct {
+	interim bool
+	partial map[string]*strings.Builder
+}
+
+func (p *realtimeTranscriptParser) parse(data []byte) ([]types.TranscriptEvent, bool, error) {
+	var msg realtimeTranscriptMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, false, fmt.Errorf("openai: invalid realtime message: %w", err)
+	}
+
+	switch msg.Type {
+	case "input_audio_buffer.speech_started":
+		return []types.TranscriptEvent{{Type: types.TranscriptEventSpeechStarted, Start: float64(msg.AudioStartMS) / 1000}}, false, nil
+	case "input_audio_buffer.speech_stopped":
+		return []types.TranscriptEvent{{Type: types.TranscriptEventUtteranceEnd, End: float64(msg.AudioEndMS) / 1000}}, false, nil
+	case "conversation.item.input_audio_transcription.delta":
+		if !p.interim || msg.Delta == "" {
+			return nil, false, nil
+		}
+		b, ok := p.partial[msg.ItemID]
+		if !ok {
+			b = &strings.Builder{}
+			p.partial[msg.ItemID] = b
+		}
+		b.WriteString(msg.Delta)
+		return []types.TranscriptEvent{{Type: types.TranscriptEventInterim, Text: b.String()}}, false, nil
+	case "conversation.item.input_audio_transcription.completed":
+		delete(p.partial, msg.ItemID)
+		return []types.TranscriptEvent{{Type: types.TranscriptEventFinal, Text: strings.TrimSpace(msg.Transcript), EndOfUtterance: true}}, false, nil
+	case "conversation.item.input_audio_transcription.failed", "error":
+		if msg.Error != nil {
+			return nil, true, fmt.Errorf("openai: %s", msg.Error.Message)
+		}
+		return nil, true, fmt.Errorf("openai: realtime transcription failed")
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,114 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"golang.org/x/net/websocket"
+)
+
+func TestTranscriptionModel_DoStreamTranscription(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan map[string]interface{}, 8)
+	server := httptest.NewServer(websocket.Handler(func(c *websocket.Conn) {
+		if got := c.Request().Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := c.Request().URL.Query().Get("intent"); got != "transcription" {
+			t.Errorf("intent = %q", got)
+		}
+		for {
+			var msg map[string]interface{}
+			if err := websocket.JSON.Receive(c, &msg); err != nil {
+				return
+			}
+			received <- msg
+			if msg["type"] != "input_audio_buffer.commit" {
+				continue
+			}
+			for _, reply := range []string{
+				`{"type":"input_audio_buffer.speech_started","audio_start_ms":500}`,
+				`{"type":"conversation.item.input_audio_transcription.delta","item_id":"a","delta":"Hello"}`,
+				`{"type":"conversation.item.input_audio_transcription.delta","item_id":"a","delta":" there"}`,
+				`{"type":"conversation.item.input_audio_transcription.completed","item_id":"a","transcript":"Hello there."}`,
+			} {
+				websocket.Message.Send(c, reply)
+			}
+			return
+		}
+	}))
+	defer server.Close()
+
+	model := NewTranscriptionModel(New(Config{APIKey: "test-key", BaseURL: server.URL + "/v1"}), "gpt-4o-transcribe")
+	session, err := model.DoStreamTranscription(context.Background(), &provider.StreamingTranscriptionOptions{
+		Encoding:       "mulaw",
+		Language:       "en",
+		InterimResults: true,
+	})
+	if err != nil {
+		t.Fatalf("DoStreamTranscription failed: %v", err)
+	}
+	defer session.Close()
+
+	if err := session.SendAudio([]byte{1, 2, 3}); err != nil {
+		t.Fatalf("SendAudio failed: %v", err)
+	}
+	if err := session.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+
+	var events []types.TranscriptEvent
+	for {
+		e, err := session.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		events = append(events, *e)
+	}
+
+	update := <-received
+	sess, _ := update["session"].(map[string]interface{})
+	if update["type"] != "transcription_session.update" || sess["input_audio_format"] != "g711_ulaw" {
+		t.Errorf("unexpected session update: %v", update)
+	}
+	if tr, _ := sess["input_audio_transcription"].(map[string]interface{}); tr["model"] != "gpt-4o-transcribe" || tr["language"] != "en" {
+		t.Errorf("unexpected transcription settings: %v", sess["input_audio_transcription"])
+	}
+	audio := <-received
+	if audio["type"] != "input_audio_buffer.append" || audio["audio"] != base64.StdEncoding.EncodeToString([]byte{1, 2, 3}) {
+		t.Errorf("unexpected audio message: %v", audio)
+	}
+
+	want := []types.TranscriptEvent{
+		{Type: types.TranscriptEventSpeechStarted, Start: 0.5},
+		{Type: types.TranscriptEventInterim, Text: "Hello"},
+		{Type: types.TranscriptEventInterim, Text: "Hello there"},
+		{Type: types.TranscriptEventFinal, Text: "Hello there.", EndOfUtterance: true},
+	}
+	got, _ := json.Marshal(events)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Errorf("events = %s, want %s", got, wantJSON)
+	}
+}
+
+func TestRealtimeTranscriptParser_Error(t *testing.T) {
+	t.Parallel()
+
+	p := &realtimeTranscriptParser{partial: make(map[string]*strings.Builder)}
+	_, done, err := p.parse([]byte(`{"type":"error","error":{"message":"invalid api key"}}`))
+	if !done || err == nil || err.Error() != "openai: invalid api key" {
+		t.Errorf("parse error = %v, done = %v", err, done)
+	}
+}
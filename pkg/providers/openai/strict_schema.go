@@ -0,0 +1,132 @@
+package openai
+
+import "github.com/digitallysavvy/go-ai/pkg/schema"
+
+// strictJSONSchema converts schema to the form OpenAI's strict structured
+// outputs accept: every object sets additionalProperties to false and lists
+// all of its properties as required. Objects that omit additionalProperties
+// are closed, which does not change which outputs are valid in practice.
+// The second result is false when strict mode would change the schema's
+// meaning, i.e. an object has optional properties, allows additional
+// properties, or uses keywords strict mode does not support; the schema
+// should then be sent with strict disabled. schema itself is not modified.
+func strictJSONSchema(schema map[string]interface{}) (map[string]interface{}, bool) {
+	out, ok := strictSchemaNode(schema)
+	if !ok {
+		return schema, false
+	}
+	return out, true
+}
+
+// strictSchemaUnsupported lists keywords strict structured outputs reject
+var strictSchemaUnsupported = []string{"allOf", "oneOf", "not", "patternProperties", "if", "then", "else", "dependentRequired", "dependentSchemas"}
+
+func strictSchemaNode(node map[string]interface{}) (map[string]interface{}, bool) {
+	for _, kw := range strictSchemaUnsupported {
+		if _, found := node[kw]; found {
+			return nil, false
+		}
+	}
+
+	out := make(map[string]interface{}, len(node)+1)
+	for k, v := range node {
+		out[k] = v
+	}
+
+	if props, isObject := node["properties"].(map[string]interface{}); isObject || node["type"] == "object" {
+		switch extra := node["additionalProperties"].(type) {
+		case nil:
+			out["additionalProperties"] = false
+		case bool:
+			if extra {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+
+		required := make(map[string]bool)
+		for _, name := range schema.RequiredProperties(node) {
+			required[name] = true
+		}
+		strictProps := make(map[string]interface{}, len(props))
+		for name, prop := range props {
+			if !required[name] {
+				return nil, false
+			}
+			child, ok := strictSchemaValue(prop)
+			if !ok {
+				return nil, false
+			}
+			strictProps[name] = child
+		}
+		if props != nil {
+			out["properties"] = strictProps
+		}
+	}
+
+	if items, found := node["items"].(map[string]interface{}); found {
+		child, ok := strictSchemaNode(items)
+		if !ok {
+			return nil, false
+		}
+		out["items"] = child
+	}
+	if anyOf, found := node["anyOf"]; found {
+		list, ok := strictSchemaList(anyOf)
+		if !ok {
+			return nil, false
+		}
+		out["anyOf"] = list
+	}
+	for _, key := range []string{"$defs", "definitions"} {
+		defs, found := node[key].(map[string]interface{})
+		if !found {
+			continue
+		}
+		strictDefs := make(map[string]interface{}, len(defs))
+		for name, def := range defs {
+			child, ok := strictSchemaValue(def)
+			if !ok {
+				return nil, false
+			}
+			strictDefs[name] = child
+		}
+		out[key] = strictDefs
+	}
+	return out, true
+}
+
+// strictSchemaValue converts a subschema given as an interface value
+func strictSchemaValue(value interface{}) (interface{}, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		// Boolean schemas are not supported in strict mode
+		return nil, false
+	}
+	return strictSchemaNode(m)
+}
+
+// strictSchemaList converts a list of subschemas, as used by anyOf
+func strictSchemaList(value interface{}) ([]interface{}, bool) {
+	var subs []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		subs = v
+	case []map[string]interface{}:
+		for _, sub := range v {
+			subs = append(subs, sub)
+		}
+	default:
+		return nil, false
+	}
+	list := make([]interface{}, len(subs))
+	for i, sub := range subs {
+		child, ok := strictSchemaValue(sub)
+		if !ok {
+			return nil, false
+		}
+		list[i] = child
+	}
+	return list, true
+}
@@ -0,0 +1,205 @@
+package openaicompatible
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+)
+
+// LanguageModel is an OpenAI-compatible language model with its quirks
+// applied. Requests are rewritten on the wire; tool calls written into the
+// message text are parsed back into tool calls.
+type LanguageModel struct {
+	*openai.LanguageModel
+	provider *Provider
+	quirks   Quirks
+}
+
+// NewLanguageModel creates a language model using the quirks configured for
+// its ID
+func NewLanguageModel(p *Provider, modelID string) *LanguageModel {
+	quirks := p.QuirksFor(modelID)
+	base := p.Provider
+	if quirks.GuidedDecoding != "" {
+		base = openai.New(openai.Config{
+			APIKey:         p.config.APIKey,
+			BaseURL:        p.config.BaseURL,
+			GuidedDecoding: quirks.GuidedDecoding,
+		})
+	}
+	return &LanguageModel{
+		LanguageModel: openai.NewLanguageModel(base, modelID),
+		provider:      p,
+		quirks:        quirks,
+	}
+}
+
+// Provider returns the provider name
+func (m *LanguageModel) Provider() string {
+	return m.provider.Name()
+}
+
+// Quirks returns the quirks applied to the model
+func (m *LanguageModel) Quirks() Quirks {
+	return m.quirks
+}
+
+// SupportsTools returns whether the model supports tool calling
+func (m *LanguageModel) SupportsTools() bool {
+	return m.quirks.ToolCalls != ToolCallsNone
+}
+
+// SupportsStructuredOutput returns whether the model supports structured output
+func (m *LanguageModel) SupportsStructuredOutput() bool {
+	return m.quirks.StructuredOutput
+}
+
+// SupportsImageInput returns whether the model accepts image inputs
+func (m *LanguageModel) SupportsImageInput() bool {
+	return m.quirks.ImageInput
+}
+
+// DoGenerate performs non-streaming text generation
+func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+	result, err := m.LanguageModel.DoGenerate(m.withQuirks(ctx), opts)
+	if err != nil {
+		return nil, err
+	}
+	if m.parsesTextToolCalls(opts) && len(result.ToolCalls) == 0 {
+		text, calls := parseTextToolCalls(m.quirks.ToolCalls, result.Text, opts.Tools)
+		if len(calls) > 0 {
+			result.Text = text
+			result.ToolCalls = calls
+			result.FinishReason = types.FinishReasonToolCalls
+		}
+	}
+	return result, nil
+}
+
+// DoStream performs streaming text generation
+func (m *LanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+	stream, err := m.LanguageModel.DoStream(m.withQuirks(ctx), opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &compatStream{inner: stream}
+	if m.parsesTextToolCalls(opts) {
+		s.format = m.quirks.ToolCalls
+		s.tools = opts.Tools
+	}
+	return s, nil
+}
+
+// parsesTextToolCalls reports whether tool calls must be parsed from the
+// message text for this call
+func (m *LanguageModel) parsesTextToolCalls(opts *provider.GenerateOptions) bool {
+	return len(opts.Tools) > 0 &&
+		(m.quirks.ToolCalls == ToolCallsHermes || m.quirks.ToolCalls == ToolCallsJSON)
+}
+
+// withQuirks returns the context for an API call, with the configured
+// headers and a request transform applying the model's quirks
+func (m *LanguageModel) withQuirks(ctx context.Context) context.Context {
+	ctx = m.provider.withHeaders(ctx)
+	return provider.ContextWithHTTPTransforms(ctx, provider.HTTPTransforms{
+		Request: []provider.RequestTransform{m.rewriteRequest},
+	})
+}
+
+// rewriteRequest applies the model's quirks to a chat completions request
+func (m *LanguageModel) rewriteRequest(ctx context.Context, req *provider.HTTPRequest) error {
+	if len(req.Body) == 0 || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+		return nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return nil
+	}
+
+	q := m.quirks
+	if v, ok := body["max_tokens"]; ok && q.MaxTokensField != "" && q.MaxTokensField != "max_tokens" {
+		delete(body, "max_tokens")
+		body[q.MaxTokensField] = v
+	}
+	if q.ToolCalls == ToolCallsNone {
+		delete(body, "tools")
+		delete(body, "tool_choice")
+		delete(body, "parallel_tool_calls")
+	}
+	if q.SystemAsUser {
+		mergeSystemIntoUser(body)
+	}
+	for _, path := range q.UnsupportedFields {
+		removeField(body, path)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req.Body = data
+	return nil
+}
+
+// mergeSystemIntoUser folds leading system and developer messages into the
+// first user message, or turns them into a user message if there is none
+func mergeSystemIntoUser(body map[string]interface{}) {
+	messages, ok := body["messages"].([]interface{})
+	if !ok {
+		return
+	}
+	var system []string
+	rest := messages[:0:0]
+	for _, raw := range messages {
+		msg, _ := raw.(map[string]interface{})
+		if role, _ := msg["role"].(string); role == "system" || role == "developer" {
+			if text, ok := msg["content"].(string); ok && text != "" {
+				system = append(system, text)
+			}
+			continue
+		}
+		rest = append(rest, raw)
+	}
+	if len(system) == 0 {
+		return
+	}
+	prefix := strings.Join(system, "\n\n")
+
+	for _, raw := range rest {
+		msg, _ := raw.(map[string]interface{})
+		if msg["role"] != "user" {
+			continue
+		}
+		switch content := msg["content"].(type) {
+		case string:
+			msg["content"] = prefix + "\n\n" + content
+		case []interface{}:
+			part := map[string]interface{}{"type": "text", "text": prefix}
+			msg["content"] = append([]interface{}{part}, content...)
+		default:
+			msg["content"] = prefix
+		}
+		body["messages"] = rest
+		return
+	}
+	user := map[string]interface{}{"role": "user", "content": prefix}
+	body["messages"] = append([]interface{}{user}, rest...)
+}
+
+// removeField deletes a dot-separated path from a JSON object
+func removeField(body map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	current := body
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
@@ -0,0 +1,164 @@
+package openaicompatible
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providers/openai"
+)
+
+// ToolCallFormat is how a server returns tool calls
+type ToolCallFormat string
+
+const (
+	// ToolCallsNative reads the OpenAI tool_calls field (the default)
+	ToolCallsNative ToolCallFormat = ""
+
+	// ToolCallsHermes parses <tool_call>{"name": ..., "arguments": ...}</tool_call>
+	// blocks from the message text, as emitted by Hermes, Qwen and similar
+	// chat templates when the server does not parse them itself
+	ToolCallsHermes ToolCallFormat = "hermes"
+
+	// ToolCallsJSON parses a message whose whole text is a JSON tool call
+	// object, or an array of them, as emitted by Llama 3 style templates
+	ToolCallsJSON ToolCallFormat = "json"
+
+	// ToolCallsNone marks a model without tool support
+	ToolCallsNone ToolCallFormat = "none"
+)
+
+// Quirks describes how a server or model deviates from the OpenAI API
+type Quirks struct {
+	// MaxTokensField is the request field for the output token limit, e.g.
+	// "max_completion_tokens" or "max_new_tokens" (default: "max_tokens")
+	MaxTokensField string
+
+	// UnsupportedFields are removed from requests before sending, for
+	// servers that reject unknown fields, e.g. "seed" or "tool_choice".
+	// Dot-separated paths reach into nested objects.
+	UnsupportedFields []string
+
+	// ToolCalls is how the server returns tool calls
+	ToolCalls ToolCallFormat
+
+	// SystemAsUser merges the system prompt into the first user message, for
+	// chat templates without a system role
+	SystemAsUser bool
+
+	// StructuredOutput reports native JSON schema support, enabling
+	// response_format json_schema in GenerateObject
+	StructuredOutput bool
+
+	// ImageInput reports that the model accepts images
+	ImageInput bool
+
+	// GuidedDecoding sends constraints as vLLM or llama.cpp parameters
+	// (openai.GuidedDecodingVLLM, openai.GuidedDecodingLlamaCpp)
+	GuidedDecoding string
+}
+
+// Config contains configuration for an OpenAI-compatible provider
+type Config struct {
+	// Name is reported as the model's provider, e.g. "litellm" or "vllm"
+	// (default: "openai-compatible")
+	Name string
+
+	// BaseURL of the server's OpenAI API, e.g. "http://localhost:4000/v1"
+	// (required)
+	BaseURL string
+
+	// APIKey is sent as a bearer token when set
+	APIKey string
+
+	// Headers are added to every request
+	Headers map[string]string
+
+	// Quirks apply to all models without an entry in ModelQuirks
+	Quirks Quirks
+
+	// ModelQuirks replace Quirks for specific models. Keys are model IDs, or
+	// prefixes ending in "*", e.g. "hermes-*"; the longest match wins.
+	ModelQuirks map[string]Quirks
+}
+
+// Provider is a generic OpenAI-compatible provider for self-hosted servers
+// and gateways such as LiteLLM, vLLM, Text Generation Inference and LM
+// Studio. Each model's quirks are applied on the wire, so tool calls,
+// token limits and streams behave like the OpenAI provider's.
+//
+// Example:
+//
+//	p := openaicompatible.New(openaicompatible.Config{
+//		Name:    "litellm",
+//		BaseURL: "http://localhost:4000/v1",
+//		APIKey:  os.Getenv("LITELLM_API_KEY"),
+//		ModelQuirks: map[string]openaicompatible.Quirks{
+//			"hermes-*":      {ToolCalls: openaicompatible.ToolCallsHermes},
+//			"tgi/mistral-7b": {SystemAsUser: true, UnsupportedFields: []string{"seed"}},
+//		},
+//	})
+//	model, err := p.LanguageModel("hermes-3-llama-3.1-8b")
+type Provider struct {
+	*openai.Provider
+	config Config
+}
+
+// New creates an OpenAI-compatible provider
+func New(cfg Config) *Provider {
+	if cfg.Name == "" {
+		cfg.Name = "openai-compatible"
+	}
+	return &Provider{
+		Provider: openai.New(openai.Config{APIKey: cfg.APIKey, BaseURL: cfg.BaseURL}),
+		config:   cfg,
+	}
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// LanguageModel returns a language model with its quirks applied
+func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
+	if modelID == "" {
+		return nil, fmt.Errorf("model ID cannot be empty")
+	}
+	if p.config.BaseURL == "" {
+		return nil, fmt.Errorf("%s: BaseURL is required", p.config.Name)
+	}
+	return NewLanguageModel(p, modelID), nil
+}
+
+// QuirksFor returns the quirks used for a model
+func (p *Provider) QuirksFor(modelID string) Quirks {
+	if q, ok := p.config.ModelQuirks[modelID]; ok {
+		return q
+	}
+	best, quirks := -1, p.config.Quirks
+	for pattern, q := range p.config.ModelQuirks {
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if ok && strings.HasPrefix(modelID, prefix) && len(prefix) > best {
+			best, quirks = len(prefix), q
+		}
+	}
+	return quirks
+}
+
+// withHeaders returns the context used for API calls, carrying the configured
+// headers
+func (p *Provider) withHeaders(ctx context.Context) context.Context {
+	if len(p.config.Headers) == 0 {
+		return ctx
+	}
+	return provider.ContextWithHTTPTransforms(ctx, provider.HTTPTransforms{
+		Request: []provider.RequestTransform{func(ctx context.Context, req *provider.HTTPRequest) error {
+			for k, v := range p.config.Headers {
+				req.Header.Set(k, v)
+			}
+			return nil
+		}},
+	})
+}
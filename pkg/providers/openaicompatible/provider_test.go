@@ -0,0 +1,277 @@
+package openaicompatible
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/providertest"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// hermesServer behaves like a self-hosted server without a tool call
+// parser: tool calls come back as Hermes text, streams end without a
+// finish reason, and non-streaming responses report TGI's "eos_token"
+func hermesServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		tools, _ := req["tools"].([]interface{})
+
+		text := "Hello there!"
+		if len(tools) > 0 {
+			text = `Let me check. <tool_call>{"name": "get_weather", "arguments": {"city": "Paris"}}</tool_call>`
+		}
+
+		if stream, _ := req["stream"].(bool); stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < len(text); i += 5 {
+				delta, _ := json.Marshal(text[i:min(i+5, len(text))])
+				fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%s}}]}\n\n", delta)
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		content, _ := json.Marshal(text)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"eos_token"}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func failingServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":{"message":"failure %d"}}`, status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func hermesModel(t *testing.T, baseURL string) provider.LanguageModel {
+	t.Helper()
+	p := New(Config{
+		Name:        "vllm",
+		BaseURL:     baseURL,
+		ModelQuirks: map[string]Quirks{"hermes-*": {ToolCalls: ToolCallsHermes}},
+	})
+	model, err := p.LanguageModel("hermes-3")
+	if err != nil {
+		t.Fatalf("LanguageModel: %v", err)
+	}
+	return model
+}
+
+func TestLanguageModel_Conformance(t *testing.T) {
+	providertest.RunConformance(t, providertest.Config{
+		NewModel: func(t *testing.T) provider.LanguageModel {
+			return hermesModel(t, hermesServer(t).URL)
+		},
+		NewFailingModel: func(t *testing.T, status int) provider.LanguageModel {
+			return hermesModel(t, failingServer(t, status).URL)
+		},
+	})
+}
+
+func TestProvider_QuirksFor(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{
+		BaseURL: "http://localhost:8000/v1",
+		Quirks:  Quirks{MaxTokensField: "max_completion_tokens"},
+		ModelQuirks: map[string]Quirks{
+			"llama-*":          {ToolCalls: ToolCallsJSON},
+			"llama-3.1-*":      {ToolCalls: ToolCallsHermes},
+			"llama-3.1-8b-tgi": {SystemAsUser: true},
+		},
+	})
+	tests := []struct {
+		model string
+		want  Quirks
+	}{
+		{"gpt-4o", Quirks{MaxTokensField: "max_completion_tokens"}},
+		{"llama-2-7b", Quirks{ToolCalls: ToolCallsJSON}},
+		{"llama-3.1-70b", Quirks{ToolCalls: ToolCallsHermes}},
+		{"llama-3.1-8b-tgi", Quirks{SystemAsUser: true}},
+	}
+	for _, tt := range tests {
+		got := p.QuirksFor(tt.model)
+		if got.MaxTokensField != tt.want.MaxTokensField || got.ToolCalls != tt.want.ToolCalls || got.SystemAsUser != tt.want.SystemAsUser {
+			t.Errorf("QuirksFor(%q) = %+v, want %+v", tt.model, got, tt.want)
+		}
+	}
+
+	if _, err := New(Config{}).LanguageModel("m"); err == nil {
+		t.Error("expected an error without BaseURL")
+	}
+}
+
+func TestLanguageModel_RewritesRequests(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan map[string]interface{}, 1)
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Team")
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests <- req
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	p := New(Config{
+		Name:    "tgi",
+		BaseURL: server.URL,
+		Headers: map[string]string{"X-Team": "search"},
+		Quirks: Quirks{
+			MaxTokensField:    "max_new_tokens",
+			UnsupportedFields: []string{"seed"},
+			ToolCalls:         ToolCallsNone,
+			SystemAsUser:      true,
+		},
+	})
+	model, _ := p.LanguageModel("mistral-7b")
+	if model.Provider() != "tgi" || model.SupportsTools() {
+		t.Errorf("Provider() = %q, SupportsTools() = %v", model.Provider(), model.SupportsTools())
+	}
+
+	maxTokens, seed := 64, 7
+	_, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt:    types.Prompt{System: "Be terse.", Text: "hi"},
+		MaxTokens: &maxTokens,
+		Seed:      &seed,
+		Tools:     []types.Tool{{Name: "get_weather"}},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	req := <-requests
+
+	if gotHeader != "search" {
+		t.Errorf("X-Team = %q", gotHeader)
+	}
+	if _, ok := req["max_tokens"]; ok || req["max_new_tokens"] != float64(64) {
+		t.Errorf("max tokens fields = %v / %v", req["max_tokens"], req["max_new_tokens"])
+	}
+	for _, field := range []string{"seed", "tools", "tool_choice"} {
+		if _, ok := req[field]; ok {
+			t.Errorf("%s was not removed", field)
+		}
+	}
+	messages, _ := req["messages"].([]interface{})
+	if len(messages) != 1 {
+		t.Fatalf("messages = %v, want the system prompt merged into one user message", messages)
+	}
+	msg := messages[0].(map[string]interface{})
+	content, _ := json.Marshal(msg["content"])
+	if msg["role"] != "user" || !strings.Contains(string(content), "Be terse.") || !strings.Contains(string(content), "hi") {
+		t.Errorf("message = %v", msg)
+	}
+}
+
+func TestLanguageModel_DoGenerateParsesHermesToolCalls(t *testing.T) {
+	t.Parallel()
+
+	model := hermesModel(t, hermesServer(t).URL)
+	result, err := model.DoGenerate(context.Background(), providertest.ToolOptions())
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].ToolName != "get_weather" || result.ToolCalls[0].Arguments["city"] != "Paris" {
+		t.Fatalf("ToolCalls = %+v", result.ToolCalls)
+	}
+	if result.Text != "Let me check." {
+		t.Errorf("Text = %q, want the tool call removed", result.Text)
+	}
+	if result.FinishReason != types.FinishReasonToolCalls {
+		t.Errorf("FinishReason = %q", result.FinishReason)
+	}
+
+	result, err = model.DoGenerate(context.Background(), providertest.TextOptions())
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+	if result.FinishReason != types.FinishReasonStop {
+		t.Errorf("FinishReason = %q, want eos_token mapped to stop", result.FinishReason)
+	}
+}
+
+func TestLanguageModel_DoStreamParsesHermesToolCalls(t *testing.T) {
+	t.Parallel()
+
+	model := hermesModel(t, hermesServer(t).URL)
+	stream, err := model.DoStream(context.Background(), providertest.ToolOptions())
+	if err != nil {
+		t.Fatalf("DoStream: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	var text strings.Builder
+	var calls []*types.ToolCall
+	var finishes []types.FinishReason
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case provider.ChunkTypeToolCall:
+			calls = append(calls, chunk.ToolCall)
+		case provider.ChunkTypeFinish:
+			finishes = append(finishes, chunk.FinishReason)
+		}
+	}
+	if strings.TrimSpace(text.String()) != "Let me check." {
+		t.Errorf("text = %q, want the tool call removed", text.String())
+	}
+	if len(calls) != 1 || calls[0].ToolName != "get_weather" || calls[0].Arguments["city"] != "Paris" {
+		t.Errorf("tool calls = %+v", calls)
+	}
+	if len(finishes) != 1 || finishes[0] != types.FinishReasonToolCalls {
+		t.Errorf("finish chunks = %v, want one tool-calls finish", finishes)
+	}
+}
+
+func TestParseTextToolCalls(t *testing.T) {
+	t.Parallel()
+
+	tools := []types.Tool{{Name: "search"}}
+	tests := []struct {
+		name      string
+		format    ToolCallFormat
+		text      string
+		wantText  string
+		wantCalls int
+	}{
+		{"hermes unterminated", ToolCallsHermes, `<tool_call>{"name":"search","arguments":{"q":"go"}}`, "", 1},
+		{"hermes string arguments", ToolCallsHermes, `<tool_call>{"name":"search","arguments":"{\"q\":\"go\"}"}</tool_call>`, "", 1},
+		{"hermes unknown tool", ToolCallsHermes, `<tool_call>{"name":"delete","arguments":{}}</tool_call>`, `<tool_call>{"name":"delete","arguments":{}}</tool_call>`, 0},
+		{"json object", ToolCallsJSON, `{"name":"search","parameters":{"q":"go"}}`, "", 1},
+		{"json array in fence", ToolCallsJSON, "```json\n[{\"name\":\"search\",\"parameters\":{}},{\"name\":\"search\",\"parameters\":{}}]\n```", "", 2},
+		{"json answer", ToolCallsJSON, `{"answer":42}`, `{"answer":42}`, 0},
+		{"plain text", ToolCallsJSON, "no tools needed", "no tools needed", 0},
+	}
+	for _, tt := range tests {
+		text, calls := parseTextToolCalls(tt.format, tt.text, tools)
+		if text != tt.wantText || len(calls) != tt.wantCalls {
+			t.Errorf("%s: got text %q and %d calls, want %q and %d", tt.name, text, len(calls), tt.wantText, tt.wantCalls)
+		}
+	}
+}
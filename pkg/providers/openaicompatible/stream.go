@@ -0,0 +1,161 @@
+package openaicompatible
+
+import (
+	"io"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// compatStream normalizes streams from OpenAI-compatible servers: it ends
+// every stream with exactly one finish chunk, including for servers that
+// close the stream without a finish reason, and parses tool calls written
+// into the text when format is set.
+//
+// Text is streamed as it arrives until it may contain a tool call: a Hermes
+// "<tool_call>" tag, or, for the JSON format, text starting with "{" or
+// "[". From there on it is held back and parsed when the model finishes.
+type compatStream struct {
+	inner  provider.TextStream
+	format ToolCallFormat
+	tools  []types.Tool
+
+	queue     []*provider.StreamChunk
+	held      strings.Builder
+	capturing bool
+	toolCalls bool
+	finished  bool
+	done      bool
+}
+
+// Next returns the next chunk in the stream
+func (s *compatStream) Next() (*provider.StreamChunk, error) {
+	for {
+		if len(s.queue) > 0 {
+			chunk := s.queue[0]
+			s.queue = s.queue[1:]
+			return chunk, nil
+		}
+		if s.done {
+			return nil, io.EOF
+		}
+
+		chunk, err := s.inner.Next()
+		if err == io.EOF {
+			s.done = true
+			if !s.finished {
+				s.finish(&provider.StreamChunk{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			if s.format == "" || s.finished {
+				return chunk, nil
+			}
+			s.addText(chunk.Text)
+		case provider.ChunkTypeToolCall:
+			s.toolCalls = true
+			return chunk, nil
+		case provider.ChunkTypeFinish:
+			if s.finished {
+				continue
+			}
+			finish := *chunk
+			s.finish(&finish)
+		default:
+			return chunk, nil
+		}
+	}
+}
+
+// addText streams text that cannot be part of a tool call and holds back
+// the rest
+func (s *compatStream) addText(text string) {
+	if s.capturing {
+		s.held.WriteString(text)
+		return
+	}
+	pending := s.held.String() + text
+	s.held.Reset()
+
+	switch s.format {
+	case ToolCallsHermes:
+		if i := strings.Index(pending, hermesOpenTag); i >= 0 {
+			s.emitText(pending[:i])
+			s.held.WriteString(pending[i:])
+			s.capturing = true
+			return
+		}
+		// Hold back a suffix that may be the start of the tag
+		keep := 0
+		for n := len(hermesOpenTag) - 1; n > 0; n-- {
+			if strings.HasSuffix(pending, hermesOpenTag[:n]) {
+				keep = n
+				break
+			}
+		}
+		s.emitText(pending[:len(pending)-keep])
+		s.held.WriteString(pending[len(pending)-keep:])
+
+	case ToolCallsJSON:
+		trimmed := strings.TrimSpace(pending)
+		switch {
+		case trimmed == "":
+			s.held.WriteString(pending)
+		case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "`"):
+			s.held.WriteString(pending)
+			s.capturing = true
+		default:
+			// Plain text: stream it and everything after it unchanged
+			s.format = ""
+			s.emitText(pending)
+		}
+	}
+}
+
+// finish queues held text, parsed tool calls and the finish chunk
+func (s *compatStream) finish(chunk *provider.StreamChunk) {
+	s.finished = true
+	if held := s.held.String(); held != "" {
+		s.held.Reset()
+		text, calls := parseTextToolCalls(s.format, held, s.tools)
+		if len(calls) > 0 {
+			s.emitText(text)
+			for i := range calls {
+				s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeToolCall, ToolCall: &calls[i]})
+			}
+			s.toolCalls = true
+		} else {
+			s.emitText(held)
+		}
+	}
+	if s.toolCalls && (chunk.FinishReason == types.FinishReasonStop || chunk.FinishReason == types.FinishReasonOther) {
+		chunk.FinishReason = types.FinishReasonToolCalls
+	}
+	s.queue = append(s.queue, chunk)
+}
+
+func (s *compatStream) emitText(text string) {
+	if text != "" {
+		s.queue = append(s.queue, &provider.StreamChunk{Type: provider.ChunkTypeText, Text: text})
+	}
+}
+
+// Err returns any error that occurred during streaming
+func (s *compatStream) Err() error {
+	return s.inner.Err()
+}
+
+// Close closes the underlying stream. Later Next calls return io.EOF.
+func (s *compatStream) Close() error {
+	s.queue = nil
+	s.done = true
+	s.finished = true
+	return s.inner.Close()
+}
@@ -0,0 +1,115 @@
+package openaicompatible
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// hermesToolCall matches a Hermes-style tool call block. The closing tag is
+// optional because some servers stop on it, leaving it out of the text.
+var hermesToolCall = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*(?:</tool_call>|$)`)
+
+// hermesOpenTag starts a Hermes-style tool call block
+const hermesOpenTag = "<tool_call>"
+
+// textToolCall is a tool call written into the message text
+type textToolCall struct {
+	Name       string          `json:"name"`
+	Arguments  json.RawMessage `json:"arguments"`
+	Parameters json.RawMessage `json:"parameters"`
+}
+
+// parseTextToolCalls extracts tool calls written into the message text and
+// returns the text that remains. Only calls to tools in the request are
+// accepted; anything else is left in the text.
+func parseTextToolCalls(format ToolCallFormat, text string, tools []types.Tool) (string, []types.ToolCall) {
+	known := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		known[t.Name] = true
+	}
+
+	switch format {
+	case ToolCallsHermes:
+		var calls []types.ToolCall
+		remaining := hermesToolCall.ReplaceAllStringFunc(text, func(block string) string {
+			body := hermesToolCall.FindStringSubmatch(block)[1]
+			var raw textToolCall
+			if json.Unmarshal([]byte(body), &raw) != nil || !known[raw.Name] {
+				return block
+			}
+			call, ok := raw.toolCall(len(calls))
+			if !ok {
+				return block
+			}
+			calls = append(calls, call)
+			return ""
+		})
+		return strings.TrimSpace(remaining), calls
+
+	case ToolCallsJSON:
+		body := strings.TrimSpace(text)
+		body = strings.TrimPrefix(body, "```json")
+		body = strings.TrimSpace(strings.Trim(body, "`"))
+
+		var raws []textToolCall
+		if strings.HasPrefix(body, "[") {
+			if json.Unmarshal([]byte(body), &raws) != nil {
+				return text, nil
+			}
+		} else {
+			var raw textToolCall
+			if json.Unmarshal([]byte(body), &raw) != nil {
+				return text, nil
+			}
+			raws = []textToolCall{raw}
+		}
+
+		calls := make([]types.ToolCall, 0, len(raws))
+		for i, raw := range raws {
+			call, ok := raw.toolCall(i)
+			if !ok || !known[raw.Name] {
+				return text, nil
+			}
+			calls = append(calls, call)
+		}
+		if len(calls) == 0 {
+			return text, nil
+		}
+		return "", calls
+	}
+	return text, nil
+}
+
+// toolCall converts a parsed call, accepting arguments as an object or as a
+// JSON-encoded string under either "arguments" or "parameters"
+func (c textToolCall) toolCall(index int) (types.ToolCall, bool) {
+	if c.Name == "" {
+		return types.ToolCall{}, false
+	}
+	raw := c.Arguments
+	if len(raw) == 0 {
+		raw = c.Parameters
+	}
+	var args map[string]interface{}
+	if len(raw) > 0 && string(raw) != "null" {
+		var encoded string
+		if json.Unmarshal(raw, &encoded) == nil {
+			raw = json.RawMessage(encoded)
+		}
+		if json.Unmarshal(raw, &args) != nil {
+			return types.ToolCall{}, false
+		}
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	return types.ToolCall{
+		ID:        fmt.Sprintf("call_%d", index),
+		ToolName:  c.Name,
+		Arguments: args,
+	}, true
+}
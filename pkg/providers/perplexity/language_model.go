@@ -2,6 +2,8 @@ package perplexity
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -103,6 +105,51 @@ func (m *LanguageModel) DoStream(ctx context.Context, opts *provider.GenerateOpt
 	return streaming.NewWarningsStream(inner, warnings), nil
 }
 
+// PerplexityProviderOptions are Perplexity-specific search options, passed
+// as ProviderOptions["perplexity"] (a PerplexityProviderOptions value or an
+// equivalent map using the same keys).
+//
+// Example:
+//
+//	opts.ProviderOptions = map[string]interface{}{
+//		"perplexity": perplexity.PerplexityProviderOptions{
+//			SearchDomainFilter:  []string{"go.dev", "-reddit.com"},
+//			SearchRecencyFilter: "month",
+//		},
+//	}
+type PerplexityProviderOptions struct {
+	// SearchDomainFilter limits search to these domains (at most 20). Prefix
+	// a domain with "-" to exclude it instead.
+	SearchDomainFilter []string `json:"search_domain_filter,omitempty"`
+
+	// SearchRecencyFilter limits results by publication time: "hour",
+	// "day", "week", "month" or "year"
+	SearchRecencyFilter string `json:"search_recency_filter,omitempty"`
+
+	// SearchAfterDateFilter and SearchBeforeDateFilter limit results to a
+	// publication date range, formatted as "3/1/2025"
+	SearchAfterDateFilter  string `json:"search_after_date_filter,omitempty"`
+	SearchBeforeDateFilter string `json:"search_before_date_filter,omitempty"`
+
+	// SearchMode selects the search index: "web" (default), "academic" or "sec"
+	SearchMode string `json:"search_mode,omitempty"`
+
+	// ReturnImages includes images in the response metadata
+	ReturnImages *bool `json:"return_images,omitempty"`
+
+	// ReturnRelatedQuestions includes follow-up questions in the response metadata
+	ReturnRelatedQuestions *bool `json:"return_related_questions,omitempty"`
+
+	// WebSearchOptions tunes how much search context the model receives
+	WebSearchOptions *PerplexityWebSearchOptions `json:"web_search_options,omitempty"`
+}
+
+// PerplexityWebSearchOptions tunes web search
+type PerplexityWebSearchOptions struct {
+	// SearchContextSize is "low", "medium" or "high"
+	SearchContextSize string `json:"search_context_size,omitempty"`
+}
+
 func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream bool) map[string]interface{} {
 	body := map[string]interface{}{
 		"model":  m.modelID,
@@ -130,6 +177,19 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 	if opts.TopP != nil {
 		body["top_p"] = *opts.TopP
 	}
+
+	// Search options use the API's own field names, so they are copied as-is
+	if raw, ok := opts.ProviderOptions["perplexity"]; ok {
+		var pplxOpts PerplexityProviderOptions
+		if jsonData, err := json.Marshal(raw); err == nil && json.Unmarshal(jsonData, &pplxOpts) == nil {
+			var fields map[string]interface{}
+			if jsonData, err := json.Marshal(pplxOpts); err == nil && json.Unmarshal(jsonData, &fields) == nil {
+				for k, v := range fields {
+					body[k] = v
+				}
+			}
+		}
+	}
 	return body
 }
 
@@ -167,6 +227,10 @@ type PerplexityMetadata struct {
 	Images []PerplexityImage   `json:"images"`
 	Usage  PerplexityUsageMeta `json:"usage"`
 	Cost   *PerplexityCost     `json:"cost"`
+
+	// RelatedQuestions are suggested follow-ups, returned when
+	// ReturnRelatedQuestions is set
+	RelatedQuestions []string `json:"relatedQuestions,omitempty"`
 }
 
 func (m *LanguageModel) convertResponse(response perplexityResponse) *types.GenerateResult {
@@ -183,6 +247,9 @@ func (m *LanguageModel) convertResponse(response perplexityResponse) *types.Gene
 		Usage:        convertPerplexityUsage(response.Usage),
 		RawResponse:  response,
 	}
+	for _, source := range convertSources(response.Citations, response.SearchResults) {
+		result.Content = append(result.Content, source)
+	}
 
 	// Build providerMetadata.perplexity — always set (matches TS SDK behaviour).
 	meta := PerplexityMetadata{
@@ -190,6 +257,7 @@ func (m *LanguageModel) convertResponse(response perplexityResponse) *types.Gene
 			CitationTokens:   response.Usage.CitationTokens,
 			NumSearchQueries: response.Usage.NumSearchQueries,
 		},
+		RelatedQuestions: response.RelatedQuestions,
 	}
 
 	// Map images from API wire format to public type.
@@ -217,6 +285,41 @@ func (m *LanguageModel) convertResponse(response perplexityResponse) *types.Gene
 	return result
 }
 
+// convertSources maps citations and search results to source parts, in
+// citation order. IDs are numbered from 1, matching the [n] citation markers
+// in the text. Search results supply titles and dates; citations without a
+// matching search result become bare URL sources.
+func convertSources(citations []string, results []perplexitySearchResult) []types.SourceContent {
+	byURL := make(map[string]perplexitySearchResult, len(results))
+	for _, r := range results {
+		byURL[r.URL] = r
+	}
+	urls := citations
+	if len(urls) == 0 {
+		for _, r := range results {
+			urls = append(urls, r.URL)
+		}
+	}
+
+	sources := make([]types.SourceContent, 0, len(urls))
+	for i, url := range urls {
+		source := types.SourceContent{
+			SourceType: "url",
+			ID:         fmt.Sprintf("perplexity-citation-%d", i+1),
+			URL:        url,
+		}
+		if r, ok := byURL[url]; ok {
+			source.Title = r.Title
+			if r.Date != "" || r.LastUpdated != "" || r.Snippet != "" {
+				meta, _ := json.Marshal(map[string]string{"date": r.Date, "lastUpdated": r.LastUpdated, "snippet": r.Snippet})
+				source.ProviderMetadata = meta
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
 func (m *LanguageModel) handleError(err error) error {
 	return providererrors.NewProviderError("perplexity", 0, "", err.Error(), err)
 }
@@ -268,6 +371,10 @@ type perplexityResponse struct {
 	Model     string               `json:"model"`
 	Citations []string             `json:"citations,omitempty"`
 	Images    []perplexityRawImage `json:"images,omitempty"`
+
+	SearchResults    []perplexitySearchResult `json:"search_results,omitempty"`
+	RelatedQuestions []string                 `json:"related_questions,omitempty"`
+
 	Choices   []struct {
 		Index        int    `json:"index"`
 		FinishReason string `json:"finish_reason"`
@@ -279,6 +386,15 @@ type perplexityResponse struct {
 	Usage perplexityUsage `json:"usage"`
 }
 
+// perplexitySearchResult is a search result the answer drew on
+type perplexitySearchResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Date        string `json:"date,omitempty"`
+	LastUpdated string `json:"last_updated,omitempty"`
+	Snippet     string `json:"snippet,omitempty"`
+}
+
 // perplexityRawImage is the wire format of an image returned by the Perplexity API.
 type perplexityRawImage struct {
 	ImageUrl  string `json:"image_url"`
@@ -322,7 +438,32 @@ type perplexityStream struct {
 }
 
 func newPerplexityStream(reader io.ReadCloser) *perplexityStream {
-	return &perplexityStream{
+	s := &perplexityStream{
 		OpenAICompatStream: streaming.NewOpenAICompatStream(reader, providerutils.MapOpenAIFinishReason),
 	}
+	// Every chunk repeats the citations; emit them as sources once
+	emitted := false
+	s.OnBeforeDelta = func(data []byte) []*provider.StreamChunk {
+		if emitted {
+			return nil
+		}
+		var peek struct {
+			Citations     []string                 `json:"citations"`
+			SearchResults []perplexitySearchResult `json:"search_results"`
+		}
+		if json.Unmarshal(data, &peek) != nil {
+			return nil
+		}
+		sources := convertSources(peek.Citations, peek.SearchResults)
+		if len(sources) == 0 {
+			return nil
+		}
+		emitted = true
+		chunks := make([]*provider.StreamChunk, len(sources))
+		for i := range sources {
+			chunks[i] = &provider.StreamChunk{Type: provider.ChunkTypeSource, SourceContent: &sources[i]}
+		}
+		return chunks
+	}
+	return s
 }
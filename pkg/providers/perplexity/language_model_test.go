@@ -1,8 +1,11 @@
 package perplexity
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/digitallysavvy/go-ai/pkg/provider"
@@ -229,3 +232,128 @@ func TestPerplexityReasoningNotAddedToBody(t *testing.T) {
 		t.Error("Perplexity should not set reasoning_effort in body; warning is emitted instead")
 	}
 }
+
+func TestPerplexityCitationsAsSources(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"test","model":"sonar","citations":["https://go.dev/doc","https://example.com/post"],"search_results":[{"title":"Go docs","url":"https://go.dev/doc","date":"2025-01-02"}],"related_questions":["What is Go?"],"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Go is fast [1][2]."}}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`))
+	}))
+	defer srv.Close()
+
+	model := NewLanguageModel(New(Config{BaseURL: srv.URL, APIKey: "test-key"}), ModelSonar)
+	result, err := model.DoGenerate(t.Context(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var sources []types.SourceContent
+	for _, part := range result.Content {
+		if src, ok := part.(types.SourceContent); ok {
+			sources = append(sources, src)
+		}
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %+v", sources)
+	}
+	if sources[0].ID != "perplexity-citation-1" || sources[0].URL != "https://go.dev/doc" || sources[0].Title != "Go docs" {
+		t.Errorf("first source = %+v", sources[0])
+	}
+	if !strings.Contains(string(sources[0].ProviderMetadata), "2025-01-02") {
+		t.Errorf("first source metadata = %s", sources[0].ProviderMetadata)
+	}
+	if sources[1].URL != "https://example.com/post" || sources[1].Title != "" {
+		t.Errorf("second source = %+v", sources[1])
+	}
+	meta := result.ProviderMetadata["perplexity"].(PerplexityMetadata)
+	if len(meta.RelatedQuestions) != 1 {
+		t.Errorf("related questions = %v", meta.RelatedQuestions)
+	}
+}
+
+func TestPerplexityStreamSourcesEmittedOnce(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"citations":["https://go.dev"],"choices":[{"delta":{"content":"Go"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"citations":["https://go.dev"],"choices":[{"delta":{"content":" [1]"},"finish_reason":null}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"citations":["https://go.dev"],"choices":[{"delta":{},"finish_reason":"stop"}]}` + "\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	model := NewLanguageModel(New(Config{BaseURL: srv.URL, APIKey: "test-key"}), ModelSonar)
+	stream, err := model.DoStream(t.Context(), &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	var sources, texts int
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeSource:
+			sources++
+			if chunk.SourceContent.URL != "https://go.dev" {
+				t.Errorf("source = %+v", chunk.SourceContent)
+			}
+		case provider.ChunkTypeText:
+			texts++
+		}
+	}
+	if sources != 1 || texts != 2 {
+		t.Errorf("sources = %d, texts = %d; want 1 and 2", sources, texts)
+	}
+}
+
+func TestPerplexitySearchOptionsInBody(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"test","model":"sonar","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"hello"}}],"usage":{}}`))
+	}))
+	defer srv.Close()
+
+	model := NewLanguageModel(New(Config{BaseURL: srv.URL, APIKey: "test-key"}), ModelSonar)
+	returnImages := true
+	for _, opts := range []interface{}{
+		PerplexityProviderOptions{
+			SearchDomainFilter:  []string{"go.dev", "-reddit.com"},
+			SearchRecencyFilter: "month",
+			ReturnImages:        &returnImages,
+			WebSearchOptions:    &PerplexityWebSearchOptions{SearchContextSize: "high"},
+		},
+		map[string]interface{}{
+			"search_domain_filter":  []string{"go.dev", "-reddit.com"},
+			"search_recency_filter": "month",
+			"return_images":         true,
+			"web_search_options":    map[string]interface{}{"search_context_size": "high"},
+		},
+	} {
+		_, err := model.DoGenerate(t.Context(), &provider.GenerateOptions{
+			ProviderOptions: map[string]interface{}{"perplexity": opts},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		domains, _ := body["search_domain_filter"].([]interface{})
+		if len(domains) != 2 || domains[1] != "-reddit.com" {
+			t.Errorf("search_domain_filter = %v", body["search_domain_filter"])
+		}
+		if body["search_recency_filter"] != "month" || body["return_images"] != true {
+			t.Errorf("body = %v", body)
+		}
+		if ws, _ := body["web_search_options"].(map[string]interface{}); ws["search_context_size"] != "high" {
+			t.Errorf("web_search_options = %v", body["web_search_options"])
+		}
+		if _, ok := body["search_mode"]; ok {
+			t.Error("unset options should be omitted")
+		}
+	}
+}
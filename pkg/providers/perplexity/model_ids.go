@@ -0,0 +1,20 @@
+package perplexity
+
+// Language model ID constants for Perplexity Sonar models.
+// See https://docs.perplexity.ai/models/model-cards for the full list.
+const (
+	// ModelSonar — lightweight search model (default)
+	ModelSonar = "sonar"
+
+	// ModelSonarPro — advanced search model with more citations
+	ModelSonarPro = "sonar-pro"
+
+	// ModelSonarReasoning — search model with chain-of-thought reasoning
+	ModelSonarReasoning = "sonar-reasoning"
+
+	// ModelSonarReasoningPro — advanced search model with reasoning
+	ModelSonarReasoningPro = "sonar-reasoning-pro"
+
+	// ModelSonarDeepResearch — exhaustive multi-step research model
+	ModelSonarDeepResearch = "sonar-deep-research"
+)
@@ -51,7 +51,7 @@ func (p *Provider) Name() string {
 // LanguageModel returns a language model by ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	if modelID == "" {
-		modelID = "llama-3.1-sonar-small-128k-online"
+		modelID = ModelSonar
 	}
 
 	return NewLanguageModel(p, modelID), nil
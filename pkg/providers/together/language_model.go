@@ -45,19 +45,23 @@ func (m *LanguageModel) ModelID() string {
 	return m.modelID
 }
 
-// SupportsTools returns whether the model supports tool calling
+// SupportsTools returns whether the model supports tool calling. Models
+// not in the catalog are assumed to support it.
 func (m *LanguageModel) SupportsTools() bool {
-	return true
+	info, ok := m.provider.LookupModel(m.modelID)
+	return !ok || info.Tools
 }
 
 // SupportsStructuredOutput returns whether the model supports structured output
 func (m *LanguageModel) SupportsStructuredOutput() bool {
-	return true
+	info, ok := m.provider.LookupModel(m.modelID)
+	return !ok || info.JSONMode
 }
 
 // SupportsImageInput returns whether the model accepts image inputs
 func (m *LanguageModel) SupportsImageInput() bool {
-	return false
+	info, ok := m.provider.LookupModel(m.modelID)
+	return ok && info.ImageInput
 }
 
 // DoGenerate performs non-streaming text generation
@@ -93,6 +97,10 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 		"model":  m.modelID,
 		"stream": stream,
 	}
+	if stream {
+		// Report usage in the final stream event
+		body["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
 	if opts.Prompt.IsMessages() {
 		body["messages"] = prompt.ToOpenAIMessages(opts.Prompt.Messages)
 	} else if opts.Prompt.IsSimple() {
@@ -124,10 +132,12 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 			body["tool_choice"] = tool.ConvertToolChoiceToOpenAI(opts.ToolChoice)
 		}
 	}
-	if opts.ResponseFormat != nil {
-		body["response_format"] = map[string]interface{}{
-			"type": opts.ResponseFormat.Type,
+	if format := providerutils.OpenAIResponseFormat(opts.ResponseFormat); format != nil {
+		// Together reads the schema from the top level of response_format
+		if jsonSchema, ok := format["json_schema"].(map[string]interface{}); ok {
+			format["schema"] = jsonSchema["schema"]
 		}
+		body["response_format"] = format
 	}
 	return body
 }
@@ -146,6 +156,7 @@ func (m *LanguageModel) convertResponse(response togetherResponse) *types.Genera
 		Usage:        convertTogetherUsage(response.Usage),
 		RawResponse:  response,
 	}
+	result.ProviderMetadata = map[string]interface{}{"together": m.metadata(response.Model, result.Usage)}
 	if len(choice.Message.ToolCalls) > 0 {
 		result.ToolCalls = make([]types.ToolCall, len(choice.Message.ToolCalls))
 		for i, tc := range choice.Message.ToolCalls {
@@ -163,6 +174,16 @@ func (m *LanguageModel) convertResponse(response togetherResponse) *types.Genera
 	return result
 }
 
+// metadata returns the served model and the cost of usage at catalog prices
+func (m *LanguageModel) metadata(model string, usage types.Usage) *Metadata {
+	meta := &Metadata{Model: model}
+	if info, ok := m.provider.LookupModel(m.modelID); ok {
+		cost := info.Cost(usage)
+		meta.Cost = &cost
+	}
+	return meta
+}
+
 func (m *LanguageModel) handleError(err error) error {
 	return providererrors.NewProviderError("together", 0, "", err.Error(), err)
 }
@@ -0,0 +1,70 @@
+package together
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+func TestDoGenerateToolCallsAndCost(t *testing.T) {
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"model":"meta-llama/Llama-3.3-70B-Instruct-Turbo","choices":[{"index":0,"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":2000,"completion_tokens":1000,"total_tokens":3000}}`)
+	}))
+	defer server.Close()
+
+	model, _ := New(Config{APIKey: "test-key", BaseURL: server.URL}).LanguageModel(ModelLlama3p3_70BInstructTurbo)
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "Weather in Paris?"},
+		Tools:  []types.Tool{{Name: "get_weather", Parameters: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate: %v", err)
+	}
+
+	if tools, _ := gotBody["tools"].([]interface{}); len(tools) != 1 {
+		t.Errorf("tools = %v", gotBody["tools"])
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].Arguments["city"] != "Paris" {
+		t.Errorf("tool calls = %+v", result.ToolCalls)
+	}
+	meta, ok := result.ProviderMetadata["together"].(*Metadata)
+	// 3000 tokens at $0.88 per million
+	if !ok || meta.Cost == nil || *meta.Cost < 0.00263 || *meta.Cost > 0.00265 {
+		t.Errorf("metadata = %#v", result.ProviderMetadata["together"])
+	}
+}
+
+func TestBuildRequestBodyJSONMode(t *testing.T) {
+	model := NewLanguageModel(New(Config{APIKey: "test-key"}), ModelDeepSeekV3)
+	schema := map[string]interface{}{"type": "object"}
+
+	body := model.buildRequestBody(&provider.GenerateOptions{
+		Prompt:         types.Prompt{Text: "hi"},
+		ResponseFormat: &provider.ResponseFormat{Type: "json_schema", Schema: schema},
+	}, false)
+	format := body["response_format"].(map[string]interface{})
+	if format["type"] != "json_schema" || format["schema"] == nil || format["json_schema"] == nil {
+		t.Errorf("response_format = %v", format)
+	}
+
+	body = model.buildRequestBody(&provider.GenerateOptions{
+		Prompt:         types.Prompt{Text: "hi"},
+		ResponseFormat: &provider.ResponseFormat{Type: "json_object"},
+	}, false)
+	if format := body["response_format"].(map[string]interface{}); format["type"] != "json_object" {
+		t.Errorf("response_format = %v", format)
+	}
+
+	if NewLanguageModel(New(Config{APIKey: "test-key"}), ModelDeepSeekR1).SupportsTools() {
+		t.Error("DeepSeek R1 on Together does not support tools")
+	}
+}
@@ -0,0 +1,82 @@
+package together
+
+import "github.com/digitallysavvy/go-ai/pkg/provider/types"
+
+// Serverless language model IDs
+const (
+	ModelLlama3p3_70BInstructTurbo  = "meta-llama/Llama-3.3-70B-Instruct-Turbo"
+	ModelLlama3p1_8BInstructTurbo   = "meta-llama/Meta-Llama-3.1-8B-Instruct-Turbo"
+	ModelLlama3p1_405BInstructTurbo = "meta-llama/Meta-Llama-3.1-405B-Instruct-Turbo"
+	ModelLlama4Maverick             = "meta-llama/Llama-4-Maverick-17B-128E-Instruct-FP8"
+	ModelDeepSeekV3                 = "deepseek-ai/DeepSeek-V3"
+	ModelDeepSeekR1                 = "deepseek-ai/DeepSeek-R1"
+	ModelQwen2p5_72BInstructTurbo   = "Qwen/Qwen2.5-72B-Instruct-Turbo"
+	ModelMixtral8x7BInstruct        = "mistralai/Mixtral-8x7B-Instruct-v0.1"
+)
+
+// Embedding model IDs
+const (
+	ModelM2Bert80M8kRetrieval = "togethercomputer/m2-bert-80M-8k-retrieval"
+	ModelBGELargeEnV1p5       = "BAAI/bge-large-en-v1.5"
+)
+
+// ModelInfo describes a model's limits, capabilities and list prices
+type ModelInfo struct {
+	// ContextWindow is the maximum number of prompt and output tokens
+	ContextWindow int
+
+	// InputPrice and OutputPrice are USD per million tokens
+	InputPrice  float64
+	OutputPrice float64
+
+	// Tools reports native function calling support
+	Tools bool
+
+	// JSONMode reports JSON mode and JSON schema support
+	JSONMode bool
+
+	// ImageInput reports image input support
+	ImageInput bool
+}
+
+// Cost returns the USD cost of usage at the model's list prices
+func (i ModelInfo) Cost(usage types.Usage) float64 {
+	return (float64(usage.GetInputTokens())*i.InputPrice + float64(usage.GetOutputTokens())*i.OutputPrice) / 1e6
+}
+
+// Models is the catalog of serverless models, keyed by model ID. Prices are
+// Together's published serverless rates; use Config.Models to add dedicated
+// endpoints or update prices.
+var Models = map[string]ModelInfo{
+	ModelLlama3p3_70BInstructTurbo:  {ContextWindow: 131072, InputPrice: 0.88, OutputPrice: 0.88, Tools: true, JSONMode: true},
+	ModelLlama3p1_8BInstructTurbo:   {ContextWindow: 131072, InputPrice: 0.18, OutputPrice: 0.18, Tools: true, JSONMode: true},
+	ModelLlama3p1_405BInstructTurbo: {ContextWindow: 130815, InputPrice: 3.50, OutputPrice: 3.50, Tools: true, JSONMode: true},
+	ModelLlama4Maverick:             {ContextWindow: 1048576, InputPrice: 0.27, OutputPrice: 0.85, Tools: true, JSONMode: true, ImageInput: true},
+	ModelDeepSeekV3:                 {ContextWindow: 131072, InputPrice: 1.25, OutputPrice: 1.25, Tools: true, JSONMode: true},
+	ModelDeepSeekR1:                 {ContextWindow: 163840, InputPrice: 3.00, OutputPrice: 7.00, JSONMode: true},
+	ModelQwen2p5_72BInstructTurbo:   {ContextWindow: 32768, InputPrice: 1.20, OutputPrice: 1.20, Tools: true, JSONMode: true},
+	ModelMixtral8x7BInstruct:        {ContextWindow: 32768, InputPrice: 0.60, OutputPrice: 0.60, JSONMode: true},
+	ModelM2Bert80M8kRetrieval:       {ContextWindow: 8192, InputPrice: 0.008},
+	ModelBGELargeEnV1p5:             {ContextWindow: 512, InputPrice: 0.016},
+}
+
+// LookupModel returns catalog information for a model, checking the
+// provider's Config.Models before the built-in catalog
+func (p *Provider) LookupModel(modelID string) (ModelInfo, bool) {
+	if info, ok := p.config.Models[modelID]; ok {
+		return info, true
+	}
+	info, ok := Models[modelID]
+	return info, ok
+}
+
+// Metadata is the Together-specific metadata of a generation, stored in
+// GenerateResult.ProviderMetadata["together"]
+type Metadata struct {
+	// Model is the model that served the request
+	Model string `json:"model,omitempty"`
+
+	// Cost is the USD cost at catalog list prices; nil for models not in
+	// the catalog
+	Cost *float64 `json:"cost,omitempty"`
+}
@@ -1,12 +1,14 @@
 package together
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 // Provider implements the provider.Provider interface for Together AI
@@ -22,6 +24,10 @@ type Config struct {
 
 	// BaseURL is the base URL for the Together AI API (optional)
 	BaseURL string
+
+	// Models adds catalog entries for dedicated endpoints and fine-tuned
+	// models, or overrides built-in entries, keyed by model ID
+	Models map[string]ModelInfo
 }
 
 // getAPIKey resolves the Together AI API key.
@@ -76,10 +82,21 @@ func (p *Provider) Name() string {
 	return "together"
 }
 
+// Ping checks that the API is reachable and the credentials are valid by
+// listing available models. It does not consume tokens.
+func (p *Provider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return providerutils.PingEndpoint(ctx, p.client, p.Name(), "/v1/models")
+}
+
+// ListModels lists the models available to the configured credentials.
+func (p *Provider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return providerutils.ListModelsEndpoint(ctx, p.client, p.Name(), "/v1/models", nil)
+}
+
 // LanguageModel returns a language model by ID
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
 	if modelID == "" {
-		modelID = "mistralai/Mixtral-8x7B-Instruct-v0.1"
+		modelID = ModelMixtral8x7BInstruct
 	}
 
 	return NewLanguageModel(p, modelID), nil
@@ -88,7 +105,7 @@ func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error)
 // EmbeddingModel returns an embedding model by ID
 func (p *Provider) EmbeddingModel(modelID string) (provider.EmbeddingModel, error) {
 	if modelID == "" {
-		modelID = "togethercomputer/m2-bert-80M-8k-retrieval"
+		modelID = ModelM2Bert80M8kRetrieval
 	}
 
 	return NewEmbeddingModel(p, modelID), nil
@@ -0,0 +1,61 @@
+package xai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/internal/polling"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+// xaiDeferredResponse is returned when a chat completion is submitted with
+// deferred: true
+type xaiDeferredResponse struct {
+	RequestID string `json:"request_id"`
+}
+
+// generateDeferred submits a deferred chat completion and polls until xAI
+// has the result. Pending completions answer 202 Accepted.
+func (m *LanguageModel) generateDeferred(ctx context.Context, reqBody map[string]interface{}, xaiOpts XAIChatProviderOptions) (xaiResponse, string, error) {
+	var created xaiDeferredResponse
+	if err := m.provider.client.PostJSON(ctx, "/v1/chat/completions", reqBody, &created); err != nil {
+		return xaiResponse{}, "", m.handleError(err)
+	}
+	if created.RequestID == "" {
+		return xaiResponse{}, "", providererrors.NewProviderError("xai", 0, "",
+			"No request_id returned for deferred chat completion", nil)
+	}
+
+	pollOpts := polling.PollOptions{PollIntervalMs: 2000, PollTimeoutMs: 600000}
+	if xaiOpts.PollIntervalMs != nil && *xaiOpts.PollIntervalMs > 0 {
+		pollOpts.PollIntervalMs = *xaiOpts.PollIntervalMs
+	}
+	if xaiOpts.PollTimeoutMs != nil && *xaiOpts.PollTimeoutMs > 0 {
+		pollOpts.PollTimeoutMs = *xaiOpts.PollTimeoutMs
+	}
+
+	var response xaiResponse
+	statusChecker := func(ctx context.Context) (*polling.JobResult, error) {
+		resp, err := m.provider.client.Do(ctx, internalhttp.Request{
+			Method: http.MethodGet,
+			Path:   "/v1/chat/deferred-completion/" + created.RequestID,
+		})
+		if err != nil {
+			return nil, m.handleError(err)
+		}
+		if resp.StatusCode == http.StatusAccepted {
+			return &polling.JobResult{Status: polling.JobStatusProcessing}, nil
+		}
+		if err := json.Unmarshal(resp.Body, &response); err != nil {
+			return nil, m.handleError(fmt.Errorf("failed to decode deferred completion: %w", err))
+		}
+		return &polling.JobResult{Status: polling.JobStatusCompleted}, nil
+	}
+	if _, err := polling.PollForCompletion(ctx, statusChecker, pollOpts); err != nil {
+		return xaiResponse{}, "", err
+	}
+	return response, created.RequestID, nil
+}
@@ -66,8 +66,14 @@ func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateO
 	warnings := m.checkUnsupportedOptions(opts)
 	reqBody := m.buildRequestBody(opts, false)
 	var response xaiResponse
-	err := m.provider.client.PostJSON(ctx, "/v1/chat/completions", reqBody, &response)
-	if err != nil {
+	var deferredID string
+	if xaiOpts := chatProviderOptions(opts); xaiOpts.Deferred != nil && *xaiOpts.Deferred {
+		reqBody["deferred"] = true
+		var err error
+		if response, deferredID, err = m.generateDeferred(ctx, reqBody, xaiOpts); err != nil {
+			return nil, err
+		}
+	} else if err := m.provider.client.PostJSON(ctx, "/v1/chat/completions", reqBody, &response); err != nil {
 		return nil, m.handleError(err)
 	}
 	// Surface API-level errors returned in the response body.
@@ -76,6 +82,12 @@ func (m *LanguageModel) DoGenerate(ctx context.Context, opts *provider.GenerateO
 	}
 	result := m.convertResponse(response, lastAssistantText(opts))
 	result.Warnings = append(warnings, result.Warnings...)
+	if deferredID != "" {
+		if result.ProviderMetadata == nil {
+			result.ProviderMetadata = map[string]interface{}{}
+		}
+		result.ProviderMetadata["xai"] = map[string]interface{}{"deferredRequestId": deferredID}
+	}
 	return result, nil
 }
 
@@ -148,6 +160,19 @@ type XAIChatProviderOptions struct {
 
 	// SearchParameters configures the Live Search / web search behavior.
 	SearchParameters *XAIChatSearchParameters `json:"searchParameters,omitempty"`
+
+	// Deferred submits the completion as a deferred request and polls for
+	// its result, for long-running reasoning requests that would otherwise
+	// hold a connection open. Ignored by DoStream.
+	Deferred *bool `json:"deferred,omitempty"`
+
+	// PollIntervalMs is the interval between deferred result checks in
+	// milliseconds (default: 2000)
+	PollIntervalMs *int `json:"pollIntervalMs,omitempty"`
+
+	// PollTimeoutMs is the maximum time to wait for a deferred result in
+	// milliseconds (default: 600000)
+	PollTimeoutMs *int `json:"pollTimeoutMs,omitempty"`
 }
 
 // XAIChatSearchParameters configures the XAI Live Search feature.
@@ -207,8 +232,8 @@ type XAIChatSearchSource struct {
 	Links []string `json:"links,omitempty"`
 }
 
-func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream bool) map[string]interface{} {
-	// Extract XAI-specific provider options.
+// chatProviderOptions extracts the XAI-specific provider options
+func chatProviderOptions(opts *provider.GenerateOptions) XAIChatProviderOptions {
 	var xaiOpts XAIChatProviderOptions
 	if opts.ProviderOptions != nil {
 		if raw, ok := opts.ProviderOptions["xai"]; ok {
@@ -217,6 +242,11 @@ func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream
 			}
 		}
 	}
+	return xaiOpts
+}
+
+func (m *LanguageModel) buildRequestBody(opts *provider.GenerateOptions, stream bool) map[string]interface{} {
+	xaiOpts := chatProviderOptions(opts)
 
 	body := map[string]interface{}{
 		"model":  m.modelID,
@@ -365,3 +365,67 @@ func TestXAIChatBuildRequestBodyLogprobsNone(t *testing.T) {
 		t.Error("top_logprobs should not be in request body when not configured")
 	}
 }
+
+// TestXAIChatDeferredCompletion verifies that deferred completions are
+// submitted with deferred:true and polled until the result is ready.
+func TestXAIChatDeferredCompletion(t *testing.T) {
+	var submitted map[string]interface{}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/chat/completions":
+			json.NewDecoder(r.Body).Decode(&submitted) //nolint:errcheck
+			json.NewEncoder(w).Encode(map[string]interface{}{"request_id": "req-1"}) //nolint:errcheck
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/chat/deferred-completion/req-1":
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+				"choices": []interface{}{map[string]interface{}{
+					"finish_reason": "stop",
+					"message": map[string]interface{}{
+						"role":              "assistant",
+						"content":           "42",
+						"reasoning_content": "thinking",
+					},
+				}},
+				"usage": map[string]interface{}{
+					"prompt_tokens":             5,
+					"completion_tokens":         1,
+					"total_tokens":              26,
+					"completion_tokens_details": map[string]interface{}{"reasoning_tokens": 20},
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	model := NewLanguageModel(New(Config{APIKey: "test-key", BaseURL: server.URL}), "grok-4")
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{
+		Prompt: types.Prompt{Text: "what is the answer?"},
+		ProviderOptions: map[string]interface{}{
+			"xai": map[string]interface{}{"deferred": true, "pollIntervalMs": 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DoGenerate() error: %v", err)
+	}
+	if submitted["deferred"] != true {
+		t.Errorf("deferred = %v, want true", submitted["deferred"])
+	}
+	if polls != 2 || result.Text != "42" {
+		t.Errorf("polls = %d, text = %q", polls, result.Text)
+	}
+	if result.Usage.OutputDetails == nil || result.Usage.OutputDetails.ReasoningTokens == nil || *result.Usage.OutputDetails.ReasoningTokens != 20 {
+		t.Errorf("reasoning tokens not mapped: %+v", result.Usage.OutputDetails)
+	}
+	if meta, _ := result.ProviderMetadata["xai"].(map[string]interface{}); meta["deferredRequestId"] != "req-1" {
+		t.Errorf("provider metadata = %v", result.ProviderMetadata)
+	}
+}
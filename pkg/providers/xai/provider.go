@@ -1,11 +1,13 @@
 package xai
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/digitallysavvy/go-ai/pkg/internal/http"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/providerutils"
 )
 
 // Provider implements the provider.Provider interface for xAI (Grok)
@@ -64,6 +66,17 @@ func (p *Provider) Name() string {
 	return "xai"
 }
 
+// Ping checks that the API is reachable and the credentials are valid by
+// listing available models. It does not consume tokens.
+func (p *Provider) Ping(ctx context.Context) (*provider.PingResult, error) {
+	return providerutils.PingEndpoint(ctx, p.client, p.Name(), "/v1/models")
+}
+
+// ListModels lists the models available to the configured credentials.
+func (p *Provider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	return providerutils.ListModelsEndpoint(ctx, p.client, p.Name(), "/v1/models", nil)
+}
+
 // LanguageModel returns a language model by ID using the Responses API (default).
 // Use ChatCompletionsLanguageModel for the legacy Chat Completions API.
 func (p *Provider) LanguageModel(modelID string) (provider.LanguageModel, error) {
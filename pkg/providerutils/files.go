@@ -0,0 +1,73 @@
+package providerutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/fileutil"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// FileMimeType returns the upload's MIME type without parameters, detecting
+// it from the filename and then the content when not given
+func FileMimeType(opts provider.FileUploadOptions) string {
+	mimeType := opts.MimeType
+	if mimeType == "" {
+		mimeType = fileutil.DetectMediaTypeFromFilename(opts.Filename).MimeType
+		if mimeType == "application/octet-stream" && len(opts.Data) > 0 {
+			mimeType = fileutil.DetectMediaType(opts.Data).MimeType
+		}
+	}
+	if base, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return base
+	}
+	return mimeType
+}
+
+// MultipartFileUpload validates the upload against maxSize (0 for no limit)
+// and encodes it as multipart form data with the file under "file" and the
+// given extra fields. It returns the body and its Content-Type.
+func MultipartFileUpload(opts provider.FileUploadOptions, maxSize int64, fields map[string]string) (io.Reader, string, error) {
+	if opts.Filename == "" {
+		return nil, "", fmt.Errorf("filename is required")
+	}
+	if len(opts.Data) == 0 {
+		return nil, "", fmt.Errorf("file %q is empty", opts.Filename)
+	}
+	if maxSize > 0 && int64(len(opts.Data)) > maxSize {
+		return nil, "", fmt.Errorf("%w: %q is %d bytes, limit is %d", provider.ErrFileTooLarge, opts.Filename, len(opts.Data), maxSize)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(opts.Filename)))
+	header.Set("Content-Type", FileMimeType(opts))
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(opts.Data); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -3,10 +3,12 @@ package providerutils
 import "github.com/digitallysavvy/go-ai/pkg/provider/types"
 
 // MapOpenAIFinishReason maps OpenAI-compatible finish reason strings to SDK types.
-// Handles both current ("tool_calls") and legacy ("function_call") values.
+// Handles both current ("tool_calls") and legacy ("function_call") values,
+// and the "eos_token" and "stop_sequence" values sent by Text Generation
+// Inference.
 func MapOpenAIFinishReason(reason string) types.FinishReason {
 	switch reason {
-	case "stop":
+	case "stop", "eos_token", "stop_sequence":
 		return types.FinishReasonStop
 	case "length":
 		return types.FinishReasonLength
@@ -12,6 +12,8 @@ func TestMapOpenAIFinishReason(t *testing.T) {
 		expected types.FinishReason
 	}{
 		{"stop", types.FinishReasonStop},
+		{"eos_token", types.FinishReasonStop},
+		{"stop_sequence", types.FinishReasonStop},
 		{"length", types.FinishReasonLength},
 		{"tool_calls", types.FinishReasonToolCalls},
 		{"function_call", types.FinishReasonToolCalls},
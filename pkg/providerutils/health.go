@@ -0,0 +1,33 @@
+package providerutils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+// PingEndpoint issues a GET request to path using the provider's configured
+// client (and therefore its credentials) and measures the round-trip latency.
+// Any status >= 400 is reported as a *providererrors.ProviderError so callers
+// can distinguish authentication failures from outages.
+func PingEndpoint(ctx context.Context, client *internalhttp.Client, providerName, path string) (*provider.PingResult, error) {
+	result := &provider.PingResult{Provider: providerName, CheckedAt: time.Now()}
+
+	resp, err := client.Do(ctx, internalhttp.Request{Method: http.MethodGet, Path: path})
+	result.Latency = time.Since(result.CheckedAt)
+	if err != nil {
+		return result, providererrors.NewProviderError(providerName, 0, "", err.Error(), err)
+	}
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		msg := fmt.Sprintf("health check failed with status %d", resp.StatusCode)
+		return result, providererrors.NewProviderError(providerName, resp.StatusCode, "", msg, nil)
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package providerutils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+func TestPingEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client := internalhttp.NewClient(internalhttp.Config{
+		BaseURL: server.URL,
+		Headers: map[string]string{"Authorization": "Bearer good"},
+	})
+	result, err := PingEndpoint(context.Background(), client, "test", "/v1/models")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.StatusCode != http.StatusOK || result.Provider != "test" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	badClient := internalhttp.NewClient(internalhttp.Config{
+		BaseURL: server.URL,
+		Headers: map[string]string{"Authorization": "Bearer bad"},
+	})
+	result, err = PingEndpoint(context.Background(), badClient, "test", "/v1/models")
+	if !providererrors.IsProviderError(err) {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+	if result == nil || result.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 result, got %+v", result)
+	}
+}
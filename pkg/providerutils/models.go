@@ -0,0 +1,112 @@
+package providerutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+// ListModelsEndpoint fetches a model list with a GET request to path using
+// the provider's configured client. It understands the OpenAI-compatible
+// {"data": [...]} envelope, Ollama's {"models": [...]} and a bare array, and
+// maps the common id/name, owned_by, type, context-length and creation-time
+// fields onto provider.ModelListing.
+func ListModelsEndpoint(ctx context.Context, client *internalhttp.Client, providerName, path string, query map[string]string) ([]provider.ModelListing, error) {
+	resp, err := client.Do(ctx, internalhttp.Request{Method: http.MethodGet, Path: path, Query: query})
+	if err != nil {
+		return nil, providererrors.NewProviderError(providerName, 0, "", err.Error(), err)
+	}
+	if resp.StatusCode >= 400 {
+		msg := fmt.Sprintf("listing models failed with status %d", resp.StatusCode)
+		return nil, providererrors.NewProviderError(providerName, resp.StatusCode, "", msg, nil)
+	}
+
+	var raw []listedModel
+	if len(resp.Body) > 0 && resp.Body[0] == '[' {
+		err = json.Unmarshal(resp.Body, &raw)
+	} else {
+		var envelope struct {
+			Data   []listedModel `json:"data"`
+			Models []listedModel `json:"models"`
+		}
+		err = json.Unmarshal(resp.Body, &envelope)
+		raw = append(envelope.Data, envelope.Models...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s model list: %w", providerName, err)
+	}
+
+	models := make([]provider.ModelListing, 0, len(raw))
+	for _, m := range raw {
+		if listing := m.listing(); listing.ID != "" {
+			models = append(models, listing)
+		}
+	}
+	return models, nil
+}
+
+// listedModel is the union of the model fields providers report
+type listedModel struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	DisplayName      string          `json:"display_name"`
+	OwnedBy          string          `json:"owned_by"`
+	Organization     string          `json:"organization"`
+	Type             string          `json:"type"`
+	ContextWindow    int             `json:"context_window"`
+	ContextLength    int             `json:"context_length"`
+	MaxContextLength int             `json:"max_context_length"`
+	Created          json.RawMessage `json:"created"`
+	CreatedAt        string          `json:"created_at"`
+	ModifiedAt       string          `json:"modified_at"`
+}
+
+func (m listedModel) listing() provider.ModelListing {
+	listing := provider.ModelListing{
+		ID:          m.ID,
+		DisplayName: firstNonEmpty(m.DisplayName, m.Name),
+		OwnedBy:     firstNonEmpty(m.OwnedBy, m.Organization),
+		Type:        m.Type,
+	}
+	if listing.ID == "" {
+		listing.ID, listing.DisplayName = m.Name, m.DisplayName
+	}
+	// Anthropic reports type "model" for every entry
+	if listing.Type == "model" {
+		listing.Type = ""
+	}
+	for _, n := range []int{m.ContextWindow, m.ContextLength, m.MaxContextLength} {
+		if n > 0 {
+			listing.ContextWindow = n
+			break
+		}
+	}
+
+	var unix int64
+	if json.Unmarshal(m.Created, &unix) == nil && unix > 0 {
+		listing.Created = time.Unix(unix, 0).UTC()
+	}
+	for _, s := range []string{m.CreatedAt, m.ModifiedAt} {
+		if listing.Created.IsZero() && s != "" {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				listing.Created = t
+			}
+		}
+	}
+	return listing
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package providerutils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	internalhttp "github.com/digitallysavvy/go-ai/pkg/internal/http"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+)
+
+func TestListModelsEndpoint(t *testing.T) {
+	responses := map[string]string{
+		"/v1/models": `{"object": "list", "data": [
+			{"id": "gpt-4o", "object": "model", "created": 1715367049, "owned_by": "system"},
+			{"id": "claude-sonnet-4-5", "type": "model", "display_name": "Claude Sonnet 4.5", "created_at": "2025-09-29T00:00:00Z"}
+		]}`,
+		"/together/models": `[{"id": "meta-llama/Llama-3.3-70B-Instruct-Turbo", "type": "chat", "organization": "Meta", "context_length": 131072}]`,
+		"/api/tags":        `{"models": [{"name": "llama3:latest", "modified_at": "2024-05-01T10:00:00.5Z"}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" && r.URL.Query().Get("limit") != "1000" {
+			t.Errorf("expected the limit query, got %q", r.URL.RawQuery)
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := internalhttp.NewClient(internalhttp.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	models, err := ListModelsEndpoint(ctx, client, "test", "/v1/models", map[string]string{"limit": "1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[0].OwnedBy != "system" || !models[0].Created.Equal(time.Unix(1715367049, 0)) {
+		t.Errorf("models = %+v", models)
+	}
+	if models[1].DisplayName != "Claude Sonnet 4.5" || models[1].Type != "" || models[1].Created.Year() != 2025 {
+		t.Errorf("anthropic model = %+v", models[1])
+	}
+
+	models, err = ListModelsEndpoint(ctx, client, "test", "/together/models", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].ContextWindow != 131072 || models[0].Type != "chat" || models[0].OwnedBy != "Meta" {
+		t.Errorf("together models = %+v", models)
+	}
+
+	models, err = ListModelsEndpoint(ctx, client, "test", "/api/tags", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].ID != "llama3:latest" || models[0].Created.IsZero() {
+		t.Errorf("ollama models = %+v", models)
+	}
+
+	if _, err := ListModelsEndpoint(ctx, client, "test", "/missing", nil); !providererrors.IsProviderError(err) {
+		t.Errorf("expected a provider error, got %v", err)
+	}
+}
@@ -87,6 +87,21 @@ func ToOpenAIMessages(messages []types.Message) []map[string]interface{} {
 							"url": imageData,
 						},
 					})
+				case types.FileContent:
+					file := map[string]interface{}{}
+					if p.FileID != "" {
+						file["file_id"] = p.FileID
+					} else {
+						file["file_data"] = fmt.Sprintf("data:%s;base64,%s",
+							p.MimeType, base64.StdEncoding.EncodeToString(p.Data))
+						if p.Filename != "" {
+							file["filename"] = p.Filename
+						}
+					}
+					contentParts = append(contentParts, map[string]interface{}{
+						"type": "file",
+						"file": file,
+					})
 				case types.CustomContent:
 					// CustomContent in assistant messages may carry OpenAI-specific
 					// provider options. Forward the openai-keyed options verbatim if
@@ -191,6 +206,8 @@ func ToAnthropicMessages(messages []types.Message) []map[string]interface{} {
 							"data":       imageData,
 						},
 					})
+				case types.FileContent:
+					contentParts = append(contentParts, anthropicFileBlock(p))
 				case types.CustomContent:
 					// CustomContent in assistant messages may carry Anthropic-specific
 					// provider options that the API understands (e.g., future block types).
@@ -282,6 +299,33 @@ func ToAnthropicMessages(messages []types.Message) []map[string]interface{} {
 	return result
 }
 
+// anthropicFileBlock converts a file to an image or document block, by
+// Files API ID when it has one
+func anthropicFileBlock(p types.FileContent) map[string]interface{} {
+	blockType := "document"
+	if strings.HasPrefix(p.MimeType, "image/") {
+		blockType = "image"
+	}
+	var source map[string]interface{}
+	switch {
+	case p.FileID != "":
+		source = map[string]interface{}{"type": "file", "file_id": p.FileID}
+	case blockType == "document" && strings.HasPrefix(p.MimeType, "text/"):
+		source = map[string]interface{}{"type": "text", "media_type": "text/plain", "data": string(p.Data)}
+	default:
+		source = map[string]interface{}{
+			"type":       "base64",
+			"media_type": p.MimeType,
+			"data":       base64.StdEncoding.EncodeToString(p.Data),
+		}
+	}
+	block := map[string]interface{}{"type": blockType, "source": source}
+	if blockType == "document" && p.Filename != "" {
+		block["title"] = p.Filename
+	}
+	return block
+}
+
 // ExtractSystemMessage extracts the system message from a list of messages
 // Used for providers that handle system messages separately (like Anthropic)
 func ExtractSystemMessage(messages []types.Message) string {
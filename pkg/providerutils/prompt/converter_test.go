@@ -565,3 +565,44 @@ func TestToGoogleMessagesAssistantFunctionCall(t *testing.T) {
 		t.Errorf("args[q] = %v, want go generics", args["q"])
 	}
 }
+
+// TestToAnthropicMessagesFileContent verifies that files are sent by Files API
+// ID when uploaded, and inline otherwise.
+func TestToAnthropicMessagesFileContent(t *testing.T) {
+	msgs := []types.Message{{
+		Role: types.RoleUser,
+		Content: []types.ContentPart{
+			types.FileContent{FileID: "file_123", MimeType: "application/pdf", Filename: "q3.pdf"},
+			types.FileContent{FileID: "file_456", MimeType: "image/png"},
+			types.FileContent{Data: []byte("hello"), MimeType: "text/plain"},
+		},
+	}}
+
+	content := ToAnthropicMessages(msgs)[0]["content"].([]map[string]interface{})
+	got, _ := json.Marshal(content)
+	want := `[{"source":{"file_id":"file_123","type":"file"},"title":"q3.pdf","type":"document"},` +
+		`{"source":{"file_id":"file_456","type":"file"},"type":"image"},` +
+		`{"source":{"data":"hello","media_type":"text/plain","type":"text"},"type":"document"}]`
+	if string(got) != want {
+		t.Errorf("content = %s, want %s", got, want)
+	}
+}
+
+// TestToOpenAIMessagesFileContent verifies file parts for Chat Completions.
+func TestToOpenAIMessagesFileContent(t *testing.T) {
+	msgs := []types.Message{{
+		Role: types.RoleUser,
+		Content: []types.ContentPart{
+			types.FileContent{FileID: "file-abc"},
+			types.FileContent{Data: []byte("%PDF"), MimeType: "application/pdf", Filename: "a.pdf"},
+		},
+	}}
+
+	content := ToOpenAIMessages(msgs)[0]["content"].([]map[string]interface{})
+	got, _ := json.Marshal(content)
+	want := `[{"file":{"file_id":"file-abc"},"type":"file"},` +
+		`{"file":{"file_data":"data:application/pdf;base64,JVBERg==","filename":"a.pdf"},"type":"file"}]`
+	if string(got) != want {
+		t.Errorf("content = %s, want %s", got, want)
+	}
+}
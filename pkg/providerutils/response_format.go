@@ -0,0 +1,36 @@
+package providerutils
+
+import "github.com/digitallysavvy/go-ai/pkg/provider"
+
+// OpenAIResponseFormat converts a ResponseFormat to the OpenAI chat
+// completions response_format object. Formats with a schema become
+// "json_schema"; "json" and "json_object" without one become "json_object".
+// Returns nil for nil or "text" formats.
+func OpenAIResponseFormat(rf *provider.ResponseFormat) map[string]interface{} {
+	if rf == nil || rf.Type == "" || rf.Type == "text" {
+		return nil
+	}
+	if rf.Schema == nil {
+		return map[string]interface{}{"type": "json_object"}
+	}
+
+	schema := rf.Schema
+	if s, ok := schema.(interface{ JSONSchema() map[string]interface{} }); ok {
+		schema = s.JSONSchema()
+	}
+	name := rf.Name
+	if name == "" {
+		name = "response"
+	}
+	jsonSchema := map[string]interface{}{
+		"name":   name,
+		"schema": schema,
+	}
+	if rf.Description != "" {
+		jsonSchema["description"] = rf.Description
+	}
+	return map[string]interface{}{
+		"type":        "json_schema",
+		"json_schema": jsonSchema,
+	}
+}
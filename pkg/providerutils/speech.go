@@ -0,0 +1,20 @@
+package providerutils
+
+// SpeechFormatMimeType maps an OpenAI-style speech response_format to its
+// MIME type. Unknown or empty formats map to "audio/mpeg", the default.
+func SpeechFormatMimeType(format string) string {
+	switch format {
+	case "wav":
+		return "audio/wav"
+	case "pcm":
+		return "audio/pcm"
+	case "opus":
+		return "audio/opus"
+	case "aac":
+		return "audio/aac"
+	case "flac":
+		return "audio/flac"
+	default:
+		return "audio/mpeg"
+	}
+}
@@ -54,6 +54,14 @@ type OpenAICompatStream struct {
 
 	// isActiveReasoning tracks whether we are inside a reasoning block.
 	isActiveReasoning bool
+
+	// pendingFinish is the finish chunk, held back until the provider's
+	// usage report (sent after the finish reason when stream_options
+	// include_usage is set) or the end of the stream.
+	pendingFinish *provider.StreamChunk
+
+	// usage is the most recent usage report
+	usage *types.Usage
 }
 
 // NewOpenAICompatStream creates a new OpenAICompatStream.
@@ -88,7 +96,8 @@ func (s *OpenAICompatStream) Err() error {
 // Text deltas are returned immediately. Tool call deltas are silently
 // accumulated; when finish_reason is received, all accumulated tool calls are
 // enqueued followed by a finish chunk, and the queue is drained one chunk per
-// call.
+// call. The finish chunk carries the provider-reported usage, which may
+// arrive in a later event.
 func (s *OpenAICompatStream) Next() (*provider.StreamChunk, error) {
 	// Drain any fully-assembled chunks before reading more SSE events.
 	if len(s.flushQueue) > 0 {
@@ -104,11 +113,17 @@ func (s *OpenAICompatStream) Next() (*provider.StreamChunk, error) {
 	event, err := s.parser.Next()
 	if err != nil {
 		s.err = err
+		if s.flushFinish() {
+			return s.Next()
+		}
 		return nil, err
 	}
 
 	if IsStreamDone(event) {
 		s.err = io.EOF
+		if s.flushFinish() {
+			return s.Next()
+		}
 		return nil, io.EOF
 	}
 
@@ -131,12 +146,22 @@ func (s *OpenAICompatStream) Next() (*provider.StreamChunk, error) {
 			} `json:"delta"`
 			FinishReason *string `json:"finish_reason"`
 		} `json:"choices"`
+		Usage *OpenAIUsage `json:"usage"`
 	}
 
 	if err := json.Unmarshal([]byte(event.Data), &chunkData); err != nil {
 		return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
 	}
 
+	// Usage report — attach it to the held finish chunk
+	if chunkData.Usage != nil {
+		usage := chunkData.Usage.ToUsage()
+		s.usage = &usage
+		if s.flushFinish() {
+			return s.Next()
+		}
+	}
+
 	// Pre-delta hook: enqueue extra chunks (e.g. top-level citations) before
 	// standard processing. Prepend so they drain before the finish chunk.
 	if s.OnBeforeDelta != nil {
@@ -235,10 +260,13 @@ func (s *OpenAICompatStream) Next() (*provider.StreamChunk, error) {
 					},
 				})
 			}
-			s.flushQueue = append(s.flushQueue, &provider.StreamChunk{
+			s.pendingFinish = &provider.StreamChunk{
 				Type:         provider.ChunkTypeFinish,
 				FinishReason: s.finishReasonMapper(*choice.FinishReason),
-			})
+			}
+			if s.usage != nil {
+				s.flushFinish()
+			}
 			return s.Next()
 		}
 	}
@@ -246,3 +274,49 @@ func (s *OpenAICompatStream) Next() (*provider.StreamChunk, error) {
 	// Empty or unrecognised event — skip and fetch the next one.
 	return s.Next()
 }
+
+// flushFinish queues the held finish chunk with the latest usage report.
+// It reports whether there was a finish chunk to queue.
+func (s *OpenAICompatStream) flushFinish() bool {
+	if s.pendingFinish == nil {
+		return false
+	}
+	s.pendingFinish.Usage = s.usage
+	s.flushQueue = append(s.flushQueue, s.pendingFinish)
+	s.pendingFinish = nil
+	return true
+}
+
+// OpenAIUsage is the usage object of OpenAI-compatible chat completions
+// responses and stream events
+type OpenAIUsage struct {
+	PromptTokens        int64 `json:"prompt_tokens"`
+	CompletionTokens    int64 `json:"completion_tokens"`
+	TotalTokens         int64 `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens *int64 `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens *int64 `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// ToUsage converts the report to types.Usage
+func (u OpenAIUsage) ToUsage() types.Usage {
+	input, output, total := u.PromptTokens, u.CompletionTokens, u.TotalTokens
+	if total == 0 {
+		total = input + output
+	}
+	usage := types.Usage{InputTokens: &input, OutputTokens: &output, TotalTokens: &total}
+	if d := u.PromptTokensDetails; d != nil && d.CachedTokens != nil && *d.CachedTokens > 0 {
+		cached := *d.CachedTokens
+		noCache := input - cached
+		usage.InputDetails = &types.InputTokenDetails{NoCacheTokens: &noCache, CacheReadTokens: &cached}
+	}
+	if d := u.CompletionTokensDetails; d != nil && d.ReasoningTokens != nil && *d.ReasoningTokens > 0 {
+		reasoning := *d.ReasoningTokens
+		text := output - reasoning
+		usage.OutputDetails = &types.OutputTokenDetails{TextTokens: &text, ReasoningTokens: &reasoning}
+	}
+	return usage
+}
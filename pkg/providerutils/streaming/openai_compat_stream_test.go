@@ -205,3 +205,40 @@ data: [DONE]
 		t.Errorf("chunk[2]: expected finish, got %v", chunks[2].Type)
 	}
 }
+
+func TestOpenAICompatStream_UsageAttachedToFinish(t *testing.T) {
+	sseData := `data: {"choices":[{"delta":{"content":"Hi"},"finish_reason":null}]}
+
+data: {"choices":[{"delta":{},"finish_reason":"stop"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}
+
+data: [DONE]
+
+`
+	stream := newTestStream(sseData)
+	defer stream.Close() //nolint:errcheck
+
+	var chunks []*provider.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 2 {
+		t.Fatalf("expected text and finish chunks, got %d", len(chunks))
+	}
+	finish := chunks[1]
+	if finish.Type != provider.ChunkTypeFinish {
+		t.Fatalf("last chunk type = %v, want finish", finish.Type)
+	}
+	if finish.Usage == nil || finish.Usage.GetInputTokens() != 7 || finish.Usage.GetOutputTokens() != 2 {
+		t.Errorf("finish usage = %+v, want 7 input and 2 output tokens", finish.Usage)
+	}
+}
@@ -143,6 +143,10 @@ func ParseSSEStream(r io.Reader) ([]*SSEEvent, error) {
 // SSEWriter writes Server-Sent Events to a writer
 type SSEWriter struct {
 	writer io.Writer
+
+	// flush and closer are set for HTTP writers; see NewHTTPSSEWriter
+	flush  func() error
+	closer io.Closer
 }
 
 // NewSSEWriter creates a new SSE writer
@@ -181,8 +185,13 @@ func (w *SSEWriter) WriteEvent(event SSEEvent) error {
 	buf.WriteString("\n")
 
 	// Write to underlying writer
-	_, err := w.writer.Write(buf.Bytes())
-	return err
+	if _, err := w.writer.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if w.flush != nil {
+		return w.flush()
+	}
+	return nil
 }
 
 // WriteData is a convenience method to write a data-only event
@@ -0,0 +1,121 @@
+package streaming
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		want   Compression
+	}{
+		{"", CompressionNone},
+		{"identity", CompressionNone},
+		{"gzip, deflate", CompressionGzip},
+		{"gzip, deflate, br", CompressionBrotli},
+		{"br;q=0.5, gzip", CompressionGzip},
+		{"br;q=0, gzip;q=0", CompressionNone},
+	}
+	for _, tt := range tests {
+		if got := NegotiateCompression(tt.header); got != tt.want {
+			t.Errorf("NegotiateCompression(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// TestHTTPSSEWriter_FlushesEachEvent checks that a compressed event can be
+// decoded by the client while the handler is still running
+func TestHTTPSSEWriter_FlushesEachEvent(t *testing.T) {
+	t.Parallel()
+
+	for _, enc := range []Compression{CompressionGzip, CompressionBrotli, CompressionNone} {
+		t.Run(string(enc)+"-encoding", func(t *testing.T) {
+			t.Parallel()
+
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sw, err := NewHTTPSSEWriter(w, r, SSEWriterOptions{})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_ = sw.WriteData("first")
+				<-release
+				_ = sw.WriteDone()
+				_ = sw.Close()
+			}))
+			defer srv.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if enc != CompressionNone {
+				req.Header.Set("Accept-Encoding", string(enc))
+			}
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get("Content-Encoding"); got != string(enc) {
+				t.Fatalf("expected Content-Encoding %q, got %q", enc, got)
+			}
+			var body io.Reader = resp.Body
+			switch enc {
+			case CompressionGzip:
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			case CompressionBrotli:
+				body = brotli.NewReader(resp.Body)
+			}
+
+			parser := NewSSEParser(body)
+			event, err := parser.Next()
+			if err != nil || event.Data != "first" {
+				t.Fatalf("expected first event before the stream ends, got %+v, %v", event, err)
+			}
+			close(release)
+
+			event, err = parser.Next()
+			if err != nil || !IsStreamDone(event) {
+				t.Fatalf("expected done event, got %+v, %v", event, err)
+			}
+			if _, err := parser.Next(); err != io.EOF {
+				t.Errorf("expected clean end of stream, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPSSEWriter_DisableCompression(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+
+	sw, err := NewHTTPSSEWriter(rec, req, SSEWriterOptions{DisableCompression: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sw.WriteData("hello")
+	_ = sw.Close()
+
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != "data: hello\n\n" {
+		t.Errorf("expected uncompressed output, got %q %q", rec.Header().Get("Content-Encoding"), rec.Body.String())
+	}
+	if !rec.Flushed {
+		t.Error("expected the response to be flushed after the event")
+	}
+}
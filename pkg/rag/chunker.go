@@ -0,0 +1,137 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chunk is a piece of a document small enough to embed
+type Chunk struct {
+	// ID is "<documentID>#<index>"
+	ID string
+
+	// DocumentID is the ID of the source document
+	DocumentID string
+
+	// Index is the position of the chunk within its document
+	Index int
+
+	// Content is the chunk text
+	Content string
+
+	// Metadata is the source document's metadata
+	Metadata map[string]any
+}
+
+// Chunker splits a document into chunks
+type Chunker interface {
+	Chunk(doc Document) []Chunk
+}
+
+// TextChunker splits text into chunks of at most Size characters, preferring
+// to break on paragraph, line, sentence and word boundaries (in that order).
+// Consecutive chunks share up to Overlap characters of context.
+type TextChunker struct {
+	// Size is the maximum chunk length in characters (default 1000)
+	Size int
+
+	// Overlap is the number of trailing characters of a chunk repeated at the
+	// start of the next one (default 0; must be less than Size)
+	Overlap int
+
+	// Separators are tried in order when a piece of text is too large.
+	// Defaults to paragraph, line, sentence and word boundaries.
+	Separators []string
+}
+
+// NewTextChunker creates a TextChunker
+func NewTextChunker(size, overlap int) *TextChunker {
+	return &TextChunker{Size: size, Overlap: overlap}
+}
+
+// Chunk implements Chunker
+func (c *TextChunker) Chunk(doc Document) []Chunk {
+	size := c.Size
+	if size <= 0 {
+		size = 1000
+	}
+	overlap := c.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+	separators := c.Separators
+	if len(separators) == 0 {
+		separators = []string{"\n\n", "\n", ". ", " "}
+	}
+
+	pieces := splitRecursive(doc.Content, size-overlap, separators)
+
+	var chunks []Chunk
+	var current strings.Builder
+	carried := 0 // bytes of overlap carried over from the previous chunk
+	emit := func() {
+		text := strings.TrimSpace(current.String())
+		if current.Len() == carried || text == "" {
+			return
+		}
+		chunks = append(chunks, Chunk{
+			ID:         fmt.Sprintf("%s#%d", doc.ID, len(chunks)),
+			DocumentID: doc.ID,
+			Index:      len(chunks),
+			Content:    text,
+			Metadata:   doc.Metadata,
+		})
+		tail := current.String()
+		current.Reset()
+		carried = 0
+		if overlap > 0 {
+			runes := []rune(tail)
+			if len(runes) > overlap {
+				runes = runes[len(runes)-overlap:]
+			}
+			current.WriteString(string(runes))
+			carried = current.Len()
+		}
+	}
+
+	for _, piece := range pieces {
+		if current.Len() > 0 && len([]rune(current.String()))+len([]rune(piece)) > size {
+			emit()
+		}
+		current.WriteString(piece)
+	}
+	emit()
+	return chunks
+}
+
+// splitRecursive splits text into pieces no longer than limit runes, using
+// the first separator that occurs and recursing with finer separators for
+// pieces that are still too long. Separators are kept on the preceding piece
+// so joining the pieces reproduces the original text.
+func splitRecursive(text string, limit int, separators []string) []string {
+	if len([]rune(text)) <= limit {
+		return []string{text}
+	}
+	for i, sep := range separators {
+		if !strings.Contains(text, sep) {
+			continue
+		}
+		var out []string
+		for _, part := range strings.SplitAfter(text, sep) {
+			if part == "" {
+				continue
+			}
+			out = append(out, splitRecursive(part, limit, separators[i+1:])...)
+		}
+		return out
+	}
+
+	// No separator left: hard split
+	runes := []rune(text)
+	var out []string
+	for len(runes) > limit {
+		out = append(out, string(runes[:limit]))
+		runes = runes[limit:]
+	}
+	return append(out, string(runes))
+}
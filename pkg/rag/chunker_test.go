@@ -0,0 +1,61 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTextChunker_RespectsSizeAndBoundaries(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20) + "\n\nSecond paragraph."
+	chunks := NewTextChunker(100, 0).Chunk(Document{ID: "doc", Content: text, Metadata: map[string]any{"source": "test"}})
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for i, c := range chunks {
+		if len([]rune(c.Content)) > 100 {
+			t.Errorf("chunk %d exceeds size: %d", i, len(c.Content))
+		}
+		if c.Index != i || c.ID != fmt.Sprintf("doc#%d", i) {
+			t.Errorf("chunk %d has ID %q index %d", i, c.ID, c.Index)
+		}
+		if c.Metadata["source"] != "test" {
+			t.Errorf("chunk %d lost document metadata", i)
+		}
+	}
+	if last := chunks[len(chunks)-1].Content; last != "Second paragraph." {
+		t.Errorf("expected paragraph boundary to be kept, last chunk = %q", last)
+	}
+}
+
+func TestTextChunker_Overlap(t *testing.T) {
+	t.Parallel()
+
+	words := strings.Repeat("alpha beta gamma delta ", 10)
+	chunks := NewTextChunker(40, 12).Chunk(Document{ID: "doc", Content: words})
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for i := 1; i < len(chunks); i++ {
+		prev := chunks[i-1].Content
+		tail := prev[len(prev)-5:]
+		if !strings.Contains(chunks[i].Content, tail) {
+			t.Errorf("chunk %d (%q) does not overlap previous chunk (%q)", i, chunks[i].Content, prev)
+		}
+	}
+}
+
+func TestTextChunker_ShortDocument(t *testing.T) {
+	t.Parallel()
+
+	chunks := NewTextChunker(100, 20).Chunk(Document{ID: "doc", Content: "short"})
+	if len(chunks) != 1 || chunks[0].Content != "short" {
+		t.Errorf("expected a single chunk, got %+v", chunks)
+	}
+	if chunks := NewTextChunker(100, 20).Chunk(Document{ID: "empty"}); len(chunks) != 0 {
+		t.Errorf("expected no chunks for empty document, got %d", len(chunks))
+	}
+}
@@ -0,0 +1,47 @@
+package rag
+
+import (
+	"context"
+	"io"
+)
+
+// Document is a unit of source content to be chunked, embedded and stored
+type Document struct {
+	// ID uniquely identifies the document. Chunk IDs are derived from it, so
+	// re-ingesting a document with the same ID overwrites its chunks.
+	ID string
+
+	// Content is the document text
+	Content string
+
+	// Metadata is copied onto every chunk of the document
+	Metadata map[string]any
+}
+
+// DocumentIterator yields documents one at a time so large corpora can be
+// ingested without loading them into memory. Next returns io.EOF when there
+// are no more documents.
+type DocumentIterator interface {
+	Next(ctx context.Context) (*Document, error)
+}
+
+// DocumentIteratorFunc adapts a function to the DocumentIterator interface
+type DocumentIteratorFunc func(ctx context.Context) (*Document, error)
+
+// Next calls f(ctx)
+func (f DocumentIteratorFunc) Next(ctx context.Context) (*Document, error) {
+	return f(ctx)
+}
+
+// SliceDocuments returns an iterator over an in-memory list of documents
+func SliceDocuments(docs ...Document) DocumentIterator {
+	i := 0
+	return DocumentIteratorFunc(func(ctx context.Context) (*Document, error) {
+		if i >= len(docs) {
+			return nil, io.EOF
+		}
+		doc := docs[i]
+		i++
+		return &doc, nil
+	})
+}
@@ -0,0 +1,393 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/internal/retry"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"golang.org/x/time/rate"
+)
+
+// IngestOptions configures Ingest
+type IngestOptions struct {
+	// Model embeds the chunks (required)
+	Model provider.EmbeddingModel
+
+	// Store receives the embedded chunks (required)
+	Store VectorStore
+
+	// Chunker splits documents. Defaults to a TextChunker with 1000-character
+	// chunks and 200 characters of overlap.
+	Chunker Chunker
+
+	// BatchSize is the number of chunks per embedding call. Defaults to the
+	// model's MaxEmbeddingsPerCall, or 100 if the model reports no limit.
+	BatchSize int
+
+	// Concurrency is the number of batches embedded in parallel (default 1)
+	Concurrency int
+
+	// MaxRetries is the number of retries for a failed embedding or upsert
+	// call (default 3; set to -1 to disable retries)
+	MaxRetries int
+
+	// RetryDelay is the initial backoff between retries (default 1s)
+	RetryDelay time.Duration
+
+	// RequestsPerMinute caps embedding calls per minute (0 = unlimited)
+	RequestsPerMinute int
+
+	// TokensPerMinute caps the estimated input tokens sent per minute
+	// (0 = unlimited). Tokens are estimated at four characters per token.
+	TokensPerMinute int
+
+	// ContinueOnError records failed batches in the result instead of
+	// aborting the whole ingestion
+	ContinueOnError bool
+
+	// OnProgress is called after each batch is stored. Calls are serialized.
+	OnProgress func(progress IngestProgress)
+
+	// Clock drives rate limiting, retry backoff and elapsed times, and RNG
+	// the backoff jitter (defaults: system clock, math/rand)
+	Clock clock.Clock
+	RNG   clock.RNG
+
+	// Headers and ProviderOptions are forwarded to the embedding model
+	Headers         map[string]string
+	ProviderOptions map[string]interface{}
+}
+
+// IngestProgress reports cumulative ingestion progress
+type IngestProgress struct {
+	// Documents read from the iterator so far
+	Documents int
+
+	// Chunks produced so far
+	Chunks int
+
+	// StoredChunks embedded and upserted so far
+	StoredChunks int
+
+	// FailedChunks skipped because their batch failed (ContinueOnError only)
+	FailedChunks int
+
+	// Usage accumulated across embedding calls
+	Usage types.EmbeddingUsage
+
+	// Elapsed time since ingestion started
+	Elapsed time.Duration
+}
+
+// IngestBatchError describes a batch that could not be embedded or stored
+type IngestBatchError struct {
+	// ChunkIDs of the chunks in the failed batch
+	ChunkIDs []string
+
+	// Err is the last error returned for the batch
+	Err error
+}
+
+// Error implements the error interface
+func (e *IngestBatchError) Error() string {
+	return fmt.Sprintf("failed to ingest batch of %d chunks: %v", len(e.ChunkIDs), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *IngestBatchError) Unwrap() error {
+	return e.Err
+}
+
+// IngestResult summarizes a completed ingestion
+type IngestResult struct {
+	IngestProgress
+
+	// Failures lists batches that failed when ContinueOnError is set
+	Failures []*IngestBatchError
+}
+
+// Ingest reads documents from docs, chunks them, embeds the chunks in
+// batches (with retries and rate limiting) and upserts them into the store.
+// On error the partial result so far is returned together with the error.
+//
+// Example:
+//
+//	result, err := rag.Ingest(ctx, rag.SliceDocuments(docs...), rag.IngestOptions{
+//		Model:             openaiProvider.EmbeddingModel("text-embedding-3-small"),
+//		Store:             store,
+//		Concurrency:       4,
+//		RequestsPerMinute: 3000,
+//		OnProgress: func(p rag.IngestProgress) {
+//			log.Printf("%d/%d chunks stored", p.StoredChunks, p.Chunks)
+//		},
+//	})
+func Ingest(ctx context.Context, docs DocumentIterator, opts IngestOptions) (*IngestResult, error) {
+	if opts.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	if opts.Store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+	if docs == nil {
+		return nil, fmt.Errorf("document iterator is required")
+	}
+
+	in := newIngester(opts)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []Chunk)
+	var wg sync.WaitGroup
+	for i := 0; i < in.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := in.processBatch(ctx, batch); err != nil {
+					in.fail(err)
+					cancel()
+				}
+			}
+		}()
+	}
+
+	readErr := in.readDocuments(ctx, docs, batches)
+	close(batches)
+	wg.Wait()
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.result.Elapsed = in.clock.Now().Sub(in.start)
+	if in.err != nil {
+		return &in.result, in.err
+	}
+	if readErr != nil {
+		return &in.result, readErr
+	}
+	return &in.result, nil
+}
+
+// ingester holds the shared state of one Ingest call
+type ingester struct {
+	opts        IngestOptions
+	chunker     Chunker
+	batchSize   int
+	concurrency int
+	retryCfg    retry.Config
+	requests    *rate.Limiter
+	tokens      *rate.Limiter
+	clock       clock.Clock
+	start       time.Time
+
+	mu     sync.Mutex
+	result IngestResult
+	err    error
+}
+
+func newIngester(opts IngestOptions) *ingester {
+	c := clock.Default(opts.Clock)
+	in := &ingester{opts: opts, chunker: opts.Chunker, clock: c, start: c.Now()}
+	if in.chunker == nil {
+		in.chunker = NewTextChunker(1000, 200)
+	}
+
+	in.batchSize = opts.BatchSize
+	if in.batchSize <= 0 {
+		in.batchSize = opts.Model.MaxEmbeddingsPerCall()
+	}
+	if in.batchSize <= 0 {
+		in.batchSize = 100
+	}
+
+	in.concurrency = opts.Concurrency
+	if in.concurrency <= 0 || !opts.Model.SupportsParallelCalls() {
+		in.concurrency = 1
+	}
+
+	in.retryCfg = retry.DefaultConfig()
+	in.retryCfg.ShouldRetry = isRetryableIngestError
+	in.retryCfg.Clock, in.retryCfg.RNG = c, opts.RNG
+	switch {
+	case opts.MaxRetries < 0:
+		// retry.Do treats 0 as "use defaults", so disable retries explicitly
+		in.retryCfg.ShouldRetry = func(error) bool { return false }
+	case opts.MaxRetries > 0:
+		in.retryCfg.MaxRetries = opts.MaxRetries
+	}
+	if opts.RetryDelay > 0 {
+		in.retryCfg.InitialDelay = opts.RetryDelay
+	}
+
+	if opts.RequestsPerMinute > 0 {
+		in.requests = rate.NewLimiter(rate.Limit(float64(opts.RequestsPerMinute)/60), 1)
+	}
+	if opts.TokensPerMinute > 0 {
+		in.tokens = rate.NewLimiter(rate.Limit(float64(opts.TokensPerMinute)/60), opts.TokensPerMinute)
+	}
+	return in
+}
+
+// readDocuments chunks documents and groups chunks into batches
+func (in *ingester) readDocuments(ctx context.Context, docs DocumentIterator, batches chan<- []Chunk) error {
+	batch := make([]Chunk, 0, in.batchSize)
+	send := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		select {
+		case batches <- batch:
+			batch = make([]Chunk, 0, in.batchSize)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		doc, err := docs.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read document: %w", err)
+		}
+		chunks := in.chunker.Chunk(*doc)
+
+		in.mu.Lock()
+		in.result.Documents++
+		in.result.Chunks += len(chunks)
+		in.mu.Unlock()
+
+		for _, chunk := range chunks {
+			batch = append(batch, chunk)
+			if len(batch) == in.batchSize && !send() {
+				return ctx.Err()
+			}
+		}
+	}
+	if !send() {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// processBatch embeds and stores one batch, retrying transient failures
+func (in *ingester) processBatch(ctx context.Context, batch []Chunk) error {
+	inputs := make([]string, len(batch))
+	estimatedTokens := 0
+	for i, c := range batch {
+		inputs[i] = c.Content
+		estimatedTokens += len(c.Content)/4 + 1
+	}
+
+	var embedded *types.EmbeddingsResult
+	err := retry.Do(ctx, in.retryCfg, func(ctx context.Context) error {
+		if err := in.wait(ctx, estimatedTokens); err != nil {
+			return err
+		}
+		res, err := in.opts.Model.DoEmbedMany(ctx, inputs, &provider.EmbedModelOptions{
+			Headers:         in.opts.Headers,
+			ProviderOptions: in.opts.ProviderOptions,
+		})
+		if err != nil {
+			return err
+		}
+		if len(res.Embeddings) != len(inputs) {
+			return fmt.Errorf("model returned %d embeddings for %d inputs", len(res.Embeddings), len(inputs))
+		}
+		embedded = res
+		return nil
+	})
+	if err == nil {
+		records := make([]Record, len(batch))
+		for i, c := range batch {
+			records[i] = Record{ID: c.ID, Vector: embedded.Embeddings[i], Content: c.Content, Metadata: c.Metadata}
+		}
+		err = retry.Do(ctx, in.retryCfg, func(ctx context.Context) error {
+			return in.opts.Store.Upsert(ctx, records)
+		})
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if embedded != nil {
+		in.result.Usage.InputTokens += embedded.Usage.InputTokens
+		in.result.Usage.TotalTokens += embedded.Usage.TotalTokens
+		in.result.Usage.CachedInputs += embedded.Usage.CachedInputs
+	}
+	if err != nil {
+		batchErr := &IngestBatchError{ChunkIDs: make([]string, len(batch)), Err: err}
+		for i, c := range batch {
+			batchErr.ChunkIDs[i] = c.ID
+		}
+		if !in.opts.ContinueOnError || ctx.Err() != nil {
+			return batchErr
+		}
+		in.result.FailedChunks += len(batch)
+		in.result.Failures = append(in.result.Failures, batchErr)
+	} else {
+		in.result.StoredChunks += len(batch)
+	}
+	if in.opts.OnProgress != nil {
+		progress := in.result.IngestProgress
+		progress.Elapsed = in.clock.Now().Sub(in.start)
+		in.opts.OnProgress(progress)
+	}
+	return nil
+}
+
+// wait blocks until the rate limiters admit a request of the given size
+func (in *ingester) wait(ctx context.Context, tokens int) error {
+	if in.requests != nil {
+		if err := clock.WaitN(ctx, in.clock, in.requests, 1); err != nil {
+			return err
+		}
+	}
+	if in.tokens != nil {
+		// A single batch larger than the per-minute budget can never be
+		// admitted; let it through at the full burst instead of failing
+		if tokens > in.tokens.Burst() {
+			tokens = in.tokens.Burst()
+		}
+		if err := clock.WaitN(ctx, in.clock, in.tokens, tokens); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fail records the first fatal error
+func (in *ingester) fail(err error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.err == nil {
+		in.err = err
+	}
+}
+
+// isRetryableIngestError retries rate limits, server errors and network
+// failures, but not validation or client errors
+func isRetryableIngestError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if providererrors.IsValidationError(err) {
+		return false
+	}
+	if providererrors.IsRateLimitError(err) {
+		return true
+	}
+	var provErr *providererrors.ProviderError
+	if errors.As(err, &provErr) {
+		return provErr.StatusCode == 0 || provErr.StatusCode == 408 || provErr.StatusCode == 429 || provErr.StatusCode >= 500
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// recordingStore is a VectorStore that keeps upserted records in memory
+type recordingStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	calls   int
+}
+
+func (s *recordingStore) Upsert(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records == nil {
+		s.records = make(map[string]Record)
+	}
+	s.calls++
+	for _, r := range records {
+		s.records[r.ID] = r
+	}
+	return nil
+}
+
+func (s *recordingStore) Query(ctx context.Context, vector []float64, opts QueryOptions) ([]QueryResult, error) {
+	return nil, nil
+}
+
+func (s *recordingStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.records, id)
+	}
+	return nil
+}
+
+func testDocuments(n int) []Document {
+	docs := make([]Document, n)
+	for i := range docs {
+		docs[i] = Document{ID: fmt.Sprintf("doc-%d", i), Content: strings.Repeat("word ", 50)}
+	}
+	return docs
+}
+
+func TestIngest_BatchesAndStores(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockEmbeddingModel{MaxEmbeddings: 4}
+	store := &recordingStore{}
+	var progressCalls int
+
+	result, err := Ingest(context.Background(), SliceDocuments(testDocuments(5)...), IngestOptions{
+		Model:      model,
+		Store:      store,
+		Chunker:    NewTextChunker(100, 0),
+		OnProgress: func(p IngestProgress) { progressCalls++ },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Each 250-character document yields 3 chunks
+	if result.Documents != 5 || result.Chunks != 15 || result.StoredChunks != 15 {
+		t.Errorf("unexpected result: %+v", result.IngestProgress)
+	}
+	if len(model.EmbedManyCalls) != 4 {
+		t.Errorf("expected 4 batches of at most 4 chunks, got %d", len(model.EmbedManyCalls))
+	}
+	if len(store.records) != 15 {
+		t.Errorf("expected 15 stored records, got %d", len(store.records))
+	}
+	if _, ok := store.records["doc-0#2"]; !ok {
+		t.Error("expected chunk IDs derived from document IDs")
+	}
+	if progressCalls != 4 {
+		t.Errorf("expected progress after each batch, got %d calls", progressCalls)
+	}
+	if result.Usage.TotalTokens != 75 {
+		t.Errorf("TotalTokens = %d, want 75", result.Usage.TotalTokens)
+	}
+}
+
+func TestIngest_RetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts int
+	model := &testutil.MockEmbeddingModel{
+		DoEmbedManyFunc: func(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, providererrors.NewProviderError("mock", 503, "", "unavailable", nil)
+			}
+			return &types.EmbeddingsResult{Embeddings: make([][]float64, len(inputs))}, nil
+		},
+	}
+
+	result, err := Ingest(context.Background(), SliceDocuments(testDocuments(1)...), IngestOptions{
+		Model:      model,
+		Store:      &recordingStore{},
+		RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 || result.StoredChunks != 1 {
+		t.Errorf("expected success on third attempt, got %d attempts and %+v", attempts, result.IngestProgress)
+	}
+}
+
+func TestIngest_ContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockEmbeddingModel{
+		MaxEmbeddings: 1,
+		DoEmbedManyFunc: func(ctx context.Context, inputs []string, opts *provider.EmbedModelOptions) (*types.EmbeddingsResult, error) {
+			if strings.Contains(inputs[0], "bad") {
+				return nil, providererrors.NewProviderError("mock", 400, "", "invalid input", nil)
+			}
+			return &types.EmbeddingsResult{Embeddings: make([][]float64, len(inputs))}, nil
+		},
+	}
+	docs := SliceDocuments(
+		Document{ID: "good", Content: "fine text"},
+		Document{ID: "bad", Content: "bad text"},
+	)
+
+	result, err := Ingest(context.Background(), docs, IngestOptions{Model: model, Store: &recordingStore{}, ContinueOnError: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.StoredChunks != 1 || result.FailedChunks != 1 || len(result.Failures) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Failures[0].ChunkIDs[0] != "bad#0" {
+		t.Errorf("unexpected failed chunk: %v", result.Failures[0].ChunkIDs)
+	}
+
+	// Without ContinueOnError the first failure aborts ingestion
+	_, err = Ingest(context.Background(), SliceDocuments(Document{ID: "bad", Content: "bad"}), IngestOptions{Model: model, Store: &recordingStore{}})
+	var batchErr *IngestBatchError
+	if !errors.As(err, &batchErr) {
+		t.Errorf("expected IngestBatchError, got %v", err)
+	}
+}
+
+func TestIngest_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Ingest(context.Background(), SliceDocuments(), IngestOptions{Store: &recordingStore{}}); err == nil {
+		t.Error("expected error without model")
+	}
+	if _, err := Ingest(context.Background(), SliceDocuments(), IngestOptions{Model: &testutil.MockEmbeddingModel{}}); err == nil {
+		t.Error("expected error without store")
+	}
+}
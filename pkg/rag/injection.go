@@ -0,0 +1,55 @@
+package rag
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+)
+
+// InjectionGuardRetriever scans retrieved content for prompt-injection
+// patterns and annotates or quarantines flagged results before they reach
+// the model
+type InjectionGuardRetriever struct {
+	// Base retriever whose results are scanned
+	Base Retriever
+
+	// Detector scans and rewrites flagged content
+	Detector *ai.InjectionDetector
+
+	// DropFlagged removes flagged results instead of rewriting them
+	DropFlagged bool
+}
+
+// NewInjectionGuardRetriever wraps base with detector
+func NewInjectionGuardRetriever(base Retriever, detector *ai.InjectionDetector) *InjectionGuardRetriever {
+	return &InjectionGuardRetriever{Base: base, Detector: detector}
+}
+
+// Retrieve implements Retriever. Flagged results get their findings under
+// the "injection" metadata key; the stored records are not modified.
+func (r *InjectionGuardRetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	results, err := r.Base.Retrieve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	out := results[:0:0]
+	for _, res := range results {
+		content, findings := r.Detector.Process(ctx, "document "+res.ID, res.Content)
+		if len(findings) == 0 {
+			out = append(out, res)
+			continue
+		}
+		if r.DropFlagged {
+			continue
+		}
+		metadata := make(map[string]any, len(res.Metadata)+1)
+		for k, v := range res.Metadata {
+			metadata[k] = v
+		}
+		metadata["injection"] = findings
+		res.Content = content
+		res.Metadata = metadata
+		out = append(out, res)
+	}
+	return out, nil
+}
@@ -0,0 +1,184 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// MemoryVectorStore is an in-process VectorStore that ranks records by
+// cosine similarity with a linear scan. It suits tests, examples and
+// corpora of up to tens of thousands of chunks.
+//
+// Example:
+//
+//	store := rag.NewMemoryVectorStore()
+//	_, err := rag.Ingest(ctx, rag.SliceDocuments(docs...), rag.IngestOptions{Model: embedder, Store: store})
+//	retriever := rag.NewVectorStoreRetriever(embedder, store, 5)
+type MemoryVectorStore struct {
+	mu      sync.RWMutex
+	records map[string]memoryRecord
+}
+
+// memoryRecord is a stored record with the norm of its vector
+type memoryRecord struct {
+	Record
+	norm float64
+}
+
+// NewMemoryVectorStore creates an empty MemoryVectorStore
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{records: make(map[string]memoryRecord)}
+}
+
+// Upsert implements VectorStore. Records are stored as copies, and nothing
+// is stored when any record is invalid.
+func (s *MemoryVectorStore) Upsert(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dims := s.dimensions()
+	for _, r := range records {
+		if r.ID == "" {
+			return fmt.Errorf("record ID is required")
+		}
+		if dims == 0 {
+			dims = len(r.Vector)
+		}
+		if len(r.Vector) != dims {
+			return fmt.Errorf("record %s has %d dimensions, store has %d", r.ID, len(r.Vector), dims)
+		}
+	}
+	for _, r := range records {
+		r.Vector = append([]float64(nil), r.Vector...)
+		if r.Metadata != nil {
+			metadata := make(map[string]any, len(r.Metadata))
+			for k, v := range r.Metadata {
+				metadata[k] = v
+			}
+			r.Metadata = metadata
+		}
+		s.records[r.ID] = memoryRecord{Record: r, norm: norm(r.Vector)}
+	}
+	return nil
+}
+
+// Query implements VectorStore. Records with a zero vector never match.
+func (s *MemoryVectorStore) Query(ctx context.Context, vector []float64, opts QueryOptions) ([]QueryResult, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = 4
+	}
+	queryNorm := norm(vector)
+	if queryNorm == 0 {
+		return nil, fmt.Errorf("query vector is zero")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if dims := s.dimensions(); dims != 0 && dims != len(vector) {
+		return nil, fmt.Errorf("query has %d dimensions, store has %d", len(vector), dims)
+	}
+	var results []QueryResult
+	for _, r := range s.records {
+		if r.norm == 0 || !matchesFilter(r.Metadata, opts.Filter) {
+			continue
+		}
+		var dot float64
+		for i, v := range r.Vector {
+			dot += v * vector[i]
+		}
+		score := dot / (r.norm * queryNorm)
+		if score < opts.MinScore {
+			continue
+		}
+		results = append(results, QueryResult{Record: r.Record, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// Delete implements VectorStore
+func (s *MemoryVectorStore) Delete(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.records, id)
+	}
+	return nil
+}
+
+// Len returns the number of stored records
+func (s *MemoryVectorStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+// dimensions returns the vector length of the stored records, or 0 when
+// the store is empty; the caller holds s.mu
+func (s *MemoryVectorStore) dimensions() int {
+	for _, r := range s.records {
+		return len(r.Vector)
+	}
+	return 0
+}
+
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// matchesFilter reports whether metadata contains every key/value pair of
+// filter. Numbers match by value, so an int filter matches a float64 read
+// back from JSON.
+func matchesFilter(metadata, filter map[string]any) bool {
+	for k, want := range filter {
+		got, ok := metadata[k]
+		if !ok {
+			return false
+		}
+		if a, ok := toFloat(got); ok {
+			if b, ok := toFloat(want); ok {
+				if a != b {
+					return false
+				}
+				continue
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,79 @@
+package rag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryVectorStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryVectorStore()
+	err := store.Upsert(ctx, []Record{
+		{ID: "go", Vector: []float64{1, 0, 0}, Content: "Go", Metadata: map[string]any{"lang": "en", "year": 2009}},
+		{ID: "gopher", Vector: []float64{0.9, 0.1, 0}, Content: "Gopher", Metadata: map[string]any{"lang": "en", "year": 2012}},
+		{ID: "rust", Vector: []float64{0, 1, 0}, Content: "Rust", Metadata: map[string]any{"lang": "en"}},
+		{ID: "zero", Vector: []float64{0, 0, 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.Query(ctx, []float64{1, 0, 0}, QueryOptions{TopK: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "go" || results[1].ID != "gopher" || results[0].Score < 0.999 {
+		t.Fatalf("results = %+v", results)
+	}
+
+	// Filters match numbers by value, e.g. after a JSON round trip
+	results, err = store.Query(ctx, []float64{1, 0, 0}, QueryOptions{Filter: map[string]any{"year": 2012.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "gopher" {
+		t.Errorf("filtered results = %+v", results)
+	}
+
+	results, err = store.Query(ctx, []float64{1, 0, 0}, QueryOptions{TopK: 10, MinScore: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("MinScore results = %+v", results)
+	}
+
+	// Upserting an existing ID replaces it
+	if err := store.Upsert(ctx, []Record{{ID: "rust", Vector: []float64{1, 0, 0}, Content: "Rust 2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, []string{"go", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	results, err = store.Query(ctx, []float64{1, 0, 0}, QueryOptions{TopK: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 3 || results[0].Content != "Rust 2" {
+		t.Errorf("len %d, results %+v", store.Len(), results)
+	}
+}
+
+func TestMemoryVectorStore_Dimensions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryVectorStore()
+	err := store.Upsert(ctx, []Record{{ID: "a", Vector: []float64{1, 0}}, {ID: "b", Vector: []float64{1, 0, 0}}})
+	if err == nil || store.Len() != 0 {
+		t.Fatalf("expected a dimension error and nothing stored, got %v with %d records", err, store.Len())
+	}
+	if err := store.Upsert(ctx, []Record{{ID: "a", Vector: []float64{1, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Query(ctx, []float64{1, 0, 0}, QueryOptions{}); err == nil {
+		t.Error("expected an error for a query with the wrong dimensions")
+	}
+}
@@ -0,0 +1,393 @@
+// Package pgvector implements rag.VectorStore on PostgreSQL with the
+// pgvector extension, through database/sql. Open db with the Postgres
+// driver of your choice, e.g. github.com/jackc/pgx/v5/stdlib:
+//
+//	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+//	store, err := pgvector.New(db, pgvector.Options{Dimensions: 1536, Index: pgvector.IndexHNSW})
+//	err = store.Migrate(ctx)
+//	_, err = rag.Ingest(ctx, docs, rag.IngestOptions{Model: embedder, Store: store})
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/rag"
+)
+
+// Distance is the pgvector distance used for similarity search
+type Distance string
+
+const (
+	// DistanceCosine ranks by cosine distance; Score is the cosine
+	// similarity
+	DistanceCosine Distance = "cosine"
+
+	// DistanceL2 ranks by Euclidean distance; Score is the negated distance
+	DistanceL2 Distance = "l2"
+
+	// DistanceInnerProduct ranks by inner product, which equals cosine
+	// similarity for normalized embeddings; Score is the inner product
+	DistanceInnerProduct Distance = "inner-product"
+)
+
+// IndexType is the approximate nearest neighbor index Migrate creates
+type IndexType string
+
+const (
+	// IndexNone creates no vector index; queries scan the table exactly
+	IndexNone IndexType = ""
+
+	// IndexHNSW creates an HNSW index: better recall and speed, slower
+	// builds and more memory
+	IndexHNSW IndexType = "hnsw"
+
+	// IndexIVFFlat creates an IVFFlat index: faster builds, lower recall.
+	// Create it after loading data, since its lists are trained on the
+	// rows present.
+	IndexIVFFlat IndexType = "ivfflat"
+)
+
+// Options configures a Store
+type Options struct {
+	// Table holds the records (default: "rag_records")
+	Table string
+
+	// Dimensions of the embeddings (required)
+	Dimensions int
+
+	// Distance used for search and the index (default: DistanceCosine)
+	Distance Distance
+
+	// Index is the index Migrate creates
+	Index IndexType
+
+	// HNSWM and HNSWEfConstruction tune an HNSW index build (pgvector
+	// defaults: 16 and 64)
+	HNSWM              int
+	HNSWEfConstruction int
+
+	// IVFLists is the number of IVFFlat lists (default: 100); rows/1000 is
+	// a good start up to a million rows
+	IVFLists int
+
+	// EfSearch sets hnsw.ef_search for each query; higher values improve
+	// recall at the cost of speed (pgvector default: 40)
+	EfSearch int
+
+	// Probes sets ivfflat.probes for each query (pgvector default: 1)
+	Probes int
+
+	// BatchSize is the number of rows per INSERT statement (default: 500)
+	BatchSize int
+}
+
+// Store is a rag.VectorStore backed by a pgvector table
+type Store struct {
+	db   *sql.DB
+	opts Options
+}
+
+var _ rag.VectorStore = (*Store)(nil)
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New returns a Store for the table in opts. Call Migrate to create it.
+func New(db *sql.DB, opts Options) (*Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is required")
+	}
+	if opts.Table == "" {
+		opts.Table = "rag_records"
+	}
+	if !sqlIdentifier.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+	if opts.Dimensions <= 0 {
+		return nil, fmt.Errorf("dimensions are required")
+	}
+	if opts.Distance == "" {
+		opts.Distance = DistanceCosine
+	}
+	if _, ok := distanceOperators[opts.Distance]; !ok {
+		return nil, fmt.Errorf("unknown distance %q", opts.Distance)
+	}
+	switch opts.Index {
+	case IndexNone, IndexHNSW, IndexIVFFlat:
+	default:
+		return nil, fmt.Errorf("unknown index type %q", opts.Index)
+	}
+	if opts.IVFLists <= 0 {
+		opts.IVFLists = 100
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	return &Store{db: db, opts: opts}, nil
+}
+
+// distanceOperators maps a distance to its pgvector operator and operator
+// class
+var distanceOperators = map[Distance]struct{ op, opclass string }{
+	DistanceCosine:       {"<=>", "vector_cosine_ops"},
+	DistanceL2:           {"<->", "vector_l2_ops"},
+	DistanceInnerProduct: {"<#>", "vector_ip_ops"},
+}
+
+// Migrate creates the vector extension, the table and the configured index
+// if they do not exist
+func (s *Store) Migrate(ctx context.Context) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, content TEXT NOT NULL, metadata JSONB NOT NULL DEFAULT '{}', embedding vector(%d) NOT NULL)",
+			s.opts.Table, s.opts.Dimensions),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_metadata_idx ON %s USING gin (metadata)", s.opts.Table, s.opts.Table),
+	}
+	opclass := distanceOperators[s.opts.Distance].opclass
+	switch s.opts.Index {
+	case IndexHNSW:
+		var with []string
+		if s.opts.HNSWM > 0 {
+			with = append(with, "m = "+strconv.Itoa(s.opts.HNSWM))
+		}
+		if s.opts.HNSWEfConstruction > 0 {
+			with = append(with, "ef_construction = "+strconv.Itoa(s.opts.HNSWEfConstruction))
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding %s)", s.opts.Table, s.opts.Table, opclass)
+		if len(with) > 0 {
+			stmt += " WITH (" + strings.Join(with, ", ") + ")"
+		}
+		statements = append(statements, stmt)
+	case IndexIVFFlat:
+		statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING ivfflat (embedding %s) WITH (lists = %d)",
+			s.opts.Table, s.opts.Table, opclass, s.opts.IVFLists))
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("pgvector migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// Upsert implements rag.VectorStore. Records are written in batches of
+// BatchSize rows within one transaction, so either all are stored or none.
+func (s *Store) Upsert(ctx context.Context, records []rag.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for _, r := range records {
+		if r.ID == "" {
+			return fmt.Errorf("record ID is required")
+		}
+		if len(r.Vector) != s.opts.Dimensions {
+			return fmt.Errorf("record %s has %d dimensions, store has %d", r.ID, len(r.Vector), s.opts.Dimensions)
+		}
+	}
+
+	// Postgres rejects a statement that updates the same row twice, so the
+	// last record with an ID wins, as if upserted one at a time
+	last := make(map[string]int, len(records))
+	for i, r := range records {
+		last[r.ID] = i
+	}
+	if len(last) < len(records) {
+		unique := make([]rag.Record, 0, len(last))
+		for i, r := range records {
+			if last[r.ID] == i {
+				unique = append(unique, r)
+			}
+		}
+		records = unique
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for start := 0; start < len(records); start += s.opts.BatchSize {
+		batch := records[start:min(start+s.opts.BatchSize, len(records))]
+		rows := make([]string, len(batch))
+		args := make([]interface{}, 0, 4*len(batch))
+		for i, r := range batch {
+			metadata := r.Metadata
+			if metadata == nil {
+				metadata = map[string]any{}
+			}
+			data, err := json.Marshal(metadata)
+			if err != nil {
+				return fmt.Errorf("record %s metadata: %w", r.ID, err)
+			}
+			n := len(args)
+			rows[i] = fmt.Sprintf("($%d, $%d, $%d::jsonb, $%d::vector)", n+1, n+2, n+3, n+4)
+			args = append(args, r.ID, r.Content, string(data), vectorLiteral(r.Vector))
+		}
+		stmt := "INSERT INTO " + s.opts.Table + " (id, content, metadata, embedding) VALUES " + strings.Join(rows, ", ") +
+			" ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding"
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("pgvector upsert: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Query implements rag.VectorStore. Filter values are matched with JSONB
+// containment, so nested objects and arrays match by subset.
+func (s *Store) Query(ctx context.Context, vector []float64, opts rag.QueryOptions) ([]rag.QueryResult, error) {
+	var results []rag.QueryResult
+	err := s.QueryEach(ctx, vector, opts, func(r rag.QueryResult) error {
+		results = append(results, r)
+		return nil
+	})
+	return results, err
+}
+
+// QueryEach runs a search like Query but passes each result to fn as it is
+// read, without holding the result set in memory, for large TopK values.
+// An error from fn stops the search and is returned.
+func (s *Store) QueryEach(ctx context.Context, vector []float64, opts rag.QueryOptions, fn func(rag.QueryResult) error) error {
+	if len(vector) != s.opts.Dimensions {
+		return fmt.Errorf("query has %d dimensions, store has %d", len(vector), s.opts.Dimensions)
+	}
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = 4
+	}
+
+	op := distanceOperators[s.opts.Distance].op
+	distance := "embedding " + op + " $1::vector"
+	var score string
+	switch s.opts.Distance {
+	case DistanceCosine:
+		score = "1 - (" + distance + ")"
+	default:
+		// <-> is a distance and <#> the negated inner product
+		score = "-(" + distance + ")"
+	}
+	args := []interface{}{vectorLiteral(vector)}
+	var where []string
+	if len(opts.Filter) > 0 {
+		data, err := json.Marshal(opts.Filter)
+		if err != nil {
+			return fmt.Errorf("query filter: %w", err)
+		}
+		args = append(args, string(data))
+		where = append(where, fmt.Sprintf("metadata @> $%d::jsonb", len(args)))
+	}
+	if opts.MinScore != 0 {
+		args = append(args, opts.MinScore)
+		where = append(where, fmt.Sprintf("%s >= $%d", score, len(args)))
+	}
+	query := "SELECT id, content, metadata::text, embedding::text, " + score + " AS score FROM " + s.opts.Table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, topK)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", distance, len(args))
+
+	// Index settings are per transaction, so hinted queries run in one
+	var q interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	} = s.db
+	if s.opts.EfSearch > 0 || s.opts.Probes > 0 {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if s.opts.EfSearch > 0 {
+			if _, err := tx.ExecContext(ctx, "SET LOCAL hnsw.ef_search = "+strconv.Itoa(s.opts.EfSearch)); err != nil {
+				return fmt.Errorf("pgvector ef_search: %w", err)
+			}
+		}
+		if s.opts.Probes > 0 {
+			if _, err := tx.ExecContext(ctx, "SET LOCAL ivfflat.probes = "+strconv.Itoa(s.opts.Probes)); err != nil {
+				return fmt.Errorf("pgvector probes: %w", err)
+			}
+		}
+		q = tx
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("pgvector query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r rag.QueryResult
+		var metadata, embedding string
+		if err := rows.Scan(&r.ID, &r.Content, &metadata, &embedding, &r.Score); err != nil {
+			return fmt.Errorf("pgvector query: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metadata), &r.Metadata); err != nil {
+			return fmt.Errorf("record %s metadata: %w", r.ID, err)
+		}
+		if r.Vector, err = parseVector(embedding); err != nil {
+			return fmt.Errorf("record %s embedding: %w", r.ID, err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Delete implements rag.VectorStore
+func (s *Store) Delete(ctx context.Context, ids []string) error {
+	for start := 0; start < len(ids); start += s.opts.BatchSize {
+		batch := ids[start:min(start+s.opts.BatchSize, len(ids))]
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			placeholders[i] = "$" + strconv.Itoa(i+1)
+			args[i] = id
+		}
+		stmt := "DELETE FROM " + s.opts.Table + " WHERE id IN (" + strings.Join(placeholders, ", ") + ")"
+		if _, err := s.db.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("pgvector delete: %w", err)
+		}
+	}
+	return nil
+}
+
+// vectorLiteral formats v in pgvector's text format, e.g. "[1,0.5,-2]"
+func vectorLiteral(v []float64) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(x, 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// parseVector parses pgvector's text format
+func parseVector(s string) ([]float64, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("invalid vector %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	v := make([]float64, len(parts))
+	for i, part := range parts {
+		x, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector %q: %w", s, err)
+		}
+		v[i] = x
+	}
+	return v, nil
+}
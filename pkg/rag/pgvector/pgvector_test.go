@@ -0,0 +1,245 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/rag"
+)
+
+// fakeDriver records statements and answers queries with scripted rows
+type fakeDriver struct{}
+
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	rows       [][]driver.Value
+	committed  int
+	rolledBack int
+}
+
+var fakeDBs sync.Map
+
+func init() {
+	sql.Register("pgvectortest", fakeDriver{})
+}
+
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	fakeDBs.Store(t.Name(), fake)
+	db, err := sql.Open("pgvectortest", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fake, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, errors.New("unknown fake database")
+	}
+	return &fakeConn{db: fake.(*fakeDB)}, nil
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{db: c.db}, nil }
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.committed++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rolledBack++
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) record(args []driver.Value) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.statements = append(s.db.statements, s.query)
+	s.db.args = append(s.db.args, args)
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.record(args)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.record(args)
+	return &fakeRows{rows: s.db.rows}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "content", "metadata", "embedding", "score"}
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestStore_Migrate(t *testing.T) {
+	db, fake := openFake(t)
+	store, err := New(db, Options{Table: "docs", Dimensions: 3, Index: IndexHNSW, HNSWM: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	all := strings.Join(fake.statements, "\n")
+	for _, want := range []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		"embedding vector(3) NOT NULL",
+		"USING gin (metadata)",
+		"USING hnsw (embedding vector_cosine_ops) WITH (m = 24)",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("migration missing %q:\n%s", want, all)
+		}
+	}
+
+	if _, err := New(db, Options{Table: "docs; DROP TABLE x", Dimensions: 3}); err == nil {
+		t.Error("expected an invalid table name error")
+	}
+}
+
+func TestStore_UpsertBatches(t *testing.T) {
+	db, fake := openFake(t)
+	store, err := New(db, Options{Dimensions: 2, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []rag.Record{
+		{ID: "a", Vector: []float64{1, 0.5}, Content: "A", Metadata: map[string]any{"lang": "en"}},
+		{ID: "b", Vector: []float64{0, 1}, Content: "B"},
+		{ID: "c", Vector: []float64{-1, 0}, Content: "C"},
+	}
+	if err := store.Upsert(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.statements) != 2 || fake.committed != 1 {
+		t.Fatalf("%d statements, %d commits", len(fake.statements), fake.committed)
+	}
+	if !strings.Contains(fake.statements[0], "($5, $6, $7::jsonb, $8::vector) ON CONFLICT (id) DO UPDATE") {
+		t.Errorf("statement = %s", fake.statements[0])
+	}
+	if args := fake.args[0]; args[2] != `{"lang":"en"}` || args[3] != "[1,0.5]" || fake.args[0][6] != "{}" {
+		t.Errorf("args = %v", args)
+	}
+
+	// Repeated IDs collapse to the last record
+	fake.statements, fake.args = nil, nil
+	err = store.Upsert(context.Background(), []rag.Record{
+		{ID: "a", Vector: []float64{1, 0}, Content: "old"},
+		{ID: "a", Vector: []float64{1, 0}, Content: "new"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args := fake.args[0]; len(args) != 4 || args[1] != "new" {
+		t.Errorf("args = %v", args)
+	}
+
+	if err := store.Upsert(context.Background(), []rag.Record{{ID: "d", Vector: []float64{1}}}); err == nil {
+		t.Error("expected a dimension error")
+	}
+}
+
+func TestStore_Query(t *testing.T) {
+	db, fake := openFake(t)
+	fake.rows = [][]driver.Value{
+		{"a", "Go", `{"lang": "en"}`, "[1,0]", 0.98},
+		{"b", "Gopher", `{}`, "[0.9,0.1]", 0.91},
+	}
+	store, err := New(db, Options{Dimensions: 2, EfSearch: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := store.Query(context.Background(), []float64{1, 0}, rag.QueryOptions{
+		TopK:     2,
+		Filter:   map[string]any{"lang": "en"},
+		MinScore: 0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "a" || results[0].Metadata["lang"] != "en" || results[1].Vector[1] != 0.1 {
+		t.Fatalf("results = %+v", results)
+	}
+	if fake.statements[0] != "SET LOCAL hnsw.ef_search = 100" {
+		t.Errorf("expected the ef_search hint first, got %q", fake.statements[0])
+	}
+	want := "SELECT id, content, metadata::text, embedding::text, 1 - (embedding <=> $1::vector) AS score FROM rag_records " +
+		"WHERE metadata @> $2::jsonb AND 1 - (embedding <=> $1::vector) >= $3 ORDER BY embedding <=> $1::vector LIMIT $4"
+	if fake.statements[1] != want {
+		t.Errorf("query =\n%s\nwant\n%s", fake.statements[1], want)
+	}
+	if args := fake.args[1]; args[0] != "[1,0]" || args[1] != `{"lang":"en"}` || args[3] != int64(2) {
+		t.Errorf("args = %v", args)
+	}
+
+	// QueryEach stops when the callback fails
+	stop := errors.New("stop")
+	fake.rows = [][]driver.Value{{"a", "Go", `{}`, "[1,0]", 0.98}, {"b", "Gopher", `{}`, "[0.9,0.1]", 0.91}}
+	seen := 0
+	err = store.QueryEach(context.Background(), []float64{1, 0}, rag.QueryOptions{}, func(r rag.QueryResult) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("err = %v after %d results", err, seen)
+	}
+}
+
+func TestStore_Delete(t *testing.T) {
+	db, fake := openFake(t)
+	store, err := New(db, Options{Dimensions: 2, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(context.Background(), []string{"a", "b", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.statements) != 2 || fake.statements[1] != "DELETE FROM rag_records WHERE id IN ($1)" {
+		t.Errorf("statements = %q", fake.statements)
+	}
+}
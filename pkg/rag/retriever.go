@@ -0,0 +1,304 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// Retriever finds content relevant to a query
+type Retriever interface {
+	Retrieve(ctx context.Context, query string) ([]QueryResult, error)
+}
+
+// RetrieverFunc adapts a function to the Retriever interface
+type RetrieverFunc func(ctx context.Context, query string) ([]QueryResult, error)
+
+// Retrieve calls f(ctx, query)
+func (f RetrieverFunc) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	return f(ctx, query)
+}
+
+// VectorStoreRetriever embeds the query and searches a VectorStore
+type VectorStoreRetriever struct {
+	// Model embeds queries; use the model the store was ingested with
+	Model provider.EmbeddingModel
+
+	// Store is searched for similar records
+	Store VectorStore
+
+	// Options are passed to every Store.Query call
+	Options QueryOptions
+}
+
+// NewVectorStoreRetriever creates a retriever returning the topK most
+// similar records
+func NewVectorStoreRetriever(model provider.EmbeddingModel, store VectorStore, topK int) *VectorStoreRetriever {
+	return &VectorStoreRetriever{Model: model, Store: store, Options: QueryOptions{TopK: topK}}
+}
+
+// Retrieve implements Retriever
+func (r *VectorStoreRetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	embedded, err := ai.Embed(ctx, ai.EmbedOptions{Model: r.Model, Input: query})
+	if err != nil {
+		return nil, err
+	}
+	return r.Store.Query(ctx, embedded.Embedding, r.Options)
+}
+
+// QueryRewriter holds the settings shared by the query-rewriting retrievers.
+// A small, cheap model is usually sufficient.
+type QueryRewriter struct {
+	// Model rewrites the query (required)
+	Model provider.LanguageModel
+
+	// Prompt overrides the default rewriting instructions. It is used as the
+	// system prompt; the user's query is sent as the prompt.
+	Prompt string
+
+	// Temperature for the rewriting model (default: provider default)
+	Temperature *float64
+
+	// OnRewrite is called with the queries actually sent to the base retriever
+	OnRewrite func(original string, rewritten []string)
+}
+
+func (w *QueryRewriter) rewrite(ctx context.Context, defaultPrompt, query string) (string, error) {
+	if w.Model == nil {
+		return "", fmt.Errorf("query rewriting model is required")
+	}
+	system := w.Prompt
+	if system == "" {
+		system = defaultPrompt
+	}
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model:       w.Model,
+		System:      system,
+		Prompt:      query,
+		Temperature: w.Temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("query rewriting failed: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+func (w *QueryRewriter) notify(original string, rewritten []string) {
+	if w.OnRewrite != nil {
+		w.OnRewrite(original, rewritten)
+	}
+}
+
+const hydePrompt = "Write a short passage that directly answers the user's question, " +
+	"as it might appear in a reference document. Respond with the passage only."
+
+// HyDERetriever implements Hypothetical Document Embeddings: the model writes
+// a plausible answer to the query and that passage, which is phrased like the
+// stored documents, is used for retrieval instead of the question.
+type HyDERetriever struct {
+	QueryRewriter
+
+	// Base retriever that receives the hypothetical document
+	Base Retriever
+
+	// IncludeQuery also retrieves with the original query and fuses the results
+	IncludeQuery bool
+}
+
+// NewHyDERetriever wraps base with HyDE query rewriting
+func NewHyDERetriever(base Retriever, model provider.LanguageModel) *HyDERetriever {
+	return &HyDERetriever{Base: base, QueryRewriter: QueryRewriter{Model: model}}
+}
+
+// Retrieve implements Retriever
+func (r *HyDERetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	passage, err := r.rewrite(ctx, hydePrompt, query)
+	if err != nil {
+		return nil, err
+	}
+	queries := []string{passage}
+	if r.IncludeQuery {
+		queries = append(queries, query)
+	}
+	r.notify(query, queries)
+	return retrieveAll(ctx, r.Base, queries)
+}
+
+const multiQueryPrompt = "Generate %d different phrasings of the user's search query " +
+	"that could retrieve relevant documents from a search index. Vary vocabulary and " +
+	"perspective. Respond with one query per line and nothing else."
+
+// MultiQueryRetriever expands the query into several paraphrases, retrieves
+// with each in parallel, and merges the results with reciprocal rank fusion
+type MultiQueryRetriever struct {
+	QueryRewriter
+
+	// Base retriever that receives each query
+	Base Retriever
+
+	// NumQueries is the number of paraphrases to generate (default 3)
+	NumQueries int
+
+	// ExcludeOriginal skips retrieval with the original query
+	ExcludeOriginal bool
+}
+
+// NewMultiQueryRetriever wraps base with multi-query expansion
+func NewMultiQueryRetriever(base Retriever, model provider.LanguageModel) *MultiQueryRetriever {
+	return &MultiQueryRetriever{Base: base, QueryRewriter: QueryRewriter{Model: model}}
+}
+
+// Retrieve implements Retriever
+func (r *MultiQueryRetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	n := r.NumQueries
+	if n <= 0 {
+		n = 3
+	}
+	text, err := r.rewrite(ctx, fmt.Sprintf(multiQueryPrompt, n), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []string
+	limit := n
+	if !r.ExcludeOriginal {
+		queries = append(queries, query)
+		limit++
+	}
+	for _, q := range parseQueryList(text) {
+		if len(queries) == limit {
+			break
+		}
+		queries = append(queries, q)
+	}
+	r.notify(query, queries)
+	return retrieveAll(ctx, r.Base, queries)
+}
+
+const stepBackPrompt = "Rewrite the user's question as a more general, higher-level " +
+	"question about the underlying concepts or principles needed to answer it. " +
+	"Respond with the question only."
+
+// StepBackRetriever retrieves with both the original query and a more
+// general "step-back" question, so background material is found alongside
+// specific matches
+type StepBackRetriever struct {
+	QueryRewriter
+
+	// Base retriever that receives both queries
+	Base Retriever
+}
+
+// NewStepBackRetriever wraps base with step-back prompting
+func NewStepBackRetriever(base Retriever, model provider.LanguageModel) *StepBackRetriever {
+	return &StepBackRetriever{Base: base, QueryRewriter: QueryRewriter{Model: model}}
+}
+
+// Retrieve implements Retriever
+func (r *StepBackRetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	stepBack, err := r.rewrite(ctx, stepBackPrompt, query)
+	if err != nil {
+		return nil, err
+	}
+	queries := []string{query, stepBack}
+	r.notify(query, queries)
+	return retrieveAll(ctx, r.Base, queries)
+}
+
+// retrieveAll runs base for every query concurrently and fuses the results
+func retrieveAll(ctx context.Context, base Retriever, queries []string) ([]QueryResult, error) {
+	if base == nil {
+		return nil, fmt.Errorf("base retriever is required")
+	}
+	if len(queries) == 1 {
+		return base.Retrieve(ctx, queries[0])
+	}
+
+	lists := make([][]QueryResult, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q string) {
+			defer wg.Done()
+			lists[i], errs[i] = base.Retrieve(ctx, q)
+		}(i, q)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return FuseResults(lists...), nil
+}
+
+// rrfK is the standard reciprocal rank fusion damping constant
+const rrfK = 60
+
+// FuseResults merges ranked result lists with reciprocal rank fusion.
+// Records appearing in several lists rank higher; duplicates are collapsed by
+// ID, keeping the highest similarity score. The fused list is as long as the
+// longest input list.
+func FuseResults(lists ...[]QueryResult) []QueryResult {
+	type fused struct {
+		result QueryResult
+		rrf    float64
+		order  int
+	}
+	byID := make(map[string]*fused)
+	limit := 0
+	for _, list := range lists {
+		limit = max(limit, len(list))
+		for rank, res := range list {
+			f, ok := byID[res.ID]
+			if !ok {
+				f = &fused{result: res, order: len(byID)}
+				byID[res.ID] = f
+			} else if res.Score > f.result.Score {
+				f.result.Score = res.Score
+			}
+			f.rrf += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	all := make([]*fused, 0, len(byID))
+	for _, f := range byID {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].rrf != all[j].rrf {
+			return all[i].rrf > all[j].rrf
+		}
+		return all[i].order < all[j].order
+	})
+
+	out := make([]QueryResult, 0, min(limit, len(all)))
+	for _, f := range all[:min(limit, len(all))] {
+		out = append(out, f.result)
+	}
+	return out
+}
+
+var listMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+
+// parseQueryList splits a model response into one query per non-empty line,
+// stripping list markers and quotes
+func parseQueryList(text string) []string {
+	var queries []string
+	for _, line := range strings.Split(text, "\n") {
+		line = listMarker.ReplaceAllString(line, "")
+		line = strings.Trim(strings.TrimSpace(line), `"`)
+		if line != "" {
+			queries = append(queries, line)
+		}
+	}
+	return queries
+}
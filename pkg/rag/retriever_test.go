@@ -0,0 +1,123 @@
+package rag
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// keywordRetriever returns canned results per query and records the queries
+type keywordRetriever struct {
+	mu      sync.Mutex
+	results map[string][]QueryResult
+	queries []string
+}
+
+func (r *keywordRetriever) Retrieve(ctx context.Context, query string) ([]QueryResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, query)
+	return r.results[query], nil
+}
+
+func rewriteModel(text string) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: text, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+}
+
+func result(id string, score float64) QueryResult {
+	return QueryResult{Record: Record{ID: id}, Score: score}
+}
+
+func ids(results []QueryResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestMultiQueryRetriever(t *testing.T) {
+	t.Parallel()
+
+	base := &keywordRetriever{results: map[string][]QueryResult{
+		"go errors":                {result("a", 0.9), result("b", 0.8)},
+		"golang error handling":    {result("b", 0.95), result("c", 0.7)},
+		"how to wrap errors in go": {result("b", 0.85)},
+	}}
+	r := NewMultiQueryRetriever(base, rewriteModel("1. golang error handling\n2. \"how to wrap errors in go\"\n3. extra query"))
+	r.NumQueries = 2
+
+	var rewritten []string
+	r.OnRewrite = func(original string, queries []string) { rewritten = queries }
+
+	got, err := r.Retrieve(context.Background(), "go errors")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"go errors", "golang error handling", "how to wrap errors in go"}; !reflect.DeepEqual(rewritten, want) {
+		t.Errorf("queries = %q, want %q", rewritten, want)
+	}
+	// b appears in all three lists, so it ranks first with its best score
+	if got[0].ID != "b" || got[0].Score != 0.95 {
+		t.Errorf("expected fused top result b@0.95, got %s@%v", got[0].ID, got[0].Score)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected fused list capped at longest input (2), got %v", ids(got))
+	}
+}
+
+func TestHyDERetriever(t *testing.T) {
+	t.Parallel()
+
+	passage := "Errors in Go are values returned alongside results."
+	base := &keywordRetriever{results: map[string][]QueryResult{passage: {result("doc", 0.9)}}}
+
+	got, err := NewHyDERetriever(base, rewriteModel(passage)).Retrieve(context.Background(), "how do go errors work?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(base.queries, []string{passage}) {
+		t.Errorf("expected retrieval with the hypothetical document, got %q", base.queries)
+	}
+	if len(got) != 1 || got[0].ID != "doc" {
+		t.Errorf("unexpected results: %v", ids(got))
+	}
+}
+
+func TestStepBackRetriever_Composes(t *testing.T) {
+	t.Parallel()
+
+	base := &keywordRetriever{results: map[string][]QueryResult{}}
+	inner := NewStepBackRetriever(base, rewriteModel("What is error handling?"))
+	outer := NewHyDERetriever(inner, rewriteModel("Passage."))
+	outer.IncludeQuery = true
+
+	if _, err := outer.Retrieve(context.Background(), "Why does errors.Is fail?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// HyDE issues two queries, and step-back doubles each
+	if len(base.queries) != 4 {
+		t.Errorf("expected 4 base queries, got %q", base.queries)
+	}
+}
+
+func TestFuseResults(t *testing.T) {
+	t.Parallel()
+
+	got := FuseResults(
+		[]QueryResult{result("a", 0.9), result("b", 0.5), result("c", 0.4)},
+		[]QueryResult{result("b", 0.6), result("c", 0.8)},
+	)
+	if want := []string{"b", "c", "a"}; !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("fused order = %v, want %v", ids(got), want)
+	}
+}
@@ -0,0 +1,52 @@
+package rag
+
+import "context"
+
+// Record is an embedded chunk as stored in a VectorStore
+type Record struct {
+	// ID uniquely identifies the record; upserting an existing ID replaces it
+	ID string
+
+	// Vector is the embedding
+	Vector []float64
+
+	// Content is the embedded text
+	Content string
+
+	// Metadata holds arbitrary filterable attributes
+	Metadata map[string]any
+}
+
+// QueryOptions controls a similarity search
+type QueryOptions struct {
+	// TopK is the maximum number of results (default 4)
+	TopK int
+
+	// Filter restricts results to records whose metadata contains all of the
+	// given key/value pairs
+	Filter map[string]any
+
+	// MinScore drops results with a lower similarity score
+	MinScore float64
+}
+
+// QueryResult is a record returned by a similarity search
+type QueryResult struct {
+	Record
+
+	// Score is the similarity to the query (higher is more similar)
+	Score float64
+}
+
+// VectorStore persists embedded records and searches them by similarity.
+// Implementations must be safe for concurrent use.
+type VectorStore interface {
+	// Upsert inserts records, replacing any with the same ID
+	Upsert(ctx context.Context, records []Record) error
+
+	// Query returns the records most similar to vector, best first
+	Query(ctx context.Context, vector []float64, opts QueryOptions) ([]QueryResult, error)
+
+	// Delete removes the records with the given IDs; unknown IDs are ignored
+	Delete(ctx context.Context, ids []string) error
+}
@@ -0,0 +1,291 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// maxUpgrades bounds replacement chains, catching catalogs whose
+// replacements form a cycle
+const maxUpgrades = 8
+
+// Catalog maps floating model names to snapshots and records when
+// snapshots are deprecated and retired. Model strings are "provider:model".
+//
+// Example:
+//
+//	r.SetCatalog(&registry.Catalog{
+//		Aliases: map[string]string{"openai:gpt-4o": "openai:gpt-4o-2024-11-20"},
+//		Models: map[string]registry.ModelLifecycle{
+//			"openai:gpt-4o-2024-05-13": {
+//				DeprecatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+//				RetiresAt:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+//				Replacement:  "openai:gpt-4o-2024-11-20",
+//			},
+//		},
+//	}, registry.CatalogOptions{Policy: registry.UpgradeAuto})
+type Catalog struct {
+	// Aliases maps a floating name ("gpt-4o" or "openai:gpt-4o") to the
+	// currently recommended snapshot
+	Aliases map[string]string
+
+	// Models holds the lifecycle of deprecated and soon-to-be deprecated
+	// snapshots
+	Models map[string]ModelLifecycle
+}
+
+// ModelLifecycle describes a snapshot's deprecation
+type ModelLifecycle struct {
+	// DeprecatedAt is when the snapshot is deprecated; zero means it
+	// already is
+	DeprecatedAt time.Time
+
+	// RetiresAt is when the provider stops serving the snapshot (zero when
+	// not announced)
+	RetiresAt time.Time
+
+	// Replacement is the snapshot that supersedes it
+	Replacement string
+}
+
+// UpgradePolicy decides what happens when a deprecated snapshot is resolved
+type UpgradePolicy string
+
+const (
+	// UpgradePin keeps resolving a deprecated snapshot, with a deprecation
+	// notice, so behavior never changes silently. Retired snapshots fail
+	// with a *RetiredModelError. This is the default.
+	UpgradePin UpgradePolicy = "pin"
+
+	// UpgradeAuto replaces deprecated snapshots with their replacement,
+	// with a deprecation notice
+	UpgradeAuto UpgradePolicy = "auto"
+)
+
+// CatalogOptions configures how a Catalog is applied
+type CatalogOptions struct {
+	// Policy for deprecated snapshots (default: UpgradePin)
+	Policy UpgradePolicy
+
+	// Pins fix floating names to a snapshot, overriding the catalog's
+	// aliases, e.g. to keep "openai:gpt-4o" on a validated snapshot when
+	// the catalog moves it
+	Pins map[string]string
+
+	// OnDeprecation is called whenever a deprecated snapshot is resolved
+	OnDeprecation func(notice DeprecationNotice)
+
+	// Clock decides whether snapshots are deprecated or retired (default:
+	// system clock)
+	Clock clock.Clock
+}
+
+// DeprecationNotice describes the resolution of a deprecated snapshot
+type DeprecationNotice struct {
+	// Requested is the model string as requested, before aliases
+	Requested string
+
+	// Deprecated is the deprecated snapshot
+	Deprecated string
+
+	// Resolved is the snapshot in use: Deprecated, or its replacement when
+	// the snapshot was upgraded
+	Resolved string
+
+	// Lifecycle of the deprecated snapshot
+	Lifecycle ModelLifecycle
+}
+
+// Upgraded reports whether the deprecated snapshot was replaced
+func (n DeprecationNotice) Upgraded() bool {
+	return n.Resolved != n.Deprecated
+}
+
+func (n DeprecationNotice) String() string {
+	msg := fmt.Sprintf("model %s is deprecated", n.Deprecated)
+	if !n.Lifecycle.RetiresAt.IsZero() {
+		msg += fmt.Sprintf(" and retires on %s", n.Lifecycle.RetiresAt.Format("2006-01-02"))
+	}
+	switch {
+	case n.Upgraded():
+		msg += fmt.Sprintf("; upgraded to %s", n.Resolved)
+	case n.Lifecycle.Replacement != "":
+		msg += fmt.Sprintf("; migrate to %s", n.Lifecycle.Replacement)
+	}
+	return msg
+}
+
+// RetiredModelError is returned when a retired snapshot is resolved and
+// the policy does not upgrade it
+type RetiredModelError struct {
+	Model       string
+	RetiredAt   time.Time
+	Replacement string
+}
+
+func (e *RetiredModelError) Error() string {
+	msg := fmt.Sprintf("model %s was retired on %s", e.Model, e.RetiredAt.Format("2006-01-02"))
+	if e.Replacement != "" {
+		msg += fmt.Sprintf("; use %s", e.Replacement)
+	}
+	return msg
+}
+
+type catalogState struct {
+	catalog *Catalog
+	opts    CatalogOptions
+}
+
+// SetCatalog applies catalog to every resolution: floating names resolve
+// to their recommended snapshot, and deprecated snapshots are pinned or
+// upgraded according to opts. nil removes the catalog. The catalog must
+// not be modified afterwards; call SetCatalog again to update it.
+//
+// Language models resolved to a deprecated snapshot add the notice to the
+// warnings of their results.
+func (r *Registry) SetCatalog(catalog *Catalog, opts CatalogOptions) {
+	if opts.Policy == "" {
+		opts.Policy = UpgradePin
+	}
+	opts.Clock = clock.Default(opts.Clock)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if catalog == nil {
+		r.catalog = nil
+		return
+	}
+	r.catalog = &catalogState{catalog: catalog, opts: opts}
+}
+
+// applyCatalog resolves floating names and deprecated snapshots. It returns
+// a notice when the result involves a deprecated snapshot.
+func (r *Registry) applyCatalog(requested, model string) (string, *DeprecationNotice, error) {
+	state := r.catalog
+	if state == nil {
+		return model, nil, nil
+	}
+	model = state.snapshot(model)
+
+	now := state.opts.Clock.Now()
+	var notice *DeprecationNotice
+	for i := 0; ; i++ {
+		lifecycle, ok := state.catalog.Models[model]
+		deprecated := ok && !lifecycle.DeprecatedAt.After(now)
+		retired := ok && !lifecycle.RetiresAt.IsZero() && !lifecycle.RetiresAt.After(now)
+		if !deprecated && !retired {
+			return model, notice, nil
+		}
+		if notice == nil {
+			notice = &DeprecationNotice{Requested: requested, Deprecated: model, Lifecycle: lifecycle}
+		}
+		upgrade := lifecycle.Replacement != "" && state.opts.Policy == UpgradeAuto
+		if !upgrade {
+			if retired {
+				return "", nil, &RetiredModelError{Model: model, RetiredAt: lifecycle.RetiresAt, Replacement: lifecycle.Replacement}
+			}
+			notice.Resolved = model
+			return model, notice, nil
+		}
+		if i == maxUpgrades {
+			return "", nil, fmt.Errorf("model %s: replacement chain longer than %d", notice.Deprecated, maxUpgrades)
+		}
+		model = state.snapshot(lifecycle.Replacement)
+		notice.Resolved = model
+	}
+}
+
+// snapshot resolves a floating name through the pins, then the aliases
+func (s *catalogState) snapshot(model string) string {
+	if pinned, ok := s.opts.Pins[model]; ok {
+		return pinned
+	}
+	if target, ok := s.catalog.Aliases[model]; ok {
+		return target
+	}
+	return model
+}
+
+// notify reports a deprecation notice to the catalog's callback. The caller
+// must not hold r.mu.
+func (r *Registry) notify(notice *DeprecationNotice) {
+	r.mu.RLock()
+	state := r.catalog
+	r.mu.RUnlock()
+	if state != nil && state.opts.OnDeprecation != nil {
+		state.opts.OnDeprecation(*notice)
+	}
+}
+
+// withDeprecationWarning adds the notice to the warnings of model's results
+func withDeprecationWarning(model provider.LanguageModel, notice *DeprecationNotice) provider.LanguageModel {
+	warning := types.Warning{Type: "other", Feature: "model", Details: notice.String()}
+	return middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{{
+		SpecificationVersion: "v3",
+		WrapGenerate: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil {
+				return nil, err
+			}
+			result.Warnings = append(result.Warnings, warning)
+			return result, nil
+		},
+		WrapStream: func(ctx context.Context, doGenerate func() (*types.GenerateResult, error), doStream func() (provider.TextStream, error), params *provider.GenerateOptions, model provider.LanguageModel) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &warningStream{TextStream: stream, warning: warning}, nil
+		},
+	}}, nil, nil)
+}
+
+// warningStream adds a warning to the stream-start chunk, sending one first
+// if the provider does not
+type warningStream struct {
+	provider.TextStream
+	warning types.Warning
+	sent    bool
+}
+
+func (s *warningStream) Next() (*provider.StreamChunk, error) {
+	if s.sent {
+		return s.TextStream.Next()
+	}
+	s.sent = true
+	chunk, err := s.TextStream.Next()
+	if err != nil && err != io.EOF {
+		return chunk, err
+	}
+	if err == nil && chunk.Type == provider.ChunkTypeStreamStart {
+		withWarning := *chunk
+		withWarning.Warnings = append(append([]types.Warning(nil), chunk.Warnings...), s.warning)
+		return &withWarning, nil
+	}
+	start := &provider.StreamChunk{Type: provider.ChunkTypeStreamStart, Warnings: []types.Warning{s.warning}}
+	s.TextStream = &prependedStream{TextStream: s.TextStream, chunk: chunk, err: err}
+	return start, nil
+}
+
+// prependedStream returns one already-read chunk (or error) before the rest
+// of the stream
+type prependedStream struct {
+	provider.TextStream
+	chunk *provider.StreamChunk
+	err   error
+	done  bool
+}
+
+func (s *prependedStream) Next() (*provider.StreamChunk, error) {
+	if !s.done {
+		s.done = true
+		return s.chunk, s.err
+	}
+	return s.TextStream.Next()
+}
@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// SetModelListCache sets the cache ListModels reads through. A registry
+// creates a default cache (10 minute TTL) on first use.
+func (r *Registry) SetModelListCache(cache *provider.ModelListCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models = cache
+}
+
+// ListModels returns the models offered by the named provider, served from
+// the registry's model list cache. It returns provider.ErrListModelsNotSupported
+// for providers that cannot list their models.
+func (r *Registry) ListModels(ctx context.Context, providerName string) ([]provider.ModelListing, error) {
+	p, err := r.GetProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.models == nil {
+		r.models = provider.NewModelListCache(provider.ModelListCacheOptions{})
+	}
+	cache := r.models
+	r.mu.Unlock()
+
+	return cache.ListModels(ctx, p)
+}
+
+// ListModels lists a provider's models using the global registry
+func ListModels(ctx context.Context, providerName string) ([]provider.ModelListing, error) {
+	return globalRegistry.ListModels(ctx, providerName)
+}
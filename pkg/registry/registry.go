@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -15,6 +17,9 @@ type Registry struct {
 	mu        sync.RWMutex
 	providers map[string]provider.Provider
 	aliases   map[string]string // model alias -> provider:model
+	residency *ResidencyPolicy
+	catalog   *catalogState
+	models    *provider.ModelListCache
 }
 
 // NewRegistry creates a new registry
@@ -57,54 +62,69 @@ func (r *Registry) RegisterAlias(alias, target string) {
 //   - "gpt-4" -> uses registered aliases
 //   - "openai:gpt-4" -> provider:model format
 func (r *Registry) ResolveLanguageModel(model string) (provider.LanguageModel, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	return r.ResolveLanguageModelContext(context.Background(), model)
+}
 
-	// Check if it's an alias
-	if target, ok := r.aliases[model]; ok {
-		model = target
-	}
+// ResolveEmbeddingModel resolves a model string to an EmbeddingModel
+func (r *Registry) ResolveEmbeddingModel(model string) (provider.EmbeddingModel, error) {
+	return r.ResolveEmbeddingModelContext(context.Background(), model)
+}
 
-	// Parse provider:model format
-	providerName, modelID, err := parseModelString(model)
+// ResolveLanguageModelContext resolves a model string like
+// ResolveLanguageModel, applying the catalog and the residency policy for
+// the tenant or region in ctx
+func (r *Registry) ResolveLanguageModelContext(ctx context.Context, model string) (provider.LanguageModel, error) {
+	p, modelID, notice, err := r.resolve(ctx, model)
 	if err != nil {
 		return nil, err
 	}
-
-	// Get provider
-	p, ok := r.providers[providerName]
-	if !ok {
-		return nil, fmt.Errorf("provider not found: %s", providerName)
+	lm, err := p.LanguageModel(modelID)
+	if err != nil || notice == nil {
+		return lm, err
 	}
+	r.notify(notice)
+	return withDeprecationWarning(lm, notice), nil
+}
 
-	// Get model from provider
-	return p.LanguageModel(modelID)
+// ResolveEmbeddingModelContext resolves an embedding model string like
+// ResolveEmbeddingModel, applying the catalog and the residency policy
+func (r *Registry) ResolveEmbeddingModelContext(ctx context.Context, model string) (provider.EmbeddingModel, error) {
+	p, modelID, notice, err := r.resolve(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	if notice != nil {
+		r.notify(notice)
+	}
+	return p.EmbeddingModel(modelID)
 }
 
-// ResolveEmbeddingModel resolves a model string to an EmbeddingModel
-func (r *Registry) ResolveEmbeddingModel(model string) (provider.EmbeddingModel, error) {
+// resolve expands aliases, applies the catalog and the residency policy,
+// and looks up the provider
+func (r *Registry) resolve(ctx context.Context, model string) (provider.Provider, string, *DeprecationNotice, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Check if it's an alias
+	requested := model
 	if target, ok := r.aliases[model]; ok {
 		model = target
 	}
-
-	// Parse provider:model format
+	model, notice, err := r.applyCatalog(requested, model)
+	if err != nil {
+		return nil, "", nil, err
+	}
 	providerName, modelID, err := parseModelString(model)
 	if err != nil {
-		return nil, err
+		return nil, "", nil, err
+	}
+	if providerName, modelID, err = r.applyResidency(ctx, providerName, modelID); err != nil {
+		return nil, "", nil, err
 	}
-
-	// Get provider
 	p, ok := r.providers[providerName]
 	if !ok {
-		return nil, fmt.Errorf("provider not found: %s", providerName)
+		return nil, "", nil, fmt.Errorf("provider not found: %s", providerName)
 	}
-
-	// Get model from provider
-	return p.EmbeddingModel(modelID)
+	return p, modelID, notice, nil
 }
 
 // ListProviders returns all registered provider names
@@ -131,6 +151,35 @@ func (r *Registry) ListAliases() map[string]string {
 	return aliases
 }
 
+// Shutdown gracefully shuts down every registered provider that supports it
+// (see provider.Shutdowner). Providers are shut down concurrently and share
+// ctx's deadline; their errors are joined.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.RLock()
+	providers := make(map[string]provider.Provider, len(r.providers))
+	for name, p := range r.providers {
+		providers[name] = p
+	}
+	r.mu.RUnlock()
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for name, p := range providers {
+		wg.Add(1)
+		go func(name string, p provider.Provider) {
+			defer wg.Done()
+			if err := provider.Shutdown(ctx, p); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				mu.Unlock()
+			}
+		}(name, p)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 // parseModelString parses a model string into provider and model ID
 // Formats supported:
 //   - "provider:model" -> ("provider", "model")
@@ -174,6 +223,17 @@ func ResolveEmbeddingModel(model string) (provider.EmbeddingModel, error) {
 	return globalRegistry.ResolveEmbeddingModel(model)
 }
 
+// ResolveLanguageModelContext resolves a model string using the global
+// registry, applying its residency policy
+func ResolveLanguageModelContext(ctx context.Context, model string) (provider.LanguageModel, error) {
+	return globalRegistry.ResolveLanguageModelContext(ctx, model)
+}
+
+// Shutdown shuts down the providers in the global registry
+func Shutdown(ctx context.Context) error {
+	return globalRegistry.Shutdown(ctx)
+}
+
 // GetGlobalRegistry returns the global registry instance
 func GetGlobalRegistry() *Registry {
 	return globalRegistry
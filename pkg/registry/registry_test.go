@@ -1,9 +1,13 @@
 package registry
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/digitallysavvy/go-ai/pkg/clock"
 	"github.com/digitallysavvy/go-ai/pkg/provider"
 	"github.com/digitallysavvy/go-ai/pkg/testutil"
 )
@@ -467,3 +471,202 @@ func TestRegistry_ListAliasesReturnsACopy(t *testing.T) {
 		t.Error("modifying returned aliases map should not affect registry")
 	}
 }
+
+func TestRegistry_ResidencyPolicy(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	for _, name := range []string{"openai", "azure-eu", "anthropic"} {
+		name := name
+		r.RegisterProvider(name, &testutil.MockProvider{
+			ProviderName: name,
+			LanguageModelFunc: func(modelID string) (provider.LanguageModel, error) {
+				return &testutil.MockLanguageModel{ProviderName: name, ModelName: modelID}, nil
+			},
+		})
+	}
+	r.SetResidencyPolicy(&ResidencyPolicy{
+		TenantRegions: map[string]string{"acme-gmbh": "eu", "acme-inc": "us", "lost": "apac"},
+		Regions: map[string]RegionRule{
+			"eu": {Routes: map[string]string{"openai": "azure-eu", "openai:gpt-4o-mini": "azure-eu:gpt-4o-mini-eu"}},
+			"us": {Providers: []string{"openai", "anthropic"}},
+		},
+	})
+
+	eu := WithTenant(context.Background(), "acme-gmbh")
+	tests := []struct {
+		ctx      context.Context
+		model    string
+		provider string
+		modelID  string
+	}{
+		{eu, "openai:gpt-4o", "azure-eu", "gpt-4o"},
+		{eu, "openai:gpt-4o-mini", "azure-eu", "gpt-4o-mini-eu"},
+		{eu, "azure-eu:gpt-4o", "azure-eu", "gpt-4o"},
+		{WithTenant(context.Background(), "acme-inc"), "anthropic:claude", "anthropic", "claude"},
+		{WithRegion(WithTenant(context.Background(), "acme-inc"), "eu"), "openai:gpt-4o", "azure-eu", "gpt-4o"},
+		{context.Background(), "anthropic:claude", "anthropic", "claude"},
+	}
+	for _, tt := range tests {
+		model, err := r.ResolveLanguageModelContext(tt.ctx, tt.model)
+		if err != nil {
+			t.Errorf("%s: %v", tt.model, err)
+			continue
+		}
+		if model.Provider() != tt.provider || model.ModelID() != tt.modelID {
+			t.Errorf("%s resolved to %s:%s", tt.model, model.Provider(), model.ModelID())
+		}
+	}
+
+	var residency *ResidencyError
+	if _, err := r.ResolveLanguageModelContext(eu, "anthropic:claude"); !errors.As(err, &residency) || residency.Region != "eu" {
+		t.Errorf("expected a ResidencyError, got %v", err)
+	}
+	if _, err := r.ResolveLanguageModelContext(WithTenant(context.Background(), "lost"), "openai:gpt-4o"); !errors.As(err, &residency) {
+		t.Errorf("expected a region without a rule to reject, got %v", err)
+	}
+}
+
+func TestRegistry_CatalogPinsAndUpgradesSnapshots(t *testing.T) {
+	t.Parallel()
+
+	newRegistry := func(opts CatalogOptions) *Registry {
+		r := NewRegistry()
+		r.RegisterProvider("openai", &testutil.MockProvider{
+			ProviderName: "openai",
+			LanguageModelFunc: func(modelID string) (provider.LanguageModel, error) {
+				return &testutil.MockLanguageModel{ProviderName: "openai", ModelName: modelID}, nil
+			},
+		})
+		r.RegisterAlias("gpt-4", "openai:gpt-4")
+		r.SetCatalog(&Catalog{
+			Aliases: map[string]string{"openai:gpt-4": "openai:gpt-4-0613", "openai:gpt-4o": "openai:gpt-4o-2024-11-20"},
+			Models: map[string]ModelLifecycle{
+				"openai:gpt-4-0613": {Replacement: "openai:gpt-4o"},
+				"openai:gpt-4-0314": {
+					RetiresAt:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					Replacement: "openai:gpt-4-0613",
+				},
+			},
+		}, opts)
+		return r
+	}
+	clk := clock.NewFake(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	var notices []DeprecationNotice
+	pinned := newRegistry(CatalogOptions{Clock: clk, OnDeprecation: func(n DeprecationNotice) { notices = append(notices, n) }})
+	model, err := pinned.ResolveLanguageModel("gpt-4")
+	if err != nil || model.ModelID() != "gpt-4-0613" {
+		t.Fatalf("pinned resolution = %v, %v", model, err)
+	}
+	if len(notices) != 1 || notices[0].Upgraded() || notices[0].Requested != "gpt-4" {
+		t.Errorf("notices = %+v", notices)
+	}
+	result, err := model.DoGenerate(context.Background(), &provider.GenerateOptions{})
+	if err != nil || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Details, "migrate to openai:gpt-4o") {
+		t.Errorf("expected a deprecation warning, got %+v (%v)", result, err)
+	}
+	var retired *RetiredModelError
+	if _, err := pinned.ResolveLanguageModel("openai:gpt-4-0314"); !errors.As(err, &retired) {
+		t.Errorf("expected RetiredModelError, got %v", err)
+	}
+	if model, _ := pinned.ResolveLanguageModel("openai:gpt-4o-2024-11-20"); model.ModelID() != "gpt-4o-2024-11-20" {
+		t.Errorf("current snapshot resolved to %s", model.ModelID())
+	}
+
+	auto := newRegistry(CatalogOptions{Policy: UpgradeAuto, Clock: clk, Pins: map[string]string{"openai:gpt-4o": "openai:gpt-4o-2024-08-06"}})
+	if model, err := auto.ResolveLanguageModel("openai:gpt-4-0314"); err != nil || model.ModelID() != "gpt-4o-2024-08-06" {
+		t.Errorf("auto upgrade resolved to %v, %v", model, err)
+	}
+}
+
+// listingProvider is a MockProvider that can list its models
+type listingProvider struct {
+	*testutil.MockProvider
+	calls int
+}
+
+func (p *listingProvider) ListModels(ctx context.Context) ([]provider.ModelListing, error) {
+	p.calls++
+	return []provider.ModelListing{{ID: "model-a"}}, nil
+}
+
+func TestRegistry_ListModels(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	p := &listingProvider{MockProvider: &testutil.MockProvider{ProviderName: "lister"}}
+	r.RegisterProvider("lister", p)
+	r.RegisterProvider("plain", &testutil.MockProvider{ProviderName: "plain"})
+
+	for i := 0; i < 3; i++ {
+		models, err := r.ListModels(context.Background(), "lister")
+		if err != nil || len(models) != 1 || models[0].ID != "model-a" {
+			t.Fatalf("models = %v, err = %v", models, err)
+		}
+	}
+	if p.calls != 1 {
+		t.Errorf("expected the list to be cached, got %d fetches", p.calls)
+	}
+	if _, err := r.ListModels(context.Background(), "plain"); !errors.Is(err, provider.ErrListModelsNotSupported) {
+		t.Errorf("expected ErrListModelsNotSupported, got %v", err)
+	}
+	if _, err := r.ListModels(context.Background(), "missing"); err == nil {
+		t.Error("expected an unknown provider error")
+	}
+}
+
+// shutdownProvider is a MockProvider that records Shutdown calls
+type shutdownProvider struct {
+	*testutil.MockProvider
+	err  error
+	shut chan struct{}
+}
+
+func (p *shutdownProvider) Shutdown(ctx context.Context) error {
+	close(p.shut)
+	return p.err
+}
+
+func TestRegistry_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	ok := &shutdownProvider{MockProvider: &testutil.MockProvider{ProviderName: "ok"}, shut: make(chan struct{})}
+	failing := &shutdownProvider{MockProvider: &testutil.MockProvider{ProviderName: "failing"}, err: errors.New("flush failed"), shut: make(chan struct{})}
+	r.RegisterProvider("ok", ok)
+	r.RegisterProvider("failing", failing)
+	r.RegisterProvider("plain", &testutil.MockProvider{ProviderName: "plain"})
+
+	err := r.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failing: flush failed") {
+		t.Errorf("expected the failing provider's error, got %v", err)
+	}
+	for _, p := range []*shutdownProvider{ok, failing} {
+		select {
+		case <-p.shut:
+		default:
+			t.Errorf("provider %s was not shut down", p.Name())
+		}
+	}
+}
+
+func TestAllowModels(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	resolve := AllowModels(func(model string) (provider.LanguageModel, error) {
+		calls++
+		return &testutil.MockLanguageModel{}, nil
+	}, []string{"openai:gpt-4o"})
+
+	if _, err := resolve("openai:gpt-4o"); err != nil {
+		t.Errorf("unexpected error for an allowed model: %v", err)
+	}
+	if _, err := resolve("anthropic:claude"); !errors.Is(err, ErrModelNotAllowed) {
+		t.Errorf("expected ErrModelNotAllowed, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected disallowed models not to be resolved, got %d calls", calls)
+	}
+}
@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ResidencyPolicy pins tenants to regions and regions to the providers
+// allowed to process their data. Each region's providers are registered
+// under their own names, e.g. an EU Azure deployment as "azure-eu".
+//
+// Example:
+//
+//	r.SetResidencyPolicy(&registry.ResidencyPolicy{
+//		TenantRegions: map[string]string{"acme-gmbh": "eu", "acme-inc": "us"},
+//		Regions: map[string]registry.RegionRule{
+//			"eu": {Providers: []string{"azure-eu", "mistral"}, Routes: map[string]string{"openai": "azure-eu"}},
+//			"us": {Providers: []string{"anthropic", "openai"}},
+//		},
+//	})
+//
+//	ctx := registry.WithTenant(ctx, "acme-gmbh")
+//	model, err := r.ResolveLanguageModelContext(ctx, "openai:gpt-4o") // azure-eu:gpt-4o
+//	_, err = r.ResolveLanguageModelContext(ctx, "anthropic:claude-sonnet-4-5") // *ResidencyError
+type ResidencyPolicy struct {
+	// TenantRegions pins tenants to a region
+	TenantRegions map[string]string
+
+	// DefaultRegion applies to tenants not in TenantRegions and to
+	// resolutions without a tenant. Empty leaves them unrestricted.
+	DefaultRegion string
+
+	// Regions holds the rule for each region. A tenant pinned to a region
+	// without a rule cannot resolve any model.
+	Regions map[string]RegionRule
+}
+
+// RegionRule is the set of providers that may serve a region
+type RegionRule struct {
+	// Providers lists the registered provider names allowed in the region
+	Providers []string
+
+	// Routes redirects models to a regional deployment before the
+	// providers are checked. Keys and values are a provider name ("openai")
+	// or a model string ("openai:gpt-4o"); a provider name keeps the model
+	// ID. Route targets are allowed even when not listed in Providers.
+	Routes map[string]string
+}
+
+// ResidencyError is returned when a model would process data outside the
+// region a tenant is pinned to
+type ResidencyError struct {
+	Tenant   string
+	Region   string
+	Provider string
+	Model    string
+}
+
+func (e *ResidencyError) Error() string {
+	who := "requests"
+	if e.Tenant != "" {
+		who = fmt.Sprintf("tenant %q", e.Tenant)
+	}
+	if e.Provider == "" {
+		return fmt.Sprintf("data residency: %s pinned to region %q, which has no rule", who, e.Region)
+	}
+	return fmt.Sprintf("data residency: %s pinned to region %q cannot use %s:%s", who, e.Region, e.Provider, e.Model)
+}
+
+type tenantKey struct{}
+
+type regionKey struct{}
+
+// WithTenant returns a context whose resolutions apply the residency rules
+// of tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// WithRegion returns a context whose resolutions are pinned to region,
+// overriding the tenant's region
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// SetResidencyPolicy enforces policy on every resolution. nil removes the
+// policy. The policy must not be modified afterwards.
+func (r *Registry) SetResidencyPolicy(policy *ResidencyPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.residency = policy
+}
+
+// applyResidency routes a model to its regional deployment and rejects it
+// when the region does not allow its provider
+func (r *Registry) applyResidency(ctx context.Context, providerName, modelID string) (string, string, error) {
+	policy := r.residency
+	if policy == nil {
+		return providerName, modelID, nil
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	region, _ := ctx.Value(regionKey{}).(string)
+	if region == "" {
+		var ok bool
+		if region, ok = policy.TenantRegions[tenant]; !ok {
+			region = policy.DefaultRegion
+		}
+	}
+	if region == "" {
+		return providerName, modelID, nil
+	}
+
+	rule, ok := policy.Regions[region]
+	if !ok {
+		return "", "", &ResidencyError{Tenant: tenant, Region: region}
+	}
+	route, ok := rule.Routes[providerName+":"+modelID]
+	if !ok {
+		route, ok = rule.Routes[providerName]
+	}
+	if ok {
+		if p, m, found := strings.Cut(route, ":"); found {
+			return p, m, nil
+		}
+		return route, modelID, nil
+	}
+	for _, allowed := range rule.Providers {
+		if allowed == providerName {
+			return providerName, modelID, nil
+		}
+	}
+	for _, target := range rule.Routes {
+		if p, _, _ := strings.Cut(target, ":"); p == providerName {
+			return providerName, modelID, nil
+		}
+	}
+	return "", "", &ResidencyError{Tenant: tenant, Region: region, Provider: providerName, Model: modelID}
+}
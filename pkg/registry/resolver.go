@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// ErrModelNotAllowed is returned by resolvers built with AllowModels for
+// models outside the allow-list
+var ErrModelNotAllowed = errors.New("model is not allowed")
+
+// ModelResolver maps a model string such as "openai:gpt-4o" to a language
+// model. ResolveLanguageModel resolves from the global registry.
+type ModelResolver func(model string) (provider.LanguageModel, error)
+
+// AllowModels restricts resolve to the listed models. Other models fail with
+// ErrModelNotAllowed without reaching resolve. An empty list allows every
+// model resolve knows.
+func AllowModels(resolve ModelResolver, models []string) ModelResolver {
+	if len(models) == 0 {
+		return resolve
+	}
+	allowed := make(map[string]bool, len(models))
+	for _, m := range models {
+		allowed[m] = true
+	}
+	return func(model string) (provider.LanguageModel, error) {
+		if !allowed[model] {
+			return nil, fmt.Errorf("%w: %q", ErrModelNotAllowed, model)
+		}
+		return resolve(model)
+	}
+}
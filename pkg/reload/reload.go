@@ -0,0 +1,199 @@
+// Package reload keeps values loaded from files, such as prompt templates and
+// agent definitions, up to date while a server runs.
+//
+// A Value polls the files it was loaded from. When one changes, the value is
+// loaded again; if loading succeeds the new value replaces the old one
+// atomically, and if it fails the old value stays in service and the error
+// is reported. Handlers call Get on every request and always see a complete,
+// validated value:
+//
+//	prompts, err := reload.Templates("prompts/*.tmpl", nil, reload.Options{
+//		OnError: func(err error) { log.Printf("prompt reload: %v", err) },
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer prompts.Close()
+//
+//	http.HandleFunc("/chat", func(w http.ResponseWriter, r *http.Request) {
+//		var system strings.Builder
+//		_ = prompts.Get().ExecuteTemplate(&system, "support.tmpl", data)
+//		...
+//	})
+package reload
+
+import (
+	"crypto/sha256"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+// LoadFunc loads a value and returns the files it was read from. Those files
+// are watched for changes. Directories may be included to notice files being
+// added or removed.
+type LoadFunc[T any] func() (value T, files []string, err error)
+
+// Options configures a Value
+type Options struct {
+	// Interval between checks for changed files (default: 1s)
+	Interval time.Duration
+
+	// OnReload is called after a changed value has been swapped in
+	OnReload func()
+
+	// OnError is called when a reload fails; the previous value stays in use
+	OnError func(err error)
+
+	// Clock drives the polling interval (default: the system clock)
+	Clock clock.Clock
+}
+
+// Value is a value loaded from files that is reloaded when they change
+type Value[T any] struct {
+	load LoadFunc[T]
+	opts Options
+
+	current atomic.Pointer[T]
+
+	mu    sync.Mutex // serializes Check
+	files map[string][32]byte
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Watch loads a value and starts watching its files. It returns an error,
+// and starts nothing, when the initial load fails.
+func Watch[T any](load LoadFunc[T], opts Options) (*Value[T], error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	opts.Clock = clock.Default(opts.Clock)
+
+	v := &Value[T]{
+		load: load,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+	go v.poll()
+	return v, nil
+}
+
+// Get returns the current value
+func (v *Value[T]) Get() T {
+	return *v.current.Load()
+}
+
+// Check reloads the value now if any of its files changed. It reports
+// whether a new value was swapped in. Watch calls it every Interval; call it
+// directly to reload on demand, e.g. from an admin endpoint.
+func (v *Value[T]) Check() (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	changed := false
+	sums := make(map[string][32]byte, len(v.files))
+	for path, sum := range v.files {
+		sums[path] = fileSum(path)
+		if sums[path] != sum {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	// Remember the new contents even if loading fails, so a broken file is
+	// reported once rather than on every check
+	v.files = sums
+	if err := v.reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Close stops watching. The current value stays available.
+func (v *Value[T]) Close() error {
+	v.once.Do(func() { close(v.stop) })
+	<-v.done
+	return nil
+}
+
+// reload loads the value and swaps it in. The caller holds v.mu, except
+// during Watch.
+func (v *Value[T]) reload() error {
+	value, files, err := v.load()
+	if err != nil {
+		return err
+	}
+	sums := make(map[string][32]byte, len(files))
+	for _, path := range files {
+		sums[path] = fileSum(path)
+	}
+	v.files = sums
+	v.current.Store(&value)
+	return nil
+}
+
+func (v *Value[T]) poll() {
+	defer close(v.done)
+	for {
+		timer := v.opts.Clock.NewTimer(v.opts.Interval)
+		select {
+		case <-v.stop:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		swapped, err := v.Check()
+		if err != nil && v.opts.OnError != nil {
+			v.opts.OnError(err)
+		}
+		if swapped && v.opts.OnReload != nil {
+			v.opts.OnReload()
+		}
+	}
+}
+
+// fileSum hashes a file's contents, or a directory's sorted entry names.
+// Missing and unreadable files hash to zero.
+func fileSum(path string) [32]byte {
+	info, err := os.Stat(path)
+	if err != nil {
+		return [32]byte{}
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return [32]byte{}
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		sort.Strings(names)
+		h := sha256.New()
+		for _, name := range names {
+			h.Write([]byte(name))
+			h.Write([]byte{0})
+		}
+		var sum [32]byte
+		copy(sum[:], h.Sum(nil))
+		return sum
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [32]byte{}
+	}
+	return sha256.Sum256(data)
+}
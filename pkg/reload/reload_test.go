@@ -0,0 +1,154 @@
+package reload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// watchText watches path, failing to load when the file contains "invalid"
+func watchText(t *testing.T, path string, opts Options) *Value[string] {
+	t.Helper()
+	v, err := Watch(func() (string, []string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.Contains(string(data), "invalid") {
+			return "", nil, errors.New("invalid content")
+		}
+		return string(data), []string{path}, nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	t.Cleanup(func() { _ = v.Close() })
+	return v
+}
+
+func TestValue_Check(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	writeFile(t, path, "v1")
+	v := watchText(t, path, Options{Interval: time.Hour})
+
+	if swapped, err := v.Check(); swapped || err != nil {
+		t.Errorf("unchanged file: swapped=%v err=%v", swapped, err)
+	}
+
+	writeFile(t, path, "v2")
+	if swapped, err := v.Check(); !swapped || err != nil {
+		t.Errorf("changed file: swapped=%v err=%v", swapped, err)
+	}
+	if v.Get() != "v2" {
+		t.Errorf("Get() = %q, want v2", v.Get())
+	}
+
+	// A broken edit keeps the last good value and is reported once
+	writeFile(t, path, "invalid")
+	if _, err := v.Check(); err == nil {
+		t.Error("expected the reload error")
+	}
+	if v.Get() != "v2" {
+		t.Errorf("Get() = %q, want the previous value", v.Get())
+	}
+	if swapped, err := v.Check(); swapped || err != nil {
+		t.Errorf("expected no retry until the file changes: swapped=%v err=%v", swapped, err)
+	}
+
+	writeFile(t, path, "v3")
+	if swapped, _ := v.Check(); !swapped || v.Get() != "v3" {
+		t.Errorf("expected the fixed file to load, got %q", v.Get())
+	}
+}
+
+func TestValue_InitialLoadError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Watch(func() (int, []string, error) { return 0, nil, errors.New("boom") }, Options{})
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("expected the load error, got %v", err)
+	}
+}
+
+func TestValue_Polls(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	writeFile(t, path, "v1")
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	reloaded := make(chan struct{}, 1)
+	v := watchText(t, path, Options{
+		Interval: time.Second,
+		Clock:    fake,
+		OnReload: func() { reloaded <- struct{}{} },
+	})
+
+	writeFile(t, path, "v2")
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+	if v.Get() != "v2" {
+		t.Errorf("Get() = %q, want v2", v.Get())
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "greet.tmpl"), "Hello {{.}}")
+	prompts, err := Templates(filepath.Join(dir, "*.tmpl"), nil, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Templates: %v", err)
+	}
+	defer prompts.Close() //nolint:errcheck
+
+	render := func(name string) string {
+		var b strings.Builder
+		if err := prompts.Get().ExecuteTemplate(&b, name, "Ada"); err != nil {
+			return "error: " + err.Error()
+		}
+		return b.String()
+	}
+	if got := render("greet.tmpl"); got != "Hello Ada" {
+		t.Errorf("greet = %q", got)
+	}
+
+	// Added templates are picked up
+	writeFile(t, filepath.Join(dir, "bye.tmpl"), "Bye {{.}}")
+	if swapped, err := prompts.Check(); !swapped || err != nil {
+		t.Fatalf("expected reload: swapped=%v err=%v", swapped, err)
+	}
+	if got := render("bye.tmpl"); got != "Bye Ada" {
+		t.Errorf("bye = %q", got)
+	}
+
+	// A template that fails to parse keeps the previous set
+	writeFile(t, filepath.Join(dir, "greet.tmpl"), "Hello {{.")
+	if _, err := prompts.Check(); err == nil {
+		t.Error("expected a parse error")
+	}
+	if got := render("greet.tmpl"); got != "Hello Ada" {
+		t.Errorf("greet after broken edit = %q", got)
+	}
+}
@@ -0,0 +1,41 @@
+package reload
+
+import (
+	"fmt"
+	"path/filepath"
+	"text/template"
+)
+
+// Templates loads the prompt templates matching a glob pattern, such as
+// "prompts/*.tmpl", and reloads them when a file changes or a matching file
+// is added or removed. Templates are named by file name; funcs, which may be
+// nil, are available to every template.
+//
+// A template that fails to parse keeps the previous set in service.
+func Templates(pattern string, funcs template.FuncMap, opts Options) (*Value[*template.Template], error) {
+	return Watch(func() (*template.Template, []string, error) {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("no templates match %q", pattern)
+		}
+
+		tmpl := template.New(filepath.Base(matches[0])).Funcs(funcs)
+		if tmpl, err = tmpl.ParseFiles(matches...); err != nil {
+			return nil, nil, err
+		}
+
+		// Watch the directories too, to notice new and removed templates
+		files := append([]string{}, matches...)
+		dirs := map[string]bool{}
+		for _, m := range matches {
+			if dir := filepath.Dir(m); !dirs[dir] {
+				dirs[dir] = true
+				files = append(files, dir)
+			}
+		}
+		return tmpl, files, nil
+	}, opts)
+}
@@ -0,0 +1,790 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: generation.proto
+
+package goaiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_generation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Message) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type GenerationSettings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Temperature   *float64               `protobuf:"fixed64,1,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	MaxTokens     *int32                 `protobuf:"varint,2,opt,name=max_tokens,json=maxTokens,proto3,oneof" json:"max_tokens,omitempty"`
+	TopP          *float64               `protobuf:"fixed64,3,opt,name=top_p,json=topP,proto3,oneof" json:"top_p,omitempty"`
+	TopK          *int32                 `protobuf:"varint,4,opt,name=top_k,json=topK,proto3,oneof" json:"top_k,omitempty"`
+	StopSequences []string               `protobuf:"bytes,5,rep,name=stop_sequences,json=stopSequences,proto3" json:"stop_sequences,omitempty"`
+	Seed          *int32                 `protobuf:"varint,6,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerationSettings) Reset() {
+	*x = GenerationSettings{}
+	mi := &file_generation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerationSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerationSettings) ProtoMessage() {}
+
+func (x *GenerationSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerationSettings.ProtoReflect.Descriptor instead.
+func (*GenerationSettings) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GenerationSettings) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *GenerationSettings) GetMaxTokens() int32 {
+	if x != nil && x.MaxTokens != nil {
+		return *x.MaxTokens
+	}
+	return 0
+}
+
+func (x *GenerationSettings) GetTopP() float64 {
+	if x != nil && x.TopP != nil {
+		return *x.TopP
+	}
+	return 0
+}
+
+func (x *GenerationSettings) GetTopK() int32 {
+	if x != nil && x.TopK != nil {
+		return *x.TopK
+	}
+	return 0
+}
+
+func (x *GenerationSettings) GetStopSequences() []string {
+	if x != nil {
+		return x.StopSequences
+	}
+	return nil
+}
+
+func (x *GenerationSettings) GetSeed() int32 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+type GenerateTextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	System        string                 `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+	Prompt        string                 `protobuf:"bytes,3,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Messages      []*Message             `protobuf:"bytes,4,rep,name=messages,proto3" json:"messages,omitempty"`
+	Settings      *GenerationSettings    `protobuf:"bytes,5,opt,name=settings,proto3" json:"settings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateTextRequest) Reset() {
+	*x = GenerateTextRequest{}
+	mi := &file_generation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateTextRequest) ProtoMessage() {}
+
+func (x *GenerateTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateTextRequest.ProtoReflect.Descriptor instead.
+func (*GenerateTextRequest) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GenerateTextRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GenerateTextRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+func (x *GenerateTextRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *GenerateTextRequest) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GenerateTextRequest) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type Usage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InputTokens   int64                  `protobuf:"varint,1,opt,name=input_tokens,json=inputTokens,proto3" json:"input_tokens,omitempty"`
+	OutputTokens  int64                  `protobuf:"varint,2,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	TotalTokens   int64                  `protobuf:"varint,3,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	mi := &file_generation_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Usage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Usage) ProtoMessage() {}
+
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Usage) GetInputTokens() int64 {
+	if x != nil {
+		return x.InputTokens
+	}
+	return 0
+}
+
+func (x *Usage) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *Usage) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+type GenerateTextResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	FinishReason  string                 `protobuf:"bytes,2,opt,name=finish_reason,json=finishReason,proto3" json:"finish_reason,omitempty"`
+	Usage         *Usage                 `protobuf:"bytes,3,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateTextResponse) Reset() {
+	*x = GenerateTextResponse{}
+	mi := &file_generation_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateTextResponse) ProtoMessage() {}
+
+func (x *GenerateTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateTextResponse.ProtoReflect.Descriptor instead.
+func (*GenerateTextResponse) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateTextResponse) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *GenerateTextResponse) GetFinishReason() string {
+	if x != nil {
+		return x.FinishReason
+	}
+	return ""
+}
+
+func (x *GenerateTextResponse) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type StreamTextResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*StreamTextResponse_TextDelta
+	//	*StreamTextResponse_ReasoningDelta
+	//	*StreamTextResponse_Finish
+	Event         isStreamTextResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTextResponse) Reset() {
+	*x = StreamTextResponse{}
+	mi := &file_generation_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTextResponse) ProtoMessage() {}
+
+func (x *StreamTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTextResponse.ProtoReflect.Descriptor instead.
+func (*StreamTextResponse) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamTextResponse) GetEvent() isStreamTextResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *StreamTextResponse) GetTextDelta() string {
+	if x != nil {
+		if x, ok := x.Event.(*StreamTextResponse_TextDelta); ok {
+			return x.TextDelta
+		}
+	}
+	return ""
+}
+
+func (x *StreamTextResponse) GetReasoningDelta() string {
+	if x != nil {
+		if x, ok := x.Event.(*StreamTextResponse_ReasoningDelta); ok {
+			return x.ReasoningDelta
+		}
+	}
+	return ""
+}
+
+func (x *StreamTextResponse) GetFinish() *Finish {
+	if x != nil {
+		if x, ok := x.Event.(*StreamTextResponse_Finish); ok {
+			return x.Finish
+		}
+	}
+	return nil
+}
+
+type isStreamTextResponse_Event interface {
+	isStreamTextResponse_Event()
+}
+
+type StreamTextResponse_TextDelta struct {
+	TextDelta string `protobuf:"bytes,1,opt,name=text_delta,json=textDelta,proto3,oneof"`
+}
+
+type StreamTextResponse_ReasoningDelta struct {
+	ReasoningDelta string `protobuf:"bytes,2,opt,name=reasoning_delta,json=reasoningDelta,proto3,oneof"`
+}
+
+type StreamTextResponse_Finish struct {
+	Finish *Finish `protobuf:"bytes,3,opt,name=finish,proto3,oneof"`
+}
+
+func (*StreamTextResponse_TextDelta) isStreamTextResponse_Event() {}
+
+func (*StreamTextResponse_ReasoningDelta) isStreamTextResponse_Event() {}
+
+func (*StreamTextResponse_Finish) isStreamTextResponse_Event() {}
+
+type Finish struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FinishReason  string                 `protobuf:"bytes,1,opt,name=finish_reason,json=finishReason,proto3" json:"finish_reason,omitempty"`
+	Usage         *Usage                 `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Finish) Reset() {
+	*x = Finish{}
+	mi := &file_generation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Finish) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Finish) ProtoMessage() {}
+
+func (x *Finish) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Finish.ProtoReflect.Descriptor instead.
+func (*Finish) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Finish) GetFinishReason() string {
+	if x != nil {
+		return x.FinishReason
+	}
+	return ""
+}
+
+func (x *Finish) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type GenerateObjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	System        string                 `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+	Prompt        string                 `protobuf:"bytes,3,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Messages      []*Message             `protobuf:"bytes,4,rep,name=messages,proto3" json:"messages,omitempty"`
+	Settings      *GenerationSettings    `protobuf:"bytes,5,opt,name=settings,proto3" json:"settings,omitempty"`
+	Schema        *structpb.Struct       `protobuf:"bytes,6,opt,name=schema,proto3" json:"schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateObjectRequest) Reset() {
+	*x = GenerateObjectRequest{}
+	mi := &file_generation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateObjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateObjectRequest) ProtoMessage() {}
+
+func (x *GenerateObjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateObjectRequest.ProtoReflect.Descriptor instead.
+func (*GenerateObjectRequest) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GenerateObjectRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GenerateObjectRequest) GetSystem() string {
+	if x != nil {
+		return x.System
+	}
+	return ""
+}
+
+func (x *GenerateObjectRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *GenerateObjectRequest) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GenerateObjectRequest) GetSettings() *GenerationSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *GenerateObjectRequest) GetSchema() *structpb.Struct {
+	if x != nil {
+		return x.Schema
+	}
+	return nil
+}
+
+type GenerateObjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Object        *structpb.Value        `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	FinishReason  string                 `protobuf:"bytes,2,opt,name=finish_reason,json=finishReason,proto3" json:"finish_reason,omitempty"`
+	Usage         *Usage                 `protobuf:"bytes,3,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateObjectResponse) Reset() {
+	*x = GenerateObjectResponse{}
+	mi := &file_generation_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateObjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateObjectResponse) ProtoMessage() {}
+
+func (x *GenerateObjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_generation_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateObjectResponse.ProtoReflect.Descriptor instead.
+func (*GenerateObjectResponse) Descriptor() ([]byte, []int) {
+	return file_generation_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GenerateObjectResponse) GetObject() *structpb.Value {
+	if x != nil {
+		return x.Object
+	}
+	return nil
+}
+
+func (x *GenerateObjectResponse) GetFinishReason() string {
+	if x != nil {
+		return x.FinishReason
+	}
+	return ""
+}
+
+func (x *GenerateObjectResponse) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+var File_generation_proto protoreflect.FileDescriptor
+
+const file_generation_proto_rawDesc = "" +
+	"\n" +
+	"\x10generation.proto\x12\agoai.v1\x1a\x1cgoogle/protobuf/struct.proto\"7\n" +
+	"\aMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"\x8f\x02\n" +
+	"\x12GenerationSettings\x12%\n" +
+	"\vtemperature\x18\x01 \x01(\x01H\x00R\vtemperature\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"max_tokens\x18\x02 \x01(\x05H\x01R\tmaxTokens\x88\x01\x01\x12\x18\n" +
+	"\x05top_p\x18\x03 \x01(\x01H\x02R\x04topP\x88\x01\x01\x12\x18\n" +
+	"\x05top_k\x18\x04 \x01(\x05H\x03R\x04topK\x88\x01\x01\x12%\n" +
+	"\x0estop_sequences\x18\x05 \x03(\tR\rstopSequences\x12\x17\n" +
+	"\x04seed\x18\x06 \x01(\x05H\x04R\x04seed\x88\x01\x01B\x0e\n" +
+	"\f_temperatureB\r\n" +
+	"\v_max_tokensB\b\n" +
+	"\x06_top_pB\b\n" +
+	"\x06_top_kB\a\n" +
+	"\x05_seed\"\xc2\x01\n" +
+	"\x13GenerateTextRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x16\n" +
+	"\x06system\x18\x02 \x01(\tR\x06system\x12\x16\n" +
+	"\x06prompt\x18\x03 \x01(\tR\x06prompt\x12,\n" +
+	"\bmessages\x18\x04 \x03(\v2\x10.goai.v1.MessageR\bmessages\x127\n" +
+	"\bsettings\x18\x05 \x01(\v2\x1b.goai.v1.GenerationSettingsR\bsettings\"r\n" +
+	"\x05Usage\x12!\n" +
+	"\finput_tokens\x18\x01 \x01(\x03R\vinputTokens\x12#\n" +
+	"\routput_tokens\x18\x02 \x01(\x03R\foutputTokens\x12!\n" +
+	"\ftotal_tokens\x18\x03 \x01(\x03R\vtotalTokens\"u\n" +
+	"\x14GenerateTextResponse\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12#\n" +
+	"\rfinish_reason\x18\x02 \x01(\tR\ffinishReason\x12$\n" +
+	"\x05usage\x18\x03 \x01(\v2\x0e.goai.v1.UsageR\x05usage\"\x94\x01\n" +
+	"\x12StreamTextResponse\x12\x1f\n" +
+	"\n" +
+	"text_delta\x18\x01 \x01(\tH\x00R\ttextDelta\x12)\n" +
+	"\x0freasoning_delta\x18\x02 \x01(\tH\x00R\x0ereasoningDelta\x12)\n" +
+	"\x06finish\x18\x03 \x01(\v2\x0f.goai.v1.FinishH\x00R\x06finishB\a\n" +
+	"\x05event\"S\n" +
+	"\x06Finish\x12#\n" +
+	"\rfinish_reason\x18\x01 \x01(\tR\ffinishReason\x12$\n" +
+	"\x05usage\x18\x02 \x01(\v2\x0e.goai.v1.UsageR\x05usage\"\xf5\x01\n" +
+	"\x15GenerateObjectRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x16\n" +
+	"\x06system\x18\x02 \x01(\tR\x06system\x12\x16\n" +
+	"\x06prompt\x18\x03 \x01(\tR\x06prompt\x12,\n" +
+	"\bmessages\x18\x04 \x03(\v2\x10.goai.v1.MessageR\bmessages\x127\n" +
+	"\bsettings\x18\x05 \x01(\v2\x1b.goai.v1.GenerationSettingsR\bsettings\x12/\n" +
+	"\x06schema\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x06schema\"\x93\x01\n" +
+	"\x16GenerateObjectResponse\x12.\n" +
+	"\x06object\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\x06object\x12#\n" +
+	"\rfinish_reason\x18\x02 \x01(\tR\ffinishReason\x12$\n" +
+	"\x05usage\x18\x03 \x01(\v2\x0e.goai.v1.UsageR\x05usage2\xfe\x01\n" +
+	"\x11GenerationService\x12K\n" +
+	"\fGenerateText\x12\x1c.goai.v1.GenerateTextRequest\x1a\x1d.goai.v1.GenerateTextResponse\x12I\n" +
+	"\n" +
+	"StreamText\x12\x1c.goai.v1.GenerateTextRequest\x1a\x1b.goai.v1.StreamTextResponse0\x01\x12Q\n" +
+	"\x0eGenerateObject\x12\x1e.goai.v1.GenerateObjectRequest\x1a\x1f.goai.v1.GenerateObjectResponseB7Z5github.com/digitallysavvy/go-ai/pkg/rpc/goaiv1;goaiv1b\x06proto3"
+
+var (
+	file_generation_proto_rawDescOnce sync.Once
+	file_generation_proto_rawDescData []byte
+)
+
+func file_generation_proto_rawDescGZIP() []byte {
+	file_generation_proto_rawDescOnce.Do(func() {
+		file_generation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_generation_proto_rawDesc), len(file_generation_proto_rawDesc)))
+	})
+	return file_generation_proto_rawDescData
+}
+
+var file_generation_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_generation_proto_goTypes = []any{
+	(*Message)(nil),                // 0: goai.v1.Message
+	(*GenerationSettings)(nil),     // 1: goai.v1.GenerationSettings
+	(*GenerateTextRequest)(nil),    // 2: goai.v1.GenerateTextRequest
+	(*Usage)(nil),                  // 3: goai.v1.Usage
+	(*GenerateTextResponse)(nil),   // 4: goai.v1.GenerateTextResponse
+	(*StreamTextResponse)(nil),     // 5: goai.v1.StreamTextResponse
+	(*Finish)(nil),                 // 6: goai.v1.Finish
+	(*GenerateObjectRequest)(nil),  // 7: goai.v1.GenerateObjectRequest
+	(*GenerateObjectResponse)(nil), // 8: goai.v1.GenerateObjectResponse
+	(*structpb.Struct)(nil),        // 9: google.protobuf.Struct
+	(*structpb.Value)(nil),         // 10: google.protobuf.Value
+}
+var file_generation_proto_depIdxs = []int32{
+	0,  // 0: goai.v1.GenerateTextRequest.messages:type_name -> goai.v1.Message
+	1,  // 1: goai.v1.GenerateTextRequest.settings:type_name -> goai.v1.GenerationSettings
+	3,  // 2: goai.v1.GenerateTextResponse.usage:type_name -> goai.v1.Usage
+	6,  // 3: goai.v1.StreamTextResponse.finish:type_name -> goai.v1.Finish
+	3,  // 4: goai.v1.Finish.usage:type_name -> goai.v1.Usage
+	0,  // 5: goai.v1.GenerateObjectRequest.messages:type_name -> goai.v1.Message
+	1,  // 6: goai.v1.GenerateObjectRequest.settings:type_name -> goai.v1.GenerationSettings
+	9,  // 7: goai.v1.GenerateObjectRequest.schema:type_name -> google.protobuf.Struct
+	10, // 8: goai.v1.GenerateObjectResponse.object:type_name -> google.protobuf.Value
+	3,  // 9: goai.v1.GenerateObjectResponse.usage:type_name -> goai.v1.Usage
+	2,  // 10: goai.v1.GenerationService.GenerateText:input_type -> goai.v1.GenerateTextRequest
+	2,  // 11: goai.v1.GenerationService.StreamText:input_type -> goai.v1.GenerateTextRequest
+	7,  // 12: goai.v1.GenerationService.GenerateObject:input_type -> goai.v1.GenerateObjectRequest
+	4,  // 13: goai.v1.GenerationService.GenerateText:output_type -> goai.v1.GenerateTextResponse
+	5,  // 14: goai.v1.GenerationService.StreamText:output_type -> goai.v1.StreamTextResponse
+	8,  // 15: goai.v1.GenerationService.GenerateObject:output_type -> goai.v1.GenerateObjectResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_generation_proto_init() }
+func file_generation_proto_init() {
+	if File_generation_proto != nil {
+		return
+	}
+	file_generation_proto_msgTypes[1].OneofWrappers = []any{}
+	file_generation_proto_msgTypes[5].OneofWrappers = []any{
+		(*StreamTextResponse_TextDelta)(nil),
+		(*StreamTextResponse_ReasoningDelta)(nil),
+		(*StreamTextResponse_Finish)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_generation_proto_rawDesc), len(file_generation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_generation_proto_goTypes,
+		DependencyIndexes: file_generation_proto_depIdxs,
+		MessageInfos:      file_generation_proto_msgTypes,
+	}.Build()
+	File_generation_proto = out.File
+	file_generation_proto_goTypes = nil
+	file_generation_proto_depIdxs = nil
+}
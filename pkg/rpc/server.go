@@ -0,0 +1,304 @@
+// Package rpc serves go-ai generation over gRPC.
+//
+// The service definition lives in goaiv1/generation.proto; clients in other
+// languages can generate stubs from it. Go clients use the generated
+// goaiv1.NewGenerationServiceClient directly.
+//
+// Regenerate the Go code after editing the .proto with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	    generation.proto
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/rpc/goaiv1"
+	"github.com/digitallysavvy/go-ai/pkg/schema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServerOptions configures a Server
+type ServerOptions struct {
+	// Resolver looks up request models. Defaults to the global registry
+	// (registry.ResolveLanguageModel), so model strings look like "openai:gpt-4o".
+	Resolver registry.ModelResolver
+
+	// DefaultModel is used when a request does not name a model (optional)
+	DefaultModel string
+
+	// AllowedModels restricts the models callers may request. Empty allows
+	// any model the resolver knows.
+	AllowedModels []string
+}
+
+// Server implements goaiv1.GenerationServiceServer on top of the ai package
+//
+// Example:
+//
+//	registry.RegisterProvider("openai", openai.New(openai.Config{APIKey: key}))
+//
+//	s := grpc.NewServer()
+//	rpc.Register(s, rpc.NewServer(rpc.ServerOptions{DefaultModel: "openai:gpt-4o-mini"}))
+//	lis, _ := net.Listen("tcp", ":50051")
+//	s.Serve(lis)
+type Server struct {
+	goaiv1.UnimplementedGenerationServiceServer
+
+	opts    ServerOptions
+	resolve registry.ModelResolver
+}
+
+// NewServer creates a Server
+func NewServer(opts ServerOptions) *Server {
+	if opts.Resolver == nil {
+		opts.Resolver = registry.ResolveLanguageModel
+	}
+	return &Server{opts: opts, resolve: registry.AllowModels(opts.Resolver, opts.AllowedModels)}
+}
+
+// Register registers srv with a gRPC server
+func Register(s grpc.ServiceRegistrar, srv *Server) {
+	goaiv1.RegisterGenerationServiceServer(s, srv)
+}
+
+// GenerateText implements goaiv1.GenerationServiceServer
+func (s *Server) GenerateText(ctx context.Context, req *goaiv1.GenerateTextRequest) (*goaiv1.GenerateTextResponse, error) {
+	model, messages, err := s.prepare(req.GetModel(), req.GetPrompt(), req.GetMessages())
+	if err != nil {
+		return nil, err
+	}
+	settings := settingsOf(req.GetSettings())
+	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+		Model:         model,
+		System:        req.GetSystem(),
+		Prompt:        req.GetPrompt(),
+		Messages:      messages,
+		Temperature:   settings.Temperature,
+		MaxTokens:     intPtr(settings.MaxTokens),
+		TopP:          settings.TopP,
+		TopK:          intPtr(settings.TopK),
+		StopSequences: settings.GetStopSequences(),
+		Seed:          intPtr(settings.Seed),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &goaiv1.GenerateTextResponse{
+		Text:         result.Text,
+		FinishReason: string(result.FinishReason),
+		Usage:        toUsage(result.Usage),
+	}, nil
+}
+
+// StreamText implements goaiv1.GenerationServiceServer
+func (s *Server) StreamText(req *goaiv1.GenerateTextRequest, stream grpc.ServerStreamingServer[goaiv1.StreamTextResponse]) error {
+	ctx := stream.Context()
+	model, messages, err := s.prepare(req.GetModel(), req.GetPrompt(), req.GetMessages())
+	if err != nil {
+		return err
+	}
+	settings := settingsOf(req.GetSettings())
+	result, err := ai.StreamText(ctx, ai.StreamTextOptions{
+		Model:         model,
+		System:        req.GetSystem(),
+		Prompt:        req.GetPrompt(),
+		Messages:      messages,
+		Temperature:   settings.Temperature,
+		MaxTokens:     intPtr(settings.MaxTokens),
+		TopP:          settings.TopP,
+		TopK:          intPtr(settings.TopK),
+		StopSequences: settings.GetStopSequences(),
+		Seed:          intPtr(settings.Seed),
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	defer result.Close()
+
+	finish := &goaiv1.Finish{}
+	var usage types.Usage
+	for {
+		chunk, err := result.Stream().Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return toStatus(err)
+		}
+
+		var event *goaiv1.StreamTextResponse
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			if chunk.Text != "" {
+				event = &goaiv1.StreamTextResponse{Event: &goaiv1.StreamTextResponse_TextDelta{TextDelta: chunk.Text}}
+			}
+		case provider.ChunkTypeReasoning:
+			if chunk.Reasoning != "" {
+				event = &goaiv1.StreamTextResponse{Event: &goaiv1.StreamTextResponse_ReasoningDelta{ReasoningDelta: chunk.Reasoning}}
+			}
+		case provider.ChunkTypeUsage:
+			if chunk.Usage != nil {
+				usage = *chunk.Usage
+			}
+		case provider.ChunkTypeFinish:
+			finish.FinishReason = string(chunk.FinishReason)
+			if chunk.Usage != nil {
+				usage = *chunk.Usage
+			}
+		case provider.ChunkTypeError:
+			return status.Errorf(codes.Internal, "stream error: %s", chunk.Text)
+		}
+		if event != nil {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	finish.Usage = toUsage(usage)
+	return stream.Send(&goaiv1.StreamTextResponse{Event: &goaiv1.StreamTextResponse_Finish{Finish: finish}})
+}
+
+// GenerateObject implements goaiv1.GenerationServiceServer
+func (s *Server) GenerateObject(ctx context.Context, req *goaiv1.GenerateObjectRequest) (*goaiv1.GenerateObjectResponse, error) {
+	if req.GetSchema() == nil {
+		return nil, status.Error(codes.InvalidArgument, "schema is required")
+	}
+	model, messages, err := s.prepare(req.GetModel(), req.GetPrompt(), req.GetMessages())
+	if err != nil {
+		return nil, err
+	}
+	settings := settingsOf(req.GetSettings())
+	result, err := ai.GenerateObject(ctx, ai.GenerateObjectOptions{
+		Model:       model,
+		System:      req.GetSystem(),
+		Prompt:      req.GetPrompt(),
+		Messages:    messages,
+		Schema:      schema.NewSimpleJSONSchema(req.GetSchema().AsMap()),
+		Temperature: settings.Temperature,
+		MaxTokens:   intPtr(settings.MaxTokens),
+		TopP:        settings.TopP,
+		Seed:        intPtr(settings.Seed),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	object, err := structpb.NewValue(result.Object)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode object: %v", err)
+	}
+	return &goaiv1.GenerateObjectResponse{
+		Object:       object,
+		FinishReason: string(result.FinishReason),
+		Usage:        toUsage(result.Usage),
+	}, nil
+}
+
+// prepare resolves the model and converts the request messages
+func (s *Server) prepare(name, prompt string, in []*goaiv1.Message) (provider.LanguageModel, []types.Message, error) {
+	if name == "" {
+		name = s.opts.DefaultModel
+	}
+	if name == "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "model is required")
+	}
+	model, err := s.resolve(name)
+	if errors.Is(err, registry.ErrModelNotAllowed) {
+		return nil, nil, status.Errorf(codes.PermissionDenied, "model %q is not allowed", name)
+	}
+	if err != nil {
+		return nil, nil, status.Errorf(codes.NotFound, "unknown model %q: %v", name, err)
+	}
+
+	if prompt != "" && len(in) > 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "set either prompt or messages, not both")
+	}
+	if prompt == "" && len(in) == 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "prompt or messages is required")
+	}
+	messages := make([]types.Message, 0, len(in))
+	for i, m := range in {
+		role := types.MessageRole(m.GetRole())
+		switch role {
+		case types.RoleSystem, types.RoleUser, types.RoleAssistant:
+		default:
+			return nil, nil, status.Errorf(codes.InvalidArgument, "message %d: unsupported role %q", i, m.GetRole())
+		}
+		messages = append(messages, types.Message{
+			Role:    role,
+			Content: []types.ContentPart{types.TextContent{Text: m.GetContent()}},
+		})
+	}
+	return model, messages, nil
+}
+
+// toStatus maps generation errors onto gRPC status codes
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case providererrors.IsRateLimitError(err):
+		code = codes.ResourceExhausted
+	case providererrors.IsValidationError(err):
+		code = codes.InvalidArgument
+	default:
+		var provErr *providererrors.ProviderError
+		if errors.As(err, &provErr) {
+			switch {
+			case provErr.StatusCode == 429:
+				code = codes.ResourceExhausted
+			case provErr.StatusCode == 400 || provErr.StatusCode == 422:
+				code = codes.InvalidArgument
+			case provErr.StatusCode == 404:
+				code = codes.NotFound
+			case provErr.StatusCode == 0 || provErr.StatusCode >= 500:
+				code = codes.Unavailable
+			}
+		}
+	}
+	return status.Error(code, err.Error())
+}
+
+// settingsOf returns s, or empty settings when the request has none
+func settingsOf(s *goaiv1.GenerationSettings) *goaiv1.GenerationSettings {
+	if s == nil {
+		return &goaiv1.GenerationSettings{}
+	}
+	return s
+}
+
+func toUsage(u types.Usage) *goaiv1.Usage {
+	return &goaiv1.Usage{
+		InputTokens:  u.GetInputTokens(),
+		OutputTokens: u.GetOutputTokens(),
+		TotalTokens:  u.GetTotalTokens(),
+	}
+}
+
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+var _ goaiv1.GenerationServiceServer = (*Server)(nil)
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/rpc/goaiv1"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// startServer serves srv over an in-memory listener and returns a client
+func startServer(t *testing.T, srv *Server) goaiv1.GenerationServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	Register(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return goaiv1.NewGenerationServiceClient(conn)
+}
+
+func resolverFor(model provider.LanguageModel) registry.ModelResolver {
+	return func(name string) (provider.LanguageModel, error) {
+		if name != "mock:model" {
+			return nil, errors.New("not registered")
+		}
+		return model, nil
+	}
+}
+
+func TestServer_GenerateText(t *testing.T) {
+	t.Parallel()
+
+	var gotOpts *provider.GenerateOptions
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			gotOpts = opts
+			return &types.GenerateResult{
+				Text:         "hello",
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{InputTokens: int64Ptr(3), OutputTokens: int64Ptr(1), TotalTokens: int64Ptr(4)},
+			}, nil
+		},
+	}
+	client := startServer(t, NewServer(ServerOptions{Resolver: resolverFor(model), DefaultModel: "mock:model"}))
+
+	temp := 0.2
+	resp, err := client.GenerateText(context.Background(), &goaiv1.GenerateTextRequest{
+		Messages: []*goaiv1.Message{{Role: "user", Content: "hi"}},
+		Settings: &goaiv1.GenerationSettings{Temperature: &temp},
+	})
+	if err != nil {
+		t.Fatalf("GenerateText: %v", err)
+	}
+	if resp.Text != "hello" || resp.FinishReason != "stop" || resp.Usage.TotalTokens != 4 {
+		t.Errorf("unexpected response: %v", resp)
+	}
+	if gotOpts.Temperature == nil || *gotOpts.Temperature != 0.2 {
+		t.Errorf("temperature not forwarded: %v", gotOpts.Temperature)
+	}
+}
+
+func TestServer_StreamText(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeReasoning, Reasoning: "thinking"},
+				{Type: provider.ChunkTypeText, Text: "Hel"},
+				{Type: provider.ChunkTypeText, Text: "lo"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop, Usage: &types.Usage{TotalTokens: int64Ptr(7)}},
+			}), nil
+		},
+	}
+	client := startServer(t, NewServer(ServerOptions{Resolver: resolverFor(model)}))
+
+	stream, err := client.StreamText(context.Background(), &goaiv1.GenerateTextRequest{Model: "mock:model", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("StreamText: %v", err)
+	}
+	var text, reasoning string
+	var finish *goaiv1.Finish
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		text += event.GetTextDelta()
+		reasoning += event.GetReasoningDelta()
+		if f := event.GetFinish(); f != nil {
+			finish = f
+		}
+	}
+	if text != "Hello" || reasoning != "thinking" {
+		t.Errorf("text = %q, reasoning = %q", text, reasoning)
+	}
+	if finish == nil || finish.FinishReason != "stop" || finish.Usage.TotalTokens != 7 {
+		t.Errorf("finish = %v", finish)
+	}
+}
+
+func TestServer_GenerateObject(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		StructuredSupport: true,
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Text: `{"name":"Ada","age":36}`, FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	client := startServer(t, NewServer(ServerOptions{Resolver: resolverFor(model)}))
+
+	schema, _ := structpb.NewStruct(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"name": map[string]any{"type": "string"}, "age": map[string]any{"type": "number"}},
+	})
+	resp, err := client.GenerateObject(context.Background(), &goaiv1.GenerateObjectRequest{
+		Model:  "mock:model",
+		Prompt: "Describe Ada Lovelace",
+		Schema: schema,
+	})
+	if err != nil {
+		t.Fatalf("GenerateObject: %v", err)
+	}
+	obj := resp.Object.GetStructValue().AsMap()
+	if obj["name"] != "Ada" || obj["age"] != float64(36) {
+		t.Errorf("object = %v", obj)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return nil, providererrors.NewProviderError("mock", 429, "rate_limit", "slow down", nil)
+		},
+	}
+	client := startServer(t, NewServer(ServerOptions{Resolver: resolverFor(model), AllowedModels: []string{"mock:model"}}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *goaiv1.GenerateTextRequest
+		code codes.Code
+	}{
+		{"missing model", &goaiv1.GenerateTextRequest{Prompt: "hi"}, codes.InvalidArgument},
+		{"disallowed model", &goaiv1.GenerateTextRequest{Model: "other:model", Prompt: "hi"}, codes.PermissionDenied},
+		{"missing prompt", &goaiv1.GenerateTextRequest{Model: "mock:model"}, codes.InvalidArgument},
+		{"bad role", &goaiv1.GenerateTextRequest{Model: "mock:model", Messages: []*goaiv1.Message{{Role: "tool"}}}, codes.InvalidArgument},
+		{"rate limited", &goaiv1.GenerateTextRequest{Model: "mock:model", Prompt: "hi"}, codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		_, err := client.GenerateText(ctx, tt.req)
+		if got := status.Code(err); got != tt.code {
+			t.Errorf("%s: code = %v, want %v (%v)", tt.name, got, tt.code, err)
+		}
+	}
+}
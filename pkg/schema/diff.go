@@ -0,0 +1,257 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a difference between two schema versions
+type ChangeKind string
+
+// Change kinds reported by Diff
+const (
+	ChangeFieldAdded       ChangeKind = "field-added"
+	ChangeFieldRemoved     ChangeKind = "field-removed"
+	ChangeNowRequired      ChangeKind = "now-required"
+	ChangeNowOptional      ChangeKind = "now-optional"
+	ChangeTypeChanged      ChangeKind = "type-changed"
+	ChangeEnumValueAdded   ChangeKind = "enum-value-added"
+	ChangeEnumValueRemoved ChangeKind = "enum-value-removed"
+)
+
+// Change is one difference between two versions of an output schema
+type Change struct {
+	// Path locates the field, e.g. "$.customer.email" or "$.items[].sku"
+	Path string
+
+	// Kind classifies the change
+	Kind ChangeKind
+
+	// Breaking reports whether consumers of the old output may fail on
+	// output valid under the new schema
+	Breaking bool
+
+	// Detail describes the change for humans
+	Detail string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Detail)
+}
+
+// Diff compares two versions of an output schema from the point of view of
+// code consuming the output. A change is breaking when output valid under
+// newSchema could be rejected or misread by code written against oldSchema:
+// removing a required field, making a required field optional, changing or
+// widening a type, or adding enum values. Adding fields, making optional
+// fields required, narrowing types and removing enum values are compatible.
+//
+// Diff follows "properties", "required", "type", "enum" and "items";
+// composition keywords such as "anyOf" and "$ref" are not compared.
+// Changes are sorted by path.
+func Diff(oldSchema, newSchema map[string]interface{}) []Change {
+	var changes []Change
+	diffNode("$", oldSchema, newSchema, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// BreakingChanges returns the breaking changes in changes
+func BreakingChanges(changes []Change) []Change {
+	var breaking []Change
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// IncompatibleError reports breaking changes between schema versions
+type IncompatibleError struct {
+	Changes []Change
+}
+
+func (e *IncompatibleError) Error() string {
+	parts := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		parts[i] = c.String()
+	}
+	return fmt.Sprintf("incompatible schema change: %s", strings.Join(parts, "; "))
+}
+
+// CheckCompatible returns an *IncompatibleError listing the breaking
+// changes from oldSchema to newSchema, or nil if there are none
+func CheckCompatible(oldSchema, newSchema map[string]interface{}) error {
+	if breaking := BreakingChanges(Diff(oldSchema, newSchema)); len(breaking) > 0 {
+		return &IncompatibleError{Changes: breaking}
+	}
+	return nil
+}
+
+func diffNode(path string, oldNode, newNode map[string]interface{}, changes *[]Change) {
+	add := func(kind ChangeKind, breaking bool, format string, args ...interface{}) {
+		*changes = append(*changes, Change{Path: path, Kind: kind, Breaking: breaking, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	oldTypes, newTypes := schemaTypes(oldNode), schemaTypes(newNode)
+	if !sameSet(oldTypes, newTypes) {
+		add(ChangeTypeChanged, !narrows(oldTypes, newTypes), "type changed from %s to %s", describeTypes(oldTypes), describeTypes(newTypes))
+	}
+
+	oldEnum, newEnum := enumValues(oldNode), enumValues(newNode)
+	switch {
+	case oldEnum != nil && newEnum == nil:
+		add(ChangeEnumValueAdded, true, "enum restriction removed")
+	case newEnum != nil:
+		for _, v := range sortedKeys(newEnum) {
+			if oldEnum != nil && !oldEnum[v] {
+				add(ChangeEnumValueAdded, true, "enum value %s added", v)
+			}
+		}
+		for _, v := range sortedKeys(oldEnum) {
+			if !newEnum[v] {
+				add(ChangeEnumValueRemoved, false, "enum value %s removed", v)
+			}
+		}
+	}
+
+	oldProps, newProps := properties(oldNode), properties(newNode)
+	oldRequired, newRequired := requiredSet(oldNode), requiredSet(newNode)
+	for _, name := range sortedKeys(oldProps) {
+		child := path + "." + name
+		newProp, ok := newProps[name]
+		if !ok {
+			if oldRequired[name] {
+				*changes = append(*changes, Change{Path: child, Kind: ChangeFieldRemoved, Breaking: true, Detail: "required field removed"})
+			} else {
+				*changes = append(*changes, Change{Path: child, Kind: ChangeFieldRemoved, Detail: "optional field removed"})
+			}
+			continue
+		}
+		switch {
+		case oldRequired[name] && !newRequired[name]:
+			*changes = append(*changes, Change{Path: child, Kind: ChangeNowOptional, Breaking: true, Detail: "field is no longer required"})
+		case !oldRequired[name] && newRequired[name]:
+			*changes = append(*changes, Change{Path: child, Kind: ChangeNowRequired, Detail: "field is now required"})
+		}
+		diffNode(child, asMap(oldProps[name]), asMap(newProp), changes)
+	}
+	for _, name := range sortedKeys(newProps) {
+		if _, ok := oldProps[name]; !ok {
+			*changes = append(*changes, Change{Path: path + "." + name, Kind: ChangeFieldAdded, Detail: "field added"})
+		}
+	}
+
+	if oldItems, newItems := asMap(oldNode["items"]), asMap(newNode["items"]); oldItems != nil || newItems != nil {
+		diffNode(path+"[]", oldItems, newItems, changes)
+	}
+}
+
+// schemaTypes returns a node's "type" as a set; nil means any type
+func schemaTypes(node map[string]interface{}) map[string]bool {
+	switch t := node["type"].(type) {
+	case string:
+		return map[string]bool{t: true}
+	case []interface{}:
+		set := make(map[string]bool, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				set[s] = true
+			}
+		}
+		return set
+	case []string:
+		set := make(map[string]bool, len(t))
+		for _, s := range t {
+			set[s] = true
+		}
+		return set
+	}
+	return nil
+}
+
+// narrows reports whether every type allowed by newTypes was allowed by
+// oldTypes. Integers are numbers.
+func narrows(oldTypes, newTypes map[string]bool) bool {
+	if oldTypes == nil {
+		return true
+	}
+	if newTypes == nil {
+		return false
+	}
+	for t := range newTypes {
+		if !oldTypes[t] && !(t == "integer" && oldTypes["number"]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(a, b map[string]bool) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func describeTypes(types map[string]bool) string {
+	if types == nil {
+		return "any"
+	}
+	return strings.Join(sortedKeys(types), "|")
+}
+
+// enumValues returns a node's enum values in Go syntax, so values of
+// different JSON types stay distinct
+func enumValues(node map[string]interface{}) map[string]bool {
+	values, ok := node["enum"].([]interface{})
+	if !ok {
+		if strs, ok := node["enum"].([]string); ok {
+			values = make([]interface{}, len(strs))
+			for i, s := range strs {
+				values[i] = s
+			}
+		} else {
+			return nil
+		}
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[fmt.Sprintf("%#v", v)] = true
+	}
+	return set
+}
+
+func properties(node map[string]interface{}) map[string]interface{} {
+	props, _ := node["properties"].(map[string]interface{})
+	return props
+}
+
+func requiredSet(node map[string]interface{}) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range RequiredProperties(node) {
+		set[name] = true
+	}
+	return set
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	oldSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": "string"},
+			"score":  map[string]interface{}{"type": "number"},
+			"status": map[string]interface{}{"type": "string", "enum": []interface{}{"open", "closed"}},
+			"note":   map[string]interface{}{"type": "string"},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		},
+		"required": []interface{}{"id", "score", "status"},
+	}
+	newSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": []interface{}{"string", "null"}},
+			"score":  map[string]interface{}{"type": "integer"},
+			"status": map[string]interface{}{"type": "string", "enum": []interface{}{"open", "pending"}},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "number"},
+			},
+			"owner": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"id", "status", "owner"},
+	}
+
+	got := map[string]Change{}
+	for _, c := range Diff(oldSchema, newSchema) {
+		got[c.Path+" "+string(c.Kind)] = c
+	}
+	want := map[string]bool{
+		"$.id type-changed":           true,
+		"$.score now-optional":        true,
+		"$.score type-changed":        false,
+		"$.status enum-value-added":   true,
+		"$.status enum-value-removed": false,
+		"$.note field-removed":        false,
+		"$.tags[] type-changed":       true,
+		"$.owner field-added":         false,
+	}
+	for key, breaking := range want {
+		c, ok := got[key]
+		if !ok {
+			t.Errorf("expected change %q, got %v", key, got)
+			continue
+		}
+		if c.Breaking != breaking {
+			t.Errorf("%s: expected breaking=%v", key, breaking)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d changes, got %v", len(want), got)
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	t.Parallel()
+
+	v1 := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"label": map[string]interface{}{"type": "string"}},
+		"required":   []interface{}{"label"},
+	}
+	if err := CheckCompatible(v1, v1); err != nil {
+		t.Errorf("expected identical schemas to be compatible, got %v", err)
+	}
+
+	v2 := map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	var incompatible *IncompatibleError
+	if err := CheckCompatible(v1, v2); !errors.As(err, &incompatible) || len(incompatible.Changes) != 1 || incompatible.Changes[0].Kind != ChangeFieldRemoved {
+		t.Errorf("expected the removed required field, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is one way a value fails a JSON schema
+type ValidationError struct {
+	// Pointer locates the failing value as an RFC 6901 JSON pointer, e.g.
+	// "/items/0/sku"; "" is the whole value
+	Pointer string
+
+	// Keyword is the schema keyword that failed, e.g. "required" or "type"
+	Keyword string
+
+	// Message describes the failure
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Message)
+}
+
+// ValidationErrors lists every way a value fails a JSON schema
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, v := range e {
+		parts[i] = v.Error()
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// ValidateValue checks value against a JSON schema and returns every
+// failure, or nil. Go values are compared by their JSON encoding.
+//
+// Supported keywords: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf,
+// anyOf and oneOf. Others, including $ref and format, are ignored.
+func ValidateValue(jsonSchema map[string]interface{}, value interface{}) ValidationErrors {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ValidationErrors{{Keyword: "type", Message: fmt.Sprintf("value is not JSON: %v", err)}}
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return ValidationErrors{{Keyword: "type", Message: fmt.Sprintf("value is not JSON: %v", err)}}
+	}
+	var errs ValidationErrors
+	validateNode(jsonSchema, normalized, "", &errs)
+	return errs
+}
+
+func validateNode(s map[string]interface{}, value interface{}, pointer string, errs *ValidationErrors) {
+	if s == nil {
+		return
+	}
+	fail := func(keyword, format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(s); types != nil && !matchesType(types, value) {
+		fail("type", "expected %s, got %s", describeTypes(types), jsonType(value))
+		return
+	}
+	if enum, ok := list(s["enum"]); ok && !containsValue(enum, value) {
+		fail("enum", "value %s is not one of %s", encode(value), encode(enum))
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, value) {
+		fail("const", "value must be %s", encode(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		props := properties(s)
+		for _, name := range sortedKeys(requiredSet(s)) {
+			if _, ok := v[name]; !ok {
+				fail("required", "missing required property %q", name)
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			child := pointer + "/" + EscapePointer(name)
+			if prop, ok := props[name]; ok {
+				validateNode(asMap(prop), v[name], child, errs)
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*errs = append(*errs, ValidationError{Pointer: child, Keyword: "additionalProperties", Message: "property is not allowed"})
+				}
+			case map[string]interface{}:
+				validateNode(extra, v[name], child, errs)
+			}
+		}
+	case []interface{}:
+		if n, ok := number(s["minItems"]); ok && float64(len(v)) < n {
+			fail("minItems", "expected at least %v items, got %d", n, len(v))
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(v)) > n {
+			fail("maxItems", "expected at most %v items, got %d", n, len(v))
+		}
+		if items := asMap(s["items"]); items != nil {
+			for i, item := range v {
+				validateNode(items, item, pointer+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(s["minLength"]); ok && length < n {
+			fail("minLength", "expected at least %v characters, got %v", n, length)
+		}
+		if n, ok := number(s["maxLength"]); ok && length > n {
+			fail("maxLength", "expected at most %v characters, got %v", n, length)
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("pattern", "value %q does not match pattern %q", v, pattern)
+			}
+		}
+	case float64:
+		if n, ok := number(s["minimum"]); ok && v < n {
+			fail("minimum", "value %v is less than %v", v, n)
+		}
+		if n, ok := number(s["maximum"]); ok && v > n {
+			fail("maximum", "value %v is greater than %v", v, n)
+		}
+		if n, ok := number(s["exclusiveMinimum"]); ok && v <= n {
+			fail("exclusiveMinimum", "value %v must be greater than %v", v, n)
+		}
+		if n, ok := number(s["exclusiveMaximum"]); ok && v >= n {
+			fail("exclusiveMaximum", "value %v must be less than %v", v, n)
+		}
+	}
+
+	if all, ok := list(s["allOf"]); ok {
+		for _, sub := range all {
+			validateNode(asMap(sub), value, pointer, errs)
+		}
+	}
+	if anyOf, ok := list(s["anyOf"]); ok && countMatches(anyOf, value) == 0 {
+		fail("anyOf", "value does not match any allowed schema")
+	}
+	if oneOf, ok := list(s["oneOf"]); ok {
+		if n := countMatches(oneOf, value); n != 1 {
+			fail("oneOf", "value matches %d schemas, expected exactly one", n)
+		}
+	}
+}
+
+func countMatches(schemas []interface{}, value interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		var errs ValidationErrors
+		validateNode(asMap(sub), value, "", &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func matchesType(types map[string]bool, value interface{}) bool {
+	t := jsonType(value)
+	if types[t] {
+		return true
+	}
+	if t == "number" && types["integer"] {
+		f := value.(float64)
+		return f == math.Trunc(f)
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if jsonEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares a schema literal with a decoded value by their JSON
+// meaning, so 1 and 1.0 are equal
+func jsonEqual(literal, value interface{}) bool {
+	data, err := json.Marshal(literal)
+	if err != nil {
+		return false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(decoded, value)
+}
+
+// list returns a schema keyword's array, which Go-built schemas may hold
+// as a typed slice
+func list(v interface{}) ([]interface{}, bool) {
+	switch l := v.(type) {
+	case []interface{}:
+		return l, true
+	case nil:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParsePointer splits an RFC 6901 JSON pointer into unescaped tokens. The
+// empty pointer, which refers to the whole value, has no tokens.
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// EscapePointer escapes a property name for use as a JSON pointer token
+func EscapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// RequiredProperties returns the names in a schema node's "required" list,
+// in order. Both []string and decoded []interface{} lists are accepted.
+func RequiredProperties(node map[string]interface{}) []string {
+	switch r := node["required"].(type) {
+	case []string:
+		return r
+	case []interface{}:
+		names := make([]string, 0, len(r))
+		for _, v := range r {
+			if s, ok := v.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
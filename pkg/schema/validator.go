@@ -30,10 +30,12 @@ func NewJSONSchema(schema map[string]interface{}) *JSONSchemaValidator {
 	return &JSONSchemaValidator{schema: schema}
 }
 
-// Validate validates data against the JSON Schema
+// Validate validates data against the JSON Schema. Failures are returned
+// as ValidationErrors, locating each problem with a JSON pointer.
 func (v *JSONSchemaValidator) Validate(data interface{}) error {
-	// TODO: Implement JSON Schema validation using github.com/santhosh-tekuri/jsonschema
-	// For now, return nil (will be implemented in Phase 2)
+	if errs := ValidateValue(v.schema, data); len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
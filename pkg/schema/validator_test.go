@@ -61,13 +61,89 @@ func TestJSONSchemaValidator_Validate(t *testing.T) {
 
 	validator := NewJSONSchema(schema)
 
-	// Validate returns nil for now (placeholder implementation)
 	err := validator.Validate(map[string]interface{}{"name": "John"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
 
+func TestJSONSchemaValidator_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	validator := NewJSONSchema(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"id", "items"},
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": "integer", "minimum": 1},
+			"status": map[string]interface{}{"enum": []string{"open", "closed"}},
+			"items": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"items": map[string]interface{}{
+					"type":                 "object",
+					"required":             []string{"sku"},
+					"additionalProperties": false,
+					"properties": map[string]interface{}{
+						"sku": map[string]interface{}{"type": "string", "pattern": "^[A-Z]+-[0-9]+$"},
+					},
+				},
+			},
+		},
+	})
+
+	type item struct {
+		SKU   string `json:"sku"`
+		Price int    `json:"price,omitempty"`
+	}
+	err := validator.Validate(map[string]interface{}{
+		"id":     1.5,
+		"status": "pending",
+		"items":  []item{{SKU: "AB-1"}, {SKU: "bad", Price: 3}},
+	})
+
+	violations, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %T: %v", err, err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Pointer+" "+v.Keyword)
+	}
+	want := []string{"/id type", "/items/1/price additionalProperties", "/items/1/sku pattern", "/status enum"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+
+	if err := validator.Validate(map[string]interface{}{"items": []interface{}{}}); err == nil ||
+		err.Error() != `schema validation failed: (root): missing required property "id"; /items: expected at least 1 items, got 0` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateValue_Composition(t *testing.T) {
+	t.Parallel()
+
+	s := map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "number", "maximum": 10},
+		},
+	}
+	if errs := ValidateValue(s, "x"); errs != nil {
+		t.Errorf("string should match: %v", errs)
+	}
+	if errs := ValidateValue(s, 11); len(errs) != 1 || errs[0].Keyword != "anyOf" {
+		t.Errorf("11 should fail anyOf: %v", errs)
+	}
+	if errs := ValidateValue(map[string]interface{}{"const": 3}, 3.0); errs != nil {
+		t.Errorf("const compares JSON values: %v", errs)
+	}
+	if errs := ValidateValue(map[string]interface{}{"properties": map[string]interface{}{"a/b": map[string]interface{}{"type": "boolean"}}},
+		map[string]interface{}{"a/b": 1}); len(errs) != 1 || errs[0].Pointer != "/a~1b" {
+		t.Errorf("pointer not escaped: %v", errs)
+	}
+}
+
 func TestNewStructSchema(t *testing.T) {
 	t.Parallel()
 
@@ -376,3 +452,36 @@ func TestStructValidator_NestedStruct(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestParsePointer(t *testing.T) {
+	t.Parallel()
+
+	tokens, err := ParsePointer("/a~1b/c~0d/0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"a/b", "c~d", "0"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %v, want %v", tokens, want)
+	}
+	if tokens, err := ParsePointer(""); err != nil || tokens != nil {
+		t.Errorf("expected no tokens for the root pointer, got %v, %v", tokens, err)
+	}
+	if _, err := ParsePointer("a/b"); err == nil {
+		t.Error("expected an error for a pointer without a leading slash")
+	}
+	if got := EscapePointer("a/b~c"); got != "a~1b~0c" {
+		t.Errorf("EscapePointer = %q", got)
+	}
+}
+
+func TestRequiredProperties(t *testing.T) {
+	t.Parallel()
+
+	decoded := map[string]interface{}{"required": []interface{}{"b", "a", 1}}
+	if got := RequiredProperties(decoded); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("RequiredProperties = %v", got)
+	}
+	if got := RequiredProperties(map[string]interface{}{"required": []string{"x"}}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("RequiredProperties = %v", got)
+	}
+}
@@ -0,0 +1,312 @@
+// Package sentence splits streamed text into complete sentences, so
+// text-to-speech can start speaking the first sentence while the model is
+// still writing the rest.
+//
+// A Segmenter finds sentence boundaries across text deltas, using the
+// punctuation and abbreviations of the configured language. Stream applies
+// a Segmenter to a model stream and adds a latency bound, so speech never
+// waits long on a sentence the model is slow to finish:
+//
+//	stream, _ := model.DoStream(ctx, opts)
+//	sentences := sentence.NewStream(stream, sentence.Options{Language: "en", MaxLatency: 800 * time.Millisecond})
+//	for {
+//		event, err := sentences.Next()
+//		if err != nil {
+//			break
+//		}
+//		if event.Chunk == nil {
+//			tts.Speak(strings.TrimSpace(event.Text))
+//		}
+//	}
+package sentence
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+// Options configures sentence segmentation
+type Options struct {
+	// Language is a BCP 47 tag such as "en" or "de-AT" selecting punctuation
+	// and abbreviations (default: "en"). Unknown languages use the English
+	// rules plus the sentence-ending marks of all supported scripts.
+	Language string
+
+	// MinLength merges sentences shorter than this many characters into the
+	// next one, avoiding choppy speech for replies like "Sure." (default: 0)
+	MinLength int
+
+	// MaxLength splits text without a sentence boundary once it grows past
+	// this many characters, at a clause break or word boundary (default: 250)
+	MaxLength int
+
+	// MaxLatency releases held text at a word boundary when no sentence has
+	// completed for this long (Stream only; default: 0, disabled)
+	MaxLatency time.Duration
+
+	// Clock drives the MaxLatency timer (default: system clock)
+	Clock clock.Clock
+}
+
+// language holds the segmentation rules for one language
+type language struct {
+	// spaced marks end a sentence when followed by whitespace
+	spaced string
+	// unspaced marks end a sentence on their own, as in CJK scripts
+	unspaced string
+	// abbreviations are lowercase words that a period does not end
+	abbreviations map[string]bool
+	// ordinals reports whether "3." is an ordinal number, as in German
+	ordinals bool
+}
+
+const (
+	commonSpaced   = ".!?…‼⁇⁈⁉"
+	commonUnspaced = "。！？｡"
+)
+
+var languages = map[string]language{
+	"en": {abbreviations: words("mr mrs ms dr prof sr jr st vs etc e.g i.e approx dept est inc ltd co corp jan feb mar apr jun jul aug sep sept oct nov dec no vol fig mt ft")},
+	"de": {abbreviations: words("hr fr dr prof bzw ca usw z.b d.h u.a vgl evtl ggf inkl nr str bspw jh mio mrd"), ordinals: true},
+	"fr": {abbreviations: words("m mme mlle dr pr etc p.ex cf av bd st ste env janv févr avr juil sept oct nov déc")},
+	"es": {abbreviations: words("sr sra srta dr dra ud uds etc p.ej pág av ej aprox núm tel")},
+	"it": {abbreviations: words("sig sigg dott prof ecc es pag tel ing avv")},
+	"pt": {abbreviations: words("sr sra dr dra etc ex pág av prof tel")},
+	"nl": {abbreviations: words("dhr mevr dr prof bijv enz nr o.a d.w.z m.b.t"), ordinals: true},
+	"ru": {abbreviations: words("г гг т.е т.д т.п др стр им ул")},
+	"el": {spaced: ";\u037e"},
+	"hi": {unspaced: "।॥"},
+	"ar": {spaced: "؟", unspaced: "۔"},
+	"fa": {spaced: "؟"},
+	"ur": {spaced: "؟", unspaced: "۔"},
+	"zh": {},
+	"ja": {},
+	"ko": {},
+}
+
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+// lookupLanguage returns the rules for a BCP 47 tag
+func lookupLanguage(tag string) language {
+	primary := strings.ToLower(tag)
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	if primary == "" {
+		primary = "en"
+	}
+	lang, ok := languages[primary]
+	if !ok {
+		lang = languages["en"]
+		for _, l := range languages {
+			lang.spaced += l.spaced
+			lang.unspaced += l.unspaced
+		}
+	}
+	lang.spaced += commonSpaced
+	lang.unspaced += commonUnspaced
+	return lang
+}
+
+// closers may follow a sentence-ending mark and belong to the sentence
+const closers = `"'”’»)]}」』）`
+
+// clauseBreaks are where MaxLength prefers to split a long sentence
+const clauseBreaks = ",;:—–、，；："
+
+// Segmenter splits text deltas into sentences. Segments keep the whitespace
+// that follows them, so concatenating every segment reproduces the input;
+// trim them before synthesis. A Segmenter is not safe for concurrent use.
+type Segmenter struct {
+	lang      language
+	minLength int
+	maxLength int
+	buf       string
+}
+
+// NewSegmenter creates a Segmenter with the given options
+func NewSegmenter(opts Options) *Segmenter {
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = 250
+	}
+	return &Segmenter{lang: lookupLanguage(opts.Language), minLength: opts.MinLength, maxLength: opts.MaxLength}
+}
+
+// Write adds a text delta and returns the sentences it completes
+func (s *Segmenter) Write(text string) []string {
+	s.buf += text
+	return s.segments(false)
+}
+
+// Flush returns all held text, ending the current sentence
+func (s *Segmenter) Flush() []string {
+	return s.segments(true)
+}
+
+// FlushWords releases held text up to its last word boundary, for callers
+// that cannot wait for the sentence to end. It returns "" if the held text
+// is a single unfinished word.
+func (s *Segmenter) FlushWords() string {
+	end := strings.LastIndexFunc(s.buf, unicode.IsSpace)
+	if end < 0 || strings.TrimSpace(s.buf[:end]) == "" {
+		return ""
+	}
+	_, size := utf8.DecodeRuneInString(s.buf[end:])
+	text := s.buf[:end+size]
+	s.buf = s.buf[end+size:]
+	return text
+}
+
+// Pending returns the text held back waiting for a sentence boundary
+func (s *Segmenter) Pending() string {
+	return s.buf
+}
+
+func (s *Segmenter) segments(final bool) []string {
+	var out []string
+	for {
+		end := s.boundary(final)
+		if end <= 0 {
+			break
+		}
+		out = append(out, s.buf[:end])
+		s.buf = s.buf[end:]
+	}
+	if final && s.buf != "" {
+		out = append(out, s.buf)
+		s.buf = ""
+	}
+	return out
+}
+
+// boundary returns the end of the first sentence in the buffer, including
+// the whitespace after it, or 0 if no sentence is complete yet
+func (s *Segmenter) boundary(final bool) int {
+	buf := s.buf
+	for i := 0; i < len(buf); {
+		r, size := utf8.DecodeRuneInString(buf[i:])
+		next := i + size
+		cut, wait := 0, false
+
+		switch {
+		case r == '\n':
+			cut = skipSpace(buf, next)
+		case strings.ContainsRune(s.lang.unspaced, r):
+			j := skipAny(buf, next, s.lang.unspaced+closers)
+			if j == len(buf) && !final {
+				wait = true
+			} else {
+				cut = skipSpace(buf, j)
+			}
+		case strings.ContainsRune(s.lang.spaced, r):
+			j := skipAny(buf, next, s.lang.spaced+closers)
+			next = j
+			if j == len(buf) {
+				if final {
+					cut = j
+				} else {
+					wait = true
+				}
+				break
+			}
+			if follow, _ := utf8.DecodeRuneInString(buf[j:]); !unicode.IsSpace(follow) {
+				break // "3.14", "example.com"
+			}
+			if r == '.' && s.abbreviation(buf[:i]) {
+				break
+			}
+			k := skipSpace(buf, j)
+			if r == '.' && k == len(buf) && !final {
+				wait = true // a lowercase word next would mean "etc. and"
+				break
+			}
+			if r == '.' && k < len(buf) {
+				if word, _ := utf8.DecodeRuneInString(buf[k:]); unicode.IsLower(word) {
+					break
+				}
+			}
+			cut = k
+		}
+
+		if wait {
+			break
+		}
+		if cut > 0 && (s.minLength <= 0 || utf8.RuneCountInString(strings.TrimSpace(buf[:cut])) >= s.minLength) {
+			return cut
+		}
+		i = next
+	}
+	return s.longCut()
+}
+
+// abbreviation reports whether the word before a period is an abbreviation,
+// an initial or (in some languages) an ordinal number
+func (s *Segmenter) abbreviation(before string) bool {
+	word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+	word = strings.ToLower(strings.TrimLeft(word, `"'“‘«([{`))
+	if word == "" {
+		return false
+	}
+	if s.lang.abbreviations[word] || strings.Contains(word, ".") {
+		return true
+	}
+	if s.lang.ordinals && strings.Trim(word, "0123456789") == "" {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return size == len(word) && unicode.IsLetter(r)
+}
+
+// longCut splits a buffer longer than maxLength at the last clause break,
+// or else the last word boundary, within the limit
+func (s *Segmenter) longCut() int {
+	if utf8.RuneCountInString(s.buf) <= s.maxLength {
+		return 0
+	}
+	limit := 0
+	for n := 0; n < s.maxLength; n++ {
+		_, size := utf8.DecodeRuneInString(s.buf[limit:])
+		limit += size
+	}
+	head := s.buf[:limit]
+	if i := strings.LastIndexAny(head, clauseBreaks); i > 0 {
+		_, size := utf8.DecodeRuneInString(head[i:])
+		return skipSpace(s.buf, i+size)
+	}
+	if i := strings.LastIndexFunc(head, unicode.IsSpace); i > 0 {
+		return skipSpace(s.buf, i)
+	}
+	return limit
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func skipAny(s string, i int, chars string) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !strings.ContainsRune(chars, r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,163 @@
+package sentence
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// segment feeds deltas to a Segmenter and returns every segment
+func segment(opts Options, deltas ...string) []string {
+	s := NewSegmenter(opts)
+	var out []string
+	for _, d := range deltas {
+		out = append(out, s.Write(d)...)
+	}
+	return append(out, s.Flush()...)
+}
+
+func TestSegmenter_Boundaries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   Options
+		deltas []string
+		want   []string
+	}{
+		{"simple", Options{}, []string{"Hello there. How are ", "you? Fine!"}, []string{"Hello there. ", "How are you? ", "Fine!"}},
+		{"split mark", Options{}, []string{"It costs 3", ".", "50 today. Ok"}, []string{"It costs 3.50 today. ", "Ok"}},
+		{"abbreviation", Options{}, []string{"Dr. Smith met Mr. J. Doe at 5 p.m. today. Bye"}, []string{"Dr. Smith met Mr. J. Doe at 5 p.m. today. ", "Bye"}},
+		{"lowercase continues", Options{}, []string{"Apples, pears etc. are fruit. Yes"}, []string{"Apples, pears etc. are fruit. ", "Yes"}},
+		{"closing quote", Options{}, []string{`He said "stop!" `, "Then left."}, []string{`He said "stop!" `, "Then left."}},
+		{"ellipsis and line", Options{}, []string{"Well... Maybe\n- one\n"}, []string{"Well... ", "Maybe\n", "- one\n"}},
+		{"german ordinal", Options{Language: "de-DE"}, []string{"Am 3. Oktober z.B. feiern wir. Gut"}, []string{"Am 3. Oktober z.B. feiern wir. ", "Gut"}},
+		{"chinese", Options{Language: "zh"}, []string{"你好。今天", "天气很好！"}, []string{"你好。", "今天天气很好！"}},
+		{"hindi", Options{Language: "hi"}, []string{"नमस्ते। आप कैसे हैं"}, []string{"नमस्ते। ", "आप कैसे हैं"}},
+		{"min length", Options{MinLength: 10}, []string{"Sure. Here is the answer. Ok."}, []string{"Sure. Here is the answer. ", "Ok."}},
+		{"max length", Options{MaxLength: 20}, []string{"one two three, four five six seven"}, []string{"one two three, ", "four five six seven"}},
+	}
+	for _, tt := range tests {
+		got := segment(tt.opts, tt.deltas...)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if strings.Join(got, "") != strings.Join(tt.deltas, "") {
+			t.Errorf("%s: segments do not reproduce the input", tt.name)
+		}
+	}
+}
+
+func TestSegmenter_WaitsForLookahead(t *testing.T) {
+	t.Parallel()
+
+	s := NewSegmenter(Options{})
+	if got := s.Write("Take the pill. "); got != nil {
+		t.Fatalf("released %q before the next word", got)
+	}
+	if got := s.Write("Then rest"); !reflect.DeepEqual(got, []string{"Take the pill. "}) {
+		t.Errorf("got %q", got)
+	}
+	if s.Pending() != "Then rest" {
+		t.Errorf("pending = %q", s.Pending())
+	}
+}
+
+func TestStream_PassesChunksInOrder(t *testing.T) {
+	t.Parallel()
+
+	source := testutil.NewMockTextStream([]provider.StreamChunk{
+		{Type: provider.ChunkTypeTextStart, ID: "t1"},
+		{Type: provider.ChunkTypeText, ID: "t1", Text: "First one. Sec"},
+		{Type: provider.ChunkTypeText, ID: "t1", Text: "ond one"},
+		{Type: provider.ChunkTypeTextEnd, ID: "t1"},
+		{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+	})
+	stream := NewStream(source, Options{})
+	var got []string
+	for {
+		event, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if event.Chunk != nil {
+			got = append(got, string(event.Chunk.Type))
+		} else {
+			got = append(got, event.Text)
+		}
+	}
+	want := []string{string(provider.ChunkTypeTextStart), "First one. ", "Second one", string(provider.ChunkTypeTextEnd), string(provider.ChunkTypeFinish)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// chanStream is a TextStream fed by the test
+type chanStream struct {
+	chunks chan provider.StreamChunk
+}
+
+func (c *chanStream) Next() (*provider.StreamChunk, error) {
+	chunk, ok := <-c.chunks
+	if !ok {
+		return nil, io.EOF
+	}
+	return &chunk, nil
+}
+
+func (c *chanStream) Err() error   { return nil }
+func (c *chanStream) Close() error { return nil }
+
+func TestStream_MaxLatencyFlushesWords(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	source := &chanStream{chunks: make(chan provider.StreamChunk, 4)}
+	stream := NewStream(source, Options{MaxLatency: 500 * time.Millisecond, Clock: clk})
+	defer stream.Close()
+
+	source.chunks <- provider.StreamChunk{Type: provider.ChunkTypeText, ID: "t1", Text: "Let me think about th"}
+	events := make(chan *Event)
+	go func() {
+		event, err := stream.Next()
+		if err != nil {
+			t.Errorf("Next: %v", err)
+		}
+		events <- event
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(500 * time.Millisecond)
+	event := <-events
+	if event.Text != "Let me think about " || !event.Partial {
+		t.Errorf("event = %+v", event)
+	}
+
+	source.chunks <- provider.StreamChunk{Type: provider.ChunkTypeText, ID: "t1", Text: "at. Done"}
+	close(source.chunks)
+	var rest []string
+	for {
+		event, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		rest = append(rest, event.Text)
+	}
+	if !reflect.DeepEqual(rest, []string{"that. ", "Done"}) {
+		t.Errorf("rest = %q", rest)
+	}
+}
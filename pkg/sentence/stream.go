@@ -0,0 +1,184 @@
+package sentence
+
+import (
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+)
+
+// Event is one item read from a Stream: either a piece of text to speak or
+// a non-text chunk passed through from the model stream
+type Event struct {
+	// Text is a sentence with the whitespace that followed it, so
+	// concatenating every Text reproduces the model's text
+	Text string
+
+	// Partial reports text released by MaxLatency before its sentence
+	// ended
+	Partial bool
+
+	// Chunk is set for non-text chunks (tool calls, usage, finish...), which
+	// are passed through in order
+	Chunk *provider.StreamChunk
+}
+
+// Stream reads a model stream as sentences. Held text is released when the
+// text block ends, the stream finishes or the source is exhausted, and at
+// a word boundary once MaxLatency passes without a complete sentence.
+type Stream struct {
+	source     provider.TextStream
+	segmenter  *Segmenter
+	maxLatency time.Duration
+	clock      clock.Clock
+	queue      []Event
+	textID     string
+	err        error
+
+	// With MaxLatency set, a goroutine reads the source so Next can wait on
+	// the source and the latency timer together
+	reads     chan read
+	done      chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+	heldSince time.Time
+}
+
+type read struct {
+	chunk *provider.StreamChunk
+	err   error
+}
+
+// NewStream wraps a model stream
+func NewStream(source provider.TextStream, opts Options) *Stream {
+	return &Stream{
+		source:     source,
+		segmenter:  NewSegmenter(opts),
+		maxLatency: opts.MaxLatency,
+		clock:      clock.Default(opts.Clock),
+		done:       make(chan struct{}),
+	}
+}
+
+// Next returns the next event, or io.EOF when the stream is complete
+func (s *Stream) Next() (*Event, error) {
+	for len(s.queue) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		chunk, timedOut, err := s.read()
+		if timedOut {
+			if text := s.segmenter.FlushWords(); text != "" {
+				s.queue = append(s.queue, Event{Text: text, Partial: true})
+			}
+			s.heldSince = s.clock.Now()
+			continue
+		}
+		if err != nil {
+			s.err = err
+			s.queueText(s.segmenter.Flush())
+			continue
+		}
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			s.textID = chunk.ID
+			wasHeld := s.segmenter.Pending() != ""
+			s.queueText(s.segmenter.Write(chunk.Text))
+			switch {
+			case s.segmenter.Pending() == "":
+				s.heldSince = time.Time{}
+			case !wasHeld || len(s.queue) > 0:
+				s.heldSince = s.clock.Now()
+			}
+			continue
+		case provider.ChunkTypeTextEnd, provider.ChunkTypeFinish:
+			s.queueText(s.segmenter.Flush())
+			s.heldSince = time.Time{}
+		}
+		s.queue = append(s.queue, Event{Chunk: chunk})
+	}
+	event := s.queue[0]
+	s.queue = s.queue[1:]
+	return &event, nil
+}
+
+// read returns the next source chunk, or timedOut when held text has
+// waited MaxLatency
+func (s *Stream) read() (chunk *provider.StreamChunk, timedOut bool, err error) {
+	if s.maxLatency <= 0 {
+		chunk, err = s.source.Next()
+		return chunk, false, err
+	}
+	s.startOnce.Do(func() {
+		s.reads = make(chan read)
+		go s.pump()
+	})
+	if s.heldSince.IsZero() {
+		r := <-s.reads
+		return r.chunk, false, r.err
+	}
+	timer := s.clock.NewTimer(s.maxLatency - s.clock.Now().Sub(s.heldSince))
+	defer timer.Stop()
+	select {
+	case r := <-s.reads:
+		return r.chunk, false, r.err
+	case <-timer.C():
+		return nil, true, nil
+	}
+}
+
+// pump reads the source until it fails or the stream is closed
+func (s *Stream) pump() {
+	for {
+		chunk, err := s.source.Next()
+		select {
+		case s.reads <- read{chunk, err}:
+		case <-s.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Err returns the source stream's error
+func (s *Stream) Err() error {
+	return s.source.Err()
+}
+
+// Close closes the source stream
+func (s *Stream) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.source.Close()
+}
+
+func (s *Stream) queueText(texts []string) {
+	for _, text := range texts {
+		s.queue = append(s.queue, Event{Text: text})
+	}
+}
+
+// NewTextStream re-chunks a model stream so each text chunk is one
+// sentence, for consumers that read a provider.TextStream directly.
+// middleware.SentenceChunkingMiddleware applies it to a model.
+func NewTextStream(source provider.TextStream, opts Options) provider.TextStream {
+	return &textStream{Stream: NewStream(source, opts)}
+}
+
+// textStream turns a Stream's sentences back into text chunks
+type textStream struct {
+	*Stream
+}
+
+func (s *textStream) Next() (*provider.StreamChunk, error) {
+	event, err := s.Stream.Next()
+	if err != nil {
+		return nil, err
+	}
+	if event.Chunk != nil {
+		return event.Chunk, nil
+	}
+	return &provider.StreamChunk{Type: provider.ChunkTypeText, ID: s.textID, Text: event.Text}, nil
+}
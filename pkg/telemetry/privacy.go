@@ -0,0 +1,193 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PayloadMode controls how prompt, response and tool bodies are recorded
+type PayloadMode string
+
+const (
+	// PayloadFull records bodies as-is (the default)
+	PayloadFull PayloadMode = "full"
+
+	// PayloadHash replaces bodies with a SHA-256 digest, so identical
+	// prompts and responses can still be correlated without being stored
+	PayloadHash PayloadMode = "hash"
+
+	// PayloadDrop removes bodies entirely
+	PayloadDrop PayloadMode = "drop"
+)
+
+// HashPayload returns the digest recorded for payload under PayloadHash
+func HashPayload(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// RedactPayload applies mode to payload. The boolean is false when the
+// payload must not be recorded at all.
+func RedactPayload(mode PayloadMode, payload string) (string, bool) {
+	switch mode {
+	case PayloadDrop:
+		return "", false
+	case PayloadHash:
+		return HashPayload(payload), true
+	default:
+		return payload, true
+	}
+}
+
+// SamplePayloadMode decides how one operation records its bodies: PayloadFull
+// for the PayloadSampleRate share of operations, Payloads for the rest.
+func (s *Settings) SamplePayloadMode() PayloadMode {
+	if s == nil || s.Payloads == "" || s.Payloads == PayloadFull {
+		return PayloadFull
+	}
+	if s.PayloadSampleRate > 0 && rand.Float64() < s.PayloadSampleRate {
+		return PayloadFull
+	}
+	return s.Payloads
+}
+
+// AttributeAllowed reports whether an attribute key passes the allow and deny
+// lists. A pattern matches a key exactly, or as a prefix when it ends in "*"
+// (e.g. "ai.telemetry.metadata.*"). Deny wins over allow; an empty allow list
+// allows every key.
+func (s *Settings) AttributeAllowed(key string) bool {
+	if s == nil {
+		return true
+	}
+	if matchesAny(s.DenyAttributes, key) {
+		return false
+	}
+	return len(s.AllowAttributes) == 0 || matchesAny(s.AllowAttributes, key)
+}
+
+// FilterAttributes returns the attributes that pass AttributeAllowed
+func (s *Settings) FilterAttributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if s == nil || (len(s.AllowAttributes) == 0 && len(s.DenyAttributes) == 0) {
+		return attrs
+	}
+	kept := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		if s.AttributeAllowed(string(attr.Key)) {
+			kept = append(kept, attr)
+		}
+	}
+	return kept
+}
+
+func matchesAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if pattern == key {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterSpan wraps span so that SetAttributes drops attributes rejected by
+// settings' allow and deny lists. It returns span unchanged when no lists
+// are configured.
+func FilterSpan(span trace.Span, settings *Settings) trace.Span {
+	if settings == nil || (len(settings.AllowAttributes) == 0 && len(settings.DenyAttributes) == 0) {
+		return span
+	}
+	return &filteredSpan{Span: span, settings: settings}
+}
+
+type filteredSpan struct {
+	trace.Span
+	settings *Settings
+}
+
+func (f *filteredSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	f.Span.SetAttributes(f.settings.FilterAttributes(attrs...)...)
+}
+
+// payloadPolicy is the per-operation privacy decision, carried in the
+// context from FireOnStart to the operation's later events
+type payloadPolicy struct {
+	settings *Settings
+	mode     PayloadMode
+}
+
+type payloadPolicyKey struct{}
+
+func withPayloadPolicy(ctx context.Context, settings *Settings) context.Context {
+	return context.WithValue(ctx, payloadPolicyKey{}, &payloadPolicy{
+		settings: settings,
+		mode:     settings.SamplePayloadMode(),
+	})
+}
+
+func payloadPolicyFrom(ctx context.Context) *payloadPolicy {
+	if p, ok := ctx.Value(payloadPolicyKey{}).(*payloadPolicy); ok {
+		return p
+	}
+	return &payloadPolicy{mode: PayloadFull}
+}
+
+// PayloadModeFromContext returns the payload mode sampled for the operation
+// running in ctx, for integrations that record bodies themselves
+func PayloadModeFromContext(ctx context.Context) PayloadMode {
+	return payloadPolicyFrom(ctx).mode
+}
+
+// text redacts a string body
+func (p *payloadPolicy) text(payload string) string {
+	if payload == "" {
+		return ""
+	}
+	redacted, _ := RedactPayload(p.mode, payload)
+	return redacted
+}
+
+// args redacts tool arguments, hashing their JSON encoding
+func (p *payloadPolicy) args(args map[string]interface{}) map[string]interface{} {
+	switch p.mode {
+	case PayloadDrop:
+		return nil
+	case PayloadHash:
+		return map[string]interface{}{"hash": hashValue(args)}
+	default:
+		return args
+	}
+}
+
+// value redacts a tool result, hashing its JSON encoding
+func (p *payloadPolicy) value(v interface{}) interface{} {
+	switch p.mode {
+	case PayloadDrop:
+		return nil
+	case PayloadHash:
+		if v == nil {
+			return nil
+		}
+		return hashValue(v)
+	default:
+		return v
+	}
+}
+
+func hashValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return HashPayload(fmt.Sprint(v))
+	}
+	return HashPayload(string(data))
+}
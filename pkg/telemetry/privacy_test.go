@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestAttributeAllowed(t *testing.T) {
+	s := &Settings{
+		AllowAttributes: []string{"ai.*", "gen_ai.request.model"},
+		DenyAttributes:  []string{"ai.telemetry.metadata.*", "ai.prompt"},
+	}
+	cases := map[string]bool{
+		"ai.operationId":                true,
+		"ai.response.text":              true,
+		"gen_ai.request.model":          true,
+		"gen_ai.system":                 false,
+		"ai.prompt":                     false,
+		"ai.telemetry.metadata.user_id": false,
+	}
+	for key, want := range cases {
+		if got := s.AttributeAllowed(key); got != want {
+			t.Errorf("AttributeAllowed(%q) = %v, want %v", key, got, want)
+		}
+	}
+
+	kept := s.FilterAttributes(attribute.String("ai.operationId", "x"), attribute.String("ai.prompt", "secret"))
+	if len(kept) != 1 || kept[0].Key != "ai.operationId" {
+		t.Errorf("unexpected filtered attributes %v", kept)
+	}
+}
+
+func TestSamplePayloadMode(t *testing.T) {
+	if mode := (*Settings)(nil).SamplePayloadMode(); mode != PayloadFull {
+		t.Errorf("expected full payloads by default, got %q", mode)
+	}
+	if mode := (&Settings{Payloads: PayloadHash}).SamplePayloadMode(); mode != PayloadHash {
+		t.Errorf("expected hashed payloads without sampling, got %q", mode)
+	}
+	if mode := (&Settings{Payloads: PayloadDrop, PayloadSampleRate: 1}).SamplePayloadMode(); mode != PayloadFull {
+		t.Errorf("expected a sample rate of 1 to keep full payloads, got %q", mode)
+	}
+}
+
+// payloadRecorder records the bodies integrations receive
+type payloadRecorder struct {
+	NoopTelemetryIntegration
+	prompt, text, chunk string
+	args                map[string]interface{}
+	result              interface{}
+}
+
+func (p *payloadRecorder) OnStart(ctx context.Context, e TelemetryStartEvent) context.Context {
+	p.prompt = e.Prompt
+	return ctx
+}
+func (p *payloadRecorder) OnChunk(_ context.Context, e TelemetryChunkEvent) { p.chunk = e.Text }
+func (p *payloadRecorder) OnToolCallFinish(_ context.Context, e TelemetryToolCallFinishEvent) {
+	p.args, p.result = e.Args, e.Result
+}
+func (p *payloadRecorder) OnFinish(_ context.Context, e TelemetryFinishEvent) { p.text = e.Text }
+
+func TestFire_RedactsPayloads(t *testing.T) {
+	rec := &payloadRecorder{}
+	RegisterTelemetryIntegration(rec)
+	defer RegisterTelemetryIntegration(NoopTelemetryIntegration{})
+
+	fire := func(settings *Settings) {
+		ctx := FireOnStart(context.Background(), TelemetryStartEvent{Settings: settings, Prompt: "my SSN is 123"})
+		FireOnChunk(ctx, TelemetryChunkEvent{ChunkType: "text", Text: "noted"})
+		FireOnToolCallFinish(ctx, TelemetryToolCallFinishEvent{Args: map[string]interface{}{"q": "x"}, Result: "y"})
+		FireOnFinish(ctx, TelemetryFinishEvent{Text: "noted", Settings: settings})
+	}
+
+	fire(&Settings{IsEnabled: true, Payloads: PayloadHash})
+	if rec.prompt != HashPayload("my SSN is 123") || rec.text != HashPayload("noted") || rec.chunk != rec.text {
+		t.Errorf("expected hashed bodies, got prompt %q, text %q, chunk %q", rec.prompt, rec.text, rec.chunk)
+	}
+	if rec.args["hash"] != HashPayload(`{"q":"x"}`) || rec.result != HashPayload(`"y"`) {
+		t.Errorf("expected hashed tool payloads, got %v and %v", rec.args, rec.result)
+	}
+
+	fire(&Settings{IsEnabled: true, Payloads: PayloadDrop})
+	if rec.prompt != "" || rec.text != "" || rec.chunk != "" || rec.args != nil || rec.result != nil {
+		t.Errorf("expected dropped bodies, got %+v", rec)
+	}
+
+	fire(&Settings{IsEnabled: true})
+	if rec.prompt != "my SSN is 123" || rec.text != "noted" || rec.result != "y" {
+		t.Errorf("expected full bodies by default, got %+v", rec)
+	}
+}
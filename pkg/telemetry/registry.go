@@ -206,6 +206,7 @@ func (OTelTelemetryIntegration) OnStart(ctx context.Context, e TelemetryStartEve
 		spanName += "." + e.Settings.FunctionID
 	}
 	ctx, span := tracer.Start(ctx, spanName)
+	span = FilterSpan(span, e.Settings)
 	span.SetAttributes(
 		attribute.String("ai.operationId", e.OperationType),
 		attribute.String("gen_ai.system", e.ModelProvider),
@@ -236,6 +237,7 @@ func (OTelTelemetryIntegration) OnToolCallStart(ctx context.Context, e Telemetry
 	}
 	tracer := span.TracerProvider().Tracer("go-ai")
 	ctx, child := tracer.Start(ctx, "ai.toolCall."+e.ToolName)
+	child = FilterSpan(child, payloadPolicyFrom(ctx).settings)
 	child.SetAttributes(
 		attribute.String("ai.toolCall.id", e.ToolCallID),
 		attribute.String("ai.toolCall.name", e.ToolName),
@@ -245,7 +247,7 @@ func (OTelTelemetryIntegration) OnToolCallStart(ctx context.Context, e Telemetry
 
 // OnToolCallFinish ends the tool-call child span.
 func (OTelTelemetryIntegration) OnToolCallFinish(ctx context.Context, e TelemetryToolCallFinishEvent) {
-	span := trace.SpanFromContext(ctx)
+	span := FilterSpan(trace.SpanFromContext(ctx), payloadPolicyFrom(ctx).settings)
 	if !span.IsRecording() {
 		return
 	}
@@ -262,7 +264,7 @@ func (OTelTelemetryIntegration) OnStepFinish(_ context.Context, _ TelemetryStepF
 
 // OnFinish sets output attributes on the root span and ends it.
 func (OTelTelemetryIntegration) OnFinish(ctx context.Context, e TelemetryFinishEvent) {
-	span := trace.SpanFromContext(ctx)
+	span := FilterSpan(trace.SpanFromContext(ctx), e.Settings)
 	if !span.IsRecording() {
 		return
 	}
@@ -397,6 +399,11 @@ func snapshot() []TelemetryIntegration {
 // FireOnStart calls OnStart on every registered integration, threading the
 // returned context through the chain so each integration can inject spans.
 func FireOnStart(ctx context.Context, e TelemetryStartEvent) context.Context {
+	// Sample the operation's payload mode once; later events read it back
+	ctx = withPayloadPolicy(ctx, e.Settings)
+	policy := payloadPolicyFrom(ctx)
+	e.Prompt = policy.text(e.Prompt)
+	e.System = policy.text(e.System)
 	for _, i := range snapshot() {
 		ctx = i.OnStart(ctx, e)
 	}
@@ -413,6 +420,7 @@ func FireOnStepStart(ctx context.Context, e TelemetryStepStartEvent) {
 // FireOnToolCallStart calls OnToolCallStart on every registered integration,
 // threading the returned context through the chain.
 func FireOnToolCallStart(ctx context.Context, e TelemetryToolCallStartEvent) context.Context {
+	e.Args = payloadPolicyFrom(ctx).args(e.Args)
 	for _, i := range snapshot() {
 		ctx = i.OnToolCallStart(ctx, e)
 	}
@@ -421,6 +429,9 @@ func FireOnToolCallStart(ctx context.Context, e TelemetryToolCallStartEvent) con
 
 // FireOnToolCallFinish calls OnToolCallFinish on every registered integration.
 func FireOnToolCallFinish(ctx context.Context, e TelemetryToolCallFinishEvent) {
+	policy := payloadPolicyFrom(ctx)
+	e.Args = policy.args(e.Args)
+	e.Result = policy.value(e.Result)
 	for _, i := range snapshot() {
 		i.OnToolCallFinish(ctx, e)
 	}
@@ -428,6 +439,7 @@ func FireOnToolCallFinish(ctx context.Context, e TelemetryToolCallFinishEvent) {
 
 // FireOnChunk calls OnChunk on every registered integration.
 func FireOnChunk(ctx context.Context, e TelemetryChunkEvent) {
+	e.Text = payloadPolicyFrom(ctx).text(e.Text)
 	for _, i := range snapshot() {
 		i.OnChunk(ctx, e)
 	}
@@ -442,6 +454,7 @@ func FireOnStepFinish(ctx context.Context, e TelemetryStepFinishEvent) {
 
 // FireOnFinish calls OnFinish on every registered integration.
 func FireOnFinish(ctx context.Context, e TelemetryFinishEvent) {
+	e.Text = payloadPolicyFrom(ctx).text(e.Text)
 	for _, i := range snapshot() {
 		i.OnFinish(ctx, e)
 	}
@@ -32,6 +32,22 @@ type Settings struct {
 
 	// Tracer is a custom OpenTelemetry tracer. If nil, the global tracer will be used.
 	Tracer trace.Tracer
+
+	// Payloads controls how recorded prompt, response and tool bodies are
+	// stored: PayloadFull (the default), PayloadHash or PayloadDrop. It
+	// applies on top of RecordInputs and RecordOutputs.
+	Payloads PayloadMode
+
+	// PayloadSampleRate is the share of operations (0 to 1) that record full
+	// bodies even though Payloads is PayloadHash or PayloadDrop, so a sample
+	// of traffic stays debuggable.
+	PayloadSampleRate float64
+
+	// AllowAttributes, when set, limits span attributes to matching keys.
+	// DenyAttributes removes matching keys. Patterns ending in "*" match by
+	// prefix, e.g. "ai.telemetry.metadata.*".
+	AllowAttributes []string
+	DenyAttributes  []string
 }
 
 // DefaultSettings returns Settings with sensible defaults.
@@ -91,3 +107,19 @@ func (s *Settings) WithTracer(tracer trace.Tracer) *Settings {
 	copy.Tracer = tracer
 	return &copy
 }
+
+// WithPayloads returns a copy of Settings with Payloads and PayloadSampleRate set to the given values.
+func (s *Settings) WithPayloads(mode PayloadMode, sampleRate float64) *Settings {
+	copy := *s
+	copy.Payloads = mode
+	copy.PayloadSampleRate = sampleRate
+	return &copy
+}
+
+// WithAttributeFilter returns a copy of Settings with AllowAttributes and DenyAttributes set to the given lists.
+func (s *Settings) WithAttributeFilter(allow, deny []string) *Settings {
+	copy := *s
+	copy.AllowAttributes = allow
+	copy.DenyAttributes = deny
+	return &copy
+}
@@ -0,0 +1,91 @@
+package telephony
+
+import (
+	"math"
+
+	"github.com/digitallysavvy/go-ai/pkg/internal/audio"
+)
+
+// TelephonySampleRate is the sample rate of G.711 audio used by phone networks
+const TelephonySampleRate = 8000
+
+const (
+	mulawBias = 0x84
+	mulawClip = 32635
+)
+
+// MulawDecode converts 8-bit G.711 mu-law samples to 16-bit linear PCM
+func MulawDecode(data []byte) []int16 {
+	samples := make([]int16, len(data))
+	for i, u := range data {
+		u = ^u
+		exponent := (u >> 4) & 0x07
+		mantissa := int(u & 0x0F)
+		sample := (((mantissa << 3) + mulawBias) << exponent) - mulawBias
+		if u&0x80 != 0 {
+			sample = -sample
+		}
+		samples[i] = int16(sample)
+	}
+	return samples
+}
+
+// MulawEncode converts 16-bit linear PCM samples to 8-bit G.711 mu-law
+func MulawEncode(samples []int16) []byte {
+	data := make([]byte, len(samples))
+	for i, s := range samples {
+		sample := int(s)
+		sign := 0
+		if sample < 0 {
+			sample = -sample
+			sign = 0x80
+		}
+		if sample > mulawClip {
+			sample = mulawClip
+		}
+		sample += mulawBias
+		exponent := 7
+		for mask := 0x4000; sample&mask == 0 && exponent > 0; mask >>= 1 {
+			exponent--
+		}
+		mantissa := (sample >> (exponent + 3)) & 0x0F
+		data[i] = ^byte(sign | exponent<<4 | mantissa)
+	}
+	return data
+}
+
+// Resample converts mono PCM between sample rates using linear interpolation.
+// It is intended for speech, where the quality loss is inaudible on a phone line.
+func Resample(samples []int16, fromRate, toRate int) []int16 {
+	return audio.Resample(samples, fromRate, toRate)
+}
+
+// EncodeWAV wraps mono 16-bit PCM samples in a WAV container, the format most
+// transcription models accept
+func EncodeWAV(samples []int16, sampleRate int) []byte {
+	return audio.EncodeWAV(samples, sampleRate)
+}
+
+// DecodeWAV extracts 16-bit PCM samples and the sample rate from a WAV file.
+// Multi-channel audio is downmixed to mono.
+func DecodeWAV(data []byte) ([]int16, int, error) {
+	return audio.DecodeWAV(data)
+}
+
+// DecodePCM16 interprets raw little-endian 16-bit PCM bytes as samples
+func DecodePCM16(data []byte) []int16 {
+	return audio.DecodePCM16(data)
+}
+
+// rms returns the root-mean-square amplitude of samples, used as a simple
+// voice activity measure
+func rms(samples []int16) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
@@ -0,0 +1,83 @@
+package telephony
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMulawRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []int16{0, 100, -100, 1000, -1000, 12000, -12000, 32767, -32768} {
+		got := MulawDecode(MulawEncode([]int16{s}))[0]
+		diff := int(got) - int(s)
+		if diff < 0 {
+			diff = -diff
+		}
+		// mu-law quantization error grows with amplitude
+		if limit := max(int(s)/16, -int(s)/16, 8); diff > limit {
+			t.Errorf("sample %d decoded as %d", s, got)
+		}
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{0, 1, -1, 32767, -32768, 1234}
+	got, rate, err := DecodeWAV(EncodeWAV(samples, 16000))
+	if err != nil {
+		t.Fatalf("DecodeWAV failed: %v", err)
+	}
+	if rate != 16000 {
+		t.Errorf("rate = %d, want 16000", rate)
+	}
+	if !reflect.DeepEqual(got, samples) {
+		t.Errorf("samples = %v, want %v", got, samples)
+	}
+
+	if _, _, err := DecodeWAV([]byte("not audio")); err == nil {
+		t.Error("expected error for non-WAV input")
+	}
+}
+
+func TestResample(t *testing.T) {
+	t.Parallel()
+
+	in := make([]int16, 24000)
+	if got := len(Resample(in, 24000, 8000)); got != 8000 {
+		t.Errorf("resampled length = %d, want 8000", got)
+	}
+	if got := Resample(in, 8000, 8000); len(got) != len(in) {
+		t.Error("expected identical rates to be a no-op")
+	}
+}
+
+func TestJitterBuffer(t *testing.T) {
+	t.Parallel()
+
+	b := NewJitterBuffer(2)
+	b.Push(1, []byte("1"))
+	b.Push(3, []byte("3"))
+	b.Push(2, []byte("2"))
+	if got := b.Pop(); len(got) != 3 || string(got[1]) != "2" {
+		t.Fatalf("expected frames 1-3 in order, got %q", got)
+	}
+
+	// Frame 4 is lost; once more than depth frames wait, it is skipped
+	b.Push(5, []byte("5"))
+	b.Push(6, []byte("6"))
+	if got := b.Pop(); len(got) != 0 {
+		t.Fatalf("expected buffer to wait for frame 4, got %q", got)
+	}
+	b.Push(7, []byte("7"))
+	if got := b.Pop(); len(got) != 3 || string(got[0]) != "5" {
+		t.Fatalf("expected frames 5-7 after skipping the gap, got %q", got)
+	}
+
+	// Late frames are dropped
+	b.Push(4, []byte("4"))
+	if got := b.Flush(); len(got) != 0 {
+		t.Errorf("expected late frame to be dropped, got %q", got)
+	}
+}
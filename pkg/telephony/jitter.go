@@ -0,0 +1,83 @@
+package telephony
+
+import "sort"
+
+// JitterBuffer reorders media frames that arrive out of order. Frames are
+// released in sequence order; when a frame is missing and more than depth
+// later frames are waiting, the gap is skipped so playout never stalls.
+// Frames older than the last released sequence number are dropped.
+//
+// JitterBuffer is not safe for concurrent use.
+type JitterBuffer struct {
+	depth   int
+	next    int
+	started bool
+	pending map[int][]byte
+}
+
+// NewJitterBuffer creates a JitterBuffer that tolerates up to depth frames of
+// reordering
+func NewJitterBuffer(depth int) *JitterBuffer {
+	if depth < 0 {
+		depth = 0
+	}
+	return &JitterBuffer{depth: depth, pending: make(map[int][]byte)}
+}
+
+// Push adds a frame with its sequence number
+func (b *JitterBuffer) Push(seq int, frame []byte) {
+	if !b.started {
+		b.started = true
+		b.next = seq
+	}
+	if seq < b.next {
+		return // late duplicate or frame we already skipped
+	}
+	b.pending[seq] = frame
+}
+
+// Pop returns the frames that are ready for playout, in order
+func (b *JitterBuffer) Pop() [][]byte {
+	var out [][]byte
+	for len(b.pending) > 0 {
+		if frame, ok := b.pending[b.next]; ok {
+			delete(b.pending, b.next)
+			out = append(out, frame)
+			b.next++
+			continue
+		}
+		if len(b.pending) <= b.depth {
+			break
+		}
+		// Give up on the missing frame
+		b.next = b.lowestPending()
+	}
+	return out
+}
+
+// Flush returns all buffered frames in order, skipping any gaps
+func (b *JitterBuffer) Flush() [][]byte {
+	seqs := make([]int, 0, len(b.pending))
+	for seq := range b.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+	out := make([][]byte, 0, len(seqs))
+	for _, seq := range seqs {
+		out = append(out, b.pending[seq])
+		delete(b.pending, seq)
+		b.next = seq + 1
+	}
+	return out
+}
+
+// lowestPending returns the smallest buffered sequence number
+func (b *JitterBuffer) lowestPending() int {
+	lowest, first := 0, true
+	for seq := range b.pending {
+		if first || seq < lowest {
+			lowest, first = seq, false
+		}
+	}
+	return lowest
+}
@@ -0,0 +1,448 @@
+package telephony
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+)
+
+// MediaStreamConn is a message-oriented websocket connection carrying
+// Twilio media stream JSON messages. Adapt your websocket library to it,
+// e.g. for gorilla/websocket:
+//
+//	type wsConn struct{ *websocket.Conn }
+//
+//	func (c wsConn) ReadMessage() ([]byte, error) {
+//		_, data, err := c.Conn.ReadMessage()
+//		return data, err
+//	}
+//
+//	func (c wsConn) WriteMessage(data []byte) error {
+//		return c.Conn.WriteMessage(websocket.TextMessage, data)
+//	}
+//
+// Any transport that speaks the same message format (for example a media
+// bridge in front of LiveKit or another SIP provider) can be used as well.
+type MediaStreamConn interface {
+	// ReadMessage blocks until the next message arrives. It should return
+	// io.EOF (or another error) once the connection is closed.
+	ReadMessage() ([]byte, error)
+
+	// WriteMessage sends a text message. Calls are serialized by the bridge.
+	WriteMessage(data []byte) error
+}
+
+// TwilioMessage is a Twilio media stream websocket message
+type TwilioMessage struct {
+	Event          string       `json:"event"`
+	SequenceNumber string       `json:"sequenceNumber,omitempty"`
+	StreamSid      string       `json:"streamSid,omitempty"`
+	Start          *TwilioStart `json:"start,omitempty"`
+	Media          *TwilioMedia `json:"media,omitempty"`
+	Mark           *TwilioMark  `json:"mark,omitempty"`
+}
+
+// TwilioStart is the payload of the "start" event
+type TwilioStart struct {
+	StreamSid        string            `json:"streamSid"`
+	AccountSid       string            `json:"accountSid"`
+	CallSid          string            `json:"callSid"`
+	Tracks           []string          `json:"tracks,omitempty"`
+	CustomParameters map[string]string `json:"customParameters,omitempty"`
+	MediaFormat      struct {
+		Encoding   string `json:"encoding"`
+		SampleRate int    `json:"sampleRate"`
+		Channels   int    `json:"channels"`
+	} `json:"mediaFormat"`
+}
+
+// TwilioMedia is the payload of the "media" event. Payload is base64-encoded
+// 8kHz mu-law audio.
+type TwilioMedia struct {
+	Track     string `json:"track,omitempty"`
+	Chunk     string `json:"chunk,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Payload   string `json:"payload"`
+}
+
+// TwilioMark is the payload of the "mark" event. Twilio echoes a mark back
+// once the audio sent before it has finished playing.
+type TwilioMark struct {
+	Name string `json:"name"`
+}
+
+// TwilioBridgeOptions tunes utterance detection and buffering. Zero values
+// use the defaults noted on each field.
+type TwilioBridgeOptions struct {
+	// SilenceThreshold is the RMS amplitude below which a frame counts as
+	// silence (default 500)
+	SilenceThreshold float64
+
+	// SilenceDuration is how long the caller must be quiet before their
+	// utterance is considered complete (default 700ms)
+	SilenceDuration time.Duration
+
+	// MinSpeechDuration is the minimum amount of speech for an utterance to
+	// be sent to the agent; shorter blips are discarded (default 200ms)
+	MinSpeechDuration time.Duration
+
+	// BargeInDuration is how long the caller must speak over the agent
+	// before its reply is interrupted (default 300ms)
+	BargeInDuration time.Duration
+
+	// JitterDepth is the number of frames of reordering tolerated (default 5)
+	JitterDepth int
+
+	// OnStart is called when Twilio announces the stream
+	OnStart func(start TwilioStart)
+
+	// OnError is called when a turn fails or outgoing audio cannot be sent
+	OnError func(err error)
+}
+
+// TwilioBridge connects a Twilio media stream to a VoiceSession. Inbound
+// mu-law audio is de-jittered, segmented into utterances with a simple
+// energy-based voice activity detector, and handed to the session as WAV.
+// The agent's spoken reply is transcoded back to 8kHz mu-law and streamed to
+// the caller. When the caller talks over the agent, the turn is interrupted
+// and Twilio's playback buffer is cleared.
+//
+// Example:
+//
+//	http.HandleFunc("/media", func(w http.ResponseWriter, r *http.Request) {
+//		ws, _ := upgrader.Upgrade(w, r, nil)
+//		defer ws.Close()
+//		bridge, err := telephony.NewTwilioBridge(wsConn{ws}, agent.VoiceSessionConfig{
+//			Agent:       supportAgent,
+//			Transcriber: whisper,
+//			Speaker:     tts,
+//		}, nil)
+//		if err != nil {
+//			return
+//		}
+//		_ = bridge.Run(r.Context())
+//	})
+type TwilioBridge struct {
+	conn    MediaStreamConn
+	opts    TwilioBridgeOptions
+	session *agent.VoiceSession
+
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	streamSid    string
+	responding   int
+	pendingMarks int
+	marks        int
+}
+
+// NewTwilioBridge creates a bridge and the VoiceSession behind it. The
+// session is configured for WAV input and, unless SpeechFormat is already
+// set, WAV speech output; callbacks in config are still invoked.
+func NewTwilioBridge(conn MediaStreamConn, config agent.VoiceSessionConfig, opts *TwilioBridgeOptions) (*TwilioBridge, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("connection is required")
+	}
+
+	b := &TwilioBridge{conn: conn}
+	if opts != nil {
+		b.opts = *opts
+	}
+	if b.opts.SilenceThreshold <= 0 {
+		b.opts.SilenceThreshold = 500
+	}
+	if b.opts.SilenceDuration <= 0 {
+		b.opts.SilenceDuration = 700 * time.Millisecond
+	}
+	if b.opts.MinSpeechDuration <= 0 {
+		b.opts.MinSpeechDuration = 200 * time.Millisecond
+	}
+	if b.opts.BargeInDuration <= 0 {
+		b.opts.BargeInDuration = 300 * time.Millisecond
+	}
+	if b.opts.JitterDepth <= 0 {
+		b.opts.JitterDepth = 5
+	}
+
+	config.MimeType = "audio/wav"
+	if config.SpeechFormat == "" {
+		config.SpeechFormat = "wav"
+	}
+	onAudio, onInterrupt := config.OnAudio, config.OnInterrupt
+	config.OnAudio = func(ctx context.Context, seg agent.VoiceAudioSegment) {
+		if err := b.sendSpeech(seg); err != nil {
+			b.reportError(err)
+		}
+		if onAudio != nil {
+			onAudio(ctx, seg)
+		}
+	}
+	config.OnInterrupt = func(ctx context.Context) {
+		b.clearPlayback()
+		if onInterrupt != nil {
+			onInterrupt(ctx)
+		}
+	}
+
+	session, err := agent.NewVoiceSession(config)
+	if err != nil {
+		return nil, err
+	}
+	b.session = session
+	return b, nil
+}
+
+// Session returns the underlying VoiceSession, e.g. to inspect its history
+func (b *TwilioBridge) Session() *agent.VoiceSession {
+	return b.session
+}
+
+// Run processes the media stream until Twilio sends "stop", the connection
+// closes, or ctx is cancelled. Because ReadMessage blocks, callers should
+// close the connection to unblock Run promptly on cancellation. Run waits
+// for in-flight turns to unwind before returning.
+func (b *TwilioBridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var turns sync.WaitGroup
+	defer turns.Wait()
+	defer b.session.Interrupt()
+
+	jitter := NewJitterBuffer(b.opts.JitterDepth)
+	vad := &utteranceDetector{opts: &b.opts}
+
+	for ctx.Err() == nil {
+		data, err := b.conn.ReadMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var msg TwilioMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("invalid media stream message: %w", err)
+		}
+
+		switch msg.Event {
+		case "start":
+			if msg.Start == nil {
+				continue
+			}
+			b.mu.Lock()
+			b.streamSid = msg.Start.StreamSid
+			b.mu.Unlock()
+			if b.opts.OnStart != nil {
+				b.opts.OnStart(*msg.Start)
+			}
+
+		case "media":
+			if msg.Media == nil || (msg.Media.Track != "" && msg.Media.Track != "inbound") {
+				continue
+			}
+			payload, err := base64.StdEncoding.DecodeString(msg.Media.Payload)
+			if err != nil {
+				continue
+			}
+			seq, err := strconv.Atoi(msg.Media.Chunk)
+			if err != nil {
+				seq, err = strconv.Atoi(msg.SequenceNumber)
+			}
+			if err != nil {
+				// Without a sequence number the frame cannot be ordered;
+				// play out what is buffered and then the frame itself
+				for _, frame := range append(jitter.Flush(), payload) {
+					b.processFrame(ctx, vad, MulawDecode(frame), &turns)
+				}
+				continue
+			}
+			jitter.Push(seq, payload)
+			for _, frame := range jitter.Pop() {
+				b.processFrame(ctx, vad, MulawDecode(frame), &turns)
+			}
+
+		case "mark":
+			b.mu.Lock()
+			if b.pendingMarks > 0 {
+				b.pendingMarks--
+			}
+			b.mu.Unlock()
+
+		case "stop":
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// processFrame feeds one decoded frame to the utterance detector and acts on
+// the result
+func (b *TwilioBridge) processFrame(ctx context.Context, vad *utteranceDetector, samples []int16, turns *sync.WaitGroup) {
+	utterance, bargeIn := vad.add(samples, b.isSpeaking())
+	if bargeIn {
+		b.session.Interrupt()
+		b.clearPlayback()
+	}
+	if utterance == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.responding++
+	b.mu.Unlock()
+	turns.Add(1)
+	go func() {
+		defer turns.Done()
+		defer func() {
+			b.mu.Lock()
+			b.responding--
+			b.mu.Unlock()
+		}()
+		wav := EncodeWAV(utterance, TelephonySampleRate)
+		if _, err := b.session.HandleUtterance(ctx, wav); err != nil && ctx.Err() == nil {
+			b.reportError(err)
+		}
+	}()
+}
+
+// isSpeaking reports whether the agent is working on or playing a reply
+func (b *TwilioBridge) isSpeaking() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.responding > 0 || b.pendingMarks > 0
+}
+
+// sendSpeech transcodes a synthesized segment to mu-law and streams it in
+// 20ms frames, followed by a mark so playback progress can be tracked
+func (b *TwilioBridge) sendSpeech(seg agent.VoiceAudioSegment) error {
+	var samples []int16
+	var rate int
+	switch seg.MimeType {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		var err error
+		if samples, rate, err = DecodeWAV(seg.Audio); err != nil {
+			return fmt.Errorf("failed to decode speech audio: %w", err)
+		}
+	case "audio/pcm":
+		// OpenAI-style raw PCM: 24kHz, 16-bit little-endian, mono
+		samples, rate = DecodePCM16(seg.Audio), 24000
+	default:
+		return fmt.Errorf("unsupported speech format %q: configure the speech model for wav or pcm output", seg.MimeType)
+	}
+	mulaw := MulawEncode(Resample(samples, rate, TelephonySampleRate))
+
+	b.mu.Lock()
+	streamSid := b.streamSid
+	b.marks++
+	markName := fmt.Sprintf("segment-%d", b.marks)
+	b.mu.Unlock()
+
+	const frameSize = TelephonySampleRate / 50 // 20ms
+	for off := 0; off < len(mulaw); off += frameSize {
+		end := min(off+frameSize, len(mulaw))
+		if err := b.send(TwilioMessage{
+			Event:     "media",
+			StreamSid: streamSid,
+			Media:     &TwilioMedia{Payload: base64.StdEncoding.EncodeToString(mulaw[off:end])},
+		}); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	b.pendingMarks++
+	b.mu.Unlock()
+	return b.send(TwilioMessage{Event: "mark", StreamSid: streamSid, Mark: &TwilioMark{Name: markName}})
+}
+
+// clearPlayback tells Twilio to discard audio it has buffered but not played
+func (b *TwilioBridge) clearPlayback() {
+	b.mu.Lock()
+	streamSid := b.streamSid
+	b.pendingMarks = 0
+	b.mu.Unlock()
+	if err := b.send(TwilioMessage{Event: "clear", StreamSid: streamSid}); err != nil {
+		b.reportError(err)
+	}
+}
+
+// send serializes writes to the connection
+func (b *TwilioBridge) send(msg TwilioMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	return b.conn.WriteMessage(data)
+}
+
+func (b *TwilioBridge) reportError(err error) {
+	if b.opts.OnError != nil {
+		b.opts.OnError(err)
+	}
+}
+
+// utteranceDetector segments a stream of frames into utterances using frame
+// energy, and detects when the caller talks over the agent
+type utteranceDetector struct {
+	opts *TwilioBridgeOptions
+
+	buffer  []int16
+	speech  time.Duration
+	silence time.Duration
+	barge   time.Duration
+
+	// interrupted latches a barge-in until the caller goes silent or the
+	// agent stops speaking, so continued speech interrupts only once
+	interrupted bool
+}
+
+// add consumes one frame. It returns a completed utterance, if any, and
+// whether the caller has spoken over the agent long enough to interrupt it.
+func (d *utteranceDetector) add(samples []int16, agentSpeaking bool) (utterance []int16, bargeIn bool) {
+	dur := time.Duration(len(samples)) * time.Second / TelephonySampleRate
+
+	if !agentSpeaking {
+		d.barge, d.interrupted = 0, false
+	}
+	if rms(samples) >= d.opts.SilenceThreshold {
+		d.buffer = append(d.buffer, samples...)
+		d.speech += dur
+		d.silence = 0
+		if agentSpeaking && !d.interrupted {
+			d.barge += dur
+			if d.barge >= d.opts.BargeInDuration {
+				d.barge, d.interrupted = 0, true
+				bargeIn = true
+			}
+		}
+		return nil, bargeIn
+	}
+
+	d.barge, d.interrupted = 0, false
+	if len(d.buffer) == 0 {
+		return nil, false
+	}
+	d.buffer = append(d.buffer, samples...)
+	d.silence += dur
+	if d.silence < d.opts.SilenceDuration {
+		return nil, false
+	}
+
+	if d.speech >= d.opts.MinSpeechDuration {
+		utterance = d.buffer
+	}
+	d.buffer, d.speech, d.silence = nil, 0, 0
+	return utterance, false
+}
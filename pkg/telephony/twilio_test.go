@@ -0,0 +1,183 @@
+package telephony
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+// fakeConn is an in-memory MediaStreamConn
+type fakeConn struct {
+	in  chan []byte
+	out chan TwilioMessage
+}
+
+func (c *fakeConn) ReadMessage() ([]byte, error) {
+	data, ok := <-c.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return data, nil
+}
+
+func (c *fakeConn) WriteMessage(data []byte) error {
+	var msg TwilioMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	c.out <- msg
+	return nil
+}
+
+func (c *fakeConn) push(t *testing.T, msg TwilioMessage) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.in <- data
+}
+
+// frames returns n 20ms mu-law frames of a tone (or silence when amplitude is 0)
+func frames(n int, amplitude float64) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		samples := make([]int16, 160)
+		for j := range samples {
+			samples[j] = int16(amplitude * math.Sin(float64(i*160+j)*2*math.Pi*440/8000))
+		}
+		out[i] = MulawEncode(samples)
+	}
+	return out
+}
+
+func TestTwilioBridge_Conversation(t *testing.T) {
+	t.Parallel()
+
+	var transcribed []byte
+	conn := &fakeConn{in: make(chan []byte, 256), out: make(chan TwilioMessage, 256)}
+	bridge, err := NewTwilioBridge(conn, agent.VoiceSessionConfig{
+		Agent: agent.NewToolLoopAgent(agent.AgentConfig{Model: &testutil.MockLanguageModel{
+			DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+				return &types.GenerateResult{Text: "Hello caller.", FinishReason: types.FinishReasonStop}, nil
+			},
+		}}),
+		Transcriber: &testutil.MockTranscriptionModel{
+			DoTranscribeFunc: func(ctx context.Context, opts *provider.TranscriptionOptions) (*types.TranscriptionResult, error) {
+				transcribed = opts.Audio
+				return &types.TranscriptionResult{Text: "hi"}, nil
+			},
+		},
+		Speaker: &testutil.MockSpeechModel{
+			DoGenerateFunc: func(ctx context.Context, opts *provider.SpeechGenerateOptions) (*types.SpeechResult, error) {
+				if opts.OutputFormat != "wav" {
+					t.Errorf("OutputFormat = %q, want wav", opts.OutputFormat)
+				}
+				// 100ms of 24kHz audio -> 800 mu-law samples -> 5 frames
+				return &types.SpeechResult{Audio: EncodeWAV(make([]int16, 2400), 24000), MimeType: "audio/wav"}, nil
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTwilioBridge failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- bridge.Run(context.Background()) }()
+
+	conn.push(t, TwilioMessage{Event: "start", Start: &TwilioStart{StreamSid: "MZ123", CallSid: "CA123"}})
+	// 400ms of speech followed by 800ms of silence completes one utterance
+	all := append(frames(20, 8000), frames(40, 0)...)
+	for i, f := range all {
+		conn.push(t, TwilioMessage{Event: "media", Media: &TwilioMedia{
+			Track:   "inbound",
+			Chunk:   strconv.Itoa(i + 1),
+			Payload: base64.StdEncoding.EncodeToString(f),
+		}})
+	}
+
+	var media int
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-conn.out:
+			if msg.StreamSid != "MZ123" {
+				t.Errorf("StreamSid = %q, want MZ123", msg.StreamSid)
+			}
+			switch msg.Event {
+			case "media":
+				media++
+			case "mark":
+				if media != 5 {
+					t.Errorf("expected 5 media frames before mark, got %d", media)
+				}
+				conn.push(t, TwilioMessage{Event: "stop"})
+				if err := <-done; err != nil {
+					t.Errorf("Run returned error: %v", err)
+				}
+				if _, _, err := DecodeWAV(transcribed); err != nil {
+					t.Errorf("expected WAV audio to be transcribed: %v", err)
+				}
+				if len(bridge.Session().History()) != 2 {
+					t.Errorf("expected one exchange in history, got %d messages", len(bridge.Session().History()))
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for agent audio")
+		}
+	}
+}
+
+func TestUtteranceDetector_BargeIn(t *testing.T) {
+	t.Parallel()
+
+	opts := TwilioBridgeOptions{
+		SilenceThreshold:  500,
+		SilenceDuration:   100 * time.Millisecond,
+		MinSpeechDuration: 40 * time.Millisecond,
+		BargeInDuration:   60 * time.Millisecond,
+	}
+	d := &utteranceDetector{opts: &opts}
+
+	loud := MulawDecode(frames(1, 8000)[0])
+	var bargeIns int
+	for i := 0; i < 10; i++ {
+		if _, barge := d.add(loud, true); barge {
+			bargeIns++
+		}
+	}
+	if bargeIns != 1 {
+		t.Errorf("expected one barge-in for 200ms of overlapping speech, got %d", bargeIns)
+	}
+
+	// A pause releases the latch, so speaking over the agent again interrupts again
+	quiet := make([]int16, 160)
+	d.add(quiet, true)
+	for i := 0; i < 3; i++ {
+		if _, barge := d.add(loud, true); barge {
+			bargeIns++
+		}
+	}
+	if bargeIns != 2 {
+		t.Errorf("expected a second barge-in after a pause, got %d", bargeIns)
+	}
+
+	var utterance []int16
+	for i := 0; i < 5 && utterance == nil; i++ {
+		utterance, _ = d.add(quiet, false)
+	}
+	if len(utterance) == 0 {
+		t.Error("expected utterance after trailing silence")
+	}
+}
@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/markdown"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// ANSI styles
+const (
+	styleUser    = "\033[1;32m"
+	styleDim     = "\033[2m"
+	styleCode    = "\033[36m"
+	styleHeading = "\033[1m"
+	styleTool    = "\033[33m"
+	styleError   = "\033[31m"
+	styleReset   = "\033[0m"
+)
+
+// maxToolText is how much of a tool call's arguments or result is shown
+const maxToolText = 160
+
+// renderer writes streamed output to the terminal
+type renderer struct {
+	out       io.Writer
+	color     bool
+	reasoning bool
+
+	// atLineStart is set when the last byte written was a newline
+	atLineStart bool
+}
+
+// printf writes formatted text in a style ("" for none)
+func (r *renderer) printf(style, format string, args ...any) {
+	text := fmt.Sprintf(format, args...)
+	if text == "" {
+		return
+	}
+	r.atLineStart = strings.HasSuffix(text, "\n")
+	if r.color && style != "" {
+		text = style + text + styleReset
+	}
+	_, _ = io.WriteString(r.out, text)
+}
+
+// newline ends the current line unless it is empty
+func (r *renderer) newline() {
+	if !r.atLineStart {
+		r.printf("", "\n")
+	}
+}
+
+// stream renders a response's chunks as they arrive and returns its text.
+// Text is split at render-safe markdown boundaries, so code blocks and
+// headings can be styled without breaking mid-fence.
+func (r *renderer) stream(ctx context.Context, chunks <-chan provider.StreamChunk, stream *ai.StreamTextResult) (string, error) {
+	var (
+		text     strings.Builder
+		splitter markdown.Splitter
+	)
+	r.atLineStart = true
+	for {
+		var chunk provider.StreamChunk
+		select {
+		case <-ctx.Done():
+			r.segments(splitter.Flush())
+			r.newline()
+			return text.String(), ctx.Err()
+		case c, ok := <-chunks:
+			if !ok {
+				r.segments(splitter.Flush())
+				r.newline()
+				return text.String(), stream.Err()
+			}
+			chunk = c
+		}
+
+		switch chunk.Type {
+		case provider.ChunkTypeText:
+			text.WriteString(chunk.Text)
+			r.segments(splitter.Write(chunk.Text))
+		case provider.ChunkTypeReasoning:
+			if r.reasoning {
+				r.printf(styleDim, "%s", chunk.Reasoning)
+			}
+		case provider.ChunkTypeToolCall:
+			if chunk.ToolCall != nil {
+				r.segments(splitter.Flush())
+				r.toolCall(*chunk.ToolCall)
+			}
+		case provider.ChunkTypeToolResult:
+			if chunk.ToolResult != nil {
+				r.toolResult(*chunk.ToolResult)
+			}
+		}
+	}
+}
+
+// segments writes markdown segments, styling code blocks and headings
+func (r *renderer) segments(segments []markdown.Segment) {
+	for _, seg := range segments {
+		switch seg.Block.Type {
+		case markdown.BlockCode:
+			r.printf(styleCode, "%s", seg.Text)
+		case markdown.BlockHeading:
+			r.printf(styleHeading, "%s", seg.Text)
+		default:
+			r.printf("", "%s", seg.Text)
+		}
+	}
+}
+
+// toolCall shows a tool call on its own line
+func (r *renderer) toolCall(call types.ToolCall) {
+	args, _ := json.Marshal(call.Arguments)
+	r.newline()
+	r.printf(styleTool, "⚙ %s %s\n", call.ToolName, truncate(string(args)))
+}
+
+// toolResult shows a tool result below its call
+func (r *renderer) toolResult(result types.ToolResult) {
+	r.newline()
+	if result.Error != nil {
+		r.printf(styleError, "  ✗ %s: %v\n", result.ToolName, result.Error)
+		return
+	}
+	var text string
+	if s, ok := result.Result.(string); ok {
+		text = s
+	} else {
+		data, _ := json.Marshal(result.Result)
+		text = string(data)
+	}
+	r.printf(styleDim, "  ↳ %s\n", truncate(text))
+}
+
+// truncate shortens s to one line of at most maxToolText runes
+func truncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxToolText {
+		return string(runes[:maxToolText-1]) + "…"
+	}
+	return s
+}
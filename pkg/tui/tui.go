@@ -0,0 +1,339 @@
+// Package tui runs a minimal terminal chat loop: it reads user input, streams
+// the model's answer as it is generated, shows tool calls and their results,
+// and keeps the conversation history.
+//
+// Ctrl-C while a response is streaming stops that response and returns to
+// the prompt; Ctrl-C at the prompt, EOF or "/exit" ends the loop. "/reset"
+// clears the history.
+//
+// Example:
+//
+//	err := tui.Run(ctx, tui.Options{
+//		Model:  model,
+//		System: "You are a helpful assistant.",
+//		Tools:  []types.Tool{weatherTool},
+//		Color:  true,
+//	})
+package tui
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Options configures a Chat
+type Options struct {
+	// Model answers the user (required)
+	Model provider.LanguageModel
+
+	// System prompt sent with every request
+	System string
+
+	// Tools the model can call. Calls are shown as they happen and their
+	// results are sent back to the model.
+	Tools []types.Tool
+
+	// History seeds the conversation, e.g. with a restored session
+	History []types.Message
+
+	// MaxSteps limits the model calls per user message when tools are used
+	// (default: 5)
+	MaxSteps int
+
+	// Prompt is printed before reading user input (default: "> ")
+	Prompt string
+
+	// In and Out default to os.Stdin and os.Stdout
+	In  io.Reader
+	Out io.Writer
+
+	// Color styles output with ANSI escape codes
+	Color bool
+
+	// ShowReasoning prints reasoning chunks, dimmed when Color is set
+	ShowReasoning bool
+
+	// Interrupts stops the streaming response when it receives; Run stops
+	// at the prompt. Defaults to os.Interrupt (Ctrl-C) notifications.
+	Interrupts <-chan os.Signal
+
+	// Configure adjusts each request before it is sent, e.g. to set the
+	// temperature or provider options
+	Configure func(opts *ai.StreamTextOptions)
+}
+
+// Turn is the outcome of one user message
+type Turn struct {
+	// Text is everything the model said, across steps
+	Text string
+
+	// Steps is the number of model calls made
+	Steps int
+
+	// ToolCalls made by the model during the turn
+	ToolCalls []types.ToolCall
+
+	// Usage summed across steps
+	Usage types.Usage
+
+	// Interrupted is set when the response was stopped before it finished.
+	// The partial response is kept in the history.
+	Interrupted bool
+}
+
+// Chat is a terminal conversation. It is safe to read the history while a
+// message is being sent.
+type Chat struct {
+	opts Options
+
+	mu      sync.Mutex
+	history []types.Message
+}
+
+// New creates a Chat
+func New(opts Options) *Chat {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = 5
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = "> "
+	}
+	if opts.In == nil {
+		opts.In = os.Stdin
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	return &Chat{opts: opts, history: append([]types.Message(nil), opts.History...)}
+}
+
+// Run creates a Chat and runs its input loop
+func Run(ctx context.Context, opts Options) error {
+	return New(opts).Run(ctx)
+}
+
+// History returns a copy of the conversation so far
+func (c *Chat) History() []types.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]types.Message(nil), c.history...)
+}
+
+// Reset clears the conversation
+func (c *Chat) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = nil
+}
+
+// Run reads messages from In until EOF, "/exit" or an interrupt at the
+// prompt, sending each to the model and streaming the answer to Out
+func (c *Chat) Run(ctx context.Context) error {
+	interrupts := c.opts.Interrupts
+	if interrupts == nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt)
+		defer signal.Stop(ch)
+		interrupts = ch
+	}
+	lines := readLines(c.opts.In)
+	r := &renderer{out: c.opts.Out, color: c.opts.Color}
+
+	for {
+		r.printf(styleUser, "%s", c.opts.Prompt)
+		var line string
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-interrupts:
+			r.printf("", "\n")
+			return nil
+		case l, ok := <-lines:
+			if !ok {
+				r.printf("", "\n")
+				return nil
+			}
+			line = strings.TrimSpace(l)
+		}
+
+		switch line {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		case "/reset":
+			c.Reset()
+			r.printf(styleDim, "history cleared\n")
+			continue
+		}
+
+		turnCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-interrupts:
+				cancel()
+			case <-done:
+			}
+		}()
+		turn, err := c.Send(turnCtx, line)
+		close(done)
+		cancel()
+
+		switch {
+		case err != nil:
+			r.printf(styleError, "error: %v\n", err)
+		case turn.Interrupted:
+			r.printf(styleDim, "[interrupted]\n")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Send sends a user message and streams the answer to Out, calling the
+// model again with tool results until it answers without tools or MaxSteps
+// is reached. On error the history is left unchanged; when ctx is cancelled
+// mid-response the partial answer is kept and Turn.Interrupted is set.
+func (c *Chat) Send(ctx context.Context, input string) (*Turn, error) {
+	messages := append(c.History(), types.Message{
+		Role:    types.RoleUser,
+		Content: []types.ContentPart{types.TextContent{Text: input}},
+	})
+	r := &renderer{out: c.opts.Out, color: c.opts.Color, reasoning: c.opts.ShowReasoning}
+	turn := &Turn{}
+
+	for turn.Steps < c.opts.MaxSteps {
+		turn.Steps++
+		streamOpts := ai.StreamTextOptions{
+			Model:    c.opts.Model,
+			System:   c.opts.System,
+			Messages: messages,
+			Tools:    c.opts.Tools,
+		}
+		if c.opts.Configure != nil {
+			c.opts.Configure(&streamOpts)
+		}
+		chunks, abandon := forwardChunks(&streamOpts)
+
+		stream, err := ai.StreamText(ctx, streamOpts)
+		if err != nil {
+			close(abandon)
+			if ctx.Err() != nil {
+				turn.Interrupted = true
+				break
+			}
+			return turn, err
+		}
+		text, err := r.stream(ctx, chunks, stream)
+		close(abandon)
+		_ = stream.Close()
+		turn.Text += text
+		turn.Usage = turn.Usage.Add(stream.Usage())
+		if err != nil {
+			if ctx.Err() == nil {
+				return turn, err
+			}
+			turn.Interrupted = true
+			if text != "" {
+				messages = append(messages, types.Message{
+					Role:    types.RoleAssistant,
+					Content: []types.ContentPart{types.TextContent{Text: text}},
+				})
+			}
+			break
+		}
+
+		calls, results := stream.ToolCalls(), stream.ToolResults()
+		turn.ToolCalls = append(turn.ToolCalls, calls...)
+		messages = append(messages, stepMessages(ctx, c.opts.Tools, text, calls, results)...)
+		if !needsFollowUp(results) {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.history = messages
+	c.mu.Unlock()
+	return turn, nil
+}
+
+// forwardChunks sets callbacks on opts that send its chunks, including
+// executed tool results, to the returned channel, which is closed when the
+// stream finishes. Closing abandon stops forwarding. Callbacks already set
+// by Configure still run.
+func forwardChunks(opts *ai.StreamTextOptions) (<-chan provider.StreamChunk, chan struct{}) {
+	chunks := make(chan provider.StreamChunk, 16)
+	abandon := make(chan struct{})
+	onChunk, onFinish := opts.OnChunk, opts.OnFinish
+	opts.OnChunk = func(chunk provider.StreamChunk) {
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+		select {
+		case chunks <- chunk:
+		case <-abandon:
+		}
+	}
+	opts.OnFinish = func(result *ai.StreamTextResult) {
+		if onFinish != nil {
+			onFinish(result)
+		}
+		close(chunks)
+	}
+	return chunks, abandon
+}
+
+// stepMessages returns the assistant and tool messages recording one step
+func stepMessages(ctx context.Context, tools []types.Tool, text string, calls []types.ToolCall, results []types.ToolResult) []types.Message {
+	if text == "" && len(calls) == 0 {
+		return nil
+	}
+	assistant := types.Message{Role: types.RoleAssistant, Content: []types.ContentPart{}, ToolCalls: calls}
+	if text != "" {
+		assistant.Content = append(assistant.Content, types.TextContent{Text: text})
+	}
+	messages := []types.Message{assistant}
+	for _, tr := range results {
+		messages = append(messages, types.Message{
+			Role:    types.RoleTool,
+			Content: []types.ContentPart{ai.NewToolResultContent(ctx, tools, tr)},
+		})
+	}
+	return messages
+}
+
+// needsFollowUp reports whether locally executed tool results must be sent
+// back to the model
+func needsFollowUp(results []types.ToolResult) bool {
+	for _, tr := range results {
+		if !tr.ProviderExecuted {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines reads r line by line until EOF
+func readLines(r io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+)
+
+func weatherTool() types.Tool {
+	return types.Tool{
+		Name:        "weather",
+		Description: "Get the weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(ctx context.Context, input map[string]interface{}, opts types.ToolExecutionOptions) (interface{}, error) {
+			return "sunny", nil
+		},
+	}
+}
+
+func TestChat_SendWithTools(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	model := &testutil.MockLanguageModel{
+		ToolSupport: true,
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			if calls.Add(1) == 1 {
+				return testutil.NewMockTextStream([]provider.StreamChunk{
+					{Type: provider.ChunkTypeToolCall, ToolCall: &types.ToolCall{ID: "call-1", ToolName: "weather", Arguments: map[string]interface{}{"city": "Paris"}}},
+					{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonToolCalls},
+				}), nil
+			}
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "It is "},
+				{Type: provider.ChunkTypeText, Text: "**sunny**.\n```\ncode\n```"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}
+
+	var out bytes.Buffer
+	chat := New(Options{Model: model, Tools: []types.Tool{weatherTool()}, Out: &out})
+	turn, err := chat.Send(context.Background(), "weather in Paris?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if turn.Steps != 2 || len(turn.ToolCalls) != 1 || turn.Text != "It is **sunny**.\n```\ncode\n```" {
+		t.Errorf("unexpected turn %+v", turn)
+	}
+
+	printed := out.String()
+	for _, want := range []string{`⚙ weather {"city":"Paris"}`, "↳ sunny", "It is **sunny**.\n```\ncode\n```\n"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("expected output to contain %q, got %q", want, printed)
+		}
+	}
+
+	roles := []types.MessageRole{}
+	for _, m := range chat.History() {
+		roles = append(roles, m.Role)
+	}
+	want := []types.MessageRole{types.RoleUser, types.RoleAssistant, types.RoleTool, types.RoleAssistant}
+	if len(roles) != len(want) {
+		t.Fatalf("expected history %v, got %v", want, roles)
+	}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Errorf("expected history %v, got %v", want, roles)
+		}
+	}
+}
+
+func TestChat_Run(t *testing.T) {
+	t.Parallel()
+
+	var prompts []int
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			prompts = append(prompts, len(opts.Prompt.Messages))
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "hello"},
+				{Type: provider.ChunkTypeFinish, FinishReason: types.FinishReasonStop},
+			}), nil
+		},
+	}
+
+	var out bytes.Buffer
+	err := Run(context.Background(), Options{
+		Model:      model,
+		In:         strings.NewReader("hi\n\nagain\n/reset\nfresh\n/exit\nignored\n"),
+		Out:        &out,
+		Interrupts: make(chan os.Signal),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 3 || prompts[0] != 1 || prompts[1] != 3 || prompts[2] != 1 {
+		t.Errorf("expected history to grow and be reset, got prompt sizes %v", prompts)
+	}
+	if strings.Count(out.String(), "hello\n") != 3 || !strings.Contains(out.String(), "history cleared") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+// blockingStream sends one text chunk and then waits for its context
+type blockingStream struct {
+	ctx  context.Context
+	sent bool
+}
+
+func (s *blockingStream) Next() (*provider.StreamChunk, error) {
+	if !s.sent {
+		s.sent = true
+		return &provider.StreamChunk{Type: provider.ChunkTypeText, Text: "partial answer "}, nil
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func (s *blockingStream) Err() error   { return nil }
+func (s *blockingStream) Close() error { return nil }
+
+func TestChat_Interrupt(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return &blockingStream{ctx: ctx}, nil
+		},
+	}
+	interrupts := make(chan os.Signal)
+	in, input := io.Pipe()
+	var out syncBuffer
+
+	done := make(chan error, 1)
+	chat := New(Options{Model: model, In: in, Out: &out, Interrupts: interrupts})
+	go func() { done <- chat.Run(context.Background()) }()
+
+	_, _ = io.WriteString(input, "tell me a story\n")
+	waitFor(t, func() bool { return strings.Contains(out.String(), "partial answer") })
+	interrupts <- os.Interrupt
+	waitFor(t, func() bool { return strings.Contains(out.String(), "[interrupted]") })
+
+	// A second interrupt at the prompt ends the loop
+	interrupts <- os.Interrupt
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+
+	history := chat.History()
+	if len(history) != 2 || history[1].Role != types.RoleAssistant {
+		t.Errorf("expected the partial answer to be kept, got %+v", history)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
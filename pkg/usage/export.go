@@ -0,0 +1,155 @@
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/webhook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Exporter delivers the totals of one flush
+type Exporter interface {
+	Export(ctx context.Context, totals []Total) error
+}
+
+// ExporterFunc adapts a function to an Exporter
+type ExporterFunc func(ctx context.Context, totals []Total) error
+
+// Export calls f
+func (f ExporterFunc) Export(ctx context.Context, totals []Total) error {
+	return f(ctx, totals)
+}
+
+// csvHeader is the first row written by a CSVExporter
+var csvHeader = []string{
+	"start", "end", "tenant", "provider", "model", "feature",
+	"requests", "input_tokens", "cached_input_tokens", "output_tokens", "cost_usd",
+}
+
+// CSVExporter appends one row per total to a CSV stream, after a header row
+type CSVExporter struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVExporter creates a CSVExporter writing to w. Pass header false when
+// appending to a file that already has one.
+func NewCSVExporter(w io.Writer, header bool) *CSVExporter {
+	return &CSVExporter{w: csv.NewWriter(w), header: header}
+}
+
+// Export writes the totals
+func (e *CSVExporter) Export(ctx context.Context, totals []Total) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.header {
+		if err := e.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		e.header = false
+	}
+	for _, t := range totals {
+		err := e.w.Write([]string{
+			t.Start.UTC().Format(time.RFC3339),
+			t.End.UTC().Format(time.RFC3339),
+			t.Tenant, t.Provider, t.Model, t.Feature,
+			strconv.FormatInt(t.Requests, 10),
+			strconv.FormatInt(t.InputTokens, 10),
+			strconv.FormatInt(t.CachedInputTokens, 10),
+			strconv.FormatInt(t.OutputTokens, 10),
+			strconv.FormatFloat(t.Cost, 'f', 6, 64),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WebhookEventType is the event type of WebhookExporter deliveries
+const WebhookEventType = "usage.report"
+
+// WebhookReport is the data of a WebhookExporter delivery
+type WebhookReport struct {
+	Totals []Total `json:"totals"`
+}
+
+// WebhookExporter posts the totals as one signed "usage.report" event.
+// Deliveries are retried by the Sender; an undelivered report is retried on
+// the next flush.
+func WebhookExporter(sender *webhook.Sender) Exporter {
+	return ExporterFunc(func(ctx context.Context, totals []Total) error {
+		return sender.Notify(ctx, WebhookEventType, WebhookReport{Totals: totals})
+	})
+}
+
+// OTelExporter adds the totals to OpenTelemetry counters, attributed by
+// tenant, provider, model and feature. Export them over OTLP by creating
+// the meter from a MeterProvider with an OTLP metric reader.
+//
+// The counters are:
+//
+//	ai.usage.requests             model calls
+//	ai.usage.input_tokens         {token}
+//	ai.usage.cached_input_tokens  {token}
+//	ai.usage.output_tokens        {token}
+//	ai.usage.cost                 USD
+type OTelExporter struct {
+	requests     metric.Int64Counter
+	inputTokens  metric.Int64Counter
+	cachedTokens metric.Int64Counter
+	outputTokens metric.Int64Counter
+	cost         metric.Float64Counter
+}
+
+// NewOTelExporter creates an OTelExporter recording to meter
+func NewOTelExporter(meter metric.Meter) (*OTelExporter, error) {
+	var e OTelExporter
+	var err error
+	if e.requests, err = meter.Int64Counter("ai.usage.requests", metric.WithDescription("Model calls"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if e.inputTokens, err = meter.Int64Counter("ai.usage.input_tokens", metric.WithDescription("Input tokens, including cached"), metric.WithUnit("{token}")); err != nil {
+		return nil, err
+	}
+	if e.cachedTokens, err = meter.Int64Counter("ai.usage.cached_input_tokens", metric.WithDescription("Input tokens read from cache"), metric.WithUnit("{token}")); err != nil {
+		return nil, err
+	}
+	if e.outputTokens, err = meter.Int64Counter("ai.usage.output_tokens", metric.WithDescription("Output tokens"), metric.WithUnit("{token}")); err != nil {
+		return nil, err
+	}
+	if e.cost, err = meter.Float64Counter("ai.usage.cost", metric.WithDescription("Cost"), metric.WithUnit("USD")); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Export adds the totals to the counters
+func (e *OTelExporter) Export(ctx context.Context, totals []Total) error {
+	for _, t := range totals {
+		attrs := metric.WithAttributes(
+			attribute.String("tenant", t.Tenant),
+			attribute.String("gen_ai.provider.name", t.Provider),
+			attribute.String("gen_ai.request.model", t.Model),
+			attribute.String("feature", t.Feature),
+		)
+		e.requests.Add(ctx, t.Requests, attrs)
+		e.inputTokens.Add(ctx, t.InputTokens, attrs)
+		e.cachedTokens.Add(ctx, t.CachedInputTokens, attrs)
+		e.outputTokens.Add(ctx, t.OutputTokens, attrs)
+		e.cost.Add(ctx, t.Cost, attrs)
+	}
+	return nil
+}
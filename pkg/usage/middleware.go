@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// Middleware returns middleware that records the usage of every call. A
+// stream is recorded once, with the last usage it reported, when it
+// finishes or is closed.
+func (a *Accountant) Middleware() *middleware.LanguageModelMiddleware {
+	return &middleware.LanguageModelMiddleware{
+		SpecificationVersion: "v3",
+		WrapGenerate: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (*types.GenerateResult, error) {
+			result, err := doGenerate()
+			if err != nil {
+				return nil, err
+			}
+			a.Record(ctx, model.Provider(), model.ModelID(), result.Usage)
+			return result, nil
+		},
+		WrapStream: func(
+			ctx context.Context,
+			doGenerate func() (*types.GenerateResult, error),
+			doStream func() (provider.TextStream, error),
+			params *provider.GenerateOptions,
+			model provider.LanguageModel,
+		) (provider.TextStream, error) {
+			stream, err := doStream()
+			if err != nil {
+				return nil, err
+			}
+			return &recordingStream{TextStream: stream, record: func(u types.Usage) {
+				a.Record(ctx, model.Provider(), model.ModelID(), u)
+			}}, nil
+		},
+	}
+}
+
+// recordingStream records the last usage a stream reports
+type recordingStream struct {
+	provider.TextStream
+	record func(types.Usage)
+
+	usage    types.Usage
+	reported bool
+	once     sync.Once
+}
+
+func (s *recordingStream) Next() (*provider.StreamChunk, error) {
+	chunk, err := s.TextStream.Next()
+	if err != nil {
+		if err == io.EOF {
+			s.done()
+		}
+		return chunk, err
+	}
+	// Estimates (from UsageStreamMiddleware) are kept only until the
+	// provider reports usage
+	if chunk.Usage != nil && (!chunk.UsageEstimated || !s.reported) {
+		s.usage = *chunk.Usage
+		s.reported = !chunk.UsageEstimated
+	}
+	if chunk.Type == provider.ChunkTypeFinish {
+		s.done()
+	}
+	return chunk, nil
+}
+
+func (s *recordingStream) Close() error {
+	s.done()
+	return s.TextStream.Close()
+}
+
+func (s *recordingStream) done() {
+	s.once.Do(func() { s.record(s.usage) })
+}
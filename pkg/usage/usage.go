@@ -0,0 +1,253 @@
+// Package usage aggregates token usage and cost by tenant, model and
+// feature, and periodically exports the totals to OpenTelemetry metrics,
+// CSV or a webhook, so chargeback does not depend on scraping logs.
+//
+// Tag calls with WithTenant and WithFeature, record them with the
+// Accountant's middleware, and run its flush loop:
+//
+//	accountant := usage.NewAccountant(usage.Options{
+//		Cost:      func(provider, model string, u types.Usage) float64 { return prices[model].Cost(u) },
+//		Exporters: []usage.Exporter{usage.NewCSVExporter(file, true)},
+//		Interval:  time.Hour,
+//	})
+//	go accountant.Run(ctx)
+//	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{accountant.Middleware()}, nil, nil)
+//
+//	ctx = usage.WithFeature(usage.WithTenant(ctx, "acme"), "support-chat")
+//	result, err := ai.GenerateText(ctx, ai.GenerateTextOptions{Model: wrapped, Prompt: "..."})
+package usage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+type tenantKey struct{}
+
+type featureKey struct{}
+
+// WithTenant returns a context whose calls are accounted to tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// WithFeature returns a context whose calls are accounted to feature, e.g.
+// "support-chat" or "summarize"
+func WithFeature(ctx context.Context, feature string) context.Context {
+	return context.WithValue(ctx, featureKey{}, feature)
+}
+
+// Key identifies one aggregation bucket
+type Key struct {
+	Tenant   string `json:"tenant"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Feature  string `json:"feature"`
+}
+
+// Total is the usage of one Key over an export window
+type Total struct {
+	Key
+
+	// Start and End bound the window the usage was recorded in
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Requests is the number of model calls
+	Requests int64 `json:"requests"`
+
+	// InputTokens includes CachedInputTokens
+	InputTokens       int64 `json:"inputTokens"`
+	CachedInputTokens int64 `json:"cachedInputTokens"`
+	OutputTokens      int64 `json:"outputTokens"`
+
+	// Cost in USD, as priced by Options.Cost
+	Cost float64 `json:"cost"`
+}
+
+// add merges other into t, widening the window to cover both
+func (t *Total) add(other Total) {
+	if t.Start.IsZero() || other.Start.Before(t.Start) {
+		t.Start = other.Start
+	}
+	if other.End.After(t.End) {
+		t.End = other.End
+	}
+	t.Requests += other.Requests
+	t.InputTokens += other.InputTokens
+	t.CachedInputTokens += other.CachedInputTokens
+	t.OutputTokens += other.OutputTokens
+	t.Cost += other.Cost
+}
+
+// Options configures an Accountant
+type Options struct {
+	// Cost prices one call's usage in USD. Without it, costs are zero.
+	Cost func(provider, model string, usage types.Usage) float64
+
+	// Exporters receive the totals on every flush
+	Exporters []Exporter
+
+	// Interval is how often Run flushes
+	// Default: 1 minute
+	Interval time.Duration
+
+	// OnError is called when Run fails to export. The totals are kept for
+	// the failed exporter and sent with its next flush.
+	OnError func(err error)
+
+	// Clock stamps windows and drives Run (default: system clock)
+	Clock clock.Clock
+}
+
+// Accountant aggregates usage and exports it. It is safe for concurrent
+// use.
+type Accountant struct {
+	opts Options
+
+	mu          sync.Mutex
+	windowStart time.Time
+	totals      map[Key]*Total
+
+	// flushMu serializes flushes; pending holds, per exporter, totals a
+	// failed export has yet to deliver
+	flushMu sync.Mutex
+	pending []map[Key]*Total
+}
+
+// NewAccountant creates an Accountant
+func NewAccountant(opts Options) *Accountant {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	opts.Clock = clock.Default(opts.Clock)
+	return &Accountant{
+		opts:        opts,
+		windowStart: opts.Clock.Now(),
+		totals:      map[Key]*Total{},
+		pending:     make([]map[Key]*Total, len(opts.Exporters)),
+	}
+}
+
+// Record accounts one call's usage to the tenant and feature in ctx
+func (a *Accountant) Record(ctx context.Context, provider, model string, u types.Usage) {
+	key := Key{Provider: provider, Model: model}
+	key.Tenant, _ = ctx.Value(tenantKey{}).(string)
+	key.Feature, _ = ctx.Value(featureKey{}).(string)
+
+	total := Total{Key: key, Requests: 1, InputTokens: u.GetInputTokens(), OutputTokens: u.GetOutputTokens()}
+	if u.InputDetails != nil && u.InputDetails.CacheReadTokens != nil {
+		total.CachedInputTokens = *u.InputDetails.CacheReadTokens
+	}
+	if a.opts.Cost != nil {
+		total.Cost = a.opts.Cost(provider, model, u)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t, ok := a.totals[key]
+	if !ok {
+		t = &Total{Key: key}
+		a.totals[key] = t
+	}
+	t.add(total)
+}
+
+// Snapshot returns the totals recorded since the last flush, sorted by key
+func (a *Accountant) Snapshot() []Total {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return sorted(a.totals, a.windowStart, a.opts.Clock.Now())
+}
+
+// Flush ends the current window and exports its totals. Exporters that
+// fail keep their totals and receive them, merged into later windows, on
+// the next flush; the errors are joined.
+func (a *Accountant) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	now := a.opts.Clock.Now()
+	window := a.totals
+	start := a.windowStart
+	a.totals = map[Key]*Total{}
+	a.windowStart = now
+	a.mu.Unlock()
+
+	var errs []error
+	for i, exporter := range a.opts.Exporters {
+		if a.pending[i] == nil {
+			a.pending[i] = map[Key]*Total{}
+		}
+		for key, t := range window {
+			merged := *t
+			merged.Start, merged.End = start, now
+			if p, ok := a.pending[i][key]; ok {
+				p.add(merged)
+			} else {
+				a.pending[i][key] = &merged
+			}
+		}
+		if len(a.pending[i]) == 0 {
+			continue
+		}
+		if err := exporter.Export(ctx, sorted(a.pending[i], time.Time{}, time.Time{})); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		a.pending[i] = nil
+	}
+	return errors.Join(errs...)
+}
+
+// Run flushes every Interval until ctx is done, then flushes once more so
+// the last window is not lost. It returns ctx's error.
+func (a *Accountant) Run(ctx context.Context) error {
+	for {
+		if err := clock.Sleep(ctx, a.opts.Clock, a.opts.Interval); err != nil {
+			a.flush(context.WithoutCancel(ctx))
+			return err
+		}
+		a.flush(ctx)
+	}
+}
+
+func (a *Accountant) flush(ctx context.Context) {
+	if err := a.Flush(ctx); err != nil && a.opts.OnError != nil {
+		a.opts.OnError(err)
+	}
+}
+
+// sorted returns totals in key order. Non-zero start and end replace the
+// totals' windows.
+func sorted(totals map[Key]*Total, start, end time.Time) []Total {
+	out := make([]Total, 0, len(totals))
+	for _, t := range totals {
+		total := *t
+		if !start.IsZero() {
+			total.Start, total.End = start, end
+		}
+		out = append(out, total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Key, out[j].Key
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Feature < b.Feature
+	})
+	return out
+}
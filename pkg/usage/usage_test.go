@@ -0,0 +1,213 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/middleware"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func tokens(input, output int64) types.Usage {
+	return types.Usage{InputTokens: &input, OutputTokens: &output}
+}
+
+func TestAccountant_MiddlewareAggregatesByTenantModelAndFeature(t *testing.T) {
+	t.Parallel()
+
+	model := &testutil.MockLanguageModel{
+		ProviderName: "openai",
+		ModelName:    "gpt-4o",
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{Usage: tokens(100, 10)}, nil
+		},
+		DoStreamFunc: func(ctx context.Context, opts *provider.GenerateOptions) (provider.TextStream, error) {
+			return testutil.NewMockTextStream([]provider.StreamChunk{
+				{Type: provider.ChunkTypeText, Text: "hi"},
+				{Type: provider.ChunkTypeUsage, Usage: &types.Usage{}, UsageEstimated: true},
+				{Type: provider.ChunkTypeFinish, Usage: &types.Usage{InputTokens: new(int64), OutputTokens: new(int64)}},
+			}), nil
+		},
+	}
+	accountant := NewAccountant(Options{
+		Cost: func(provider, model string, u types.Usage) float64 {
+			return float64(u.GetInputTokens()+u.GetOutputTokens()) / 1000
+		},
+		Clock: clock.NewFake(time.Unix(0, 0)),
+	})
+	wrapped := middleware.WrapLanguageModel(model, []*middleware.LanguageModelMiddleware{accountant.Middleware()}, nil, nil)
+
+	acme := WithFeature(WithTenant(context.Background(), "acme"), "chat")
+	for i := 0; i < 2; i++ {
+		if _, err := wrapped.DoGenerate(acme, &provider.GenerateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := wrapped.DoGenerate(WithTenant(context.Background(), "globex"), &provider.GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := wrapped.DoStream(acme, &provider.GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Next(); err == io.EOF {
+			break
+		}
+	}
+	stream.Close()
+
+	totals := accountant.Snapshot()
+	if len(totals) != 2 {
+		t.Fatalf("expected 2 totals, got %+v", totals)
+	}
+	acmeTotal, globex := totals[0], totals[1]
+	if acmeTotal.Key != (Key{Tenant: "acme", Provider: "openai", Model: "gpt-4o", Feature: "chat"}) ||
+		acmeTotal.Requests != 3 || acmeTotal.InputTokens != 200 || acmeTotal.OutputTokens != 20 || acmeTotal.Cost < 0.219 || acmeTotal.Cost > 0.221 {
+		t.Errorf("acme total = %+v", acmeTotal)
+	}
+	if globex.Tenant != "globex" || globex.Feature != "" || globex.Requests != 1 {
+		t.Errorf("globex total = %+v", globex)
+	}
+}
+
+func TestAccountant_FlushKeepsTotalsForFailedExporters(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	failing := true
+	var received []Total
+	flaky := ExporterFunc(func(ctx context.Context, totals []Total) error {
+		if failing {
+			return errors.New("endpoint down")
+		}
+		received = totals
+		return nil
+	})
+	accountant := NewAccountant(Options{Exporters: []Exporter{NewCSVExporter(&buf, true), flaky}, Clock: clk})
+	ctx := WithTenant(context.Background(), "acme")
+
+	accountant.Record(ctx, "openai", "gpt-4o", tokens(100, 10))
+	clk.Advance(time.Hour)
+	if err := accountant.Flush(context.Background()); err == nil {
+		t.Fatal("expected the failing exporter's error")
+	}
+	failing = false
+	accountant.Record(ctx, "openai", "gpt-4o", tokens(50, 5))
+	clk.Advance(time.Hour)
+	if err := accountant.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 1 || received[0].Requests != 2 || received[0].InputTokens != 150 ||
+		!received[0].Start.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !received[0].End.Equal(clk.Now()) {
+		t.Errorf("retried export = %+v", received)
+	}
+	want := "start,end,tenant,provider,model,feature,requests,input_tokens,cached_input_tokens,output_tokens,cost_usd\n" +
+		"2026-01-01T00:00:00Z,2026-01-01T01:00:00Z,acme,openai,gpt-4o,,1,100,0,10,0.000000\n" +
+		"2026-01-01T01:00:00Z,2026-01-01T02:00:00Z,acme,openai,gpt-4o,,1,50,0,5,0.000000\n"
+	if buf.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestAccountant_RunFlushesOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	exported := make(chan []Total, 1)
+	accountant := NewAccountant(Options{
+		Exporters: []Exporter{ExporterFunc(func(ctx context.Context, totals []Total) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			exported <- totals
+			return nil
+		})},
+		Clock: clock.NewFake(time.Unix(0, 0)),
+	})
+	accountant.Record(context.Background(), "openai", "gpt-4o", tokens(1, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- accountant.Run(ctx) }()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v", err)
+	}
+	if totals := <-exported; len(totals) != 1 {
+		t.Errorf("final flush exported %+v", totals)
+	}
+}
+
+// recordingMeter records counter additions by instrument name
+type recordingMeter struct {
+	noop.Meter
+	adds map[string]float64
+	attr map[string]string
+}
+
+type recordingInt64Counter struct {
+	noop.Int64Counter
+	name  string
+	meter *recordingMeter
+}
+
+func (c recordingInt64Counter) Add(ctx context.Context, v int64, opts ...metric.AddOption) {
+	c.meter.record(c.name, float64(v), opts)
+}
+
+type recordingFloat64Counter struct {
+	noop.Float64Counter
+	name  string
+	meter *recordingMeter
+}
+
+func (c recordingFloat64Counter) Add(ctx context.Context, v float64, opts ...metric.AddOption) {
+	c.meter.record(c.name, v, opts)
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingInt64Counter{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) Float64Counter(name string, _ ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	return recordingFloat64Counter{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) record(name string, v float64, opts []metric.AddOption) {
+	m.adds[name] += v
+	set := metric.NewAddConfig(opts).Attributes()
+	for _, kv := range set.ToSlice() {
+		m.attr[string(kv.Key)] = kv.Value.Emit()
+	}
+}
+
+func TestOTelExporter(t *testing.T) {
+	t.Parallel()
+
+	meter := &recordingMeter{adds: map[string]float64{}, attr: map[string]string{}}
+	exporter, err := NewOTelExporter(meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := Total{Key: Key{Tenant: "acme", Provider: "openai", Model: "gpt-4o", Feature: "chat"}, Requests: 2, InputTokens: 30, OutputTokens: 7, Cost: 0.5}
+	if err := exporter.Export(context.Background(), []Total{total, total}); err != nil {
+		t.Fatal(err)
+	}
+	if meter.adds["ai.usage.requests"] != 4 || meter.adds["ai.usage.input_tokens"] != 60 || meter.adds["ai.usage.cost"] != 1 {
+		t.Errorf("counters = %v", meter.adds)
+	}
+	if meter.attr["tenant"] != "acme" || meter.attr["gen_ai.request.model"] != "gpt-4o" || meter.attr["feature"] != "chat" {
+		t.Errorf("attributes = %v", meter.attr)
+	}
+}
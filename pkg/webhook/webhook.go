@@ -0,0 +1,226 @@
+// Package webhook delivers signed event notifications over HTTP, so external
+// systems learn when long-running work such as queued generation and agent
+// jobs completes or fails.
+//
+// Each delivery is a JSON POST carrying three headers:
+//
+//	Webhook-Id:        unique event ID, stable across retries
+//	Webhook-Timestamp: Unix seconds when the delivery was signed
+//	Webhook-Signature: v1=<hex HMAC-SHA256 of "id.timestamp.body">
+//
+// Receivers check the signature with Verify:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		body, _ := io.ReadAll(r.Body)
+//		if err := webhook.Verify(secret, r.Header, body, 5*time.Minute); err != nil {
+//			http.Error(w, err.Error(), http.StatusUnauthorized)
+//			return
+//		}
+//		var event webhook.Event
+//		_ = json.Unmarshal(body, &event)
+//		...
+//	}
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/internal/retry"
+	"github.com/google/uuid"
+)
+
+// Header names set on every delivery
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// Event is the JSON body of a delivery
+type Event struct {
+	// ID uniquely identifies the event; receivers use it to deduplicate
+	// retried deliveries
+	ID string `json:"id"`
+
+	// Type names the event, e.g. "job.succeeded"
+	Type string `json:"type"`
+
+	// CreatedAt is when the event happened
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Data is the event payload
+	Data interface{} `json:"data"`
+}
+
+// Options configures a Sender
+type Options struct {
+	// URL receives the deliveries (required)
+	URL string
+
+	// Secret signs deliveries (required). Share it with the receiver.
+	Secret string
+
+	// HTTPClient sends deliveries (default: a client with a 10s timeout)
+	HTTPClient *http.Client
+
+	// Headers are added to every delivery, e.g. for receiver authentication
+	Headers map[string]string
+
+	// MaxRetries is the number of retries after a failed delivery (default
+	// 5; set to -1 to disable retries). Network errors, 408, 429 and 5xx
+	// responses are retried with exponential backoff.
+	MaxRetries int
+
+	// Clock and RNG drive retry backoff (defaults: system clock, math/rand)
+	Clock clock.Clock
+	RNG   clock.RNG
+}
+
+// Sender delivers events to one webhook endpoint
+type Sender struct {
+	opts     Options
+	retryCfg retry.Config
+}
+
+// NewSender creates a Sender
+func NewSender(opts Options) (*Sender, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	if opts.Secret == "" {
+		return nil, fmt.Errorf("webhook secret is required")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	s := &Sender{opts: opts}
+	s.retryCfg = retry.DefaultConfig()
+	s.retryCfg.MaxRetries = 5
+	s.retryCfg.ShouldRetry = isRetryable
+	s.retryCfg.Clock, s.retryCfg.RNG = opts.Clock, opts.RNG
+	switch {
+	case opts.MaxRetries < 0:
+		// retry.Do treats 0 as "use defaults", so disable retries explicitly
+		s.retryCfg.ShouldRetry = func(error) bool { return false }
+	case opts.MaxRetries > 0:
+		s.retryCfg.MaxRetries = opts.MaxRetries
+	}
+	return s, nil
+}
+
+// Notify creates an event of the given type and delivers it
+func (s *Sender) Notify(ctx context.Context, eventType string, data interface{}) error {
+	return s.Send(ctx, Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: clock.Default(s.opts.Clock).Now().UTC(),
+		Data:      data,
+	})
+}
+
+// Send delivers an event, retrying transient failures. It returns the last
+// error once retries are exhausted or ctx is done.
+func (s *Sender) Send(ctx context.Context, event Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	return retry.Do(ctx, s.retryCfg, func(ctx context.Context) error {
+		timestamp := clock.Default(s.opts.Clock).Now().Unix()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.URL, bytes.NewReader(body))
+		if err != nil {
+			return &permanentError{err}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range s.opts.Headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set(HeaderID, event.ID)
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(HeaderSignature, "v1="+sign(s.opts.Secret, event.ID, timestamp, body))
+
+		resp, err := s.opts.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("webhook endpoint returned %s", resp.Status)
+		if resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return err
+		}
+		return &permanentError{err}
+	})
+}
+
+// Verify checks the signature headers of a delivery against secret, and
+// that it was signed within tolerance of now (0 skips the age check). The
+// signature header may hold several space-separated signatures, so senders
+// can rotate secrets.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	id := header.Get(HeaderID)
+	ts := header.Get(HeaderTimestamp)
+	sigs := header.Get(HeaderSignature)
+	if id == "" || ts == "" || sigs == "" {
+		return errors.New("webhook: missing signature headers")
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("webhook: invalid timestamp")
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return errors.New("webhook: timestamp outside tolerance")
+		}
+	}
+
+	expected := []byte(sign(secret, id, timestamp, body))
+	for _, sig := range strings.Fields(sigs) {
+		if v, ok := strings.CutPrefix(sig, "v1="); ok && hmac.Equal([]byte(v), expected) {
+			return nil
+		}
+	}
+	return errors.New("webhook: signature mismatch")
+}
+
+// sign returns the hex HMAC-SHA256 of "id.timestamp.body"
+func sign(secret, id string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s.%d.", id, timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// permanentError marks a delivery failure that retrying cannot fix
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func isRetryable(err error) bool {
+	var perm *permanentError
+	return !errors.As(err, &perm) && !errors.Is(err, context.Canceled)
+}
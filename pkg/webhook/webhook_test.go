@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+)
+
+func TestSender_SignsDeliveries(t *testing.T) {
+	t.Parallel()
+
+	var got Event
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = Verify("s3cret", r.Header, body, time.Minute)
+		_ = json.Unmarshal(body, &got)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(Options{URL: server.URL, Secret: "s3cret", Headers: map[string]string{"Authorization": "Bearer token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Notify(context.Background(), "job.succeeded", map[string]string{"jobId": "j1"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("Verify: %v", verifyErr)
+	}
+	if got.ID == "" || got.Type != "job.succeeded" || got.Data.(map[string]interface{})["jobId"] != "j1" {
+		t.Errorf("unexpected event %+v", got)
+	}
+}
+
+func TestSender_RetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(HeaderID))
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	sender, _ := NewSender(Options{URL: server.URL, Secret: "s", Clock: fake})
+	done := make(chan error, 1)
+	go func() { done <- sender.Notify(context.Background(), "job.failed", nil) }()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the retry")
+	}
+	if attempts.Load() != 2 || ids[0] != ids[1] {
+		t.Errorf("expected 2 attempts with the same event ID, got %v", ids)
+	}
+}
+
+func TestSender_DoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	sender, _ := NewSender(Options{URL: server.URL, Secret: "s"})
+	err := sender.Notify(context.Background(), "job.failed", nil)
+	if err == nil || !strings.Contains(err.Error(), "410") {
+		t.Errorf("expected the 410 error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"id":"e1"}`)
+	now := time.Now().Unix()
+	header := http.Header{}
+	header.Set(HeaderID, "e1")
+	header.Set(HeaderTimestamp, strconv.FormatInt(now, 10))
+	header.Set(HeaderSignature, "v1=stale v1="+sign("new", "e1", now, body))
+
+	if err := Verify("new", header, body, time.Minute); err != nil {
+		t.Errorf("expected a rotated signature to verify: %v", err)
+	}
+	if err := Verify("other", header, body, time.Minute); err == nil {
+		t.Error("expected a wrong secret to fail")
+	}
+	if err := Verify("new", header, []byte(`{"id":"e2"}`), time.Minute); err == nil {
+		t.Error("expected a modified body to fail")
+	}
+
+	old := now - 3600
+	header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	header.Set(HeaderSignature, "v1="+sign("new", "e1", old, body))
+	if err := Verify("new", header, body, 5*time.Minute); err == nil {
+		t.Error("expected an old delivery to fail")
+	}
+}
@@ -0,0 +1,119 @@
+// Package worker consumes generation and agent jobs from a message queue,
+// runs them with concurrency, rate and token-budget controls, and publishes
+// results and usage events.
+//
+// The package is transport-agnostic: Kafka, NATS or any other queue is
+// plugged in by implementing Source and Publisher, which takes a few lines
+// with the usual clients. For NATS JetStream:
+//
+//	type natsMessage struct{ msg jetstream.Msg }
+//
+//	func (m natsMessage) Data() []byte                  { return m.msg.Data() }
+//	func (m natsMessage) Ack(ctx context.Context) error  { return m.msg.Ack() }
+//	func (m natsMessage) Nack(ctx context.Context) error { return m.msg.Nak() }
+//
+//	source := worker.SourceFunc(func(ctx context.Context) (worker.Message, error) {
+//		msg, err := consumer.Next(jetstream.FetchContext(ctx))
+//		return natsMessage{msg}, err
+//	})
+//
+// and for Kafka (segmentio/kafka-go), Ack commits the offset:
+//
+//	source := worker.SourceFunc(func(ctx context.Context) (worker.Message, error) {
+//		msg, err := reader.FetchMessage(ctx)
+//		return kafkaMessage{reader, msg}, err
+//	})
+package worker
+
+import (
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+)
+
+// JobType selects how a job is executed
+type JobType string
+
+const (
+	// JobTypeGenerate runs a single ai.GenerateText call
+	JobTypeGenerate JobType = "generate"
+
+	// JobTypeAgent runs a named agent from Options.Agents
+	JobTypeAgent JobType = "agent"
+)
+
+// Job is the JSON payload of a queued job
+type Job struct {
+	// ID identifies the job in results and usage events (required)
+	ID string `json:"id"`
+
+	// Type of job (default "generate")
+	Type JobType `json:"type,omitempty"`
+
+	// Model is a registry model string such as "openai:gpt-4o" (generate
+	// jobs). Empty uses Options.DefaultModel.
+	Model string `json:"model,omitempty"`
+
+	// Agent names the agent to run (agent jobs)
+	Agent string `json:"agent,omitempty"`
+
+	// System prompt (generate jobs)
+	System string `json:"system,omitempty"`
+
+	// Prompt or Messages is the input
+	Prompt   string       `json:"prompt,omitempty"`
+	Messages []JobMessage `json:"messages,omitempty"`
+
+	// Generation settings (generate jobs)
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"maxTokens,omitempty"`
+
+	// Metadata is copied to the result and usage event
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// JobMessage is a text message in a job's conversation
+type JobMessage struct {
+	Role    types.MessageRole `json:"role"`
+	Content string            `json:"content"`
+}
+
+// JobStatus is the outcome of a job
+type JobStatus string
+
+const (
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Webhook event types sent to Options.Webhook
+const (
+	EventJobSucceeded = "job.succeeded"
+	EventJobFailed    = "job.failed"
+)
+
+// JobResult is published to Options.ResultTopic when a job finishes
+type JobResult struct {
+	JobID        string            `json:"jobId"`
+	Status       JobStatus         `json:"status"`
+	Text         string            `json:"text,omitempty"`
+	FinishReason string            `json:"finishReason,omitempty"`
+	Usage        types.Usage       `json:"usage"`
+	Error        string            `json:"error,omitempty"`
+	Attempts     int               `json:"attempts"`
+	StartedAt    time.Time         `json:"startedAt"`
+	FinishedAt   time.Time         `json:"finishedAt"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// UsageEvent is published to Options.UsageTopic for every job that consumed
+// tokens, for billing and quota tracking
+type UsageEvent struct {
+	JobID        string            `json:"jobId"`
+	Model        string            `json:"model"`
+	InputTokens  int64             `json:"inputTokens"`
+	OutputTokens int64             `json:"outputTokens"`
+	TotalTokens  int64             `json:"totalTokens"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+var errQueueFull = errors.New("memory queue is full")
+
+// MemoryQueue is an in-process Source and Publisher for local development,
+// tests, and single-binary deployments. Nacked messages are redelivered.
+type MemoryQueue struct {
+	jobs chan []byte
+
+	mu        sync.Mutex
+	published map[string][][]byte
+}
+
+// NewMemoryQueue creates a MemoryQueue holding up to buffer pending jobs
+func NewMemoryQueue(buffer int) *MemoryQueue {
+	return &MemoryQueue{jobs: make(chan []byte, buffer), published: make(map[string][][]byte)}
+}
+
+// Enqueue adds a job, blocking while the queue is full
+func (q *MemoryQueue) Enqueue(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.enqueueRaw(ctx, data)
+}
+
+func (q *MemoryQueue) enqueueRaw(ctx context.Context, data []byte) error {
+	select {
+	case q.jobs <- data:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Fetch implements Source
+func (q *MemoryQueue) Fetch(ctx context.Context) (Message, error) {
+	select {
+	case data := <-q.jobs:
+		return &memoryMessage{queue: q, data: data}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Publish implements Publisher
+func (q *MemoryQueue) Publish(ctx context.Context, topic string, data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.published[topic] = append(q.published[topic], append([]byte(nil), data...))
+	return nil
+}
+
+// Published returns the messages published to topic so far
+func (q *MemoryQueue) Published(topic string) [][]byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([][]byte(nil), q.published[topic]...)
+}
+
+type memoryMessage struct {
+	queue *MemoryQueue
+	data  []byte
+}
+
+func (m *memoryMessage) Data() []byte { return m.data }
+
+func (m *memoryMessage) Ack(ctx context.Context) error { return nil }
+
+func (m *memoryMessage) Nack(ctx context.Context) error {
+	select {
+	case m.queue.jobs <- m.data:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
@@ -0,0 +1,463 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/ai"
+	"github.com/digitallysavvy/go-ai/pkg/clock"
+	"github.com/digitallysavvy/go-ai/pkg/internal/retry"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/webhook"
+	"golang.org/x/time/rate"
+)
+
+// ErrBudgetExhausted is returned by Run when Options.TokenBudget is used up
+var ErrBudgetExhausted = errors.New("worker token budget exhausted")
+
+// Message is a job delivered by a queue
+type Message interface {
+	// Data returns the JSON-encoded Job
+	Data() []byte
+
+	// Ack acknowledges the message so it is not redelivered
+	Ack(ctx context.Context) error
+
+	// Nack returns the message to the queue for redelivery
+	Nack(ctx context.Context) error
+}
+
+// Source delivers job messages
+type Source interface {
+	// Fetch blocks until a message is available or ctx is done
+	Fetch(ctx context.Context) (Message, error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context) (Message, error)
+
+// Fetch calls f(ctx)
+func (f SourceFunc) Fetch(ctx context.Context) (Message, error) {
+	return f(ctx)
+}
+
+// Publisher publishes result and usage events
+type Publisher interface {
+	Publish(ctx context.Context, topic string, data []byte) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface
+type PublisherFunc func(ctx context.Context, topic string, data []byte) error
+
+// Publish calls f(ctx, topic, data)
+func (f PublisherFunc) Publish(ctx context.Context, topic string, data []byte) error {
+	return f(ctx, topic, data)
+}
+
+// Options configures a Worker
+type Options struct {
+	// ResultTopic receives a JobResult for every job (required)
+	ResultTopic string
+
+	// UsageTopic receives a UsageEvent for every job that used tokens
+	// (optional)
+	UsageTopic string
+
+	// ResolveModel looks up job models. Defaults to the global registry.
+	ResolveModel registry.ModelResolver
+
+	// DefaultModel is used by generate jobs without a model (optional)
+	DefaultModel string
+
+	// Agents available to agent jobs, by name
+	Agents map[string]agent.Agent
+
+	// Concurrency is the number of jobs run in parallel (default 1)
+	Concurrency int
+
+	// JobTimeout bounds each attempt of a job (0 = no timeout)
+	JobTimeout time.Duration
+
+	// MaxRetries is the number of retries for transient failures such as rate
+	// limits and server errors (default 3; set to -1 to disable retries)
+	MaxRetries int
+
+	// RequestsPerMinute caps the jobs started per minute (0 = unlimited)
+	RequestsPerMinute int
+
+	// MaxTokensPerJob caps the output tokens of generate jobs (0 = no cap)
+	MaxTokensPerJob int
+
+	// TokenBudget is the total number of tokens the worker may consume. Once
+	// it is reached the worker stops fetching and Run returns
+	// ErrBudgetExhausted (0 = unlimited).
+	TokenBudget int64
+
+	// Webhook, if set, is notified of every finished job with a
+	// "job.succeeded" or "job.failed" event whose data is the JobResult.
+	// Deliveries run in the background, so retries do not hold up jobs;
+	// Run waits for pending deliveries before returning.
+	Webhook *webhook.Sender
+
+	// OnError is called for errors that do not fail a job, such as
+	// undecodable messages or publish and webhook failures (optional)
+	OnError func(err error)
+
+	// Clock drives rate limiting, job timeouts, retry backoff and result
+	// timestamps, and RNG the backoff jitter (defaults: system clock,
+	// math/rand)
+	Clock clock.Clock
+	RNG   clock.RNG
+}
+
+// Stats are cumulative worker counters
+type Stats struct {
+	Processed int64
+	Succeeded int64
+	Failed    int64
+	Tokens    int64
+}
+
+// Worker consumes jobs from a Source and publishes results to a Publisher
+//
+// Example:
+//
+//	w, err := worker.New(source, publisher, worker.Options{
+//		ResultTopic:  "ai.results",
+//		UsageTopic:   "ai.usage",
+//		DefaultModel: "openai:gpt-4o-mini",
+//		Concurrency:  8,
+//		TokenBudget:  5_000_000,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	return w.Run(ctx)
+type Worker struct {
+	source    Source
+	publisher Publisher
+	opts      Options
+	retryCfg  retry.Config
+	limiter   *rate.Limiter
+
+	processed atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	tokens    atomic.Int64
+
+	webhooks sync.WaitGroup // pending webhook deliveries
+}
+
+// New creates a Worker
+func New(source Source, publisher Publisher, opts Options) (*Worker, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source is required")
+	}
+	if publisher == nil {
+		return nil, fmt.Errorf("publisher is required")
+	}
+	if opts.ResultTopic == "" {
+		return nil, fmt.Errorf("result topic is required")
+	}
+	if opts.ResolveModel == nil {
+		opts.ResolveModel = registry.ResolveLanguageModel
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	w := &Worker{source: source, publisher: publisher, opts: opts}
+	w.retryCfg = retry.DefaultConfig()
+	w.retryCfg.ShouldRetry = isRetryable
+	w.retryCfg.Clock, w.retryCfg.RNG = opts.Clock, opts.RNG
+	switch {
+	case opts.MaxRetries < 0:
+		// retry.Do treats 0 as "use defaults", so disable retries explicitly
+		w.retryCfg.ShouldRetry = func(error) bool { return false }
+	case opts.MaxRetries > 0:
+		w.retryCfg.MaxRetries = opts.MaxRetries
+	}
+	if opts.RequestsPerMinute > 0 {
+		w.limiter = rate.NewLimiter(rate.Limit(float64(opts.RequestsPerMinute)/60), 1)
+	}
+	return w, nil
+}
+
+// Stats returns the worker's counters
+func (w *Worker) Stats() Stats {
+	return Stats{
+		Processed: w.processed.Load(),
+		Succeeded: w.succeeded.Load(),
+		Failed:    w.failed.Load(),
+		Tokens:    w.tokens.Load(),
+	}
+}
+
+// Run consumes jobs until ctx is cancelled, the source fails, or the token
+// budget is exhausted. In-flight jobs are finished before Run returns; jobs
+// interrupted by cancellation are nacked so the queue redelivers them.
+func (w *Worker) Run(ctx context.Context) error {
+	sem := make(chan struct{}, w.opts.Concurrency)
+	var wg sync.WaitGroup
+	// Deferred calls run last-in first-out: jobs finish, then the webhooks
+	// they queued are delivered
+	defer w.webhooks.Wait()
+	defer wg.Wait()
+
+	for {
+		if w.budgetExhausted() {
+			return ErrBudgetExhausted
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// The budget may have been spent while waiting for a slot
+		if w.budgetExhausted() {
+			<-sem
+			return ErrBudgetExhausted
+		}
+
+		msg, err := w.source.Fetch(ctx)
+		if err != nil {
+			<-sem
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to fetch job: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.handle(ctx, msg)
+		}()
+	}
+}
+
+func (w *Worker) budgetExhausted() bool {
+	return w.opts.TokenBudget > 0 && w.tokens.Load() >= w.opts.TokenBudget
+}
+
+// handle runs one message and settles it with the queue
+func (w *Worker) handle(ctx context.Context, msg Message) {
+	// Settling uses a fresh context so shutdown does not leave messages
+	// unacknowledged
+	settleCtx := context.WithoutCancel(ctx)
+
+	var job Job
+	if err := json.Unmarshal(msg.Data(), &job); err != nil || job.ID == "" {
+		if err == nil {
+			err = fmt.Errorf("job id is required")
+		}
+		// A malformed job can never succeed; drop it rather than redeliver
+		w.reportError(fmt.Errorf("invalid job: %w", err))
+		w.reportError(msg.Ack(settleCtx))
+		return
+	}
+
+	result := w.execute(ctx, &job)
+	if ctx.Err() != nil && result.Status == JobStatusFailed {
+		w.reportError(msg.Nack(settleCtx))
+		return
+	}
+
+	w.processed.Add(1)
+	if result.Status == JobStatusSucceeded {
+		w.succeeded.Add(1)
+	} else {
+		w.failed.Add(1)
+	}
+	w.publish(settleCtx, w.opts.ResultTopic, result)
+	w.notify(settleCtx, result)
+	w.reportError(msg.Ack(settleCtx))
+}
+
+// notify delivers the job's webhook event in the background
+func (w *Worker) notify(ctx context.Context, result *JobResult) {
+	if w.opts.Webhook == nil {
+		return
+	}
+	eventType := EventJobSucceeded
+	if result.Status == JobStatusFailed {
+		eventType = EventJobFailed
+	}
+	w.webhooks.Add(1)
+	go func() {
+		defer w.webhooks.Done()
+		if err := w.opts.Webhook.Notify(ctx, eventType, result); err != nil {
+			w.reportError(fmt.Errorf("webhook for job %s: %w", result.JobID, err))
+		}
+	}()
+}
+
+// execute runs a job with retries and records its usage
+func (w *Worker) execute(ctx context.Context, job *Job) *JobResult {
+	result := &JobResult{JobID: job.ID, StartedAt: clock.Default(w.opts.Clock).Now(), Metadata: job.Metadata}
+
+	model, run, err := w.prepare(job)
+	if err == nil {
+		err = retry.Do(ctx, w.retryCfg, func(ctx context.Context) error {
+			result.Attempts++
+			if w.limiter != nil {
+				if err := clock.WaitN(ctx, w.opts.Clock, w.limiter, 1); err != nil {
+					return err
+				}
+			}
+			if w.opts.JobTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = clock.WithTimeout(ctx, w.opts.Clock, w.opts.JobTimeout)
+				defer cancel()
+			}
+			text, finish, usage, err := run(ctx)
+			result.Usage = result.Usage.Add(usage)
+			if err != nil {
+				return err
+			}
+			result.Text, result.FinishReason = text, string(finish)
+			return nil
+		})
+	}
+
+	result.FinishedAt = clock.Default(w.opts.Clock).Now()
+	if err != nil {
+		result.Status = JobStatusFailed
+		result.Error = err.Error()
+	} else {
+		result.Status = JobStatusSucceeded
+	}
+
+	if total := result.Usage.GetTotalTokens(); total > 0 {
+		w.tokens.Add(total)
+		if w.opts.UsageTopic != "" {
+			w.publish(context.WithoutCancel(ctx), w.opts.UsageTopic, UsageEvent{
+				JobID:        job.ID,
+				Model:        model,
+				InputTokens:  result.Usage.GetInputTokens(),
+				OutputTokens: result.Usage.GetOutputTokens(),
+				TotalTokens:  total,
+				Timestamp:    result.FinishedAt,
+				Metadata:     job.Metadata,
+			})
+		}
+	}
+	return result
+}
+
+type runFunc func(ctx context.Context) (string, types.FinishReason, types.Usage, error)
+
+// prepare validates a job and returns the model name for usage events and a
+// function running one attempt
+func (w *Worker) prepare(job *Job) (string, runFunc, error) {
+	if job.Prompt == "" && len(job.Messages) == 0 {
+		return "", nil, fmt.Errorf("prompt or messages is required")
+	}
+	messages := make([]types.Message, len(job.Messages))
+	for i, m := range job.Messages {
+		messages[i] = types.Message{Role: m.Role, Content: []types.ContentPart{types.TextContent{Text: m.Content}}}
+	}
+
+	switch job.Type {
+	case JobTypeGenerate, "":
+		name := job.Model
+		if name == "" {
+			name = w.opts.DefaultModel
+		}
+		if name == "" {
+			return "", nil, fmt.Errorf("model is required")
+		}
+		model, err := w.opts.ResolveModel(name)
+		if err != nil {
+			return name, nil, fmt.Errorf("unknown model %q: %w", name, err)
+		}
+		maxTokens := job.MaxTokens
+		if limit := w.opts.MaxTokensPerJob; limit > 0 && (maxTokens == nil || *maxTokens > limit) {
+			maxTokens = &limit
+		}
+		return name, func(ctx context.Context) (string, types.FinishReason, types.Usage, error) {
+			res, err := ai.GenerateText(ctx, ai.GenerateTextOptions{
+				Model:       model,
+				System:      job.System,
+				Prompt:      job.Prompt,
+				Messages:    messages,
+				Temperature: job.Temperature,
+				MaxTokens:   maxTokens,
+			})
+			if err != nil {
+				return "", "", types.Usage{}, err
+			}
+			return res.Text, res.FinishReason, res.Usage, nil
+		}, nil
+
+	case JobTypeAgent:
+		a, ok := w.opts.Agents[job.Agent]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown agent %q", job.Agent)
+		}
+		if job.Prompt != "" {
+			messages = append(messages, types.Message{
+				Role:    types.RoleUser,
+				Content: []types.ContentPart{types.TextContent{Text: job.Prompt}},
+			})
+		}
+		return "agent:" + job.Agent, func(ctx context.Context) (string, types.FinishReason, types.Usage, error) {
+			res, err := a.ExecuteWithMessages(ctx, messages)
+			if err != nil {
+				if res != nil {
+					return "", "", res.Usage, err
+				}
+				return "", "", types.Usage{}, err
+			}
+			return res.Text, res.FinishReason, res.Usage, nil
+		}, nil
+
+	default:
+		return "", nil, fmt.Errorf("unknown job type %q", job.Type)
+	}
+}
+
+func (w *Worker) publish(ctx context.Context, topic string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		w.reportError(fmt.Errorf("failed to encode %s event: %w", topic, err))
+		return
+	}
+	if err := w.publisher.Publish(ctx, topic, data); err != nil {
+		w.reportError(fmt.Errorf("failed to publish to %s: %w", topic, err))
+	}
+}
+
+func (w *Worker) reportError(err error) {
+	if err != nil && w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// isRetryable retries rate limits, server errors and network failures, but
+// not validation or client errors
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if providererrors.IsValidationError(err) {
+		return false
+	}
+	if providererrors.IsRateLimitError(err) {
+		return true
+	}
+	var provErr *providererrors.ProviderError
+	if errors.As(err, &provErr) {
+		return provErr.StatusCode == 0 || provErr.StatusCode == 408 || provErr.StatusCode == 429 || provErr.StatusCode >= 500
+	}
+	return true
+}
@@ -0,0 +1,285 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitallysavvy/go-ai/pkg/agent"
+	"github.com/digitallysavvy/go-ai/pkg/provider"
+	providererrors "github.com/digitallysavvy/go-ai/pkg/provider/errors"
+	"github.com/digitallysavvy/go-ai/pkg/provider/types"
+	"github.com/digitallysavvy/go-ai/pkg/registry"
+	"github.com/digitallysavvy/go-ai/pkg/testutil"
+	"github.com/digitallysavvy/go-ai/pkg/webhook"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func usageModel(text string, tokens int64) *testutil.MockLanguageModel {
+	return &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			return &types.GenerateResult{
+				Text:         text,
+				FinishReason: types.FinishReasonStop,
+				Usage:        types.Usage{InputTokens: int64Ptr(tokens / 2), OutputTokens: int64Ptr(tokens / 2), TotalTokens: int64Ptr(tokens)},
+			}, nil
+		},
+	}
+}
+
+func resolveTo(model provider.LanguageModel) registry.ModelResolver {
+	return func(string) (provider.LanguageModel, error) { return model, nil }
+}
+
+// runUntil runs w until n results have been published
+func runUntil(t *testing.T, w *Worker, q *MemoryQueue, n int) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	for len(q.Published("results")) < n {
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %d results", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	return <-done
+}
+
+func decodeResults(t *testing.T, q *MemoryQueue) map[string]JobResult {
+	t.Helper()
+	results := make(map[string]JobResult)
+	for _, data := range q.Published("results") {
+		var r JobResult
+		if err := json.Unmarshal(data, &r); err != nil {
+			t.Fatalf("invalid result: %v", err)
+		}
+		results[r.JobID] = r
+	}
+	return results
+}
+
+func TestWorker_ProcessesJobsAndPublishesUsage(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue(10)
+	var maxTokens atomic.Int64
+	model := usageModel("done", 10)
+	generate := model.DoGenerateFunc
+	model.DoGenerateFunc = func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+		if opts.MaxTokens != nil {
+			maxTokens.Store(int64(*opts.MaxTokens))
+		}
+		return generate(ctx, opts)
+	}
+	w, err := New(q, q, Options{
+		ResultTopic:     "results",
+		UsageTopic:      "usage",
+		ResolveModel:    resolveTo(model),
+		DefaultModel:    "mock:model",
+		Concurrency:     3,
+		MaxTokensPerJob: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	big := 500
+	for _, job := range []Job{
+		{ID: "a", Prompt: "one", MaxTokens: &big, Metadata: map[string]string{"tenant": "t1"}},
+		{ID: "b", Messages: []JobMessage{{Role: types.RoleUser, Content: "two"}}},
+		{ID: "c", Prompt: "three"},
+	} {
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runUntil(t, w, q, 3); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+	results := decodeResults(t, q)
+	for _, id := range []string{"a", "b", "c"} {
+		if r := results[id]; r.Status != JobStatusSucceeded || r.Text != "done" || r.Attempts != 1 {
+			t.Errorf("job %s result = %+v", id, r)
+		}
+	}
+	if results["a"].Metadata["tenant"] != "t1" {
+		t.Errorf("metadata not propagated: %v", results["a"].Metadata)
+	}
+	if maxTokens.Load() != 100 {
+		t.Errorf("MaxTokensPerJob not applied, got %d", maxTokens.Load())
+	}
+	if got := len(q.Published("usage")); got != 3 {
+		t.Errorf("got %d usage events, want 3", got)
+	}
+	if s := w.Stats(); s.Succeeded != 3 || s.Tokens != 30 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestWorker_RetriesAndFailures(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	model := &testutil.MockLanguageModel{
+		DoGenerateFunc: func(ctx context.Context, opts *provider.GenerateOptions) (*types.GenerateResult, error) {
+			if opts.Prompt.System == "bad" {
+				return nil, providererrors.NewProviderError("mock", 400, "invalid", "bad request", nil)
+			}
+			if calls.Add(1) == 1 {
+				return nil, providererrors.NewProviderError("mock", 503, "unavailable", "try again", nil)
+			}
+			return &types.GenerateResult{Text: "ok", FinishReason: types.FinishReasonStop}, nil
+		},
+	}
+	q := NewMemoryQueue(10)
+	var reported atomic.Int32
+	w, _ := New(q, q, Options{
+		ResultTopic:  "results",
+		ResolveModel: resolveTo(model),
+		DefaultModel: "mock:model",
+		OnError:      func(error) { reported.Add(1) },
+	})
+	w.retryCfg.InitialDelay = time.Millisecond
+
+	ctx := context.Background()
+	q.Enqueue(ctx, Job{ID: "flaky", Prompt: "hello"})
+	q.Enqueue(ctx, Job{ID: "bad", System: "bad", Prompt: "hello"})
+	q.Enqueue(ctx, Job{ID: "agentless", Type: JobTypeAgent, Agent: "missing", Prompt: "x"})
+	q.jobs <- []byte("not json")
+
+	runUntil(t, w, q, 3)
+	results := decodeResults(t, q)
+	if r := results["flaky"]; r.Status != JobStatusSucceeded || r.Attempts != 2 {
+		t.Errorf("flaky = %+v", r)
+	}
+	if r := results["bad"]; r.Status != JobStatusFailed || r.Attempts != 1 {
+		t.Errorf("bad = %+v", r)
+	}
+	if r := results["agentless"]; r.Status != JobStatusFailed || r.Error == "" {
+		t.Errorf("agentless = %+v", r)
+	}
+	if reported.Load() == 0 {
+		t.Error("malformed job was not reported")
+	}
+}
+
+func TestWorker_AgentJob(t *testing.T) {
+	t.Parallel()
+
+	a := agent.NewToolLoopAgent(agent.AgentConfig{Model: usageModel("agent reply", 4)})
+	q := NewMemoryQueue(1)
+	w, _ := New(q, q, Options{ResultTopic: "results", UsageTopic: "usage", Agents: map[string]agent.Agent{"helper": a}})
+	q.Enqueue(context.Background(), Job{ID: "j", Type: JobTypeAgent, Agent: "helper", Prompt: "hi"})
+
+	runUntil(t, w, q, 1)
+	if r := decodeResults(t, q)["j"]; r.Status != JobStatusSucceeded || r.Text != "agent reply" {
+		t.Errorf("result = %+v", r)
+	}
+	var event UsageEvent
+	json.Unmarshal(q.Published("usage")[0], &event)
+	if event.Model != "agent:helper" || event.TotalTokens != 4 {
+		t.Errorf("usage event = %+v", event)
+	}
+}
+
+func TestWorker_TokenBudget(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue(10)
+	w, _ := New(q, q, Options{
+		ResultTopic:  "results",
+		ResolveModel: resolveTo(usageModel("x", 10)),
+		DefaultModel: "mock:model",
+		TokenBudget:  15,
+	})
+	for _, id := range []string{"1", "2", "3", "4"} {
+		q.Enqueue(context.Background(), Job{ID: id, Prompt: "p"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Run(ctx); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("Run returned %v, want ErrBudgetExhausted", err)
+	}
+	if got := len(q.Published("results")); got != 2 {
+		t.Errorf("processed %d jobs, want 2", got)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Parallel()
+
+	q := NewMemoryQueue(1)
+	if _, err := New(nil, q, Options{ResultTopic: "r"}); err == nil {
+		t.Error("expected error without source")
+	}
+	if _, err := New(q, q, Options{}); err == nil {
+		t.Error("expected error without result topic")
+	}
+}
+
+func TestWorker_Webhook(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	events := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify("secret", r.Header, body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event struct {
+			Type string    `json:"type"`
+			Data JobResult `json:"data"`
+		}
+		_ = json.Unmarshal(body, &event)
+		mu.Lock()
+		events[event.Data.JobID] = event.Type
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sender, err := webhook.NewSender(webhook.Options{URL: server.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := NewMemoryQueue(10)
+	w, err := New(q, q, Options{
+		ResultTopic:  "results",
+		ResolveModel: resolveTo(usageModel("done", 2)),
+		DefaultModel: "mock:model",
+		Webhook:      sender,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = q.Enqueue(context.Background(), Job{ID: "ok", Prompt: "hi"})
+	_ = q.Enqueue(context.Background(), Job{ID: "bad"})
+
+	// Run returns only after pending webhooks are delivered
+	if err := runUntil(t, w, q, 2); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if events["ok"] != EventJobSucceeded || events["bad"] != EventJobFailed {
+		t.Errorf("unexpected webhook events %v", events)
+	}
+}